@@ -16,6 +16,7 @@ export interface OrgUnitListResponse {
   page?: number
   size?: number
   total?: number
+  next_cursor?: string
   org_units: OrgUnitAPIItem[]
 }
 
@@ -39,6 +40,23 @@ export type OrgUnitListStatusFilter = 'all' | 'active' | 'inactive'
 export type OrgUnitListSortField = 'code' | 'name' | 'status' | `ext:${string}`
 export type OrgUnitListSortOrder = 'asc' | 'desc'
 
+export type OrgUnitListFilterOp =
+  | 'eq'
+  | 'in'
+  | 'prefix'
+  | 'gt'
+  | 'gte'
+  | 'lt'
+  | 'lte'
+  | 'between'
+  | 'is_null'
+  | 'not_null'
+
+export type OrgUnitListFilter =
+  | { and: OrgUnitListFilter[] }
+  | { or: OrgUnitListFilter[] }
+  | { field: string; op: OrgUnitListFilterOp; value?: string | number | boolean; values?: Array<string | number | boolean> }
+
 export async function listOrgUnitsPage(options: {
   asOf: string
   parentOrgCode?: string
@@ -52,13 +70,20 @@ export async function listOrgUnitsPage(options: {
   sortOrder?: OrgUnitListSortOrder | null
   extFilterFieldKey?: string
   extFilterValue?: string
+  filter?: OrgUnitListFilter | null
+  // Keyset paging: pass '' for the first page, then the previous response's next_cursor; page is ignored.
+  cursor?: string | null
 }): Promise<OrgUnitListResponse> {
   const query = new URLSearchParams({
     as_of: options.asOf,
     mode: 'grid',
-    page: String(options.page),
     size: String(options.pageSize)
   })
+  if (typeof options.cursor === 'string') {
+    query.set('cursor', options.cursor)
+  } else {
+    query.set('page', String(options.page))
+  }
   if (options.parentOrgCode) {
     query.set('parent_org_code', options.parentOrgCode)
   }
@@ -86,6 +111,9 @@ export async function listOrgUnitsPage(options: {
     query.set('ext_filter_field_key', options.extFilterFieldKey)
     query.set('ext_filter_value', options.extFilterValue)
   }
+  if (options.filter) {
+    query.set('filter', JSON.stringify(options.filter))
+  }
 
   return httpClient.get<OrgUnitListResponse>(`/org/api/org-units?${query.toString()}`)
 }
@@ -191,17 +219,22 @@ export interface OrgUnitAuditResponse {
   org_code: string
   limit: number
   has_more: boolean
+  next_cursor?: string
   events: OrgUnitAuditAPIItem[]
 }
 
 export async function listOrgUnitAudit(options: {
   orgCode: string
   limit?: number
+  cursor?: string
 }): Promise<OrgUnitAuditResponse> {
   const query = new URLSearchParams({ org_code: options.orgCode })
   if (options.limit) {
     query.set('limit', String(options.limit))
   }
+  if (options.cursor) {
+    query.set('cursor', options.cursor)
+  }
 
   return httpClient.get<OrgUnitAuditResponse>(`/org/api/org-units/audit?${query.toString()}`)
 }
@@ -213,6 +246,16 @@ export interface OrgUnitSearchResult {
   path_org_ids: number[]
   path_org_codes?: string[]
   tree_as_of: string
+  match_field?: OrgUnitSearchMatchField
+  highlights?: OrgUnitSearchHighlight[]
+}
+
+export type OrgUnitSearchMatchField = 'org_code' | 'name' | 'full_name_path' | 'pinyin' | 'pinyin_initials'
+
+export interface OrgUnitSearchHighlight {
+  field: 'org_code' | 'name' | 'full_name_path'
+  start: number
+  end: number
 }
 
 export interface OrgUnitSearchCandidate {
@@ -221,6 +264,10 @@ export interface OrgUnitSearchCandidate {
   status?: string
   as_of?: string
   path_org_codes?: string[]
+  full_name_path?: string
+  match_field?: OrgUnitSearchMatchField
+  score?: number
+  highlights?: OrgUnitSearchHighlight[]
 }
 
 export interface OrgUnitSearchAmbiguousDetails {
@@ -237,6 +284,7 @@ export async function searchOrgUnit(options: {
   query: string
   asOf: string
   includeDisabled?: boolean
+  limit?: number
 }): Promise<OrgUnitSearchResult> {
   const queryParams = new URLSearchParams({
     query: options.query,
@@ -245,6 +293,9 @@ export async function searchOrgUnit(options: {
   if (options.includeDisabled) {
     queryParams.set('include_disabled', '1')
   }
+  if (options.limit) {
+    queryParams.set('limit', String(options.limit))
+  }
 
   return httpClient.get<OrgUnitSearchResult>(`/org/api/org-units/search?${queryParams.toString()}`)
 }
@@ -14,6 +14,7 @@ export const AUTHZ_CAPABILITY_KEYS = {
   iamDictReleaseAdmin: 'iam.dict_release:admin',
   iamDictsAdmin: 'iam.dicts:admin',
   iamDictsRead: 'iam.dicts:read',
+  iamWebhooksAdmin: 'iam.webhooks:admin',
   orgunitOrgUnitsAdmin: 'orgunit.orgunits:admin',
   orgunitOrgUnitsRead: 'orgunit.orgunits:read'
 } as const satisfies Record<string, AuthzCapabilityKey>
@@ -11,10 +11,15 @@ const localizedMessages: Record<string, LocalizedErrorMessage> = {
   policy_conflict_ambiguous: { en: 'Field policy is conflicting.', zh: '字段策略存在冲突，请联系管理员修复。' },
   policy_missing: { en: 'Field policy is missing for current context.', zh: '当前上下文缺少字段策略，请刷新后重试。' },
   FIELD_POLICY_EXPR_INVALID: { en: 'Default rule expression is invalid.', zh: '默认规则表达式不合法。' },
+  FIELD_VALIDATION_FAILED: { en: 'Some fields failed the tenant validation rules.', zh: '部分字段未通过租户校验规则，请按提示修改。' },
   FIELD_REQUIRED_VALUE_MISSING: { en: 'Required field value is missing.', zh: '策略决议后必填字段仍为空，请补全后重试。' },
+  MANAGER_PERNR_INACTIVE: { en: 'Manager is not active on the effective date.', zh: '负责人在生效日不是在职状态。' },
+  MANAGER_PERNR_INVALID: { en: 'Manager employee number is invalid.', zh: '负责人工号格式无效（1-8 位数字）。' },
+  MANAGER_PERNR_NOT_FOUND: { en: 'Manager employee number was not found in the person directory.', zh: '人员目录中不存在该负责人工号。' },
   ORG_ALREADY_EXISTS: { en: 'Org already exists.', zh: '请求失败（org already exists）。' },
   ORG_CODE_INVALID: { en: 'Org code is invalid.', zh: '请求失败（org code invalid）。' },
   ORG_CODE_NOT_FOUND: { en: 'Org code not found.', zh: '请求失败（org code not found）。' },
+  ORG_COMPOSITE_TARGET_DISABLED: { en: 'A unit in the merge or split is disabled on the effective date.', zh: '参与合并/拆分的组织在生效日已停用。' },
   ORG_EVENT_NOT_FOUND: { en: 'Target effective-date record is not found.', zh: '未找到目标生效日记录。' },
   ORG_EVENT_RESCINDED: { en: 'Target record has been rescinded.', zh: '目标记录已被撤销。' },
   ORG_EXT_QUERY_FIELD_NOT_ALLOWED: { en: 'Org ext query field not allowed.', zh: '请求失败（org ext query field not allowed）。' },
@@ -22,10 +27,24 @@ const localizedMessages: Record<string, LocalizedErrorMessage> = {
   ORG_FIELD_DEFINITION_NOT_FOUND: { en: 'Org field definition not found.', zh: '请求失败（org field definition not found）。' },
   ORG_FIELD_OPTIONS_FIELD_NOT_ENABLED_AS_OF: { en: 'Org field options field not enabled as of.', zh: '请求失败（org field options field not enabled as of）。' },
   ORG_FIELD_OPTIONS_NOT_SUPPORTED: { en: 'Org field options not supported.', zh: '请求失败（org field options not supported）。' },
+  ORG_FIELD_VALIDATION_RULE_NOT_FOUND: { en: 'Field validation rule not found.', zh: '未找到该字段校验规则。' },
   ORG_INTENT_NOT_SUPPORTED: { en: 'Org intent not supported.', zh: '请求失败（org intent not supported）。' },
+  ORG_MERGE_SURVIVOR_INVALID: { en: 'The surviving unit cannot be a merged unit or one of its descendants.', zh: '保留组织不能是被合并组织或其下级。' },
   ORG_NOT_FOUND_AS_OF: { en: 'Org not found as of.', zh: '请求失败（org not found as of）。' },
   ORG_ROOT_ALREADY_EXISTS: { en: 'Org root already exists.', zh: '请求失败（org root already exists）。' },
+  ORG_ROOT_CANNOT_BE_MERGED: { en: 'The root unit cannot be merged.', zh: '根组织不允许被合并。' },
+  ORG_ROOT_CANNOT_BE_SPLIT: { en: 'The root unit cannot be split.', zh: '根组织不允许拆分。' },
+  ORG_SPLIT_CHILD_NOT_UNDER_SOURCE: { en: 'Each distributed child must be a direct child of the split unit and used once.', zh: '拆分的下级组织必须是被拆分组织的直接下级，且只能分配一次。' },
+  ORG_SPLIT_SOURCE_HAS_CHILDREN: { en: 'The split unit still has undistributed children and cannot be disabled.', zh: '被拆分组织仍有未分配的下级组织，不能停用。' },
   ORG_TREE_NOT_INITIALIZED: { en: 'Org tree not initialized.', zh: '请求失败（org tree not initialized）。' },
+  PERSON_DISPLAY_NAME_REQUIRED: { en: 'Person display name is required.', zh: '人员姓名不能为空。' },
+  PERSON_EFFECTIVE_DATE_INVALID: { en: 'Person effective date is invalid.', zh: '人员生效日期无效。' },
+  PERSON_IMPORT_EMPTY: { en: 'Person import contains no rows.', zh: '人员导入内容为空。' },
+  PERSON_IMPORT_TOO_LARGE: { en: 'Person import has too many rows.', zh: '人员导入行数超出上限。' },
+  PERSON_IN_USE: { en: 'Person is still assigned as an org unit manager.', zh: '该人员仍是组织负责人，无法删除。' },
+  PERSON_NOT_FOUND: { en: 'Person not found.', zh: '人员不存在。' },
+  PERSON_PERNR_INVALID: { en: 'Employee number is invalid.', zh: '工号格式无效（1-8 位数字）。' },
+  PERSON_STATUS_INVALID: { en: 'Person status is invalid.', zh: '人员状态无效。' },
   audit_error: { en: 'Audit error.', zh: '请求失败（audit error）。' },
   ai_actor_auth_snapshot_expired: { en: 'Auth snapshot expired. Please re-auth and retry.', zh: '身份快照已过期，请重新认证后重试。' },
   ai_actor_role_drift_detected: { en: 'Role changed during this conversation. Please re-confirm.', zh: '会话期间角色发生变化，请重新确认后提交。' },
@@ -48,6 +67,8 @@ const localizedMessages: Record<string, LocalizedErrorMessage> = {
   ai_runtime_config_invalid: { en: 'Assistant runtime model configuration is invalid. Please fix and restart service.', zh: '助手运行时模型配置不合法，请修正配置并重启服务。' },
   ai_runtime_config_missing: { en: 'Assistant runtime model configuration is missing. Please configure and restart service.', zh: '助手运行时模型配置缺失，请完成配置并重启服务。' },
   ai_model_secret_missing: { en: 'Model provider secret is missing. Please configure key reference and retry.', zh: '模型密钥缺失，请检查 key_ref 配置后重试。' },
+  ai_model_secret_vault_unavailable: { en: 'Secret vault is not configured. Use an env:// key reference instead.', zh: '密钥保险库未配置，请改用 env:// 密钥引用。' },
+  ai_budget_exceeded: { en: 'AI token budget reached. Ask an administrator to raise the budget.', zh: 'AI 用量已达到预算上限，请联系管理员调整预算。' },
   ai_reply_render_failed: { en: 'Assistant reply generation failed. Please retry.', zh: '助手回复生成失败，请稍后重试。' },
   ai_reply_model_target_mismatch: { en: 'Assistant reply did not come from the expected model pipeline. Please retry later.', zh: '助手回复未命中预期的大模型链路，请稍后重试。' },
   authz_error: { en: 'Authz error.', zh: '请求失败（authz error）。' },
@@ -77,9 +98,14 @@ const localizedMessages: Record<string, LocalizedErrorMessage> = {
   conversation_turn_not_found: { en: 'Turn is not found in this conversation.', zh: '当前会话中未找到该回合。' },
   cubebox_conversation_create_failed: { en: 'Failed to create conversation. Please retry.', zh: '创建会话失败，请稍后重试。' },
   cubebox_conversation_list_failed: { en: 'Failed to load conversation list. Please retry.', zh: '加载会话列表失败，请稍后重试。' },
+  cubebox_conversation_cursor_invalid: { en: 'Conversation list position is no longer valid. Reload the list.', zh: '会话列表翻页位置无效，请刷新列表。' },
   cubebox_conversation_not_found: { en: 'Conversation is not found.', zh: '会话不存在，请重新选择或新建。' },
   cubebox_conversation_read_failed: { en: 'Failed to load conversation. Please retry.', zh: '读取会话失败，请稍后重试。' },
   cubebox_conversation_update_failed: { en: 'Failed to update conversation. Please retry.', zh: '更新会话失败，请稍后重试。' },
+  cubebox_conversation_turn_not_found: { en: 'Turn is not found in this conversation.', zh: '当前会话中未找到该回合。' },
+  cubebox_share_not_found: { en: 'Shared conversation is not found, expired or revoked.', zh: '分享不存在、已过期或已撤销。' },
+  cubebox_share_failed: { en: 'Failed to share conversation. Please retry.', zh: '分享会话失败，请稍后重试。' },
+  cubebox_feedback_failed: { en: 'Failed to process answer feedback. Please retry.', zh: '回答反馈处理失败，请稍后重试。' },
   cubebox_turn_stream_failed: { en: 'CubeBox response failed. Please retry later.', zh: 'CubeBox 回复失败，请稍后重试。' },
   idempotency_key_conflict: { en: 'Request payload conflicts with existing idempotency key.', zh: '请求载荷与已有幂等键冲突，请使用新的 request_id 重试。' },
   request_in_progress: { en: 'Request is still in progress. Please retry shortly.', zh: '请求仍在处理中，请稍后重试。' },
@@ -88,8 +114,22 @@ const localizedMessages: Record<string, LocalizedErrorMessage> = {
   identity_error: { en: 'Identity error.', zh: '请求失败（identity error）。' },
   identity_provider_error: { en: 'Identity provider error.', zh: '请求失败（identity provider error）。' },
   idp_error: { en: 'Idp error.', zh: '请求失败（idp error）。' },
+  impersonation_audit_error: { en: 'Impersonated request could not be audited; it was refused.', zh: '模拟会话请求无法记录审计，已拒绝。' },
+  impersonation_ended: { en: 'This impersonation has already ended.', zh: '该模拟会话已结束。' },
+  impersonation_invalid_ttl: { en: 'Impersonation duration is invalid.', zh: '模拟会话时长无效。' },
+  impersonation_list_error: { en: 'Impersonation sessions could not be loaded.', zh: '无法加载模拟会话记录。' },
+  impersonation_not_found: { en: 'Impersonation is not found.', zh: '未找到模拟会话。' },
+  impersonation_principal_inactive: { en: 'The selected user is not active.', zh: '所选用户未启用。' },
+  impersonation_principal_not_found: { en: 'The selected user is not found in this tenant.', zh: '该租户中未找到所选用户。' },
+  impersonation_read_only: { en: 'This impersonated session is read-only.', zh: '当前模拟会话为只读。' },
+  impersonation_reason_required: { en: 'An impersonation reason is required.', zh: '请填写模拟登录原因。' },
+  impersonation_tenant_inactive: { en: 'Impersonation requires an active tenant.', zh: '仅可模拟登录已启用的租户。' },
+  impersonation_tenant_no_domain: { en: 'The tenant has no domain to open a session on.', zh: '该租户未绑定域名，无法打开会话。' },
+  impersonation_ticket_invalid: { en: 'The impersonation link is invalid, used, or expired.', zh: '模拟登录链接无效、已使用或已过期。' },
   internal_error: { en: 'Internal server error.', zh: '请求失败（internal error）。' },
   invalid_as_of: { en: 'Invalid as of.', zh: '请求失败（invalid as of）。' },
+  invalid_audit_log_cursor: { en: 'Invalid audit log cursor.', zh: '审计日志分页游标无效。' },
+  invalid_audit_log_filter: { en: 'Invalid audit log filter.', zh: '审计日志筛选条件无效。' },
   invalid_credentials: { en: 'Invalid credentials.', zh: '请求失败（invalid credentials）。' },
   invalid_effective_date: { en: 'Invalid effective date.', zh: '请求失败（invalid effective date）。' },
   invalid_form: { en: 'Invalid form.', zh: '请求失败（invalid form）。' },
@@ -98,15 +138,20 @@ const localizedMessages: Record<string, LocalizedErrorMessage> = {
   invalid_input: { en: 'Invalid input.', zh: '请求失败（invalid input）。' },
   invalid_authz_capability_key: { en: 'Authorization identifier format is invalid.', zh: '授权项标识格式无效。' },
   invalid_json: { en: 'Invalid json.', zh: '请求失败（invalid json）。' },
+  invalid_known_at: { en: 'Known-at time must be an RFC 3339 timestamp.', zh: '查询时点（known_at）格式无效。' },
+  invalid_org_unit_cursor: { en: 'The page cursor is invalid or was issued for a different query.', zh: '分页游标无效或与当前查询不匹配，请从第一页重新加载。' },
   invalid_request: { en: 'Request parameters are invalid. Please check and retry.', zh: '请求参数无效，请检查后重试。' },
   invalid_role_definition: { en: 'Role definition is invalid. Please check capabilities and name.', zh: '角色定义不合法，请检查授权项和名称。' },
   invalid_role_payload: { en: 'Role definition request is invalid. Please check and retry.', zh: '角色定义请求无效，请检查后重试。' },
   invalid_user_assignment: { en: 'User authorization settings are invalid. Please check roles and organization scope.', zh: '用户授权设置无效，请检查角色和组织范围。' },
   method_not_allowed: { en: 'HTTP method is not allowed for this endpoint.', zh: '请求失败（method not allowed）。' },
   not_found: { en: 'Requested resource is not found.', zh: '请求失败（not found）。' },
+  openapi_drift: { en: 'API description is out of sync with the route allowlist.', zh: '请求失败（openapi drift）。' },
   org_code_invalid: { en: 'Org code is invalid.', zh: '组织 org_code 无效，请检查后重试。' },
   org_code_not_found: { en: 'Org code not found.', zh: '组织 org_code 不存在，请检查后重试。' },
   org_unit_not_found: { en: 'Org unit not found.', zh: '请求失败（org unit not found）。' },
+  orgunit_known_at_unsupported: { en: 'Orgunit store cannot replay history as known at a time.', zh: '请求失败（orgunit known at unsupported）。' },
+  orgunit_manager_reader_missing: { en: 'Orgunit manager reader is missing.', zh: '请求失败（orgunit manager reader missing）。' },
   orgunit_resolve_org_code_failed: { en: 'Orgunit resolve org code failed.', zh: '请求失败（orgunit resolve org code failed）。' },
   orgunit_service_missing: { en: 'Orgunit service is missing.', zh: '请求失败（orgunit service missing）。' },
   orgunit_store_missing: { en: 'Orgunit store is missing.', zh: '请求失败（orgunit store missing）。' },
@@ -123,13 +168,30 @@ const localizedMessages: Record<string, LocalizedErrorMessage> = {
   stale_revision: { en: 'Data version has changed. Please refresh and retry.', zh: '数据版本已变化，请刷新后重试。' },
   system_role_readonly: { en: 'System-managed roles cannot be modified.', zh: '系统内置角色不可修改。' },
   stream_not_supported: { en: 'Streaming is not supported in the current environment.', zh: '当前环境不支持流式响应，请稍后重试。' },
+  tenant_domain_conflict: { en: 'This hostname is already bound to a tenant.', zh: '该域名已绑定到租户。' },
   tenant_missing: { en: 'Tenant context is missing. Please refresh and retry.', zh: '租户上下文缺失，请刷新后重试。' },
   tenant_not_found: { en: 'Tenant is not found. Please check the current host.', zh: '未找到租户，请检查当前访问域名。' },
+  tenant_offboarding_already_scheduled: { en: 'Tenant deletion is already scheduled.', zh: '该租户已安排删除。' },
+  tenant_offboarding_grace_period: { en: 'The deletion grace period has not elapsed yet.', zh: '删除宽限期尚未结束。' },
+  tenant_offboarding_not_found: { en: 'Tenant offboarding record is not found.', zh: '未找到租户下线记录。' },
+  tenant_offboarding_not_scheduled: { en: 'No tenant deletion is scheduled.', zh: '该租户未安排删除。' },
+  tenant_offboarding_protected: { en: 'This tenant cannot be offboarded.', zh: '该租户不允许下线。' },
+  tenant_offboarding_residual_data: { en: 'Tenant data remained after deletion; nothing was deleted.', zh: '删除后仍有租户数据残留，已回滚删除。' },
+  tenant_offboarding_tenant_active: { en: 'Disable the tenant before offboarding it.', zh: '请先停用租户再执行下线。' },
+  tenant_offboarding_token_mismatch: { en: 'Confirmation token does not match.', zh: '确认令牌不匹配。' },
+  tenant_provision_not_found: { en: 'Tenant provisioning run is not found.', zh: '未找到租户开通记录。' },
+  tenant_provision_request_conflict: { en: 'This request ID was already used with different provisioning input.', zh: '该请求编号已用于不同的开通参数。' },
   tenant_resolve_error: { en: 'Tenant resolution failed. Please retry later.', zh: '租户解析失败，请稍后重试。' },
   turn_id_required: { en: 'Turn ID is required.', zh: '缺少回合 ID，请重试。' },
   unauthorized: { en: 'Your session has expired. Please sign in again.', zh: '登录已失效，请重新登录。' },
   unknown_authz_capability_key: { en: 'Authorization identifier is not registered.', zh: '授权项标识未登记。' },
   web_mui_index_missing: { en: 'Web mui index is missing.', zh: '请求失败（web mui index missing）。' },
+  webhook_endpoint_invalid: { en: 'Webhook endpoint must be a public https URL without credentials.', zh: 'Webhook 地址必须是不含凭据的公网 https URL。' },
+  webhook_event_types_invalid: { en: 'Webhook event types are invalid.', zh: 'Webhook 事件类型无效。' },
+  webhook_replay_invalid: { en: 'Webhook replay needs either delivery IDs or a subscription with a start time.', zh: 'Webhook 重放需指定投递 ID，或同时指定订阅与起始时间。' },
+  webhook_store_error: { en: 'Webhook store request failed.', zh: 'Webhook 存储请求失败。' },
+  webhook_store_missing: { en: 'Webhook store is missing.', zh: '请求失败（webhook store missing）。' },
+  webhook_subscription_not_found: { en: 'Webhook subscription not found.', zh: 'Webhook 订阅不存在。' },
   write_disabled: { en: 'Write disabled.', zh: '请求失败（write disabled）。' },
 }
 
@@ -242,6 +242,7 @@ const messages = {
     cubebox_settings_base_url: 'Base URL',
     cubebox_settings_provider_enabled: 'Provider Enabled',
     cubebox_settings_save_provider: 'Save Provider',
+    cubebox_settings_secret_value: 'Secret Value (stored encrypted)',
     cubebox_settings_secret_ref: 'Secret Ref',
     cubebox_settings_masked_secret: 'Masked Secret',
     cubebox_settings_rotate_credential: 'Rotate Credential',
@@ -710,6 +711,7 @@ const messages = {
     cubebox_settings_base_url: 'Base URL',
     cubebox_settings_provider_enabled: '启用 Provider',
     cubebox_settings_save_provider: '保存 Provider',
+    cubebox_settings_secret_value: '密钥值（加密保存）',
     cubebox_settings_secret_ref: '密钥引用',
     cubebox_settings_masked_secret: '掩码密钥',
     cubebox_settings_rotate_credential: '轮换密钥',
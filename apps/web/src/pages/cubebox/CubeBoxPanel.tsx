@@ -70,6 +70,7 @@ export function CubeBoxPanel() {
   const [providerEnabled, setProviderEnabled] = useState(true)
   const [credentialSecretRef, setCredentialSecretRef] = useState('')
   const [credentialMaskedSecret, setCredentialMaskedSecret] = useState('sk-****')
+  const [credentialSecretValue, setCredentialSecretValue] = useState('')
   const [modelSlug, setModelSlug] = useState('gpt-4.1')
   const [capabilitySummaryText, setCapabilitySummaryText] = useState('{"streaming":true,"tool_calls":false}')
   const localCanReadConversations =
@@ -163,8 +164,10 @@ export function CubeBoxPanel() {
       await rotateModelCredential({
         providerID,
         secretRef: credentialSecretRef,
-        maskedSecret: credentialMaskedSecret
+        maskedSecret: credentialMaskedSecret,
+        secretValue: credentialSecretValue
       })
+      setCredentialSecretValue('')
       await refreshSettings()
     } catch (error) {
       setSettingsError(error instanceof Error ? error.message : 'unknown error')
@@ -468,6 +471,7 @@ export function CubeBoxPanel() {
               {t('cubebox_settings_save_provider')}
             </Button>
             <Divider />
+            <TextField autoComplete='off' fullWidth disabled={!canRotateCredential || settingsSaving} label={t('cubebox_settings_secret_value')} onChange={(event) => setCredentialSecretValue(event.target.value)} type='password' value={credentialSecretValue} />
             <TextField fullWidth disabled={!canRotateCredential || settingsSaving} label={t('cubebox_settings_secret_ref')} onChange={(event) => setCredentialSecretRef(event.target.value)} value={credentialSecretRef} />
             <TextField fullWidth disabled={!canRotateCredential || settingsSaving} label={t('cubebox_settings_masked_secret')} onChange={(event) => setCredentialMaskedSecret(event.target.value)} value={credentialMaskedSecret} />
             <Button disabled={!canRotateCredential || settingsSaving} onClick={() => void handleCredentialRotate()} variant='outlined'>
@@ -207,18 +207,24 @@ export async function rotateModelCredential(input: {
   providerID: string
   secretRef: string
   maskedSecret: string
+  secretValue?: string
 }): Promise<CubeBoxModelCredential> {
+  const secretValue = input.secretValue?.trim() ?? ''
   const response = await fetch('/internal/cubebox/settings/credentials', {
     credentials: 'include',
     method: 'POST',
     headers: {
       'Content-Type': 'application/json'
     },
-    body: JSON.stringify({
-      provider_id: input.providerID,
-      secret_ref: input.secretRef,
-      masked_secret: input.maskedSecret
-    })
+    body: JSON.stringify(
+      secretValue
+        ? { provider_id: input.providerID, secret_value: secretValue }
+        : {
+            provider_id: input.providerID,
+            secret_ref: input.secretRef,
+            masked_secret: input.maskedSecret
+          }
+    )
   })
   if (!response.ok) {
     await readError(response, 'ai_model_secret_missing', `rotate credential failed: ${response.status}`)
@@ -15,6 +15,7 @@ export interface CubeBoxConversationSummary {
 
 export interface CubeBoxConversationListResponse {
   items: CubeBoxConversationSummary[]
+  next_cursor?: string
 }
 
 export interface CanonicalEvent {
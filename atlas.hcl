@@ -32,3 +32,19 @@ env "orgunit_ci" {
     format = "goose"
   }
 }
+
+env "person_dev" {
+  src = "file://modules/person/infrastructure/persistence/schema"
+  migration {
+    dir    = "file://migrations/person"
+    format = "goose"
+  }
+}
+
+env "person_ci" {
+  src = "file://modules/person/infrastructure/persistence/schema"
+  migration {
+    dir    = "file://migrations/person"
+    format = "goose"
+  }
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/server"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+)
+
+func main() {
+	root, err := findRepoRoot()
+	if err != nil {
+		fatal(err)
+	}
+	suitePath := flag.String("suite", filepath.Join(root, "config", "cubebox", "eval", "suite.json"), "scenario suite JSON")
+	baselinePath := flag.String("baseline", filepath.Join(root, "config", "cubebox", "eval", "baseline.json"), "baseline report JSON to compare against; skipped when the file is missing")
+	writeBaseline := flag.Bool("write-baseline", false, "overwrite the baseline with this run instead of comparing")
+	outPath := flag.String("out", "", "write the full report JSON to this path")
+	baseURL := flag.String("base-url", "", "local OpenAI-compatible endpoint; empty replays the recorded provider outputs")
+	model := flag.String("model", "", "model slug for -base-url")
+	flag.Parse()
+
+	suite, err := cubebox.LoadEvalSuite(*suitePath)
+	if err != nil {
+		fatal(err)
+	}
+	report, err := server.RunCubeBoxEvalSuite(context.Background(), suite, server.CubeBoxEvalOptions{
+		BaseURL: *baseURL,
+		Model:   *model,
+		APIKey:  os.Getenv("CUBEBOX_EVAL_API_KEY"),
+	})
+	if err != nil {
+		fatal(err)
+	}
+	printReport(report)
+	if *outPath != "" {
+		if err := writeJSON(*outPath, report); err != nil {
+			fatal(err)
+		}
+	}
+	if *writeBaseline {
+		if err := writeJSON(*baselinePath, report); err != nil {
+			fatal(err)
+		}
+		fmt.Printf("[cubebox-eval] baseline written: %s\n", *baselinePath)
+		return
+	}
+	baseline, err := cubebox.LoadEvalReport(*baselinePath)
+	if os.IsNotExist(err) {
+		fmt.Println("[cubebox-eval] no baseline, comparison skipped")
+		return
+	}
+	if err != nil {
+		fatal(err)
+	}
+	comparison := cubebox.CompareEvalReports(baseline, report)
+	printComparison(comparison)
+	if comparison.HasRegression() {
+		fmt.Fprintln(os.Stderr, "[cubebox-eval] regression against baseline")
+		os.Exit(1)
+	}
+	fmt.Println("[cubebox-eval] OK")
+}
+
+func printReport(report cubebox.EvalReport) {
+	for _, result := range report.Results {
+		status := "PASS"
+		if !result.Passed {
+			status = "FAIL"
+		}
+		fmt.Printf("[cubebox-eval] %s %s outcome=%s rounds=%d\n", status, result.ID, result.Observation.Outcome, result.Observation.PlanningRounds)
+		for _, failure := range result.Failures {
+			fmt.Printf("    - %s\n", failure)
+		}
+	}
+	m := report.Metrics
+	fmt.Printf("[cubebox-eval] suite=%s provider=%s passed=%d/%d\n", report.Suite, report.Provider, m.Passed, m.Scenarios)
+	fmt.Printf("[cubebox-eval] plan_accuracy=%.4f narration_pass_rate=%.4f clarification_rate=%.4f boundary_violation_rate=%.4f\n",
+		m.PlanAccuracy, m.NarrationPassRate, m.ClarificationRate, m.BoundaryViolationRate)
+	fmt.Printf("[cubebox-eval] planning_rounds mean=%.2f max=%d budget=%d exhausted=%d\n",
+		m.MeanPlanningRounds, m.MaxPlanningRounds, m.BudgetPlanningRounds, m.BudgetExhausted)
+}
+
+func printComparison(comparison cubebox.EvalComparison) {
+	keys := make([]string, 0, len(comparison.Deltas))
+	for key := range comparison.Deltas {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("[cubebox-eval] delta %s=%+.4f\n", key, comparison.Deltas[key])
+	}
+	for _, id := range comparison.Regressed {
+		fmt.Printf("[cubebox-eval] regressed %s\n", id)
+	}
+	for _, id := range comparison.Fixed {
+		fmt.Printf("[cubebox-eval] fixed %s\n", id)
+	}
+	for _, id := range comparison.Missing {
+		fmt.Printf("[cubebox-eval] missing %s\n", id)
+	}
+}
+
+func writeJSON(path string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func findRepoRoot() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	dir := wd
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("repo root not found from %s", wd)
+		}
+		dir = parent
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "[cubebox-eval] %v\n", err)
+	os.Exit(1)
+}
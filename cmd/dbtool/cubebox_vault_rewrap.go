@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+)
+
+// cubeboxVaultRewrap rewraps cubebox vault data keys under the newest master key in the key file. Run it
+// after adding the new key to the file every server loads: servers re-read the file on their next vault
+// access, so from then on they wrap new data keys under the new version too. Once a run reports
+// rewrapped=0, the old key line can be removed.
+func cubeboxVaultRewrap(args []string) {
+	fs := flag.NewFlagSet("cubebox-vault-rewrap", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	var url string
+	var keyFile string
+	fs.StringVar(&url, "url", "", "postgres connection string")
+	fs.StringVar(&keyFile, "key-file", os.Getenv(cubebox.SecretVaultMasterKeyFileEnv), "vault master key file")
+	if err := fs.Parse(args); err != nil {
+		fatal(err)
+	}
+	if url == "" {
+		fatalf("missing --url")
+	}
+	if strings.TrimSpace(keyFile) == "" {
+		fatalf("missing --key-file")
+	}
+
+	keys, err := cubebox.LoadSecretVaultKeyringFile(keyFile)
+	if err != nil {
+		fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, url)
+	if err != nil {
+		fatal(err)
+	}
+	defer conn.Close(context.Background())
+
+	rewrapped, err := cubebox.RewrapSecretVaultDataKeys(ctx, conn, keys)
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Printf("[cubebox-vault-rewrap] OK master_key_version=%d rewrapped=%d\n", keys.ActiveVersion(), rewrapped)
+}
@@ -21,7 +21,7 @@ import (
 
 func main() {
 	if len(os.Args) < 2 {
-		fatalf("usage: dbtool <rls-smoke|orgunit-smoke|orgunit-code-validate|orgunit-snapshot-export|orgunit-snapshot-check|orgunit-snapshot-bootstrap-target|orgunit-snapshot-import|orgunit-snapshot-verify> [args]")
+		fatalf("usage: dbtool <rls-smoke|orgunit-smoke|orgunit-code-validate|orgunit-snapshot-export|orgunit-snapshot-check|orgunit-snapshot-bootstrap-target|orgunit-snapshot-import|orgunit-snapshot-verify|orgunit-search-index-backfill|cubebox-vault-rewrap> [args]")
 	}
 
 	switch os.Args[1] {
@@ -41,6 +41,10 @@ func main() {
 		orgunitSnapshotImport(os.Args[2:])
 	case "orgunit-snapshot-verify":
 		orgunitSnapshotVerify(os.Args[2:])
+	case "orgunit-search-index-backfill":
+		orgunitSearchIndexBackfill(os.Args[2:])
+	case "cubebox-vault-rewrap":
+		cubeboxVaultRewrap(os.Args[2:])
 	default:
 		fatalf("unknown subcommand: %s", os.Args[1])
 	}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/orgunit"
+)
+
+// orgunitSearchIndexBackfill fills pinyin for org unit search index rows that the migration backfill or
+// an out-of-band write left without it. Each tenant is filled in its own transaction.
+func orgunitSearchIndexBackfill(args []string) {
+	fs := flag.NewFlagSet("orgunit-search-index-backfill", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	var url string
+	var tenantUUID string
+	fs.StringVar(&url, "url", "", "postgres connection string")
+	fs.StringVar(&tenantUUID, "tenant", "", "tenant uuid (default all tenants)")
+	if err := fs.Parse(args); err != nil {
+		fatal(err)
+	}
+	if url == "" {
+		fatalf("missing --url")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, url)
+	if err != nil {
+		fatal(err)
+	}
+	defer conn.Close(context.Background())
+
+	tenantIDs := []string{strings.TrimSpace(tenantUUID)}
+	if tenantIDs[0] == "" {
+		tenantIDs, err = listOrgunitSearchIndexTenants(ctx, conn)
+		if err != nil {
+			fatal(err)
+		}
+	}
+
+	total := 0
+	for _, tenantID := range tenantIDs {
+		filled, err := backfillOrgunitSearchIndexTenant(ctx, conn, tenantID)
+		if err != nil {
+			fatalf("tenant %s: %v", tenantID, err)
+		}
+		total += filled
+	}
+	fmt.Printf("[orgunit-search-index-backfill] OK tenants=%d filled=%d\n", len(tenantIDs), total)
+}
+
+func listOrgunitSearchIndexTenants(ctx context.Context, conn *pgx.Conn) ([]string, error) {
+	rows, err := conn.Query(ctx, `SELECT id::text FROM iam.tenants ORDER BY id ASC;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+func backfillOrgunitSearchIndexTenant(ctx context.Context, conn *pgx.Conn, tenantID string) (int, error) {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
+		return 0, err
+	}
+	filled, err := orgunit.FillSearchPinyin(ctx, tx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return filled, nil
+}
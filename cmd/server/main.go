@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/server"
 )
@@ -14,8 +19,24 @@ func main() {
 		addr = ":8080"
 	}
 
+	// Cancelled on SIGINT/SIGTERM: stops the handler's background workers and drains in-flight requests.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	h, err := server.NewHandlerWithOptions(server.HandlerOptions{Context: ctx})
+	if err != nil {
+		log.Fatal(errors.New("server: failed to build handler: " + err.Error()))
+	}
+	srv := &http.Server{Addr: addr, Handler: h}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
 	log.Printf("listening on %s", addr)
-	if err := http.ListenAndServe(addr, server.MustNewHandler()); err != nil {
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal(err)
 	}
 }
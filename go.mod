@@ -7,6 +7,7 @@ require (
 	github.com/google/cel-go v0.26.1
 	github.com/google/uuid v1.6.0
 	github.com/jackc/pgx/v5 v5.8.0
+	github.com/mozillazg/go-pinyin v0.21.0
 	github.com/open-policy-agent/opa v1.15.2
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -14,6 +14,7 @@ type ErrorEnvelope struct {
 	Message string            `json:"message"`
 	TraceID string            `json:"trace_id"`
 	Meta    ErrorEnvelopeMeta `json:"meta"`
+	Details any               `json:"details,omitempty"`
 }
 
 type ErrorEnvelopeMeta struct {
@@ -27,6 +28,12 @@ var (
 )
 
 func WriteError(w http.ResponseWriter, r *http.Request, rc RouteClass, status int, code string, message string) {
+	WriteErrorWithDetails(w, r, rc, status, code, message, nil)
+}
+
+// WriteErrorWithDetails is WriteError plus a machine-readable details payload for JSON clients, e.g. the
+// per-field errors of a rejected write. HTML responses ignore details.
+func WriteErrorWithDetails(w http.ResponseWriter, r *http.Request, rc RouteClass, status int, code string, message string, details any) {
 	message = normalizeErrorMessage(code, message)
 	if isJSONOnly(rc) || wantsJSON(r) {
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -39,6 +46,7 @@ func WriteError(w http.ResponseWriter, r *http.Request, rc RouteClass, status in
 				Path:   r.URL.Path,
 				Method: r.Method,
 			},
+			Details: details,
 		})
 		return
 	}
@@ -172,10 +180,20 @@ func knownErrorMessage(code string) string {
 		return "创建会话失败，请稍后重试。"
 	case "cubebox_conversation_list_failed":
 		return "加载会话列表失败，请稍后重试。"
+	case "cubebox_conversation_cursor_invalid":
+		return "会话列表翻页位置无效，请刷新列表。"
 	case "cubebox_conversation_read_failed":
 		return "读取会话失败，请稍后重试。"
 	case "cubebox_conversation_update_failed":
 		return "更新会话失败，请稍后重试。"
+	case "cubebox_conversation_turn_not_found":
+		return "当前会话中未找到该回合。"
+	case "cubebox_share_not_found":
+		return "分享不存在、已过期或已撤销。"
+	case "cubebox_share_failed":
+		return "分享会话失败，请稍后重试。"
+	case "cubebox_feedback_failed":
+		return "回答反馈处理失败，请稍后重试。"
 	case "stream_not_supported":
 		return "当前环境不支持流式响应，请稍后重试。"
 	case "ORG_ROOT_ALREADY_EXISTS":
@@ -194,6 +212,28 @@ func knownErrorMessage(code string) string {
 		return "字段值不在允许范围内，请重新选择。"
 	case "FIELD_REQUIRED_VALUE_MISSING":
 		return "必填字段缺少有效值，请补全后重试。"
+	case "MANAGER_PERNR_INVALID":
+		return "负责人工号格式无效（1-8 位数字）。"
+	case "MANAGER_PERNR_NOT_FOUND":
+		return "人员目录中不存在该负责人工号。"
+	case "MANAGER_PERNR_INACTIVE":
+		return "负责人在生效日不是在职状态。"
+	case "PERSON_PERNR_INVALID":
+		return "工号格式无效（1-8 位数字）。"
+	case "PERSON_NOT_FOUND":
+		return "人员不存在。"
+	case "PERSON_DISPLAY_NAME_REQUIRED":
+		return "人员姓名不能为空。"
+	case "PERSON_STATUS_INVALID":
+		return "人员状态无效。"
+	case "PERSON_EFFECTIVE_DATE_INVALID":
+		return "人员生效日期无效。"
+	case "PERSON_IMPORT_EMPTY":
+		return "人员导入内容为空。"
+	case "PERSON_IMPORT_TOO_LARGE":
+		return "人员导入行数超出上限。"
+	case "PERSON_IN_USE":
+		return "该人员仍是组织负责人，无法删除。"
 	case "policy_missing":
 		return "未找到匹配的字段策略，请刷新后重试。"
 	case "policy_conflict_ambiguous":
@@ -240,6 +280,10 @@ func knownErrorMessage(code string) string {
 		return "助手运行时模型配置缺失，请完成配置并重启服务。"
 	case "ai_model_secret_missing":
 		return "模型密钥缺失，请检查 key_ref 配置后重试。"
+	case "ai_model_secret_vault_unavailable":
+		return "密钥保险库未配置，请改用 env:// 密钥引用。"
+	case "ai_budget_exceeded":
+		return "AI 用量已达到预算上限，请联系管理员调整预算。"
 	case "cubebox_turn_stream_failed":
 		return "CubeBox 回复失败，请稍后重试。"
 	case "ai_reply_model_target_mismatch":
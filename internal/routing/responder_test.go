@@ -127,6 +127,25 @@ func TestWriteError_KeepExplicitMessage(t *testing.T) {
 	}
 }
 
+func TestWriteErrorWithDetails_EncodesDetails(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/org/api/org-units/write", nil)
+	rec := httptest.NewRecorder()
+	WriteErrorWithDetails(rec, req, RouteClassInternalAPI, http.StatusUnprocessableEntity, "FIELD_VALIDATION_FAILED", "field validation failed", map[string]any{
+		"field_errors": []map[string]string{{"field_key": "name"}},
+	})
+	if !strings.Contains(rec.Body.String(), `"details":{"field_errors":[{"field_key":"name"}]}`) {
+		t.Fatalf("body=%s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	WriteError(rec, req, RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "bad")
+	if strings.Contains(rec.Body.String(), `"details"`) {
+		t.Fatalf("body=%s", rec.Body.String())
+	}
+}
+
 func TestNormalizeErrorMessage_Branches(t *testing.T) {
 	t.Parallel()
 
@@ -221,8 +240,13 @@ func TestKnownErrorMessage_AllCases(t *testing.T) {
 		{code: "cubebox_conversation_not_found", want: "会话不存在，请重新选择或新建。"},
 		{code: "cubebox_conversation_create_failed", want: "创建会话失败，请稍后重试。"},
 		{code: "cubebox_conversation_list_failed", want: "加载会话列表失败，请稍后重试。"},
+		{code: "cubebox_conversation_cursor_invalid", want: "会话列表翻页位置无效，请刷新列表。"},
 		{code: "cubebox_conversation_read_failed", want: "读取会话失败，请稍后重试。"},
 		{code: "cubebox_conversation_update_failed", want: "更新会话失败，请稍后重试。"},
+		{code: "cubebox_conversation_turn_not_found", want: "当前会话中未找到该回合。"},
+		{code: "cubebox_share_not_found", want: "分享不存在、已过期或已撤销。"},
+		{code: "cubebox_share_failed", want: "分享会话失败，请稍后重试。"},
+		{code: "cubebox_feedback_failed", want: "回答反馈处理失败，请稍后重试。"},
 		{code: "stream_not_supported", want: "当前环境不支持流式响应，请稍后重试。"},
 		{code: "ORG_ROOT_ALREADY_EXISTS", want: "根组织已存在，请改为选择上级组织后新建。"},
 		{code: "ORG_TREE_NOT_INITIALIZED", want: "组织树尚未初始化，请先创建根组织。"},
@@ -253,6 +277,8 @@ func TestKnownErrorMessage_AllCases(t *testing.T) {
 		{code: "ai_runtime_config_invalid", want: "助手运行时模型配置不合法，请修正配置并重启服务。"},
 		{code: "ai_runtime_config_missing", want: "助手运行时模型配置缺失，请完成配置并重启服务。"},
 		{code: "ai_model_secret_missing", want: "模型密钥缺失，请检查 key_ref 配置后重试。"},
+		{code: "ai_model_secret_vault_unavailable", want: "密钥保险库未配置，请改用 env:// 密钥引用。"},
+		{code: "ai_budget_exceeded", want: "AI 用量已达到预算上限，请联系管理员调整预算。"},
 		{code: "unknown", want: ""},
 	}
 
@@ -3,6 +3,7 @@ package routing
 import (
 	"net/http"
 	"runtime/debug"
+	"sort"
 )
 
 type Router struct {
@@ -13,9 +14,20 @@ type Router struct {
 
 type routeEntry struct {
 	rc      RouteClass
+	doc     RouteDoc
 	handler http.Handler
 }
 
+// RouteDoc describes what a route's handler decodes and encodes; the OpenAPI document is generated from
+// the docs attached at registration so it cannot drift from the handlers.
+type RouteDoc struct {
+	Summary     string
+	Request     any
+	Response    any
+	Status      int
+	ContentType string
+}
+
 func NewRouter(classifier *Classifier) *Router {
 	return &Router{
 		classifier:    classifier,
@@ -25,8 +37,13 @@ func NewRouter(classifier *Classifier) *Router {
 }
 
 func (r *Router) Handle(rc RouteClass, method string, path string, h http.Handler) {
+	r.HandleDoc(rc, method, path, RouteDoc{}, h)
+}
+
+// HandleDoc registers h like Handle and records doc for Routes.
+func (r *Router) HandleDoc(rc RouteClass, method string, path string, doc RouteDoc, h http.Handler) {
 	if p, ok := parsePathPattern(path); ok {
-		r.addPatternRoute(p, rc, method, h)
+		r.addPatternRoute(p, rc, method, doc, h)
 		return
 	}
 	if r.routes[path] == nil {
@@ -34,7 +51,8 @@ func (r *Router) Handle(rc RouteClass, method string, path string, h http.Handle
 	}
 
 	r.routes[path][method] = routeEntry{
-		rc: rc,
+		rc:  rc,
+		doc: doc,
 		handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 			defer func() {
 				if rec := recover(); rec != nil {
@@ -47,6 +65,37 @@ func (r *Router) Handle(rc RouteClass, method string, path string, h http.Handle
 	}
 }
 
+// RegisteredRoute is one method/path pair registered on a Router, as reported by Routes.
+type RegisteredRoute struct {
+	Method     string
+	Path       string
+	RouteClass RouteClass
+	Doc        RouteDoc
+}
+
+// Routes lists every registered route sorted by path then method. Pattern routes keep their
+// "{param}" form so they can be matched against the allowlist.
+func (r *Router) Routes() []RegisteredRoute {
+	var out []RegisteredRoute
+	for path, methods := range r.routes {
+		for method, entry := range methods {
+			out = append(out, RegisteredRoute{Method: method, Path: path, RouteClass: entry.rc, Doc: entry.doc})
+		}
+	}
+	for _, pr := range r.patternRoutes {
+		for method, entry := range pr.methods {
+			out = append(out, RegisteredRoute{Method: method, Path: pr.pattern.raw, RouteClass: entry.rc, Doc: entry.doc})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Path == out[j].Path {
+			return out[i].Method < out[j].Method
+		}
+		return out[i].Path < out[j].Path
+	})
+	return out
+}
+
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	methods, ok := r.routes[req.URL.Path]
 	if !ok {
@@ -77,11 +126,12 @@ type patternRoute struct {
 	methods map[string]routeEntry
 }
 
-func (r *Router) addPatternRoute(p PathPattern, rc RouteClass, method string, h http.Handler) {
+func (r *Router) addPatternRoute(p PathPattern, rc RouteClass, method string, doc RouteDoc, h http.Handler) {
 	for _, existing := range r.patternRoutes {
 		if existing.pattern.raw == p.raw {
 			existing.methods[method] = routeEntry{
-				rc: rc,
+				rc:  rc,
+				doc: doc,
 				handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 					defer func() {
 						if rec := recover(); rec != nil {
@@ -101,7 +151,8 @@ func (r *Router) addPatternRoute(p PathPattern, rc RouteClass, method string, h
 		methods: make(map[string]routeEntry),
 	}
 	pr.methods[method] = routeEntry{
-		rc: rc,
+		rc:  rc,
+		doc: doc,
 		handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 			defer func() {
 				if rec := recover(); rec != nil {
@@ -159,3 +159,38 @@ func TestRouter_PathPattern_MergeMethodsAndRecover(t *testing.T) {
 		t.Fatalf("status=%d", okRec.Code)
 	}
 }
+
+func TestRouter_RoutesListsExactAndPatternRoutes(t *testing.T) {
+	t.Parallel()
+
+	a := Allowlist{
+		Version: 1,
+		Entrypoints: map[string]Entrypoint{
+			"server": {Routes: []Route{{Path: "/health", Methods: []string{"GET"}, RouteClass: "ops"}}},
+		},
+	}
+	c, err := NewClassifier(a, "server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRouter(c)
+	noop := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	r.Handle(RouteClassInternalAPI, http.MethodPut, "/iam/api/roles/{role_slug}", noop)
+	r.HandleDoc(RouteClassOps, http.MethodGet, "/health", RouteDoc{Summary: "Liveness probe."}, noop)
+	r.Handle(RouteClassInternalAPI, http.MethodGet, "/iam/api/roles/{role_slug}", noop)
+
+	got := r.Routes()
+	want := []RegisteredRoute{
+		{Method: http.MethodGet, Path: "/health", RouteClass: RouteClassOps, Doc: RouteDoc{Summary: "Liveness probe."}},
+		{Method: http.MethodGet, Path: "/iam/api/roles/{role_slug}", RouteClass: RouteClassInternalAPI},
+		{Method: http.MethodPut, Path: "/iam/api/roles/{role_slug}", RouteClass: RouteClassInternalAPI},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("routes=%+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("routes[%d]=%+v want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -139,7 +139,7 @@ func ownerModuleForAllowlistRoute(entrypoint string, route routing.Route) string
 		return "superadmin"
 	case path == "/health" || path == "/healthz" || strings.HasPrefix(path, "/assets/"):
 		return "platform"
-	case path == "/logout" || strings.HasPrefix(path, "/iam/"):
+	case path == "/logout" || path == dictCacheStatsPath || strings.HasPrefix(path, "/iam/"):
 		return "iam"
 	case strings.HasPrefix(path, "/org/"):
 		return "orgunit"
@@ -422,10 +422,11 @@ func authzAllowlistRouteRequiresRequirement(route AuthzAllowlistRoute) bool {
 		return false
 	}
 	if route.RouteClass != string(routing.RouteClassInternalAPI) && route.RouteClass != string(routing.RouteClassPublicAPI) {
-		return route.Method == http.MethodPost && route.Path == "/logout"
+		return (route.Method == http.MethodPost && route.Path == "/logout") || (route.Method == http.MethodGet && route.Path == dictCacheStatsPath)
 	}
 	switch route.Method + " " + route.Path {
 	case "GET /iam/api/me/capabilities",
+		"GET /openapi.json",
 		"GET /internal/cubebox/capabilities",
 		"POST /internal/cubebox/conversations/{conversation_id}:compact":
 		return false
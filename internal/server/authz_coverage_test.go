@@ -240,17 +240,28 @@ func testAuthzAllowlist() routing.Allowlist {
 					{Path: "/iam/api/dicts/values/audit", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/iam/api/dicts:release", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/iam/api/dicts:release:preview", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/ops/dict-cache", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassOps)},
+					{Path: "/iam/api/webhooks/subscriptions", Methods: []string{"GET", "POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/iam/api/webhooks/subscriptions/{subscription_id}", Methods: []string{"PUT"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/iam/api/webhooks/deliveries", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/iam/api/webhooks/deliveries:replay", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/logout", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassAuthn)},
+					{Path: "/iam/impersonation/accept", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassAuthn)},
+					{Path: "/iam/api/impersonation-sessions", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/org/api/org-units", Methods: []string{"GET", "POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/org/api/org-units/field-definitions", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/org/api/org-units/field-configs", Methods: []string{"GET", "POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/org/api/org-units/field-configs:enable-candidates", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/org/api/org-units/field-configs:disable", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/org/api/org-units/field-validation-rules", Methods: []string{"GET", "POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/org/api/org-units/field-validation-rules:disable", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/org/api/org-units/field-validation-rules:test", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/org/api/org-units/fields:options", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/org/api/org-units/details", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/org/api/org-units/versions", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/org/api/org-units/audit", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/org/api/org-units/search", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/org/api/org-units/managed-by", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/org/api/org-units/rename", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/org/api/org-units/move", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/org/api/org-units/disable", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
@@ -260,7 +271,13 @@ func testAuthzAllowlist() routing.Allowlist {
 					{Path: "/org/api/org-units/status-corrections", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/org/api/org-units/rescinds", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/org/api/org-units/rescinds/org", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/org/api/org-units/merges", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/org/api/org-units/splits", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/org/api/org-units/set-business-unit", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/person/api/persons", Methods: []string{"GET", "POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/person/api/persons:by-pernr", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/person/api/persons:import", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/person/api/persons:delete", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/internal/cubebox/conversations", Methods: []string{"GET", "POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/internal/cubebox/conversations/{conversation_id}", Methods: []string{"GET", "PATCH"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/internal/cubebox/conversations/{conversation_id}:compact", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
@@ -273,6 +290,18 @@ func testAuthzAllowlist() routing.Allowlist {
 					{Path: "/internal/cubebox/settings/credentials/{credential_id}:deactivate", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/internal/cubebox/settings/selection", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
 					{Path: "/internal/cubebox/settings/verify", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/internal/cubebox/settings/usage", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/internal/cubebox/settings/budgets", Methods: []string{"GET", "POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/internal/cubebox/settings/redaction", Methods: []string{"GET", "POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/internal/cubebox/settings/redaction:preview", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/internal/cubebox/settings/retention", Methods: []string{"GET", "POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/internal/cubebox/conversations/{conversation_id}:export", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/internal/cubebox/shares", Methods: []string{"GET", "POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/internal/cubebox/shares/{share_id}", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/internal/cubebox/shares/{share_id}:revoke", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/internal/cubebox/conversations/{conversation_id}:feedback", Methods: []string{"POST"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/internal/cubebox/settings/feedback", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassInternalAPI)},
+					{Path: "/internal/cubebox/settings/feedback:export", Methods: []string{"GET"}, RouteClass: string(routing.RouteClassInternalAPI)},
 				},
 			},
 			"superadmin": {
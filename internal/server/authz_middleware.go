@@ -83,6 +83,7 @@ var exactRouteRequirements = []routeRequirement{
 	{Method: http.MethodGet, Path: "/iam/api/authz/roles", Object: authz.ObjectIAMAuthz, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/iam/api/authz/roles", Object: authz.ObjectIAMAuthz, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodGet, Path: "/iam/api/authz/user-assignments", Object: authz.ObjectIAMAuthz, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodGet, Path: "/iam/api/impersonation-sessions", Object: authz.ObjectIAMAuthz, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodGet, Path: "/iam/api/dicts", Object: authz.ObjectIAMDicts, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/iam/api/dicts", Object: authz.ObjectIAMDicts, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/iam/api/dicts:disable", Object: authz.ObjectIAMDicts, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
@@ -93,6 +94,11 @@ var exactRouteRequirements = []routeRequirement{
 	{Method: http.MethodGet, Path: "/iam/api/dicts/values/audit", Object: authz.ObjectIAMDicts, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/iam/api/dicts:release", Object: authz.ObjectIAMDictRelease, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/iam/api/dicts:release:preview", Object: authz.ObjectIAMDictRelease, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodGet, Path: dictCacheStatsPath, Object: authz.ObjectIAMDicts, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodGet, Path: "/iam/api/webhooks/subscriptions", Object: authz.ObjectIAMWebhooks, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPost, Path: "/iam/api/webhooks/subscriptions", Object: authz.ObjectIAMWebhooks, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodGet, Path: "/iam/api/webhooks/deliveries", Object: authz.ObjectIAMWebhooks, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPost, Path: "/iam/api/webhooks/deliveries:replay", Object: authz.ObjectIAMWebhooks, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/logout", Object: authz.ObjectIAMSession, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodGet, Path: "/org/api/org-units", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/org/api/org-units", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
@@ -101,11 +107,16 @@ var exactRouteRequirements = []routeRequirement{
 	{Method: http.MethodPost, Path: "/org/api/org-units/field-configs", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodGet, Path: "/org/api/org-units/field-configs:enable-candidates", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/org/api/org-units/field-configs:disable", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodGet, Path: "/org/api/org-units/field-validation-rules", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPost, Path: "/org/api/org-units/field-validation-rules", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPost, Path: "/org/api/org-units/field-validation-rules:disable", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPost, Path: "/org/api/org-units/field-validation-rules:test", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodGet, Path: "/org/api/org-units/fields:options", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodGet, Path: "/org/api/org-units/details", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodGet, Path: "/org/api/org-units/versions", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodGet, Path: "/org/api/org-units/audit", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodGet, Path: "/org/api/org-units/search", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodGet, Path: "/org/api/org-units/managed-by", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/org/api/org-units/rename", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/org/api/org-units/move", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/org/api/org-units/disable", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
@@ -115,7 +126,14 @@ var exactRouteRequirements = []routeRequirement{
 	{Method: http.MethodPost, Path: "/org/api/org-units/status-corrections", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/org/api/org-units/rescinds", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/org/api/org-units/rescinds/org", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPost, Path: "/org/api/org-units/merges", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPost, Path: "/org/api/org-units/splits", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/org/api/org-units/set-business-unit", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodGet, Path: "/person/api/persons", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPost, Path: "/person/api/persons", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodGet, Path: "/person/api/persons:by-pernr", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPost, Path: "/person/api/persons:import", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPost, Path: "/person/api/persons:delete", Object: authz.ObjectOrgUnitOrgUnits, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodGet, Path: "/internal/cubebox/conversations", Object: authz.ObjectCubeBoxConversations, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/internal/cubebox/conversations", Object: authz.ObjectCubeBoxConversations, Action: authz.ActionUse, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/internal/cubebox/turns:stream", Object: authz.ObjectCubeBoxConversations, Action: authz.ActionUse, Surface: authz.CapabilitySurfaceTenantAPI},
@@ -124,14 +142,31 @@ var exactRouteRequirements = []routeRequirement{
 	{Method: http.MethodPost, Path: "/internal/cubebox/settings/credentials", Object: authz.ObjectCubeBoxModelCredential, Action: authz.ActionRotate, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/internal/cubebox/settings/selection", Object: authz.ObjectCubeBoxModelSelection, Action: authz.ActionSelect, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/internal/cubebox/settings/verify", Object: authz.ObjectCubeBoxModelSelection, Action: authz.ActionVerify, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodGet, Path: "/internal/cubebox/settings/usage", Object: authz.ObjectCubeBoxModelCredential, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodGet, Path: "/internal/cubebox/settings/budgets", Object: authz.ObjectCubeBoxModelCredential, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPost, Path: "/internal/cubebox/settings/budgets", Object: authz.ObjectCubeBoxModelProvider, Action: authz.ActionUpdate, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodGet, Path: "/internal/cubebox/settings/redaction", Object: authz.ObjectCubeBoxModelCredential, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPost, Path: "/internal/cubebox/settings/redaction", Object: authz.ObjectCubeBoxModelProvider, Action: authz.ActionUpdate, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPost, Path: "/internal/cubebox/settings/redaction:preview", Object: authz.ObjectCubeBoxModelCredential, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodGet, Path: "/internal/cubebox/settings/retention", Object: authz.ObjectCubeBoxModelCredential, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPost, Path: "/internal/cubebox/settings/retention", Object: authz.ObjectCubeBoxModelProvider, Action: authz.ActionUpdate, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodGet, Path: "/internal/cubebox/shares", Object: authz.ObjectCubeBoxConversations, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPost, Path: "/internal/cubebox/shares", Object: authz.ObjectCubeBoxConversations, Action: authz.ActionUse, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodGet, Path: "/internal/cubebox/settings/feedback", Object: authz.ObjectCubeBoxModelCredential, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodGet, Path: "/internal/cubebox/settings/feedback:export", Object: authz.ObjectCubeBoxModelCredential, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
 }
 
 var patternRouteRequirements = []routeRequirement{
 	{Method: http.MethodGet, Path: "/iam/api/authz/roles/{role_slug}", Object: authz.ObjectIAMAuthz, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPut, Path: "/iam/api/authz/roles/{role_slug}", Object: authz.ObjectIAMAuthz, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPut, Path: "/iam/api/authz/user-assignments/{principal_id}", Object: authz.ObjectIAMAuthz, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPut, Path: "/iam/api/webhooks/subscriptions/{subscription_id}", Object: authz.ObjectIAMWebhooks, Action: authz.ActionAdmin, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodGet, Path: "/internal/cubebox/conversations/{conversation_id}", Object: authz.ObjectCubeBoxConversations, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPatch, Path: "/internal/cubebox/conversations/{conversation_id}", Object: authz.ObjectCubeBoxConversations, Action: authz.ActionUse, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodGet, Path: "/internal/cubebox/conversations/{conversation_id}:export", Object: authz.ObjectCubeBoxConversations, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPost, Path: "/internal/cubebox/conversations/{conversation_id}:feedback", Object: authz.ObjectCubeBoxConversations, Action: authz.ActionUse, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodGet, Path: "/internal/cubebox/shares/{share_id}", Object: authz.ObjectCubeBoxConversations, Action: authz.ActionRead, Surface: authz.CapabilitySurfaceTenantAPI},
+	{Method: http.MethodPost, Path: "/internal/cubebox/shares/{share_id}:revoke", Object: authz.ObjectCubeBoxConversations, Action: authz.ActionUse, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/internal/cubebox/turns/{turn_id}:interrupt", Object: authz.ObjectCubeBoxConversations, Action: authz.ActionUse, Surface: authz.CapabilitySurfaceTenantAPI},
 	{Method: http.MethodPost, Path: "/internal/cubebox/settings/credentials/{credential_id}:deactivate", Object: authz.ObjectCubeBoxModelCredential, Action: authz.ActionDeactivate, Surface: authz.CapabilitySurfaceTenantAPI},
 }
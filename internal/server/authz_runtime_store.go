@@ -166,6 +166,7 @@ func builtinTenantAdminCapabilityKeys() []string {
 		"iam.dicts:read",
 		"iam.dicts:admin",
 		"iam.dict_release:admin",
+		"iam.webhooks:admin",
 		"cubebox.conversations:read",
 		"cubebox.conversations:use",
 		"cubebox.model_provider:update",
@@ -8,10 +8,12 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
 	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
 	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/authz"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/pagecursor"
 )
 
 type cubeboxCreateConversationRequest struct{}
@@ -39,7 +41,7 @@ type cubeboxInterruptResponse struct {
 type cubeboxConversationStore interface {
 	CreateConversation(ctx context.Context, tenantID string, principalID string) (cubebox.ConversationReplayResponse, error)
 	GetConversation(ctx context.Context, tenantID string, principalID string, conversationID string) (cubebox.ConversationReplayResponse, error)
-	ListConversations(ctx context.Context, tenantID string, principalID string, limit int32) (cubebox.ConversationListResponse, error)
+	ListConversations(ctx context.Context, tenantID string, principalID string, query cubebox.ConversationListQuery) (cubebox.ConversationListResponse, error)
 	RenameConversation(ctx context.Context, tenantID string, principalID string, conversationID string, title string) (cubebox.ConversationReplayResponse, error)
 	ArchiveConversation(ctx context.Context, tenantID string, principalID string, conversationID string, archived bool) (cubebox.ConversationReplayResponse, error)
 	PrepareConversationPromptView(ctx context.Context, tenantID string, principalID string, conversationID string, canonicalContext cubebox.CanonicalContext, reason string) (cubebox.PromptViewPreparationResponse, error)
@@ -69,8 +71,9 @@ type cubeboxProviderUpsertRequest struct {
 
 type cubeboxCredentialRotateRequest struct {
 	ProviderID   string `json:"provider_id"`
-	SecretRef    string `json:"secret_ref"`
-	MaskedSecret string `json:"masked_secret"`
+	SecretRef    string `json:"secret_ref,omitempty"`
+	MaskedSecret string `json:"masked_secret,omitempty"`
+	SecretValue  string `json:"secret_value,omitempty"`
 }
 
 type cubeboxSelectionRequest struct {
@@ -171,25 +174,96 @@ func handleCubeBoxCreateConversationAPI(w http.ResponseWriter, r *http.Request,
 	writeJSON(w, http.StatusCreated, payload)
 }
 
+// handleCubeBoxListConversationsAPI pages with cursor (the previous next_cursor) and filters by q (title and
+// message text), archived (true/false) and updated_from/updated_to (inclusive UTC dates).
 func handleCubeBoxListConversationsAPI(w http.ResponseWriter, r *http.Request, store cubeboxConversationStore) {
 	tenant, principal, ok := cubeboxRequestActor(w, r)
 	if !ok {
 		return
 	}
-	limit := int32(20)
-	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+	values := r.URL.Query()
+	cursorScope := pagecursor.Scope(tenant.ID+"\x00"+principal.ID, cubeboxConversationCursorKind, values)
+	position, err := cubeboxConversationCursorPosition(values.Get("cursor"), cursorScope)
+	if err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "cubebox_conversation_cursor_invalid", "cursor invalid")
+		return
+	}
+	query := cubebox.ConversationListQuery{
+		Limit:  20,
+		Cursor: position,
+		Query:  values.Get("q"),
+	}
+	if raw := strings.TrimSpace(values.Get("limit")); raw != "" {
 		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 && parsed <= 100 {
-			limit = int32(parsed)
+			query.Limit = int32(parsed)
+		}
+	}
+	if raw := strings.TrimSpace(values.Get("archived")); raw != "" {
+		archived, err := strconv.ParseBool(raw)
+		if err != nil {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "archived invalid")
+			return
 		}
+		query.Archived = &archived
+	}
+	for _, bound := range []struct {
+		key    string
+		target *time.Time
+		days   int
+	}{
+		{key: "updated_from", target: &query.UpdatedFrom},
+		{key: "updated_to", target: &query.UpdatedTo, days: 1},
+	} {
+		raw := strings.TrimSpace(values.Get(bound.key))
+		if raw == "" {
+			continue
+		}
+		day, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", bound.key+" must be YYYY-MM-DD")
+			return
+		}
+		*bound.target = day.AddDate(0, 0, bound.days)
 	}
-	payload, err := store.ListConversations(r.Context(), tenant.ID, principal.ID, limit)
+	payload, err := store.ListConversations(r.Context(), tenant.ID, principal.ID, query)
 	if err != nil {
-		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "cubebox_conversation_list_failed", "list conversations failed")
+		switch {
+		case errors.Is(err, cubebox.ErrConversationCursorInvalid):
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "cubebox_conversation_cursor_invalid", "cursor invalid")
+		case errors.Is(err, cubebox.ErrConversationListQueryInvalid):
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "conversation list query invalid")
+		default:
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "cubebox_conversation_list_failed", "list conversations failed")
+		}
 		return
 	}
+	if payload.NextCursor != "" {
+		payload.NextCursor = cubeboxConversationNextCursor(cursorScope, payload.NextCursor)
+	}
 	writeJSON(w, http.StatusOK, payload)
 }
 
+const cubeboxConversationCursorKind = "cubebox.conversations"
+
+// cubeboxConversationCursorPosition returns the store position of a cursor issued for scope; an empty
+// token starts at the first page. The store's keyset position travels inside a signed page cursor whose
+// scope binds it to the principal and to q, archived and the date bounds, so a next_cursor only continues
+// the listing it was issued for.
+func cubeboxConversationCursorPosition(token string, scope string) (string, error) {
+	if strings.TrimSpace(token) == "" {
+		return "", nil
+	}
+	var position string
+	if err := pagecursor.Decode(token, cubeboxConversationCursorKind, scope, &position); err != nil || strings.TrimSpace(position) == "" {
+		return "", cubebox.ErrConversationCursorInvalid
+	}
+	return position, nil
+}
+
+func cubeboxConversationNextCursor(scope string, position string) string {
+	return pagecursor.Encode(cubeboxConversationCursorKind, scope, position)
+}
+
 func handleCubeBoxConversationAPI(w http.ResponseWriter, r *http.Request, store cubeboxConversationStore) {
 	switch r.Method {
 	case http.MethodGet:
@@ -315,10 +389,15 @@ func handleCubeBoxStreamTurnAPI(w http.ResponseWriter, r *http.Request, runtime
 		w:       w,
 		flusher: flusher,
 	}
-	if queryFlow != nil && queryFlow.TryHandle(r.Context(), streamRequest, sink) {
+	ctx, admitted := gateway.AdmitTurn(r.Context(), streamRequest, store, sink)
+	if !admitted {
+		return
+	}
+	defer func() { _ = gateway.RecordTurnUsage(ctx, streamRequest) }()
+	if queryFlow != nil && queryFlow.TryHandle(ctx, streamRequest, sink) {
 		return
 	}
-	gateway.StreamTurn(r.Context(), streamRequest, store, sink)
+	gateway.StreamTurn(ctx, streamRequest, store, sink)
 }
 
 type cubeboxSSEEventSink struct {
@@ -452,20 +531,35 @@ func handleCubeBoxSettingsCredentialsAPI(w http.ResponseWriter, r *http.Request,
 		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
 		return
 	}
-	if strings.TrimSpace(req.ProviderID) == "" || strings.TrimSpace(req.SecretRef) == "" || strings.TrimSpace(req.MaskedSecret) == "" {
+	// A submitted secret_value is sealed into the vault and only its mask is ever returned; a secret_ref keeps
+	// pointing at an env:// variable. vault:// refs are only minted by the server.
+	hasSecretValue := strings.TrimSpace(req.SecretValue) != ""
+	if strings.TrimSpace(req.ProviderID) == "" || (!hasSecretValue && (strings.TrimSpace(req.SecretRef) == "" || strings.TrimSpace(req.MaskedSecret) == "")) {
 		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "ai_model_secret_missing", "credential missing")
 		return
 	}
-	payload, err := store.RotateModelCredential(r.Context(), tenant.ID, principal.ID, cubebox.RotateModelCredentialInput{
+	if !hasSecretValue && strings.HasPrefix(strings.TrimSpace(req.SecretRef), "vault://") {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "ai_model_config_invalid", "vault secret refs are issued by the server")
+		return
+	}
+	input := cubebox.RotateModelCredentialInput{
 		ProviderID:   req.ProviderID,
 		SecretRef:    req.SecretRef,
 		MaskedSecret: req.MaskedSecret,
-	})
+	}
+	if hasSecretValue {
+		input = cubebox.RotateModelCredentialInput{ProviderID: req.ProviderID, SecretValue: req.SecretValue}
+	}
+	payload, err := store.RotateModelCredential(r.Context(), tenant.ID, principal.ID, input)
 	if err != nil {
 		if errors.Is(err, cubebox.ErrModelProviderNotFound) {
 			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusNotFound, "ai_model_provider_unavailable", "provider unavailable")
 			return
 		}
+		if errors.Is(err, cubebox.ErrSecretVaultUnavailable) {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusServiceUnavailable, "ai_model_secret_vault_unavailable", "secret vault unavailable")
+			return
+		}
 		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "ai_model_config_invalid", "credential save failed")
 		return
 	}
@@ -2,14 +2,18 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/pagecursor"
 )
 
 func newTestGateway(runtime *cubebox.Runtime) *cubebox.GatewayService {
@@ -122,7 +126,7 @@ func (s cubeboxAuthorizerStub) ReplacePrincipalAssignment(context.Context, strin
 type cubeboxStoreStub struct {
 	createFn               func(context.Context, string, string) (cubebox.ConversationReplayResponse, error)
 	getFn                  func(context.Context, string, string, string) (cubebox.ConversationReplayResponse, error)
-	listFn                 func(context.Context, string, string, int32) (cubebox.ConversationListResponse, error)
+	listFn                 func(context.Context, string, string, cubebox.ConversationListQuery) (cubebox.ConversationListResponse, error)
 	renameFn               func(context.Context, string, string, string, string) (cubebox.ConversationReplayResponse, error)
 	archiveFn              func(context.Context, string, string, string, bool) (cubebox.ConversationReplayResponse, error)
 	preparePromptViewFn    func(context.Context, string, string, string, cubebox.CanonicalContext, string) (cubebox.PromptViewPreparationResponse, error)
@@ -149,8 +153,8 @@ func (s cubeboxStoreStub) GetConversation(ctx context.Context, tenantID string,
 	return s.getFn(ctx, tenantID, principalID, conversationID)
 }
 
-func (s cubeboxStoreStub) ListConversations(ctx context.Context, tenantID string, principalID string, limit int32) (cubebox.ConversationListResponse, error) {
-	return s.listFn(ctx, tenantID, principalID, limit)
+func (s cubeboxStoreStub) ListConversations(ctx context.Context, tenantID string, principalID string, query cubebox.ConversationListQuery) (cubebox.ConversationListResponse, error) {
+	return s.listFn(ctx, tenantID, principalID, query)
 }
 
 func (s cubeboxStoreStub) RenameConversation(ctx context.Context, tenantID string, principalID string, conversationID string, title string) (cubebox.ConversationReplayResponse, error) {
@@ -260,7 +264,7 @@ func TestCubeBoxCreateConversationAPI(t *testing.T) {
 		getFn: func(context.Context, string, string, string) (cubebox.ConversationReplayResponse, error) {
 			return cubebox.ConversationReplayResponse{}, errors.New("unexpected")
 		},
-		listFn: func(context.Context, string, string, int32) (cubebox.ConversationListResponse, error) {
+		listFn: func(context.Context, string, string, cubebox.ConversationListQuery) (cubebox.ConversationListResponse, error) {
 			return cubebox.ConversationListResponse{}, errors.New("unexpected")
 		},
 		renameFn: func(context.Context, string, string, string, string) (cubebox.ConversationReplayResponse, error) {
@@ -309,8 +313,11 @@ func TestCubeBoxCapabilitiesAPI(t *testing.T) {
 }
 
 func TestCubeBoxListConversationsAPI(t *testing.T) {
+	filters := url.Values{"q": {"财务"}, "archived": {"true"}, "updated_from": {"2026-10-01"}, "updated_to": {"2026-10-19"}}
+	scope := pagecursor.Scope("t1\x00p1", cubeboxConversationCursorKind, filters)
+	target := "/internal/cubebox/conversations?limit=5&cursor=" + cubeboxConversationNextCursor(scope, "c1") + "&" + filters.Encode()
 	rec := httptest.NewRecorder()
-	req := httptest.NewRequest(http.MethodGet, "/internal/cubebox/conversations?limit=5", nil)
+	req := httptest.NewRequest(http.MethodGet, target, nil)
 	req = req.WithContext(withTenant(req.Context(), Tenant{ID: "t1"}))
 	req = req.WithContext(withPrincipal(req.Context(), Principal{ID: "p1"}))
 
@@ -321,12 +328,15 @@ func TestCubeBoxListConversationsAPI(t *testing.T) {
 		getFn: func(context.Context, string, string, string) (cubebox.ConversationReplayResponse, error) {
 			return cubebox.ConversationReplayResponse{}, errors.New("unexpected")
 		},
-		listFn: func(_ context.Context, tenantID string, principalID string, limit int32) (cubebox.ConversationListResponse, error) {
-			if tenantID != "t1" || principalID != "p1" || limit != 5 {
-				t.Fatalf("tenant=%q principal=%q limit=%d", tenantID, principalID, limit)
+		listFn: func(_ context.Context, tenantID string, principalID string, query cubebox.ConversationListQuery) (cubebox.ConversationListResponse, error) {
+			if tenantID != "t1" || principalID != "p1" || query.Limit != 5 || query.Cursor != "c1" || query.Query != "财务" ||
+				query.Archived == nil || !*query.Archived ||
+				!query.UpdatedFrom.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) || !query.UpdatedTo.Equal(time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)) {
+				t.Fatalf("tenant=%q principal=%q query=%+v", tenantID, principalID, query)
 			}
 			return cubebox.ConversationListResponse{
-				Items: []cubebox.ConversationListItem{{ID: "conv_1", Title: "a", Status: "active"}},
+				Items:      []cubebox.ConversationListItem{{ID: "conv_1", Title: "a", Status: "active"}},
+				NextCursor: "c2",
 			}, nil
 		},
 		renameFn: func(context.Context, string, string, string, string) (cubebox.ConversationReplayResponse, error) {
@@ -337,9 +347,41 @@ func TestCubeBoxListConversationsAPI(t *testing.T) {
 		},
 	})
 
-	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"items"`) {
+	var page cubebox.ConversationListResponse
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &page) != nil || len(page.Items) != 1 {
 		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
 	}
+	if position, err := cubeboxConversationCursorPosition(page.NextCursor, scope); err != nil || position != "c2" {
+		t.Fatalf("next_cursor=%q position=%q err=%v", page.NextCursor, position, err)
+	}
+
+	// The same token is rejected once any filter differs or another principal presents it.
+	otherQuery := url.Values{"q": {"人事"}, "archived": {"true"}, "updated_from": {"2026-10-01"}, "updated_to": {"2026-10-19"}}
+	if _, err := cubeboxConversationCursorPosition(page.NextCursor, pagecursor.Scope("t1\x00p1", cubeboxConversationCursorKind, otherQuery)); !errors.Is(err, cubebox.ErrConversationCursorInvalid) {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := cubeboxConversationCursorPosition(page.NextCursor, pagecursor.Scope("t1\x00p2", cubeboxConversationCursorKind, filters)); !errors.Is(err, cubebox.ErrConversationCursorInvalid) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestCubeBoxListConversationsAPIRejectsBadFilters(t *testing.T) {
+	store := cubeboxStoreStub{
+		listFn: func(context.Context, string, string, cubebox.ConversationListQuery) (cubebox.ConversationListResponse, error) {
+			return cubebox.ConversationListResponse{}, cubebox.ErrConversationCursorInvalid
+		},
+	}
+	for target, code := range map[string]string{
+		"/internal/cubebox/conversations?archived=maybe":         "invalid_request",
+		"/internal/cubebox/conversations?updated_from=yesterday": "invalid_request",
+		"/internal/cubebox/conversations?cursor=forged":          "cubebox_conversation_cursor_invalid",
+	} {
+		rec := httptest.NewRecorder()
+		handleCubeBoxListConversationsAPI(rec, newCubeBoxUsageRequest(http.MethodGet, target, ""), store)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), code) {
+			t.Fatalf("%s: status=%d body=%s", target, rec.Code, rec.Body.String())
+		}
+	}
 }
 
 func TestCubeBoxLoadConversationAPI(t *testing.T) {
@@ -362,7 +404,7 @@ func TestCubeBoxLoadConversationAPI(t *testing.T) {
 				NextSequence: 1,
 			}, nil
 		},
-		listFn: func(context.Context, string, string, int32) (cubebox.ConversationListResponse, error) {
+		listFn: func(context.Context, string, string, cubebox.ConversationListQuery) (cubebox.ConversationListResponse, error) {
 			return cubebox.ConversationListResponse{}, errors.New("unexpected")
 		},
 		renameFn: func(context.Context, string, string, string, string) (cubebox.ConversationReplayResponse, error) {
@@ -392,7 +434,7 @@ func TestCubeBoxStreamTurnAPI(t *testing.T) {
 		getFn: func(context.Context, string, string, string) (cubebox.ConversationReplayResponse, error) {
 			return cubebox.ConversationReplayResponse{}, errors.New("unexpected")
 		},
-		listFn: func(context.Context, string, string, int32) (cubebox.ConversationListResponse, error) {
+		listFn: func(context.Context, string, string, cubebox.ConversationListQuery) (cubebox.ConversationListResponse, error) {
 			return cubebox.ConversationListResponse{}, errors.New("unexpected")
 		},
 		renameFn: func(context.Context, string, string, string, string) (cubebox.ConversationReplayResponse, error) {
@@ -590,7 +632,7 @@ func TestCubeBoxStreamTurnAPIWritesFallbackErrorWhenAppendFails(t *testing.T) {
 		getFn: func(context.Context, string, string, string) (cubebox.ConversationReplayResponse, error) {
 			return cubebox.ConversationReplayResponse{}, errors.New("unexpected")
 		},
-		listFn: func(context.Context, string, string, int32) (cubebox.ConversationListResponse, error) {
+		listFn: func(context.Context, string, string, cubebox.ConversationListQuery) (cubebox.ConversationListResponse, error) {
 			return cubebox.ConversationListResponse{}, errors.New("unexpected")
 		},
 		renameFn: func(context.Context, string, string, string, string) (cubebox.ConversationReplayResponse, error) {
@@ -671,6 +713,57 @@ func TestCubeBoxSettingsProvidersAPI(t *testing.T) {
 	}
 }
 
+func TestCubeBoxSettingsCredentialsAPI(t *testing.T) {
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/internal/cubebox/settings/credentials", strings.NewReader(body))
+		req = req.WithContext(withTenant(req.Context(), Tenant{ID: "t1"}))
+		return req.WithContext(withPrincipal(req.Context(), Principal{ID: "p1"}))
+	}
+
+	t.Run("secret value is sealed and never echoed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleCubeBoxSettingsCredentialsAPI(rec, newRequest(`{"provider_id":"openai-compatible","secret_ref":"env://IGNORED","secret_value":"sk-live-0123456789abcd"}`), cubeboxStoreStub{
+			credentialFn: func(_ context.Context, _ string, _ string, input cubebox.RotateModelCredentialInput) (cubebox.ModelCredential, error) {
+				if input.SecretValue != "sk-live-0123456789abcd" || input.SecretRef != "" || input.MaskedSecret != "" {
+					t.Fatalf("input=%+v", input)
+				}
+				return cubebox.ModelCredential{ID: "cred_1", ProviderID: input.ProviderID, SecretRef: "vault://sec_1", MaskedSecret: cubebox.MaskSecretValue(input.SecretValue), Version: 1, Active: true}, nil
+			},
+		})
+		if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "0123456789abcd") || !strings.Contains(rec.Body.String(), `"sk-****abcd"`) {
+			t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("vault refs cannot be submitted", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleCubeBoxSettingsCredentialsAPI(rec, newRequest(`{"provider_id":"openai-compatible","secret_ref":"vault://sec_other","masked_secret":"sk-****"}`), cubeboxStoreStub{})
+		if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "ai_model_config_invalid") {
+			t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("vault unavailable", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleCubeBoxSettingsCredentialsAPI(rec, newRequest(`{"provider_id":"openai-compatible","secret_value":"sk-live-0123456789abcd"}`), cubeboxStoreStub{
+			credentialFn: func(context.Context, string, string, cubebox.RotateModelCredentialInput) (cubebox.ModelCredential, error) {
+				return cubebox.ModelCredential{}, cubebox.ErrSecretVaultUnavailable
+			},
+		})
+		if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "ai_model_secret_vault_unavailable") {
+			t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("env ref still requires mask", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleCubeBoxSettingsCredentialsAPI(rec, newRequest(`{"provider_id":"openai-compatible","secret_ref":"env://OPENAI_API_KEY"}`), cubeboxStoreStub{})
+		if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "ai_model_secret_missing") {
+			t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+		}
+	})
+}
+
 func TestCubeBoxSettingsVerifyAPI(t *testing.T) {
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/internal/cubebox/settings/verify", strings.NewReader(`{}`))
@@ -707,7 +800,7 @@ func TestCubeBoxStreamTurnAPIUsesUUIDEventIDs(t *testing.T) {
 		getFn: func(context.Context, string, string, string) (cubebox.ConversationReplayResponse, error) {
 			return cubebox.ConversationReplayResponse{}, errors.New("unexpected")
 		},
-		listFn: func(context.Context, string, string, int32) (cubebox.ConversationListResponse, error) {
+		listFn: func(context.Context, string, string, cubebox.ConversationListQuery) (cubebox.ConversationListResponse, error) {
 			return cubebox.ConversationListResponse{}, errors.New("unexpected")
 		},
 		renameFn: func(context.Context, string, string, string, string) (cubebox.ConversationReplayResponse, error) {
@@ -760,7 +853,7 @@ func TestCubeBoxPatchConversationAPIRenamesConversation(t *testing.T) {
 		getFn: func(context.Context, string, string, string) (cubebox.ConversationReplayResponse, error) {
 			return cubebox.ConversationReplayResponse{}, errors.New("unexpected")
 		},
-		listFn: func(context.Context, string, string, int32) (cubebox.ConversationListResponse, error) {
+		listFn: func(context.Context, string, string, cubebox.ConversationListQuery) (cubebox.ConversationListResponse, error) {
 			return cubebox.ConversationListResponse{}, errors.New("unexpected")
 		},
 		renameFn: func(_ context.Context, tenantID string, principalID string, conversationID string, title string) (cubebox.ConversationReplayResponse, error) {
@@ -795,7 +888,7 @@ func TestCubeBoxPatchConversationAPIArchivesConversation(t *testing.T) {
 		getFn: func(context.Context, string, string, string) (cubebox.ConversationReplayResponse, error) {
 			return cubebox.ConversationReplayResponse{}, errors.New("unexpected")
 		},
-		listFn: func(context.Context, string, string, int32) (cubebox.ConversationListResponse, error) {
+		listFn: func(context.Context, string, string, cubebox.ConversationListQuery) (cubebox.ConversationListResponse, error) {
 			return cubebox.ConversationListResponse{}, errors.New("unexpected")
 		},
 		renameFn: func(context.Context, string, string, string, string) (cubebox.ConversationReplayResponse, error) {
@@ -830,7 +923,7 @@ func TestCubeBoxPatchConversationAPIUnarchivesConversation(t *testing.T) {
 		getFn: func(context.Context, string, string, string) (cubebox.ConversationReplayResponse, error) {
 			return cubebox.ConversationReplayResponse{}, errors.New("unexpected")
 		},
-		listFn: func(context.Context, string, string, int32) (cubebox.ConversationListResponse, error) {
+		listFn: func(context.Context, string, string, cubebox.ConversationListQuery) (cubebox.ConversationListResponse, error) {
 			return cubebox.ConversationListResponse{}, errors.New("unexpected")
 		},
 		renameFn: func(context.Context, string, string, string, string) (cubebox.ConversationReplayResponse, error) {
@@ -880,7 +973,7 @@ func TestCubeBoxLoadConversationAPIReturnsPhaseCLifecycleRoundtripGolden(t *test
 				NextSequence: 8,
 			}, nil
 		},
-		listFn: func(context.Context, string, string, int32) (cubebox.ConversationListResponse, error) {
+		listFn: func(context.Context, string, string, cubebox.ConversationListQuery) (cubebox.ConversationListResponse, error) {
 			return cubebox.ConversationListResponse{}, errors.New("unexpected")
 		},
 		renameFn: func(context.Context, string, string, string, string) (cubebox.ConversationReplayResponse, error) {
@@ -925,7 +1018,7 @@ func TestCubeBoxStreamTurnAPIPreTurnAutoCompactUsesActorAndUpdatedSequence(t *te
 		getFn: func(context.Context, string, string, string) (cubebox.ConversationReplayResponse, error) {
 			return cubebox.ConversationReplayResponse{}, errors.New("unexpected")
 		},
-		listFn: func(context.Context, string, string, int32) (cubebox.ConversationListResponse, error) {
+		listFn: func(context.Context, string, string, cubebox.ConversationListQuery) (cubebox.ConversationListResponse, error) {
 			return cubebox.ConversationListResponse{}, errors.New("unexpected")
 		},
 		renameFn: func(context.Context, string, string, string, string) (cubebox.ConversationReplayResponse, error) {
@@ -1067,15 +1160,21 @@ func TestCubeBoxStreamTurnAPIUsesAPIQueryFlow(t *testing.T) {
 		t.Fatal("expected query flow events appended")
 	}
 	foundCandidates := false
+	var evidence []cubebox.QueryEvidenceCall
 	for _, event := range appended {
 		if event.Type == cubebox.QueryCandidatesPresentedEventType {
 			foundCandidates = true
-			break
+		}
+		if event.Type == cubebox.QueryEvidenceRecordedEventType {
+			evidence, _ = event.Payload["calls"].([]cubebox.QueryEvidenceCall)
 		}
 	}
 	if !foundCandidates {
 		t.Fatalf("expected presented candidates metadata, got %#v", appended)
 	}
+	if len(evidence) != 1 || evidence[0].OperationID != "orgunit.list" || evidence[0].ResultSummary["org_units_count"] != 1 || len(evidence[0].Entities) != 1 {
+		t.Fatalf("expected recorded query evidence, got %#v", evidence)
+	}
 }
 
 func TestCubeBoxStreamTurnAPIQueryFlowExecutionErrorDoesNotFallback(t *testing.T) {
@@ -1112,6 +1211,41 @@ func TestCubeBoxStreamTurnAPIQueryFlowExecutionErrorDoesNotFallback(t *testing.T
 	}
 }
 
+func TestCubeBoxStreamTurnAPIQueryFlowInterruptCancelsExecution(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/internal/cubebox/turns:stream", strings.NewReader(`{"conversation_id":"conv_1","prompt":"列出今天全部组织","next_sequence":4}`))
+	req = req.WithContext(withTenant(req.Context(), Tenant{ID: "tenant-a"}))
+	req = req.WithContext(withPrincipal(req.Context(), Principal{ID: "principal-a"}))
+
+	runtime := cubebox.NewRuntime()
+	store := cubeboxStoreStub{
+		preparePromptViewFn: func(context.Context, string, string, string, cubebox.CanonicalContext, string) (cubebox.PromptViewPreparationResponse, error) {
+			return cubebox.PromptViewPreparationResponse{
+				Conversation: cubebox.Conversation{ID: "conv_1", Title: "新对话", Status: "active"},
+				NextSequence: 4,
+			}, nil
+		},
+		appendFn: func(context.Context, string, string, string, cubebox.CanonicalEvent) error { return nil },
+	}
+	runner := cubeboxAPIToolRunnerStub{fn: func(ctx context.Context, _ cubebox.ExecuteRequest, _ cubebox.APICallPlan) ([]cubebox.ExecuteResult, error) {
+		if !runtime.InterruptTurn("turn_seq_4") {
+			t.Fatal("expected running query turn")
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+	flow := queryLoopTestFlow(runner, cubeboxAPIPlanProducerStub{result: queryPlannerAPICallsResult(queryAPIPlanForOrgUnitList("2026-04-25", ""))}, cubeboxQueryNarratorStub{text: "should not narrate"})
+	flow.runtime = runtime
+	flow.store = store
+
+	handleCubeBoxStreamTurnAPI(rec, req, runtime, store, newTestGateway(runtime), flow)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, `"type":"turn.interrupted"`) || !strings.Contains(body, `"status":"interrupted"`) || strings.Contains(body, `"type":"turn.error"`) {
+		t.Fatalf("expected interrupted query turn, got %s", body)
+	}
+}
+
 func TestCubeBoxStreamTurnAPIRejectsLegacyPageContextField(t *testing.T) {
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/internal/cubebox/turns:stream", strings.NewReader(`{"conversation_id":"conv_1","prompt":"hello","next_sequence":8,"page_context":{"page":"/org/units/100000"}}`))
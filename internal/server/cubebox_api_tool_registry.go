@@ -0,0 +1,205 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/authz"
+)
+
+// cubeboxAPIToolDomain is what a module contributes to CubeBox: the knowledge pack the planner reads and an
+// executor for the module's API tools. Module is both the operation_id prefix of the module's tools and the
+// authz owner module of the capabilities they require.
+type cubeboxAPIToolDomain interface {
+	Module() string
+	KnowledgePackDir() string
+	ExecuteCall(ctx context.Context, request cubebox.ExecuteRequest, tool cubebox.APITool, call cubebox.APICallStep) (cubebox.ExecuteResult, error)
+}
+
+// cubeboxAPIToolRegistry serves the tools of every registered domain as one runner. Each call is routed to
+// the domain named by its tool's operation_id prefix, so a single plan may mix modules and still share the
+// concurrent executor.
+type cubeboxAPIToolRegistry struct {
+	domains        map[string]cubeboxAPIToolDomain
+	packDirs       []string
+	tools          []cubebox.APITool
+	toolMap        map[string]cubebox.APITool
+	maxConcurrency int
+}
+
+func newCubeboxAPIToolRegistry(domains ...cubeboxAPIToolDomain) (*cubeboxAPIToolRegistry, error) {
+	facts, err := CollectAuthzAPICatalogRuntimeFacts()
+	if err != nil {
+		return nil, err
+	}
+	tools, err := BuildCubeBoxRuntimeAPITools(facts)
+	if err != nil {
+		return nil, err
+	}
+	return buildCubeboxAPIToolRegistry(tools, domains...)
+}
+
+func buildCubeboxAPIToolRegistry(tools []cubebox.APITool, domains ...cubeboxAPIToolDomain) (*cubeboxAPIToolRegistry, error) {
+	registry := &cubeboxAPIToolRegistry{
+		domains:        map[string]cubeboxAPIToolDomain{},
+		toolMap:        map[string]cubebox.APITool{},
+		maxConcurrency: cubebox.DefaultAPICallPlanMaxConcurrency,
+	}
+	for _, domain := range domains {
+		if domain == nil {
+			continue
+		}
+		module := strings.TrimSpace(domain.Module())
+		if module == "" {
+			return nil, fmt.Errorf("cubebox api tool domain module required")
+		}
+		if _, exists := registry.domains[module]; exists {
+			return nil, fmt.Errorf("duplicate cubebox api tool domain: %s", module)
+		}
+		registry.domains[module] = domain
+		registry.packDirs = append(registry.packDirs, strings.TrimSpace(domain.KnowledgePackDir()))
+	}
+	if len(registry.domains) == 0 {
+		return nil, nil
+	}
+	for _, tool := range tools {
+		tool = tool.Normalized()
+		module := cubebox.APIToolOperationDomain(tool.OperationID)
+		if _, ok := registry.domains[module]; !ok {
+			// The module is not wired in this process (its store is missing), so the planner must not see it.
+			continue
+		}
+		if tool.AuthzCapabilityKey != "" {
+			capability, ok := authz.LookupAuthzCapability(tool.AuthzCapabilityKey)
+			if !ok || capability.OwnerModule != module {
+				return nil, fmt.Errorf("cubebox api tool %s requires capability %s owned by another module", tool.OperationID, tool.AuthzCapabilityKey)
+			}
+		}
+		registry.tools = append(registry.tools, tool)
+		registry.toolMap[cubebox.APIToolRouteID(tool.Method, tool.Path)] = tool
+	}
+	for module := range registry.domains {
+		if !registry.hasModuleTools(module) {
+			return nil, fmt.Errorf("cubebox api tool domain has no tools: %s", module)
+		}
+	}
+	sort.SliceStable(registry.tools, func(i, j int) bool {
+		if registry.tools[i].Path == registry.tools[j].Path {
+			return registry.tools[i].Method < registry.tools[j].Method
+		}
+		return registry.tools[i].Path < registry.tools[j].Path
+	})
+	return registry, nil
+}
+
+func (r *cubeboxAPIToolRegistry) hasModuleTools(module string) bool {
+	for _, tool := range r.tools {
+		if cubebox.APIToolOperationDomain(tool.OperationID) == module {
+			return true
+		}
+	}
+	return false
+}
+
+// KnowledgePackDirs lists the packs of the registered domains in registration order.
+func (r *cubeboxAPIToolRegistry) KnowledgePackDirs() []string {
+	if r == nil {
+		return nil
+	}
+	return append([]string(nil), r.packDirs...)
+}
+
+func (r *cubeboxAPIToolRegistry) Tools() []cubebox.APITool {
+	if r == nil {
+		return nil
+	}
+	out := make([]cubebox.APITool, 0, len(r.tools))
+	for _, tool := range r.tools {
+		out = append(out, tool.Normalized())
+	}
+	return out
+}
+
+func (r *cubeboxAPIToolRegistry) ExecutePlan(ctx context.Context, request cubebox.ExecuteRequest, plan cubebox.APICallPlan) ([]cubebox.ExecuteResult, error) {
+	if r == nil || len(r.toolMap) == 0 {
+		return nil, cubebox.ErrAPICatalogDriftOrExecutorMissing
+	}
+	if err := cubebox.ValidateAPICallPlan(plan); err != nil {
+		return nil, err
+	}
+	// Resolve every tool and check every call's params up front, so a bad step fails the plan before any
+	// call runs rather than after its independent siblings already have.
+	tools := make(map[string]cubebox.APITool, len(plan.Calls))
+	for _, call := range plan.Calls {
+		tool, ok := r.toolMap[cubebox.APIToolRouteID(call.Method, call.Path)]
+		if !ok {
+			return nil, cubebox.ErrAPICatalogDriftOrExecutorMissing
+		}
+		if err := validateAPICallParams(tool, call.Params); err != nil {
+			return nil, err
+		}
+		tools[strings.TrimSpace(call.ID)] = tool
+	}
+	return cubebox.ExecuteAPICallPlanConcurrently(ctx, plan, r.maxConcurrency, func(ctx context.Context, call cubebox.APICallStep) (cubebox.ExecuteResult, error) {
+		tool := tools[strings.TrimSpace(call.ID)]
+		domain, ok := r.domains[cubebox.APIToolOperationDomain(tool.OperationID)]
+		if !ok {
+			return cubebox.ExecuteResult{}, cubebox.ErrAPICatalogDriftOrExecutorMissing
+		}
+		result, err := domain.ExecuteCall(ctx, request, tool, call)
+		if err != nil {
+			return cubebox.ExecuteResult{}, err
+		}
+		result.StepID = strings.TrimSpace(call.ID)
+		result.Method = strings.ToUpper(strings.TrimSpace(call.Method))
+		result.Path = normalizeServerAPIToolPath(call.Path)
+		result.OperationID = tool.OperationID
+		result.Params = call.Params
+		if result.ResultFocus == nil {
+			result.ResultFocus = append([]string(nil), call.ResultFocus...)
+		}
+		return result, nil
+	})
+}
+
+// authorizeAPIToolCall is the capability check every domain runs before dispatching a call: the tool must
+// still match its route requirement, belong to the calling module, and be granted to the principal.
+// Session-scoped tools only read the caller's own session and skip the capability lookup.
+func authorizeAPIToolCall(ctx context.Context, runtime authzRuntimeStore, module string, request cubebox.ExecuteRequest, tool cubebox.APITool) (bool, error) {
+	tenantID := strings.TrimSpace(request.TenantID)
+	principalID := strings.TrimSpace(request.PrincipalID)
+	if tenantID == "" || principalID == "" {
+		return false, cubebox.ErrAPICallPlanBoundaryViolation
+	}
+	if cubebox.APIToolOperationDomain(tool.OperationID) != module {
+		return false, cubebox.ErrAPICatalogDriftOrExecutorMissing
+	}
+	if tool.AuthzCapabilityKey == "" && tool.ResourceObject == "" && tool.Action == "" {
+		if _, ok := findRouteRequirement(tool.Method, tool.Path); ok || !isSessionScopedAPITool(tool) {
+			return false, cubebox.ErrAPICatalogDriftOrExecutorMissing
+		}
+		return true, nil
+	}
+	if tool.AuthzCapabilityKey == "" || tool.ResourceObject == "" || tool.Action == "" {
+		return false, cubebox.ErrAPICatalogDriftOrExecutorMissing
+	}
+	if tool.AuthzCapabilityKey != authz.AuthzCapabilityKey(tool.ResourceObject, tool.Action) {
+		return false, cubebox.ErrAPICatalogDriftOrExecutorMissing
+	}
+	if capability, ok := authz.LookupAuthzCapability(tool.AuthzCapabilityKey); !ok || capability.OwnerModule != module {
+		return false, cubebox.ErrAPICatalogDriftOrExecutorMissing
+	}
+	if req, ok := findRouteRequirement(tool.Method, tool.Path); !ok ||
+		req.Surface != authz.CapabilitySurfaceTenantAPI ||
+		req.Object != tool.ResourceObject ||
+		req.Action != tool.Action {
+		return false, cubebox.ErrAPICatalogDriftOrExecutorMissing
+	}
+	if runtime == nil {
+		return false, cubebox.ErrAPICatalogDriftOrExecutorMissing
+	}
+	return runtime.AuthorizePrincipal(ctx, tenantID, principalID, tool.ResourceObject, tool.Action)
+}
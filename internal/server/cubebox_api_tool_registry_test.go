@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/authz"
+)
+
+type cubeboxToolAuthzStub struct {
+	sessionCapabilitiesAuthorizerStub
+	roles []authzRoleDefinition
+}
+
+func (s cubeboxToolAuthzStub) AuthorizePrincipal(_ context.Context, _ string, _ string, object string, action string) (bool, error) {
+	return s.allowed[authz.AuthzCapabilityKey(object, action)], nil
+}
+
+func (s cubeboxToolAuthzStub) ListRoleDefinitions(context.Context, string) ([]authzRoleDefinition, error) {
+	return s.roles, nil
+}
+
+func newTestCubeboxAPIToolRegistry(t *testing.T, runtime authzRuntimeStore) *cubeboxAPIToolRegistry {
+	t.Helper()
+	registry, err := newCubeboxAPIToolRegistry(
+		newCubeboxOrgUnitAPIToolDomain(newOrgUnitMemoryStore(), runtime),
+		newCubeboxIAMAPIToolDomain(newDictMemoryStore(), runtime),
+	)
+	if err != nil {
+		t.Fatalf("newCubeboxAPIToolRegistry err=%v", err)
+	}
+	return registry
+}
+
+func TestCubeboxAPIToolRegistryCombinesModuleTools(t *testing.T) {
+	registry := newTestCubeboxAPIToolRegistry(t, cubeboxToolAuthzStub{})
+
+	operations := map[string]cubebox.APITool{}
+	for _, tool := range registry.Tools() {
+		operations[tool.OperationID] = tool
+	}
+	for _, operationID := range []string{"orgunit.list", "orgunit.details", "iam.dicts", "iam.dict_values", "iam.roles", "iam.my_capabilities"} {
+		if _, ok := operations[operationID]; !ok {
+			t.Fatalf("missing tool %s in %+v", operationID, operations)
+		}
+	}
+	if got := operations["iam.dict_values"].AuthzCapabilityKey; got != "iam.dicts:read" {
+		t.Fatalf("iam.dict_values capability=%q", got)
+	}
+	if got := operations["iam.my_capabilities"].AuthzCapabilityKey; got != "" {
+		t.Fatalf("session-scoped capability=%q", got)
+	}
+
+	packs := make([]cubebox.KnowledgePack, 0, len(registry.KnowledgePackDirs()))
+	for _, dir := range registry.KnowledgePackDirs() {
+		pack, err := cubebox.LoadKnowledgePack(dir)
+		if err != nil {
+			t.Fatalf("LoadKnowledgePack(%s) err=%v", dir, err)
+		}
+		packs = append(packs, pack)
+	}
+	if len(packs) != 2 {
+		t.Fatalf("packs=%d", len(packs))
+	}
+	if err := cubebox.ValidateKnowledgePacks(packs); err != nil {
+		t.Fatalf("ValidateKnowledgePacks err=%v", err)
+	}
+}
+
+func TestCubeboxAPIToolRegistryExecutesIAMTools(t *testing.T) {
+	runtime := cubeboxToolAuthzStub{
+		sessionCapabilitiesAuthorizerStub: sessionCapabilitiesAuthorizerStub{allowed: map[string]bool{"iam.dicts:read": true}},
+		roles:                             []authzRoleDefinition{{RoleSlug: "tenant-viewer", Name: "Viewer"}},
+	}
+	registry := newTestCubeboxAPIToolRegistry(t, runtime)
+	request := cubebox.ExecuteRequest{TenantID: "t1", PrincipalID: "p1"}
+
+	results, err := registry.ExecutePlan(context.Background(), request, cubebox.APICallPlan{Calls: []cubebox.APICallStep{
+		{ID: "step-1", Method: "GET", Path: "/iam/api/dicts/values", Params: map[string]any{"dict_code": "org_type", "as_of": "2026-04-25", "keyword": "20"}, DependsOn: []string{}},
+		{ID: "step-2", Method: "GET", Path: "/iam/api/me/capabilities", Params: map[string]any{}, DependsOn: []string{}},
+	}})
+	if err != nil {
+		t.Fatalf("ExecutePlan err=%v", err)
+	}
+	if len(results) != 2 || results[0].OperationID != "iam.dict_values" || results[1].OperationID != "iam.my_capabilities" {
+		t.Fatalf("results=%+v", results)
+	}
+	values, _ := results[0].Payload["values"].([]any)
+	if len(values) != 1 || values[0].(map[string]any)["label"] != "单位" {
+		t.Fatalf("values=%+v", results[0].Payload)
+	}
+	if keys, _ := results[1].Payload["authz_capability_keys"].([]any); len(keys) != 1 || keys[0] != "iam.dicts:read" {
+		t.Fatalf("capabilities=%+v", results[1].Payload)
+	}
+
+	// iam.roles needs iam.authz:read, which this principal lacks.
+	_, err = registry.ExecutePlan(context.Background(), request, cubebox.APICallPlan{Calls: []cubebox.APICallStep{
+		{ID: "step-1", Method: "GET", Path: "/iam/api/authz/roles", Params: map[string]any{}, DependsOn: []string{}},
+	}})
+	var forbidden *iamAPIToolForbiddenError
+	if !errors.As(err, &forbidden) || queryExecutionErrorToTerminal(err).Code != "forbidden" {
+		t.Fatalf("err=%v", err)
+	}
+
+	// Org unit calls still go through the orgunit domain and its own denial.
+	_, err = registry.ExecutePlan(context.Background(), request, cubebox.APICallPlan{Calls: []cubebox.APICallStep{
+		{ID: "step-1", Method: "GET", Path: "/org/api/org-units", Params: map[string]any{"as_of": "2026-04-25"}, DependsOn: []string{}},
+	}})
+	var scope *orgUnitAuthzScopeError
+	if !errors.As(err, &scope) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestBuildCubeboxAPIToolRegistryRejectsForeignCapability(t *testing.T) {
+	runtime := cubeboxToolAuthzStub{}
+	tool := cubebox.APITool{
+		Method:             "GET",
+		Path:               "/iam/api/dicts",
+		OperationID:        "iam.dicts",
+		ResourceObject:     authz.ObjectOrgUnitOrgUnits,
+		Action:             authz.ActionRead,
+		AuthzCapabilityKey: authz.AuthzCapabilityKey(authz.ObjectOrgUnitOrgUnits, authz.ActionRead),
+	}
+	_, err := buildCubeboxAPIToolRegistry([]cubebox.APITool{tool}, newCubeboxIAMAPIToolDomain(newDictMemoryStore(), runtime))
+	if err == nil || !strings.Contains(err.Error(), "owned by another module") {
+		t.Fatalf("err=%v", err)
+	}
+
+	// A module with no callable tools is a wiring mistake, not an empty runner.
+	orgOnly := cubebox.APITool{Method: "GET", Path: "/org/api/org-units", OperationID: "orgunit.list"}
+	_, err = buildCubeboxAPIToolRegistry([]cubebox.APITool{orgOnly}, newCubeboxIAMAPIToolDomain(newDictMemoryStore(), runtime))
+	if err == nil || !strings.Contains(err.Error(), "has no tools: iam") {
+		t.Fatalf("err=%v", err)
+	}
+
+	// A tool stripped of its capability is only accepted when the overlay declares it session-scoped.
+	domain := newCubeboxIAMAPIToolDomain(newDictMemoryStore(), runtime)
+	stripped := cubebox.APITool{Method: "GET", Path: "/iam/api/dicts", OperationID: "iam.dicts"}
+	_, err = domain.ExecuteCall(context.Background(), cubebox.ExecuteRequest{TenantID: "t1", PrincipalID: "p1"}, stripped, cubebox.APICallStep{Params: map[string]any{"as_of": "2026-04-25"}})
+	if !errors.Is(err, cubebox.ErrAPICatalogDriftOrExecutorMissing) {
+		t.Fatalf("err=%v", err)
+	}
+}
@@ -7,11 +7,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
-	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/authz"
 )
 
 type cubeboxAPIToolRunner interface {
@@ -19,100 +19,29 @@ type cubeboxAPIToolRunner interface {
 	ExecutePlan(ctx context.Context, request cubebox.ExecuteRequest, plan cubebox.APICallPlan) ([]cubebox.ExecuteResult, error)
 }
 
-type cubeboxOrgUnitAPIToolRunner struct {
+// cubeboxOrgUnitAPIToolDomain executes the orgunit module's read tools against the org unit handlers.
+type cubeboxOrgUnitAPIToolDomain struct {
 	store   OrgUnitStore
 	runtime authzRuntimeStore
-	tools   []cubebox.APITool
-	toolMap map[string]cubebox.APITool
 }
 
-func newCubeboxOrgUnitAPIToolRunner(store OrgUnitStore, runtime authzRuntimeStore) (*cubeboxOrgUnitAPIToolRunner, error) {
+func newCubeboxOrgUnitAPIToolDomain(store OrgUnitStore, runtime authzRuntimeStore) *cubeboxOrgUnitAPIToolDomain {
 	if store == nil || runtime == nil {
-		return nil, nil
-	}
-	facts, err := CollectAuthzAPICatalogRuntimeFacts()
-	if err != nil {
-		return nil, err
+		return nil
 	}
-	tools, err := BuildCubeBoxRuntimeAPITools(facts)
-	if err != nil {
-		return nil, err
-	}
-	toolMap := make(map[string]cubebox.APITool, len(tools))
-	for _, tool := range tools {
-		tool = tool.Normalized()
-		toolMap[cubebox.APIToolRouteID(tool.Method, tool.Path)] = tool
-	}
-	return &cubeboxOrgUnitAPIToolRunner{
-		store:   store,
-		runtime: runtime,
-		tools:   tools,
-		toolMap: toolMap,
-	}, nil
+	return &cubeboxOrgUnitAPIToolDomain{store: store, runtime: runtime}
 }
 
-func (r *cubeboxOrgUnitAPIToolRunner) Tools() []cubebox.APITool {
-	if r == nil {
-		return nil
-	}
-	out := make([]cubebox.APITool, 0, len(r.tools))
-	for _, tool := range r.tools {
-		out = append(out, tool.Normalized())
-	}
-	return out
+func (d *cubeboxOrgUnitAPIToolDomain) Module() string {
+	return "orgunit"
 }
 
-func (r *cubeboxOrgUnitAPIToolRunner) ExecutePlan(ctx context.Context, request cubebox.ExecuteRequest, plan cubebox.APICallPlan) ([]cubebox.ExecuteResult, error) {
-	if r == nil || r.store == nil || r.runtime == nil || len(r.toolMap) == 0 {
-		return nil, cubebox.ErrAPICatalogDriftOrExecutorMissing
-	}
-	if err := cubebox.ValidateAPICallPlan(plan); err != nil {
-		return nil, err
-	}
-	results := make([]cubebox.ExecuteResult, 0, len(plan.Calls))
-	for _, call := range plan.Calls {
-		tool, ok := r.toolMap[cubebox.APIToolRouteID(call.Method, call.Path)]
-		if !ok {
-			return nil, cubebox.ErrAPICatalogDriftOrExecutorMissing
-		}
-		if err := validateAPICallParams(tool, call.Params); err != nil {
-			return nil, err
-		}
-		result, err := r.executeCall(ctx, request, tool, call)
-		if err != nil {
-			return nil, err
-		}
-		result.StepID = strings.TrimSpace(call.ID)
-		result.Method = strings.ToUpper(strings.TrimSpace(call.Method))
-		result.Path = normalizeServerAPIToolPath(call.Path)
-		result.OperationID = tool.OperationID
-		if result.ResultFocus == nil {
-			result.ResultFocus = append([]string(nil), call.ResultFocus...)
-		}
-		results = append(results, result)
-	}
-	return results, nil
+func (d *cubeboxOrgUnitAPIToolDomain) KnowledgePackDir() string {
+	return mustResolveRepoPath(filepath.Join("modules", "orgunit", "presentation", "cubebox"))
 }
 
-func (r *cubeboxOrgUnitAPIToolRunner) executeCall(ctx context.Context, request cubebox.ExecuteRequest, tool cubebox.APITool, call cubebox.APICallStep) (cubebox.ExecuteResult, error) {
-	tenantID := strings.TrimSpace(request.TenantID)
-	principalID := strings.TrimSpace(request.PrincipalID)
-	if tenantID == "" || principalID == "" {
-		return cubebox.ExecuteResult{}, cubebox.ErrAPICallPlanBoundaryViolation
-	}
-	if tool.AuthzCapabilityKey == "" || tool.ResourceObject == "" || tool.Action == "" {
-		return cubebox.ExecuteResult{}, cubebox.ErrAPICatalogDriftOrExecutorMissing
-	}
-	if tool.AuthzCapabilityKey != authz.AuthzCapabilityKey(tool.ResourceObject, tool.Action) {
-		return cubebox.ExecuteResult{}, cubebox.ErrAPICatalogDriftOrExecutorMissing
-	}
-	if req, ok := findRouteRequirement(tool.Method, tool.Path); !ok ||
-		req.Surface != authz.CapabilitySurfaceTenantAPI ||
-		req.Object != tool.ResourceObject ||
-		req.Action != tool.Action {
-		return cubebox.ExecuteResult{}, cubebox.ErrAPICatalogDriftOrExecutorMissing
-	}
-	allowed, err := r.runtime.AuthorizePrincipal(ctx, tenantID, principalID, tool.ResourceObject, tool.Action)
+func (d *cubeboxOrgUnitAPIToolDomain) ExecuteCall(ctx context.Context, request cubebox.ExecuteRequest, tool cubebox.APITool, call cubebox.APICallStep) (cubebox.ExecuteResult, error) {
+	allowed, err := authorizeAPIToolCall(ctx, d.runtime, d.Module(), request, tool)
 	if err != nil {
 		return cubebox.ExecuteResult{}, err
 	}
@@ -127,13 +56,13 @@ func (r *cubeboxOrgUnitAPIToolRunner) executeCall(ctx context.Context, request c
 	rec := httptest.NewRecorder()
 	switch tool.Path {
 	case "/org/api/org-units":
-		handleOrgUnitsAPI(rec, httpReq, r.store, nil, r.runtime)
+		handleOrgUnitsAPI(rec, httpReq, d.store, nil, d.runtime)
 	case "/org/api/org-units/details":
-		handleOrgUnitsDetailsAPI(rec, httpReq, r.store, r.runtime)
+		handleOrgUnitsDetailsAPI(rec, httpReq, d.store, d.runtime)
 	case "/org/api/org-units/search":
-		handleOrgUnitsSearchAPI(rec, httpReq, r.store, r.runtime)
+		handleOrgUnitsSearchAPI(rec, httpReq, d.store, d.runtime)
 	case "/org/api/org-units/audit":
-		handleOrgUnitsAuditAPI(rec, httpReq, r.store, r.runtime)
+		handleOrgUnitsAuditAPI(rec, httpReq, d.store, d.runtime)
 	default:
 		return cubebox.ExecuteResult{}, cubebox.ErrAPICatalogDriftOrExecutorMissing
 	}
@@ -215,6 +144,13 @@ func appendAPIToolQueryParam(values url.Values, name string, value any) error {
 			return fmt.Errorf("%w: numeric param must be integer: %s", cubebox.ErrAPICallPlanBoundaryViolation, name)
 		}
 		values.Set(name, strconv.Itoa(int(v)))
+	case map[string]any:
+		// Structured params (the org unit list filter) travel as their JSON encoding.
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("%w: unsupported param value for %s", cubebox.ErrAPICallPlanBoundaryViolation, name)
+		}
+		values.Set(name, string(encoded))
 	default:
 		return fmt.Errorf("%w: unsupported param type for %s", cubebox.ErrAPICallPlanBoundaryViolation, name)
 	}
@@ -222,6 +158,14 @@ func appendAPIToolQueryParam(values url.Values, name string, value any) error {
 }
 
 func normalizeOrgUnitListAPIToolPagination(values url.Values) {
+	if _, ok := values["cursor"]; ok {
+		// Following a next_cursor: the cursor already fixes the position, so a page number would be rejected.
+		values.Del("page")
+		if strings.TrimSpace(values.Get("size")) == "" {
+			values.Set("size", "100")
+		}
+		return
+	}
 	rawPage := strings.TrimSpace(values.Get("page"))
 	rawSize := strings.TrimSpace(values.Get("size"))
 	if rawPage == "" && rawSize == "" {
@@ -274,7 +218,7 @@ func apiToolConfirmedEntity(tool cubebox.APITool, payload map[string]any) *cubeb
 	}
 	entity := cubebox.QueryEntity{
 		Intent:    strings.TrimSpace(tool.OperationID),
-		Domain:    "orgunit",
+		Domain:    cubebox.APIToolOperationDomain(tool.OperationID),
 		EntityKey: key,
 		AsOf:      stringFromPayload(payload["as_of"]),
 	}
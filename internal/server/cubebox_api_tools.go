@@ -12,9 +12,12 @@ import (
 )
 
 type apiToolOverlayDefinition struct {
-	Method                string
-	Path                  string
-	CubeBoxCallable       bool
+	Method          string
+	Path            string
+	CubeBoxCallable bool
+	// SessionScoped marks a route that only reads the caller's own session, such as their capabilities. It
+	// carries no authz requirement, so it stays out of the authz catalog and is never capability-checked.
+	SessionScoped         bool
 	OperationID           string
 	UseSummary            string
 	RequestSchema         cubebox.APIToolRequestSchema
@@ -22,16 +25,21 @@ type apiToolOverlayDefinition struct {
 	ObservationProjection cubebox.APIToolObservationProjection
 }
 
+// orgUnitListFilterToolParamDescription documents the list filter grammar for the planner; it mirrors
+// orgunitservices.ParseOrgUnitListFilter.
+const orgUnitListFilterToolParamDescription = "结构化过滤表达式：分组 {\"and\":[...]} 或 {\"or\":[...]}（最多 4 层、32 个条件）；条件 {\"field\":字段,\"op\":操作,\"value\":值} 或 {\"field\":字段,\"op\":\"in\"|\"between\",\"values\":[...]}。" +
+	"op 取 eq、in、prefix、gt、gte、lt、lte、between、is_null、not_null；field 取 org_code、name、status、is_business_unit 或允许过滤的扩展字段 key（如 org_type）。"
+
 var apiToolOverlayDefinitions = []apiToolOverlayDefinition{
 	{
 		Method:          http.MethodGet,
 		Path:            "/org/api/org-units",
 		CubeBoxCallable: true,
 		OperationID:     "orgunit.list",
-		UseSummary:      "按日期列出当前用户可访问的组织；可用 keyword、parent_org_code、status、is_business_unit 和分页参数收窄。",
+		UseSummary:      "按日期列出当前用户可访问的组织；可用 keyword、parent_org_code、status、is_business_unit、filter 和分页参数收窄。",
 		RequestSchema: cubebox.APIToolRequestSchema{
 			Required: []string{"as_of"},
-			Optional: []string{"include_disabled", "parent_org_code", "all_org_units", "keyword", "status", "is_business_unit", "page", "page_size"},
+			Optional: []string{"include_disabled", "parent_org_code", "all_org_units", "keyword", "status", "is_business_unit", "filter", "page", "page_size", "cursor"},
 			Params: map[string]cubebox.APIParamSpec{
 				"as_of":            {Type: "date", Description: "业务生效日期，YYYY-MM-DD。"},
 				"include_disabled": {Type: "boolean"},
@@ -40,14 +48,16 @@ var apiToolOverlayDefinitions = []apiToolOverlayDefinition{
 				"keyword":          {Type: "string"},
 				"status":           {Type: "string"},
 				"is_business_unit": {Type: "boolean"},
+				"filter":           {Type: "object", Description: orgUnitListFilterToolParamDescription},
 				"page":             {Type: "integer", Description: "planner-facing 1 基页码。"},
 				"page_size":        {Type: "integer"},
+				"cursor":           {Type: "string", Description: "上一页结果中的 next_cursor；续翻时原样传入并保持其余参数不变，不再传 page。"},
 			},
 		},
 		ResponseSchemaRef: "orgUnitListAPIResponse",
 		ObservationProjection: cubebox.APIToolObservationProjection{
 			RootField:       "org_units",
-			SummaryFields:   []string{"as_of", "total", "page", "size"},
+			SummaryFields:   []string{"as_of", "total", "page", "size", "next_cursor"},
 			EntityKeyField:  "org_code",
 			EntityNameField: "name",
 		},
@@ -80,14 +90,15 @@ var apiToolOverlayDefinitions = []apiToolOverlayDefinition{
 		Path:            "/org/api/org-units/search",
 		CubeBoxCallable: true,
 		OperationID:     "orgunit.search",
-		UseSummary:      "按关键词在指定日期搜索一个组织，并返回其路径编码。",
+		UseSummary:      "按关键词在指定日期搜索一个组织，并返回其路径编码；支持编码、名称、全路径、拼音全拼与首字母及近似拼写。",
 		RequestSchema: cubebox.APIToolRequestSchema{
 			Required: []string{"query", "as_of"},
-			Optional: []string{"include_disabled"},
+			Optional: []string{"include_disabled", "limit"},
 			Params: map[string]cubebox.APIParamSpec{
 				"query":            {Type: "string"},
 				"as_of":            {Type: "date", Description: "业务生效日期，YYYY-MM-DD。"},
 				"include_disabled": {Type: "boolean"},
+				"limit":            {Type: "integer", Description: "候选数量上限，1-50，缺省 8。"},
 			},
 		},
 		ResponseSchemaRef: "OrgUnitSearchResult",
@@ -106,19 +117,92 @@ var apiToolOverlayDefinitions = []apiToolOverlayDefinition{
 		UseSummary:      "按组织编码读取当前用户有权访问的组织审计事件。",
 		RequestSchema: cubebox.APIToolRequestSchema{
 			Required: []string{"org_code"},
-			Optional: []string{"limit"},
+			Optional: []string{"limit", "cursor"},
 			Params: map[string]cubebox.APIParamSpec{
 				"org_code": {Type: "string"},
 				"limit":    {Type: "integer"},
+				"cursor":   {Type: "string", Description: "上一页结果中的 next_cursor；续读更早的审计事件时原样传入。"},
 			},
 		},
 		ResponseSchemaRef: "orgUnitAuditAPIResponse",
 		ObservationProjection: cubebox.APIToolObservationProjection{
 			RootField:      "events",
-			SummaryFields:  []string{"org_code", "limit", "has_more"},
+			SummaryFields:  []string{"org_code", "limit", "has_more", "next_cursor"},
 			EntityKeyField: "org_code",
 		},
 	},
+	{
+		Method:          http.MethodGet,
+		Path:            "/iam/api/dicts",
+		CubeBoxCallable: true,
+		OperationID:     "iam.dicts",
+		UseSummary:      "按日期列出当前租户的字典（字典编码、名称、状态）。",
+		RequestSchema: cubebox.APIToolRequestSchema{
+			Required: []string{"as_of"},
+			Params: map[string]cubebox.APIParamSpec{
+				"as_of": {Type: "date", Description: "业务生效日期，YYYY-MM-DD。"},
+			},
+		},
+		ResponseSchemaRef: "dictListResponse",
+		ObservationProjection: cubebox.APIToolObservationProjection{
+			RootField:       "dicts",
+			SummaryFields:   []string{"as_of"},
+			EntityKeyField:  "dict_code",
+			EntityNameField: "name",
+		},
+	},
+	{
+		Method:          http.MethodGet,
+		Path:            "/iam/api/dicts/values",
+		CubeBoxCallable: true,
+		OperationID:     "iam.dict_values",
+		UseSummary:      "按字典编码和日期列出字典值（编码、名称、状态）；可用 keyword、status、limit 收窄。",
+		RequestSchema: cubebox.APIToolRequestSchema{
+			Required: []string{"dict_code", "as_of"},
+			Optional: []string{"keyword", "status", "limit"},
+			Params: map[string]cubebox.APIParamSpec{
+				"dict_code": {Type: "string", Description: "字典编码，如 org_type。"},
+				"as_of":     {Type: "date", Description: "业务生效日期，YYYY-MM-DD。"},
+				"keyword":   {Type: "string", Description: "按值编码或名称模糊匹配。"},
+				"status":    {Type: "string", Description: "active、inactive 或 all，缺省 all。"},
+				"limit":     {Type: "integer", Description: "返回数量上限，1-50，缺省 10。"},
+			},
+		},
+		ResponseSchemaRef: "dictValuesResponse",
+		ObservationProjection: cubebox.APIToolObservationProjection{
+			RootField:     "values",
+			SummaryFields: []string{"dict_code", "as_of"},
+		},
+	},
+	{
+		Method:          http.MethodGet,
+		Path:            "/iam/api/authz/roles",
+		CubeBoxCallable: true,
+		OperationID:     "iam.roles",
+		UseSummary:      "列出当前租户的角色定义及各角色包含的功能授权项。",
+		RequestSchema: cubebox.APIToolRequestSchema{
+			Params: map[string]cubebox.APIParamSpec{},
+		},
+		ResponseSchemaRef: "authzRolesResponse",
+		ObservationProjection: cubebox.APIToolObservationProjection{
+			RootField: "roles",
+		},
+	},
+	{
+		Method:          http.MethodGet,
+		Path:            "/iam/api/me/capabilities",
+		CubeBoxCallable: true,
+		SessionScoped:   true,
+		OperationID:     "iam.my_capabilities",
+		UseSummary:      "读取当前登录用户自己拥有的功能授权项。",
+		RequestSchema: cubebox.APIToolRequestSchema{
+			Params: map[string]cubebox.APIParamSpec{},
+		},
+		ResponseSchemaRef: "sessionCapabilitiesResponse",
+		ObservationProjection: cubebox.APIToolObservationProjection{
+			SummaryFields: []string{"authz_capability_keys"},
+		},
+	},
 }
 
 func listAPIToolOverlayDefinitions() []apiToolOverlayDefinition {
@@ -136,6 +220,9 @@ func ListAuthzToolOverlayCoverage() []AuthzToolOverlayCoverage {
 	definitions := listAPIToolOverlayDefinitions()
 	out := make([]AuthzToolOverlayCoverage, 0, len(definitions))
 	for _, definition := range definitions {
+		if definition.SessionScoped {
+			continue
+		}
 		out = append(out, AuthzToolOverlayCoverage{
 			Method:          strings.ToUpper(strings.TrimSpace(definition.Method)),
 			Path:            normalizeServerAPIToolPath(definition.Path),
@@ -170,6 +257,13 @@ func BuildCubeBoxRuntimeAPITools(facts AuthzCoverageFacts) ([]cubebox.APITool, e
 		if strings.ToUpper(strings.TrimSpace(definition.Method)) != http.MethodGet {
 			return nil, fmt.Errorf("cubebox api tool overlay must be GET: %s", routeID)
 		}
+		if definition.SessionScoped {
+			if _, ok := findRouteRequirement(definition.Method, definition.Path); ok {
+				return nil, fmt.Errorf("cubebox session-scoped api tool overlay has an authz requirement: %s", routeID)
+			}
+			tools = append(tools, apiToolFromOverlayDefinition(definition, authzAPICatalogEntry{}))
+			continue
+		}
 		entry, ok := entryByRoute[routeID]
 		if !ok {
 			return nil, fmt.Errorf("cubebox api tool overlay missing authz catalog entry: %s", routeID)
@@ -180,19 +274,7 @@ func BuildCubeBoxRuntimeAPITools(facts AuthzCoverageFacts) ([]cubebox.APITool, e
 		if entry.AuthzCapabilityKey == "" || entry.ResourceObject == "" || entry.Action == "" {
 			return nil, fmt.Errorf("cubebox api tool overlay missing authz requirement fields: %s", routeID)
 		}
-		tool := cubebox.APITool{
-			Method:                definition.Method,
-			Path:                  definition.Path,
-			OperationID:           definition.OperationID,
-			UseSummary:            definition.UseSummary,
-			RequestSchema:         definition.RequestSchema,
-			ResponseSchemaRef:     definition.ResponseSchemaRef,
-			ObservationProjection: definition.ObservationProjection,
-			ResourceObject:        entry.ResourceObject,
-			Action:                entry.Action,
-			AuthzCapabilityKey:    entry.AuthzCapabilityKey,
-		}.Normalized()
-		tools = append(tools, tool)
+		tools = append(tools, apiToolFromOverlayDefinition(definition, entry))
 	}
 	if len(tools) == 0 {
 		return nil, errors.New("cubebox api tools missing")
@@ -206,6 +288,33 @@ func BuildCubeBoxRuntimeAPITools(facts AuthzCoverageFacts) ([]cubebox.APITool, e
 	return tools, nil
 }
 
+func apiToolFromOverlayDefinition(definition apiToolOverlayDefinition, entry authzAPICatalogEntry) cubebox.APITool {
+	return cubebox.APITool{
+		Method:                definition.Method,
+		Path:                  definition.Path,
+		OperationID:           definition.OperationID,
+		UseSummary:            definition.UseSummary,
+		RequestSchema:         definition.RequestSchema,
+		ResponseSchemaRef:     definition.ResponseSchemaRef,
+		ObservationProjection: definition.ObservationProjection,
+		ResourceObject:        entry.ResourceObject,
+		Action:                entry.Action,
+		AuthzCapabilityKey:    entry.AuthzCapabilityKey,
+	}.Normalized()
+}
+
+// isSessionScopedAPITool reports whether a tool without an authz capability is one of the overlays declared
+// session-scoped; any other tool missing its capability is catalog drift.
+func isSessionScopedAPITool(tool cubebox.APITool) bool {
+	routeID := cubebox.APIToolRouteID(tool.Method, tool.Path)
+	for _, definition := range apiToolOverlayDefinitions {
+		if definition.SessionScoped && cubebox.APIToolRouteID(definition.Method, definition.Path) == routeID {
+			return true
+		}
+	}
+	return false
+}
+
 func normalizeServerAPIToolPath(path string) string {
 	path = strings.TrimSpace(path)
 	if path == "" || strings.HasPrefix(path, "/") {
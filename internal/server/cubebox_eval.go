@@ -0,0 +1,439 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/authz"
+)
+
+const (
+	cubeboxEvalTenantID    = "t1"
+	cubeboxEvalPrincipalID = "cubebox-eval"
+	cubeboxEvalProviderID  = "cubebox-eval"
+
+	cubeboxEvalProviderScripted = "scripted"
+	cubeboxEvalProviderLocal    = "local"
+)
+
+// CubeBoxEvalOptions picks the provider stand-in for RunCubeBoxEvalSuite. With no BaseURL the recorded
+// planner and narrator outputs of each turn are replayed; otherwise every call goes to a local
+// OpenAI-compatible endpoint and the recordings are ignored.
+type CubeBoxEvalOptions struct {
+	BaseURL string
+	Model   string
+	APIKey  string
+}
+
+func (o CubeBoxEvalOptions) providerName() string {
+	if strings.TrimSpace(o.BaseURL) == "" {
+		return cubeboxEvalProviderScripted
+	}
+	return cubeboxEvalProviderLocal
+}
+
+// RunCubeBoxEvalSuite replays every scenario through the production query flow, producer and narrator,
+// backed by in-memory org, dict and authz stores, and scores the last turn of each scenario. Each scenario
+// gets fresh stores so scenarios cannot leak history into one another.
+func RunCubeBoxEvalSuite(ctx context.Context, suite cubebox.EvalSuite, options CubeBoxEvalOptions) (cubebox.EvalReport, error) {
+	if err := cubebox.ValidateEvalSuite(suite); err != nil {
+		return cubebox.EvalReport{}, err
+	}
+	if options.providerName() == cubeboxEvalProviderLocal && strings.TrimSpace(options.Model) == "" {
+		return cubebox.EvalReport{}, fmt.Errorf("cubebox eval: model required for a local provider")
+	}
+	results := make([]cubebox.EvalScenarioResult, 0, len(suite.Scenarios))
+	for _, scenario := range suite.Scenarios {
+		observation, err := runCubeboxEvalScenario(ctx, suite, scenario, options)
+		if err != nil {
+			return cubebox.EvalReport{}, fmt.Errorf("cubebox eval scenario %s: %w", scenario.ID, err)
+		}
+		results = append(results, cubebox.ScoreEvalScenario(scenario, observation))
+	}
+	return cubebox.SummarizeEvalResults(suite, options.providerName(), results), nil
+}
+
+func runCubeboxEvalScenario(ctx context.Context, suite cubebox.EvalSuite, scenario cubebox.EvalScenario, options CubeBoxEvalOptions) (cubebox.EvalObservation, error) {
+	orgStore, err := newCubeboxEvalOrgStore(ctx, suite.OrgUnits)
+	if err != nil {
+		return cubebox.EvalObservation{}, err
+	}
+	authzRuntime := newMemoryAuthzRuntimeStore()
+	if err := authzRuntime.EnsurePrincipalRoleAssignment(ctx, cubeboxEvalTenantID, cubeboxEvalPrincipalID, authz.RoleTenantAdmin); err != nil {
+		return cubebox.EvalObservation{}, err
+	}
+	registry, err := newCubeboxAPIToolRegistry(
+		newCubeboxOrgUnitAPIToolDomain(orgStore, authzRuntime),
+		newCubeboxIAMAPIToolDomain(newDictMemoryStore(), authzRuntime),
+	)
+	if err != nil {
+		return cubebox.EvalObservation{}, err
+	}
+
+	var adapter cubebox.ProviderAdapter = cubebox.NewOpenAICompatibleAdapter(nil)
+	var script *cubeboxEvalScriptedAdapter
+	if options.providerName() == cubeboxEvalProviderScripted {
+		script = &cubeboxEvalScriptedAdapter{}
+		adapter = script
+	}
+	config := cubeboxEvalRuntimeConfig{options: options}
+	secrets := cubeboxEvalSecretResolver{apiKey: options.APIKey}
+	producer := newCubeboxProviderAPIPlanProducer(config, adapter, secrets)
+	if asOf := strings.TrimSpace(suite.AsOf); asOf != "" {
+		pinned, _ := time.Parse(time.DateOnly, asOf)
+		producer.now = func() time.Time { return pinned.Add(12 * time.Hour) }
+	}
+	observer := &cubeboxEvalObserver{producer: producer, runner: registry}
+	store := newCubeboxEvalTurnStore()
+	flow, err := newCubeboxQueryFlow(
+		cubebox.NewRuntime(),
+		store,
+		observer,
+		observer,
+		newCubeboxProviderQueryNarrator(config, adapter, secrets),
+		registry.KnowledgePackDirs(),
+	)
+	if err != nil {
+		return cubebox.EvalObservation{}, err
+	}
+
+	conversationID := "conv_eval_" + strings.TrimSpace(scenario.ID)
+	var observation cubebox.EvalObservation
+	for _, turn := range scenario.Turns {
+		if script != nil {
+			script.load(turn)
+		}
+		observer.reset()
+		mark := store.eventCount(conversationID)
+		request := cubebox.GatewayStreamRequest{
+			TenantID:       cubeboxEvalTenantID,
+			PrincipalID:    cubeboxEvalPrincipalID,
+			ConversationID: conversationID,
+			Prompt:         turn.Prompt,
+			NextSequence:   store.nextSequence(conversationID),
+		}
+		handled := flow.TryHandle(ctx, request, cubeboxEvalEventSink{})
+		observation = observer.observe(handled, store.eventsSince(conversationID, mark))
+	}
+	return observation, nil
+}
+
+func newCubeboxEvalOrgStore(ctx context.Context, units []cubebox.EvalOrgUnit) (*orgUnitMemoryStore, error) {
+	store := newOrgUnitMemoryStore()
+	keys := map[string]string{}
+	for _, unit := range units {
+		parentKey := ""
+		if parent := strings.TrimSpace(unit.ParentOrgCode); parent != "" {
+			key, ok := keys[parent]
+			if !ok {
+				return nil, fmt.Errorf("cubebox eval org unit %s: parent %s must be listed first", unit.OrgCode, parent)
+			}
+			parentKey = key
+		}
+		node, err := store.CreateNodeCurrent(ctx, cubeboxEvalTenantID, "", unit.OrgCode, unit.Name, parentKey, unit.IsBusinessUnit)
+		if err != nil {
+			return nil, fmt.Errorf("cubebox eval org unit %s: %w", unit.OrgCode, err)
+		}
+		keys[strings.TrimSpace(unit.OrgCode)] = node.ID
+	}
+	return store, nil
+}
+
+// cubeboxEvalObserver sits between the flow and the real producer and runner, recording how many planning
+// rounds ran, which calls were attempted and whether any plan crossed the tool boundary.
+type cubeboxEvalObserver struct {
+	producer cubeboxAPIPlanProducer
+	runner   cubeboxAPIToolRunner
+
+	mu                sync.Mutex
+	rounds            int
+	calls             []cubebox.APICallStep
+	boundaryViolation bool
+}
+
+func (o *cubeboxEvalObserver) reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.rounds = 0
+	o.calls = nil
+	o.boundaryViolation = false
+}
+
+func (o *cubeboxEvalObserver) ProduceAPIPlan(ctx context.Context, input cubeboxAPIPlanProductionInput) (cubeboxAPIPlanProductionResult, error) {
+	result, err := o.producer.ProduceAPIPlan(ctx, input)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if input.WorkingResults != nil {
+		o.rounds = max(o.rounds, input.WorkingResults.RoundIndex)
+	}
+	if errors.Is(err, cubebox.ErrAPICallPlanBoundaryViolation) {
+		o.boundaryViolation = true
+	}
+	return result, err
+}
+
+func (o *cubeboxEvalObserver) Tools() []cubebox.APITool {
+	return o.runner.Tools()
+}
+
+func (o *cubeboxEvalObserver) ExecutePlan(ctx context.Context, request cubebox.ExecuteRequest, plan cubebox.APICallPlan) ([]cubebox.ExecuteResult, error) {
+	results, err := o.runner.ExecutePlan(ctx, request, plan)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.calls = append(o.calls, plan.Calls...)
+	if errors.Is(err, cubebox.ErrAPICallPlanBoundaryViolation) || !o.plansWithinTools(plan) {
+		o.boundaryViolation = true
+	}
+	return results, err
+}
+
+// plansWithinTools reports whether every call names a route from api_tools. The runner reports an unknown
+// route as catalog drift, but for the planner it is the same mistake as any other boundary violation.
+func (o *cubeboxEvalObserver) plansWithinTools(plan cubebox.APICallPlan) bool {
+	routes := map[string]struct{}{}
+	for _, tool := range o.runner.Tools() {
+		routes[cubebox.APIToolRouteID(tool.Method, tool.Path)] = struct{}{}
+	}
+	for _, call := range plan.Calls {
+		if _, ok := routes[cubebox.APIToolRouteID(call.Method, call.Path)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// observe turns the events the flow persisted for one turn into an observation. Clarification metadata is
+// only appended to the event log, never streamed, so the log rather than the sink is the source.
+func (o *cubeboxEvalObserver) observe(handled bool, events []cubebox.CanonicalEvent) cubebox.EvalObservation {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	observation := cubebox.EvalObservation{
+		Calls:             append([]cubebox.APICallStep(nil), o.calls...),
+		PlanningRounds:    o.rounds,
+		BoundaryViolation: o.boundaryViolation,
+	}
+	if !handled {
+		observation.Outcome = cubebox.EvalOutcomeError
+		observation.ErrorCode = "cubebox_query_not_handled"
+		return observation
+	}
+	var narration strings.Builder
+	clarified := false
+	for _, event := range events {
+		switch event.Type {
+		case "turn.agent_message.delta":
+			delta, _ := event.Payload["delta"].(string)
+			narration.WriteString(delta)
+		case "turn.error":
+			code, _ := event.Payload["code"].(string)
+			observation.ErrorCode = strings.TrimSpace(code)
+		case cubebox.QueryClarificationRequestedEventType:
+			clarified = true
+			observation.MissingParams = evalStringList(event.Payload["missing_params"])
+		}
+	}
+	observation.Narration = strings.TrimSpace(narration.String())
+	switch {
+	case observation.ErrorCode != "":
+		observation.Outcome = cubebox.EvalOutcomeError
+	case clarified:
+		observation.Outcome = string(cubebox.PlannerOutcomeClarify)
+	case len(observation.Calls) > 0:
+		observation.Outcome = string(cubebox.PlannerOutcomeAPICalls)
+	default:
+		observation.Outcome = string(cubebox.PlannerOutcomeNoQuery)
+	}
+	if observation.ErrorCode == queryPlanErrorToTerminal(cubebox.ErrAPICallPlanBoundaryViolation).Code {
+		observation.BoundaryViolation = true
+	}
+	observation.BudgetExhausted = observation.ErrorCode == queryLoopBudgetExceededTerminal().Code
+	return observation
+}
+
+func evalStringList(value any) []string {
+	switch items := value.(type) {
+	case []string:
+		return append([]string(nil), items...)
+	case []any:
+		out := make([]string, 0, len(items))
+		for _, item := range items {
+			if text, ok := item.(string); ok {
+				out = append(out, text)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// cubeboxEvalScriptedAdapter replays one turn's recorded provider outputs. Planner requests and
+// narrator-side requests (narration, clarification, guidance) draw from separate queues, so a recording
+// does not depend on how many narrator calls the flow happens to make. An exhausted queue reports the
+// provider unavailable, which the flow handles like a real outage.
+type cubeboxEvalScriptedAdapter struct {
+	mu       sync.Mutex
+	planner  []string
+	narrator []string
+}
+
+func (a *cubeboxEvalScriptedAdapter) load(turn cubebox.EvalTurn) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.planner = append([]string(nil), turn.Planner...)
+	a.narrator = append([]string(nil), turn.Narrator...)
+}
+
+func (a *cubeboxEvalScriptedAdapter) StreamChatCompletion(_ context.Context, request cubebox.ProviderChatRequest) (cubebox.ProviderChatStream, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	queue := &a.narrator
+	if isCubeboxPlannerChatRequest(request) {
+		queue = &a.planner
+	}
+	if len(*queue) == 0 {
+		return nil, cubebox.ErrProviderUnavailable
+	}
+	text := (*queue)[0]
+	*queue = (*queue)[1:]
+	return &cubeboxEvalScriptedStream{text: text}, nil
+}
+
+func isCubeboxPlannerChatRequest(request cubebox.ProviderChatRequest) bool {
+	return len(request.Messages) > 0 && strings.Contains(request.Messages[0].Content, "只读查询计划器")
+}
+
+type cubeboxEvalScriptedStream struct {
+	text string
+	done bool
+}
+
+func (s *cubeboxEvalScriptedStream) Recv() (cubebox.ProviderChatChunk, error) {
+	if s.done {
+		return cubebox.ProviderChatChunk{}, io.EOF
+	}
+	s.done = true
+	return cubebox.ProviderChatChunk{Delta: s.text, Done: true}, nil
+}
+
+func (s *cubeboxEvalScriptedStream) Close() error {
+	return nil
+}
+
+type cubeboxEvalRuntimeConfig struct {
+	options CubeBoxEvalOptions
+}
+
+func (c cubeboxEvalRuntimeConfig) GetActiveModelRuntimeConfig(context.Context, string) (cubebox.ActiveModelRuntimeConfig, error) {
+	model := strings.TrimSpace(c.options.Model)
+	if model == "" {
+		model = cubeboxEvalProviderScripted
+	}
+	return cubebox.ActiveModelRuntimeConfig{
+		Selection: cubebox.ActiveModelSelection{ProviderID: cubeboxEvalProviderID, ModelSlug: model},
+		Provider: cubebox.ModelProvider{
+			ID:           cubeboxEvalProviderID,
+			ProviderType: "openai-compatible",
+			BaseURL:      strings.TrimSpace(c.options.BaseURL),
+			Enabled:      true,
+		},
+		Credential: cubebox.ModelCredential{ProviderID: cubeboxEvalProviderID, SecretRef: "env://CUBEBOX_EVAL_API_KEY", Active: true},
+	}, nil
+}
+
+type cubeboxEvalSecretResolver struct {
+	apiKey string
+}
+
+func (r cubeboxEvalSecretResolver) ResolveSecretRef(context.Context, string, string, string) (string, error) {
+	return r.apiKey, nil
+}
+
+type cubeboxEvalEventSink struct{}
+
+func (cubeboxEvalEventSink) Write(cubebox.CanonicalEvent) bool { return true }
+
+func (cubeboxEvalEventSink) WriteFallback(cubebox.CanonicalEvent) {}
+
+// cubeboxEvalTurnStore keeps one run's conversations in memory. The query flow only reads the replay and
+// prompt view and appends events; the settings half of the store interface is never reached offline and is
+// left to the embedded nil interface.
+type cubeboxEvalTurnStore struct {
+	cubeboxTurnStore
+
+	mu     sync.Mutex
+	events map[string][]cubebox.CanonicalEvent
+}
+
+func newCubeboxEvalTurnStore() *cubeboxEvalTurnStore {
+	return &cubeboxEvalTurnStore{events: map[string][]cubebox.CanonicalEvent{}}
+}
+
+func (s *cubeboxEvalTurnStore) GetConversation(_ context.Context, _ string, _ string, conversationID string) (cubebox.ConversationReplayResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return cubebox.ConversationReplayResponse{
+		Conversation: cubebox.Conversation{ID: conversationID},
+		Events:       append([]cubebox.CanonicalEvent(nil), s.events[conversationID]...),
+		NextSequence: s.nextSequenceLocked(conversationID),
+	}, nil
+}
+
+func (s *cubeboxEvalTurnStore) PrepareConversationPromptView(_ context.Context, _ string, _ string, conversationID string, _ cubebox.CanonicalContext, _ string) (cubebox.PromptViewPreparationResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return cubebox.PromptViewPreparationResponse{
+		Conversation: cubebox.Conversation{ID: conversationID},
+		NextSequence: s.nextSequenceLocked(conversationID),
+	}, nil
+}
+
+func (s *cubeboxEvalTurnStore) AppendEvent(_ context.Context, _ string, _ string, conversationID string, event cubebox.CanonicalEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events[conversationID] = append(s.events[conversationID], event)
+	return nil
+}
+
+func (s *cubeboxEvalTurnStore) AppendEvents(_ context.Context, _ string, _ string, conversationID string, events []cubebox.CanonicalEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events[conversationID] = append(s.events[conversationID], events...)
+	return nil
+}
+
+func (s *cubeboxEvalTurnStore) nextSequence(conversationID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nextSequenceLocked(conversationID)
+}
+
+func (s *cubeboxEvalTurnStore) nextSequenceLocked(conversationID string) int {
+	next := 1
+	for _, event := range s.events[conversationID] {
+		next = max(next, event.Sequence+1)
+	}
+	return next
+}
+
+func (s *cubeboxEvalTurnStore) eventCount(conversationID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.events[conversationID])
+}
+
+func (s *cubeboxEvalTurnStore) eventsSince(conversationID string, mark int) []cubebox.CanonicalEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := s.events[conversationID]
+	if mark >= len(events) {
+		return nil
+	}
+	return append([]cubebox.CanonicalEvent(nil), events[mark:]...)
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+)
+
+func TestRunCubeBoxEvalSuiteMatchesBaseline(t *testing.T) {
+	suite, err := cubebox.LoadEvalSuite(mustResolveRepoPath(filepath.Join("config", "cubebox", "eval", "suite.json")))
+	if err != nil {
+		t.Fatalf("LoadEvalSuite err=%v", err)
+	}
+	report, err := RunCubeBoxEvalSuite(context.Background(), suite, CubeBoxEvalOptions{})
+	if err != nil {
+		t.Fatalf("RunCubeBoxEvalSuite err=%v", err)
+	}
+	for _, result := range report.Results {
+		if !result.Passed {
+			t.Errorf("scenario %s failed: %v (%+v)", result.ID, result.Failures, result.Observation)
+		}
+	}
+	baseline, err := cubebox.LoadEvalReport(mustResolveRepoPath(filepath.Join("config", "cubebox", "eval", "baseline.json")))
+	if err != nil {
+		t.Fatalf("LoadEvalReport err=%v", err)
+	}
+	if comparison := cubebox.CompareEvalReports(baseline, report); comparison.HasRegression() || len(comparison.Missing) > 0 {
+		t.Fatalf("comparison=%+v", comparison)
+	}
+}
+
+func TestRunCubeBoxEvalSuiteScoresPlannerMistakes(t *testing.T) {
+	plan := func(path string, params map[string]any) string {
+		body, _ := json.Marshal(map[string]any{
+			"outcome": "API_CALLS",
+			"calls":   []map[string]any{{"id": "step-1", "method": "GET", "path": path, "params": params, "depends_on": []string{}}},
+		})
+		return string(body)
+	}
+	suite := cubebox.EvalSuite{
+		Name:     "mistakes",
+		AsOf:     "2026-04-25",
+		OrgUnits: []cubebox.EvalOrgUnit{{OrgCode: "100000", Name: "集团总部"}},
+		Scenarios: []cubebox.EvalScenario{
+			{
+				// The planner lists instead of fetching details: the plan is wrong even though the turn completes.
+				ID: "wrong-tool",
+				Turns: []cubebox.EvalTurn{{
+					Prompt:   "查 100000 的组织详情",
+					Planner:  []string{plan("/org/api/org-units", map[string]any{"as_of": "2026-04-25"}), `{"outcome":"DONE"}`},
+					Narrator: []string{"集团总部当前启用。"},
+				}},
+				Expect: cubebox.EvalExpectation{
+					Outcome: "API_CALLS",
+					Calls:   []cubebox.EvalExpectedCall{{Method: "GET", Path: "/org/api/org-units/details"}},
+				},
+			},
+			{
+				// The planner keeps repeating the same executed call until the loop gives up.
+				ID: "repeated-plan",
+				Turns: []cubebox.EvalTurn{{
+					Prompt: "查 100000 的组织详情",
+					Planner: []string{
+						plan("/org/api/org-units/details", map[string]any{"org_code": "100000", "as_of": "2026-04-25"}),
+						plan("/org/api/org-units/details", map[string]any{"org_code": "100000", "as_of": "2026-04-25"}),
+						plan("/org/api/org-units/details", map[string]any{"org_code": "100000", "as_of": "2026-04-25"}),
+						plan("/org/api/org-units/details", map[string]any{"org_code": "100000", "as_of": "2026-04-25"}),
+					},
+				}},
+				Expect: cubebox.EvalExpectation{Outcome: cubebox.EvalOutcomeError},
+			},
+		},
+	}
+	report, err := RunCubeBoxEvalSuite(context.Background(), suite, CubeBoxEvalOptions{})
+	if err != nil {
+		t.Fatalf("RunCubeBoxEvalSuite err=%v", err)
+	}
+	wrong, repeated := report.Results[0], report.Results[1]
+	if wrong.PlanCorrect || wrong.Observation.Outcome != "API_CALLS" || wrong.Observation.PlanningRounds != 2 {
+		t.Fatalf("wrong-tool=%+v", wrong)
+	}
+	if !repeated.Passed || repeated.Observation.ErrorCode != queryLoopRepeatedPlanTerminal().Code || repeated.Observation.PlanningRounds < 3 {
+		t.Fatalf("repeated-plan=%+v", repeated)
+	}
+	if report.Metrics.PlanAccuracy != 0.5 || report.Metrics.BoundaryViolationRate != 0 {
+		t.Fatalf("metrics=%+v", report.Metrics)
+	}
+
+	if _, err := RunCubeBoxEvalSuite(context.Background(), suite, CubeBoxEvalOptions{BaseURL: "http://127.0.0.1:1/v1"}); err == nil {
+		t.Fatal("expected model required for a local provider")
+	}
+}
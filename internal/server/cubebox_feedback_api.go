@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+)
+
+type cubeboxFeedbackStore interface {
+	ListTurnFeedback(ctx context.Context, tenantID string, query cubebox.TurnFeedbackQuery) ([]cubebox.TurnFeedbackRecord, error)
+}
+
+type cubeboxTurnFeedbackRequest struct {
+	TurnID  string   `json:"turn_id"`
+	Rating  string   `json:"rating"`
+	Reasons []string `json:"reasons"`
+	Comment string   `json:"comment"`
+}
+
+// handleCubeBoxConversationFeedbackAPI rates a finished turn of the caller's own conversation by appending
+// a turn.feedback event to it.
+func handleCubeBoxConversationFeedbackAPI(w http.ResponseWriter, r *http.Request, store cubeboxConversationStore) {
+	if r.Method != http.MethodPost {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	conversationID := conversationIDFromFeedbackPath(r.URL.Path)
+	if conversationID == "" {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "conversation_id_required", "conversation id required")
+		return
+	}
+	tenant, principal, ok := cubeboxRequestActor(w, r)
+	if !ok {
+		return
+	}
+	var req cubeboxTurnFeedbackRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+		return
+	}
+	replay, err := store.GetConversation(r.Context(), tenant.ID, principal.ID, conversationID)
+	var feedback cubebox.TurnFeedback
+	if err == nil {
+		feedback, err = cubebox.BuildTurnFeedback(replay, cubebox.TurnFeedbackInput{
+			TurnID:  req.TurnID,
+			Rating:  req.Rating,
+			Reasons: req.Reasons,
+			Comment: req.Comment,
+		})
+	}
+	var event cubebox.CanonicalEvent
+	if err == nil {
+		event = cubebox.CanonicalEvent{
+			EventID:        "evt_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+			ConversationID: conversationID,
+			TurnID:         turnIDPtr(feedback.TurnID),
+			Sequence:       replay.NextSequence,
+			Type:           cubebox.TurnFeedbackEventType,
+			TS:             time.Now().UTC().Format(time.RFC3339),
+			Payload:        feedback.Payload(),
+		}
+		err = store.AppendEvent(r.Context(), tenant.ID, principal.ID, conversationID, event)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, cubebox.ErrTurnFeedbackInvalid):
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_form", "feedback invalid")
+		case errors.Is(err, cubebox.ErrConversationNotFound):
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusNotFound, "cubebox_conversation_not_found", "conversation not found")
+		case errors.Is(err, cubebox.ErrConversationTurnNotFound):
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusNotFound, "cubebox_conversation_turn_not_found", "turn not found")
+		default:
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "cubebox_feedback_failed", "save feedback failed")
+		}
+		return
+	}
+	writeJSON(w, http.StatusCreated, event)
+}
+
+// handleCubeBoxSettingsFeedbackAPI reports rated turns by reason and by operation_id. from and to are
+// inclusive UTC dates; the default window is the last 30 days.
+func handleCubeBoxSettingsFeedbackAPI(w http.ResponseWriter, r *http.Request, store cubeboxFeedbackStore) {
+	if r.Method != http.MethodGet {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	tenant, _, ok := cubeboxRequestActor(w, r)
+	if !ok {
+		return
+	}
+	query, ok := cubeboxTurnFeedbackQuery(w, r)
+	if !ok {
+		return
+	}
+	records, err := store.ListTurnFeedback(r.Context(), tenant.ID, query)
+	if err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "cubebox_feedback_failed", "feedback report failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, cubebox.BuildTurnFeedbackReport(query, records))
+}
+
+// handleCubeBoxSettingsFeedbackExportAPI downloads rated turns as an eval suite file for cubebox-eval. It
+// takes the same window as the report and an optional rating.
+func handleCubeBoxSettingsFeedbackExportAPI(w http.ResponseWriter, r *http.Request, store cubeboxFeedbackStore) {
+	if r.Method != http.MethodGet {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	tenant, _, ok := cubeboxRequestActor(w, r)
+	if !ok {
+		return
+	}
+	query, ok := cubeboxTurnFeedbackQuery(w, r)
+	if !ok {
+		return
+	}
+	records, err := store.ListTurnFeedback(r.Context(), tenant.ID, query)
+	if err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "cubebox_feedback_failed", "feedback export failed")
+		return
+	}
+	name := fmt.Sprintf("cubebox-feedback-%s-%s", query.From.Format("20060102"), query.To.Add(-time.Nanosecond).Format("20060102"))
+	if query.Rating != "" {
+		name += "-" + query.Rating
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".json"))
+	writeJSON(w, http.StatusOK, cubebox.BuildTurnFeedbackEvalSuite(name, records))
+}
+
+func cubeboxTurnFeedbackQuery(w http.ResponseWriter, r *http.Request) (cubebox.TurnFeedbackQuery, bool) {
+	query := cubebox.TurnFeedbackQuery{Rating: strings.TrimSpace(r.URL.Query().Get("rating"))}
+	if raw := strings.TrimSpace(r.URL.Query().Get("from")); raw != "" {
+		from, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_form", "from must be YYYY-MM-DD")
+			return cubebox.TurnFeedbackQuery{}, false
+		}
+		query.From = from
+	}
+	if raw := strings.TrimSpace(r.URL.Query().Get("to")); raw != "" {
+		to, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_form", "to must be YYYY-MM-DD")
+			return cubebox.TurnFeedbackQuery{}, false
+		}
+		query.To = to.AddDate(0, 0, 1)
+	}
+	query, err := cubebox.NormalizeTurnFeedbackQuery(query, time.Now().UTC())
+	if err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_form", "feedback query invalid")
+		return cubebox.TurnFeedbackQuery{}, false
+	}
+	return query, true
+}
+
+func conversationIDFromFeedbackPath(path string) string {
+	return strings.TrimSpace(strings.TrimSuffix(conversationIDFromPath(path), ":feedback"))
+}
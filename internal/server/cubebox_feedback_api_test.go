@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+)
+
+type cubeboxFeedbackStoreStub struct {
+	records []cubebox.TurnFeedbackRecord
+	query   cubebox.TurnFeedbackQuery
+}
+
+func (s *cubeboxFeedbackStoreStub) ListTurnFeedback(_ context.Context, _ string, query cubebox.TurnFeedbackQuery) ([]cubebox.TurnFeedbackRecord, error) {
+	s.query = query
+	return s.records, nil
+}
+
+func TestCubeBoxConversationFeedbackAPIAppendsFeedbackEvent(t *testing.T) {
+	replay := newCubeBoxShareStoreStub().replay
+	replay.NextSequence = 6
+	var appended []cubebox.CanonicalEvent
+	store := cubeboxStoreStub{
+		getFn: func(_ context.Context, _ string, _ string, conversationID string) (cubebox.ConversationReplayResponse, error) {
+			if conversationID != replay.Conversation.ID {
+				return cubebox.ConversationReplayResponse{}, cubebox.ErrConversationNotFound
+			}
+			return replay, nil
+		},
+		appendFn: func(_ context.Context, _ string, _ string, _ string, event cubebox.CanonicalEvent) error {
+			appended = append(appended, event)
+			return nil
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	handleCubeBoxConversationFeedbackAPI(rec, newCubeBoxUsageRequest(http.MethodPost, "/internal/cubebox/conversations/conv_1:feedback", `{"turn_id":"turn_1","rating":"down","reasons":["wrong_entity"],"comment":"不是这个部门"}`), store)
+	if rec.Code != http.StatusCreated || len(appended) != 1 {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	event := appended[0]
+	if event.Type != cubebox.TurnFeedbackEventType || event.Sequence != 6 || *event.TurnID != "turn_1" ||
+		event.Payload["outcome"] != "API_CALLS" || event.Payload["narration"] != "负责人是张三。" {
+		t.Fatalf("event=%+v", event)
+	}
+
+	for _, tc := range []struct {
+		target string
+		body   string
+		status int
+	}{
+		{"/internal/cubebox/conversations/conv_1:feedback", `{"turn_id":"turn_1","rating":"meh"}`, http.StatusUnprocessableEntity},
+		{"/internal/cubebox/conversations/conv_1:feedback", `{"turn_id":"turn_9","rating":"up"}`, http.StatusNotFound},
+		{"/internal/cubebox/conversations/conv_9:feedback", `{"turn_id":"turn_1","rating":"up"}`, http.StatusNotFound},
+	} {
+		rec := httptest.NewRecorder()
+		handleCubeBoxConversationFeedbackAPI(rec, newCubeBoxUsageRequest(http.MethodPost, tc.target, tc.body), store)
+		if rec.Code != tc.status {
+			t.Fatalf("%s %s status=%d body=%s", tc.target, tc.body, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestCubeBoxSettingsFeedbackReportAPI(t *testing.T) {
+	store := &cubeboxFeedbackStoreStub{records: []cubebox.TurnFeedbackRecord{{
+		ConversationID: "conv_1",
+		TurnFeedback: cubebox.TurnFeedback{
+			TurnID:  "turn_1",
+			Rating:  cubebox.TurnFeedbackRatingDown,
+			Reasons: []string{"wrong_data"},
+			Outcome: "API_CALLS",
+			Plan:    []cubebox.QueryEvidenceCall{{Method: "GET", Path: "/org/api/org-units/details", OperationID: "orgunit.details"}},
+		},
+	}}}
+	rec := httptest.NewRecorder()
+	handleCubeBoxSettingsFeedbackAPI(rec, newCubeBoxUsageRequest(http.MethodGet, "/internal/cubebox/settings/feedback?from=2026-10-01&to=2026-10-19", ""), store)
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, `"key":"wrong_data"`) || !strings.Contains(body, `"key":"orgunit.details"`) {
+		t.Fatalf("status=%d body=%s", rec.Code, body)
+	}
+	if store.query.To.Format("2006-01-02") != "2026-10-20" {
+		t.Fatalf("query=%+v", store.query)
+	}
+	for _, target := range []string{"/internal/cubebox/settings/feedback?from=bad", "/internal/cubebox/settings/feedback?rating=meh"} {
+		rec := httptest.NewRecorder()
+		handleCubeBoxSettingsFeedbackAPI(rec, newCubeBoxUsageRequest(http.MethodGet, target, ""), store)
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("%s status=%d body=%s", target, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+// An exported up-rated turn must load as a suite and pass when replayed by the harness it was made for.
+func TestCubeBoxSettingsFeedbackExportAPIReplaysInEvalHarness(t *testing.T) {
+	store := &cubeboxFeedbackStoreStub{records: []cubebox.TurnFeedbackRecord{{
+		ConversationID: "conv_1",
+		TurnFeedback: cubebox.TurnFeedback{
+			TurnID:  "turn_1",
+			TraceID: "trace-1",
+			Rating:  cubebox.TurnFeedbackRatingUp,
+			Reasons: []string{"accurate"},
+			Prompt:  "查 100000 今天的组织详情",
+			Outcome: "API_CALLS",
+			Plan: []cubebox.QueryEvidenceCall{{
+				Method:      "GET",
+				Path:        "/org/api/org-units/details",
+				OperationID: "orgunit.details",
+				Params:      map[string]any{"org_code": "100000", "as_of": "2026-04-25", "include_disabled": false},
+				Entities:    []cubebox.QueryCandidate{{Domain: "orgunit", EntityKey: "100000", Name: "集团总部", AsOf: "2026-04-25"}},
+			}},
+			Narration: "组织 100000 是集团总部，当前状态为启用。",
+		},
+	}}}
+	rec := httptest.NewRecorder()
+	handleCubeBoxSettingsFeedbackExportAPI(rec, newCubeBoxUsageRequest(http.MethodGet, "/internal/cubebox/settings/feedback:export?from=2026-10-01&to=2026-10-19&rating=up", ""), store)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Header().Get("Content-Disposition"), "cubebox-feedback-20261001-20261019-up.json") {
+		t.Fatalf("status=%d headers=%v body=%s", rec.Code, rec.Header(), rec.Body.String())
+	}
+	var suite cubebox.EvalSuite
+	decoder := json.NewDecoder(strings.NewReader(rec.Body.String()))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&suite); err != nil {
+		t.Fatalf("err=%v body=%s", err, rec.Body.String())
+	}
+	if store.query.Rating != "up" || suite.Scenarios[0].Source.TraceID != "trace-1" {
+		t.Fatalf("query=%+v suite=%+v", store.query, suite)
+	}
+	report, err := RunCubeBoxEvalSuite(context.Background(), suite, CubeBoxEvalOptions{})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if report.Metrics.Passed != 1 {
+		t.Fatalf("report=%+v", report.Results)
+	}
+}
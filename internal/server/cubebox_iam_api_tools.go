@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+)
+
+// cubeboxIAMAPIToolDomain executes the iam module's read tools: dictionaries, role definitions and the
+// caller's own capabilities.
+type cubeboxIAMAPIToolDomain struct {
+	dicts   DictStore
+	runtime authzRuntimeStore
+}
+
+func newCubeboxIAMAPIToolDomain(dicts DictStore, runtime authzRuntimeStore) *cubeboxIAMAPIToolDomain {
+	if dicts == nil || runtime == nil {
+		return nil
+	}
+	return &cubeboxIAMAPIToolDomain{dicts: dicts, runtime: runtime}
+}
+
+func (d *cubeboxIAMAPIToolDomain) Module() string {
+	return "iam"
+}
+
+func (d *cubeboxIAMAPIToolDomain) KnowledgePackDir() string {
+	return mustResolveRepoPath(filepath.Join("modules", "iam", "presentation", "cubebox"))
+}
+
+func (d *cubeboxIAMAPIToolDomain) ExecuteCall(ctx context.Context, request cubebox.ExecuteRequest, tool cubebox.APITool, call cubebox.APICallStep) (cubebox.ExecuteResult, error) {
+	allowed, err := authorizeAPIToolCall(ctx, d.runtime, d.Module(), request, tool)
+	if err != nil {
+		return cubebox.ExecuteResult{}, err
+	}
+	if !allowed {
+		return cubebox.ExecuteResult{}, &iamAPIToolForbiddenError{}
+	}
+
+	httpReq, err := buildAPIToolHTTPRequest(ctx, request, tool, call)
+	if err != nil {
+		return cubebox.ExecuteResult{}, err
+	}
+	rec := httptest.NewRecorder()
+	switch tool.Path {
+	case "/iam/api/dicts":
+		handleDictsAPI(rec, httpReq, d.dicts)
+	case "/iam/api/dicts/values":
+		handleDictValuesAPI(rec, httpReq, d.dicts)
+	case "/iam/api/authz/roles":
+		handleAuthzRolesAPI(rec, httpReq, d.runtime)
+	case "/iam/api/me/capabilities":
+		handleSessionCapabilitiesAPI(rec, httpReq, d.runtime)
+	default:
+		return cubebox.ExecuteResult{}, cubebox.ErrAPICatalogDriftOrExecutorMissing
+	}
+	if rec.Code < 200 || rec.Code >= 300 {
+		return cubebox.ExecuteResult{}, iamAPIToolHTTPError(rec.Code, rec.Body.String())
+	}
+	payload := map[string]any{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		return cubebox.ExecuteResult{}, err
+	}
+	return projectAPIToolResult(tool, payload), nil
+}
+
+func iamAPIToolHTTPError(status int, body string) error {
+	switch status {
+	case http.StatusForbidden, http.StatusUnauthorized:
+		return &iamAPIToolForbiddenError{}
+	case http.StatusNotFound:
+		return &iamAPIToolNotFoundError{}
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return newBadRequestError(body)
+	default:
+		return apiToolHTTPStatusError{status: status, body: body}
+	}
+}
+
+type iamAPIToolForbiddenError struct{}
+
+func (e *iamAPIToolForbiddenError) Error() string {
+	return "forbidden"
+}
+
+func (e *iamAPIToolForbiddenError) QueryTerminalError() *cubebox.ExecutionTerminalError {
+	return &cubebox.ExecutionTerminalError{
+		Code:      "forbidden",
+		Message:   "当前账号没有查看该数据的权限。",
+		Retryable: false,
+	}
+}
+
+type iamAPIToolNotFoundError struct{}
+
+func (e *iamAPIToolNotFoundError) Error() string {
+	return "not_found"
+}
+
+func (e *iamAPIToolNotFoundError) QueryTerminalError() *cubebox.ExecutionTerminalError {
+	return &cubebox.ExecutionTerminalError{
+		Code:      "not_found",
+		Message:   "未找到对应的字典或角色，请检查编码后重试。",
+		Retryable: false,
+	}
+}
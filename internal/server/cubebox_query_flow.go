@@ -135,6 +135,8 @@ type cubeboxQueryLifecycle struct {
 	modelSlug    string
 	runtime      string
 	startedAt    time.Time
+	usage        *cubebox.UsageMeter
+	redactor     *cubebox.Redactor
 }
 
 type cubeboxQueryNarrationEnvelope struct {
@@ -448,6 +450,7 @@ func (f *cubeboxQueryFlow) TryHandle(
 	}
 
 	var finalResults []cubebox.ExecuteResult
+	var executedResults []cubebox.ExecuteResult
 	for {
 		switch outcome.Type {
 		case cubebox.PlannerOutcomeAPICalls:
@@ -483,11 +486,19 @@ func (f *cubeboxQueryFlow) TryHandle(
 				f.writeQueryTerminalError(ctx, request, prepared.turn.TurnID, &prepared.sequence, prepared.lifecycle, sink, queryLoopBudgetExceededTerminal())
 				return true
 			}
-			results, err := f.runner.ExecutePlan(ctx, cubebox.ExecuteRequest{
+			execCtx, cancelExec := prepared.turn.WithInterrupt(ctx)
+			results, err := f.runner.ExecutePlan(execCtx, cubebox.ExecuteRequest{
 				TenantID:       request.TenantID,
 				PrincipalID:    request.PrincipalID,
 				ConversationID: request.ConversationID,
 			}, plan)
+			interrupted := ctx.Err() == nil && execCtx.Err() != nil
+			cancelExec()
+			if interrupted {
+				_ = writeEvent("turn.interrupted", f.queryInterruptedPayload("user_requested", prepared.lifecycle))
+				_ = writeEvent("turn.completed", f.queryCompletedPayload("interrupted", prepared.lifecycle))
+				return true
+			}
 			if err != nil {
 				if f.writeQueryExecutionClarification(ctx, request, prepared, queryContext, produced, err, writeEvent) {
 					return true
@@ -497,6 +508,7 @@ func (f *cubeboxQueryFlow) TryHandle(
 			}
 			workingState.AppendPlan(workingState.Snapshot().RoundIndex, plan, results)
 			finalResults = results
+			executedResults = append(executedResults, results...)
 			if !workingState.CanPlan() {
 				f.writeQueryTerminalError(ctx, request, prepared.turn.TurnID, &prepared.sequence, prepared.lifecycle, sink, queryLoopBudgetExceededTerminal())
 				return true
@@ -532,6 +544,7 @@ func (f *cubeboxQueryFlow) TryHandle(
 				return true
 			}
 			f.writeQueryResultMetadata(ctx, request, prepared.turn.TurnID, &prepared.sequence, finalResults)
+			f.appendQueryMetadataEvent(ctx, request, prepared.turn.TurnID, &prepared.sequence, cubebox.QueryEvidenceRecordedEventType, cubebox.QueryEvidencePayload(cubebox.QueryEvidenceCallsFromResults(executedResults)))
 			if !writeEvent("turn.agent_message.delta", map[string]any{"message_id": prepared.turn.AssistantMessageID, "delta": answer}) {
 				return true
 			}
@@ -581,12 +594,22 @@ func (p *cubeboxProviderAPIPlanProducer) ProduceAPIPlan(
 		return cubeboxAPIPlanProductionResult{}, err
 	}
 
+	// Models that declare tool_calls get the catalog as native functions; the JSON envelope prompt stays
+	// in place so a plain-text answer is still decoded the old way.
+	messages := p.buildPlannerMessages(input)
+	var tools []cubebox.ProviderTool
+	if cubebox.SupportsNativeToolCalling(config.Selection.CapabilitySummary) && len(input.APITools) > 0 {
+		tools = cubebox.PlannerFunctionTools(input.APITools)
+		last := len(messages) - 1
+		messages = append(messages[:last:last], cubebox.PromptItem{Role: "system", Content: plannerNativeToolCallingPromptBlock}, messages[last])
+	}
 	stream, err := p.adapter.StreamChatCompletion(ctx, cubebox.ProviderChatRequest{
 		BaseURL:  strings.TrimSpace(config.Provider.BaseURL),
 		APIKey:   secret,
 		Model:    modelSlug,
-		Messages: p.buildPlannerMessages(input),
+		Messages: messages,
 		Input:    input.Prompt,
+		Tools:    tools,
 	})
 	if err != nil {
 		return cubeboxAPIPlanProductionResult{}, err
@@ -594,6 +617,7 @@ func (p *cubeboxProviderAPIPlanProducer) ProduceAPIPlan(
 	defer func() { _ = stream.Close() }()
 
 	var out strings.Builder
+	var toolCalls cubebox.ProviderToolCallBuffer
 	for {
 		chunk, err := stream.Recv()
 		if err != nil {
@@ -603,12 +627,17 @@ func (p *cubeboxProviderAPIPlanProducer) ProduceAPIPlan(
 			return cubeboxAPIPlanProductionResult{}, err
 		}
 		out.WriteString(chunk.Delta)
+		toolCalls.Add(chunk.ToolCalls)
 		if chunk.Done {
 			break
 		}
 	}
-	raw := strings.TrimSpace(out.String())
-	outcome, err := cubebox.DecodePlannerOutcome([]byte(raw))
+	var outcome cubebox.PlannerOutcome
+	if toolCalls.Len() > 0 {
+		outcome, err = cubebox.DecodePlannerToolCalls(toolCalls.Calls(), input.APITools)
+	} else {
+		outcome, err = cubebox.DecodePlannerOutcome([]byte(strings.TrimSpace(out.String())))
+	}
 	if err != nil {
 		return cubeboxAPIPlanProductionResult{}, err
 	}
@@ -641,7 +670,8 @@ func (p *cubeboxProviderAPIPlanProducer) buildPlannerMessages(input cubeboxAPIPl
 	- 不要输出解释、前后缀或额外文本
 	- 只允许生成只读查询计划
 	- 只允许输出 JSON envelope：{"outcome":"API_CALLS","calls":[...]}、{"outcome":"CLARIFY","missing_params":[...],"clarifying_question":"..."}、{"outcome":"DONE"}、{"outcome":"NO_QUERY"}
-	- API_CALLS.calls 必须符合线性多步只读编排约束，且每次只规划当前最小必要查询
+	- API_CALLS.calls 构成只读依赖 DAG：每个 call 的 depends_on 只能引用同一计划中更早声明的 call id，不得引用自身或成环；互不依赖的 call 将并发执行，其 depends_on 为 []；每次只规划当前最小必要查询
+	- calls[].params 在规划时即确定，depends_on 只约束执行先后，不会把前一个 call 的结果填入后一个 call；需要用前一步结果作参数的查询，留到下一轮根据 working_results 规划
 	- calls[].method/path 必须来自 api_tools；calls[].params 只能包含对应 tool 的 request_schema 参数
 	- CLARIFY 表示缺少必要参数或无法稳定判断引用对象，必须给出 missing_params 与 clarifying_question
 	- DONE 表示当前 working_results 已足够进入最终回答；不要用 NO_QUERY 表示“已经查够”
@@ -724,7 +754,7 @@ func buildQueryEvidenceWindowPromptBlock(queryContext cubebox.QueryContext, curr
 	- open_clarification.reply_candidate=true 表示当前输入可能在回答上一轮澄清；不要因为输入短就抢先输出 NO_QUERY。
 	- observations.kind=entity_fact 只表示先前工具结果曾产生某个实体事实，不是当前轮 winner。
 	- observations.kind=presented_options 只表示先前给用户展示过一组选项；用户说“第一个/第二个/以上/全部/这些/都要/不是这个/另一个”时，由模型结合 recent_turns 和当前输入自行判断。
-- observations.kind=result_list 表示上一轮已经成功返回过一组明确结果；若当前轮要求“补充字段/增加列/列出路径”，可将该组 entity_key 作为当前 target set，并在规模可控时生成线性 API_CALLS 逐个补查详情字段。
+- observations.kind=result_list 表示上一轮已经成功返回过一组明确结果；若当前轮要求“补充字段/增加列/列出路径”，可将该组 entity_key 作为当前 target set，并在规模可控时生成一组互不依赖（depends_on 为 []）、可并发执行的 API_CALLS 逐个补查详情字段。
 - 如果目标明确，输出显式 API call 参数。
 - 如果缺少执行所需事实，由模型生成澄清问题。
 - 如果已有 working_results 足够，由模型输出 DONE。
@@ -768,6 +798,11 @@ func queryPromptOverridesHistoricalScope(prompt string) bool {
 	return strings.Contains(text, "不是") && strings.Contains(text, "而是")
 }
 
+const plannerNativeToolCallingPromptBlock = `本次请求提供了原生函数：
+- 需要查询时，直接调用与 api_tools 对应的函数，参数即 request_schema 参数；可同时调用多个互不依赖的函数。
+- 需要追问时调用 cubebox_clarify；已查够时调用 cubebox_done；超出查询域时调用 cubebox_no_query。这三个函数只能单独调用。
+- 调用函数时不要再输出 JSON envelope。`
+
 func buildAPIToolCatalogPromptBlock(entries []cubebox.APITool) string {
 	if len(entries) == 0 {
 		return ""
@@ -1149,10 +1184,19 @@ func (f *cubeboxQueryFlow) queryErrorPayload(code string, message string, retrya
 	return payload
 }
 
+func (f *cubeboxQueryFlow) queryInterruptedPayload(reason string, lifecycle cubeboxQueryLifecycle) map[string]any {
+	payload := f.queryLifecyclePayload(lifecycle)
+	payload["reason"] = reason
+	payload["latency_ms"] = f.queryLatencyMS(lifecycle)
+	return payload
+}
+
 func (f *cubeboxQueryFlow) queryCompletedPayload(status string, lifecycle cubeboxQueryLifecycle) map[string]any {
 	payload := f.queryLifecyclePayload(lifecycle)
 	payload["status"] = status
 	payload["latency_ms"] = f.queryLatencyMS(lifecycle)
+	lifecycle.usage.AnnotatePayload(payload)
+	lifecycle.redactor.AnnotatePayload(payload)
 	return payload
 }
 
@@ -1816,6 +1860,7 @@ func buildDefaultCubeboxQueryFlow(
 	runtime *cubebox.Runtime,
 	store cubeboxTurnStore,
 	orgStore OrgUnitStore,
+	dictStore DictStore,
 	authzRuntime authzRuntimeStore,
 	producer cubeboxAPIPlanProducer,
 	narrator cubeboxQueryNarrator,
@@ -1823,17 +1868,27 @@ func buildDefaultCubeboxQueryFlow(
 	if runtime == nil || store == nil || orgStore == nil || producer == nil || narrator == nil {
 		return nil, nil
 	}
-	runner, err := newCubeboxOrgUnitAPIToolRunner(orgStore, authzRuntime)
+	domains := []cubeboxAPIToolDomain{}
+	if domain := newCubeboxOrgUnitAPIToolDomain(orgStore, authzRuntime); domain != nil {
+		domains = append(domains, domain)
+	}
+	if domain := newCubeboxIAMAPIToolDomain(dictStore, authzRuntime); domain != nil {
+		domains = append(domains, domain)
+	}
+	if len(domains) == 0 {
+		return nil, nil
+	}
+	registry, err := newCubeboxAPIToolRegistry(domains...)
 	if err != nil {
 		return nil, err
 	}
 	return newCubeboxQueryFlow(
 		runtime,
 		store,
-		runner,
+		registry,
 		producer,
 		narrator,
-		[]string{mustResolveRepoPath(filepath.Join("modules", "orgunit", "presentation", "cubebox"))},
+		registry.KnowledgePackDirs(),
 	)
 }
 
@@ -1849,6 +1904,8 @@ func (f *cubeboxQueryFlow) prepareQueryTurn(
 		modelSlug:    strings.TrimSpace(produced.ModelSlug),
 		runtime:      "cubebox-query-api-calls",
 		startedAt:    f.clockNow(),
+		usage:        cubebox.UsageMeterFromContext(ctx),
+		redactor:     cubebox.RedactorFromContext(ctx),
 	}
 	canonicalContext := f.buildQueryCanonicalContext(request, lifecycle)
 	prepared, err := cubebox.PrepareTurnStream(ctx, f.store, request, canonicalContext)
@@ -1860,6 +1917,8 @@ func (f *cubeboxQueryFlow) prepareQueryTurn(
 		PrincipalID:    request.PrincipalID,
 		ConversationID: request.ConversationID,
 	}, request.Prompt, prepared.TurnIDs)
+	// Planner rounds run before the turn exists; binding here attributes them to it too.
+	lifecycle.usage.Bind(turn.TurnID, lifecycle.providerID)
 	return cubeboxPreparedQueryTurn{
 		turn:      turn,
 		lifecycle: lifecycle,
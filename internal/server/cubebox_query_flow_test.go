@@ -963,6 +963,56 @@ func TestCubeboxProviderAPIPlanProducerRejectsBareDone(t *testing.T) {
 	}
 }
 
+func TestCubeboxProviderAPIPlanProducerUsesNativeToolCallsWhenModelSupportsThem(t *testing.T) {
+	newProducer := func(capability map[string]any, chunks []cubebox.ProviderChatChunk) (*cubeboxProviderAPIPlanProducer, *cubeboxProviderAdapterStub) {
+		adapter := &cubeboxProviderAdapterStub{stream: &cubeboxProviderChatStreamTextStub{chunks: chunks}}
+		return &cubeboxProviderAPIPlanProducer{
+			configReader: cubeboxRuntimeConfigReaderStub{config: cubebox.ActiveModelRuntimeConfig{
+				Provider:   cubebox.ModelProvider{ID: "provider-a", ProviderType: "openai-compatible", BaseURL: "https://example.com", Enabled: true},
+				Selection:  cubebox.ActiveModelSelection{ModelSlug: "gpt-5.2", CapabilitySummary: capability},
+				Credential: cubebox.ModelCredential{SecretRef: "env://OPENAI_API_KEY"},
+			}},
+			adapter:        adapter,
+			secretResolver: cubeboxSecretResolverStub{secret: "sk-test"},
+			now:            func() time.Time { return time.Date(2026, 4, 25, 12, 0, 0, 0, time.UTC) },
+		}, adapter
+	}
+	input := cubeboxAPIPlanProductionInput{TenantID: "tenant-a", Prompt: "查 100000 的详情", APITools: testCubeBoxAPITools()}
+
+	producer, adapter := newProducer(map[string]any{"streaming": true, "tool_calls": true}, []cubebox.ProviderChatChunk{
+		{ToolCalls: []cubebox.ProviderToolCallDelta{{Index: 0, ID: "call_1", Name: "orgunit_details", Arguments: `{"org_code":"100`}}},
+		{ToolCalls: []cubebox.ProviderToolCallDelta{{Index: 0, Arguments: `000","as_of":"2026-04-25"}`}}},
+		{Done: true},
+	})
+	result, err := producer.ProduceAPIPlan(context.Background(), input)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if !result.Handled || result.Outcome.Type != cubebox.PlannerOutcomeAPICalls || len(result.Plan.Calls) != 1 ||
+		result.Plan.Calls[0].Path != "/org/api/org-units/details" || result.Plan.Calls[0].Params["org_code"] != "100000" {
+		t.Fatalf("result=%+v", result)
+	}
+	request := adapter.lastRequest
+	if len(request.Tools) != len(input.APITools)+3 || request.Tools[1].Name != "orgunit_details" {
+		t.Fatalf("tools=%+v", request.Tools)
+	}
+	if last := request.Messages[len(request.Messages)-1]; last.Role != "user" || !strings.Contains(request.Messages[len(request.Messages)-2].Content, "cubebox_clarify") {
+		t.Fatalf("messages=%+v", request.Messages)
+	}
+
+	// Without the capability, and when a tool-calling model answers in text, the JSON envelope still decodes.
+	for _, capability := range []map[string]any{{"streaming": true, "tool_calls": false}, {"tool_calls": true}} {
+		producer, adapter := newProducer(capability, []cubebox.ProviderChatChunk{{Delta: `{"outcome":"DONE"}`}, {Done: true}})
+		result, err := producer.ProduceAPIPlan(context.Background(), input)
+		if err != nil || result.Outcome.Type != cubebox.PlannerOutcomeDone {
+			t.Fatalf("capability=%v result=%+v err=%v", capability, result, err)
+		}
+		if native := len(adapter.lastRequest.Tools) > 0; native != cubebox.SupportsNativeToolCalling(capability) {
+			t.Fatalf("capability=%v tools=%d", capability, len(adapter.lastRequest.Tools))
+		}
+	}
+}
+
 func TestCubeboxProviderQueryNarratorNarratesNoQueryGuidance(t *testing.T) {
 	adapter := &cubeboxProviderAdapterStub{
 		stream: &cubeboxProviderChatStreamTextStub{
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+)
+
+const cubeboxRedactionPreviewMaxBytes = 64 << 10
+
+type cubeboxRedactionStore interface {
+	GetRedactionPolicy(ctx context.Context, tenantID string) (cubebox.RedactionPolicy, error)
+	UpdateRedactionPolicy(ctx context.Context, tenantID string, principalID string, input cubebox.RedactionPolicy) (cubebox.RedactionPolicy, error)
+	ListRedactionPolicyAudit(ctx context.Context, tenantID string) ([]cubebox.RedactionPolicyAuditEntry, error)
+}
+
+type cubeboxRedactionPolicyRequest struct {
+	Enabled   bool     `json:"enabled"`
+	Kinds     []string `json:"kinds"`
+	ExtFields []string `json:"ext_fields"`
+}
+
+type cubeboxRedactionPolicyResponse struct {
+	Policy cubebox.RedactionPolicy             `json:"policy"`
+	Audit  []cubebox.RedactionPolicyAuditEntry `json:"audit"`
+}
+
+// cubeboxRedactionPreviewRequest previews text against the stored policy, or against Policy when given so
+// that a change can be tried before it is saved.
+type cubeboxRedactionPreviewRequest struct {
+	Text   string                         `json:"text"`
+	Policy *cubeboxRedactionPolicyRequest `json:"policy,omitempty"`
+}
+
+func handleCubeBoxSettingsRedactionAPI(w http.ResponseWriter, r *http.Request, store cubeboxRedactionStore) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	tenant, principal, ok := cubeboxRequestActor(w, r)
+	if !ok {
+		return
+	}
+	if r.Method == http.MethodPost {
+		var req cubeboxRedactionPolicyRequest
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&req); err != nil {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+			return
+		}
+		if _, err := store.UpdateRedactionPolicy(r.Context(), tenant.ID, principal.ID, req.policy()); err != nil {
+			if errors.Is(err, cubebox.ErrRedactionPolicyInvalid) {
+				routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_form", "redaction policy invalid")
+				return
+			}
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "ai_model_config_invalid", "redaction policy save failed")
+			return
+		}
+	}
+	policy, err := store.GetRedactionPolicy(r.Context(), tenant.ID)
+	if err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "ai_model_config_invalid", "redaction policy load failed")
+		return
+	}
+	audit, err := store.ListRedactionPolicyAudit(r.Context(), tenant.ID)
+	if err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "ai_model_config_invalid", "redaction audit load failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, cubeboxRedactionPolicyResponse{Policy: policy, Audit: audit})
+}
+
+// handleCubeBoxSettingsRedactionPreviewAPI is a dry run: it shows what a provider would receive for text,
+// without a turn and without touching any conversation.
+func handleCubeBoxSettingsRedactionPreviewAPI(w http.ResponseWriter, r *http.Request, store cubeboxRedactionStore) {
+	if r.Method != http.MethodPost {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	tenant, _, ok := cubeboxRequestActor(w, r)
+	if !ok {
+		return
+	}
+	var req cubeboxRedactionPreviewRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, cubeboxRedactionPreviewMaxBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+		return
+	}
+	if strings.TrimSpace(req.Text) == "" {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_form", "text required")
+		return
+	}
+	var policy cubebox.RedactionPolicy
+	if req.Policy != nil {
+		normalized, err := cubebox.NormalizeRedactionPolicy(req.Policy.policy())
+		if err != nil {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_form", "redaction policy invalid")
+			return
+		}
+		policy = normalized
+	} else {
+		stored, err := store.GetRedactionPolicy(r.Context(), tenant.ID)
+		if err != nil {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "ai_model_config_invalid", "redaction policy load failed")
+			return
+		}
+		policy = stored
+	}
+	// The preview redactor has no request identity, so only values found in the text itself are listed.
+	writeJSON(w, http.StatusOK, cubebox.NewRedactor(policy, cubebox.GatewayStreamRequest{}).Preview(req.Text))
+}
+
+func (req cubeboxRedactionPolicyRequest) policy() cubebox.RedactionPolicy {
+	return cubebox.RedactionPolicy{Enabled: req.Enabled, Kinds: req.Kinds, ExtFields: req.ExtFields}
+}
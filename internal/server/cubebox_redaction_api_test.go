@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+)
+
+type cubeboxRedactionStoreStub struct {
+	policy  cubebox.RedactionPolicy
+	updated *cubebox.RedactionPolicy
+}
+
+func (s *cubeboxRedactionStoreStub) GetRedactionPolicy(context.Context, string) (cubebox.RedactionPolicy, error) {
+	return s.policy, nil
+}
+
+func (s *cubeboxRedactionStoreStub) UpdateRedactionPolicy(_ context.Context, _ string, principalID string, input cubebox.RedactionPolicy) (cubebox.RedactionPolicy, error) {
+	policy, err := cubebox.NormalizeRedactionPolicy(input)
+	if err != nil {
+		return cubebox.RedactionPolicy{}, err
+	}
+	policy.UpdatedBy = principalID
+	s.policy = policy
+	s.updated = &policy
+	return policy, nil
+}
+
+func (s *cubeboxRedactionStoreStub) ListRedactionPolicyAudit(context.Context, string) ([]cubebox.RedactionPolicyAuditEntry, error) {
+	if s.updated == nil {
+		return []cubebox.RedactionPolicyAuditEntry{}, nil
+	}
+	return []cubebox.RedactionPolicyAuditEntry{{ID: 1, ChangedBy: s.updated.UpdatedBy, Policy: *s.updated}}, nil
+}
+
+func TestCubeBoxSettingsRedactionAPI(t *testing.T) {
+	store := &cubeboxRedactionStoreStub{policy: cubebox.DefaultRedactionPolicy()}
+
+	rec := httptest.NewRecorder()
+	handleCubeBoxSettingsRedactionAPI(rec, newCubeBoxUsageRequest(http.MethodGet, "/internal/cubebox/settings/redaction", ""), store)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"kinds":["email","identity","pernr","phone"]`) || !strings.Contains(rec.Body.String(), `"audit":[]`) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handleCubeBoxSettingsRedactionAPI(rec, newCubeBoxUsageRequest(http.MethodPost, "/internal/cubebox/settings/redaction", `{"enabled":true,"kinds":["pernr"],"ext_fields":["x_cost_center"]}`), store)
+	if rec.Code != http.StatusOK || store.updated == nil || store.updated.UpdatedBy != "p1" || !strings.Contains(rec.Body.String(), `"changed_by":"p1"`) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handleCubeBoxSettingsRedactionAPI(rec, newCubeBoxUsageRequest(http.MethodPost, "/internal/cubebox/settings/redaction", `{"enabled":true,"kinds":["salary"]}`), store)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "invalid_form") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCubeBoxSettingsRedactionPreviewAPI(t *testing.T) {
+	store := &cubeboxRedactionStoreStub{policy: cubebox.DefaultRedactionPolicy()}
+
+	rec := httptest.NewRecorder()
+	handleCubeBoxSettingsRedactionPreviewAPI(rec, newCubeBoxUsageRequest(http.MethodPost, "/internal/cubebox/settings/redaction:preview", `{"text":"{\"manager_pernr\":\"100023\"} 邮箱 a.b@example.com"}`), store)
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, `[[PERNR_1]]`) || !strings.Contains(body, `"kind":"email"`) || strings.Contains(body, `"text":"{\"manager_pernr\":\"100023\"}`) {
+		t.Fatalf("status=%d body=%s", rec.Code, body)
+	}
+
+	// A policy in the request is previewed instead of the stored one.
+	rec = httptest.NewRecorder()
+	handleCubeBoxSettingsRedactionPreviewAPI(rec, newCubeBoxUsageRequest(http.MethodPost, "/internal/cubebox/settings/redaction:preview", `{"text":"邮箱 a.b@example.com","policy":{"enabled":true,"kinds":["phone"]}}`), store)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"text":"邮箱 a.b@example.com"`) || !strings.Contains(rec.Body.String(), `"replacements":[]`) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handleCubeBoxSettingsRedactionPreviewAPI(rec, newCubeBoxUsageRequest(http.MethodPost, "/internal/cubebox/settings/redaction:preview", `{"text":"  "}`), store)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+)
+
+type cubeboxRetentionStore interface {
+	GetRetentionPolicy(ctx context.Context, tenantID string) (cubebox.RetentionPolicy, error)
+	UpsertRetentionPolicy(ctx context.Context, tenantID string, principalID string, input cubebox.RetentionPolicy) (cubebox.RetentionPolicy, error)
+	ListRetentionRuns(ctx context.Context, tenantID string) ([]cubebox.RetentionRun, error)
+}
+
+type cubeboxRetentionPolicyRequest struct {
+	ArchivedRetentionDays int `json:"archived_retention_days"`
+}
+
+type cubeboxRetentionPolicyResponse struct {
+	Policy cubebox.RetentionPolicy `json:"policy"`
+	Runs   []cubebox.RetentionRun  `json:"runs"`
+}
+
+func handleCubeBoxSettingsRetentionAPI(w http.ResponseWriter, r *http.Request, store cubeboxRetentionStore) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	tenant, principal, ok := cubeboxRequestActor(w, r)
+	if !ok {
+		return
+	}
+	if r.Method == http.MethodPost {
+		var req cubeboxRetentionPolicyRequest
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&req); err != nil {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+			return
+		}
+		if _, err := store.UpsertRetentionPolicy(r.Context(), tenant.ID, principal.ID, cubebox.RetentionPolicy{ArchivedRetentionDays: req.ArchivedRetentionDays}); err != nil {
+			if errors.Is(err, cubebox.ErrRetentionPolicyInvalid) {
+				routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_form", "retention policy invalid")
+				return
+			}
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "ai_model_config_invalid", "retention policy save failed")
+			return
+		}
+	}
+	policy, err := store.GetRetentionPolicy(r.Context(), tenant.ID)
+	if err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "ai_model_config_invalid", "retention policy load failed")
+		return
+	}
+	runs, err := store.ListRetentionRuns(r.Context(), tenant.ID)
+	if err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "ai_model_config_invalid", "retention runs load failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, cubeboxRetentionPolicyResponse{Policy: policy, Runs: runs})
+}
+
+// startCubeBoxRetentionWorker purges expired archived conversations in the background. It needs the real
+// pool; the in-memory dev server keeps no conversations worth purging.
+func startCubeBoxRetentionWorker(ctx context.Context, store *cubebox.Store, hasPool bool) {
+	if !hasPool {
+		return
+	}
+	worker := &cubebox.RetentionWorker{
+		Purger:   store,
+		Interval: cubeboxRetentionIntervalFromEnv(),
+	}
+	go func() { _ = worker.Run(ctx) }()
+}
+
+func cubeboxRetentionIntervalFromEnv() time.Duration {
+	const defaultMinutes = 60
+
+	n, err := strconv.Atoi(os.Getenv("CUBEBOX_RETENTION_INTERVAL_MINUTES"))
+	if err != nil || n <= 0 {
+		return time.Minute * defaultMinutes
+	}
+	return time.Minute * time.Duration(n)
+}
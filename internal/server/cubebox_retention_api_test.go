@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+)
+
+type cubeboxRetentionStoreStub struct {
+	policy cubebox.RetentionPolicy
+	runs   []cubebox.RetentionRun
+}
+
+func (s *cubeboxRetentionStoreStub) GetRetentionPolicy(context.Context, string) (cubebox.RetentionPolicy, error) {
+	return s.policy, nil
+}
+
+func (s *cubeboxRetentionStoreStub) UpsertRetentionPolicy(_ context.Context, _ string, principalID string, input cubebox.RetentionPolicy) (cubebox.RetentionPolicy, error) {
+	if err := cubebox.ValidateRetentionPolicy(input); err != nil {
+		return cubebox.RetentionPolicy{}, err
+	}
+	s.policy = cubebox.RetentionPolicy{ArchivedRetentionDays: input.ArchivedRetentionDays, UpdatedBy: principalID}
+	return s.policy, nil
+}
+
+func (s *cubeboxRetentionStoreStub) ListRetentionRuns(context.Context, string) ([]cubebox.RetentionRun, error) {
+	return s.runs, nil
+}
+
+func TestCubeBoxSettingsRetentionAPI(t *testing.T) {
+	store := &cubeboxRetentionStoreStub{runs: []cubebox.RetentionRun{{ID: 3, TenantID: "t1", PurgedConversations: 4, PurgedEvents: 40}}}
+
+	rec := httptest.NewRecorder()
+	handleCubeBoxSettingsRetentionAPI(rec, newCubeBoxUsageRequest(http.MethodGet, "/internal/cubebox/settings/retention", ""), store)
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, `"archived_retention_days":0`) || !strings.Contains(body, `"purged_events":40`) || strings.Contains(body, `"t1"`) {
+		t.Fatalf("status=%d body=%s", rec.Code, body)
+	}
+
+	rec = httptest.NewRecorder()
+	handleCubeBoxSettingsRetentionAPI(rec, newCubeBoxUsageRequest(http.MethodPost, "/internal/cubebox/settings/retention", `{"archived_retention_days":90}`), store)
+	if rec.Code != http.StatusOK || store.policy.ArchivedRetentionDays != 90 || store.policy.UpdatedBy != "p1" {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handleCubeBoxSettingsRetentionAPI(rec, newCubeBoxUsageRequest(http.MethodPost, "/internal/cubebox/settings/retention", `{"archived_retention_days":-1}`), store)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "invalid_form") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,281 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/authz"
+)
+
+type cubeboxShareStore interface {
+	GetConversation(ctx context.Context, tenantID string, principalID string, conversationID string) (cubebox.ConversationReplayResponse, error)
+	CreateConversationShare(ctx context.Context, tenantID string, ownerID string, input cubebox.ConversationShareInput) (cubebox.ConversationShare, error)
+	ListConversationShares(ctx context.Context, tenantID string, ownerID string) ([]cubebox.ConversationShare, error)
+	GetConversationShare(ctx context.Context, tenantID string, shareID string) (cubebox.ConversationShare, error)
+	RevokeConversationShare(ctx context.Context, tenantID string, ownerID string, shareID string) (cubebox.ConversationShare, error)
+}
+
+type cubeboxShareCreateRequest struct {
+	ConversationID string   `json:"conversation_id"`
+	TurnID         string   `json:"turn_id"`
+	PrincipalIDs   []string `json:"principal_ids"`
+	ExpiresInDays  int      `json:"expires_in_days"`
+}
+
+type cubeboxShareListResponse struct {
+	Items []cubebox.ConversationShare `json:"items"`
+}
+
+// cubeboxSharedConversationResponse is what a share viewer receives. The share's principal list is left out:
+// a viewer learns who shared it, not who else it was shared with.
+type cubeboxSharedConversationResponse struct {
+	ShareID   string                     `json:"share_id"`
+	SharedBy  string                     `json:"shared_by"`
+	ExpiresAt string                     `json:"expires_at"`
+	Export    cubebox.ConversationExport `json:"export"`
+}
+
+// handleCubeBoxConversationExportAPI exports the caller's own conversation, or one turn of it, as JSON or
+// Markdown. Evidence is re-checked against the caller's current org scopes like a share is.
+func handleCubeBoxConversationExportAPI(w http.ResponseWriter, r *http.Request, store cubeboxConversationStore, scope orgUnitScopeDeps) {
+	if r.Method != http.MethodGet {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	conversationID := conversationIDFromExportPath(r.URL.Path)
+	if conversationID == "" {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "conversation_id_required", "conversation id required")
+		return
+	}
+	format, ok := cubeboxExportFormat(w, r)
+	if !ok {
+		return
+	}
+	tenant, principal, ok := cubeboxRequestActor(w, r)
+	if !ok {
+		return
+	}
+	replay, err := store.GetConversation(r.Context(), tenant.ID, principal.ID, conversationID)
+	if err != nil {
+		if errors.Is(err, cubebox.ErrConversationNotFound) {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusNotFound, "cubebox_conversation_not_found", "conversation not found")
+			return
+		}
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "cubebox_conversation_read_failed", "read conversation failed")
+		return
+	}
+	export, err := cubebox.BuildConversationExport(replay, r.URL.Query().Get("turn_id"))
+	if err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusNotFound, "cubebox_conversation_turn_not_found", "turn not found")
+		return
+	}
+	export = export.FilterEvidence(cubeboxEvidenceCallAuthorizer(r.Context(), scope.runtime, tenant.ID, principal.ID, principal.ID), cubeboxEvidenceScopeFilter(r.Context(), scope, tenant.ID))
+	if format == "markdown" {
+		writeCubeBoxExportMarkdown(w, conversationID, export)
+		return
+	}
+	writeJSON(w, http.StatusOK, export)
+}
+
+func handleCubeBoxSharesAPI(w http.ResponseWriter, r *http.Request, store cubeboxShareStore) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	tenant, principal, ok := cubeboxRequestActor(w, r)
+	if !ok {
+		return
+	}
+	if r.Method == http.MethodGet {
+		items, err := store.ListConversationShares(r.Context(), tenant.ID, principal.ID)
+		if err != nil {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "cubebox_share_failed", "list shares failed")
+			return
+		}
+		writeJSON(w, http.StatusOK, cubeboxShareListResponse{Items: items})
+		return
+	}
+	var req cubeboxShareCreateRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+		return
+	}
+	share, err := store.CreateConversationShare(r.Context(), tenant.ID, principal.ID, cubebox.ConversationShareInput{
+		ConversationID: req.ConversationID,
+		TurnID:         req.TurnID,
+		PrincipalIDs:   req.PrincipalIDs,
+		ExpiresInDays:  req.ExpiresInDays,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, cubebox.ErrConversationShareInvalid):
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_form", "share invalid")
+		case errors.Is(err, cubebox.ErrConversationNotFound):
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusNotFound, "cubebox_conversation_not_found", "conversation not found")
+		case errors.Is(err, cubebox.ErrConversationTurnNotFound):
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusNotFound, "cubebox_conversation_turn_not_found", "turn not found")
+		default:
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "cubebox_share_failed", "create share failed")
+		}
+		return
+	}
+	writeJSON(w, http.StatusCreated, share)
+}
+
+// handleCubeBoxShareAPI opens a share for a principal it was shared with. A share that does not exist, has
+// expired, was revoked or was not shared with the caller is reported the same way, so ids cannot be probed.
+func handleCubeBoxShareAPI(w http.ResponseWriter, r *http.Request, store cubeboxShareStore, scope orgUnitScopeDeps) {
+	if r.Method != http.MethodGet {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	format, ok := cubeboxExportFormat(w, r)
+	if !ok {
+		return
+	}
+	tenant, principal, ok := cubeboxRequestActor(w, r)
+	if !ok {
+		return
+	}
+	share, err := store.GetConversationShare(r.Context(), tenant.ID, shareIDFromPath(r.URL.Path))
+	if err == nil && !share.VisibleTo(principal.ID, time.Now().UTC()) {
+		err = cubebox.ErrConversationShareNotFound
+	}
+	var replay cubebox.ConversationReplayResponse
+	if err == nil {
+		replay, err = store.GetConversation(r.Context(), tenant.ID, share.OwnerID, share.ConversationID)
+	}
+	var export cubebox.ConversationExport
+	if err == nil {
+		export, err = cubebox.BuildConversationExport(replay, share.TurnID)
+	}
+	if err != nil {
+		if errors.Is(err, cubebox.ErrConversationShareNotFound) || errors.Is(err, cubebox.ErrConversationNotFound) || errors.Is(err, cubebox.ErrConversationTurnNotFound) {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusNotFound, "cubebox_share_not_found", "share not found")
+			return
+		}
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "cubebox_conversation_read_failed", "read shared conversation failed")
+		return
+	}
+	export = export.FilterEvidence(cubeboxEvidenceCallAuthorizer(r.Context(), scope.runtime, tenant.ID, principal.ID, share.OwnerID), cubeboxEvidenceScopeFilter(r.Context(), scope, tenant.ID))
+	if format == "markdown" {
+		writeCubeBoxExportMarkdown(w, share.ConversationID, export)
+		return
+	}
+	writeJSON(w, http.StatusOK, cubeboxSharedConversationResponse{
+		ShareID:   share.ID,
+		SharedBy:  share.OwnerID,
+		ExpiresAt: share.ExpiresAt,
+		Export:    export,
+	})
+}
+
+func handleCubeBoxShareRevokeAPI(w http.ResponseWriter, r *http.Request, store cubeboxShareStore) {
+	if r.Method != http.MethodPost {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	tenant, principal, ok := cubeboxRequestActor(w, r)
+	if !ok {
+		return
+	}
+	share, err := store.RevokeConversationShare(r.Context(), tenant.ID, principal.ID, shareIDFromRevokePath(r.URL.Path))
+	if err != nil {
+		if errors.Is(err, cubebox.ErrConversationShareNotFound) {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusNotFound, "cubebox_share_not_found", "share not found")
+			return
+		}
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "cubebox_share_failed", "revoke share failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, share)
+}
+
+// cubeboxEvidenceCallAuthorizer re-checks each evidence call against the reading principal's own capability
+// for the route it called, so a share never shows results of a tool the reader could not call. A route with
+// no capability of its own is a session-scoped tool that read the owner's session; only the owner may see
+// it. Any other call without a known route is withheld.
+func cubeboxEvidenceCallAuthorizer(ctx context.Context, runtime authzRuntimeStore, tenantID string, readerID string, ownerID string) func(cubebox.QueryEvidenceCall) bool {
+	checked := map[string]bool{}
+	return func(call cubebox.QueryEvidenceCall) bool {
+		requirement, ok := findRouteRequirement(call.Method, call.Path)
+		if !ok {
+			return readerID == ownerID && isSessionScopedAPITool(cubebox.APITool{Method: call.Method, Path: call.Path})
+		}
+		if runtime == nil {
+			return true
+		}
+		key := authz.AuthzCapabilityKey(requirement.Object, requirement.Action)
+		if allowed, ok := checked[key]; ok {
+			return allowed
+		}
+		allowed, err := runtime.AuthorizePrincipal(ctx, tenantID, readerID, requirement.Object, requirement.Action)
+		allowed = err == nil && allowed
+		checked[key] = allowed
+		return allowed
+	}
+}
+
+// cubeboxEvidenceScopeFilter re-checks each org unit cited by evidence, or named in its params, against the
+// reading principal's own org scopes, at the as-of date the evidence was read. Entities of other domains
+// carry no org scope. A unit that cannot be resolved for the reader, for whatever reason, or that has no
+// code to resolve, withholds its evidence.
+func cubeboxEvidenceScopeFilter(ctx context.Context, scope orgUnitScopeDeps, tenantID string) func(cubebox.QueryCandidate) bool {
+	checked := map[string]bool{}
+	return func(entity cubebox.QueryCandidate) bool {
+		if entity.Domain != "orgunit" {
+			return true
+		}
+		if strings.TrimSpace(entity.EntityKey) == "" {
+			return false
+		}
+		key := entity.EntityKey + "\x00" + entity.AsOf
+		if allowed, ok := checked[key]; ok {
+			return allowed
+		}
+		allowed := ensureCurrentPrincipalOrgCodeScopeAllows(ctx, scope.store, scope.runtime, tenantID, entity.EntityKey, entity.AsOf) == nil
+		checked[key] = allowed
+		return allowed
+	}
+}
+
+func cubeboxExportFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	switch format := strings.TrimSpace(r.URL.Query().Get("format")); format {
+	case "", "json":
+		return "json", true
+	case "markdown":
+		return format, true
+	default:
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "format must be json or markdown")
+		return "", false
+	}
+}
+
+func writeCubeBoxExportMarkdown(w http.ResponseWriter, conversationID string, export cubebox.ConversationExport) {
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", conversationID+".md"))
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, cubebox.RenderConversationExportMarkdown(export))
+}
+
+func conversationIDFromExportPath(path string) string {
+	return strings.TrimSpace(strings.TrimSuffix(conversationIDFromPath(path), ":export"))
+}
+
+func shareIDFromPath(path string) string {
+	return strings.TrimSpace(strings.TrimPrefix(path, "/internal/cubebox/shares/"))
+}
+
+func shareIDFromRevokePath(path string) string {
+	return strings.TrimSpace(strings.TrimSuffix(shareIDFromPath(path), ":revoke"))
+}
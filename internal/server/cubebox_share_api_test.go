@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/authz"
+)
+
+type cubeboxShareStoreStub struct {
+	replay      cubebox.ConversationReplayResponse
+	share       cubebox.ConversationShare
+	createErr   error
+	created     cubebox.ConversationShareInput
+	readAs      string
+	revokedFrom string
+}
+
+func (s *cubeboxShareStoreStub) GetConversation(_ context.Context, _ string, principalID string, conversationID string) (cubebox.ConversationReplayResponse, error) {
+	s.readAs = principalID
+	if conversationID != s.replay.Conversation.ID {
+		return cubebox.ConversationReplayResponse{}, cubebox.ErrConversationNotFound
+	}
+	return s.replay, nil
+}
+
+func (s *cubeboxShareStoreStub) CreateConversationShare(_ context.Context, _ string, ownerID string, input cubebox.ConversationShareInput) (cubebox.ConversationShare, error) {
+	s.created = input
+	if s.createErr != nil {
+		return cubebox.ConversationShare{}, s.createErr
+	}
+	return cubebox.ConversationShare{ID: "shr_1", ConversationID: input.ConversationID, OwnerID: ownerID, PrincipalIDs: input.PrincipalIDs}, nil
+}
+
+func (s *cubeboxShareStoreStub) ListConversationShares(context.Context, string, string) ([]cubebox.ConversationShare, error) {
+	return []cubebox.ConversationShare{s.share}, nil
+}
+
+func (s *cubeboxShareStoreStub) GetConversationShare(_ context.Context, _ string, shareID string) (cubebox.ConversationShare, error) {
+	if shareID != s.share.ID {
+		return cubebox.ConversationShare{}, cubebox.ErrConversationShareNotFound
+	}
+	return s.share, nil
+}
+
+func (s *cubeboxShareStoreStub) RevokeConversationShare(_ context.Context, _ string, ownerID string, shareID string) (cubebox.ConversationShare, error) {
+	s.revokedFrom = ownerID
+	if shareID != s.share.ID {
+		return cubebox.ConversationShare{}, cubebox.ErrConversationShareNotFound
+	}
+	return s.share, nil
+}
+
+func newCubeBoxShareStoreStub() *cubeboxShareStoreStub {
+	turnID := "turn_1"
+	calls := cubebox.QueryEvidenceCallsFromResults([]cubebox.ExecuteResult{{
+		Method:              "GET",
+		Path:                "/org/api/org-units/details",
+		OperationID:         "orgunit.details",
+		Params:              map[string]any{"org_code": "A001", "as_of": "2026-10-01"},
+		Payload:             map[string]any{"org_unit": map[string]any{"org_code": "A001", "name": "财务部"}},
+		PresentedCandidates: []cubebox.QueryCandidate{{Domain: "orgunit", EntityKey: "A001", Name: "财务部", AsOf: "2026-10-01"}},
+	}})
+	return &cubeboxShareStoreStub{
+		replay: cubebox.ConversationReplayResponse{
+			Conversation: cubebox.Conversation{ID: "conv_1", Title: "财务部负责人"},
+			Events: []cubebox.CanonicalEvent{
+				{Sequence: 2, TurnID: &turnID, Type: "turn.user_message.accepted", Payload: map[string]any{"text": "财务部负责人是谁"}},
+				{Sequence: 3, TurnID: &turnID, Type: cubebox.QueryEvidenceRecordedEventType, Payload: cubebox.QueryEvidencePayload(calls)},
+				{Sequence: 4, TurnID: &turnID, Type: "turn.agent_message.delta", Payload: map[string]any{"delta": "负责人是张三。"}},
+				{Sequence: 5, TurnID: &turnID, Type: "turn.completed", Payload: map[string]any{"status": "completed"}},
+			},
+		},
+		share: cubebox.ConversationShare{
+			ID:             "shr_1",
+			ConversationID: "conv_1",
+			OwnerID:        "owner-1",
+			PrincipalIDs:   []string{"p1"},
+			ExpiresAt:      time.Now().UTC().Add(time.Hour).Format(time.RFC3339),
+		},
+	}
+}
+
+func TestCubeBoxConversationExportAPI(t *testing.T) {
+	store := newCubeBoxShareStoreStub()
+	conversations := cubeboxStoreStub{getFn: store.GetConversation}
+
+	rec := httptest.NewRecorder()
+	handleCubeBoxConversationExportAPI(rec, newCubeBoxUsageRequest(http.MethodGet, "/internal/cubebox/conversations/conv_1:export?turn_id=turn_1", ""), conversations, orgUnitScopeDeps{})
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"org_code":"A001"`) || !strings.Contains(rec.Body.String(), "负责人是张三。") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handleCubeBoxConversationExportAPI(rec, newCubeBoxUsageRequest(http.MethodGet, "/internal/cubebox/conversations/conv_1:export?format=markdown", ""), conversations, orgUnitScopeDeps{})
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/markdown") || !strings.Contains(rec.Body.String(), "# 财务部负责人") {
+		t.Fatalf("status=%d headers=%v body=%s", rec.Code, rec.Header(), rec.Body.String())
+	}
+
+	for target, want := range map[string]int{
+		"/internal/cubebox/conversations/conv_1:export?format=pdf":     http.StatusBadRequest,
+		"/internal/cubebox/conversations/conv_1:export?turn_id=turn_9": http.StatusNotFound,
+		"/internal/cubebox/conversations/conv_9:export":                http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		handleCubeBoxConversationExportAPI(rec, newCubeBoxUsageRequest(http.MethodGet, target, ""), conversations, orgUnitScopeDeps{})
+		if rec.Code != want {
+			t.Fatalf("%s status=%d body=%s", target, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestCubeBoxSharesAPICreatesAndRevokes(t *testing.T) {
+	store := newCubeBoxShareStoreStub()
+	rec := httptest.NewRecorder()
+	handleCubeBoxSharesAPI(rec, newCubeBoxUsageRequest(http.MethodPost, "/internal/cubebox/shares", `{"conversation_id":"conv_1","turn_id":"turn_1","principal_ids":["p2"]}`), store)
+	if rec.Code != http.StatusCreated || store.created.TurnID != "turn_1" || !strings.Contains(rec.Body.String(), `"share_id":"shr_1"`) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	for err, want := range map[error]int{
+		cubebox.ErrConversationShareInvalid: http.StatusUnprocessableEntity,
+		cubebox.ErrConversationNotFound:     http.StatusNotFound,
+		cubebox.ErrConversationTurnNotFound: http.StatusNotFound,
+	} {
+		store.createErr = err
+		rec := httptest.NewRecorder()
+		handleCubeBoxSharesAPI(rec, newCubeBoxUsageRequest(http.MethodPost, "/internal/cubebox/shares", `{"conversation_id":"conv_1","principal_ids":["p2"]}`), store)
+		if rec.Code != want {
+			t.Fatalf("err=%v status=%d body=%s", err, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	handleCubeBoxShareRevokeAPI(rec, newCubeBoxUsageRequest(http.MethodPost, "/internal/cubebox/shares/shr_1:revoke", ""), store)
+	if rec.Code != http.StatusOK || store.revokedFrom != "p1" {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	handleCubeBoxShareRevokeAPI(rec, newCubeBoxUsageRequest(http.MethodPost, "/internal/cubebox/shares/shr_9:revoke", ""), store)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCubeBoxShareAPIRechecksViewerOrgScope(t *testing.T) {
+	store := newCubeBoxShareStoreStub()
+	rec := httptest.NewRecorder()
+	handleCubeBoxShareAPI(rec, newCubeBoxUsageRequest(http.MethodGet, "/internal/cubebox/shares/shr_1", ""), store, orgUnitScopeDeps{})
+	if rec.Code != http.StatusOK || store.readAs != "owner-1" || !strings.Contains(rec.Body.String(), `"name":"财务部"`) || strings.Contains(rec.Body.String(), "principal_ids") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	// A viewer without org scope sees neither the evidence nor the answer narrated from it.
+	scope := orgUnitScopeDeps{store: &resolveOrgCodeStore{}, runtime: &orgUnitScopeRuntimeStub{err: errAuthzOrgScopeRequired}}
+	rec = httptest.NewRecorder()
+	handleCubeBoxShareAPI(rec, newCubeBoxUsageRequest(http.MethodGet, "/internal/cubebox/shares/shr_1", ""), store, scope)
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, `"withheld":true`) || !strings.Contains(body, `"answer_withheld":true`) || strings.Contains(body, "A001") || strings.Contains(body, "负责人是张三。") {
+		t.Fatalf("status=%d body=%s", rec.Code, body)
+	}
+
+	store.share.PrincipalIDs = []string{"p2"}
+	for _, target := range []string{"/internal/cubebox/shares/shr_1", "/internal/cubebox/shares/shr_9"} {
+		rec := httptest.NewRecorder()
+		handleCubeBoxShareAPI(rec, newCubeBoxUsageRequest(http.MethodGet, target, ""), store, orgUnitScopeDeps{})
+		if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "cubebox_share_not_found") {
+			t.Fatalf("%s status=%d body=%s", target, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestCubeBoxShareAPIRechecksViewerCapability(t *testing.T) {
+	store := newCubeBoxShareStoreStub()
+	turnID := "turn_1"
+	calls := cubebox.QueryEvidenceCallsFromResults([]cubebox.ExecuteResult{
+		{Method: "GET", Path: "/iam/api/dicts", OperationID: "iam.dicts", Params: map[string]any{"as_of": "2026-10-01"}, Payload: map[string]any{"dicts": []any{map[string]any{"dict_code": "org_type"}}}},
+		{Method: "GET", Path: "/iam/api/authz/roles", OperationID: "iam.authz.roles", Payload: map[string]any{"roles": []any{map[string]any{"role_slug": "hr-admin"}}}},
+	})
+	store.replay.Events = []cubebox.CanonicalEvent{
+		{Sequence: 2, TurnID: &turnID, Type: "turn.user_message.accepted", Payload: map[string]any{"text": "有哪些角色"}},
+		{Sequence: 3, TurnID: &turnID, Type: cubebox.QueryEvidenceRecordedEventType, Payload: cubebox.QueryEvidencePayload(calls)},
+		{Sequence: 4, TurnID: &turnID, Type: "turn.agent_message.delta", Payload: map[string]any{"delta": "共有 1 个角色。"}},
+	}
+
+	runtime := cubeboxToolAuthzStub{sessionCapabilitiesAuthorizerStub: sessionCapabilitiesAuthorizerStub{allowed: map[string]bool{
+		authz.AuthzCapabilityKey(authz.ObjectIAMDicts, authz.ActionRead): true,
+	}}}
+	rec := httptest.NewRecorder()
+	handleCubeBoxShareAPI(rec, newCubeBoxUsageRequest(http.MethodGet, "/internal/cubebox/shares/shr_1", ""), store, orgUnitScopeDeps{runtime: runtime})
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, `"dicts_count":1`) || !strings.Contains(body, `"answer_withheld":true`) || strings.Contains(body, "roles_count") || strings.Contains(body, "共有 1 个角色。") {
+		t.Fatalf("status=%d body=%s", rec.Code, body)
+	}
+}
+
+func TestCubeBoxEvidenceCallAuthorizerWithholdsOwnerSessionCalls(t *testing.T) {
+	call := cubebox.QueryEvidenceCall{Method: "GET", Path: "/iam/api/me/capabilities", OperationID: "iam.me.capabilities"}
+	if !cubeboxEvidenceCallAuthorizer(context.Background(), nil, "t1", "owner-1", "owner-1")(call) {
+		t.Fatal("owner should see own session-scoped evidence")
+	}
+	if cubeboxEvidenceCallAuthorizer(context.Background(), nil, "t1", "p1", "owner-1")(call) {
+		t.Fatal("share viewer must not see the owner's session-scoped evidence")
+	}
+	if cubeboxEvidenceCallAuthorizer(context.Background(), nil, "t1", "owner-1", "owner-1")(cubebox.QueryEvidenceCall{Method: "GET", Path: "/unknown/api"}) {
+		t.Fatal("unknown route must be withheld")
+	}
+}
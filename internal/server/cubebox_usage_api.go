@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+)
+
+type cubeboxUsageStore interface {
+	GetUsageReport(ctx context.Context, tenantID string, query cubebox.UsageReportQuery) (cubebox.UsageReport, error)
+	ListTokenBudgets(ctx context.Context, tenantID string) ([]cubebox.TokenBudget, error)
+	UpsertTokenBudget(ctx context.Context, tenantID string, principalID string, input cubebox.TokenBudget) (cubebox.TokenBudget, error)
+}
+
+type cubeboxTokenBudgetRequest struct {
+	PrincipalID       string `json:"principal_id"`
+	DailyTokenLimit   int64  `json:"daily_token_limit"`
+	MonthlyTokenLimit int64  `json:"monthly_token_limit"`
+}
+
+type cubeboxTokenBudgetsResponse struct {
+	Budgets []cubebox.TokenBudget `json:"budgets"`
+}
+
+// handleCubeBoxSettingsUsageAPI reports metered token usage. from and to are inclusive UTC dates; the
+// default window is the current month.
+func handleCubeBoxSettingsUsageAPI(w http.ResponseWriter, r *http.Request, store cubeboxUsageStore) {
+	if r.Method != http.MethodGet {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	tenant, _, ok := cubeboxRequestActor(w, r)
+	if !ok {
+		return
+	}
+	query := cubebox.UsageReportQuery{GroupBy: strings.TrimSpace(r.URL.Query().Get("group_by"))}
+	if raw := strings.TrimSpace(r.URL.Query().Get("from")); raw != "" {
+		from, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_form", "from must be YYYY-MM-DD")
+			return
+		}
+		query.From = from
+	}
+	if raw := strings.TrimSpace(r.URL.Query().Get("to")); raw != "" {
+		to, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_form", "to must be YYYY-MM-DD")
+			return
+		}
+		query.To = to.AddDate(0, 0, 1)
+	}
+	query, err := cubebox.NormalizeUsageReportQuery(query, time.Now().UTC())
+	if err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_form", "usage report query invalid")
+		return
+	}
+	payload, err := store.GetUsageReport(r.Context(), tenant.ID, query)
+	if err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "ai_model_config_invalid", "usage report failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, payload)
+}
+
+func handleCubeBoxSettingsBudgetsAPI(w http.ResponseWriter, r *http.Request, store cubeboxUsageStore) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	tenant, principal, ok := cubeboxRequestActor(w, r)
+	if !ok {
+		return
+	}
+	if r.Method == http.MethodGet {
+		budgets, err := store.ListTokenBudgets(r.Context(), tenant.ID)
+		if err != nil {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "ai_model_config_invalid", "budgets load failed")
+			return
+		}
+		writeJSON(w, http.StatusOK, cubeboxTokenBudgetsResponse{Budgets: budgets})
+		return
+	}
+	var req cubeboxTokenBudgetRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+		return
+	}
+	payload, err := store.UpsertTokenBudget(r.Context(), tenant.ID, principal.ID, cubebox.TokenBudget{
+		PrincipalID:       req.PrincipalID,
+		DailyTokenLimit:   req.DailyTokenLimit,
+		MonthlyTokenLimit: req.MonthlyTokenLimit,
+	})
+	if err != nil {
+		if errors.Is(err, cubebox.ErrTokenBudgetInvalid) {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_form", "token budget invalid")
+			return
+		}
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "ai_model_config_invalid", "budget save failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, payload)
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+)
+
+type cubeboxUsageStoreStub struct {
+	reportQuery cubebox.UsageReportQuery
+	budgets     []cubebox.TokenBudget
+	upserted    *cubebox.TokenBudget
+	snapshot    cubebox.TokenBudgetSnapshot
+	records     []cubebox.UsageRecord
+}
+
+func (s *cubeboxUsageStoreStub) GetUsageReport(_ context.Context, _ string, query cubebox.UsageReportQuery) (cubebox.UsageReport, error) {
+	s.reportQuery = query
+	return cubebox.UsageReport{GroupBy: query.GroupBy, Rows: []cubebox.UsageReportRow{{Key: "gpt-4.1", TotalTokens: 42, Turns: 2}}}, nil
+}
+
+func (s *cubeboxUsageStoreStub) ListTokenBudgets(context.Context, string) ([]cubebox.TokenBudget, error) {
+	return s.budgets, nil
+}
+
+func (s *cubeboxUsageStoreStub) UpsertTokenBudget(_ context.Context, _ string, _ string, input cubebox.TokenBudget) (cubebox.TokenBudget, error) {
+	if err := cubebox.ValidateTokenBudget(input); err != nil {
+		return cubebox.TokenBudget{}, err
+	}
+	s.upserted = &input
+	return input, nil
+}
+
+func (s *cubeboxUsageStoreStub) RecordTurnUsage(_ context.Context, records []cubebox.UsageRecord) error {
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func (s *cubeboxUsageStoreStub) GetTokenBudgetSnapshot(context.Context, string, string, time.Time) (cubebox.TokenBudgetSnapshot, error) {
+	return s.snapshot, nil
+}
+
+func newCubeBoxUsageRequest(method string, target string, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req = req.WithContext(withTenant(req.Context(), Tenant{ID: "t1"}))
+	return req.WithContext(withPrincipal(req.Context(), Principal{ID: "p1"}))
+}
+
+func TestCubeBoxSettingsUsageAPI(t *testing.T) {
+	store := &cubeboxUsageStoreStub{}
+	rec := httptest.NewRecorder()
+	handleCubeBoxSettingsUsageAPI(rec, newCubeBoxUsageRequest(http.MethodGet, "/internal/cubebox/settings/usage?from=2026-10-01&to=2026-10-19&group_by=model", ""), store)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"total_tokens":42`) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	// to is inclusive, so the query runs to the start of the next day.
+	if !store.reportQuery.From.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) || !store.reportQuery.To.Equal(time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)) || store.reportQuery.GroupBy != "model" {
+		t.Fatalf("query=%+v", store.reportQuery)
+	}
+
+	for _, target := range []string{
+		"/internal/cubebox/settings/usage?from=yesterday",
+		"/internal/cubebox/settings/usage?group_by=tenant",
+		"/internal/cubebox/settings/usage?from=2026-10-19&to=2026-10-01",
+	} {
+		rec := httptest.NewRecorder()
+		handleCubeBoxSettingsUsageAPI(rec, newCubeBoxUsageRequest(http.MethodGet, target, ""), store)
+		if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "invalid_form") {
+			t.Fatalf("%s: status=%d body=%s", target, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestCubeBoxSettingsBudgetsAPI(t *testing.T) {
+	store := &cubeboxUsageStoreStub{budgets: []cubebox.TokenBudget{{MonthlyTokenLimit: 1000000}}}
+
+	rec := httptest.NewRecorder()
+	handleCubeBoxSettingsBudgetsAPI(rec, newCubeBoxUsageRequest(http.MethodGet, "/internal/cubebox/settings/budgets", ""), store)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"monthly_token_limit":1000000`) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handleCubeBoxSettingsBudgetsAPI(rec, newCubeBoxUsageRequest(http.MethodPost, "/internal/cubebox/settings/budgets", `{"principal_id":"00000000-0000-0000-0000-000000000001","daily_token_limit":5000}`), store)
+	if rec.Code != http.StatusOK || store.upserted == nil || store.upserted.DailyTokenLimit != 5000 {
+		t.Fatalf("status=%d body=%s upserted=%+v", rec.Code, rec.Body.String(), store.upserted)
+	}
+
+	rec = httptest.NewRecorder()
+	handleCubeBoxSettingsBudgetsAPI(rec, newCubeBoxUsageRequest(http.MethodPost, "/internal/cubebox/settings/budgets", `{"daily_token_limit":-1}`), store)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "invalid_form") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCubeBoxStreamTurnAPIRefusesTurnOverBudget(t *testing.T) {
+	usage := &cubeboxUsageStoreStub{snapshot: cubebox.TokenBudgetSnapshot{
+		Principal:          &cubebox.TokenBudget{PrincipalID: "p1", DailyTokenLimit: 100},
+		PrincipalDailyUsed: 120,
+	}}
+	runtime := cubebox.NewRuntime()
+	gateway := cubebox.NewGatewayService(runtime, nil, nil, nil).WithUsageStore(usage)
+	var appended []cubebox.CanonicalEvent
+	store := cubeboxStoreStub{
+		preparePromptViewFn: func(context.Context, string, string, string, cubebox.CanonicalContext, string) (cubebox.PromptViewPreparationResponse, error) {
+			return cubebox.PromptViewPreparationResponse{}, nil
+		},
+		appendFn: func(_ context.Context, _ string, _ string, _ string, event cubebox.CanonicalEvent) error {
+			appended = append(appended, event)
+			return nil
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	handleCubeBoxStreamTurnAPI(rec, newCubeBoxUsageRequest(http.MethodPost, "/internal/cubebox/turns:stream", `{"conversation_id":"conv_1","prompt":"hello","next_sequence":1}`), runtime, store, gateway, nil)
+
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, `"code":"ai_budget_exceeded"`) || strings.Contains(body, `"type":"turn.started"`) {
+		t.Fatalf("status=%d body=%s", rec.Code, body)
+	}
+	if len(appended) != 2 || appended[0].Type != "turn.error" || appended[1].Type != "turn.completed" {
+		t.Fatalf("appended=%+v", appended)
+	}
+	if len(usage.records) != 0 {
+		t.Fatalf("refused turn recorded usage: %+v", usage.records)
+	}
+}
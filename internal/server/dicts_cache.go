@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	iammodule "github.com/jacksonlee411/Bugs-And-Blossoms/modules/iam"
+	dictpkg "github.com/jacksonlee411/Bugs-And-Blossoms/pkg/dict"
+)
+
+// dictResolverWithCache wraps the dict store in an invalidation-aware cache when a dedicated Postgres pool
+// is available for LISTEN/NOTIFY. Without a notification source the cache could serve stale labels across
+// instances, so other stores are registered as-is.
+func dictResolverWithCache(ctx context.Context, store dictpkg.Resolver, orgStore OrgUnitStore) (dictpkg.Resolver, error) {
+	pool := dictChangeListenPool(orgStore)
+	if pool == nil {
+		return store, nil
+	}
+	cache, err := dictpkg.NewCachingResolver(store, dictpkg.CacheOptions{
+		MaxEntries: dictCacheMaxEntriesFromEnv(),
+		TTL:        dictCacheTTLFromEnv(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	listener := iammodule.NewDictChangeListener(pool, cache)
+	go func() { _ = listener.Run(ctx) }()
+	return cache, nil
+}
+
+// dictCacheStatsPath reports the dict cache counters of the process that answers. Unlike the health probes
+// it requires a session and iam.dicts admin, like the other dict operator routes.
+const dictCacheStatsPath = "/ops/dict-cache"
+
+type dictCacheStatsResponse struct {
+	Enabled       bool   `json:"enabled"`
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Bypasses      uint64 `json:"bypasses"`
+	Evictions     uint64 `json:"evictions"`
+	Invalidations uint64 `json:"invalidations"`
+	Entries       int    `json:"entries"`
+}
+
+func dictCacheStatsHandler(resolver dictpkg.Resolver) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		cache, ok := resolver.(*dictpkg.CachingResolver)
+		if !ok {
+			writeJSON(w, http.StatusOK, dictCacheStatsResponse{})
+			return
+		}
+		stats := cache.Stats()
+		writeJSON(w, http.StatusOK, dictCacheStatsResponse{
+			Enabled:       true,
+			Hits:          stats.Hits,
+			Misses:        stats.Misses,
+			Bypasses:      stats.Bypasses,
+			Evictions:     stats.Evictions,
+			Invalidations: stats.Invalidations,
+			Entries:       stats.Entries,
+		})
+	})
+}
+
+func dictChangeListenPool(orgStore OrgUnitStore) *pgxpool.Pool {
+	pgStore, ok := orgStore.(*orgUnitPGStore)
+	if !ok {
+		return nil
+	}
+	pool, _ := pgStore.pool.(*pgxpool.Pool)
+	return pool
+}
+
+func dictCacheMaxEntriesFromEnv() int {
+	n, err := strconv.Atoi(os.Getenv("DICT_CACHE_MAX_ENTRIES"))
+	if err != nil || n <= 0 {
+		return dictpkg.DefaultCacheMaxEntries
+	}
+	return n
+}
+
+func dictCacheTTLFromEnv() time.Duration {
+	n, err := strconv.Atoi(os.Getenv("DICT_CACHE_TTL_SECONDS"))
+	if err != nil || n <= 0 {
+		return dictpkg.DefaultCacheTTL
+	}
+	return time.Second * time.Duration(n)
+}
@@ -2,116 +2,12 @@ package server
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"testing"
 
 	"github.com/jackc/pgx/v5"
 )
 
-func TestDictBaselineReleaseHelpers(t *testing.T) {
-	req := normalizeDictBaselineReleaseRequest(DictBaselineReleaseRequest{
-		SourceTenantID: " ",
-		TargetTenantID: " 00000000-0000-0000-0000-000000000001 ",
-		AsOf:           " 2026-01-01 ",
-		ReleaseID:      " rel-1 ",
-		RequestID:      " req-1 ",
-		Operator:       " op ",
-		Initiator:      " init ",
-	})
-	if req.SourceTenantID != globalTenantID || req.TargetTenantID != "00000000-0000-0000-0000-000000000001" || req.AsOf != "2026-01-01" || req.ReleaseID != "rel-1" || req.RequestID != "req-1" || req.Operator != "op" || req.Initiator != "init" {
-		t.Fatalf("normalized req=%+v", req)
-	}
-
-	valid := DictBaselineReleaseRequest{
-		SourceTenantID: globalTenantID,
-		TargetTenantID: "00000000-0000-0000-0000-000000000001",
-		AsOf:           "2026-01-01",
-		ReleaseID:      "rel-1",
-		RequestID:      "req-1",
-	}
-	if err := validateDictBaselineReleaseRequest(valid, true); err != nil {
-		t.Fatalf("validate err=%v", err)
-	}
-	if err := validateDictBaselineReleaseRequest(DictBaselineReleaseRequest{}, false); !errors.Is(err, errDictReleaseIDRequired) {
-		t.Fatalf("err=%v", err)
-	}
-	if err := validateDictBaselineReleaseRequest(DictBaselineReleaseRequest{ReleaseID: "r"}, false); !errors.Is(err, errDictReleaseTargetRequired) {
-		t.Fatalf("err=%v", err)
-	}
-	if err := validateDictBaselineReleaseRequest(DictBaselineReleaseRequest{ReleaseID: "r", TargetTenantID: "00000000-0000-0000-0000-000000000001"}, false); !errors.Is(err, errDictEffectiveDayRequired) {
-		t.Fatalf("err=%v", err)
-	}
-	if err := validateDictBaselineReleaseRequest(DictBaselineReleaseRequest{ReleaseID: "r", TargetTenantID: "00000000-0000-0000-0000-000000000001", AsOf: "2026-01-01"}, true); !errors.Is(err, errDictRequestIDRequired) {
-		t.Fatalf("err=%v", err)
-	}
-	if err := validateDictBaselineReleaseRequest(DictBaselineReleaseRequest{ReleaseID: "r", TargetTenantID: "00000000-0000-0000-0000-000000000001", AsOf: "2026-01-01", SourceTenantID: "bad", RequestID: "req"}, true); !errors.Is(err, errDictReleaseSourceInvalid) {
-		t.Fatalf("err=%v", err)
-	}
-	if err := validateDictBaselineReleaseRequest(DictBaselineReleaseRequest{ReleaseID: "r", TargetTenantID: "bad", AsOf: "2026-01-01", SourceTenantID: globalTenantID, RequestID: "req"}, true); !errors.Is(err, errDictReleaseTargetRequired) {
-		t.Fatalf("err=%v", err)
-	}
-
-	if got := dictBaselineReleaseRequestCode("req", "dict", 9); got != "req#dict#9" {
-		t.Fatalf("request code=%q", got)
-	}
-	if got := dictBaselineReleaseTaskID("rel", "00000000-0000-0000-0000-000000000001", "2026-01-01"); got == "" {
-		t.Fatal("task id empty")
-	}
-	conflicts := make([]DictBaselineReleaseConflict, 0)
-	appendReleaseConflict(&conflicts, 1, DictBaselineReleaseConflict{Kind: "a"})
-	appendReleaseConflict(&conflicts, 1, DictBaselineReleaseConflict{Kind: "b"})
-	if len(conflicts) != 1 || conflicts[0].Kind != "a" {
-		t.Fatalf("conflicts=%+v", conflicts)
-	}
-	key := joinDictValueKey("org_type", "10")
-	dictCode, code := splitDictValueKey(key)
-	if dictCode != "org_type" || code != "10" {
-		t.Fatalf("split=%s %s", dictCode, code)
-	}
-	dictOnly, codeOnly := splitDictValueKey("org_type")
-	if dictOnly != "org_type" || codeOnly != "" {
-		t.Fatalf("split no sep=%s %s", dictOnly, codeOnly)
-	}
-}
-
-func TestWithReleaseMetadata(t *testing.T) {
-	req := DictBaselineReleaseRequest{
-		SourceTenantID: globalTenantID,
-		TargetTenantID: "00000000-0000-0000-0000-000000000001",
-		AsOf:           "2026-01-01",
-		ReleaseID:      "rel-1",
-		Operator:       "u1",
-	}
-
-	got, err := withReleaseMetadata(nil, req, 1, "src-r1")
-	if err != nil {
-		t.Fatalf("err=%v", err)
-	}
-	var payload map[string]any
-	if err := json.Unmarshal(got, &payload); err != nil {
-		t.Fatalf("unmarshal err=%v", err)
-	}
-	if _, ok := payload["release"]; !ok {
-		t.Fatalf("payload=%v", payload)
-	}
-
-	got2, err := withReleaseMetadata([]byte(`{"label":"部门"}`), req, 2, "src-r2")
-	if err != nil {
-		t.Fatalf("err=%v", err)
-	}
-	if err := json.Unmarshal(got2, &payload); err != nil {
-		t.Fatalf("unmarshal err=%v", err)
-	}
-	if payload["label"] != "部门" {
-		t.Fatalf("payload=%v", payload)
-	}
-
-	if _, err := withReleaseMetadata([]byte(`{`), req, 3, "src-r3"); !errors.Is(err, errDictReleasePayloadInvalid) {
-		t.Fatalf("err=%v", err)
-	}
-}
-
 func TestDictPGStorePreviewBaseline(t *testing.T) {
 	ctx := context.Background()
 
@@ -19,8 +19,10 @@ import (
 	orgunitmodule "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit"
 	orgunitports "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/ports"
 	orgunitservices "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/services"
+	personmodule "github.com/jacksonlee411/Bugs-And-Blossoms/modules/person"
 	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/authz"
 	dictpkg "github.com/jacksonlee411/Bugs-And-Blossoms/pkg/dict"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/pagecursor"
 )
 
 //go:embed assets/*
@@ -30,12 +32,21 @@ func NewHandler() (http.Handler, error) {
 	return NewHandlerWithOptions(HandlerOptions{})
 }
 
+type sessionLoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
 type HandlerOptions struct {
+	// Context bounds the background work the handler starts: the dict change listener, the webhook
+	// dispatcher and the CubeBox retention worker. Cancel it on shutdown. Nil means context.Background().
+	Context             context.Context
 	TenancyResolver     TenancyResolver
 	IdentityProvider    identityProvider
 	OrgUnitStore        OrgUnitStore
 	OrgUnitWriteService orgunitservices.OrgUnitWriteService
 	DictStore           DictStore
+	PersonStore         PersonStore
 	AuthzRuntimeStore   authzRuntimeStore
 }
 
@@ -62,12 +73,20 @@ func NewHandlerWithOptions(opts HandlerOptions) (http.Handler, error) {
 	orgStore := opts.OrgUnitStore
 	orgUnitWriteService := opts.OrgUnitWriteService
 	dictStore := opts.DictStore
+	personStore := opts.PersonStore
 	tenancyResolver := opts.TenancyResolver
 	identityProvider := opts.IdentityProvider
 	authzRuntime := opts.AuthzRuntimeStore
+	serverCtx := opts.Context
+	if serverCtx == nil {
+		serverCtx = context.Background()
+	}
 
 	var pgPool *pgxpool.Pool
 	if orgStore == nil {
+		if err := pagecursor.RequireSecret(); err != nil {
+			return nil, err
+		}
 		dsn := dbDSNFromEnv()
 		pool, err := pgxpool.New(context.Background(), dsn)
 		if err != nil {
@@ -77,13 +96,28 @@ func NewHandlerWithOptions(opts HandlerOptions) (http.Handler, error) {
 		orgStore = newOrgUnitPGStore(pgPool)
 	}
 
+	if personStore == nil {
+		if pgStore, ok := orgStore.(*orgUnitPGStore); ok {
+			personStore = personmodule.NewPGStore(pgStore.pool)
+		} else {
+			personStore = personmodule.NewMemoryStore()
+		}
+	}
+	managerDirectory := orgunitmodule.NewPersonManagerDirectory(personStore)
+
 	if orgUnitWriteService == nil {
 		if writeStore, ok := orgStore.(orgunitports.OrgUnitWriteStore); ok {
-			orgUnitWriteService = orgunitmodule.NewWriteService(writeStore)
+			orgUnitWriteService = orgunitmodule.NewWriteServiceWithManagerDirectory(writeStore, managerDirectory)
 		} else if pgStore, ok := orgStore.(*orgUnitPGStore); ok {
-			orgUnitWriteService = orgunitmodule.NewWriteServiceWithPGStore(pgStore.pool)
+			orgUnitWriteService = orgunitmodule.NewWriteServiceWithManagerDirectory(orgunitmodule.NewPGStore(pgStore.pool), managerDirectory)
 		}
 	}
+	orgUnitManagers, _ := orgStore.(OrgUnitManagerReader)
+
+	var fieldValidationRules orgunitports.TenantFieldValidationRuleStore
+	if pgStore, ok := orgStore.(*orgUnitPGStore); ok {
+		fieldValidationRules = orgunitmodule.NewFieldValidationRulePGStore(pgStore.pool)
+	}
 
 	if dictStore == nil {
 		if pgStore, ok := orgStore.(*orgUnitPGStore); ok {
@@ -92,21 +126,35 @@ func NewHandlerWithOptions(opts HandlerOptions) (http.Handler, error) {
 			dictStore = iammodule.NewDictMemoryStore()
 		}
 	}
-	if err := dictpkg.RegisterResolver(dictStore); err != nil {
+	dictResolver, err := dictResolverWithCache(serverCtx, dictStore, orgStore)
+	if err != nil {
 		return nil, err
 	}
+	if err := dictpkg.RegisterResolver(dictResolver); err != nil {
+		return nil, err
+	}
+	webhookStore := newWebhookStore(orgStore)
+	startWebhookDispatcher(serverCtx, orgStore, webhookStore)
 	if authzRuntime == nil {
 		authzRuntime = newAuthzRuntimeStore(pgPool)
 	}
 
 	cubeboxRuntime := cubebox.NewRuntime()
-	cubeboxStore := cubebox.NewStore(pgPool)
-	cubeboxAdapter := cubebox.NewOpenAICompatibleAdapter(nil)
-	cubeboxSecretResolver := cubebox.EnvSecretResolver{}
-	cubeboxGateway := cubebox.NewGatewayService(cubeboxRuntime, cubeboxStore, cubeboxAdapter, cubeboxSecretResolver)
+	cubeboxVaultKeys, err := cubebox.LoadSecretVaultKeyringFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	cubeboxSecretResolver := cubebox.NewSecretVault(pgPool, cubeboxVaultKeys)
+	cubeboxStore := cubebox.NewStore(pgPool).WithSecretVault(cubeboxSecretResolver)
+	// Redaction wraps metering so usage is counted on the text the provider actually receives.
+	cubeboxAdapter := cubebox.NewRedactingProviderAdapter(cubebox.NewMeteredProviderAdapter(cubebox.NewOpenAICompatibleAdapter(nil)))
+	cubeboxGateway := cubebox.NewGatewayService(cubeboxRuntime, cubeboxStore, cubeboxAdapter, cubeboxSecretResolver).
+		WithUsageStore(cubeboxStore).
+		WithRedactionPolicies(cubeboxStore)
+	startCubeBoxRetentionWorker(serverCtx, cubeboxStore, pgPool != nil)
 	cubeboxQueryProducer := newCubeboxProviderAPIPlanProducer(cubeboxStore, cubeboxAdapter, cubeboxSecretResolver)
 	cubeboxQueryNarrator := newCubeboxProviderQueryNarrator(cubeboxStore, cubeboxAdapter, cubeboxSecretResolver)
-	cubeboxQueryFlow, err := buildDefaultCubeboxQueryFlow(cubeboxRuntime, cubeboxStore, orgStore, authzRuntime, cubeboxQueryProducer, cubeboxQueryNarrator)
+	cubeboxQueryFlow, err := buildDefaultCubeboxQueryFlow(cubeboxRuntime, cubeboxStore, orgStore, dictStore, authzRuntime, cubeboxQueryProducer, cubeboxQueryNarrator)
 	if err != nil {
 		return nil, err
 	}
@@ -127,26 +175,26 @@ func NewHandlerWithOptions(opts HandlerOptions) (http.Handler, error) {
 
 	principals := newPrincipalStore(pgPool)
 	sessions := newSessionStore(pgPool)
+	impersonations := newImpersonationStore(pgPool)
 	router.Handle(routing.RouteClassUI, http.MethodGet, "/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/app", http.StatusFound)
 	}))
 
-	router.Handle(routing.RouteClassOps, http.MethodGet, "/health", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	router.HandleDoc(routing.RouteClassOps, http.MethodGet, "/health", routing.RouteDoc{Summary: "Liveness probe.", Response: "", ContentType: "text/plain"}, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok\n"))
 	}))
-	router.Handle(routing.RouteClassOps, http.MethodGet, "/healthz", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	router.HandleDoc(routing.RouteClassOps, http.MethodGet, "/healthz", routing.RouteDoc{Summary: "Liveness probe.", Response: "", ContentType: "text/plain"}, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok\n"))
 	}))
+	router.HandleDoc(routing.RouteClassOps, http.MethodGet, dictCacheStatsPath, routing.RouteDoc{Summary: "Dict label cache counters of this process.", Response: dictCacheStatsResponse{}}, dictCacheStatsHandler(dictResolver))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, openAPIDocumentPath, routing.RouteDoc{Summary: "This OpenAPI document; requires a session."}, openAPIDocumentHandler(a, router))
 
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/sessions", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/sessions", routing.RouteDoc{Summary: "Log in and set the session cookie.", Request: sessionLoginRequest{}, Status: http.StatusNoContent}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		tenant, _ := currentTenant(r.Context())
 
-		var req struct {
-			Email    string `json:"email"`
-			Password string `json:"password"`
-		}
+		var req sessionLoginRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
 			return
@@ -209,177 +257,280 @@ func NewHandlerWithOptions(opts HandlerOptions) (http.Handler, error) {
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 		w.WriteHeader(http.StatusNoContent)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/me/capabilities", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/me/capabilities", routing.RouteDoc{Summary: "Capabilities granted to the current session.", Response: sessionCapabilitiesResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleSessionCapabilitiesAPI(w, r, authzRuntime)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/authz/capabilities", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/authz/capabilities", routing.RouteDoc{Summary: "Authz capability registry.", Response: authzCapabilitiesResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleAuthzCapabilitiesAPI(w, r)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/authz/api-catalog", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/authz/api-catalog", routing.RouteDoc{Summary: "Protected tenant API catalog.", Response: authzAPICatalogResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleAuthzAPICatalogAPI(w, r)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/authz/roles", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/authz/roles", routing.RouteDoc{Summary: "List role definitions.", Response: authzRolesResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleAuthzRolesAPI(w, r, authzRuntime)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/authz/roles", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/authz/roles", routing.RouteDoc{Summary: "Create a role definition.", Request: saveAuthzRoleDefinitionRequest{}, Response: authzRoleResponse{}, Status: http.StatusCreated}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleAuthzRolesAPI(w, r, authzRuntime)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/authz/roles/{role_slug}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/authz/roles/{role_slug}", routing.RouteDoc{Summary: "Read a role definition.", Response: authzRoleResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleAuthzRoleAPI(w, r, authzRuntime)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPut, "/iam/api/authz/roles/{role_slug}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPut, "/iam/api/authz/roles/{role_slug}", routing.RouteDoc{Summary: "Update a role definition.", Request: saveAuthzRoleDefinitionRequest{}, Response: authzRoleResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleAuthzRoleAPI(w, r, authzRuntime)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/authz/user-assignments", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/authz/user-assignments", routing.RouteDoc{Summary: "Read a principal's role assignments.", Response: principalAuthzAssignmentResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handlePrincipalAuthzAssignmentGetAPI(w, r, authzRuntime, principals, orgStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPut, "/iam/api/authz/user-assignments/{principal_id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPut, "/iam/api/authz/user-assignments/{principal_id}", routing.RouteDoc{Summary: "Replace a principal's role assignments.", Request: replacePrincipalAssignmentRequest{}, Response: principalAuthzAssignmentResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handlePrincipalAuthzAssignmentPutAPI(w, r, authzRuntime, orgStore, authzRuntime)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/dicts", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/dicts", routing.RouteDoc{Summary: "List dictionaries.", Response: dictListResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleDictsAPI(w, r, dictStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/dicts", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/dicts", routing.RouteDoc{Summary: "Create a dictionary.", Request: dictCreatePayload{}, Response: dictMutationResponse{}, Status: http.StatusCreated}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleDictsAPI(w, r, dictStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/dicts:disable", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/dicts:disable", routing.RouteDoc{Summary: "Disable a dictionary.", Request: dictDisablePayload{}, Response: dictMutationResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleDictsDisableAPI(w, r, dictStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/dicts/values", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/dicts/values", routing.RouteDoc{Summary: "List dictionary values.", Response: dictValuesResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleDictValuesAPI(w, r, dictStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/dicts/values", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/dicts/values", routing.RouteDoc{Summary: "Create a dictionary value.", Request: dictCreateValuePayload{}, Response: dictValueMutationResponse{}, Status: http.StatusCreated}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleDictValuesAPI(w, r, dictStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/dicts/values:disable", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/dicts/values:disable", routing.RouteDoc{Summary: "Disable a dictionary value.", Request: dictDisableValuePayload{}, Response: dictValueMutationResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleDictValuesDisableAPI(w, r, dictStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/dicts/values:correct", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/dicts/values:correct", routing.RouteDoc{Summary: "Correct a dictionary value label.", Request: dictCorrectValuePayload{}, Response: dictValueMutationResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleDictValuesCorrectAPI(w, r, dictStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/dicts/values/audit", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/dicts/values/audit", routing.RouteDoc{Summary: "Dictionary value audit trail.", Response: dictAuditResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleDictValuesAuditAPI(w, r, dictStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/dicts:release", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/dicts:release", routing.RouteDoc{Summary: "Publish the dictionary baseline.", Request: dictReleasePayload{}, Response: DictBaselineReleaseResult{}, Status: http.StatusCreated}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		releaseStore, _ := dictStore.(DictBaselineReleaseStore)
 		handleDictReleaseAPI(w, r, releaseStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/dicts:release:preview", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/dicts:release:preview", routing.RouteDoc{Summary: "Preview a dictionary baseline release.", Request: dictReleasePayload{}, Response: DictBaselineReleasePreview{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		releaseStore, _ := dictStore.(DictBaselineReleaseStore)
 		handleDictReleasePreviewAPI(w, r, releaseStore)
 	}))
-	router.Handle(routing.RouteClassAuthn, http.MethodPost, "/logout", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/webhooks/subscriptions", routing.RouteDoc{Summary: "List webhook subscriptions.", Response: webhookSubscriptionsResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleWebhookSubscriptionsAPI(w, r, webhookStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/webhooks/subscriptions", routing.RouteDoc{Summary: "Create a webhook subscription.", Request: iammodule.WebhookSubscriptionCreateRequest{}, Response: webhookSubscriptionResponse{}, Status: http.StatusCreated}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleWebhookSubscriptionsAPI(w, r, webhookStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPut, "/iam/api/webhooks/subscriptions/{subscription_id}", routing.RouteDoc{Summary: "Update a webhook subscription.", Request: webhookSubscriptionUpdatePayload{}, Response: webhookSubscriptionResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleWebhookSubscriptionAPI(w, r, webhookStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/webhooks/deliveries", routing.RouteDoc{Summary: "List webhook deliveries.", Response: webhookDeliveriesResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleWebhookDeliveriesAPI(w, r, webhookStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/iam/api/webhooks/deliveries:replay", routing.RouteDoc{Summary: "Re-queue webhook deliveries.", Request: iammodule.WebhookReplayRequest{}, Response: webhookReplayResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleWebhookDeliveriesReplayAPI(w, r, webhookStore)
+	}))
+	router.HandleDoc(routing.RouteClassAuthn, http.MethodPost, "/logout", routing.RouteDoc{Summary: "End the session and redirect to the login page.", Status: http.StatusFound}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if sid, ok := readSID(r); ok {
 			_ = sessions.Revoke(r.Context(), sid)
 		}
 		clearSIDCookie(w)
 		http.Redirect(w, r, "/app/login", http.StatusFound)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassAuthn, http.MethodGet, "/iam/impersonation/accept", routing.RouteDoc{Summary: "Accept an impersonation handoff and redirect into the app.", Status: http.StatusFound}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleImpersonationAccept(w, r, impersonations)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/iam/api/impersonation-sessions", routing.RouteDoc{Summary: "List impersonation sessions of the tenant."}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleImpersonationSessionsAPI(w, r, impersonations)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units", routing.RouteDoc{Summary: "List org units.", Response: orgUnitListResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitsAPI(w, r, orgStore, orgUnitWriteService, authzRuntime)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units", routing.RouteDoc{Summary: "Create an org unit.", Request: orgUnitCreateAPIRequest{}, Status: http.StatusCreated}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitsAPI(w, r, orgStore, orgUnitWriteService, authzRuntime)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/field-definitions", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/field-definitions", routing.RouteDoc{Summary: "Extension field definitions.", Response: orgUnitFieldDefinitionsAPIResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitFieldDefinitionsAPI(w, r)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/field-configs", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/field-configs", routing.RouteDoc{Summary: "Extension field configs.", Response: orgUnitFieldConfigsAPIResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitFieldConfigsAPI(w, r, orgStore, dictStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/field-configs", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/field-configs", routing.RouteDoc{Summary: "Enable an extension field.", Request: orgUnitFieldConfigsEnableRequest{}, Response: orgUnitFieldConfigAPIItem{}, Status: http.StatusCreated}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitFieldConfigsAPI(w, r, orgStore, dictStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/field-configs:enable-candidates", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/field-configs:enable-candidates", routing.RouteDoc{Summary: "Fields that can be enabled.", Response: orgUnitFieldConfigsEnableCandidatesAPIResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitFieldConfigsEnableCandidatesAPI(w, r, dictStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/field-configs:disable", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/field-configs:disable", routing.RouteDoc{Summary: "Disable an extension field.", Request: orgUnitFieldConfigsDisableRequest{}, Response: orgUnitFieldConfigAPIItem{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitFieldConfigsDisableAPI(w, r, orgStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/fields:options", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/field-validation-rules", routing.RouteDoc{Summary: "Tenant field validation rules.", Response: orgUnitFieldValidationRulesAPIResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleOrgUnitFieldValidationRulesAPI(w, r, fieldValidationRules)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/field-validation-rules", routing.RouteDoc{Summary: "Create or update a field validation rule.", Request: orgUnitFieldValidationRuleUpsertRequest{}, Response: orgUnitFieldValidationRuleAPIItem{}, Status: http.StatusCreated}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleOrgUnitFieldValidationRulesAPI(w, r, fieldValidationRules)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/field-validation-rules:disable", routing.RouteDoc{Summary: "Disable a field validation rule.", Request: orgUnitFieldValidationRuleDisableRequest{}, Response: orgUnitFieldValidationRuleAPIItem{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleOrgUnitFieldValidationRulesDisableAPI(w, r, fieldValidationRules)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/field-validation-rules:test", routing.RouteDoc{Summary: "Evaluate a validation rule against sample input.", Request: orgUnitFieldValidationRuleTestRequest{}, Response: orgUnitFieldValidationRuleTestResponse{}}, http.HandlerFunc(handleOrgUnitFieldValidationRulesTestAPI))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/fields:options", routing.RouteDoc{Summary: "Options for an extension field.", Response: orgUnitFieldOptionsAPIResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitFieldOptionsAPI(w, r, orgStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/details", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/details", routing.RouteDoc{Summary: "Org unit details.", Response: orgUnitDetailsAPIResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitsDetailsAPI(w, r, orgStore, authzRuntime)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/versions", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/versions", routing.RouteDoc{Summary: "Org unit version history.", Response: orgUnitVersionsAPIResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitsVersionsAPI(w, r, orgStore, authzRuntime)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/audit", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/audit", routing.RouteDoc{Summary: "Org unit audit events.", Response: orgUnitAuditAPIResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitsAuditAPI(w, r, orgStore, authzRuntime)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/search", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/search", routing.RouteDoc{Summary: "Search org units.", Response: OrgUnitSearchResult{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitsSearchAPI(w, r, orgStore, authzRuntime)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/rename", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/org/api/org-units/managed-by", routing.RouteDoc{Summary: "Org units managed by a person.", Response: orgUnitManagedByResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleOrgUnitsManagedByAPI(w, r, orgUnitManagers, personStore, authzRuntime)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/rename", routing.RouteDoc{Summary: "Rename an org unit.", Request: orgUnitRenameAPIRequest{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitsRenameAPI(w, r, orgUnitWriteService, orgUnitScopeDeps{store: orgStore, runtime: authzRuntime})
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/move", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/move", routing.RouteDoc{Summary: "Move an org unit.", Request: orgUnitMoveAPIRequest{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitsMoveAPI(w, r, orgUnitWriteService, orgUnitScopeDeps{store: orgStore, runtime: authzRuntime})
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/disable", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/disable", routing.RouteDoc{Summary: "Disable an org unit.", Request: orgUnitDisableAPIRequest{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitsDisableAPI(w, r, orgUnitWriteService, orgUnitScopeDeps{store: orgStore, runtime: authzRuntime})
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/enable", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/enable", routing.RouteDoc{Summary: "Enable an org unit.", Request: orgUnitEnableAPIRequest{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitsEnableAPI(w, r, orgUnitWriteService, orgUnitScopeDeps{store: orgStore, runtime: authzRuntime})
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/write", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/write", routing.RouteDoc{Summary: "Write an org unit through a policy-checked intent.", Request: orgUnitWriteAPIRequest{}, Response: orgUnitWriteAPIResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitsWriteAPI(w, r, orgUnitWriteService, orgUnitScopeDeps{store: orgStore, runtime: authzRuntime})
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/corrections", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/corrections", routing.RouteDoc{Summary: "Correct an org unit event.", Request: orgUnitCorrectionAPIRequest{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitsCorrectionsAPI(w, r, orgUnitWriteService, orgUnitScopeDeps{store: orgStore, runtime: authzRuntime})
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/status-corrections", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/status-corrections", routing.RouteDoc{Summary: "Correct an org unit status event.", Request: orgUnitStatusCorrectionAPIRequest{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitsStatusCorrectionsAPI(w, r, orgUnitWriteService, orgUnitScopeDeps{store: orgStore, runtime: authzRuntime})
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/rescinds", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/rescinds", routing.RouteDoc{Summary: "Rescind an org unit event.", Request: orgUnitRescindRecordAPIRequest{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitsRescindsAPI(w, r, orgUnitWriteService, orgUnitScopeDeps{store: orgStore, runtime: authzRuntime})
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/rescinds/org", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/rescinds/org", routing.RouteDoc{Summary: "Rescind an org unit.", Request: orgUnitRescindOrgAPIRequest{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitsRescindsOrgAPI(w, r, orgUnitWriteService, orgUnitScopeDeps{store: orgStore, runtime: authzRuntime})
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/set-business-unit", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/merges", routing.RouteDoc{Summary: "Merge org units.", Request: orgUnitMergeAPIRequest{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleOrgUnitsMergeAPI(w, r, orgUnitWriteService, orgUnitScopeDeps{store: orgStore, runtime: authzRuntime})
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/splits", routing.RouteDoc{Summary: "Split an org unit.", Request: orgUnitSplitAPIRequest{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleOrgUnitsSplitAPI(w, r, orgUnitWriteService, orgUnitScopeDeps{store: orgStore, runtime: authzRuntime})
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/org/api/org-units/set-business-unit", routing.RouteDoc{Summary: "Set the business unit flag.", Request: orgUnitBusinessUnitAPIRequest{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleOrgUnitsBusinessUnitAPI(w, r, orgUnitWriteService, orgUnitScopeDeps{store: orgStore, runtime: authzRuntime})
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/conversations", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/person/api/persons", routing.RouteDoc{Summary: "List persons.", Response: personListResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlePersonsAPI(w, r, personStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/person/api/persons", routing.RouteDoc{Summary: "Create or update a person.", Request: personUpsertPayload{}, Response: personMutationResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlePersonsAPI(w, r, personStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/person/api/persons:by-pernr", routing.RouteDoc{Summary: "Read a person by pernr.", Response: PersonDetail{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlePersonDetailAPI(w, r, personStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/person/api/persons:import", routing.RouteDoc{Summary: "Import persons.", Request: personImportPayload{}, Response: PersonImportResult{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlePersonsImportAPI(w, r, personStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/person/api/persons:delete", routing.RouteDoc{Summary: "Delete persons.", Request: personDeletePayload{}, Response: personDeleteResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlePersonsDeleteAPI(w, r, personStore, orgUnitManagers)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/conversations", routing.RouteDoc{Summary: "Create a conversation.", Request: cubeboxCreateConversationRequest{}, Response: cubebox.ConversationReplayResponse{}, Status: http.StatusCreated}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleCubeBoxConversationsAPI(w, r, cubeboxStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/internal/cubebox/conversations", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/internal/cubebox/conversations", routing.RouteDoc{Summary: "List conversations.", Response: cubebox.ConversationListResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleCubeBoxConversationsAPI(w, r, cubeboxStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/internal/cubebox/capabilities", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/internal/cubebox/capabilities", routing.RouteDoc{Summary: "CubeBox availability for the session.", Response: cubeboxCapabilitiesResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleCubeBoxCapabilitiesAPI(w, r, authzRuntime)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/internal/cubebox/conversations/{conversation_id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/internal/cubebox/conversations/{conversation_id}", routing.RouteDoc{Summary: "Replay a conversation.", Response: cubebox.ConversationReplayResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleCubeBoxConversationAPI(w, r, cubeboxStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPatch, "/internal/cubebox/conversations/{conversation_id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPatch, "/internal/cubebox/conversations/{conversation_id}", routing.RouteDoc{Summary: "Rename or archive a conversation.", Request: cubeboxConversationPatchRequest{}, Response: cubebox.ConversationReplayResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleCubeBoxConversationAPI(w, r, cubeboxStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/turns:stream", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/turns:stream", routing.RouteDoc{Summary: "Run a turn and stream its events.", Request: cubeboxStreamTurnRequest{}, ContentType: "text/event-stream"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleCubeBoxStreamTurnAPI(w, r, cubeboxRuntime, cubeboxStore, cubeboxGateway, cubeboxQueryFlow)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/turns/{turn_id}:interrupt", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/turns/{turn_id}:interrupt", routing.RouteDoc{Summary: "Interrupt a running turn.", Request: cubeboxInterruptRequest{}, Response: cubeboxInterruptResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleCubeBoxInterruptTurnAPI(w, r, cubeboxRuntime)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/internal/cubebox/settings", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/internal/cubebox/settings", routing.RouteDoc{Summary: "Model settings.", Response: cubebox.ModelSettingsSnapshot{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleCubeBoxSettingsAPI(w, r, cubeboxStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/settings/providers", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/settings/providers", routing.RouteDoc{Summary: "Create or update a model provider.", Request: cubeboxProviderUpsertRequest{}, Response: cubebox.ModelProvider{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleCubeBoxSettingsProvidersAPI(w, r, cubeboxStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/settings/credentials", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/settings/credentials", routing.RouteDoc{Summary: "Rotate a model credential.", Request: cubeboxCredentialRotateRequest{}, Response: cubebox.ModelCredential{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleCubeBoxSettingsCredentialsAPI(w, r, cubeboxStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/settings/credentials/{credential_id}:deactivate", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/settings/credentials/{credential_id}:deactivate", routing.RouteDoc{Summary: "Deactivate a model credential.", Response: cubebox.ModelCredential{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleCubeBoxSettingsCredentialDeactivateAPI(w, r, cubeboxStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/settings/selection", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/settings/selection", routing.RouteDoc{Summary: "Select the active model.", Request: cubeboxSelectionRequest{}, Response: cubebox.ActiveModelSelection{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleCubeBoxSettingsSelectionAPI(w, r, cubeboxStore)
 	}))
-	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/settings/verify", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/settings/verify", routing.RouteDoc{Summary: "Verify the active model.", Response: cubebox.ModelHealth{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleCubeBoxSettingsVerifyAPI(w, r, cubeboxStore)
 	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/internal/cubebox/settings/usage", routing.RouteDoc{Summary: "Token usage report.", Response: cubebox.UsageReport{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleCubeBoxSettingsUsageAPI(w, r, cubeboxStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/internal/cubebox/settings/budgets", routing.RouteDoc{Summary: "List token budgets.", Response: cubeboxTokenBudgetsResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleCubeBoxSettingsBudgetsAPI(w, r, cubeboxStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/settings/budgets", routing.RouteDoc{Summary: "Set or clear a token budget.", Request: cubeboxTokenBudgetRequest{}, Response: cubebox.TokenBudget{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleCubeBoxSettingsBudgetsAPI(w, r, cubeboxStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/internal/cubebox/settings/redaction", routing.RouteDoc{Summary: "Get the redaction policy and its recent changes.", Response: cubeboxRedactionPolicyResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleCubeBoxSettingsRedactionAPI(w, r, cubeboxStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/settings/redaction", routing.RouteDoc{Summary: "Replace the redaction policy.", Request: cubeboxRedactionPolicyRequest{}, Response: cubeboxRedactionPolicyResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleCubeBoxSettingsRedactionAPI(w, r, cubeboxStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/settings/redaction:preview", routing.RouteDoc{Summary: "Dry-run redaction of sample text.", Request: cubeboxRedactionPreviewRequest{}, Response: cubebox.RedactionPreview{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleCubeBoxSettingsRedactionPreviewAPI(w, r, cubeboxStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/internal/cubebox/settings/retention", routing.RouteDoc{Summary: "Get the conversation retention policy and recent purge runs.", Response: cubeboxRetentionPolicyResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleCubeBoxSettingsRetentionAPI(w, r, cubeboxStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/settings/retention", routing.RouteDoc{Summary: "Set the conversation retention policy.", Request: cubeboxRetentionPolicyRequest{}, Response: cubeboxRetentionPolicyResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleCubeBoxSettingsRetentionAPI(w, r, cubeboxStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/internal/cubebox/conversations/{conversation_id}:export", routing.RouteDoc{Summary: "Export a conversation or one turn with its query evidence; format=markdown returns text/markdown.", Response: cubebox.ConversationExport{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleCubeBoxConversationExportAPI(w, r, cubeboxStore, orgUnitScopeDeps{store: orgStore, runtime: authzRuntime})
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/internal/cubebox/shares", routing.RouteDoc{Summary: "List the caller's conversation shares.", Response: cubeboxShareListResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleCubeBoxSharesAPI(w, r, cubeboxStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/shares", routing.RouteDoc{Summary: "Share a conversation or one turn read-only with principals of the tenant.", Request: cubeboxShareCreateRequest{}, Response: cubebox.ConversationShare{}, Status: http.StatusCreated}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleCubeBoxSharesAPI(w, r, cubeboxStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/internal/cubebox/shares/{share_id}", routing.RouteDoc{Summary: "Open a conversation shared with the caller; format=markdown returns text/markdown.", Response: cubeboxSharedConversationResponse{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleCubeBoxShareAPI(w, r, cubeboxStore, orgUnitScopeDeps{store: orgStore, runtime: authzRuntime})
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/shares/{share_id}:revoke", routing.RouteDoc{Summary: "Revoke a conversation share.", Response: cubebox.ConversationShare{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleCubeBoxShareRevokeAPI(w, r, cubeboxStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodPost, "/internal/cubebox/conversations/{conversation_id}:feedback", routing.RouteDoc{Summary: "Rate a finished turn; appends a turn.feedback event.", Request: cubeboxTurnFeedbackRequest{}, Response: cubebox.CanonicalEvent{}, Status: http.StatusCreated}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleCubeBoxConversationFeedbackAPI(w, r, cubeboxStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/internal/cubebox/settings/feedback", routing.RouteDoc{Summary: "Answer feedback report by reason and operation.", Response: cubebox.TurnFeedbackReport{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleCubeBoxSettingsFeedbackAPI(w, r, cubeboxStore)
+	}))
+	router.HandleDoc(routing.RouteClassInternalAPI, http.MethodGet, "/internal/cubebox/settings/feedback:export", routing.RouteDoc{Summary: "Export rated turns as a cubebox-eval scenario suite.", Response: cubebox.EvalSuite{}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleCubeBoxSettingsFeedbackExportAPI(w, r, cubeboxStore)
+	}))
 	assetsSub, _ := fs.Sub(embeddedAssets, "assets")
 
 	entrypoint := http.NewServeMux()
@@ -391,7 +542,7 @@ func NewHandlerWithOptions(opts HandlerOptions) (http.Handler, error) {
 	}))
 	entrypoint.Handle("/", router)
 
-	guarded := withTenantAndSession(classifier, tenancyResolver, principals, sessions, withAuthz(classifier, authorizer, authzRuntime, entrypoint))
+	guarded := withTenantAndSession(classifier, tenancyResolver, principals, sessions, withImpersonationAudit(classifier, impersonations, withAuthz(classifier, authorizer, authzRuntime, entrypoint)))
 
 	mux := http.NewServeMux()
 	mux.Handle("/favicon.ico", http.RedirectHandler("/assets/web/favicon.svg", http.StatusMovedPermanently))
@@ -464,14 +615,14 @@ func withTenantAndSession(classifier *routing.Classifier, tenants TenancyResolve
 			next.ServeHTTP(w, r)
 			return
 		}
-		if path == "/app/login" || (path == "/iam/api/sessions" && r.Method == http.MethodPost) {
+		if path == "/app/login" || path == impersonationAcceptPath || (path == "/iam/api/sessions" && r.Method == http.MethodPost) {
 			next.ServeHTTP(w, r)
 			return
 		}
 
 		sid, ok := readSID(r)
 		if !ok {
-			if rc == routing.RouteClassInternalAPI || rc == routing.RouteClassPublicAPI || rc == routing.RouteClassWebhook {
+			if rc == routing.RouteClassInternalAPI || rc == routing.RouteClassPublicAPI || rc == routing.RouteClassWebhook || rc == routing.RouteClassOps {
 				routing.WriteError(w, r, rc, http.StatusUnauthorized, "unauthorized", "unauthorized")
 				return
 			}
@@ -486,7 +637,7 @@ func withTenantAndSession(classifier *routing.Classifier, tenants TenancyResolve
 		}
 		if !ok {
 			clearSIDCookie(w)
-			if rc == routing.RouteClassInternalAPI || rc == routing.RouteClassPublicAPI || rc == routing.RouteClassWebhook {
+			if rc == routing.RouteClassInternalAPI || rc == routing.RouteClassPublicAPI || rc == routing.RouteClassWebhook || rc == routing.RouteClassOps {
 				routing.WriteError(w, r, rc, http.StatusUnauthorized, "unauthorized", "unauthorized")
 				return
 			}
@@ -495,7 +646,7 @@ func withTenantAndSession(classifier *routing.Classifier, tenants TenancyResolve
 		}
 		if sess.TenantID != t.ID {
 			clearSIDCookie(w)
-			if rc == routing.RouteClassInternalAPI || rc == routing.RouteClassPublicAPI || rc == routing.RouteClassWebhook {
+			if rc == routing.RouteClassInternalAPI || rc == routing.RouteClassPublicAPI || rc == routing.RouteClassWebhook || rc == routing.RouteClassOps {
 				routing.WriteError(w, r, rc, http.StatusUnauthorized, "unauthorized", "unauthorized")
 				return
 			}
@@ -510,7 +661,7 @@ func withTenantAndSession(classifier *routing.Classifier, tenants TenancyResolve
 		}
 		if !ok || p.Status != "active" {
 			clearSIDCookie(w)
-			if rc == routing.RouteClassInternalAPI || rc == routing.RouteClassPublicAPI || rc == routing.RouteClassWebhook {
+			if rc == routing.RouteClassInternalAPI || rc == routing.RouteClassPublicAPI || rc == routing.RouteClassWebhook || rc == routing.RouteClassOps {
 				routing.WriteError(w, r, rc, http.StatusUnauthorized, "unauthorized", "unauthorized")
 				return
 			}
@@ -518,6 +669,9 @@ func withTenantAndSession(classifier *routing.Classifier, tenants TenancyResolve
 			return
 		}
 		r = r.WithContext(withPrincipal(r.Context(), p))
+		if sess.Impersonation != nil {
+			r = r.WithContext(withImpersonation(r.Context(), *sess.Impersonation))
+		}
 
 		next.ServeHTTP(w, r)
 	})
@@ -0,0 +1,335 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+)
+
+const (
+	impersonationAcceptPath      = "/iam/impersonation/accept"
+	impersonationHeader          = "X-Impersonation-Id"
+	impersonationLoggedPathLimit = 2048
+)
+
+var errImpersonationTicketInvalid = errors.New("server: impersonation ticket invalid")
+
+// SessionImpersonation describes the superadmin behind an impersonated tenant session.
+type SessionImpersonation struct {
+	ID         string    `json:"impersonation_id"`
+	Actor      string    `json:"superadmin_actor"`
+	ActorEmail string    `json:"superadmin_email"`
+	ReadOnly   bool      `json:"read_only"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type ImpersonationSessionSummary struct {
+	ID              string     `json:"impersonation_id"`
+	PrincipalID     string     `json:"principal_id"`
+	PrincipalEmail  string     `json:"principal_email"`
+	SuperadminEmail string     `json:"superadmin_email"`
+	Reason          string     `json:"reason"`
+	ReadOnly        bool       `json:"read_only"`
+	CreatedAt       time.Time  `json:"created_at"`
+	AcceptedAt      *time.Time `json:"accepted_at,omitempty"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"`
+	RequestCount    int64      `json:"request_count"`
+}
+
+type impersonationRequestEntry struct {
+	ImpersonationID string
+	TenantID        string
+	Actor           string
+	Method          string
+	Path            string
+	RequestID       string
+}
+
+type impersonationStore interface {
+	Accept(ctx context.Context, tenantID string, ticket string, ip string, userAgent string) (sid string, imp SessionImpersonation, err error)
+	LogRequest(ctx context.Context, entry impersonationRequestEntry) (int64, error)
+	CompleteRequest(ctx context.Context, tenantID string, logID int64, status int) error
+	ListForTenant(ctx context.Context, tenantID string) ([]ImpersonationSessionSummary, error)
+}
+
+type impersonationContextKey struct{}
+
+func withImpersonation(ctx context.Context, imp SessionImpersonation) context.Context {
+	return context.WithValue(ctx, impersonationContextKey{}, imp)
+}
+
+func currentImpersonation(ctx context.Context) (SessionImpersonation, bool) {
+	imp, ok := ctx.Value(impersonationContextKey{}).(SessionImpersonation)
+	return imp, ok
+}
+
+func newImpersonationStore(pool *pgxpool.Pool) impersonationStore {
+	if pool == nil {
+		return newMemoryImpersonationStore()
+	}
+	return &pgImpersonationStore{pool: pool}
+}
+
+// memoryImpersonationStore backs the DB-less dev server. Tickets are only ever issued by the superadmin
+// server, so Accept never succeeds here; request logging still works so the middleware behaves the same.
+type memoryImpersonationStore struct {
+	mu   sync.Mutex
+	logs []impersonationRequestEntry
+}
+
+func newMemoryImpersonationStore() *memoryImpersonationStore {
+	return &memoryImpersonationStore{}
+}
+
+func (s *memoryImpersonationStore) Accept(context.Context, string, string, string, string) (string, SessionImpersonation, error) {
+	return "", SessionImpersonation{}, errImpersonationTicketInvalid
+}
+
+func (s *memoryImpersonationStore) LogRequest(_ context.Context, entry impersonationRequestEntry) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs = append(s.logs, entry)
+	return int64(len(s.logs)), nil
+}
+
+func (s *memoryImpersonationStore) CompleteRequest(context.Context, string, int64, int) error {
+	return nil
+}
+
+func (s *memoryImpersonationStore) ListForTenant(context.Context, string) ([]ImpersonationSessionSummary, error) {
+	return []ImpersonationSessionSummary{}, nil
+}
+
+type impersonationDB interface {
+	pgBeginner
+	queryExecer
+}
+
+type pgImpersonationStore struct {
+	pool impersonationDB
+}
+
+// Accept redeems a one-time handoff ticket and creates the impersonated session in the same transaction,
+// so a ticket can never yield two sessions.
+func (s *pgImpersonationStore) Accept(ctx context.Context, tenantID string, ticket string, ip string, userAgent string) (string, SessionImpersonation, error) {
+	ticket = strings.TrimSpace(ticket)
+	if ticket == "" {
+		return "", SessionImpersonation{}, errImpersonationTicketInvalid
+	}
+	ticketSum := sha256.Sum256([]byte(ticket))
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return "", SessionImpersonation{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	var imp SessionImpersonation
+	var principalID string
+	if err := tx.QueryRow(ctx, `
+SELECT id::text, principal_id::text, superadmin_actor, superadmin_email, read_only, expires_at
+FROM iam.impersonation_sessions
+WHERE handoff_token_sha256 = $1
+  AND tenant_uuid = $2::uuid
+  AND accepted_at IS NULL
+  AND ended_at IS NULL
+  AND handoff_expires_at > now()
+FOR UPDATE
+`, ticketSum[:], tenantID).Scan(&imp.ID, &principalID, &imp.Actor, &imp.ActorEmail, &imp.ReadOnly, &imp.ExpiresAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", SessionImpersonation{}, errImpersonationTicketInvalid
+		}
+		return "", SessionImpersonation{}, err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE iam.impersonation_sessions SET accepted_at = now() WHERE id = $1::uuid;`, imp.ID); err != nil {
+		return "", SessionImpersonation{}, err
+	}
+
+	sid, tokenSha256, err := newSID()
+	if err != nil {
+		return "", SessionImpersonation{}, err
+	}
+	if _, err := tx.Exec(ctx, `
+INSERT INTO iam.sessions (token_sha256, tenant_uuid, principal_id, expires_at, ip, user_agent, impersonation_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7::uuid);
+`, tokenSha256, tenantID, principalID, imp.ExpiresAt, ip, userAgent, imp.ID); err != nil {
+		return "", SessionImpersonation{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return "", SessionImpersonation{}, err
+	}
+	return sid, imp, nil
+}
+
+func (s *pgImpersonationStore) LogRequest(ctx context.Context, entry impersonationRequestEntry) (int64, error) {
+	var id int64
+	err := s.pool.QueryRow(ctx, `
+INSERT INTO iam.impersonation_request_logs (impersonation_id, tenant_uuid, superadmin_actor, method, path, request_id)
+VALUES ($1::uuid, $2::uuid, $3, $4, $5, $6)
+RETURNING id;
+`, entry.ImpersonationID, entry.TenantID, entry.Actor, entry.Method, entry.Path, entry.RequestID).Scan(&id)
+	return id, err
+}
+
+func (s *pgImpersonationStore) CompleteRequest(ctx context.Context, tenantID string, logID int64, status int) error {
+	_, err := s.pool.Exec(ctx, `
+UPDATE iam.impersonation_request_logs
+SET status_code = $3, completed_at = now()
+WHERE tenant_uuid = $1::uuid AND id = $2;
+`, tenantID, logID, status)
+	return err
+}
+
+func (s *pgImpersonationStore) ListForTenant(ctx context.Context, tenantID string) ([]ImpersonationSessionSummary, error) {
+	rows, err := s.pool.Query(ctx, `
+SELECT i.id::text, i.principal_id::text, COALESCE(p.email, ''), i.superadmin_email, i.reason, i.read_only,
+  i.created_at, i.accepted_at, i.expires_at, i.ended_at,
+  (SELECT count(*) FROM iam.impersonation_request_logs l WHERE l.impersonation_id = i.id)
+FROM iam.impersonation_sessions i
+LEFT JOIN iam.principals p ON p.id = i.principal_id
+WHERE i.tenant_uuid = $1::uuid
+ORDER BY i.created_at DESC, i.id
+LIMIT 200;
+`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]ImpersonationSessionSummary, 0)
+	for rows.Next() {
+		var v ImpersonationSessionSummary
+		if err := rows.Scan(&v.ID, &v.PrincipalID, &v.PrincipalEmail, &v.SuperadminEmail, &v.Reason, &v.ReadOnly,
+			&v.CreatedAt, &v.AcceptedAt, &v.ExpiresAt, &v.EndedAt, &v.RequestCount); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func handleImpersonationAccept(w http.ResponseWriter, r *http.Request, store impersonationStore) {
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassAuthn, http.StatusInternalServerError, "tenant_missing", "tenant missing")
+		return
+	}
+	sid, _, err := store.Accept(r.Context(), tenant.ID, r.URL.Query().Get("ticket"), r.RemoteAddr, r.UserAgent())
+	if err != nil {
+		if errors.Is(err, errImpersonationTicketInvalid) {
+			routing.WriteError(w, r, routing.RouteClassAuthn, http.StatusUnauthorized, "impersonation_ticket_invalid", "impersonation ticket invalid")
+			return
+		}
+		routing.WriteError(w, r, routing.RouteClassAuthn, http.StatusInternalServerError, "session_error", "session error")
+		return
+	}
+	setSIDCookie(w, sid)
+	http.Redirect(w, r, "/app", http.StatusFound)
+}
+
+func handleImpersonationSessionsAPI(w http.ResponseWriter, r *http.Request, store impersonationStore) {
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "tenant_missing", "tenant missing")
+		return
+	}
+	items, err := store.ListForTenant(r.Context(), tenant.ID)
+	if err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "impersonation_list_error", "impersonation list error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"impersonation_sessions": items})
+}
+
+// withImpersonationAudit records every request made under an impersonated session before it is served and
+// enforces read-only impersonation. A request that cannot be recorded is refused.
+func withImpersonationAudit(classifier *routing.Classifier, store impersonationStore, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		imp, ok := currentImpersonation(r.Context())
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rc := routing.RouteClassUI
+		if classifier != nil {
+			rc = classifier.Classify(r.URL.Path)
+		}
+		tenant, _ := currentTenant(r.Context())
+
+		path := r.URL.RequestURI()
+		if len(path) > impersonationLoggedPathLimit {
+			path = path[:impersonationLoggedPathLimit]
+		}
+		logID, err := store.LogRequest(r.Context(), impersonationRequestEntry{
+			ImpersonationID: imp.ID,
+			TenantID:        tenant.ID,
+			Actor:           imp.Actor,
+			Method:          r.Method,
+			Path:            path,
+			RequestID:       strings.TrimSpace(r.Header.Get("X-Request-Id")),
+		})
+		if err != nil {
+			routing.WriteError(w, r, rc, http.StatusInternalServerError, "impersonation_audit_error", "impersonation audit error")
+			return
+		}
+
+		w.Header().Set(impersonationHeader, imp.ID)
+		rec := &impersonationStatusRecorder{ResponseWriter: w, status: http.StatusOK}
+		if imp.ReadOnly && !impersonationReadOnlyAllows(r.Method, r.URL.Path) {
+			routing.WriteError(rec, r, rc, http.StatusForbidden, "impersonation_read_only", "impersonation read only")
+		} else {
+			next.ServeHTTP(rec, r)
+		}
+		_ = store.CompleteRequest(context.WithoutCancel(r.Context()), tenant.ID, logID, rec.status)
+	})
+}
+
+func impersonationReadOnlyAllows(method string, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return method == http.MethodPost && path == "/logout"
+}
+
+type impersonationStatusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *impersonationStatusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *impersonationStatusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *impersonationStatusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *impersonationStatusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,455 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type stubImpersonationStore struct {
+	acceptTicket string
+	acceptSID    string
+	acceptErr    error
+	logErr       error
+	logged       []impersonationRequestEntry
+	completed    map[int64]int
+	listItems    []ImpersonationSessionSummary
+	listErr      error
+}
+
+func (s *stubImpersonationStore) Accept(_ context.Context, _ string, ticket string, _ string, _ string) (string, SessionImpersonation, error) {
+	s.acceptTicket = ticket
+	if s.acceptErr != nil {
+		return "", SessionImpersonation{}, s.acceptErr
+	}
+	return s.acceptSID, SessionImpersonation{ID: "imp1"}, nil
+}
+
+func (s *stubImpersonationStore) LogRequest(_ context.Context, entry impersonationRequestEntry) (int64, error) {
+	if s.logErr != nil {
+		return 0, s.logErr
+	}
+	s.logged = append(s.logged, entry)
+	return int64(len(s.logged)), nil
+}
+
+func (s *stubImpersonationStore) CompleteRequest(_ context.Context, _ string, logID int64, status int) error {
+	if s.completed == nil {
+		s.completed = map[int64]int{}
+	}
+	s.completed[logID] = status
+	return nil
+}
+
+func (s *stubImpersonationStore) ListForTenant(context.Context, string) ([]ImpersonationSessionSummary, error) {
+	return s.listItems, s.listErr
+}
+
+func impersonatedRequest(method string, target string, imp SessionImpersonation) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	ctx := withTenant(req.Context(), Tenant{ID: "t1", Domain: "localhost", Name: "T"})
+	return req.WithContext(withImpersonation(ctx, imp))
+}
+
+func TestWithImpersonationAudit(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	t.Run("passthrough without impersonation", func(t *testing.T) {
+		store := &stubImpersonationStore{}
+		rec := httptest.NewRecorder()
+		withImpersonationAudit(nil, store, okHandler).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/org/api/org-units", nil))
+		if rec.Code != http.StatusNoContent || len(store.logged) != 0 || rec.Header().Get(impersonationHeader) != "" {
+			t.Fatalf("status=%d logged=%d", rec.Code, len(store.logged))
+		}
+	})
+
+	t.Run("read only get is served and recorded", func(t *testing.T) {
+		store := &stubImpersonationStore{}
+		rec := httptest.NewRecorder()
+		req := impersonatedRequest(http.MethodGet, "/org/api/org-units?as_of=2026-01-01", SessionImpersonation{ID: "imp1", Actor: "sa1", ReadOnly: true})
+		req.Header.Set("X-Request-Id", " req-1 ")
+		withImpersonationAudit(mustInternalAPIClassifier(t), store, okHandler).ServeHTTP(rec, req)
+		if rec.Code != http.StatusNoContent || rec.Header().Get(impersonationHeader) != "imp1" {
+			t.Fatalf("status=%d header=%q", rec.Code, rec.Header().Get(impersonationHeader))
+		}
+		if len(store.logged) != 1 {
+			t.Fatalf("logged=%d", len(store.logged))
+		}
+		got := store.logged[0]
+		if got.ImpersonationID != "imp1" || got.TenantID != "t1" || got.Actor != "sa1" || got.Method != http.MethodGet ||
+			got.Path != "/org/api/org-units?as_of=2026-01-01" || got.RequestID != "req-1" {
+			t.Fatalf("entry=%+v", got)
+		}
+		if store.completed[1] != http.StatusNoContent {
+			t.Fatalf("completed=%v", store.completed)
+		}
+	})
+
+	t.Run("read only write is refused and recorded", func(t *testing.T) {
+		store := &stubImpersonationStore{}
+		rec := httptest.NewRecorder()
+		called := false
+		next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true })
+		req := impersonatedRequest(http.MethodPost, "/org/api/org-units", SessionImpersonation{ID: "imp1", ReadOnly: true})
+		withImpersonationAudit(mustInternalAPIClassifier(t), store, next).ServeHTTP(rec, req)
+		if called || rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "impersonation_read_only") {
+			t.Fatalf("called=%v status=%d body=%s", called, rec.Code, rec.Body.String())
+		}
+		if store.completed[1] != http.StatusForbidden {
+			t.Fatalf("completed=%v", store.completed)
+		}
+	})
+
+	t.Run("read only logout is allowed", func(t *testing.T) {
+		store := &stubImpersonationStore{}
+		rec := httptest.NewRecorder()
+		req := impersonatedRequest(http.MethodPost, "/logout", SessionImpersonation{ID: "imp1", ReadOnly: true})
+		withImpersonationAudit(nil, store, okHandler).ServeHTTP(rec, req)
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+
+	t.Run("writable session may write", func(t *testing.T) {
+		store := &stubImpersonationStore{}
+		rec := httptest.NewRecorder()
+		next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("ok")) })
+		req := impersonatedRequest(http.MethodPost, "/org/api/org-units", SessionImpersonation{ID: "imp1"})
+		withImpersonationAudit(nil, store, next).ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || store.completed[1] != http.StatusOK {
+			t.Fatalf("status=%d completed=%v", rec.Code, store.completed)
+		}
+	})
+
+	t.Run("audit failure refuses request", func(t *testing.T) {
+		store := &stubImpersonationStore{logErr: errors.New("boom")}
+		rec := httptest.NewRecorder()
+		called := false
+		next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true })
+		req := impersonatedRequest(http.MethodGet, "/org/api/org-units", SessionImpersonation{ID: "imp1"})
+		withImpersonationAudit(mustInternalAPIClassifier(t), store, next).ServeHTTP(rec, req)
+		if called || rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "impersonation_audit_error") {
+			t.Fatalf("called=%v status=%d body=%s", called, rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("long paths are truncated", func(t *testing.T) {
+		store := &stubImpersonationStore{}
+		rec := httptest.NewRecorder()
+		req := impersonatedRequest(http.MethodGet, "/app?q="+strings.Repeat("a", impersonationLoggedPathLimit), SessionImpersonation{ID: "imp1"})
+		withImpersonationAudit(nil, store, okHandler).ServeHTTP(rec, req)
+		if len(store.logged) != 1 || len(store.logged[0].Path) != impersonationLoggedPathLimit {
+			t.Fatalf("logged=%+v", store.logged)
+		}
+	})
+}
+
+func TestImpersonationStatusRecorder(t *testing.T) {
+	rec := httptest.NewRecorder()
+	r := &impersonationStatusRecorder{ResponseWriter: rec, status: http.StatusOK}
+	r.WriteHeader(http.StatusAccepted)
+	r.WriteHeader(http.StatusTeapot)
+	r.Flush()
+	if r.status != http.StatusAccepted || !rec.Flushed || r.Unwrap() != rec {
+		t.Fatalf("status=%d flushed=%v", r.status, rec.Flushed)
+	}
+}
+
+func TestHandleImpersonationAccept(t *testing.T) {
+	t.Run("missing tenant", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleImpersonationAccept(rec, httptest.NewRequest(http.MethodGet, impersonationAcceptPath+"?ticket=x", nil), &stubImpersonationStore{})
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+
+	newReq := func() *http.Request {
+		req := httptest.NewRequest(http.MethodGet, impersonationAcceptPath+"?ticket=tk", nil)
+		return req.WithContext(withTenant(req.Context(), Tenant{ID: "t1"}))
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		store := &stubImpersonationStore{acceptSID: "sid-imp"}
+		rec := httptest.NewRecorder()
+		handleImpersonationAccept(rec, newReq(), store)
+		if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/app" || store.acceptTicket != "tk" {
+			t.Fatalf("status=%d location=%q ticket=%q", rec.Code, rec.Header().Get("Location"), store.acceptTicket)
+		}
+		if !strings.Contains(rec.Header().Get("Set-Cookie"), sidCookieName+"=sid-imp") {
+			t.Fatalf("cookie=%q", rec.Header().Get("Set-Cookie"))
+		}
+	})
+
+	t.Run("invalid ticket", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleImpersonationAccept(rec, newReq(), &stubImpersonationStore{acceptErr: errImpersonationTicketInvalid})
+		if rec.Code != http.StatusUnauthorized || rec.Header().Get("Set-Cookie") != "" {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleImpersonationAccept(rec, newReq(), &stubImpersonationStore{acceptErr: errors.New("boom")})
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+}
+
+func TestHandleImpersonationSessionsAPI(t *testing.T) {
+	t.Run("missing tenant", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleImpersonationSessionsAPI(rec, httptest.NewRequest(http.MethodGet, "/iam/api/impersonation-sessions", nil), &stubImpersonationStore{})
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+
+	newReq := func() *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/iam/api/impersonation-sessions", nil)
+		return req.WithContext(withTenant(req.Context(), Tenant{ID: "t1"}))
+	}
+
+	t.Run("error", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleImpersonationSessionsAPI(rec, newReq(), &stubImpersonationStore{listErr: errors.New("boom")})
+		if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "impersonation_list_error") {
+			t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		store := &stubImpersonationStore{listItems: []ImpersonationSessionSummary{{ID: "imp1", SuperadminEmail: "sa@example.invalid", RequestCount: 3}}}
+		handleImpersonationSessionsAPI(rec, newReq(), store)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status=%d", rec.Code)
+		}
+		var body struct {
+			Items []ImpersonationSessionSummary `json:"impersonation_sessions"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Items) != 1 || body.Items[0].ID != "imp1" || body.Items[0].RequestCount != 3 {
+			t.Fatalf("items=%+v", body.Items)
+		}
+	})
+}
+
+func TestMemoryImpersonationStore(t *testing.T) {
+	ctx := context.Background()
+	s := newImpersonationStore(nil)
+	if _, _, err := s.Accept(ctx, "t1", "tk", "", ""); !errors.Is(err, errImpersonationTicketInvalid) {
+		t.Fatalf("err=%v", err)
+	}
+	if id, err := s.LogRequest(ctx, impersonationRequestEntry{ImpersonationID: "imp1"}); err != nil || id != 1 {
+		t.Fatalf("id=%d err=%v", id, err)
+	}
+	if err := s.CompleteRequest(ctx, "t1", 1, http.StatusOK); err != nil {
+		t.Fatal(err)
+	}
+	if items, err := s.ListForTenant(ctx, "t1"); err != nil || len(items) != 0 {
+		t.Fatalf("items=%v err=%v", items, err)
+	}
+}
+
+func TestPGImpersonationStore_Accept(t *testing.T) {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour).UTC()
+	okRow := scanRow{scan: func(dest ...any) error {
+		*(dest[0].(*string)) = "imp1"
+		*(dest[1].(*string)) = "p1"
+		*(dest[2].(*string)) = "sa1"
+		*(dest[3].(*string)) = "sa@example.invalid"
+		*(dest[4].(*bool)) = true
+		*(dest[5].(*time.Time)) = expiresAt
+		return nil
+	}}
+
+	t.Run("empty ticket", func(t *testing.T) {
+		s := &pgImpersonationStore{pool: &stubTx{}}
+		if _, _, err := s.Accept(ctx, "t1", " ", "", ""); !errors.Is(err, errImpersonationTicketInvalid) {
+			t.Fatalf("err=%v", err)
+		}
+	})
+
+	t.Run("unknown ticket", func(t *testing.T) {
+		s := &pgImpersonationStore{pool: &stubTx{rowErr: pgx.ErrNoRows}}
+		if _, _, err := s.Accept(ctx, "t1", "tk", "", ""); !errors.Is(err, errImpersonationTicketInvalid) {
+			t.Fatalf("err=%v", err)
+		}
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		s := &pgImpersonationStore{pool: &stubTx{rowErr: errors.New("boom")}}
+		if _, _, err := s.Accept(ctx, "t1", "tk", "", ""); err == nil || errors.Is(err, errImpersonationTicketInvalid) {
+			t.Fatalf("err=%v", err)
+		}
+	})
+
+	t.Run("begin error", func(t *testing.T) {
+		s := &pgImpersonationStore{pool: struct {
+			beginnerFunc
+			*stubQ
+		}{beginnerFunc(func(context.Context) (pgx.Tx, error) { return nil, errors.New("boom") }), &stubQ{}}}
+		if _, _, err := s.Accept(ctx, "t1", "tk", "", ""); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	for _, at := range []int{1, 2} {
+		s := &pgImpersonationStore{pool: &stubTx{row: okRow, execErr: errors.New("boom"), execErrAt: at}}
+		if _, _, err := s.Accept(ctx, "t1", "tk", "", ""); err == nil {
+			t.Fatalf("exec %d: expected error", at)
+		}
+	}
+
+	t.Run("commit error", func(t *testing.T) {
+		s := &pgImpersonationStore{pool: &stubTx{row: okRow, commitErr: errors.New("boom")}}
+		if _, _, err := s.Accept(ctx, "t1", "tk", "", ""); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		tx := &stubTx{row: okRow}
+		s := &pgImpersonationStore{pool: tx}
+		sid, imp, err := s.Accept(ctx, "t1", "tk", "127.0.0.1", "ua")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sid == "" || imp.ID != "imp1" || imp.Actor != "sa1" || !imp.ReadOnly || !imp.ExpiresAt.Equal(expiresAt) {
+			t.Fatalf("sid=%q imp=%+v", sid, imp)
+		}
+		if len(tx.execSQLs) != 2 || !strings.Contains(tx.execSQLs[1], "impersonation_id") {
+			t.Fatalf("exec=%v", tx.execSQLs)
+		}
+	})
+}
+
+type impersonationSummaryRows struct {
+	stubRows
+	acceptedAt *time.Time
+}
+
+func (r *impersonationSummaryRows) Scan(dest ...any) error {
+	*(dest[0].(*string)) = "imp1"
+	*(dest[1].(*string)) = "p1"
+	*(dest[2].(*string)) = "user@example.invalid"
+	*(dest[3].(*string)) = "sa@example.invalid"
+	*(dest[4].(*string)) = "support ticket"
+	*(dest[5].(*bool)) = true
+	*(dest[6].(*time.Time)) = time.Unix(100, 0).UTC()
+	*(dest[7].(**time.Time)) = r.acceptedAt
+	*(dest[8].(*time.Time)) = time.Unix(300, 0).UTC()
+	*(dest[9].(**time.Time)) = nil
+	*(dest[10].(*int64)) = 7
+	return nil
+}
+
+func TestPGImpersonationStore_RequestLogAndList(t *testing.T) {
+	ctx := context.Background()
+
+	s := &pgImpersonationStore{pool: &stubTx{row: scanRow{scan: func(dest ...any) error {
+		*(dest[0].(*int64)) = 42
+		return nil
+	}}}}
+	if id, err := s.LogRequest(ctx, impersonationRequestEntry{ImpersonationID: "imp1", TenantID: "t1"}); err != nil || id != 42 {
+		t.Fatalf("id=%d err=%v", id, err)
+	}
+	if err := s.CompleteRequest(ctx, "t1", 42, http.StatusOK); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&pgImpersonationStore{pool: &stubTx{execErr: errors.New("boom")}}).CompleteRequest(ctx, "t1", 42, http.StatusOK); err == nil {
+		t.Fatal("expected error")
+	}
+
+	acceptedAt := time.Unix(200, 0).UTC()
+	items, err := (&pgImpersonationStore{pool: &stubTx{rows: &impersonationSummaryRows{acceptedAt: &acceptedAt}}}).ListForTenant(ctx, "t1")
+	if err != nil || len(items) != 1 || items[0].ID != "imp1" || items[0].AcceptedAt == nil || items[0].EndedAt != nil || items[0].RequestCount != 7 {
+		t.Fatalf("items=%v err=%v", items, err)
+	}
+	if _, err := (&pgImpersonationStore{pool: &stubTx{queryErr: errors.New("boom")}}).ListForTenant(ctx, "t1"); err == nil {
+		t.Fatal("expected query error")
+	}
+	if _, err := (&pgImpersonationStore{pool: &stubTx{rows: &stubRows{scanErr: errors.New("boom")}}}).ListForTenant(ctx, "t1"); err == nil {
+		t.Fatal("expected scan error")
+	}
+	if _, err := (&pgImpersonationStore{pool: &stubTx{rows: &stubRows{empty: true, err: errors.New("boom")}}}).ListForTenant(ctx, "t1"); err == nil {
+		t.Fatal("expected rows error")
+	}
+}
+
+func TestPGSessionStore_Lookup_Impersonation(t *testing.T) {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour).UTC()
+	row := func(endedAt *time.Time) scanRow {
+		return scanRow{scan: func(dest ...any) error {
+			*(dest[0].(*string)) = "t1"
+			*(dest[1].(*string)) = "p1"
+			*(dest[2].(*time.Time)) = expiresAt
+			*(dest[3].(**time.Time)) = nil
+			*(dest[4].(*string)) = "imp1"
+			*(dest[5].(*string)) = "sa1"
+			*(dest[6].(*string)) = "sa@example.invalid"
+			*(dest[7].(*bool)) = true
+			*(dest[8].(**time.Time)) = endedAt
+			return nil
+		}}
+	}
+
+	out, ok, err := (&pgSessionStore{q: &stubQ{row: row(nil)}}).Lookup(ctx, "sid1")
+	if err != nil || !ok || out.Impersonation == nil {
+		t.Fatalf("ok=%v err=%v out=%+v", ok, err, out)
+	}
+	if out.Impersonation.ID != "imp1" || out.Impersonation.ActorEmail != "sa@example.invalid" || !out.Impersonation.ReadOnly || !out.Impersonation.ExpiresAt.Equal(expiresAt) {
+		t.Fatalf("impersonation=%+v", out.Impersonation)
+	}
+
+	ended := time.Now().UTC()
+	if _, ok, err := (&pgSessionStore{q: &stubQ{row: row(&ended)}}).Lookup(ctx, "sid1"); err != nil || ok {
+		t.Fatalf("ok=%v err=%v", ok, err)
+	}
+}
+
+func TestWithTenantAndSession_CarriesImpersonation(t *testing.T) {
+	imp := &SessionImpersonation{ID: "imp1", Actor: "sa1", ReadOnly: true}
+	sessions := &stubSessionStore{sess: Session{TenantID: "t1", PrincipalID: "p1", Impersonation: imp}, ok: true}
+	principals := &stubPrincipalStore{p: Principal{ID: "p1", TenantID: "t1", Status: "active"}, ok: true}
+	tenants := stubTenancyResolver{tenant: Tenant{ID: "t1", Domain: "localhost"}, ok: true}
+
+	var got SessionImpersonation
+	var found bool
+	h := withTenantAndSession(mustInternalAPIClassifier(t), tenants, principals, sessions, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, found = currentImpersonation(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/org/api/org-units", nil)
+	req.AddCookie(&http.Cookie{Name: sidCookieName, Value: "sid1"})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !found || got.ID != "imp1" || !got.ReadOnly {
+		t.Fatalf("status=%d found=%v got=%+v", rec.Code, found, got)
+	}
+
+	accept := httptest.NewRequest(http.MethodGet, "http://localhost"+impersonationAcceptPath+"?ticket=tk", nil)
+	rec = httptest.NewRecorder()
+	found = false
+	h.ServeHTTP(rec, accept)
+	if rec.Code != http.StatusOK || found {
+		t.Fatalf("accept status=%d found=%v", rec.Code, found)
+	}
+}
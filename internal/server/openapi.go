@@ -0,0 +1,258 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/authz"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/openapi"
+)
+
+// openAPIDocumentPath serves the route catalog to signed-in tenant users only: it lists every internal
+// endpoint, which is reconnaissance an anonymous caller should not get for free.
+const openAPIDocumentPath = "/openapi.json"
+
+// openAPIReservedRoutes are allowlisted ahead of their handlers; they are left out of the document
+// instead of failing the drift check.
+var openAPIReservedRoutes = map[string]bool{
+	"POST /internal/cubebox/conversations/{conversation_id}:compact": true,
+}
+
+var openAPIPathParamPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// BuildOpenAPIDocument describes every API route that is both allowlisted for the server entrypoint and
+// registered on the router, typed from the routing.RouteDoc each route was registered with. A route present
+// on only one side, registered without a doc, or with a different route class, is drift and fails the build
+// so the document can never silently disagree with what is served.
+func BuildOpenAPIDocument(allowlist routing.Allowlist, registered []routing.RegisteredRoute) (openapi.Document, error) {
+	server, ok := allowlist.Entrypoints["server"]
+	if !ok {
+		return openapi.Document{}, errors.New("openapi: allowlist missing server entrypoint")
+	}
+
+	registeredByID := map[string]routing.RegisteredRoute{}
+	for _, route := range registered {
+		registeredByID[routeCoverageID(route.Method, route.Path)] = route
+	}
+	requirementByID := map[string]routeRequirement{}
+	for _, req := range listRouteRequirements() {
+		requirementByID[routeCoverageID(req.Method, req.Path)] = req
+	}
+	overlayByID := map[string]apiToolOverlayDefinition{}
+	for _, overlay := range listAPIToolOverlayDefinitions() {
+		overlayByID[routeCoverageID(overlay.Method, overlay.Path)] = overlay
+	}
+
+	schemas := openapi.NewSchemas()
+	errorRef := schemas.For(routing.ErrorEnvelope{})
+	// Cubebox tools already publish response refs; register those names first so the document and the
+	// tool catalog agree on them.
+	for routeID, overlay := range overlayByID {
+		if route, ok := registeredByID[routeID]; ok && overlay.ResponseSchemaRef != "" && route.Doc.Response != nil {
+			schemas.Named(overlay.ResponseSchemaRef, route.Doc.Response)
+		}
+	}
+	doc := openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "Bugs-And-Blossoms API",
+			Version:     "1",
+			Description: "Generated from the route allowlist, router registrations and authz route requirements.",
+		},
+		Paths: map[string]*openapi.PathItem{},
+	}
+
+	var drift []string
+	allowlisted := map[string]bool{}
+	documented := map[string]bool{}
+	for _, route := range server.Routes {
+		if !openAPIDocumentedRouteClass(route.RouteClass) {
+			continue
+		}
+		for _, method := range route.Methods {
+			routeID := routeCoverageID(method, route.Path)
+			allowlisted[routeID] = true
+			registeredRoute, ok := registeredByID[routeID]
+			if !ok {
+				if !openAPIReservedRoutes[routeID] {
+					drift = append(drift, "allowlisted route is not registered: "+routeID)
+				}
+				continue
+			}
+			if string(registeredRoute.RouteClass) != route.RouteClass {
+				drift = append(drift, fmt.Sprintf("route class mismatch for %s: allowlist %s, router %s", routeID, route.RouteClass, registeredRoute.RouteClass))
+				continue
+			}
+			op := buildOpenAPIOperation(schemas, errorRef, route, method, registeredRoute.Doc, requirementByID, overlayByID)
+			item := doc.Paths[route.Path]
+			if item == nil {
+				item = &openapi.PathItem{}
+				doc.Paths[route.Path] = item
+			}
+			if !item.SetOperation(method, op) {
+				drift = append(drift, "unsupported method: "+routeID)
+				continue
+			}
+			documented[routeID] = true
+		}
+	}
+	for _, route := range registered {
+		routeID := routeCoverageID(route.Method, route.Path)
+		if !openAPIDocumentedRouteClass(string(route.RouteClass)) {
+			continue
+		}
+		if !allowlisted[routeID] {
+			drift = append(drift, "registered route is not allowlisted: "+routeID)
+		}
+		if route.Doc.Summary == "" {
+			drift = append(drift, "registered route has no route doc: "+routeID)
+		}
+	}
+	for routeID := range overlayByID {
+		if !documented[routeID] {
+			drift = append(drift, "api tool overlay route is not documented: "+routeID)
+		}
+	}
+	if len(drift) > 0 {
+		sort.Strings(drift)
+		return openapi.Document{}, fmt.Errorf("openapi drift: %s", strings.Join(drift, "; "))
+	}
+
+	doc.Components = openapi.Components{Schemas: schemas.Components()}
+	return doc, nil
+}
+
+func buildOpenAPIOperation(schemas *openapi.Schemas, errorRef *openapi.Schema, route routing.Route, method string, spec routing.RouteDoc, requirements map[string]routeRequirement, overlays map[string]apiToolOverlayDefinition) *openapi.Operation {
+	routeID := routeCoverageID(method, route.Path)
+	op := &openapi.Operation{
+		OperationID: openAPIOperationID(method, route.Path),
+		Summary:     spec.Summary,
+		Tags:        []string{ownerModuleForAllowlistRoute("server", route)},
+		RouteClass:  route.RouteClass,
+		Responses:   map[string]*openapi.Response{},
+	}
+	if req, ok := requirements[routeID]; ok {
+		op.AuthzCapabilityKey = authz.AuthzCapabilityKey(req.Object, req.Action)
+	}
+	for _, match := range openAPIPathParamPattern.FindAllStringSubmatch(route.Path, -1) {
+		op.Parameters = append(op.Parameters, openapi.Parameter{Name: match[1], In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}})
+	}
+
+	var responseSchema *openapi.Schema
+	if spec.Response != nil {
+		responseSchema = schemas.For(spec.Response)
+	}
+	if overlay, ok := overlays[routeID]; ok {
+		op.OperationID = overlay.OperationID
+		op.CubeBoxCallable = overlay.CubeBoxCallable
+		if op.Summary == "" {
+			op.Summary = overlay.UseSummary
+		}
+		op.Parameters = append(op.Parameters, openAPIQueryParameters(overlay.RequestSchema)...)
+	}
+	if spec.Request != nil {
+		op.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content:  map[string]*openapi.MediaType{"application/json": {Schema: schemas.For(spec.Request)}},
+		}
+	}
+
+	status := spec.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &openapi.Response{Description: http.StatusText(status)}
+	contentType := spec.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	switch {
+	case status == http.StatusNoContent || status == http.StatusFound:
+	case responseSchema != nil:
+		success.Content = map[string]*openapi.MediaType{contentType: {Schema: responseSchema}}
+	default:
+		success.Content = map[string]*openapi.MediaType{contentType: {Schema: &openapi.Schema{}}}
+	}
+	op.Responses[fmt.Sprint(status)] = success
+	op.Responses["default"] = &openapi.Response{
+		Description: "Error envelope.",
+		Content:     map[string]*openapi.MediaType{"application/json": {Schema: errorRef}},
+	}
+	return op
+}
+
+func openAPIQueryParameters(schema cubebox.APIToolRequestSchema) []openapi.Parameter {
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	names := append(append([]string(nil), schema.Required...), schema.Optional...)
+	out := make([]openapi.Parameter, 0, len(names))
+	for _, name := range names {
+		spec := schema.Params[name]
+		param := openapi.Parameter{Name: name, In: "query", Required: required[name], Description: spec.Description}
+		switch spec.Type {
+		case "boolean", "integer":
+			param.Schema = &openapi.Schema{Type: spec.Type}
+		case "date":
+			param.Schema = &openapi.Schema{Type: "string", Format: "date"}
+		default:
+			// Object params such as the org unit list filter travel as JSON text in the query string.
+			param.Schema = &openapi.Schema{Type: "string"}
+		}
+		out = append(out, param)
+	}
+	return out
+}
+
+func openAPIDocumentedRouteClass(routeClass string) bool {
+	switch routing.RouteClass(routeClass) {
+	case routing.RouteClassUI, routing.RouteClassStatic:
+		return false
+	default:
+		return true
+	}
+}
+
+func openAPIOperationID(method string, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, r := range path {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r >= 'A' && r <= 'Z':
+			b.WriteRune(r + ('a' - 'A'))
+		case r == '{' || r == '}':
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return strings.TrimRight(b.String(), "_")
+}
+
+// openAPIDocumentHandler builds the document on first request, after every route has been registered.
+func openAPIDocumentHandler(allowlist routing.Allowlist, router *routing.Router) http.Handler {
+	var (
+		once sync.Once
+		doc  openapi.Document
+		err  error
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() {
+			doc, err = BuildOpenAPIDocument(allowlist, router.Routes())
+		})
+		if err != nil {
+			routing.WriteError(w, r, routing.RouteClassOps, http.StatusInternalServerError, "openapi_drift", "openapi drift")
+			return
+		}
+		writeJSON(w, http.StatusOK, doc)
+	})
+}
@@ -0,0 +1,233 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/authz"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/openapi"
+)
+
+func openAPITestAllowlistPath(t *testing.T) string {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Clean(filepath.Join(wd, "..", "..", "config", "routing", "allowlist.yaml"))
+}
+
+func TestOpenAPIDocument_ServedAndCoversAllowlist(t *testing.T) {
+	allowlistPath := openAPITestAllowlistPath(t)
+	t.Setenv("ALLOWLIST_PATH", allowlistPath)
+
+	h, err := NewHandlerWithOptions(HandlerOptions{
+		TenancyResolver: localTenancyResolver(),
+		IdentityProvider: staticIdentityProvider{ident: authenticatedIdentity{
+			KratosIdentityID: "00000000-0000-0000-0000-0000000000aa",
+			Email:            "tenant-admin@example.invalid",
+		}},
+		OrgUnitStore: newOrgUnitMemoryStore(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, openAPIDocumentPath, nil)
+	req.Host = "localhost:8080"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	loginReq := httptest.NewRequest(http.MethodPost, "/iam/api/sessions", strings.NewReader(`{"email":"tenant-admin@example.invalid","password":"pw"}`))
+	loginReq.Host = "localhost:8080"
+	loginReq.Header.Set("Content-Type", "application/json")
+	loginRec := httptest.NewRecorder()
+	h.ServeHTTP(loginRec, loginReq)
+	var session *http.Cookie
+	for _, c := range loginRec.Result().Cookies() {
+		if c.Name == sidCookieName {
+			session = c
+		}
+	}
+	if session == nil {
+		t.Fatalf("login status=%d", loginRec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, openAPIDocumentPath, nil)
+	req.Host = "localhost:8080"
+	req.AddCookie(session)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	var doc openapi.Document
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != "3.1.0" {
+		t.Fatalf("openapi=%q", doc.OpenAPI)
+	}
+
+	allowlist, err := routing.LoadAllowlist(allowlistPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, route := range allowlist.Entrypoints["server"].Routes {
+		if !openAPIDocumentedRouteClass(route.RouteClass) {
+			if doc.Paths[route.Path] != nil {
+				t.Fatalf("%s route documented: %s", route.RouteClass, route.Path)
+			}
+			continue
+		}
+		for _, method := range route.Methods {
+			routeID := routeCoverageID(method, route.Path)
+			if openAPIReservedRoutes[routeID] {
+				continue
+			}
+			item := doc.Paths[route.Path]
+			if item == nil || item.Operation(method) == nil {
+				t.Fatalf("missing operation %s", routeID)
+			}
+			if op := item.Operation(method); op.RouteClass != route.RouteClass {
+				t.Fatalf("%s x-route-class=%q", routeID, op.RouteClass)
+			}
+		}
+	}
+
+	for _, req := range listRouteRequirements() {
+		item := doc.Paths[req.Path]
+		if item == nil || item.Operation(req.Method) == nil {
+			continue
+		}
+		want := authz.AuthzCapabilityKey(req.Object, req.Action)
+		if got := item.Operation(req.Method).AuthzCapabilityKey; got != want {
+			t.Fatalf("%s %s capability=%q want %q", req.Method, req.Path, got, want)
+		}
+	}
+	if got := doc.Paths["/health"].Get.AuthzCapabilityKey; got != "" {
+		t.Fatalf("health capability=%q", got)
+	}
+
+	for _, overlay := range listAPIToolOverlayDefinitions() {
+		op := doc.Paths[overlay.Path].Operation(overlay.Method)
+		if op.OperationID != overlay.OperationID {
+			t.Fatalf("%s operationId=%q", overlay.Path, op.OperationID)
+		}
+		if overlay.ResponseSchemaRef == "" {
+			continue
+		}
+		if _, ok := doc.Components.Schemas[overlay.ResponseSchemaRef]; !ok {
+			t.Fatalf("missing component %s", overlay.ResponseSchemaRef)
+		}
+		if got := op.Responses["200"].Content["application/json"].Schema.Ref; got != "#/components/schemas/"+overlay.ResponseSchemaRef {
+			t.Fatalf("%s response ref=%q", overlay.Path, got)
+		}
+	}
+
+	list := doc.Paths["/org/api/org-units"].Get
+	params := map[string]openapi.Parameter{}
+	for _, p := range list.Parameters {
+		params[p.Name] = p
+	}
+	if p, ok := params["as_of"]; !ok || p.In != "query" {
+		t.Fatalf("list params=%+v", list.Parameters)
+	}
+
+	role := doc.Paths["/iam/api/authz/roles/{role_slug}"].Put
+	if len(role.Parameters) != 1 || role.Parameters[0].In != "path" || role.Parameters[0].Name != "role_slug" {
+		t.Fatalf("role params=%+v", role.Parameters)
+	}
+	if role.RequestBody == nil || role.RequestBody.Content["application/json"].Schema.Ref == "" {
+		t.Fatalf("role request=%+v", role.RequestBody)
+	}
+	if _, ok := doc.Paths["/iam/api/sessions"].Post.Responses["204"]; !ok {
+		t.Fatalf("sessions responses=%+v", doc.Paths["/iam/api/sessions"].Post.Responses)
+	}
+	if _, ok := doc.Components.Schemas["ErrorEnvelope"]; !ok {
+		t.Fatal("missing error envelope component")
+	}
+}
+
+func TestOpenAPIBuild_DetectsDrift(t *testing.T) {
+	allowlist := routing.Allowlist{Entrypoints: map[string]routing.Entrypoint{
+		"server": {Routes: []routing.Route{
+			{Path: "/health", Methods: []string{http.MethodGet}, RouteClass: string(routing.RouteClassOps)},
+			{Path: "/iam/api/dicts", Methods: []string{http.MethodGet}, RouteClass: string(routing.RouteClassInternalAPI)},
+			{Path: "/app/home", Methods: []string{http.MethodGet}, RouteClass: string(routing.RouteClassUI)},
+		}},
+	}}
+
+	cases := []struct {
+		name       string
+		registered []routing.RegisteredRoute
+		want       string
+	}{
+		{
+			name:       "allowlisted but not registered",
+			registered: []routing.RegisteredRoute{{Method: http.MethodGet, Path: "/health", RouteClass: routing.RouteClassOps}},
+			want:       "allowlisted route is not registered: GET /iam/api/dicts",
+		},
+		{
+			name: "registered but not allowlisted",
+			registered: []routing.RegisteredRoute{
+				{Method: http.MethodGet, Path: "/health", RouteClass: routing.RouteClassOps},
+				{Method: http.MethodGet, Path: "/iam/api/dicts", RouteClass: routing.RouteClassInternalAPI},
+				{Method: http.MethodGet, Path: "/iam/api/secret", RouteClass: routing.RouteClassInternalAPI},
+			},
+			want: "registered route is not allowlisted: GET /iam/api/secret",
+		},
+		{
+			name: "route class mismatch",
+			registered: []routing.RegisteredRoute{
+				{Method: http.MethodGet, Path: "/health", RouteClass: routing.RouteClassOps},
+				{Method: http.MethodGet, Path: "/iam/api/dicts", RouteClass: routing.RouteClassPublicAPI},
+			},
+			want: "route class mismatch for GET /iam/api/dicts",
+		},
+		{
+			name: "registered without a route doc",
+			registered: []routing.RegisteredRoute{
+				{Method: http.MethodGet, Path: "/health", RouteClass: routing.RouteClassOps, Doc: routing.RouteDoc{Summary: "Liveness probe."}},
+				{Method: http.MethodGet, Path: "/iam/api/dicts", RouteClass: routing.RouteClassInternalAPI},
+			},
+			want: "registered route has no route doc: GET /iam/api/dicts",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := BuildOpenAPIDocument(allowlist, tc.registered)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("err=%v", err)
+			}
+		})
+	}
+
+	if _, err := BuildOpenAPIDocument(routing.Allowlist{}, nil); err == nil {
+		t.Fatal("expected missing server entrypoint error")
+	}
+}
+
+func TestOpenAPIOperationID(t *testing.T) {
+	cases := map[string][2]string{
+		"get_iam_api_dicts":                               {http.MethodGet, "/iam/api/dicts"},
+		"post_iam_api_dicts_release_preview":              {http.MethodPost, "/iam/api/dicts:release:preview"},
+		"put_iam_api_authz_roles_role_slug":               {http.MethodPut, "/iam/api/authz/roles/{role_slug}"},
+		"post_internal_cubebox_turns_turn_id_interrupt":   {http.MethodPost, "/internal/cubebox/turns/{turn_id}:interrupt"},
+		"get_org_api_org_units_field_configs_enable_cand": {http.MethodGet, "/org/api/org-units/field-configs:enable-cand"},
+	}
+	for want, in := range cases {
+		if got := openAPIOperationID(in[0], in[1]); got != want {
+			t.Fatalf("openAPIOperationID(%s %s)=%q want %q", in[0], in[1], got, want)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -17,6 +18,7 @@ import (
 	orgunitservices "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/services"
 	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/authz"
 	orgunitpkg "github.com/jacksonlee411/Bugs-And-Blossoms/pkg/orgunit"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/pagecursor"
 )
 
 type orgUnitBusinessUnitAPIRequest struct {
@@ -79,14 +81,17 @@ type orgUnitListItem struct {
 	HasChildren        *bool    `json:"has_children,omitempty"`
 	HasVisibleChildren *bool    `json:"has_visible_children,omitempty"`
 	PathOrgNodeKeys    []string `json:"-"`
+	SortValue          *string  `json:"-"`
 }
 
 type orgUnitListResponse struct {
 	AsOf            string            `json:"as_of"`
+	KnownAt         string            `json:"known_at,omitempty"`
 	IncludeDisabled bool              `json:"include_disabled"`
 	Page            *int              `json:"page,omitempty"`
 	Size            *int              `json:"size,omitempty"`
 	Total           *int              `json:"total,omitempty"`
+	NextCursor      string            `json:"next_cursor,omitempty"`
 	OrgUnits        []orgUnitListItem `json:"org_units"`
 }
 
@@ -119,8 +124,10 @@ type orgUnitExtFieldAPIItem struct {
 
 type orgUnitDetailsAPIResponse struct {
 	AsOf      string                   `json:"as_of"`
+	KnownAt   string                   `json:"known_at,omitempty"`
 	OrgUnit   orgUnitDetailsAPIItem    `json:"org_unit"`
 	ExtFields []orgUnitExtFieldAPIItem `json:"ext_fields"`
+	Lineage   []orgUnitLineageAPIItem  `json:"lineage,omitempty"`
 }
 
 type orgUnitVersionAPIItem struct {
@@ -132,6 +139,7 @@ type orgUnitVersionAPIItem struct {
 
 type orgUnitVersionsAPIResponse struct {
 	OrgCode  string                  `json:"org_code"`
+	KnownAt  string                  `json:"known_at,omitempty"`
 	Versions []orgUnitVersionAPIItem `json:"versions"`
 }
 
@@ -155,10 +163,13 @@ type orgUnitAuditAPIItem struct {
 }
 
 type orgUnitAuditAPIResponse struct {
-	OrgCode string                `json:"org_code"`
-	Limit   int                   `json:"limit"`
-	HasMore bool                  `json:"has_more"`
-	Events  []orgUnitAuditAPIItem `json:"events"`
+	OrgCode    string                             `json:"org_code"`
+	Limit      int                                `json:"limit"`
+	HasMore    bool                               `json:"has_more"`
+	NextCursor string                             `json:"next_cursor,omitempty"`
+	Events     []orgUnitAuditAPIItem              `json:"events"`
+	Lineage    []orgUnitLineageAPIItem            `json:"lineage,omitempty"`
+	Operations []orgUnitCompositeOperationAPIItem `json:"operations,omitempty"`
 }
 
 type orgUnitCreateAPIRequest struct {
@@ -264,6 +275,13 @@ const (
 	orgUnitErrHasDependenciesCannotDelete = "ORG_HAS_DEPENDENCIES_CANNOT_DELETE"
 	orgUnitErrEventRescinded              = "ORG_EVENT_RESCINDED"
 	orgUnitErrHighRiskReorderForbidden    = "ORG_HIGH_RISK_REORDER_FORBIDDEN"
+	orgUnitErrAlreadyExists               = "ORG_ALREADY_EXISTS"
+	orgUnitErrMergeSurvivorInvalid        = "ORG_MERGE_SURVIVOR_INVALID"
+	orgUnitErrRootCannotBeMerged          = "ORG_ROOT_CANNOT_BE_MERGED"
+	orgUnitErrRootCannotBeSplit           = "ORG_ROOT_CANNOT_BE_SPLIT"
+	orgUnitErrSplitChildNotUnderSource    = "ORG_SPLIT_CHILD_NOT_UNDER_SOURCE"
+	orgUnitErrSplitSourceHasChildren      = "ORG_SPLIT_SOURCE_HAS_CHILDREN"
+	orgUnitErrCompositeTargetDisabled     = "ORG_COMPOSITE_TARGET_DISABLED"
 
 	orgUnitErrFieldDefinitionNotFound            = "ORG_FIELD_DEFINITION_NOT_FOUND"
 	orgUnitErrFieldConfigInvalidDataSourceConfig = "ORG_FIELD_CONFIG_INVALID_DATA_SOURCE_CONFIG"
@@ -286,6 +304,8 @@ const (
 	orgUnitErrOrgCodeConflict                    = "ORG_CODE_CONFLICT"
 	orgUnitErrFieldPolicyScopeOverlap            = "FIELD_POLICY_SCOPE_OVERLAP"
 	orgUnitErrFieldPolicyNotFound                = "ORG_FIELD_POLICY_NOT_FOUND"
+	orgUnitErrFieldValidationFailed              = "FIELD_VALIDATION_FAILED"
+	orgUnitErrFieldValidationRuleNotFound        = "ORG_FIELD_VALIDATION_RULE_NOT_FOUND"
 )
 
 const (
@@ -316,9 +336,12 @@ type orgUnitListQueryOptions struct {
 	SortOrder          string
 	ExtFilterFieldKey  string
 	ExtFilterValue     string
+	Filter             *orgunitservices.OrgUnitListFilter
 	Paginate           bool
 	Page               int
 	PageSize           int
+	CursorMode         bool
+	Cursor             string
 }
 
 func parseOrgUnitListQueryOptions(values url.Values) (orgUnitListQueryOptions, bool, error) {
@@ -458,6 +481,17 @@ func parseOrgUnitListQueryOptions(values url.Values) (orgUnitListQueryOptions, b
 		}
 	}
 
+	if hasKey("cursor") {
+		// An empty cursor starts keyset paging; page/offset paging stays available for older callers.
+		hasAny = true
+		if pagePresent {
+			return orgUnitListQueryOptions{}, false, errors.New("cursor cannot be combined with page")
+		}
+		opts.CursorMode = true
+		opts.Paginate = true
+		opts.Cursor = strings.TrimSpace(values.Get("cursor"))
+	}
+
 	extFilterKeyPresent := hasKey("ext_filter_field_key")
 	extFilterValuePresent := hasKey("ext_filter_value")
 	if extFilterKeyPresent || extFilterValuePresent {
@@ -472,7 +506,16 @@ func parseOrgUnitListQueryOptions(values url.Values) (orgUnitListQueryOptions, b
 		}
 	}
 
-	if (opts.ExtFilterFieldKey != "" || opts.ExtSortFieldKey != "") && !opts.GridMode && !opts.Paginate {
+	if hasKey("filter") {
+		hasAny = true
+		filter, err := orgunitservices.ParseOrgUnitListFilter(values.Get("filter"))
+		if err != nil {
+			return orgUnitListQueryOptions{}, false, errors.New("filter invalid: " + strings.TrimPrefix(err.Error(), orgunitservices.ErrOrgUnitListFilterInvalid.Error()+": "))
+		}
+		opts.Filter = filter
+	}
+
+	if (opts.ExtFilterFieldKey != "" || opts.ExtSortFieldKey != "" || opts.Filter != nil) && !opts.GridMode && !opts.Paginate {
 		return orgUnitListQueryOptions{}, false, errors.New("ext query requires mode=grid or pagination")
 	}
 
@@ -593,7 +636,7 @@ func ensureCurrentPrincipalOrgCodeScopeAllows(ctx context.Context, store OrgUnit
 	return nil
 }
 
-func resolveOrgUnitReadNodeForCurrentPrincipal(ctx context.Context, store OrgUnitStore, runtime authzRuntimeStore, tenantID string, orgCode string, asOf string, includeDisabled bool, caller string) (orgunitservices.OrgUnitReadNode, error) {
+func resolveOrgUnitReadNodeForCurrentPrincipal(ctx context.Context, store OrgUnitStore, runtime authzRuntimeStore, tenantID string, orgCode string, asOf string, knownAt string, includeDisabled bool, caller string) (orgunitservices.OrgUnitReadNode, error) {
 	orgCode = strings.TrimSpace(orgCode)
 	if orgCode == "" {
 		return orgunitservices.OrgUnitReadNode{}, orgunitservices.ErrOrgUnitReadInvalidArgument
@@ -612,6 +655,7 @@ func resolveOrgUnitReadNodeForCurrentPrincipal(ctx context.Context, store OrgUni
 		ScopeFilter:     scopeFilter,
 		OrgCodes:        []string{orgCode},
 		IncludeDisabled: includeDisabled,
+		KnownAt:         knownAt,
 		Caller:          caller,
 	})
 	if err != nil {
@@ -773,6 +817,14 @@ func writeOrgUnitReadServiceError(w http.ResponseWriter, r *http.Request, err er
 		writeOrgUnitScopeError(w, r, errAuthzScopeForbidden)
 	case errors.Is(err, orgunitservices.ErrOrgUnitReadExtQueryNotAllowed):
 		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, orgUnitErrExtQueryFieldNotAllowed, "ext query not allowed")
+	case errors.Is(err, orgunitservices.ErrOrgUnitListFilterInvalid):
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "filter invalid")
+	case errors.Is(err, orgunitservices.ErrOrgUnitReadKnownAtInvalid):
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, orgUnitErrKnownAtInvalid, "known_at must be an RFC 3339 timestamp")
+	case errors.Is(err, errOrgUnitCursorInvalid):
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, orgUnitErrCursorInvalid, "cursor invalid")
+	case errors.Is(err, orgunitservices.ErrOrgUnitReadKnownAtUnsupported):
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "orgunit_known_at_unsupported", "orgunit store cannot replay known_at")
 	case errors.Is(err, orgunitpkg.ErrOrgCodeInvalid):
 		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "org_code_invalid", "org_code invalid")
 	case errors.Is(err, orgunitservices.ErrOrgUnitReadInvalidArgument):
@@ -802,6 +854,7 @@ func handleOrgUnitsAPI(w http.ResponseWriter, r *http.Request, store OrgUnitStor
 		}
 		q := r.URL.Query()
 		includeDisabled := parseIncludeDisabled(q.Get("include_disabled"))
+		knownAt := orgUnitKnownAtFromURL(r)
 
 		listOpts, hasListOpts, err := parseOrgUnitListQueryOptions(q)
 		if err != nil {
@@ -822,9 +875,20 @@ func handleOrgUnitsAPI(w http.ResponseWriter, r *http.Request, store OrgUnitStor
 			var pagePtr *int
 			var sizePtr *int
 			var totalPtr *int
+			var keyset *orgunitservices.OrgUnitListKeyset
 			limit := 0
 			offset := 0
-			if listOpts.Paginate {
+			cursorScope := pagecursor.Scope(tenant.ID, orgUnitCursorKindList, q)
+			if listOpts.CursorMode {
+				keyset, err = orgUnitListKeysetFromCursor(listOpts.Cursor, orgUnitCursorKindList, cursorScope)
+				if err != nil {
+					writeOrgUnitReadServiceError(w, r, err, "orgunit_list_failed")
+					return
+				}
+				// One extra row tells whether another page follows.
+				limit = listOpts.PageSize + 1
+				sizePtr = &listOpts.PageSize
+			} else if listOpts.Paginate {
 				limit = listOpts.PageSize
 				offset = listOpts.Page * listOpts.PageSize
 				pagePtr = &listOpts.Page
@@ -846,15 +910,24 @@ func handleOrgUnitsAPI(w http.ResponseWriter, r *http.Request, store OrgUnitStor
 				SortOrder:          listOpts.SortOrder,
 				ExtFilterFieldKey:  listOpts.ExtFilterFieldKey,
 				ExtFilterValue:     listOpts.ExtFilterValue,
+				Filter:             listOpts.Filter,
 				IncludeDisabled:    includeDisabled,
 				Limit:              limit,
 				Offset:             offset,
+				Keyset:             keyset,
+				KnownAt:            knownAt,
 				Caller:             "orgunit.http.list",
 			})
 			if err != nil {
 				writeOrgUnitReadServiceError(w, r, err, "orgunit_list_failed")
 				return
 			}
+			nextCursor := ""
+			if listOpts.CursorMode && len(nodes) > listOpts.PageSize {
+				nodes = nodes[:listOpts.PageSize]
+				last := orgunitservices.OrgUnitListKeysetOf(nodes[len(nodes)-1], listOpts.SortField)
+				nextCursor = orgUnitListNextCursor(orgUnitCursorKindList, cursorScope, last)
+			}
 			items := orgUnitListItemsFromReadNodes(nodes)
 
 			if listOpts.Paginate {
@@ -864,10 +937,12 @@ func handleOrgUnitsAPI(w http.ResponseWriter, r *http.Request, store OrgUnitStor
 			w.Header().Set("Content-Type", "application/json; charset=utf-8")
 			_ = json.NewEncoder(w).Encode(orgUnitListResponse{
 				AsOf:            asOf,
+				KnownAt:         knownAt,
 				IncludeDisabled: includeDisabled,
 				Page:            pagePtr,
 				Size:            sizePtr,
 				Total:           totalPtr,
+				NextCursor:      nextCursor,
 				OrgUnits:        items,
 			})
 			return
@@ -892,6 +967,7 @@ func handleOrgUnitsAPI(w http.ResponseWriter, r *http.Request, store OrgUnitStor
 				ScopeFilter:     scopeFilter,
 				ParentOrgCode:   normalizedParentCode,
 				IncludeDisabled: includeDisabled,
+				KnownAt:         knownAt,
 				Caller:          "orgunit.http.children",
 			})
 			if err != nil {
@@ -903,6 +979,7 @@ func handleOrgUnitsAPI(w http.ResponseWriter, r *http.Request, store OrgUnitStor
 			w.Header().Set("Content-Type", "application/json; charset=utf-8")
 			_ = json.NewEncoder(w).Encode(orgUnitListResponse{
 				AsOf:            asOf,
+				KnownAt:         knownAt,
 				IncludeDisabled: includeDisabled,
 				OrgUnits:        items,
 			})
@@ -914,6 +991,7 @@ func handleOrgUnitsAPI(w http.ResponseWriter, r *http.Request, store OrgUnitStor
 			AsOf:            asOf,
 			ScopeFilter:     scopeFilter,
 			IncludeDisabled: includeDisabled,
+			KnownAt:         knownAt,
 			Caller:          "orgunit.http.roots",
 		})
 		if err != nil {
@@ -925,6 +1003,7 @@ func handleOrgUnitsAPI(w http.ResponseWriter, r *http.Request, store OrgUnitStor
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 		_ = json.NewEncoder(w).Encode(orgUnitListResponse{
 			AsOf:            asOf,
+			KnownAt:         knownAt,
 			IncludeDisabled: includeDisabled,
 			OrgUnits:        items,
 		})
@@ -998,18 +1077,23 @@ func handleOrgUnitsDetailsAPI(w http.ResponseWriter, r *http.Request, store OrgU
 		return
 	}
 	includeDisabled := parseIncludeDisabled(r.URL.Query().Get("include_disabled"))
+	knownAt := orgUnitKnownAtFromURL(r)
 
 	rawCode := strings.TrimSpace(r.URL.Query().Get("org_code"))
 	if rawCode == "" {
 		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "org_code required")
 		return
 	}
-	resolved, err := resolveOrgUnitReadNodeForCurrentPrincipal(r.Context(), store, runtimeStoreFromVariadic(runtime), tenant.ID, rawCode, asOf, includeDisabled, "orgunit.http.details")
+	resolved, err := resolveOrgUnitReadNodeForCurrentPrincipal(r.Context(), store, runtimeStoreFromVariadic(runtime), tenant.ID, rawCode, asOf, knownAt, includeDisabled, "orgunit.http.details")
 	if err != nil {
 		writeOrgUnitReadServiceError(w, r, err, "orgunit_details_failed")
 		return
 	}
 	orgNodeKey := strings.TrimSpace(resolved.OrgNodeKey)
+	if knownAt != "" {
+		writeOrgUnitKnownAtDetails(w, r, store, tenant.ID, orgNodeKey, asOf, knownAt, includeDisabled)
+		return
+	}
 
 	details, err := getNodeDetailsByVisibilityByNodeKey(r.Context(), store, tenant.ID, orgNodeKey, asOf, includeDisabled)
 	if err != nil {
@@ -1060,6 +1144,12 @@ func handleOrgUnitsDetailsAPI(w http.ResponseWriter, r *http.Request, store OrgU
 		return
 	}
 	resp.ExtFields = extFields
+	lineage, err := orgUnitLineageItems(r.Context(), store, tenant.ID, detailsOrgNodeKey)
+	if err != nil {
+		writeInternalAPIError(w, r, err, "orgunit_details_lineage_failed")
+		return
+	}
+	resp.Lineage = lineage
 
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
@@ -1082,6 +1172,10 @@ func handleOrgUnitsVersionsAPI(w http.ResponseWriter, r *http.Request, store Org
 		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "org_code required")
 		return
 	}
+	if knownAt := orgUnitKnownAtFromURL(r); knownAt != "" {
+		writeOrgUnitKnownAtVersions(w, r, store, runtimeStoreFromVariadic(runtime), tenant.ID, rawCode, knownAt)
+		return
+	}
 	normalized, orgNodeKey, err := resolveOrgUnitHistoryTargetForCurrentPrincipal(r.Context(), store, runtimeStoreFromVariadic(runtime), tenant.ID, rawCode)
 	if err != nil {
 		writeOrgUnitReadServiceError(w, r, err, "orgunit_versions_failed")
@@ -1139,7 +1233,18 @@ func handleOrgUnitsAuditAPI(w http.ResponseWriter, r *http.Request, store OrgUni
 	}
 
 	limit := orgNodeAuditLimitFromURL(r)
-	rows, err := listNodeAuditEventsByNodeKey(r.Context(), store, tenant.ID, orgNodeKey, limit+1)
+	cursorScope := pagecursor.Scope(tenant.ID, orgUnitCursorKindAudit, r.URL.Query())
+	before, err := orgUnitAuditKeysetFromCursor(r.URL.Query().Get("cursor"), cursorScope)
+	if err != nil {
+		writeOrgUnitReadServiceError(w, r, err, "orgunit_audit_failed")
+		return
+	}
+	var rows []OrgUnitNodeAuditEvent
+	if before != nil {
+		rows, err = listNodeAuditEventsByNodeKeyBefore(r.Context(), store, tenant.ID, orgNodeKey, *before, limit+1)
+	} else {
+		rows, err = listNodeAuditEventsByNodeKey(r.Context(), store, tenant.ID, orgNodeKey, limit+1)
+	}
 	if err != nil {
 		if errors.Is(err, errOrgUnitNotFound) {
 			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusNotFound, "org_unit_not_found", "org unit not found")
@@ -1150,8 +1255,10 @@ func handleOrgUnitsAuditAPI(w http.ResponseWriter, r *http.Request, store OrgUni
 	}
 
 	hasMore := len(rows) > limit
+	nextCursor := ""
 	if hasMore {
 		rows = rows[:limit]
+		nextCursor = orgUnitAuditNextCursor(cursorScope, rows[len(rows)-1])
 	}
 
 	items := make([]orgUnitAuditAPIItem, 0, len(rows))
@@ -1175,14 +1282,27 @@ func handleOrgUnitsAuditAPI(w http.ResponseWriter, r *http.Request, store OrgUni
 			AfterSnapshot:        row.AfterSnapshot,
 		})
 	}
+	lineage, err := orgUnitLineageItems(r.Context(), store, tenant.ID, orgNodeKey)
+	if err != nil {
+		writeInternalAPIError(w, r, err, "orgunit_audit_failed")
+		return
+	}
+	operations, err := orgUnitCompositeOperationItems(r.Context(), store, tenant.ID, orgNodeKey)
+	if err != nil {
+		writeInternalAPIError(w, r, err, "orgunit_audit_failed")
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(orgUnitAuditAPIResponse{
-		OrgCode: normalized,
-		Limit:   limit,
-		HasMore: hasMore,
-		Events:  items,
+		OrgCode:    normalized,
+		Limit:      limit,
+		HasMore:    hasMore,
+		NextCursor: nextCursor,
+		Events:     items,
+		Lineage:    lineage,
+		Operations: operations,
 	})
 }
 
@@ -1216,19 +1336,42 @@ func handleOrgUnitsSearchAPI(w http.ResponseWriter, r *http.Request, store OrgUn
 		writeOrgUnitScopeError(w, r, err)
 		return
 	}
+	if _, ok := r.URL.Query()["cursor"]; ok {
+		writeOrgUnitSearchCandidatesPage(w, r, readSvc, orgunitservices.OrgUnitSearchRequest{
+			TenantID:        tenant.ID,
+			AsOf:            asOf,
+			ScopeFilter:     scopeFilter,
+			Query:           query,
+			IncludeDisabled: includeDisabled,
+			Caller:          "orgunit.http.search.candidates",
+		})
+		return
+	}
+	limit := orgUnitSearchDefaultLimit
+	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > orgUnitSearchMaxLimit {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "limit invalid")
+			return
+		}
+		limit = n
+	}
 	nodes, err := readSvc.Search(r.Context(), orgunitservices.OrgUnitSearchRequest{
 		TenantID:        tenant.ID,
 		AsOf:            asOf,
 		ScopeFilter:     scopeFilter,
 		Query:           query,
 		IncludeDisabled: includeDisabled,
-		Limit:           8,
+		Limit:           limit,
 		Caller:          "orgunit.http.search",
 	})
 	if err != nil {
 		writeOrgUnitReadServiceError(w, r, err, "orgunit_search_failed")
 		return
 	}
+	if len(nodes) > 1 && orgUnitSearchDecisive(nodes) {
+		nodes = nodes[:1]
+	}
 	if len(nodes) > 1 {
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 		w.WriteHeader(http.StatusConflict)
@@ -1256,10 +1399,28 @@ func handleOrgUnitsSearchAPI(w http.ResponseWriter, r *http.Request, store OrgUn
 }
 
 type orgUnitSearchCandidateAPIItem struct {
-	OrgCode string `json:"org_code"`
-	Name    string `json:"name"`
-	Status  string `json:"status,omitempty"`
-	AsOf    string `json:"as_of,omitempty"`
+	OrgCode      string                       `json:"org_code"`
+	Name         string                       `json:"name"`
+	Status       string                       `json:"status,omitempty"`
+	AsOf         string                       `json:"as_of,omitempty"`
+	FullNamePath string                       `json:"full_name_path,omitempty"`
+	MatchField   string                       `json:"match_field,omitempty"`
+	Score        float64                      `json:"score,omitempty"`
+	Highlights   []orgunitpkg.SearchHighlight `json:"highlights,omitempty"`
+}
+
+// orgUnitSearchDecisive reports whether the top ranked candidate is an exact hit (code, name, full
+// pinyin or initials) and no other candidate is, so "yfb" resolves 研发部 without a clarification round.
+func orgUnitSearchDecisive(nodes []orgunitservices.OrgUnitReadNode) bool {
+	if len(nodes) == 0 || nodes[0].SearchMatch == nil || !nodes[0].SearchMatch.IsExact() {
+		return false
+	}
+	for _, node := range nodes[1:] {
+		if node.SearchMatch != nil && node.SearchMatch.IsExact() {
+			return false
+		}
+	}
+	return true
 }
 
 type orgUnitSearchCandidatesAPIResponse struct {
@@ -1270,6 +1431,52 @@ type orgUnitSearchCandidatesAPIResponse struct {
 	CannotSilentSelect bool                            `json:"cannot_silent_select"`
 	Candidates         []orgUnitSearchCandidateAPIItem `json:"candidates"`
 	TreeAsOf           string                          `json:"tree_as_of"`
+	NextCursor         string                          `json:"next_cursor,omitempty"`
+}
+
+// writeOrgUnitSearchCandidatesPage serves search with a cursor parameter: instead of resolving a single
+// unit it pages through every matching candidate by org code, so callers can walk past the first eight.
+func writeOrgUnitSearchCandidatesPage(w http.ResponseWriter, r *http.Request, readSvc orgunitservices.OrgUnitReadService, req orgunitservices.OrgUnitSearchRequest) {
+	q := r.URL.Query()
+	size := orgUnitListDefaultPageSize
+	if raw, ok := q["size"]; ok {
+		n, err := strconv.Atoi(strings.TrimSpace(raw[0]))
+		if err != nil || n <= 0 || n > orgUnitListMaxPageSize {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "size invalid")
+			return
+		}
+		size = n
+	}
+	scope := pagecursor.Scope(req.TenantID, orgUnitCursorKindSearch, q)
+	keyset, err := orgUnitListKeysetFromCursor(q.Get("cursor"), orgUnitCursorKindSearch, scope)
+	if err != nil {
+		writeOrgUnitReadServiceError(w, r, err, "orgunit_search_failed")
+		return
+	}
+	req.Keyset = keyset
+	req.Limit = size + 1
+	nodes, err := readSvc.Search(r.Context(), req)
+	if err != nil {
+		writeOrgUnitReadServiceError(w, r, err, "orgunit_search_failed")
+		return
+	}
+	nextCursor := ""
+	if len(nodes) > size {
+		nodes = nodes[:size]
+		nextCursor = orgUnitListNextCursor(orgUnitCursorKindSearch, scope, orgunitservices.OrgUnitListKeysetOf(nodes[len(nodes)-1], orgunitservices.OrgUnitListSortCode))
+	}
+	candidates := orgUnitSearchCandidateAPIItemsFromReadNodes(nodes, req.AsOf)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(orgUnitSearchCandidatesAPIResponse{
+		Ambiguous:          len(candidates) > 1 || nextCursor != "",
+		CandidateSource:    "search_page",
+		CandidateCount:     len(candidates),
+		CannotSilentSelect: len(candidates) > 1 || nextCursor != "",
+		Candidates:         candidates,
+		TreeAsOf:           req.AsOf,
+		NextCursor:         nextCursor,
+	})
 }
 
 func orgUnitSearchCandidateAPIItemsFromReadNodes(nodes []orgunitservices.OrgUnitReadNode, asOf string) []orgUnitSearchCandidateAPIItem {
@@ -1283,12 +1490,19 @@ func orgUnitSearchCandidateAPIItemsFromReadNodes(nodes []orgunitservices.OrgUnit
 		if status == "" {
 			status = orgUnitListStatusActive
 		}
-		items = append(items, orgUnitSearchCandidateAPIItem{
+		item := orgUnitSearchCandidateAPIItem{
 			OrgCode: orgCode,
 			Name:    strings.TrimSpace(node.Name),
 			Status:  status,
 			AsOf:    strings.TrimSpace(asOf),
-		})
+		}
+		if match := node.SearchMatch; match != nil {
+			item.FullNamePath = strings.TrimSpace(node.FullNamePath)
+			item.MatchField = match.Field
+			item.Score = math.Round(match.Score*1000) / 1000
+			item.Highlights = match.Highlights
+		}
+		items = append(items, item)
 	}
 	return items
 }
@@ -1706,6 +1920,14 @@ func writeOrgUnitResult(w http.ResponseWriter, r *http.Request, status int, resu
 }
 
 func writeOrgUnitServiceError(w http.ResponseWriter, r *http.Request, err error, defaultCode string) {
+	var fieldErr *orgunitservices.OrgUnitFieldValidationError
+	if errors.As(err, &fieldErr) {
+		routing.WriteErrorWithDetails(w, r, routing.RouteClassInternalAPI, http.StatusUnprocessableEntity, orgUnitErrFieldValidationFailed, "field validation failed", orgUnitFieldValidationErrorDetails{
+			FieldErrors: fieldErr.FieldErrors,
+		})
+		return
+	}
+
 	code := strings.TrimSpace(stablePgMessage(err))
 	if code == "" {
 		code = strings.TrimSpace(err.Error())
@@ -1778,6 +2000,7 @@ func orgUnitAPIStatusForCode(code string) (int, bool) {
 		orgUnitErrFieldDefinitionNotFound,
 		orgUnitErrFieldConfigNotFound,
 		orgUnitErrFieldPolicyNotFound,
+		orgUnitErrFieldValidationRuleNotFound,
 		orgUnitErrFieldOptionsFieldNotEnabled,
 		orgUnitErrFieldOptionsNotSupported:
 		return http.StatusNotFound, true
@@ -1793,6 +2016,13 @@ func orgUnitAPIStatusForCode(code string) (int, bool) {
 		orgUnitErrHasDependenciesCannotDelete,
 		orgUnitErrEventRescinded,
 		orgUnitErrHighRiskReorderForbidden,
+		orgUnitErrAlreadyExists,
+		orgUnitErrMergeSurvivorInvalid,
+		orgUnitErrRootCannotBeMerged,
+		orgUnitErrRootCannotBeSplit,
+		orgUnitErrSplitChildNotUnderSource,
+		orgUnitErrSplitSourceHasChildren,
+		orgUnitErrCompositeTargetDisabled,
 		orgUnitErrFieldConfigAlreadyEnabled,
 		orgUnitErrFieldConfigSlotExhausted,
 		orgUnitErrFieldConfigDisabledOnInvalid,
@@ -1801,7 +2031,8 @@ func orgUnitAPIStatusForCode(code string) (int, bool) {
 		orgUnitErrFieldPolicyScopeOverlap,
 		orgUnitErrFieldPolicyConflict:
 		return http.StatusConflict, true
-	case orgUnitErrFieldPolicyMissing:
+	case orgUnitErrFieldPolicyMissing,
+		orgUnitErrFieldValidationFailed:
 		return http.StatusUnprocessableEntity, true
 	default:
 		return 0, false
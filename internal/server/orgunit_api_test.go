@@ -349,6 +349,8 @@ type orgUnitWriteServiceStub struct {
 	correctStatusFn   func(context.Context, string, orgunitservices.CorrectStatusOrgUnitRequest) (orgunittypes.OrgUnitResult, error)
 	rescindRecordFn   func(context.Context, string, orgunitservices.RescindRecordOrgUnitRequest) (orgunittypes.OrgUnitResult, error)
 	rescindOrgFn      func(context.Context, string, orgunitservices.RescindOrgUnitRequest) (orgunittypes.OrgUnitResult, error)
+	mergeFn           func(context.Context, string, orgunitservices.MergeOrgUnitsRequest) (orgunittypes.OrgUnitResult, error)
+	splitFn           func(context.Context, string, orgunitservices.SplitOrgUnitRequest) (orgunittypes.OrgUnitResult, error)
 }
 
 func (s orgUnitWriteServiceStub) Write(ctx context.Context, tenantID string, req orgunitservices.WriteOrgUnitRequest) (orgunitservices.OrgUnitWriteResult, error) {
@@ -427,3 +429,17 @@ func (s orgUnitWriteServiceStub) RescindOrg(ctx context.Context, tenantID string
 	}
 	return s.rescindOrgFn(ctx, tenantID, req)
 }
+
+func (s orgUnitWriteServiceStub) Merge(ctx context.Context, tenantID string, req orgunitservices.MergeOrgUnitsRequest) (orgunittypes.OrgUnitResult, error) {
+	if s.mergeFn == nil {
+		return orgunittypes.OrgUnitResult{}, nil
+	}
+	return s.mergeFn(ctx, tenantID, req)
+}
+
+func (s orgUnitWriteServiceStub) Split(ctx context.Context, tenantID string, req orgunitservices.SplitOrgUnitRequest) (orgunittypes.OrgUnitResult, error) {
+	if s.splitFn == nil {
+		return orgunittypes.OrgUnitResult{}, nil
+	}
+	return s.splitFn(ctx, tenantID, req)
+}
@@ -0,0 +1,343 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+	orgunitservices "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/services"
+)
+
+const orgUnitCompositeOperationsLimit = 20
+
+type orgUnitMergeAPIRequest struct {
+	EffectiveDate   string   `json:"effective_date"`
+	SurvivorOrgCode string   `json:"survivor_org_code"`
+	SourceOrgCodes  []string `json:"source_org_codes"`
+	RequestID       string   `json:"request_id"`
+}
+
+type orgUnitSplitNewUnitAPIRequest struct {
+	OrgCode        string   `json:"org_code"`
+	Name           string   `json:"name"`
+	IsBusinessUnit *bool    `json:"is_business_unit"`
+	ChildOrgCodes  []string `json:"child_org_codes"`
+}
+
+type orgUnitSplitAPIRequest struct {
+	EffectiveDate string                          `json:"effective_date"`
+	SourceOrgCode string                          `json:"source_org_code"`
+	NewUnits      []orgUnitSplitNewUnitAPIRequest `json:"new_units"`
+	DisableSource bool                            `json:"disable_source"`
+	RequestID     string                          `json:"request_id"`
+}
+
+// OrgUnitLineageEntry is one lineage edge seen from a unit. Outgoing relations (merged_into, split_from)
+// are stored by the kernel; the incoming ones (merged_from, split_into) are the same rows read backwards.
+type OrgUnitLineageEntry struct {
+	Relation      string
+	OrgNodeKey    string
+	OrgCode       string
+	EffectiveDate string
+	OperationUUID string
+	OperationType string
+	RequestID     string
+	TxTime        time.Time
+}
+
+// OrgUnitCompositeOperation is a MERGE or SPLIT that touched a unit, with the aggregate snapshots of every
+// unit it changed.
+type OrgUnitCompositeOperation struct {
+	OperationUUID  string
+	OperationType  string
+	EffectiveDate  string
+	RequestID      string
+	TxTime         time.Time
+	EventUUIDs     []string
+	Payload        json.RawMessage
+	BeforeSnapshot json.RawMessage
+	AfterSnapshot  json.RawMessage
+}
+
+// orgUnitLineageStore is implemented by stores that can read composite lineage; details and audit only
+// carry lineage when the store supports it.
+type orgUnitLineageStore interface {
+	ListOrgUnitLineage(ctx context.Context, tenantID string, orgNodeKey string) ([]OrgUnitLineageEntry, error)
+	ListOrgUnitCompositeOperations(ctx context.Context, tenantID string, orgNodeKey string, limit int) ([]OrgUnitCompositeOperation, error)
+}
+
+type orgUnitLineageAPIItem struct {
+	Relation      string    `json:"relation"`
+	OrgCode       string    `json:"org_code"`
+	EffectiveDate string    `json:"effective_date"`
+	OperationType string    `json:"operation_type"`
+	OperationUUID string    `json:"operation_uuid"`
+	RequestID     string    `json:"request_id"`
+	TxTime        time.Time `json:"tx_time"`
+}
+
+type orgUnitCompositeOperationAPIItem struct {
+	OperationUUID  string          `json:"operation_uuid"`
+	OperationType  string          `json:"operation_type"`
+	EffectiveDate  string          `json:"effective_date"`
+	RequestID      string          `json:"request_id"`
+	TxTime         time.Time       `json:"tx_time"`
+	EventUUIDs     []string        `json:"event_uuids"`
+	Payload        json.RawMessage `json:"payload"`
+	BeforeSnapshot json.RawMessage `json:"before_snapshot"`
+	AfterSnapshot  json.RawMessage `json:"after_snapshot"`
+}
+
+func handleOrgUnitsMergeAPI(w http.ResponseWriter, r *http.Request, writeSvc orgunitservices.OrgUnitWriteService, scopeDeps ...orgUnitScopeDeps) {
+	scope := orgUnitScopeDepsFromVariadic(scopeDeps)
+	tenantID, ok := orgUnitCompositeWritePrelude(w, r, writeSvc)
+	if !ok {
+		return
+	}
+
+	var req orgUnitMergeAPIRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_json", "bad json")
+		return
+	}
+	for _, code := range append([]string{req.SurvivorOrgCode}, req.SourceOrgCodes...) {
+		if err := ensureCurrentPrincipalOrgCodeScopeAllows(r.Context(), scope.store, scope.runtime, tenantID, code, req.EffectiveDate); err != nil {
+			writeOrgUnitScopeError(w, r, err)
+			return
+		}
+	}
+
+	result, err := writeSvc.Merge(r.Context(), tenantID, orgunitservices.MergeOrgUnitsRequest{
+		EffectiveDate:   req.EffectiveDate,
+		SurvivorOrgCode: req.SurvivorOrgCode,
+		SourceOrgCodes:  req.SourceOrgCodes,
+		RequestID:       req.RequestID,
+		InitiatorUUID:   orgUnitInitiatorUUID(r.Context(), tenantID),
+	})
+	if err != nil {
+		writeOrgUnitServiceError(w, r, err, "orgunit_merge_failed")
+		return
+	}
+	writeOrgUnitResult(w, r, http.StatusOK, result)
+}
+
+func handleOrgUnitsSplitAPI(w http.ResponseWriter, r *http.Request, writeSvc orgunitservices.OrgUnitWriteService, scopeDeps ...orgUnitScopeDeps) {
+	scope := orgUnitScopeDepsFromVariadic(scopeDeps)
+	tenantID, ok := orgUnitCompositeWritePrelude(w, r, writeSvc)
+	if !ok {
+		return
+	}
+
+	var req orgUnitSplitAPIRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_json", "bad json")
+		return
+	}
+	scopeCodes := []string{req.SourceOrgCode}
+	newUnits := make([]orgunitservices.SplitOrgUnitNewUnit, 0, len(req.NewUnits))
+	for _, unit := range req.NewUnits {
+		scopeCodes = append(scopeCodes, unit.ChildOrgCodes...)
+		newUnits = append(newUnits, orgunitservices.SplitOrgUnitNewUnit{
+			OrgCode:        unit.OrgCode,
+			Name:           unit.Name,
+			IsBusinessUnit: unit.IsBusinessUnit,
+			ChildOrgCodes:  unit.ChildOrgCodes,
+		})
+	}
+	for _, code := range scopeCodes {
+		if err := ensureCurrentPrincipalOrgCodeScopeAllows(r.Context(), scope.store, scope.runtime, tenantID, code, req.EffectiveDate); err != nil {
+			writeOrgUnitScopeError(w, r, err)
+			return
+		}
+	}
+
+	result, err := writeSvc.Split(r.Context(), tenantID, orgunitservices.SplitOrgUnitRequest{
+		EffectiveDate: req.EffectiveDate,
+		SourceOrgCode: req.SourceOrgCode,
+		NewUnits:      newUnits,
+		DisableSource: req.DisableSource,
+		RequestID:     req.RequestID,
+		InitiatorUUID: orgUnitInitiatorUUID(r.Context(), tenantID),
+	})
+	if err != nil {
+		writeOrgUnitServiceError(w, r, err, "orgunit_split_failed")
+		return
+	}
+	writeOrgUnitResult(w, r, http.StatusOK, result)
+}
+
+func orgUnitCompositeWritePrelude(w http.ResponseWriter, r *http.Request, writeSvc orgunitservices.OrgUnitWriteService) (string, bool) {
+	if r.Method != http.MethodPost {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return "", false
+	}
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "tenant_missing", "tenant missing")
+		return "", false
+	}
+	if writeSvc == nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "orgunit_service_missing", "orgunit service missing")
+		return "", false
+	}
+	return tenant.ID, true
+}
+
+// orgUnitLineageItems reads lineage for the details and audit views. A store without lineage support
+// yields no items rather than an error.
+func orgUnitLineageItems(ctx context.Context, store OrgUnitStore, tenantID string, orgNodeKey string) ([]orgUnitLineageAPIItem, error) {
+	reader, ok := store.(orgUnitLineageStore)
+	if !ok {
+		return nil, nil
+	}
+	entries, err := reader.ListOrgUnitLineage(ctx, tenantID, orgNodeKey)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]orgUnitLineageAPIItem, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, orgUnitLineageAPIItem{
+			Relation:      entry.Relation,
+			OrgCode:       entry.OrgCode,
+			EffectiveDate: entry.EffectiveDate,
+			OperationType: entry.OperationType,
+			OperationUUID: entry.OperationUUID,
+			RequestID:     entry.RequestID,
+			TxTime:        entry.TxTime,
+		})
+	}
+	return items, nil
+}
+
+func orgUnitCompositeOperationItems(ctx context.Context, store OrgUnitStore, tenantID string, orgNodeKey string) ([]orgUnitCompositeOperationAPIItem, error) {
+	reader, ok := store.(orgUnitLineageStore)
+	if !ok {
+		return nil, nil
+	}
+	ops, err := reader.ListOrgUnitCompositeOperations(ctx, tenantID, orgNodeKey, orgUnitCompositeOperationsLimit)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]orgUnitCompositeOperationAPIItem, 0, len(ops))
+	for _, op := range ops {
+		items = append(items, orgUnitCompositeOperationAPIItem(op))
+	}
+	return items, nil
+}
+
+func (s *orgUnitPGStore) ListOrgUnitLineage(ctx context.Context, tenantID string, orgNodeKey string) ([]OrgUnitLineageEntry, error) {
+	requestedOrgNodeKey, err := normalizeOrgNodeKeyInput(orgNodeKey)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT x.relation, x.other_key, COALESCE(c.org_code, ''), x.effective_date::text, o.operation_uuid::text, o.operation_type, o.request_id, o.tx_time
+		FROM (
+		  SELECT l.relation, btrim(l.related_org_node_key::text) AS other_key, l.effective_date, l.operation_uuid
+		  FROM orgunit.org_unit_lineage l
+		  WHERE l.tenant_uuid = $1::uuid AND btrim(l.org_node_key::text) = $2::text
+		  UNION ALL
+		  SELECT CASE l.relation WHEN 'merged_into' THEN 'merged_from' ELSE 'split_into' END, btrim(l.org_node_key::text), l.effective_date, l.operation_uuid
+		  FROM orgunit.org_unit_lineage l
+		  WHERE l.tenant_uuid = $1::uuid AND btrim(l.related_org_node_key::text) = $2::text
+		) x
+		JOIN orgunit.org_composite_operations o ON o.operation_uuid = x.operation_uuid
+		LEFT JOIN orgunit.org_unit_codes c
+		  ON c.tenant_uuid = $1::uuid
+		 AND `+orgNodeKeyCompatExpr("c")+` = x.other_key
+		ORDER BY o.tx_time, x.relation, x.other_key
+		`, tenantID, requestedOrgNodeKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]OrgUnitLineageEntry, 0)
+	for rows.Next() {
+		var entry OrgUnitLineageEntry
+		if err := rows.Scan(&entry.Relation, &entry.OrgNodeKey, &entry.OrgCode, &entry.EffectiveDate, &entry.OperationUUID, &entry.OperationType, &entry.RequestID, &entry.TxTime); err != nil {
+			return nil, err
+		}
+		out = append(out, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *orgUnitPGStore) ListOrgUnitCompositeOperations(ctx context.Context, tenantID string, orgNodeKey string, limit int) ([]OrgUnitCompositeOperation, error) {
+	requestedOrgNodeKey, err := normalizeOrgNodeKeyInput(orgNodeKey)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = orgUnitCompositeOperationsLimit
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT operation_uuid::text, operation_type, effective_date::text, request_id, tx_time,
+		       ARRAY(SELECT u::text FROM unnest(event_uuids) u), payload, before_snapshot, after_snapshot
+		FROM orgunit.org_composite_operations
+		WHERE tenant_uuid = $1::uuid
+		  AND (before_snapshot ? $2::text OR after_snapshot ? $2::text)
+		ORDER BY tx_time DESC, id DESC
+		LIMIT $3::int
+		`, tenantID, requestedOrgNodeKey, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]OrgUnitCompositeOperation, 0)
+	for rows.Next() {
+		var op OrgUnitCompositeOperation
+		var payload, before, after []byte
+		if err := rows.Scan(&op.OperationUUID, &op.OperationType, &op.EffectiveDate, &op.RequestID, &op.TxTime, &op.EventUUIDs, &payload, &before, &after); err != nil {
+			return nil, err
+		}
+		op.Payload = json.RawMessage(payload)
+		op.BeforeSnapshot = json.RawMessage(before)
+		op.AfterSnapshot = json.RawMessage(after)
+		out = append(out, op)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	orgunittypes "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+	orgunitservices "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/services"
+)
+
+type orgUnitLineageStoreStub struct {
+	*resolveOrgCodeStore
+	lineage    []OrgUnitLineageEntry
+	operations []OrgUnitCompositeOperation
+	err        error
+	nodeKeyArg string
+}
+
+func (s *orgUnitLineageStoreStub) ListOrgUnitLineage(_ context.Context, _ string, orgNodeKey string) ([]OrgUnitLineageEntry, error) {
+	s.nodeKeyArg = orgNodeKey
+	return s.lineage, s.err
+}
+
+func (s *orgUnitLineageStoreStub) ListOrgUnitCompositeOperations(_ context.Context, _ string, _ string, _ int) ([]OrgUnitCompositeOperation, error) {
+	return s.operations, s.err
+}
+
+func TestHandleOrgUnitsMergeAPI_Success(t *testing.T) {
+	var got orgunitservices.MergeOrgUnitsRequest
+	svc := orgUnitWriteServiceStub{
+		mergeFn: func(_ context.Context, tenantID string, req orgunitservices.MergeOrgUnitsRequest) (orgunittypes.OrgUnitResult, error) {
+			if tenantID != "t1" {
+				return orgunittypes.OrgUnitResult{}, errors.New("bad tenant")
+			}
+			got = req
+			return orgunittypes.OrgUnitResult{
+				OrgCode:       req.SurvivorOrgCode,
+				EffectiveDate: req.EffectiveDate,
+				Fields:        map[string]any{"operation": "MERGE", "request_id": req.RequestID, "event_count": 3},
+			}, nil
+		},
+	}
+	body := strings.NewReader(`{"effective_date":"2026-01-01","survivor_org_code":"A001","source_org_codes":["B001","C001"],"request_id":"r1"}`)
+	req := httptest.NewRequest(http.MethodPost, "/org/api/org-units/merges", body)
+	req = req.WithContext(withTenant(req.Context(), Tenant{ID: "t1", Name: "T"}))
+	rec := httptest.NewRecorder()
+	handleOrgUnitsMergeAPI(rec, req, svc)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	if got.SurvivorOrgCode != "A001" || len(got.SourceOrgCodes) != 2 || got.RequestID != "r1" || got.InitiatorUUID != "t1" {
+		t.Fatalf("req=%+v", got)
+	}
+	if !strings.Contains(rec.Body.String(), `"operation":"MERGE"`) {
+		t.Fatalf("body=%s", rec.Body.String())
+	}
+}
+
+func TestHandleOrgUnitsSplitAPI_Success(t *testing.T) {
+	var got orgunitservices.SplitOrgUnitRequest
+	svc := orgUnitWriteServiceStub{
+		splitFn: func(_ context.Context, _ string, req orgunitservices.SplitOrgUnitRequest) (orgunittypes.OrgUnitResult, error) {
+			got = req
+			return orgunittypes.OrgUnitResult{OrgCode: req.SourceOrgCode, EffectiveDate: req.EffectiveDate}, nil
+		},
+	}
+	body := strings.NewReader(`{"effective_date":"2026-01-01","source_org_code":"A001","disable_source":true,"request_id":"r1",
+		"new_units":[{"org_code":"A101","name":"East","is_business_unit":true,"child_org_codes":["C001"]},{"org_code":"A102","name":"West"}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/org/api/org-units/splits", body)
+	req = req.WithContext(withTenant(req.Context(), Tenant{ID: "t1", Name: "T"}))
+	rec := httptest.NewRecorder()
+	handleOrgUnitsSplitAPI(rec, req, svc)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	if !got.DisableSource || len(got.NewUnits) != 2 {
+		t.Fatalf("req=%+v", got)
+	}
+	if first := got.NewUnits[0]; first.IsBusinessUnit == nil || !*first.IsBusinessUnit || len(first.ChildOrgCodes) != 1 {
+		t.Fatalf("first=%+v", first)
+	}
+	if got.NewUnits[1].IsBusinessUnit != nil {
+		t.Fatalf("second=%+v", got.NewUnits[1])
+	}
+}
+
+func TestHandleOrgUnitsCompositeAPI_Errors(t *testing.T) {
+	failing := orgUnitWriteServiceStub{
+		mergeFn: func(context.Context, string, orgunitservices.MergeOrgUnitsRequest) (orgunittypes.OrgUnitResult, error) {
+			return orgunittypes.OrgUnitResult{}, errors.New("ORG_MERGE_SURVIVOR_INVALID")
+		},
+		splitFn: func(context.Context, string, orgunitservices.SplitOrgUnitRequest) (orgunittypes.OrgUnitResult, error) {
+			return orgunittypes.OrgUnitResult{}, errors.New("ORG_SPLIT_SOURCE_HAS_CHILDREN")
+		},
+	}
+	cases := []struct {
+		name    string
+		method  string
+		body    string
+		handler func(http.ResponseWriter, *http.Request, orgunitservices.OrgUnitWriteService, ...orgUnitScopeDeps)
+		svc     orgunitservices.OrgUnitWriteService
+		status  int
+		code    string
+	}{
+		{name: "merge method", method: http.MethodGet, handler: handleOrgUnitsMergeAPI, svc: failing, status: http.StatusMethodNotAllowed, code: "method_not_allowed"},
+		{name: "merge service missing", method: http.MethodPost, body: `{}`, handler: handleOrgUnitsMergeAPI, status: http.StatusInternalServerError, code: "orgunit_service_missing"},
+		{name: "merge unknown field", method: http.MethodPost, body: `{"survivor":"A001"}`, handler: handleOrgUnitsMergeAPI, svc: failing, status: http.StatusBadRequest, code: "bad_json"},
+		{name: "merge survivor invalid", method: http.MethodPost, body: `{"effective_date":"2026-01-01","survivor_org_code":"A001","source_org_codes":["A001"],"request_id":"r1"}`, handler: handleOrgUnitsMergeAPI, svc: failing, status: http.StatusConflict, code: "ORG_MERGE_SURVIVOR_INVALID"},
+		{name: "split bad json", method: http.MethodPost, body: `{`, handler: handleOrgUnitsSplitAPI, svc: failing, status: http.StatusBadRequest, code: "bad_json"},
+		{name: "split source has children", method: http.MethodPost, body: `{"effective_date":"2026-01-01","source_org_code":"A001","new_units":[{"org_code":"A101","name":"East"}],"disable_source":true,"request_id":"r1"}`, handler: handleOrgUnitsSplitAPI, svc: failing, status: http.StatusConflict, code: "ORG_SPLIT_SOURCE_HAS_CHILDREN"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/org/api/org-units/merges", strings.NewReader(tc.body))
+			req = req.WithContext(withTenant(req.Context(), Tenant{ID: "t1", Name: "T"}))
+			rec := httptest.NewRecorder()
+			tc.handler(rec, req, tc.svc)
+			if rec.Code != tc.status {
+				t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tc.code) {
+				t.Fatalf("body=%s", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleOrgUnitsAuditAPI_IncludesCompositeLineage(t *testing.T) {
+	txTime := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	store := &orgUnitLineageStoreStub{
+		resolveOrgCodeStore: &resolveOrgCodeStore{
+			resolveID:   10000001,
+			auditEvents: []OrgUnitNodeAuditEvent{{EventID: 2, EventUUID: "e2", EventType: "DISABLE", EffectiveDate: "2026-01-01", RequestID: "r1#2"}},
+		},
+		lineage: []OrgUnitLineageEntry{{Relation: "merged_into", OrgNodeKey: "AAAAAAAC", OrgCode: "A001", EffectiveDate: "2026-01-01", OperationUUID: "op1", OperationType: "MERGE", RequestID: "r1", TxTime: txTime}},
+		operations: []OrgUnitCompositeOperation{{
+			OperationUUID:  "op1",
+			OperationType:  "MERGE",
+			EffectiveDate:  "2026-01-01",
+			RequestID:      "r1",
+			TxTime:         txTime,
+			EventUUIDs:     []string{"e1", "e2"},
+			Payload:        json.RawMessage(`{}`),
+			BeforeSnapshot: json.RawMessage(`{}`),
+			AfterSnapshot:  json.RawMessage(`{}`),
+		}},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/org/api/org-units/audit?org_code=B001", nil)
+	req = req.WithContext(withTenant(req.Context(), Tenant{ID: "t1", Name: "T"}))
+	rec := httptest.NewRecorder()
+	handleOrgUnitsAuditAPI(rec, req, store)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	var resp orgUnitAuditAPIResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal err=%v", err)
+	}
+	if len(resp.Lineage) != 1 || resp.Lineage[0].Relation != "merged_into" || resp.Lineage[0].OrgCode != "A001" || resp.Lineage[0].RequestID != "r1" {
+		t.Fatalf("lineage=%+v", resp.Lineage)
+	}
+	if len(resp.Operations) != 1 || len(resp.Operations[0].EventUUIDs) != 2 {
+		t.Fatalf("operations=%+v", resp.Operations)
+	}
+	if want := mustOrgNodeKeyForTest(t, 10000001); store.nodeKeyArg != want {
+		t.Fatalf("orgNodeKey=%q want=%q", store.nodeKeyArg, want)
+	}
+
+	store.err = errors.New("boom")
+	rec = httptest.NewRecorder()
+	handleOrgUnitsAuditAPI(rec, req, store)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
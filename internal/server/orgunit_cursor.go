@@ -0,0 +1,128 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	orgunitservices "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/services"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/pagecursor"
+)
+
+const (
+	orgUnitCursorKindList   = "list"
+	orgUnitCursorKindSearch = "search"
+	orgUnitCursorKindAudit  = "audit"
+
+	orgUnitErrCursorInvalid = "invalid_org_unit_cursor"
+)
+
+var errOrgUnitCursorInvalid = errors.New("orgunit: invalid cursor")
+
+// orgUnitCursor is the position inside an org unit page token. The token's scope binds it to the query it
+// was issued for, so a cursor cannot be replayed against a different filter, sort, tenant or as_of.
+type orgUnitCursor struct {
+	SortValue  *string   `json:"v,omitempty"`
+	OrgNodeKey string    `json:"n,omitempty"`
+	TxTime     time.Time `json:"t,omitzero"`
+	EventID    int64     `json:"e,omitempty"`
+}
+
+func encodeOrgUnitCursor(kind string, scope string, c orgUnitCursor) string {
+	return pagecursor.Encode(kind, scope, c)
+}
+
+// decodeOrgUnitCursor verifies the signature and that the token was issued for kind and scope.
+func decodeOrgUnitCursor(token string, kind string, scope string) (orgUnitCursor, error) {
+	var c orgUnitCursor
+	if err := pagecursor.Decode(token, kind, scope, &c); err != nil {
+		return orgUnitCursor{}, errOrgUnitCursorInvalid
+	}
+	return c, nil
+}
+
+// orgUnitListKeysetFromCursor decodes the list/search cursor query value. An empty value starts keyset
+// paging at the first page.
+func orgUnitListKeysetFromCursor(token string, kind string, scope string) (*orgunitservices.OrgUnitListKeyset, error) {
+	if strings.TrimSpace(token) == "" {
+		return &orgunitservices.OrgUnitListKeyset{}, nil
+	}
+	c, err := decodeOrgUnitCursor(token, kind, scope)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(c.OrgNodeKey) == "" {
+		return nil, errOrgUnitCursorInvalid
+	}
+	return &orgunitservices.OrgUnitListKeyset{SortValue: c.SortValue, OrgNodeKey: c.OrgNodeKey}, nil
+}
+
+func orgUnitListNextCursor(kind string, scope string, keyset orgunitservices.OrgUnitListKeyset) string {
+	return encodeOrgUnitCursor(kind, scope, orgUnitCursor{SortValue: keyset.SortValue, OrgNodeKey: keyset.OrgNodeKey})
+}
+
+var orgUnitListKeysetSQLTypes = map[string]string{
+	"text":    "text",
+	"int":     "bigint",
+	"uuid":    "uuid",
+	"bool":    "boolean",
+	"date":    "date",
+	"numeric": "numeric",
+}
+
+// orgUnitListKeysetSQL renders the "strictly after keyset" predicate for ORDER BY sort NULLS LAST,
+// node key ASC. The cursor value travels as text and is cast back to the sort column's type.
+func orgUnitListKeysetSQL(sortColumn orgUnitListFilterColumn, nodeKeyExpr string, desc bool, keyset orgunitservices.OrgUnitListKeyset, argPos int) (string, []any, int, error) {
+	keyPos := argPos
+	if keyset.SortValue == nil {
+		return fmt.Sprintf("(%s IS NULL AND %s > $%d::text)", sortColumn.Expr, nodeKeyExpr, keyPos), []any{keyset.OrgNodeKey}, argPos + 1, nil
+	}
+	sqlType, ok := orgUnitListKeysetSQLTypes[sortColumn.ValueType]
+	if !ok {
+		return "", nil, 0, errOrgUnitCursorInvalid
+	}
+	if _, err := parseOrgUnitExtQueryValue(sortColumn.ValueType, *keyset.SortValue); err != nil {
+		return "", nil, 0, errOrgUnitCursorInvalid
+	}
+	valuePos := argPos + 1
+	cmp := ">"
+	if desc {
+		cmp = "<"
+	}
+	value := fmt.Sprintf("($%d::text)::%s", valuePos, sqlType)
+	sql := fmt.Sprintf("(%[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND %[4]s > $%[5]d::text) OR %[1]s IS NULL)", sortColumn.Expr, cmp, value, nodeKeyExpr, keyPos)
+	return sql, []any{keyset.OrgNodeKey, *keyset.SortValue}, argPos + 2, nil
+}
+
+// orgUnitAuditKeyset is the position of the last audit event on a page; audit pages run newest first
+// by (tx_time, id).
+type orgUnitAuditKeyset struct {
+	TxTime  time.Time
+	EventID int64
+}
+
+func (k orgUnitAuditKeyset) before(event OrgUnitNodeAuditEvent) bool {
+	if event.TxTime.Equal(k.TxTime) {
+		return event.EventID < k.EventID
+	}
+	return event.TxTime.Before(k.TxTime)
+}
+
+func orgUnitAuditKeysetFromCursor(token string, scope string) (*orgUnitAuditKeyset, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, nil
+	}
+	c, err := decodeOrgUnitCursor(token, orgUnitCursorKindAudit, scope)
+	if err != nil {
+		return nil, err
+	}
+	if c.TxTime.IsZero() || c.EventID <= 0 {
+		return nil, errOrgUnitCursorInvalid
+	}
+	return &orgUnitAuditKeyset{TxTime: c.TxTime, EventID: c.EventID}, nil
+}
+
+func orgUnitAuditNextCursor(scope string, last OrgUnitNodeAuditEvent) string {
+	return encodeOrgUnitCursor(orgUnitCursorKindAudit, scope, orgUnitCursor{TxTime: last.TxTime.UTC(), EventID: last.EventID})
+}
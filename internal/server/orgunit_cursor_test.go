@@ -0,0 +1,237 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	orgunitservices "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/services"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/pagecursor"
+)
+
+func TestOrgUnitCursor_RoundTripAndRejects(t *testing.T) {
+	value := "Sales"
+	scope := pagecursor.Scope("t1", orgUnitCursorKindList, url.Values{"as_of": {"2026-01-01"}, "sort": {"name"}, "size": {"10"}})
+	token := orgUnitListNextCursor(orgUnitCursorKindList, scope, orgunitservices.OrgUnitListKeyset{SortValue: &value, OrgNodeKey: "AAAAAAAB"})
+
+	keyset, err := orgUnitListKeysetFromCursor(token, orgUnitCursorKindList, scope)
+	if err != nil || keyset.SortValue == nil || *keyset.SortValue != "Sales" || keyset.OrgNodeKey != "AAAAAAAB" {
+		t.Fatalf("keyset=%+v err=%v", keyset, err)
+	}
+	if sameScope := pagecursor.Scope("t1", orgUnitCursorKindList, url.Values{"as_of": {"2026-01-01"}, "sort": {"name"}, "size": {"50"}, "cursor": {token}}); sameScope != scope {
+		t.Fatalf("paging params must not change the scope")
+	}
+	if keyset, err := orgUnitListKeysetFromCursor("", orgUnitCursorKindList, scope); err != nil || !keyset.IsStart() {
+		t.Fatalf("empty cursor keyset=%+v err=%v", keyset, err)
+	}
+
+	payload, sig, _ := strings.Cut(token, ".")
+	cases := map[string]struct {
+		token string
+		kind  string
+		scope string
+	}{
+		"tampered signature": {token: payload + "." + strings.Repeat("A", len(sig)), kind: orgUnitCursorKindList, scope: scope},
+		"not a token":        {token: "page-2", kind: orgUnitCursorKindList, scope: scope},
+		"other kind":         {token: token, kind: orgUnitCursorKindSearch, scope: scope},
+		"other query":        {token: token, kind: orgUnitCursorKindList, scope: pagecursor.Scope("t1", orgUnitCursorKindList, url.Values{"as_of": {"2026-02-01"}, "sort": {"name"}})},
+		"other tenant":       {token: token, kind: orgUnitCursorKindList, scope: pagecursor.Scope("t2", orgUnitCursorKindList, url.Values{"as_of": {"2026-01-01"}, "sort": {"name"}})},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := orgUnitListKeysetFromCursor(tc.token, tc.kind, tc.scope); err != errOrgUnitCursorInvalid {
+				t.Fatalf("err=%v", err)
+			}
+		})
+	}
+}
+
+func TestOrgUnitListKeysetSQL(t *testing.T) {
+	value := "12"
+	col := orgUnitListFilterColumn{Expr: `v."ext_int_01"`, ValueType: "int"}
+	sql, args, next, err := orgUnitListKeysetSQL(col, "v.org_node_key", true, orgunitservices.OrgUnitListKeyset{SortValue: &value, OrgNodeKey: "AAAAAAAB"}, 5)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want := `(v."ext_int_01" < ($6::text)::bigint OR (v."ext_int_01" = ($6::text)::bigint AND v.org_node_key > $5::text) OR v."ext_int_01" IS NULL)`
+	if sql != want || !reflect.DeepEqual(args, []any{"AAAAAAAB", "12"}) || next != 7 {
+		t.Fatalf("sql=%s args=%#v next=%d", sql, args, next)
+	}
+
+	sql, args, next, err = orgUnitListKeysetSQL(col, "v.org_node_key", false, orgunitservices.OrgUnitListKeyset{OrgNodeKey: "AAAAAAAB"}, 5)
+	if err != nil || sql != `(v."ext_int_01" IS NULL AND v.org_node_key > $5::text)` || len(args) != 1 || next != 6 {
+		t.Fatalf("null sql=%s args=%#v next=%d err=%v", sql, args, next, err)
+	}
+
+	bad := "many"
+	if _, _, _, err := orgUnitListKeysetSQL(col, "v.org_node_key", false, orgunitservices.OrgUnitListKeyset{SortValue: &bad, OrgNodeKey: "AAAAAAAB"}, 5); err != errOrgUnitCursorInvalid {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestOrgUnitPGStore_ListOrgUnitsPage_Keyset(t *testing.T) {
+	value := "Sales"
+	tx := &queryCaptureTx{stubTx: &stubTx{
+		row:  metadataScanRow{vals: []any{int(3)}},
+		rows: &metadataScanRows{records: [][]any{{"A002", "Sales", "active", true, false, "AAAAAAAC", []string{"AAAAAAAC"}, "Sales"}}},
+	}}
+	store := &orgUnitPGStore{pool: beginnerFunc(func(context.Context) (pgx.Tx, error) { return tx, nil })}
+	items, total, err := store.ListOrgUnitsPage(context.Background(), "t1", orgUnitListPageRequest{
+		AsOf:        "2026-01-01",
+		AllOrgUnits: true,
+		SortField:   orgUnitListSortName,
+		SortOrder:   "desc",
+		Limit:       3,
+		Offset:      40,
+		Keyset:      &orgunitservices.OrgUnitListKeyset{SortValue: &value, OrgNodeKey: "AAAAAAAB"},
+	})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if total != 3 || len(items) != 1 || items[0].SortValue == nil || *items[0].SortValue != "Sales" {
+		t.Fatalf("total=%d items=%+v", total, items)
+	}
+	sql := tx.querySQLs[0]
+	for _, want := range []string{
+		"(v.name < ($4::text)::text OR (v.name = ($4::text)::text AND",
+		"ORDER BY v.name DESC NULLS LAST, ",
+		"\nLIMIT $5",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Fatalf("missing %q in sql=%s", want, sql)
+		}
+	}
+	if strings.Contains(sql, "OFFSET") {
+		t.Fatalf("keyset page must not use OFFSET, sql=%s", sql)
+	}
+	if args := tx.queryArgs[0]; len(args) != 5 || args[2] != "AAAAAAAB" || args[3] != "Sales" || args[4] != 3 {
+		t.Fatalf("args=%#v", args)
+	}
+}
+
+func TestHandleOrgUnitsAPI_CursorWalksChildren(t *testing.T) {
+	ctx := context.Background()
+	store := newOrgUnitMemoryStore()
+	root, err := store.CreateNodeCurrent(ctx, "t1", "2026-01-01", "ROOT", "Root", "", true)
+	if err != nil {
+		t.Fatalf("root err=%v", err)
+	}
+	for _, code := range []string{"C003", "C001", "C005", "C002", "C004"} {
+		if _, err := store.CreateNodeCurrent(ctx, "t1", "2026-01-01", code, "Child "+code, root.ID, false); err != nil {
+			t.Fatalf("child err=%v", err)
+		}
+	}
+
+	var seen []string
+	cursor := ""
+	for range 5 {
+		req := httptest.NewRequest(http.MethodGet, "/org/api/org-units?as_of=2026-01-01&parent_org_code=ROOT&size=2&cursor="+url.QueryEscape(cursor), nil)
+		req = req.WithContext(withTenant(req.Context(), Tenant{ID: "t1", Name: "T"}))
+		rec := httptest.NewRecorder()
+		handleOrgUnitsAPI(rec, req, store, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+		}
+		var resp orgUnitListResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("unmarshal err=%v", err)
+		}
+		if resp.Page != nil || resp.Total == nil || *resp.Total != 5 {
+			t.Fatalf("resp=%+v", resp)
+		}
+		for _, item := range resp.OrgUnits {
+			seen = append(seen, item.OrgCode)
+		}
+		if resp.NextCursor == "" {
+			break
+		}
+		cursor = resp.NextCursor
+	}
+	if want := []string{"C001", "C002", "C003", "C004", "C005"}; !reflect.DeepEqual(seen, want) {
+		t.Fatalf("seen=%v", seen)
+	}
+
+	// A cursor only continues the query it was issued for.
+	req := httptest.NewRequest(http.MethodGet, "/org/api/org-units?as_of=2026-01-01&all_org_units=true&size=2&cursor="+url.QueryEscape(cursor), nil)
+	req = req.WithContext(withTenant(req.Context(), Tenant{ID: "t1", Name: "T"}))
+	rec := httptest.NewRecorder()
+	handleOrgUnitsAPI(rec, req, store, nil)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), orgUnitErrCursorInvalid) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/org/api/org-units?as_of=2026-01-01&page=1&cursor=", nil)
+	req = req.WithContext(withTenant(req.Context(), Tenant{ID: "t1", Name: "T"}))
+	rec = httptest.NewRecorder()
+	handleOrgUnitsAPI(rec, req, store, nil)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid_request") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandleOrgUnitsAuditAPI_Cursor(t *testing.T) {
+	base := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	store := &resolveOrgCodeStore{resolveID: 10000001}
+	for i := 5; i >= 1; i-- {
+		store.auditEvents = append(store.auditEvents, OrgUnitNodeAuditEvent{EventID: int64(i), EventUUID: "e" + string(rune('0'+i)), EventType: "RENAME", EffectiveDate: "2026-01-01", TxTime: base.Add(time.Duration(i) * time.Minute)})
+	}
+
+	var seen []int64
+	cursor := ""
+	for range 5 {
+		req := httptest.NewRequest(http.MethodGet, "/org/api/org-units/audit?org_code=A001&limit=2&cursor="+url.QueryEscape(cursor), nil)
+		req = req.WithContext(withTenant(req.Context(), Tenant{ID: "t1", Name: "T"}))
+		rec := httptest.NewRecorder()
+		handleOrgUnitsAuditAPI(rec, req, store)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+		}
+		var resp orgUnitAuditAPIResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("unmarshal err=%v", err)
+		}
+		for _, event := range resp.Events {
+			seen = append(seen, event.EventID)
+		}
+		if resp.HasMore != (resp.NextCursor != "") {
+			t.Fatalf("has_more=%v next_cursor=%q", resp.HasMore, resp.NextCursor)
+		}
+		if resp.NextCursor == "" {
+			break
+		}
+		cursor = resp.NextCursor
+	}
+	if want := []int64{5, 4, 3, 2, 1}; !reflect.DeepEqual(seen, want) {
+		t.Fatalf("seen=%v", seen)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/org/api/org-units/audit?org_code=B001&cursor="+url.QueryEscape(cursor), nil)
+	req = req.WithContext(withTenant(req.Context(), Tenant{ID: "t1", Name: "T"}))
+	rec := httptest.NewRecorder()
+	handleOrgUnitsAuditAPI(rec, req, store)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), orgUnitErrCursorInvalid) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestNormalizeOrgUnitListAPIToolPagination_FollowsCursor(t *testing.T) {
+	values := url.Values{"page": {"3"}, "cursor": {"abc.def"}}
+	normalizeOrgUnitListAPIToolPagination(values)
+	if values.Has("page") || values.Get("cursor") != "abc.def" || values.Get("size") != "100" {
+		t.Fatalf("values=%v", values)
+	}
+
+	values = url.Values{"page": {"3"}, "size": {"20"}}
+	normalizeOrgUnitListAPIToolPagination(values)
+	if values.Get("page") != "2" || values.Get("size") != "20" {
+		t.Fatalf("values=%v", values)
+	}
+}
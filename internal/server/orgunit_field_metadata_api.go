@@ -13,9 +13,8 @@ import (
 	"time"
 
 	"github.com/google/cel-go/cel"
-	celtypes "github.com/google/cel-go/common/types"
-	"github.com/google/cel-go/common/types/ref"
 	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+	orgunitservices "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/services"
 )
 
 type orgUnitFieldDefinitionsAPIResponse struct {
@@ -754,29 +753,15 @@ func orgUnitFieldDataSourceConfigOptionsJSON(def orgUnitFieldDefinition) []json.
 	return raws
 }
 
-func orgUnitFieldPolicyCELNextOrgCode(_ ...ref.Val) ref.Val {
-	return celtypes.String("")
-}
-
-var newOrgUnitFieldPolicyCELEnv = func() (*cel.Env, error) {
-	return cel.NewEnv(
-		cel.Function(
-			"next_org_code",
-			cel.Overload(
-				"next_org_code_string_int",
-				[]*cel.Type{cel.StringType, cel.IntType},
-				cel.StringType,
-				cel.FunctionBinding(orgUnitFieldPolicyCELNextOrgCode),
-			),
-		),
-	)
-}
+var newOrgUnitFieldPolicyCELEnv = orgunitservices.NewOrgUnitDefaultRuleCELEnv
 
-var nextOrgCodePolicyRuleRe = regexp.MustCompile(`^next_org_code\(\s*"([^"]*)"\s*,\s*([0-9]+)\s*\)$`)
+// nextOrgCodeSingleQuotedRe catches next_org_code called with a single-quoted prefix anywhere in a rule;
+// default rules use double-quoted literals for the prefix (DEV-PLAN-120 §4.2.1).
+var nextOrgCodeSingleQuotedRe = regexp.MustCompile(`next_org_code\(\s*'`)
 
 func validateFieldPolicyCELExpr(expr string) error {
 	expr = strings.TrimSpace(expr)
-	if strings.Contains(strings.ToLower(expr), "next_org_code(") && !nextOrgCodePolicyRuleRe.MatchString(expr) {
+	if nextOrgCodeSingleQuotedRe.MatchString(expr) {
 		return errors.New("next_org_code must use double quotes")
 	}
 	env, err := newOrgUnitFieldPolicyCELEnv()
@@ -840,6 +840,20 @@ func (s *orgUnitPGStore) ListOrgUnitsPage(ctx context.Context, tenantID string,
 		argPos++
 	}
 
+	if req.Filter != nil {
+		columns, err := resolveOrgUnitListFilterColumnsTx(ctx, tx, tenantID, req.AsOf, *req.Filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		filterSQL, filterArgs, nextPos, err := compileOrgUnitListFilterSQL(*req.Filter, columns, argPos)
+		if err != nil {
+			return nil, 0, err
+		}
+		where = append(where, filterSQL)
+		args = append(args, filterArgs...)
+		argPos = nextPos
+	}
+
 	var extSortConfig *orgUnitTenantFieldConfig
 	if req.ExtSortFieldKey != "" {
 		cfg, ok, err := getEnabledTenantFieldConfigAsOfTx(ctx, tx, tenantID, req.ExtSortFieldKey, req.AsOf)
@@ -876,18 +890,19 @@ WHERE ` + whereSQL
 		return nil, 0, err
 	}
 
-	sortExpr := "c.org_code"
+	sortColumn := orgUnitListFilterCoreColumns["org_code"]
 	switch req.SortField {
 	case orgUnitListSortName:
-		sortExpr = "v.name"
+		sortColumn = orgUnitListFilterCoreColumns["name"]
 	case orgUnitListSortStatus:
-		sortExpr = "v.status"
+		sortColumn = orgUnitListFilterCoreColumns["status"]
 	case orgUnitListSortCode:
-		sortExpr = "c.org_code"
+		sortColumn = orgUnitListFilterCoreColumns["org_code"]
 	}
 	if extSortConfig != nil {
-		sortExpr = "v." + quoteSQLIdentifier(extSortConfig.PhysicalCol)
+		sortColumn = orgUnitListFilterColumn{Expr: "v." + quoteSQLIdentifier(extSortConfig.PhysicalCol), ValueType: extSortConfig.ValueType}
 	}
+	sortExpr := sortColumn.Expr
 
 	sortOrder := strings.ToUpper(strings.TrimSpace(req.SortOrder))
 	if sortOrder != "DESC" {
@@ -923,20 +938,40 @@ WHERE ` + whereSQL
 	     LIMIT 1
 	   ) AS has_children,
 	  %s AS org_node_key,
-	  %s AS path_org_node_keys`, strings.Join(visibleChildWhere, "\n       AND "), orgNodeKeyCompatExpr("v"), pathOrgNodeKeysCompatExpr("v"))
+	  %s AS path_org_node_keys,
+	  (%s)::text AS sort_value`, strings.Join(visibleChildWhere, "\n       AND "), orgNodeKeyCompatExpr("v"), pathOrgNodeKeysCompatExpr("v"), sortExpr)
 
 	listSQL := selectCols + `
 FROM orgunit.org_unit_versions v
 JOIN orgunit.org_unit_codes c
   ON c.tenant_uuid = v.tenant_uuid
  AND ` + orgNodeKeyCompatExpr("c") + ` = ` + orgNodeKeyCompatExpr("v") + `
-WHERE ` + whereSQL + `
-ORDER BY ` + sortExpr + ` ` + sortOrder + `, c.org_code ASC`
+WHERE ` + whereSQL
 
 	queryArgs := append([]any(nil), args...)
-	if req.Limit > 0 {
-		listSQL += fmt.Sprintf("\nLIMIT $%d OFFSET $%d", argPos, argPos+1)
-		queryArgs = append(queryArgs, req.Limit, req.Offset)
+	if req.Keyset != nil {
+		// Keyset pages order by (sort value, org_node_key) so the position survives concurrent writes;
+		// the count above deliberately ignores the position and stays the total of the whole list.
+		if !req.Keyset.IsStart() {
+			keysetSQL, keysetArgs, nextPos, err := orgUnitListKeysetSQL(sortColumn, orgNodeKeyCompatExpr("v"), sortOrder == "DESC", *req.Keyset, argPos)
+			if err != nil {
+				return nil, 0, err
+			}
+			listSQL += "\n  AND " + keysetSQL
+			queryArgs = append(queryArgs, keysetArgs...)
+			argPos = nextPos
+		}
+		listSQL += "\nORDER BY " + sortExpr + " " + sortOrder + " NULLS LAST, " + orgNodeKeyCompatExpr("v") + " ASC"
+		if req.Limit > 0 {
+			listSQL += fmt.Sprintf("\nLIMIT $%d", argPos)
+			queryArgs = append(queryArgs, req.Limit)
+		}
+	} else {
+		listSQL += "\nORDER BY " + sortExpr + " " + sortOrder + ", c.org_code ASC"
+		if req.Limit > 0 {
+			listSQL += fmt.Sprintf("\nLIMIT $%d OFFSET $%d", argPos, argPos+1)
+			queryArgs = append(queryArgs, req.Limit, req.Offset)
+		}
 	}
 
 	rows, err := tx.Query(ctx, listSQL, queryArgs...)
@@ -952,7 +987,7 @@ ORDER BY ` + sortExpr + ` ` + sortOrder + `, c.org_code ASC`
 		var status string
 		var isBusinessUnit bool
 		var hasChildren bool
-		if err := rows.Scan(&item.OrgCode, &item.Name, &status, &isBusinessUnit, &hasChildren, &item.OrgNodeKey, &pathOrgNodeKeys); err != nil {
+		if err := rows.Scan(&item.OrgCode, &item.Name, &status, &isBusinessUnit, &hasChildren, &item.OrgNodeKey, &pathOrgNodeKeys, &item.SortValue); err != nil {
 			return nil, 0, err
 		}
 		item.PathOrgNodeKeys = append([]string(nil), pathOrgNodeKeys...)
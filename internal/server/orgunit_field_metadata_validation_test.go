@@ -17,6 +17,21 @@ func TestValidateFieldPolicyCELExpr(t *testing.T) {
 		t.Fatalf("expected double-quote error, got %v", err)
 	}
 
+	for _, expr := range []string{
+		`parent.org_code + "-" + next_seq(4)`,
+		`parent.org_code == "" ? next_org_code("O", 6) : next_org_code(parent.org_code, 3)`,
+		`inherit("cost_center")`,
+		`"P" + format_date(as_of, "yyyyMM") + dict_label("org_type", record.org_type)`,
+	} {
+		if err := validateFieldPolicyCELExpr(expr); err != nil {
+			t.Fatalf("expr=%s err=%v", expr, err)
+		}
+	}
+
+	if err := validateFieldPolicyCELExpr(`"A" + next_org_code('ORG', 1)`); err == nil || !strings.Contains(err.Error(), "double quotes") {
+		t.Fatalf("expected double-quote error, got %v", err)
+	}
+
 	if err := validateFieldPolicyCELExpr(`next_org_code("ORG")`); err == nil {
 		t.Fatal("expected compile error")
 	}
@@ -0,0 +1,299 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+	orgunitports "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/ports"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+	orgunitservices "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/services"
+)
+
+var orgUnitFieldValidationKeyRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+type orgUnitFieldValidationRulesAPIResponse struct {
+	AsOf  string                              `json:"as_of"`
+	Rules []orgUnitFieldValidationRuleAPIItem `json:"rules"`
+}
+
+type orgUnitFieldValidationRuleAPIItem struct {
+	FieldKey   string    `json:"field_key"`
+	FormKey    string    `json:"form_key"`
+	RuleKey    string    `json:"rule_key"`
+	RuleExpr   string    `json:"rule_expr"`
+	Message    *string   `json:"message"`
+	EnabledOn  string    `json:"enabled_on"`
+	DisabledOn *string   `json:"disabled_on"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type orgUnitFieldValidationRuleUpsertRequest struct {
+	FieldKey  string `json:"field_key"`
+	FormKey   string `json:"form_key"`
+	RuleKey   string `json:"rule_key"`
+	RuleExpr  string `json:"rule_expr"`
+	Message   string `json:"message"`
+	EnabledOn string `json:"enabled_on"`
+	RequestID string `json:"request_id"`
+}
+
+type orgUnitFieldValidationRuleDisableRequest struct {
+	FieldKey   string `json:"field_key"`
+	FormKey    string `json:"form_key"`
+	RuleKey    string `json:"rule_key"`
+	DisabledOn string `json:"disabled_on"`
+	RequestID  string `json:"request_id"`
+}
+
+// orgUnitFieldValidationRuleTestRequest evaluates an unsaved rule against sample data. Record and parent use
+// the same flat shape the write path builds: core fields and ext fields side by side, keyed by field_key.
+type orgUnitFieldValidationRuleTestRequest struct {
+	FieldKey string         `json:"field_key"`
+	RuleKey  string         `json:"rule_key"`
+	RuleExpr string         `json:"rule_expr"`
+	Message  string         `json:"message"`
+	Intent   string         `json:"intent"`
+	AsOf     string         `json:"as_of"`
+	Record   map[string]any `json:"record"`
+	Parent   map[string]any `json:"parent"`
+}
+
+type orgUnitFieldValidationRuleTestResponse struct {
+	Passed      bool                                            `json:"passed"`
+	FieldErrors []orgunitservices.OrgUnitFieldValidationErrorV1 `json:"field_errors"`
+}
+
+type orgUnitFieldValidationErrorDetails struct {
+	FieldErrors []orgunitservices.OrgUnitFieldValidationErrorV1 `json:"field_errors"`
+}
+
+func handleOrgUnitFieldValidationRulesAPI(w http.ResponseWriter, r *http.Request, ruleStore orgunitports.TenantFieldValidationRuleStore) {
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "tenant_missing", "tenant missing")
+		return
+	}
+
+	if ruleStore == nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "orgunit_store_missing", "orgunit store missing")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		asOf, err := parseRequiredQueryDay(r, "as_of")
+		if err != nil {
+			writeInternalDayFieldError(w, r, err)
+			return
+		}
+		status := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("status")))
+		switch status {
+		case "", "all", "enabled", "disabled":
+		default:
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "status invalid")
+			return
+		}
+
+		rows, err := ruleStore.ListTenantFieldValidationRules(r.Context(), tenant.ID)
+		if err != nil {
+			writeInternalAPIError(w, r, err, "orgunit_field_validation_rules_list_failed")
+			return
+		}
+		items := make([]orgUnitFieldValidationRuleAPIItem, 0, len(rows))
+		for _, row := range rows {
+			enabled := orgUnitFieldValidationRuleEnabledAsOf(row, asOf)
+			if status == "enabled" && !enabled {
+				continue
+			}
+			if status == "disabled" && enabled {
+				continue
+			}
+			items = append(items, orgUnitFieldValidationRuleAPIItemFromRow(row))
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(orgUnitFieldValidationRulesAPIResponse{AsOf: asOf, Rules: items})
+	case http.MethodPost:
+		var req orgUnitFieldValidationRuleUpsertRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_json", "bad json")
+			return
+		}
+		key, ok := normalizeOrgUnitFieldValidationRuleKey(req.FieldKey, req.FormKey, req.RuleKey)
+		req.RuleExpr = strings.TrimSpace(req.RuleExpr)
+		req.EnabledOn = strings.TrimSpace(req.EnabledOn)
+		req.RequestID = strings.TrimSpace(req.RequestID)
+		if !ok || req.RuleExpr == "" || req.RequestID == "" {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "field_key/form_key/rule_key/rule_expr/request_id invalid")
+			return
+		}
+		if _, err := time.Parse(time.DateOnly, req.EnabledOn); err != nil {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "enabled_on invalid")
+			return
+		}
+		if err := orgunitservices.CompileOrgUnitFieldValidationExpr(req.RuleExpr); err != nil {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, orgUnitErrFieldPolicyExprInvalid, err.Error())
+			return
+		}
+		var message *string
+		if trimmed := strings.TrimSpace(req.Message); trimmed != "" {
+			message = &trimmed
+		}
+
+		rule, err := ruleStore.UpsertTenantFieldValidationRule(
+			r.Context(),
+			tenant.ID,
+			key,
+			req.RuleExpr,
+			message,
+			req.EnabledOn,
+			req.RequestID,
+			orgUnitInitiatorUUID(r.Context(), tenant.ID),
+		)
+		if err != nil {
+			writeOrgUnitServiceError(w, r, err, "orgunit_field_validation_rule_upsert_failed")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(orgUnitFieldValidationRuleAPIItemFromRow(rule))
+	default:
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+	}
+}
+
+func handleOrgUnitFieldValidationRulesDisableAPI(w http.ResponseWriter, r *http.Request, ruleStore orgunitports.TenantFieldValidationRuleStore) {
+	if r.Method != http.MethodPost {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "tenant_missing", "tenant missing")
+		return
+	}
+
+	if ruleStore == nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "orgunit_store_missing", "orgunit store missing")
+		return
+	}
+
+	var req orgUnitFieldValidationRuleDisableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_json", "bad json")
+		return
+	}
+	key, ok := normalizeOrgUnitFieldValidationRuleKey(req.FieldKey, req.FormKey, req.RuleKey)
+	req.DisabledOn = strings.TrimSpace(req.DisabledOn)
+	req.RequestID = strings.TrimSpace(req.RequestID)
+	if !ok || req.RequestID == "" {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "field_key/form_key/rule_key/request_id invalid")
+		return
+	}
+	if _, err := time.Parse(time.DateOnly, req.DisabledOn); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "disabled_on invalid")
+		return
+	}
+
+	rule, err := ruleStore.DisableTenantFieldValidationRule(
+		r.Context(),
+		tenant.ID,
+		key,
+		req.DisabledOn,
+		req.RequestID,
+		orgUnitInitiatorUUID(r.Context(), tenant.ID),
+	)
+	if err != nil {
+		writeOrgUnitServiceError(w, r, err, "orgunit_field_validation_rule_disable_failed")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(orgUnitFieldValidationRuleAPIItemFromRow(rule))
+}
+
+// handleOrgUnitFieldValidationRulesTestAPI lets an admin try an expression before saving it. Nothing is
+// persisted and no tenant data is read; the caller supplies the record, parent and as-of date.
+func handleOrgUnitFieldValidationRulesTestAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+
+	var req orgUnitFieldValidationRuleTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_json", "bad json")
+		return
+	}
+	asOf, err := parseRequiredDay(req.AsOf, "as_of")
+	if err != nil {
+		writeInternalDayFieldError(w, r, err)
+		return
+	}
+	if err := orgunitservices.CompileOrgUnitFieldValidationExpr(req.RuleExpr); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, orgUnitErrFieldPolicyExprInvalid, err.Error())
+		return
+	}
+
+	fieldErrors, err := orgunitservices.EvaluateOrgUnitFieldValidationRulesV1([]types.TenantFieldValidationRule{{
+		FieldKey: strings.TrimSpace(req.FieldKey),
+		RuleKey:  strings.TrimSpace(req.RuleKey),
+		Expr:     req.RuleExpr,
+		Message:  req.Message,
+	}}, orgunitservices.OrgUnitFieldValidationInputV1{
+		Intent: strings.TrimSpace(req.Intent),
+		AsOf:   asOf,
+		Record: req.Record,
+		Parent: req.Parent,
+	})
+	if err != nil {
+		writeOrgUnitServiceError(w, r, err, "orgunit_field_validation_rule_test_failed")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(orgUnitFieldValidationRuleTestResponse{
+		Passed:      len(fieldErrors) == 0,
+		FieldErrors: fieldErrors,
+	})
+}
+
+func normalizeOrgUnitFieldValidationRuleKey(fieldKey, formKey, ruleKey string) (types.TenantFieldValidationRuleKey, bool) {
+	key := types.TenantFieldValidationRuleKey{
+		FieldKey: strings.TrimSpace(fieldKey),
+		FormKey:  strings.TrimSpace(formKey),
+		RuleKey:  strings.TrimSpace(ruleKey),
+	}
+	if !orgUnitFieldValidationKeyRe.MatchString(key.FieldKey) || !orgUnitFieldValidationKeyRe.MatchString(key.RuleKey) {
+		return types.TenantFieldValidationRuleKey{}, false
+	}
+	if key.FormKey != "" && !orgunitservices.IsOrgUnitFieldValidationFormKey(key.FormKey) {
+		return types.TenantFieldValidationRuleKey{}, false
+	}
+	return key, true
+}
+
+func orgUnitFieldValidationRuleEnabledAsOf(rule types.TenantFieldValidationRuleRecord, asOf string) bool {
+	if rule.EnabledOn > asOf {
+		return false
+	}
+	return rule.DisabledOn == nil || asOf < *rule.DisabledOn
+}
+
+func orgUnitFieldValidationRuleAPIItemFromRow(row types.TenantFieldValidationRuleRecord) orgUnitFieldValidationRuleAPIItem {
+	return orgUnitFieldValidationRuleAPIItem{
+		FieldKey:   row.FieldKey,
+		FormKey:    row.FormKey,
+		RuleKey:    row.RuleKey,
+		RuleExpr:   row.RuleExpr,
+		Message:    row.Message,
+		EnabledOn:  row.EnabledOn,
+		DisabledOn: row.DisabledOn,
+		UpdatedAt:  row.UpdatedAt,
+	}
+}
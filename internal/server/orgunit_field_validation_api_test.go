@@ -0,0 +1,364 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	orgunittypes "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+	orgunitservices "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/services"
+)
+
+type stubOrgUnitFieldValidationRuleStore struct {
+	listFn    func(ctx context.Context, tenantID string) ([]orgunittypes.TenantFieldValidationRuleRecord, error)
+	upsertFn  func(ctx context.Context, tenantID string, key orgunittypes.TenantFieldValidationRuleKey, ruleExpr string, message *string, enabledOn string, requestID string, initiatorUUID string) (orgunittypes.TenantFieldValidationRuleRecord, error)
+	disableFn func(ctx context.Context, tenantID string, key orgunittypes.TenantFieldValidationRuleKey, disabledOn string, requestID string, initiatorUUID string) (orgunittypes.TenantFieldValidationRuleRecord, error)
+}
+
+func (s stubOrgUnitFieldValidationRuleStore) ListTenantFieldValidationRules(ctx context.Context, tenantID string) ([]orgunittypes.TenantFieldValidationRuleRecord, error) {
+	if s.listFn != nil {
+		return s.listFn(ctx, tenantID)
+	}
+	return []orgunittypes.TenantFieldValidationRuleRecord{}, nil
+}
+
+func (s stubOrgUnitFieldValidationRuleStore) UpsertTenantFieldValidationRule(ctx context.Context, tenantID string, key orgunittypes.TenantFieldValidationRuleKey, ruleExpr string, message *string, enabledOn string, requestID string, initiatorUUID string) (orgunittypes.TenantFieldValidationRuleRecord, error) {
+	if s.upsertFn != nil {
+		return s.upsertFn(ctx, tenantID, key, ruleExpr, message, enabledOn, requestID, initiatorUUID)
+	}
+	return orgunittypes.TenantFieldValidationRuleRecord{}, nil
+}
+
+func (s stubOrgUnitFieldValidationRuleStore) DisableTenantFieldValidationRule(ctx context.Context, tenantID string, key orgunittypes.TenantFieldValidationRuleKey, disabledOn string, requestID string, initiatorUUID string) (orgunittypes.TenantFieldValidationRuleRecord, error) {
+	if s.disableFn != nil {
+		return s.disableFn(ctx, tenantID, key, disabledOn, requestID, initiatorUUID)
+	}
+	return orgunittypes.TenantFieldValidationRuleRecord{}, nil
+}
+
+func newOrgUnitFieldValidationRequest(method string, target string, body string) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	return req.WithContext(withTenant(req.Context(), Tenant{ID: "t1"}))
+}
+
+func TestHandleOrgUnitFieldValidationRulesAPI(t *testing.T) {
+	const path = "/org/api/org-units/field-validation-rules"
+
+	t.Run("tenant missing", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleOrgUnitFieldValidationRulesAPI(rec, httptest.NewRequest(http.MethodGet, path+"?as_of=2026-01-01", nil), nil)
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+
+	t.Run("store missing interface", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleOrgUnitFieldValidationRulesAPI(rec, newOrgUnitFieldValidationRequest(http.MethodGet, path+"?as_of=2026-01-01", ""), nil)
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+
+	t.Run("get invalid query", func(t *testing.T) {
+		store := stubOrgUnitFieldValidationRuleStore{}
+		for _, query := range []string{"?as_of=bad", "?as_of=2026-01-01&status=bad"} {
+			rec := httptest.NewRecorder()
+			handleOrgUnitFieldValidationRulesAPI(rec, newOrgUnitFieldValidationRequest(http.MethodGet, path+query, ""), store)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("query=%s status=%d", query, rec.Code)
+			}
+		}
+	})
+
+	t.Run("get list error", func(t *testing.T) {
+		store := stubOrgUnitFieldValidationRuleStore{
+			listFn: func(context.Context, string) ([]orgunittypes.TenantFieldValidationRuleRecord, error) {
+				return nil, errors.New("boom")
+			},
+		}
+		rec := httptest.NewRecorder()
+		handleOrgUnitFieldValidationRulesAPI(rec, newOrgUnitFieldValidationRequest(http.MethodGet, path+"?as_of=2026-01-01", ""), store)
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+
+	t.Run("get filters by status", func(t *testing.T) {
+		disabledOn := "2026-02-01"
+		store := stubOrgUnitFieldValidationRuleStore{
+			listFn: func(context.Context, string) ([]orgunittypes.TenantFieldValidationRuleRecord, error) {
+				return []orgunittypes.TenantFieldValidationRuleRecord{
+					{FieldKey: "cost_center", RuleKey: "format", RuleExpr: "true", EnabledOn: "2026-01-01", UpdatedAt: time.Unix(1, 0).UTC()},
+					{FieldKey: "name", RuleKey: "max_len", RuleExpr: "true", EnabledOn: "2025-01-01", DisabledOn: &disabledOn},
+					{FieldKey: "location_code", RuleKey: "site", RuleExpr: "true", EnabledOn: "2026-06-01"},
+				}, nil
+			},
+		}
+		cases := map[string][]string{
+			"":         {"cost_center", "name", "location_code"},
+			"enabled":  {"cost_center"},
+			"disabled": {"name", "location_code"},
+		}
+		for status, want := range cases {
+			rec := httptest.NewRecorder()
+			handleOrgUnitFieldValidationRulesAPI(rec, newOrgUnitFieldValidationRequest(http.MethodGet, path+"?as_of=2026-03-01&status="+status, ""), store)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+			}
+			var resp orgUnitFieldValidationRulesAPIResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			got := make([]string, 0, len(resp.Rules))
+			for _, item := range resp.Rules {
+				got = append(got, item.FieldKey)
+			}
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Fatalf("status=%q got=%v", status, got)
+			}
+		}
+	})
+
+	t.Run("post bad json", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleOrgUnitFieldValidationRulesAPI(rec, newOrgUnitFieldValidationRequest(http.MethodPost, path, "{"), stubOrgUnitFieldValidationRuleStore{})
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+
+	t.Run("post invalid request", func(t *testing.T) {
+		store := stubOrgUnitFieldValidationRuleStore{}
+		for _, body := range []string{
+			`{"field_key":"Bad","rule_key":"r","rule_expr":"true","enabled_on":"2026-01-01","request_id":"r1"}`,
+			`{"field_key":"name","form_key":"nope","rule_key":"r","rule_expr":"true","enabled_on":"2026-01-01","request_id":"r1"}`,
+			`{"field_key":"name","rule_key":"r","rule_expr":"","enabled_on":"2026-01-01","request_id":"r1"}`,
+			`{"field_key":"name","rule_key":"r","rule_expr":"true","enabled_on":"2026-01-01","request_id":""}`,
+			`{"field_key":"name","rule_key":"r","rule_expr":"true","enabled_on":"bad","request_id":"r1"}`,
+		} {
+			rec := httptest.NewRecorder()
+			handleOrgUnitFieldValidationRulesAPI(rec, newOrgUnitFieldValidationRequest(http.MethodPost, path, body), store)
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid_request") {
+				t.Fatalf("body=%s status=%d resp=%s", body, rec.Code, rec.Body.String())
+			}
+		}
+	})
+
+	t.Run("post expr invalid", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		body := `{"field_key":"name","rule_key":"max_len","rule_expr":"size(record.name)","enabled_on":"2026-01-01","request_id":"r1"}`
+		handleOrgUnitFieldValidationRulesAPI(rec, newOrgUnitFieldValidationRequest(http.MethodPost, path, body), stubOrgUnitFieldValidationRuleStore{})
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), orgUnitErrFieldPolicyExprInvalid) {
+			t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("post store error", func(t *testing.T) {
+		store := stubOrgUnitFieldValidationRuleStore{
+			upsertFn: func(context.Context, string, orgunittypes.TenantFieldValidationRuleKey, string, *string, string, string, string) (orgunittypes.TenantFieldValidationRuleRecord, error) {
+				return orgunittypes.TenantFieldValidationRuleRecord{}, errors.New("boom")
+			},
+		}
+		rec := httptest.NewRecorder()
+		body := `{"field_key":"name","rule_key":"max_len","rule_expr":"size(record.name) <= 40","enabled_on":"2026-01-01","request_id":"r1"}`
+		handleOrgUnitFieldValidationRulesAPI(rec, newOrgUnitFieldValidationRequest(http.MethodPost, path, body), store)
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+
+	t.Run("post ok", func(t *testing.T) {
+		var gotKey orgunittypes.TenantFieldValidationRuleKey
+		var gotMessage *string
+		store := stubOrgUnitFieldValidationRuleStore{
+			upsertFn: func(_ context.Context, _ string, key orgunittypes.TenantFieldValidationRuleKey, ruleExpr string, message *string, enabledOn string, _ string, _ string) (orgunittypes.TenantFieldValidationRuleRecord, error) {
+				gotKey = key
+				gotMessage = message
+				return orgunittypes.TenantFieldValidationRuleRecord{FieldKey: key.FieldKey, FormKey: key.FormKey, RuleKey: key.RuleKey, RuleExpr: ruleExpr, Message: message, EnabledOn: enabledOn}, nil
+			},
+		}
+		rec := httptest.NewRecorder()
+		body := `{"field_key":" name ","form_key":" orgunit.create_dialog ","rule_key":"max_len","rule_expr":" size(record.name) <= 40 ","message":" 名称过长 ","enabled_on":"2026-01-01","request_id":"r1"}`
+		handleOrgUnitFieldValidationRulesAPI(rec, newOrgUnitFieldValidationRequest(http.MethodPost, path, body), store)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+		}
+		if gotKey != (orgunittypes.TenantFieldValidationRuleKey{FieldKey: "name", FormKey: "orgunit.create_dialog", RuleKey: "max_len"}) {
+			t.Fatalf("key=%+v", gotKey)
+		}
+		if gotMessage == nil || *gotMessage != "名称过长" {
+			t.Fatalf("message=%v", gotMessage)
+		}
+		var item orgUnitFieldValidationRuleAPIItem
+		if err := json.Unmarshal(rec.Body.Bytes(), &item); err != nil || item.RuleExpr != "size(record.name) <= 40" {
+			t.Fatalf("item=%+v err=%v", item, err)
+		}
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleOrgUnitFieldValidationRulesAPI(rec, newOrgUnitFieldValidationRequest(http.MethodPut, path, ""), stubOrgUnitFieldValidationRuleStore{})
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+}
+
+func TestHandleOrgUnitFieldValidationRulesDisableAPI(t *testing.T) {
+	const path = "/org/api/org-units/field-validation-rules:disable"
+	const okBody = `{"field_key":"name","rule_key":"max_len","disabled_on":"2026-02-01","request_id":"r2"}`
+
+	t.Run("method not allowed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleOrgUnitFieldValidationRulesDisableAPI(rec, newOrgUnitFieldValidationRequest(http.MethodGet, path, ""), nil)
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+
+	t.Run("tenant missing", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleOrgUnitFieldValidationRulesDisableAPI(rec, httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(okBody)), nil)
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+
+	t.Run("store missing interface", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleOrgUnitFieldValidationRulesDisableAPI(rec, newOrgUnitFieldValidationRequest(http.MethodPost, path, okBody), nil)
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		store := stubOrgUnitFieldValidationRuleStore{}
+		for _, body := range []string{
+			"{",
+			`{"field_key":"name","rule_key":"max_len","disabled_on":"2026-02-01","request_id":""}`,
+			`{"field_key":"name","rule_key":"max_len","disabled_on":"bad","request_id":"r2"}`,
+		} {
+			rec := httptest.NewRecorder()
+			handleOrgUnitFieldValidationRulesDisableAPI(rec, newOrgUnitFieldValidationRequest(http.MethodPost, path, body), store)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("body=%s status=%d", body, rec.Code)
+			}
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		store := stubOrgUnitFieldValidationRuleStore{
+			disableFn: func(context.Context, string, orgunittypes.TenantFieldValidationRuleKey, string, string, string) (orgunittypes.TenantFieldValidationRuleRecord, error) {
+				return orgunittypes.TenantFieldValidationRuleRecord{}, errors.New(orgUnitErrFieldValidationRuleNotFound)
+			},
+		}
+		rec := httptest.NewRecorder()
+		handleOrgUnitFieldValidationRulesDisableAPI(rec, newOrgUnitFieldValidationRequest(http.MethodPost, path, okBody), store)
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		store := stubOrgUnitFieldValidationRuleStore{
+			disableFn: func(_ context.Context, _ string, key orgunittypes.TenantFieldValidationRuleKey, disabledOn string, _ string, _ string) (orgunittypes.TenantFieldValidationRuleRecord, error) {
+				return orgunittypes.TenantFieldValidationRuleRecord{FieldKey: key.FieldKey, FormKey: key.FormKey, RuleKey: key.RuleKey, EnabledOn: "2026-01-01", DisabledOn: &disabledOn}, nil
+			},
+		}
+		rec := httptest.NewRecorder()
+		handleOrgUnitFieldValidationRulesDisableAPI(rec, newOrgUnitFieldValidationRequest(http.MethodPost, path, okBody), store)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+		}
+		var item orgUnitFieldValidationRuleAPIItem
+		if err := json.Unmarshal(rec.Body.Bytes(), &item); err != nil || item.FormKey != "" || item.DisabledOn == nil || *item.DisabledOn != "2026-02-01" {
+			t.Fatalf("item=%+v err=%v", item, err)
+		}
+	})
+}
+
+func TestHandleOrgUnitFieldValidationRulesTestAPI(t *testing.T) {
+	const path = "/org/api/org-units/field-validation-rules:test"
+
+	t.Run("method not allowed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleOrgUnitFieldValidationRulesTestAPI(rec, newOrgUnitFieldValidationRequest(http.MethodGet, path, ""))
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		for _, body := range []string{
+			"{",
+			`{"rule_expr":"true","as_of":"bad"}`,
+			`{"rule_expr":"record.name +","as_of":"2026-01-01"}`,
+		} {
+			rec := httptest.NewRecorder()
+			handleOrgUnitFieldValidationRulesTestAPI(rec, newOrgUnitFieldValidationRequest(http.MethodPost, path, body))
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("body=%s status=%d", body, rec.Code)
+			}
+		}
+	})
+
+	t.Run("evaluates sample input", func(t *testing.T) {
+		cases := []struct {
+			record string
+			passed bool
+		}{
+			{record: `{"org_type":"site","location_code":"SH01"}`, passed: true},
+			{record: `{"org_type":"site","location_code":""}`, passed: false},
+			{record: `{"org_type":"dept"}`, passed: true},
+		}
+		for _, tc := range cases {
+			body := `{"field_key":"location_code","rule_key":"required_for_site","rule_expr":"record.org_type != 'site' || record.location_code != ''","message":"站点必须填写地点编码","as_of":"2026-01-01","record":` + tc.record + `}`
+			rec := httptest.NewRecorder()
+			handleOrgUnitFieldValidationRulesTestAPI(rec, newOrgUnitFieldValidationRequest(http.MethodPost, path, body))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+			}
+			var resp orgUnitFieldValidationRuleTestResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if resp.Passed != tc.passed || len(resp.FieldErrors) != map[bool]int{true: 0, false: 1}[tc.passed] {
+				t.Fatalf("record=%s resp=%+v", tc.record, resp)
+			}
+			if !tc.passed && resp.FieldErrors[0].Message != "站点必须填写地点编码" {
+				t.Fatalf("resp=%+v", resp)
+			}
+		}
+	})
+}
+
+func TestWriteOrgUnitServiceError_FieldValidationDetails(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/org/api/org-units/write", nil)
+	rec := httptest.NewRecorder()
+	writeOrgUnitServiceError(rec, req, &orgunitservices.OrgUnitFieldValidationError{
+		FieldErrors: []orgunitservices.OrgUnitFieldValidationErrorV1{
+			{FieldKey: "cost_center", RuleKey: "format", Code: "FIELD_VALIDATION_RULE_VIOLATED", Message: "成本中心须为 6 位数字"},
+		},
+	}, "orgunit_write_failed")
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status=%d", rec.Code)
+	}
+	var resp struct {
+		Code    string                             `json:"code"`
+		Details orgUnitFieldValidationErrorDetails `json:"details"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.Code != orgUnitErrFieldValidationFailed || len(resp.Details.FieldErrors) != 1 || resp.Details.FieldErrors[0].FieldKey != "cost_center" {
+		t.Fatalf("resp=%+v body=%s", resp, rec.Body.String())
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	orgunitmodule "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit"
+	orgunitports "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/ports"
+	orgunittypes "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+	orgunitservices "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/services"
+	orgunitpkg "github.com/jacksonlee411/Bugs-And-Blossoms/pkg/orgunit"
+)
+
+const orgUnitErrKnownAtInvalid = "invalid_known_at"
+
+// ListReplayEventsKnownAt reads the tenant's event stream as known at knownAt through the orgunit module,
+// so read services on this store can fold known_at snapshots.
+func (s *orgUnitPGStore) ListReplayEventsKnownAt(ctx context.Context, tenantID string, knownAt time.Time) ([]orgunittypes.OrgUnitReplayEvent, error) {
+	return orgunitmodule.NewKnownAtEventPGStore(s.pool).ListReplayEventsKnownAt(ctx, tenantID, knownAt)
+}
+
+func (a orgUnitReadStoreAdapter) ListReplayEventsKnownAt(ctx context.Context, tenantID string, knownAt time.Time) ([]orgunittypes.OrgUnitReplayEvent, error) {
+	reader, ok := a.store.(orgunitports.OrgUnitKnownAtEventStore)
+	if !ok {
+		return nil, orgunitservices.ErrOrgUnitReadKnownAtUnsupported
+	}
+	return reader.ListReplayEventsKnownAt(ctx, tenantID, knownAt)
+}
+
+// orgUnitKnownAtFromURL returns the optional known_at query parameter; empty means "as currently known".
+func orgUnitKnownAtFromURL(r *http.Request) string {
+	return strings.TrimSpace(r.URL.Query().Get("known_at"))
+}
+
+// writeOrgUnitKnownAtDetails answers the details API from the replayed snapshot. Ext field values are
+// projected columns the replay does not rebuild, so the known_at view carries none.
+func writeOrgUnitKnownAtDetails(w http.ResponseWriter, r *http.Request, store OrgUnitStore, tenantID string, orgNodeKey string, asOf string, knownAt string, includeDisabled bool) {
+	snap, err := orgunitservices.OrgUnitKnownAtSnapshotFor(r.Context(), store, tenantID, knownAt)
+	if err != nil {
+		writeOrgUnitReadServiceError(w, r, err, "orgunit_details_failed")
+		return
+	}
+	details, err := snap.Details(orgNodeKey, asOf, includeDisabled)
+	if err != nil {
+		writeOrgUnitReadServiceError(w, r, err, "orgunit_details_failed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(orgUnitDetailsAPIResponse{
+		AsOf:      asOf,
+		KnownAt:   knownAt,
+		ExtFields: []orgUnitExtFieldAPIItem{},
+		OrgUnit: orgUnitDetailsAPIItem{
+			OrgCode:          details.OrgCode,
+			Name:             details.Name,
+			Status:           details.Status,
+			ParentOrgNodeKey: details.ParentOrgNodeKey,
+			ParentOrgCode:    details.ParentOrgCode,
+			ParentName:       details.ParentName,
+			IsBusinessUnit:   details.IsBusinessUnit,
+			ManagerPernr:     details.ManagerPernr,
+			FullNamePath:     details.FullNamePath,
+			CreatedAt:        details.CreatedAt,
+			UpdatedAt:        details.UpdatedAt,
+			EventUUID:        details.EventUUID,
+		},
+	})
+}
+
+// writeOrgUnitKnownAtVersions lists the effective events of an org unit as known at knownAt. The code is
+// resolved against the snapshot, so units rescinded since then remain reachable for audits.
+func writeOrgUnitKnownAtVersions(w http.ResponseWriter, r *http.Request, store OrgUnitStore, runtime authzRuntimeStore, tenantID string, rawCode string, knownAt string) {
+	normalized, err := orgunitpkg.NormalizeOrgCode(rawCode)
+	if err != nil {
+		writeOrgUnitReadServiceError(w, r, err, "orgunit_versions_failed")
+		return
+	}
+	snap, err := orgunitservices.OrgUnitKnownAtSnapshotFor(r.Context(), store, tenantID, knownAt)
+	if err != nil {
+		writeOrgUnitReadServiceError(w, r, err, "orgunit_versions_failed")
+		return
+	}
+	orgNodeKey, ok := snap.OrgNodeKeyByCode(normalized)
+	if !ok {
+		writeOrgUnitReadServiceError(w, r, orgunitservices.ErrOrgUnitReadNotFound, "orgunit_versions_failed")
+		return
+	}
+	if err := ensureCurrentPrincipalHistoryOrgNodeScopeAllows(r.Context(), store, runtime, tenantID, orgNodeKey); err != nil {
+		writeOrgUnitReadServiceError(w, r, err, "orgunit_versions_failed")
+		return
+	}
+
+	events := snap.Events(orgNodeKey)
+	items := make([]orgUnitVersionAPIItem, 0, len(events))
+	for _, event := range events {
+		items = append(items, orgUnitVersionAPIItem{
+			EventID:       event.EventID,
+			EventUUID:     event.EventUUID,
+			EffectiveDate: event.EffectiveDate,
+			EventType:     event.EventType,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(orgUnitVersionsAPIResponse{
+		OrgCode:  normalized,
+		KnownAt:  knownAt,
+		Versions: items,
+	})
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	orgunittypes "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+)
+
+type orgUnitKnownAtStoreStub struct {
+	*orgUnitMemoryStore
+	events []orgunittypes.OrgUnitReplayEvent
+}
+
+func (s orgUnitKnownAtStoreStub) ListReplayEventsKnownAt(_ context.Context, _ string, knownAt time.Time) ([]orgunittypes.OrgUnitReplayEvent, error) {
+	out := make([]orgunittypes.OrgUnitReplayEvent, 0, len(s.events))
+	for _, event := range s.events {
+		if !event.TxTime.After(knownAt) {
+			out = append(out, event)
+		}
+	}
+	return out, nil
+}
+
+func newOrgUnitKnownAtStoreStub() orgUnitKnownAtStoreStub {
+	at := func(raw string) time.Time {
+		parsed, _ := time.Parse(time.RFC3339, raw)
+		return parsed
+	}
+	return orgUnitKnownAtStoreStub{
+		orgUnitMemoryStore: newOrgUnitMemoryStore(),
+		events: []orgunittypes.OrgUnitReplayEvent{
+			{EventID: 1, EventUUID: "e1", OrgNodeKey: "AAAAAAAB", OrgCode: "ROOT", EventType: "CREATE", EffectiveDate: "2026-01-01", Payload: []byte(`{"name":"Root"}`), TxTime: at("2026-01-01T08:00:00Z")},
+			{EventID: 2, EventUUID: "e2", OrgNodeKey: "AAAAAAAC", OrgCode: "OPS", EventType: "CREATE", EffectiveDate: "2026-01-01", Payload: []byte(`{"name":"Ops","parent_org_node_key":"AAAAAAAB"}`), TxTime: at("2026-01-01T09:00:00Z")},
+			{EventID: 3, EventUUID: "e3", OrgNodeKey: "AAAAAAAC", OrgCode: "OPS", EventType: "RENAME", EffectiveDate: "2026-03-01", Payload: []byte(`{"new_name":"Operations"}`), TxTime: at("2026-03-01T08:00:00Z")},
+			{EventID: 4, EventUUID: "e4", OrgNodeKey: "AAAAAAAC", OrgCode: "OPS", EventType: "RENAME", EffectiveDate: "2026-02-01", Payload: []byte(`{"new_name":"Ops Team"}`), TxTime: at("2026-05-01T08:00:00Z")},
+		},
+	}
+}
+
+func orgUnitKnownAtRequest(target string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	return req.WithContext(withTenant(req.Context(), Tenant{ID: "t-known-at-api", Name: "T"}))
+}
+
+func TestHandleOrgUnitsAPI_KnownAt(t *testing.T) {
+	store := newOrgUnitKnownAtStoreStub()
+
+	rec := httptest.NewRecorder()
+	handleOrgUnitsAPI(rec, orgUnitKnownAtRequest("/org/api/org-units?as_of=2026-02-15&parent_org_code=ROOT&known_at=2026-04-01T00:00:00Z"), store, nil)
+	var list orgUnitListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	// The February rename was only recorded in May, so on 2026-04-01 the unit was still called Ops.
+	if list.KnownAt != "2026-04-01T00:00:00Z" || len(list.OrgUnits) != 1 || list.OrgUnits[0].Name != "Ops" {
+		t.Fatalf("list=%+v", list)
+	}
+
+	rec = httptest.NewRecorder()
+	handleOrgUnitsAPI(rec, orgUnitKnownAtRequest("/org/api/org-units?as_of=2026-02-15&known_at=last-week"), store, nil)
+	if rec.Code != http.StatusBadRequest || decodePersonErrorCode(t, rec) != orgUnitErrKnownAtInvalid {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/org/api/org-units?as_of=2026-02-15&known_at=2026-04-01T00:00:00Z", nil)
+	req = req.WithContext(withTenant(req.Context(), Tenant{ID: "t-known-at-memory", Name: "T"}))
+	handleOrgUnitsAPI(rec, req, newOrgUnitMemoryStore(), nil)
+	if rec.Code != http.StatusInternalServerError || decodePersonErrorCode(t, rec) != "orgunit_known_at_unsupported" {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandleOrgUnitsDetailsAndVersionsAPI_KnownAt(t *testing.T) {
+	store := newOrgUnitKnownAtStoreStub()
+
+	rec := httptest.NewRecorder()
+	handleOrgUnitsDetailsAPI(rec, orgUnitKnownAtRequest("/org/api/org-units/details?org_code=OPS&as_of=2026-02-15&known_at=2026-06-01T00:00:00Z"), store)
+	var details orgUnitDetailsAPIResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &details); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	if details.OrgUnit.Name != "Ops Team" || details.OrgUnit.ParentOrgCode != "ROOT" || details.OrgUnit.FullNamePath != "Root / Ops Team" || details.OrgUnit.EventUUID != "e4" {
+		t.Fatalf("details=%+v", details)
+	}
+
+	rec = httptest.NewRecorder()
+	handleOrgUnitsVersionsAPI(rec, orgUnitKnownAtRequest("/org/api/org-units/versions?org_code=OPS&known_at=2026-04-01T00:00:00Z"), store)
+	var versions orgUnitVersionsAPIResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &versions); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	if len(versions.Versions) != 2 || versions.Versions[1].EventUUID != "e3" || versions.KnownAt == "" {
+		t.Fatalf("versions=%+v", versions)
+	}
+
+	rec = httptest.NewRecorder()
+	handleOrgUnitsVersionsAPI(rec, orgUnitKnownAtRequest("/org/api/org-units/versions?org_code=OPS&known_at=2025-12-01T00:00:00Z"), store)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	orgunitservices "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/services"
+)
+
+// orgUnitListFilterColumn is the SQL side of a filterable field: the column expression in the list query
+// and the value type its operands are parsed as.
+type orgUnitListFilterColumn struct {
+	Expr      string
+	ValueType string
+}
+
+var orgUnitListFilterCoreColumns = map[string]orgUnitListFilterColumn{
+	"org_code":         {Expr: "c.org_code", ValueType: "text"},
+	"name":             {Expr: "v.name", ValueType: "text"},
+	"status":           {Expr: "v.status", ValueType: "text"},
+	"is_business_unit": {Expr: "v.is_business_unit", ValueType: "bool"},
+}
+
+// resolveOrgUnitListFilterColumnsTx maps every field named by the filter to a column. Ext fields must be
+// enabled for the tenant as of asOf and marked AllowFilter, the same gate as ext_filter_field_key.
+func resolveOrgUnitListFilterColumnsTx(ctx context.Context, tx pgx.Tx, tenantID string, asOf string, filter orgunitservices.OrgUnitListFilter) (map[string]orgUnitListFilterColumn, error) {
+	out := make(map[string]orgUnitListFilterColumn)
+	for _, field := range filter.Fields() {
+		if col, ok := orgUnitListFilterCoreColumns[field]; ok {
+			out[field] = col
+			continue
+		}
+		cfg, ok, err := getEnabledTenantFieldConfigAsOfTx(ctx, tx, tenantID, field, asOf)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, errOrgUnitExtQueryFieldNotAllowed
+		}
+		allowFilter := false
+		if def, ok := lookupOrgUnitFieldDefinition(field); ok {
+			allowFilter = def.AllowFilter
+		} else if isCustomOrgUnitDictFieldKey(field) {
+			allowFilter = true
+		}
+		if !allowFilter || !orgUnitExtPhysicalColRe.MatchString(cfg.PhysicalCol) {
+			return nil, errOrgUnitExtQueryFieldNotAllowed
+		}
+		out[field] = orgUnitListFilterColumn{Expr: "v." + quoteSQLIdentifier(cfg.PhysicalCol), ValueType: cfg.ValueType}
+	}
+	return out, nil
+}
+
+// compileOrgUnitListFilterSQL renders the filter as one parenthesised WHERE term with placeholders
+// numbered from argPos, and returns the args to append and the next free placeholder.
+func compileOrgUnitListFilterSQL(filter orgunitservices.OrgUnitListFilter, columns map[string]orgUnitListFilterColumn, argPos int) (string, []any, int, error) {
+	c := orgUnitListFilterCompiler{columns: columns, argPos: argPos}
+	sql, err := c.node(filter)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	return sql, c.args, c.argPos, nil
+}
+
+type orgUnitListFilterCompiler struct {
+	columns map[string]orgUnitListFilterColumn
+	args    []any
+	argPos  int
+}
+
+func (c *orgUnitListFilterCompiler) node(filter orgunitservices.OrgUnitListFilter) (string, error) {
+	if filter.IsGroup() {
+		members, joiner := filter.And, " AND "
+		if len(filter.Or) > 0 {
+			members, joiner = filter.Or, " OR "
+		}
+		parts := make([]string, 0, len(members))
+		for _, member := range members {
+			part, err := c.node(member)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, part)
+		}
+		return "(" + strings.Join(parts, joiner) + ")", nil
+	}
+
+	col, ok := c.columns[filter.Field]
+	if !ok {
+		return "", errOrgUnitExtQueryFieldNotAllowed
+	}
+	switch filter.Op {
+	case orgunitservices.OrgUnitListFilterOpIsNull:
+		return "(" + col.Expr + " IS NULL)", nil
+	case orgunitservices.OrgUnitListFilterOpNotNull:
+		return "(" + col.Expr + " IS NOT NULL)", nil
+	case orgunitservices.OrgUnitListFilterOpEq:
+		ph, err := c.bind(filter.Field, col, filter.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(%s = %s)", col.Expr, ph), nil
+	case orgunitservices.OrgUnitListFilterOpIn:
+		phs := make([]string, 0, len(filter.Values))
+		for _, value := range filter.Values {
+			ph, err := c.bind(filter.Field, col, value)
+			if err != nil {
+				return "", err
+			}
+			phs = append(phs, ph)
+		}
+		return fmt.Sprintf("(%s IN (%s))", col.Expr, strings.Join(phs, ", ")), nil
+	case orgunitservices.OrgUnitListFilterOpPrefix:
+		if col.ValueType != "text" {
+			return "", orgUnitListFilterTypeError(filter)
+		}
+		ph, err := c.bind(filter.Field, col, filter.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(starts_with(%s, %s::text))", col.Expr, ph), nil
+	case orgunitservices.OrgUnitListFilterOpGt, orgunitservices.OrgUnitListFilterOpGte, orgunitservices.OrgUnitListFilterOpLt, orgunitservices.OrgUnitListFilterOpLte:
+		if col.ValueType == "bool" || col.ValueType == "uuid" {
+			return "", orgUnitListFilterTypeError(filter)
+		}
+		ph, err := c.bind(filter.Field, col, filter.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(%s %s %s)", col.Expr, orgUnitListFilterComparators[filter.Op], ph), nil
+	case orgunitservices.OrgUnitListFilterOpBetween:
+		if col.ValueType == "bool" || col.ValueType == "uuid" || len(filter.Values) != 2 {
+			return "", orgUnitListFilterTypeError(filter)
+		}
+		lo, err := c.bind(filter.Field, col, filter.Values[0])
+		if err != nil {
+			return "", err
+		}
+		hi, err := c.bind(filter.Field, col, filter.Values[1])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(%s BETWEEN %s AND %s)", col.Expr, lo, hi), nil
+	default:
+		return "", orgUnitListFilterTypeError(filter)
+	}
+}
+
+var orgUnitListFilterComparators = map[string]string{
+	orgunitservices.OrgUnitListFilterOpGt:  ">",
+	orgunitservices.OrgUnitListFilterOpGte: ">=",
+	orgunitservices.OrgUnitListFilterOpLt:  "<",
+	orgunitservices.OrgUnitListFilterOpLte: "<=",
+}
+
+func (c *orgUnitListFilterCompiler) bind(field string, col orgUnitListFilterColumn, raw string) (string, error) {
+	value, err := parseOrgUnitExtQueryValue(col.ValueType, raw)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s value %q does not match %s", orgunitservices.ErrOrgUnitListFilterInvalid, field, raw, col.ValueType)
+	}
+	c.args = append(c.args, value)
+	ph := fmt.Sprintf("$%d", c.argPos)
+	c.argPos++
+	return ph, nil
+}
+
+func orgUnitListFilterTypeError(filter orgunitservices.OrgUnitListFilter) error {
+	return fmt.Errorf("%w: %s is not supported on %s", orgunitservices.ErrOrgUnitListFilterInvalid, filter.Op, filter.Field)
+}
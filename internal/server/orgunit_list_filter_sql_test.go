@@ -0,0 +1,132 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	orgunitservices "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/services"
+)
+
+func TestCompileOrgUnitListFilterSQL(t *testing.T) {
+	filter, err := orgunitservices.ParseOrgUnitListFilter(`{"and":[
+		{"field":"org_type","op":"in","values":["dept","team"]},
+		{"or":[{"field":"cost_center","op":"prefix","value":"41"},{"field":"location_code","op":"eq","value":"SH"}]},
+		{"field":"headcount","op":"between","values":[10,20]},
+		{"field":"is_business_unit","op":"eq","value":false},
+		{"field":"name","op":"not_null"}
+	]}`)
+	if err != nil {
+		t.Fatalf("parse err=%v", err)
+	}
+	columns := map[string]orgUnitListFilterColumn{
+		"org_type":         {Expr: `v."ext_str_01"`, ValueType: "text"},
+		"cost_center":      {Expr: `v."ext_str_02"`, ValueType: "text"},
+		"location_code":    {Expr: `v."ext_str_03"`, ValueType: "text"},
+		"headcount":        {Expr: `v."ext_int_01"`, ValueType: "int"},
+		"is_business_unit": orgUnitListFilterCoreColumns["is_business_unit"],
+		"name":             orgUnitListFilterCoreColumns["name"],
+	}
+
+	sql, args, next, err := compileOrgUnitListFilterSQL(*filter, columns, 4)
+	if err != nil {
+		t.Fatalf("compile err=%v", err)
+	}
+	wantSQL := `((v."ext_str_01" IN ($4, $5)) AND ((starts_with(v."ext_str_02", $6::text)) OR (v."ext_str_03" = $7)) AND (v."ext_int_01" BETWEEN $8 AND $9) AND (v.is_business_unit = $10) AND (v.name IS NOT NULL))`
+	if sql != wantSQL {
+		t.Fatalf("sql=%s", sql)
+	}
+	if want := []any{"dept", "team", "41", "SH", 10, 20, false}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args=%#v", args)
+	}
+	if next != 11 {
+		t.Fatalf("next=%d", next)
+	}
+}
+
+func TestCompileOrgUnitListFilterSQL_Rejects(t *testing.T) {
+	columns := map[string]orgUnitListFilterColumn{
+		"name":             orgUnitListFilterCoreColumns["name"],
+		"is_business_unit": orgUnitListFilterCoreColumns["is_business_unit"],
+		"headcount":        {Expr: `v."ext_int_01"`, ValueType: "int"},
+	}
+	cases := map[string]struct {
+		filter orgunitservices.OrgUnitListFilter
+		want   error
+	}{
+		"unknown field":     {filter: orgunitservices.OrgUnitListFilter{Field: "cost_center", Op: "eq", Value: "41"}, want: errOrgUnitExtQueryFieldNotAllowed},
+		"prefix on int":     {filter: orgunitservices.OrgUnitListFilter{Field: "headcount", Op: "prefix", Value: "1"}, want: orgunitservices.ErrOrgUnitListFilterInvalid},
+		"range on bool":     {filter: orgunitservices.OrgUnitListFilter{Field: "is_business_unit", Op: "gt", Value: "true"}, want: orgunitservices.ErrOrgUnitListFilterInvalid},
+		"value type":        {filter: orgunitservices.OrgUnitListFilter{Field: "headcount", Op: "gte", Value: "many"}, want: orgunitservices.ErrOrgUnitListFilterInvalid},
+		"value type nested": {filter: orgunitservices.OrgUnitListFilter{Or: []orgunitservices.OrgUnitListFilter{{Field: "name", Op: "is_null"}, {Field: "headcount", Op: "in", Values: []string{"1", "x"}}}}, want: orgunitservices.ErrOrgUnitListFilterInvalid},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, _, _, err := compileOrgUnitListFilterSQL(tc.filter, columns, 3); !errors.Is(err, tc.want) {
+				t.Fatalf("err=%v", err)
+			}
+		})
+	}
+}
+
+func TestHandleOrgUnitsAPI_FilterExpressionPassedToStore(t *testing.T) {
+	store := &orgUnitListPageReaderStore{
+		resolveOrgCodeStore: &resolveOrgCodeStore{resolveID: 42},
+		items:               []orgUnitListItem{{OrgCode: "A001", Name: "Root", Status: "active"}},
+		total:               1,
+	}
+	filter := `{"and":[{"field":"org_type","op":"in","values":["dept","team"]},{"field":"cost_center","op":"prefix","value":"41"}]}`
+	req := httptest.NewRequest(http.MethodGet, "/org/api/org-units?as_of=2026-01-01&all_org_units=true&page=0&size=10&filter="+url.QueryEscape(filter), nil)
+	req = req.WithContext(withTenant(req.Context(), Tenant{ID: "t1", Name: "T"}))
+	rec := httptest.NewRecorder()
+	handleOrgUnitsAPI(rec, req, store, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	got := store.capturedReq.Filter
+	if got == nil || len(got.And) != 2 || got.And[0].Field != "org_type" || got.And[1].Op != orgunitservices.OrgUnitListFilterOpPrefix {
+		t.Fatalf("filter=%+v", got)
+	}
+
+	store.err = errOrgUnitExtQueryFieldNotAllowed
+	rec = httptest.NewRecorder()
+	handleOrgUnitsAPI(rec, req, store, nil)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), orgUnitErrExtQueryFieldNotAllowed) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandleOrgUnitsAPI_FilterExpressionInvalid(t *testing.T) {
+	cases := []string{
+		"/org/api/org-units?as_of=2026-01-01&page=0&size=10&filter=" + url.QueryEscape(`{"field":"name","op":"like","value":"a"}`),
+		"/org/api/org-units?as_of=2026-01-01&filter=" + url.QueryEscape(`{"field":"name","op":"is_null"}`),
+	}
+	for _, target := range cases {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = req.WithContext(withTenant(req.Context(), Tenant{ID: "t1", Name: "T"}))
+		rec := httptest.NewRecorder()
+		handleOrgUnitsAPI(rec, req, newOrgUnitMemoryStore(), nil)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid_request") {
+			t.Fatalf("target=%s status=%d body=%s", target, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestAppendAPIToolQueryParam_EncodesStructuredFilter(t *testing.T) {
+	values := url.Values{}
+	filter := map[string]any{"field": "org_type", "op": "in", "values": []any{"dept", "team"}}
+	if err := appendAPIToolQueryParam(values, "filter", filter); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	parsed, err := orgunitservices.ParseOrgUnitListFilter(values.Get("filter"))
+	if err != nil {
+		t.Fatalf("parse err=%v raw=%s", err, values.Get("filter"))
+	}
+	if parsed.Field != "org_type" || !reflect.DeepEqual(parsed.Values, []string{"dept", "team"}) {
+		t.Fatalf("parsed=%+v", parsed)
+	}
+}
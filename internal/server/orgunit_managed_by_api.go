@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+)
+
+// OrgUnitManagerReader answers manager_uuid questions for the person directory. It is optional: stores
+// that never record manager_uuid (the in-memory store) do not implement it.
+type OrgUnitManagerReader interface {
+	ListOrgUnitsManagedBy(ctx context.Context, tenantID string, managerUUID string, asOfDate string, includeDisabled bool) ([]orgUnitListItem, error)
+	OrgUnitManagerReferenced(ctx context.Context, tenantID string, managerUUID string) (bool, error)
+}
+
+type orgUnitManagedByManager struct {
+	Pernr       string `json:"pernr"`
+	DisplayName string `json:"display_name"`
+	Status      string `json:"status"`
+}
+
+type orgUnitManagedByResponse struct {
+	AsOf            string                  `json:"as_of"`
+	IncludeDisabled bool                    `json:"include_disabled"`
+	Manager         orgUnitManagedByManager `json:"manager"`
+	OrgUnits        []orgUnitListItem       `json:"org_units"`
+}
+
+func (s *orgUnitPGStore) ListOrgUnitsManagedBy(ctx context.Context, tenantID string, managerUUID string, asOfDate string, includeDisabled bool) ([]orgUnitListItem, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT
+		  `+orgNodeKeyCompatExpr("v")+` AS org_node_key,
+		  c.org_code,
+		  v.name,
+		  v.status,
+		  v.is_business_unit,
+		  `+pathOrgNodeKeysCompatExpr("v")+` AS path_org_node_keys
+		FROM orgunit.org_unit_versions v
+		JOIN orgunit.org_unit_codes c
+		  ON c.tenant_uuid = $1::uuid
+		 AND `+orgNodeKeyCompatExpr("c")+` = `+orgNodeKeyCompatExpr("v")+`
+		WHERE v.tenant_uuid = $1::uuid
+		  AND v.manager_uuid = $2::uuid
+		  AND v.validity @> $3::date
+		  AND ($4::boolean OR v.status = 'active')
+		ORDER BY c.org_code
+		`, tenantID, managerUUID, asOfDate, includeDisabled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]orgUnitListItem, 0)
+	for rows.Next() {
+		var item orgUnitListItem
+		var isBusinessUnit bool
+		if err := rows.Scan(&item.OrgNodeKey, &item.OrgCode, &item.Name, &item.Status, &isBusinessUnit, &item.PathOrgNodeKeys); err != nil {
+			return nil, err
+		}
+		item.IsBusinessUnit = &isBusinessUnit
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *orgUnitPGStore) OrgUnitManagerReferenced(ctx context.Context, tenantID string, managerUUID string) (bool, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
+		return false, err
+	}
+
+	var referenced bool
+	if err := tx.QueryRow(ctx, `
+		SELECT EXISTS (
+		  SELECT 1
+		  FROM orgunit.org_unit_versions
+		  WHERE tenant_uuid = $1::uuid
+		    AND manager_uuid = $2::uuid
+		)
+		`, tenantID, managerUUID).Scan(&referenced); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return referenced, nil
+}
+
+func handleOrgUnitsManagedByAPI(w http.ResponseWriter, r *http.Request, managers OrgUnitManagerReader, persons PersonStore, runtime ...authzRuntimeStore) {
+	if r.Method != http.MethodGet {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	if managers == nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "orgunit_manager_reader_missing", "orgunit manager reader missing")
+		return
+	}
+
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "tenant_missing", "tenant missing")
+		return
+	}
+
+	asOf, err := parseRequiredQueryDay(r, "as_of")
+	if err != nil {
+		writeInternalDayFieldError(w, r, err)
+		return
+	}
+	includeDisabled := includeDisabledFromURL(r)
+
+	manager, found, err := persons.FindByPernrAsOf(r.Context(), tenant.ID, strings.TrimSpace(r.URL.Query().Get("manager_pernr")), asOf)
+	if err != nil {
+		if errors.Is(err, errPersonPernrInvalid) {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, orgUnitErrManagerInvalid, "manager_pernr invalid")
+			return
+		}
+		writeInternalAPIError(w, r, err, "orgunit_managed_by_failed")
+		return
+	}
+	if !found {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusNotFound, orgUnitErrManagerNotFound, "manager_pernr not found")
+		return
+	}
+
+	scopeFilter, err := orgUnitReadScopeFilterFromRuntime(r.Context(), runtimeStoreFromVariadic(runtime), tenant.ID)
+	if err != nil {
+		writeOrgUnitScopeError(w, r, err)
+		return
+	}
+
+	items, err := managers.ListOrgUnitsManagedBy(r.Context(), tenant.ID, manager.PersonUUID, asOf, includeDisabled)
+	if err != nil {
+		writeInternalAPIError(w, r, err, "orgunit_managed_by_failed")
+		return
+	}
+	items = filterOrgUnitListItemsByReadScope(items, scopeFilter)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(orgUnitManagedByResponse{
+		AsOf:            asOf,
+		IncludeDisabled: includeDisabled,
+		Manager: orgUnitManagedByManager{
+			Pernr:       manager.Pernr,
+			DisplayName: manager.DisplayName,
+			Status:      manager.Status,
+		},
+		OrgUnits: items,
+	})
+}
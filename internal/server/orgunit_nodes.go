@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -67,14 +68,19 @@ type OrgUnitSearchResult struct {
 	PathOrgNodeKeys  []string `json:"-"`
 	PathOrgCodes     []string `json:"path_org_codes,omitempty"`
 	TreeAsOf         string   `json:"tree_as_of"`
+
+	MatchField string                       `json:"match_field,omitempty"`
+	Highlights []orgunitpkg.SearchHighlight `json:"highlights,omitempty"`
 }
 
 type OrgUnitSearchCandidate struct {
-	OrgID      int
-	OrgNodeKey string
-	OrgCode    string
-	Name       string
-	Status     string
+	OrgID        int
+	OrgNodeKey   string
+	OrgCode      string
+	Name         string
+	Status       string
+	FullNamePath string
+	Match        *orgunitpkg.SearchMatch
 }
 
 type OrgUnitNodeVersion struct {
@@ -134,6 +140,13 @@ func orgNodeWriteErrorMessage(err error) string {
 		"ORG_HAS_DEPENDENCIES_CANNOT_DELETE":       "存在下游依赖，不能删除",
 		"ORG_EVENT_RESCINDED":                      "该记录已删除",
 		"ORG_HIGH_RISK_REORDER_FORBIDDEN":          "该变更会触发高风险全量重放，请改用新增/插入记录",
+		"ORG_ALREADY_EXISTS":                       "组织编码已存在",
+		"ORG_MERGE_SURVIVOR_INVALID":               "合并目标无效：保留组织不能是被合并组织或其下级",
+		"ORG_ROOT_CANNOT_BE_MERGED":                "根组织不允许被合并",
+		"ORG_ROOT_CANNOT_BE_SPLIT":                 "根组织不允许拆分",
+		"ORG_SPLIT_CHILD_NOT_UNDER_SOURCE":         "拆分的下级组织必须是被拆分组织的直接下级，且只能分配一次",
+		"ORG_SPLIT_SOURCE_HAS_CHILDREN":            "被拆分组织仍有未分配的下级组织，不能停用",
+		"ORG_COMPOSITE_TARGET_DISABLED":            "参与合并/拆分的组织在生效日已停用",
 		"ORGUNIT_CODES_WRITE_FORBIDDEN":            "系统写入权限异常（ORGUNIT_CODES_WRITE_FORBIDDEN），请联系管理员",
 		"EFFECTIVE_DATE_INVALID":                   "生效日期无效",
 		"ORG_INVALID_ARGUMENT":                     "请求参数不完整",
@@ -542,6 +555,33 @@ func listNodeAuditEvents(ctx context.Context, store OrgUnitStore, tenantID strin
 	return reader.ListNodeAuditEvents(ctx, tenantID, orgID, limit)
 }
 
+type orgUnitNodeAuditBeforeReader interface {
+	ListNodeAuditEventsByNodeKeyBefore(ctx context.Context, tenantID string, orgNodeKey string, before orgUnitAuditKeyset, limit int) ([]OrgUnitNodeAuditEvent, error)
+}
+
+// listNodeAuditEventsByNodeKeyBefore reads the audit page after a cursor position. Stores without keyset
+// support are read in full and cut in memory, which is only suitable for the small in-memory stores.
+func listNodeAuditEventsByNodeKeyBefore(ctx context.Context, store OrgUnitStore, tenantID string, orgNodeKey string, before orgUnitAuditKeyset, limit int) ([]OrgUnitNodeAuditEvent, error) {
+	if reader, ok := store.(orgUnitNodeAuditBeforeReader); ok {
+		return reader.ListNodeAuditEventsByNodeKeyBefore(ctx, tenantID, orgNodeKey, before, limit)
+	}
+	rows, err := listNodeAuditEventsByNodeKey(ctx, store, tenantID, orgNodeKey, math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]OrgUnitNodeAuditEvent, 0, limit)
+	for _, row := range rows {
+		if !before.before(row) {
+			continue
+		}
+		out = append(out, row)
+		if len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
 func listNodeAuditEventsByNodeKey(ctx context.Context, store OrgUnitStore, tenantID string, orgNodeKey string, limit int) ([]OrgUnitNodeAuditEvent, error) {
 	if reader, ok := store.(orgUnitNodeAuditByKeyReader); ok {
 		return reader.ListNodeAuditEventsByNodeKey(ctx, tenantID, orgNodeKey, limit)
@@ -1106,6 +1146,15 @@ func (s *orgUnitPGStore) GetNodeDetails(ctx context.Context, tenantID string, or
 	return s.GetNodeDetailsByNodeKey(ctx, tenantID, requestedOrgNodeKey, asOfDate)
 }
 
+// orgUnitManagerJoinSQL resolves v.manager_uuid against the person directory as of $3.
+const orgUnitManagerJoinSQL = `LEFT JOIN person.persons mp
+		  ON mp.tenant_uuid = $1::uuid
+		 AND mp.person_uuid = v.manager_uuid
+		LEFT JOIN person.person_versions mpv
+		  ON mpv.tenant_uuid = $1::uuid
+		 AND mpv.person_uuid = v.manager_uuid
+		 AND mpv.validity @> $3::date`
+
 func (s *orgUnitPGStore) GetNodeDetailsByNodeKey(ctx context.Context, tenantID string, orgNodeKey string, asOfDate string) (OrgUnitNodeDetails, error) {
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
@@ -1134,8 +1183,8 @@ func (s *orgUnitPGStore) GetNodeDetailsByNodeKey(ctx context.Context, tenantID s
 		  COALESCE(pc.org_code, '') AS parent_org_code,
 		  COALESCE(pv.name, '') AS parent_name,
 		  v.is_business_unit,
-		  '' AS manager_pernr,
-		  '' AS manager_name,
+		  COALESCE(mp.pernr, '') AS manager_pernr,
+		  COALESCE(mpv.display_name, '') AS manager_name,
 		  `+pathOrgNodeKeysCompatExpr("v")+` AS path_org_node_keys,
 		  COALESCE(v.full_name_path, '') AS full_name_path,
 		  c.created_at,
@@ -1155,6 +1204,7 @@ func (s *orgUnitPGStore) GetNodeDetailsByNodeKey(ctx context.Context, tenantID s
 		 AND `+orgNodeKeyCompatExpr("pv")+` = `+parentOrgNodeKeyCompatExpr("v")+`
 		 AND pv.status = 'active'
 		 AND pv.validity @> $3::date
+		`+orgUnitManagerJoinSQL+`
 		WHERE v.tenant_uuid = $1::uuid
 		  AND `+orgNodeKeyCompatExpr("v")+` = $2::text
 		  AND v.status = 'active'
@@ -1236,8 +1286,8 @@ func (s *orgUnitPGStore) GetNodeDetailsWithVisibilityByNodeKey(ctx context.Conte
 		  COALESCE(pc.org_code, '') AS parent_org_code,
 		  COALESCE(pv.name, '') AS parent_name,
 		  v.is_business_unit,
-		  '' AS manager_pernr,
-		  '' AS manager_name,
+		  COALESCE(mp.pernr, '') AS manager_pernr,
+		  COALESCE(mpv.display_name, '') AS manager_name,
 		  `+pathOrgNodeKeysCompatExpr("v")+` AS path_org_node_keys,
 		  COALESCE(v.full_name_path, '') AS full_name_path,
 		  c.created_at,
@@ -1256,6 +1306,7 @@ func (s *orgUnitPGStore) GetNodeDetailsWithVisibilityByNodeKey(ctx context.Conte
 		  ON pv.tenant_uuid = $1::uuid
 		 AND `+orgNodeKeyCompatExpr("pv")+` = `+parentOrgNodeKeyCompatExpr("v")+`
 		 AND pv.validity @> $3::date
+		`+orgUnitManagerJoinSQL+`
 		WHERE v.tenant_uuid = $1::uuid
 		  AND `+orgNodeKeyCompatExpr("v")+` = $2::text
 		  AND v.validity @> $3::date
@@ -1450,11 +1501,7 @@ func (s *orgUnitPGStore) SearchNodeCandidates(ctx context.Context, tenantID stri
 		return nil, errors.New("query is required")
 	}
 	if limit == 0 {
-		limit = 8
-	}
-	var queryLimit any = limit
-	if limit < 0 {
-		queryLimit = nil
+		limit = orgUnitSearchDefaultLimit
 	}
 
 	tx, err := s.pool.Begin(ctx)
@@ -1504,43 +1551,14 @@ func (s *orgUnitPGStore) SearchNodeCandidates(ctx context.Context, tenantID stri
 			if err := tx.Commit(ctx); err != nil {
 				return nil, err
 			}
-			return out, nil
+			return rankOrgUnitSearchCandidates(trimmed, out, limit), nil
 		}
 	}
 
-	rows, err := tx.Query(ctx, `
-		SELECT `+orgNodeKeyCompatExpr("v")+` AS org_node_key, c.org_code, v.name
-		FROM orgunit.org_unit_versions v
-		JOIN orgunit.org_unit_codes c
-		  ON c.tenant_uuid = $1::uuid
-		 AND `+orgNodeKeyCompatExpr("c")+` = `+orgNodeKeyCompatExpr("v")+`
-		WHERE v.tenant_uuid = $1::uuid
-		  AND v.status = 'active'
-		  AND v.validity @> $3::date
-	  AND v.name ILIKE $2::text
-	ORDER BY v.node_path
-	LIMIT $4::int
-		`, tenantID, "%"+trimmed+"%", asOfDate, queryLimit)
+	out, err := queryOrgUnitSearchIndex(ctx, tx, tenantID, trimmed, asOfDate, false, limit)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var out []OrgUnitSearchCandidate
-	for rows.Next() {
-		var item OrgUnitSearchCandidate
-		if err := rows.Scan(&item.OrgNodeKey, &item.OrgCode, &item.Name); err != nil {
-			return nil, err
-		}
-		if err := hydrateOrgUnitSearchCandidateCompat(&item); err != nil {
-			return nil, err
-		}
-		item.Status = "active"
-		out = append(out, item)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
 	if len(out) == 0 {
 		return nil, errOrgUnitNotFound
 	}
@@ -1560,11 +1578,7 @@ func (s *orgUnitPGStore) SearchNodeCandidatesWithVisibility(ctx context.Context,
 		return nil, errors.New("query is required")
 	}
 	if limit == 0 {
-		limit = 8
-	}
-	var queryLimit any = limit
-	if limit < 0 {
-		queryLimit = nil
+		limit = orgUnitSearchDefaultLimit
 	}
 
 	tx, err := s.pool.Begin(ctx)
@@ -1612,41 +1626,14 @@ func (s *orgUnitPGStore) SearchNodeCandidatesWithVisibility(ctx context.Context,
 			if err := tx.Commit(ctx); err != nil {
 				return nil, err
 			}
-			return out, nil
+			return rankOrgUnitSearchCandidates(trimmed, out, limit), nil
 		}
 	}
 
-	rows, err := tx.Query(ctx, `
-		SELECT `+orgNodeKeyCompatExpr("v")+` AS org_node_key, c.org_code, v.name, v.status
-		FROM orgunit.org_unit_versions v
-		JOIN orgunit.org_unit_codes c
-		  ON c.tenant_uuid = $1::uuid
-		 AND `+orgNodeKeyCompatExpr("c")+` = `+orgNodeKeyCompatExpr("v")+`
-		WHERE v.tenant_uuid = $1::uuid
-		  AND v.validity @> $3::date
-	  AND v.name ILIKE $2::text
-	ORDER BY v.node_path
-	LIMIT $4::int
-		`, tenantID, "%"+trimmed+"%", asOfDate, queryLimit)
+	out, err := queryOrgUnitSearchIndex(ctx, tx, tenantID, trimmed, asOfDate, true, limit)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var out []OrgUnitSearchCandidate
-	for rows.Next() {
-		var item OrgUnitSearchCandidate
-		if err := rows.Scan(&item.OrgNodeKey, &item.OrgCode, &item.Name, &item.Status); err != nil {
-			return nil, err
-		}
-		if err := hydrateOrgUnitSearchCandidateCompat(&item); err != nil {
-			return nil, err
-		}
-		out = append(out, item)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
 	if len(out) == 0 {
 		return nil, errOrgUnitNotFound
 	}
@@ -1720,6 +1707,15 @@ func (s *orgUnitPGStore) ListNodeAuditEvents(ctx context.Context, tenantID strin
 }
 
 func (s *orgUnitPGStore) ListNodeAuditEventsByNodeKey(ctx context.Context, tenantID string, orgNodeKey string, limit int) ([]OrgUnitNodeAuditEvent, error) {
+	return s.listNodeAuditEventsByNodeKey(ctx, tenantID, orgNodeKey, nil, limit)
+}
+
+// ListNodeAuditEventsByNodeKeyBefore continues an audit page strictly after (older than) before.
+func (s *orgUnitPGStore) ListNodeAuditEventsByNodeKeyBefore(ctx context.Context, tenantID string, orgNodeKey string, before orgUnitAuditKeyset, limit int) ([]OrgUnitNodeAuditEvent, error) {
+	return s.listNodeAuditEventsByNodeKey(ctx, tenantID, orgNodeKey, &before, limit)
+}
+
+func (s *orgUnitPGStore) listNodeAuditEventsByNodeKey(ctx context.Context, tenantID string, orgNodeKey string, before *orgUnitAuditKeyset, limit int) ([]OrgUnitNodeAuditEvent, error) {
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return nil, err
@@ -1739,6 +1735,14 @@ func (s *orgUnitPGStore) ListNodeAuditEventsByNodeKey(ctx context.Context, tenan
 		return nil, err
 	}
 
+	args := []any{tenantID, requestedOrgNodeKey, limit}
+	beforeSQL := ""
+	if before != nil {
+		beforeSQL = `
+		  AND (e.tx_time, e.id) < ($4::timestamptz, $5::bigint)`
+		args = append(args, before.TxTime, before.EventID)
+	}
+
 	rows, err := tx.Query(ctx, `
 		SELECT
 		  e.id,
@@ -1771,10 +1775,10 @@ func (s *orgUnitPGStore) ListNodeAuditEventsByNodeKey(ctx context.Context, tenan
 	  LIMIT 1
 		) re ON true
 		WHERE e.tenant_uuid = $1::uuid
-		  AND `+orgNodeKeyCompatExpr("e")+` = $2::text
+		  AND `+orgNodeKeyCompatExpr("e")+` = $2::text`+beforeSQL+`
 		ORDER BY e.tx_time DESC, e.id DESC
 		LIMIT $3::int
-		`, tenantID, requestedOrgNodeKey, limit)
+		`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -2800,35 +2804,50 @@ func (s *orgUnitMemoryStore) SearchNodeCandidates(_ context.Context, tenantID st
 		}
 	}
 
-	lower := strings.ToLower(trimmed)
 	var out []OrgUnitSearchCandidate
 	for _, node := range s.nodes[tenantID] {
-		if strings.Contains(strings.ToLower(node.Name), lower) {
-			orgNodeKey, ok := orgUnitNodeStoredKey(node)
-			if !ok {
-				continue
-			}
-			item := OrgUnitSearchCandidate{
-				OrgNodeKey: orgNodeKey,
-				OrgCode:    node.OrgCode,
-				Name:       node.Name,
-				Status:     strings.TrimSpace(node.Status),
-			}
-			if err := hydrateOrgUnitSearchCandidateCompat(&item); err != nil {
-				return nil, err
-			}
-			out = append(out, item)
-			if limit > 0 && len(out) >= limit {
-				break
-			}
+		orgNodeKey, ok := orgUnitNodeStoredKey(node)
+		if !ok {
+			continue
 		}
+		fullNamePath := s.fullNamePathForNode(tenantID, orgNodeKey)
+		if _, ok := orgunitpkg.MatchSearch(trimmed, orgunitpkg.SearchDocument{OrgCode: node.OrgCode, Name: node.Name, FullNamePath: fullNamePath}); !ok {
+			continue
+		}
+		item := OrgUnitSearchCandidate{
+			OrgNodeKey:   orgNodeKey,
+			OrgCode:      node.OrgCode,
+			Name:         node.Name,
+			Status:       strings.TrimSpace(node.Status),
+			FullNamePath: fullNamePath,
+		}
+		if err := hydrateOrgUnitSearchCandidateCompat(&item); err != nil {
+			return nil, err
+		}
+		out = append(out, item)
 	}
+	out = rankOrgUnitSearchCandidates(trimmed, out, limit)
 	if len(out) == 0 {
 		return nil, errOrgUnitNotFound
 	}
 	return out, nil
 }
 
+// fullNamePathForNode mirrors org_unit_versions.full_name_path ("总部 / 研发部") for the memory store.
+func (s *orgUnitMemoryStore) fullNamePathForNode(tenantID string, orgNodeKey string) string {
+	keys, err := s.pathForNode(tenantID, orgNodeKey)
+	if err != nil {
+		return ""
+	}
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if node, ok := s.nodeByKey(tenantID, key); ok {
+			names = append(names, strings.TrimSpace(node.Name))
+		}
+	}
+	return strings.Join(names, " / ")
+}
+
 func (s *orgUnitMemoryStore) SearchNodeCandidatesWithVisibility(ctx context.Context, tenantID string, query string, asOfDate string, limit int, _ bool) ([]OrgUnitSearchCandidate, error) {
 	return s.SearchNodeCandidates(ctx, tenantID, query, asOfDate, limit)
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"strings"
 	"testing"
@@ -1571,6 +1572,66 @@ func TestHandleOrgUnitsSearchAPI_AmbiguousCandidates(t *testing.T) {
 	}
 }
 
+func TestHandleOrgUnitsSearchAPI_PinyinAndLimit(t *testing.T) {
+	store := newOrgUnitMemoryStore()
+	root, err := store.CreateNodeCurrent(context.Background(), "t1", "2026-01-01", "HQ", "总部", "", true)
+	if err != nil {
+		t.Fatalf("create root err=%v", err)
+	}
+	for _, item := range []struct {
+		code string
+		name string
+	}{
+		{code: "RD", name: "研发部"},
+		{code: "RD01", name: "研发中心"},
+		{code: "OPS", name: "运维部"},
+	} {
+		if _, err := store.CreateNodeCurrent(context.Background(), "t1", "2026-01-01", item.code, item.name, root.ID, false); err != nil {
+			t.Fatalf("create %s err=%v", item.code, err)
+		}
+	}
+
+	search := func(rawQuery string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/org/api/org-units/search?as_of=2026-01-01&"+rawQuery, nil)
+		req = req.WithContext(withTenant(req.Context(), Tenant{ID: "t1", Name: "T"}))
+		rec := httptest.NewRecorder()
+		handleOrgUnitsSearchAPI(rec, req, store)
+		return rec
+	}
+
+	for _, query := range []string{"yfb", "yanfabu", "研发布"} {
+		rec := search("query=" + url.QueryEscape(query))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("query=%s status=%d body=%s", query, rec.Code, rec.Body.String())
+		}
+		if !strings.Contains(rec.Body.String(), `"target_org_code":"RD"`) || !strings.Contains(rec.Body.String(), `"highlights":[{"field":"name","start":0,"end":3}]`) {
+			t.Fatalf("query=%s body=%s", query, rec.Body.String())
+		}
+	}
+
+	rec := search("query=yanfa")
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	for _, expected := range []string{`"org_code":"RD"`, `"org_code":"RD01"`, `"match_field":"pinyin"`, `"full_name_path":"总部 / 研发中心"`} {
+		if !strings.Contains(rec.Body.String(), expected) {
+			t.Fatalf("expected body to contain %q, got %s", expected, rec.Body.String())
+		}
+	}
+	if strings.Contains(rec.Body.String(), `"org_code":"OPS"`) {
+		t.Fatalf("body=%s", rec.Body.String())
+	}
+
+	if rec := search("query=yanfa&limit=1"); rec.Code != http.StatusOK {
+		t.Fatalf("limit=1 status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	for _, limit := range []string{"0", "51", "x"} {
+		if rec := search("query=yanfa&limit=" + limit); rec.Code != http.StatusBadRequest {
+			t.Fatalf("limit=%s status=%d body=%s", limit, rec.Code, rec.Body.String())
+		}
+	}
+}
+
 func TestHandleOrgUnitsSearchAPI_ReturnsOnlyVisibleCandidate(t *testing.T) {
 	store := newOrgUnitMemoryStore()
 	for _, item := range []struct {
@@ -26,8 +26,10 @@ type orgUnitListPageRequest struct {
 	SortOrder          string
 	ExtFilterFieldKey  string
 	ExtFilterValue     string
+	Filter             *orgunitservices.OrgUnitListFilter
 	Limit              int
 	Offset             int
+	Keyset             *orgunitservices.OrgUnitListKeyset
 }
 
 func (r orgUnitListPageRequest) ShouldSearchAllOrgUnits() bool {
@@ -105,7 +107,7 @@ func (a orgUnitReadStoreAdapter) ListTree(ctx context.Context, tenantID string,
 }
 
 func (a orgUnitReadStoreAdapter) ListPage(ctx context.Context, req orgunitservices.OrgUnitReadListPageRequest) ([]orgunitservices.OrgUnitReadNode, int, error) {
-	if strings.TrimSpace(req.ExtFilterFieldKey) != "" || strings.TrimSpace(req.ExtSortFieldKey) != "" {
+	if strings.TrimSpace(req.ExtFilterFieldKey) != "" || strings.TrimSpace(req.ExtSortFieldKey) != "" || req.Filter != nil {
 		if _, ok := a.store.(orgUnitListPageReader); !ok {
 			return nil, 0, orgunitservices.ErrOrgUnitReadExtQueryNotAllowed
 		}
@@ -148,8 +150,10 @@ func (a orgUnitReadStoreAdapter) ListPage(ctx context.Context, req orgunitservic
 		SortOrder:          req.SortOrder,
 		ExtFilterFieldKey:  req.ExtFilterFieldKey,
 		ExtFilterValue:     req.ExtFilterValue,
+		Filter:             req.Filter,
 		Limit:              req.Limit,
 		Offset:             req.Offset,
+		Keyset:             req.Keyset,
 	})
 	if err != nil {
 		if errors.Is(err, errOrgUnitExtQueryFieldNotAllowed) {
@@ -213,6 +217,10 @@ func (a orgUnitReadStoreAdapter) Search(ctx context.Context, tenantID string, qu
 		if err != nil {
 			return nil, err
 		}
+		if readNode.FullNamePath == "" {
+			readNode.FullNamePath = strings.TrimSpace(candidate.FullNamePath)
+		}
+		readNode.SearchMatch = candidate.Match
 		out = append(out, readNode)
 	}
 	return out, nil
@@ -359,6 +367,18 @@ func listOrgUnitListPage(ctx context.Context, store OrgUnitStore, tenantID strin
 	items = filterOrgUnitListItemsByReadScope(items, req.ScopeFilter)
 
 	total := len(items)
+	if req.Keyset != nil {
+		byKey := make(map[string]orgUnitListItem, len(items))
+		for _, item := range items {
+			byKey[item.OrgNodeKey] = item
+		}
+		nodes := orgunitservices.PageReadNodesByKeyset(orgUnitReadNodesFromListItems(items), req.SortField, req.SortOrder, *req.Keyset, req.Limit)
+		page := make([]orgUnitListItem, 0, len(nodes))
+		for _, node := range nodes {
+			page = append(page, byKey[node.OrgNodeKey])
+		}
+		return page, total, nil
+	}
 	if req.Limit <= 0 {
 		return items, total, nil
 	}
@@ -613,6 +633,7 @@ func orgUnitReadNodesFromListItems(items []orgUnitListItem) []orgunitservices.Or
 			IsBusinessUnit:     item.IsBusinessUnit,
 			HasVisibleChildren: boolPtrValue(item.HasVisibleChildren, boolPtrValue(item.HasChildren, false)),
 			PathOrgNodeKeys:    append([]string(nil), item.PathOrgNodeKeys...),
+			SortValue:          item.SortValue,
 		})
 	}
 	return out
@@ -634,6 +655,10 @@ func orgUnitSearchResultFromReadNode(node orgunitservices.OrgUnitReadNode, asOf
 		PathOrgCodes:     append([]string(nil), node.PathOrgCodes...),
 		TreeAsOf:         strings.TrimSpace(asOf),
 	}
+	if node.SearchMatch != nil {
+		result.MatchField = node.SearchMatch.Field
+		result.Highlights = node.SearchMatch.Highlights
+	}
 	_ = hydrateOrgUnitSearchResultCompat(&result)
 	return result
 }
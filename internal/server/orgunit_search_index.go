@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	orgunitpkg "github.com/jacksonlee411/Bugs-And-Blossoms/pkg/orgunit"
+)
+
+const (
+	orgUnitSearchDefaultLimit = 8
+	orgUnitSearchMaxLimit     = 50
+
+	// orgUnitSearchIndexPoolMin is how many index rows are fetched at least before ranking, so a strong
+	// match is not cut off by the SQL pre-ordering.
+	orgUnitSearchIndexPoolMin = 50
+)
+
+// orgUnitSearchIndexQuery recalls candidates from orgunit.org_unit_search_index: substring hits on name,
+// full name path, org_code and pinyin, plus pg_trgm fuzzy hits on name and pinyin. Ranking and
+// highlighting happen in Go (rankOrgUnitSearchCandidates) so every store ranks the same way.
+const orgUnitSearchIndexQuery = `
+SELECT i.org_node_key::text AS org_node_key, i.org_code, i.name, i.status, i.full_name_path
+FROM orgunit.org_unit_search_index i
+WHERE i.tenant_uuid = $1::uuid
+  AND i.validity @> $2::date
+  AND ($3::boolean OR i.status = 'active')
+  AND (
+    lower(i.name) LIKE $4::text
+    OR lower(i.full_name_path) LIKE $4::text
+    OR lower(i.org_code) LIKE $5::text
+    OR i.name_pinyin LIKE $6::text
+    OR i.name_initials LIKE $7::text
+    OR lower(i.name) % $8::text
+    OR i.name_pinyin % $9::text
+  )
+ORDER BY
+  COALESCE(lower(i.name) LIKE $4::text OR i.name_pinyin LIKE $6::text OR i.name_initials LIKE $7::text, false) DESC,
+  GREATEST(similarity(lower(i.name), $8::text), similarity(COALESCE(i.name_pinyin, ''), COALESCE($9::text, ''))) DESC,
+  i.full_name_path,
+  i.org_node_key
+LIMIT $10::int
+`
+
+func queryOrgUnitSearchIndex(ctx context.Context, tx pgx.Tx, tenantID string, query string, asOfDate string, includeDisabled bool, limit int) ([]OrgUnitSearchCandidate, error) {
+	q := strings.ToLower(strings.TrimSpace(query))
+	var pool any
+	if limit > 0 {
+		pool = max(limit*5, orgUnitSearchIndexPoolMin)
+	}
+	var pinyinTerm, pinyinLike, initialsLike any
+	if term := orgunitpkg.SearchQueryPinyin(q); term != "" {
+		pinyinTerm = term
+		pinyinLike = "%" + escapeOrgUnitSearchLike(term) + "%"
+		// Initials only make sense for Latin input ("yfb"); Chinese input meets the index on full pinyin.
+		if len(term) > 1 && isASCIIOrgUnitSearchTerm(q) {
+			initialsLike = pinyinLike
+		}
+	}
+
+	rows, err := tx.Query(ctx, orgUnitSearchIndexQuery,
+		tenantID,
+		asOfDate,
+		includeDisabled,
+		"%"+escapeOrgUnitSearchLike(q)+"%",
+		escapeOrgUnitSearchLike(q)+"%",
+		pinyinLike,
+		initialsLike,
+		q,
+		pinyinTerm,
+		pool,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []OrgUnitSearchCandidate
+	for rows.Next() {
+		var item OrgUnitSearchCandidate
+		if err := rows.Scan(&item.OrgNodeKey, &item.OrgCode, &item.Name, &item.Status, &item.FullNamePath); err != nil {
+			return nil, err
+		}
+		if err := hydrateOrgUnitSearchCandidateCompat(&item); err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(item.Status) == "" {
+			item.Status = "active"
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return rankOrgUnitSearchCandidates(query, out, limit), nil
+}
+
+func isASCIIOrgUnitSearchTerm(s string) bool {
+	for _, r := range s {
+		if r >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+func escapeOrgUnitSearchLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// rankOrgUnitSearchCandidates scores candidates against query, keeps only the best match tier and
+// orders by score. Rows the store recalled but the Go matcher cannot explain (pg_trgm is slightly more
+// lenient) are kept as fuzzy matches rather than dropped.
+func rankOrgUnitSearchCandidates(query string, candidates []OrgUnitSearchCandidate, limit int) []OrgUnitSearchCandidate {
+	if len(candidates) == 0 {
+		return candidates
+	}
+	bestTier := 0
+	for i := range candidates {
+		match, ok := orgunitpkg.MatchSearch(query, orgunitpkg.SearchDocument{
+			OrgCode:      candidates[i].OrgCode,
+			Name:         candidates[i].Name,
+			FullNamePath: candidates[i].FullNamePath,
+		})
+		if !ok {
+			match = orgunitpkg.SearchMatch{Field: orgunitpkg.SearchFieldName, Fuzzy: true}
+		}
+		candidates[i].Match = &match
+		bestTier = max(bestTier, match.Tier())
+	}
+	out := candidates[:0]
+	for _, candidate := range candidates {
+		if candidate.Match.Tier() == bestTier {
+			out = append(out, candidate)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Match.Score > out[j].Match.Score
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	orgunitpkg "github.com/jacksonlee411/Bugs-And-Blossoms/pkg/orgunit"
+)
+
+type searchIndexArgsTx struct {
+	*stubTx
+	args []any
+}
+
+func (t *searchIndexArgsTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	t.args = args
+	return t.stubTx.Query(ctx, sql, args...)
+}
+
+func TestQueryOrgUnitSearchIndex_Args(t *testing.T) {
+	tx := &searchIndexArgsTx{stubTx: &stubTx{rows: &recordRows{}}}
+	if _, err := queryOrgUnitSearchIndex(context.Background(), tx, "t1", " YFB ", "2026-01-01", false, 5); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if tx.args[3] != "%yfb%" || tx.args[4] != "yfb%" || tx.args[5] != "%yfb%" || tx.args[6] != "%yfb%" || tx.args[8] != "yfb" || tx.args[9] != orgUnitSearchIndexPoolMin {
+		t.Fatalf("args=%v", tx.args)
+	}
+
+	tx = &searchIndexArgsTx{stubTx: &stubTx{rows: &recordRows{}}}
+	if _, err := queryOrgUnitSearchIndex(context.Background(), tx, "t1", "研发_1", "2026-01-01", true, -1); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if tx.args[2] != true || tx.args[3] != `%研发\_1%` || tx.args[5] != "%yanfa1%" || tx.args[6] != nil || tx.args[9] != nil {
+		t.Fatalf("args=%v", tx.args)
+	}
+}
+
+func TestQueryOrgUnitSearchIndex_RanksRecalledRows(t *testing.T) {
+	tx := &stubTx{rows: &recordRows{records: [][]any{
+		{"10000003", "RD01", "研发中心", "active", "总部 / 研发部 / 研发中心"},
+		{"10000002", "RD", "研发部", "", "总部 / 研发部"},
+		{"10000004", "YF", "运费部", "active", "总部 / 运费部"},
+	}}}
+	out, err := queryOrgUnitSearchIndex(context.Background(), tx, "t1", "yfb", "2026-01-01", false, 8)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	// 研发中心 is only a trigram recall here and drops out next to two initials hits.
+	if len(out) != 2 || out[0].OrgCode != "RD" || out[0].Status != "active" || out[1].OrgCode != "YF" {
+		t.Fatalf("out=%+v", out)
+	}
+	if out[0].Match == nil || out[0].Match.Field != orgunitpkg.SearchFieldPinyinInitials || !out[0].Match.IsExact() {
+		t.Fatalf("match=%+v", out[0].Match)
+	}
+}
+
+func TestRankOrgUnitSearchCandidates_KeepsBestTier(t *testing.T) {
+	candidates := []OrgUnitSearchCandidate{
+		{OrgCode: "QA", Name: "质量组", FullNamePath: "总部 / 研发部 / 质量组"},
+		{OrgCode: "RD", Name: "研发部", FullNamePath: "总部 / 研发部"},
+		{OrgCode: "X", Name: "无关"},
+	}
+	out := rankOrgUnitSearchCandidates("研发", candidates, 0)
+	if len(out) != 1 || out[0].OrgCode != "RD" {
+		t.Fatalf("out=%+v", out)
+	}
+
+	out = rankOrgUnitSearchCandidates("无法匹配", []OrgUnitSearchCandidate{{OrgCode: "X", Name: "无关"}}, 1)
+	if len(out) != 1 || !out[0].Match.Fuzzy {
+		t.Fatalf("unexplained recall should stay as fuzzy: %+v", out)
+	}
+	if out := rankOrgUnitSearchCandidates("x", nil, 1); len(out) != 0 {
+		t.Fatalf("out=%+v", out)
+	}
+}
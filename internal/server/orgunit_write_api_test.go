@@ -55,6 +55,12 @@ func (s fakeOrgUnitWriteService) RescindRecord(context.Context, string, orgunits
 func (s fakeOrgUnitWriteService) RescindOrg(context.Context, string, orgunitservices.RescindOrgUnitRequest) (orgunittypes.OrgUnitResult, error) {
 	return orgunittypes.OrgUnitResult{}, nil
 }
+func (s fakeOrgUnitWriteService) Merge(context.Context, string, orgunitservices.MergeOrgUnitsRequest) (orgunittypes.OrgUnitResult, error) {
+	return orgunittypes.OrgUnitResult{}, nil
+}
+func (s fakeOrgUnitWriteService) Split(context.Context, string, orgunitservices.SplitOrgUnitRequest) (orgunittypes.OrgUnitResult, error) {
+	return orgunittypes.OrgUnitResult{}, nil
+}
 
 func TestHandleOrgUnitsWriteAPI_BasicValidation(t *testing.T) {
 	svc := fakeOrgUnitWriteService{}
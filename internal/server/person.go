@@ -0,0 +1,288 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+	personpersistence "github.com/jacksonlee411/Bugs-And-Blossoms/modules/person/infrastructure/persistence"
+)
+
+type PersonStore = personpersistence.Store
+type PersonItem = personpersistence.PersonItem
+type PersonDetail = personpersistence.PersonDetail
+type PersonUpsertRequest = personpersistence.UpsertPersonRequest
+type PersonImportResult = personpersistence.ImportResult
+
+const personErrInUse = "PERSON_IN_USE"
+
+var (
+	errPersonPernrInvalid         = personpersistence.ErrPersonPernrInvalid
+	errPersonNotFound             = personpersistence.ErrPersonNotFound
+	errPersonDisplayNameRequired  = personpersistence.ErrPersonDisplayNameRequired
+	errPersonStatusInvalid        = personpersistence.ErrPersonStatusInvalid
+	errPersonEffectiveDateInvalid = personpersistence.ErrPersonEffectiveDateInvalid
+	errPersonImportEmpty          = personpersistence.ErrPersonImportEmpty
+	errPersonImportTooLarge       = personpersistence.ErrPersonImportTooLarge
+)
+
+type personListResponse struct {
+	AsOf    string       `json:"as_of"`
+	Persons []PersonItem `json:"persons"`
+}
+
+type personMutationResponse struct {
+	PersonItem
+	Created bool `json:"created"`
+}
+
+type personUpsertPayload struct {
+	Pernr         string `json:"pernr"`
+	DisplayName   string `json:"display_name"`
+	Status        string `json:"status"`
+	EffectiveDate string `json:"effective_date"`
+}
+
+func (p personUpsertPayload) request() PersonUpsertRequest {
+	return PersonUpsertRequest{Pernr: p.Pernr, DisplayName: p.DisplayName, Status: p.Status, EffectiveDate: p.EffectiveDate}
+}
+
+type personImportPayload struct {
+	Persons []personUpsertPayload `json:"persons"`
+}
+
+type personDeletePayload struct {
+	Pernr string `json:"pernr"`
+}
+
+type personDeleteResponse struct {
+	Pernr   string `json:"pernr"`
+	Deleted bool   `json:"deleted"`
+}
+
+func handlePersonsAPI(w http.ResponseWriter, r *http.Request, store PersonStore) {
+	switch r.Method {
+	case http.MethodGet:
+		handlePersonsListAPI(w, r, store)
+	case http.MethodPost:
+		handlePersonsUpsertAPI(w, r, store)
+	default:
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+	}
+}
+
+func handlePersonsListAPI(w http.ResponseWriter, r *http.Request, store PersonStore) {
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "tenant_missing", "tenant missing")
+		return
+	}
+
+	asOf, err := requiredAsOf(r)
+	if err != nil {
+		writeInternalDayFieldError(w, r, err)
+		return
+	}
+
+	limit := 50
+	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "limit invalid")
+			return
+		}
+		limit = n
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	items, err := store.ListPersons(r.Context(), tenant.ID, asOf, r.URL.Query().Get("keyword"), limit)
+	if err != nil {
+		writePersonAPIError(w, r, err, "person_list_failed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(personListResponse{AsOf: asOf, Persons: items})
+}
+
+func handlePersonsUpsertAPI(w http.ResponseWriter, r *http.Request, store PersonStore) {
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "tenant_missing", "tenant missing")
+		return
+	}
+
+	var req personUpsertPayload
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_json", "bad json")
+		return
+	}
+
+	item, created, err := store.UpsertPerson(r.Context(), tenant.ID, req.request())
+	if err != nil {
+		writePersonAPIError(w, r, err, "person_upsert_failed")
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(personMutationResponse{PersonItem: item, Created: created})
+}
+
+func handlePersonDetailAPI(w http.ResponseWriter, r *http.Request, store PersonStore) {
+	if r.Method != http.MethodGet {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "tenant_missing", "tenant missing")
+		return
+	}
+
+	asOf, err := requiredAsOf(r)
+	if err != nil {
+		writeInternalDayFieldError(w, r, err)
+		return
+	}
+
+	detail, err := store.GetPerson(r.Context(), tenant.ID, r.URL.Query().Get("pernr"), asOf)
+	if err != nil {
+		writePersonAPIError(w, r, err, "person_get_failed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(detail)
+}
+
+func handlePersonsImportAPI(w http.ResponseWriter, r *http.Request, store PersonStore) {
+	if r.Method != http.MethodPost {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "tenant_missing", "tenant missing")
+		return
+	}
+
+	var req personImportPayload
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_json", "bad json")
+		return
+	}
+
+	reqs := make([]PersonUpsertRequest, 0, len(req.Persons))
+	for _, row := range req.Persons {
+		reqs = append(reqs, row.request())
+	}
+	result, err := store.ImportPersons(r.Context(), tenant.ID, reqs)
+	if err != nil {
+		writePersonAPIError(w, r, err, "person_import_failed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// handlePersonsDeleteAPI refuses to delete a person that any org unit version still names as manager;
+// managerRefs may be nil when the org store keeps no manager references.
+func handlePersonsDeleteAPI(w http.ResponseWriter, r *http.Request, store PersonStore, managerRefs OrgUnitManagerReader) {
+	if r.Method != http.MethodPost {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "tenant_missing", "tenant missing")
+		return
+	}
+
+	var req personDeletePayload
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_json", "bad json")
+		return
+	}
+
+	personUUID, found, err := store.ResolvePersonUUID(r.Context(), tenant.ID, req.Pernr)
+	if err != nil {
+		writePersonAPIError(w, r, err, "person_delete_failed")
+		return
+	}
+	if !found {
+		writePersonAPIError(w, r, errPersonNotFound, "person_delete_failed")
+		return
+	}
+	if managerRefs != nil {
+		referenced, err := managerRefs.OrgUnitManagerReferenced(r.Context(), tenant.ID, personUUID)
+		if err != nil {
+			writePersonAPIError(w, r, err, "person_delete_failed")
+			return
+		}
+		if referenced {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusConflict, personErrInUse, "person is still referenced as org unit manager")
+			return
+		}
+	}
+
+	if _, err := store.DeletePerson(r.Context(), tenant.ID, req.Pernr); err != nil {
+		writePersonAPIError(w, r, err, "person_delete_failed")
+		return
+	}
+
+	pernr, _ := personpersistence.NormalizePernr(req.Pernr)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(personDeleteResponse{Pernr: pernr, Deleted: true})
+}
+
+func writePersonAPIError(w http.ResponseWriter, r *http.Request, err error, defaultCode string) {
+	code, status := personErrorCode(err)
+	if code == "" {
+		writeInternalAPIError(w, r, err, defaultCode)
+		return
+	}
+	message := defaultCode
+	var rowErr *personpersistence.ImportRowError
+	if errors.As(err, &rowErr) {
+		message = fmt.Sprintf("row %d: %s", rowErr.Row, code)
+	}
+	routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, message)
+}
+
+func personErrorCode(err error) (string, int) {
+	if errors.Is(err, errPersonNotFound) {
+		return errPersonNotFound.Error(), http.StatusNotFound
+	}
+	for _, target := range []error{
+		errPersonPernrInvalid,
+		errPersonDisplayNameRequired,
+		errPersonStatusInvalid,
+		errPersonEffectiveDateInvalid,
+		errPersonImportEmpty,
+		errPersonImportTooLarge,
+	} {
+		if errors.Is(err, target) {
+			return target.Error(), http.StatusBadRequest
+		}
+	}
+	return "", 0
+}
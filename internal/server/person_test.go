@@ -0,0 +1,193 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+	orgunitmodule "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit"
+	personmodule "github.com/jacksonlee411/Bugs-And-Blossoms/modules/person"
+)
+
+type orgUnitManagerReaderStub struct {
+	items      []orgUnitListItem
+	referenced bool
+	gotUUID    string
+	gotAsOf    string
+}
+
+func (s *orgUnitManagerReaderStub) ListOrgUnitsManagedBy(_ context.Context, _ string, managerUUID string, asOfDate string, _ bool) ([]orgUnitListItem, error) {
+	s.gotUUID = managerUUID
+	s.gotAsOf = asOfDate
+	return s.items, nil
+}
+
+func (s *orgUnitManagerReaderStub) OrgUnitManagerReferenced(_ context.Context, _ string, managerUUID string) (bool, error) {
+	s.gotUUID = managerUUID
+	return s.referenced, nil
+}
+
+func personTestRequest(method string, target string, body string) *http.Request {
+	var req *http.Request
+	if body == "" {
+		req = httptest.NewRequest(method, target, nil)
+	} else {
+		req = httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	}
+	return req.WithContext(withTenant(req.Context(), Tenant{ID: "t1", Domain: "localhost", Name: "T"}))
+}
+
+func decodePersonErrorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var env routing.ErrorEnvelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &env); err != nil {
+		t.Fatalf("decode error body %q: %v", rec.Body.String(), err)
+	}
+	return env.Code
+}
+
+func TestPersonsAPI_UpsertImportListDetail(t *testing.T) {
+	store := personmodule.NewMemoryStore()
+
+	rec := httptest.NewRecorder()
+	handlePersonsAPI(rec, personTestRequest(http.MethodPost, "/person/api/persons", `{"pernr":"0101","display_name":"Alice","effective_date":"2026-01-01"}`), store)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	handlePersonsAPI(rec, personTestRequest(http.MethodPost, "/person/api/persons", `{"pernr":"101","display_name":"Alice","status":"inactive","effective_date":"2026-06-01"}`), store)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handlePersonsImportAPI(rec, personTestRequest(http.MethodPost, "/person/api/persons:import", `{"persons":[{"pernr":"102","display_name":"Bob","effective_date":"2026-01-01"},{"pernr":"103","display_name":"","effective_date":"2026-01-01"}]}`), store)
+	if rec.Code != http.StatusBadRequest || decodePersonErrorCode(t, rec) != "PERSON_DISPLAY_NAME_REQUIRED" || !strings.Contains(rec.Body.String(), "row 2") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	handlePersonsImportAPI(rec, personTestRequest(http.MethodPost, "/person/api/persons:import", `{"persons":[{"pernr":"102","display_name":"Bob","effective_date":"2026-01-01"}]}`), store)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"created":1`) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handlePersonsAPI(rec, personTestRequest(http.MethodGet, "/person/api/persons?as_of=2026-03-01", ""), store)
+	var list personListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || rec.Code != http.StatusOK || len(list.Persons) != 2 {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handlePersonsAPI(rec, personTestRequest(http.MethodGet, "/person/api/persons", ""), store)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("missing as_of status=%d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handlePersonDetailAPI(rec, personTestRequest(http.MethodGet, "/person/api/persons:by-pernr?pernr=101&as_of=2026-07-01", ""), store)
+	var detail PersonDetail
+	if err := json.Unmarshal(rec.Body.Bytes(), &detail); err != nil || detail.Status != "inactive" || len(detail.Versions) != 2 {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handlePersonDetailAPI(rec, personTestRequest(http.MethodGet, "/person/api/persons:by-pernr?pernr=999&as_of=2026-07-01", ""), store)
+	if rec.Code != http.StatusNotFound || decodePersonErrorCode(t, rec) != "PERSON_NOT_FOUND" {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPersonsDeleteAPI_RefusesReferencedManager(t *testing.T) {
+	store := personmodule.NewMemoryStore()
+	item, _, err := store.UpsertPerson(context.Background(), "t1", PersonUpsertRequest{Pernr: "7", DisplayName: "Alice", EffectiveDate: "2026-01-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs := &orgUnitManagerReaderStub{referenced: true}
+
+	rec := httptest.NewRecorder()
+	handlePersonsDeleteAPI(rec, personTestRequest(http.MethodPost, "/person/api/persons:delete", `{"pernr":"7"}`), store, refs)
+	if rec.Code != http.StatusConflict || decodePersonErrorCode(t, rec) != personErrInUse || refs.gotUUID != item.PersonUUID {
+		t.Fatalf("status=%d body=%s uuid=%q", rec.Code, rec.Body.String(), refs.gotUUID)
+	}
+
+	refs.referenced = false
+	rec = httptest.NewRecorder()
+	handlePersonsDeleteAPI(rec, personTestRequest(http.MethodPost, "/person/api/persons:delete", `{"pernr":"7"}`), store, refs)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handlePersonsDeleteAPI(rec, personTestRequest(http.MethodPost, "/person/api/persons:delete", `{"pernr":"7"}`), store, refs)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestOrgUnitsManagedByAPI(t *testing.T) {
+	store := personmodule.NewMemoryStore()
+	item, _, err := store.UpsertPerson(context.Background(), "t1", PersonUpsertRequest{Pernr: "7", DisplayName: "Alice", EffectiveDate: "2026-01-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	managers := &orgUnitManagerReaderStub{items: []orgUnitListItem{{OrgCode: "R&D", OrgNodeKey: "10000002", Name: "R&D", Status: "active"}}}
+
+	rec := httptest.NewRecorder()
+	handleOrgUnitsManagedByAPI(rec, personTestRequest(http.MethodGet, "/org/api/org-units/managed-by?manager_pernr=007&as_of=2026-02-01", ""), managers, store)
+	var resp orgUnitManagedByResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	if resp.Manager.DisplayName != "Alice" || len(resp.OrgUnits) != 1 || managers.gotUUID != item.PersonUUID || managers.gotAsOf != "2026-02-01" {
+		t.Fatalf("resp=%+v managers=%+v", resp, managers)
+	}
+
+	rec = httptest.NewRecorder()
+	handleOrgUnitsManagedByAPI(rec, personTestRequest(http.MethodGet, "/org/api/org-units/managed-by?manager_pernr=8&as_of=2026-02-01", ""), managers, store)
+	if rec.Code != http.StatusNotFound || decodePersonErrorCode(t, rec) != orgUnitErrManagerNotFound {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handleOrgUnitsManagedByAPI(rec, personTestRequest(http.MethodGet, "/org/api/org-units/managed-by?manager_pernr=x1&as_of=2026-02-01", ""), managers, store)
+	if rec.Code != http.StatusBadRequest || decodePersonErrorCode(t, rec) != orgUnitErrManagerInvalid {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handleOrgUnitsManagedByAPI(rec, personTestRequest(http.MethodGet, "/org/api/org-units/managed-by?manager_pernr=7&as_of=2026-02-01", ""), nil, store)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPersonManagerDirectory(t *testing.T) {
+	store := personmodule.NewMemoryStore()
+	ctx := context.Background()
+	if _, _, err := store.UpsertPerson(ctx, "t1", PersonUpsertRequest{Pernr: "7", DisplayName: "Alice", EffectiveDate: "2026-01-01"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.UpsertPerson(ctx, "t1", PersonUpsertRequest{Pernr: "7", DisplayName: "Alice", Status: "inactive", EffectiveDate: "2026-06-01"}); err != nil {
+		t.Fatal(err)
+	}
+	dir := orgunitmodule.NewPersonManagerDirectory(store)
+
+	rec, found, err := dir.FindManagerAsOf(ctx, "t1", "7", "2026-03-01")
+	if err != nil || !found || !rec.Active || rec.DisplayName != "Alice" {
+		t.Fatalf("rec=%+v found=%v err=%v", rec, found, err)
+	}
+	rec, found, err = dir.FindManagerAsOf(ctx, "t1", "7", "2026-07-01")
+	if err != nil || !found || rec.Active {
+		t.Fatalf("rec=%+v found=%v err=%v", rec, found, err)
+	}
+	if _, found, err := dir.FindManagerAsOf(ctx, "t1", "8", "2026-03-01"); err != nil || found {
+		t.Fatalf("found=%v err=%v", found, err)
+	}
+}
@@ -8,6 +8,8 @@ import (
 
 type sessionCapabilitiesResponse struct {
 	AuthzCapabilityKeys []string `json:"authz_capability_keys"`
+	// Impersonation lets the UI show a banner while a superadmin is acting as this principal.
+	Impersonation *SessionImpersonation `json:"impersonation,omitempty"`
 }
 
 func handleSessionCapabilitiesAPI(w http.ResponseWriter, r *http.Request, runtime authzRuntimeStore) {
@@ -36,5 +38,9 @@ func handleSessionCapabilitiesAPI(w http.ResponseWriter, r *http.Request, runtim
 		return
 	}
 
-	writeJSON(w, http.StatusOK, sessionCapabilitiesResponse{AuthzCapabilityKeys: keys})
+	resp := sessionCapabilitiesResponse{AuthzCapabilityKeys: keys}
+	if imp, ok := currentImpersonation(r.Context()); ok {
+		resp.Impersonation = &imp
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
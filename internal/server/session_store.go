@@ -29,6 +29,8 @@ type Session struct {
 	PrincipalID string
 	ExpiresAt   time.Time
 	RevokedAt   *time.Time
+	// Impersonation is set when the session was issued to a superadmin through an impersonation handoff.
+	Impersonation *SessionImpersonation
 }
 
 type sessionStore interface {
@@ -417,11 +419,16 @@ func (s *pgSessionStore) Lookup(ctx context.Context, sid string) (Session, bool,
 	var out Session
 	out.RevokedAt = nil
 	var revokedAt *time.Time
+	var imp SessionImpersonation
+	var impEndedAt *time.Time
 	err := s.q.QueryRow(ctx, `
-SELECT tenant_uuid::text, principal_id::text, expires_at, revoked_at
-FROM iam.sessions
-WHERE token_sha256 = $1;
-	`, sum[:]).Scan(&out.TenantID, &out.PrincipalID, &out.ExpiresAt, &revokedAt)
+SELECT s.tenant_uuid::text, s.principal_id::text, s.expires_at, s.revoked_at,
+  COALESCE(i.id::text, ''), COALESCE(i.superadmin_actor, ''), COALESCE(i.superadmin_email, ''),
+  COALESCE(i.read_only, true), i.ended_at
+FROM iam.sessions s
+LEFT JOIN iam.impersonation_sessions i ON i.id = s.impersonation_id
+WHERE s.token_sha256 = $1;
+	`, sum[:]).Scan(&out.TenantID, &out.PrincipalID, &out.ExpiresAt, &revokedAt, &imp.ID, &imp.Actor, &imp.ActorEmail, &imp.ReadOnly, &impEndedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return Session{}, false, nil
@@ -438,6 +445,13 @@ WHERE token_sha256 = $1;
 	if time.Now().After(out.ExpiresAt) {
 		return Session{}, false, nil
 	}
+	if imp.ID != "" {
+		if impEndedAt != nil {
+			return Session{}, false, nil
+		}
+		imp.ExpiresAt = out.ExpiresAt
+		out.Impersonation = &imp
+	}
 	return out, true, nil
 }
 
@@ -146,6 +146,30 @@ func TestWithTenantAndSession_HealthBypass(t *testing.T) {
 	}
 }
 
+func TestWithTenantAndSession_DictCacheStatsRequiresSession(t *testing.T) {
+	classifier, err := routing.NewClassifier(routing.Allowlist{
+		Version: 1,
+		Entrypoints: map[string]routing.Entrypoint{
+			"server": {Routes: []routing.Route{{Path: dictCacheStatsPath, Methods: []string{"GET"}, RouteClass: "ops"}}},
+		},
+	}, "server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tnt := Tenant{ID: "t1", Domain: "localhost", Name: "Local"}
+	h := withTenantAndSession(classifier, stubTenancyResolver{tenant: tnt, ok: true}, newMemoryPrincipalStore(), newMemorySessionStore(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Fatal("unexpected next")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, dictCacheStatsPath, nil)
+	req.Host = "localhost:8080"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status=%d", rec.Code)
+	}
+}
+
 func TestWithTenantAndSession_TenantNotFound(t *testing.T) {
 	h := withTenantAndSession(nil, stubTenancyResolver{ok: false}, newMemoryPrincipalStore(), newMemorySessionStore(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
 		t.Fatal("unexpected next")
@@ -0,0 +1,284 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+	iammodule "github.com/jacksonlee411/Bugs-And-Blossoms/modules/iam"
+)
+
+type WebhookStore = iammodule.WebhookStore
+type WebhookSubscription = iammodule.WebhookSubscription
+type WebhookDelivery = iammodule.WebhookDelivery
+
+const (
+	webhookDeliveriesDefaultLimit = 50
+	webhookDeliveriesMaxLimit     = 200
+)
+
+type webhookSubscriptionsResponse struct {
+	Subscriptions []WebhookSubscription `json:"subscriptions"`
+	EventTypes    []string              `json:"event_types"`
+}
+
+type webhookSubscriptionResponse struct {
+	Subscription WebhookSubscription `json:"subscription"`
+}
+
+type webhookDeliveriesResponse struct {
+	Limit      int               `json:"limit"`
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+type webhookReplayResponse struct {
+	Replayed int `json:"replayed"`
+}
+
+type webhookSubscriptionUpdatePayload struct {
+	EndpointURL  string   `json:"endpoint_url"`
+	EventTypes   []string `json:"event_types"`
+	Description  string   `json:"description"`
+	Active       *bool    `json:"active"`
+	RotateSecret bool     `json:"rotate_secret"`
+}
+
+func newWebhookStore(orgStore OrgUnitStore) WebhookStore {
+	if pgStore, ok := orgStore.(*orgUnitPGStore); ok {
+		store := iammodule.NewWebhookPGStore(pgStore.pool)
+		store.AllowInsecureEndpoints = webhookAllowInsecureEndpointsFromEnv()
+		return store
+	}
+	return iammodule.NewWebhookMemoryStore()
+}
+
+// startWebhookDispatcher runs the outbox delivery worker when the server owns a real pool. The memory store
+// has no trigger feeding it, so the dev server skips the worker.
+func startWebhookDispatcher(ctx context.Context, orgStore OrgUnitStore, store WebhookStore) {
+	queue, ok := store.(*iammodule.WebhookPGStore)
+	if !ok || dictChangeListenPool(orgStore) == nil {
+		return
+	}
+	dispatcher := iammodule.NewWebhookDispatcher(queue)
+	dispatcher.MaxAttempts = webhookMaxAttemptsFromEnv()
+	dispatcher.PollInterval = webhookPollIntervalFromEnv()
+	dispatcher.AllowInsecureEndpoints = queue.AllowInsecureEndpoints
+	go func() { _ = dispatcher.Run(ctx) }()
+}
+
+// webhookAllowInsecureEndpointsFromEnv lets a local stack register http://localhost receivers. Anywhere
+// else endpoints must be public https URLs, since deliveries are sent from inside the server network.
+func webhookAllowInsecureEndpointsFromEnv() bool {
+	return os.Getenv("WEBHOOK_UNSAFE_ALLOW_INSECURE_ENDPOINTS") == "1"
+}
+
+func webhookMaxAttemptsFromEnv() int {
+	const defaultMaxAttempts = 8
+
+	n, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS"))
+	if err != nil || n <= 0 {
+		return defaultMaxAttempts
+	}
+	return n
+}
+
+func webhookPollIntervalFromEnv() time.Duration {
+	const defaultSeconds = 2
+
+	n, err := strconv.Atoi(os.Getenv("WEBHOOK_POLL_INTERVAL_SECONDS"))
+	if err != nil || n <= 0 {
+		return time.Second * defaultSeconds
+	}
+	return time.Second * time.Duration(n)
+}
+
+func handleWebhookSubscriptionsAPI(w http.ResponseWriter, r *http.Request, store WebhookStore) {
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "tenant_missing", "tenant missing")
+		return
+	}
+	if store == nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "webhook_store_missing", "webhook store missing")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		items, err := store.ListWebhookSubscriptions(r.Context(), tenant.ID)
+		if err != nil {
+			writeWebhookAPIError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, webhookSubscriptionsResponse{Subscriptions: items, EventTypes: iammodule.WebhookEventTypes()})
+	case http.MethodPost:
+		var req iammodule.WebhookSubscriptionCreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_json", "bad json")
+			return
+		}
+		item, err := store.CreateWebhookSubscription(r.Context(), tenant.ID, req)
+		if err != nil {
+			writeWebhookAPIError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, webhookSubscriptionResponse{Subscription: item})
+	default:
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+	}
+}
+
+func handleWebhookSubscriptionAPI(w http.ResponseWriter, r *http.Request, store WebhookStore) {
+	if r.Method != http.MethodPut {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "tenant_missing", "tenant missing")
+		return
+	}
+	if store == nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "webhook_store_missing", "webhook store missing")
+		return
+	}
+	subscriptionID := webhookSubscriptionIDFromPath(r.URL.Path)
+	if !isWebhookUUID(subscriptionID) {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusNotFound, "webhook_subscription_not_found", "webhook subscription not found")
+		return
+	}
+	var payload webhookSubscriptionUpdatePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_json", "bad json")
+		return
+	}
+	if payload.Active == nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "active required")
+		return
+	}
+	item, err := store.UpdateWebhookSubscription(r.Context(), tenant.ID, iammodule.WebhookSubscriptionUpdateRequest{
+		ID:           subscriptionID,
+		EndpointURL:  payload.EndpointURL,
+		EventTypes:   payload.EventTypes,
+		Description:  payload.Description,
+		Active:       *payload.Active,
+		RotateSecret: payload.RotateSecret,
+	})
+	if err != nil {
+		writeWebhookAPIError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, webhookSubscriptionResponse{Subscription: item})
+}
+
+func handleWebhookDeliveriesAPI(w http.ResponseWriter, r *http.Request, store WebhookStore) {
+	if r.Method != http.MethodGet {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "tenant_missing", "tenant missing")
+		return
+	}
+	if store == nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "webhook_store_missing", "webhook store missing")
+		return
+	}
+	query := r.URL.Query()
+	filter := iammodule.WebhookDeliveryFilter{
+		Status:         strings.TrimSpace(query.Get("status")),
+		SubscriptionID: strings.TrimSpace(query.Get("subscription_id")),
+		Limit:          webhookDeliveriesDefaultLimit,
+	}
+	switch filter.Status {
+	case "", iammodule.WebhookDeliveryPending, iammodule.WebhookDeliveryDelivered, iammodule.WebhookDeliveryDead:
+	default:
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "status invalid")
+		return
+	}
+	if filter.SubscriptionID != "" && !isWebhookUUID(filter.SubscriptionID) {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "subscription_id invalid")
+		return
+	}
+	if raw := strings.TrimSpace(query.Get("limit")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "invalid_request", "limit invalid")
+			return
+		}
+		filter.Limit = min(n, webhookDeliveriesMaxLimit)
+	}
+
+	items, err := store.ListWebhookDeliveries(r.Context(), tenant.ID, filter)
+	if err != nil {
+		writeWebhookAPIError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, webhookDeliveriesResponse{Limit: filter.Limit, Deliveries: items})
+}
+
+func handleWebhookDeliveriesReplayAPI(w http.ResponseWriter, r *http.Request, store WebhookStore) {
+	if r.Method != http.MethodPost {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "tenant_missing", "tenant missing")
+		return
+	}
+	if store == nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "webhook_store_missing", "webhook store missing")
+		return
+	}
+	var req iammodule.WebhookReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_json", "bad json")
+		return
+	}
+	if req.SubscriptionID != "" && !isWebhookUUID(req.SubscriptionID) {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "webhook_replay_invalid", "webhook replay invalid")
+		return
+	}
+	replayed, err := store.ReplayWebhookDeliveries(r.Context(), tenant.ID, req)
+	if err != nil {
+		writeWebhookAPIError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, webhookReplayResponse{Replayed: replayed})
+}
+
+func webhookSubscriptionIDFromPath(path string) string {
+	const prefix = "/iam/api/webhooks/subscriptions/"
+	if !strings.HasPrefix(path, prefix) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(path, prefix))
+}
+
+func writeWebhookAPIError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, iammodule.ErrWebhookEndpointInvalid):
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "webhook_endpoint_invalid", "webhook endpoint invalid")
+	case errors.Is(err, iammodule.ErrWebhookEventTypesInvalid):
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "webhook_event_types_invalid", "webhook event types invalid")
+	case errors.Is(err, iammodule.ErrWebhookReplayInvalid):
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "webhook_replay_invalid", "webhook replay invalid")
+	case errors.Is(err, iammodule.ErrWebhookSubscriptionNotFound):
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusNotFound, "webhook_subscription_not_found", "webhook subscription not found")
+	default:
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "webhook_store_error", "webhook store error")
+	}
+}
+
+func isWebhookUUID(value string) bool {
+	_, err := uuid.Parse(value)
+	return err == nil
+}
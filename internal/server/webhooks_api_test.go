@@ -0,0 +1,253 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	iammodule "github.com/jacksonlee411/Bugs-And-Blossoms/modules/iam"
+)
+
+type webhookStoreErrStub struct {
+	WebhookStore
+	err error
+}
+
+func (s webhookStoreErrStub) ListWebhookSubscriptions(context.Context, string) ([]WebhookSubscription, error) {
+	return nil, s.err
+}
+
+func (s webhookStoreErrStub) ListWebhookDeliveries(context.Context, string, iammodule.WebhookDeliveryFilter) ([]WebhookDelivery, error) {
+	return nil, s.err
+}
+
+func webhookAPIRequest(method string, target string, body string, withTenantCtx bool) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	if withTenantCtx {
+		req = req.WithContext(withTenant(req.Context(), Tenant{ID: "00000000-0000-0000-0000-000000000001", Domain: "localhost", Name: "T1"}))
+	}
+	return req
+}
+
+func webhookAPIErrorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode error body=%s err=%v", rec.Body.String(), err)
+	}
+	return body.Code
+}
+
+func TestWebhookSubscriptionsAPI(t *testing.T) {
+	store := iammodule.NewWebhookMemoryStore()
+
+	rec := httptest.NewRecorder()
+	handleWebhookSubscriptionsAPI(rec, webhookAPIRequest(http.MethodPost, "/iam/api/webhooks/subscriptions", `{"endpoint_url":"https://payroll.example.com/hooks","event_types":["orgunit.moved","orgunit.created"]}`, true), store)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	var created webhookSubscriptionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Subscription.Secret == "" || len(created.Subscription.EventTypes) != 2 || created.Subscription.EventTypes[0] != "orgunit.created" {
+		t.Fatalf("created=%+v", created.Subscription)
+	}
+
+	rec = httptest.NewRecorder()
+	handleWebhookSubscriptionsAPI(rec, webhookAPIRequest(http.MethodGet, "/iam/api/webhooks/subscriptions", "", true), store)
+	var listed webhookSubscriptionsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || len(listed.Subscriptions) != 1 || listed.Subscriptions[0].Secret != "" || len(listed.EventTypes) == 0 {
+		t.Fatalf("list status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	path := "/iam/api/webhooks/subscriptions/" + created.Subscription.ID
+	rec = httptest.NewRecorder()
+	handleWebhookSubscriptionAPI(rec, webhookAPIRequest(http.MethodPut, path, `{"endpoint_url":"https://payroll.example.com/v2","event_types":["*"],"active":false,"rotate_secret":true}`, true), store)
+	var updated webhookSubscriptionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &updated); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || updated.Subscription.Active || updated.Subscription.Secret == "" || updated.Subscription.Secret == created.Subscription.Secret {
+		t.Fatalf("update status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	cases := []struct {
+		name   string
+		call   func(*httptest.ResponseRecorder)
+		status int
+		code   string
+	}{
+		{"tenant missing", func(rec *httptest.ResponseRecorder) {
+			handleWebhookSubscriptionsAPI(rec, webhookAPIRequest(http.MethodGet, "/iam/api/webhooks/subscriptions", "", false), store)
+		}, http.StatusInternalServerError, "tenant_missing"},
+		{"store missing", func(rec *httptest.ResponseRecorder) {
+			handleWebhookSubscriptionsAPI(rec, webhookAPIRequest(http.MethodGet, "/iam/api/webhooks/subscriptions", "", true), nil)
+		}, http.StatusInternalServerError, "webhook_store_missing"},
+		{"method", func(rec *httptest.ResponseRecorder) {
+			handleWebhookSubscriptionsAPI(rec, webhookAPIRequest(http.MethodDelete, "/iam/api/webhooks/subscriptions", "", true), store)
+		}, http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"bad json", func(rec *httptest.ResponseRecorder) {
+			handleWebhookSubscriptionsAPI(rec, webhookAPIRequest(http.MethodPost, "/iam/api/webhooks/subscriptions", "{", true), store)
+		}, http.StatusBadRequest, "bad_json"},
+		{"endpoint invalid", func(rec *httptest.ResponseRecorder) {
+			handleWebhookSubscriptionsAPI(rec, webhookAPIRequest(http.MethodPost, "/iam/api/webhooks/subscriptions", `{"endpoint_url":"ftp://x","event_types":["*"]}`, true), store)
+		}, http.StatusBadRequest, "webhook_endpoint_invalid"},
+		{"event types invalid", func(rec *httptest.ResponseRecorder) {
+			handleWebhookSubscriptionsAPI(rec, webhookAPIRequest(http.MethodPost, "/iam/api/webhooks/subscriptions", `{"endpoint_url":"https://x.example.com","event_types":["orgunit.unknown"]}`, true), store)
+		}, http.StatusBadRequest, "webhook_event_types_invalid"},
+		{"store error", func(rec *httptest.ResponseRecorder) {
+			handleWebhookSubscriptionsAPI(rec, webhookAPIRequest(http.MethodGet, "/iam/api/webhooks/subscriptions", "", true), webhookStoreErrStub{err: errors.New("boom")})
+		}, http.StatusInternalServerError, "webhook_store_error"},
+		{"update method", func(rec *httptest.ResponseRecorder) {
+			handleWebhookSubscriptionAPI(rec, webhookAPIRequest(http.MethodGet, path, "", true), store)
+		}, http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"update id invalid", func(rec *httptest.ResponseRecorder) {
+			handleWebhookSubscriptionAPI(rec, webhookAPIRequest(http.MethodPut, "/iam/api/webhooks/subscriptions/nope", `{"active":true}`, true), store)
+		}, http.StatusNotFound, "webhook_subscription_not_found"},
+		{"update not found", func(rec *httptest.ResponseRecorder) {
+			handleWebhookSubscriptionAPI(rec, webhookAPIRequest(http.MethodPut, "/iam/api/webhooks/subscriptions/00000000-0000-0000-0000-0000000000ff", `{"endpoint_url":"https://x.example.com","event_types":["*"],"active":true}`, true), store)
+		}, http.StatusNotFound, "webhook_subscription_not_found"},
+		{"update active required", func(rec *httptest.ResponseRecorder) {
+			handleWebhookSubscriptionAPI(rec, webhookAPIRequest(http.MethodPut, path, `{"endpoint_url":"https://x.example.com","event_types":["*"]}`, true), store)
+		}, http.StatusBadRequest, "invalid_request"},
+		{"update bad json", func(rec *httptest.ResponseRecorder) {
+			handleWebhookSubscriptionAPI(rec, webhookAPIRequest(http.MethodPut, path, "{", true), store)
+		}, http.StatusBadRequest, "bad_json"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tc.call(rec)
+			if rec.Code != tc.status || webhookAPIErrorCode(t, rec) != tc.code {
+				t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestWebhookDeliveriesAPI(t *testing.T) {
+	store := iammodule.NewWebhookMemoryStore()
+	tenantID := "00000000-0000-0000-0000-000000000001"
+	sub, err := store.CreateWebhookSubscription(context.Background(), tenantID, iammodule.WebhookSubscriptionCreateRequest{
+		EndpointURL: "https://payroll.example.com/hooks",
+		EventTypes:  []string{"orgunit.renamed"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Enqueue(tenantID, "00000000-0000-0000-0000-0000000000a1", "orgunit.renamed", json.RawMessage(`{"org_code":"A1"}`))
+	store.Enqueue(tenantID, "00000000-0000-0000-0000-0000000000a2", "orgunit.moved", json.RawMessage(`{"org_code":"A2"}`))
+
+	rec := httptest.NewRecorder()
+	handleWebhookDeliveriesAPI(rec, webhookAPIRequest(http.MethodGet, "/iam/api/webhooks/deliveries?status=pending&subscription_id="+sub.ID+"&limit=500", "", true), store)
+	var listed webhookDeliveriesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || listed.Limit != webhookDeliveriesMaxLimit || len(listed.Deliveries) != 1 || listed.Deliveries[0].EventType != "orgunit.renamed" {
+		t.Fatalf("list status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	deliveryID := strconv.FormatInt(listed.Deliveries[0].ID, 10)
+	rec = httptest.NewRecorder()
+	handleWebhookDeliveriesReplayAPI(rec, webhookAPIRequest(http.MethodPost, "/iam/api/webhooks/deliveries:replay", `{"delivery_ids":[`+deliveryID+`]}`, true), store)
+	if rec.Code != http.StatusOK || rec.Body.String() != "{\"replayed\":1}\n" {
+		t.Fatalf("replay status=%d body=%q", rec.Code, rec.Body.String())
+	}
+
+	cases := []struct {
+		name   string
+		call   func(*httptest.ResponseRecorder)
+		status int
+		code   string
+	}{
+		{"list method", func(rec *httptest.ResponseRecorder) {
+			handleWebhookDeliveriesAPI(rec, webhookAPIRequest(http.MethodPost, "/iam/api/webhooks/deliveries", "", true), store)
+		}, http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"list tenant missing", func(rec *httptest.ResponseRecorder) {
+			handleWebhookDeliveriesAPI(rec, webhookAPIRequest(http.MethodGet, "/iam/api/webhooks/deliveries", "", false), store)
+		}, http.StatusInternalServerError, "tenant_missing"},
+		{"list store missing", func(rec *httptest.ResponseRecorder) {
+			handleWebhookDeliveriesAPI(rec, webhookAPIRequest(http.MethodGet, "/iam/api/webhooks/deliveries", "", true), nil)
+		}, http.StatusInternalServerError, "webhook_store_missing"},
+		{"list status invalid", func(rec *httptest.ResponseRecorder) {
+			handleWebhookDeliveriesAPI(rec, webhookAPIRequest(http.MethodGet, "/iam/api/webhooks/deliveries?status=lost", "", true), store)
+		}, http.StatusBadRequest, "invalid_request"},
+		{"list subscription invalid", func(rec *httptest.ResponseRecorder) {
+			handleWebhookDeliveriesAPI(rec, webhookAPIRequest(http.MethodGet, "/iam/api/webhooks/deliveries?subscription_id=x", "", true), store)
+		}, http.StatusBadRequest, "invalid_request"},
+		{"list limit invalid", func(rec *httptest.ResponseRecorder) {
+			handleWebhookDeliveriesAPI(rec, webhookAPIRequest(http.MethodGet, "/iam/api/webhooks/deliveries?limit=0", "", true), store)
+		}, http.StatusBadRequest, "invalid_request"},
+		{"list store error", func(rec *httptest.ResponseRecorder) {
+			handleWebhookDeliveriesAPI(rec, webhookAPIRequest(http.MethodGet, "/iam/api/webhooks/deliveries", "", true), webhookStoreErrStub{err: errors.New("boom")})
+		}, http.StatusInternalServerError, "webhook_store_error"},
+		{"replay method", func(rec *httptest.ResponseRecorder) {
+			handleWebhookDeliveriesReplayAPI(rec, webhookAPIRequest(http.MethodGet, "/iam/api/webhooks/deliveries:replay", "", true), store)
+		}, http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"replay tenant missing", func(rec *httptest.ResponseRecorder) {
+			handleWebhookDeliveriesReplayAPI(rec, webhookAPIRequest(http.MethodPost, "/iam/api/webhooks/deliveries:replay", "{}", false), store)
+		}, http.StatusInternalServerError, "tenant_missing"},
+		{"replay store missing", func(rec *httptest.ResponseRecorder) {
+			handleWebhookDeliveriesReplayAPI(rec, webhookAPIRequest(http.MethodPost, "/iam/api/webhooks/deliveries:replay", "{}", true), nil)
+		}, http.StatusInternalServerError, "webhook_store_missing"},
+		{"replay bad json", func(rec *httptest.ResponseRecorder) {
+			handleWebhookDeliveriesReplayAPI(rec, webhookAPIRequest(http.MethodPost, "/iam/api/webhooks/deliveries:replay", "{", true), store)
+		}, http.StatusBadRequest, "bad_json"},
+		{"replay empty", func(rec *httptest.ResponseRecorder) {
+			handleWebhookDeliveriesReplayAPI(rec, webhookAPIRequest(http.MethodPost, "/iam/api/webhooks/deliveries:replay", "{}", true), store)
+		}, http.StatusBadRequest, "webhook_replay_invalid"},
+		{"replay subscription invalid", func(rec *httptest.ResponseRecorder) {
+			handleWebhookDeliveriesReplayAPI(rec, webhookAPIRequest(http.MethodPost, "/iam/api/webhooks/deliveries:replay", `{"subscription_id":"x","since":"2026-01-01T00:00:00Z"}`, true), store)
+		}, http.StatusBadRequest, "webhook_replay_invalid"},
+		{"replay subscription not found", func(rec *httptest.ResponseRecorder) {
+			handleWebhookDeliveriesReplayAPI(rec, webhookAPIRequest(http.MethodPost, "/iam/api/webhooks/deliveries:replay", `{"subscription_id":"00000000-0000-0000-0000-0000000000ff","since":"2026-01-01T00:00:00Z"}`, true), store)
+		}, http.StatusNotFound, "webhook_subscription_not_found"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tc.call(rec)
+			if rec.Code != tc.status || webhookAPIErrorCode(t, rec) != tc.code {
+				t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestWebhookSubscriptionIDFromPath(t *testing.T) {
+	if got := webhookSubscriptionIDFromPath("/iam/api/webhooks/subscriptions/abc"); got != "abc" {
+		t.Fatalf("got=%q", got)
+	}
+	if got := webhookSubscriptionIDFromPath("/iam/api/other/abc"); got != "" {
+		t.Fatalf("got=%q", got)
+	}
+}
+
+func TestWebhookEnvDefaults(t *testing.T) {
+	t.Setenv("WEBHOOK_MAX_ATTEMPTS", "")
+	t.Setenv("WEBHOOK_POLL_INTERVAL_SECONDS", "bad")
+	if webhookMaxAttemptsFromEnv() != 8 || webhookPollIntervalFromEnv().Seconds() != 2 {
+		t.Fatal("expected defaults")
+	}
+	t.Setenv("WEBHOOK_MAX_ATTEMPTS", "3")
+	t.Setenv("WEBHOOK_POLL_INTERVAL_SECONDS", "5")
+	if webhookMaxAttemptsFromEnv() != 3 || webhookPollIntervalFromEnv().Seconds() != 5 {
+		t.Fatal("expected env overrides")
+	}
+	if _, ok := newWebhookStore(newOrgUnitMemoryStore()).(*iammodule.WebhookMemoryStore); !ok {
+		t.Fatal("expected memory store")
+	}
+	startWebhookDispatcher(context.Background(), newOrgUnitMemoryStore(), iammodule.NewWebhookMemoryStore())
+}
@@ -1339,3009 +1339,6774 @@ $$;
 
 -- end: modules/iam/infrastructure/persistence/schema/00011_iam_authz_role_runtime.sql
 
--- begin: modules/orgunit/infrastructure/persistence/schema/00001_orgunit_schema.sql
-CREATE EXTENSION IF NOT EXISTS pgcrypto;
-CREATE EXTENSION IF NOT EXISTS ltree;
-CREATE EXTENSION IF NOT EXISTS btree_gist;
+-- begin: modules/iam/infrastructure/persistence/schema/00012_iam_dict_change_notify.sql
+CREATE OR REPLACE FUNCTION iam.notify_dict_changed()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  -- NOTIFY is transactional: listeners only see the change after the submitting transaction commits.
+  PERFORM pg_notify(
+    'iam_dict_changed',
+    jsonb_build_object('tenant_uuid', NEW.tenant_uuid, 'dict_code', NEW.dict_code)::text
+  );
+  RETURN NULL;
+END;
+$$;
 
-CREATE SCHEMA IF NOT EXISTS orgunit;
+DROP TRIGGER IF EXISTS dict_events_notify_changed ON iam.dict_events;
+CREATE TRIGGER dict_events_notify_changed
+AFTER INSERT ON iam.dict_events
+FOR EACH ROW
+EXECUTE FUNCTION iam.notify_dict_changed();
 
-CREATE OR REPLACE FUNCTION orgunit.global_tenant_id()
-RETURNS uuid
-LANGUAGE sql
-IMMUTABLE
-AS $$
-  SELECT '00000000-0000-0000-0000-000000000000'::uuid;
+DROP TRIGGER IF EXISTS dict_value_events_notify_changed ON iam.dict_value_events;
+CREATE TRIGGER dict_value_events_notify_changed
+AFTER INSERT ON iam.dict_value_events
+FOR EACH ROW
+EXECUTE FUNCTION iam.notify_dict_changed();
+
+-- end: modules/iam/infrastructure/persistence/schema/00012_iam_dict_change_notify.sql
+
+-- begin: modules/iam/infrastructure/persistence/schema/00013_iam_superadmin_tenant_provisioning.sql
+CREATE TABLE IF NOT EXISTS iam.superadmin_tenant_provisioning_runs (
+  id bigserial PRIMARY KEY,
+  request_id text NOT NULL,
+  tenant_uuid uuid NULL REFERENCES iam.tenants(id) ON DELETE SET NULL,
+  status text NOT NULL DEFAULT 'running',
+  input jsonb NOT NULL DEFAULT '{}'::jsonb,
+  steps jsonb NOT NULL DEFAULT '[]'::jsonb,
+  actor text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT superadmin_tenant_provisioning_runs_request_id_nonempty_check CHECK (btrim(request_id) <> ''),
+  CONSTRAINT superadmin_tenant_provisioning_runs_status_check CHECK (status IN ('running', 'succeeded', 'failed')),
+  CONSTRAINT superadmin_tenant_provisioning_runs_input_is_object_check CHECK (jsonb_typeof(input) = 'object'),
+  CONSTRAINT superadmin_tenant_provisioning_runs_steps_is_array_check CHECK (jsonb_typeof(steps) = 'array')
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS superadmin_tenant_provisioning_runs_request_id_unique ON iam.superadmin_tenant_provisioning_runs (request_id);
+CREATE INDEX IF NOT EXISTS superadmin_tenant_provisioning_runs_tenant_idx ON iam.superadmin_tenant_provisioning_runs (tenant_uuid, id);
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.superadmin_tenant_provisioning_runs TO superadmin_runtime';
+    EXECUTE 'GRANT USAGE, SELECT ON SEQUENCE iam.superadmin_tenant_provisioning_runs_id_seq TO superadmin_runtime';
+    EXECUTE 'GRANT SELECT ON iam.dict_events, iam.dict_value_events, iam.dicts, iam.dict_value_segments TO superadmin_runtime';
+    EXECUTE 'GRANT EXECUTE ON FUNCTION iam.seed_builtin_authz_roles(uuid) TO superadmin_runtime';
+  END IF;
+END
 $$;
 
-CREATE OR REPLACE FUNCTION orgunit.assert_current_tenant(p_tenant_uuid uuid)
-RETURNS void
+-- end: modules/iam/infrastructure/persistence/schema/00013_iam_superadmin_tenant_provisioning.sql
+
+-- begin: modules/iam/infrastructure/persistence/schema/00014_iam_superadmin_tenant_offboarding.sql
+-- Offboarding requests intentionally carry no FK to iam.tenants: the row (and its verification report)
+-- must outlive the tenant it describes.
+CREATE TABLE IF NOT EXISTS iam.superadmin_tenant_offboarding_requests (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL,
+  tenant_name text NOT NULL,
+  status text NOT NULL DEFAULT 'scheduled',
+  reason text NOT NULL DEFAULT '',
+  token_sha256 bytea NOT NULL,
+  purge_after timestamptz NOT NULL,
+  requested_by text NOT NULL,
+  closed_by text NULL,
+  closed_at timestamptz NULL,
+  verification jsonb NOT NULL DEFAULT '{}'::jsonb,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT superadmin_tenant_offboarding_requests_status_check CHECK (status IN ('scheduled', 'cancelled', 'deleted')),
+  CONSTRAINT superadmin_tenant_offboarding_requests_token_len_check CHECK (octet_length(token_sha256) = 32),
+  CONSTRAINT superadmin_tenant_offboarding_requests_verification_is_object_check CHECK (jsonb_typeof(verification) = 'object')
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS superadmin_tenant_offboarding_requests_scheduled_unique
+  ON iam.superadmin_tenant_offboarding_requests (tenant_uuid)
+  WHERE status = 'scheduled';
+CREATE INDEX IF NOT EXISTS superadmin_tenant_offboarding_requests_tenant_idx
+  ON iam.superadmin_tenant_offboarding_requests (tenant_uuid, id DESC);
+
+CREATE OR REPLACE FUNCTION iam.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
 LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
 AS $$
 DECLARE
-  v_ctx_raw text;
-  v_ctx_tenant uuid;
+  v_tables text[] := ARRAY[
+    'iam.principal_org_scope_bindings',
+    'iam.principal_authz_assignment_revisions',
+    'iam.principal_role_assignments',
+    'iam.role_definitions',
+    'iam.cubebox_model_selections',
+    'iam.cubebox_model_health_checks',
+    'iam.cubebox_model_credentials',
+    'iam.cubebox_model_providers',
+    'iam.cubebox_conversation_events',
+    'iam.cubebox_conversations',
+    'iam.dict_value_segments',
+    'iam.dict_value_events',
+    'iam.dict_events',
+    'iam.dicts',
+    'iam.sessions',
+    'iam.principals',
+    'iam.tenant_domains'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
 BEGIN
-  IF p_tenant_uuid IS NULL THEN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
     RAISE EXCEPTION USING
-      ERRCODE = 'P0001',
-      MESSAGE = 'ORG_INVALID_ARGUMENT',
-      DETAIL = 'tenant_uuid is required';
+      MESSAGE = 'IAM_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
   END IF;
-
-  v_ctx_raw := current_setting('app.current_tenant', true);
-  IF v_ctx_raw IS NULL OR btrim(v_ctx_raw) = '' THEN
+  IF EXISTS (SELECT 1 FROM iam.tenants WHERE id = p_tenant_uuid AND is_active) THEN
     RAISE EXCEPTION USING
-      ERRCODE = 'P0001',
-      MESSAGE = 'RLS_TENANT_CONTEXT_MISSING',
-      DETAIL = 'app.current_tenant is required';
+      MESSAGE = 'IAM_TENANT_PURGE_ACTIVE',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
   END IF;
 
-  BEGIN
-    v_ctx_tenant := v_ctx_raw::uuid;
-  EXCEPTION
-    WHEN invalid_text_representation THEN
-      RAISE EXCEPTION USING
-        ERRCODE = 'P0001',
-        MESSAGE = 'RLS_TENANT_CONTEXT_INVALID',
-        DETAIL = format('app.current_tenant=%s', v_ctx_raw);
-  END;
-
-  IF v_ctx_tenant <> p_tenant_uuid THEN
-    RAISE EXCEPTION USING
-      ERRCODE = 'P0001',
-      MESSAGE = 'RLS_TENANT_CONTEXT_MISMATCH',
-      DETAIL = format('ctx=%s arg=%s', v_ctx_tenant, p_tenant_uuid);
-  END IF;
-END;
-$$;
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
 
--- end: modules/orgunit/infrastructure/persistence/schema/00001_orgunit_schema.sql
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (SELECT id FROM iam.role_definitions WHERE tenant_uuid = p_tenant_uuid);
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.role_authz_capabilities', v_count);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
 
--- begin: modules/orgunit/infrastructure/persistence/schema/00023_orgunit_org_node_key_schema.sql
-DROP VIEW IF EXISTS orgunit.org_events_effective CASCADE;
+  DELETE FROM iam.tenants WHERE id = p_tenant_uuid;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.tenants', v_count);
 
-DROP TABLE IF EXISTS orgunit.org_id_allocators CASCADE;
-DROP TABLE IF EXISTS orgunit.org_unit_codes CASCADE;
-DROP TABLE IF EXISTS orgunit.org_unit_versions CASCADE;
-DROP TABLE IF EXISTS orgunit.org_events CASCADE;
-DROP TABLE IF EXISTS orgunit.org_trees CASCADE;
-DROP TABLE IF EXISTS orgunit.org_node_key_registry CASCADE;
-DROP SEQUENCE IF EXISTS orgunit.org_node_key_seq;
+  RETURN v_counts;
+END;
+$$;
 
-DROP FUNCTION IF EXISTS orgunit.org_path_ids(ltree);
-DROP FUNCTION IF EXISTS orgunit.org_ltree_label(int);
-DROP FUNCTION IF EXISTS orgunit.allocate_org_id(uuid);
+REVOKE ALL ON FUNCTION iam.purge_tenant_data(uuid) FROM PUBLIC;
 
-CREATE OR REPLACE FUNCTION orgunit.is_valid_org_node_key(p_value text)
-RETURNS boolean
-LANGUAGE sql
-IMMUTABLE
-AS $$
-  SELECT btrim(COALESCE(p_value, '')) ~ '^[ABCDEFGHJKLMNPQRSTUVWXYZ][ABCDEFGHJKLMNPQRSTUVWXYZ23456789]{7}$';
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.superadmin_tenant_offboarding_requests TO superadmin_runtime';
+    EXECUTE 'GRANT USAGE, SELECT ON SEQUENCE iam.superadmin_tenant_offboarding_requests_id_seq TO superadmin_runtime';
+    EXECUTE 'GRANT SELECT ON ' ||
+      'iam.principals, iam.sessions, ' ||
+      'iam.role_definitions, iam.role_authz_capabilities, iam.principal_role_assignments, ' ||
+      'iam.principal_authz_assignment_revisions, iam.principal_org_scope_bindings, ' ||
+      'iam.cubebox_conversations, iam.cubebox_conversation_events, ' ||
+      'iam.cubebox_model_providers, iam.cubebox_model_credentials, ' ||
+      'iam.cubebox_model_selections, iam.cubebox_model_health_checks ' ||
+      'TO superadmin_runtime';
+    EXECUTE 'GRANT EXECUTE ON FUNCTION iam.purge_tenant_data(uuid) TO superadmin_runtime';
+  END IF;
+END
 $$;
 
-CREATE OR REPLACE FUNCTION orgunit.org_ltree_label(p_org_node_key text)
-RETURNS text
-LANGUAGE sql
-IMMUTABLE
-AS $$
-  SELECT btrim(p_org_node_key);
-$$;
+-- end: modules/iam/infrastructure/persistence/schema/00014_iam_superadmin_tenant_offboarding.sql
 
-CREATE OR REPLACE FUNCTION orgunit.org_path_node_keys(p_path ltree)
-RETURNS text[]
-LANGUAGE sql
-IMMUTABLE
-AS $$
-  SELECT array_agg(t.part ORDER BY t.ord)
-  FROM unnest(string_to_array(p_path::text, '.')) WITH ORDINALITY AS t(part, ord);
-$$;
+-- begin: modules/iam/infrastructure/persistence/schema/00015_iam_superadmin_impersonation.sql
+-- Impersonation sessions are issued by superadmins and redeemed once on the tenant host. They follow
+-- iam.sessions (no RLS, always filtered by tenant_uuid) and are removed with the principal they target.
+CREATE TABLE IF NOT EXISTS iam.impersonation_sessions (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  principal_id uuid NOT NULL REFERENCES iam.principals(id) ON DELETE CASCADE,
+  superadmin_actor text NOT NULL,
+  superadmin_email text NOT NULL DEFAULT '',
+  reason text NOT NULL,
+  read_only boolean NOT NULL DEFAULT true,
+  handoff_token_sha256 bytea NOT NULL,
+  handoff_expires_at timestamptz NOT NULL,
+  accepted_at timestamptz NULL,
+  expires_at timestamptz NOT NULL,
+  ended_at timestamptz NULL,
+  ended_by text NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT impersonation_sessions_actor_nonempty_check CHECK (btrim(superadmin_actor) <> ''),
+  CONSTRAINT impersonation_sessions_reason_nonempty_check CHECK (btrim(reason) <> ''),
+  CONSTRAINT impersonation_sessions_handoff_token_len_check CHECK (octet_length(handoff_token_sha256) = 32),
+  CONSTRAINT impersonation_sessions_handoff_before_expiry_check CHECK (handoff_expires_at <= expires_at)
+);
 
-CREATE OR REPLACE FUNCTION orgunit.is_org_event_snapshot_presence_valid(
-  p_event_type text,
-  p_before_snapshot jsonb,
-  p_after_snapshot jsonb,
-  p_rescind_outcome text
-)
-RETURNS boolean
-LANGUAGE sql
-IMMUTABLE
-AS $$
-  SELECT CASE
-    WHEN p_event_type = 'CREATE'
-      THEN p_after_snapshot IS NOT NULL
+CREATE UNIQUE INDEX IF NOT EXISTS impersonation_sessions_handoff_token_unique ON iam.impersonation_sessions (handoff_token_sha256);
+CREATE INDEX IF NOT EXISTS impersonation_sessions_tenant_idx ON iam.impersonation_sessions (tenant_uuid, created_at DESC);
 
-    WHEN p_event_type IN ('UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT','CORRECT_EVENT','CORRECT_STATUS')
-      THEN p_before_snapshot IS NOT NULL AND p_after_snapshot IS NOT NULL
+CREATE TABLE IF NOT EXISTS iam.impersonation_request_logs (
+  id bigserial PRIMARY KEY,
+  impersonation_id uuid NOT NULL REFERENCES iam.impersonation_sessions(id) ON DELETE CASCADE,
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  superadmin_actor text NOT NULL,
+  method text NOT NULL,
+  path text NOT NULL,
+  request_id text NOT NULL DEFAULT '',
+  status_code integer NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  completed_at timestamptz NULL,
+  CONSTRAINT impersonation_request_logs_method_nonempty_check CHECK (btrim(method) <> ''),
+  CONSTRAINT impersonation_request_logs_path_nonempty_check CHECK (btrim(path) <> '')
+);
 
-    WHEN p_event_type IN ('RESCIND_EVENT','RESCIND_ORG')
-      THEN p_before_snapshot IS NOT NULL
-           AND (
-             (p_rescind_outcome = 'ABSENT' AND p_after_snapshot IS NULL)
-             OR (p_rescind_outcome = 'PRESENT' AND p_after_snapshot IS NOT NULL)
-           )
+CREATE INDEX IF NOT EXISTS impersonation_request_logs_session_idx ON iam.impersonation_request_logs (impersonation_id, id);
+CREATE INDEX IF NOT EXISTS impersonation_request_logs_tenant_idx ON iam.impersonation_request_logs (tenant_uuid, id);
 
-    ELSE true
-  END;
-$$;
+ALTER TABLE iam.sessions
+  ADD COLUMN IF NOT EXISTS impersonation_id uuid NULL REFERENCES iam.impersonation_sessions(id) ON DELETE CASCADE;
 
-CREATE OR REPLACE FUNCTION orgunit.is_orgunit_snapshot_complete(p_snapshot jsonb)
-RETURNS boolean
-LANGUAGE sql
-IMMUTABLE
-AS $$
-  SELECT p_snapshot IS NOT NULL
-    AND jsonb_typeof(p_snapshot) = 'object'
-    AND p_snapshot ?& ARRAY[
-      'org_node_key',
-      'name',
-      'status',
-      'parent_org_node_key',
-      'node_path',
-      'validity',
-      'full_name_path',
-      'is_business_unit'
-    ];
-$$;
+CREATE INDEX IF NOT EXISTS sessions_impersonation_idx ON iam.sessions (impersonation_id) WHERE impersonation_id IS NOT NULL;
 
-CREATE OR REPLACE FUNCTION orgunit.is_org_event_snapshot_content_valid(
-  p_event_type text,
-  p_before_snapshot jsonb,
-  p_after_snapshot jsonb,
-  p_rescind_outcome text
-)
-RETURNS boolean
-LANGUAGE sql
-IMMUTABLE
-AS $$
-  SELECT CASE
-    WHEN p_event_type IN ('RESCIND_EVENT','RESCIND_ORG')
-      THEN orgunit.is_orgunit_snapshot_complete(p_before_snapshot)
-           AND (
-             p_rescind_outcome = 'ABSENT'
-             OR (
-               p_rescind_outcome = 'PRESENT'
-               AND orgunit.is_orgunit_snapshot_complete(p_after_snapshot)
-             )
-           )
-    ELSE true
-  END;
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.impersonation_sessions TO superadmin_runtime';
+    EXECUTE 'GRANT SELECT ON iam.impersonation_request_logs TO superadmin_runtime';
+    EXECUTE 'GRANT UPDATE (revoked_at) ON iam.sessions TO superadmin_runtime';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_runtime') THEN
+    EXECUTE 'GRANT SELECT, UPDATE ON iam.impersonation_sessions TO app_runtime';
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.impersonation_request_logs TO app_runtime';
+    EXECUTE 'GRANT USAGE, SELECT ON SEQUENCE iam.impersonation_request_logs_id_seq TO app_runtime';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_nobypassrls') THEN
+    EXECUTE 'GRANT SELECT, UPDATE ON iam.impersonation_sessions TO app_nobypassrls';
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.impersonation_request_logs TO app_nobypassrls';
+    EXECUTE 'GRANT USAGE, SELECT ON SEQUENCE iam.impersonation_request_logs_id_seq TO app_nobypassrls';
+  END IF;
+END
 $$;
 
-CREATE SEQUENCE IF NOT EXISTS orgunit.org_node_key_seq
-  AS bigint
-  START WITH 1
-  MINVALUE 1
-  NO MAXVALUE
-  CACHE 1;
+-- end: modules/iam/infrastructure/persistence/schema/00015_iam_superadmin_impersonation.sql
 
-CREATE TABLE IF NOT EXISTS orgunit.org_node_key_registry (
-  org_node_key char(8) PRIMARY KEY,
-  seq bigint NOT NULL UNIQUE,
-  tenant_uuid uuid NOT NULL,
-  created_at timestamptz NOT NULL DEFAULT now(),
-  CONSTRAINT org_node_key_registry_key_format_check CHECK (
-    orgunit.is_valid_org_node_key(btrim(org_node_key::text))
-  )
-);
+-- begin: modules/iam/infrastructure/persistence/schema/00016_iam_superadmin_audit_query.sql
+-- Keyset indexes for the superadmin audit log API: filters by actor, action or time range page by id.
+CREATE INDEX IF NOT EXISTS superadmin_audit_logs_actor_idx ON iam.superadmin_audit_logs (actor, id);
+CREATE INDEX IF NOT EXISTS superadmin_audit_logs_action_idx ON iam.superadmin_audit_logs (action text_pattern_ops, id);
+CREATE INDEX IF NOT EXISTS superadmin_audit_logs_created_at_idx ON iam.superadmin_audit_logs (created_at, id);
 
-CREATE TABLE IF NOT EXISTS orgunit.org_trees (
-  tenant_uuid uuid NOT NULL,
-  root_org_node_key char(8) NOT NULL,
+-- end: modules/iam/infrastructure/persistence/schema/00016_iam_superadmin_audit_query.sql
+
+-- begin: modules/iam/infrastructure/persistence/schema/00017_iam_webhook_outbox.sql
+-- Webhook outbox: change events are written by triggers in the same transaction as the business event, then
+-- delivered to tenant-registered endpoints by the server's delivery worker. The worker spans tenants, so the
+-- tables follow iam.sessions (no RLS, always filtered by tenant_uuid) and rows are only written through
+-- iam.enqueue_webhook_event() or the tenant-scoped webhook APIs.
+CREATE TABLE IF NOT EXISTS iam.webhook_subscriptions (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  endpoint_url text NOT NULL,
+  event_types text[] NOT NULL,
+  secret text NOT NULL,
+  description text NOT NULL DEFAULT '',
+  active boolean NOT NULL DEFAULT true,
   created_at timestamptz NOT NULL DEFAULT now(),
   updated_at timestamptz NOT NULL DEFAULT now(),
-  PRIMARY KEY (tenant_uuid),
-  CONSTRAINT org_trees_root_org_node_key_format_check CHECK (
-    orgunit.is_valid_org_node_key(btrim(root_org_node_key::text))
-  )
+  CONSTRAINT webhook_subscriptions_endpoint_url_check CHECK (endpoint_url ~ '^https?://'),
+  CONSTRAINT webhook_subscriptions_event_types_nonempty_check CHECK (cardinality(event_types) > 0),
+  CONSTRAINT webhook_subscriptions_secret_len_check CHECK (char_length(secret) >= 16)
 );
 
-CREATE TABLE IF NOT EXISTS orgunit.org_events (
+CREATE INDEX IF NOT EXISTS webhook_subscriptions_tenant_idx ON iam.webhook_subscriptions (tenant_uuid, created_at, id);
+
+CREATE TABLE IF NOT EXISTS iam.webhook_outbox (
   id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  source text NOT NULL,
   event_uuid uuid NOT NULL,
-  tenant_uuid uuid NOT NULL,
-  org_node_key char(8) NOT NULL,
   event_type text NOT NULL,
-  effective_date date NOT NULL,
   payload jsonb NOT NULL DEFAULT '{}'::jsonb,
-  request_id text NOT NULL,
-  initiator_uuid uuid NOT NULL,
-  initiator_name text NULL,
-  initiator_employee_id text NULL,
-  reason text NULL,
-  before_snapshot jsonb NULL,
-  after_snapshot jsonb NULL,
-  rescind_outcome text NULL,
-  tx_time timestamptz NOT NULL DEFAULT now(),
-  transaction_time timestamptz NOT NULL DEFAULT now(),
-  created_at timestamptz NOT NULL DEFAULT now(),
-  CONSTRAINT org_events_org_node_key_format_check CHECK (
-    orgunit.is_valid_org_node_key(btrim(org_node_key::text))
-  ),
-  CONSTRAINT org_events_event_type_check CHECK (event_type IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT','CORRECT_EVENT','CORRECT_STATUS','RESCIND_EVENT','RESCIND_ORG')),
-  CONSTRAINT org_events_target_event_uuid_required CHECK (
-    event_type NOT IN ('CORRECT_EVENT','CORRECT_STATUS','RESCIND_EVENT','RESCIND_ORG')
-    OR (
-      payload ? 'target_event_uuid'
-      AND NULLIF(btrim(payload->>'target_event_uuid'), '') IS NOT NULL
-    )
-  ),
-  CONSTRAINT org_events_rescind_payload_required CHECK (
-    event_type NOT IN ('RESCIND_EVENT','RESCIND_ORG')
-    OR (
-      COALESCE(NULLIF(btrim(payload->>'op'), ''), '') = event_type
-      AND COALESCE(NULLIF(btrim(payload->>'reason'), ''), '') <> ''
-      AND COALESCE(NULLIF(btrim(payload->>'target_event_uuid'), ''), '') <> ''
-      AND COALESCE(NULLIF(btrim(payload->>'target_effective_date'), ''), '') = to_char(effective_date, 'YYYY-MM-DD')
-    )
-  ),
-  CONSTRAINT org_events_snapshot_shape_check CHECK (
-    (before_snapshot IS NULL OR jsonb_typeof(before_snapshot) = 'object')
-    AND (after_snapshot IS NULL OR jsonb_typeof(after_snapshot) = 'object')
-  ),
-  CONSTRAINT org_events_rescind_outcome_check CHECK (
-    (
-      event_type NOT IN ('RESCIND_EVENT','RESCIND_ORG')
-      AND rescind_outcome IS NULL
-    )
-    OR (
-      event_type IN ('RESCIND_EVENT','RESCIND_ORG')
-      AND rescind_outcome IN ('PRESENT','ABSENT')
-    )
-  ),
-  CONSTRAINT org_events_snapshot_presence_check CHECK (
-    orgunit.is_org_event_snapshot_presence_valid(
-      event_type,
-      before_snapshot,
-      after_snapshot,
-      rescind_outcome
-    )
-  ),
-  CONSTRAINT org_events_snapshot_content_check CHECK (
-    orgunit.is_org_event_snapshot_content_valid(
-      event_type,
-      before_snapshot,
-      after_snapshot,
-      rescind_outcome
-    )
-  ),
-  CONSTRAINT org_events_request_id_unique UNIQUE (tenant_uuid, request_id)
-);
-
-CREATE UNIQUE INDEX IF NOT EXISTS org_events_event_uuid_unique ON orgunit.org_events (event_uuid);
-CREATE INDEX IF NOT EXISTS org_events_tenant_org_effective_idx ON orgunit.org_events (tenant_uuid, org_node_key, effective_date, id);
-CREATE INDEX IF NOT EXISTS org_events_tenant_effective_idx ON orgunit.org_events (tenant_uuid, effective_date, id);
-CREATE INDEX IF NOT EXISTS org_events_tenant_tx_time_idx ON orgunit.org_events (tenant_uuid, tx_time DESC, id DESC);
-
-CREATE TABLE IF NOT EXISTS orgunit.org_unit_versions (
-  id bigserial PRIMARY KEY,
-  tenant_uuid uuid NOT NULL,
-  org_node_key char(8) NOT NULL,
-  parent_org_node_key char(8) NULL,
-  node_path ltree NOT NULL,
-  validity daterange NOT NULL,
-  path_node_keys text[] GENERATED ALWAYS AS (orgunit.org_path_node_keys(node_path)) STORED,
-  name varchar(255) NOT NULL,
-  full_name_path text NOT NULL,
-  status text NOT NULL DEFAULT 'active',
-  is_business_unit boolean NOT NULL DEFAULT false,
-  manager_uuid uuid NULL,
-  last_event_id bigint NOT NULL,
-  CONSTRAINT org_unit_versions_org_node_key_format_check CHECK (
-    orgunit.is_valid_org_node_key(btrim(org_node_key::text))
-  ),
-  CONSTRAINT org_unit_versions_parent_org_node_key_format_check CHECK (
-    parent_org_node_key IS NULL
-    OR orgunit.is_valid_org_node_key(btrim(parent_org_node_key::text))
-  ),
-  CONSTRAINT org_unit_versions_last_event_id_fkey
-    FOREIGN KEY (last_event_id)
-    REFERENCES orgunit.org_events(id)
-    DEFERRABLE INITIALLY DEFERRED,
-  CONSTRAINT org_unit_versions_status_check CHECK (status IN ('active','disabled')),
-  CONSTRAINT org_unit_versions_validity_check CHECK (NOT isempty(validity)),
-  CONSTRAINT org_unit_versions_validity_bounds_check CHECK (lower_inc(validity) AND NOT upper_inc(validity)),
-  CONSTRAINT org_unit_versions_no_overlap
-    EXCLUDE USING gist (
-      tenant_uuid gist_uuid_ops WITH =,
-      org_node_key gist_bpchar_ops WITH =,
-      validity WITH &&
-    )
-);
-
-CREATE INDEX IF NOT EXISTS org_unit_versions_search_gist
-  ON orgunit.org_unit_versions
-  USING gist (tenant_uuid gist_uuid_ops, node_path, validity);
-
-CREATE INDEX IF NOT EXISTS org_unit_versions_active_day_gist
-  ON orgunit.org_unit_versions
-  USING gist (tenant_uuid gist_uuid_ops, validity)
-  WHERE status = 'active';
-
-CREATE INDEX IF NOT EXISTS org_unit_versions_lookup_btree
-  ON orgunit.org_unit_versions (tenant_uuid, org_node_key, lower(validity));
-
-CREATE INDEX IF NOT EXISTS org_unit_versions_path_node_keys_gin
-  ON orgunit.org_unit_versions
-  USING gin (path_node_keys);
-
-CREATE TABLE IF NOT EXISTS orgunit.org_unit_codes (
-  tenant_uuid uuid NOT NULL,
-  org_node_key char(8) NOT NULL,
-  org_code text NOT NULL,
+  occurred_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT webhook_outbox_source_check CHECK (source IN ('orgunit','dict')),
+  CONSTRAINT webhook_outbox_event_type_nonempty_check CHECK (btrim(event_type) <> ''),
+  CONSTRAINT webhook_outbox_payload_is_object_check CHECK (jsonb_typeof(payload) = 'object')
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS webhook_outbox_event_unique ON iam.webhook_outbox (source, event_uuid);
+CREATE INDEX IF NOT EXISTS webhook_outbox_tenant_idx ON iam.webhook_outbox (tenant_uuid, occurred_at, id);
+
+CREATE TABLE IF NOT EXISTS iam.webhook_deliveries (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  subscription_id uuid NOT NULL REFERENCES iam.webhook_subscriptions(id) ON DELETE CASCADE,
+  outbox_id bigint NOT NULL REFERENCES iam.webhook_outbox(id) ON DELETE CASCADE,
+  status text NOT NULL DEFAULT 'pending',
+  attempts integer NOT NULL DEFAULT 0,
+  next_attempt_at timestamptz NOT NULL DEFAULT now(),
+  last_status_code integer NULL,
+  last_error text NOT NULL DEFAULT '',
   created_at timestamptz NOT NULL DEFAULT now(),
   updated_at timestamptz NOT NULL DEFAULT now(),
-  PRIMARY KEY (tenant_uuid, org_node_key),
-  CONSTRAINT org_unit_codes_org_node_key_format_check CHECK (
-    orgunit.is_valid_org_node_key(btrim(org_node_key::text))
-  ),
-  CONSTRAINT org_unit_codes_org_code_format CHECK (
-    length(org_code) BETWEEN 1 AND 64
-    AND org_code = upper(org_code)
-    AND org_code ~ E'^[\t\x20-\x7E\u3000-\u303F\uFF01-\uFF60\uFFE0-\uFFEE]{1,64}$'
-    AND org_code !~ E'^[\t\x20\u3000]+$'
-  ),
-  CONSTRAINT org_unit_codes_org_code_unique UNIQUE (tenant_uuid, org_code)
+  delivered_at timestamptz NULL,
+  dead_at timestamptz NULL,
+  CONSTRAINT webhook_deliveries_status_check CHECK (status IN ('pending','delivered','dead')),
+  CONSTRAINT webhook_deliveries_attempts_check CHECK (attempts >= 0),
+  CONSTRAINT webhook_deliveries_subscription_outbox_unique UNIQUE (subscription_id, outbox_id)
 );
 
-ALTER TABLE orgunit.org_node_key_registry ENABLE ROW LEVEL SECURITY;
-ALTER TABLE orgunit.org_node_key_registry FORCE ROW LEVEL SECURITY;
-DROP POLICY IF EXISTS tenant_isolation ON orgunit.org_node_key_registry;
-CREATE POLICY tenant_isolation ON orgunit.org_node_key_registry
-USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
-WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
-
-ALTER TABLE orgunit.org_trees ENABLE ROW LEVEL SECURITY;
-ALTER TABLE orgunit.org_trees FORCE ROW LEVEL SECURITY;
-DROP POLICY IF EXISTS tenant_isolation ON orgunit.org_trees;
-CREATE POLICY tenant_isolation ON orgunit.org_trees
-USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
-WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
-
-ALTER TABLE orgunit.org_events ENABLE ROW LEVEL SECURITY;
-ALTER TABLE orgunit.org_events FORCE ROW LEVEL SECURITY;
-DROP POLICY IF EXISTS tenant_isolation ON orgunit.org_events;
-CREATE POLICY tenant_isolation ON orgunit.org_events
-USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
-WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
-
-ALTER TABLE orgunit.org_unit_versions ENABLE ROW LEVEL SECURITY;
-ALTER TABLE orgunit.org_unit_versions FORCE ROW LEVEL SECURITY;
-DROP POLICY IF EXISTS tenant_isolation ON orgunit.org_unit_versions;
-CREATE POLICY tenant_isolation ON orgunit.org_unit_versions
-USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
-WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
-
-ALTER TABLE orgunit.org_unit_codes ENABLE ROW LEVEL SECURITY;
-ALTER TABLE orgunit.org_unit_codes FORCE ROW LEVEL SECURITY;
-DROP POLICY IF EXISTS tenant_isolation ON orgunit.org_unit_codes;
-CREATE POLICY tenant_isolation ON orgunit.org_unit_codes
-USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
-WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
-
--- end: modules/orgunit/infrastructure/persistence/schema/00023_orgunit_org_node_key_schema.sql
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON iam.webhook_deliveries (next_attempt_at, id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_tenant_idx ON iam.webhook_deliveries (tenant_uuid, status, id DESC);
 
--- begin: modules/orgunit/infrastructure/persistence/schema/00024_orgunit_org_node_key_allocator.sql
-CREATE OR REPLACE FUNCTION orgunit.encode_org_node_key(p_seq bigint)
-RETURNS char(8)
+-- enqueue_webhook_event records one change event and fans it out to the tenant's active subscriptions.
+-- It is idempotent per (source, event_uuid); the delivery worker polls for due rows.
+CREATE OR REPLACE FUNCTION iam.enqueue_webhook_event(
+  p_tenant_uuid uuid,
+  p_source text,
+  p_event_uuid uuid,
+  p_event_type text,
+  p_payload jsonb
+)
+RETURNS bigint
 LANGUAGE plpgsql
-IMMUTABLE
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
 AS $$
 DECLARE
-  v_guard_alphabet constant text := 'ABCDEFGHJKLMNPQRSTUVWXYZ';
-  v_body_alphabet constant text := 'ABCDEFGHJKLMNPQRSTUVWXYZ23456789';
-  v_body_base constant bigint := 32;
-  v_body_width constant integer := 7;
-  v_capacity constant bigint := 34359738368;
-  v_guard_index integer;
-  v_body_value bigint;
-  v_body text := '';
-  v_digit_index integer;
+  v_outbox_id bigint;
 BEGIN
-  IF p_seq IS NULL OR p_seq <= 0 THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_INVALID_ARGUMENT',
-      DETAIL = 'seq must be positive';
-  END IF;
+  INSERT INTO iam.webhook_outbox (tenant_uuid, source, event_uuid, event_type, payload)
+  VALUES (p_tenant_uuid, p_source, p_event_uuid, p_event_type, COALESCE(p_payload, '{}'::jsonb))
+  ON CONFLICT (source, event_uuid) DO NOTHING
+  RETURNING id INTO v_outbox_id;
 
-  IF p_seq > 824633720831 THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_NODE_KEY_EXHAUSTED',
-      DETAIL = format('seq=%s', p_seq);
+  IF v_outbox_id IS NULL THEN
+    RETURN NULL;
   END IF;
 
-  v_guard_index := floor(p_seq::numeric / v_capacity)::integer % length(v_guard_alphabet);
-  v_body_value := mod(p_seq, v_capacity);
-
-  FOR i IN REVERSE v_body_width..1 LOOP
-    v_digit_index := mod(v_body_value, v_body_base)::integer;
-    v_body := substr(v_body_alphabet, v_digit_index + 1, 1) || v_body;
-    v_body_value := floor(v_body_value::numeric / v_body_base)::bigint;
-  END LOOP;
+  INSERT INTO iam.webhook_deliveries (tenant_uuid, subscription_id, outbox_id)
+  SELECT s.tenant_uuid, s.id, v_outbox_id
+  FROM iam.webhook_subscriptions s
+  WHERE s.tenant_uuid = p_tenant_uuid
+    AND s.active
+    AND (p_event_type = ANY (s.event_types) OR '*' = ANY (s.event_types));
 
-  RETURN (substr(v_guard_alphabet, v_guard_index + 1, 1) || v_body)::char(8);
+  RETURN v_outbox_id;
 END;
 $$;
 
-CREATE OR REPLACE FUNCTION orgunit.decode_org_node_key(p_org_node_key char(8))
-RETURNS bigint
+REVOKE ALL ON FUNCTION iam.enqueue_webhook_event(uuid, text, uuid, text, jsonb) FROM PUBLIC;
+
+CREATE OR REPLACE FUNCTION iam.enqueue_dict_value_webhook_event()
+RETURNS trigger
 LANGUAGE plpgsql
-IMMUTABLE
 AS $$
 DECLARE
-  v_key text := btrim(COALESCE(p_org_node_key::text, ''));
-  v_guard_alphabet constant text := 'ABCDEFGHJKLMNPQRSTUVWXYZ';
-  v_body_alphabet constant text := 'ABCDEFGHJKLMNPQRSTUVWXYZ23456789';
-  v_body_base constant bigint := 32;
-  v_capacity constant bigint := 34359738368;
-  v_guard_index integer;
-  v_digit_index integer;
-  v_body_value bigint := 0;
+  v_event_type text;
 BEGIN
-  IF NOT orgunit.is_valid_org_node_key(v_key) THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_INVALID_ARGUMENT',
-      DETAIL = format('org_node_key invalid: %s', COALESCE(v_key, '<null>'));
-  END IF;
-
-  v_guard_index := strpos(v_guard_alphabet, substr(v_key, 1, 1)) - 1;
-  IF v_guard_index < 0 THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_INVALID_ARGUMENT',
-      DETAIL = format('org_node_key invalid: %s', v_key);
-  END IF;
-
-  FOR i IN 2..length(v_key) LOOP
-    v_digit_index := strpos(v_body_alphabet, substr(v_key, i, 1)) - 1;
-    IF v_digit_index < 0 THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_INVALID_ARGUMENT',
-        DETAIL = format('org_node_key invalid: %s', v_key);
-    END IF;
-    v_body_value := v_body_value * v_body_base + v_digit_index;
-  END LOOP;
+  v_event_type := CASE NEW.event_type
+    WHEN 'DICT_VALUE_CREATED' THEN 'dict.value_created'
+    WHEN 'LABEL_CORRECTED' THEN 'dict.value_corrected'
+    WHEN 'DISABLED' THEN 'dict.value_disabled'
+    WHEN 'REENABLED' THEN 'dict.value_enabled'
+    WHEN 'RESCINDED' THEN 'dict.value_rescinded'
+    ELSE 'dict.value_changed'
+  END;
 
-  RETURN v_guard_index::bigint * v_capacity + v_body_value;
+  PERFORM iam.enqueue_webhook_event(
+    NEW.tenant_uuid,
+    'dict',
+    NEW.event_uuid,
+    v_event_type,
+    jsonb_build_object(
+      'dict_code', NEW.dict_code,
+      'code', NEW.code,
+      'effective_day', NEW.effective_day,
+      'source_event_type', NEW.event_type,
+      'request_id', NEW.request_id,
+      'before', NEW.before_snapshot,
+      'after', NEW.after_snapshot
+    )
+  );
+  RETURN NULL;
 END;
 $$;
 
-CREATE OR REPLACE FUNCTION orgunit.allocate_org_node_key(p_tenant_uuid uuid)
-RETURNS char(8)
+DROP TRIGGER IF EXISTS dict_value_events_enqueue_webhook ON iam.dict_value_events;
+CREATE TRIGGER dict_value_events_enqueue_webhook
+AFTER INSERT ON iam.dict_value_events
+FOR EACH ROW
+EXECUTE FUNCTION iam.enqueue_dict_value_webhook_event();
+
+CREATE OR REPLACE FUNCTION iam.seed_builtin_authz_roles(p_tenant_uuid uuid)
+RETURNS void
 LANGUAGE plpgsql
+SECURITY DEFINER
 AS $$
 DECLARE
-  v_seq bigint;
-  v_org_node_key char(8);
+  v_admin_role_id uuid;
+  v_viewer_role_id uuid;
 BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
 
-  IF p_tenant_uuid IS NULL THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_INVALID_ARGUMENT',
-      DETAIL = 'tenant_uuid is required';
-  END IF;
+  INSERT INTO iam.role_definitions (tenant_uuid, role_slug, name, description, system_managed)
+  VALUES (
+    p_tenant_uuid,
+    'tenant-admin',
+    'Tenant Admin',
+    'Built-in tenant administrator role',
+    true
+  )
+  ON CONFLICT (tenant_uuid, role_slug) DO UPDATE SET
+    name = EXCLUDED.name,
+    description = EXCLUDED.description,
+    system_managed = true,
+    updated_at = now()
+  RETURNING id INTO v_admin_role_id;
 
-  SELECT nextval('orgunit.org_node_key_seq') INTO v_seq;
-  IF v_seq IS NULL OR v_seq <= 0 OR v_seq > 824633720831 THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_NODE_KEY_EXHAUSTED',
-      DETAIL = format('tenant_uuid=%s seq=%s', p_tenant_uuid, COALESCE(v_seq::text, '<null>'));
-  END IF;
+  INSERT INTO iam.role_definitions (tenant_uuid, role_slug, name, description, system_managed)
+  VALUES (
+    p_tenant_uuid,
+    'tenant-viewer',
+    'Tenant Viewer',
+    'Built-in tenant viewer role',
+    true
+  )
+  ON CONFLICT (tenant_uuid, role_slug) DO UPDATE SET
+    name = EXCLUDED.name,
+    description = EXCLUDED.description,
+    system_managed = true,
+    updated_at = now()
+  RETURNING id INTO v_viewer_role_id;
 
-  v_org_node_key := orgunit.encode_org_node_key(v_seq);
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (v_admin_role_id, v_viewer_role_id);
 
-  INSERT INTO orgunit.org_node_key_registry (
-    org_node_key,
-    seq,
-    tenant_uuid
-  )
-  VALUES (
-    v_org_node_key,
-    v_seq,
-    p_tenant_uuid
-  );
+  INSERT INTO iam.role_authz_capabilities (role_id, authz_capability_key)
+  SELECT v_admin_role_id, key
+  FROM unnest(ARRAY[
+    'iam.authz:read',
+    'iam.authz:admin',
+    'iam.dicts:read',
+    'iam.dicts:admin',
+    'iam.dict_release:admin',
+    'iam.webhooks:admin',
+    'cubebox.conversations:read',
+    'cubebox.conversations:use',
+    'cubebox.model_provider:update',
+    'cubebox.model_credential:read',
+    'cubebox.model_credential:rotate',
+    'cubebox.model_credential:deactivate',
+    'cubebox.model_selection:select',
+    'cubebox.model_selection:verify',
+    'orgunit.orgunits:read',
+    'orgunit.orgunits:admin'
+  ]::text[]) AS key
+  ON CONFLICT DO NOTHING;
 
-  RETURN v_org_node_key;
-END;
+  INSERT INTO iam.role_authz_capabilities (role_id, authz_capability_key)
+  SELECT v_viewer_role_id, key
+  FROM unnest(ARRAY[
+    'iam.dicts:read',
+    'cubebox.conversations:read',
+    'cubebox.conversations:use',
+    'orgunit.orgunits:read'
+  ]::text[]) AS key
+  ON CONFLICT DO NOTHING;
+END
 $$;
 
--- end: modules/orgunit/infrastructure/persistence/schema/00024_orgunit_org_node_key_allocator.sql
+DO $$
+DECLARE
+  v_tenant uuid;
+BEGIN
+  FOR v_tenant IN SELECT id FROM iam.tenants LOOP
+    PERFORM iam.seed_builtin_authz_roles(v_tenant);
+  END LOOP;
+END
+$$;
 
--- begin: modules/orgunit/infrastructure/persistence/schema/00025_orgunit_org_node_key_kernel_privileges.sql
 DO $$
 BEGIN
-  IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'orgunit_kernel') THEN
-    CREATE ROLE orgunit_kernel NOLOGIN NOBYPASSRLS;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_runtime') THEN
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.webhook_subscriptions TO app_runtime';
+    EXECUTE 'GRANT SELECT ON iam.webhook_outbox TO app_runtime';
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.webhook_deliveries TO app_runtime';
+    EXECUTE 'GRANT USAGE, SELECT ON SEQUENCE iam.webhook_deliveries_id_seq TO app_runtime';
+    EXECUTE 'GRANT EXECUTE ON FUNCTION iam.enqueue_webhook_event(uuid, text, uuid, text, jsonb) TO app_runtime';
   END IF;
-END $$;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_nobypassrls') THEN
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.webhook_subscriptions TO app_nobypassrls';
+    EXECUTE 'GRANT SELECT ON iam.webhook_outbox TO app_nobypassrls';
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.webhook_deliveries TO app_nobypassrls';
+    EXECUTE 'GRANT USAGE, SELECT ON SEQUENCE iam.webhook_deliveries_id_seq TO app_nobypassrls';
+    EXECUTE 'GRANT EXECUTE ON FUNCTION iam.enqueue_webhook_event(uuid, text, uuid, text, jsonb) TO app_nobypassrls';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    -- Tenant provisioning publishes the dict baseline, which fires the dict value webhook trigger.
+    EXECUTE 'GRANT EXECUTE ON FUNCTION iam.enqueue_webhook_event(uuid, text, uuid, text, jsonb) TO superadmin_runtime';
+    -- Offboarding verification counts residual rows in these tables after iam.purge_tenant_data().
+    EXECUTE 'GRANT SELECT ON iam.webhook_subscriptions, iam.webhook_outbox, iam.webhook_deliveries TO superadmin_runtime';
+  END IF;
+END
+$$;
 
-GRANT USAGE ON SCHEMA orgunit TO orgunit_kernel;
-GRANT USAGE ON SCHEMA iam TO orgunit_kernel;
+-- Tenant hard delete also clears webhook subscriptions, the outbox and the delivery log.
+CREATE OR REPLACE FUNCTION iam.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'iam.webhook_deliveries',
+    'iam.webhook_outbox',
+    'iam.webhook_subscriptions',
+    'iam.principal_org_scope_bindings',
+    'iam.principal_authz_assignment_revisions',
+    'iam.principal_role_assignments',
+    'iam.role_definitions',
+    'iam.cubebox_model_selections',
+    'iam.cubebox_model_health_checks',
+    'iam.cubebox_model_credentials',
+    'iam.cubebox_model_providers',
+    'iam.cubebox_conversation_events',
+    'iam.cubebox_conversations',
+    'iam.dict_value_segments',
+    'iam.dict_value_events',
+    'iam.dict_events',
+    'iam.dicts',
+    'iam.sessions',
+    'iam.principals',
+    'iam.tenant_domains'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+  IF EXISTS (SELECT 1 FROM iam.tenants WHERE id = p_tenant_uuid AND is_active) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_ACTIVE',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
 
-ALTER TABLE IF EXISTS orgunit.org_node_key_registry OWNER TO orgunit_kernel;
-ALTER TABLE IF EXISTS orgunit.org_trees OWNER TO orgunit_kernel;
-ALTER TABLE IF EXISTS orgunit.org_events OWNER TO orgunit_kernel;
-ALTER TABLE IF EXISTS orgunit.org_unit_versions OWNER TO orgunit_kernel;
-ALTER TABLE IF EXISTS orgunit.org_unit_codes OWNER TO orgunit_kernel;
-ALTER SEQUENCE IF EXISTS orgunit.org_node_key_seq OWNER TO orgunit_kernel;
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
 
-GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE
-  orgunit.org_node_key_registry,
-  orgunit.org_events,
-  orgunit.org_unit_versions,
-  orgunit.org_trees,
-  orgunit.org_unit_codes
-TO orgunit_kernel;
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (SELECT id FROM iam.role_definitions WHERE tenant_uuid = p_tenant_uuid);
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.role_authz_capabilities', v_count);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
 
-GRANT USAGE, SELECT ON SEQUENCE orgunit.org_node_key_seq TO orgunit_kernel;
-GRANT SELECT ON TABLE iam.principals TO orgunit_kernel;
+  DELETE FROM iam.tenants WHERE id = p_tenant_uuid;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.tenants', v_count);
 
-ALTER FUNCTION orgunit.is_valid_org_node_key(text)
-  OWNER TO orgunit_kernel;
+  RETURN v_counts;
+END;
+$$;
 
-ALTER FUNCTION orgunit.org_ltree_label(text)
-  OWNER TO orgunit_kernel;
+-- end: modules/iam/infrastructure/persistence/schema/00017_iam_webhook_outbox.sql
+
+-- begin: modules/iam/infrastructure/persistence/schema/00018_iam_cubebox_secret_vault.sql
+-- CubeBox secret vault: model secrets are encrypted with a per-tenant data key, and the data key is stored
+-- wrapped by a master key that only the server holds (CUBEBOX_VAULT_MASTER_KEY_FILE). master_key_version
+-- records which master key wrapped each data key so rotation can rewrap them tenant by tenant. Like the other
+-- cubebox tables these carry no RLS and are always filtered by tenant_uuid.
+CREATE TABLE IF NOT EXISTS iam.cubebox_vault_data_keys (
+  tenant_uuid uuid PRIMARY KEY REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  wrapped_key bytea NOT NULL,
+  master_key_version integer NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  rewrapped_at timestamptz NULL,
+  CONSTRAINT cubebox_vault_data_keys_wrapped_key_nonempty_check CHECK (octet_length(wrapped_key) > 0),
+  CONSTRAINT cubebox_vault_data_keys_master_key_version_positive_check CHECK (master_key_version > 0)
+);
 
-ALTER FUNCTION orgunit.org_path_node_keys(ltree)
-  OWNER TO orgunit_kernel;
+CREATE INDEX IF NOT EXISTS cubebox_vault_data_keys_master_key_version_idx
+  ON iam.cubebox_vault_data_keys (master_key_version);
 
-ALTER FUNCTION orgunit.encode_org_node_key(bigint)
-  OWNER TO orgunit_kernel;
+CREATE TABLE IF NOT EXISTS iam.cubebox_vault_secrets (
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  secret_id text NOT NULL,
+  provider_id text NOT NULL,
+  ciphertext bytea NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (tenant_uuid, secret_id),
+  CONSTRAINT cubebox_vault_secrets_id_nonempty_check CHECK (btrim(secret_id) <> ''),
+  CONSTRAINT cubebox_vault_secrets_provider_nonempty_check CHECK (btrim(provider_id) <> ''),
+  CONSTRAINT cubebox_vault_secrets_ciphertext_nonempty_check CHECK (octet_length(ciphertext) > 0)
+);
 
-ALTER FUNCTION orgunit.decode_org_node_key(char)
-  OWNER TO orgunit_kernel;
+CREATE TABLE IF NOT EXISTS iam.cubebox_vault_decrypt_audit (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  secret_id text NOT NULL,
+  provider_id text NOT NULL DEFAULT '',
+  master_key_version integer NOT NULL,
+  decrypted_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT cubebox_vault_decrypt_audit_secret_nonempty_check CHECK (btrim(secret_id) <> ''),
+  CONSTRAINT cubebox_vault_decrypt_audit_master_key_version_positive_check CHECK (master_key_version > 0)
+);
 
-ALTER FUNCTION orgunit.allocate_org_node_key(uuid)
-  OWNER TO orgunit_kernel;
-ALTER FUNCTION orgunit.allocate_org_node_key(uuid)
-  SECURITY DEFINER;
-ALTER FUNCTION orgunit.allocate_org_node_key(uuid)
-  SET search_path = pg_catalog, orgunit, public;
+CREATE INDEX IF NOT EXISTS cubebox_vault_decrypt_audit_tenant_decrypted_idx
+  ON iam.cubebox_vault_decrypt_audit (tenant_uuid, decrypted_at DESC, id DESC);
 
--- end: modules/orgunit/infrastructure/persistence/schema/00025_orgunit_org_node_key_kernel_privileges.sql
+-- Offboarding verification counts residual rows in these tables after iam.purge_tenant_data().
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT ON ' ||
+      'iam.cubebox_vault_decrypt_audit, ' ||
+      'iam.cubebox_vault_secrets, ' ||
+      'iam.cubebox_vault_data_keys ' ||
+      'TO superadmin_runtime';
+  END IF;
+END
+$$;
 
--- begin: modules/orgunit/infrastructure/persistence/schema/00026_orgunit_org_node_key_engine.sql
--- DEV-PLAN-320 P3: org_node_key runtime engine overlay
-CREATE OR REPLACE FUNCTION orgunit.assert_current_tenant(p_tenant_uuid uuid)
-RETURNS void
+-- Tenant hard delete also clears the vault: data keys, sealed secrets and the decrypt audit.
+CREATE OR REPLACE FUNCTION iam.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
 LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
 AS $$
-  DECLARE
-  v_ctx_raw text;
-  v_ctx_tenant uuid;
+DECLARE
+  v_tables text[] := ARRAY[
+    'iam.cubebox_vault_decrypt_audit',
+    'iam.cubebox_vault_secrets',
+    'iam.cubebox_vault_data_keys',
+    'iam.webhook_deliveries',
+    'iam.webhook_outbox',
+    'iam.webhook_subscriptions',
+    'iam.principal_org_scope_bindings',
+    'iam.principal_authz_assignment_revisions',
+    'iam.principal_role_assignments',
+    'iam.role_definitions',
+    'iam.cubebox_model_selections',
+    'iam.cubebox_model_health_checks',
+    'iam.cubebox_model_credentials',
+    'iam.cubebox_model_providers',
+    'iam.cubebox_conversation_events',
+    'iam.cubebox_conversations',
+    'iam.dict_value_segments',
+    'iam.dict_value_events',
+    'iam.dict_events',
+    'iam.dicts',
+    'iam.sessions',
+    'iam.principals',
+    'iam.tenant_domains'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
 BEGIN
-  IF p_tenant_uuid IS NULL THEN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
     RAISE EXCEPTION USING
-      ERRCODE = 'P0001',
-      MESSAGE = 'ORG_INVALID_ARGUMENT',
-      DETAIL = 'tenant_uuid is required';
+      MESSAGE = 'IAM_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
   END IF;
-
-  v_ctx_raw := current_setting('app.current_tenant', true);
-  IF v_ctx_raw IS NULL OR btrim(v_ctx_raw) = '' THEN
+  IF EXISTS (SELECT 1 FROM iam.tenants WHERE id = p_tenant_uuid AND is_active) THEN
     RAISE EXCEPTION USING
-      ERRCODE = 'P0001',
-      MESSAGE = 'RLS_TENANT_CONTEXT_MISSING',
-      DETAIL = 'app.current_tenant is required';
+      MESSAGE = 'IAM_TENANT_PURGE_ACTIVE',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
   END IF;
 
-  BEGIN
-    v_ctx_tenant := v_ctx_raw::uuid;
-  EXCEPTION
-    WHEN invalid_text_representation THEN
-      RAISE EXCEPTION USING
-        ERRCODE = 'P0001',
-        MESSAGE = 'RLS_TENANT_CONTEXT_INVALID',
-        DETAIL = format('app.current_tenant=%s', v_ctx_raw);
-  END;
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
 
-  IF v_ctx_tenant <> p_tenant_uuid THEN
-    RAISE EXCEPTION USING
-      ERRCODE = 'P0001',
-      MESSAGE = 'RLS_TENANT_MISMATCH',
-      DETAIL = format('tenant_param=%s tenant_ctx=%s', p_tenant_uuid, v_ctx_tenant);
-  END IF;
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (SELECT id FROM iam.role_definitions WHERE tenant_uuid = p_tenant_uuid);
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.role_authz_capabilities', v_count);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  DELETE FROM iam.tenants WHERE id = p_tenant_uuid;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.tenants', v_count);
+
+  RETURN v_counts;
 END;
 $$;
 
-CREATE OR REPLACE FUNCTION orgunit.fill_org_event_audit_snapshot()
-RETURNS trigger
+-- end: modules/iam/infrastructure/persistence/schema/00018_iam_cubebox_secret_vault.sql
+
+-- begin: modules/iam/infrastructure/persistence/schema/00019_iam_cubebox_token_usage.sql
+-- CubeBox token usage: one row per (turn, model) with prompt/completion token counts taken from the provider
+-- response, or estimated from text length when the provider reports none (estimated = true). Rows are a cost
+-- ledger and deliberately outlive the conversation and principal they describe.
+-- Token budgets cap daily and monthly usage per tenant (principal_id = '') or per principal; a limit of 0
+-- means unlimited. Like the other cubebox tables these carry no RLS and are always filtered by tenant_uuid.
+CREATE TABLE IF NOT EXISTS iam.cubebox_turn_usage (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  principal_id uuid NOT NULL,
+  conversation_id text NOT NULL,
+  turn_id text NOT NULL,
+  provider_id text NOT NULL DEFAULT '',
+  model_slug text NOT NULL,
+  prompt_tokens bigint NOT NULL DEFAULT 0,
+  completion_tokens bigint NOT NULL DEFAULT 0,
+  total_tokens bigint GENERATED ALWAYS AS (prompt_tokens + completion_tokens) STORED,
+  estimated boolean NOT NULL DEFAULT false,
+  recorded_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT cubebox_turn_usage_turn_model_unique UNIQUE (tenant_uuid, conversation_id, turn_id, model_slug),
+  CONSTRAINT cubebox_turn_usage_turn_nonempty_check CHECK (btrim(turn_id) <> ''),
+  CONSTRAINT cubebox_turn_usage_model_nonempty_check CHECK (btrim(model_slug) <> ''),
+  CONSTRAINT cubebox_turn_usage_tokens_nonnegative_check CHECK (prompt_tokens >= 0 AND completion_tokens >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS cubebox_turn_usage_tenant_recorded_idx
+  ON iam.cubebox_turn_usage (tenant_uuid, recorded_at DESC);
+
+CREATE INDEX IF NOT EXISTS cubebox_turn_usage_tenant_principal_recorded_idx
+  ON iam.cubebox_turn_usage (tenant_uuid, principal_id, recorded_at DESC);
+
+CREATE TABLE IF NOT EXISTS iam.cubebox_token_budgets (
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  principal_id text NOT NULL DEFAULT '',
+  daily_token_limit bigint NOT NULL DEFAULT 0,
+  monthly_token_limit bigint NOT NULL DEFAULT 0,
+  updated_by uuid NULL,
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (tenant_uuid, principal_id),
+  CONSTRAINT cubebox_token_budgets_limits_nonnegative_check CHECK (daily_token_limit >= 0 AND monthly_token_limit >= 0)
+);
+
+-- Offboarding export reads these tables and verification counts residual rows after iam.purge_tenant_data().
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT ON ' ||
+      'iam.cubebox_turn_usage, ' ||
+      'iam.cubebox_token_budgets ' ||
+      'TO superadmin_runtime';
+  END IF;
+END
+$$;
+
+-- Tenant hard delete also clears the usage ledger and token budgets.
+CREATE OR REPLACE FUNCTION iam.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
 LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
 AS $$
 DECLARE
-  v_name text;
-  v_employee text;
+  v_tables text[] := ARRAY[
+    'iam.cubebox_turn_usage',
+    'iam.cubebox_token_budgets',
+    'iam.cubebox_vault_decrypt_audit',
+    'iam.cubebox_vault_secrets',
+    'iam.cubebox_vault_data_keys',
+    'iam.webhook_deliveries',
+    'iam.webhook_outbox',
+    'iam.webhook_subscriptions',
+    'iam.principal_org_scope_bindings',
+    'iam.principal_authz_assignment_revisions',
+    'iam.principal_role_assignments',
+    'iam.role_definitions',
+    'iam.cubebox_model_selections',
+    'iam.cubebox_model_health_checks',
+    'iam.cubebox_model_credentials',
+    'iam.cubebox_model_providers',
+    'iam.cubebox_conversation_events',
+    'iam.cubebox_conversations',
+    'iam.dict_value_segments',
+    'iam.dict_value_events',
+    'iam.dict_events',
+    'iam.dicts',
+    'iam.sessions',
+    'iam.principals',
+    'iam.tenant_domains'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
 BEGIN
-  IF NEW.tx_time IS NULL THEN
-    NEW.tx_time := COALESCE(NEW.transaction_time, now());
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
   END IF;
-
-  IF NULLIF(btrim(COALESCE(NEW.initiator_name, '')), '') IS NOT NULL
-    AND NULLIF(btrim(COALESCE(NEW.initiator_employee_id, '')), '') IS NOT NULL
-  THEN
-    RETURN NEW;
+  IF EXISTS (SELECT 1 FROM iam.tenants WHERE id = p_tenant_uuid AND is_active) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_ACTIVE',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
   END IF;
 
-  IF to_regclass('iam.principals') IS NOT NULL THEN
-    SELECT
-      COALESCE(NULLIF(btrim(p.display_name), ''), NULLIF(btrim(p.email), ''), NEW.initiator_uuid::text),
-      COALESCE(NULLIF(btrim(p.email), ''), NEW.initiator_uuid::text)
-    INTO v_name, v_employee
-    FROM iam.principals p
-    WHERE p.tenant_uuid = NEW.tenant_uuid
-      AND p.id = NEW.initiator_uuid
-    LIMIT 1;
-  END IF;
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
 
-  NEW.initiator_name := COALESCE(NULLIF(btrim(COALESCE(NEW.initiator_name, '')), ''), v_name, NEW.initiator_uuid::text);
-  NEW.initiator_employee_id := COALESCE(NULLIF(btrim(COALESCE(NEW.initiator_employee_id, '')), ''), v_employee, NEW.initiator_uuid::text);
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (SELECT id FROM iam.role_definitions WHERE tenant_uuid = p_tenant_uuid);
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.role_authz_capabilities', v_count);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
 
-  RETURN NEW;
+  DELETE FROM iam.tenants WHERE id = p_tenant_uuid;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.tenants', v_count);
+
+  RETURN v_counts;
 END;
 $$;
 
-DROP TRIGGER IF EXISTS org_events_fill_audit_snapshot ON orgunit.org_events;
-CREATE TRIGGER org_events_fill_audit_snapshot
-BEFORE INSERT ON orgunit.org_events
-FOR EACH ROW
-EXECUTE FUNCTION orgunit.fill_org_event_audit_snapshot();
+-- end: modules/iam/infrastructure/persistence/schema/00019_iam_cubebox_token_usage.sql
 
-CREATE OR REPLACE FUNCTION orgunit.merge_org_event_payload_with_correction(
-  p_base_payload jsonb,
-  p_correction_payload jsonb
-)
-RETURNS jsonb
-LANGUAGE sql
-IMMUTABLE
-AS $$
-  WITH base_payload AS (
-    SELECT COALESCE(p_base_payload, '{}'::jsonb) AS payload
-  ),
-  correction_patch AS (
-    SELECT COALESCE(p_correction_payload, '{}'::jsonb) - 'effective_date' - 'target_event_uuid' - 'op' AS payload
-  ),
-  parts AS (
-    SELECT
-      (b.payload - 'ext' - 'ext_labels_snapshot') AS base_noext,
-      (c.payload - 'ext' - 'ext_labels_snapshot') AS patch_noext,
-      CASE WHEN jsonb_typeof(b.payload->'ext') = 'object' THEN b.payload->'ext' ELSE '{}'::jsonb END AS base_ext,
-      CASE WHEN jsonb_typeof(c.payload->'ext') = 'object' THEN c.payload->'ext' ELSE '{}'::jsonb END AS patch_ext,
-      CASE WHEN jsonb_typeof(b.payload->'ext_labels_snapshot') = 'object' THEN b.payload->'ext_labels_snapshot' ELSE '{}'::jsonb END AS base_labels,
-      CASE WHEN jsonb_typeof(c.payload->'ext_labels_snapshot') = 'object' THEN c.payload->'ext_labels_snapshot' ELSE '{}'::jsonb END AS patch_labels,
-      (b.payload ? 'ext') OR (c.payload ? 'ext') AS has_ext,
-      (b.payload ? 'ext_labels_snapshot') OR (c.payload ? 'ext_labels_snapshot') AS has_labels
-    FROM base_payload b
-    CROSS JOIN correction_patch c
-  ),
-  merged AS (
-    SELECT
-      (base_noext || patch_noext) AS merged_noext,
-      (base_ext || patch_ext) AS ext_merged,
-      (base_labels || patch_labels) AS labels_merged,
-      has_ext,
-      has_labels
-    FROM parts
-  )
-  SELECT
-    merged_noext
-    || CASE
-         WHEN has_ext
-           THEN jsonb_build_object('ext', ext_merged)
-         ELSE '{}'::jsonb
-       END
-    || CASE
-         WHEN has_labels
-           THEN jsonb_build_object(
-             'ext_labels_snapshot',
-             labels_merged - COALESCE((
-               SELECT array_agg(key)
-               FROM jsonb_each(ext_merged)
-               WHERE value = 'null'::jsonb
-             ), ARRAY[]::text[])
-           )
-         ELSE '{}'::jsonb
-       END
-  FROM merged;
-$$;
+-- begin: modules/iam/infrastructure/persistence/schema/00020_iam_cubebox_redaction_policies.sql
+-- CubeBox redaction policies: which kinds of values (pernr, email, phone, identity) and which ext field keys
+-- are replaced with placeholders before prompts reach a model provider. Tenants without a row get the
+-- built-in default (enabled, every kind). Each change is appended to the audit table with the full policy.
+-- Like the other cubebox tables these carry no RLS and are always filtered by tenant_uuid.
+CREATE TABLE IF NOT EXISTS iam.cubebox_redaction_policies (
+  tenant_uuid uuid PRIMARY KEY REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  enabled boolean NOT NULL DEFAULT true,
+  kinds text[] NOT NULL DEFAULT '{}',
+  ext_fields text[] NOT NULL DEFAULT '{}',
+  updated_by uuid NULL,
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
 
-CREATE OR REPLACE VIEW orgunit.org_events_effective AS
-WITH correction_events AS (
-  SELECT
-    e.*,
-    (e.payload->>'target_event_uuid')::uuid AS target_event_uuid
-  FROM orgunit.org_events e
-  WHERE e.event_type IN ('CORRECT_EVENT','CORRECT_STATUS','RESCIND_EVENT','RESCIND_ORG')
-    AND e.payload ? 'target_event_uuid'
-),
-latest_corrections AS (
-  SELECT DISTINCT ON (tenant_uuid, target_event_uuid)
-    tenant_uuid,
-    target_event_uuid,
-    event_type AS correction_type,
-    payload AS correction_payload,
-    tx_time,
-    id
-  FROM correction_events
-  ORDER BY tenant_uuid, target_event_uuid, tx_time DESC, id DESC
-),
-latest_effective_date_corrections AS (
-  -- Sticky effective_date: take the latest CORRECT_EVENT that explicitly carries effective_date,
-  -- regardless of later corrections that don't include effective_date.
-  SELECT DISTINCT ON (tenant_uuid, target_event_uuid)
-    tenant_uuid,
-    target_event_uuid,
-    NULLIF(btrim(payload->>'effective_date'), '')::date AS sticky_effective_date,
-    tx_time,
-    id
-  FROM correction_events
-  WHERE event_type = 'CORRECT_EVENT'
-    AND payload ? 'effective_date'
-  ORDER BY tenant_uuid, target_event_uuid, tx_time DESC, id DESC
-)
-SELECT
-  e.id,
-  e.event_uuid,
-  e.tenant_uuid,
-  e.org_node_key,
-  CASE
-    WHEN lc.correction_type = 'CORRECT_STATUS'
-      AND COALESCE(lc.correction_payload->>'target_status', '') = 'active'
-      THEN 'ENABLE'
-    WHEN lc.correction_type = 'CORRECT_STATUS'
-      AND COALESCE(lc.correction_payload->>'target_status', '') = 'disabled'
-      THEN 'DISABLE'
-    ELSE e.event_type
-  END AS event_type,
-  COALESCE(lec.sticky_effective_date, e.effective_date) AS effective_date,
-  CASE
-    WHEN lc.correction_type = 'CORRECT_EVENT'
-      THEN orgunit.merge_org_event_payload_with_correction(e.payload, lc.correction_payload)
-    ELSE e.payload
-  END AS payload,
-  e.request_id,
-  e.initiator_uuid,
-  e.transaction_time,
-  e.created_at
-FROM orgunit.org_events e
-LEFT JOIN latest_corrections lc
-  ON lc.tenant_uuid = e.tenant_uuid
- AND lc.target_event_uuid = e.event_uuid
-LEFT JOIN latest_effective_date_corrections lec
-  ON lec.tenant_uuid = e.tenant_uuid
- AND lec.target_event_uuid = e.event_uuid
-WHERE e.event_type IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT')
-  AND COALESCE(lc.correction_type, '') NOT IN ('RESCIND_EVENT', 'RESCIND_ORG');
+CREATE TABLE IF NOT EXISTS iam.cubebox_redaction_policy_audit (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  changed_by uuid NOT NULL,
+  changed_at timestamptz NOT NULL DEFAULT now(),
+  policy jsonb NOT NULL,
+  CONSTRAINT cubebox_redaction_policy_audit_policy_object_check CHECK (jsonb_typeof(policy) = 'object')
+);
 
-CREATE OR REPLACE FUNCTION orgunit.is_org_ext_payload_allowed_for_event(
-  p_event_type text,
-  p_target_event_type text
-)
-RETURNS boolean
-LANGUAGE sql
-IMMUTABLE
-AS $$
-  SELECT CASE
-    WHEN p_event_type IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT') THEN true
-    WHEN p_event_type = 'CORRECT_EVENT'
-      THEN p_target_event_type IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT')
-    ELSE false
-  END;
+CREATE INDEX IF NOT EXISTS cubebox_redaction_policy_audit_tenant_id_idx
+  ON iam.cubebox_redaction_policy_audit (tenant_uuid, id DESC);
+
+-- Offboarding export reads these tables and verification counts residual rows after iam.purge_tenant_data().
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT ON ' ||
+      'iam.cubebox_redaction_policy_audit, ' ||
+      'iam.cubebox_redaction_policies ' ||
+      'TO superadmin_runtime';
+  END IF;
+END
 $$;
 
-CREATE OR REPLACE FUNCTION orgunit.apply_org_event_ext_payload(
-  p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_effective_date date,
-  p_event_type text,
-  p_payload jsonb,
-  p_event_db_id bigint,
-  p_target_event_type text DEFAULT NULL
-)
-RETURNS void
+-- Tenant hard delete also clears redaction policies and their audit trail.
+CREATE OR REPLACE FUNCTION iam.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
 LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
 AS $$
 DECLARE
-  v_payload jsonb;
-  v_ext jsonb;
-  v_labels jsonb;
-  v_has_ext_payload boolean;
-  v_has_ext_fields boolean;
-  v_field_key text;
-  v_label_key text;
-  v_field_value jsonb;
-  v_label_value jsonb;
-  v_label_text text;
-  v_value_text text;
-  v_physical_col text;
-  v_value_type text;
-  v_data_source_type text;
-  v_enabled_on date;
-  v_disabled_on date;
-  v_cast_type text;
-  v_sql text;
+  v_tables text[] := ARRAY[
+    'iam.cubebox_redaction_policy_audit',
+    'iam.cubebox_redaction_policies',
+    'iam.cubebox_turn_usage',
+    'iam.cubebox_token_budgets',
+    'iam.cubebox_vault_decrypt_audit',
+    'iam.cubebox_vault_secrets',
+    'iam.cubebox_vault_data_keys',
+    'iam.webhook_deliveries',
+    'iam.webhook_outbox',
+    'iam.webhook_subscriptions',
+    'iam.principal_org_scope_bindings',
+    'iam.principal_authz_assignment_revisions',
+    'iam.principal_role_assignments',
+    'iam.role_definitions',
+    'iam.cubebox_model_selections',
+    'iam.cubebox_model_health_checks',
+    'iam.cubebox_model_credentials',
+    'iam.cubebox_model_providers',
+    'iam.cubebox_conversation_events',
+    'iam.cubebox_conversations',
+    'iam.dict_value_segments',
+    'iam.dict_value_events',
+    'iam.dict_events',
+    'iam.dicts',
+    'iam.sessions',
+    'iam.principals',
+    'iam.tenant_domains'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
 BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
-
-  IF p_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
-  END IF;
-  IF p_effective_date IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
   END IF;
-  IF p_event_type IS NULL OR btrim(p_event_type) = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'event_type is required';
+  IF EXISTS (SELECT 1 FROM iam.tenants WHERE id = p_tenant_uuid AND is_active) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_ACTIVE',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
   END IF;
 
-  v_payload := COALESCE(p_payload, '{}'::jsonb);
-  v_has_ext_payload := v_payload ? 'ext' OR v_payload ? 'ext_labels_snapshot';
-  IF NOT v_has_ext_payload THEN
-    RETURN;
-  END IF;
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
 
-  IF NOT orgunit.is_org_ext_payload_allowed_for_event(p_event_type, p_target_event_type) THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_EXT_PAYLOAD_NOT_ALLOWED_FOR_EVENT',
-      DETAIL = format('event_type=%s target_event_type=%s', p_event_type, COALESCE(p_target_event_type, 'NULL'));
-  END IF;
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (SELECT id FROM iam.role_definitions WHERE tenant_uuid = p_tenant_uuid);
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.role_authz_capabilities', v_count);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
 
-  v_ext := COALESCE(v_payload->'ext', '{}'::jsonb);
-  v_labels := COALESCE(v_payload->'ext_labels_snapshot', '{}'::jsonb);
+  DELETE FROM iam.tenants WHERE id = p_tenant_uuid;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.tenants', v_count);
 
-  IF jsonb_typeof(v_ext) <> 'object' OR jsonb_typeof(v_labels) <> 'object' THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_EXT_PAYLOAD_INVALID_SHAPE',
-      DETAIL = 'payload.ext and payload.ext_labels_snapshot must be objects';
-  END IF;
+  RETURN v_counts;
+END;
+$$;
 
-  FOR v_label_key IN
-    SELECT key
-    FROM jsonb_object_keys(v_labels) AS t(key)
-  LOOP
-    IF NOT (v_ext ? v_label_key) THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_EXT_LABEL_SNAPSHOT_NOT_ALLOWED',
-        DETAIL = format('field_key=%s', v_label_key);
-    END IF;
-  END LOOP;
+-- end: modules/iam/infrastructure/persistence/schema/00020_iam_cubebox_redaction_policies.sql
 
-  SELECT EXISTS (
-    SELECT 1
-    FROM jsonb_object_keys(v_ext)
-  ) INTO v_has_ext_fields;
+-- begin: modules/iam/infrastructure/persistence/schema/00021_iam_cubebox_conversation_search_retention.sql
+-- CubeBox conversation search: one document per conversation holding the text of its user messages and
+-- agent reply deltas, appended by a trigger in the event insert transaction. Messages are mostly Chinese,
+-- which text search configurations do not segment, so the document and the title are trigram indexed and
+-- searched with ILIKE.
+-- Retention: a tenant policy purges conversations archived for longer than archived_retention_days (0 keeps
+-- them forever); every run that deletes something leaves an audit row with its counts. Like the other
+-- cubebox tables these carry no RLS and are always filtered by tenant_uuid.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
 
-  IF NOT v_has_ext_fields THEN
-    RETURN;
-  END IF;
+CREATE TABLE IF NOT EXISTS iam.cubebox_conversation_search_docs (
+  tenant_uuid uuid NOT NULL,
+  conversation_id text NOT NULL,
+  body text NOT NULL DEFAULT '',
+  PRIMARY KEY (tenant_uuid, conversation_id),
+  CONSTRAINT cubebox_conversation_search_docs_conversation_fk FOREIGN KEY (tenant_uuid, conversation_id)
+    REFERENCES iam.cubebox_conversations(tenant_uuid, conversation_id) ON DELETE CASCADE
+);
 
-  IF p_event_db_id IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'event_db_id is required';
-  END IF;
+CREATE INDEX IF NOT EXISTS cubebox_conversation_search_docs_body_trgm
+  ON iam.cubebox_conversation_search_docs
+  USING gin (body gin_trgm_ops);
 
-  PERFORM orgunit.split_org_unit_version_at(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_db_id);
+CREATE INDEX IF NOT EXISTS cubebox_conversations_title_trgm
+  ON iam.cubebox_conversations
+  USING gin (title gin_trgm_ops);
 
-  FOR v_field_key, v_field_value IN
-    SELECT key, value
-    FROM jsonb_each(v_ext)
-  LOOP
-    IF v_field_key !~ '^[a-z][a-z0-9_]{0,62}$' THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_EXT_FIELD_NOT_CONFIGURED',
-        DETAIL = format('field_key=%s', v_field_key);
-    END IF;
+CREATE OR REPLACE FUNCTION iam.cubebox_conversation_search_docs_append()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_text text;
+BEGIN
+  IF NEW.event_type = 'turn.user_message.accepted' THEN
+    v_text := E'\n' || COALESCE(NEW.payload->>'text', '') || E'\n';
+  ELSE
+    v_text := COALESCE(NEW.payload->>'delta', '');
+  END IF;
+  IF v_text = '' THEN
+    RETURN NEW;
+  END IF;
+  INSERT INTO iam.cubebox_conversation_search_docs (tenant_uuid, conversation_id, body)
+  VALUES (NEW.tenant_uuid, NEW.conversation_id, v_text)
+  ON CONFLICT (tenant_uuid, conversation_id) DO UPDATE
+  SET body = iam.cubebox_conversation_search_docs.body || EXCLUDED.body;
+  RETURN NEW;
+END;
+$$;
 
-    SELECT
-      physical_col,
-      value_type,
-      data_source_type,
-      enabled_on,
-      disabled_on
-    INTO v_physical_col, v_value_type, v_data_source_type, v_enabled_on, v_disabled_on
-    FROM orgunit.tenant_field_configs
-    WHERE tenant_uuid = p_tenant_uuid
-      AND field_key = v_field_key
-    LIMIT 1;
+DROP TRIGGER IF EXISTS cubebox_conversation_events_search_docs ON iam.cubebox_conversation_events;
+CREATE TRIGGER cubebox_conversation_events_search_docs
+  AFTER INSERT ON iam.cubebox_conversation_events
+  FOR EACH ROW
+  WHEN (NEW.event_type IN ('turn.user_message.accepted', 'turn.agent_message.delta'))
+  EXECUTE FUNCTION iam.cubebox_conversation_search_docs_append();
 
-    IF NOT FOUND THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_EXT_FIELD_NOT_CONFIGURED',
-        DETAIL = format('field_key=%s', v_field_key);
-    END IF;
+INSERT INTO iam.cubebox_conversation_search_docs (tenant_uuid, conversation_id, body)
+SELECT
+  tenant_uuid,
+  conversation_id,
+  string_agg(
+    CASE
+      WHEN event_type = 'turn.user_message.accepted' THEN E'\n' || COALESCE(payload->>'text', '') || E'\n'
+      ELSE COALESCE(payload->>'delta', '')
+    END,
+    '' ORDER BY sequence
+  )
+FROM iam.cubebox_conversation_events
+WHERE event_type IN ('turn.user_message.accepted', 'turn.agent_message.delta')
+GROUP BY tenant_uuid, conversation_id
+ON CONFLICT (tenant_uuid, conversation_id) DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS cubebox_conversations_tenant_archived_at_idx
+  ON iam.cubebox_conversations (tenant_uuid, archived_at)
+  WHERE archived;
+
+CREATE TABLE IF NOT EXISTS iam.cubebox_retention_policies (
+  tenant_uuid uuid PRIMARY KEY REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  archived_retention_days integer NOT NULL DEFAULT 0,
+  updated_by uuid NULL,
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT cubebox_retention_policies_days_check CHECK (archived_retention_days BETWEEN 0 AND 3650)
+);
 
-    IF NOT (
-      v_enabled_on <= p_effective_date
-      AND (v_disabled_on IS NULL OR p_effective_date < v_disabled_on)
-    ) THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_EXT_FIELD_NOT_ENABLED_AS_OF',
-        DETAIL = format('field_key=%s effective_date=%s', v_field_key, p_effective_date);
-    END IF;
+CREATE TABLE IF NOT EXISTS iam.cubebox_retention_runs (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  ran_at timestamptz NOT NULL DEFAULT now(),
+  cutoff timestamptz NOT NULL,
+  archived_retention_days integer NOT NULL,
+  purged_conversations bigint NOT NULL DEFAULT 0,
+  purged_events bigint NOT NULL DEFAULT 0,
+  CONSTRAINT cubebox_retention_runs_counts_nonnegative_check CHECK (purged_conversations >= 0 AND purged_events >= 0)
+);
 
-    IF v_physical_col !~ '^ext_(str|int|uuid|bool|date|num)_[0-9]{2}$'
-      OR (v_value_type = 'text' AND v_physical_col NOT LIKE 'ext_str_%')
-      OR (v_value_type = 'int' AND v_physical_col NOT LIKE 'ext_int_%')
-      OR (v_value_type = 'uuid' AND v_physical_col NOT LIKE 'ext_uuid_%')
-      OR (v_value_type = 'bool' AND v_physical_col NOT LIKE 'ext_bool_%')
-      OR (v_value_type = 'date' AND v_physical_col NOT LIKE 'ext_date_%')
-      OR (v_value_type = 'numeric' AND v_physical_col NOT LIKE 'ext_num_%')
-    THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
-        DETAIL = format('field_key=%s physical_col=%s value_type=%s', v_field_key, v_physical_col, v_value_type);
-    END IF;
+CREATE INDEX IF NOT EXISTS cubebox_retention_runs_tenant_id_idx
+  ON iam.cubebox_retention_runs (tenant_uuid, id DESC);
 
-    IF NOT EXISTS (
-      SELECT 1
-      FROM information_schema.columns c
-      WHERE c.table_schema = 'orgunit'
-        AND c.table_name = 'org_unit_versions'
-        AND c.column_name = v_physical_col
-    ) THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
-        DETAIL = format('field_key=%s physical_col=%s missing_in_org_unit_versions', v_field_key, v_physical_col);
-    END IF;
+-- Offboarding verification counts residual rows in these tables; they cascade with the tenant and its conversations.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT ON ' ||
+      'iam.cubebox_conversation_search_docs, ' ||
+      'iam.cubebox_retention_policies, ' ||
+      'iam.cubebox_retention_runs ' ||
+      'TO superadmin_runtime';
+  END IF;
+END
+$$;
 
-    v_value_text := NULL;
-    IF v_field_value <> 'null'::jsonb THEN
-      IF v_value_type = 'text' THEN
-        IF jsonb_typeof(v_field_value) <> 'string' THEN
-          RAISE EXCEPTION USING
-            MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
-            DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
-        END IF;
-        v_value_text := v_field_value #>> '{}';
-      ELSIF v_value_type = 'int' THEN
-        IF jsonb_typeof(v_field_value) NOT IN ('number', 'string') THEN
-          RAISE EXCEPTION USING
-            MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
-            DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
-        END IF;
-        BEGIN
-          v_value_text := ((v_field_value #>> '{}')::int)::text;
-        EXCEPTION
-          WHEN invalid_text_representation OR numeric_value_out_of_range THEN
-            RAISE EXCEPTION USING
-              MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
-              DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
-        END;
-      ELSIF v_value_type = 'uuid' THEN
-        IF jsonb_typeof(v_field_value) <> 'string' THEN
-          RAISE EXCEPTION USING
-            MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
-            DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
-        END IF;
-        BEGIN
-          v_value_text := ((v_field_value #>> '{}')::uuid)::text;
-        EXCEPTION
-          WHEN invalid_text_representation THEN
-            RAISE EXCEPTION USING
-              MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
-              DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
-        END;
-      ELSIF v_value_type = 'bool' THEN
-        IF jsonb_typeof(v_field_value) <> 'boolean' THEN
-          RAISE EXCEPTION USING
-            MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
-            DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
-        END IF;
-        v_value_text := ((v_field_value #>> '{}')::boolean)::text;
-      ELSIF v_value_type = 'date' THEN
-        IF jsonb_typeof(v_field_value) <> 'string' THEN
-          RAISE EXCEPTION USING
-            MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
-            DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
-        END IF;
-        BEGIN
-          v_value_text := ((v_field_value #>> '{}')::date)::text;
-        EXCEPTION
-          WHEN invalid_text_representation OR datetime_field_overflow THEN
-            RAISE EXCEPTION USING
-              MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
-              DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
-        END;
-      ELSIF v_value_type = 'numeric' THEN
-        IF jsonb_typeof(v_field_value) NOT IN ('number', 'string') THEN
-          RAISE EXCEPTION USING
-            MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
-            DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
-        END IF;
-        BEGIN
-          v_value_text := ((v_field_value #>> '{}')::numeric)::text;
-        EXCEPTION
-          WHEN invalid_text_representation OR numeric_value_out_of_range THEN
-            RAISE EXCEPTION USING
-              MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
-              DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
-        END;
-      ELSE
-        RAISE EXCEPTION USING
-          MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
-          DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
-      END IF;
-    END IF;
+-- end: modules/iam/infrastructure/persistence/schema/00021_iam_cubebox_conversation_search_retention.sql
 
-    v_label_text := NULL;
-    IF v_data_source_type = 'DICT' THEN
-      IF v_field_value <> 'null'::jsonb THEN
-        IF NOT (v_labels ? v_field_key) THEN
-          RAISE EXCEPTION USING
-            MESSAGE = 'ORG_EXT_LABEL_SNAPSHOT_REQUIRED',
-            DETAIL = format('field_key=%s', v_field_key);
-        END IF;
+-- begin: modules/iam/infrastructure/persistence/schema/00022_iam_cubebox_conversation_shares.sql
+-- CubeBox share links: read-only access to one conversation, or to one turn of it, for a fixed list of
+-- principals of the owner's tenant. Links expire and can be revoked by their owner; revoked rows are kept
+-- so the owner can see what was shared. Shares go with their conversation, including retention purges.
+-- Like the other cubebox tables this carries no RLS and is always filtered by tenant_uuid.
+CREATE TABLE IF NOT EXISTS iam.cubebox_conversation_shares (
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  share_id text NOT NULL,
+  conversation_id text NOT NULL,
+  turn_id text NULL,
+  owner_principal_id uuid NOT NULL,
+  principal_ids uuid[] NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  expires_at timestamptz NOT NULL,
+  revoked_at timestamptz NULL,
+  PRIMARY KEY (tenant_uuid, share_id),
+  CONSTRAINT cubebox_conversation_shares_conversation_fk FOREIGN KEY (tenant_uuid, conversation_id)
+    REFERENCES iam.cubebox_conversations(tenant_uuid, conversation_id) ON DELETE CASCADE,
+  CONSTRAINT cubebox_conversation_shares_principals_nonempty_check CHECK (cardinality(principal_ids) > 0),
+  CONSTRAINT cubebox_conversation_shares_expiry_check CHECK (expires_at > created_at),
+  CONSTRAINT cubebox_conversation_shares_turn_id_nonempty_or_null_check CHECK (
+    turn_id IS NULL OR btrim(turn_id) <> ''
+  )
+);
 
-        v_label_value := v_labels->v_field_key;
-        IF jsonb_typeof(v_label_value) <> 'string'
-          OR NULLIF(btrim(v_label_value #>> '{}'), '') IS NULL
-        THEN
-          RAISE EXCEPTION USING
-            MESSAGE = 'ORG_EXT_LABEL_SNAPSHOT_REQUIRED',
-            DETAIL = format('field_key=%s', v_field_key);
-        END IF;
-        v_label_text := btrim(v_label_value #>> '{}');
-      ELSIF v_labels ? v_field_key THEN
-        v_label_value := v_labels->v_field_key;
-        IF jsonb_typeof(v_label_value) <> 'null' THEN
-          RAISE EXCEPTION USING
-            MESSAGE = 'ORG_EXT_LABEL_SNAPSHOT_REQUIRED',
-            DETAIL = format('field_key=%s', v_field_key);
-        END IF;
-      END IF;
-    ELSIF v_labels ? v_field_key THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_EXT_LABEL_SNAPSHOT_NOT_ALLOWED',
-        DETAIL = format('field_key=%s', v_field_key);
-    END IF;
+CREATE INDEX IF NOT EXISTS cubebox_conversation_shares_owner_idx
+  ON iam.cubebox_conversation_shares (tenant_uuid, owner_principal_id, created_at DESC);
 
-    v_cast_type := CASE v_value_type
-      WHEN 'text' THEN 'text'
-      WHEN 'int' THEN 'int'
-      WHEN 'uuid' THEN 'uuid'
-      WHEN 'bool' THEN 'boolean'
-      WHEN 'date' THEN 'date'
-      WHEN 'numeric' THEN 'numeric'
-      ELSE NULL
-    END;
+-- Offboarding export reads share links and verification counts residual rows; they cascade with their conversation.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT ON ' ||
+      'iam.cubebox_conversation_shares ' ||
+      'TO superadmin_runtime';
+  END IF;
+END
+$$;
 
-    IF v_cast_type IS NULL THEN
+-- end: modules/iam/infrastructure/persistence/schema/00022_iam_cubebox_conversation_shares.sql
+
+-- begin: modules/iam/infrastructure/persistence/schema/00023_iam_cubebox_turn_feedback.sql
+-- CubeBox answer feedback is stored as turn.feedback conversation events. Reports and dataset exports read
+-- them tenant-wide by time, which the per-conversation event index cannot serve.
+CREATE INDEX IF NOT EXISTS cubebox_conversation_events_feedback_idx
+  ON iam.cubebox_conversation_events (tenant_uuid, created_at)
+  WHERE event_type = 'turn.feedback';
+
+-- end: modules/iam/infrastructure/persistence/schema/00023_iam_cubebox_turn_feedback.sql
+
+-- begin: modules/iam/infrastructure/persistence/schema/00024_iam_tenant_purge_from_catalog.sql
+-- Tenant hard delete purges every iam table that carries a tenant_uuid column, found in the catalog the same
+-- way offboarding verification finds them, so a new tenant-scoped table is covered without redefining this
+-- function. Tables go before the tables they reference. The superadmin's provisioning runs and offboarding
+-- requests are kept: they are records about the tenant, and runs lose their tenant reference with the row.
+CREATE OR REPLACE FUNCTION iam.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
+AS $$
+DECLARE
+  v_keep oid[] := ARRAY[
+    'iam.superadmin_tenant_provisioning_runs'::regclass::oid,
+    'iam.superadmin_tenant_offboarding_requests'::regclass::oid
+  ];
+  v_pending oid[];
+  v_table oid;
+  v_name text;
+  v_progress boolean;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+  IF EXISTS (SELECT 1 FROM iam.tenants WHERE id = p_tenant_uuid AND is_active) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_ACTIVE',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (SELECT id FROM iam.role_definitions WHERE tenant_uuid = p_tenant_uuid);
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.role_authz_capabilities', v_count);
+
+  SELECT COALESCE(array_agg(c.oid ORDER BY c.relname), '{}'::oid[]) INTO v_pending
+  FROM pg_class c
+  JOIN pg_attribute a ON a.attrelid = c.oid AND a.attname = 'tenant_uuid' AND NOT a.attisdropped
+  WHERE c.relnamespace = 'iam'::regnamespace
+    AND c.relkind IN ('r', 'p')
+    AND NOT c.relispartition
+    AND c.oid <> ALL (v_keep);
+
+  WHILE cardinality(v_pending) > 0 LOOP
+    v_progress := false;
+    FOREACH v_table IN ARRAY v_pending LOOP
+      CONTINUE WHEN EXISTS (
+        SELECT 1
+        FROM pg_constraint fk
+        WHERE fk.contype = 'f'
+          AND fk.confrelid = v_table
+          AND fk.conrelid <> v_table
+          AND fk.conrelid = ANY (v_pending)
+      );
+      SELECT format('iam.%I', relname) INTO v_name FROM pg_class WHERE oid = v_table;
+      EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_name) USING p_tenant_uuid;
+      GET DIAGNOSTICS v_count = ROW_COUNT;
+      v_counts := v_counts || jsonb_build_object(v_name, v_count);
+      v_pending := array_remove(v_pending, v_table);
+      v_progress := true;
+    END LOOP;
+    IF NOT v_progress THEN
       RAISE EXCEPTION USING
-        MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
-        DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
+        MESSAGE = 'IAM_TENANT_PURGE_FK_CYCLE',
+        DETAIL = format('tables=%s', v_pending::regclass[]);
     END IF;
+  END LOOP;
 
-    IF v_data_source_type = 'DICT' THEN
-      IF v_field_value = 'null'::jsonb THEN
-        v_sql := format(
-          'UPDATE orgunit.org_unit_versions
-             SET %1$I = $4::%2$s,
-                 ext_labels_snapshot = COALESCE(ext_labels_snapshot, ''{}''::jsonb) - $5::text,
-                 last_event_id = $6::bigint
-           WHERE tenant_uuid = $1::uuid
-             AND org_node_key = $2::char(8)
-             AND lower(validity) >= $3::date',
-          v_physical_col,
-          v_cast_type
-        );
-        EXECUTE v_sql USING p_tenant_uuid, p_org_node_key, p_effective_date, v_value_text, v_field_key, p_event_db_id;
-      ELSE
-        v_sql := format(
-          'UPDATE orgunit.org_unit_versions
-             SET %1$I = $4::%2$s,
-                 ext_labels_snapshot = COALESCE(ext_labels_snapshot, ''{}''::jsonb)
-                   || jsonb_build_object($5::text, to_jsonb($6::text)),
-                 last_event_id = $7::bigint
-           WHERE tenant_uuid = $1::uuid
-             AND org_node_key = $2::char(8)
-             AND lower(validity) >= $3::date',
-          v_physical_col,
-          v_cast_type
-        );
-        EXECUTE v_sql USING p_tenant_uuid, p_org_node_key, p_effective_date, v_value_text, v_field_key, v_label_text, p_event_db_id;
-      END IF;
-    ELSE
-      v_sql := format(
-        'UPDATE orgunit.org_unit_versions
-           SET %1$I = $4::%2$s,
-               last_event_id = $5::bigint
-         WHERE tenant_uuid = $1::uuid
-           AND org_node_key = $2::char(8)
-           AND lower(validity) >= $3::date',
-        v_physical_col,
-        v_cast_type
-      );
-      EXECUTE v_sql USING p_tenant_uuid, p_org_node_key, p_effective_date, v_value_text, p_event_db_id;
-    END IF;
-  END LOOP;
+  DELETE FROM iam.tenants WHERE id = p_tenant_uuid;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.tenants', v_count);
+
+  RETURN v_counts;
 END;
 $$;
 
-CREATE OR REPLACE FUNCTION orgunit.split_org_unit_version_at(
-  p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_effective_date date,
-  p_event_db_id bigint
+-- end: modules/iam/infrastructure/persistence/schema/00024_iam_tenant_purge_from_catalog.sql
+
+-- begin: modules/iam/infrastructure/persistence/schema/00025_iam_superadmin_audit_tenant_columns.sql
+-- Superadmin audit rows outlive the tenant they are about: the target tenant is a plain column (the FK used
+-- to null it when the tenant was hard-deleted) and its name is captured when the row is written.
+ALTER TABLE iam.superadmin_audit_logs
+  DROP CONSTRAINT IF EXISTS superadmin_audit_logs_target_tenant_uuid_fkey;
+ALTER TABLE iam.superadmin_audit_logs
+  ADD COLUMN IF NOT EXISTS target_tenant_name text NOT NULL DEFAULT '';
+
+-- Rows written before the change: recover targets nulled by a delete from the payload, then names from
+-- the live tenant or, for deleted tenants, the offboarding record.
+UPDATE iam.superadmin_audit_logs
+SET target_tenant_uuid = (payload->>'tenant_id')::uuid
+WHERE target_tenant_uuid IS NULL
+  AND payload->>'tenant_id' ~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$';
+UPDATE iam.superadmin_audit_logs l
+SET target_tenant_name = COALESCE(
+  (SELECT t.name FROM iam.tenants t WHERE t.id = l.target_tenant_uuid),
+  (SELECT r.tenant_name FROM iam.superadmin_tenant_offboarding_requests r
+   WHERE r.tenant_uuid = l.target_tenant_uuid ORDER BY r.id DESC LIMIT 1),
+  ''
 )
-RETURNS void
+WHERE l.target_tenant_uuid IS NOT NULL;
+
+-- end: modules/iam/infrastructure/persistence/schema/00025_iam_superadmin_audit_tenant_columns.sql
+
+-- begin: modules/iam/infrastructure/persistence/schema/00026_iam_superadmin_tenant_provisioning_claim.sql
+-- A provisioning run is claimed on its status row for the duration of one runner instead of holding a
+-- transaction open; an expired claim can be taken over by a retry.
+ALTER TABLE iam.superadmin_tenant_provisioning_runs
+  ADD COLUMN IF NOT EXISTS claim_token text NULL,
+  ADD COLUMN IF NOT EXISTS claimed_until timestamptz NULL;
+
+-- The seed_builtin_roles step verifies the built-in roles after seeding them.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT ON iam.role_definitions TO superadmin_runtime';
+  END IF;
+END
+$$;
+
+-- end: modules/iam/infrastructure/persistence/schema/00026_iam_superadmin_tenant_provisioning_claim.sql
+
+-- begin: modules/iam/infrastructure/persistence/schema/00027_iam_webhook_dict_event_outbox.sql
+-- Dict-level events (iam.dict_events, written by iam.submit_dict_event) feed the same webhook outbox as
+-- dict value events.
+CREATE OR REPLACE FUNCTION iam.enqueue_dict_webhook_event()
+RETURNS trigger
 LANGUAGE plpgsql
 AS $$
 DECLARE
-  v_row orgunit.org_unit_versions%ROWTYPE;
+  v_event_type text;
 BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+  v_event_type := CASE NEW.event_type
+    WHEN 'DICT_CREATED' THEN 'dict.created'
+    WHEN 'DICT_DISABLED' THEN 'dict.disabled'
+    ELSE 'dict.changed'
+  END;
 
-  IF p_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  PERFORM iam.enqueue_webhook_event(
+    NEW.tenant_uuid,
+    'dict',
+    NEW.event_uuid,
+    v_event_type,
+    jsonb_build_object(
+      'dict_code', NEW.dict_code,
+      'effective_day', NEW.effective_day,
+      'source_event_type', NEW.event_type,
+      'request_id', NEW.request_id,
+      'before', NEW.before_snapshot,
+      'after', NEW.after_snapshot
+    )
+  );
+  RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS dict_events_enqueue_webhook ON iam.dict_events;
+CREATE TRIGGER dict_events_enqueue_webhook
+AFTER INSERT ON iam.dict_events
+FOR EACH ROW
+EXECUTE FUNCTION iam.enqueue_dict_webhook_event();
+
+-- end: modules/iam/infrastructure/persistence/schema/00027_iam_webhook_dict_event_outbox.sql
+
+-- begin: modules/orgunit/infrastructure/persistence/schema/00001_orgunit_schema.sql
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+CREATE EXTENSION IF NOT EXISTS ltree;
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE SCHEMA IF NOT EXISTS orgunit;
+
+CREATE OR REPLACE FUNCTION orgunit.global_tenant_id()
+RETURNS uuid
+LANGUAGE sql
+IMMUTABLE
+AS $$
+  SELECT '00000000-0000-0000-0000-000000000000'::uuid;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.assert_current_tenant(p_tenant_uuid uuid)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_ctx_raw text;
+  v_ctx_tenant uuid;
+BEGIN
+  IF p_tenant_uuid IS NULL THEN
+    RAISE EXCEPTION USING
+      ERRCODE = 'P0001',
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = 'tenant_uuid is required';
   END IF;
-  IF p_effective_date IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
+
+  v_ctx_raw := current_setting('app.current_tenant', true);
+  IF v_ctx_raw IS NULL OR btrim(v_ctx_raw) = '' THEN
+    RAISE EXCEPTION USING
+      ERRCODE = 'P0001',
+      MESSAGE = 'RLS_TENANT_CONTEXT_MISSING',
+      DETAIL = 'app.current_tenant is required';
   END IF;
 
-  SELECT * INTO v_row
-  FROM orgunit.org_unit_versions
-  WHERE tenant_uuid = p_tenant_uuid
-    AND org_node_key = p_org_node_key
-    AND validity @> p_effective_date
-    AND lower(validity) < p_effective_date
-  ORDER BY lower(validity) DESC
-  LIMIT 1
-  FOR UPDATE;
+  BEGIN
+    v_ctx_tenant := v_ctx_raw::uuid;
+  EXCEPTION
+    WHEN invalid_text_representation THEN
+      RAISE EXCEPTION USING
+        ERRCODE = 'P0001',
+        MESSAGE = 'RLS_TENANT_CONTEXT_INVALID',
+        DETAIL = format('app.current_tenant=%s', v_ctx_raw);
+  END;
 
-  IF NOT FOUND THEN
-    RETURN;
+  IF v_ctx_tenant <> p_tenant_uuid THEN
+    RAISE EXCEPTION USING
+      ERRCODE = 'P0001',
+      MESSAGE = 'RLS_TENANT_CONTEXT_MISMATCH',
+      DETAIL = format('ctx=%s arg=%s', v_ctx_tenant, p_tenant_uuid);
   END IF;
+END;
+$$;
 
-  UPDATE orgunit.org_unit_versions
-  SET validity = daterange(lower(validity), p_effective_date, '[)')
-  WHERE id = v_row.id;
+-- end: modules/orgunit/infrastructure/persistence/schema/00001_orgunit_schema.sql
 
-  INSERT INTO orgunit.org_unit_versions (
-    tenant_uuid,
-    org_node_key,
-    parent_org_node_key,
-    node_path,
-    validity,
-    name,
-    full_name_path,
-    status,
-    is_business_unit,
-    manager_uuid,
-    ext_str_01,
-    ext_str_02,
-    ext_str_03,
-    ext_str_04,
-    ext_str_05,
-    ext_str_06,
-    ext_str_07,
-    ext_str_08,
-    ext_str_09,
-    ext_str_10,
-    ext_str_11,
-    ext_str_12,
-    ext_str_13,
-    ext_str_14,
-    ext_str_15,
-    ext_str_16,
-    ext_str_17,
-    ext_str_18,
-    ext_str_19,
-    ext_str_20,
-    ext_str_21,
-    ext_str_22,
-    ext_str_23,
-    ext_str_24,
-    ext_str_25,
-    ext_str_26,
-    ext_str_27,
-    ext_str_28,
-    ext_str_29,
-    ext_str_30,
-    ext_str_31,
-    ext_str_32,
-    ext_str_33,
-    ext_str_34,
-    ext_str_35,
-    ext_str_36,
-    ext_str_37,
-    ext_str_38,
-    ext_str_39,
-    ext_str_40,
-    ext_str_41,
-    ext_str_42,
-    ext_str_43,
-    ext_str_44,
-    ext_str_45,
-    ext_str_46,
-    ext_str_47,
-    ext_str_48,
-    ext_str_49,
-    ext_str_50,
-    ext_str_51,
-    ext_str_52,
-    ext_str_53,
-    ext_str_54,
-    ext_str_55,
-    ext_str_56,
-    ext_str_57,
-    ext_str_58,
-    ext_str_59,
-    ext_str_60,
-    ext_str_61,
-    ext_str_62,
-    ext_str_63,
-    ext_str_64,
-    ext_str_65,
-    ext_str_66,
-    ext_str_67,
-    ext_str_68,
-    ext_str_69,
-    ext_str_70,
-    ext_int_01,
-    ext_int_02,
-    ext_int_03,
-    ext_int_04,
-    ext_int_05,
-    ext_int_06,
-    ext_int_07,
-    ext_int_08,
-    ext_int_09,
-    ext_int_10,
-    ext_int_11,
-    ext_int_12,
-    ext_int_13,
-    ext_int_14,
-    ext_int_15,
-    ext_uuid_01,
-    ext_uuid_02,
-    ext_uuid_03,
-    ext_uuid_04,
-    ext_uuid_05,
-    ext_uuid_06,
-    ext_uuid_07,
-    ext_uuid_08,
-    ext_uuid_09,
-    ext_uuid_10,
-    ext_bool_01,
-    ext_bool_02,
-    ext_bool_03,
-    ext_bool_04,
-    ext_bool_05,
-    ext_bool_06,
-    ext_bool_07,
-    ext_bool_08,
-    ext_bool_09,
-    ext_bool_10,
-    ext_bool_11,
-    ext_bool_12,
-    ext_bool_13,
-    ext_bool_14,
-    ext_bool_15,
-    ext_date_01,
-    ext_date_02,
-    ext_date_03,
-    ext_date_04,
-    ext_date_05,
-    ext_date_06,
-    ext_date_07,
-    ext_date_08,
-    ext_date_09,
-    ext_date_10,
-    ext_date_11,
-    ext_date_12,
-    ext_date_13,
-    ext_date_14,
-    ext_date_15,
-    ext_num_01,
-    ext_num_02,
-    ext_num_03,
-    ext_num_04,
-    ext_num_05,
-    ext_num_06,
-    ext_num_07,
-    ext_num_08,
-    ext_num_09,
-    ext_num_10,
-    ext_labels_snapshot,
-    last_event_id
-  )
-  VALUES (
-    v_row.tenant_uuid,
-    v_row.org_node_key,
-    v_row.parent_org_node_key,
-    v_row.node_path,
-    daterange(p_effective_date, upper(v_row.validity), '[)'),
-    v_row.name,
-    v_row.full_name_path,
-    v_row.status,
-    v_row.is_business_unit,
-    v_row.manager_uuid,
-    v_row.ext_str_01,
-    v_row.ext_str_02,
-    v_row.ext_str_03,
-    v_row.ext_str_04,
-    v_row.ext_str_05,
-    v_row.ext_str_06,
-    v_row.ext_str_07,
-    v_row.ext_str_08,
-    v_row.ext_str_09,
-    v_row.ext_str_10,
-    v_row.ext_str_11,
-    v_row.ext_str_12,
-    v_row.ext_str_13,
-    v_row.ext_str_14,
-    v_row.ext_str_15,
-    v_row.ext_str_16,
-    v_row.ext_str_17,
-    v_row.ext_str_18,
-    v_row.ext_str_19,
-    v_row.ext_str_20,
-    v_row.ext_str_21,
-    v_row.ext_str_22,
-    v_row.ext_str_23,
-    v_row.ext_str_24,
-    v_row.ext_str_25,
-    v_row.ext_str_26,
-    v_row.ext_str_27,
-    v_row.ext_str_28,
-    v_row.ext_str_29,
-    v_row.ext_str_30,
-    v_row.ext_str_31,
-    v_row.ext_str_32,
-    v_row.ext_str_33,
-    v_row.ext_str_34,
-    v_row.ext_str_35,
-    v_row.ext_str_36,
-    v_row.ext_str_37,
-    v_row.ext_str_38,
-    v_row.ext_str_39,
-    v_row.ext_str_40,
-    v_row.ext_str_41,
-    v_row.ext_str_42,
-    v_row.ext_str_43,
-    v_row.ext_str_44,
-    v_row.ext_str_45,
-    v_row.ext_str_46,
-    v_row.ext_str_47,
-    v_row.ext_str_48,
-    v_row.ext_str_49,
-    v_row.ext_str_50,
-    v_row.ext_str_51,
-    v_row.ext_str_52,
-    v_row.ext_str_53,
-    v_row.ext_str_54,
-    v_row.ext_str_55,
-    v_row.ext_str_56,
-    v_row.ext_str_57,
-    v_row.ext_str_58,
-    v_row.ext_str_59,
-    v_row.ext_str_60,
-    v_row.ext_str_61,
-    v_row.ext_str_62,
-    v_row.ext_str_63,
-    v_row.ext_str_64,
-    v_row.ext_str_65,
-    v_row.ext_str_66,
-    v_row.ext_str_67,
-    v_row.ext_str_68,
-    v_row.ext_str_69,
-    v_row.ext_str_70,
-    v_row.ext_int_01,
-    v_row.ext_int_02,
-    v_row.ext_int_03,
-    v_row.ext_int_04,
-    v_row.ext_int_05,
-    v_row.ext_int_06,
-    v_row.ext_int_07,
-    v_row.ext_int_08,
-    v_row.ext_int_09,
-    v_row.ext_int_10,
-    v_row.ext_int_11,
-    v_row.ext_int_12,
-    v_row.ext_int_13,
-    v_row.ext_int_14,
-    v_row.ext_int_15,
-    v_row.ext_uuid_01,
-    v_row.ext_uuid_02,
-    v_row.ext_uuid_03,
-    v_row.ext_uuid_04,
-    v_row.ext_uuid_05,
-    v_row.ext_uuid_06,
-    v_row.ext_uuid_07,
-    v_row.ext_uuid_08,
-    v_row.ext_uuid_09,
-    v_row.ext_uuid_10,
-    v_row.ext_bool_01,
-    v_row.ext_bool_02,
-    v_row.ext_bool_03,
-    v_row.ext_bool_04,
-    v_row.ext_bool_05,
-    v_row.ext_bool_06,
-    v_row.ext_bool_07,
-    v_row.ext_bool_08,
-    v_row.ext_bool_09,
-    v_row.ext_bool_10,
-    v_row.ext_bool_11,
-    v_row.ext_bool_12,
-    v_row.ext_bool_13,
-    v_row.ext_bool_14,
-    v_row.ext_bool_15,
-    v_row.ext_date_01,
-    v_row.ext_date_02,
-    v_row.ext_date_03,
-    v_row.ext_date_04,
-    v_row.ext_date_05,
-    v_row.ext_date_06,
-    v_row.ext_date_07,
-    v_row.ext_date_08,
-    v_row.ext_date_09,
-    v_row.ext_date_10,
-    v_row.ext_date_11,
-    v_row.ext_date_12,
-    v_row.ext_date_13,
-    v_row.ext_date_14,
-    v_row.ext_date_15,
-    v_row.ext_num_01,
-    v_row.ext_num_02,
-    v_row.ext_num_03,
-    v_row.ext_num_04,
-    v_row.ext_num_05,
-    v_row.ext_num_06,
-    v_row.ext_num_07,
-    v_row.ext_num_08,
-    v_row.ext_num_09,
-    v_row.ext_num_10,
-    v_row.ext_labels_snapshot,
-    p_event_db_id
-  );
-END;
-$$;
+-- begin: modules/orgunit/infrastructure/persistence/schema/00023_orgunit_org_node_key_schema.sql
+DROP VIEW IF EXISTS orgunit.org_events_effective CASCADE;
 
-CREATE OR REPLACE FUNCTION orgunit.apply_create_logic(
-  p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_org_code text,
-  p_parent_org_node_key char(8),
-  p_effective_date date,
-  p_name text,
-  p_manager_uuid uuid,
-  p_is_business_unit boolean,
-  p_event_db_id bigint,
-  p_status text DEFAULT 'active'
-)
-RETURNS void
-LANGUAGE plpgsql
-AS $$
-DECLARE
-  v_parent_path ltree;
-  v_node_path ltree;
-  v_root_org_node_key char(8);
-  v_is_business_unit boolean;
-  v_org_code text;
-  v_status text;
-BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+DROP TABLE IF EXISTS orgunit.org_id_allocators CASCADE;
+DROP TABLE IF EXISTS orgunit.org_unit_codes CASCADE;
+DROP TABLE IF EXISTS orgunit.org_unit_versions CASCADE;
+DROP TABLE IF EXISTS orgunit.org_events CASCADE;
+DROP TABLE IF EXISTS orgunit.org_trees CASCADE;
+DROP TABLE IF EXISTS orgunit.org_node_key_registry CASCADE;
+DROP SEQUENCE IF EXISTS orgunit.org_node_key_seq;
 
-  IF p_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
-  END IF;
-  IF p_effective_date IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
-  END IF;
-  IF p_name IS NULL OR btrim(p_name) = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'name is required';
-  END IF;
+DROP FUNCTION IF EXISTS orgunit.org_path_ids(ltree);
+DROP FUNCTION IF EXISTS orgunit.org_ltree_label(int);
+DROP FUNCTION IF EXISTS orgunit.allocate_org_id(uuid);
 
-  v_status := lower(btrim(COALESCE(p_status, 'active')));
-  IF v_status IN ('enabled', '有效') THEN
-    v_status := 'active';
-  ELSIF v_status IN ('inactive', '无效') THEN
-    v_status := 'disabled';
-  END IF;
-  IF v_status NOT IN ('active', 'disabled') THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_INVALID_ARGUMENT',
-      DETAIL = format('status=%s', p_status);
-  END IF;
+CREATE OR REPLACE FUNCTION orgunit.is_valid_org_node_key(p_value text)
+RETURNS boolean
+LANGUAGE sql
+IMMUTABLE
+AS $$
+  SELECT btrim(COALESCE(p_value, '')) ~ '^[ABCDEFGHJKLMNPQRSTUVWXYZ][ABCDEFGHJKLMNPQRSTUVWXYZ23456789]{7}$';
+$$;
 
-  IF EXISTS (
-    SELECT 1
-    FROM orgunit.org_unit_versions
-    WHERE tenant_uuid = p_tenant_uuid AND org_node_key = p_org_node_key
-    LIMIT 1
-  ) THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_ALREADY_EXISTS', DETAIL = format('org_node_key=%s', p_org_node_key);
-  END IF;
+CREATE OR REPLACE FUNCTION orgunit.org_ltree_label(p_org_node_key text)
+RETURNS text
+LANGUAGE sql
+IMMUTABLE
+AS $$
+  SELECT btrim(p_org_node_key);
+$$;
 
-  IF p_parent_org_node_key IS NULL THEN
-    SELECT t.root_org_node_key INTO v_root_org_node_key
-    FROM orgunit.org_trees t
-    WHERE t.tenant_uuid = p_tenant_uuid
-    FOR UPDATE;
-
-    IF v_root_org_node_key IS NOT NULL THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_ROOT_ALREADY_EXISTS',
-        DETAIL = format('root_org_node_key=%s', v_root_org_node_key);
-    END IF;
-
-    INSERT INTO orgunit.org_trees (tenant_uuid, root_org_node_key)
-    VALUES (p_tenant_uuid, p_org_node_key);
-
-    v_node_path := text2ltree(orgunit.org_ltree_label(p_org_node_key));
-    IF p_is_business_unit IS NOT NULL AND p_is_business_unit = false THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_ROOT_BUSINESS_UNIT_REQUIRED',
-        DETAIL = format('org_node_key=%s', p_org_node_key);
-    END IF;
-    v_is_business_unit := true;
-  ELSE
-    SELECT t.root_org_node_key INTO v_root_org_node_key
-    FROM orgunit.org_trees t
-    WHERE t.tenant_uuid = p_tenant_uuid;
-
-    IF v_root_org_node_key IS NULL THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_TREE_NOT_INITIALIZED',
-        DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
-    END IF;
-
-    SELECT v.node_path INTO v_parent_path
-    FROM orgunit.org_unit_versions v
-    WHERE v.tenant_uuid = p_tenant_uuid
-      AND v.org_node_key = p_parent_org_node_key
-      AND v.status = 'active'
-      AND v.validity @> p_effective_date
-    LIMIT 1;
-
-    IF v_parent_path IS NULL THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_PARENT_NOT_FOUND_AS_OF',
-        DETAIL = format('parent_org_node_key=%s as_of=%s', p_parent_org_node_key, p_effective_date);
-    END IF;
-
-    v_node_path := v_parent_path || text2ltree(orgunit.org_ltree_label(p_org_node_key));
-    v_is_business_unit := COALESCE(p_is_business_unit, false);
-  END IF;
-
-  v_org_code := NULLIF(p_org_code, '');
-  IF v_org_code IS NOT NULL THEN
-    v_org_code := upper(v_org_code);
-    INSERT INTO orgunit.org_unit_codes (tenant_uuid, org_node_key, org_code)
-    VALUES (p_tenant_uuid, p_org_node_key, v_org_code);
-  END IF;
-
-  INSERT INTO orgunit.org_unit_versions (
-    tenant_uuid,
-    org_node_key,
-    parent_org_node_key,
-    node_path,
-    validity,
-    name,
-    full_name_path,
-    status,
-    is_business_unit,
-    manager_uuid,
-    last_event_id
-  )
-  VALUES (
-    p_tenant_uuid,
-    p_org_node_key,
-    p_parent_org_node_key,
-    v_node_path,
-    daterange(p_effective_date, NULL, '[)'),
-    p_name,
-    p_name,
-    v_status,
-    v_is_business_unit,
-    p_manager_uuid,
-    p_event_db_id
-  );
-END;
+CREATE OR REPLACE FUNCTION orgunit.org_path_node_keys(p_path ltree)
+RETURNS text[]
+LANGUAGE sql
+IMMUTABLE
+AS $$
+  SELECT array_agg(t.part ORDER BY t.ord)
+  FROM unnest(string_to_array(p_path::text, '.')) WITH ORDINALITY AS t(part, ord);
 $$;
 
-CREATE OR REPLACE FUNCTION orgunit.apply_move_logic(
-  p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_new_parent_org_node_key char(8),
-  p_effective_date date,
-  p_event_db_id bigint
+CREATE OR REPLACE FUNCTION orgunit.is_org_event_snapshot_presence_valid(
+  p_event_type text,
+  p_before_snapshot jsonb,
+  p_after_snapshot jsonb,
+  p_rescind_outcome text
 )
-RETURNS void
-LANGUAGE plpgsql
+RETURNS boolean
+LANGUAGE sql
+IMMUTABLE
 AS $$
-DECLARE
-  v_root_org_node_key char(8);
-  v_old_path ltree;
-  v_new_parent_path ltree;
-  v_new_prefix ltree;
-  v_old_level int;
-BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
-
-  IF p_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
-  END IF;
-  IF p_new_parent_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'new_parent_org_node_key is required';
-  END IF;
-  IF p_effective_date IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
-  END IF;
+  SELECT CASE
+    WHEN p_event_type = 'CREATE'
+      THEN p_after_snapshot IS NOT NULL
 
-  SELECT t.root_org_node_key INTO v_root_org_node_key
-  FROM orgunit.org_trees t
-  WHERE t.tenant_uuid = p_tenant_uuid;
+    WHEN p_event_type IN ('UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT','CORRECT_EVENT','CORRECT_STATUS')
+      THEN p_before_snapshot IS NOT NULL AND p_after_snapshot IS NOT NULL
 
-  IF v_root_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_TREE_NOT_INITIALIZED',
-      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
-  END IF;
-  IF v_root_org_node_key = p_org_node_key THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_ROOT_CANNOT_BE_MOVED',
-      DETAIL = format('root_org_node_key=%s', v_root_org_node_key);
-  END IF;
+    WHEN p_event_type IN ('RESCIND_EVENT','RESCIND_ORG')
+      THEN p_before_snapshot IS NOT NULL
+           AND (
+             (p_rescind_outcome = 'ABSENT' AND p_after_snapshot IS NULL)
+             OR (p_rescind_outcome = 'PRESENT' AND p_after_snapshot IS NOT NULL)
+           )
 
-  SELECT v.node_path INTO v_old_path
-  FROM orgunit.org_unit_versions v
-  WHERE v.tenant_uuid = p_tenant_uuid
-    AND v.org_node_key = p_org_node_key
-    AND v.validity @> p_effective_date
-  ORDER BY lower(v.validity) DESC
-  LIMIT 1
-  FOR UPDATE;
+    ELSE true
+  END;
+$$;
 
-  IF v_old_path IS NULL THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_NOT_FOUND_AS_OF',
-      DETAIL = format('org_node_key=%s as_of=%s', p_org_node_key, p_effective_date);
-  END IF;
+CREATE OR REPLACE FUNCTION orgunit.is_orgunit_snapshot_complete(p_snapshot jsonb)
+RETURNS boolean
+LANGUAGE sql
+IMMUTABLE
+AS $$
+  SELECT p_snapshot IS NOT NULL
+    AND jsonb_typeof(p_snapshot) = 'object'
+    AND p_snapshot ?& ARRAY[
+      'org_node_key',
+      'name',
+      'status',
+      'parent_org_node_key',
+      'node_path',
+      'validity',
+      'full_name_path',
+      'is_business_unit'
+    ];
+$$;
 
-  SELECT v.node_path INTO v_new_parent_path
-  FROM orgunit.org_unit_versions v
-  WHERE v.tenant_uuid = p_tenant_uuid
-    AND v.org_node_key = p_new_parent_org_node_key
-    AND v.validity @> p_effective_date
-  LIMIT 1;
+CREATE OR REPLACE FUNCTION orgunit.is_org_event_snapshot_content_valid(
+  p_event_type text,
+  p_before_snapshot jsonb,
+  p_after_snapshot jsonb,
+  p_rescind_outcome text
+)
+RETURNS boolean
+LANGUAGE sql
+IMMUTABLE
+AS $$
+  SELECT CASE
+    WHEN p_event_type IN ('RESCIND_EVENT','RESCIND_ORG')
+      THEN orgunit.is_orgunit_snapshot_complete(p_before_snapshot)
+           AND (
+             p_rescind_outcome = 'ABSENT'
+             OR (
+               p_rescind_outcome = 'PRESENT'
+               AND orgunit.is_orgunit_snapshot_complete(p_after_snapshot)
+             )
+           )
+    ELSE true
+  END;
+$$;
 
-  IF v_new_parent_path IS NULL THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_PARENT_NOT_FOUND_AS_OF',
-      DETAIL = format('parent_org_node_key=%s as_of=%s', p_new_parent_org_node_key, p_effective_date);
-  END IF;
+CREATE SEQUENCE IF NOT EXISTS orgunit.org_node_key_seq
+  AS bigint
+  START WITH 1
+  MINVALUE 1
+  NO MAXVALUE
+  CACHE 1;
 
-  IF v_new_parent_path <@ v_old_path THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_CYCLE_MOVE',
-      DETAIL = format('cycle move: org_node_key=%s new_parent_org_node_key=%s', p_org_node_key, p_new_parent_org_node_key);
-  END IF;
+CREATE TABLE IF NOT EXISTS orgunit.org_node_key_registry (
+  org_node_key char(8) PRIMARY KEY,
+  seq bigint NOT NULL UNIQUE,
+  tenant_uuid uuid NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT org_node_key_registry_key_format_check CHECK (
+    orgunit.is_valid_org_node_key(btrim(org_node_key::text))
+  )
+);
 
-  v_new_prefix := v_new_parent_path || text2ltree(orgunit.org_ltree_label(p_org_node_key));
-  v_old_level := nlevel(v_old_path);
+CREATE TABLE IF NOT EXISTS orgunit.org_trees (
+  tenant_uuid uuid NOT NULL,
+  root_org_node_key char(8) NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (tenant_uuid),
+  CONSTRAINT org_trees_root_org_node_key_format_check CHECK (
+    orgunit.is_valid_org_node_key(btrim(root_org_node_key::text))
+  )
+);
 
-  WITH split AS (
-    SELECT *
-    FROM orgunit.org_unit_versions
-    WHERE tenant_uuid = p_tenant_uuid
-      AND node_path <@ v_old_path
-      AND validity @> p_effective_date
-      AND lower(validity) < p_effective_date
+CREATE TABLE IF NOT EXISTS orgunit.org_events (
+  id bigserial PRIMARY KEY,
+  event_uuid uuid NOT NULL,
+  tenant_uuid uuid NOT NULL,
+  org_node_key char(8) NOT NULL,
+  event_type text NOT NULL,
+  effective_date date NOT NULL,
+  payload jsonb NOT NULL DEFAULT '{}'::jsonb,
+  request_id text NOT NULL,
+  initiator_uuid uuid NOT NULL,
+  initiator_name text NULL,
+  initiator_employee_id text NULL,
+  reason text NULL,
+  before_snapshot jsonb NULL,
+  after_snapshot jsonb NULL,
+  rescind_outcome text NULL,
+  tx_time timestamptz NOT NULL DEFAULT now(),
+  transaction_time timestamptz NOT NULL DEFAULT now(),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT org_events_org_node_key_format_check CHECK (
+    orgunit.is_valid_org_node_key(btrim(org_node_key::text))
   ),
-  upd AS (
-    UPDATE orgunit.org_unit_versions v
-    SET validity = daterange(lower(v.validity), p_effective_date, '[)')
-    FROM split s
-    WHERE v.id = s.id
-    RETURNING s.*
+  CONSTRAINT org_events_event_type_check CHECK (event_type IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT','CORRECT_EVENT','CORRECT_STATUS','RESCIND_EVENT','RESCIND_ORG')),
+  CONSTRAINT org_events_target_event_uuid_required CHECK (
+    event_type NOT IN ('CORRECT_EVENT','CORRECT_STATUS','RESCIND_EVENT','RESCIND_ORG')
+    OR (
+      payload ? 'target_event_uuid'
+      AND NULLIF(btrim(payload->>'target_event_uuid'), '') IS NOT NULL
+    )
+  ),
+  CONSTRAINT org_events_rescind_payload_required CHECK (
+    event_type NOT IN ('RESCIND_EVENT','RESCIND_ORG')
+    OR (
+      COALESCE(NULLIF(btrim(payload->>'op'), ''), '') = event_type
+      AND COALESCE(NULLIF(btrim(payload->>'reason'), ''), '') <> ''
+      AND COALESCE(NULLIF(btrim(payload->>'target_event_uuid'), ''), '') <> ''
+      AND COALESCE(NULLIF(btrim(payload->>'target_effective_date'), ''), '') = to_char(effective_date, 'YYYY-MM-DD')
+    )
+  ),
+  CONSTRAINT org_events_snapshot_shape_check CHECK (
+    (before_snapshot IS NULL OR jsonb_typeof(before_snapshot) = 'object')
+    AND (after_snapshot IS NULL OR jsonb_typeof(after_snapshot) = 'object')
+  ),
+  CONSTRAINT org_events_rescind_outcome_check CHECK (
+    (
+      event_type NOT IN ('RESCIND_EVENT','RESCIND_ORG')
+      AND rescind_outcome IS NULL
+    )
+    OR (
+      event_type IN ('RESCIND_EVENT','RESCIND_ORG')
+      AND rescind_outcome IN ('PRESENT','ABSENT')
+    )
+  ),
+  CONSTRAINT org_events_snapshot_presence_check CHECK (
+    orgunit.is_org_event_snapshot_presence_valid(
+      event_type,
+      before_snapshot,
+      after_snapshot,
+      rescind_outcome
+    )
+  ),
+  CONSTRAINT org_events_snapshot_content_check CHECK (
+    orgunit.is_org_event_snapshot_content_valid(
+      event_type,
+      before_snapshot,
+      after_snapshot,
+      rescind_outcome
+    )
+  ),
+  CONSTRAINT org_events_request_id_unique UNIQUE (tenant_uuid, request_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS org_events_event_uuid_unique ON orgunit.org_events (event_uuid);
+CREATE INDEX IF NOT EXISTS org_events_tenant_org_effective_idx ON orgunit.org_events (tenant_uuid, org_node_key, effective_date, id);
+CREATE INDEX IF NOT EXISTS org_events_tenant_effective_idx ON orgunit.org_events (tenant_uuid, effective_date, id);
+CREATE INDEX IF NOT EXISTS org_events_tenant_tx_time_idx ON orgunit.org_events (tenant_uuid, tx_time DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS orgunit.org_unit_versions (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL,
+  org_node_key char(8) NOT NULL,
+  parent_org_node_key char(8) NULL,
+  node_path ltree NOT NULL,
+  validity daterange NOT NULL,
+  path_node_keys text[] GENERATED ALWAYS AS (orgunit.org_path_node_keys(node_path)) STORED,
+  name varchar(255) NOT NULL,
+  full_name_path text NOT NULL,
+  status text NOT NULL DEFAULT 'active',
+  is_business_unit boolean NOT NULL DEFAULT false,
+  manager_uuid uuid NULL,
+  last_event_id bigint NOT NULL,
+  CONSTRAINT org_unit_versions_org_node_key_format_check CHECK (
+    orgunit.is_valid_org_node_key(btrim(org_node_key::text))
+  ),
+  CONSTRAINT org_unit_versions_parent_org_node_key_format_check CHECK (
+    parent_org_node_key IS NULL
+    OR orgunit.is_valid_org_node_key(btrim(parent_org_node_key::text))
+  ),
+  CONSTRAINT org_unit_versions_last_event_id_fkey
+    FOREIGN KEY (last_event_id)
+    REFERENCES orgunit.org_events(id)
+    DEFERRABLE INITIALLY DEFERRED,
+  CONSTRAINT org_unit_versions_status_check CHECK (status IN ('active','disabled')),
+  CONSTRAINT org_unit_versions_validity_check CHECK (NOT isempty(validity)),
+  CONSTRAINT org_unit_versions_validity_bounds_check CHECK (lower_inc(validity) AND NOT upper_inc(validity)),
+  CONSTRAINT org_unit_versions_no_overlap
+    EXCLUDE USING gist (
+      tenant_uuid gist_uuid_ops WITH =,
+      org_node_key gist_bpchar_ops WITH =,
+      validity WITH &&
+    )
+);
+
+CREATE INDEX IF NOT EXISTS org_unit_versions_search_gist
+  ON orgunit.org_unit_versions
+  USING gist (tenant_uuid gist_uuid_ops, node_path, validity);
+
+CREATE INDEX IF NOT EXISTS org_unit_versions_active_day_gist
+  ON orgunit.org_unit_versions
+  USING gist (tenant_uuid gist_uuid_ops, validity)
+  WHERE status = 'active';
+
+CREATE INDEX IF NOT EXISTS org_unit_versions_lookup_btree
+  ON orgunit.org_unit_versions (tenant_uuid, org_node_key, lower(validity));
+
+CREATE INDEX IF NOT EXISTS org_unit_versions_path_node_keys_gin
+  ON orgunit.org_unit_versions
+  USING gin (path_node_keys);
+
+CREATE TABLE IF NOT EXISTS orgunit.org_unit_codes (
+  tenant_uuid uuid NOT NULL,
+  org_node_key char(8) NOT NULL,
+  org_code text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (tenant_uuid, org_node_key),
+  CONSTRAINT org_unit_codes_org_node_key_format_check CHECK (
+    orgunit.is_valid_org_node_key(btrim(org_node_key::text))
+  ),
+  CONSTRAINT org_unit_codes_org_code_format CHECK (
+    length(org_code) BETWEEN 1 AND 64
+    AND org_code = upper(org_code)
+    AND org_code ~ E'^[\t\x20-\x7E\u3000-\u303F\uFF01-\uFF60\uFFE0-\uFFEE]{1,64}$'
+    AND org_code !~ E'^[\t\x20\u3000]+$'
+  ),
+  CONSTRAINT org_unit_codes_org_code_unique UNIQUE (tenant_uuid, org_code)
+);
+
+ALTER TABLE orgunit.org_node_key_registry ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.org_node_key_registry FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.org_node_key_registry;
+CREATE POLICY tenant_isolation ON orgunit.org_node_key_registry
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
+
+ALTER TABLE orgunit.org_trees ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.org_trees FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.org_trees;
+CREATE POLICY tenant_isolation ON orgunit.org_trees
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
+
+ALTER TABLE orgunit.org_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.org_events FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.org_events;
+CREATE POLICY tenant_isolation ON orgunit.org_events
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
+
+ALTER TABLE orgunit.org_unit_versions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.org_unit_versions FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.org_unit_versions;
+CREATE POLICY tenant_isolation ON orgunit.org_unit_versions
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
+
+ALTER TABLE orgunit.org_unit_codes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.org_unit_codes FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.org_unit_codes;
+CREATE POLICY tenant_isolation ON orgunit.org_unit_codes
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
+
+-- end: modules/orgunit/infrastructure/persistence/schema/00023_orgunit_org_node_key_schema.sql
+
+-- begin: modules/orgunit/infrastructure/persistence/schema/00024_orgunit_org_node_key_allocator.sql
+CREATE OR REPLACE FUNCTION orgunit.encode_org_node_key(p_seq bigint)
+RETURNS char(8)
+LANGUAGE plpgsql
+IMMUTABLE
+AS $$
+DECLARE
+  v_guard_alphabet constant text := 'ABCDEFGHJKLMNPQRSTUVWXYZ';
+  v_body_alphabet constant text := 'ABCDEFGHJKLMNPQRSTUVWXYZ23456789';
+  v_body_base constant bigint := 32;
+  v_body_width constant integer := 7;
+  v_capacity constant bigint := 34359738368;
+  v_guard_index integer;
+  v_body_value bigint;
+  v_body text := '';
+  v_digit_index integer;
+BEGIN
+  IF p_seq IS NULL OR p_seq <= 0 THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = 'seq must be positive';
+  END IF;
+
+  IF p_seq > 824633720831 THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_NODE_KEY_EXHAUSTED',
+      DETAIL = format('seq=%s', p_seq);
+  END IF;
+
+  v_guard_index := floor(p_seq::numeric / v_capacity)::integer % length(v_guard_alphabet);
+  v_body_value := mod(p_seq, v_capacity);
+
+  FOR i IN REVERSE v_body_width..1 LOOP
+    v_digit_index := mod(v_body_value, v_body_base)::integer;
+    v_body := substr(v_body_alphabet, v_digit_index + 1, 1) || v_body;
+    v_body_value := floor(v_body_value::numeric / v_body_base)::bigint;
+  END LOOP;
+
+  RETURN (substr(v_guard_alphabet, v_guard_index + 1, 1) || v_body)::char(8);
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.decode_org_node_key(p_org_node_key char(8))
+RETURNS bigint
+LANGUAGE plpgsql
+IMMUTABLE
+AS $$
+DECLARE
+  v_key text := btrim(COALESCE(p_org_node_key::text, ''));
+  v_guard_alphabet constant text := 'ABCDEFGHJKLMNPQRSTUVWXYZ';
+  v_body_alphabet constant text := 'ABCDEFGHJKLMNPQRSTUVWXYZ23456789';
+  v_body_base constant bigint := 32;
+  v_capacity constant bigint := 34359738368;
+  v_guard_index integer;
+  v_digit_index integer;
+  v_body_value bigint := 0;
+BEGIN
+  IF NOT orgunit.is_valid_org_node_key(v_key) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = format('org_node_key invalid: %s', COALESCE(v_key, '<null>'));
+  END IF;
+
+  v_guard_index := strpos(v_guard_alphabet, substr(v_key, 1, 1)) - 1;
+  IF v_guard_index < 0 THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = format('org_node_key invalid: %s', v_key);
+  END IF;
+
+  FOR i IN 2..length(v_key) LOOP
+    v_digit_index := strpos(v_body_alphabet, substr(v_key, i, 1)) - 1;
+    IF v_digit_index < 0 THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_INVALID_ARGUMENT',
+        DETAIL = format('org_node_key invalid: %s', v_key);
+    END IF;
+    v_body_value := v_body_value * v_body_base + v_digit_index;
+  END LOOP;
+
+  RETURN v_guard_index::bigint * v_capacity + v_body_value;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.allocate_org_node_key(p_tenant_uuid uuid)
+RETURNS char(8)
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_seq bigint;
+  v_org_node_key char(8);
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_tenant_uuid IS NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = 'tenant_uuid is required';
+  END IF;
+
+  SELECT nextval('orgunit.org_node_key_seq') INTO v_seq;
+  IF v_seq IS NULL OR v_seq <= 0 OR v_seq > 824633720831 THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_NODE_KEY_EXHAUSTED',
+      DETAIL = format('tenant_uuid=%s seq=%s', p_tenant_uuid, COALESCE(v_seq::text, '<null>'));
+  END IF;
+
+  v_org_node_key := orgunit.encode_org_node_key(v_seq);
+
+  INSERT INTO orgunit.org_node_key_registry (
+    org_node_key,
+    seq,
+    tenant_uuid
+  )
+  VALUES (
+    v_org_node_key,
+    v_seq,
+    p_tenant_uuid
+  );
+
+  RETURN v_org_node_key;
+END;
+$$;
+
+-- end: modules/orgunit/infrastructure/persistence/schema/00024_orgunit_org_node_key_allocator.sql
+
+-- begin: modules/orgunit/infrastructure/persistence/schema/00025_orgunit_org_node_key_kernel_privileges.sql
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'orgunit_kernel') THEN
+    CREATE ROLE orgunit_kernel NOLOGIN NOBYPASSRLS;
+  END IF;
+END $$;
+
+GRANT USAGE ON SCHEMA orgunit TO orgunit_kernel;
+GRANT USAGE ON SCHEMA iam TO orgunit_kernel;
+
+ALTER TABLE IF EXISTS orgunit.org_node_key_registry OWNER TO orgunit_kernel;
+ALTER TABLE IF EXISTS orgunit.org_trees OWNER TO orgunit_kernel;
+ALTER TABLE IF EXISTS orgunit.org_events OWNER TO orgunit_kernel;
+ALTER TABLE IF EXISTS orgunit.org_unit_versions OWNER TO orgunit_kernel;
+ALTER TABLE IF EXISTS orgunit.org_unit_codes OWNER TO orgunit_kernel;
+ALTER SEQUENCE IF EXISTS orgunit.org_node_key_seq OWNER TO orgunit_kernel;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE
+  orgunit.org_node_key_registry,
+  orgunit.org_events,
+  orgunit.org_unit_versions,
+  orgunit.org_trees,
+  orgunit.org_unit_codes
+TO orgunit_kernel;
+
+GRANT USAGE, SELECT ON SEQUENCE orgunit.org_node_key_seq TO orgunit_kernel;
+GRANT SELECT ON TABLE iam.principals TO orgunit_kernel;
+
+ALTER FUNCTION orgunit.is_valid_org_node_key(text)
+  OWNER TO orgunit_kernel;
+
+ALTER FUNCTION orgunit.org_ltree_label(text)
+  OWNER TO orgunit_kernel;
+
+ALTER FUNCTION orgunit.org_path_node_keys(ltree)
+  OWNER TO orgunit_kernel;
+
+ALTER FUNCTION orgunit.encode_org_node_key(bigint)
+  OWNER TO orgunit_kernel;
+
+ALTER FUNCTION orgunit.decode_org_node_key(char)
+  OWNER TO orgunit_kernel;
+
+ALTER FUNCTION orgunit.allocate_org_node_key(uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.allocate_org_node_key(uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.allocate_org_node_key(uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+-- end: modules/orgunit/infrastructure/persistence/schema/00025_orgunit_org_node_key_kernel_privileges.sql
+
+-- begin: modules/orgunit/infrastructure/persistence/schema/00026_orgunit_org_node_key_engine.sql
+-- DEV-PLAN-320 P3: org_node_key runtime engine overlay
+CREATE OR REPLACE FUNCTION orgunit.assert_current_tenant(p_tenant_uuid uuid)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+  DECLARE
+  v_ctx_raw text;
+  v_ctx_tenant uuid;
+BEGIN
+  IF p_tenant_uuid IS NULL THEN
+    RAISE EXCEPTION USING
+      ERRCODE = 'P0001',
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = 'tenant_uuid is required';
+  END IF;
+
+  v_ctx_raw := current_setting('app.current_tenant', true);
+  IF v_ctx_raw IS NULL OR btrim(v_ctx_raw) = '' THEN
+    RAISE EXCEPTION USING
+      ERRCODE = 'P0001',
+      MESSAGE = 'RLS_TENANT_CONTEXT_MISSING',
+      DETAIL = 'app.current_tenant is required';
+  END IF;
+
+  BEGIN
+    v_ctx_tenant := v_ctx_raw::uuid;
+  EXCEPTION
+    WHEN invalid_text_representation THEN
+      RAISE EXCEPTION USING
+        ERRCODE = 'P0001',
+        MESSAGE = 'RLS_TENANT_CONTEXT_INVALID',
+        DETAIL = format('app.current_tenant=%s', v_ctx_raw);
+  END;
+
+  IF v_ctx_tenant <> p_tenant_uuid THEN
+    RAISE EXCEPTION USING
+      ERRCODE = 'P0001',
+      MESSAGE = 'RLS_TENANT_MISMATCH',
+      DETAIL = format('tenant_param=%s tenant_ctx=%s', p_tenant_uuid, v_ctx_tenant);
+  END IF;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.fill_org_event_audit_snapshot()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_name text;
+  v_employee text;
+BEGIN
+  IF NEW.tx_time IS NULL THEN
+    NEW.tx_time := COALESCE(NEW.transaction_time, now());
+  END IF;
+
+  IF NULLIF(btrim(COALESCE(NEW.initiator_name, '')), '') IS NOT NULL
+    AND NULLIF(btrim(COALESCE(NEW.initiator_employee_id, '')), '') IS NOT NULL
+  THEN
+    RETURN NEW;
+  END IF;
+
+  IF to_regclass('iam.principals') IS NOT NULL THEN
+    SELECT
+      COALESCE(NULLIF(btrim(p.display_name), ''), NULLIF(btrim(p.email), ''), NEW.initiator_uuid::text),
+      COALESCE(NULLIF(btrim(p.email), ''), NEW.initiator_uuid::text)
+    INTO v_name, v_employee
+    FROM iam.principals p
+    WHERE p.tenant_uuid = NEW.tenant_uuid
+      AND p.id = NEW.initiator_uuid
+    LIMIT 1;
+  END IF;
+
+  NEW.initiator_name := COALESCE(NULLIF(btrim(COALESCE(NEW.initiator_name, '')), ''), v_name, NEW.initiator_uuid::text);
+  NEW.initiator_employee_id := COALESCE(NULLIF(btrim(COALESCE(NEW.initiator_employee_id, '')), ''), v_employee, NEW.initiator_uuid::text);
+
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS org_events_fill_audit_snapshot ON orgunit.org_events;
+CREATE TRIGGER org_events_fill_audit_snapshot
+BEFORE INSERT ON orgunit.org_events
+FOR EACH ROW
+EXECUTE FUNCTION orgunit.fill_org_event_audit_snapshot();
+
+CREATE OR REPLACE FUNCTION orgunit.merge_org_event_payload_with_correction(
+  p_base_payload jsonb,
+  p_correction_payload jsonb
+)
+RETURNS jsonb
+LANGUAGE sql
+IMMUTABLE
+AS $$
+  WITH base_payload AS (
+    SELECT COALESCE(p_base_payload, '{}'::jsonb) AS payload
+  ),
+  correction_patch AS (
+    SELECT COALESCE(p_correction_payload, '{}'::jsonb) - 'effective_date' - 'target_event_uuid' - 'op' AS payload
+  ),
+  parts AS (
+    SELECT
+      (b.payload - 'ext' - 'ext_labels_snapshot') AS base_noext,
+      (c.payload - 'ext' - 'ext_labels_snapshot') AS patch_noext,
+      CASE WHEN jsonb_typeof(b.payload->'ext') = 'object' THEN b.payload->'ext' ELSE '{}'::jsonb END AS base_ext,
+      CASE WHEN jsonb_typeof(c.payload->'ext') = 'object' THEN c.payload->'ext' ELSE '{}'::jsonb END AS patch_ext,
+      CASE WHEN jsonb_typeof(b.payload->'ext_labels_snapshot') = 'object' THEN b.payload->'ext_labels_snapshot' ELSE '{}'::jsonb END AS base_labels,
+      CASE WHEN jsonb_typeof(c.payload->'ext_labels_snapshot') = 'object' THEN c.payload->'ext_labels_snapshot' ELSE '{}'::jsonb END AS patch_labels,
+      (b.payload ? 'ext') OR (c.payload ? 'ext') AS has_ext,
+      (b.payload ? 'ext_labels_snapshot') OR (c.payload ? 'ext_labels_snapshot') AS has_labels
+    FROM base_payload b
+    CROSS JOIN correction_patch c
+  ),
+  merged AS (
+    SELECT
+      (base_noext || patch_noext) AS merged_noext,
+      (base_ext || patch_ext) AS ext_merged,
+      (base_labels || patch_labels) AS labels_merged,
+      has_ext,
+      has_labels
+    FROM parts
+  )
+  SELECT
+    merged_noext
+    || CASE
+         WHEN has_ext
+           THEN jsonb_build_object('ext', ext_merged)
+         ELSE '{}'::jsonb
+       END
+    || CASE
+         WHEN has_labels
+           THEN jsonb_build_object(
+             'ext_labels_snapshot',
+             labels_merged - COALESCE((
+               SELECT array_agg(key)
+               FROM jsonb_each(ext_merged)
+               WHERE value = 'null'::jsonb
+             ), ARRAY[]::text[])
+           )
+         ELSE '{}'::jsonb
+       END
+  FROM merged;
+$$;
+
+CREATE OR REPLACE VIEW orgunit.org_events_effective AS
+WITH correction_events AS (
+  SELECT
+    e.*,
+    (e.payload->>'target_event_uuid')::uuid AS target_event_uuid
+  FROM orgunit.org_events e
+  WHERE e.event_type IN ('CORRECT_EVENT','CORRECT_STATUS','RESCIND_EVENT','RESCIND_ORG')
+    AND e.payload ? 'target_event_uuid'
+),
+latest_corrections AS (
+  SELECT DISTINCT ON (tenant_uuid, target_event_uuid)
+    tenant_uuid,
+    target_event_uuid,
+    event_type AS correction_type,
+    payload AS correction_payload,
+    tx_time,
+    id
+  FROM correction_events
+  ORDER BY tenant_uuid, target_event_uuid, tx_time DESC, id DESC
+),
+latest_effective_date_corrections AS (
+  -- Sticky effective_date: take the latest CORRECT_EVENT that explicitly carries effective_date,
+  -- regardless of later corrections that don't include effective_date.
+  SELECT DISTINCT ON (tenant_uuid, target_event_uuid)
+    tenant_uuid,
+    target_event_uuid,
+    NULLIF(btrim(payload->>'effective_date'), '')::date AS sticky_effective_date,
+    tx_time,
+    id
+  FROM correction_events
+  WHERE event_type = 'CORRECT_EVENT'
+    AND payload ? 'effective_date'
+  ORDER BY tenant_uuid, target_event_uuid, tx_time DESC, id DESC
+)
+SELECT
+  e.id,
+  e.event_uuid,
+  e.tenant_uuid,
+  e.org_node_key,
+  CASE
+    WHEN lc.correction_type = 'CORRECT_STATUS'
+      AND COALESCE(lc.correction_payload->>'target_status', '') = 'active'
+      THEN 'ENABLE'
+    WHEN lc.correction_type = 'CORRECT_STATUS'
+      AND COALESCE(lc.correction_payload->>'target_status', '') = 'disabled'
+      THEN 'DISABLE'
+    ELSE e.event_type
+  END AS event_type,
+  COALESCE(lec.sticky_effective_date, e.effective_date) AS effective_date,
+  CASE
+    WHEN lc.correction_type = 'CORRECT_EVENT'
+      THEN orgunit.merge_org_event_payload_with_correction(e.payload, lc.correction_payload)
+    ELSE e.payload
+  END AS payload,
+  e.request_id,
+  e.initiator_uuid,
+  e.transaction_time,
+  e.created_at
+FROM orgunit.org_events e
+LEFT JOIN latest_corrections lc
+  ON lc.tenant_uuid = e.tenant_uuid
+ AND lc.target_event_uuid = e.event_uuid
+LEFT JOIN latest_effective_date_corrections lec
+  ON lec.tenant_uuid = e.tenant_uuid
+ AND lec.target_event_uuid = e.event_uuid
+WHERE e.event_type IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT')
+  AND COALESCE(lc.correction_type, '') NOT IN ('RESCIND_EVENT', 'RESCIND_ORG');
+
+CREATE OR REPLACE FUNCTION orgunit.is_org_ext_payload_allowed_for_event(
+  p_event_type text,
+  p_target_event_type text
+)
+RETURNS boolean
+LANGUAGE sql
+IMMUTABLE
+AS $$
+  SELECT CASE
+    WHEN p_event_type IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT') THEN true
+    WHEN p_event_type = 'CORRECT_EVENT'
+      THEN p_target_event_type IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT')
+    ELSE false
+  END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.apply_org_event_ext_payload(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_effective_date date,
+  p_event_type text,
+  p_payload jsonb,
+  p_event_db_id bigint,
+  p_target_event_type text DEFAULT NULL
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_payload jsonb;
+  v_ext jsonb;
+  v_labels jsonb;
+  v_has_ext_payload boolean;
+  v_has_ext_fields boolean;
+  v_field_key text;
+  v_label_key text;
+  v_field_value jsonb;
+  v_label_value jsonb;
+  v_label_text text;
+  v_value_text text;
+  v_physical_col text;
+  v_value_type text;
+  v_data_source_type text;
+  v_enabled_on date;
+  v_disabled_on date;
+  v_cast_type text;
+  v_sql text;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+  IF p_effective_date IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
+  END IF;
+  IF p_event_type IS NULL OR btrim(p_event_type) = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'event_type is required';
+  END IF;
+
+  v_payload := COALESCE(p_payload, '{}'::jsonb);
+  v_has_ext_payload := v_payload ? 'ext' OR v_payload ? 'ext_labels_snapshot';
+  IF NOT v_has_ext_payload THEN
+    RETURN;
+  END IF;
+
+  IF NOT orgunit.is_org_ext_payload_allowed_for_event(p_event_type, p_target_event_type) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_EXT_PAYLOAD_NOT_ALLOWED_FOR_EVENT',
+      DETAIL = format('event_type=%s target_event_type=%s', p_event_type, COALESCE(p_target_event_type, 'NULL'));
+  END IF;
+
+  v_ext := COALESCE(v_payload->'ext', '{}'::jsonb);
+  v_labels := COALESCE(v_payload->'ext_labels_snapshot', '{}'::jsonb);
+
+  IF jsonb_typeof(v_ext) <> 'object' OR jsonb_typeof(v_labels) <> 'object' THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_EXT_PAYLOAD_INVALID_SHAPE',
+      DETAIL = 'payload.ext and payload.ext_labels_snapshot must be objects';
+  END IF;
+
+  FOR v_label_key IN
+    SELECT key
+    FROM jsonb_object_keys(v_labels) AS t(key)
+  LOOP
+    IF NOT (v_ext ? v_label_key) THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_EXT_LABEL_SNAPSHOT_NOT_ALLOWED',
+        DETAIL = format('field_key=%s', v_label_key);
+    END IF;
+  END LOOP;
+
+  SELECT EXISTS (
+    SELECT 1
+    FROM jsonb_object_keys(v_ext)
+  ) INTO v_has_ext_fields;
+
+  IF NOT v_has_ext_fields THEN
+    RETURN;
+  END IF;
+
+  IF p_event_db_id IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'event_db_id is required';
+  END IF;
+
+  PERFORM orgunit.split_org_unit_version_at(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_db_id);
+
+  FOR v_field_key, v_field_value IN
+    SELECT key, value
+    FROM jsonb_each(v_ext)
+  LOOP
+    IF v_field_key !~ '^[a-z][a-z0-9_]{0,62}$' THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_EXT_FIELD_NOT_CONFIGURED',
+        DETAIL = format('field_key=%s', v_field_key);
+    END IF;
+
+    SELECT
+      physical_col,
+      value_type,
+      data_source_type,
+      enabled_on,
+      disabled_on
+    INTO v_physical_col, v_value_type, v_data_source_type, v_enabled_on, v_disabled_on
+    FROM orgunit.tenant_field_configs
+    WHERE tenant_uuid = p_tenant_uuid
+      AND field_key = v_field_key
+    LIMIT 1;
+
+    IF NOT FOUND THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_EXT_FIELD_NOT_CONFIGURED',
+        DETAIL = format('field_key=%s', v_field_key);
+    END IF;
+
+    IF NOT (
+      v_enabled_on <= p_effective_date
+      AND (v_disabled_on IS NULL OR p_effective_date < v_disabled_on)
+    ) THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_EXT_FIELD_NOT_ENABLED_AS_OF',
+        DETAIL = format('field_key=%s effective_date=%s', v_field_key, p_effective_date);
+    END IF;
+
+    IF v_physical_col !~ '^ext_(str|int|uuid|bool|date|num)_[0-9]{2}$'
+      OR (v_value_type = 'text' AND v_physical_col NOT LIKE 'ext_str_%')
+      OR (v_value_type = 'int' AND v_physical_col NOT LIKE 'ext_int_%')
+      OR (v_value_type = 'uuid' AND v_physical_col NOT LIKE 'ext_uuid_%')
+      OR (v_value_type = 'bool' AND v_physical_col NOT LIKE 'ext_bool_%')
+      OR (v_value_type = 'date' AND v_physical_col NOT LIKE 'ext_date_%')
+      OR (v_value_type = 'numeric' AND v_physical_col NOT LIKE 'ext_num_%')
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
+        DETAIL = format('field_key=%s physical_col=%s value_type=%s', v_field_key, v_physical_col, v_value_type);
+    END IF;
+
+    IF NOT EXISTS (
+      SELECT 1
+      FROM information_schema.columns c
+      WHERE c.table_schema = 'orgunit'
+        AND c.table_name = 'org_unit_versions'
+        AND c.column_name = v_physical_col
+    ) THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
+        DETAIL = format('field_key=%s physical_col=%s missing_in_org_unit_versions', v_field_key, v_physical_col);
+    END IF;
+
+    v_value_text := NULL;
+    IF v_field_value <> 'null'::jsonb THEN
+      IF v_value_type = 'text' THEN
+        IF jsonb_typeof(v_field_value) <> 'string' THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
+            DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
+        END IF;
+        v_value_text := v_field_value #>> '{}';
+      ELSIF v_value_type = 'int' THEN
+        IF jsonb_typeof(v_field_value) NOT IN ('number', 'string') THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
+            DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
+        END IF;
+        BEGIN
+          v_value_text := ((v_field_value #>> '{}')::int)::text;
+        EXCEPTION
+          WHEN invalid_text_representation OR numeric_value_out_of_range THEN
+            RAISE EXCEPTION USING
+              MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
+              DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
+        END;
+      ELSIF v_value_type = 'uuid' THEN
+        IF jsonb_typeof(v_field_value) <> 'string' THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
+            DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
+        END IF;
+        BEGIN
+          v_value_text := ((v_field_value #>> '{}')::uuid)::text;
+        EXCEPTION
+          WHEN invalid_text_representation THEN
+            RAISE EXCEPTION USING
+              MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
+              DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
+        END;
+      ELSIF v_value_type = 'bool' THEN
+        IF jsonb_typeof(v_field_value) <> 'boolean' THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
+            DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
+        END IF;
+        v_value_text := ((v_field_value #>> '{}')::boolean)::text;
+      ELSIF v_value_type = 'date' THEN
+        IF jsonb_typeof(v_field_value) <> 'string' THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
+            DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
+        END IF;
+        BEGIN
+          v_value_text := ((v_field_value #>> '{}')::date)::text;
+        EXCEPTION
+          WHEN invalid_text_representation OR datetime_field_overflow THEN
+            RAISE EXCEPTION USING
+              MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
+              DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
+        END;
+      ELSIF v_value_type = 'numeric' THEN
+        IF jsonb_typeof(v_field_value) NOT IN ('number', 'string') THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
+            DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
+        END IF;
+        BEGIN
+          v_value_text := ((v_field_value #>> '{}')::numeric)::text;
+        EXCEPTION
+          WHEN invalid_text_representation OR numeric_value_out_of_range THEN
+            RAISE EXCEPTION USING
+              MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
+              DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
+        END;
+      ELSE
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
+          DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
+      END IF;
+    END IF;
+
+    v_label_text := NULL;
+    IF v_data_source_type = 'DICT' THEN
+      IF v_field_value <> 'null'::jsonb THEN
+        IF NOT (v_labels ? v_field_key) THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_EXT_LABEL_SNAPSHOT_REQUIRED',
+            DETAIL = format('field_key=%s', v_field_key);
+        END IF;
+
+        v_label_value := v_labels->v_field_key;
+        IF jsonb_typeof(v_label_value) <> 'string'
+          OR NULLIF(btrim(v_label_value #>> '{}'), '') IS NULL
+        THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_EXT_LABEL_SNAPSHOT_REQUIRED',
+            DETAIL = format('field_key=%s', v_field_key);
+        END IF;
+        v_label_text := btrim(v_label_value #>> '{}');
+      ELSIF v_labels ? v_field_key THEN
+        v_label_value := v_labels->v_field_key;
+        IF jsonb_typeof(v_label_value) <> 'null' THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_EXT_LABEL_SNAPSHOT_REQUIRED',
+            DETAIL = format('field_key=%s', v_field_key);
+        END IF;
+      END IF;
+    ELSIF v_labels ? v_field_key THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_EXT_LABEL_SNAPSHOT_NOT_ALLOWED',
+        DETAIL = format('field_key=%s', v_field_key);
+    END IF;
+
+    v_cast_type := CASE v_value_type
+      WHEN 'text' THEN 'text'
+      WHEN 'int' THEN 'int'
+      WHEN 'uuid' THEN 'uuid'
+      WHEN 'bool' THEN 'boolean'
+      WHEN 'date' THEN 'date'
+      WHEN 'numeric' THEN 'numeric'
+      ELSE NULL
+    END;
+
+    IF v_cast_type IS NULL THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_EXT_FIELD_TYPE_MISMATCH',
+        DETAIL = format('field_key=%s value_type=%s', v_field_key, v_value_type);
+    END IF;
+
+    IF v_data_source_type = 'DICT' THEN
+      IF v_field_value = 'null'::jsonb THEN
+        v_sql := format(
+          'UPDATE orgunit.org_unit_versions
+             SET %1$I = $4::%2$s,
+                 ext_labels_snapshot = COALESCE(ext_labels_snapshot, ''{}''::jsonb) - $5::text,
+                 last_event_id = $6::bigint
+           WHERE tenant_uuid = $1::uuid
+             AND org_node_key = $2::char(8)
+             AND lower(validity) >= $3::date',
+          v_physical_col,
+          v_cast_type
+        );
+        EXECUTE v_sql USING p_tenant_uuid, p_org_node_key, p_effective_date, v_value_text, v_field_key, p_event_db_id;
+      ELSE
+        v_sql := format(
+          'UPDATE orgunit.org_unit_versions
+             SET %1$I = $4::%2$s,
+                 ext_labels_snapshot = COALESCE(ext_labels_snapshot, ''{}''::jsonb)
+                   || jsonb_build_object($5::text, to_jsonb($6::text)),
+                 last_event_id = $7::bigint
+           WHERE tenant_uuid = $1::uuid
+             AND org_node_key = $2::char(8)
+             AND lower(validity) >= $3::date',
+          v_physical_col,
+          v_cast_type
+        );
+        EXECUTE v_sql USING p_tenant_uuid, p_org_node_key, p_effective_date, v_value_text, v_field_key, v_label_text, p_event_db_id;
+      END IF;
+    ELSE
+      v_sql := format(
+        'UPDATE orgunit.org_unit_versions
+           SET %1$I = $4::%2$s,
+               last_event_id = $5::bigint
+         WHERE tenant_uuid = $1::uuid
+           AND org_node_key = $2::char(8)
+           AND lower(validity) >= $3::date',
+        v_physical_col,
+        v_cast_type
+      );
+      EXECUTE v_sql USING p_tenant_uuid, p_org_node_key, p_effective_date, v_value_text, p_event_db_id;
+    END IF;
+  END LOOP;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.split_org_unit_version_at(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_effective_date date,
+  p_event_db_id bigint
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_row orgunit.org_unit_versions%ROWTYPE;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+  IF p_effective_date IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
+  END IF;
+
+  SELECT * INTO v_row
+  FROM orgunit.org_unit_versions
+  WHERE tenant_uuid = p_tenant_uuid
+    AND org_node_key = p_org_node_key
+    AND validity @> p_effective_date
+    AND lower(validity) < p_effective_date
+  ORDER BY lower(validity) DESC
+  LIMIT 1
+  FOR UPDATE;
+
+  IF NOT FOUND THEN
+    RETURN;
+  END IF;
+
+  UPDATE orgunit.org_unit_versions
+  SET validity = daterange(lower(validity), p_effective_date, '[)')
+  WHERE id = v_row.id;
+
+  INSERT INTO orgunit.org_unit_versions (
+    tenant_uuid,
+    org_node_key,
+    parent_org_node_key,
+    node_path,
+    validity,
+    name,
+    full_name_path,
+    status,
+    is_business_unit,
+    manager_uuid,
+    ext_str_01,
+    ext_str_02,
+    ext_str_03,
+    ext_str_04,
+    ext_str_05,
+    ext_str_06,
+    ext_str_07,
+    ext_str_08,
+    ext_str_09,
+    ext_str_10,
+    ext_str_11,
+    ext_str_12,
+    ext_str_13,
+    ext_str_14,
+    ext_str_15,
+    ext_str_16,
+    ext_str_17,
+    ext_str_18,
+    ext_str_19,
+    ext_str_20,
+    ext_str_21,
+    ext_str_22,
+    ext_str_23,
+    ext_str_24,
+    ext_str_25,
+    ext_str_26,
+    ext_str_27,
+    ext_str_28,
+    ext_str_29,
+    ext_str_30,
+    ext_str_31,
+    ext_str_32,
+    ext_str_33,
+    ext_str_34,
+    ext_str_35,
+    ext_str_36,
+    ext_str_37,
+    ext_str_38,
+    ext_str_39,
+    ext_str_40,
+    ext_str_41,
+    ext_str_42,
+    ext_str_43,
+    ext_str_44,
+    ext_str_45,
+    ext_str_46,
+    ext_str_47,
+    ext_str_48,
+    ext_str_49,
+    ext_str_50,
+    ext_str_51,
+    ext_str_52,
+    ext_str_53,
+    ext_str_54,
+    ext_str_55,
+    ext_str_56,
+    ext_str_57,
+    ext_str_58,
+    ext_str_59,
+    ext_str_60,
+    ext_str_61,
+    ext_str_62,
+    ext_str_63,
+    ext_str_64,
+    ext_str_65,
+    ext_str_66,
+    ext_str_67,
+    ext_str_68,
+    ext_str_69,
+    ext_str_70,
+    ext_int_01,
+    ext_int_02,
+    ext_int_03,
+    ext_int_04,
+    ext_int_05,
+    ext_int_06,
+    ext_int_07,
+    ext_int_08,
+    ext_int_09,
+    ext_int_10,
+    ext_int_11,
+    ext_int_12,
+    ext_int_13,
+    ext_int_14,
+    ext_int_15,
+    ext_uuid_01,
+    ext_uuid_02,
+    ext_uuid_03,
+    ext_uuid_04,
+    ext_uuid_05,
+    ext_uuid_06,
+    ext_uuid_07,
+    ext_uuid_08,
+    ext_uuid_09,
+    ext_uuid_10,
+    ext_bool_01,
+    ext_bool_02,
+    ext_bool_03,
+    ext_bool_04,
+    ext_bool_05,
+    ext_bool_06,
+    ext_bool_07,
+    ext_bool_08,
+    ext_bool_09,
+    ext_bool_10,
+    ext_bool_11,
+    ext_bool_12,
+    ext_bool_13,
+    ext_bool_14,
+    ext_bool_15,
+    ext_date_01,
+    ext_date_02,
+    ext_date_03,
+    ext_date_04,
+    ext_date_05,
+    ext_date_06,
+    ext_date_07,
+    ext_date_08,
+    ext_date_09,
+    ext_date_10,
+    ext_date_11,
+    ext_date_12,
+    ext_date_13,
+    ext_date_14,
+    ext_date_15,
+    ext_num_01,
+    ext_num_02,
+    ext_num_03,
+    ext_num_04,
+    ext_num_05,
+    ext_num_06,
+    ext_num_07,
+    ext_num_08,
+    ext_num_09,
+    ext_num_10,
+    ext_labels_snapshot,
+    last_event_id
+  )
+  VALUES (
+    v_row.tenant_uuid,
+    v_row.org_node_key,
+    v_row.parent_org_node_key,
+    v_row.node_path,
+    daterange(p_effective_date, upper(v_row.validity), '[)'),
+    v_row.name,
+    v_row.full_name_path,
+    v_row.status,
+    v_row.is_business_unit,
+    v_row.manager_uuid,
+    v_row.ext_str_01,
+    v_row.ext_str_02,
+    v_row.ext_str_03,
+    v_row.ext_str_04,
+    v_row.ext_str_05,
+    v_row.ext_str_06,
+    v_row.ext_str_07,
+    v_row.ext_str_08,
+    v_row.ext_str_09,
+    v_row.ext_str_10,
+    v_row.ext_str_11,
+    v_row.ext_str_12,
+    v_row.ext_str_13,
+    v_row.ext_str_14,
+    v_row.ext_str_15,
+    v_row.ext_str_16,
+    v_row.ext_str_17,
+    v_row.ext_str_18,
+    v_row.ext_str_19,
+    v_row.ext_str_20,
+    v_row.ext_str_21,
+    v_row.ext_str_22,
+    v_row.ext_str_23,
+    v_row.ext_str_24,
+    v_row.ext_str_25,
+    v_row.ext_str_26,
+    v_row.ext_str_27,
+    v_row.ext_str_28,
+    v_row.ext_str_29,
+    v_row.ext_str_30,
+    v_row.ext_str_31,
+    v_row.ext_str_32,
+    v_row.ext_str_33,
+    v_row.ext_str_34,
+    v_row.ext_str_35,
+    v_row.ext_str_36,
+    v_row.ext_str_37,
+    v_row.ext_str_38,
+    v_row.ext_str_39,
+    v_row.ext_str_40,
+    v_row.ext_str_41,
+    v_row.ext_str_42,
+    v_row.ext_str_43,
+    v_row.ext_str_44,
+    v_row.ext_str_45,
+    v_row.ext_str_46,
+    v_row.ext_str_47,
+    v_row.ext_str_48,
+    v_row.ext_str_49,
+    v_row.ext_str_50,
+    v_row.ext_str_51,
+    v_row.ext_str_52,
+    v_row.ext_str_53,
+    v_row.ext_str_54,
+    v_row.ext_str_55,
+    v_row.ext_str_56,
+    v_row.ext_str_57,
+    v_row.ext_str_58,
+    v_row.ext_str_59,
+    v_row.ext_str_60,
+    v_row.ext_str_61,
+    v_row.ext_str_62,
+    v_row.ext_str_63,
+    v_row.ext_str_64,
+    v_row.ext_str_65,
+    v_row.ext_str_66,
+    v_row.ext_str_67,
+    v_row.ext_str_68,
+    v_row.ext_str_69,
+    v_row.ext_str_70,
+    v_row.ext_int_01,
+    v_row.ext_int_02,
+    v_row.ext_int_03,
+    v_row.ext_int_04,
+    v_row.ext_int_05,
+    v_row.ext_int_06,
+    v_row.ext_int_07,
+    v_row.ext_int_08,
+    v_row.ext_int_09,
+    v_row.ext_int_10,
+    v_row.ext_int_11,
+    v_row.ext_int_12,
+    v_row.ext_int_13,
+    v_row.ext_int_14,
+    v_row.ext_int_15,
+    v_row.ext_uuid_01,
+    v_row.ext_uuid_02,
+    v_row.ext_uuid_03,
+    v_row.ext_uuid_04,
+    v_row.ext_uuid_05,
+    v_row.ext_uuid_06,
+    v_row.ext_uuid_07,
+    v_row.ext_uuid_08,
+    v_row.ext_uuid_09,
+    v_row.ext_uuid_10,
+    v_row.ext_bool_01,
+    v_row.ext_bool_02,
+    v_row.ext_bool_03,
+    v_row.ext_bool_04,
+    v_row.ext_bool_05,
+    v_row.ext_bool_06,
+    v_row.ext_bool_07,
+    v_row.ext_bool_08,
+    v_row.ext_bool_09,
+    v_row.ext_bool_10,
+    v_row.ext_bool_11,
+    v_row.ext_bool_12,
+    v_row.ext_bool_13,
+    v_row.ext_bool_14,
+    v_row.ext_bool_15,
+    v_row.ext_date_01,
+    v_row.ext_date_02,
+    v_row.ext_date_03,
+    v_row.ext_date_04,
+    v_row.ext_date_05,
+    v_row.ext_date_06,
+    v_row.ext_date_07,
+    v_row.ext_date_08,
+    v_row.ext_date_09,
+    v_row.ext_date_10,
+    v_row.ext_date_11,
+    v_row.ext_date_12,
+    v_row.ext_date_13,
+    v_row.ext_date_14,
+    v_row.ext_date_15,
+    v_row.ext_num_01,
+    v_row.ext_num_02,
+    v_row.ext_num_03,
+    v_row.ext_num_04,
+    v_row.ext_num_05,
+    v_row.ext_num_06,
+    v_row.ext_num_07,
+    v_row.ext_num_08,
+    v_row.ext_num_09,
+    v_row.ext_num_10,
+    v_row.ext_labels_snapshot,
+    p_event_db_id
+  );
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.apply_create_logic(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_org_code text,
+  p_parent_org_node_key char(8),
+  p_effective_date date,
+  p_name text,
+  p_manager_uuid uuid,
+  p_is_business_unit boolean,
+  p_event_db_id bigint,
+  p_status text DEFAULT 'active'
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_parent_path ltree;
+  v_node_path ltree;
+  v_root_org_node_key char(8);
+  v_is_business_unit boolean;
+  v_org_code text;
+  v_status text;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+  IF p_effective_date IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
+  END IF;
+  IF p_name IS NULL OR btrim(p_name) = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'name is required';
+  END IF;
+
+  v_status := lower(btrim(COALESCE(p_status, 'active')));
+  IF v_status IN ('enabled', '有效') THEN
+    v_status := 'active';
+  ELSIF v_status IN ('inactive', '无效') THEN
+    v_status := 'disabled';
+  END IF;
+  IF v_status NOT IN ('active', 'disabled') THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = format('status=%s', p_status);
+  END IF;
+
+  IF EXISTS (
+    SELECT 1
+    FROM orgunit.org_unit_versions
+    WHERE tenant_uuid = p_tenant_uuid AND org_node_key = p_org_node_key
+    LIMIT 1
+  ) THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_ALREADY_EXISTS', DETAIL = format('org_node_key=%s', p_org_node_key);
+  END IF;
+
+  IF p_parent_org_node_key IS NULL THEN
+    SELECT t.root_org_node_key INTO v_root_org_node_key
+    FROM orgunit.org_trees t
+    WHERE t.tenant_uuid = p_tenant_uuid
+    FOR UPDATE;
+
+    IF v_root_org_node_key IS NOT NULL THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_ROOT_ALREADY_EXISTS',
+        DETAIL = format('root_org_node_key=%s', v_root_org_node_key);
+    END IF;
+
+    INSERT INTO orgunit.org_trees (tenant_uuid, root_org_node_key)
+    VALUES (p_tenant_uuid, p_org_node_key);
+
+    v_node_path := text2ltree(orgunit.org_ltree_label(p_org_node_key));
+    IF p_is_business_unit IS NOT NULL AND p_is_business_unit = false THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_ROOT_BUSINESS_UNIT_REQUIRED',
+        DETAIL = format('org_node_key=%s', p_org_node_key);
+    END IF;
+    v_is_business_unit := true;
+  ELSE
+    SELECT t.root_org_node_key INTO v_root_org_node_key
+    FROM orgunit.org_trees t
+    WHERE t.tenant_uuid = p_tenant_uuid;
+
+    IF v_root_org_node_key IS NULL THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_TREE_NOT_INITIALIZED',
+        DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+    END IF;
+
+    SELECT v.node_path INTO v_parent_path
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.org_node_key = p_parent_org_node_key
+      AND v.status = 'active'
+      AND v.validity @> p_effective_date
+    LIMIT 1;
+
+    IF v_parent_path IS NULL THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_PARENT_NOT_FOUND_AS_OF',
+        DETAIL = format('parent_org_node_key=%s as_of=%s', p_parent_org_node_key, p_effective_date);
+    END IF;
+
+    v_node_path := v_parent_path || text2ltree(orgunit.org_ltree_label(p_org_node_key));
+    v_is_business_unit := COALESCE(p_is_business_unit, false);
+  END IF;
+
+  v_org_code := NULLIF(p_org_code, '');
+  IF v_org_code IS NOT NULL THEN
+    v_org_code := upper(v_org_code);
+    INSERT INTO orgunit.org_unit_codes (tenant_uuid, org_node_key, org_code)
+    VALUES (p_tenant_uuid, p_org_node_key, v_org_code);
+  END IF;
+
+  INSERT INTO orgunit.org_unit_versions (
+    tenant_uuid,
+    org_node_key,
+    parent_org_node_key,
+    node_path,
+    validity,
+    name,
+    full_name_path,
+    status,
+    is_business_unit,
+    manager_uuid,
+    last_event_id
+  )
+  VALUES (
+    p_tenant_uuid,
+    p_org_node_key,
+    p_parent_org_node_key,
+    v_node_path,
+    daterange(p_effective_date, NULL, '[)'),
+    p_name,
+    p_name,
+    v_status,
+    v_is_business_unit,
+    p_manager_uuid,
+    p_event_db_id
+  );
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.apply_move_logic(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_new_parent_org_node_key char(8),
+  p_effective_date date,
+  p_event_db_id bigint
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_root_org_node_key char(8);
+  v_old_path ltree;
+  v_new_parent_path ltree;
+  v_new_prefix ltree;
+  v_old_level int;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+  IF p_new_parent_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'new_parent_org_node_key is required';
+  END IF;
+  IF p_effective_date IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
+  END IF;
+
+  SELECT t.root_org_node_key INTO v_root_org_node_key
+  FROM orgunit.org_trees t
+  WHERE t.tenant_uuid = p_tenant_uuid;
+
+  IF v_root_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_TREE_NOT_INITIALIZED',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+  IF v_root_org_node_key = p_org_node_key THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_ROOT_CANNOT_BE_MOVED',
+      DETAIL = format('root_org_node_key=%s', v_root_org_node_key);
+  END IF;
+
+  SELECT v.node_path INTO v_old_path
+  FROM orgunit.org_unit_versions v
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.org_node_key = p_org_node_key
+    AND v.validity @> p_effective_date
+  ORDER BY lower(v.validity) DESC
+  LIMIT 1
+  FOR UPDATE;
+
+  IF v_old_path IS NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_NOT_FOUND_AS_OF',
+      DETAIL = format('org_node_key=%s as_of=%s', p_org_node_key, p_effective_date);
+  END IF;
+
+  SELECT v.node_path INTO v_new_parent_path
+  FROM orgunit.org_unit_versions v
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.org_node_key = p_new_parent_org_node_key
+    AND v.validity @> p_effective_date
+  LIMIT 1;
+
+  IF v_new_parent_path IS NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_PARENT_NOT_FOUND_AS_OF',
+      DETAIL = format('parent_org_node_key=%s as_of=%s', p_new_parent_org_node_key, p_effective_date);
+  END IF;
+
+  IF v_new_parent_path <@ v_old_path THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_CYCLE_MOVE',
+      DETAIL = format('cycle move: org_node_key=%s new_parent_org_node_key=%s', p_org_node_key, p_new_parent_org_node_key);
+  END IF;
+
+  v_new_prefix := v_new_parent_path || text2ltree(orgunit.org_ltree_label(p_org_node_key));
+  v_old_level := nlevel(v_old_path);
+
+  WITH split AS (
+    SELECT *
+    FROM orgunit.org_unit_versions
+    WHERE tenant_uuid = p_tenant_uuid
+      AND node_path <@ v_old_path
+      AND validity @> p_effective_date
+      AND lower(validity) < p_effective_date
+  ),
+  upd AS (
+    UPDATE orgunit.org_unit_versions v
+    SET validity = daterange(lower(v.validity), p_effective_date, '[)')
+    FROM split s
+    WHERE v.id = s.id
+    RETURNING s.*
+  )
+  INSERT INTO orgunit.org_unit_versions (
+    tenant_uuid,
+    org_node_key,
+    parent_org_node_key,
+    node_path,
+    validity,
+    name,
+    full_name_path,
+    status,
+    is_business_unit,
+    manager_uuid,
+    ext_str_01,
+    ext_str_02,
+    ext_str_03,
+    ext_str_04,
+    ext_str_05,
+    ext_str_06,
+    ext_str_07,
+    ext_str_08,
+    ext_str_09,
+    ext_str_10,
+    ext_str_11,
+    ext_str_12,
+    ext_str_13,
+    ext_str_14,
+    ext_str_15,
+    ext_str_16,
+    ext_str_17,
+    ext_str_18,
+    ext_str_19,
+    ext_str_20,
+    ext_str_21,
+    ext_str_22,
+    ext_str_23,
+    ext_str_24,
+    ext_str_25,
+    ext_str_26,
+    ext_str_27,
+    ext_str_28,
+    ext_str_29,
+    ext_str_30,
+    ext_str_31,
+    ext_str_32,
+    ext_str_33,
+    ext_str_34,
+    ext_str_35,
+    ext_str_36,
+    ext_str_37,
+    ext_str_38,
+    ext_str_39,
+    ext_str_40,
+    ext_str_41,
+    ext_str_42,
+    ext_str_43,
+    ext_str_44,
+    ext_str_45,
+    ext_str_46,
+    ext_str_47,
+    ext_str_48,
+    ext_str_49,
+    ext_str_50,
+    ext_str_51,
+    ext_str_52,
+    ext_str_53,
+    ext_str_54,
+    ext_str_55,
+    ext_str_56,
+    ext_str_57,
+    ext_str_58,
+    ext_str_59,
+    ext_str_60,
+    ext_str_61,
+    ext_str_62,
+    ext_str_63,
+    ext_str_64,
+    ext_str_65,
+    ext_str_66,
+    ext_str_67,
+    ext_str_68,
+    ext_str_69,
+    ext_str_70,
+    ext_int_01,
+    ext_int_02,
+    ext_int_03,
+    ext_int_04,
+    ext_int_05,
+    ext_int_06,
+    ext_int_07,
+    ext_int_08,
+    ext_int_09,
+    ext_int_10,
+    ext_int_11,
+    ext_int_12,
+    ext_int_13,
+    ext_int_14,
+    ext_int_15,
+    ext_uuid_01,
+    ext_uuid_02,
+    ext_uuid_03,
+    ext_uuid_04,
+    ext_uuid_05,
+    ext_uuid_06,
+    ext_uuid_07,
+    ext_uuid_08,
+    ext_uuid_09,
+    ext_uuid_10,
+    ext_bool_01,
+    ext_bool_02,
+    ext_bool_03,
+    ext_bool_04,
+    ext_bool_05,
+    ext_bool_06,
+    ext_bool_07,
+    ext_bool_08,
+    ext_bool_09,
+    ext_bool_10,
+    ext_bool_11,
+    ext_bool_12,
+    ext_bool_13,
+    ext_bool_14,
+    ext_bool_15,
+    ext_date_01,
+    ext_date_02,
+    ext_date_03,
+    ext_date_04,
+    ext_date_05,
+    ext_date_06,
+    ext_date_07,
+    ext_date_08,
+    ext_date_09,
+    ext_date_10,
+    ext_date_11,
+    ext_date_12,
+    ext_date_13,
+    ext_date_14,
+    ext_date_15,
+    ext_num_01,
+    ext_num_02,
+    ext_num_03,
+    ext_num_04,
+    ext_num_05,
+    ext_num_06,
+    ext_num_07,
+    ext_num_08,
+    ext_num_09,
+    ext_num_10,
+    ext_labels_snapshot,
+    last_event_id
+  )
+  SELECT
+    u.tenant_uuid,
+    u.org_node_key,
+    CASE WHEN u.org_node_key = p_org_node_key THEN p_new_parent_org_node_key ELSE u.parent_org_node_key END,
+    CASE
+      WHEN u.org_node_key = p_org_node_key THEN v_new_prefix
+      ELSE v_new_prefix || subpath(u.node_path, v_old_level)
+    END,
+    daterange(p_effective_date, upper(u.validity), '[)'),
+    u.name,
+    u.full_name_path,
+    u.status,
+    u.is_business_unit,
+    u.manager_uuid,
+    u.ext_str_01,
+    u.ext_str_02,
+    u.ext_str_03,
+    u.ext_str_04,
+    u.ext_str_05,
+    u.ext_str_06,
+    u.ext_str_07,
+    u.ext_str_08,
+    u.ext_str_09,
+    u.ext_str_10,
+    u.ext_str_11,
+    u.ext_str_12,
+    u.ext_str_13,
+    u.ext_str_14,
+    u.ext_str_15,
+    u.ext_str_16,
+    u.ext_str_17,
+    u.ext_str_18,
+    u.ext_str_19,
+    u.ext_str_20,
+    u.ext_str_21,
+    u.ext_str_22,
+    u.ext_str_23,
+    u.ext_str_24,
+    u.ext_str_25,
+    u.ext_str_26,
+    u.ext_str_27,
+    u.ext_str_28,
+    u.ext_str_29,
+    u.ext_str_30,
+    u.ext_str_31,
+    u.ext_str_32,
+    u.ext_str_33,
+    u.ext_str_34,
+    u.ext_str_35,
+    u.ext_str_36,
+    u.ext_str_37,
+    u.ext_str_38,
+    u.ext_str_39,
+    u.ext_str_40,
+    u.ext_str_41,
+    u.ext_str_42,
+    u.ext_str_43,
+    u.ext_str_44,
+    u.ext_str_45,
+    u.ext_str_46,
+    u.ext_str_47,
+    u.ext_str_48,
+    u.ext_str_49,
+    u.ext_str_50,
+    u.ext_str_51,
+    u.ext_str_52,
+    u.ext_str_53,
+    u.ext_str_54,
+    u.ext_str_55,
+    u.ext_str_56,
+    u.ext_str_57,
+    u.ext_str_58,
+    u.ext_str_59,
+    u.ext_str_60,
+    u.ext_str_61,
+    u.ext_str_62,
+    u.ext_str_63,
+    u.ext_str_64,
+    u.ext_str_65,
+    u.ext_str_66,
+    u.ext_str_67,
+    u.ext_str_68,
+    u.ext_str_69,
+    u.ext_str_70,
+    u.ext_int_01,
+    u.ext_int_02,
+    u.ext_int_03,
+    u.ext_int_04,
+    u.ext_int_05,
+    u.ext_int_06,
+    u.ext_int_07,
+    u.ext_int_08,
+    u.ext_int_09,
+    u.ext_int_10,
+    u.ext_int_11,
+    u.ext_int_12,
+    u.ext_int_13,
+    u.ext_int_14,
+    u.ext_int_15,
+    u.ext_uuid_01,
+    u.ext_uuid_02,
+    u.ext_uuid_03,
+    u.ext_uuid_04,
+    u.ext_uuid_05,
+    u.ext_uuid_06,
+    u.ext_uuid_07,
+    u.ext_uuid_08,
+    u.ext_uuid_09,
+    u.ext_uuid_10,
+    u.ext_bool_01,
+    u.ext_bool_02,
+    u.ext_bool_03,
+    u.ext_bool_04,
+    u.ext_bool_05,
+    u.ext_bool_06,
+    u.ext_bool_07,
+    u.ext_bool_08,
+    u.ext_bool_09,
+    u.ext_bool_10,
+    u.ext_bool_11,
+    u.ext_bool_12,
+    u.ext_bool_13,
+    u.ext_bool_14,
+    u.ext_bool_15,
+    u.ext_date_01,
+    u.ext_date_02,
+    u.ext_date_03,
+    u.ext_date_04,
+    u.ext_date_05,
+    u.ext_date_06,
+    u.ext_date_07,
+    u.ext_date_08,
+    u.ext_date_09,
+    u.ext_date_10,
+    u.ext_date_11,
+    u.ext_date_12,
+    u.ext_date_13,
+    u.ext_date_14,
+    u.ext_date_15,
+    u.ext_num_01,
+    u.ext_num_02,
+    u.ext_num_03,
+    u.ext_num_04,
+    u.ext_num_05,
+    u.ext_num_06,
+    u.ext_num_07,
+    u.ext_num_08,
+    u.ext_num_09,
+    u.ext_num_10,
+    u.ext_labels_snapshot,
+    p_event_db_id
+  FROM upd u;
+
+  UPDATE orgunit.org_unit_versions v
+  SET
+    node_path = CASE
+      WHEN v.org_node_key = p_org_node_key THEN v_new_prefix
+        ELSE v_new_prefix || subpath(v.node_path, v_old_level)
+      END,
+    parent_org_node_key = CASE WHEN v.org_node_key = p_org_node_key THEN p_new_parent_org_node_key ELSE v.parent_org_node_key END,
+    last_event_id = p_event_db_id
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.node_path <@ v_old_path
+    AND lower(v.validity) >= p_effective_date;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.apply_rename_logic(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_effective_date date,
+  p_new_name text,
+  p_event_db_id bigint
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_stop_date date;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+  IF p_effective_date IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
+  END IF;
+  IF p_new_name IS NULL OR btrim(p_new_name) = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'new_name is required';
+  END IF;
+
+  IF NOT EXISTS (
+    SELECT 1
+    FROM orgunit.org_unit_versions
+    WHERE tenant_uuid = p_tenant_uuid
+      AND org_node_key = p_org_node_key
+      AND validity @> p_effective_date
+    LIMIT 1
+  ) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_NOT_FOUND_AS_OF',
+      DETAIL = format('org_node_key=%s as_of=%s', p_org_node_key, p_effective_date);
+  END IF;
+
+  PERFORM orgunit.split_org_unit_version_at(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_db_id);
+
+  SELECT MIN(e.effective_date) INTO v_stop_date
+  FROM orgunit.org_events_effective e
+  WHERE e.tenant_uuid = p_tenant_uuid
+    AND e.org_node_key = p_org_node_key
+    AND e.event_type = 'RENAME'
+    AND e.effective_date > p_effective_date;
+
+  UPDATE orgunit.org_unit_versions
+  SET name = p_new_name, last_event_id = p_event_db_id
+  WHERE tenant_uuid = p_tenant_uuid
+    AND org_node_key = p_org_node_key
+    AND lower(validity) >= p_effective_date
+    AND (v_stop_date IS NULL OR lower(validity) < v_stop_date);
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.apply_disable_logic(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_effective_date date,
+  p_event_db_id bigint
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+  IF p_effective_date IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
+  END IF;
+
+  IF NOT EXISTS (
+    SELECT 1
+    FROM orgunit.org_unit_versions
+    WHERE tenant_uuid = p_tenant_uuid
+      AND org_node_key = p_org_node_key
+      AND validity @> p_effective_date
+    LIMIT 1
+  ) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_NOT_FOUND_AS_OF',
+      DETAIL = format('org_node_key=%s as_of=%s', p_org_node_key, p_effective_date);
+  END IF;
+
+  PERFORM orgunit.split_org_unit_version_at(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_db_id);
+
+  UPDATE orgunit.org_unit_versions
+  SET status = 'disabled', last_event_id = p_event_db_id
+  WHERE tenant_uuid = p_tenant_uuid
+    AND org_node_key = p_org_node_key
+    AND lower(validity) >= p_effective_date;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.apply_enable_logic(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_effective_date date,
+  p_event_db_id bigint
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+  IF p_effective_date IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
+  END IF;
+
+  IF NOT EXISTS (
+    SELECT 1
+    FROM orgunit.org_unit_versions
+    WHERE tenant_uuid = p_tenant_uuid
+      AND org_node_key = p_org_node_key
+      AND validity @> p_effective_date
+    LIMIT 1
+  ) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_NOT_FOUND_AS_OF',
+      DETAIL = format('org_node_key=%s as_of=%s', p_org_node_key, p_effective_date);
+  END IF;
+
+  PERFORM orgunit.split_org_unit_version_at(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_db_id);
+
+  UPDATE orgunit.org_unit_versions
+  SET status = 'active', last_event_id = p_event_db_id
+  WHERE tenant_uuid = p_tenant_uuid
+    AND org_node_key = p_org_node_key
+    AND lower(validity) >= p_effective_date;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.apply_set_business_unit_logic(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_effective_date date,
+  p_is_business_unit boolean,
+  p_event_db_id bigint
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_stop_date date;
+  v_status text;
+  v_root_org_node_key char(8);
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+  IF p_effective_date IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
+  END IF;
+  IF p_is_business_unit IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'is_business_unit is required';
+  END IF;
+
+  SELECT v.status INTO v_status
+  FROM orgunit.org_unit_versions v
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.org_node_key = p_org_node_key
+    AND v.validity @> p_effective_date
+  ORDER BY lower(v.validity) DESC
+  LIMIT 1;
+
+  IF v_status IS NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_NOT_FOUND_AS_OF',
+      DETAIL = format('org_node_key=%s as_of=%s', p_org_node_key, p_effective_date);
+  END IF;
+
+  SELECT t.root_org_node_key INTO v_root_org_node_key
+  FROM orgunit.org_trees t
+  WHERE t.tenant_uuid = p_tenant_uuid;
+
+  IF v_root_org_node_key IS NOT NULL AND v_root_org_node_key = p_org_node_key AND p_is_business_unit = false THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_ROOT_BUSINESS_UNIT_REQUIRED',
+      DETAIL = format('org_node_key=%s', p_org_node_key);
+  END IF;
+
+  PERFORM orgunit.split_org_unit_version_at(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_db_id);
+
+  SELECT MIN(e.effective_date) INTO v_stop_date
+  FROM orgunit.org_events_effective e
+  WHERE e.tenant_uuid = p_tenant_uuid
+    AND e.org_node_key = p_org_node_key
+    AND e.event_type = 'SET_BUSINESS_UNIT'
+    AND e.effective_date > p_effective_date;
+
+  UPDATE orgunit.org_unit_versions
+  SET is_business_unit = p_is_business_unit, last_event_id = p_event_db_id
+  WHERE tenant_uuid = p_tenant_uuid
+    AND org_node_key = p_org_node_key
+    AND lower(validity) >= p_effective_date
+    AND (v_stop_date IS NULL OR lower(validity) < v_stop_date);
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.apply_update_logic(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_effective_date date,
+  p_payload jsonb,
+  p_event_db_id bigint
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_payload jsonb;
+  v_parent_org_node_key char(8);
+  v_name text;
+  v_status text;
+  v_is_business_unit boolean;
+  v_manager_uuid uuid;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+  IF p_effective_date IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
+  END IF;
+
+  v_payload := COALESCE(p_payload, '{}'::jsonb);
+  IF jsonb_typeof(v_payload) <> 'object' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'payload must be an object';
+  END IF;
+
+  -- 1) parent (move)
+  IF v_payload ? 'parent_org_node_key' OR v_payload ? 'new_parent_org_node_key' THEN
+    v_parent_org_node_key := NULLIF(COALESCE(v_payload->>'parent_org_node_key', v_payload->>'new_parent_org_node_key'), '')::char(8);
+    IF v_parent_org_node_key IS NULL THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'parent_org_node_key is required';
+    END IF;
+    PERFORM orgunit.apply_move_logic(p_tenant_uuid, p_org_node_key, v_parent_org_node_key, p_effective_date, p_event_db_id);
+  END IF;
+
+  -- 2) status (enable/disable)
+  IF v_payload ? 'status' THEN
+    v_status := lower(btrim(COALESCE(v_payload->>'status', '')));
+    IF v_status IN ('enabled', '有效') THEN
+      v_status := 'active';
+    ELSIF v_status IN ('inactive', '无效') THEN
+      v_status := 'disabled';
+    END IF;
+    IF v_status = 'active' THEN
+      PERFORM orgunit.apply_enable_logic(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_db_id);
+    ELSIF v_status = 'disabled' THEN
+      PERFORM orgunit.apply_disable_logic(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_db_id);
+    ELSE
+      RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'status invalid';
+    END IF;
+  END IF;
+
+  -- 3) is_business_unit
+  IF v_payload ? 'is_business_unit' THEN
+    BEGIN
+      v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
+    EXCEPTION
+      WHEN invalid_text_representation THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_INVALID_ARGUMENT',
+          DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
+    END;
+    PERFORM orgunit.apply_set_business_unit_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_is_business_unit, p_event_db_id);
+  END IF;
+
+  -- 4) manager
+  IF v_payload ? 'manager_uuid' THEN
+    v_manager_uuid := NULLIF(v_payload->>'manager_uuid', '')::uuid;
+    IF NOT EXISTS (
+      SELECT 1
+      FROM orgunit.org_unit_versions
+      WHERE tenant_uuid = p_tenant_uuid
+        AND org_node_key = p_org_node_key
+        AND validity @> p_effective_date
+      LIMIT 1
+    ) THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_NOT_FOUND_AS_OF',
+        DETAIL = format('org_node_key=%s as_of=%s', p_org_node_key, p_effective_date);
+    END IF;
+
+    PERFORM orgunit.split_org_unit_version_at(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_db_id);
+
+    UPDATE orgunit.org_unit_versions
+    SET manager_uuid = v_manager_uuid, last_event_id = p_event_db_id
+    WHERE tenant_uuid = p_tenant_uuid
+      AND org_node_key = p_org_node_key
+      AND lower(validity) >= p_effective_date;
+  END IF;
+
+  -- 5) name (rename)
+  IF v_payload ? 'name' OR v_payload ? 'new_name' THEN
+    v_name := NULLIF(btrim(COALESCE(v_payload->>'name', v_payload->>'new_name')), '');
+    IF v_name IS NULL THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'name is required';
+    END IF;
+    PERFORM orgunit.apply_rename_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_name, p_event_db_id);
+  END IF;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.rebuild_full_name_path_subtree(
+  p_tenant_uuid uuid,
+  p_root_path ltree,
+  p_from_date date
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_root_path IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'root_path is required';
+  END IF;
+  IF p_from_date IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'from_date is required';
+  END IF;
+
+  UPDATE orgunit.org_unit_versions v
+  SET full_name_path = (
+    SELECT string_agg(a.name, ' / ' ORDER BY t.idx)
+    FROM unnest(v.path_node_keys) WITH ORDINALITY AS t(uid, idx)
+    JOIN orgunit.org_unit_versions a
+      ON a.tenant_uuid = v.tenant_uuid
+     AND a.org_node_key = t.uid::char(8)
+     AND a.validity @> lower(v.validity)
+  )
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.node_path <@ p_root_path
+    AND lower(v.validity) >= p_from_date;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.assert_org_unit_validity(
+  p_tenant_uuid uuid,
+  p_org_node_keys char(8)[]
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_keys IS NULL OR array_length(p_org_node_keys, 1) IS NULL THEN
+    RETURN;
+  END IF;
+
+  IF EXISTS (
+    WITH ordered AS (
+      SELECT
+        org_node_key,
+        validity,
+        lag(validity) OVER (PARTITION BY org_node_key ORDER BY lower(validity)) AS prev_validity
+      FROM orgunit.org_unit_versions
+      WHERE tenant_uuid = p_tenant_uuid
+        AND org_node_key = ANY(p_org_node_keys)
+    )
+    SELECT 1
+    FROM ordered
+    WHERE prev_validity IS NOT NULL
+      AND lower(validity) <> upper(prev_validity)
+    LIMIT 1
+  ) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_VALIDITY_GAP',
+      DETAIL = 'org_unit_versions must be gapless';
+  END IF;
+
+  IF EXISTS (
+    SELECT 1
+    FROM (
+      SELECT DISTINCT ON (org_node_key) org_node_key, validity
+      FROM orgunit.org_unit_versions
+      WHERE tenant_uuid = p_tenant_uuid
+        AND org_node_key = ANY(p_org_node_keys)
+      ORDER BY org_node_key, lower(validity) DESC
+    ) last
+    WHERE NOT upper_inf(last.validity)
+    LIMIT 1
+  ) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_VALIDITY_NOT_INFINITE',
+      DETAIL = 'last version validity must be unbounded (infinity)';
+  END IF;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.org_events_effective_for_replay(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_pending_event_id bigint,
+  p_pending_event_uuid uuid,
+  p_pending_event_type text,
+  p_pending_effective_date date,
+  p_pending_payload jsonb,
+  p_pending_request_id text,
+  p_pending_initiator_uuid uuid,
+  p_pending_tx_time timestamptz,
+  p_pending_transaction_time timestamptz,
+  p_pending_created_at timestamptz
+)
+RETURNS TABLE (
+  id bigint,
+  event_uuid uuid,
+  tenant_uuid uuid,
+  org_node_key char(8),
+  event_type text,
+  effective_date date,
+  payload jsonb,
+  request_id text,
+  initiator_uuid uuid,
+  transaction_time timestamptz,
+  created_at timestamptz
+)
+LANGUAGE sql
+STABLE
+AS $$
+  WITH source_events AS (
+    SELECT
+      e.id,
+      e.event_uuid,
+      e.tenant_uuid,
+      e.org_node_key,
+      e.event_type,
+      e.effective_date,
+      COALESCE(e.payload, '{}'::jsonb) AS payload,
+      e.request_id,
+      e.initiator_uuid,
+      e.tx_time,
+      e.transaction_time,
+      e.created_at
+    FROM orgunit.org_events e
+    WHERE e.tenant_uuid = p_tenant_uuid
+      AND e.org_node_key = p_org_node_key
+
+    UNION ALL
+
+    SELECT
+      p_pending_event_id,
+      p_pending_event_uuid,
+      p_tenant_uuid,
+      p_org_node_key,
+      p_pending_event_type,
+      p_pending_effective_date,
+      COALESCE(p_pending_payload, '{}'::jsonb),
+      p_pending_request_id,
+      p_pending_initiator_uuid,
+      p_pending_tx_time,
+      p_pending_transaction_time,
+      p_pending_created_at
+    WHERE p_pending_event_id IS NOT NULL
+  ),
+  correction_events AS (
+    SELECT
+      se.*,
+      (se.payload->>'target_event_uuid')::uuid AS target_event_uuid
+    FROM source_events se
+    WHERE se.event_type IN ('CORRECT_EVENT','CORRECT_STATUS','RESCIND_EVENT','RESCIND_ORG')
+      AND se.payload ? 'target_event_uuid'
+  ),
+  latest_corrections AS (
+    SELECT DISTINCT ON (tenant_uuid, target_event_uuid)
+      tenant_uuid,
+      target_event_uuid,
+      event_type AS correction_type,
+      payload AS correction_payload,
+      tx_time,
+      id
+    FROM correction_events
+    ORDER BY tenant_uuid, target_event_uuid, tx_time DESC, id DESC
+  ),
+  latest_effective_date_corrections AS (
+    -- Sticky effective_date: take the latest CORRECT_EVENT that explicitly carries effective_date,
+    -- regardless of later corrections that don't include effective_date.
+    SELECT DISTINCT ON (tenant_uuid, target_event_uuid)
+      tenant_uuid,
+      target_event_uuid,
+      NULLIF(btrim(payload->>'effective_date'), '')::date AS sticky_effective_date,
+      tx_time,
+      id
+    FROM correction_events
+    WHERE event_type = 'CORRECT_EVENT'
+      AND payload ? 'effective_date'
+    ORDER BY tenant_uuid, target_event_uuid, tx_time DESC, id DESC
+  )
+  SELECT
+    se.id,
+    se.event_uuid,
+    se.tenant_uuid,
+    se.org_node_key,
+    CASE
+      WHEN lc.correction_type = 'CORRECT_STATUS'
+        AND COALESCE(lc.correction_payload->>'target_status', '') = 'active'
+        THEN 'ENABLE'
+      WHEN lc.correction_type = 'CORRECT_STATUS'
+        AND COALESCE(lc.correction_payload->>'target_status', '') = 'disabled'
+        THEN 'DISABLE'
+      WHEN lc.correction_type = 'CORRECT_EVENT'
+        AND se.event_type <> 'CREATE'
+        AND (
+          orgunit.merge_org_event_payload_with_correction(se.payload, lc.correction_payload) ?| ARRAY[
+            'name',
+            'parent_org_node_key',
+            'status',
+            'is_business_unit',
+            'manager_uuid',
+            'manager_pernr',
+            'ext',
+            'new_name',
+            'new_parent_org_node_key'
+          ]
+        )
+        THEN 'UPDATE'
+      ELSE se.event_type
+    END AS event_type,
+    COALESCE(lec.sticky_effective_date, se.effective_date) AS effective_date,
+    CASE
+      WHEN lc.correction_type = 'CORRECT_EVENT'
+        THEN orgunit.merge_org_event_payload_with_correction(se.payload, lc.correction_payload)
+      ELSE se.payload
+    END AS payload,
+    se.request_id,
+    se.initiator_uuid,
+    se.transaction_time,
+    se.created_at
+  FROM source_events se
+  LEFT JOIN latest_corrections lc
+    ON lc.tenant_uuid = se.tenant_uuid
+   AND lc.target_event_uuid = se.event_uuid
+  LEFT JOIN latest_effective_date_corrections lec
+    ON lec.tenant_uuid = se.tenant_uuid
+   AND lec.target_event_uuid = se.event_uuid
+  WHERE se.event_type IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT')
+    AND COALESCE(lc.correction_type, '') NOT IN ('RESCIND_EVENT', 'RESCIND_ORG')
+  ORDER BY effective_date, id;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.rebuild_org_unit_versions_for_org_with_pending_event(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_pending_event_id bigint,
+  p_pending_event_uuid uuid,
+  p_pending_event_type text,
+  p_pending_effective_date date,
+  p_pending_payload jsonb,
+  p_pending_request_id text,
+  p_pending_initiator_uuid uuid,
+  p_pending_tx_time timestamptz,
+  p_pending_transaction_time timestamptz,
+  p_pending_created_at timestamptz
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_event record;
+  v_payload jsonb;
+  v_parent_org_node_key char(8);
+  v_new_parent_org_node_key char(8);
+  v_name text;
+  v_new_name text;
+  v_manager_uuid uuid;
+  v_is_business_unit boolean;
+  v_org_code text;
+  v_root_path ltree;
+  v_org_node_keys char(8)[];
+  v_root_org_node_key char(8);
+  v_has_create boolean;
+  v_status text;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+
+  IF p_pending_event_id IS NOT NULL THEN
+    IF p_pending_event_uuid IS NULL
+      OR p_pending_event_type IS NULL
+      OR p_pending_effective_date IS NULL
+      OR p_pending_request_id IS NULL
+      OR p_pending_initiator_uuid IS NULL
+      OR p_pending_tx_time IS NULL
+      OR p_pending_transaction_time IS NULL
+      OR p_pending_created_at IS NULL
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_INVALID_ARGUMENT',
+        DETAIL = 'pending event metadata is incomplete';
+    END IF;
+  END IF;
+
+  SELECT t.root_org_node_key INTO v_root_org_node_key
+  FROM orgunit.org_trees t
+  WHERE t.tenant_uuid = p_tenant_uuid;
+
+  SELECT EXISTS (
+    SELECT 1
+    FROM orgunit.org_events_effective_for_replay(
+      p_tenant_uuid,
+      p_org_node_key,
+      p_pending_event_id,
+      p_pending_event_uuid,
+      p_pending_event_type,
+      p_pending_effective_date,
+      p_pending_payload,
+      p_pending_request_id,
+      p_pending_initiator_uuid,
+      p_pending_tx_time,
+      p_pending_transaction_time,
+      p_pending_created_at
+    ) e
+    WHERE e.event_type = 'CREATE'
+  ) INTO v_has_create;
+
+  IF v_has_create AND v_root_org_node_key = p_org_node_key THEN
+    DELETE FROM orgunit.org_trees
+    WHERE tenant_uuid = p_tenant_uuid;
+  ELSIF v_root_org_node_key = p_org_node_key AND NOT v_has_create THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = format('root org missing create event: org_node_key=%s', p_org_node_key);
+  END IF;
+
+  DELETE FROM orgunit.org_unit_codes
+  WHERE tenant_uuid = p_tenant_uuid
+    AND org_node_key = p_org_node_key;
+
+  DELETE FROM orgunit.org_unit_versions
+  WHERE tenant_uuid = p_tenant_uuid
+    AND org_node_key = p_org_node_key;
+
+  FOR v_event IN
+    SELECT *
+    FROM orgunit.org_events_effective_for_replay(
+      p_tenant_uuid,
+      p_org_node_key,
+      p_pending_event_id,
+      p_pending_event_uuid,
+      p_pending_event_type,
+      p_pending_effective_date,
+      p_pending_payload,
+      p_pending_request_id,
+      p_pending_initiator_uuid,
+      p_pending_tx_time,
+      p_pending_transaction_time,
+      p_pending_created_at
+    )
+    ORDER BY effective_date, id
+  LOOP
+    v_payload := COALESCE(v_event.payload, '{}'::jsonb);
+
+    IF v_event.event_type = 'CREATE' THEN
+      v_parent_org_node_key := NULLIF(v_payload->>'parent_org_node_key', '')::char(8);
+      v_name := NULLIF(btrim(v_payload->>'name'), '');
+      v_manager_uuid := NULLIF(v_payload->>'manager_uuid', '')::uuid;
+      v_org_code := NULLIF(v_payload->>'org_code', '');
+      v_status := NULLIF(btrim(v_payload->>'status'), '');
+      v_is_business_unit := NULL;
+      IF v_payload ? 'is_business_unit' THEN
+        BEGIN
+          v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
+        EXCEPTION
+          WHEN invalid_text_representation THEN
+            RAISE EXCEPTION USING
+              MESSAGE = 'ORG_INVALID_ARGUMENT',
+              DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
+        END;
+      END IF;
+      PERFORM orgunit.apply_create_logic(p_tenant_uuid, v_event.org_node_key, v_org_code, v_parent_org_node_key, v_event.effective_date, v_name, v_manager_uuid, v_is_business_unit, v_event.id, v_status);
+      SELECT v.node_path INTO v_root_path
+      FROM orgunit.org_unit_versions v
+      WHERE v.tenant_uuid = p_tenant_uuid
+        AND v.org_node_key = p_org_node_key
+        AND v.validity @> v_event.effective_date
+      ORDER BY lower(v.validity) DESC
+      LIMIT 1;
+      IF v_root_path IS NOT NULL THEN
+        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, v_event.effective_date);
+      END IF;
+    ELSIF v_event.event_type = 'UPDATE' THEN
+      PERFORM orgunit.apply_update_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_payload, v_event.id);
+      IF (v_payload ? 'parent_org_node_key')
+        OR (v_payload ? 'new_parent_org_node_key')
+        OR (v_payload ? 'name')
+        OR (v_payload ? 'new_name')
+      THEN
+        SELECT v.node_path INTO v_root_path
+        FROM orgunit.org_unit_versions v
+        WHERE v.tenant_uuid = p_tenant_uuid
+          AND v.org_node_key = p_org_node_key
+          AND v.validity @> v_event.effective_date
+        ORDER BY lower(v.validity) DESC
+        LIMIT 1;
+        IF v_root_path IS NOT NULL THEN
+          PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, v_event.effective_date);
+        END IF;
+      END IF;
+    ELSIF v_event.event_type = 'MOVE' THEN
+      v_new_parent_org_node_key := NULLIF(v_payload->>'new_parent_org_node_key', '')::char(8);
+      PERFORM orgunit.apply_move_logic(p_tenant_uuid, v_event.org_node_key, v_new_parent_org_node_key, v_event.effective_date, v_event.id);
+      SELECT v.node_path INTO v_root_path
+      FROM orgunit.org_unit_versions v
+      WHERE v.tenant_uuid = p_tenant_uuid
+        AND v.org_node_key = p_org_node_key
+        AND v.validity @> v_event.effective_date
+      ORDER BY lower(v.validity) DESC
+      LIMIT 1;
+      IF v_root_path IS NOT NULL THEN
+        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, v_event.effective_date);
+      END IF;
+    ELSIF v_event.event_type = 'RENAME' THEN
+      v_new_name := NULLIF(btrim(v_payload->>'new_name'), '');
+      PERFORM orgunit.apply_rename_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_new_name, v_event.id);
+      SELECT v.node_path INTO v_root_path
+      FROM orgunit.org_unit_versions v
+      WHERE v.tenant_uuid = p_tenant_uuid
+        AND v.org_node_key = p_org_node_key
+        AND v.validity @> v_event.effective_date
+      ORDER BY lower(v.validity) DESC
+      LIMIT 1;
+      IF v_root_path IS NOT NULL THEN
+        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, v_event.effective_date);
+      END IF;
+    ELSIF v_event.event_type = 'DISABLE' THEN
+      PERFORM orgunit.apply_disable_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_event.id);
+    ELSIF v_event.event_type = 'ENABLE' THEN
+      PERFORM orgunit.apply_enable_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_event.id);
+    ELSIF v_event.event_type = 'SET_BUSINESS_UNIT' THEN
+      IF NOT (v_payload ? 'is_business_unit') THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_INVALID_ARGUMENT',
+          DETAIL = 'is_business_unit is required';
+      END IF;
+      BEGIN
+        v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
+      EXCEPTION
+        WHEN invalid_text_representation THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_INVALID_ARGUMENT',
+            DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
+      END;
+      PERFORM orgunit.apply_set_business_unit_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_is_business_unit, v_event.id);
+    ELSE
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_INVALID_ARGUMENT',
+        DETAIL = format('unexpected event_type: %s', v_event.event_type);
+    END IF;
+
+    PERFORM orgunit.apply_org_event_ext_payload(
+      p_tenant_uuid,
+      v_event.org_node_key,
+      v_event.effective_date,
+      v_event.event_type,
+      v_payload,
+      v_event.id
+    );
+  END LOOP;
+
+  SELECT v.node_path INTO v_root_path
+  FROM orgunit.org_unit_versions v
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.org_node_key = p_org_node_key
+  ORDER BY lower(v.validity) DESC
+  LIMIT 1;
+
+  IF v_root_path IS NULL THEN
+    RETURN;
+  END IF;
+
+  SELECT array_agg(DISTINCT v.org_node_key) INTO v_org_node_keys
+  FROM orgunit.org_unit_versions v
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.node_path <@ v_root_path;
+
+  PERFORM orgunit.assert_org_unit_validity(p_tenant_uuid, v_org_node_keys);
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.rebuild_org_unit_versions_for_org(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8)
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  PERFORM orgunit.rebuild_org_unit_versions_for_org_with_pending_event(
+    p_tenant_uuid,
+    p_org_node_key,
+    NULL,
+    NULL,
+    NULL,
+    NULL,
+    NULL,
+    NULL,
+    NULL,
+    NULL,
+    NULL,
+    NULL
+  );
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.extract_orgunit_snapshot(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_as_of date
+)
+RETURNS jsonb
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_row orgunit.org_unit_versions%ROWTYPE;
+  v_snapshot jsonb;
+  v_org_code text;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+  IF p_as_of IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'as_of is required';
+  END IF;
+
+  SELECT * INTO v_row
+  FROM orgunit.org_unit_versions v
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.org_node_key = p_org_node_key
+    AND v.validity @> p_as_of
+  ORDER BY lower(v.validity) DESC
+  LIMIT 1;
+
+  IF NOT FOUND THEN
+    RETURN NULL;
+  END IF;
+
+  SELECT c.org_code INTO v_org_code
+  FROM orgunit.org_unit_codes c
+  WHERE c.tenant_uuid = p_tenant_uuid
+    AND c.org_node_key = p_org_node_key
+  LIMIT 1;
+
+  v_snapshot := to_jsonb(v_row) - 'id' - 'tenant_uuid' - 'last_event_id' - 'path_node_keys';
+  IF v_org_code IS NOT NULL THEN
+    v_snapshot := jsonb_set(v_snapshot, '{org_code}', to_jsonb(v_org_code), true);
+  END IF;
+
+  IF jsonb_typeof(v_snapshot) <> 'object' THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_AUDIT_SNAPSHOT_INVALID',
+      DETAIL = format('org_node_key=%s as_of=%s snapshot_type=%s', p_org_node_key, p_as_of, COALESCE(jsonb_typeof(v_snapshot), 'null'));
+  END IF;
+
+  RETURN v_snapshot;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.assert_org_event_snapshots(
+  p_event_type text,
+  p_before_snapshot jsonb,
+  p_after_snapshot jsonb,
+  p_rescind_outcome text
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  IF p_before_snapshot IS NOT NULL AND jsonb_typeof(p_before_snapshot) <> 'object' THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_AUDIT_SNAPSHOT_INVALID',
+      DETAIL = format('event_type=%s before_snapshot_type=%s', p_event_type, jsonb_typeof(p_before_snapshot));
+  END IF;
+
+  IF p_after_snapshot IS NOT NULL AND jsonb_typeof(p_after_snapshot) <> 'object' THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_AUDIT_SNAPSHOT_INVALID',
+      DETAIL = format('event_type=%s after_snapshot_type=%s', p_event_type, jsonb_typeof(p_after_snapshot));
+  END IF;
+
+  IF NOT orgunit.is_org_event_snapshot_presence_valid(
+    p_event_type,
+    p_before_snapshot,
+    p_after_snapshot,
+    p_rescind_outcome
+  ) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_AUDIT_SNAPSHOT_MISSING',
+      DETAIL = format(
+        'event_type=%s before=%s after=%s rescind_outcome=%s',
+        p_event_type,
+        p_before_snapshot IS NOT NULL,
+        p_after_snapshot IS NOT NULL,
+        COALESCE(p_rescind_outcome, 'NULL')
+      );
+  END IF;
+
+  IF NOT orgunit.is_org_event_snapshot_content_valid(
+    p_event_type,
+    p_before_snapshot,
+    p_after_snapshot,
+    p_rescind_outcome
+  ) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_AUDIT_SNAPSHOT_INVALID',
+      DETAIL = format(
+        'event_type=%s incomplete_snapshot_content=true rescind_outcome=%s',
+        p_event_type,
+        COALESCE(p_rescind_outcome, 'NULL')
+      );
+  END IF;
+END;
+$$;
+
+-- Backward-compatible wrapper for existing 3-arg callers during rollout.
+CREATE OR REPLACE FUNCTION orgunit.assert_org_event_snapshots(
+  p_event_type text,
+  p_before_snapshot jsonb,
+  p_after_snapshot jsonb
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  PERFORM orgunit.assert_org_event_snapshots(
+    p_event_type,
+    p_before_snapshot,
+    p_after_snapshot,
+    NULL
+  );
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.submit_org_event(
+  p_event_uuid uuid,
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_event_type text,
+  p_effective_date date,
+  p_payload jsonb,
+  p_request_id text,
+  p_initiator_uuid uuid
+)
+RETURNS bigint
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_lock_key text;
+  v_event_db_id bigint;
+  v_existing orgunit.org_events%ROWTYPE;
+  v_existing_request orgunit.org_events%ROWTYPE;
+  v_payload jsonb;
+  v_parent_org_node_key char(8);
+  v_new_parent_org_node_key char(8);
+  v_name text;
+  v_new_name text;
+  v_manager_uuid uuid;
+  v_is_business_unit boolean;
+  v_org_code text;
+  v_root_path ltree;
+  v_org_node_keys char(8)[];
+  v_status text;
+  v_before_snapshot jsonb;
+  v_after_snapshot jsonb;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_event_uuid IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'event_uuid is required';
+  END IF;
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+  IF p_effective_date IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
+  END IF;
+  IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
+  END IF;
+  IF p_initiator_uuid IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
+  END IF;
+
+  IF p_event_type NOT IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT') THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = format('unsupported event_type: %s', p_event_type);
+  END IF;
+
+  v_lock_key := format('org:write-lock:%s', p_tenant_uuid);
+  PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
+
+  v_payload := COALESCE(p_payload, '{}'::jsonb);
+  IF p_event_type = 'SET_BUSINESS_UNIT' THEN
+    IF NOT (v_payload ? 'is_business_unit') THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_INVALID_ARGUMENT',
+        DETAIL = 'is_business_unit is required';
+    END IF;
+    BEGIN
+      PERFORM (v_payload->>'is_business_unit')::boolean;
+    EXCEPTION
+      WHEN invalid_text_representation THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_INVALID_ARGUMENT',
+          DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
+    END;
+  END IF;
+
+  SELECT * INTO v_existing_request
+  FROM orgunit.org_events
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id
+  LIMIT 1;
+
+  IF FOUND THEN
+    IF v_existing_request.event_uuid <> p_event_uuid
+      OR v_existing_request.org_node_key <> p_org_node_key
+      OR v_existing_request.event_type <> p_event_type
+      OR v_existing_request.effective_date <> p_effective_date
+      OR v_existing_request.payload <> v_payload
+      OR v_existing_request.initiator_uuid <> p_initiator_uuid
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
+        DETAIL = format('request_id=%s', p_request_id);
+    END IF;
+
+    RETURN v_existing_request.id;
+  END IF;
+
+  v_before_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, p_effective_date);
+
+  SELECT * INTO v_existing
+  FROM orgunit.org_events
+  WHERE event_uuid = p_event_uuid;
+
+  IF FOUND THEN
+    IF v_existing.tenant_uuid <> p_tenant_uuid
+      OR v_existing.org_node_key <> p_org_node_key
+      OR v_existing.event_type <> p_event_type
+      OR v_existing.effective_date <> p_effective_date
+      OR v_existing.payload <> v_payload
+      OR v_existing.request_id <> p_request_id
+      OR v_existing.initiator_uuid <> p_initiator_uuid
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_IDEMPOTENCY_REUSED',
+        DETAIL = format('event_uuid=%s existing_id=%s', p_event_uuid, v_existing.id);
+    END IF;
+
+    RETURN v_existing.id;
+  END IF;
+
+  SELECT nextval(pg_get_serial_sequence('orgunit.org_events', 'id')) INTO v_event_db_id;
+
+  IF p_event_type = 'CREATE' THEN
+    v_parent_org_node_key := NULLIF(v_payload->>'parent_org_node_key', '')::char(8);
+    v_name := NULLIF(btrim(v_payload->>'name'), '');
+    v_manager_uuid := NULLIF(v_payload->>'manager_uuid', '')::uuid;
+    v_org_code := NULLIF(v_payload->>'org_code', '');
+    v_status := NULLIF(btrim(v_payload->>'status'), '');
+    v_is_business_unit := NULL;
+    IF v_payload ? 'is_business_unit' THEN
+      BEGIN
+        v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
+      EXCEPTION
+        WHEN invalid_text_representation THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_INVALID_ARGUMENT',
+            DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
+      END;
+    END IF;
+    PERFORM orgunit.apply_create_logic(p_tenant_uuid, p_org_node_key, v_org_code, v_parent_org_node_key, p_effective_date, v_name, v_manager_uuid, v_is_business_unit, v_event_db_id, v_status);
+    SELECT v.node_path INTO v_root_path
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.org_node_key = p_org_node_key
+      AND v.validity @> p_effective_date
+    ORDER BY lower(v.validity) DESC
+    LIMIT 1;
+    PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
+    v_org_node_keys := ARRAY[p_org_node_key];
+  ELSIF p_event_type = 'UPDATE' THEN
+    PERFORM orgunit.apply_update_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_payload, v_event_db_id);
+    IF (v_payload ? 'parent_org_node_key')
+      OR (v_payload ? 'new_parent_org_node_key')
+      OR (v_payload ? 'name')
+      OR (v_payload ? 'new_name')
+    THEN
+      SELECT v.node_path INTO v_root_path
+      FROM orgunit.org_unit_versions v
+      WHERE v.tenant_uuid = p_tenant_uuid
+        AND v.org_node_key = p_org_node_key
+        AND v.validity @> p_effective_date
+      ORDER BY lower(v.validity) DESC
+      LIMIT 1;
+      IF v_root_path IS NOT NULL THEN
+        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
+      END IF;
+    END IF;
+    SELECT array_agg(DISTINCT v.org_node_key) INTO v_org_node_keys
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.org_node_key = p_org_node_key;
+  ELSIF p_event_type = 'MOVE' THEN
+    v_new_parent_org_node_key := NULLIF(v_payload->>'new_parent_org_node_key', '')::char(8);
+    PERFORM orgunit.apply_move_logic(p_tenant_uuid, p_org_node_key, v_new_parent_org_node_key, p_effective_date, v_event_db_id);
+    SELECT v.node_path INTO v_root_path
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.org_node_key = p_org_node_key
+      AND v.validity @> p_effective_date
+    ORDER BY lower(v.validity) DESC
+    LIMIT 1;
+    PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
+    SELECT array_agg(DISTINCT v.org_node_key) INTO v_org_node_keys
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.node_path <@ v_root_path;
+  ELSIF p_event_type = 'RENAME' THEN
+    v_new_name := NULLIF(btrim(v_payload->>'new_name'), '');
+    PERFORM orgunit.apply_rename_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_new_name, v_event_db_id);
+    SELECT v.node_path INTO v_root_path
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.org_node_key = p_org_node_key
+      AND v.validity @> p_effective_date
+    ORDER BY lower(v.validity) DESC
+    LIMIT 1;
+    PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
+    v_org_node_keys := ARRAY[p_org_node_key];
+  ELSIF p_event_type = 'DISABLE' THEN
+    PERFORM orgunit.apply_disable_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_event_db_id);
+    v_org_node_keys := ARRAY[p_org_node_key];
+  ELSIF p_event_type = 'ENABLE' THEN
+    PERFORM orgunit.apply_enable_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_event_db_id);
+    v_org_node_keys := ARRAY[p_org_node_key];
+  ELSIF p_event_type = 'SET_BUSINESS_UNIT' THEN
+    v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
+    PERFORM orgunit.apply_set_business_unit_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_is_business_unit, v_event_db_id);
+    v_org_node_keys := ARRAY[p_org_node_key];
+  END IF;
+
+  PERFORM orgunit.assert_org_unit_validity(p_tenant_uuid, v_org_node_keys);
+
+  v_after_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, p_effective_date);
+  PERFORM orgunit.assert_org_event_snapshots(p_event_type, v_before_snapshot, v_after_snapshot, NULL);
+
+  INSERT INTO orgunit.org_events (
+    id,
+    event_uuid,
+    tenant_uuid,
+    org_node_key,
+    event_type,
+    effective_date,
+    payload,
+    request_id,
+    initiator_uuid,
+    before_snapshot,
+    after_snapshot
+  )
+  VALUES (
+    v_event_db_id,
+    p_event_uuid,
+    p_tenant_uuid,
+    p_org_node_key,
+    p_event_type,
+    p_effective_date,
+    v_payload,
+    p_request_id,
+    p_initiator_uuid,
+    v_before_snapshot,
+    v_after_snapshot
+  );
+
+  RETURN v_event_db_id;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.submit_org_event_rescind(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_target_effective_date date,
+  p_reason text,
+  p_request_id text,
+  p_initiator_uuid uuid
+)
+RETURNS uuid
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_lock_key text;
+  v_target orgunit.org_events%ROWTYPE;
+  v_existing_request orgunit.org_events%ROWTYPE;
+  v_existing_rescind orgunit.org_events%ROWTYPE;
+  v_reason text;
+  v_event_uuid uuid;
+  v_payload jsonb;
+  v_before_snapshot jsonb;
+  v_after_snapshot jsonb;
+  v_event_db_id bigint;
+  v_rescind_outcome text;
+  v_pending_time timestamptz;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+  IF p_target_effective_date IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'target_effective_date is required';
+  END IF;
+  v_reason := btrim(COALESCE(p_reason, ''));
+  IF v_reason = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'reason is required';
+  END IF;
+  IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
+  END IF;
+  IF p_initiator_uuid IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
+  END IF;
+
+  v_lock_key := format('org:write-lock:%s', p_tenant_uuid);
+  PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
+
+  SELECT * INTO v_existing_request
+  FROM orgunit.org_events
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id
+  LIMIT 1;
+
+  IF FOUND THEN
+    IF v_existing_request.event_type <> 'RESCIND_EVENT'
+      OR v_existing_request.org_node_key <> p_org_node_key
+      OR v_existing_request.effective_date <> p_target_effective_date
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
+        DETAIL = format('request_id=%s', p_request_id);
+    END IF;
+
+    RETURN v_existing_request.event_uuid;
+  END IF;
+
+  SELECT e.* INTO v_target
+  FROM orgunit.org_events e
+  JOIN orgunit.org_events_effective ee
+    ON ee.event_uuid = e.event_uuid
+   AND ee.tenant_uuid = e.tenant_uuid
+   AND ee.org_node_key = e.org_node_key
+  WHERE ee.tenant_uuid = p_tenant_uuid
+    AND ee.org_node_key = p_org_node_key
+    AND ee.effective_date = p_target_effective_date
+  LIMIT 1;
+
+  IF NOT FOUND THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_EVENT_NOT_FOUND',
+      DETAIL = format('org_node_key=%s target_effective_date=%s', p_org_node_key, p_target_effective_date);
+  END IF;
+
+  SELECT * INTO v_existing_rescind
+  FROM orgunit.org_events r
+  WHERE r.tenant_uuid = p_tenant_uuid
+    AND r.event_type IN ('RESCIND_EVENT','RESCIND_ORG')
+    AND r.payload->>'target_event_uuid' = v_target.event_uuid::text
+  LIMIT 1;
+
+  IF FOUND THEN
+    RETURN v_existing_rescind.event_uuid;
+  END IF;
+
+  v_payload := jsonb_build_object(
+    'op', 'RESCIND_EVENT',
+    'reason', v_reason,
+    'target_event_uuid', v_target.event_uuid,
+    'target_effective_date', p_target_effective_date
+  );
+
+  v_before_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, p_target_effective_date);
+  v_event_uuid := gen_random_uuid();
+  v_pending_time := now();
+  SELECT nextval(pg_get_serial_sequence('orgunit.org_events', 'id')) INTO v_event_db_id;
+
+  PERFORM orgunit.rebuild_org_unit_versions_for_org_with_pending_event(
+    p_tenant_uuid,
+    p_org_node_key,
+    v_event_db_id,
+    v_event_uuid,
+    'RESCIND_EVENT',
+    p_target_effective_date,
+    v_payload,
+    p_request_id,
+    p_initiator_uuid,
+    v_pending_time,
+    v_pending_time,
+    v_pending_time
+  );
+
+  v_after_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, p_target_effective_date);
+  v_rescind_outcome := CASE WHEN v_after_snapshot IS NULL THEN 'ABSENT' ELSE 'PRESENT' END;
+  PERFORM orgunit.assert_org_event_snapshots('RESCIND_EVENT', v_before_snapshot, v_after_snapshot, v_rescind_outcome);
+
+  INSERT INTO orgunit.org_events (
+    id,
+    tenant_uuid,
+    org_node_key,
+    event_uuid,
+    event_type,
+    effective_date,
+    payload,
+    request_id,
+    initiator_uuid,
+    reason,
+    before_snapshot,
+    after_snapshot,
+    rescind_outcome,
+    tx_time,
+    transaction_time,
+    created_at
+  )
+  VALUES (
+    v_event_db_id,
+    p_tenant_uuid,
+    p_org_node_key,
+    v_event_uuid,
+    'RESCIND_EVENT',
+    p_target_effective_date,
+    v_payload,
+    p_request_id,
+    p_initiator_uuid,
+    v_reason,
+    v_before_snapshot,
+    v_after_snapshot,
+    v_rescind_outcome,
+    v_pending_time,
+    v_pending_time,
+    v_pending_time
+  );
+
+  RETURN v_event_uuid;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.submit_org_rescind(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_reason text,
+  p_request_id text,
+  p_initiator_uuid uuid
+)
+RETURNS int
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_lock_key text;
+  v_reason text;
+  v_root_org_node_key char(8);
+  v_node_path ltree;
+  v_event_count int;
+  v_existing_batch_count int;
+  v_need_apply boolean;
+  v_request_id_seq text;
+  v_payload jsonb;
+  v_existing_request orgunit.org_events%ROWTYPE;
+  v_existing_rescind orgunit.org_events%ROWTYPE;
+  v_before_snapshot jsonb;
+  v_after_snapshot jsonb;
+  v_rescind_event_uuid uuid;
+  v_rescind_event_id bigint;
+  v_rescind_outcome text;
+  v_pending_time timestamptz;
+  rec record;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+  v_reason := btrim(COALESCE(p_reason, ''));
+  IF v_reason = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'reason is required';
+  END IF;
+  IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
+  END IF;
+  IF p_initiator_uuid IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
+  END IF;
+
+  v_lock_key := format('org:write-lock:%s', p_tenant_uuid);
+  PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
+
+  SELECT * INTO v_existing_request
+  FROM orgunit.org_events
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id
+  LIMIT 1;
+
+  IF FOUND THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
+      DETAIL = format('request_id=%s', p_request_id);
+  END IF;
+
+  SELECT t.root_org_node_key INTO v_root_org_node_key
+  FROM orgunit.org_trees t
+  WHERE t.tenant_uuid = p_tenant_uuid
+  LIMIT 1;
+
+  IF v_root_org_node_key = p_org_node_key THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_ROOT_DELETE_FORBIDDEN',
+      DETAIL = format('org_node_key=%s', p_org_node_key);
+  END IF;
+
+  SELECT v.node_path INTO v_node_path
+  FROM orgunit.org_unit_versions v
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.org_node_key = p_org_node_key
+  ORDER BY lower(v.validity) DESC
+  LIMIT 1;
+
+  IF v_node_path IS NOT NULL AND EXISTS (
+    SELECT 1
+    FROM orgunit.org_unit_versions c
+    WHERE c.tenant_uuid = p_tenant_uuid
+      AND c.node_path <@ v_node_path
+      AND c.org_node_key <> p_org_node_key
+    LIMIT 1
+  ) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_HAS_CHILDREN_CANNOT_DELETE',
+      DETAIL = format('org_node_key=%s', p_org_node_key);
+  END IF;
+
+  SELECT COUNT(*) INTO v_event_count
+  FROM orgunit.org_events e
+  WHERE e.tenant_uuid = p_tenant_uuid
+    AND e.org_node_key = p_org_node_key;
+
+  IF v_event_count = 0 THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_EVENT_NOT_FOUND',
+      DETAIL = format('org_node_key=%s', p_org_node_key);
+  END IF;
+
+  SELECT COUNT(*) INTO v_existing_batch_count
+  FROM orgunit.org_events e
+  WHERE e.tenant_uuid = p_tenant_uuid
+    AND e.request_id LIKE p_request_id || '#%';
+
+  IF v_existing_batch_count > 0 AND v_existing_batch_count <> v_event_count THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
+      DETAIL = format('request_id=%s', p_request_id);
+  END IF;
+
+  v_need_apply := false;
+
+  FOR rec IN
+    SELECT
+      row_number() OVER (ORDER BY e.effective_date, e.id) AS seq,
+      e.event_uuid,
+      COALESCE(ee.effective_date, e.effective_date) AS target_effective_date
+    FROM orgunit.org_events e
+    LEFT JOIN orgunit.org_events_effective ee
+      ON ee.event_uuid = e.event_uuid
+     AND ee.tenant_uuid = e.tenant_uuid
+     AND ee.org_node_key = e.org_node_key
+    WHERE e.tenant_uuid = p_tenant_uuid
+      AND e.org_node_key = p_org_node_key
+    ORDER BY e.effective_date, e.id
+  LOOP
+    v_request_id_seq := format('%s#%s', p_request_id, lpad(rec.seq::text, 4, '0'));
+    SELECT * INTO v_existing_request
+    FROM orgunit.org_events e
+    WHERE e.tenant_uuid = p_tenant_uuid
+      AND e.request_id = v_request_id_seq
+    LIMIT 1;
+
+    IF FOUND THEN
+      IF v_existing_request.event_type <> 'RESCIND_ORG'
+        OR v_existing_request.org_node_key <> p_org_node_key
+        OR COALESCE(v_existing_request.payload->>'target_event_uuid', '') <> rec.event_uuid::text
+      THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
+          DETAIL = format('request_id=%s', p_request_id);
+      END IF;
+      CONTINUE;
+    END IF;
+
+    v_need_apply := true;
+  END LOOP;
+
+  IF NOT v_need_apply THEN
+    RETURN v_event_count;
+  END IF;
+
+  FOR rec IN
+    SELECT
+      row_number() OVER (ORDER BY e.effective_date, e.id) AS seq,
+      e.event_uuid,
+      COALESCE(ee.effective_date, e.effective_date) AS target_effective_date
+    FROM orgunit.org_events e
+    LEFT JOIN orgunit.org_events_effective ee
+      ON ee.event_uuid = e.event_uuid
+     AND ee.tenant_uuid = e.tenant_uuid
+     AND ee.org_node_key = e.org_node_key
+    WHERE e.tenant_uuid = p_tenant_uuid
+      AND e.org_node_key = p_org_node_key
+    ORDER BY e.effective_date, e.id
+  LOOP
+    v_request_id_seq := format('%s#%s', p_request_id, lpad(rec.seq::text, 4, '0'));
+    SELECT * INTO v_existing_request
+    FROM orgunit.org_events e
+    WHERE e.tenant_uuid = p_tenant_uuid
+      AND e.request_id = v_request_id_seq
+    LIMIT 1;
+
+    IF FOUND THEN
+      CONTINUE;
+    END IF;
+
+    SELECT * INTO v_existing_rescind
+    FROM orgunit.org_events r
+    WHERE r.tenant_uuid = p_tenant_uuid
+      AND r.event_type IN ('RESCIND_EVENT','RESCIND_ORG')
+      AND r.payload->>'target_event_uuid' = rec.event_uuid::text
+    LIMIT 1;
+
+    IF FOUND THEN
+      CONTINUE;
+    END IF;
+
+    v_payload := jsonb_build_object(
+      'op', 'RESCIND_ORG',
+      'reason', v_reason,
+      'batch_request_id', p_request_id,
+      'target_event_uuid', rec.event_uuid,
+      'target_effective_date', rec.target_effective_date
+    );
+
+    v_before_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, rec.target_effective_date);
+    v_rescind_event_uuid := gen_random_uuid();
+    v_pending_time := now();
+    SELECT nextval(pg_get_serial_sequence('orgunit.org_events', 'id')) INTO v_rescind_event_id;
+
+    PERFORM orgunit.rebuild_org_unit_versions_for_org_with_pending_event(
+      p_tenant_uuid,
+      p_org_node_key,
+      v_rescind_event_id,
+      v_rescind_event_uuid,
+      'RESCIND_ORG',
+      rec.target_effective_date,
+      v_payload,
+      v_request_id_seq,
+      p_initiator_uuid,
+      v_pending_time,
+      v_pending_time,
+      v_pending_time
+    );
+
+    v_after_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, rec.target_effective_date);
+    v_rescind_outcome := CASE WHEN v_after_snapshot IS NULL THEN 'ABSENT' ELSE 'PRESENT' END;
+    PERFORM orgunit.assert_org_event_snapshots('RESCIND_ORG', v_before_snapshot, v_after_snapshot, v_rescind_outcome);
+
+    INSERT INTO orgunit.org_events (
+      id,
+      tenant_uuid,
+      org_node_key,
+      event_uuid,
+      event_type,
+      effective_date,
+      payload,
+      request_id,
+      initiator_uuid,
+      reason,
+      before_snapshot,
+      after_snapshot,
+      rescind_outcome,
+      tx_time,
+      transaction_time,
+      created_at
+    )
+    VALUES (
+      v_rescind_event_id,
+      p_tenant_uuid,
+      p_org_node_key,
+      v_rescind_event_uuid,
+      'RESCIND_ORG',
+      rec.target_effective_date,
+      v_payload,
+      v_request_id_seq,
+      p_initiator_uuid,
+      v_reason,
+      v_before_snapshot,
+      v_after_snapshot,
+      v_rescind_outcome,
+      v_pending_time,
+      v_pending_time,
+      v_pending_time
+    );
+  END LOOP;
+
+  RETURN v_event_count;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.submit_org_event_correction(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_target_effective_date date,
+  p_patch jsonb,
+  p_request_id text,
+  p_initiator_uuid uuid
+)
+RETURNS uuid
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_lock_key text;
+  v_target orgunit.org_events%ROWTYPE;
+  v_existing_request orgunit.org_events%ROWTYPE;
+  v_existing_rescind orgunit.org_events%ROWTYPE;
+  v_patch jsonb;
+  v_payload jsonb;
+  v_effective_payload jsonb;
+  v_target_effective date;
+  v_prev_effective date;
+  v_new_effective date;
+  v_next_effective date;
+  v_parent_org_node_key char(8);
+  v_target_path ltree;
+  v_descendant_min_create date;
+  v_event_uuid uuid;
+  v_before_snapshot jsonb;
+  v_after_snapshot jsonb;
+  v_event_db_id bigint;
+  v_pending_time timestamptz;
+  v_base_ext jsonb;
+  v_patch_ext jsonb;
+  v_ext_merged jsonb;
+  v_base_labels jsonb;
+  v_patch_labels jsonb;
+  v_labels_merged jsonb;
+  v_label_key text;
+  v_label_remove_keys text[];
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+  IF p_target_effective_date IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'target_effective_date is required';
+  END IF;
+  IF p_patch IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'patch is required';
+  END IF;
+  IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
+  END IF;
+  IF p_initiator_uuid IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
+  END IF;
+
+  v_patch := p_patch;
+  IF jsonb_typeof(v_patch) <> 'object' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'patch must be an object';
+  END IF;
+
+  IF v_patch ? 'ext' AND jsonb_typeof(v_patch->'ext') <> 'object' THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_EXT_PAYLOAD_INVALID_SHAPE',
+      DETAIL = 'patch.ext must be an object';
+  END IF;
+  IF v_patch ? 'ext_labels_snapshot' AND jsonb_typeof(v_patch->'ext_labels_snapshot') <> 'object' THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_EXT_PAYLOAD_INVALID_SHAPE',
+      DETAIL = 'patch.ext_labels_snapshot must be an object';
+  END IF;
+  IF v_patch ? 'ext_labels_snapshot' THEN
+    FOR v_label_key IN
+      SELECT key
+      FROM jsonb_object_keys(v_patch->'ext_labels_snapshot') AS t(key)
+    LOOP
+      IF NOT (COALESCE(v_patch->'ext', '{}'::jsonb) ? v_label_key) THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_EXT_LABEL_SNAPSHOT_NOT_ALLOWED',
+          DETAIL = format('field_key=%s', v_label_key);
+      END IF;
+    END LOOP;
+  END IF;
+
+  v_lock_key := format('org:write-lock:%s', p_tenant_uuid);
+  PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
+
+  SELECT e.* INTO v_target
+  FROM orgunit.org_events e
+  JOIN orgunit.org_events_effective ee
+    ON ee.event_uuid = e.event_uuid
+   AND ee.tenant_uuid = e.tenant_uuid
+   AND ee.org_node_key = e.org_node_key
+  WHERE ee.tenant_uuid = p_tenant_uuid
+    AND ee.org_node_key = p_org_node_key
+    AND ee.effective_date = p_target_effective_date
+  LIMIT 1;
+
+  IF NOT FOUND THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_EVENT_NOT_FOUND',
+      DETAIL = format('org_node_key=%s target_effective_date=%s', p_org_node_key, p_target_effective_date);
+  END IF;
+
+  SELECT * INTO v_existing_rescind
+  FROM orgunit.org_events r
+  WHERE r.tenant_uuid = p_tenant_uuid
+    AND r.event_type IN ('RESCIND_EVENT','RESCIND_ORG')
+    AND r.payload->>'target_event_uuid' = v_target.event_uuid::text
+  LIMIT 1;
+
+  IF FOUND THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_EVENT_RESCINDED',
+      DETAIL = format('event_uuid=%s', v_target.event_uuid);
+  END IF;
+
+  SELECT ee.effective_date, ee.payload
+  INTO v_target_effective, v_effective_payload
+  FROM orgunit.org_events_effective ee
+  WHERE ee.tenant_uuid = p_tenant_uuid
+    AND ee.org_node_key = p_org_node_key
+    AND ee.event_uuid = v_target.event_uuid
+  LIMIT 1;
+
+  v_payload := (COALESCE(v_effective_payload, '{}'::jsonb) - 'ext' - 'ext_labels_snapshot')
+    || (v_patch - 'ext' - 'ext_labels_snapshot');
+
+  v_base_ext := CASE
+    WHEN jsonb_typeof(COALESCE(v_effective_payload, '{}'::jsonb)->'ext') = 'object'
+      THEN COALESCE(v_effective_payload, '{}'::jsonb)->'ext'
+    ELSE '{}'::jsonb
+  END;
+  v_patch_ext := CASE
+    WHEN jsonb_typeof(v_patch->'ext') = 'object'
+      THEN v_patch->'ext'
+    ELSE '{}'::jsonb
+  END;
+  v_ext_merged := v_base_ext || v_patch_ext;
+
+  v_base_labels := CASE
+    WHEN jsonb_typeof(COALESCE(v_effective_payload, '{}'::jsonb)->'ext_labels_snapshot') = 'object'
+      THEN COALESCE(v_effective_payload, '{}'::jsonb)->'ext_labels_snapshot'
+    ELSE '{}'::jsonb
+  END;
+  v_patch_labels := CASE
+    WHEN jsonb_typeof(v_patch->'ext_labels_snapshot') = 'object'
+      THEN v_patch->'ext_labels_snapshot'
+    ELSE '{}'::jsonb
+  END;
+  v_labels_merged := v_base_labels || v_patch_labels;
+
+  SELECT array_agg(key) INTO v_label_remove_keys
+  FROM jsonb_each(v_ext_merged)
+  WHERE value = 'null'::jsonb;
+  v_label_remove_keys := COALESCE(v_label_remove_keys, ARRAY[]::text[]);
+  v_labels_merged := v_labels_merged - v_label_remove_keys;
+
+  IF COALESCE(v_effective_payload, '{}'::jsonb) ? 'ext' OR v_patch ? 'ext' THEN
+    v_payload := v_payload || jsonb_build_object('ext', v_ext_merged);
+  END IF;
+  IF COALESCE(v_effective_payload, '{}'::jsonb) ? 'ext_labels_snapshot' OR v_patch ? 'ext_labels_snapshot' THEN
+    v_payload := v_payload || jsonb_build_object('ext_labels_snapshot', v_labels_merged);
+  END IF;
+  v_new_effective := v_target_effective;
+
+  IF v_payload ? 'effective_date' THEN
+    BEGIN
+      v_new_effective := NULLIF(btrim(v_payload->>'effective_date'), '')::date;
+    EXCEPTION
+      WHEN invalid_text_representation THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'EFFECTIVE_DATE_INVALID',
+          DETAIL = format('effective_date=%s', v_payload->>'effective_date');
+    END;
+    IF v_new_effective IS NULL THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'EFFECTIVE_DATE_INVALID',
+        DETAIL = 'effective_date is required';
+    END IF;
+    -- keep effective_date in payload for audit and correction view
+  END IF;
+
+  SELECT MAX(e.effective_date) INTO v_prev_effective
+  FROM orgunit.org_events_effective e
+  WHERE e.tenant_uuid = p_tenant_uuid
+    AND e.org_node_key = p_org_node_key
+    AND e.effective_date < v_target_effective;
+
+  SELECT MIN(e.effective_date) INTO v_next_effective
+  FROM orgunit.org_events_effective e
+  WHERE e.tenant_uuid = p_tenant_uuid
+    AND e.org_node_key = p_org_node_key
+    AND e.effective_date > v_target_effective;
+
+  IF v_prev_effective IS NOT NULL AND v_new_effective <= v_prev_effective THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'EFFECTIVE_DATE_OUT_OF_RANGE',
+      DETAIL = format('prev=%s new=%s', v_prev_effective, v_new_effective);
+  END IF;
+  IF v_next_effective IS NOT NULL AND v_new_effective >= v_next_effective THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'EFFECTIVE_DATE_OUT_OF_RANGE',
+      DETAIL = format('next=%s new=%s', v_next_effective, v_new_effective);
+  END IF;
+
+  IF EXISTS (
+    SELECT 1
+    FROM orgunit.org_events_effective e
+    WHERE e.tenant_uuid = p_tenant_uuid
+      AND e.org_node_key = p_org_node_key
+      AND e.effective_date = v_new_effective
+      AND e.event_uuid <> v_target.event_uuid
+    LIMIT 1
+  ) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'EVENT_DATE_CONFLICT',
+      DETAIL = format('org_node_key=%s effective_date=%s', p_org_node_key, v_new_effective);
+  END IF;
+
+  IF v_payload ? 'parent_org_node_key' THEN
+    v_parent_org_node_key := NULLIF(v_payload->>'parent_org_node_key', '')::char(8);
+    IF v_parent_org_node_key IS NOT NULL THEN
+      IF v_parent_org_node_key = p_org_node_key THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_CYCLE_DETECTED',
+          DETAIL = format('org_node_key=%s parent_org_node_key=%s', p_org_node_key, v_parent_org_node_key);
+      END IF;
+      IF NOT EXISTS (
+        SELECT 1
+        FROM orgunit.org_unit_versions v
+        WHERE v.tenant_uuid = p_tenant_uuid
+          AND v.org_node_key = v_parent_org_node_key
+          AND v.validity @> v_new_effective
+        LIMIT 1
+      ) THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_PARENT_NOT_FOUND_AS_OF',
+          DETAIL = format('parent_org_node_key=%s as_of=%s', v_parent_org_node_key, v_new_effective);
+      END IF;
+    END IF;
+  END IF;
+
+  -- Guard high-risk create reordering that would force replay to fail after full-table churn.
+  IF v_target.event_type = 'CREATE' AND v_new_effective > v_target_effective THEN
+    SELECT v.node_path INTO v_target_path
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.org_node_key = p_org_node_key
+      AND v.validity @> v_target_effective
+    ORDER BY lower(v.validity) DESC
+    LIMIT 1;
+
+    IF v_target_path IS NOT NULL THEN
+      SELECT MIN(e.effective_date) INTO v_descendant_min_create
+      FROM orgunit.org_events_effective e
+      WHERE e.tenant_uuid = p_tenant_uuid
+        AND e.event_type = 'CREATE'
+        AND e.org_node_key <> p_org_node_key
+        AND EXISTS (
+          SELECT 1
+          FROM orgunit.org_unit_versions dv
+          WHERE dv.tenant_uuid = p_tenant_uuid
+            AND dv.org_node_key = e.org_node_key
+            AND dv.node_path <@ v_target_path
+          LIMIT 1
+        );
+
+      IF v_descendant_min_create IS NOT NULL AND v_descendant_min_create < v_new_effective THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_HIGH_RISK_REORDER_FORBIDDEN',
+          DETAIL = format(
+            'org_node_key=%s target_effective=%s new_effective=%s descendant_create=%s',
+            p_org_node_key,
+            v_target_effective,
+            v_new_effective,
+            v_descendant_min_create
+          );
+      END IF;
+    END IF;
+  END IF;
+
+  v_payload := jsonb_build_object(
+    'op', 'CORRECT_EVENT',
+    'target_event_uuid', v_target.event_uuid,
+    'target_effective_date', p_target_effective_date
+  ) || v_payload;
+
+  SELECT * INTO v_existing_request
+  FROM orgunit.org_events
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id
+  LIMIT 1;
+
+  IF FOUND THEN
+    IF v_existing_request.event_type <> 'CORRECT_EVENT'
+      OR v_existing_request.org_node_key <> p_org_node_key
+      OR v_existing_request.payload <> v_payload
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
+        DETAIL = format('request_id=%s', p_request_id);
+    END IF;
+
+    RETURN v_existing_request.event_uuid;
+  END IF;
+
+  v_before_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, p_target_effective_date);
+  v_event_uuid := gen_random_uuid();
+  v_pending_time := now();
+  SELECT nextval(pg_get_serial_sequence('orgunit.org_events', 'id')) INTO v_event_db_id;
+
+  PERFORM orgunit.rebuild_org_unit_versions_for_org_with_pending_event(
+    p_tenant_uuid,
+    p_org_node_key,
+    v_event_db_id,
+    v_event_uuid,
+    'CORRECT_EVENT',
+    p_target_effective_date,
+    v_payload,
+    p_request_id,
+    p_initiator_uuid,
+    v_pending_time,
+    v_pending_time,
+    v_pending_time
+  );
+
+  v_after_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, p_target_effective_date);
+  PERFORM orgunit.assert_org_event_snapshots('CORRECT_EVENT', v_before_snapshot, v_after_snapshot, NULL);
+
+  INSERT INTO orgunit.org_events (
+    id,
+    tenant_uuid,
+    org_node_key,
+    event_uuid,
+    event_type,
+    effective_date,
+    payload,
+    request_id,
+    initiator_uuid,
+    before_snapshot,
+    after_snapshot,
+    tx_time,
+    transaction_time,
+    created_at
   )
-  INSERT INTO orgunit.org_unit_versions (
-    tenant_uuid,
-    org_node_key,
-    parent_org_node_key,
-    node_path,
-    validity,
-    name,
-    full_name_path,
-    status,
-    is_business_unit,
-    manager_uuid,
-    ext_str_01,
-    ext_str_02,
-    ext_str_03,
-    ext_str_04,
-    ext_str_05,
-    ext_str_06,
-    ext_str_07,
-    ext_str_08,
-    ext_str_09,
-    ext_str_10,
-    ext_str_11,
-    ext_str_12,
-    ext_str_13,
-    ext_str_14,
-    ext_str_15,
-    ext_str_16,
-    ext_str_17,
-    ext_str_18,
-    ext_str_19,
-    ext_str_20,
-    ext_str_21,
-    ext_str_22,
-    ext_str_23,
-    ext_str_24,
-    ext_str_25,
-    ext_str_26,
-    ext_str_27,
-    ext_str_28,
-    ext_str_29,
-    ext_str_30,
-    ext_str_31,
-    ext_str_32,
-    ext_str_33,
-    ext_str_34,
-    ext_str_35,
-    ext_str_36,
-    ext_str_37,
-    ext_str_38,
-    ext_str_39,
-    ext_str_40,
-    ext_str_41,
-    ext_str_42,
-    ext_str_43,
-    ext_str_44,
-    ext_str_45,
-    ext_str_46,
-    ext_str_47,
-    ext_str_48,
-    ext_str_49,
-    ext_str_50,
-    ext_str_51,
-    ext_str_52,
-    ext_str_53,
-    ext_str_54,
-    ext_str_55,
-    ext_str_56,
-    ext_str_57,
-    ext_str_58,
-    ext_str_59,
-    ext_str_60,
-    ext_str_61,
-    ext_str_62,
-    ext_str_63,
-    ext_str_64,
-    ext_str_65,
-    ext_str_66,
-    ext_str_67,
-    ext_str_68,
-    ext_str_69,
-    ext_str_70,
-    ext_int_01,
-    ext_int_02,
-    ext_int_03,
-    ext_int_04,
-    ext_int_05,
-    ext_int_06,
-    ext_int_07,
-    ext_int_08,
-    ext_int_09,
-    ext_int_10,
-    ext_int_11,
-    ext_int_12,
-    ext_int_13,
-    ext_int_14,
-    ext_int_15,
-    ext_uuid_01,
-    ext_uuid_02,
-    ext_uuid_03,
-    ext_uuid_04,
-    ext_uuid_05,
-    ext_uuid_06,
-    ext_uuid_07,
-    ext_uuid_08,
-    ext_uuid_09,
-    ext_uuid_10,
-    ext_bool_01,
-    ext_bool_02,
-    ext_bool_03,
-    ext_bool_04,
-    ext_bool_05,
-    ext_bool_06,
-    ext_bool_07,
-    ext_bool_08,
-    ext_bool_09,
-    ext_bool_10,
-    ext_bool_11,
-    ext_bool_12,
-    ext_bool_13,
-    ext_bool_14,
-    ext_bool_15,
-    ext_date_01,
-    ext_date_02,
-    ext_date_03,
-    ext_date_04,
-    ext_date_05,
-    ext_date_06,
-    ext_date_07,
-    ext_date_08,
-    ext_date_09,
-    ext_date_10,
-    ext_date_11,
-    ext_date_12,
-    ext_date_13,
-    ext_date_14,
-    ext_date_15,
-    ext_num_01,
-    ext_num_02,
-    ext_num_03,
-    ext_num_04,
-    ext_num_05,
-    ext_num_06,
-    ext_num_07,
-    ext_num_08,
-    ext_num_09,
-    ext_num_10,
-    ext_labels_snapshot,
-    last_event_id
+  VALUES (
+    v_event_db_id,
+    p_tenant_uuid,
+    p_org_node_key,
+    v_event_uuid,
+    'CORRECT_EVENT',
+    p_target_effective_date,
+    v_payload,
+    p_request_id,
+    p_initiator_uuid,
+    v_before_snapshot,
+    v_after_snapshot,
+    v_pending_time,
+    v_pending_time,
+    v_pending_time
+  );
+
+  RETURN v_event_uuid;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.submit_org_status_correction(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_target_effective_date date,
+  p_target_status text,
+  p_request_id text,
+  p_initiator_uuid uuid
+)
+RETURNS uuid
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_lock_key text;
+  v_target orgunit.org_events%ROWTYPE;
+  v_existing_request orgunit.org_events%ROWTYPE;
+  v_existing_rescind orgunit.org_events%ROWTYPE;
+  v_target_status text;
+  v_target_effective date;
+  v_effective_payload jsonb;
+  v_event_uuid uuid;
+  v_payload jsonb;
+  v_before_snapshot jsonb;
+  v_after_snapshot jsonb;
+  v_event_db_id bigint;
+  v_pending_time timestamptz;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+  IF p_target_effective_date IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'target_effective_date is required';
+  END IF;
+  IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
+  END IF;
+  IF p_initiator_uuid IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
+  END IF;
+
+  v_target_status := lower(btrim(COALESCE(p_target_status, '')));
+  IF v_target_status IN ('enabled', '有效') THEN
+    v_target_status := 'active';
+  ELSIF v_target_status IN ('inactive', '无效') THEN
+    v_target_status := 'disabled';
+  END IF;
+  IF v_target_status NOT IN ('active', 'disabled') THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'target_status invalid';
+  END IF;
+
+  v_lock_key := format('org:write-lock:%s', p_tenant_uuid);
+  PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
+
+  SELECT e.* INTO v_target
+  FROM orgunit.org_events e
+  JOIN orgunit.org_events_effective ee
+    ON ee.event_uuid = e.event_uuid
+   AND ee.tenant_uuid = e.tenant_uuid
+   AND ee.org_node_key = e.org_node_key
+  WHERE ee.tenant_uuid = p_tenant_uuid
+    AND ee.org_node_key = p_org_node_key
+    AND ee.effective_date = p_target_effective_date
+  LIMIT 1;
+
+  IF NOT FOUND THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_EVENT_NOT_FOUND',
+      DETAIL = format('org_node_key=%s target_effective_date=%s', p_org_node_key, p_target_effective_date);
+  END IF;
+
+  IF v_target.event_type NOT IN ('ENABLE', 'DISABLE') THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_STATUS_CORRECTION_UNSUPPORTED_TARGET',
+      DETAIL = format('event_type=%s', v_target.event_type);
+  END IF;
+
+  SELECT * INTO v_existing_rescind
+  FROM orgunit.org_events r
+  WHERE r.tenant_uuid = p_tenant_uuid
+    AND r.event_type IN ('RESCIND_EVENT','RESCIND_ORG')
+    AND r.payload->>'target_event_uuid' = v_target.event_uuid::text
+  LIMIT 1;
+
+  IF FOUND THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_EVENT_RESCINDED',
+      DETAIL = format('event_uuid=%s', v_target.event_uuid);
+  END IF;
+
+  SELECT ee.effective_date, ee.payload
+  INTO v_target_effective, v_effective_payload
+  FROM orgunit.org_events_effective ee
+  WHERE ee.tenant_uuid = p_tenant_uuid
+    AND ee.org_node_key = p_org_node_key
+    AND ee.event_uuid = v_target.event_uuid
+  LIMIT 1;
+
+  IF v_target_effective IS NULL THEN
+    v_target_effective := v_target.effective_date;
+    v_effective_payload := v_target.payload;
+  END IF;
+
+  v_payload := jsonb_build_object(
+    'op', 'CORRECT_STATUS',
+    'target_status', v_target_status,
+    'target_event_uuid', v_target.event_uuid,
+    'target_effective_date', p_target_effective_date
+  );
+
+  SELECT * INTO v_existing_request
+  FROM orgunit.org_events
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id
+  LIMIT 1;
+
+  IF FOUND THEN
+    IF v_existing_request.event_type <> 'CORRECT_STATUS'
+      OR v_existing_request.org_node_key <> p_org_node_key
+      OR v_existing_request.payload <> v_payload
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
+        DETAIL = format('request_id=%s', p_request_id);
+    END IF;
+
+    RETURN v_existing_request.event_uuid;
+  END IF;
+
+  v_before_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, p_target_effective_date);
+  v_event_uuid := gen_random_uuid();
+  v_pending_time := now();
+  SELECT nextval(pg_get_serial_sequence('orgunit.org_events', 'id')) INTO v_event_db_id;
+
+  PERFORM orgunit.rebuild_org_unit_versions_for_org_with_pending_event(
+    p_tenant_uuid,
+    p_org_node_key,
+    v_event_db_id,
+    v_event_uuid,
+    'CORRECT_STATUS',
+    p_target_effective_date,
+    v_payload,
+    p_request_id,
+    p_initiator_uuid,
+    v_pending_time,
+    v_pending_time,
+    v_pending_time
+  );
+
+  v_after_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, p_target_effective_date);
+  PERFORM orgunit.assert_org_event_snapshots('CORRECT_STATUS', v_before_snapshot, v_after_snapshot, NULL);
+
+  INSERT INTO orgunit.org_events (
+    id,
+    tenant_uuid,
+    org_node_key,
+    event_uuid,
+    event_type,
+    effective_date,
+    payload,
+    request_id,
+    initiator_uuid,
+    before_snapshot,
+    after_snapshot,
+    tx_time,
+    transaction_time,
+    created_at
   )
-  SELECT
-    u.tenant_uuid,
-    u.org_node_key,
-    CASE WHEN u.org_node_key = p_org_node_key THEN p_new_parent_org_node_key ELSE u.parent_org_node_key END,
-    CASE
-      WHEN u.org_node_key = p_org_node_key THEN v_new_prefix
-      ELSE v_new_prefix || subpath(u.node_path, v_old_level)
-    END,
-    daterange(p_effective_date, upper(u.validity), '[)'),
-    u.name,
-    u.full_name_path,
-    u.status,
-    u.is_business_unit,
-    u.manager_uuid,
-    u.ext_str_01,
-    u.ext_str_02,
-    u.ext_str_03,
-    u.ext_str_04,
-    u.ext_str_05,
-    u.ext_str_06,
-    u.ext_str_07,
-    u.ext_str_08,
-    u.ext_str_09,
-    u.ext_str_10,
-    u.ext_str_11,
-    u.ext_str_12,
-    u.ext_str_13,
-    u.ext_str_14,
-    u.ext_str_15,
-    u.ext_str_16,
-    u.ext_str_17,
-    u.ext_str_18,
-    u.ext_str_19,
-    u.ext_str_20,
-    u.ext_str_21,
-    u.ext_str_22,
-    u.ext_str_23,
-    u.ext_str_24,
-    u.ext_str_25,
-    u.ext_str_26,
-    u.ext_str_27,
-    u.ext_str_28,
-    u.ext_str_29,
-    u.ext_str_30,
-    u.ext_str_31,
-    u.ext_str_32,
-    u.ext_str_33,
-    u.ext_str_34,
-    u.ext_str_35,
-    u.ext_str_36,
-    u.ext_str_37,
-    u.ext_str_38,
-    u.ext_str_39,
-    u.ext_str_40,
-    u.ext_str_41,
-    u.ext_str_42,
-    u.ext_str_43,
-    u.ext_str_44,
-    u.ext_str_45,
-    u.ext_str_46,
-    u.ext_str_47,
-    u.ext_str_48,
-    u.ext_str_49,
-    u.ext_str_50,
-    u.ext_str_51,
-    u.ext_str_52,
-    u.ext_str_53,
-    u.ext_str_54,
-    u.ext_str_55,
-    u.ext_str_56,
-    u.ext_str_57,
-    u.ext_str_58,
-    u.ext_str_59,
-    u.ext_str_60,
-    u.ext_str_61,
-    u.ext_str_62,
-    u.ext_str_63,
-    u.ext_str_64,
-    u.ext_str_65,
-    u.ext_str_66,
-    u.ext_str_67,
-    u.ext_str_68,
-    u.ext_str_69,
-    u.ext_str_70,
-    u.ext_int_01,
-    u.ext_int_02,
-    u.ext_int_03,
-    u.ext_int_04,
-    u.ext_int_05,
-    u.ext_int_06,
-    u.ext_int_07,
-    u.ext_int_08,
-    u.ext_int_09,
-    u.ext_int_10,
-    u.ext_int_11,
-    u.ext_int_12,
-    u.ext_int_13,
-    u.ext_int_14,
-    u.ext_int_15,
-    u.ext_uuid_01,
-    u.ext_uuid_02,
-    u.ext_uuid_03,
-    u.ext_uuid_04,
-    u.ext_uuid_05,
-    u.ext_uuid_06,
-    u.ext_uuid_07,
-    u.ext_uuid_08,
-    u.ext_uuid_09,
-    u.ext_uuid_10,
-    u.ext_bool_01,
-    u.ext_bool_02,
-    u.ext_bool_03,
-    u.ext_bool_04,
-    u.ext_bool_05,
-    u.ext_bool_06,
-    u.ext_bool_07,
-    u.ext_bool_08,
-    u.ext_bool_09,
-    u.ext_bool_10,
-    u.ext_bool_11,
-    u.ext_bool_12,
-    u.ext_bool_13,
-    u.ext_bool_14,
-    u.ext_bool_15,
-    u.ext_date_01,
-    u.ext_date_02,
-    u.ext_date_03,
-    u.ext_date_04,
-    u.ext_date_05,
-    u.ext_date_06,
-    u.ext_date_07,
-    u.ext_date_08,
-    u.ext_date_09,
-    u.ext_date_10,
-    u.ext_date_11,
-    u.ext_date_12,
-    u.ext_date_13,
-    u.ext_date_14,
-    u.ext_date_15,
-    u.ext_num_01,
-    u.ext_num_02,
-    u.ext_num_03,
-    u.ext_num_04,
-    u.ext_num_05,
-    u.ext_num_06,
-    u.ext_num_07,
-    u.ext_num_08,
-    u.ext_num_09,
-    u.ext_num_10,
-    u.ext_labels_snapshot,
-    p_event_db_id
-  FROM upd u;
+  VALUES (
+    v_event_db_id,
+    p_tenant_uuid,
+    p_org_node_key,
+    v_event_uuid,
+    'CORRECT_STATUS',
+    p_target_effective_date,
+    v_payload,
+    p_request_id,
+    p_initiator_uuid,
+    v_before_snapshot,
+    v_after_snapshot,
+    v_pending_time,
+    v_pending_time,
+    v_pending_time
+  );
 
-  UPDATE orgunit.org_unit_versions v
-  SET
-    node_path = CASE
-      WHEN v.org_node_key = p_org_node_key THEN v_new_prefix
-        ELSE v_new_prefix || subpath(v.node_path, v_old_level)
-      END,
-    parent_org_node_key = CASE WHEN v.org_node_key = p_org_node_key THEN p_new_parent_org_node_key ELSE v.parent_org_node_key END,
-    last_event_id = p_event_db_id
+  RETURN v_event_uuid;
+END;
+$$;
+
+-- end: modules/orgunit/infrastructure/persistence/schema/00026_orgunit_org_node_key_engine.sql
+
+-- begin: modules/orgunit/infrastructure/persistence/schema/00027_orgunit_org_node_key_read.sql
+-- DEV-PLAN-320 P3: org_node_key runtime read overlay
+DROP FUNCTION IF EXISTS orgunit.get_org_snapshot(uuid, date);
+
+CREATE OR REPLACE FUNCTION orgunit.get_org_snapshot(p_tenant_uuid uuid, p_query_date date)
+RETURNS TABLE (
+  org_node_key char(8),
+  parent_org_node_key char(8),
+  name varchar(255),
+  is_business_unit boolean,
+  full_name_path text,
+  depth int,
+  manager_uuid uuid,
+  node_path ltree,
+  path_node_keys text[]
+)
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_query_date IS NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = 'query_date is required';
+  END IF;
+
+  RETURN QUERY
+  SELECT
+    v.org_node_key,
+    v.parent_org_node_key,
+    v.name,
+    v.is_business_unit,
+    v.full_name_path,
+    nlevel(v.node_path) - 1 AS depth,
+    v.manager_uuid,
+    v.node_path,
+    v.path_node_keys
+  FROM orgunit.org_unit_versions v
   WHERE v.tenant_uuid = p_tenant_uuid
-    AND v.node_path <@ v_old_path
-    AND lower(v.validity) >= p_effective_date;
+    AND v.status = 'active'
+    AND v.validity @> p_query_date
+  ORDER BY v.node_path;
 END;
 $$;
 
-CREATE OR REPLACE FUNCTION orgunit.apply_rename_logic(
-  p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_effective_date date,
-  p_new_name text,
-  p_event_db_id bigint
-)
-RETURNS void
-LANGUAGE plpgsql
-AS $$
-DECLARE
-  v_stop_date date;
-BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+-- end: modules/orgunit/infrastructure/persistence/schema/00027_orgunit_org_node_key_read.sql
+
+-- begin: modules/orgunit/infrastructure/persistence/schema/00028_orgunit_org_node_key_submit_allocator.sql
+-- DEV-PLAN-320 P3: submit_org_event org_node_key allocator overlay
+CREATE OR REPLACE FUNCTION orgunit.submit_org_event(
+  p_event_uuid uuid,
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_event_type text,
+  p_effective_date date,
+  p_payload jsonb,
+  p_request_id text,
+  p_initiator_uuid uuid
+)
+RETURNS bigint
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_lock_key text;
+  v_event_db_id bigint;
+  v_existing orgunit.org_events%ROWTYPE;
+  v_existing_request orgunit.org_events%ROWTYPE;
+  v_payload jsonb;
+  v_org_node_key char(8);
+  v_parent_org_node_key char(8);
+  v_new_parent_org_node_key char(8);
+  v_name text;
+  v_new_name text;
+  v_status text;
+  v_manager_uuid uuid;
+  v_is_business_unit boolean;
+  v_org_code text;
+  v_root_path ltree;
+  v_org_node_keys char(8)[];
+  v_before_snapshot jsonb;
+  v_after_snapshot jsonb;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_event_uuid IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'event_uuid is required';
+  END IF;
+  IF p_effective_date IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
+  END IF;
+  IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
+  END IF;
+  IF p_initiator_uuid IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
+  END IF;
+
+  IF p_event_type NOT IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT') THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = format('unsupported event_type: %s', p_event_type);
+  END IF;
+
+  v_lock_key := format('org:write-lock:%s', p_tenant_uuid);
+  PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
+
+  v_payload := COALESCE(p_payload, '{}'::jsonb);
+  IF p_event_type = 'SET_BUSINESS_UNIT' THEN
+    IF NOT (v_payload ? 'is_business_unit') THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_INVALID_ARGUMENT',
+        DETAIL = 'is_business_unit is required';
+    END IF;
+    BEGIN
+      PERFORM (v_payload->>'is_business_unit')::boolean;
+    EXCEPTION
+      WHEN invalid_text_representation THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_INVALID_ARGUMENT',
+          DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
+    END;
+  END IF;
+
+  IF p_event_type = 'CREATE' AND p_org_node_key IS NULL THEN
+    SELECT * INTO v_existing
+    FROM orgunit.org_events
+    WHERE event_uuid = p_event_uuid;
+
+    IF FOUND THEN
+      IF v_existing.tenant_uuid <> p_tenant_uuid
+        OR v_existing.event_type <> p_event_type
+        OR v_existing.effective_date <> p_effective_date
+        OR v_existing.payload <> v_payload
+        OR v_existing.request_id <> p_request_id
+        OR v_existing.initiator_uuid <> p_initiator_uuid
+      THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_IDEMPOTENCY_REUSED',
+          DETAIL = format('event_uuid=%s existing_id=%s', p_event_uuid, v_existing.id);
+      END IF;
+
+      RETURN v_existing.id;
+    END IF;
+
+    v_org_node_key := orgunit.allocate_org_node_key(p_tenant_uuid);
+  ELSE
+    IF p_org_node_key IS NULL THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+    END IF;
+    v_org_node_key := p_org_node_key;
+  END IF;
+
+  SELECT * INTO v_existing_request
+  FROM orgunit.org_events
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id
+  LIMIT 1;
+
+  IF FOUND THEN
+    -- Idempotency key is request_id: allow server-generated event_uuid to differ across retries.
+    IF v_existing_request.org_node_key <> v_org_node_key
+      OR v_existing_request.event_type <> p_event_type
+      OR v_existing_request.effective_date <> p_effective_date
+      OR v_existing_request.payload <> v_payload
+      OR v_existing_request.initiator_uuid <> p_initiator_uuid
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
+        DETAIL = format('request_id=%s', p_request_id);
+    END IF;
+
+    RETURN v_existing_request.id;
+  END IF;
+
+  v_before_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, v_org_node_key, p_effective_date);
+
+  SELECT * INTO v_existing
+  FROM orgunit.org_events
+  WHERE event_uuid = p_event_uuid;
+
+  IF FOUND THEN
+    IF v_existing.tenant_uuid <> p_tenant_uuid
+      OR v_existing.org_node_key <> v_org_node_key
+      OR v_existing.event_type <> p_event_type
+      OR v_existing.effective_date <> p_effective_date
+      OR v_existing.payload <> v_payload
+      OR v_existing.request_id <> p_request_id
+      OR v_existing.initiator_uuid <> p_initiator_uuid
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_IDEMPOTENCY_REUSED',
+        DETAIL = format('event_uuid=%s existing_id=%s', p_event_uuid, v_existing.id);
+    END IF;
+
+    RETURN v_existing.id;
+  END IF;
+
+  SELECT nextval(pg_get_serial_sequence('orgunit.org_events', 'id')) INTO v_event_db_id;
+
+  IF p_event_type = 'CREATE' THEN
+    v_parent_org_node_key := NULLIF(v_payload->>'parent_org_node_key', '')::char(8);
+    v_name := NULLIF(btrim(v_payload->>'name'), '');
+    v_manager_uuid := NULLIF(v_payload->>'manager_uuid', '')::uuid;
+    v_org_code := NULLIF(v_payload->>'org_code', '');
+    v_status := NULLIF(btrim(v_payload->>'status'), '');
+    v_is_business_unit := NULL;
+    IF v_payload ? 'is_business_unit' THEN
+      BEGIN
+        v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
+      EXCEPTION
+        WHEN invalid_text_representation THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_INVALID_ARGUMENT',
+            DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
+      END;
+    END IF;
+    PERFORM orgunit.apply_create_logic(p_tenant_uuid, v_org_node_key, v_org_code, v_parent_org_node_key, p_effective_date, v_name, v_manager_uuid, v_is_business_unit, v_event_db_id, v_status);
+    SELECT v.node_path INTO v_root_path
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.org_node_key = v_org_node_key
+      AND v.validity @> p_effective_date
+    ORDER BY lower(v.validity) DESC
+    LIMIT 1;
+    PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
+    v_org_node_keys := ARRAY[v_org_node_key];
+  ELSIF p_event_type = 'UPDATE' THEN
+    PERFORM orgunit.apply_update_logic(p_tenant_uuid, v_org_node_key, p_effective_date, v_payload, v_event_db_id);
+
+    IF (v_payload ? 'parent_org_node_key')
+      OR (v_payload ? 'new_parent_org_node_key')
+      OR (v_payload ? 'name')
+      OR (v_payload ? 'new_name')
+    THEN
+      SELECT v.node_path INTO v_root_path
+      FROM orgunit.org_unit_versions v
+      WHERE v.tenant_uuid = p_tenant_uuid
+        AND v.org_node_key = v_org_node_key
+        AND v.validity @> p_effective_date
+      ORDER BY lower(v.validity) DESC
+      LIMIT 1;
+
+      IF v_root_path IS NOT NULL THEN
+        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
+      END IF;
+    END IF;
+
+    IF (v_payload ? 'parent_org_node_key') OR (v_payload ? 'new_parent_org_node_key') THEN
+      SELECT v.node_path INTO v_root_path
+      FROM orgunit.org_unit_versions v
+      WHERE v.tenant_uuid = p_tenant_uuid
+        AND v.org_node_key = v_org_node_key
+        AND v.validity @> p_effective_date
+      ORDER BY lower(v.validity) DESC
+      LIMIT 1;
 
-  IF p_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
-  END IF;
-  IF p_effective_date IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
-  END IF;
-  IF p_new_name IS NULL OR btrim(p_new_name) = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'new_name is required';
+      SELECT array_agg(DISTINCT v.org_node_key) INTO v_org_node_keys
+      FROM orgunit.org_unit_versions v
+      WHERE v.tenant_uuid = p_tenant_uuid
+        AND v.node_path <@ v_root_path;
+    ELSE
+      v_org_node_keys := ARRAY[v_org_node_key];
+    END IF;
+  ELSIF p_event_type = 'MOVE' THEN
+    v_new_parent_org_node_key := NULLIF(v_payload->>'new_parent_org_node_key', '')::char(8);
+    PERFORM orgunit.apply_move_logic(p_tenant_uuid, v_org_node_key, v_new_parent_org_node_key, p_effective_date, v_event_db_id);
+    SELECT v.node_path INTO v_root_path
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.org_node_key = v_org_node_key
+      AND v.validity @> p_effective_date
+    ORDER BY lower(v.validity) DESC
+    LIMIT 1;
+    PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
+    SELECT array_agg(DISTINCT v.org_node_key) INTO v_org_node_keys
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.node_path <@ v_root_path;
+  ELSIF p_event_type = 'RENAME' THEN
+    v_new_name := NULLIF(btrim(v_payload->>'new_name'), '');
+    PERFORM orgunit.apply_rename_logic(p_tenant_uuid, v_org_node_key, p_effective_date, v_new_name, v_event_db_id);
+    SELECT v.node_path INTO v_root_path
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.org_node_key = v_org_node_key
+      AND v.validity @> p_effective_date
+    ORDER BY lower(v.validity) DESC
+    LIMIT 1;
+    PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
+    v_org_node_keys := ARRAY[v_org_node_key];
+  ELSIF p_event_type = 'DISABLE' THEN
+    PERFORM orgunit.apply_disable_logic(p_tenant_uuid, v_org_node_key, p_effective_date, v_event_db_id);
+    v_org_node_keys := ARRAY[v_org_node_key];
+  ELSIF p_event_type = 'ENABLE' THEN
+    PERFORM orgunit.apply_enable_logic(p_tenant_uuid, v_org_node_key, p_effective_date, v_event_db_id);
+    v_org_node_keys := ARRAY[v_org_node_key];
+  ELSIF p_event_type = 'SET_BUSINESS_UNIT' THEN
+    v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
+    PERFORM orgunit.apply_set_business_unit_logic(p_tenant_uuid, v_org_node_key, p_effective_date, v_is_business_unit, v_event_db_id);
+    v_org_node_keys := ARRAY[v_org_node_key];
   END IF;
 
-  IF NOT EXISTS (
-    SELECT 1
-    FROM orgunit.org_unit_versions
-    WHERE tenant_uuid = p_tenant_uuid
-      AND org_node_key = p_org_node_key
-      AND validity @> p_effective_date
-    LIMIT 1
-  ) THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_NOT_FOUND_AS_OF',
-      DETAIL = format('org_node_key=%s as_of=%s', p_org_node_key, p_effective_date);
-  END IF;
+  PERFORM orgunit.apply_org_event_ext_payload(
+    p_tenant_uuid,
+    v_org_node_key,
+    p_effective_date,
+    p_event_type,
+    v_payload,
+    v_event_db_id
+  );
 
-  PERFORM orgunit.split_org_unit_version_at(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_db_id);
+  PERFORM orgunit.assert_org_unit_validity(p_tenant_uuid, v_org_node_keys);
 
-  SELECT MIN(e.effective_date) INTO v_stop_date
-  FROM orgunit.org_events_effective e
-  WHERE e.tenant_uuid = p_tenant_uuid
-    AND e.org_node_key = p_org_node_key
-    AND e.event_type = 'RENAME'
-    AND e.effective_date > p_effective_date;
+  v_after_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, v_org_node_key, p_effective_date);
+  PERFORM orgunit.assert_org_event_snapshots(p_event_type, v_before_snapshot, v_after_snapshot, NULL);
 
-  UPDATE orgunit.org_unit_versions
-  SET name = p_new_name, last_event_id = p_event_db_id
-  WHERE tenant_uuid = p_tenant_uuid
-    AND org_node_key = p_org_node_key
-    AND lower(validity) >= p_effective_date
-    AND (v_stop_date IS NULL OR lower(validity) < v_stop_date);
+  INSERT INTO orgunit.org_events (
+    id,
+    event_uuid,
+    tenant_uuid,
+    org_node_key,
+    event_type,
+    effective_date,
+    payload,
+    request_id,
+    initiator_uuid,
+    before_snapshot,
+    after_snapshot
+  )
+  VALUES (
+    v_event_db_id,
+    p_event_uuid,
+    p_tenant_uuid,
+    v_org_node_key,
+    p_event_type,
+    p_effective_date,
+    v_payload,
+    p_request_id,
+    p_initiator_uuid,
+    v_before_snapshot,
+    v_after_snapshot
+  );
+
+  RETURN v_event_db_id;
 END;
 $$;
 
-CREATE OR REPLACE FUNCTION orgunit.apply_disable_logic(
-  p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_effective_date date,
-  p_event_db_id bigint
-)
-RETURNS void
-LANGUAGE plpgsql
-AS $$
-BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
 
-  IF p_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
-  END IF;
-  IF p_effective_date IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
-  END IF;
+ALTER FUNCTION orgunit.submit_org_event(uuid, uuid, char(8), text, date, jsonb, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.submit_org_event(uuid, uuid, char(8), text, date, jsonb, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.submit_org_event(uuid, uuid, char(8), text, date, jsonb, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+-- end: modules/orgunit/infrastructure/persistence/schema/00028_orgunit_org_node_key_submit_allocator.sql
+
+-- begin: modules/orgunit/infrastructure/persistence/schema/00029_orgunit_org_node_key_engine_privileges.sql
+-- DEV-PLAN-320 P3: org_node_key runtime function privileges
+GRANT SELECT ON TABLE orgunit.org_events_effective TO orgunit_kernel;
+
+ALTER FUNCTION orgunit.submit_org_event_correction(uuid, char(8), date, jsonb, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.submit_org_event_correction(uuid, char(8), date, jsonb, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.submit_org_event_correction(uuid, char(8), date, jsonb, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+ALTER FUNCTION orgunit.submit_org_status_correction(uuid, char(8), date, text, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.submit_org_status_correction(uuid, char(8), date, text, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.submit_org_status_correction(uuid, char(8), date, text, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+ALTER FUNCTION orgunit.submit_org_event_rescind(uuid, char(8), date, text, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.submit_org_event_rescind(uuid, char(8), date, text, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.submit_org_event_rescind(uuid, char(8), date, text, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+ALTER FUNCTION orgunit.submit_org_rescind(uuid, char(8), text, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.submit_org_rescind(uuid, char(8), text, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.submit_org_rescind(uuid, char(8), text, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+-- end: modules/orgunit/infrastructure/persistence/schema/00029_orgunit_org_node_key_engine_privileges.sql
+
+-- begin: modules/orgunit/infrastructure/persistence/schema/00030_orgunit_field_configs_schema.sql
+-- OrgUnit tenant field configs (metadata) + wide-table ext slots (Phase 1)
+-- SSOT: docs/dev-plans/100a-org-metadata-wide-table-phase0-contract-freeze-readiness.md
+-- SSOT: docs/dev-plans/100b-org-metadata-wide-table-phase1-schema-and-metadata-skeleton.md
+
+CREATE TABLE IF NOT EXISTS orgunit.tenant_field_configs (
+  tenant_uuid uuid NOT NULL,
+  field_key text NOT NULL,
+  physical_col text NOT NULL,
+  value_type text NOT NULL,
+  data_source_type text NOT NULL,
+  data_source_config jsonb NOT NULL DEFAULT '{}'::jsonb,
+  display_label text NULL,
+  enabled_on date NOT NULL,
+  disabled_on date NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  disabled_at timestamptz NULL,
+  PRIMARY KEY (tenant_uuid, field_key),
+  -- field_key will be used as payload.ext key; keep it simple and safe.
+  CONSTRAINT tenant_field_configs_field_key_format_check CHECK (field_key ~ '^[a-z][a-z0-9_]{0,62}$'),
+  CONSTRAINT tenant_field_configs_value_type_check CHECK (value_type IN ('text','int','uuid','bool','date','numeric')),
+  CONSTRAINT tenant_field_configs_data_source_type_check CHECK (data_source_type IN ('PLAIN','DICT','ENTITY')),
+  CONSTRAINT tenant_field_configs_data_source_config_is_object_check CHECK (jsonb_typeof(data_source_config) = 'object'),
+  CONSTRAINT tenant_field_configs_display_label_check CHECK (
+    display_label IS NULL OR (display_label = btrim(display_label) AND display_label <> '')
+  ),
+  -- data_source_config shape (SSOT: DEV-PLAN-100A §4.3)
+  CONSTRAINT tenant_field_configs_plain_config_check CHECK (
+    data_source_type <> 'PLAIN' OR data_source_config = '{}'::jsonb
+  ),
+  CONSTRAINT tenant_field_configs_dict_config_check CHECK (
+    data_source_type <> 'DICT' OR (
+      value_type = 'text'
+      AND data_source_config ? 'dict_code'
+      AND jsonb_typeof(data_source_config->'dict_code') = 'string'
+      AND NULLIF(btrim(data_source_config->>'dict_code'), '') IS NOT NULL
+      -- forbid extra keys to avoid "hidden config" drift
+      AND data_source_config = jsonb_build_object('dict_code', data_source_config->'dict_code')
+    )
+  ),
+  CONSTRAINT tenant_field_configs_entity_config_check CHECK (
+    data_source_type <> 'ENTITY' OR (
+      data_source_config ? 'entity'
+      AND jsonb_typeof(data_source_config->'entity') = 'string'
+      AND NULLIF(btrim(data_source_config->>'entity'), '') IS NOT NULL
+      AND data_source_config ? 'id_kind'
+      AND jsonb_typeof(data_source_config->'id_kind') = 'string'
+      AND (data_source_config->>'id_kind') IN ('uuid','int')
+      AND (
+        ((data_source_config->>'id_kind') = 'uuid' AND value_type = 'uuid')
+        OR
+        ((data_source_config->>'id_kind') = 'int' AND value_type = 'int')
+      )
+      -- forbid extra keys to avoid "hidden config" drift
+      AND data_source_config = jsonb_build_object(
+        'entity', data_source_config->'entity',
+        'id_kind', data_source_config->'id_kind'
+      )
+    )
+  ),
+  -- physical_col is used for dynamic SQL allowlist; keep the format strict.
+  CONSTRAINT tenant_field_configs_physical_col_format_check CHECK (
+    physical_col ~ '^ext_(str|int|uuid|bool|date|num)_[0-9]{2}$'
+  ),
+  CONSTRAINT tenant_field_configs_physical_col_group_check CHECK (
+    (value_type = 'text' AND physical_col LIKE 'ext_str_%')
+    OR (value_type = 'int' AND physical_col LIKE 'ext_int_%')
+    OR (value_type = 'uuid' AND physical_col LIKE 'ext_uuid_%')
+    OR (value_type = 'bool' AND physical_col LIKE 'ext_bool_%')
+    OR (value_type = 'date' AND physical_col LIKE 'ext_date_%')
+    OR (value_type = 'numeric' AND physical_col LIKE 'ext_num_%')
+  ),
+  CONSTRAINT tenant_field_configs_disabled_on_check CHECK (disabled_on IS NULL OR disabled_on >= enabled_on),
+  CONSTRAINT tenant_field_configs_physical_col_unique UNIQUE (tenant_uuid, physical_col)
+);
+
+CREATE TABLE IF NOT EXISTS orgunit.tenant_field_config_events (
+  id bigserial PRIMARY KEY,
+  event_uuid uuid NOT NULL,
+  tenant_uuid uuid NOT NULL,
+  event_type text NOT NULL,
+  field_key text NOT NULL,
+  payload jsonb NOT NULL DEFAULT '{}'::jsonb,
+  request_id text NOT NULL,
+  initiator_uuid uuid NOT NULL,
+  transaction_time timestamptz NOT NULL DEFAULT now(),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT tenant_field_config_events_event_type_check CHECK (event_type IN ('ENABLE','DISABLE','REKEY')),
+  CONSTRAINT tenant_field_config_events_field_key_format_check CHECK (field_key ~ '^[a-z][a-z0-9_]{0,62}$'),
+  CONSTRAINT tenant_field_config_events_request_id_unique UNIQUE (tenant_uuid, request_id),
+  CONSTRAINT tenant_field_config_events_event_uuid_unique UNIQUE (event_uuid),
+  CONSTRAINT tenant_field_config_events_payload_is_object_check CHECK (jsonb_typeof(payload) = 'object')
+);
+
+CREATE INDEX IF NOT EXISTS tenant_field_config_events_tenant_time_idx
+  ON orgunit.tenant_field_config_events (tenant_uuid, transaction_time DESC, id DESC);
 
-  IF NOT EXISTS (
-    SELECT 1
-    FROM orgunit.org_unit_versions
-    WHERE tenant_uuid = p_tenant_uuid
-      AND org_node_key = p_org_node_key
-      AND validity @> p_effective_date
-    LIMIT 1
-  ) THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_NOT_FOUND_AS_OF',
-      DETAIL = format('org_node_key=%s as_of=%s', p_org_node_key, p_effective_date);
-  END IF;
+ALTER TABLE orgunit.tenant_field_config_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.tenant_field_config_events FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.tenant_field_config_events;
+CREATE POLICY tenant_isolation ON orgunit.tenant_field_config_events
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
 
-  PERFORM orgunit.split_org_unit_version_at(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_db_id);
+ALTER TABLE orgunit.tenant_field_configs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.tenant_field_configs FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.tenant_field_configs;
+CREATE POLICY tenant_isolation ON orgunit.tenant_field_configs
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
 
-  UPDATE orgunit.org_unit_versions
-  SET status = 'disabled', last_event_id = p_event_db_id
-  WHERE tenant_uuid = p_tenant_uuid
-    AND org_node_key = p_org_node_key
-    AND lower(validity) >= p_effective_date;
-END;
-$$;
+-- -------------------------------------------------------------------
+-- DB guards (fail-closed): no direct DML; only orgunit_kernel can write.
+-- -------------------------------------------------------------------
 
-CREATE OR REPLACE FUNCTION orgunit.apply_enable_logic(
-  p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_effective_date date,
-  p_event_db_id bigint
-)
-RETURNS void
+CREATE OR REPLACE FUNCTION orgunit.guard_tenant_field_configs_write()
+RETURNS trigger
 LANGUAGE plpgsql
 AS $$
 BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
-
-  IF p_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
-  END IF;
-  IF p_effective_date IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
-  END IF;
-
-  IF NOT EXISTS (
-    SELECT 1
-    FROM orgunit.org_unit_versions
-    WHERE tenant_uuid = p_tenant_uuid
-      AND org_node_key = p_org_node_key
-      AND validity @> p_effective_date
-    LIMIT 1
-  ) THEN
+  IF current_user <> 'orgunit_kernel' THEN
     RAISE EXCEPTION USING
-      MESSAGE = 'ORG_NOT_FOUND_AS_OF',
-      DETAIL = format('org_node_key=%s as_of=%s', p_org_node_key, p_effective_date);
+      MESSAGE = 'ORGUNIT_FIELD_CONFIGS_WRITE_FORBIDDEN',
+      DETAIL = format('role=%s table=%s', current_user, TG_TABLE_NAME);
   END IF;
 
-  PERFORM orgunit.split_org_unit_version_at(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_db_id);
-
-  UPDATE orgunit.org_unit_versions
-  SET status = 'active', last_event_id = p_event_db_id
-  WHERE tenant_uuid = p_tenant_uuid
-    AND org_node_key = p_org_node_key
-    AND lower(validity) >= p_effective_date;
+  IF TG_OP = 'DELETE' THEN
+    RETURN OLD;
+  END IF;
+  RETURN NEW;
 END;
 $$;
 
-CREATE OR REPLACE FUNCTION orgunit.apply_set_business_unit_logic(
-  p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_effective_date date,
-  p_is_business_unit boolean,
-  p_event_db_id bigint
-)
-RETURNS void
-LANGUAGE plpgsql
-AS $$
-DECLARE
-  v_stop_date date;
-  v_status text;
-  v_root_org_node_key char(8);
-BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
-
-  IF p_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
-  END IF;
-  IF p_effective_date IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
-  END IF;
-  IF p_is_business_unit IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'is_business_unit is required';
-  END IF;
-
-  SELECT v.status INTO v_status
-  FROM orgunit.org_unit_versions v
-  WHERE v.tenant_uuid = p_tenant_uuid
-    AND v.org_node_key = p_org_node_key
-    AND v.validity @> p_effective_date
-  ORDER BY lower(v.validity) DESC
-  LIMIT 1;
-
-  IF v_status IS NULL THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_NOT_FOUND_AS_OF',
-      DETAIL = format('org_node_key=%s as_of=%s', p_org_node_key, p_effective_date);
-  END IF;
-
-  SELECT t.root_org_node_key INTO v_root_org_node_key
-  FROM orgunit.org_trees t
-  WHERE t.tenant_uuid = p_tenant_uuid;
-
-  IF v_root_org_node_key IS NOT NULL AND v_root_org_node_key = p_org_node_key AND p_is_business_unit = false THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_ROOT_BUSINESS_UNIT_REQUIRED',
-      DETAIL = format('org_node_key=%s', p_org_node_key);
-  END IF;
-
-  PERFORM orgunit.split_org_unit_version_at(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_db_id);
+DROP TRIGGER IF EXISTS guard_tenant_field_configs_write ON orgunit.tenant_field_configs;
+CREATE TRIGGER guard_tenant_field_configs_write
+BEFORE INSERT OR UPDATE OR DELETE ON orgunit.tenant_field_configs
+FOR EACH ROW EXECUTE FUNCTION orgunit.guard_tenant_field_configs_write();
 
-  SELECT MIN(e.effective_date) INTO v_stop_date
-  FROM orgunit.org_events_effective e
-  WHERE e.tenant_uuid = p_tenant_uuid
-    AND e.org_node_key = p_org_node_key
-    AND e.event_type = 'SET_BUSINESS_UNIT'
-    AND e.effective_date > p_effective_date;
+DROP TRIGGER IF EXISTS guard_tenant_field_config_events_write ON orgunit.tenant_field_config_events;
+CREATE TRIGGER guard_tenant_field_config_events_write
+BEFORE INSERT OR UPDATE OR DELETE ON orgunit.tenant_field_config_events
+FOR EACH ROW EXECUTE FUNCTION orgunit.guard_tenant_field_configs_write();
 
-  UPDATE orgunit.org_unit_versions
-  SET is_business_unit = p_is_business_unit, last_event_id = p_event_db_id
-  WHERE tenant_uuid = p_tenant_uuid
-    AND org_node_key = p_org_node_key
-    AND lower(validity) >= p_effective_date
-    AND (v_stop_date IS NULL OR lower(validity) < v_stop_date);
-END;
-$$;
+-- -------------------------------------------------------------------
+-- Mapping immutability + disabled_on rules (defense-in-depth).
+-- -------------------------------------------------------------------
 
-CREATE OR REPLACE FUNCTION orgunit.apply_update_logic(
-  p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_effective_date date,
-  p_payload jsonb,
-  p_event_db_id bigint
-)
-RETURNS void
+CREATE OR REPLACE FUNCTION orgunit.assert_tenant_field_configs_update_allowed()
+RETURNS trigger
 LANGUAGE plpgsql
 AS $$
 DECLARE
-  v_payload jsonb;
-  v_parent_org_node_key char(8);
-  v_name text;
-  v_status text;
-  v_is_business_unit boolean;
-  v_manager_uuid uuid;
+  v_allow_rekey boolean := (current_setting('app.orgunit_field_config_allow_rekey', true) = 'on');
 BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
-
-  IF p_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
-  END IF;
-  IF p_effective_date IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
-  END IF;
-
-  v_payload := COALESCE(p_payload, '{}'::jsonb);
-  IF jsonb_typeof(v_payload) <> 'object' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'payload must be an object';
-  END IF;
-
-  -- 1) parent (move)
-  IF v_payload ? 'parent_org_node_key' OR v_payload ? 'new_parent_org_node_key' THEN
-    v_parent_org_node_key := NULLIF(COALESCE(v_payload->>'parent_org_node_key', v_payload->>'new_parent_org_node_key'), '')::char(8);
-    IF v_parent_org_node_key IS NULL THEN
-      RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'parent_org_node_key is required';
-    END IF;
-    PERFORM orgunit.apply_move_logic(p_tenant_uuid, p_org_node_key, v_parent_org_node_key, p_effective_date, p_event_db_id);
+  IF TG_OP <> 'UPDATE' THEN
+    RETURN NEW;
   END IF;
 
-  -- 2) status (enable/disable)
-  IF v_payload ? 'status' THEN
-    v_status := lower(btrim(COALESCE(v_payload->>'status', '')));
-    IF v_status IN ('enabled', '有效') THEN
-      v_status := 'active';
-    ELSIF v_status IN ('inactive', '无效') THEN
-      v_status := 'disabled';
-    END IF;
-    IF v_status = 'active' THEN
-      PERFORM orgunit.apply_enable_logic(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_db_id);
-    ELSIF v_status = 'disabled' THEN
-      PERFORM orgunit.apply_disable_logic(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_db_id);
-    ELSE
-      RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'status invalid';
+  -- Mapping is immutable after enable. Rekey requires explicit one-shot opt-in.
+  IF NEW.field_key <> OLD.field_key THEN
+    IF NOT v_allow_rekey
+      OR NEW.physical_col <> OLD.physical_col
+      OR NEW.value_type <> OLD.value_type
+      OR NEW.data_source_type <> OLD.data_source_type
+      OR NEW.data_source_config <> OLD.data_source_config
+      OR NEW.display_label IS DISTINCT FROM OLD.display_label
+      OR NEW.enabled_on <> OLD.enabled_on
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_FIELD_CONFIG_MAPPING_IMMUTABLE',
+        DETAIL = format('field_key=%s', OLD.field_key);
     END IF;
+  ELSIF NEW.physical_col <> OLD.physical_col
+    OR NEW.value_type <> OLD.value_type
+    OR NEW.data_source_type <> OLD.data_source_type
+    OR NEW.data_source_config <> OLD.data_source_config
+    OR NEW.display_label IS DISTINCT FROM OLD.display_label
+    OR NEW.enabled_on <> OLD.enabled_on
+  THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_FIELD_CONFIG_MAPPING_IMMUTABLE',
+      DETAIL = format('field_key=%s', OLD.field_key);
   END IF;
 
-  -- 3) is_business_unit
-  IF v_payload ? 'is_business_unit' THEN
-    BEGIN
-      v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
-    EXCEPTION
-      WHEN invalid_text_representation THEN
-        RAISE EXCEPTION USING
-          MESSAGE = 'ORG_INVALID_ARGUMENT',
-          DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
-    END;
-    PERFORM orgunit.apply_set_business_unit_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_is_business_unit, p_event_db_id);
+  -- disabled_on cannot be "unset".
+  IF OLD.disabled_on IS NOT NULL AND NEW.disabled_on IS NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID',
+      DETAIL = format('field_key=%s', OLD.field_key);
   END IF;
 
-  -- 4) manager
-  IF v_payload ? 'manager_uuid' THEN
-    v_manager_uuid := NULLIF(v_payload->>'manager_uuid', '')::uuid;
-    IF NOT EXISTS (
-      SELECT 1
-      FROM orgunit.org_unit_versions
-      WHERE tenant_uuid = p_tenant_uuid
-        AND org_node_key = p_org_node_key
-        AND validity @> p_effective_date
-      LIMIT 1
-    ) THEN
+  -- When setting disabled_on for the first time, forbid backdating.
+  IF OLD.disabled_on IS NULL AND NEW.disabled_on IS NOT NULL THEN
+    IF NEW.disabled_on < current_date THEN
       RAISE EXCEPTION USING
-        MESSAGE = 'ORG_NOT_FOUND_AS_OF',
-        DETAIL = format('org_node_key=%s as_of=%s', p_org_node_key, p_effective_date);
+        MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID',
+        DETAIL = format('field_key=%s disabled_on=%s', OLD.field_key, NEW.disabled_on);
     END IF;
-
-    PERFORM orgunit.split_org_unit_version_at(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_db_id);
-
-    UPDATE orgunit.org_unit_versions
-    SET manager_uuid = v_manager_uuid, last_event_id = p_event_db_id
-    WHERE tenant_uuid = p_tenant_uuid
-      AND org_node_key = p_org_node_key
-      AND lower(validity) >= p_effective_date;
+    RETURN NEW;
   END IF;
 
-  -- 5) name (rename)
-  IF v_payload ? 'name' OR v_payload ? 'new_name' THEN
-    v_name := NULLIF(btrim(COALESCE(v_payload->>'name', v_payload->>'new_name')), '');
-    IF v_name IS NULL THEN
-      RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'name is required';
+  -- If disabled_on changes, it must be "not effective yet" and only postponed.
+  IF OLD.disabled_on IS NOT NULL AND NEW.disabled_on IS NOT NULL AND NEW.disabled_on <> OLD.disabled_on THEN
+    IF current_date >= OLD.disabled_on THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID',
+        DETAIL = format('field_key=%s disabled_on=%s', OLD.field_key, OLD.disabled_on);
+    END IF;
+    IF NEW.disabled_on <= OLD.disabled_on THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID',
+        DETAIL = format('field_key=%s old=%s new=%s', OLD.field_key, OLD.disabled_on, NEW.disabled_on);
     END IF;
-    PERFORM orgunit.apply_rename_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_name, p_event_db_id);
   END IF;
+
+  RETURN NEW;
 END;
 $$;
 
-CREATE OR REPLACE FUNCTION orgunit.rebuild_full_name_path_subtree(
-  p_tenant_uuid uuid,
-  p_root_path ltree,
-  p_from_date date
-)
-RETURNS void
-LANGUAGE plpgsql
-AS $$
-BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+DROP TRIGGER IF EXISTS tenant_field_configs_update_allowed ON orgunit.tenant_field_configs;
+CREATE TRIGGER tenant_field_configs_update_allowed
+BEFORE UPDATE ON orgunit.tenant_field_configs
+FOR EACH ROW EXECUTE FUNCTION orgunit.assert_tenant_field_configs_update_allowed();
 
-  IF p_root_path IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'root_path is required';
-  END IF;
-  IF p_from_date IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'from_date is required';
-  END IF;
+-- -------------------------------------------------------------------
+-- Wide-table ext slots.
+-- -------------------------------------------------------------------
 
-  UPDATE orgunit.org_unit_versions v
-  SET full_name_path = (
-    SELECT string_agg(a.name, ' / ' ORDER BY t.idx)
-    FROM unnest(v.path_node_keys) WITH ORDINALITY AS t(uid, idx)
-    JOIN orgunit.org_unit_versions a
-      ON a.tenant_uuid = v.tenant_uuid
-     AND a.org_node_key = t.uid::char(8)
-     AND a.validity @> lower(v.validity)
-  )
-  WHERE v.tenant_uuid = p_tenant_uuid
-    AND v.node_path <@ p_root_path
-    AND lower(v.validity) >= p_from_date;
-END;
-$$;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_01 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_02 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_03 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_04 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_05 text NULL;
 
-CREATE OR REPLACE FUNCTION orgunit.assert_org_unit_validity(
-  p_tenant_uuid uuid,
-  p_org_node_keys char(8)[]
-)
-RETURNS void
-LANGUAGE plpgsql
-AS $$
-BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_int_01 int NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_int_02 int NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_int_03 int NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_int_04 int NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_int_05 int NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_int_06 int NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_int_07 int NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_int_08 int NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_int_09 int NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_int_10 int NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_int_11 int NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_int_12 int NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_int_13 int NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_int_14 int NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_int_15 int NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_uuid_01 uuid NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_uuid_02 uuid NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_uuid_03 uuid NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_uuid_04 uuid NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_uuid_05 uuid NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_uuid_06 uuid NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_uuid_07 uuid NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_uuid_08 uuid NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_uuid_09 uuid NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_uuid_10 uuid NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_bool_01 boolean NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_date_01 date NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_bool_02 boolean NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_bool_03 boolean NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_bool_04 boolean NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_bool_05 boolean NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_bool_06 boolean NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_bool_07 boolean NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_bool_08 boolean NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_bool_09 boolean NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_bool_10 boolean NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_bool_11 boolean NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_bool_12 boolean NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_bool_13 boolean NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_bool_14 boolean NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_bool_15 boolean NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_date_02 date NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_date_03 date NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_date_04 date NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_date_05 date NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_date_06 date NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_date_07 date NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_date_08 date NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_date_09 date NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_date_10 date NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_date_11 date NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_date_12 date NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_date_13 date NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_date_14 date NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_date_15 date NULL;
 
-  IF p_org_node_keys IS NULL OR array_length(p_org_node_keys, 1) IS NULL THEN
-    RETURN;
-  END IF;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_num_01 numeric NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_num_02 numeric NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_num_03 numeric NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_num_04 numeric NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_num_05 numeric NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_num_06 numeric NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_num_07 numeric NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_num_08 numeric NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_num_09 numeric NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_num_10 numeric NULL;
 
-  IF EXISTS (
-    WITH ordered AS (
-      SELECT
-        org_node_key,
-        validity,
-        lag(validity) OVER (PARTITION BY org_node_key ORDER BY lower(validity)) AS prev_validity
-      FROM orgunit.org_unit_versions
-      WHERE tenant_uuid = p_tenant_uuid
-        AND org_node_key = ANY(p_org_node_keys)
-    )
-    SELECT 1
-    FROM ordered
-    WHERE prev_validity IS NOT NULL
-      AND lower(validity) <> upper(prev_validity)
-    LIMIT 1
-  ) THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_VALIDITY_GAP',
-      DETAIL = 'org_unit_versions must be gapless';
-  END IF;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_06 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_07 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_08 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_09 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_10 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_11 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_12 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_13 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_14 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_15 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_16 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_17 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_18 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_19 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_20 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_21 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_22 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_23 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_24 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_25 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_26 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_27 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_28 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_29 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_30 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_31 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_32 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_33 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_34 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_35 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_36 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_37 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_38 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_39 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_40 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_41 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_42 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_43 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_44 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_45 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_46 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_47 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_48 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_49 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_50 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_51 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_52 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_53 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_54 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_55 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_56 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_57 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_58 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_59 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_60 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_61 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_62 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_63 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_64 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_65 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_66 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_67 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_68 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_69 text NULL;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_str_70 text NULL;
 
-  IF EXISTS (
-    SELECT 1
-    FROM (
-      SELECT DISTINCT ON (org_node_key) org_node_key, validity
-      FROM orgunit.org_unit_versions
-      WHERE tenant_uuid = p_tenant_uuid
-        AND org_node_key = ANY(p_org_node_keys)
-      ORDER BY org_node_key, lower(validity) DESC
-    ) last
-    WHERE NOT upper_inf(last.validity)
-    LIMIT 1
-  ) THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_VALIDITY_NOT_INFINITE',
-      DETAIL = 'last version validity must be unbounded (infinity)';
-  END IF;
-END;
-$$;
+ALTER TABLE orgunit.org_unit_versions
+  ADD COLUMN IF NOT EXISTS ext_labels_snapshot jsonb NOT NULL DEFAULT '{}'::jsonb;
 
-CREATE OR REPLACE FUNCTION orgunit.org_events_effective_for_replay(
-  p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_pending_event_id bigint,
-  p_pending_event_uuid uuid,
-  p_pending_event_type text,
-  p_pending_effective_date date,
-  p_pending_payload jsonb,
-  p_pending_request_id text,
-  p_pending_initiator_uuid uuid,
-  p_pending_tx_time timestamptz,
-  p_pending_transaction_time timestamptz,
-  p_pending_created_at timestamptz
-)
-RETURNS TABLE (
-  id bigint,
-  event_uuid uuid,
-  tenant_uuid uuid,
-  org_node_key char(8),
-  event_type text,
-  effective_date date,
-  payload jsonb,
-  request_id text,
-  initiator_uuid uuid,
-  transaction_time timestamptz,
-  created_at timestamptz
-)
-LANGUAGE sql
-STABLE
-AS $$
-  WITH source_events AS (
-    SELECT
-      e.id,
-      e.event_uuid,
-      e.tenant_uuid,
-      e.org_node_key,
-      e.event_type,
-      e.effective_date,
-      COALESCE(e.payload, '{}'::jsonb) AS payload,
-      e.request_id,
-      e.initiator_uuid,
-      e.tx_time,
-      e.transaction_time,
-      e.created_at
-    FROM orgunit.org_events e
-    WHERE e.tenant_uuid = p_tenant_uuid
-      AND e.org_node_key = p_org_node_key
+-- MVP: at least one DICT field requires list filter/sort (see DEV-PLAN-100A §9.1).
+-- Since physical_col is assigned dynamically, we pre-index all ext_str slots.
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_01_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_01)
+  WHERE ext_str_01 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_02_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_02)
+  WHERE ext_str_02 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_03_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_03)
+  WHERE ext_str_03 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_04_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_04)
+  WHERE ext_str_04 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_05_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_05)
+  WHERE ext_str_05 IS NOT NULL;
 
-    UNION ALL
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_06_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_06)
+  WHERE ext_str_06 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_07_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_07)
+  WHERE ext_str_07 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_08_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_08)
+  WHERE ext_str_08 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_09_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_09)
+  WHERE ext_str_09 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_10_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_10)
+  WHERE ext_str_10 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_11_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_11)
+  WHERE ext_str_11 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_12_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_12)
+  WHERE ext_str_12 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_13_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_13)
+  WHERE ext_str_13 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_14_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_14)
+  WHERE ext_str_14 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_15_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_15)
+  WHERE ext_str_15 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_16_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_16)
+  WHERE ext_str_16 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_17_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_17)
+  WHERE ext_str_17 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_18_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_18)
+  WHERE ext_str_18 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_19_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_19)
+  WHERE ext_str_19 IS NOT NULL;
+CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_20_idx
+  ON orgunit.org_unit_versions (tenant_uuid, ext_str_20)
+  WHERE ext_str_20 IS NOT NULL;
 
-    SELECT
-      p_pending_event_id,
-      p_pending_event_uuid,
-      p_tenant_uuid,
-      p_org_node_key,
-      p_pending_event_type,
-      p_pending_effective_date,
-      COALESCE(p_pending_payload, '{}'::jsonb),
-      p_pending_request_id,
-      p_pending_initiator_uuid,
-      p_pending_tx_time,
-      p_pending_transaction_time,
-      p_pending_created_at
-    WHERE p_pending_event_id IS NOT NULL
-  ),
-  correction_events AS (
-    SELECT
-      se.*,
-      (se.payload->>'target_event_uuid')::uuid AS target_event_uuid
-    FROM source_events se
-    WHERE se.event_type IN ('CORRECT_EVENT','CORRECT_STATUS','RESCIND_EVENT','RESCIND_ORG')
-      AND se.payload ? 'target_event_uuid'
-  ),
-  latest_corrections AS (
-    SELECT DISTINCT ON (tenant_uuid, target_event_uuid)
-      tenant_uuid,
-      target_event_uuid,
-      event_type AS correction_type,
-      payload AS correction_payload,
-      tx_time,
-      id
-    FROM correction_events
-    ORDER BY tenant_uuid, target_event_uuid, tx_time DESC, id DESC
-  ),
-  latest_effective_date_corrections AS (
-    -- Sticky effective_date: take the latest CORRECT_EVENT that explicitly carries effective_date,
-    -- regardless of later corrections that don't include effective_date.
-    SELECT DISTINCT ON (tenant_uuid, target_event_uuid)
-      tenant_uuid,
-      target_event_uuid,
-      NULLIF(btrim(payload->>'effective_date'), '')::date AS sticky_effective_date,
-      tx_time,
-      id
-    FROM correction_events
-    WHERE event_type = 'CORRECT_EVENT'
-      AND payload ? 'effective_date'
-    ORDER BY tenant_uuid, target_event_uuid, tx_time DESC, id DESC
-  )
-  SELECT
-    se.id,
-    se.event_uuid,
-    se.tenant_uuid,
-    se.org_node_key,
-    CASE
-      WHEN lc.correction_type = 'CORRECT_STATUS'
-        AND COALESCE(lc.correction_payload->>'target_status', '') = 'active'
-        THEN 'ENABLE'
-      WHEN lc.correction_type = 'CORRECT_STATUS'
-        AND COALESCE(lc.correction_payload->>'target_status', '') = 'disabled'
-        THEN 'DISABLE'
-      WHEN lc.correction_type = 'CORRECT_EVENT'
-        AND se.event_type <> 'CREATE'
-        AND (
-          orgunit.merge_org_event_payload_with_correction(se.payload, lc.correction_payload) ?| ARRAY[
-            'name',
-            'parent_org_node_key',
-            'status',
-            'is_business_unit',
-            'manager_uuid',
-            'manager_pernr',
-            'ext',
-            'new_name',
-            'new_parent_org_node_key'
-          ]
-        )
-        THEN 'UPDATE'
-      ELSE se.event_type
-    END AS event_type,
-    COALESCE(lec.sticky_effective_date, se.effective_date) AS effective_date,
-    CASE
-      WHEN lc.correction_type = 'CORRECT_EVENT'
-        THEN orgunit.merge_org_event_payload_with_correction(se.payload, lc.correction_payload)
-      ELSE se.payload
-    END AS payload,
-    se.request_id,
-    se.initiator_uuid,
-    se.transaction_time,
-    se.created_at
-  FROM source_events se
-  LEFT JOIN latest_corrections lc
-    ON lc.tenant_uuid = se.tenant_uuid
-   AND lc.target_event_uuid = se.event_uuid
-  LEFT JOIN latest_effective_date_corrections lec
-    ON lec.tenant_uuid = se.tenant_uuid
-   AND lec.target_event_uuid = se.event_uuid
-  WHERE se.event_type IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT')
-    AND COALESCE(lc.correction_type, '') NOT IN ('RESCIND_EVENT', 'RESCIND_ORG')
-  ORDER BY effective_date, id;
-$$;
+-- -------------------------------------------------------------------
+-- Kernel write entrypoints (One Door for metadata writes).
+-- -------------------------------------------------------------------
 
-CREATE OR REPLACE FUNCTION orgunit.rebuild_org_unit_versions_for_org_with_pending_event(
+CREATE OR REPLACE FUNCTION orgunit.enable_tenant_field_config(
   p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_pending_event_id bigint,
-  p_pending_event_uuid uuid,
-  p_pending_event_type text,
-  p_pending_effective_date date,
-  p_pending_payload jsonb,
-  p_pending_request_id text,
-  p_pending_initiator_uuid uuid,
-  p_pending_tx_time timestamptz,
-  p_pending_transaction_time timestamptz,
-  p_pending_created_at timestamptz
+  p_field_key text,
+  p_value_type text,
+  p_enabled_on date,
+  p_data_source_type text,
+  p_data_source_config jsonb,
+  p_display_label text,
+  p_request_id text,
+  p_initiator_uuid uuid
 )
 RETURNS void
 LANGUAGE plpgsql
 AS $$
 DECLARE
-  v_event record;
+  v_lock_key text;
+  v_existing orgunit.tenant_field_config_events%ROWTYPE;
+  v_config jsonb;
+  v_display_label text;
+  v_physical_col text;
+  v_candidate_cols text[];
+  v_col text;
   v_payload jsonb;
-  v_parent_org_node_key char(8);
-  v_new_parent_org_node_key char(8);
-  v_name text;
-  v_new_name text;
-  v_manager_uuid uuid;
-  v_is_business_unit boolean;
-  v_org_code text;
-  v_root_path ltree;
-  v_org_node_keys char(8)[];
-  v_root_org_node_key char(8);
-  v_has_create boolean;
-  v_status text;
 BEGIN
   PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
 
-  IF p_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  IF p_field_key IS NULL OR btrim(p_field_key) = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'field_key is required';
+  END IF;
+  IF p_field_key !~ '^[a-z][a-z0-9_]{0,62}$' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = format('field_key=%s', p_field_key);
+  END IF;
+  IF p_value_type IS NULL OR btrim(p_value_type) = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'value_type is required';
+  END IF;
+  IF p_enabled_on IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'enabled_on is required';
+  END IF;
+  IF p_data_source_type IS NULL OR btrim(p_data_source_type) = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'data_source_type is required';
+  END IF;
+  IF p_display_label IS NOT NULL AND btrim(p_display_label) = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'display_label invalid';
+  END IF;
+  IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
+  END IF;
+  IF p_initiator_uuid IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
   END IF;
 
-  IF p_pending_event_id IS NOT NULL THEN
-    IF p_pending_event_uuid IS NULL
-      OR p_pending_event_type IS NULL
-      OR p_pending_effective_date IS NULL
-      OR p_pending_request_id IS NULL
-      OR p_pending_initiator_uuid IS NULL
-      OR p_pending_tx_time IS NULL
-      OR p_pending_transaction_time IS NULL
-      OR p_pending_created_at IS NULL
+  v_lock_key := format('org:field-config-lock:%s', p_tenant_uuid);
+  PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
+
+  v_config := COALESCE(p_data_source_config, '{}'::jsonb);
+  IF jsonb_typeof(v_config) <> 'object' THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_FIELD_CONFIG_INVALID_DATA_SOURCE_CONFIG',
+      DETAIL = 'data_source_config must be an object';
+  END IF;
+  v_display_label := NULLIF(btrim(p_display_label), '');
+
+  SELECT * INTO v_existing
+  FROM orgunit.tenant_field_config_events
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id
+  LIMIT 1;
+
+  IF FOUND THEN
+    IF v_existing.event_type <> 'ENABLE'
+      OR v_existing.field_key <> p_field_key
+      OR v_existing.initiator_uuid <> p_initiator_uuid
+      OR COALESCE(v_existing.payload->>'value_type', '') <> p_value_type
+      OR COALESCE(v_existing.payload->>'data_source_type', '') <> p_data_source_type
+      OR COALESCE(v_existing.payload->>'enabled_on', '') <> p_enabled_on::text
+      OR COALESCE(v_existing.payload->'data_source_config', '{}'::jsonb) <> v_config
+      OR COALESCE(v_existing.payload->>'display_label', '') <> COALESCE(v_display_label, '')
+      OR v_existing.payload->>'disabled_on' IS NOT NULL
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
+        DETAIL = format('request_id=%s', p_request_id);
+    END IF;
+
+    RETURN;
+  END IF;
+
+  IF p_value_type NOT IN ('text','int','uuid','bool','date','numeric') THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = format('value_type=%s', p_value_type);
+  END IF;
+  IF p_data_source_type NOT IN ('PLAIN','DICT','ENTITY') THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = format('data_source_type=%s', p_data_source_type);
+  END IF;
+
+  IF p_data_source_type = 'PLAIN' THEN
+    IF v_config <> '{}'::jsonb THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_FIELD_CONFIG_INVALID_DATA_SOURCE_CONFIG',
+        DETAIL = format('field_key=%s data_source_type=PLAIN requires {}', p_field_key);
+    END IF;
+  ELSIF p_data_source_type = 'DICT' THEN
+    IF p_value_type <> 'text'
+      OR NOT (v_config ? 'dict_code')
+      OR jsonb_typeof(v_config->'dict_code') <> 'string'
+      OR NULLIF(btrim(v_config->>'dict_code'), '') IS NULL
+      OR v_config <> jsonb_build_object('dict_code', v_config->'dict_code')
     THEN
       RAISE EXCEPTION USING
-        MESSAGE = 'ORG_INVALID_ARGUMENT',
-        DETAIL = 'pending event metadata is incomplete';
+        MESSAGE = 'ORG_FIELD_CONFIG_INVALID_DATA_SOURCE_CONFIG',
+        DETAIL = format('field_key=%s data_source_type=DICT', p_field_key);
+    END IF;
+  ELSIF p_data_source_type = 'ENTITY' THEN
+    IF NOT (v_config ? 'entity')
+      OR jsonb_typeof(v_config->'entity') <> 'string'
+      OR NULLIF(btrim(v_config->>'entity'), '') IS NULL
+      OR NOT (v_config ? 'id_kind')
+      OR jsonb_typeof(v_config->'id_kind') <> 'string'
+      OR (v_config->>'id_kind') NOT IN ('uuid','int')
+      OR (
+        ((v_config->>'id_kind') = 'uuid' AND p_value_type <> 'uuid')
+        OR
+        ((v_config->>'id_kind') = 'int' AND p_value_type <> 'int')
+      )
+      OR v_config <> jsonb_build_object(
+        'entity', v_config->'entity',
+        'id_kind', v_config->'id_kind'
+      )
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_FIELD_CONFIG_INVALID_DATA_SOURCE_CONFIG',
+        DETAIL = format('field_key=%s data_source_type=ENTITY', p_field_key);
     END IF;
   END IF;
 
-  SELECT t.root_org_node_key INTO v_root_org_node_key
-  FROM orgunit.org_trees t
-  WHERE t.tenant_uuid = p_tenant_uuid;
-
-  SELECT EXISTS (
+  IF EXISTS (
     SELECT 1
-    FROM orgunit.org_events_effective_for_replay(
-      p_tenant_uuid,
-      p_org_node_key,
-      p_pending_event_id,
-      p_pending_event_uuid,
-      p_pending_event_type,
-      p_pending_effective_date,
-      p_pending_payload,
-      p_pending_request_id,
-      p_pending_initiator_uuid,
-      p_pending_tx_time,
-      p_pending_transaction_time,
-      p_pending_created_at
-    ) e
-    WHERE e.event_type = 'CREATE'
-  ) INTO v_has_create;
-
-  IF v_has_create AND v_root_org_node_key = p_org_node_key THEN
-    DELETE FROM orgunit.org_trees
-    WHERE tenant_uuid = p_tenant_uuid;
-  ELSIF v_root_org_node_key = p_org_node_key AND NOT v_has_create THEN
+    FROM orgunit.tenant_field_configs
+    WHERE tenant_uuid = p_tenant_uuid
+      AND field_key = p_field_key
+  ) THEN
     RAISE EXCEPTION USING
-      MESSAGE = 'ORG_INVALID_ARGUMENT',
-      DETAIL = format('root org missing create event: org_node_key=%s', p_org_node_key);
+      MESSAGE = 'ORG_FIELD_CONFIG_ALREADY_ENABLED',
+      DETAIL = format('field_key=%s', p_field_key);
   END IF;
 
-  DELETE FROM orgunit.org_unit_codes
-  WHERE tenant_uuid = p_tenant_uuid
-    AND org_node_key = p_org_node_key;
-
-  DELETE FROM orgunit.org_unit_versions
-  WHERE tenant_uuid = p_tenant_uuid
-    AND org_node_key = p_org_node_key;
-
-  FOR v_event IN
-    SELECT *
-    FROM orgunit.org_events_effective_for_replay(
-      p_tenant_uuid,
-      p_org_node_key,
-      p_pending_event_id,
-      p_pending_event_uuid,
-      p_pending_event_type,
-      p_pending_effective_date,
-      p_pending_payload,
-      p_pending_request_id,
-      p_pending_initiator_uuid,
-      p_pending_tx_time,
-      p_pending_transaction_time,
-      p_pending_created_at
-    )
-    ORDER BY effective_date, id
-  LOOP
-    v_payload := COALESCE(v_event.payload, '{}'::jsonb);
-
-    IF v_event.event_type = 'CREATE' THEN
-      v_parent_org_node_key := NULLIF(v_payload->>'parent_org_node_key', '')::char(8);
-      v_name := NULLIF(btrim(v_payload->>'name'), '');
-      v_manager_uuid := NULLIF(v_payload->>'manager_uuid', '')::uuid;
-      v_org_code := NULLIF(v_payload->>'org_code', '');
-      v_status := NULLIF(btrim(v_payload->>'status'), '');
-      v_is_business_unit := NULL;
-      IF v_payload ? 'is_business_unit' THEN
-        BEGIN
-          v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
-        EXCEPTION
-          WHEN invalid_text_representation THEN
-            RAISE EXCEPTION USING
-              MESSAGE = 'ORG_INVALID_ARGUMENT',
-              DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
-        END;
-      END IF;
-      PERFORM orgunit.apply_create_logic(p_tenant_uuid, v_event.org_node_key, v_org_code, v_parent_org_node_key, v_event.effective_date, v_name, v_manager_uuid, v_is_business_unit, v_event.id, v_status);
-      SELECT v.node_path INTO v_root_path
-      FROM orgunit.org_unit_versions v
-      WHERE v.tenant_uuid = p_tenant_uuid
-        AND v.org_node_key = p_org_node_key
-        AND v.validity @> v_event.effective_date
-      ORDER BY lower(v.validity) DESC
-      LIMIT 1;
-      IF v_root_path IS NOT NULL THEN
-        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, v_event.effective_date);
-      END IF;
-    ELSIF v_event.event_type = 'UPDATE' THEN
-      PERFORM orgunit.apply_update_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_payload, v_event.id);
-      IF (v_payload ? 'parent_org_node_key')
-        OR (v_payload ? 'new_parent_org_node_key')
-        OR (v_payload ? 'name')
-        OR (v_payload ? 'new_name')
-      THEN
-        SELECT v.node_path INTO v_root_path
-        FROM orgunit.org_unit_versions v
-        WHERE v.tenant_uuid = p_tenant_uuid
-          AND v.org_node_key = p_org_node_key
-          AND v.validity @> v_event.effective_date
-        ORDER BY lower(v.validity) DESC
-        LIMIT 1;
-        IF v_root_path IS NOT NULL THEN
-          PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, v_event.effective_date);
-        END IF;
-      END IF;
-    ELSIF v_event.event_type = 'MOVE' THEN
-      v_new_parent_org_node_key := NULLIF(v_payload->>'new_parent_org_node_key', '')::char(8);
-      PERFORM orgunit.apply_move_logic(p_tenant_uuid, v_event.org_node_key, v_new_parent_org_node_key, v_event.effective_date, v_event.id);
-      SELECT v.node_path INTO v_root_path
-      FROM orgunit.org_unit_versions v
-      WHERE v.tenant_uuid = p_tenant_uuid
-        AND v.org_node_key = p_org_node_key
-        AND v.validity @> v_event.effective_date
-      ORDER BY lower(v.validity) DESC
-      LIMIT 1;
-      IF v_root_path IS NOT NULL THEN
-        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, v_event.effective_date);
-      END IF;
-    ELSIF v_event.event_type = 'RENAME' THEN
-      v_new_name := NULLIF(btrim(v_payload->>'new_name'), '');
-      PERFORM orgunit.apply_rename_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_new_name, v_event.id);
-      SELECT v.node_path INTO v_root_path
-      FROM orgunit.org_unit_versions v
-      WHERE v.tenant_uuid = p_tenant_uuid
-        AND v.org_node_key = p_org_node_key
-        AND v.validity @> v_event.effective_date
-      ORDER BY lower(v.validity) DESC
-      LIMIT 1;
-      IF v_root_path IS NOT NULL THEN
-        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, v_event.effective_date);
-      END IF;
-    ELSIF v_event.event_type = 'DISABLE' THEN
-      PERFORM orgunit.apply_disable_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_event.id);
-    ELSIF v_event.event_type = 'ENABLE' THEN
-      PERFORM orgunit.apply_enable_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_event.id);
-    ELSIF v_event.event_type = 'SET_BUSINESS_UNIT' THEN
-      IF NOT (v_payload ? 'is_business_unit') THEN
-        RAISE EXCEPTION USING
-          MESSAGE = 'ORG_INVALID_ARGUMENT',
-          DETAIL = 'is_business_unit is required';
-      END IF;
-      BEGIN
-        v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
-      EXCEPTION
-        WHEN invalid_text_representation THEN
-          RAISE EXCEPTION USING
-            MESSAGE = 'ORG_INVALID_ARGUMENT',
-            DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
-      END;
-      PERFORM orgunit.apply_set_business_unit_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_is_business_unit, v_event.id);
-    ELSE
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_INVALID_ARGUMENT',
-        DETAIL = format('unexpected event_type: %s', v_event.event_type);
-    END IF;
+  IF p_value_type = 'text' THEN
+    v_candidate_cols := ARRAY[
+      'ext_str_01','ext_str_02','ext_str_03','ext_str_04','ext_str_05',
+      'ext_str_06','ext_str_07','ext_str_08','ext_str_09','ext_str_10',
+      'ext_str_11','ext_str_12','ext_str_13','ext_str_14','ext_str_15',
+      'ext_str_16','ext_str_17','ext_str_18','ext_str_19','ext_str_20',
+      'ext_str_21','ext_str_22','ext_str_23','ext_str_24','ext_str_25',
+      'ext_str_26','ext_str_27','ext_str_28','ext_str_29','ext_str_30',
+      'ext_str_31','ext_str_32','ext_str_33','ext_str_34','ext_str_35',
+      'ext_str_36','ext_str_37','ext_str_38','ext_str_39','ext_str_40',
+      'ext_str_41','ext_str_42','ext_str_43','ext_str_44','ext_str_45',
+      'ext_str_46','ext_str_47','ext_str_48','ext_str_49','ext_str_50',
+      'ext_str_51','ext_str_52','ext_str_53','ext_str_54','ext_str_55',
+      'ext_str_56','ext_str_57','ext_str_58','ext_str_59','ext_str_60',
+      'ext_str_61','ext_str_62','ext_str_63','ext_str_64','ext_str_65',
+      'ext_str_66','ext_str_67','ext_str_68','ext_str_69','ext_str_70'
+    ];
+  ELSIF p_value_type = 'int' THEN
+    v_candidate_cols := ARRAY[
+      'ext_int_01','ext_int_02','ext_int_03','ext_int_04','ext_int_05',
+      'ext_int_06','ext_int_07','ext_int_08','ext_int_09','ext_int_10',
+      'ext_int_11','ext_int_12','ext_int_13','ext_int_14','ext_int_15'
+    ];
+  ELSIF p_value_type = 'uuid' THEN
+    v_candidate_cols := ARRAY[
+      'ext_uuid_01','ext_uuid_02','ext_uuid_03','ext_uuid_04','ext_uuid_05',
+      'ext_uuid_06','ext_uuid_07','ext_uuid_08','ext_uuid_09','ext_uuid_10'
+    ];
+  ELSIF p_value_type = 'bool' THEN
+    v_candidate_cols := ARRAY[
+      'ext_bool_01','ext_bool_02','ext_bool_03','ext_bool_04','ext_bool_05',
+      'ext_bool_06','ext_bool_07','ext_bool_08','ext_bool_09','ext_bool_10',
+      'ext_bool_11','ext_bool_12','ext_bool_13','ext_bool_14','ext_bool_15'
+    ];
+  ELSIF p_value_type = 'date' THEN
+    v_candidate_cols := ARRAY[
+      'ext_date_01','ext_date_02','ext_date_03','ext_date_04','ext_date_05',
+      'ext_date_06','ext_date_07','ext_date_08','ext_date_09','ext_date_10',
+      'ext_date_11','ext_date_12','ext_date_13','ext_date_14','ext_date_15'
+    ];
+  ELSIF p_value_type = 'numeric' THEN
+    v_candidate_cols := ARRAY[
+      'ext_num_01','ext_num_02','ext_num_03','ext_num_04','ext_num_05',
+      'ext_num_06','ext_num_07','ext_num_08','ext_num_09','ext_num_10'
+    ];
+  ELSE
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = format('value_type=%s', p_value_type);
+  END IF;
 
-    PERFORM orgunit.apply_org_event_ext_payload(
-      p_tenant_uuid,
-      v_event.org_node_key,
-      v_event.effective_date,
-      v_event.event_type,
-      v_payload,
-      v_event.id
-    );
+  v_physical_col := NULL;
+  FOREACH v_col IN ARRAY v_candidate_cols LOOP
+    IF NOT EXISTS (
+      SELECT 1
+      FROM orgunit.tenant_field_configs
+      WHERE tenant_uuid = p_tenant_uuid
+        AND physical_col = v_col
+    ) THEN
+      v_physical_col := v_col;
+      EXIT;
+    END IF;
   END LOOP;
 
-  SELECT v.node_path INTO v_root_path
-  FROM orgunit.org_unit_versions v
-  WHERE v.tenant_uuid = p_tenant_uuid
-    AND v.org_node_key = p_org_node_key
-  ORDER BY lower(v.validity) DESC
-  LIMIT 1;
-
-  IF v_root_path IS NULL THEN
-    RETURN;
+  IF v_physical_col IS NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_FIELD_CONFIG_SLOT_EXHAUSTED',
+      DETAIL = format('field_key=%s value_type=%s', p_field_key, p_value_type);
   END IF;
 
-  SELECT array_agg(DISTINCT v.org_node_key) INTO v_org_node_keys
-  FROM orgunit.org_unit_versions v
-  WHERE v.tenant_uuid = p_tenant_uuid
-    AND v.node_path <@ v_root_path;
+  v_payload := jsonb_build_object(
+    'physical_col', v_physical_col,
+    'value_type', p_value_type,
+    'data_source_type', p_data_source_type,
+    'data_source_config', v_config,
+    'display_label', v_display_label,
+    'enabled_on', p_enabled_on::text,
+    'disabled_on', NULL
+  );
 
-  PERFORM orgunit.assert_org_unit_validity(p_tenant_uuid, v_org_node_keys);
-END;
-$$;
+  INSERT INTO orgunit.tenant_field_config_events (
+    event_uuid,
+    tenant_uuid,
+    event_type,
+    field_key,
+    payload,
+    request_id,
+    initiator_uuid
+  )
+  VALUES (
+    gen_random_uuid(),
+    p_tenant_uuid,
+    'ENABLE',
+    p_field_key,
+    v_payload,
+    p_request_id,
+    p_initiator_uuid
+  );
 
-CREATE OR REPLACE FUNCTION orgunit.rebuild_org_unit_versions_for_org(
-  p_tenant_uuid uuid,
-  p_org_node_key char(8)
-)
-RETURNS void
-LANGUAGE plpgsql
-AS $$
-BEGIN
-  PERFORM orgunit.rebuild_org_unit_versions_for_org_with_pending_event(
+  INSERT INTO orgunit.tenant_field_configs (
+    tenant_uuid,
+    field_key,
+    physical_col,
+    value_type,
+    data_source_type,
+    data_source_config,
+    display_label,
+    enabled_on,
+    disabled_on,
+    created_at,
+    updated_at
+  )
+  VALUES (
     p_tenant_uuid,
-    p_org_node_key,
-    NULL,
-    NULL,
-    NULL,
-    NULL,
-    NULL,
-    NULL,
-    NULL,
-    NULL,
+    p_field_key,
+    v_physical_col,
+    p_value_type,
+    p_data_source_type,
+    v_config,
+    v_display_label,
+    p_enabled_on,
     NULL,
-    NULL
+    now(),
+    now()
   );
 END;
 $$;
 
-CREATE OR REPLACE FUNCTION orgunit.extract_orgunit_snapshot(
-  p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_as_of date
+CREATE OR REPLACE FUNCTION orgunit.rename_jsonb_object_key_strict(
+  p_obj jsonb,
+  p_old_key text,
+  p_new_key text
 )
 RETURNS jsonb
 LANGUAGE plpgsql
 AS $$
 DECLARE
-  v_row orgunit.org_unit_versions%ROWTYPE;
-  v_snapshot jsonb;
-  v_org_code text;
-BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
-
-  IF p_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
-  END IF;
-  IF p_as_of IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'as_of is required';
-  END IF;
-
-  SELECT * INTO v_row
-  FROM orgunit.org_unit_versions v
-  WHERE v.tenant_uuid = p_tenant_uuid
-    AND v.org_node_key = p_org_node_key
-    AND v.validity @> p_as_of
-  ORDER BY lower(v.validity) DESC
-  LIMIT 1;
-
-  IF NOT FOUND THEN
-    RETURN NULL;
-  END IF;
-
-  SELECT c.org_code INTO v_org_code
-  FROM orgunit.org_unit_codes c
-  WHERE c.tenant_uuid = p_tenant_uuid
-    AND c.org_node_key = p_org_node_key
-  LIMIT 1;
-
-  v_snapshot := to_jsonb(v_row) - 'id' - 'tenant_uuid' - 'last_event_id' - 'path_node_keys';
-  IF v_org_code IS NOT NULL THEN
-    v_snapshot := jsonb_set(v_snapshot, '{org_code}', to_jsonb(v_org_code), true);
-  END IF;
-
-  IF jsonb_typeof(v_snapshot) <> 'object' THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_AUDIT_SNAPSHOT_INVALID',
-      DETAIL = format('org_node_key=%s as_of=%s snapshot_type=%s', p_org_node_key, p_as_of, COALESCE(jsonb_typeof(v_snapshot), 'null'));
-  END IF;
-
-  RETURN v_snapshot;
-END;
-$$;
-
-CREATE OR REPLACE FUNCTION orgunit.assert_org_event_snapshots(
-  p_event_type text,
-  p_before_snapshot jsonb,
-  p_after_snapshot jsonb,
-  p_rescind_outcome text
-)
-RETURNS void
-LANGUAGE plpgsql
-AS $$
+  v_obj jsonb := COALESCE(p_obj, '{}'::jsonb);
 BEGIN
-  IF p_before_snapshot IS NOT NULL AND jsonb_typeof(p_before_snapshot) <> 'object' THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_AUDIT_SNAPSHOT_INVALID',
-      DETAIL = format('event_type=%s before_snapshot_type=%s', p_event_type, jsonb_typeof(p_before_snapshot));
-  END IF;
-
-  IF p_after_snapshot IS NOT NULL AND jsonb_typeof(p_after_snapshot) <> 'object' THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_AUDIT_SNAPSHOT_INVALID',
-      DETAIL = format('event_type=%s after_snapshot_type=%s', p_event_type, jsonb_typeof(p_after_snapshot));
+  IF jsonb_typeof(v_obj) <> 'object' THEN
+    RETURN '{}'::jsonb;
   END IF;
-
-  IF NOT orgunit.is_org_event_snapshot_presence_valid(
-    p_event_type,
-    p_before_snapshot,
-    p_after_snapshot,
-    p_rescind_outcome
-  ) THEN
+  IF v_obj ? p_old_key AND v_obj ? p_new_key THEN
     RAISE EXCEPTION USING
-      MESSAGE = 'ORG_AUDIT_SNAPSHOT_MISSING',
-      DETAIL = format(
-        'event_type=%s before=%s after=%s rescind_outcome=%s',
-        p_event_type,
-        p_before_snapshot IS NOT NULL,
-        p_after_snapshot IS NOT NULL,
-        COALESCE(p_rescind_outcome, 'NULL')
-      );
+      MESSAGE = 'ORG_FIELD_CONFIG_REKEY_CONFLICT',
+      DETAIL = format('old_field_key=%s new_field_key=%s', p_old_key, p_new_key);
   END IF;
-
-  IF NOT orgunit.is_org_event_snapshot_content_valid(
-    p_event_type,
-    p_before_snapshot,
-    p_after_snapshot,
-    p_rescind_outcome
-  ) THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_AUDIT_SNAPSHOT_INVALID',
-      DETAIL = format(
-        'event_type=%s incomplete_snapshot_content=true rescind_outcome=%s',
-        p_event_type,
-        COALESCE(p_rescind_outcome, 'NULL')
-      );
+  IF v_obj ? p_old_key THEN
+    RETURN (v_obj - p_old_key) || jsonb_build_object(p_new_key, v_obj->p_old_key);
   END IF;
+  RETURN v_obj;
 END;
 $$;
 
--- Backward-compatible wrapper for existing 3-arg callers during rollout.
-CREATE OR REPLACE FUNCTION orgunit.assert_org_event_snapshots(
-  p_event_type text,
-  p_before_snapshot jsonb,
-  p_after_snapshot jsonb
-)
-RETURNS void
-LANGUAGE plpgsql
-AS $$
-BEGIN
-  PERFORM orgunit.assert_org_event_snapshots(
-    p_event_type,
-    p_before_snapshot,
-    p_after_snapshot,
-    NULL
-  );
-END;
-$$;
-
-CREATE OR REPLACE FUNCTION orgunit.submit_org_event(
-  p_event_uuid uuid,
+CREATE OR REPLACE FUNCTION orgunit.rekey_tenant_field_config(
   p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_event_type text,
-  p_effective_date date,
-  p_payload jsonb,
+  p_old_field_key text,
+  p_new_field_key text,
   p_request_id text,
   p_initiator_uuid uuid
 )
-RETURNS bigint
+RETURNS void
 LANGUAGE plpgsql
 AS $$
 DECLARE
   v_lock_key text;
-  v_event_db_id bigint;
-  v_existing orgunit.org_events%ROWTYPE;
-  v_existing_request orgunit.org_events%ROWTYPE;
+  v_existing orgunit.tenant_field_config_events%ROWTYPE;
+  v_cfg orgunit.tenant_field_configs%ROWTYPE;
+  v_dict_code text;
   v_payload jsonb;
-  v_parent_org_node_key char(8);
-  v_new_parent_org_node_key char(8);
-  v_name text;
-  v_new_name text;
-  v_manager_uuid uuid;
-  v_is_business_unit boolean;
-  v_org_code text;
-  v_root_path ltree;
-  v_org_node_keys char(8)[];
-  v_status text;
-  v_before_snapshot jsonb;
-  v_after_snapshot jsonb;
+  v_updated_events int := 0;
+  v_updated_versions int := 0;
 BEGIN
   PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
 
-  IF p_event_uuid IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'event_uuid is required';
-  END IF;
-  IF p_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  p_old_field_key := lower(btrim(p_old_field_key));
+  p_new_field_key := lower(btrim(p_new_field_key));
+
+  IF p_old_field_key IS NULL OR p_old_field_key = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'old_field_key is required';
   END IF;
-  IF p_effective_date IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
+  IF p_new_field_key IS NULL OR p_new_field_key = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'new_field_key is required';
   END IF;
   IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
     RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
@@ -4349,240 +8114,212 @@ BEGIN
   IF p_initiator_uuid IS NULL THEN
     RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
   END IF;
-
-  IF p_event_type NOT IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT') THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_INVALID_ARGUMENT',
-      DETAIL = format('unsupported event_type: %s', p_event_type);
+  IF p_old_field_key !~ '^[a-z][a-z0-9_]{0,62}$' OR p_new_field_key !~ '^[a-z][a-z0-9_]{0,62}$' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'field_key invalid';
+  END IF;
+  IF p_old_field_key = p_new_field_key THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'old_field_key/new_field_key must differ';
   END IF;
 
-  v_lock_key := format('org:write-lock:%s', p_tenant_uuid);
+  v_lock_key := format('org:field-config-lock:%s', p_tenant_uuid);
   PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
 
-  v_payload := COALESCE(p_payload, '{}'::jsonb);
-  IF p_event_type = 'SET_BUSINESS_UNIT' THEN
-    IF NOT (v_payload ? 'is_business_unit') THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_INVALID_ARGUMENT',
-        DETAIL = 'is_business_unit is required';
-    END IF;
-    BEGIN
-      PERFORM (v_payload->>'is_business_unit')::boolean;
-    EXCEPTION
-      WHEN invalid_text_representation THEN
-        RAISE EXCEPTION USING
-          MESSAGE = 'ORG_INVALID_ARGUMENT',
-          DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
-    END;
-  END IF;
-
-  SELECT * INTO v_existing_request
-  FROM orgunit.org_events
+  SELECT * INTO v_existing
+  FROM orgunit.tenant_field_config_events
   WHERE tenant_uuid = p_tenant_uuid
     AND request_id = p_request_id
   LIMIT 1;
 
   IF FOUND THEN
-    IF v_existing_request.event_uuid <> p_event_uuid
-      OR v_existing_request.org_node_key <> p_org_node_key
-      OR v_existing_request.event_type <> p_event_type
-      OR v_existing_request.effective_date <> p_effective_date
-      OR v_existing_request.payload <> v_payload
-      OR v_existing_request.initiator_uuid <> p_initiator_uuid
+    IF v_existing.event_type <> 'REKEY'
+      OR COALESCE(v_existing.payload->>'old_field_key', '') <> p_old_field_key
+      OR COALESCE(v_existing.payload->>'new_field_key', '') <> p_new_field_key
+      OR v_existing.initiator_uuid <> p_initiator_uuid
     THEN
       RAISE EXCEPTION USING
         MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
         DETAIL = format('request_id=%s', p_request_id);
     END IF;
-
-    RETURN v_existing_request.id;
+    RETURN;
   END IF;
 
-  v_before_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, p_effective_date);
+  SELECT * INTO v_cfg
+  FROM orgunit.tenant_field_configs
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_old_field_key
+  LIMIT 1;
 
-  SELECT * INTO v_existing
-  FROM orgunit.org_events
-  WHERE event_uuid = p_event_uuid;
+  IF NOT FOUND THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_FIELD_CONFIG_NOT_FOUND',
+      DETAIL = format('field_key=%s', p_old_field_key);
+  END IF;
 
-  IF FOUND THEN
-    IF v_existing.tenant_uuid <> p_tenant_uuid
-      OR v_existing.org_node_key <> p_org_node_key
-      OR v_existing.event_type <> p_event_type
-      OR v_existing.effective_date <> p_effective_date
-      OR v_existing.payload <> v_payload
-      OR v_existing.request_id <> p_request_id
-      OR v_existing.initiator_uuid <> p_initiator_uuid
-    THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_IDEMPOTENCY_REUSED',
-        DETAIL = format('event_uuid=%s existing_id=%s', p_event_uuid, v_existing.id);
-    END IF;
+  IF v_cfg.data_source_type <> 'DICT' OR v_cfg.value_type <> 'text' THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_FIELD_CONFIG_INVALID_DATA_SOURCE_CONFIG',
+      DETAIL = format('field_key=%s', p_old_field_key);
+  END IF;
 
-    RETURN v_existing.id;
+  v_dict_code := NULLIF(btrim(v_cfg.data_source_config->>'dict_code'), '');
+  IF v_dict_code IS NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_FIELD_CONFIG_INVALID_DATA_SOURCE_CONFIG',
+      DETAIL = format('field_key=%s missing_dict_code', p_old_field_key);
+  END IF;
+  IF p_new_field_key <> ('d_' || v_dict_code) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_FIELD_CONFIG_INVALID_DATA_SOURCE_CONFIG',
+      DETAIL = format('field_key=%s expected_new_field_key=%s', p_old_field_key, 'd_' || v_dict_code);
   END IF;
 
-  SELECT nextval(pg_get_serial_sequence('orgunit.org_events', 'id')) INTO v_event_db_id;
+  IF EXISTS (
+    SELECT 1
+    FROM orgunit.tenant_field_configs
+    WHERE tenant_uuid = p_tenant_uuid
+      AND field_key = p_new_field_key
+  ) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_FIELD_CONFIG_ALREADY_ENABLED',
+      DETAIL = format('field_key=%s', p_new_field_key);
+  END IF;
 
-  IF p_event_type = 'CREATE' THEN
-    v_parent_org_node_key := NULLIF(v_payload->>'parent_org_node_key', '')::char(8);
-    v_name := NULLIF(btrim(v_payload->>'name'), '');
-    v_manager_uuid := NULLIF(v_payload->>'manager_uuid', '')::uuid;
-    v_org_code := NULLIF(v_payload->>'org_code', '');
-    v_status := NULLIF(btrim(v_payload->>'status'), '');
-    v_is_business_unit := NULL;
-    IF v_payload ? 'is_business_unit' THEN
-      BEGIN
-        v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
-      EXCEPTION
-        WHEN invalid_text_representation THEN
-          RAISE EXCEPTION USING
-            MESSAGE = 'ORG_INVALID_ARGUMENT',
-            DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
-      END;
-    END IF;
-    PERFORM orgunit.apply_create_logic(p_tenant_uuid, p_org_node_key, v_org_code, v_parent_org_node_key, p_effective_date, v_name, v_manager_uuid, v_is_business_unit, v_event_db_id, v_status);
-    SELECT v.node_path INTO v_root_path
-    FROM orgunit.org_unit_versions v
-    WHERE v.tenant_uuid = p_tenant_uuid
-      AND v.org_node_key = p_org_node_key
-      AND v.validity @> p_effective_date
-    ORDER BY lower(v.validity) DESC
-    LIMIT 1;
-    PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
-    v_org_node_keys := ARRAY[p_org_node_key];
-  ELSIF p_event_type = 'UPDATE' THEN
-    PERFORM orgunit.apply_update_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_payload, v_event_db_id);
-    IF (v_payload ? 'parent_org_node_key')
-      OR (v_payload ? 'new_parent_org_node_key')
-      OR (v_payload ? 'name')
-      OR (v_payload ? 'new_name')
-    THEN
-      SELECT v.node_path INTO v_root_path
-      FROM orgunit.org_unit_versions v
-      WHERE v.tenant_uuid = p_tenant_uuid
-        AND v.org_node_key = p_org_node_key
-        AND v.validity @> p_effective_date
-      ORDER BY lower(v.validity) DESC
-      LIMIT 1;
-      IF v_root_path IS NOT NULL THEN
-        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
-      END IF;
-    END IF;
-    SELECT array_agg(DISTINCT v.org_node_key) INTO v_org_node_keys
-    FROM orgunit.org_unit_versions v
-    WHERE v.tenant_uuid = p_tenant_uuid
-      AND v.org_node_key = p_org_node_key;
-  ELSIF p_event_type = 'MOVE' THEN
-    v_new_parent_org_node_key := NULLIF(v_payload->>'new_parent_org_node_key', '')::char(8);
-    PERFORM orgunit.apply_move_logic(p_tenant_uuid, p_org_node_key, v_new_parent_org_node_key, p_effective_date, v_event_db_id);
-    SELECT v.node_path INTO v_root_path
-    FROM orgunit.org_unit_versions v
-    WHERE v.tenant_uuid = p_tenant_uuid
-      AND v.org_node_key = p_org_node_key
-      AND v.validity @> p_effective_date
-    ORDER BY lower(v.validity) DESC
-    LIMIT 1;
-    PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
-    SELECT array_agg(DISTINCT v.org_node_key) INTO v_org_node_keys
-    FROM orgunit.org_unit_versions v
-    WHERE v.tenant_uuid = p_tenant_uuid
-      AND v.node_path <@ v_root_path;
-  ELSIF p_event_type = 'RENAME' THEN
-    v_new_name := NULLIF(btrim(v_payload->>'new_name'), '');
-    PERFORM orgunit.apply_rename_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_new_name, v_event_db_id);
-    SELECT v.node_path INTO v_root_path
+  IF EXISTS (
+    SELECT 1
+    FROM orgunit.org_events e
+    WHERE e.tenant_uuid = p_tenant_uuid
+      AND jsonb_typeof(e.payload->'ext') = 'object'
+      AND (e.payload->'ext') ? p_old_field_key
+      AND (e.payload->'ext') ? p_new_field_key
+    LIMIT 1
+  ) OR EXISTS (
+    SELECT 1
+    FROM orgunit.org_events e
+    WHERE e.tenant_uuid = p_tenant_uuid
+      AND jsonb_typeof(e.payload->'ext_labels_snapshot') = 'object'
+      AND (e.payload->'ext_labels_snapshot') ? p_old_field_key
+      AND (e.payload->'ext_labels_snapshot') ? p_new_field_key
+    LIMIT 1
+  ) OR EXISTS (
+    SELECT 1
     FROM orgunit.org_unit_versions v
     WHERE v.tenant_uuid = p_tenant_uuid
-      AND v.org_node_key = p_org_node_key
-      AND v.validity @> p_effective_date
-    ORDER BY lower(v.validity) DESC
-    LIMIT 1;
-    PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
-    v_org_node_keys := ARRAY[p_org_node_key];
-  ELSIF p_event_type = 'DISABLE' THEN
-    PERFORM orgunit.apply_disable_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_event_db_id);
-    v_org_node_keys := ARRAY[p_org_node_key];
-  ELSIF p_event_type = 'ENABLE' THEN
-    PERFORM orgunit.apply_enable_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_event_db_id);
-    v_org_node_keys := ARRAY[p_org_node_key];
-  ELSIF p_event_type = 'SET_BUSINESS_UNIT' THEN
-    v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
-    PERFORM orgunit.apply_set_business_unit_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_is_business_unit, v_event_db_id);
-    v_org_node_keys := ARRAY[p_org_node_key];
+      AND v.ext_labels_snapshot ? p_old_field_key
+      AND v.ext_labels_snapshot ? p_new_field_key
+    LIMIT 1
+  ) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_FIELD_CONFIG_REKEY_CONFLICT',
+      DETAIL = format('old_field_key=%s new_field_key=%s', p_old_field_key, p_new_field_key);
   END IF;
 
-  PERFORM orgunit.assert_org_unit_validity(p_tenant_uuid, v_org_node_keys);
+  PERFORM set_config('app.orgunit_field_config_allow_rekey', 'on', true);
+  UPDATE orgunit.tenant_field_configs
+  SET field_key = p_new_field_key,
+      updated_at = now()
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_old_field_key;
+
+  WITH rewritten AS (
+    SELECT
+      id,
+      payload,
+      CASE
+        WHEN jsonb_typeof(payload->'ext') = 'object' THEN
+          orgunit.rename_jsonb_object_key_strict(payload->'ext', p_old_field_key, p_new_field_key)
+        ELSE NULL
+      END AS new_ext,
+      CASE
+        WHEN jsonb_typeof(payload->'ext_labels_snapshot') = 'object' THEN
+          orgunit.rename_jsonb_object_key_strict(payload->'ext_labels_snapshot', p_old_field_key, p_new_field_key)
+        ELSE NULL
+      END AS new_labels
+    FROM orgunit.org_events
+    WHERE tenant_uuid = p_tenant_uuid
+  ), updates AS (
+    UPDATE orgunit.org_events e
+    SET payload = (
+      (r.payload - 'ext' - 'ext_labels_snapshot')
+      || CASE WHEN r.new_ext IS NULL THEN '{}'::jsonb ELSE jsonb_build_object('ext', r.new_ext) END
+      || CASE WHEN r.new_labels IS NULL THEN '{}'::jsonb ELSE jsonb_build_object('ext_labels_snapshot', r.new_labels) END
+    )
+    FROM rewritten r
+    WHERE e.id = r.id
+      AND e.tenant_uuid = p_tenant_uuid
+      AND (
+        (r.new_ext IS NOT NULL AND r.new_ext IS DISTINCT FROM COALESCE(r.payload->'ext', '{}'::jsonb))
+        OR
+        (r.new_labels IS NOT NULL AND r.new_labels IS DISTINCT FROM COALESCE(r.payload->'ext_labels_snapshot', '{}'::jsonb))
+      )
+    RETURNING 1
+  )
+  SELECT count(*) INTO v_updated_events FROM updates;
+
+  UPDATE orgunit.org_unit_versions v
+  SET ext_labels_snapshot = orgunit.rename_jsonb_object_key_strict(v.ext_labels_snapshot, p_old_field_key, p_new_field_key)
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND jsonb_typeof(v.ext_labels_snapshot) = 'object'
+    AND v.ext_labels_snapshot ? p_old_field_key;
+  GET DIAGNOSTICS v_updated_versions = ROW_COUNT;
 
-  v_after_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, p_effective_date);
-  PERFORM orgunit.assert_org_event_snapshots(p_event_type, v_before_snapshot, v_after_snapshot, NULL);
+  v_payload := jsonb_build_object(
+    'old_field_key', p_old_field_key,
+    'new_field_key', p_new_field_key,
+    'dict_code', v_dict_code,
+    'physical_col', v_cfg.physical_col,
+    'enabled_on', v_cfg.enabled_on::text,
+    'display_label', v_cfg.display_label,
+    'updated_org_events', v_updated_events,
+    'updated_org_unit_versions', v_updated_versions
+  );
 
-  INSERT INTO orgunit.org_events (
-    id,
+  INSERT INTO orgunit.tenant_field_config_events (
     event_uuid,
     tenant_uuid,
-    org_node_key,
     event_type,
-    effective_date,
+    field_key,
     payload,
     request_id,
-    initiator_uuid,
-    before_snapshot,
-    after_snapshot
+    initiator_uuid
   )
   VALUES (
-    v_event_db_id,
-    p_event_uuid,
+    gen_random_uuid(),
     p_tenant_uuid,
-    p_org_node_key,
-    p_event_type,
-    p_effective_date,
+    'REKEY',
+    p_new_field_key,
     v_payload,
     p_request_id,
-    p_initiator_uuid,
-    v_before_snapshot,
-    v_after_snapshot
+    p_initiator_uuid
   );
-
-  RETURN v_event_db_id;
 END;
 $$;
 
-CREATE OR REPLACE FUNCTION orgunit.submit_org_event_rescind(
+CREATE OR REPLACE FUNCTION orgunit.disable_tenant_field_config(
   p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_target_effective_date date,
-  p_reason text,
+  p_field_key text,
+  p_disabled_on date,
   p_request_id text,
   p_initiator_uuid uuid
 )
-RETURNS uuid
+RETURNS void
 LANGUAGE plpgsql
 AS $$
 DECLARE
   v_lock_key text;
-  v_target orgunit.org_events%ROWTYPE;
-  v_existing_request orgunit.org_events%ROWTYPE;
-  v_existing_rescind orgunit.org_events%ROWTYPE;
-  v_reason text;
-  v_event_uuid uuid;
+  v_existing orgunit.tenant_field_config_events%ROWTYPE;
+  v_cfg orgunit.tenant_field_configs%ROWTYPE;
   v_payload jsonb;
-  v_before_snapshot jsonb;
-  v_after_snapshot jsonb;
-  v_event_db_id bigint;
-  v_rescind_outcome text;
-  v_pending_time timestamptz;
 BEGIN
   PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
 
-  IF p_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  IF p_field_key IS NULL OR btrim(p_field_key) = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'field_key is required';
   END IF;
-  IF p_target_effective_date IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'target_effective_date is required';
+  IF p_field_key !~ '^[a-z][a-z0-9_]{0,62}$' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = format('field_key=%s', p_field_key);
   END IF;
-  v_reason := btrim(COALESCE(p_reason, ''));
-  IF v_reason = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'reason is required';
+  IF p_disabled_on IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'disabled_on is required';
   END IF;
   IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
     RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
@@ -4591,3167 +8328,3626 @@ BEGIN
     RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
   END IF;
 
-  v_lock_key := format('org:write-lock:%s', p_tenant_uuid);
+  v_lock_key := format('org:field-config-lock:%s', p_tenant_uuid);
   PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
 
-  SELECT * INTO v_existing_request
-  FROM orgunit.org_events
+  SELECT * INTO v_existing
+  FROM orgunit.tenant_field_config_events
   WHERE tenant_uuid = p_tenant_uuid
     AND request_id = p_request_id
   LIMIT 1;
 
   IF FOUND THEN
-    IF v_existing_request.event_type <> 'RESCIND_EVENT'
-      OR v_existing_request.org_node_key <> p_org_node_key
-      OR v_existing_request.effective_date <> p_target_effective_date
+    IF v_existing.event_type <> 'DISABLE'
+      OR v_existing.field_key <> p_field_key
+      OR v_existing.initiator_uuid <> p_initiator_uuid
+      OR COALESCE(v_existing.payload->>'disabled_on', '') <> p_disabled_on::text
     THEN
       RAISE EXCEPTION USING
         MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
         DETAIL = format('request_id=%s', p_request_id);
     END IF;
 
-    RETURN v_existing_request.event_uuid;
-  END IF;
-
-  SELECT e.* INTO v_target
-  FROM orgunit.org_events e
-  JOIN orgunit.org_events_effective ee
-    ON ee.event_uuid = e.event_uuid
-   AND ee.tenant_uuid = e.tenant_uuid
-   AND ee.org_node_key = e.org_node_key
-  WHERE ee.tenant_uuid = p_tenant_uuid
-    AND ee.org_node_key = p_org_node_key
-    AND ee.effective_date = p_target_effective_date
-  LIMIT 1;
-
-  IF NOT FOUND THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_EVENT_NOT_FOUND',
-      DETAIL = format('org_node_key=%s target_effective_date=%s', p_org_node_key, p_target_effective_date);
-  END IF;
-
-  SELECT * INTO v_existing_rescind
-  FROM orgunit.org_events r
-  WHERE r.tenant_uuid = p_tenant_uuid
-    AND r.event_type IN ('RESCIND_EVENT','RESCIND_ORG')
-    AND r.payload->>'target_event_uuid' = v_target.event_uuid::text
-  LIMIT 1;
-
-  IF FOUND THEN
-    RETURN v_existing_rescind.event_uuid;
-  END IF;
-
-  v_payload := jsonb_build_object(
-    'op', 'RESCIND_EVENT',
-    'reason', v_reason,
-    'target_event_uuid', v_target.event_uuid,
-    'target_effective_date', p_target_effective_date
-  );
-
-  v_before_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, p_target_effective_date);
-  v_event_uuid := gen_random_uuid();
-  v_pending_time := now();
-  SELECT nextval(pg_get_serial_sequence('orgunit.org_events', 'id')) INTO v_event_db_id;
-
-  PERFORM orgunit.rebuild_org_unit_versions_for_org_with_pending_event(
-    p_tenant_uuid,
-    p_org_node_key,
-    v_event_db_id,
-    v_event_uuid,
-    'RESCIND_EVENT',
-    p_target_effective_date,
-    v_payload,
-    p_request_id,
-    p_initiator_uuid,
-    v_pending_time,
-    v_pending_time,
-    v_pending_time
-  );
-
-  v_after_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, p_target_effective_date);
-  v_rescind_outcome := CASE WHEN v_after_snapshot IS NULL THEN 'ABSENT' ELSE 'PRESENT' END;
-  PERFORM orgunit.assert_org_event_snapshots('RESCIND_EVENT', v_before_snapshot, v_after_snapshot, v_rescind_outcome);
-
-  INSERT INTO orgunit.org_events (
-    id,
-    tenant_uuid,
-    org_node_key,
-    event_uuid,
-    event_type,
-    effective_date,
-    payload,
-    request_id,
-    initiator_uuid,
-    reason,
-    before_snapshot,
-    after_snapshot,
-    rescind_outcome,
-    tx_time,
-    transaction_time,
-    created_at
-  )
-  VALUES (
-    v_event_db_id,
-    p_tenant_uuid,
-    p_org_node_key,
-    v_event_uuid,
-    'RESCIND_EVENT',
-    p_target_effective_date,
-    v_payload,
-    p_request_id,
-    p_initiator_uuid,
-    v_reason,
-    v_before_snapshot,
-    v_after_snapshot,
-    v_rescind_outcome,
-    v_pending_time,
-    v_pending_time,
-    v_pending_time
-  );
-
-  RETURN v_event_uuid;
-END;
-$$;
-
-CREATE OR REPLACE FUNCTION orgunit.submit_org_rescind(
-  p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_reason text,
-  p_request_id text,
-  p_initiator_uuid uuid
-)
-RETURNS int
-LANGUAGE plpgsql
-AS $$
-DECLARE
-  v_lock_key text;
-  v_reason text;
-  v_root_org_node_key char(8);
-  v_node_path ltree;
-  v_event_count int;
-  v_existing_batch_count int;
-  v_need_apply boolean;
-  v_request_id_seq text;
-  v_payload jsonb;
-  v_existing_request orgunit.org_events%ROWTYPE;
-  v_existing_rescind orgunit.org_events%ROWTYPE;
-  v_before_snapshot jsonb;
-  v_after_snapshot jsonb;
-  v_rescind_event_uuid uuid;
-  v_rescind_event_id bigint;
-  v_rescind_outcome text;
-  v_pending_time timestamptz;
-  rec record;
-BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
-
-  IF p_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
-  END IF;
-  v_reason := btrim(COALESCE(p_reason, ''));
-  IF v_reason = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'reason is required';
-  END IF;
-  IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
-  END IF;
-  IF p_initiator_uuid IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
+    RETURN;
   END IF;
 
-  v_lock_key := format('org:write-lock:%s', p_tenant_uuid);
-  PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
-
-  SELECT * INTO v_existing_request
-  FROM orgunit.org_events
+  SELECT * INTO v_cfg
+  FROM orgunit.tenant_field_configs
   WHERE tenant_uuid = p_tenant_uuid
-    AND request_id = p_request_id
+    AND field_key = p_field_key
   LIMIT 1;
 
-  IF FOUND THEN
+  IF NOT FOUND THEN
     RAISE EXCEPTION USING
-      MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
-      DETAIL = format('request_id=%s', p_request_id);
+      MESSAGE = 'ORG_FIELD_CONFIG_NOT_FOUND',
+      DETAIL = format('field_key=%s', p_field_key);
   END IF;
 
-  SELECT t.root_org_node_key INTO v_root_org_node_key
-  FROM orgunit.org_trees t
-  WHERE t.tenant_uuid = p_tenant_uuid
-  LIMIT 1;
-
-  IF v_root_org_node_key = p_org_node_key THEN
+  IF p_disabled_on < v_cfg.enabled_on THEN
     RAISE EXCEPTION USING
-      MESSAGE = 'ORG_ROOT_DELETE_FORBIDDEN',
-      DETAIL = format('org_node_key=%s', p_org_node_key);
+      MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID',
+      DETAIL = format('field_key=%s enabled_on=%s disabled_on=%s', p_field_key, v_cfg.enabled_on, p_disabled_on);
   END IF;
-
-  SELECT v.node_path INTO v_node_path
-  FROM orgunit.org_unit_versions v
-  WHERE v.tenant_uuid = p_tenant_uuid
-    AND v.org_node_key = p_org_node_key
-  ORDER BY lower(v.validity) DESC
-  LIMIT 1;
-
-  IF v_node_path IS NOT NULL AND EXISTS (
-    SELECT 1
-    FROM orgunit.org_unit_versions c
-    WHERE c.tenant_uuid = p_tenant_uuid
-      AND c.node_path <@ v_node_path
-      AND c.org_node_key <> p_org_node_key
-    LIMIT 1
-  ) THEN
+  -- MVP freeze: forbid backdating disables.
+  IF p_disabled_on < current_date THEN
     RAISE EXCEPTION USING
-      MESSAGE = 'ORG_HAS_CHILDREN_CANNOT_DELETE',
-      DETAIL = format('org_node_key=%s', p_org_node_key);
+      MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID',
+      DETAIL = format('field_key=%s disabled_on=%s', p_field_key, p_disabled_on);
   END IF;
 
-  SELECT COUNT(*) INTO v_event_count
-  FROM orgunit.org_events e
-  WHERE e.tenant_uuid = p_tenant_uuid
-    AND e.org_node_key = p_org_node_key;
-
-  IF v_event_count = 0 THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_EVENT_NOT_FOUND',
-      DETAIL = format('org_node_key=%s', p_org_node_key);
+  IF v_cfg.disabled_on IS NOT NULL THEN
+    IF current_date >= v_cfg.disabled_on THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID',
+        DETAIL = format('field_key=%s disabled_on=%s', p_field_key, v_cfg.disabled_on);
+    END IF;
+    IF p_disabled_on <= v_cfg.disabled_on THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID',
+        DETAIL = format('field_key=%s old=%s new=%s', p_field_key, v_cfg.disabled_on, p_disabled_on);
+    END IF;
   END IF;
 
-  SELECT COUNT(*) INTO v_existing_batch_count
-  FROM orgunit.org_events e
-  WHERE e.tenant_uuid = p_tenant_uuid
-    AND e.request_id LIKE p_request_id || '#%';
+  v_payload := jsonb_build_object('disabled_on', p_disabled_on::text);
 
-  IF v_existing_batch_count > 0 AND v_existing_batch_count <> v_event_count THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
-      DETAIL = format('request_id=%s', p_request_id);
+  INSERT INTO orgunit.tenant_field_config_events (
+    event_uuid,
+    tenant_uuid,
+    event_type,
+    field_key,
+    payload,
+    request_id,
+    initiator_uuid
+  )
+  VALUES (
+    gen_random_uuid(),
+    p_tenant_uuid,
+    'DISABLE',
+    p_field_key,
+    v_payload,
+    p_request_id,
+    p_initiator_uuid
+  );
+
+  UPDATE orgunit.tenant_field_configs
+  SET disabled_on = p_disabled_on,
+      disabled_at = now(),
+      updated_at = now()
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_field_key;
+END;
+$$;
+
+-- end: modules/orgunit/infrastructure/persistence/schema/00030_orgunit_field_configs_schema.sql
+
+-- begin: modules/orgunit/infrastructure/persistence/schema/00031_orgunit_field_configs_kernel_privileges.sql
+-- OrgUnit tenant field configs (metadata) kernel privileges (Phase 1)
+-- SSOT: docs/dev-plans/100b-org-metadata-wide-table-phase1-schema-and-metadata-skeleton.md
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'orgunit_kernel') THEN
+    CREATE ROLE orgunit_kernel NOLOGIN NOBYPASSRLS;
   END IF;
+END $$;
 
-  v_need_apply := false;
+GRANT USAGE ON SCHEMA orgunit TO orgunit_kernel;
 
-  FOR rec IN
-    SELECT
-      row_number() OVER (ORDER BY e.effective_date, e.id) AS seq,
-      e.event_uuid,
-      COALESCE(ee.effective_date, e.effective_date) AS target_effective_date
-    FROM orgunit.org_events e
-    LEFT JOIN orgunit.org_events_effective ee
-      ON ee.event_uuid = e.event_uuid
-     AND ee.tenant_uuid = e.tenant_uuid
-     AND ee.org_node_key = e.org_node_key
-    WHERE e.tenant_uuid = p_tenant_uuid
-      AND e.org_node_key = p_org_node_key
-    ORDER BY e.effective_date, e.id
-  LOOP
-    v_request_id_seq := format('%s#%s', p_request_id, lpad(rec.seq::text, 4, '0'));
-    SELECT * INTO v_existing_request
-    FROM orgunit.org_events e
-    WHERE e.tenant_uuid = p_tenant_uuid
-      AND e.request_id = v_request_id_seq
-    LIMIT 1;
+ALTER TABLE IF EXISTS orgunit.tenant_field_config_events OWNER TO orgunit_kernel;
+ALTER TABLE IF EXISTS orgunit.tenant_field_configs OWNER TO orgunit_kernel;
 
-    IF FOUND THEN
-      IF v_existing_request.event_type <> 'RESCIND_ORG'
-        OR v_existing_request.org_node_key <> p_org_node_key
-        OR COALESCE(v_existing_request.payload->>'target_event_uuid', '') <> rec.event_uuid::text
-      THEN
-        RAISE EXCEPTION USING
-          MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
-          DETAIL = format('request_id=%s', p_request_id);
-      END IF;
-      CONTINUE;
-    END IF;
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE
+  orgunit.tenant_field_config_events,
+  orgunit.tenant_field_configs
+TO orgunit_kernel;
 
-    v_need_apply := true;
-  END LOOP;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA orgunit TO orgunit_kernel;
 
-  IF NOT v_need_apply THEN
-    RETURN v_event_count;
-  END IF;
+ALTER FUNCTION orgunit.guard_tenant_field_configs_write()
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.guard_tenant_field_configs_write()
+  SET search_path = pg_catalog, orgunit, public;
 
-  FOR rec IN
-    SELECT
-      row_number() OVER (ORDER BY e.effective_date, e.id) AS seq,
-      e.event_uuid,
-      COALESCE(ee.effective_date, e.effective_date) AS target_effective_date
-    FROM orgunit.org_events e
-    LEFT JOIN orgunit.org_events_effective ee
-      ON ee.event_uuid = e.event_uuid
-     AND ee.tenant_uuid = e.tenant_uuid
-     AND ee.org_node_key = e.org_node_key
-    WHERE e.tenant_uuid = p_tenant_uuid
-      AND e.org_node_key = p_org_node_key
-    ORDER BY e.effective_date, e.id
-  LOOP
-    v_request_id_seq := format('%s#%s', p_request_id, lpad(rec.seq::text, 4, '0'));
-    SELECT * INTO v_existing_request
-    FROM orgunit.org_events e
-    WHERE e.tenant_uuid = p_tenant_uuid
-      AND e.request_id = v_request_id_seq
-    LIMIT 1;
+ALTER FUNCTION orgunit.assert_tenant_field_configs_update_allowed()
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.assert_tenant_field_configs_update_allowed()
+  SET search_path = pg_catalog, orgunit, public;
 
-    IF FOUND THEN
-      CONTINUE;
-    END IF;
+ALTER FUNCTION orgunit.enable_tenant_field_config(uuid, text, text, date, text, jsonb, text, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.enable_tenant_field_config(uuid, text, text, date, text, jsonb, text, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.enable_tenant_field_config(uuid, text, text, date, text, jsonb, text, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+ALTER FUNCTION orgunit.disable_tenant_field_config(uuid, text, date, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.disable_tenant_field_config(uuid, text, date, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.disable_tenant_field_config(uuid, text, date, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+ALTER FUNCTION orgunit.rename_jsonb_object_key_strict(jsonb, text, text)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.rename_jsonb_object_key_strict(jsonb, text, text)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.rename_jsonb_object_key_strict(jsonb, text, text)
+  SET search_path = pg_catalog, orgunit, public;
+
+ALTER FUNCTION orgunit.rekey_tenant_field_config(uuid, text, text, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.rekey_tenant_field_config(uuid, text, text, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.rekey_tenant_field_config(uuid, text, text, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.tenant_field_config_events, ' ||
+      'orgunit.tenant_field_configs ' ||
+      'FROM app';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.tenant_field_config_events, ' ||
+      'orgunit.tenant_field_configs ' ||
+      'TO app';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_runtime') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.tenant_field_config_events, ' ||
+      'orgunit.tenant_field_configs ' ||
+      'FROM app_runtime';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.tenant_field_config_events, ' ||
+      'orgunit.tenant_field_configs ' ||
+      'TO app_runtime';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_nobypassrls') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.tenant_field_config_events, ' ||
+      'orgunit.tenant_field_configs ' ||
+      'FROM app_nobypassrls';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.tenant_field_config_events, ' ||
+      'orgunit.tenant_field_configs ' ||
+      'TO app_nobypassrls';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.tenant_field_config_events, ' ||
+      'orgunit.tenant_field_configs ' ||
+      'FROM superadmin_runtime';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.tenant_field_config_events, ' ||
+      'orgunit.tenant_field_configs ' ||
+      'TO superadmin_runtime';
+  END IF;
+END $$;
 
-    SELECT * INTO v_existing_rescind
-    FROM orgunit.org_events r
-    WHERE r.tenant_uuid = p_tenant_uuid
-      AND r.event_type IN ('RESCIND_EVENT','RESCIND_ORG')
-      AND r.payload->>'target_event_uuid' = rec.event_uuid::text
-    LIMIT 1;
+-- end: modules/orgunit/infrastructure/persistence/schema/00031_orgunit_field_configs_kernel_privileges.sql
 
-    IF FOUND THEN
-      CONTINUE;
-    END IF;
+-- begin: modules/orgunit/infrastructure/persistence/schema/00032_orgunit_field_policies_schema.sql
+-- OrgUnit tenant field policies (maintainable/default-rule/scope)
+-- SSOT: docs/dev-plans/120-org-field-default-values-cel-rule-engine-roadmap.md
 
-    v_payload := jsonb_build_object(
-      'op', 'RESCIND_ORG',
-      'reason', v_reason,
-      'batch_request_id', p_request_id,
-      'target_event_uuid', rec.event_uuid,
-      'target_effective_date', rec.target_effective_date
-    );
+CREATE TABLE IF NOT EXISTS orgunit.tenant_field_policies (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL,
+  field_key text NOT NULL,
+  scope_type text NOT NULL,
+  scope_key text NOT NULL,
+  maintainable boolean NOT NULL DEFAULT true,
+  default_mode text NOT NULL DEFAULT 'NONE',
+  default_rule_expr text NULL,
+  enabled_on date NOT NULL,
+  disabled_on date NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  disabled_at timestamptz NULL,
+  CONSTRAINT tenant_field_policies_field_key_format_check CHECK (field_key ~ '^[a-z][a-z0-9_]{0,62}$'),
+  CONSTRAINT tenant_field_policies_scope_type_check CHECK (scope_type IN ('GLOBAL','FORM')),
+  CONSTRAINT tenant_field_policies_scope_key_check CHECK (
+    (scope_type = 'GLOBAL' AND scope_key = 'global')
+    OR
+    (scope_type = 'FORM' AND scope_key IN (
+      'orgunit.create_dialog',
+      'orgunit.details.add_version_dialog',
+      'orgunit.details.insert_version_dialog',
+      'orgunit.details.correct_dialog'
+    ))
+  ),
+  CONSTRAINT tenant_field_policies_default_mode_check CHECK (default_mode IN ('NONE','CEL')),
+  CONSTRAINT tenant_field_policies_default_rule_expr_required_check CHECK (
+    (default_mode = 'NONE' AND default_rule_expr IS NULL)
+    OR
+    (default_mode = 'CEL' AND default_rule_expr IS NOT NULL AND btrim(default_rule_expr) <> '')
+  ),
+  CONSTRAINT tenant_field_policies_disabled_on_check CHECK (disabled_on IS NULL OR disabled_on > enabled_on)
+);
 
-    v_before_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, rec.target_effective_date);
-    v_rescind_event_uuid := gen_random_uuid();
-    v_pending_time := now();
-    SELECT nextval(pg_get_serial_sequence('orgunit.org_events', 'id')) INTO v_rescind_event_id;
+CREATE INDEX IF NOT EXISTS tenant_field_policies_tenant_field_scope_idx
+  ON orgunit.tenant_field_policies (tenant_uuid, field_key, scope_type, scope_key, enabled_on DESC);
 
-    PERFORM orgunit.rebuild_org_unit_versions_for_org_with_pending_event(
-      p_tenant_uuid,
-      p_org_node_key,
-      v_rescind_event_id,
-      v_rescind_event_uuid,
-      'RESCIND_ORG',
-      rec.target_effective_date,
-      v_payload,
-      v_request_id_seq,
-      p_initiator_uuid,
-      v_pending_time,
-      v_pending_time,
-      v_pending_time
-    );
+CREATE TABLE IF NOT EXISTS orgunit.tenant_field_policy_events (
+  id bigserial PRIMARY KEY,
+  event_uuid uuid NOT NULL,
+  tenant_uuid uuid NOT NULL,
+  event_type text NOT NULL,
+  field_key text NOT NULL,
+  scope_type text NOT NULL,
+  scope_key text NOT NULL,
+  payload jsonb NOT NULL DEFAULT '{}'::jsonb,
+  request_id text NOT NULL,
+  initiator_uuid uuid NOT NULL,
+  transaction_time timestamptz NOT NULL DEFAULT now(),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT tenant_field_policy_events_event_type_check CHECK (event_type IN ('UPSERT','DISABLE')),
+  CONSTRAINT tenant_field_policy_events_field_key_format_check CHECK (field_key ~ '^[a-z][a-z0-9_]{0,62}$'),
+  CONSTRAINT tenant_field_policy_events_scope_type_check CHECK (scope_type IN ('GLOBAL','FORM')),
+  CONSTRAINT tenant_field_policy_events_scope_key_non_empty_check CHECK (btrim(scope_key) <> ''),
+  CONSTRAINT tenant_field_policy_events_request_id_unique UNIQUE (tenant_uuid, request_id),
+  CONSTRAINT tenant_field_policy_events_event_uuid_unique UNIQUE (event_uuid),
+  CONSTRAINT tenant_field_policy_events_payload_is_object_check CHECK (jsonb_typeof(payload) = 'object')
+);
 
-    v_after_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, rec.target_effective_date);
-    v_rescind_outcome := CASE WHEN v_after_snapshot IS NULL THEN 'ABSENT' ELSE 'PRESENT' END;
-    PERFORM orgunit.assert_org_event_snapshots('RESCIND_ORG', v_before_snapshot, v_after_snapshot, v_rescind_outcome);
+CREATE INDEX IF NOT EXISTS tenant_field_policy_events_tenant_time_idx
+  ON orgunit.tenant_field_policy_events (tenant_uuid, transaction_time DESC, id DESC);
 
-    INSERT INTO orgunit.org_events (
-      id,
-      tenant_uuid,
-      org_node_key,
-      event_uuid,
-      event_type,
-      effective_date,
-      payload,
-      request_id,
-      initiator_uuid,
-      reason,
-      before_snapshot,
-      after_snapshot,
-      rescind_outcome,
-      tx_time,
-      transaction_time,
-      created_at
-    )
-    VALUES (
-      v_rescind_event_id,
-      p_tenant_uuid,
-      p_org_node_key,
-      v_rescind_event_uuid,
-      'RESCIND_ORG',
-      rec.target_effective_date,
-      v_payload,
-      v_request_id_seq,
-      p_initiator_uuid,
-      v_reason,
-      v_before_snapshot,
-      v_after_snapshot,
-      v_rescind_outcome,
-      v_pending_time,
-      v_pending_time,
-      v_pending_time
-    );
-  END LOOP;
+ALTER TABLE orgunit.tenant_field_policies ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.tenant_field_policies FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.tenant_field_policies;
+CREATE POLICY tenant_isolation ON orgunit.tenant_field_policies
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
 
-  RETURN v_event_count;
-END;
-$$;
+ALTER TABLE orgunit.tenant_field_policy_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.tenant_field_policy_events FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.tenant_field_policy_events;
+CREATE POLICY tenant_isolation ON orgunit.tenant_field_policy_events
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
 
-CREATE OR REPLACE FUNCTION orgunit.submit_org_event_correction(
-  p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_target_effective_date date,
-  p_patch jsonb,
-  p_request_id text,
-  p_initiator_uuid uuid
-)
-RETURNS uuid
+CREATE OR REPLACE FUNCTION orgunit.guard_tenant_field_policies_write()
+RETURNS trigger
 LANGUAGE plpgsql
 AS $$
-DECLARE
-  v_lock_key text;
-  v_target orgunit.org_events%ROWTYPE;
-  v_existing_request orgunit.org_events%ROWTYPE;
-  v_existing_rescind orgunit.org_events%ROWTYPE;
-  v_patch jsonb;
-  v_payload jsonb;
-  v_effective_payload jsonb;
-  v_target_effective date;
-  v_prev_effective date;
-  v_new_effective date;
-  v_next_effective date;
-  v_parent_org_node_key char(8);
-  v_target_path ltree;
-  v_descendant_min_create date;
-  v_event_uuid uuid;
-  v_before_snapshot jsonb;
-  v_after_snapshot jsonb;
-  v_event_db_id bigint;
-  v_pending_time timestamptz;
-  v_base_ext jsonb;
-  v_patch_ext jsonb;
-  v_ext_merged jsonb;
-  v_base_labels jsonb;
-  v_patch_labels jsonb;
-  v_labels_merged jsonb;
-  v_label_key text;
-  v_label_remove_keys text[];
 BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
-
-  IF p_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
-  END IF;
-  IF p_target_effective_date IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'target_effective_date is required';
-  END IF;
-  IF p_patch IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'patch is required';
-  END IF;
-  IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
-  END IF;
-  IF p_initiator_uuid IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
-  END IF;
-
-  v_patch := p_patch;
-  IF jsonb_typeof(v_patch) <> 'object' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'patch must be an object';
-  END IF;
-
-  IF v_patch ? 'ext' AND jsonb_typeof(v_patch->'ext') <> 'object' THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_EXT_PAYLOAD_INVALID_SHAPE',
-      DETAIL = 'patch.ext must be an object';
-  END IF;
-  IF v_patch ? 'ext_labels_snapshot' AND jsonb_typeof(v_patch->'ext_labels_snapshot') <> 'object' THEN
+  IF current_user <> 'orgunit_kernel' THEN
     RAISE EXCEPTION USING
-      MESSAGE = 'ORG_EXT_PAYLOAD_INVALID_SHAPE',
-      DETAIL = 'patch.ext_labels_snapshot must be an object';
-  END IF;
-  IF v_patch ? 'ext_labels_snapshot' THEN
-    FOR v_label_key IN
-      SELECT key
-      FROM jsonb_object_keys(v_patch->'ext_labels_snapshot') AS t(key)
-    LOOP
-      IF NOT (COALESCE(v_patch->'ext', '{}'::jsonb) ? v_label_key) THEN
-        RAISE EXCEPTION USING
-          MESSAGE = 'ORG_EXT_LABEL_SNAPSHOT_NOT_ALLOWED',
-          DETAIL = format('field_key=%s', v_label_key);
-      END IF;
-    END LOOP;
+      MESSAGE = 'ORGUNIT_FIELD_POLICIES_WRITE_FORBIDDEN',
+      DETAIL = format('role=%s table=%s', current_user, TG_TABLE_NAME);
   END IF;
-
-  v_lock_key := format('org:write-lock:%s', p_tenant_uuid);
-  PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
-
-  SELECT e.* INTO v_target
-  FROM orgunit.org_events e
-  JOIN orgunit.org_events_effective ee
-    ON ee.event_uuid = e.event_uuid
-   AND ee.tenant_uuid = e.tenant_uuid
-   AND ee.org_node_key = e.org_node_key
-  WHERE ee.tenant_uuid = p_tenant_uuid
-    AND ee.org_node_key = p_org_node_key
-    AND ee.effective_date = p_target_effective_date
-  LIMIT 1;
-
-  IF NOT FOUND THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_EVENT_NOT_FOUND',
-      DETAIL = format('org_node_key=%s target_effective_date=%s', p_org_node_key, p_target_effective_date);
+  IF TG_OP = 'DELETE' THEN
+    RETURN OLD;
   END IF;
+  RETURN NEW;
+END;
+$$;
 
-  SELECT * INTO v_existing_rescind
-  FROM orgunit.org_events r
-  WHERE r.tenant_uuid = p_tenant_uuid
-    AND r.event_type IN ('RESCIND_EVENT','RESCIND_ORG')
-    AND r.payload->>'target_event_uuid' = v_target.event_uuid::text
-  LIMIT 1;
+DROP TRIGGER IF EXISTS guard_tenant_field_policies_write ON orgunit.tenant_field_policies;
+CREATE TRIGGER guard_tenant_field_policies_write
+BEFORE INSERT OR UPDATE OR DELETE ON orgunit.tenant_field_policies
+FOR EACH ROW EXECUTE FUNCTION orgunit.guard_tenant_field_policies_write();
 
-  IF FOUND THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_EVENT_RESCINDED',
-      DETAIL = format('event_uuid=%s', v_target.event_uuid);
+DROP TRIGGER IF EXISTS guard_tenant_field_policy_events_write ON orgunit.tenant_field_policy_events;
+CREATE TRIGGER guard_tenant_field_policy_events_write
+BEFORE INSERT OR UPDATE OR DELETE ON orgunit.tenant_field_policy_events
+FOR EACH ROW EXECUTE FUNCTION orgunit.guard_tenant_field_policies_write();
+
+CREATE OR REPLACE FUNCTION orgunit.assert_tenant_field_policies_non_overlapping()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_overlap_id bigint;
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    RETURN OLD;
   END IF;
 
-  SELECT ee.effective_date, ee.payload
-  INTO v_target_effective, v_effective_payload
-  FROM orgunit.org_events_effective ee
-  WHERE ee.tenant_uuid = p_tenant_uuid
-    AND ee.org_node_key = p_org_node_key
-    AND ee.event_uuid = v_target.event_uuid
+  SELECT p.id
+  INTO v_overlap_id
+  FROM orgunit.tenant_field_policies p
+  WHERE p.tenant_uuid = NEW.tenant_uuid
+    AND p.field_key = NEW.field_key
+    AND p.scope_type = NEW.scope_type
+    AND p.scope_key = NEW.scope_key
+    AND p.id <> NEW.id
+    AND daterange(
+      NEW.enabled_on,
+      COALESCE(NEW.disabled_on, 'infinity'::date),
+      '[)'
+    ) && daterange(
+      p.enabled_on,
+      COALESCE(p.disabled_on, 'infinity'::date),
+      '[)'
+    )
   LIMIT 1;
 
-  v_payload := (COALESCE(v_effective_payload, '{}'::jsonb) - 'ext' - 'ext_labels_snapshot')
-    || (v_patch - 'ext' - 'ext_labels_snapshot');
-
-  v_base_ext := CASE
-    WHEN jsonb_typeof(COALESCE(v_effective_payload, '{}'::jsonb)->'ext') = 'object'
-      THEN COALESCE(v_effective_payload, '{}'::jsonb)->'ext'
-    ELSE '{}'::jsonb
-  END;
-  v_patch_ext := CASE
-    WHEN jsonb_typeof(v_patch->'ext') = 'object'
-      THEN v_patch->'ext'
-    ELSE '{}'::jsonb
-  END;
-  v_ext_merged := v_base_ext || v_patch_ext;
+  IF v_overlap_id IS NOT NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'FIELD_POLICY_SCOPE_OVERLAP',
+      DETAIL = format(
+        'field_key=%s scope_type=%s scope_key=%s overlap_id=%s',
+        NEW.field_key, NEW.scope_type, NEW.scope_key, v_overlap_id
+      );
+  END IF;
 
-  v_base_labels := CASE
-    WHEN jsonb_typeof(COALESCE(v_effective_payload, '{}'::jsonb)->'ext_labels_snapshot') = 'object'
-      THEN COALESCE(v_effective_payload, '{}'::jsonb)->'ext_labels_snapshot'
-    ELSE '{}'::jsonb
-  END;
-  v_patch_labels := CASE
-    WHEN jsonb_typeof(v_patch->'ext_labels_snapshot') = 'object'
-      THEN v_patch->'ext_labels_snapshot'
-    ELSE '{}'::jsonb
-  END;
-  v_labels_merged := v_base_labels || v_patch_labels;
+  RETURN NEW;
+END;
+$$;
 
-  SELECT array_agg(key) INTO v_label_remove_keys
-  FROM jsonb_each(v_ext_merged)
-  WHERE value = 'null'::jsonb;
-  v_label_remove_keys := COALESCE(v_label_remove_keys, ARRAY[]::text[]);
-  v_labels_merged := v_labels_merged - v_label_remove_keys;
+DROP TRIGGER IF EXISTS tenant_field_policies_non_overlapping ON orgunit.tenant_field_policies;
+CREATE TRIGGER tenant_field_policies_non_overlapping
+BEFORE INSERT OR UPDATE ON orgunit.tenant_field_policies
+FOR EACH ROW EXECUTE FUNCTION orgunit.assert_tenant_field_policies_non_overlapping();
 
-  IF COALESCE(v_effective_payload, '{}'::jsonb) ? 'ext' OR v_patch ? 'ext' THEN
-    v_payload := v_payload || jsonb_build_object('ext', v_ext_merged);
+CREATE OR REPLACE FUNCTION orgunit.upsert_tenant_field_policy(
+  p_tenant_uuid uuid,
+  p_field_key text,
+  p_scope_type text,
+  p_scope_key text,
+  p_maintainable boolean,
+  p_default_mode text,
+  p_default_rule_expr text,
+  p_enabled_on date,
+  p_request_id text,
+  p_initiator_uuid uuid
+)
+RETURNS bigint
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_scope_type text := upper(btrim(p_scope_type));
+  v_scope_key text := btrim(p_scope_key);
+  v_default_mode text := upper(btrim(p_default_mode));
+  v_default_rule_expr text := NULLIF(btrim(p_default_rule_expr), '');
+  v_event_type text;
+  v_policy_id bigint;
+  v_open_id bigint;
+  v_open_enabled_on date;
+  v_next_enabled_on date;
+BEGIN
+  IF v_scope_type NOT IN ('GLOBAL','FORM') THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_type invalid';
   END IF;
-  IF COALESCE(v_effective_payload, '{}'::jsonb) ? 'ext_labels_snapshot' OR v_patch ? 'ext_labels_snapshot' THEN
-    v_payload := v_payload || jsonb_build_object('ext_labels_snapshot', v_labels_merged);
+  IF v_scope_type = 'GLOBAL' THEN
+    v_scope_key := 'global';
   END IF;
-  v_new_effective := v_target_effective;
-
-  IF v_payload ? 'effective_date' THEN
-    BEGIN
-      v_new_effective := NULLIF(btrim(v_payload->>'effective_date'), '')::date;
-    EXCEPTION
-      WHEN invalid_text_representation THEN
-        RAISE EXCEPTION USING
-          MESSAGE = 'EFFECTIVE_DATE_INVALID',
-          DETAIL = format('effective_date=%s', v_payload->>'effective_date');
-    END;
-    IF v_new_effective IS NULL THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'EFFECTIVE_DATE_INVALID',
-        DETAIL = 'effective_date is required';
-    END IF;
-    -- keep effective_date in payload for audit and correction view
+  IF v_scope_key = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_key required';
+  END IF;
+  IF v_default_mode NOT IN ('NONE','CEL') THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'default_mode invalid';
+  END IF;
+  IF v_default_mode = 'NONE' THEN
+    v_default_rule_expr := NULL;
+  END IF;
+  IF v_default_mode = 'CEL' AND v_default_rule_expr IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'FIELD_POLICY_EXPR_INVALID', DETAIL = 'default_rule_expr required';
   END IF;
 
-  SELECT MAX(e.effective_date) INTO v_prev_effective
-  FROM orgunit.org_events_effective e
-  WHERE e.tenant_uuid = p_tenant_uuid
-    AND e.org_node_key = p_org_node_key
-    AND e.effective_date < v_target_effective;
+  PERFORM pg_advisory_xact_lock(hashtextextended(
+    format('orgunit.field_policy:%s:%s:%s:%s', p_tenant_uuid, p_field_key, v_scope_type, v_scope_key),
+    0
+  ));
 
-  SELECT MIN(e.effective_date) INTO v_next_effective
-  FROM orgunit.org_events_effective e
-  WHERE e.tenant_uuid = p_tenant_uuid
-    AND e.org_node_key = p_org_node_key
-    AND e.effective_date > v_target_effective;
+  SELECT event_type, (payload->>'policy_id')::bigint
+  INTO v_event_type, v_policy_id
+  FROM orgunit.tenant_field_policy_events
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id
+  LIMIT 1;
 
-  IF v_prev_effective IS NOT NULL AND v_new_effective <= v_prev_effective THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'EFFECTIVE_DATE_OUT_OF_RANGE',
-      DETAIL = format('prev=%s new=%s', v_prev_effective, v_new_effective);
-  END IF;
-  IF v_next_effective IS NOT NULL AND v_new_effective >= v_next_effective THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'EFFECTIVE_DATE_OUT_OF_RANGE',
-      DETAIL = format('next=%s new=%s', v_next_effective, v_new_effective);
+  IF FOUND THEN
+    IF v_event_type <> 'UPSERT' THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_REQUEST_ID_CONFLICT', DETAIL = format('request_id=%s', p_request_id);
+    END IF;
+    RETURN v_policy_id;
   END IF;
 
-  IF EXISTS (
-    SELECT 1
-    FROM orgunit.org_events_effective e
-    WHERE e.tenant_uuid = p_tenant_uuid
-      AND e.org_node_key = p_org_node_key
-      AND e.effective_date = v_new_effective
-      AND e.event_uuid <> v_target.event_uuid
-    LIMIT 1
-  ) THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'EVENT_DATE_CONFLICT',
-      DETAIL = format('org_node_key=%s effective_date=%s', p_org_node_key, v_new_effective);
+  SELECT id, enabled_on
+  INTO v_open_id, v_open_enabled_on
+  FROM orgunit.tenant_field_policies
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_field_key
+    AND scope_type = v_scope_type
+    AND scope_key = v_scope_key
+    AND disabled_on IS NULL
+  ORDER BY enabled_on DESC
+  LIMIT 1
+  FOR UPDATE;
+
+  IF v_open_id IS NOT NULL AND v_open_enabled_on < p_enabled_on THEN
+    UPDATE orgunit.tenant_field_policies
+    SET disabled_on = p_enabled_on, disabled_at = now(), updated_at = now()
+    WHERE id = v_open_id;
   END IF;
 
-  IF v_payload ? 'parent_org_node_key' THEN
-    v_parent_org_node_key := NULLIF(v_payload->>'parent_org_node_key', '')::char(8);
-    IF v_parent_org_node_key IS NOT NULL THEN
-      IF v_parent_org_node_key = p_org_node_key THEN
-        RAISE EXCEPTION USING
-          MESSAGE = 'ORG_CYCLE_DETECTED',
-          DETAIL = format('org_node_key=%s parent_org_node_key=%s', p_org_node_key, v_parent_org_node_key);
-      END IF;
-      IF NOT EXISTS (
-        SELECT 1
-        FROM orgunit.org_unit_versions v
-        WHERE v.tenant_uuid = p_tenant_uuid
-          AND v.org_node_key = v_parent_org_node_key
-          AND v.validity @> v_new_effective
-        LIMIT 1
-      ) THEN
-        RAISE EXCEPTION USING
-          MESSAGE = 'ORG_PARENT_NOT_FOUND_AS_OF',
-          DETAIL = format('parent_org_node_key=%s as_of=%s', v_parent_org_node_key, v_new_effective);
-      END IF;
-    END IF;
+  UPDATE orgunit.tenant_field_policies
+  SET maintainable = p_maintainable,
+      default_mode = v_default_mode,
+      default_rule_expr = v_default_rule_expr,
+      updated_at = now()
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_field_key
+    AND scope_type = v_scope_type
+    AND scope_key = v_scope_key
+    AND enabled_on = p_enabled_on
+  RETURNING id INTO v_policy_id;
+
+  IF v_policy_id IS NULL THEN
+    SELECT MIN(enabled_on)
+    INTO v_next_enabled_on
+    FROM orgunit.tenant_field_policies
+    WHERE tenant_uuid = p_tenant_uuid
+      AND field_key = p_field_key
+      AND scope_type = v_scope_type
+      AND scope_key = v_scope_key
+      AND enabled_on > p_enabled_on;
+
+    INSERT INTO orgunit.tenant_field_policies (
+      tenant_uuid,
+      field_key,
+      scope_type,
+      scope_key,
+      maintainable,
+      default_mode,
+      default_rule_expr,
+      enabled_on,
+      disabled_on
+    ) VALUES (
+      p_tenant_uuid,
+      p_field_key,
+      v_scope_type,
+      v_scope_key,
+      p_maintainable,
+      v_default_mode,
+      v_default_rule_expr,
+      p_enabled_on,
+      v_next_enabled_on
+    )
+    RETURNING id INTO v_policy_id;
   END IF;
 
-  -- Guard high-risk create reordering that would force replay to fail after full-table churn.
-  IF v_target.event_type = 'CREATE' AND v_new_effective > v_target_effective THEN
-    SELECT v.node_path INTO v_target_path
-    FROM orgunit.org_unit_versions v
-    WHERE v.tenant_uuid = p_tenant_uuid
-      AND v.org_node_key = p_org_node_key
-      AND v.validity @> v_target_effective
-    ORDER BY lower(v.validity) DESC
-    LIMIT 1;
+  INSERT INTO orgunit.tenant_field_policy_events (
+    event_uuid,
+    tenant_uuid,
+    event_type,
+    field_key,
+    scope_type,
+    scope_key,
+    payload,
+    request_id,
+    initiator_uuid
+  ) VALUES (
+    gen_random_uuid(),
+    p_tenant_uuid,
+    'UPSERT',
+    p_field_key,
+    v_scope_type,
+    v_scope_key,
+    jsonb_build_object('policy_id', v_policy_id),
+    p_request_id,
+    p_initiator_uuid
+  );
 
-    IF v_target_path IS NOT NULL THEN
-      SELECT MIN(e.effective_date) INTO v_descendant_min_create
-      FROM orgunit.org_events_effective e
-      WHERE e.tenant_uuid = p_tenant_uuid
-        AND e.event_type = 'CREATE'
-        AND e.org_node_key <> p_org_node_key
-        AND EXISTS (
-          SELECT 1
-          FROM orgunit.org_unit_versions dv
-          WHERE dv.tenant_uuid = p_tenant_uuid
-            AND dv.org_node_key = e.org_node_key
-            AND dv.node_path <@ v_target_path
-          LIMIT 1
-        );
+  RETURN v_policy_id;
+END;
+$$;
 
-      IF v_descendant_min_create IS NOT NULL AND v_descendant_min_create < v_new_effective THEN
-        RAISE EXCEPTION USING
-          MESSAGE = 'ORG_HIGH_RISK_REORDER_FORBIDDEN',
-          DETAIL = format(
-            'org_node_key=%s target_effective=%s new_effective=%s descendant_create=%s',
-            p_org_node_key,
-            v_target_effective,
-            v_new_effective,
-            v_descendant_min_create
-          );
-      END IF;
-    END IF;
+CREATE OR REPLACE FUNCTION orgunit.disable_tenant_field_policy(
+  p_tenant_uuid uuid,
+  p_field_key text,
+  p_scope_type text,
+  p_scope_key text,
+  p_disabled_on date,
+  p_request_id text,
+  p_initiator_uuid uuid
+)
+RETURNS bigint
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_scope_type text := upper(btrim(p_scope_type));
+  v_scope_key text := btrim(p_scope_key);
+  v_event_type text;
+  v_policy_id bigint;
+  v_enabled_on date;
+  v_disabled_on_old date;
+BEGIN
+  IF v_scope_type NOT IN ('GLOBAL','FORM') THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_type invalid';
+  END IF;
+  IF v_scope_type = 'GLOBAL' THEN
+    v_scope_key := 'global';
+  END IF;
+  IF v_scope_key = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_key required';
   END IF;
 
-  v_payload := jsonb_build_object(
-    'op', 'CORRECT_EVENT',
-    'target_event_uuid', v_target.event_uuid,
-    'target_effective_date', p_target_effective_date
-  ) || v_payload;
+  PERFORM pg_advisory_xact_lock(hashtextextended(
+    format('orgunit.field_policy:%s:%s:%s:%s', p_tenant_uuid, p_field_key, v_scope_type, v_scope_key),
+    0
+  ));
 
-  SELECT * INTO v_existing_request
-  FROM orgunit.org_events
+  SELECT event_type, (payload->>'policy_id')::bigint
+  INTO v_event_type, v_policy_id
+  FROM orgunit.tenant_field_policy_events
   WHERE tenant_uuid = p_tenant_uuid
     AND request_id = p_request_id
   LIMIT 1;
 
   IF FOUND THEN
-    IF v_existing_request.event_type <> 'CORRECT_EVENT'
-      OR v_existing_request.org_node_key <> p_org_node_key
-      OR v_existing_request.payload <> v_payload
-    THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
-        DETAIL = format('request_id=%s', p_request_id);
+    IF v_event_type <> 'DISABLE' THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_REQUEST_ID_CONFLICT', DETAIL = format('request_id=%s', p_request_id);
     END IF;
-
-    RETURN v_existing_request.event_uuid;
+    RETURN v_policy_id;
   END IF;
 
-  v_before_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, p_target_effective_date);
-  v_event_uuid := gen_random_uuid();
-  v_pending_time := now();
-  SELECT nextval(pg_get_serial_sequence('orgunit.org_events', 'id')) INTO v_event_db_id;
+  SELECT id, enabled_on, disabled_on
+  INTO v_policy_id, v_enabled_on, v_disabled_on_old
+  FROM orgunit.tenant_field_policies
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_field_key
+    AND scope_type = v_scope_type
+    AND scope_key = v_scope_key
+    AND enabled_on < p_disabled_on
+    AND p_disabled_on < COALESCE(disabled_on, 'infinity'::date)
+  ORDER BY enabled_on DESC
+  LIMIT 1
+  FOR UPDATE;
 
-  PERFORM orgunit.rebuild_org_unit_versions_for_org_with_pending_event(
-    p_tenant_uuid,
-    p_org_node_key,
-    v_event_db_id,
-    v_event_uuid,
-    'CORRECT_EVENT',
-    p_target_effective_date,
-    v_payload,
-    p_request_id,
-    p_initiator_uuid,
-    v_pending_time,
-    v_pending_time,
-    v_pending_time
-  );
+  IF v_policy_id IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_FIELD_POLICY_NOT_FOUND', DETAIL = format('field_key=%s', p_field_key);
+  END IF;
+  IF p_disabled_on <= v_enabled_on THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID', DETAIL = format('field_key=%s', p_field_key);
+  END IF;
 
-  v_after_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, p_target_effective_date);
-  PERFORM orgunit.assert_org_event_snapshots('CORRECT_EVENT', v_before_snapshot, v_after_snapshot, NULL);
+  UPDATE orgunit.tenant_field_policies
+  SET disabled_on = p_disabled_on, disabled_at = now(), updated_at = now()
+  WHERE id = v_policy_id;
 
-  INSERT INTO orgunit.org_events (
-    id,
-    tenant_uuid,
-    org_node_key,
+  INSERT INTO orgunit.tenant_field_policy_events (
     event_uuid,
+    tenant_uuid,
     event_type,
-    effective_date,
+    field_key,
+    scope_type,
+    scope_key,
     payload,
     request_id,
-    initiator_uuid,
-    before_snapshot,
-    after_snapshot,
-    tx_time,
-    transaction_time,
-    created_at
-  )
-  VALUES (
-    v_event_db_id,
+    initiator_uuid
+  ) VALUES (
+    gen_random_uuid(),
     p_tenant_uuid,
-    p_org_node_key,
-    v_event_uuid,
-    'CORRECT_EVENT',
-    p_target_effective_date,
-    v_payload,
+    'DISABLE',
+    p_field_key,
+    v_scope_type,
+    v_scope_key,
+    jsonb_build_object('policy_id', v_policy_id, 'disabled_on', p_disabled_on),
     p_request_id,
-    p_initiator_uuid,
-    v_before_snapshot,
-    v_after_snapshot,
-    v_pending_time,
-    v_pending_time,
-    v_pending_time
+    p_initiator_uuid
   );
 
-  RETURN v_event_uuid;
-END;
-$$;
+  RETURN v_policy_id;
+END;
+$$;
+
+-- end: modules/orgunit/infrastructure/persistence/schema/00032_orgunit_field_policies_schema.sql
+
+-- begin: modules/orgunit/infrastructure/persistence/schema/00033_orgunit_field_policies_kernel_privileges.sql
+-- OrgUnit tenant field policies kernel privileges
+-- SSOT: docs/dev-plans/120-org-field-default-values-cel-rule-engine-roadmap.md
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'orgunit_kernel') THEN
+    CREATE ROLE orgunit_kernel NOLOGIN NOBYPASSRLS;
+  END IF;
+END $$;
+
+GRANT USAGE ON SCHEMA orgunit TO orgunit_kernel;
+
+ALTER TABLE IF EXISTS orgunit.tenant_field_policies OWNER TO orgunit_kernel;
+ALTER TABLE IF EXISTS orgunit.tenant_field_policy_events OWNER TO orgunit_kernel;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE
+  orgunit.tenant_field_policies,
+  orgunit.tenant_field_policy_events
+TO orgunit_kernel;
+
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA orgunit TO orgunit_kernel;
+
+ALTER FUNCTION orgunit.guard_tenant_field_policies_write()
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.guard_tenant_field_policies_write()
+  SET search_path = pg_catalog, orgunit, public;
 
-CREATE OR REPLACE FUNCTION orgunit.submit_org_status_correction(
-  p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_target_effective_date date,
-  p_target_status text,
-  p_request_id text,
-  p_initiator_uuid uuid
-)
-RETURNS uuid
-LANGUAGE plpgsql
-AS $$
-DECLARE
-  v_lock_key text;
-  v_target orgunit.org_events%ROWTYPE;
-  v_existing_request orgunit.org_events%ROWTYPE;
-  v_existing_rescind orgunit.org_events%ROWTYPE;
-  v_target_status text;
-  v_target_effective date;
-  v_effective_payload jsonb;
-  v_event_uuid uuid;
-  v_payload jsonb;
-  v_before_snapshot jsonb;
-  v_after_snapshot jsonb;
-  v_event_db_id bigint;
-  v_pending_time timestamptz;
-BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+ALTER FUNCTION orgunit.assert_tenant_field_policies_non_overlapping()
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.assert_tenant_field_policies_non_overlapping()
+  SET search_path = pg_catalog, orgunit, public;
 
-  IF p_org_node_key IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+ALTER FUNCTION orgunit.upsert_tenant_field_policy(uuid, text, text, text, boolean, text, text, date, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.upsert_tenant_field_policy(uuid, text, text, text, boolean, text, text, date, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.upsert_tenant_field_policy(uuid, text, text, text, boolean, text, text, date, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+ALTER FUNCTION orgunit.disable_tenant_field_policy(uuid, text, text, text, date, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.disable_tenant_field_policy(uuid, text, text, text, date, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.disable_tenant_field_policy(uuid, text, text, text, date, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.tenant_field_policies, ' ||
+      'orgunit.tenant_field_policy_events ' ||
+      'FROM app';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.tenant_field_policies, ' ||
+      'orgunit.tenant_field_policy_events ' ||
+      'TO app';
   END IF;
-  IF p_target_effective_date IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'target_effective_date is required';
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_runtime') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.tenant_field_policies, ' ||
+      'orgunit.tenant_field_policy_events ' ||
+      'FROM app_runtime';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.tenant_field_policies, ' ||
+      'orgunit.tenant_field_policy_events ' ||
+      'TO app_runtime';
   END IF;
-  IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_nobypassrls') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.tenant_field_policies, ' ||
+      'orgunit.tenant_field_policy_events ' ||
+      'FROM app_nobypassrls';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.tenant_field_policies, ' ||
+      'orgunit.tenant_field_policy_events ' ||
+      'TO app_nobypassrls';
   END IF;
-  IF p_initiator_uuid IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.tenant_field_policies, ' ||
+      'orgunit.tenant_field_policy_events ' ||
+      'FROM superadmin_runtime';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.tenant_field_policies, ' ||
+      'orgunit.tenant_field_policy_events ' ||
+      'TO superadmin_runtime';
   END IF;
+END $$;
 
-  v_target_status := lower(btrim(COALESCE(p_target_status, '')));
-  IF v_target_status IN ('enabled', '有效') THEN
-    v_target_status := 'active';
-  ELSIF v_target_status IN ('inactive', '无效') THEN
-    v_target_status := 'disabled';
-  END IF;
-  IF v_target_status NOT IN ('active', 'disabled') THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'target_status invalid';
+-- end: modules/orgunit/infrastructure/persistence/schema/00033_orgunit_field_policies_kernel_privileges.sql
+
+-- begin: modules/orgunit/infrastructure/persistence/schema/00034_orgunit_tenant_purge.sql
+-- Tenant hard delete: orgunit tables are kernel-owned and guarded, so the purge runs as orgunit_kernel.
+CREATE OR REPLACE FUNCTION orgunit.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'orgunit.org_unit_versions',
+    'orgunit.org_unit_codes',
+    'orgunit.org_trees',
+    'orgunit.org_events',
+    'orgunit.org_node_key_registry',
+    'orgunit.tenant_field_policies',
+    'orgunit.tenant_field_policy_events',
+    'orgunit.tenant_field_configs',
+    'orgunit.tenant_field_config_events'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = orgunit.global_tenant_id() THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORGUNIT_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
   END IF;
 
-  v_lock_key := format('org:write-lock:%s', p_tenant_uuid);
-  PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
 
-  SELECT e.* INTO v_target
-  FROM orgunit.org_events e
-  JOIN orgunit.org_events_effective ee
-    ON ee.event_uuid = e.event_uuid
-   AND ee.tenant_uuid = e.tenant_uuid
-   AND ee.org_node_key = e.org_node_key
-  WHERE ee.tenant_uuid = p_tenant_uuid
-    AND ee.org_node_key = p_org_node_key
-    AND ee.effective_date = p_target_effective_date
-  LIMIT 1;
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
 
-  IF NOT FOUND THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_EVENT_NOT_FOUND',
-      DETAIL = format('org_node_key=%s target_effective_date=%s', p_org_node_key, p_target_effective_date);
-  END IF;
+  RETURN v_counts;
+END;
+$$;
 
-  IF v_target.event_type NOT IN ('ENABLE', 'DISABLE') THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_STATUS_CORRECTION_UNSUPPORTED_TARGET',
-      DETAIL = format('event_type=%s', v_target.event_type);
-  END IF;
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SET search_path = pg_catalog, orgunit, public;
 
-  SELECT * INTO v_existing_rescind
-  FROM orgunit.org_events r
-  WHERE r.tenant_uuid = p_tenant_uuid
-    AND r.event_type IN ('RESCIND_EVENT','RESCIND_ORG')
-    AND r.payload->>'target_event_uuid' = v_target.event_uuid::text
-  LIMIT 1;
+REVOKE ALL ON FUNCTION orgunit.purge_tenant_data(uuid) FROM PUBLIC;
 
-  IF FOUND THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_EVENT_RESCINDED',
-      DETAIL = format('event_uuid=%s', v_target.event_uuid);
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT USAGE ON SCHEMA orgunit TO superadmin_runtime';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.org_node_key_registry, ' ||
+      'orgunit.org_trees, ' ||
+      'orgunit.org_events, ' ||
+      'orgunit.org_unit_versions, ' ||
+      'orgunit.org_unit_codes, ' ||
+      'orgunit.tenant_field_configs, ' ||
+      'orgunit.tenant_field_config_events ' ||
+      'TO superadmin_runtime';
+    EXECUTE 'GRANT EXECUTE ON FUNCTION orgunit.purge_tenant_data(uuid) TO superadmin_runtime';
   END IF;
+END $$;
 
-  SELECT ee.effective_date, ee.payload
-  INTO v_target_effective, v_effective_payload
-  FROM orgunit.org_events_effective ee
-  WHERE ee.tenant_uuid = p_tenant_uuid
-    AND ee.org_node_key = p_org_node_key
-    AND ee.event_uuid = v_target.event_uuid
-  LIMIT 1;
+-- end: modules/orgunit/infrastructure/persistence/schema/00034_orgunit_tenant_purge.sql
 
-  IF v_target_effective IS NULL THEN
-    v_target_effective := v_target.effective_date;
-    v_effective_payload := v_target.payload;
-  END IF;
+-- begin: modules/orgunit/infrastructure/persistence/schema/00035_orgunit_known_at_replay.sql
+-- Bitemporal reads (known_at): org_events_effective_for_replay takes an optional tx_time bound, and with a
+-- NULL org it folds the whole tenant. The per-event projection steps move out of the org rebuild into
+-- apply_replayed_org_event so a known_at replay of the tenant runs exactly the kernel projection.
+DROP FUNCTION IF EXISTS orgunit.org_events_effective_for_replay(uuid, char(8), bigint, uuid, text, date, jsonb, text, uuid, timestamptz, timestamptz, timestamptz);
 
-  v_payload := jsonb_build_object(
-    'op', 'CORRECT_STATUS',
-    'target_status', v_target_status,
-    'target_event_uuid', v_target.event_uuid,
-    'target_effective_date', p_target_effective_date
-  );
+CREATE OR REPLACE FUNCTION orgunit.org_events_effective_for_replay(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_pending_event_id bigint DEFAULT NULL,
+  p_pending_event_uuid uuid DEFAULT NULL,
+  p_pending_event_type text DEFAULT NULL,
+  p_pending_effective_date date DEFAULT NULL,
+  p_pending_payload jsonb DEFAULT NULL,
+  p_pending_request_id text DEFAULT NULL,
+  p_pending_initiator_uuid uuid DEFAULT NULL,
+  p_pending_tx_time timestamptz DEFAULT NULL,
+  p_pending_transaction_time timestamptz DEFAULT NULL,
+  p_pending_created_at timestamptz DEFAULT NULL,
+  p_known_at timestamptz DEFAULT NULL
+)
+RETURNS TABLE (
+  id bigint,
+  event_uuid uuid,
+  tenant_uuid uuid,
+  org_node_key char(8),
+  event_type text,
+  effective_date date,
+  payload jsonb,
+  request_id text,
+  initiator_uuid uuid,
+  transaction_time timestamptz,
+  created_at timestamptz
+)
+LANGUAGE sql
+STABLE
+AS $$
+  WITH source_events AS (
+    SELECT
+      e.id,
+      e.event_uuid,
+      e.tenant_uuid,
+      e.org_node_key,
+      e.event_type,
+      e.effective_date,
+      COALESCE(e.payload, '{}'::jsonb) AS payload,
+      e.request_id,
+      e.initiator_uuid,
+      e.tx_time,
+      e.transaction_time,
+      e.created_at
+    FROM orgunit.org_events e
+    WHERE e.tenant_uuid = p_tenant_uuid
+      AND (p_org_node_key IS NULL OR e.org_node_key = p_org_node_key)
+      AND (p_known_at IS NULL OR e.tx_time <= p_known_at)
+
+    UNION ALL
+
+    SELECT
+      p_pending_event_id,
+      p_pending_event_uuid,
+      p_tenant_uuid,
+      p_org_node_key,
+      p_pending_event_type,
+      p_pending_effective_date,
+      COALESCE(p_pending_payload, '{}'::jsonb),
+      p_pending_request_id,
+      p_pending_initiator_uuid,
+      p_pending_tx_time,
+      p_pending_transaction_time,
+      p_pending_created_at
+    WHERE p_pending_event_id IS NOT NULL
+  ),
+  correction_events AS (
+    SELECT
+      se.*,
+      (se.payload->>'target_event_uuid')::uuid AS target_event_uuid
+    FROM source_events se
+    WHERE se.event_type IN ('CORRECT_EVENT','CORRECT_STATUS','RESCIND_EVENT','RESCIND_ORG')
+      AND se.payload ? 'target_event_uuid'
+  ),
+  latest_corrections AS (
+    SELECT DISTINCT ON (tenant_uuid, target_event_uuid)
+      tenant_uuid,
+      target_event_uuid,
+      event_type AS correction_type,
+      payload AS correction_payload,
+      tx_time,
+      id
+    FROM correction_events
+    ORDER BY tenant_uuid, target_event_uuid, tx_time DESC, id DESC
+  ),
+  latest_effective_date_corrections AS (
+    -- Sticky effective_date: take the latest CORRECT_EVENT that explicitly carries effective_date,
+    -- regardless of later corrections that don't include effective_date.
+    SELECT DISTINCT ON (tenant_uuid, target_event_uuid)
+      tenant_uuid,
+      target_event_uuid,
+      NULLIF(btrim(payload->>'effective_date'), '')::date AS sticky_effective_date,
+      tx_time,
+      id
+    FROM correction_events
+    WHERE event_type = 'CORRECT_EVENT'
+      AND payload ? 'effective_date'
+    ORDER BY tenant_uuid, target_event_uuid, tx_time DESC, id DESC
+  )
+  SELECT
+    se.id,
+    se.event_uuid,
+    se.tenant_uuid,
+    se.org_node_key,
+    CASE
+      WHEN lc.correction_type = 'CORRECT_STATUS'
+        AND COALESCE(lc.correction_payload->>'target_status', '') = 'active'
+        THEN 'ENABLE'
+      WHEN lc.correction_type = 'CORRECT_STATUS'
+        AND COALESCE(lc.correction_payload->>'target_status', '') = 'disabled'
+        THEN 'DISABLE'
+      WHEN lc.correction_type = 'CORRECT_EVENT'
+        AND se.event_type <> 'CREATE'
+        AND (
+          orgunit.merge_org_event_payload_with_correction(se.payload, lc.correction_payload) ?| ARRAY[
+            'name',
+            'parent_org_node_key',
+            'status',
+            'is_business_unit',
+            'manager_uuid',
+            'manager_pernr',
+            'ext',
+            'new_name',
+            'new_parent_org_node_key'
+          ]
+        )
+        THEN 'UPDATE'
+      ELSE se.event_type
+    END AS event_type,
+    COALESCE(lec.sticky_effective_date, se.effective_date) AS effective_date,
+    CASE
+      WHEN lc.correction_type = 'CORRECT_EVENT'
+        THEN orgunit.merge_org_event_payload_with_correction(se.payload, lc.correction_payload)
+      ELSE se.payload
+    END AS payload,
+    se.request_id,
+    se.initiator_uuid,
+    se.transaction_time,
+    se.created_at
+  FROM source_events se
+  LEFT JOIN latest_corrections lc
+    ON lc.tenant_uuid = se.tenant_uuid
+   AND lc.target_event_uuid = se.event_uuid
+  LEFT JOIN latest_effective_date_corrections lec
+    ON lec.tenant_uuid = se.tenant_uuid
+   AND lec.target_event_uuid = se.event_uuid
+  WHERE se.event_type IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT')
+    AND COALESCE(lc.correction_type, '') NOT IN ('RESCIND_EVENT', 'RESCIND_ORG')
+  ORDER BY effective_date, id;
+$$;
 
-  SELECT * INTO v_existing_request
-  FROM orgunit.org_events
-  WHERE tenant_uuid = p_tenant_uuid
-    AND request_id = p_request_id
-  LIMIT 1;
+CREATE OR REPLACE FUNCTION orgunit.apply_replayed_org_event(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_event_id bigint,
+  p_event_type text,
+  p_effective_date date,
+  p_payload jsonb
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_payload jsonb;
+  v_parent_org_node_key char(8);
+  v_new_parent_org_node_key char(8);
+  v_name text;
+  v_new_name text;
+  v_manager_uuid uuid;
+  v_is_business_unit boolean;
+  v_org_code text;
+  v_root_path ltree;
+  v_status text;
+BEGIN
+  v_payload := COALESCE(p_payload, '{}'::jsonb);
 
-  IF FOUND THEN
-    IF v_existing_request.event_type <> 'CORRECT_STATUS'
-      OR v_existing_request.org_node_key <> p_org_node_key
-      OR v_existing_request.payload <> v_payload
+  IF p_event_type = 'CREATE' THEN
+    v_parent_org_node_key := NULLIF(v_payload->>'parent_org_node_key', '')::char(8);
+    v_name := NULLIF(btrim(v_payload->>'name'), '');
+    v_manager_uuid := NULLIF(v_payload->>'manager_uuid', '')::uuid;
+    v_org_code := NULLIF(v_payload->>'org_code', '');
+    v_status := NULLIF(btrim(v_payload->>'status'), '');
+    v_is_business_unit := NULL;
+    IF v_payload ? 'is_business_unit' THEN
+      BEGIN
+        v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
+      EXCEPTION
+        WHEN invalid_text_representation THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_INVALID_ARGUMENT',
+            DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
+      END;
+    END IF;
+    PERFORM orgunit.apply_create_logic(p_tenant_uuid, p_org_node_key, v_org_code, v_parent_org_node_key, p_effective_date, v_name, v_manager_uuid, v_is_business_unit, p_event_id, v_status);
+    SELECT v.node_path INTO v_root_path
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.org_node_key = p_org_node_key
+      AND v.validity @> p_effective_date
+    ORDER BY lower(v.validity) DESC
+    LIMIT 1;
+    IF v_root_path IS NOT NULL THEN
+      PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
+    END IF;
+  ELSIF p_event_type = 'UPDATE' THEN
+    PERFORM orgunit.apply_update_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_payload, p_event_id);
+    IF (v_payload ? 'parent_org_node_key')
+      OR (v_payload ? 'new_parent_org_node_key')
+      OR (v_payload ? 'name')
+      OR (v_payload ? 'new_name')
     THEN
+      SELECT v.node_path INTO v_root_path
+      FROM orgunit.org_unit_versions v
+      WHERE v.tenant_uuid = p_tenant_uuid
+        AND v.org_node_key = p_org_node_key
+        AND v.validity @> p_effective_date
+      ORDER BY lower(v.validity) DESC
+      LIMIT 1;
+      IF v_root_path IS NOT NULL THEN
+        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
+      END IF;
+    END IF;
+  ELSIF p_event_type = 'MOVE' THEN
+    v_new_parent_org_node_key := NULLIF(v_payload->>'new_parent_org_node_key', '')::char(8);
+    PERFORM orgunit.apply_move_logic(p_tenant_uuid, p_org_node_key, v_new_parent_org_node_key, p_effective_date, p_event_id);
+    SELECT v.node_path INTO v_root_path
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.org_node_key = p_org_node_key
+      AND v.validity @> p_effective_date
+    ORDER BY lower(v.validity) DESC
+    LIMIT 1;
+    IF v_root_path IS NOT NULL THEN
+      PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
+    END IF;
+  ELSIF p_event_type = 'RENAME' THEN
+    v_new_name := NULLIF(btrim(v_payload->>'new_name'), '');
+    PERFORM orgunit.apply_rename_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_new_name, p_event_id);
+    SELECT v.node_path INTO v_root_path
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.org_node_key = p_org_node_key
+      AND v.validity @> p_effective_date
+    ORDER BY lower(v.validity) DESC
+    LIMIT 1;
+    IF v_root_path IS NOT NULL THEN
+      PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
+    END IF;
+  ELSIF p_event_type = 'DISABLE' THEN
+    PERFORM orgunit.apply_disable_logic(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_id);
+  ELSIF p_event_type = 'ENABLE' THEN
+    PERFORM orgunit.apply_enable_logic(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_id);
+  ELSIF p_event_type = 'SET_BUSINESS_UNIT' THEN
+    IF NOT (v_payload ? 'is_business_unit') THEN
       RAISE EXCEPTION USING
-        MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
-        DETAIL = format('request_id=%s', p_request_id);
+        MESSAGE = 'ORG_INVALID_ARGUMENT',
+        DETAIL = 'is_business_unit is required';
     END IF;
-
-    RETURN v_existing_request.event_uuid;
+    BEGIN
+      v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
+    EXCEPTION
+      WHEN invalid_text_representation THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_INVALID_ARGUMENT',
+          DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
+    END;
+    PERFORM orgunit.apply_set_business_unit_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_is_business_unit, p_event_id);
+  ELSE
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = format('unexpected event_type: %s', p_event_type);
   END IF;
 
-  v_before_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, p_target_effective_date);
-  v_event_uuid := gen_random_uuid();
-  v_pending_time := now();
-  SELECT nextval(pg_get_serial_sequence('orgunit.org_events', 'id')) INTO v_event_db_id;
-
-  PERFORM orgunit.rebuild_org_unit_versions_for_org_with_pending_event(
-    p_tenant_uuid,
-    p_org_node_key,
-    v_event_db_id,
-    v_event_uuid,
-    'CORRECT_STATUS',
-    p_target_effective_date,
-    v_payload,
-    p_request_id,
-    p_initiator_uuid,
-    v_pending_time,
-    v_pending_time,
-    v_pending_time
-  );
-
-  v_after_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, p_org_node_key, p_target_effective_date);
-  PERFORM orgunit.assert_org_event_snapshots('CORRECT_STATUS', v_before_snapshot, v_after_snapshot, NULL);
-
-  INSERT INTO orgunit.org_events (
-    id,
-    tenant_uuid,
-    org_node_key,
-    event_uuid,
-    event_type,
-    effective_date,
-    payload,
-    request_id,
-    initiator_uuid,
-    before_snapshot,
-    after_snapshot,
-    tx_time,
-    transaction_time,
-    created_at
-  )
-  VALUES (
-    v_event_db_id,
+  PERFORM orgunit.apply_org_event_ext_payload(
     p_tenant_uuid,
     p_org_node_key,
-    v_event_uuid,
-    'CORRECT_STATUS',
-    p_target_effective_date,
+    p_effective_date,
+    p_event_type,
     v_payload,
-    p_request_id,
-    p_initiator_uuid,
-    v_before_snapshot,
-    v_after_snapshot,
-    v_pending_time,
-    v_pending_time,
-    v_pending_time
+    p_event_id
   );
-
-  RETURN v_event_uuid;
 END;
 $$;
 
--- end: modules/orgunit/infrastructure/persistence/schema/00026_orgunit_org_node_key_engine.sql
-
--- begin: modules/orgunit/infrastructure/persistence/schema/00027_orgunit_org_node_key_read.sql
--- DEV-PLAN-320 P3: org_node_key runtime read overlay
-DROP FUNCTION IF EXISTS orgunit.get_org_snapshot(uuid, date);
-
-CREATE OR REPLACE FUNCTION orgunit.get_org_snapshot(p_tenant_uuid uuid, p_query_date date)
-RETURNS TABLE (
-  org_node_key char(8),
-  parent_org_node_key char(8),
-  name varchar(255),
-  is_business_unit boolean,
-  full_name_path text,
-  depth int,
-  manager_uuid uuid,
-  node_path ltree,
-  path_node_keys text[]
+CREATE OR REPLACE FUNCTION orgunit.rebuild_org_unit_versions_for_org_with_pending_event(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_pending_event_id bigint,
+  p_pending_event_uuid uuid,
+  p_pending_event_type text,
+  p_pending_effective_date date,
+  p_pending_payload jsonb,
+  p_pending_request_id text,
+  p_pending_initiator_uuid uuid,
+  p_pending_tx_time timestamptz,
+  p_pending_transaction_time timestamptz,
+  p_pending_created_at timestamptz
 )
+RETURNS void
 LANGUAGE plpgsql
 AS $$
+DECLARE
+  v_event record;
+  v_root_path ltree;
+  v_org_node_keys char(8)[];
+  v_root_org_node_key char(8);
+  v_has_create boolean;
 BEGIN
   PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
 
-  IF p_query_date IS NULL THEN
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+
+  IF p_pending_event_id IS NOT NULL THEN
+    IF p_pending_event_uuid IS NULL
+      OR p_pending_event_type IS NULL
+      OR p_pending_effective_date IS NULL
+      OR p_pending_request_id IS NULL
+      OR p_pending_initiator_uuid IS NULL
+      OR p_pending_tx_time IS NULL
+      OR p_pending_transaction_time IS NULL
+      OR p_pending_created_at IS NULL
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_INVALID_ARGUMENT',
+        DETAIL = 'pending event metadata is incomplete';
+    END IF;
+  END IF;
+
+  SELECT t.root_org_node_key INTO v_root_org_node_key
+  FROM orgunit.org_trees t
+  WHERE t.tenant_uuid = p_tenant_uuid;
+
+  SELECT EXISTS (
+    SELECT 1
+    FROM orgunit.org_events_effective_for_replay(
+      p_tenant_uuid,
+      p_org_node_key,
+      p_pending_event_id,
+      p_pending_event_uuid,
+      p_pending_event_type,
+      p_pending_effective_date,
+      p_pending_payload,
+      p_pending_request_id,
+      p_pending_initiator_uuid,
+      p_pending_tx_time,
+      p_pending_transaction_time,
+      p_pending_created_at
+    ) e
+    WHERE e.event_type = 'CREATE'
+  ) INTO v_has_create;
+
+  IF v_has_create AND v_root_org_node_key = p_org_node_key THEN
+    DELETE FROM orgunit.org_trees
+    WHERE tenant_uuid = p_tenant_uuid;
+  ELSIF v_root_org_node_key = p_org_node_key AND NOT v_has_create THEN
     RAISE EXCEPTION USING
       MESSAGE = 'ORG_INVALID_ARGUMENT',
-      DETAIL = 'query_date is required';
+      DETAIL = format('root org missing create event: org_node_key=%s', p_org_node_key);
   END IF;
 
-  RETURN QUERY
-  SELECT
-    v.org_node_key,
-    v.parent_org_node_key,
-    v.name,
-    v.is_business_unit,
-    v.full_name_path,
-    nlevel(v.node_path) - 1 AS depth,
-    v.manager_uuid,
-    v.node_path,
-    v.path_node_keys
+  DELETE FROM orgunit.org_unit_codes
+  WHERE tenant_uuid = p_tenant_uuid
+    AND org_node_key = p_org_node_key;
+
+  DELETE FROM orgunit.org_unit_versions
+  WHERE tenant_uuid = p_tenant_uuid
+    AND org_node_key = p_org_node_key;
+
+  FOR v_event IN
+    SELECT *
+    FROM orgunit.org_events_effective_for_replay(
+      p_tenant_uuid,
+      p_org_node_key,
+      p_pending_event_id,
+      p_pending_event_uuid,
+      p_pending_event_type,
+      p_pending_effective_date,
+      p_pending_payload,
+      p_pending_request_id,
+      p_pending_initiator_uuid,
+      p_pending_tx_time,
+      p_pending_transaction_time,
+      p_pending_created_at
+    )
+    ORDER BY effective_date, id
+  LOOP
+    PERFORM orgunit.apply_replayed_org_event(
+      p_tenant_uuid,
+      v_event.org_node_key,
+      v_event.id,
+      v_event.event_type,
+      v_event.effective_date,
+      v_event.payload
+    );
+  END LOOP;
+
+  SELECT v.node_path INTO v_root_path
   FROM orgunit.org_unit_versions v
   WHERE v.tenant_uuid = p_tenant_uuid
-    AND v.status = 'active'
-    AND v.validity @> p_query_date
-  ORDER BY v.node_path;
+    AND v.org_node_key = p_org_node_key
+  ORDER BY lower(v.validity) DESC
+  LIMIT 1;
+
+  IF v_root_path IS NULL THEN
+    RETURN;
+  END IF;
+
+  SELECT array_agg(DISTINCT v.org_node_key) INTO v_org_node_keys
+  FROM orgunit.org_unit_versions v
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.node_path <@ v_root_path;
+
+  PERFORM orgunit.assert_org_unit_validity(p_tenant_uuid, v_org_node_keys);
 END;
 $$;
 
--- end: modules/orgunit/infrastructure/persistence/schema/00027_orgunit_org_node_key_read.sql
-
--- begin: modules/orgunit/infrastructure/persistence/schema/00028_orgunit_org_node_key_submit_allocator.sql
--- DEV-PLAN-320 P3: submit_org_event org_node_key allocator overlay
-CREATE OR REPLACE FUNCTION orgunit.submit_org_event(
-  p_event_uuid uuid,
+-- Projects the tenant's org history into org_unit_versions and org_unit_codes as it was known at p_known_at:
+-- every effective event recorded by then is replayed in effective-date order through the same kernel steps
+-- a write uses. It rewrites the live projection, so callers run it in a transaction they roll back after
+-- reading. It holds the tenant write lock until then.
+CREATE OR REPLACE FUNCTION orgunit.replay_org_unit_versions_known_at(
   p_tenant_uuid uuid,
-  p_org_node_key char(8),
-  p_event_type text,
-  p_effective_date date,
-  p_payload jsonb,
-  p_request_id text,
-  p_initiator_uuid uuid
+  p_known_at timestamptz
 )
-RETURNS bigint
+RETURNS void
 LANGUAGE plpgsql
 AS $$
 DECLARE
-  v_lock_key text;
-  v_event_db_id bigint;
-  v_existing orgunit.org_events%ROWTYPE;
-  v_existing_request orgunit.org_events%ROWTYPE;
-  v_payload jsonb;
-  v_org_node_key char(8);
-  v_parent_org_node_key char(8);
-  v_new_parent_org_node_key char(8);
-  v_name text;
-  v_new_name text;
-  v_status text;
-  v_manager_uuid uuid;
-  v_is_business_unit boolean;
-  v_org_code text;
-  v_root_path ltree;
-  v_org_node_keys char(8)[];
-  v_before_snapshot jsonb;
-  v_after_snapshot jsonb;
+  v_event record;
 BEGIN
   PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
 
-  IF p_event_uuid IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'event_uuid is required';
-  END IF;
-  IF p_effective_date IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
-  END IF;
-  IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
-  END IF;
-  IF p_initiator_uuid IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
+  IF p_known_at IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'known_at is required';
   END IF;
 
-  IF p_event_type NOT IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT') THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_INVALID_ARGUMENT',
-      DETAIL = format('unsupported event_type: %s', p_event_type);
-  END IF;
+  PERFORM pg_advisory_xact_lock(hashtextextended(format('org:write-lock:%s', p_tenant_uuid), 0));
 
-  v_lock_key := format('org:write-lock:%s', p_tenant_uuid);
-  PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
+  DELETE FROM orgunit.org_trees
+  WHERE tenant_uuid = p_tenant_uuid;
 
-  v_payload := COALESCE(p_payload, '{}'::jsonb);
-  IF p_event_type = 'SET_BUSINESS_UNIT' THEN
-    IF NOT (v_payload ? 'is_business_unit') THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_INVALID_ARGUMENT',
-        DETAIL = 'is_business_unit is required';
-    END IF;
-    BEGIN
-      PERFORM (v_payload->>'is_business_unit')::boolean;
-    EXCEPTION
-      WHEN invalid_text_representation THEN
-        RAISE EXCEPTION USING
-          MESSAGE = 'ORG_INVALID_ARGUMENT',
-          DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
-    END;
-  END IF;
+  DELETE FROM orgunit.org_unit_codes
+  WHERE tenant_uuid = p_tenant_uuid;
 
-  IF p_event_type = 'CREATE' AND p_org_node_key IS NULL THEN
-    SELECT * INTO v_existing
-    FROM orgunit.org_events
-    WHERE event_uuid = p_event_uuid;
+  DELETE FROM orgunit.org_unit_versions
+  WHERE tenant_uuid = p_tenant_uuid;
 
-    IF FOUND THEN
-      IF v_existing.tenant_uuid <> p_tenant_uuid
-        OR v_existing.event_type <> p_event_type
-        OR v_existing.effective_date <> p_effective_date
-        OR v_existing.payload <> v_payload
-        OR v_existing.request_id <> p_request_id
-        OR v_existing.initiator_uuid <> p_initiator_uuid
-      THEN
-        RAISE EXCEPTION USING
-          MESSAGE = 'ORG_IDEMPOTENCY_REUSED',
-          DETAIL = format('event_uuid=%s existing_id=%s', p_event_uuid, v_existing.id);
-      END IF;
+  FOR v_event IN
+    SELECT *
+    FROM orgunit.org_events_effective_for_replay(p_tenant_uuid, NULL, p_known_at => p_known_at)
+    ORDER BY effective_date, id
+  LOOP
+    PERFORM orgunit.apply_replayed_org_event(
+      p_tenant_uuid,
+      v_event.org_node_key,
+      v_event.id,
+      v_event.event_type,
+      v_event.effective_date,
+      v_event.payload
+    );
+  END LOOP;
+END;
+$$;
 
-      RETURN v_existing.id;
-    END IF;
+ALTER FUNCTION orgunit.replay_org_unit_versions_known_at(uuid, timestamptz)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.replay_org_unit_versions_known_at(uuid, timestamptz)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.replay_org_unit_versions_known_at(uuid, timestamptz)
+  SET search_path = pg_catalog, orgunit, public;
 
-    v_org_node_key := orgunit.allocate_org_node_key(p_tenant_uuid);
-  ELSE
-    IF p_org_node_key IS NULL THEN
-      RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
-    END IF;
-    v_org_node_key := p_org_node_key;
-  END IF;
+-- end: modules/orgunit/infrastructure/persistence/schema/00035_orgunit_known_at_replay.sql
+
+-- begin: modules/orgunit/infrastructure/persistence/schema/00036_orgunit_composite_operations.sql
+-- Org unit MERGE/SPLIT: a composite operation is one request_id, one audit row and a set of ordinary
+-- step events (MOVE/DISABLE/CREATE) submitted in the same transaction. Lineage records which units were
+-- folded into a survivor or carved out of a source.
+CREATE TABLE IF NOT EXISTS orgunit.org_composite_operations (
+  id bigserial PRIMARY KEY,
+  operation_uuid uuid NOT NULL UNIQUE,
+  tenant_uuid uuid NOT NULL,
+  operation_type text NOT NULL,
+  effective_date date NOT NULL,
+  payload jsonb NOT NULL DEFAULT '{}'::jsonb,
+  request_id text NOT NULL,
+  initiator_uuid uuid NOT NULL,
+  event_uuids uuid[] NOT NULL DEFAULT '{}'::uuid[],
+  before_snapshot jsonb NOT NULL DEFAULT '{}'::jsonb,
+  after_snapshot jsonb NOT NULL DEFAULT '{}'::jsonb,
+  tx_time timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT org_composite_operations_type_check CHECK (operation_type IN ('MERGE','SPLIT')),
+  CONSTRAINT org_composite_operations_payload_is_object_check CHECK (jsonb_typeof(payload) = 'object'),
+  CONSTRAINT org_composite_operations_request_id_unique UNIQUE (tenant_uuid, request_id)
+);
+
+CREATE TABLE IF NOT EXISTS orgunit.org_unit_lineage (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL,
+  org_node_key char(8) NOT NULL,
+  relation text NOT NULL,
+  related_org_node_key char(8) NOT NULL,
+  effective_date date NOT NULL,
+  operation_uuid uuid NOT NULL,
+  CONSTRAINT org_unit_lineage_relation_check CHECK (relation IN ('merged_into','split_from')),
+  CONSTRAINT org_unit_lineage_org_node_key_format_check CHECK (
+    orgunit.is_valid_org_node_key(btrim(org_node_key::text))
+  ),
+  CONSTRAINT org_unit_lineage_related_org_node_key_format_check CHECK (
+    orgunit.is_valid_org_node_key(btrim(related_org_node_key::text))
+  ),
+  CONSTRAINT org_unit_lineage_operation_fkey
+    FOREIGN KEY (operation_uuid)
+    REFERENCES orgunit.org_composite_operations(operation_uuid)
+    ON DELETE CASCADE,
+  CONSTRAINT org_unit_lineage_unique UNIQUE (tenant_uuid, org_node_key, relation, related_org_node_key, operation_uuid)
+);
+
+CREATE INDEX IF NOT EXISTS org_unit_lineage_node_idx
+  ON orgunit.org_unit_lineage (tenant_uuid, org_node_key);
+CREATE INDEX IF NOT EXISTS org_unit_lineage_related_idx
+  ON orgunit.org_unit_lineage (tenant_uuid, related_org_node_key);
+
+ALTER TABLE orgunit.org_composite_operations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.org_composite_operations FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.org_composite_operations;
+CREATE POLICY tenant_isolation ON orgunit.org_composite_operations
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
+
+ALTER TABLE orgunit.org_unit_lineage ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.org_unit_lineage FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.org_unit_lineage;
+CREATE POLICY tenant_isolation ON orgunit.org_unit_lineage
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
 
-  SELECT * INTO v_existing_request
-  FROM orgunit.org_events
-  WHERE tenant_uuid = p_tenant_uuid
-    AND request_id = p_request_id
-  LIMIT 1;
+-- orgunit.composite_children lists the direct children of p_parent as of p_as_of, regardless of status.
+CREATE OR REPLACE FUNCTION orgunit.composite_children(
+  p_tenant_uuid uuid,
+  p_parent char(8),
+  p_as_of date
+)
+RETURNS char(8)[]
+LANGUAGE sql
+STABLE
+AS $$
+  SELECT COALESCE(array_agg(v.org_node_key ORDER BY v.org_node_key), ARRAY[]::char(8)[])
+  FROM orgunit.org_unit_versions v
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.parent_org_node_key = p_parent
+    AND v.validity @> p_as_of;
+$$;
 
-  IF FOUND THEN
-    -- Idempotency key is request_id: allow server-generated event_uuid to differ across retries.
-    IF v_existing_request.org_node_key <> v_org_node_key
-      OR v_existing_request.event_type <> p_event_type
-      OR v_existing_request.effective_date <> p_effective_date
-      OR v_existing_request.payload <> v_payload
-      OR v_existing_request.initiator_uuid <> p_initiator_uuid
-    THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
-        DETAIL = format('request_id=%s', p_request_id);
-    END IF;
+CREATE OR REPLACE FUNCTION orgunit.submit_org_composite(
+  p_operation_uuid uuid,
+  p_tenant_uuid uuid,
+  p_operation_type text,
+  p_effective_date date,
+  p_payload jsonb,
+  p_request_id text,
+  p_initiator_uuid uuid
+)
+RETURNS jsonb
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_lock_key text;
+  v_payload jsonb;
+  v_existing orgunit.org_composite_operations%ROWTYPE;
+  v_survivor char(8);
+  v_source char(8);
+  v_sources char(8)[];
+  v_parent char(8);
+  v_child char(8);
+  v_children char(8)[];
+  v_involved char(8)[] := ARRAY[]::char(8)[];
+  v_created char(8)[] := ARRAY[]::char(8)[];
+  v_distributed char(8)[] := ARRAY[]::char(8)[];
+  v_unit jsonb;
+  v_new_key char(8);
+  v_status text;
+  v_step int := 0;
+  v_event_uuid uuid;
+  v_event_uuids uuid[] := ARRAY[]::uuid[];
+  v_before jsonb := '{}'::jsonb;
+  v_after jsonb := '{}'::jsonb;
+  v_key char(8);
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
 
-    RETURN v_existing_request.id;
+  IF p_operation_uuid IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'operation_uuid is required';
+  END IF;
+  IF p_operation_type IS NULL OR p_operation_type NOT IN ('MERGE','SPLIT') THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = format('unsupported operation_type: %s', p_operation_type);
+  END IF;
+  IF p_effective_date IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
+  END IF;
+  IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
+  END IF;
+  IF p_initiator_uuid IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
   END IF;
 
-  v_before_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, v_org_node_key, p_effective_date);
+  v_lock_key := format('org:write-lock:%s', p_tenant_uuid);
+  PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
+
+  v_payload := COALESCE(p_payload, '{}'::jsonb);
 
   SELECT * INTO v_existing
-  FROM orgunit.org_events
-  WHERE event_uuid = p_event_uuid;
+  FROM orgunit.org_composite_operations
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id;
 
   IF FOUND THEN
-    IF v_existing.tenant_uuid <> p_tenant_uuid
-      OR v_existing.org_node_key <> v_org_node_key
-      OR v_existing.event_type <> p_event_type
+    IF v_existing.operation_type <> p_operation_type
       OR v_existing.effective_date <> p_effective_date
       OR v_existing.payload <> v_payload
-      OR v_existing.request_id <> p_request_id
       OR v_existing.initiator_uuid <> p_initiator_uuid
     THEN
       RAISE EXCEPTION USING
-        MESSAGE = 'ORG_IDEMPOTENCY_REUSED',
-        DETAIL = format('event_uuid=%s existing_id=%s', p_event_uuid, v_existing.id);
+        MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
+        DETAIL = format('request_id=%s', p_request_id);
     END IF;
 
-    RETURN v_existing.id;
+    RETURN jsonb_build_object(
+      'operation_uuid', v_existing.operation_uuid,
+      'operation_type', v_existing.operation_type,
+      'event_uuids', to_jsonb(v_existing.event_uuids),
+      'after_snapshot', v_existing.after_snapshot
+    );
   END IF;
 
-  SELECT nextval(pg_get_serial_sequence('orgunit.org_events', 'id')) INTO v_event_db_id;
+  IF p_operation_type = 'MERGE' THEN
+    v_survivor := NULLIF(btrim(v_payload->>'survivor_org_node_key'), '')::char(8);
+    SELECT COALESCE(array_agg(DISTINCT btrim(x)::char(8)), ARRAY[]::char(8)[]) INTO v_sources
+    FROM jsonb_array_elements_text(COALESCE(v_payload->'source_org_node_keys', '[]'::jsonb)) AS x;
 
-  IF p_event_type = 'CREATE' THEN
-    v_parent_org_node_key := NULLIF(v_payload->>'parent_org_node_key', '')::char(8);
-    v_name := NULLIF(btrim(v_payload->>'name'), '');
-    v_manager_uuid := NULLIF(v_payload->>'manager_uuid', '')::uuid;
-    v_org_code := NULLIF(v_payload->>'org_code', '');
-    v_status := NULLIF(btrim(v_payload->>'status'), '');
-    v_is_business_unit := NULL;
-    IF v_payload ? 'is_business_unit' THEN
-      BEGIN
-        v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
-      EXCEPTION
-        WHEN invalid_text_representation THEN
-          RAISE EXCEPTION USING
-            MESSAGE = 'ORG_INVALID_ARGUMENT',
-            DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
-      END;
+    IF v_survivor IS NULL OR cardinality(v_sources) = 0 THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'survivor and sources are required';
+    END IF;
+    IF v_survivor = ANY(v_sources) THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_MERGE_SURVIVOR_INVALID',
+        DETAIL = format('survivor=%s is also a source', v_survivor);
     END IF;
-    PERFORM orgunit.apply_create_logic(p_tenant_uuid, v_org_node_key, v_org_code, v_parent_org_node_key, p_effective_date, v_name, v_manager_uuid, v_is_business_unit, v_event_db_id, v_status);
-    SELECT v.node_path INTO v_root_path
-    FROM orgunit.org_unit_versions v
-    WHERE v.tenant_uuid = p_tenant_uuid
-      AND v.org_node_key = v_org_node_key
-      AND v.validity @> p_effective_date
-    ORDER BY lower(v.validity) DESC
-    LIMIT 1;
-    PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
-    v_org_node_keys := ARRAY[v_org_node_key];
-  ELSIF p_event_type = 'UPDATE' THEN
-    PERFORM orgunit.apply_update_logic(p_tenant_uuid, v_org_node_key, p_effective_date, v_payload, v_event_db_id);
 
-    IF (v_payload ? 'parent_org_node_key')
-      OR (v_payload ? 'new_parent_org_node_key')
-      OR (v_payload ? 'name')
-      OR (v_payload ? 'new_name')
-    THEN
-      SELECT v.node_path INTO v_root_path
+    v_involved := ARRAY[v_survivor] || v_sources;
+    FOREACH v_key IN ARRAY v_involved LOOP
+      SELECT v.status, v.parent_org_node_key INTO v_status, v_parent
       FROM orgunit.org_unit_versions v
       WHERE v.tenant_uuid = p_tenant_uuid
-        AND v.org_node_key = v_org_node_key
-        AND v.validity @> p_effective_date
-      ORDER BY lower(v.validity) DESC
-      LIMIT 1;
-
-      IF v_root_path IS NOT NULL THEN
-        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
+        AND v.org_node_key = v_key
+        AND v.validity @> p_effective_date;
+      IF NOT FOUND THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_NOT_FOUND_AS_OF',
+          DETAIL = format('org_node_key=%s as_of=%s', v_key, p_effective_date);
       END IF;
-    END IF;
+      IF v_status <> 'active' THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_COMPOSITE_TARGET_DISABLED',
+          DETAIL = format('org_node_key=%s as_of=%s', v_key, p_effective_date);
+      END IF;
+      IF v_key <> v_survivor AND v_parent IS NULL THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_ROOT_CANNOT_BE_MERGED',
+          DETAIL = format('org_node_key=%s', v_key);
+      END IF;
+    END LOOP;
 
-    IF (v_payload ? 'parent_org_node_key') OR (v_payload ? 'new_parent_org_node_key') THEN
-      SELECT v.node_path INTO v_root_path
+    -- Re-parenting a source's children under a survivor that sits inside that source's subtree would
+    -- create a cycle, so the survivor must live outside every source subtree.
+    IF EXISTS (
+      SELECT 1
       FROM orgunit.org_unit_versions v
       WHERE v.tenant_uuid = p_tenant_uuid
-        AND v.org_node_key = v_org_node_key
+        AND v.org_node_key = v_survivor
         AND v.validity @> p_effective_date
-      ORDER BY lower(v.validity) DESC
-      LIMIT 1;
+        AND v.path_node_keys && (SELECT array_agg(btrim(s::text)) FROM unnest(v_sources) AS s)
+    ) THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_MERGE_SURVIVOR_INVALID',
+        DETAIL = format('survivor=%s is inside a source subtree', v_survivor);
+    END IF;
 
-      SELECT array_agg(DISTINCT v.org_node_key) INTO v_org_node_keys
-      FROM orgunit.org_unit_versions v
-      WHERE v.tenant_uuid = p_tenant_uuid
-        AND v.node_path <@ v_root_path;
-    ELSE
-      v_org_node_keys := ARRAY[v_org_node_key];
+    FOREACH v_source IN ARRAY v_sources LOOP
+      v_involved := v_involved || orgunit.composite_children(p_tenant_uuid, v_source, p_effective_date);
+    END LOOP;
+    FOREACH v_key IN ARRAY v_involved LOOP
+      v_before := v_before || jsonb_build_object(btrim(v_key::text), orgunit.extract_orgunit_snapshot(p_tenant_uuid, v_key, p_effective_date));
+    END LOOP;
+
+    INSERT INTO orgunit.org_composite_operations (
+      operation_uuid, tenant_uuid, operation_type, effective_date, payload, request_id, initiator_uuid
+    )
+    VALUES (
+      p_operation_uuid, p_tenant_uuid, p_operation_type, p_effective_date, v_payload, p_request_id, p_initiator_uuid
+    );
+
+    FOREACH v_source IN ARRAY v_sources LOOP
+      v_children := orgunit.composite_children(p_tenant_uuid, v_source, p_effective_date);
+      FOREACH v_child IN ARRAY v_children LOOP
+        v_step := v_step + 1;
+        v_event_uuid := gen_random_uuid();
+        PERFORM orgunit.submit_org_event(
+          v_event_uuid, p_tenant_uuid, v_child, 'MOVE', p_effective_date,
+          jsonb_build_object('new_parent_org_node_key', btrim(v_survivor::text)),
+          format('%s#%s', p_request_id, v_step), p_initiator_uuid
+        );
+        v_event_uuids := v_event_uuids || v_event_uuid;
+      END LOOP;
+
+      v_step := v_step + 1;
+      v_event_uuid := gen_random_uuid();
+      PERFORM orgunit.submit_org_event(
+        v_event_uuid, p_tenant_uuid, v_source, 'DISABLE', p_effective_date,
+        '{}'::jsonb, format('%s#%s', p_request_id, v_step), p_initiator_uuid
+      );
+      v_event_uuids := v_event_uuids || v_event_uuid;
+
+      INSERT INTO orgunit.org_unit_lineage (tenant_uuid, org_node_key, relation, related_org_node_key, effective_date, operation_uuid)
+      VALUES (p_tenant_uuid, v_source, 'merged_into', v_survivor, p_effective_date, p_operation_uuid);
+    END LOOP;
+  ELSE
+    v_source := NULLIF(btrim(v_payload->>'source_org_node_key'), '')::char(8);
+    IF v_source IS NULL
+      OR jsonb_typeof(v_payload->'new_units') IS DISTINCT FROM 'array'
+      OR jsonb_array_length(v_payload->'new_units') = 0
+    THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'source and new_units are required';
     END IF;
-  ELSIF p_event_type = 'MOVE' THEN
-    v_new_parent_org_node_key := NULLIF(v_payload->>'new_parent_org_node_key', '')::char(8);
-    PERFORM orgunit.apply_move_logic(p_tenant_uuid, v_org_node_key, v_new_parent_org_node_key, p_effective_date, v_event_db_id);
-    SELECT v.node_path INTO v_root_path
-    FROM orgunit.org_unit_versions v
-    WHERE v.tenant_uuid = p_tenant_uuid
-      AND v.org_node_key = v_org_node_key
-      AND v.validity @> p_effective_date
-    ORDER BY lower(v.validity) DESC
-    LIMIT 1;
-    PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
-    SELECT array_agg(DISTINCT v.org_node_key) INTO v_org_node_keys
-    FROM orgunit.org_unit_versions v
-    WHERE v.tenant_uuid = p_tenant_uuid
-      AND v.node_path <@ v_root_path;
-  ELSIF p_event_type = 'RENAME' THEN
-    v_new_name := NULLIF(btrim(v_payload->>'new_name'), '');
-    PERFORM orgunit.apply_rename_logic(p_tenant_uuid, v_org_node_key, p_effective_date, v_new_name, v_event_db_id);
-    SELECT v.node_path INTO v_root_path
+
+    SELECT v.status, v.parent_org_node_key INTO v_status, v_parent
     FROM orgunit.org_unit_versions v
     WHERE v.tenant_uuid = p_tenant_uuid
-      AND v.org_node_key = v_org_node_key
-      AND v.validity @> p_effective_date
-    ORDER BY lower(v.validity) DESC
-    LIMIT 1;
-    PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
-    v_org_node_keys := ARRAY[v_org_node_key];
-  ELSIF p_event_type = 'DISABLE' THEN
-    PERFORM orgunit.apply_disable_logic(p_tenant_uuid, v_org_node_key, p_effective_date, v_event_db_id);
-    v_org_node_keys := ARRAY[v_org_node_key];
-  ELSIF p_event_type = 'ENABLE' THEN
-    PERFORM orgunit.apply_enable_logic(p_tenant_uuid, v_org_node_key, p_effective_date, v_event_db_id);
-    v_org_node_keys := ARRAY[v_org_node_key];
-  ELSIF p_event_type = 'SET_BUSINESS_UNIT' THEN
-    v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
-    PERFORM orgunit.apply_set_business_unit_logic(p_tenant_uuid, v_org_node_key, p_effective_date, v_is_business_unit, v_event_db_id);
-    v_org_node_keys := ARRAY[v_org_node_key];
-  END IF;
+      AND v.org_node_key = v_source
+      AND v.validity @> p_effective_date;
+    IF NOT FOUND THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_NOT_FOUND_AS_OF',
+        DETAIL = format('org_node_key=%s as_of=%s', v_source, p_effective_date);
+    END IF;
+    IF v_status <> 'active' THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_COMPOSITE_TARGET_DISABLED',
+        DETAIL = format('org_node_key=%s as_of=%s', v_source, p_effective_date);
+    END IF;
+    IF v_parent IS NULL THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_ROOT_CANNOT_BE_SPLIT',
+        DETAIL = format('org_node_key=%s', v_source);
+    END IF;
 
-  PERFORM orgunit.apply_org_event_ext_payload(
-    p_tenant_uuid,
-    v_org_node_key,
-    p_effective_date,
-    p_event_type,
-    v_payload,
-    v_event_db_id
-  );
+    v_children := orgunit.composite_children(p_tenant_uuid, v_source, p_effective_date);
+    FOR v_unit IN SELECT value FROM jsonb_array_elements(v_payload->'new_units') LOOP
+      FOR v_child IN
+        SELECT btrim(x)::char(8)
+        FROM jsonb_array_elements_text(COALESCE(v_unit->'child_org_node_keys', '[]'::jsonb)) AS x
+      LOOP
+        IF NOT (v_child = ANY(v_children)) THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_SPLIT_CHILD_NOT_UNDER_SOURCE',
+            DETAIL = format('child=%s source=%s', v_child, v_source);
+        END IF;
+        IF v_child = ANY(v_distributed) THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_INVALID_ARGUMENT',
+            DETAIL = format('child=%s is assigned twice', v_child);
+        END IF;
+        v_distributed := v_distributed || v_child;
+      END LOOP;
+    END LOOP;
+    IF COALESCE((v_payload->>'disable_source')::boolean, false)
+      AND EXISTS (SELECT 1 FROM unnest(v_children) AS c WHERE NOT (c = ANY(v_distributed)))
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_SPLIT_SOURCE_HAS_CHILDREN',
+        DETAIL = format('source=%s', v_source);
+    END IF;
 
-  PERFORM orgunit.assert_org_unit_validity(p_tenant_uuid, v_org_node_keys);
+    v_involved := ARRAY[v_source] || v_children;
+    FOREACH v_key IN ARRAY v_involved LOOP
+      v_before := v_before || jsonb_build_object(btrim(v_key::text), orgunit.extract_orgunit_snapshot(p_tenant_uuid, v_key, p_effective_date));
+    END LOOP;
 
-  v_after_snapshot := orgunit.extract_orgunit_snapshot(p_tenant_uuid, v_org_node_key, p_effective_date);
-  PERFORM orgunit.assert_org_event_snapshots(p_event_type, v_before_snapshot, v_after_snapshot, NULL);
+    INSERT INTO orgunit.org_composite_operations (
+      operation_uuid, tenant_uuid, operation_type, effective_date, payload, request_id, initiator_uuid
+    )
+    VALUES (
+      p_operation_uuid, p_tenant_uuid, p_operation_type, p_effective_date, v_payload, p_request_id, p_initiator_uuid
+    );
 
-  INSERT INTO orgunit.org_events (
-    id,
-    event_uuid,
-    tenant_uuid,
-    org_node_key,
-    event_type,
-    effective_date,
-    payload,
-    request_id,
-    initiator_uuid,
-    before_snapshot,
-    after_snapshot
-  )
-  VALUES (
-    v_event_db_id,
-    p_event_uuid,
-    p_tenant_uuid,
-    v_org_node_key,
-    p_event_type,
-    p_effective_date,
-    v_payload,
-    p_request_id,
-    p_initiator_uuid,
-    v_before_snapshot,
-    v_after_snapshot
-  );
+    FOR v_unit IN SELECT value FROM jsonb_array_elements(v_payload->'new_units') LOOP
+      v_step := v_step + 1;
+      v_event_uuid := gen_random_uuid();
+      PERFORM orgunit.submit_org_event(
+        v_event_uuid, p_tenant_uuid, NULL, 'CREATE', p_effective_date,
+        jsonb_strip_nulls(jsonb_build_object(
+          'parent_org_node_key', btrim(v_parent::text),
+          'org_code', v_unit->>'org_code',
+          'name', v_unit->>'name',
+          'is_business_unit', v_unit->'is_business_unit'
+        )),
+        format('%s#%s', p_request_id, v_step), p_initiator_uuid
+      );
+      v_event_uuids := v_event_uuids || v_event_uuid;
+
+      SELECT e.org_node_key INTO v_new_key
+      FROM orgunit.org_events e
+      WHERE e.event_uuid = v_event_uuid;
+      v_created := v_created || v_new_key;
+
+      INSERT INTO orgunit.org_unit_lineage (tenant_uuid, org_node_key, relation, related_org_node_key, effective_date, operation_uuid)
+      VALUES (p_tenant_uuid, v_new_key, 'split_from', v_source, p_effective_date, p_operation_uuid);
+
+      FOR v_child IN
+        SELECT btrim(x)::char(8)
+        FROM jsonb_array_elements_text(COALESCE(v_unit->'child_org_node_keys', '[]'::jsonb)) AS x
+      LOOP
+        v_step := v_step + 1;
+        v_event_uuid := gen_random_uuid();
+        PERFORM orgunit.submit_org_event(
+          v_event_uuid, p_tenant_uuid, v_child, 'MOVE', p_effective_date,
+          jsonb_build_object('new_parent_org_node_key', btrim(v_new_key::text)),
+          format('%s#%s', p_request_id, v_step), p_initiator_uuid
+        );
+        v_event_uuids := v_event_uuids || v_event_uuid;
+      END LOOP;
+    END LOOP;
+
+    IF COALESCE((v_payload->>'disable_source')::boolean, false) THEN
+      v_step := v_step + 1;
+      v_event_uuid := gen_random_uuid();
+      PERFORM orgunit.submit_org_event(
+        v_event_uuid, p_tenant_uuid, v_source, 'DISABLE', p_effective_date,
+        '{}'::jsonb, format('%s#%s', p_request_id, v_step), p_initiator_uuid
+      );
+      v_event_uuids := v_event_uuids || v_event_uuid;
+    END IF;
 
-  RETURN v_event_db_id;
+    v_involved := v_involved || v_created;
+  END IF;
+
+  FOREACH v_key IN ARRAY v_involved LOOP
+    v_after := v_after || jsonb_build_object(btrim(v_key::text), orgunit.extract_orgunit_snapshot(p_tenant_uuid, v_key, p_effective_date));
+  END LOOP;
+
+  UPDATE orgunit.org_composite_operations
+  SET event_uuids = v_event_uuids,
+      before_snapshot = v_before,
+      after_snapshot = v_after
+  WHERE operation_uuid = p_operation_uuid;
+
+  RETURN jsonb_build_object(
+    'operation_uuid', p_operation_uuid,
+    'operation_type', p_operation_type,
+    'event_uuids', to_jsonb(v_event_uuids),
+    'after_snapshot', v_after
+  );
 END;
 $$;
 
+ALTER TABLE IF EXISTS orgunit.org_composite_operations OWNER TO orgunit_kernel;
+ALTER TABLE IF EXISTS orgunit.org_unit_lineage OWNER TO orgunit_kernel;
 
-ALTER FUNCTION orgunit.submit_org_event(uuid, uuid, char(8), text, date, jsonb, text, uuid)
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE
+  orgunit.org_composite_operations,
+  orgunit.org_unit_lineage
+TO orgunit_kernel;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA orgunit TO orgunit_kernel;
+
+ALTER FUNCTION orgunit.composite_children(uuid, char(8), date)
   OWNER TO orgunit_kernel;
-ALTER FUNCTION orgunit.submit_org_event(uuid, uuid, char(8), text, date, jsonb, text, uuid)
+
+ALTER FUNCTION orgunit.submit_org_composite(uuid, uuid, text, date, jsonb, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.submit_org_composite(uuid, uuid, text, date, jsonb, text, uuid)
   SECURITY DEFINER;
-ALTER FUNCTION orgunit.submit_org_event(uuid, uuid, char(8), text, date, jsonb, text, uuid)
+ALTER FUNCTION orgunit.submit_org_composite(uuid, uuid, text, date, jsonb, text, uuid)
   SET search_path = pg_catalog, orgunit, public;
 
--- end: modules/orgunit/infrastructure/persistence/schema/00028_orgunit_org_node_key_submit_allocator.sql
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_nobypassrls') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.org_composite_operations, ' ||
+      'orgunit.org_unit_lineage ' ||
+      'FROM app_nobypassrls';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.org_composite_operations, ' ||
+      'orgunit.org_unit_lineage ' ||
+      'TO app_nobypassrls';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.org_composite_operations, ' ||
+      'orgunit.org_unit_lineage ' ||
+      'TO superadmin_runtime';
+  END IF;
+END $$;
 
--- begin: modules/orgunit/infrastructure/persistence/schema/00029_orgunit_org_node_key_engine_privileges.sql
--- DEV-PLAN-320 P3: org_node_key runtime function privileges
-GRANT SELECT ON TABLE orgunit.org_events_effective TO orgunit_kernel;
+-- Tenant hard delete also clears composite operations; lineage rows cascade with their operation.
+CREATE OR REPLACE FUNCTION orgunit.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'orgunit.org_unit_lineage',
+    'orgunit.org_composite_operations',
+    'orgunit.org_unit_versions',
+    'orgunit.org_unit_codes',
+    'orgunit.org_trees',
+    'orgunit.org_events',
+    'orgunit.org_node_key_registry',
+    'orgunit.tenant_field_policies',
+    'orgunit.tenant_field_policy_events',
+    'orgunit.tenant_field_configs',
+    'orgunit.tenant_field_config_events'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = orgunit.global_tenant_id() THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORGUNIT_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
 
-ALTER FUNCTION orgunit.submit_org_event_correction(uuid, char(8), date, jsonb, text, uuid)
-  OWNER TO orgunit_kernel;
-ALTER FUNCTION orgunit.submit_org_event_correction(uuid, char(8), date, jsonb, text, uuid)
-  SECURITY DEFINER;
-ALTER FUNCTION orgunit.submit_org_event_correction(uuid, char(8), date, jsonb, text, uuid)
-  SET search_path = pg_catalog, orgunit, public;
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
 
-ALTER FUNCTION orgunit.submit_org_status_correction(uuid, char(8), date, text, text, uuid)
-  OWNER TO orgunit_kernel;
-ALTER FUNCTION orgunit.submit_org_status_correction(uuid, char(8), date, text, text, uuid)
-  SECURITY DEFINER;
-ALTER FUNCTION orgunit.submit_org_status_correction(uuid, char(8), date, text, text, uuid)
-  SET search_path = pg_catalog, orgunit, public;
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
 
-ALTER FUNCTION orgunit.submit_org_event_rescind(uuid, char(8), date, text, text, uuid)
-  OWNER TO orgunit_kernel;
-ALTER FUNCTION orgunit.submit_org_event_rescind(uuid, char(8), date, text, text, uuid)
-  SECURITY DEFINER;
-ALTER FUNCTION orgunit.submit_org_event_rescind(uuid, char(8), date, text, text, uuid)
-  SET search_path = pg_catalog, orgunit, public;
+  RETURN v_counts;
+END;
+$$;
 
-ALTER FUNCTION orgunit.submit_org_rescind(uuid, char(8), text, text, uuid)
-  OWNER TO orgunit_kernel;
-ALTER FUNCTION orgunit.submit_org_rescind(uuid, char(8), text, text, uuid)
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
   SECURITY DEFINER;
-ALTER FUNCTION orgunit.submit_org_rescind(uuid, char(8), text, text, uuid)
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
   SET search_path = pg_catalog, orgunit, public;
 
--- end: modules/orgunit/infrastructure/persistence/schema/00029_orgunit_org_node_key_engine_privileges.sql
+-- end: modules/orgunit/infrastructure/persistence/schema/00036_orgunit_composite_operations.sql
 
--- begin: modules/orgunit/infrastructure/persistence/schema/00030_orgunit_field_configs_schema.sql
--- OrgUnit tenant field configs (metadata) + wide-table ext slots (Phase 1)
--- SSOT: docs/dev-plans/100a-org-metadata-wide-table-phase0-contract-freeze-readiness.md
--- SSOT: docs/dev-plans/100b-org-metadata-wide-table-phase1-schema-and-metadata-skeleton.md
+-- begin: modules/orgunit/infrastructure/persistence/schema/00037_orgunit_search_index.sql
+-- Org unit search index: one row per org_unit_versions row, so an as_of search is a validity lookup.
+-- Name, full name path, org_code and status follow the versions/codes tables through triggers inside the
+-- event transaction; pinyin columns are computed by the application (the database has no pinyin
+-- dictionary) and filled through orgunit.set_org_unit_search_pinyin. NULL pinyin means "not yet filled".
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
 
-CREATE TABLE IF NOT EXISTS orgunit.tenant_field_configs (
+CREATE TABLE IF NOT EXISTS orgunit.org_unit_search_index (
   tenant_uuid uuid NOT NULL,
-  field_key text NOT NULL,
-  physical_col text NOT NULL,
-  value_type text NOT NULL,
-  data_source_type text NOT NULL,
-  data_source_config jsonb NOT NULL DEFAULT '{}'::jsonb,
-  display_label text NULL,
-  enabled_on date NOT NULL,
-  disabled_on date NULL,
-  created_at timestamptz NOT NULL DEFAULT now(),
-  updated_at timestamptz NOT NULL DEFAULT now(),
-  disabled_at timestamptz NULL,
-  PRIMARY KEY (tenant_uuid, field_key),
-  -- field_key will be used as payload.ext key; keep it simple and safe.
-  CONSTRAINT tenant_field_configs_field_key_format_check CHECK (field_key ~ '^[a-z][a-z0-9_]{0,62}$'),
-  CONSTRAINT tenant_field_configs_value_type_check CHECK (value_type IN ('text','int','uuid','bool','date','numeric')),
-  CONSTRAINT tenant_field_configs_data_source_type_check CHECK (data_source_type IN ('PLAIN','DICT','ENTITY')),
-  CONSTRAINT tenant_field_configs_data_source_config_is_object_check CHECK (jsonb_typeof(data_source_config) = 'object'),
-  CONSTRAINT tenant_field_configs_display_label_check CHECK (
-    display_label IS NULL OR (display_label = btrim(display_label) AND display_label <> '')
-  ),
-  -- data_source_config shape (SSOT: DEV-PLAN-100A §4.3)
-  CONSTRAINT tenant_field_configs_plain_config_check CHECK (
-    data_source_type <> 'PLAIN' OR data_source_config = '{}'::jsonb
-  ),
-  CONSTRAINT tenant_field_configs_dict_config_check CHECK (
-    data_source_type <> 'DICT' OR (
-      value_type = 'text'
-      AND data_source_config ? 'dict_code'
-      AND jsonb_typeof(data_source_config->'dict_code') = 'string'
-      AND NULLIF(btrim(data_source_config->>'dict_code'), '') IS NOT NULL
-      -- forbid extra keys to avoid "hidden config" drift
-      AND data_source_config = jsonb_build_object('dict_code', data_source_config->'dict_code')
-    )
-  ),
-  CONSTRAINT tenant_field_configs_entity_config_check CHECK (
-    data_source_type <> 'ENTITY' OR (
-      data_source_config ? 'entity'
-      AND jsonb_typeof(data_source_config->'entity') = 'string'
-      AND NULLIF(btrim(data_source_config->>'entity'), '') IS NOT NULL
-      AND data_source_config ? 'id_kind'
-      AND jsonb_typeof(data_source_config->'id_kind') = 'string'
-      AND (data_source_config->>'id_kind') IN ('uuid','int')
-      AND (
-        ((data_source_config->>'id_kind') = 'uuid' AND value_type = 'uuid')
-        OR
-        ((data_source_config->>'id_kind') = 'int' AND value_type = 'int')
-      )
-      -- forbid extra keys to avoid "hidden config" drift
-      AND data_source_config = jsonb_build_object(
-        'entity', data_source_config->'entity',
-        'id_kind', data_source_config->'id_kind'
-      )
-    )
-  ),
-  -- physical_col is used for dynamic SQL allowlist; keep the format strict.
-  CONSTRAINT tenant_field_configs_physical_col_format_check CHECK (
-    physical_col ~ '^ext_(str|int|uuid|bool|date|num)_[0-9]{2}$'
-  ),
-  CONSTRAINT tenant_field_configs_physical_col_group_check CHECK (
-    (value_type = 'text' AND physical_col LIKE 'ext_str_%')
-    OR (value_type = 'int' AND physical_col LIKE 'ext_int_%')
-    OR (value_type = 'uuid' AND physical_col LIKE 'ext_uuid_%')
-    OR (value_type = 'bool' AND physical_col LIKE 'ext_bool_%')
-    OR (value_type = 'date' AND physical_col LIKE 'ext_date_%')
-    OR (value_type = 'numeric' AND physical_col LIKE 'ext_num_%')
-  ),
-  CONSTRAINT tenant_field_configs_disabled_on_check CHECK (disabled_on IS NULL OR disabled_on >= enabled_on),
-  CONSTRAINT tenant_field_configs_physical_col_unique UNIQUE (tenant_uuid, physical_col)
+  version_id bigint NOT NULL,
+  org_node_key char(8) NOT NULL,
+  validity daterange NOT NULL,
+  org_code text NOT NULL DEFAULT '',
+  name text NOT NULL,
+  full_name_path text NOT NULL DEFAULT '',
+  status text NOT NULL DEFAULT 'active',
+  name_pinyin text NULL,
+  name_initials text NULL,
+  PRIMARY KEY (tenant_uuid, version_id),
+  CONSTRAINT org_unit_search_index_org_node_key_format_check CHECK (
+    orgunit.is_valid_org_node_key(btrim(org_node_key::text))
+  )
 );
 
-CREATE TABLE IF NOT EXISTS orgunit.tenant_field_config_events (
-  id bigserial PRIMARY KEY,
-  event_uuid uuid NOT NULL,
-  tenant_uuid uuid NOT NULL,
-  event_type text NOT NULL,
-  field_key text NOT NULL,
-  payload jsonb NOT NULL DEFAULT '{}'::jsonb,
-  request_id text NOT NULL,
-  initiator_uuid uuid NOT NULL,
-  transaction_time timestamptz NOT NULL DEFAULT now(),
-  created_at timestamptz NOT NULL DEFAULT now(),
-  CONSTRAINT tenant_field_config_events_event_type_check CHECK (event_type IN ('ENABLE','DISABLE','REKEY')),
-  CONSTRAINT tenant_field_config_events_field_key_format_check CHECK (field_key ~ '^[a-z][a-z0-9_]{0,62}$'),
-  CONSTRAINT tenant_field_config_events_request_id_unique UNIQUE (tenant_uuid, request_id),
-  CONSTRAINT tenant_field_config_events_event_uuid_unique UNIQUE (event_uuid),
-  CONSTRAINT tenant_field_config_events_payload_is_object_check CHECK (jsonb_typeof(payload) = 'object')
-);
+CREATE INDEX IF NOT EXISTS org_unit_search_index_validity_gist
+  ON orgunit.org_unit_search_index
+  USING gist (tenant_uuid gist_uuid_ops, validity);
 
-CREATE INDEX IF NOT EXISTS tenant_field_config_events_tenant_time_idx
-  ON orgunit.tenant_field_config_events (tenant_uuid, transaction_time DESC, id DESC);
+CREATE INDEX IF NOT EXISTS org_unit_search_index_node_idx
+  ON orgunit.org_unit_search_index (tenant_uuid, org_node_key);
 
-ALTER TABLE orgunit.tenant_field_config_events ENABLE ROW LEVEL SECURITY;
-ALTER TABLE orgunit.tenant_field_config_events FORCE ROW LEVEL SECURITY;
-DROP POLICY IF EXISTS tenant_isolation ON orgunit.tenant_field_config_events;
-CREATE POLICY tenant_isolation ON orgunit.tenant_field_config_events
-USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
-WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
+CREATE INDEX IF NOT EXISTS org_unit_search_index_name_trgm
+  ON orgunit.org_unit_search_index
+  USING gin (lower(name) gin_trgm_ops);
 
-ALTER TABLE orgunit.tenant_field_configs ENABLE ROW LEVEL SECURITY;
-ALTER TABLE orgunit.tenant_field_configs FORCE ROW LEVEL SECURITY;
-DROP POLICY IF EXISTS tenant_isolation ON orgunit.tenant_field_configs;
-CREATE POLICY tenant_isolation ON orgunit.tenant_field_configs
+CREATE INDEX IF NOT EXISTS org_unit_search_index_path_trgm
+  ON orgunit.org_unit_search_index
+  USING gin (lower(full_name_path) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS org_unit_search_index_code_trgm
+  ON orgunit.org_unit_search_index
+  USING gin (lower(org_code) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS org_unit_search_index_pinyin_trgm
+  ON orgunit.org_unit_search_index
+  USING gin (name_pinyin gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS org_unit_search_index_initials_trgm
+  ON orgunit.org_unit_search_index
+  USING gin (name_initials gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS org_unit_search_index_pending_pinyin_idx
+  ON orgunit.org_unit_search_index (tenant_uuid)
+  WHERE name_pinyin IS NULL;
+
+ALTER TABLE orgunit.org_unit_search_index ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.org_unit_search_index FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.org_unit_search_index;
+CREATE POLICY tenant_isolation ON orgunit.org_unit_search_index
 USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
 WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
 
--- -------------------------------------------------------------------
--- DB guards (fail-closed): no direct DML; only orgunit_kernel can write.
--- -------------------------------------------------------------------
+-- orgunit.sync_org_unit_search_version mirrors one org_unit_versions row. Pinyin survives updates that
+-- keep the name and is cleared when the name changes.
+CREATE OR REPLACE FUNCTION orgunit.sync_org_unit_search_version()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    DELETE FROM orgunit.org_unit_search_index
+    WHERE tenant_uuid = OLD.tenant_uuid
+      AND version_id = OLD.id;
+    RETURN OLD;
+  END IF;
 
-CREATE OR REPLACE FUNCTION orgunit.guard_tenant_field_configs_write()
+  INSERT INTO orgunit.org_unit_search_index (
+    tenant_uuid,
+    version_id,
+    org_node_key,
+    validity,
+    org_code,
+    name,
+    full_name_path,
+    status
+  )
+  VALUES (
+    NEW.tenant_uuid,
+    NEW.id,
+    NEW.org_node_key,
+    NEW.validity,
+    COALESCE((
+      SELECT c.org_code
+      FROM orgunit.org_unit_codes c
+      WHERE c.tenant_uuid = NEW.tenant_uuid
+        AND c.org_node_key = NEW.org_node_key
+    ), ''),
+    NEW.name,
+    COALESCE(NEW.full_name_path, ''),
+    NEW.status
+  )
+  ON CONFLICT (tenant_uuid, version_id) DO UPDATE
+  SET org_node_key = EXCLUDED.org_node_key,
+      validity = EXCLUDED.validity,
+      org_code = EXCLUDED.org_code,
+      name = EXCLUDED.name,
+      full_name_path = EXCLUDED.full_name_path,
+      status = EXCLUDED.status,
+      name_pinyin = CASE WHEN orgunit.org_unit_search_index.name = EXCLUDED.name THEN orgunit.org_unit_search_index.name_pinyin END,
+      name_initials = CASE WHEN orgunit.org_unit_search_index.name = EXCLUDED.name THEN orgunit.org_unit_search_index.name_initials END;
+  RETURN NEW;
+END;
+$$;
+
+-- orgunit.sync_org_unit_search_code keeps org_code current; on CREATE the version row can land before
+-- its code row.
+CREATE OR REPLACE FUNCTION orgunit.sync_org_unit_search_code()
 RETURNS trigger
 LANGUAGE plpgsql
 AS $$
 BEGIN
-  IF current_user <> 'orgunit_kernel' THEN
+  UPDATE orgunit.org_unit_search_index
+  SET org_code = NEW.org_code
+  WHERE tenant_uuid = NEW.tenant_uuid
+    AND org_node_key = NEW.org_node_key
+    AND org_code IS DISTINCT FROM NEW.org_code;
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS org_unit_versions_search_index_sync ON orgunit.org_unit_versions;
+CREATE TRIGGER org_unit_versions_search_index_sync
+AFTER INSERT OR UPDATE OR DELETE ON orgunit.org_unit_versions
+FOR EACH ROW EXECUTE FUNCTION orgunit.sync_org_unit_search_version();
+
+DROP TRIGGER IF EXISTS org_unit_codes_search_index_sync ON orgunit.org_unit_codes;
+CREATE TRIGGER org_unit_codes_search_index_sync
+AFTER INSERT OR UPDATE OF org_code ON orgunit.org_unit_codes
+FOR EACH ROW EXECUTE FUNCTION orgunit.sync_org_unit_search_code();
+
+-- orgunit.set_org_unit_search_pinyin fills pinyin for index rows whose name still matches; it is the
+-- only write path into the index that the application role has. Returns the number of rows filled.
+CREATE OR REPLACE FUNCTION orgunit.set_org_unit_search_pinyin(
+  p_tenant_uuid uuid,
+  p_names text[],
+  p_pinyin text[],
+  p_initials text[]
+)
+RETURNS integer
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_count integer;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+  IF COALESCE(array_length(p_names, 1), 0) <> COALESCE(array_length(p_pinyin, 1), 0)
+    OR COALESCE(array_length(p_names, 1), 0) <> COALESCE(array_length(p_initials, 1), 0) THEN
     RAISE EXCEPTION USING
-      MESSAGE = 'ORGUNIT_FIELD_CONFIGS_WRITE_FORBIDDEN',
-      DETAIL = format('role=%s table=%s', current_user, TG_TABLE_NAME);
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = 'names, pinyin and initials must have the same length';
+  END IF;
+
+  UPDATE orgunit.org_unit_search_index i
+  SET name_pinyin = t.pinyin,
+      name_initials = t.initials
+  FROM unnest(p_names, p_pinyin, p_initials) AS t(name, pinyin, initials)
+  WHERE i.tenant_uuid = p_tenant_uuid
+    AND i.name = t.name
+    AND i.name_pinyin IS NULL;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  RETURN v_count;
+END;
+$$;
+
+INSERT INTO orgunit.org_unit_search_index (
+  tenant_uuid,
+  version_id,
+  org_node_key,
+  validity,
+  org_code,
+  name,
+  full_name_path,
+  status
+)
+SELECT
+  v.tenant_uuid,
+  v.id,
+  v.org_node_key,
+  v.validity,
+  COALESCE(c.org_code, ''),
+  v.name,
+  COALESCE(v.full_name_path, ''),
+  v.status
+FROM orgunit.org_unit_versions v
+LEFT JOIN orgunit.org_unit_codes c
+  ON c.tenant_uuid = v.tenant_uuid
+ AND c.org_node_key = v.org_node_key
+ON CONFLICT (tenant_uuid, version_id) DO NOTHING;
+
+ALTER TABLE IF EXISTS orgunit.org_unit_search_index OWNER TO orgunit_kernel;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE orgunit.org_unit_search_index TO orgunit_kernel;
+
+ALTER FUNCTION orgunit.sync_org_unit_search_version()
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.sync_org_unit_search_version()
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.sync_org_unit_search_version()
+  SET search_path = pg_catalog, orgunit, public;
+
+ALTER FUNCTION orgunit.sync_org_unit_search_code()
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.sync_org_unit_search_code()
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.sync_org_unit_search_code()
+  SET search_path = pg_catalog, orgunit, public;
+
+ALTER FUNCTION orgunit.set_org_unit_search_pinyin(uuid, text[], text[], text[])
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.set_org_unit_search_pinyin(uuid, text[], text[], text[])
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.set_org_unit_search_pinyin(uuid, text[], text[], text[])
+  SET search_path = pg_catalog, orgunit, public;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_nobypassrls') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE orgunit.org_unit_search_index FROM app_nobypassrls';
+    EXECUTE 'GRANT SELECT ON TABLE orgunit.org_unit_search_index TO app_nobypassrls';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT ON TABLE orgunit.org_unit_search_index TO superadmin_runtime';
+  END IF;
+END $$;
+
+-- Tenant hard delete also clears the search index.
+CREATE OR REPLACE FUNCTION orgunit.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'orgunit.org_unit_search_index',
+    'orgunit.org_unit_lineage',
+    'orgunit.org_composite_operations',
+    'orgunit.org_unit_versions',
+    'orgunit.org_unit_codes',
+    'orgunit.org_trees',
+    'orgunit.org_events',
+    'orgunit.org_node_key_registry',
+    'orgunit.tenant_field_policies',
+    'orgunit.tenant_field_policy_events',
+    'orgunit.tenant_field_configs',
+    'orgunit.tenant_field_config_events'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = orgunit.global_tenant_id() THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORGUNIT_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
   END IF;
 
-  IF TG_OP = 'DELETE' THEN
-    RETURN OLD;
-  END IF;
-  RETURN NEW;
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  RETURN v_counts;
 END;
 $$;
 
-DROP TRIGGER IF EXISTS guard_tenant_field_configs_write ON orgunit.tenant_field_configs;
-CREATE TRIGGER guard_tenant_field_configs_write
-BEFORE INSERT OR UPDATE OR DELETE ON orgunit.tenant_field_configs
-FOR EACH ROW EXECUTE FUNCTION orgunit.guard_tenant_field_configs_write();
-
-DROP TRIGGER IF EXISTS guard_tenant_field_config_events_write ON orgunit.tenant_field_config_events;
-CREATE TRIGGER guard_tenant_field_config_events_write
-BEFORE INSERT OR UPDATE OR DELETE ON orgunit.tenant_field_config_events
-FOR EACH ROW EXECUTE FUNCTION orgunit.guard_tenant_field_configs_write();
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SET search_path = pg_catalog, orgunit, public;
 
--- -------------------------------------------------------------------
--- Mapping immutability + disabled_on rules (defense-in-depth).
--- -------------------------------------------------------------------
+-- end: modules/orgunit/infrastructure/persistence/schema/00037_orgunit_search_index.sql
 
-CREATE OR REPLACE FUNCTION orgunit.assert_tenant_field_configs_update_allowed()
+-- begin: modules/orgunit/infrastructure/persistence/schema/00038_orgunit_webhook_outbox.sql
+-- Every committed org event is mirrored into iam.webhook_outbox by this trigger, so downstream systems get
+-- creates, moves, renames, status changes, corrections and rescinds without polling the audit API.
+CREATE OR REPLACE FUNCTION orgunit.enqueue_org_event_webhook()
 RETURNS trigger
 LANGUAGE plpgsql
 AS $$
 DECLARE
-  v_allow_rekey boolean := (current_setting('app.orgunit_field_config_allow_rekey', true) = 'on');
+  v_event_type text;
 BEGIN
-  IF TG_OP <> 'UPDATE' THEN
-    RETURN NEW;
-  END IF;
+  v_event_type := CASE NEW.event_type
+    WHEN 'CREATE' THEN 'orgunit.created'
+    WHEN 'UPDATE' THEN 'orgunit.updated'
+    WHEN 'MOVE' THEN 'orgunit.moved'
+    WHEN 'RENAME' THEN 'orgunit.renamed'
+    WHEN 'DISABLE' THEN 'orgunit.disabled'
+    WHEN 'ENABLE' THEN 'orgunit.enabled'
+    WHEN 'SET_BUSINESS_UNIT' THEN 'orgunit.business_unit_changed'
+    WHEN 'CORRECT_EVENT' THEN 'orgunit.corrected'
+    WHEN 'CORRECT_STATUS' THEN 'orgunit.corrected'
+    WHEN 'RESCIND_EVENT' THEN 'orgunit.rescinded'
+    WHEN 'RESCIND_ORG' THEN 'orgunit.rescinded'
+    ELSE 'orgunit.changed'
+  END;
 
-  -- Mapping is immutable after enable. Rekey requires explicit one-shot opt-in.
-  IF NEW.field_key <> OLD.field_key THEN
-    IF NOT v_allow_rekey
-      OR NEW.physical_col <> OLD.physical_col
-      OR NEW.value_type <> OLD.value_type
-      OR NEW.data_source_type <> OLD.data_source_type
-      OR NEW.data_source_config <> OLD.data_source_config
-      OR NEW.display_label IS DISTINCT FROM OLD.display_label
-      OR NEW.enabled_on <> OLD.enabled_on
-    THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_FIELD_CONFIG_MAPPING_IMMUTABLE',
-        DETAIL = format('field_key=%s', OLD.field_key);
-    END IF;
-  ELSIF NEW.physical_col <> OLD.physical_col
-    OR NEW.value_type <> OLD.value_type
-    OR NEW.data_source_type <> OLD.data_source_type
-    OR NEW.data_source_config <> OLD.data_source_config
-    OR NEW.display_label IS DISTINCT FROM OLD.display_label
-    OR NEW.enabled_on <> OLD.enabled_on
-  THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_FIELD_CONFIG_MAPPING_IMMUTABLE',
-      DETAIL = format('field_key=%s', OLD.field_key);
-  END IF;
+  PERFORM iam.enqueue_webhook_event(
+    NEW.tenant_uuid,
+    'orgunit',
+    NEW.event_uuid,
+    v_event_type,
+    jsonb_build_object(
+      'org_node_key', btrim(NEW.org_node_key::text),
+      'org_code', COALESCE(NEW.after_snapshot->>'org_code', NEW.before_snapshot->>'org_code', NEW.payload->>'org_code'),
+      'effective_date', NEW.effective_date,
+      'source_event_type', NEW.event_type,
+      'target_event_uuid', NEW.payload->>'target_event_uuid',
+      'request_id', NEW.request_id,
+      'before', NEW.before_snapshot,
+      'after', NEW.after_snapshot
+    )
+  );
+  RETURN NULL;
+END;
+$$;
 
-  -- disabled_on cannot be "unset".
-  IF OLD.disabled_on IS NOT NULL AND NEW.disabled_on IS NULL THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID',
-      DETAIL = format('field_key=%s', OLD.field_key);
-  END IF;
+ALTER FUNCTION orgunit.enqueue_org_event_webhook()
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.enqueue_org_event_webhook()
+  SET search_path = pg_catalog, orgunit, public;
 
-  -- When setting disabled_on for the first time, forbid backdating.
-  IF OLD.disabled_on IS NULL AND NEW.disabled_on IS NOT NULL THEN
-    IF NEW.disabled_on < current_date THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID',
-        DETAIL = format('field_key=%s disabled_on=%s', OLD.field_key, NEW.disabled_on);
-    END IF;
-    RETURN NEW;
-  END IF;
+GRANT EXECUTE ON FUNCTION iam.enqueue_webhook_event(uuid, text, uuid, text, jsonb) TO orgunit_kernel;
 
-  -- If disabled_on changes, it must be "not effective yet" and only postponed.
-  IF OLD.disabled_on IS NOT NULL AND NEW.disabled_on IS NOT NULL AND NEW.disabled_on <> OLD.disabled_on THEN
-    IF current_date >= OLD.disabled_on THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID',
-        DETAIL = format('field_key=%s disabled_on=%s', OLD.field_key, OLD.disabled_on);
-    END IF;
-    IF NEW.disabled_on <= OLD.disabled_on THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID',
-        DETAIL = format('field_key=%s old=%s new=%s', OLD.field_key, OLD.disabled_on, NEW.disabled_on);
-    END IF;
-  END IF;
+DROP TRIGGER IF EXISTS org_events_enqueue_webhook ON orgunit.org_events;
+CREATE TRIGGER org_events_enqueue_webhook
+AFTER INSERT ON orgunit.org_events
+FOR EACH ROW
+EXECUTE FUNCTION orgunit.enqueue_org_event_webhook();
 
-  RETURN NEW;
-END;
-$$;
+-- end: modules/orgunit/infrastructure/persistence/schema/00038_orgunit_webhook_outbox.sql
 
-DROP TRIGGER IF EXISTS tenant_field_configs_update_allowed ON orgunit.tenant_field_configs;
-CREATE TRIGGER tenant_field_configs_update_allowed
-BEFORE UPDATE ON orgunit.tenant_field_configs
-FOR EACH ROW EXECUTE FUNCTION orgunit.assert_tenant_field_configs_update_allowed();
+-- begin: modules/orgunit/infrastructure/persistence/schema/00039_orgunit_field_validation_rules.sql
+-- Tenant field validation rules: CEL predicates over the candidate org unit record, evaluated by the write
+-- service and the create/append/correct prechecks. Rules are effective-dated per (field, scope, rule_key)
+-- the same way tenant_field_policies are, and only orgunit_kernel may write them.
+CREATE TABLE IF NOT EXISTS orgunit.tenant_field_validation_rules (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL,
+  field_key text NOT NULL,
+  scope_type text NOT NULL,
+  scope_key text NOT NULL,
+  rule_key text NOT NULL,
+  rule_expr text NOT NULL,
+  message text NULL,
+  enabled_on date NOT NULL,
+  disabled_on date NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  disabled_at timestamptz NULL,
+  CONSTRAINT tenant_field_validation_rules_field_key_format_check CHECK (field_key ~ '^[a-z][a-z0-9_]{0,62}$'),
+  CONSTRAINT tenant_field_validation_rules_rule_key_format_check CHECK (rule_key ~ '^[a-z][a-z0-9_]{0,62}$'),
+  CONSTRAINT tenant_field_validation_rules_scope_type_check CHECK (scope_type IN ('GLOBAL','FORM')),
+  CONSTRAINT tenant_field_validation_rules_scope_key_check CHECK (
+    (scope_type = 'GLOBAL' AND scope_key = 'global')
+    OR
+    (scope_type = 'FORM' AND scope_key IN (
+      'orgunit.create_dialog',
+      'orgunit.details.add_version_dialog',
+      'orgunit.details.insert_version_dialog',
+      'orgunit.details.correct_dialog'
+    ))
+  ),
+  CONSTRAINT tenant_field_validation_rules_rule_expr_check CHECK (btrim(rule_expr) <> ''),
+  CONSTRAINT tenant_field_validation_rules_message_check CHECK (
+    message IS NULL OR (message = btrim(message) AND message <> '')
+  ),
+  CONSTRAINT tenant_field_validation_rules_disabled_on_check CHECK (disabled_on IS NULL OR disabled_on > enabled_on)
+);
 
--- -------------------------------------------------------------------
--- Wide-table ext slots.
--- -------------------------------------------------------------------
+CREATE INDEX IF NOT EXISTS tenant_field_validation_rules_tenant_scope_idx
+  ON orgunit.tenant_field_validation_rules (tenant_uuid, field_key, scope_type, scope_key, rule_key, enabled_on DESC);
 
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_01 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_02 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_03 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_04 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_05 text NULL;
+CREATE TABLE IF NOT EXISTS orgunit.tenant_field_validation_rule_events (
+  id bigserial PRIMARY KEY,
+  event_uuid uuid NOT NULL,
+  tenant_uuid uuid NOT NULL,
+  event_type text NOT NULL,
+  field_key text NOT NULL,
+  scope_type text NOT NULL,
+  scope_key text NOT NULL,
+  rule_key text NOT NULL,
+  payload jsonb NOT NULL DEFAULT '{}'::jsonb,
+  request_id text NOT NULL,
+  initiator_uuid uuid NOT NULL,
+  transaction_time timestamptz NOT NULL DEFAULT now(),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT tenant_field_validation_rule_events_event_type_check CHECK (event_type IN ('UPSERT','DISABLE')),
+  CONSTRAINT tenant_field_validation_rule_events_request_id_unique UNIQUE (tenant_uuid, request_id),
+  CONSTRAINT tenant_field_validation_rule_events_event_uuid_unique UNIQUE (event_uuid),
+  CONSTRAINT tenant_field_validation_rule_events_payload_is_object_check CHECK (jsonb_typeof(payload) = 'object')
+);
 
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_int_01 int NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_int_02 int NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_int_03 int NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_int_04 int NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_int_05 int NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_int_06 int NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_int_07 int NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_int_08 int NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_int_09 int NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_int_10 int NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_int_11 int NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_int_12 int NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_int_13 int NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_int_14 int NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_int_15 int NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_uuid_01 uuid NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_uuid_02 uuid NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_uuid_03 uuid NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_uuid_04 uuid NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_uuid_05 uuid NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_uuid_06 uuid NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_uuid_07 uuid NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_uuid_08 uuid NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_uuid_09 uuid NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_uuid_10 uuid NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_bool_01 boolean NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_date_01 date NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_bool_02 boolean NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_bool_03 boolean NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_bool_04 boolean NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_bool_05 boolean NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_bool_06 boolean NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_bool_07 boolean NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_bool_08 boolean NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_bool_09 boolean NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_bool_10 boolean NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_bool_11 boolean NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_bool_12 boolean NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_bool_13 boolean NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_bool_14 boolean NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_bool_15 boolean NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_date_02 date NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_date_03 date NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_date_04 date NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_date_05 date NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_date_06 date NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_date_07 date NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_date_08 date NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_date_09 date NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_date_10 date NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_date_11 date NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_date_12 date NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_date_13 date NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_date_14 date NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_date_15 date NULL;
+CREATE INDEX IF NOT EXISTS tenant_field_validation_rule_events_tenant_time_idx
+  ON orgunit.tenant_field_validation_rule_events (tenant_uuid, transaction_time DESC, id DESC);
+
+ALTER TABLE orgunit.tenant_field_validation_rules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.tenant_field_validation_rules FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.tenant_field_validation_rules;
+CREATE POLICY tenant_isolation ON orgunit.tenant_field_validation_rules
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
+
+ALTER TABLE orgunit.tenant_field_validation_rule_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.tenant_field_validation_rule_events FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.tenant_field_validation_rule_events;
+CREATE POLICY tenant_isolation ON orgunit.tenant_field_validation_rule_events
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
 
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_num_01 numeric NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_num_02 numeric NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_num_03 numeric NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_num_04 numeric NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_num_05 numeric NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_num_06 numeric NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_num_07 numeric NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_num_08 numeric NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_num_09 numeric NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_num_10 numeric NULL;
+DROP TRIGGER IF EXISTS guard_tenant_field_validation_rules_write ON orgunit.tenant_field_validation_rules;
+CREATE TRIGGER guard_tenant_field_validation_rules_write
+BEFORE INSERT OR UPDATE OR DELETE ON orgunit.tenant_field_validation_rules
+FOR EACH ROW EXECUTE FUNCTION orgunit.guard_tenant_field_policies_write();
 
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_06 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_07 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_08 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_09 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_10 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_11 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_12 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_13 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_14 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_15 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_16 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_17 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_18 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_19 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_20 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_21 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_22 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_23 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_24 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_25 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_26 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_27 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_28 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_29 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_30 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_31 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_32 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_33 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_34 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_35 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_36 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_37 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_38 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_39 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_40 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_41 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_42 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_43 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_44 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_45 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_46 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_47 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_48 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_49 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_50 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_51 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_52 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_53 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_54 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_55 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_56 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_57 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_58 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_59 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_60 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_61 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_62 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_63 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_64 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_65 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_66 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_67 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_68 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_69 text NULL;
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_str_70 text NULL;
+DROP TRIGGER IF EXISTS guard_tenant_field_validation_rule_events_write ON orgunit.tenant_field_validation_rule_events;
+CREATE TRIGGER guard_tenant_field_validation_rule_events_write
+BEFORE INSERT OR UPDATE OR DELETE ON orgunit.tenant_field_validation_rule_events
+FOR EACH ROW EXECUTE FUNCTION orgunit.guard_tenant_field_policies_write();
 
-ALTER TABLE orgunit.org_unit_versions
-  ADD COLUMN IF NOT EXISTS ext_labels_snapshot jsonb NOT NULL DEFAULT '{}'::jsonb;
+CREATE OR REPLACE FUNCTION orgunit.assert_tenant_field_validation_rules_non_overlapping()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_overlap_id bigint;
+BEGIN
+  SELECT r.id
+  INTO v_overlap_id
+  FROM orgunit.tenant_field_validation_rules r
+  WHERE r.tenant_uuid = NEW.tenant_uuid
+    AND r.field_key = NEW.field_key
+    AND r.scope_type = NEW.scope_type
+    AND r.scope_key = NEW.scope_key
+    AND r.rule_key = NEW.rule_key
+    AND r.id <> NEW.id
+    AND daterange(NEW.enabled_on, COALESCE(NEW.disabled_on, 'infinity'::date), '[)')
+      && daterange(r.enabled_on, COALESCE(r.disabled_on, 'infinity'::date), '[)')
+  LIMIT 1;
 
--- MVP: at least one DICT field requires list filter/sort (see DEV-PLAN-100A §9.1).
--- Since physical_col is assigned dynamically, we pre-index all ext_str slots.
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_01_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_01)
-  WHERE ext_str_01 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_02_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_02)
-  WHERE ext_str_02 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_03_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_03)
-  WHERE ext_str_03 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_04_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_04)
-  WHERE ext_str_04 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_05_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_05)
-  WHERE ext_str_05 IS NOT NULL;
+  IF v_overlap_id IS NOT NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'FIELD_POLICY_SCOPE_OVERLAP',
+      DETAIL = format(
+        'field_key=%s scope_type=%s scope_key=%s rule_key=%s overlap_id=%s',
+        NEW.field_key, NEW.scope_type, NEW.scope_key, NEW.rule_key, v_overlap_id
+      );
+  END IF;
 
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_06_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_06)
-  WHERE ext_str_06 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_07_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_07)
-  WHERE ext_str_07 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_08_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_08)
-  WHERE ext_str_08 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_09_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_09)
-  WHERE ext_str_09 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_10_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_10)
-  WHERE ext_str_10 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_11_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_11)
-  WHERE ext_str_11 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_12_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_12)
-  WHERE ext_str_12 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_13_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_13)
-  WHERE ext_str_13 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_14_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_14)
-  WHERE ext_str_14 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_15_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_15)
-  WHERE ext_str_15 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_16_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_16)
-  WHERE ext_str_16 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_17_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_17)
-  WHERE ext_str_17 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_18_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_18)
-  WHERE ext_str_18 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_19_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_19)
-  WHERE ext_str_19 IS NOT NULL;
-CREATE INDEX IF NOT EXISTS org_unit_versions_ext_str_20_idx
-  ON orgunit.org_unit_versions (tenant_uuid, ext_str_20)
-  WHERE ext_str_20 IS NOT NULL;
+  RETURN NEW;
+END;
+$$;
 
--- -------------------------------------------------------------------
--- Kernel write entrypoints (One Door for metadata writes).
--- -------------------------------------------------------------------
+DROP TRIGGER IF EXISTS tenant_field_validation_rules_non_overlapping ON orgunit.tenant_field_validation_rules;
+CREATE TRIGGER tenant_field_validation_rules_non_overlapping
+BEFORE INSERT OR UPDATE ON orgunit.tenant_field_validation_rules
+FOR EACH ROW EXECUTE FUNCTION orgunit.assert_tenant_field_validation_rules_non_overlapping();
 
-CREATE OR REPLACE FUNCTION orgunit.enable_tenant_field_config(
+CREATE OR REPLACE FUNCTION orgunit.upsert_tenant_field_validation_rule(
   p_tenant_uuid uuid,
   p_field_key text,
-  p_value_type text,
+  p_scope_type text,
+  p_scope_key text,
+  p_rule_key text,
+  p_rule_expr text,
+  p_message text,
   p_enabled_on date,
-  p_data_source_type text,
-  p_data_source_config jsonb,
-  p_display_label text,
   p_request_id text,
   p_initiator_uuid uuid
 )
-RETURNS void
+RETURNS bigint
 LANGUAGE plpgsql
-AS $$
-DECLARE
-  v_lock_key text;
-  v_existing orgunit.tenant_field_config_events%ROWTYPE;
-  v_config jsonb;
-  v_display_label text;
-  v_physical_col text;
-  v_candidate_cols text[];
-  v_col text;
-  v_payload jsonb;
+AS $$
+DECLARE
+  v_scope_type text := upper(btrim(p_scope_type));
+  v_scope_key text := btrim(p_scope_key);
+  v_rule_expr text := NULLIF(btrim(p_rule_expr), '');
+  v_message text := NULLIF(btrim(p_message), '');
+  v_event_type text;
+  v_rule_id bigint;
+  v_open_id bigint;
+  v_open_enabled_on date;
+  v_next_enabled_on date;
 BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
-
-  IF p_field_key IS NULL OR btrim(p_field_key) = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'field_key is required';
-  END IF;
-  IF p_field_key !~ '^[a-z][a-z0-9_]{0,62}$' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = format('field_key=%s', p_field_key);
-  END IF;
-  IF p_value_type IS NULL OR btrim(p_value_type) = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'value_type is required';
-  END IF;
-  IF p_enabled_on IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'enabled_on is required';
-  END IF;
-  IF p_data_source_type IS NULL OR btrim(p_data_source_type) = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'data_source_type is required';
+  IF v_scope_type NOT IN ('GLOBAL','FORM') THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_type invalid';
   END IF;
-  IF p_display_label IS NOT NULL AND btrim(p_display_label) = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'display_label invalid';
+  IF v_scope_type = 'GLOBAL' THEN
+    v_scope_key := 'global';
   END IF;
-  IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
+  IF v_scope_key = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_key required';
   END IF;
-  IF p_initiator_uuid IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
+  IF v_rule_expr IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'FIELD_POLICY_EXPR_INVALID', DETAIL = 'rule_expr required';
   END IF;
 
-  v_lock_key := format('org:field-config-lock:%s', p_tenant_uuid);
-  PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
-
-  v_config := COALESCE(p_data_source_config, '{}'::jsonb);
-  IF jsonb_typeof(v_config) <> 'object' THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_FIELD_CONFIG_INVALID_DATA_SOURCE_CONFIG',
-      DETAIL = 'data_source_config must be an object';
-  END IF;
-  v_display_label := NULLIF(btrim(p_display_label), '');
+  PERFORM pg_advisory_xact_lock(hashtextextended(
+    format('orgunit.field_validation_rule:%s:%s:%s:%s:%s', p_tenant_uuid, p_field_key, v_scope_type, v_scope_key, p_rule_key),
+    0
+  ));
 
-  SELECT * INTO v_existing
-  FROM orgunit.tenant_field_config_events
+  SELECT event_type, (payload->>'rule_id')::bigint
+  INTO v_event_type, v_rule_id
+  FROM orgunit.tenant_field_validation_rule_events
   WHERE tenant_uuid = p_tenant_uuid
     AND request_id = p_request_id
   LIMIT 1;
 
   IF FOUND THEN
-    IF v_existing.event_type <> 'ENABLE'
-      OR v_existing.field_key <> p_field_key
-      OR v_existing.initiator_uuid <> p_initiator_uuid
-      OR COALESCE(v_existing.payload->>'value_type', '') <> p_value_type
-      OR COALESCE(v_existing.payload->>'data_source_type', '') <> p_data_source_type
-      OR COALESCE(v_existing.payload->>'enabled_on', '') <> p_enabled_on::text
-      OR COALESCE(v_existing.payload->'data_source_config', '{}'::jsonb) <> v_config
-      OR COALESCE(v_existing.payload->>'display_label', '') <> COALESCE(v_display_label, '')
-      OR v_existing.payload->>'disabled_on' IS NOT NULL
-    THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
-        DETAIL = format('request_id=%s', p_request_id);
+    IF v_event_type <> 'UPSERT' THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_REQUEST_ID_CONFLICT', DETAIL = format('request_id=%s', p_request_id);
     END IF;
-
-    RETURN;
+    RETURN v_rule_id;
   END IF;
 
-  IF p_value_type NOT IN ('text','int','uuid','bool','date','numeric') THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = format('value_type=%s', p_value_type);
-  END IF;
-  IF p_data_source_type NOT IN ('PLAIN','DICT','ENTITY') THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = format('data_source_type=%s', p_data_source_type);
-  END IF;
+  SELECT id, enabled_on
+  INTO v_open_id, v_open_enabled_on
+  FROM orgunit.tenant_field_validation_rules
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_field_key
+    AND scope_type = v_scope_type
+    AND scope_key = v_scope_key
+    AND rule_key = p_rule_key
+    AND disabled_on IS NULL
+  ORDER BY enabled_on DESC
+  LIMIT 1
+  FOR UPDATE;
 
-  IF p_data_source_type = 'PLAIN' THEN
-    IF v_config <> '{}'::jsonb THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_FIELD_CONFIG_INVALID_DATA_SOURCE_CONFIG',
-        DETAIL = format('field_key=%s data_source_type=PLAIN requires {}', p_field_key);
-    END IF;
-  ELSIF p_data_source_type = 'DICT' THEN
-    IF p_value_type <> 'text'
-      OR NOT (v_config ? 'dict_code')
-      OR jsonb_typeof(v_config->'dict_code') <> 'string'
-      OR NULLIF(btrim(v_config->>'dict_code'), '') IS NULL
-      OR v_config <> jsonb_build_object('dict_code', v_config->'dict_code')
-    THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_FIELD_CONFIG_INVALID_DATA_SOURCE_CONFIG',
-        DETAIL = format('field_key=%s data_source_type=DICT', p_field_key);
-    END IF;
-  ELSIF p_data_source_type = 'ENTITY' THEN
-    IF NOT (v_config ? 'entity')
-      OR jsonb_typeof(v_config->'entity') <> 'string'
-      OR NULLIF(btrim(v_config->>'entity'), '') IS NULL
-      OR NOT (v_config ? 'id_kind')
-      OR jsonb_typeof(v_config->'id_kind') <> 'string'
-      OR (v_config->>'id_kind') NOT IN ('uuid','int')
-      OR (
-        ((v_config->>'id_kind') = 'uuid' AND p_value_type <> 'uuid')
-        OR
-        ((v_config->>'id_kind') = 'int' AND p_value_type <> 'int')
-      )
-      OR v_config <> jsonb_build_object(
-        'entity', v_config->'entity',
-        'id_kind', v_config->'id_kind'
-      )
-    THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_FIELD_CONFIG_INVALID_DATA_SOURCE_CONFIG',
-        DETAIL = format('field_key=%s data_source_type=ENTITY', p_field_key);
-    END IF;
+  IF v_open_id IS NOT NULL AND v_open_enabled_on < p_enabled_on THEN
+    UPDATE orgunit.tenant_field_validation_rules
+    SET disabled_on = p_enabled_on, disabled_at = now(), updated_at = now()
+    WHERE id = v_open_id;
   END IF;
 
-  IF EXISTS (
-    SELECT 1
-    FROM orgunit.tenant_field_configs
+  UPDATE orgunit.tenant_field_validation_rules
+  SET rule_expr = v_rule_expr,
+      message = v_message,
+      updated_at = now()
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_field_key
+    AND scope_type = v_scope_type
+    AND scope_key = v_scope_key
+    AND rule_key = p_rule_key
+    AND enabled_on = p_enabled_on
+  RETURNING id INTO v_rule_id;
+
+  IF v_rule_id IS NULL THEN
+    SELECT MIN(enabled_on)
+    INTO v_next_enabled_on
+    FROM orgunit.tenant_field_validation_rules
     WHERE tenant_uuid = p_tenant_uuid
       AND field_key = p_field_key
-  ) THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_FIELD_CONFIG_ALREADY_ENABLED',
-      DETAIL = format('field_key=%s', p_field_key);
+      AND scope_type = v_scope_type
+      AND scope_key = v_scope_key
+      AND rule_key = p_rule_key
+      AND enabled_on > p_enabled_on;
+
+    INSERT INTO orgunit.tenant_field_validation_rules (
+      tenant_uuid,
+      field_key,
+      scope_type,
+      scope_key,
+      rule_key,
+      rule_expr,
+      message,
+      enabled_on,
+      disabled_on
+    ) VALUES (
+      p_tenant_uuid,
+      p_field_key,
+      v_scope_type,
+      v_scope_key,
+      p_rule_key,
+      v_rule_expr,
+      v_message,
+      p_enabled_on,
+      v_next_enabled_on
+    )
+    RETURNING id INTO v_rule_id;
   END IF;
 
-  IF p_value_type = 'text' THEN
-    v_candidate_cols := ARRAY[
-      'ext_str_01','ext_str_02','ext_str_03','ext_str_04','ext_str_05',
-      'ext_str_06','ext_str_07','ext_str_08','ext_str_09','ext_str_10',
-      'ext_str_11','ext_str_12','ext_str_13','ext_str_14','ext_str_15',
-      'ext_str_16','ext_str_17','ext_str_18','ext_str_19','ext_str_20',
-      'ext_str_21','ext_str_22','ext_str_23','ext_str_24','ext_str_25',
-      'ext_str_26','ext_str_27','ext_str_28','ext_str_29','ext_str_30',
-      'ext_str_31','ext_str_32','ext_str_33','ext_str_34','ext_str_35',
-      'ext_str_36','ext_str_37','ext_str_38','ext_str_39','ext_str_40',
-      'ext_str_41','ext_str_42','ext_str_43','ext_str_44','ext_str_45',
-      'ext_str_46','ext_str_47','ext_str_48','ext_str_49','ext_str_50',
-      'ext_str_51','ext_str_52','ext_str_53','ext_str_54','ext_str_55',
-      'ext_str_56','ext_str_57','ext_str_58','ext_str_59','ext_str_60',
-      'ext_str_61','ext_str_62','ext_str_63','ext_str_64','ext_str_65',
-      'ext_str_66','ext_str_67','ext_str_68','ext_str_69','ext_str_70'
-    ];
-  ELSIF p_value_type = 'int' THEN
-    v_candidate_cols := ARRAY[
-      'ext_int_01','ext_int_02','ext_int_03','ext_int_04','ext_int_05',
-      'ext_int_06','ext_int_07','ext_int_08','ext_int_09','ext_int_10',
-      'ext_int_11','ext_int_12','ext_int_13','ext_int_14','ext_int_15'
-    ];
-  ELSIF p_value_type = 'uuid' THEN
-    v_candidate_cols := ARRAY[
-      'ext_uuid_01','ext_uuid_02','ext_uuid_03','ext_uuid_04','ext_uuid_05',
-      'ext_uuid_06','ext_uuid_07','ext_uuid_08','ext_uuid_09','ext_uuid_10'
-    ];
-  ELSIF p_value_type = 'bool' THEN
-    v_candidate_cols := ARRAY[
-      'ext_bool_01','ext_bool_02','ext_bool_03','ext_bool_04','ext_bool_05',
-      'ext_bool_06','ext_bool_07','ext_bool_08','ext_bool_09','ext_bool_10',
-      'ext_bool_11','ext_bool_12','ext_bool_13','ext_bool_14','ext_bool_15'
-    ];
-  ELSIF p_value_type = 'date' THEN
-    v_candidate_cols := ARRAY[
-      'ext_date_01','ext_date_02','ext_date_03','ext_date_04','ext_date_05',
-      'ext_date_06','ext_date_07','ext_date_08','ext_date_09','ext_date_10',
-      'ext_date_11','ext_date_12','ext_date_13','ext_date_14','ext_date_15'
-    ];
-  ELSIF p_value_type = 'numeric' THEN
-    v_candidate_cols := ARRAY[
-      'ext_num_01','ext_num_02','ext_num_03','ext_num_04','ext_num_05',
-      'ext_num_06','ext_num_07','ext_num_08','ext_num_09','ext_num_10'
-    ];
-  ELSE
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = format('value_type=%s', p_value_type);
+  INSERT INTO orgunit.tenant_field_validation_rule_events (
+    event_uuid,
+    tenant_uuid,
+    event_type,
+    field_key,
+    scope_type,
+    scope_key,
+    rule_key,
+    payload,
+    request_id,
+    initiator_uuid
+  ) VALUES (
+    gen_random_uuid(),
+    p_tenant_uuid,
+    'UPSERT',
+    p_field_key,
+    v_scope_type,
+    v_scope_key,
+    p_rule_key,
+    jsonb_build_object('rule_id', v_rule_id, 'rule_expr', v_rule_expr),
+    p_request_id,
+    p_initiator_uuid
+  );
+
+  RETURN v_rule_id;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.disable_tenant_field_validation_rule(
+  p_tenant_uuid uuid,
+  p_field_key text,
+  p_scope_type text,
+  p_scope_key text,
+  p_rule_key text,
+  p_disabled_on date,
+  p_request_id text,
+  p_initiator_uuid uuid
+)
+RETURNS bigint
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_scope_type text := upper(btrim(p_scope_type));
+  v_scope_key text := btrim(p_scope_key);
+  v_event_type text;
+  v_rule_id bigint;
+  v_enabled_on date;
+BEGIN
+  IF v_scope_type NOT IN ('GLOBAL','FORM') THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_type invalid';
   END IF;
+  IF v_scope_type = 'GLOBAL' THEN
+    v_scope_key := 'global';
+  END IF;
+  IF v_scope_key = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_key required';
+  END IF;
+
+  PERFORM pg_advisory_xact_lock(hashtextextended(
+    format('orgunit.field_validation_rule:%s:%s:%s:%s:%s', p_tenant_uuid, p_field_key, v_scope_type, v_scope_key, p_rule_key),
+    0
+  ));
 
-  v_physical_col := NULL;
-  FOREACH v_col IN ARRAY v_candidate_cols LOOP
-    IF NOT EXISTS (
-      SELECT 1
-      FROM orgunit.tenant_field_configs
-      WHERE tenant_uuid = p_tenant_uuid
-        AND physical_col = v_col
-    ) THEN
-      v_physical_col := v_col;
-      EXIT;
+  SELECT event_type, (payload->>'rule_id')::bigint
+  INTO v_event_type, v_rule_id
+  FROM orgunit.tenant_field_validation_rule_events
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id
+  LIMIT 1;
+
+  IF FOUND THEN
+    IF v_event_type <> 'DISABLE' THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_REQUEST_ID_CONFLICT', DETAIL = format('request_id=%s', p_request_id);
     END IF;
-  END LOOP;
+    RETURN v_rule_id;
+  END IF;
 
-  IF v_physical_col IS NULL THEN
+  SELECT id, enabled_on
+  INTO v_rule_id, v_enabled_on
+  FROM orgunit.tenant_field_validation_rules
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_field_key
+    AND scope_type = v_scope_type
+    AND scope_key = v_scope_key
+    AND rule_key = p_rule_key
+    AND enabled_on < p_disabled_on
+    AND p_disabled_on < COALESCE(disabled_on, 'infinity'::date)
+  ORDER BY enabled_on DESC
+  LIMIT 1
+  FOR UPDATE;
+
+  IF v_rule_id IS NULL THEN
     RAISE EXCEPTION USING
-      MESSAGE = 'ORG_FIELD_CONFIG_SLOT_EXHAUSTED',
-      DETAIL = format('field_key=%s value_type=%s', p_field_key, p_value_type);
+      MESSAGE = 'ORG_FIELD_VALIDATION_RULE_NOT_FOUND',
+      DETAIL = format('field_key=%s rule_key=%s', p_field_key, p_rule_key);
   END IF;
 
-  v_payload := jsonb_build_object(
-    'physical_col', v_physical_col,
-    'value_type', p_value_type,
-    'data_source_type', p_data_source_type,
-    'data_source_config', v_config,
-    'display_label', v_display_label,
-    'enabled_on', p_enabled_on::text,
-    'disabled_on', NULL
-  );
+  UPDATE orgunit.tenant_field_validation_rules
+  SET disabled_on = p_disabled_on, disabled_at = now(), updated_at = now()
+  WHERE id = v_rule_id;
 
-  INSERT INTO orgunit.tenant_field_config_events (
+  INSERT INTO orgunit.tenant_field_validation_rule_events (
     event_uuid,
     tenant_uuid,
     event_type,
     field_key,
+    scope_type,
+    scope_key,
+    rule_key,
     payload,
     request_id,
     initiator_uuid
-  )
-  VALUES (
+  ) VALUES (
     gen_random_uuid(),
     p_tenant_uuid,
-    'ENABLE',
+    'DISABLE',
     p_field_key,
-    v_payload,
+    v_scope_type,
+    v_scope_key,
+    p_rule_key,
+    jsonb_build_object('rule_id', v_rule_id, 'disabled_on', p_disabled_on),
     p_request_id,
     p_initiator_uuid
   );
 
-  INSERT INTO orgunit.tenant_field_configs (
-    tenant_uuid,
-    field_key,
-    physical_col,
-    value_type,
-    data_source_type,
-    data_source_config,
-    display_label,
-    enabled_on,
-    disabled_on,
-    created_at,
-    updated_at
-  )
-  VALUES (
-    p_tenant_uuid,
-    p_field_key,
-    v_physical_col,
-    p_value_type,
-    p_data_source_type,
-    v_config,
-    v_display_label,
-    p_enabled_on,
-    NULL,
-    now(),
-    now()
-  );
+  RETURN v_rule_id;
 END;
 $$;
 
-CREATE OR REPLACE FUNCTION orgunit.rename_jsonb_object_key_strict(
-  p_obj jsonb,
-  p_old_key text,
-  p_new_key text
-)
+ALTER TABLE IF EXISTS orgunit.tenant_field_validation_rules OWNER TO orgunit_kernel;
+ALTER TABLE IF EXISTS orgunit.tenant_field_validation_rule_events OWNER TO orgunit_kernel;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE
+  orgunit.tenant_field_validation_rules,
+  orgunit.tenant_field_validation_rule_events
+TO orgunit_kernel;
+
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA orgunit TO orgunit_kernel;
+
+ALTER FUNCTION orgunit.assert_tenant_field_validation_rules_non_overlapping()
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.assert_tenant_field_validation_rules_non_overlapping()
+  SET search_path = pg_catalog, orgunit, public;
+
+ALTER FUNCTION orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, text, date, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, text, date, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, text, date, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+ALTER FUNCTION orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, text, date, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, text, date, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, text, date, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.tenant_field_validation_rules, ' ||
+      'orgunit.tenant_field_validation_rule_events ' ||
+      'FROM app';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.tenant_field_validation_rules, ' ||
+      'orgunit.tenant_field_validation_rule_events ' ||
+      'TO app';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_runtime') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.tenant_field_validation_rules, ' ||
+      'orgunit.tenant_field_validation_rule_events ' ||
+      'FROM app_runtime';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.tenant_field_validation_rules, ' ||
+      'orgunit.tenant_field_validation_rule_events ' ||
+      'TO app_runtime';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_nobypassrls') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.tenant_field_validation_rules, ' ||
+      'orgunit.tenant_field_validation_rule_events ' ||
+      'FROM app_nobypassrls';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.tenant_field_validation_rules, ' ||
+      'orgunit.tenant_field_validation_rule_events ' ||
+      'TO app_nobypassrls';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.tenant_field_validation_rules, ' ||
+      'orgunit.tenant_field_validation_rule_events ' ||
+      'FROM superadmin_runtime';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.tenant_field_validation_rules, ' ||
+      'orgunit.tenant_field_validation_rule_events ' ||
+      'TO superadmin_runtime';
+  END IF;
+END $$;
+
+-- Tenant hard delete also clears validation rules.
+CREATE OR REPLACE FUNCTION orgunit.purge_tenant_data(p_tenant_uuid uuid)
 RETURNS jsonb
 LANGUAGE plpgsql
 AS $$
 DECLARE
-  v_obj jsonb := COALESCE(p_obj, '{}'::jsonb);
+  v_tables text[] := ARRAY[
+    'orgunit.org_unit_search_index',
+    'orgunit.org_unit_lineage',
+    'orgunit.org_composite_operations',
+    'orgunit.org_unit_versions',
+    'orgunit.org_unit_codes',
+    'orgunit.org_trees',
+    'orgunit.org_events',
+    'orgunit.org_node_key_registry',
+    'orgunit.tenant_field_validation_rules',
+    'orgunit.tenant_field_validation_rule_events',
+    'orgunit.tenant_field_policies',
+    'orgunit.tenant_field_policy_events',
+    'orgunit.tenant_field_configs',
+    'orgunit.tenant_field_config_events'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
 BEGIN
-  IF jsonb_typeof(v_obj) <> 'object' THEN
-    RETURN '{}'::jsonb;
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = orgunit.global_tenant_id() THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORGUNIT_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
   END IF;
-  IF v_obj ? p_old_key AND v_obj ? p_new_key THEN
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  RETURN v_counts;
+END;
+$$;
+
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+-- end: modules/orgunit/infrastructure/persistence/schema/00039_orgunit_field_validation_rules.sql
+
+-- begin: modules/orgunit/infrastructure/persistence/schema/00040_orgunit_superadmin_provisioning_grants.sql
+-- Tenant provisioning creates the root org unit through the regular write service on the superadmin pool.
+-- Writes still go through the kernel submit functions; superadmin_runtime only needs to read what the
+-- write service resolves before submitting (codes, versions, field metadata and the effective events).
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT USAGE ON SCHEMA orgunit TO superadmin_runtime';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.org_node_key_registry, ' ||
+      'orgunit.org_trees, ' ||
+      'orgunit.org_events, ' ||
+      'orgunit.org_events_effective, ' ||
+      'orgunit.org_unit_versions, ' ||
+      'orgunit.org_unit_codes, ' ||
+      'orgunit.tenant_field_configs, ' ||
+      'orgunit.tenant_field_policies ' ||
+      'TO superadmin_runtime';
+  END IF;
+END $$;
+
+-- end: modules/orgunit/infrastructure/persistence/schema/00040_orgunit_superadmin_provisioning_grants.sql
+
+-- begin: modules/orgunit/infrastructure/persistence/schema/00041_orgunit_tenant_purge_from_catalog.sql
+-- Tenant hard delete purges every orgunit table that carries a tenant_uuid column, found in the catalog
+-- the same way offboarding verification finds them, so a new tenant-scoped table is covered without
+-- redefining this function. Tables go before the tables they reference.
+CREATE OR REPLACE FUNCTION orgunit.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_pending oid[];
+  v_table oid;
+  v_name text;
+  v_progress boolean;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = orgunit.global_tenant_id() THEN
     RAISE EXCEPTION USING
-      MESSAGE = 'ORG_FIELD_CONFIG_REKEY_CONFLICT',
-      DETAIL = format('old_field_key=%s new_field_key=%s', p_old_key, p_new_key);
+      MESSAGE = 'ORGUNIT_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
   END IF;
-  IF v_obj ? p_old_key THEN
-    RETURN (v_obj - p_old_key) || jsonb_build_object(p_new_key, v_obj->p_old_key);
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  SELECT COALESCE(array_agg(c.oid ORDER BY c.relname), '{}'::oid[]) INTO v_pending
+  FROM pg_class c
+  JOIN pg_attribute a ON a.attrelid = c.oid AND a.attname = 'tenant_uuid' AND NOT a.attisdropped
+  WHERE c.relnamespace = 'orgunit'::regnamespace
+    AND c.relkind IN ('r', 'p')
+    AND NOT c.relispartition;
+
+  WHILE cardinality(v_pending) > 0 LOOP
+    v_progress := false;
+    FOREACH v_table IN ARRAY v_pending LOOP
+      CONTINUE WHEN EXISTS (
+        SELECT 1
+        FROM pg_constraint fk
+        WHERE fk.contype = 'f'
+          AND fk.confrelid = v_table
+          AND fk.conrelid <> v_table
+          AND fk.conrelid = ANY (v_pending)
+      );
+      SELECT format('orgunit.%I', relname) INTO v_name FROM pg_class WHERE oid = v_table;
+      EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_name) USING p_tenant_uuid;
+      GET DIAGNOSTICS v_count = ROW_COUNT;
+      v_counts := v_counts || jsonb_build_object(v_name, v_count);
+      v_pending := array_remove(v_pending, v_table);
+      v_progress := true;
+    END LOOP;
+    IF NOT v_progress THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORGUNIT_TENANT_PURGE_FK_CYCLE',
+        DETAIL = format('tables=%s', v_pending::regclass[]);
+    END IF;
+  END LOOP;
+
+  RETURN v_counts;
+END;
+$$;
+
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+-- end: modules/orgunit/infrastructure/persistence/schema/00041_orgunit_tenant_purge_from_catalog.sql
+
+-- begin: modules/orgunit/infrastructure/persistence/schema/00042_orgunit_field_validation_rule_form_key.sql
+-- Validation rules apply to every write, or to the one dialog named by form_key. form_key reuses the form
+-- keys of tenant field configs; '' means the rule is not tied to a form.
+ALTER TABLE orgunit.tenant_field_validation_rules DISABLE TRIGGER guard_tenant_field_validation_rules_write;
+ALTER TABLE orgunit.tenant_field_validation_rule_events DISABLE TRIGGER guard_tenant_field_validation_rule_events_write;
+
+ALTER TABLE orgunit.tenant_field_validation_rules ADD COLUMN IF NOT EXISTS form_key text NOT NULL DEFAULT '';
+ALTER TABLE orgunit.tenant_field_validation_rule_events ADD COLUMN IF NOT EXISTS form_key text NOT NULL DEFAULT '';
+UPDATE orgunit.tenant_field_validation_rules SET form_key = scope_key WHERE scope_type = 'FORM';
+UPDATE orgunit.tenant_field_validation_rule_events SET form_key = scope_key WHERE scope_type = 'FORM';
+
+ALTER TABLE orgunit.tenant_field_validation_rules ENABLE TRIGGER guard_tenant_field_validation_rules_write;
+ALTER TABLE orgunit.tenant_field_validation_rule_events ENABLE TRIGGER guard_tenant_field_validation_rule_events_write;
+
+DROP INDEX IF EXISTS orgunit.tenant_field_validation_rules_tenant_scope_idx;
+DROP FUNCTION IF EXISTS orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, text, date, text, uuid);
+DROP FUNCTION IF EXISTS orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, text, date, text, uuid);
+
+ALTER TABLE orgunit.tenant_field_validation_rules
+  DROP CONSTRAINT IF EXISTS tenant_field_validation_rules_scope_type_check,
+  DROP CONSTRAINT IF EXISTS tenant_field_validation_rules_scope_key_check,
+  DROP COLUMN IF EXISTS scope_type,
+  DROP COLUMN IF EXISTS scope_key,
+  ADD CONSTRAINT tenant_field_validation_rules_form_key_check CHECK (
+    form_key IN (
+      '',
+      'orgunit.create_dialog',
+      'orgunit.details.add_version_dialog',
+      'orgunit.details.insert_version_dialog',
+      'orgunit.details.correct_dialog'
+    )
+  );
+ALTER TABLE orgunit.tenant_field_validation_rule_events
+  DROP COLUMN IF EXISTS scope_type,
+  DROP COLUMN IF EXISTS scope_key;
+
+CREATE INDEX IF NOT EXISTS tenant_field_validation_rules_tenant_form_idx
+  ON orgunit.tenant_field_validation_rules (tenant_uuid, field_key, form_key, rule_key, enabled_on DESC);
+
+CREATE OR REPLACE FUNCTION orgunit.assert_tenant_field_validation_rules_non_overlapping()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_overlap_id bigint;
+BEGIN
+  SELECT r.id
+  INTO v_overlap_id
+  FROM orgunit.tenant_field_validation_rules r
+  WHERE r.tenant_uuid = NEW.tenant_uuid
+    AND r.field_key = NEW.field_key
+    AND r.form_key = NEW.form_key
+    AND r.rule_key = NEW.rule_key
+    AND r.id <> NEW.id
+    AND daterange(NEW.enabled_on, COALESCE(NEW.disabled_on, 'infinity'::date), '[)')
+      && daterange(r.enabled_on, COALESCE(r.disabled_on, 'infinity'::date), '[)')
+  LIMIT 1;
+
+  IF v_overlap_id IS NOT NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'FIELD_POLICY_SCOPE_OVERLAP',
+      DETAIL = format(
+        'field_key=%s form_key=%s rule_key=%s overlap_id=%s',
+        NEW.field_key, NEW.form_key, NEW.rule_key, v_overlap_id
+      );
   END IF;
-  RETURN v_obj;
+
+  RETURN NEW;
 END;
 $$;
 
-CREATE OR REPLACE FUNCTION orgunit.rekey_tenant_field_config(
+CREATE OR REPLACE FUNCTION orgunit.upsert_tenant_field_validation_rule(
   p_tenant_uuid uuid,
-  p_old_field_key text,
-  p_new_field_key text,
+  p_field_key text,
+  p_form_key text,
+  p_rule_key text,
+  p_rule_expr text,
+  p_message text,
+  p_enabled_on date,
   p_request_id text,
   p_initiator_uuid uuid
 )
-RETURNS void
+RETURNS bigint
 LANGUAGE plpgsql
 AS $$
 DECLARE
-  v_lock_key text;
-  v_existing orgunit.tenant_field_config_events%ROWTYPE;
-  v_cfg orgunit.tenant_field_configs%ROWTYPE;
-  v_dict_code text;
-  v_payload jsonb;
-  v_updated_events int := 0;
-  v_updated_versions int := 0;
+  v_form_key text := btrim(COALESCE(p_form_key, ''));
+  v_rule_expr text := NULLIF(btrim(p_rule_expr), '');
+  v_message text := NULLIF(btrim(p_message), '');
+  v_event_type text;
+  v_rule_id bigint;
+  v_open_id bigint;
+  v_open_enabled_on date;
+  v_next_enabled_on date;
 BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
-
-  p_old_field_key := lower(btrim(p_old_field_key));
-  p_new_field_key := lower(btrim(p_new_field_key));
-
-  IF p_old_field_key IS NULL OR p_old_field_key = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'old_field_key is required';
-  END IF;
-  IF p_new_field_key IS NULL OR p_new_field_key = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'new_field_key is required';
-  END IF;
-  IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
-  END IF;
-  IF p_initiator_uuid IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
-  END IF;
-  IF p_old_field_key !~ '^[a-z][a-z0-9_]{0,62}$' OR p_new_field_key !~ '^[a-z][a-z0-9_]{0,62}$' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'field_key invalid';
+  IF v_form_key NOT IN (
+    '',
+    'orgunit.create_dialog',
+    'orgunit.details.add_version_dialog',
+    'orgunit.details.insert_version_dialog',
+    'orgunit.details.correct_dialog'
+  ) THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'form_key invalid';
   END IF;
-  IF p_old_field_key = p_new_field_key THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'old_field_key/new_field_key must differ';
+  IF v_rule_expr IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'FIELD_POLICY_EXPR_INVALID', DETAIL = 'rule_expr required';
   END IF;
 
-  v_lock_key := format('org:field-config-lock:%s', p_tenant_uuid);
-  PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
+  PERFORM pg_advisory_xact_lock(hashtextextended(
+    format('orgunit.field_validation_rule:%s:%s:%s:%s', p_tenant_uuid, p_field_key, v_form_key, p_rule_key),
+    0
+  ));
 
-  SELECT * INTO v_existing
-  FROM orgunit.tenant_field_config_events
+  SELECT event_type, (payload->>'rule_id')::bigint
+  INTO v_event_type, v_rule_id
+  FROM orgunit.tenant_field_validation_rule_events
   WHERE tenant_uuid = p_tenant_uuid
     AND request_id = p_request_id
   LIMIT 1;
 
   IF FOUND THEN
-    IF v_existing.event_type <> 'REKEY'
-      OR COALESCE(v_existing.payload->>'old_field_key', '') <> p_old_field_key
-      OR COALESCE(v_existing.payload->>'new_field_key', '') <> p_new_field_key
-      OR v_existing.initiator_uuid <> p_initiator_uuid
-    THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
-        DETAIL = format('request_id=%s', p_request_id);
+    IF v_event_type <> 'UPSERT' THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_REQUEST_ID_CONFLICT', DETAIL = format('request_id=%s', p_request_id);
     END IF;
-    RETURN;
+    RETURN v_rule_id;
   END IF;
 
-  SELECT * INTO v_cfg
-  FROM orgunit.tenant_field_configs
+  SELECT id, enabled_on
+  INTO v_open_id, v_open_enabled_on
+  FROM orgunit.tenant_field_validation_rules
   WHERE tenant_uuid = p_tenant_uuid
-    AND field_key = p_old_field_key
-  LIMIT 1;
-
-  IF NOT FOUND THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_FIELD_CONFIG_NOT_FOUND',
-      DETAIL = format('field_key=%s', p_old_field_key);
-  END IF;
-
-  IF v_cfg.data_source_type <> 'DICT' OR v_cfg.value_type <> 'text' THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_FIELD_CONFIG_INVALID_DATA_SOURCE_CONFIG',
-      DETAIL = format('field_key=%s', p_old_field_key);
-  END IF;
-
-  v_dict_code := NULLIF(btrim(v_cfg.data_source_config->>'dict_code'), '');
-  IF v_dict_code IS NULL THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_FIELD_CONFIG_INVALID_DATA_SOURCE_CONFIG',
-      DETAIL = format('field_key=%s missing_dict_code', p_old_field_key);
-  END IF;
-  IF p_new_field_key <> ('d_' || v_dict_code) THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_FIELD_CONFIG_INVALID_DATA_SOURCE_CONFIG',
-      DETAIL = format('field_key=%s expected_new_field_key=%s', p_old_field_key, 'd_' || v_dict_code);
-  END IF;
-
-  IF EXISTS (
-    SELECT 1
-    FROM orgunit.tenant_field_configs
-    WHERE tenant_uuid = p_tenant_uuid
-      AND field_key = p_new_field_key
-  ) THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_FIELD_CONFIG_ALREADY_ENABLED',
-      DETAIL = format('field_key=%s', p_new_field_key);
-  END IF;
+    AND field_key = p_field_key
+    AND form_key = v_form_key
+    AND rule_key = p_rule_key
+    AND disabled_on IS NULL
+  ORDER BY enabled_on DESC
+  LIMIT 1
+  FOR UPDATE;
 
-  IF EXISTS (
-    SELECT 1
-    FROM orgunit.org_events e
-    WHERE e.tenant_uuid = p_tenant_uuid
-      AND jsonb_typeof(e.payload->'ext') = 'object'
-      AND (e.payload->'ext') ? p_old_field_key
-      AND (e.payload->'ext') ? p_new_field_key
-    LIMIT 1
-  ) OR EXISTS (
-    SELECT 1
-    FROM orgunit.org_events e
-    WHERE e.tenant_uuid = p_tenant_uuid
-      AND jsonb_typeof(e.payload->'ext_labels_snapshot') = 'object'
-      AND (e.payload->'ext_labels_snapshot') ? p_old_field_key
-      AND (e.payload->'ext_labels_snapshot') ? p_new_field_key
-    LIMIT 1
-  ) OR EXISTS (
-    SELECT 1
-    FROM orgunit.org_unit_versions v
-    WHERE v.tenant_uuid = p_tenant_uuid
-      AND v.ext_labels_snapshot ? p_old_field_key
-      AND v.ext_labels_snapshot ? p_new_field_key
-    LIMIT 1
-  ) THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_FIELD_CONFIG_REKEY_CONFLICT',
-      DETAIL = format('old_field_key=%s new_field_key=%s', p_old_field_key, p_new_field_key);
+  IF v_open_id IS NOT NULL AND v_open_enabled_on < p_enabled_on THEN
+    UPDATE orgunit.tenant_field_validation_rules
+    SET disabled_on = p_enabled_on, disabled_at = now(), updated_at = now()
+    WHERE id = v_open_id;
   END IF;
 
-  PERFORM set_config('app.orgunit_field_config_allow_rekey', 'on', true);
-  UPDATE orgunit.tenant_field_configs
-  SET field_key = p_new_field_key,
+  UPDATE orgunit.tenant_field_validation_rules
+  SET rule_expr = v_rule_expr,
+      message = v_message,
       updated_at = now()
   WHERE tenant_uuid = p_tenant_uuid
-    AND field_key = p_old_field_key;
+    AND field_key = p_field_key
+    AND form_key = v_form_key
+    AND rule_key = p_rule_key
+    AND enabled_on = p_enabled_on
+  RETURNING id INTO v_rule_id;
 
-  WITH rewritten AS (
-    SELECT
-      id,
-      payload,
-      CASE
-        WHEN jsonb_typeof(payload->'ext') = 'object' THEN
-          orgunit.rename_jsonb_object_key_strict(payload->'ext', p_old_field_key, p_new_field_key)
-        ELSE NULL
-      END AS new_ext,
-      CASE
-        WHEN jsonb_typeof(payload->'ext_labels_snapshot') = 'object' THEN
-          orgunit.rename_jsonb_object_key_strict(payload->'ext_labels_snapshot', p_old_field_key, p_new_field_key)
-        ELSE NULL
-      END AS new_labels
-    FROM orgunit.org_events
+  IF v_rule_id IS NULL THEN
+    SELECT MIN(enabled_on)
+    INTO v_next_enabled_on
+    FROM orgunit.tenant_field_validation_rules
     WHERE tenant_uuid = p_tenant_uuid
-  ), updates AS (
-    UPDATE orgunit.org_events e
-    SET payload = (
-      (r.payload - 'ext' - 'ext_labels_snapshot')
-      || CASE WHEN r.new_ext IS NULL THEN '{}'::jsonb ELSE jsonb_build_object('ext', r.new_ext) END
-      || CASE WHEN r.new_labels IS NULL THEN '{}'::jsonb ELSE jsonb_build_object('ext_labels_snapshot', r.new_labels) END
-    )
-    FROM rewritten r
-    WHERE e.id = r.id
-      AND e.tenant_uuid = p_tenant_uuid
-      AND (
-        (r.new_ext IS NOT NULL AND r.new_ext IS DISTINCT FROM COALESCE(r.payload->'ext', '{}'::jsonb))
-        OR
-        (r.new_labels IS NOT NULL AND r.new_labels IS DISTINCT FROM COALESCE(r.payload->'ext_labels_snapshot', '{}'::jsonb))
-      )
-    RETURNING 1
-  )
-  SELECT count(*) INTO v_updated_events FROM updates;
-
-  UPDATE orgunit.org_unit_versions v
-  SET ext_labels_snapshot = orgunit.rename_jsonb_object_key_strict(v.ext_labels_snapshot, p_old_field_key, p_new_field_key)
-  WHERE v.tenant_uuid = p_tenant_uuid
-    AND jsonb_typeof(v.ext_labels_snapshot) = 'object'
-    AND v.ext_labels_snapshot ? p_old_field_key;
-  GET DIAGNOSTICS v_updated_versions = ROW_COUNT;
+      AND field_key = p_field_key
+      AND form_key = v_form_key
+      AND rule_key = p_rule_key
+      AND enabled_on > p_enabled_on;
 
-  v_payload := jsonb_build_object(
-    'old_field_key', p_old_field_key,
-    'new_field_key', p_new_field_key,
-    'dict_code', v_dict_code,
-    'physical_col', v_cfg.physical_col,
-    'enabled_on', v_cfg.enabled_on::text,
-    'display_label', v_cfg.display_label,
-    'updated_org_events', v_updated_events,
-    'updated_org_unit_versions', v_updated_versions
-  );
+    INSERT INTO orgunit.tenant_field_validation_rules (
+      tenant_uuid,
+      field_key,
+      form_key,
+      rule_key,
+      rule_expr,
+      message,
+      enabled_on,
+      disabled_on
+    ) VALUES (
+      p_tenant_uuid,
+      p_field_key,
+      v_form_key,
+      p_rule_key,
+      v_rule_expr,
+      v_message,
+      p_enabled_on,
+      v_next_enabled_on
+    )
+    RETURNING id INTO v_rule_id;
+  END IF;
 
-  INSERT INTO orgunit.tenant_field_config_events (
+  INSERT INTO orgunit.tenant_field_validation_rule_events (
     event_uuid,
     tenant_uuid,
     event_type,
     field_key,
+    form_key,
+    rule_key,
     payload,
     request_id,
     initiator_uuid
-  )
-  VALUES (
+  ) VALUES (
     gen_random_uuid(),
     p_tenant_uuid,
-    'REKEY',
-    p_new_field_key,
-    v_payload,
+    'UPSERT',
+    p_field_key,
+    v_form_key,
+    p_rule_key,
+    jsonb_build_object('rule_id', v_rule_id, 'rule_expr', v_rule_expr),
     p_request_id,
     p_initiator_uuid
   );
+
+  RETURN v_rule_id;
 END;
 $$;
 
-CREATE OR REPLACE FUNCTION orgunit.disable_tenant_field_config(
+CREATE OR REPLACE FUNCTION orgunit.disable_tenant_field_validation_rule(
   p_tenant_uuid uuid,
   p_field_key text,
+  p_form_key text,
+  p_rule_key text,
   p_disabled_on date,
   p_request_id text,
   p_initiator_uuid uuid
 )
-RETURNS void
+RETURNS bigint
 LANGUAGE plpgsql
 AS $$
 DECLARE
-  v_lock_key text;
-  v_existing orgunit.tenant_field_config_events%ROWTYPE;
-  v_cfg orgunit.tenant_field_configs%ROWTYPE;
-  v_payload jsonb;
+  v_form_key text := btrim(COALESCE(p_form_key, ''));
+  v_event_type text;
+  v_rule_id bigint;
+  v_enabled_on date;
 BEGIN
-  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
-
-  IF p_field_key IS NULL OR btrim(p_field_key) = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'field_key is required';
-  END IF;
-  IF p_field_key !~ '^[a-z][a-z0-9_]{0,62}$' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = format('field_key=%s', p_field_key);
-  END IF;
-  IF p_disabled_on IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'disabled_on is required';
-  END IF;
-  IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
-  END IF;
-  IF p_initiator_uuid IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
-  END IF;
-
-  v_lock_key := format('org:field-config-lock:%s', p_tenant_uuid);
-  PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
-
-  SELECT * INTO v_existing
-  FROM orgunit.tenant_field_config_events
-  WHERE tenant_uuid = p_tenant_uuid
-    AND request_id = p_request_id
-  LIMIT 1;
-
-  IF FOUND THEN
-    IF v_existing.event_type <> 'DISABLE'
-      OR v_existing.field_key <> p_field_key
-      OR v_existing.initiator_uuid <> p_initiator_uuid
-      OR COALESCE(v_existing.payload->>'disabled_on', '') <> p_disabled_on::text
-    THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
-        DETAIL = format('request_id=%s', p_request_id);
-    END IF;
-
-    RETURN;
-  END IF;
-
-  SELECT * INTO v_cfg
-  FROM orgunit.tenant_field_configs
-  WHERE tenant_uuid = p_tenant_uuid
-    AND field_key = p_field_key
-  LIMIT 1;
-
-  IF NOT FOUND THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_FIELD_CONFIG_NOT_FOUND',
-      DETAIL = format('field_key=%s', p_field_key);
-  END IF;
-
-  IF p_disabled_on < v_cfg.enabled_on THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID',
-      DETAIL = format('field_key=%s enabled_on=%s disabled_on=%s', p_field_key, v_cfg.enabled_on, p_disabled_on);
-  END IF;
-  -- MVP freeze: forbid backdating disables.
-  IF p_disabled_on < current_date THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID',
-      DETAIL = format('field_key=%s disabled_on=%s', p_field_key, p_disabled_on);
+  IF v_form_key NOT IN (
+    '',
+    'orgunit.create_dialog',
+    'orgunit.details.add_version_dialog',
+    'orgunit.details.insert_version_dialog',
+    'orgunit.details.correct_dialog'
+  ) THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'form_key invalid';
   END IF;
 
-  IF v_cfg.disabled_on IS NOT NULL THEN
-    IF current_date >= v_cfg.disabled_on THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID',
-        DETAIL = format('field_key=%s disabled_on=%s', p_field_key, v_cfg.disabled_on);
-    END IF;
-    IF p_disabled_on <= v_cfg.disabled_on THEN
-      RAISE EXCEPTION USING
-        MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID',
-        DETAIL = format('field_key=%s old=%s new=%s', p_field_key, v_cfg.disabled_on, p_disabled_on);
+  PERFORM pg_advisory_xact_lock(hashtextextended(
+    format('orgunit.field_validation_rule:%s:%s:%s:%s', p_tenant_uuid, p_field_key, v_form_key, p_rule_key),
+    0
+  ));
+
+  SELECT event_type, (payload->>'rule_id')::bigint
+  INTO v_event_type, v_rule_id
+  FROM orgunit.tenant_field_validation_rule_events
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id
+  LIMIT 1;
+
+  IF FOUND THEN
+    IF v_event_type <> 'DISABLE' THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_REQUEST_ID_CONFLICT', DETAIL = format('request_id=%s', p_request_id);
     END IF;
+    RETURN v_rule_id;
   END IF;
 
-  v_payload := jsonb_build_object('disabled_on', p_disabled_on::text);
+  SELECT id, enabled_on
+  INTO v_rule_id, v_enabled_on
+  FROM orgunit.tenant_field_validation_rules
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_field_key
+    AND form_key = v_form_key
+    AND rule_key = p_rule_key
+    AND enabled_on < p_disabled_on
+    AND p_disabled_on < COALESCE(disabled_on, 'infinity'::date)
+  ORDER BY enabled_on DESC
+  LIMIT 1
+  FOR UPDATE;
 
-  INSERT INTO orgunit.tenant_field_config_events (
+  IF v_rule_id IS NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_FIELD_VALIDATION_RULE_NOT_FOUND',
+      DETAIL = format('field_key=%s rule_key=%s', p_field_key, p_rule_key);
+  END IF;
+
+  UPDATE orgunit.tenant_field_validation_rules
+  SET disabled_on = p_disabled_on, disabled_at = now(), updated_at = now()
+  WHERE id = v_rule_id;
+
+  INSERT INTO orgunit.tenant_field_validation_rule_events (
     event_uuid,
     tenant_uuid,
     event_type,
     field_key,
+    form_key,
+    rule_key,
     payload,
     request_id,
     initiator_uuid
-  )
-  VALUES (
+  ) VALUES (
     gen_random_uuid(),
     p_tenant_uuid,
     'DISABLE',
     p_field_key,
-    v_payload,
+    v_form_key,
+    p_rule_key,
+    jsonb_build_object('rule_id', v_rule_id, 'disabled_on', p_disabled_on),
     p_request_id,
     p_initiator_uuid
   );
 
-  UPDATE orgunit.tenant_field_configs
-  SET disabled_on = p_disabled_on,
-      disabled_at = now(),
-      updated_at = now()
-  WHERE tenant_uuid = p_tenant_uuid
-    AND field_key = p_field_key;
+  RETURN v_rule_id;
 END;
 $$;
 
--- end: modules/orgunit/infrastructure/persistence/schema/00030_orgunit_field_configs_schema.sql
-
--- begin: modules/orgunit/infrastructure/persistence/schema/00031_orgunit_field_configs_kernel_privileges.sql
--- OrgUnit tenant field configs (metadata) kernel privileges (Phase 1)
--- SSOT: docs/dev-plans/100b-org-metadata-wide-table-phase1-schema-and-metadata-skeleton.md
-
-DO $$
-BEGIN
-  IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'orgunit_kernel') THEN
-    CREATE ROLE orgunit_kernel NOLOGIN NOBYPASSRLS;
-  END IF;
-END $$;
-
-GRANT USAGE ON SCHEMA orgunit TO orgunit_kernel;
-
-ALTER TABLE IF EXISTS orgunit.tenant_field_config_events OWNER TO orgunit_kernel;
-ALTER TABLE IF EXISTS orgunit.tenant_field_configs OWNER TO orgunit_kernel;
-
-GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE
-  orgunit.tenant_field_config_events,
-  orgunit.tenant_field_configs
-TO orgunit_kernel;
-
-GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA orgunit TO orgunit_kernel;
-
-ALTER FUNCTION orgunit.guard_tenant_field_configs_write()
-  OWNER TO orgunit_kernel;
-ALTER FUNCTION orgunit.guard_tenant_field_configs_write()
-  SET search_path = pg_catalog, orgunit, public;
-
-ALTER FUNCTION orgunit.assert_tenant_field_configs_update_allowed()
-  OWNER TO orgunit_kernel;
-ALTER FUNCTION orgunit.assert_tenant_field_configs_update_allowed()
-  SET search_path = pg_catalog, orgunit, public;
-
-ALTER FUNCTION orgunit.enable_tenant_field_config(uuid, text, text, date, text, jsonb, text, text, uuid)
-  OWNER TO orgunit_kernel;
-ALTER FUNCTION orgunit.enable_tenant_field_config(uuid, text, text, date, text, jsonb, text, text, uuid)
-  SECURITY DEFINER;
-ALTER FUNCTION orgunit.enable_tenant_field_config(uuid, text, text, date, text, jsonb, text, text, uuid)
-  SET search_path = pg_catalog, orgunit, public;
-
-ALTER FUNCTION orgunit.disable_tenant_field_config(uuid, text, date, text, uuid)
-  OWNER TO orgunit_kernel;
-ALTER FUNCTION orgunit.disable_tenant_field_config(uuid, text, date, text, uuid)
-  SECURITY DEFINER;
-ALTER FUNCTION orgunit.disable_tenant_field_config(uuid, text, date, text, uuid)
-  SET search_path = pg_catalog, orgunit, public;
-
-ALTER FUNCTION orgunit.rename_jsonb_object_key_strict(jsonb, text, text)
+ALTER FUNCTION orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, date, text, uuid)
   OWNER TO orgunit_kernel;
-ALTER FUNCTION orgunit.rename_jsonb_object_key_strict(jsonb, text, text)
+ALTER FUNCTION orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, date, text, uuid)
   SECURITY DEFINER;
-ALTER FUNCTION orgunit.rename_jsonb_object_key_strict(jsonb, text, text)
+ALTER FUNCTION orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, date, text, uuid)
   SET search_path = pg_catalog, orgunit, public;
-
-ALTER FUNCTION orgunit.rekey_tenant_field_config(uuid, text, text, text, uuid)
+ALTER FUNCTION orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, date, text, uuid)
   OWNER TO orgunit_kernel;
-ALTER FUNCTION orgunit.rekey_tenant_field_config(uuid, text, text, text, uuid)
+ALTER FUNCTION orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, date, text, uuid)
   SECURITY DEFINER;
-ALTER FUNCTION orgunit.rekey_tenant_field_config(uuid, text, text, text, uuid)
+ALTER FUNCTION orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, date, text, uuid)
   SET search_path = pg_catalog, orgunit, public;
 
-DO $$
-BEGIN
-  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app') THEN
-    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
-      'orgunit.tenant_field_config_events, ' ||
-      'orgunit.tenant_field_configs ' ||
-      'FROM app';
-    EXECUTE 'GRANT SELECT ON TABLE ' ||
-      'orgunit.tenant_field_config_events, ' ||
-      'orgunit.tenant_field_configs ' ||
-      'TO app';
-  END IF;
-  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_runtime') THEN
-    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
-      'orgunit.tenant_field_config_events, ' ||
-      'orgunit.tenant_field_configs ' ||
-      'FROM app_runtime';
-    EXECUTE 'GRANT SELECT ON TABLE ' ||
-      'orgunit.tenant_field_config_events, ' ||
-      'orgunit.tenant_field_configs ' ||
-      'TO app_runtime';
-  END IF;
-  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_nobypassrls') THEN
-    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
-      'orgunit.tenant_field_config_events, ' ||
-      'orgunit.tenant_field_configs ' ||
-      'FROM app_nobypassrls';
-    EXECUTE 'GRANT SELECT ON TABLE ' ||
-      'orgunit.tenant_field_config_events, ' ||
-      'orgunit.tenant_field_configs ' ||
-      'TO app_nobypassrls';
-  END IF;
-  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
-    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
-      'orgunit.tenant_field_config_events, ' ||
-      'orgunit.tenant_field_configs ' ||
-      'FROM superadmin_runtime';
-    EXECUTE 'GRANT SELECT ON TABLE ' ||
-      'orgunit.tenant_field_config_events, ' ||
-      'orgunit.tenant_field_configs ' ||
-      'TO superadmin_runtime';
-  END IF;
-END $$;
-
--- end: modules/orgunit/infrastructure/persistence/schema/00031_orgunit_field_configs_kernel_privileges.sql
-
--- begin: modules/orgunit/infrastructure/persistence/schema/00032_orgunit_field_policies_schema.sql
--- OrgUnit tenant field policies (maintainable/default-rule/scope)
--- SSOT: docs/dev-plans/120-org-field-default-values-cel-rule-engine-roadmap.md
-
-CREATE TABLE IF NOT EXISTS orgunit.tenant_field_policies (
-  id bigserial PRIMARY KEY,
-  tenant_uuid uuid NOT NULL,
-  field_key text NOT NULL,
-  scope_type text NOT NULL,
-  scope_key text NOT NULL,
-  maintainable boolean NOT NULL DEFAULT true,
-  default_mode text NOT NULL DEFAULT 'NONE',
-  default_rule_expr text NULL,
-  enabled_on date NOT NULL,
-  disabled_on date NULL,
-  created_at timestamptz NOT NULL DEFAULT now(),
-  updated_at timestamptz NOT NULL DEFAULT now(),
-  disabled_at timestamptz NULL,
-  CONSTRAINT tenant_field_policies_field_key_format_check CHECK (field_key ~ '^[a-z][a-z0-9_]{0,62}$'),
-  CONSTRAINT tenant_field_policies_scope_type_check CHECK (scope_type IN ('GLOBAL','FORM')),
-  CONSTRAINT tenant_field_policies_scope_key_check CHECK (
-    (scope_type = 'GLOBAL' AND scope_key = 'global')
-    OR
-    (scope_type = 'FORM' AND scope_key IN (
-      'orgunit.create_dialog',
-      'orgunit.details.add_version_dialog',
-      'orgunit.details.insert_version_dialog',
-      'orgunit.details.correct_dialog'
-    ))
-  ),
-  CONSTRAINT tenant_field_policies_default_mode_check CHECK (default_mode IN ('NONE','CEL')),
-  CONSTRAINT tenant_field_policies_default_rule_expr_required_check CHECK (
-    (default_mode = 'NONE' AND default_rule_expr IS NULL)
-    OR
-    (default_mode = 'CEL' AND default_rule_expr IS NOT NULL AND btrim(default_rule_expr) <> '')
-  ),
-  CONSTRAINT tenant_field_policies_disabled_on_check CHECK (disabled_on IS NULL OR disabled_on > enabled_on)
-);
-
-CREATE INDEX IF NOT EXISTS tenant_field_policies_tenant_field_scope_idx
-  ON orgunit.tenant_field_policies (tenant_uuid, field_key, scope_type, scope_key, enabled_on DESC);
-
-CREATE TABLE IF NOT EXISTS orgunit.tenant_field_policy_events (
-  id bigserial PRIMARY KEY,
-  event_uuid uuid NOT NULL,
-  tenant_uuid uuid NOT NULL,
-  event_type text NOT NULL,
-  field_key text NOT NULL,
-  scope_type text NOT NULL,
-  scope_key text NOT NULL,
-  payload jsonb NOT NULL DEFAULT '{}'::jsonb,
-  request_id text NOT NULL,
-  initiator_uuid uuid NOT NULL,
-  transaction_time timestamptz NOT NULL DEFAULT now(),
-  created_at timestamptz NOT NULL DEFAULT now(),
-  CONSTRAINT tenant_field_policy_events_event_type_check CHECK (event_type IN ('UPSERT','DISABLE')),
-  CONSTRAINT tenant_field_policy_events_field_key_format_check CHECK (field_key ~ '^[a-z][a-z0-9_]{0,62}$'),
-  CONSTRAINT tenant_field_policy_events_scope_type_check CHECK (scope_type IN ('GLOBAL','FORM')),
-  CONSTRAINT tenant_field_policy_events_scope_key_non_empty_check CHECK (btrim(scope_key) <> ''),
-  CONSTRAINT tenant_field_policy_events_request_id_unique UNIQUE (tenant_uuid, request_id),
-  CONSTRAINT tenant_field_policy_events_event_uuid_unique UNIQUE (event_uuid),
-  CONSTRAINT tenant_field_policy_events_payload_is_object_check CHECK (jsonb_typeof(payload) = 'object')
-);
-
-CREATE INDEX IF NOT EXISTS tenant_field_policy_events_tenant_time_idx
-  ON orgunit.tenant_field_policy_events (tenant_uuid, transaction_time DESC, id DESC);
-
-ALTER TABLE orgunit.tenant_field_policies ENABLE ROW LEVEL SECURITY;
-ALTER TABLE orgunit.tenant_field_policies FORCE ROW LEVEL SECURITY;
-DROP POLICY IF EXISTS tenant_isolation ON orgunit.tenant_field_policies;
-CREATE POLICY tenant_isolation ON orgunit.tenant_field_policies
-USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
-WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
-
-ALTER TABLE orgunit.tenant_field_policy_events ENABLE ROW LEVEL SECURITY;
-ALTER TABLE orgunit.tenant_field_policy_events FORCE ROW LEVEL SECURITY;
-DROP POLICY IF EXISTS tenant_isolation ON orgunit.tenant_field_policy_events;
-CREATE POLICY tenant_isolation ON orgunit.tenant_field_policy_events
-USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
-WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
-
-CREATE OR REPLACE FUNCTION orgunit.guard_tenant_field_policies_write()
-RETURNS trigger
-LANGUAGE plpgsql
-AS $$
-BEGIN
-  IF current_user <> 'orgunit_kernel' THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'ORGUNIT_FIELD_POLICIES_WRITE_FORBIDDEN',
-      DETAIL = format('role=%s table=%s', current_user, TG_TABLE_NAME);
-  END IF;
-  IF TG_OP = 'DELETE' THEN
-    RETURN OLD;
-  END IF;
-  RETURN NEW;
-END;
-$$;
-
-DROP TRIGGER IF EXISTS guard_tenant_field_policies_write ON orgunit.tenant_field_policies;
-CREATE TRIGGER guard_tenant_field_policies_write
-BEFORE INSERT OR UPDATE OR DELETE ON orgunit.tenant_field_policies
-FOR EACH ROW EXECUTE FUNCTION orgunit.guard_tenant_field_policies_write();
+-- end: modules/orgunit/infrastructure/persistence/schema/00042_orgunit_field_validation_rule_form_key.sql
 
-DROP TRIGGER IF EXISTS guard_tenant_field_policy_events_write ON orgunit.tenant_field_policy_events;
-CREATE TRIGGER guard_tenant_field_policy_events_write
-BEFORE INSERT OR UPDATE OR DELETE ON orgunit.tenant_field_policy_events
-FOR EACH ROW EXECUTE FUNCTION orgunit.guard_tenant_field_policies_write();
+-- begin: modules/orgunit/infrastructure/persistence/schema/00043_orgunit_known_at_event_stream.sql
+-- Bitemporal reads (known_at) fold the effective event stream into a snapshot in the read service instead
+-- of replaying it into the live projection, so the rebuild kernel goes back to its original shape. The
+-- correction folding itself lives once, in org_events_effective_known_at: with a NULL p_known_at and an
+-- org it is exactly the stream the rebuild replays, with a NULL org it covers the whole tenant.
+DROP FUNCTION IF EXISTS orgunit.replay_org_unit_versions_known_at(uuid, timestamptz);
+DROP FUNCTION IF EXISTS orgunit.org_events_effective_for_replay(uuid, char(8), bigint, uuid, text, date, jsonb, text, uuid, timestamptz, timestamptz, timestamptz, timestamptz);
 
-CREATE OR REPLACE FUNCTION orgunit.assert_tenant_field_policies_non_overlapping()
-RETURNS trigger
-LANGUAGE plpgsql
+CREATE OR REPLACE FUNCTION orgunit.org_events_effective_known_at(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_known_at timestamptz,
+  p_pending_event_id bigint DEFAULT NULL,
+  p_pending_event_uuid uuid DEFAULT NULL,
+  p_pending_event_type text DEFAULT NULL,
+  p_pending_effective_date date DEFAULT NULL,
+  p_pending_payload jsonb DEFAULT NULL,
+  p_pending_request_id text DEFAULT NULL,
+  p_pending_initiator_uuid uuid DEFAULT NULL,
+  p_pending_tx_time timestamptz DEFAULT NULL,
+  p_pending_transaction_time timestamptz DEFAULT NULL,
+  p_pending_created_at timestamptz DEFAULT NULL
+)
+RETURNS TABLE (
+  id bigint,
+  event_uuid uuid,
+  tenant_uuid uuid,
+  org_node_key char(8),
+  event_type text,
+  effective_date date,
+  payload jsonb,
+  request_id text,
+  initiator_uuid uuid,
+  transaction_time timestamptz,
+  created_at timestamptz
+)
+LANGUAGE sql
+STABLE
 AS $$
-DECLARE
-  v_overlap_id bigint;
-BEGIN
-  IF TG_OP = 'DELETE' THEN
-    RETURN OLD;
-  END IF;
-
-  SELECT p.id
-  INTO v_overlap_id
-  FROM orgunit.tenant_field_policies p
-  WHERE p.tenant_uuid = NEW.tenant_uuid
-    AND p.field_key = NEW.field_key
-    AND p.scope_type = NEW.scope_type
-    AND p.scope_key = NEW.scope_key
-    AND p.id <> NEW.id
-    AND daterange(
-      NEW.enabled_on,
-      COALESCE(NEW.disabled_on, 'infinity'::date),
-      '[)'
-    ) && daterange(
-      p.enabled_on,
-      COALESCE(p.disabled_on, 'infinity'::date),
-      '[)'
-    )
-  LIMIT 1;
+  WITH source_events AS (
+    SELECT
+      e.id,
+      e.event_uuid,
+      e.tenant_uuid,
+      e.org_node_key,
+      e.event_type,
+      e.effective_date,
+      COALESCE(e.payload, '{}'::jsonb) AS payload,
+      e.request_id,
+      e.initiator_uuid,
+      e.tx_time,
+      e.transaction_time,
+      e.created_at
+    FROM orgunit.org_events e
+    WHERE e.tenant_uuid = p_tenant_uuid
+      AND (p_org_node_key IS NULL OR e.org_node_key = p_org_node_key)
+      AND (p_known_at IS NULL OR e.tx_time <= p_known_at)
 
-  IF v_overlap_id IS NOT NULL THEN
-    RAISE EXCEPTION USING
-      MESSAGE = 'FIELD_POLICY_SCOPE_OVERLAP',
-      DETAIL = format(
-        'field_key=%s scope_type=%s scope_key=%s overlap_id=%s',
-        NEW.field_key, NEW.scope_type, NEW.scope_key, v_overlap_id
-      );
-  END IF;
+    UNION ALL
 
-  RETURN NEW;
-END;
+    SELECT
+      p_pending_event_id,
+      p_pending_event_uuid,
+      p_tenant_uuid,
+      p_org_node_key,
+      p_pending_event_type,
+      p_pending_effective_date,
+      COALESCE(p_pending_payload, '{}'::jsonb),
+      p_pending_request_id,
+      p_pending_initiator_uuid,
+      p_pending_tx_time,
+      p_pending_transaction_time,
+      p_pending_created_at
+    WHERE p_pending_event_id IS NOT NULL
+  ),
+  correction_events AS (
+    SELECT
+      se.*,
+      (se.payload->>'target_event_uuid')::uuid AS target_event_uuid
+    FROM source_events se
+    WHERE se.event_type IN ('CORRECT_EVENT','CORRECT_STATUS','RESCIND_EVENT','RESCIND_ORG')
+      AND se.payload ? 'target_event_uuid'
+  ),
+  latest_corrections AS (
+    SELECT DISTINCT ON (tenant_uuid, target_event_uuid)
+      tenant_uuid,
+      target_event_uuid,
+      event_type AS correction_type,
+      payload AS correction_payload,
+      tx_time,
+      id
+    FROM correction_events
+    ORDER BY tenant_uuid, target_event_uuid, tx_time DESC, id DESC
+  ),
+  latest_effective_date_corrections AS (
+    -- Sticky effective_date: take the latest CORRECT_EVENT that explicitly carries effective_date,
+    -- regardless of later corrections that don't include effective_date.
+    SELECT DISTINCT ON (tenant_uuid, target_event_uuid)
+      tenant_uuid,
+      target_event_uuid,
+      NULLIF(btrim(payload->>'effective_date'), '')::date AS sticky_effective_date,
+      tx_time,
+      id
+    FROM correction_events
+    WHERE event_type = 'CORRECT_EVENT'
+      AND payload ? 'effective_date'
+    ORDER BY tenant_uuid, target_event_uuid, tx_time DESC, id DESC
+  )
+  SELECT
+    se.id,
+    se.event_uuid,
+    se.tenant_uuid,
+    se.org_node_key,
+    CASE
+      WHEN lc.correction_type = 'CORRECT_STATUS'
+        AND COALESCE(lc.correction_payload->>'target_status', '') = 'active'
+        THEN 'ENABLE'
+      WHEN lc.correction_type = 'CORRECT_STATUS'
+        AND COALESCE(lc.correction_payload->>'target_status', '') = 'disabled'
+        THEN 'DISABLE'
+      WHEN lc.correction_type = 'CORRECT_EVENT'
+        AND se.event_type <> 'CREATE'
+        AND (
+          orgunit.merge_org_event_payload_with_correction(se.payload, lc.correction_payload) ?| ARRAY[
+            'name',
+            'parent_org_node_key',
+            'status',
+            'is_business_unit',
+            'manager_uuid',
+            'manager_pernr',
+            'ext',
+            'new_name',
+            'new_parent_org_node_key'
+          ]
+        )
+        THEN 'UPDATE'
+      ELSE se.event_type
+    END AS event_type,
+    COALESCE(lec.sticky_effective_date, se.effective_date) AS effective_date,
+    CASE
+      WHEN lc.correction_type = 'CORRECT_EVENT'
+        THEN orgunit.merge_org_event_payload_with_correction(se.payload, lc.correction_payload)
+      ELSE se.payload
+    END AS payload,
+    se.request_id,
+    se.initiator_uuid,
+    se.transaction_time,
+    se.created_at
+  FROM source_events se
+  LEFT JOIN latest_corrections lc
+    ON lc.tenant_uuid = se.tenant_uuid
+   AND lc.target_event_uuid = se.event_uuid
+  LEFT JOIN latest_effective_date_corrections lec
+    ON lec.tenant_uuid = se.tenant_uuid
+   AND lec.target_event_uuid = se.event_uuid
+  WHERE se.event_type IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT')
+    AND COALESCE(lc.correction_type, '') NOT IN ('RESCIND_EVENT', 'RESCIND_ORG')
+  ORDER BY effective_date, id;
 $$;
 
-DROP TRIGGER IF EXISTS tenant_field_policies_non_overlapping ON orgunit.tenant_field_policies;
-CREATE TRIGGER tenant_field_policies_non_overlapping
-BEFORE INSERT OR UPDATE ON orgunit.tenant_field_policies
-FOR EACH ROW EXECUTE FUNCTION orgunit.assert_tenant_field_policies_non_overlapping();
+CREATE OR REPLACE FUNCTION orgunit.org_events_effective_for_replay(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_pending_event_id bigint,
+  p_pending_event_uuid uuid,
+  p_pending_event_type text,
+  p_pending_effective_date date,
+  p_pending_payload jsonb,
+  p_pending_request_id text,
+  p_pending_initiator_uuid uuid,
+  p_pending_tx_time timestamptz,
+  p_pending_transaction_time timestamptz,
+  p_pending_created_at timestamptz
+)
+RETURNS TABLE (
+  id bigint,
+  event_uuid uuid,
+  tenant_uuid uuid,
+  org_node_key char(8),
+  event_type text,
+  effective_date date,
+  payload jsonb,
+  request_id text,
+  initiator_uuid uuid,
+  transaction_time timestamptz,
+  created_at timestamptz
+)
+LANGUAGE sql
+STABLE
+AS $$
+  SELECT *
+  FROM orgunit.org_events_effective_known_at(
+    p_tenant_uuid,
+    p_org_node_key,
+    NULL,
+    p_pending_event_id,
+    p_pending_event_uuid,
+    p_pending_event_type,
+    p_pending_effective_date,
+    p_pending_payload,
+    p_pending_request_id,
+    p_pending_initiator_uuid,
+    p_pending_tx_time,
+    p_pending_transaction_time,
+    p_pending_created_at
+  ) e
+  ORDER BY e.effective_date, e.id;
+$$;
 
-CREATE OR REPLACE FUNCTION orgunit.upsert_tenant_field_policy(
+CREATE OR REPLACE FUNCTION orgunit.rebuild_org_unit_versions_for_org_with_pending_event(
   p_tenant_uuid uuid,
-  p_field_key text,
-  p_scope_type text,
-  p_scope_key text,
-  p_maintainable boolean,
-  p_default_mode text,
-  p_default_rule_expr text,
-  p_enabled_on date,
-  p_request_id text,
-  p_initiator_uuid uuid
+  p_org_node_key char(8),
+  p_pending_event_id bigint,
+  p_pending_event_uuid uuid,
+  p_pending_event_type text,
+  p_pending_effective_date date,
+  p_pending_payload jsonb,
+  p_pending_request_id text,
+  p_pending_initiator_uuid uuid,
+  p_pending_tx_time timestamptz,
+  p_pending_transaction_time timestamptz,
+  p_pending_created_at timestamptz
 )
-RETURNS bigint
+RETURNS void
 LANGUAGE plpgsql
 AS $$
 DECLARE
-  v_scope_type text := upper(btrim(p_scope_type));
-  v_scope_key text := btrim(p_scope_key);
-  v_default_mode text := upper(btrim(p_default_mode));
-  v_default_rule_expr text := NULLIF(btrim(p_default_rule_expr), '');
-  v_event_type text;
-  v_policy_id bigint;
-  v_open_id bigint;
-  v_open_enabled_on date;
-  v_next_enabled_on date;
-BEGIN
-  IF v_scope_type NOT IN ('GLOBAL','FORM') THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_type invalid';
-  END IF;
-  IF v_scope_type = 'GLOBAL' THEN
-    v_scope_key := 'global';
-  END IF;
-  IF v_scope_key = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_key required';
-  END IF;
-  IF v_default_mode NOT IN ('NONE','CEL') THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'default_mode invalid';
-  END IF;
-  IF v_default_mode = 'NONE' THEN
-    v_default_rule_expr := NULL;
-  END IF;
-  IF v_default_mode = 'CEL' AND v_default_rule_expr IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'FIELD_POLICY_EXPR_INVALID', DETAIL = 'default_rule_expr required';
-  END IF;
-
-  PERFORM pg_advisory_xact_lock(hashtextextended(
-    format('orgunit.field_policy:%s:%s:%s:%s', p_tenant_uuid, p_field_key, v_scope_type, v_scope_key),
-    0
-  ));
+  v_event record;
+  v_payload jsonb;
+  v_parent_org_node_key char(8);
+  v_new_parent_org_node_key char(8);
+  v_name text;
+  v_new_name text;
+  v_manager_uuid uuid;
+  v_is_business_unit boolean;
+  v_org_code text;
+  v_root_path ltree;
+  v_org_node_keys char(8)[];
+  v_root_org_node_key char(8);
+  v_has_create boolean;
+  v_status text;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
 
-  SELECT event_type, (payload->>'policy_id')::bigint
-  INTO v_event_type, v_policy_id
-  FROM orgunit.tenant_field_policy_events
-  WHERE tenant_uuid = p_tenant_uuid
-    AND request_id = p_request_id
-  LIMIT 1;
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
 
-  IF FOUND THEN
-    IF v_event_type <> 'UPSERT' THEN
-      RAISE EXCEPTION USING MESSAGE = 'ORG_REQUEST_ID_CONFLICT', DETAIL = format('request_id=%s', p_request_id);
+  IF p_pending_event_id IS NOT NULL THEN
+    IF p_pending_event_uuid IS NULL
+      OR p_pending_event_type IS NULL
+      OR p_pending_effective_date IS NULL
+      OR p_pending_request_id IS NULL
+      OR p_pending_initiator_uuid IS NULL
+      OR p_pending_tx_time IS NULL
+      OR p_pending_transaction_time IS NULL
+      OR p_pending_created_at IS NULL
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_INVALID_ARGUMENT',
+        DETAIL = 'pending event metadata is incomplete';
     END IF;
-    RETURN v_policy_id;
   END IF;
 
-  SELECT id, enabled_on
-  INTO v_open_id, v_open_enabled_on
-  FROM orgunit.tenant_field_policies
-  WHERE tenant_uuid = p_tenant_uuid
-    AND field_key = p_field_key
-    AND scope_type = v_scope_type
-    AND scope_key = v_scope_key
-    AND disabled_on IS NULL
-  ORDER BY enabled_on DESC
-  LIMIT 1
-  FOR UPDATE;
+  SELECT t.root_org_node_key INTO v_root_org_node_key
+  FROM orgunit.org_trees t
+  WHERE t.tenant_uuid = p_tenant_uuid;
 
-  IF v_open_id IS NOT NULL AND v_open_enabled_on < p_enabled_on THEN
-    UPDATE orgunit.tenant_field_policies
-    SET disabled_on = p_enabled_on, disabled_at = now(), updated_at = now()
-    WHERE id = v_open_id;
+  SELECT EXISTS (
+    SELECT 1
+    FROM orgunit.org_events_effective_for_replay(
+      p_tenant_uuid,
+      p_org_node_key,
+      p_pending_event_id,
+      p_pending_event_uuid,
+      p_pending_event_type,
+      p_pending_effective_date,
+      p_pending_payload,
+      p_pending_request_id,
+      p_pending_initiator_uuid,
+      p_pending_tx_time,
+      p_pending_transaction_time,
+      p_pending_created_at
+    ) e
+    WHERE e.event_type = 'CREATE'
+  ) INTO v_has_create;
+
+  IF v_has_create AND v_root_org_node_key = p_org_node_key THEN
+    DELETE FROM orgunit.org_trees
+    WHERE tenant_uuid = p_tenant_uuid;
+  ELSIF v_root_org_node_key = p_org_node_key AND NOT v_has_create THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = format('root org missing create event: org_node_key=%s', p_org_node_key);
   END IF;
 
-  UPDATE orgunit.tenant_field_policies
-  SET maintainable = p_maintainable,
-      default_mode = v_default_mode,
-      default_rule_expr = v_default_rule_expr,
-      updated_at = now()
+  DELETE FROM orgunit.org_unit_codes
   WHERE tenant_uuid = p_tenant_uuid
-    AND field_key = p_field_key
-    AND scope_type = v_scope_type
-    AND scope_key = v_scope_key
-    AND enabled_on = p_enabled_on
-  RETURNING id INTO v_policy_id;
+    AND org_node_key = p_org_node_key;
 
-  IF v_policy_id IS NULL THEN
-    SELECT MIN(enabled_on)
-    INTO v_next_enabled_on
-    FROM orgunit.tenant_field_policies
-    WHERE tenant_uuid = p_tenant_uuid
-      AND field_key = p_field_key
-      AND scope_type = v_scope_type
-      AND scope_key = v_scope_key
-      AND enabled_on > p_enabled_on;
+  DELETE FROM orgunit.org_unit_versions
+  WHERE tenant_uuid = p_tenant_uuid
+    AND org_node_key = p_org_node_key;
 
-    INSERT INTO orgunit.tenant_field_policies (
-      tenant_uuid,
-      field_key,
-      scope_type,
-      scope_key,
-      maintainable,
-      default_mode,
-      default_rule_expr,
-      enabled_on,
-      disabled_on
-    ) VALUES (
+  FOR v_event IN
+    SELECT *
+    FROM orgunit.org_events_effective_for_replay(
       p_tenant_uuid,
-      p_field_key,
-      v_scope_type,
-      v_scope_key,
-      p_maintainable,
-      v_default_mode,
-      v_default_rule_expr,
-      p_enabled_on,
-      v_next_enabled_on
+      p_org_node_key,
+      p_pending_event_id,
+      p_pending_event_uuid,
+      p_pending_event_type,
+      p_pending_effective_date,
+      p_pending_payload,
+      p_pending_request_id,
+      p_pending_initiator_uuid,
+      p_pending_tx_time,
+      p_pending_transaction_time,
+      p_pending_created_at
     )
-    RETURNING id INTO v_policy_id;
-  END IF;
-
-  INSERT INTO orgunit.tenant_field_policy_events (
-    event_uuid,
-    tenant_uuid,
-    event_type,
-    field_key,
-    scope_type,
-    scope_key,
-    payload,
-    request_id,
-    initiator_uuid
-  ) VALUES (
-    gen_random_uuid(),
-    p_tenant_uuid,
-    'UPSERT',
-    p_field_key,
-    v_scope_type,
-    v_scope_key,
-    jsonb_build_object('policy_id', v_policy_id),
-    p_request_id,
-    p_initiator_uuid
-  );
-
-  RETURN v_policy_id;
-END;
-$$;
+    ORDER BY effective_date, id
+  LOOP
+    v_payload := COALESCE(v_event.payload, '{}'::jsonb);
 
-CREATE OR REPLACE FUNCTION orgunit.disable_tenant_field_policy(
-  p_tenant_uuid uuid,
-  p_field_key text,
-  p_scope_type text,
-  p_scope_key text,
-  p_disabled_on date,
-  p_request_id text,
-  p_initiator_uuid uuid
-)
-RETURNS bigint
-LANGUAGE plpgsql
-AS $$
-DECLARE
-  v_scope_type text := upper(btrim(p_scope_type));
-  v_scope_key text := btrim(p_scope_key);
-  v_event_type text;
-  v_policy_id bigint;
-  v_enabled_on date;
-  v_disabled_on_old date;
-BEGIN
-  IF v_scope_type NOT IN ('GLOBAL','FORM') THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_type invalid';
-  END IF;
-  IF v_scope_type = 'GLOBAL' THEN
-    v_scope_key := 'global';
-  END IF;
-  IF v_scope_key = '' THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_key required';
-  END IF;
+    IF v_event.event_type = 'CREATE' THEN
+      v_parent_org_node_key := NULLIF(v_payload->>'parent_org_node_key', '')::char(8);
+      v_name := NULLIF(btrim(v_payload->>'name'), '');
+      v_manager_uuid := NULLIF(v_payload->>'manager_uuid', '')::uuid;
+      v_org_code := NULLIF(v_payload->>'org_code', '');
+      v_status := NULLIF(btrim(v_payload->>'status'), '');
+      v_is_business_unit := NULL;
+      IF v_payload ? 'is_business_unit' THEN
+        BEGIN
+          v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
+        EXCEPTION
+          WHEN invalid_text_representation THEN
+            RAISE EXCEPTION USING
+              MESSAGE = 'ORG_INVALID_ARGUMENT',
+              DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
+        END;
+      END IF;
+      PERFORM orgunit.apply_create_logic(p_tenant_uuid, v_event.org_node_key, v_org_code, v_parent_org_node_key, v_event.effective_date, v_name, v_manager_uuid, v_is_business_unit, v_event.id, v_status);
+      SELECT v.node_path INTO v_root_path
+      FROM orgunit.org_unit_versions v
+      WHERE v.tenant_uuid = p_tenant_uuid
+        AND v.org_node_key = p_org_node_key
+        AND v.validity @> v_event.effective_date
+      ORDER BY lower(v.validity) DESC
+      LIMIT 1;
+      IF v_root_path IS NOT NULL THEN
+        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, v_event.effective_date);
+      END IF;
+    ELSIF v_event.event_type = 'UPDATE' THEN
+      PERFORM orgunit.apply_update_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_payload, v_event.id);
+      IF (v_payload ? 'parent_org_node_key')
+        OR (v_payload ? 'new_parent_org_node_key')
+        OR (v_payload ? 'name')
+        OR (v_payload ? 'new_name')
+      THEN
+        SELECT v.node_path INTO v_root_path
+        FROM orgunit.org_unit_versions v
+        WHERE v.tenant_uuid = p_tenant_uuid
+          AND v.org_node_key = p_org_node_key
+          AND v.validity @> v_event.effective_date
+        ORDER BY lower(v.validity) DESC
+        LIMIT 1;
+        IF v_root_path IS NOT NULL THEN
+          PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, v_event.effective_date);
+        END IF;
+      END IF;
+    ELSIF v_event.event_type = 'MOVE' THEN
+      v_new_parent_org_node_key := NULLIF(v_payload->>'new_parent_org_node_key', '')::char(8);
+      PERFORM orgunit.apply_move_logic(p_tenant_uuid, v_event.org_node_key, v_new_parent_org_node_key, v_event.effective_date, v_event.id);
+      SELECT v.node_path INTO v_root_path
+      FROM orgunit.org_unit_versions v
+      WHERE v.tenant_uuid = p_tenant_uuid
+        AND v.org_node_key = p_org_node_key
+        AND v.validity @> v_event.effective_date
+      ORDER BY lower(v.validity) DESC
+      LIMIT 1;
+      IF v_root_path IS NOT NULL THEN
+        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, v_event.effective_date);
+      END IF;
+    ELSIF v_event.event_type = 'RENAME' THEN
+      v_new_name := NULLIF(btrim(v_payload->>'new_name'), '');
+      PERFORM orgunit.apply_rename_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_new_name, v_event.id);
+      SELECT v.node_path INTO v_root_path
+      FROM orgunit.org_unit_versions v
+      WHERE v.tenant_uuid = p_tenant_uuid
+        AND v.org_node_key = p_org_node_key
+        AND v.validity @> v_event.effective_date
+      ORDER BY lower(v.validity) DESC
+      LIMIT 1;
+      IF v_root_path IS NOT NULL THEN
+        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, v_event.effective_date);
+      END IF;
+    ELSIF v_event.event_type = 'DISABLE' THEN
+      PERFORM orgunit.apply_disable_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_event.id);
+    ELSIF v_event.event_type = 'ENABLE' THEN
+      PERFORM orgunit.apply_enable_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_event.id);
+    ELSIF v_event.event_type = 'SET_BUSINESS_UNIT' THEN
+      IF NOT (v_payload ? 'is_business_unit') THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_INVALID_ARGUMENT',
+          DETAIL = 'is_business_unit is required';
+      END IF;
+      BEGIN
+        v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
+      EXCEPTION
+        WHEN invalid_text_representation THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_INVALID_ARGUMENT',
+            DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
+      END;
+      PERFORM orgunit.apply_set_business_unit_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_is_business_unit, v_event.id);
+    ELSE
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_INVALID_ARGUMENT',
+        DETAIL = format('unexpected event_type: %s', v_event.event_type);
+    END IF;
 
-  PERFORM pg_advisory_xact_lock(hashtextextended(
-    format('orgunit.field_policy:%s:%s:%s:%s', p_tenant_uuid, p_field_key, v_scope_type, v_scope_key),
-    0
-  ));
+    PERFORM orgunit.apply_org_event_ext_payload(
+      p_tenant_uuid,
+      v_event.org_node_key,
+      v_event.effective_date,
+      v_event.event_type,
+      v_payload,
+      v_event.id
+    );
+  END LOOP;
 
-  SELECT event_type, (payload->>'policy_id')::bigint
-  INTO v_event_type, v_policy_id
-  FROM orgunit.tenant_field_policy_events
-  WHERE tenant_uuid = p_tenant_uuid
-    AND request_id = p_request_id
+  SELECT v.node_path INTO v_root_path
+  FROM orgunit.org_unit_versions v
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.org_node_key = p_org_node_key
+  ORDER BY lower(v.validity) DESC
   LIMIT 1;
 
-  IF FOUND THEN
-    IF v_event_type <> 'DISABLE' THEN
-      RAISE EXCEPTION USING MESSAGE = 'ORG_REQUEST_ID_CONFLICT', DETAIL = format('request_id=%s', p_request_id);
-    END IF;
-    RETURN v_policy_id;
+  IF v_root_path IS NULL THEN
+    RETURN;
   END IF;
 
-  SELECT id, enabled_on, disabled_on
-  INTO v_policy_id, v_enabled_on, v_disabled_on_old
-  FROM orgunit.tenant_field_policies
-  WHERE tenant_uuid = p_tenant_uuid
-    AND field_key = p_field_key
-    AND scope_type = v_scope_type
-    AND scope_key = v_scope_key
-    AND enabled_on < p_disabled_on
-    AND p_disabled_on < COALESCE(disabled_on, 'infinity'::date)
-  ORDER BY enabled_on DESC
-  LIMIT 1
-  FOR UPDATE;
+  SELECT array_agg(DISTINCT v.org_node_key) INTO v_org_node_keys
+  FROM orgunit.org_unit_versions v
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.node_path <@ v_root_path;
 
-  IF v_policy_id IS NULL THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_FIELD_POLICY_NOT_FOUND', DETAIL = format('field_key=%s', p_field_key);
-  END IF;
-  IF p_disabled_on <= v_enabled_on THEN
-    RAISE EXCEPTION USING MESSAGE = 'ORG_FIELD_CONFIG_DISABLED_ON_INVALID', DETAIL = format('field_key=%s', p_field_key);
-  END IF;
+  PERFORM orgunit.assert_org_unit_validity(p_tenant_uuid, v_org_node_keys);
+END;
+$$;
 
-  UPDATE orgunit.tenant_field_policies
-  SET disabled_on = p_disabled_on, disabled_at = now(), updated_at = now()
-  WHERE id = v_policy_id;
+DROP FUNCTION IF EXISTS orgunit.apply_replayed_org_event(uuid, char(8), bigint, text, date, jsonb);
 
-  INSERT INTO orgunit.tenant_field_policy_events (
-    event_uuid,
-    tenant_uuid,
-    event_type,
-    field_key,
-    scope_type,
-    scope_key,
-    payload,
-    request_id,
-    initiator_uuid
-  ) VALUES (
-    gen_random_uuid(),
-    p_tenant_uuid,
-    'DISABLE',
-    p_field_key,
-    v_scope_type,
-    v_scope_key,
-    jsonb_build_object('policy_id', v_policy_id, 'disabled_on', p_disabled_on),
-    p_request_id,
-    p_initiator_uuid
-  );
+-- end: modules/orgunit/infrastructure/persistence/schema/00043_orgunit_known_at_event_stream.sql
 
-  RETURN v_policy_id;
-END;
-$$;
+-- begin: modules/person/infrastructure/persistence/schema/00001_person_schema.sql
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+CREATE EXTENSION IF NOT EXISTS btree_gist;
 
--- end: modules/orgunit/infrastructure/persistence/schema/00032_orgunit_field_policies_schema.sql
+CREATE SCHEMA IF NOT EXISTS person;
 
--- begin: modules/orgunit/infrastructure/persistence/schema/00033_orgunit_field_policies_kernel_privileges.sql
--- OrgUnit tenant field policies kernel privileges
--- SSOT: docs/dev-plans/120-org-field-default-values-cel-rule-engine-roadmap.md
+-- Person identity anchor: pernr is stored canonical (1-8 digits, no leading zeros) and unique per tenant.
+CREATE TABLE IF NOT EXISTS person.persons (
+  tenant_uuid uuid NOT NULL,
+  person_uuid uuid NOT NULL DEFAULT gen_random_uuid(),
+  pernr text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (tenant_uuid, person_uuid),
+  CONSTRAINT persons_tenant_pernr_key UNIQUE (tenant_uuid, pernr),
+  CONSTRAINT persons_pernr_format_check CHECK (pernr ~ '^[0-9]{1,8}$'),
+  CONSTRAINT persons_pernr_canonical_check CHECK (pernr = '0' OR pernr !~ '^0')
+);
 
-DO $$
-BEGIN
-  IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'orgunit_kernel') THEN
-    CREATE ROLE orgunit_kernel NOLOGIN NOBYPASSRLS;
-  END IF;
-END $$;
+-- Effective-dated attributes; the timeline of one person never overlaps.
+CREATE TABLE IF NOT EXISTS person.person_versions (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL,
+  person_uuid uuid NOT NULL,
+  validity daterange NOT NULL,
+  display_name text NOT NULL,
+  status text NOT NULL DEFAULT 'active',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT person_versions_person_fkey
+    FOREIGN KEY (tenant_uuid, person_uuid)
+    REFERENCES person.persons(tenant_uuid, person_uuid)
+    ON DELETE CASCADE,
+  CONSTRAINT person_versions_display_name_check CHECK (display_name <> '' AND btrim(display_name) = display_name),
+  CONSTRAINT person_versions_status_check CHECK (status IN ('active', 'inactive')),
+  CONSTRAINT person_versions_validity_check CHECK (NOT isempty(validity)),
+  CONSTRAINT person_versions_validity_bounds_check CHECK (lower_inc(validity) AND NOT upper_inc(validity)),
+  CONSTRAINT person_versions_no_overlap
+    EXCLUDE USING gist (
+      tenant_uuid gist_uuid_ops WITH =,
+      person_uuid gist_uuid_ops WITH =,
+      validity WITH &&
+    )
+);
 
-GRANT USAGE ON SCHEMA orgunit TO orgunit_kernel;
+CREATE INDEX IF NOT EXISTS person_versions_lookup_btree
+  ON person.person_versions (tenant_uuid, person_uuid, lower(validity));
 
-ALTER TABLE IF EXISTS orgunit.tenant_field_policies OWNER TO orgunit_kernel;
-ALTER TABLE IF EXISTS orgunit.tenant_field_policy_events OWNER TO orgunit_kernel;
+ALTER TABLE person.persons ENABLE ROW LEVEL SECURITY;
+ALTER TABLE person.persons FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON person.persons;
+CREATE POLICY tenant_isolation ON person.persons
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
 
-GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE
-  orgunit.tenant_field_policies,
-  orgunit.tenant_field_policy_events
-TO orgunit_kernel;
+ALTER TABLE person.person_versions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE person.person_versions FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON person.person_versions;
+CREATE POLICY tenant_isolation ON person.person_versions
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
 
-GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA orgunit TO orgunit_kernel;
+-- Tenant hard delete (superadmin offboarding); versions go with their person through the FK.
+CREATE OR REPLACE FUNCTION person.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, person, public
+AS $$
+DECLARE
+  v_versions bigint;
+  v_persons bigint;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'PERSON_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
 
-ALTER FUNCTION orgunit.guard_tenant_field_policies_write()
-  OWNER TO orgunit_kernel;
-ALTER FUNCTION orgunit.guard_tenant_field_policies_write()
-  SET search_path = pg_catalog, orgunit, public;
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
 
-ALTER FUNCTION orgunit.assert_tenant_field_policies_non_overlapping()
-  OWNER TO orgunit_kernel;
-ALTER FUNCTION orgunit.assert_tenant_field_policies_non_overlapping()
-  SET search_path = pg_catalog, orgunit, public;
+  DELETE FROM person.person_versions WHERE tenant_uuid = p_tenant_uuid;
+  GET DIAGNOSTICS v_versions = ROW_COUNT;
+  DELETE FROM person.persons WHERE tenant_uuid = p_tenant_uuid;
+  GET DIAGNOSTICS v_persons = ROW_COUNT;
 
-ALTER FUNCTION orgunit.upsert_tenant_field_policy(uuid, text, text, text, boolean, text, text, date, text, uuid)
-  OWNER TO orgunit_kernel;
-ALTER FUNCTION orgunit.upsert_tenant_field_policy(uuid, text, text, text, boolean, text, text, date, text, uuid)
-  SECURITY DEFINER;
-ALTER FUNCTION orgunit.upsert_tenant_field_policy(uuid, text, text, text, boolean, text, text, date, text, uuid)
-  SET search_path = pg_catalog, orgunit, public;
+  RETURN jsonb_build_object('person.person_versions', v_versions, 'person.persons', v_persons);
+END;
+$$;
 
-ALTER FUNCTION orgunit.disable_tenant_field_policy(uuid, text, text, text, date, text, uuid)
-  OWNER TO orgunit_kernel;
-ALTER FUNCTION orgunit.disable_tenant_field_policy(uuid, text, text, text, date, text, uuid)
-  SECURITY DEFINER;
-ALTER FUNCTION orgunit.disable_tenant_field_policy(uuid, text, text, text, date, text, uuid)
-  SET search_path = pg_catalog, orgunit, public;
+REVOKE ALL ON FUNCTION person.purge_tenant_data(uuid) FROM PUBLIC;
 
 DO $$
 BEGIN
-  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app') THEN
-    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
-      'orgunit.tenant_field_policies, ' ||
-      'orgunit.tenant_field_policy_events ' ||
-      'FROM app';
-    EXECUTE 'GRANT SELECT ON TABLE ' ||
-      'orgunit.tenant_field_policies, ' ||
-      'orgunit.tenant_field_policy_events ' ||
-      'TO app';
-  END IF;
-  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_runtime') THEN
-    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
-      'orgunit.tenant_field_policies, ' ||
-      'orgunit.tenant_field_policy_events ' ||
-      'FROM app_runtime';
-    EXECUTE 'GRANT SELECT ON TABLE ' ||
-      'orgunit.tenant_field_policies, ' ||
-      'orgunit.tenant_field_policy_events ' ||
-      'TO app_runtime';
-  END IF;
   IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_nobypassrls') THEN
-    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
-      'orgunit.tenant_field_policies, ' ||
-      'orgunit.tenant_field_policy_events ' ||
-      'FROM app_nobypassrls';
-    EXECUTE 'GRANT SELECT ON TABLE ' ||
-      'orgunit.tenant_field_policies, ' ||
-      'orgunit.tenant_field_policy_events ' ||
-      'TO app_nobypassrls';
+    EXECUTE 'GRANT USAGE ON SCHEMA person TO app_nobypassrls';
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE, DELETE ON person.persons, person.person_versions TO app_nobypassrls';
+    EXECUTE 'GRANT USAGE, SELECT ON SEQUENCE person.person_versions_id_seq TO app_nobypassrls';
   END IF;
   IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
-    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
-      'orgunit.tenant_field_policies, ' ||
-      'orgunit.tenant_field_policy_events ' ||
-      'FROM superadmin_runtime';
-    EXECUTE 'GRANT SELECT ON TABLE ' ||
-      'orgunit.tenant_field_policies, ' ||
-      'orgunit.tenant_field_policy_events ' ||
-      'TO superadmin_runtime';
+    EXECUTE 'GRANT USAGE ON SCHEMA person TO superadmin_runtime';
+    EXECUTE 'GRANT SELECT ON person.persons, person.person_versions TO superadmin_runtime';
+    EXECUTE 'GRANT EXECUTE ON FUNCTION person.purge_tenant_data(uuid) TO superadmin_runtime';
   END IF;
-END $$;
+END
+$$;
 
--- end: modules/orgunit/infrastructure/persistence/schema/00033_orgunit_field_policies_kernel_privileges.sql
+-- end: modules/person/infrastructure/persistence/schema/00001_person_schema.sql
+
+-- begin: modules/person/infrastructure/persistence/schema/00002_person_tenant_purge_from_catalog.sql
+-- Tenant hard delete purges every person table that carries a tenant_uuid column, found in the catalog the
+-- same way offboarding verification finds them, so a new tenant-scoped table is covered without redefining
+-- this function. Tables go before the tables they reference.
+CREATE OR REPLACE FUNCTION person.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, person, public
+AS $$
+DECLARE
+  v_pending oid[];
+  v_table oid;
+  v_name text;
+  v_progress boolean;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'PERSON_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  SELECT COALESCE(array_agg(c.oid ORDER BY c.relname), '{}'::oid[]) INTO v_pending
+  FROM pg_class c
+  JOIN pg_attribute a ON a.attrelid = c.oid AND a.attname = 'tenant_uuid' AND NOT a.attisdropped
+  WHERE c.relnamespace = 'person'::regnamespace
+    AND c.relkind IN ('r', 'p')
+    AND NOT c.relispartition;
+
+  WHILE cardinality(v_pending) > 0 LOOP
+    v_progress := false;
+    FOREACH v_table IN ARRAY v_pending LOOP
+      CONTINUE WHEN EXISTS (
+        SELECT 1
+        FROM pg_constraint fk
+        WHERE fk.contype = 'f'
+          AND fk.confrelid = v_table
+          AND fk.conrelid <> v_table
+          AND fk.conrelid = ANY (v_pending)
+      );
+      SELECT format('person.%I', relname) INTO v_name FROM pg_class WHERE oid = v_table;
+      EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_name) USING p_tenant_uuid;
+      GET DIAGNOSTICS v_count = ROW_COUNT;
+      v_counts := v_counts || jsonb_build_object(v_name, v_count);
+      v_pending := array_remove(v_pending, v_table);
+      v_progress := true;
+    END LOOP;
+    IF NOT v_progress THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'PERSON_TENANT_PURGE_FK_CYCLE',
+        DETAIL = format('tables=%s', v_pending::regclass[]);
+    END IF;
+  END LOOP;
+
+  RETURN v_counts;
+END;
+$$;
+
+-- end: modules/person/infrastructure/persistence/schema/00002_person_tenant_purge_from_catalog.sql
 
@@ -0,0 +1,245 @@
+package superadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/pagecursor"
+)
+
+const (
+	defaultAuditLogPageSize = 100
+	maxAuditLogPageSize     = 500
+	auditLogExportBatchSize = 500
+	auditLogCursorKind      = "superadmin.audit_logs"
+)
+
+var (
+	errAuditLogFilterInvalid = errors.New("superadmin: invalid audit log filter")
+	errAuditLogCursorInvalid = errors.New("superadmin: invalid audit log cursor")
+)
+
+// superadminAuditFilter narrows iam.superadmin_audit_logs. Since is inclusive and Until exclusive; BeforeID
+// and AfterID are keyset bounds on the log id (pages walk newest first, exports oldest first). CursorScope
+// fingerprints the filter so a page cursor only continues the listing it was issued for.
+type superadminAuditFilter struct {
+	Actor       string
+	Action      string
+	TenantID    string
+	Since       *time.Time
+	Until       *time.Time
+	BeforeID    int64
+	AfterID     int64
+	Limit       int
+	CursorScope string
+}
+
+type superadminAuditEntry struct {
+	ID         int64           `json:"id"`
+	EventID    string          `json:"event_id"`
+	Actor      string          `json:"actor"`
+	ActorEmail string          `json:"actor_email,omitempty"`
+	Action     string          `json:"action"`
+	TenantID   string          `json:"tenant_id,omitempty"`
+	TenantName string          `json:"tenant_name,omitempty"`
+	Payload    json.RawMessage `json:"payload"`
+	RequestID  string          `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// parseAuditLogFilter reads the shared query parameters of the list and export endpoints: actor (principal
+// id or email), action (exact, or a prefix ending in ".*"), tenant_id, since/until (RFC 3339 or YYYY-MM-DD),
+// limit and cursor.
+func parseAuditLogFilter(q url.Values) (superadminAuditFilter, error) {
+	f := superadminAuditFilter{
+		Actor:       strings.TrimSpace(q.Get("actor")),
+		Action:      strings.TrimSpace(q.Get("action")),
+		Limit:       defaultAuditLogPageSize,
+		CursorScope: pagecursor.Scope("superadmin", auditLogCursorKind, q),
+	}
+
+	if v := strings.TrimSpace(q.Get("tenant_id")); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return superadminAuditFilter{}, fmt.Errorf("%w: tenant_id", errAuditLogFilterInvalid)
+		}
+		f.TenantID = id.String()
+	}
+	if f.Action == ".*" || f.Action == "*" {
+		return superadminAuditFilter{}, fmt.Errorf("%w: action", errAuditLogFilterInvalid)
+	}
+
+	for _, field := range []struct {
+		name string
+		dst  **time.Time
+	}{{"since", &f.Since}, {"until", &f.Until}} {
+		v := strings.TrimSpace(q.Get(field.name))
+		if v == "" {
+			continue
+		}
+		t, err := parseAuditLogTime(v)
+		if err != nil {
+			return superadminAuditFilter{}, fmt.Errorf("%w: %s", errAuditLogFilterInvalid, field.name)
+		}
+		*field.dst = &t
+	}
+	if f.Since != nil && f.Until != nil && !f.Since.Before(*f.Until) {
+		return superadminAuditFilter{}, fmt.Errorf("%w: since must be before until", errAuditLogFilterInvalid)
+	}
+
+	if v := strings.TrimSpace(q.Get("limit")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxAuditLogPageSize {
+			return superadminAuditFilter{}, fmt.Errorf("%w: limit", errAuditLogFilterInvalid)
+		}
+		f.Limit = n
+	}
+	if v := strings.TrimSpace(q.Get("cursor")); v != "" {
+		id, err := decodeAuditLogCursor(v, f.CursorScope)
+		if err != nil {
+			return superadminAuditFilter{}, err
+		}
+		f.BeforeID = id
+	}
+	if v := strings.TrimSpace(q.Get("after_id")); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id < 0 {
+			return superadminAuditFilter{}, fmt.Errorf("%w: after_id", errAuditLogFilterInvalid)
+		}
+		f.AfterID = id
+	}
+	return f, nil
+}
+
+func parseAuditLogTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Parse(time.DateOnly, v)
+}
+
+func encodeAuditLogCursor(scope string, id int64) string {
+	return pagecursor.Encode(auditLogCursorKind, scope, id)
+}
+
+func decodeAuditLogCursor(cursor string, scope string) (int64, error) {
+	var id int64
+	if err := pagecursor.Decode(cursor, auditLogCursorKind, scope, &id); err != nil || id <= 0 {
+		return 0, errAuditLogCursorInvalid
+	}
+	return id, nil
+}
+
+func auditLogQuery(f superadminAuditFilter, ascending bool) (string, []any) {
+	var where []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if f.Actor != "" {
+		n := arg(f.Actor)
+		where = append(where, "(l.actor = "+n+" OR p.email = lower("+n+"))")
+	}
+	if prefix, ok := strings.CutSuffix(f.Action, ".*"); ok {
+		where = append(where, "l.action LIKE "+arg(escapeLikePattern(prefix)+".%")+" ESCAPE '\\'")
+	} else if f.Action != "" {
+		where = append(where, "l.action = "+arg(f.Action))
+	}
+	if f.TenantID != "" {
+		where = append(where, "l.target_tenant_uuid = "+arg(f.TenantID)+"::uuid")
+	}
+	if f.Since != nil {
+		where = append(where, "l.created_at >= "+arg(*f.Since))
+	}
+	if f.Until != nil {
+		where = append(where, "l.created_at < "+arg(*f.Until))
+	}
+	if f.BeforeID > 0 {
+		where = append(where, "l.id < "+arg(f.BeforeID))
+	}
+	if f.AfterID > 0 {
+		where = append(where, "l.id > "+arg(f.AfterID))
+	}
+
+	var b strings.Builder
+	b.WriteString(`
+SELECT l.id, l.event_uuid::text, l.actor, COALESCE(p.email, ''), l.action,
+  COALESCE(l.target_tenant_uuid::text, ''), l.target_tenant_name, l.payload, l.request_id, l.created_at
+FROM iam.superadmin_audit_logs l
+LEFT JOIN iam.superadmin_principals p ON p.id::text = l.actor
+`)
+	if len(where) > 0 {
+		b.WriteString("WHERE " + strings.Join(where, "\n  AND ") + "\n")
+	}
+	if ascending {
+		b.WriteString("ORDER BY l.id ASC\n")
+	} else {
+		b.WriteString("ORDER BY l.id DESC\n")
+	}
+	b.WriteString("LIMIT " + arg(f.Limit))
+	return b.String(), args
+}
+
+func escapeLikePattern(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(v)
+}
+
+func queryAuditLogs(ctx context.Context, pool pgBeginner, f superadminAuditFilter, ascending bool) ([]superadminAuditEntry, error) {
+	sql, args := auditLogQuery(f, ascending)
+	rows, err := pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]superadminAuditEntry, 0, f.Limit)
+	for rows.Next() {
+		var e superadminAuditEntry
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.EventID, &e.Actor, &e.ActorEmail, &e.Action, &e.TenantID, &e.TenantName, &payload, &e.RequestID, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Payload = json.RawMessage(payload)
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// listAuditLogs returns one page, newest first, and the cursor of the next page when there is one.
+func listAuditLogs(ctx context.Context, pool pgBeginner, f superadminAuditFilter) ([]superadminAuditEntry, string, error) {
+	page := f
+	page.Limit = f.Limit + 1
+	items, err := queryAuditLogs(ctx, pool, page, false)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(items) <= f.Limit {
+		return items, "", nil
+	}
+	items = items[:f.Limit]
+	return items, encodeAuditLogCursor(f.CursorScope, items[len(items)-1].ID), nil
+}
+
+func auditLogErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, errAuditLogFilterInvalid):
+		return http.StatusBadRequest, "invalid_audit_log_filter"
+	case errors.Is(err, errAuditLogCursorInvalid):
+		return http.StatusBadRequest, "invalid_audit_log_cursor"
+	default:
+		return http.StatusInternalServerError, "db_error"
+	}
+}
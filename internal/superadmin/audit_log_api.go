@@ -0,0 +1,77 @@
+package superadmin
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+)
+
+func handleAuditLogsAPI(w http.ResponseWriter, r *http.Request, pool pgBeginner) {
+	f, err := parseAuditLogFilter(r.URL.Query())
+	if err != nil {
+		status, code := auditLogErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, err.Error())
+		return
+	}
+
+	items, next, err := listAuditLogs(r.Context(), pool, f)
+	if err != nil {
+		status, code := auditLogErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, "")
+		return
+	}
+	resp := map[string]any{"items": items}
+	if next != "" {
+		resp["next_cursor"] = next
+	}
+	writeTenantOffboardJSON(w, http.StatusOK, resp)
+}
+
+// handleAuditLogsExportAPI streams every matching entry as NDJSON, oldest first. Collectors resume from the
+// last id they received with after_id. limit is ignored; entries are read in fixed-size batches.
+func handleAuditLogsExportAPI(w http.ResponseWriter, r *http.Request, pool pgBeginner) {
+	f, err := parseAuditLogFilter(r.URL.Query())
+	if err != nil {
+		status, code := auditLogErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, err.Error())
+		return
+	}
+	f.Limit = auditLogExportBatchSize
+
+	ctx := r.Context()
+	batch, err := queryAuditLogs(ctx, pool, f, true)
+	if err != nil {
+		status, code := auditLogErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, "")
+		return
+	}
+
+	filename := "superadmin-audit-" + time.Now().UTC().Format("20060102T150405Z") + ".ndjson"
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	for {
+		for _, e := range batch {
+			if err := enc.Encode(e); err != nil {
+				return
+			}
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if len(batch) < f.Limit {
+			return
+		}
+		// Headers are already sent, so a failure past the first batch ends the stream; the collector
+		// resumes from the last id it stored.
+		f.AfterID = batch[len(batch)-1].ID
+		if batch, err = queryAuditLogs(ctx, pool, f, true); err != nil {
+			return
+		}
+	}
+}
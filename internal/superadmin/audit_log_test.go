@@ -0,0 +1,228 @@
+package superadmin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/pagecursor"
+)
+
+func auditLogValues(id int64) []any {
+	return []any{id, "ev-1", "actor-1", "sa@example.invalid", "tenant.create", "t1", "Acme", []byte(`{"name":"Acme"}`), "req-1", time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)}
+}
+
+func TestParseAuditLogFilter(t *testing.T) {
+	f, err := parseAuditLogFilter(url.Values{})
+	if err != nil || f.Limit != defaultAuditLogPageSize || f.Since != nil || f.BeforeID != 0 {
+		t.Fatalf("f=%+v err=%v", f, err)
+	}
+
+	q := url.Values{
+		"actor":     {" sa@example.invalid "},
+		"action":    {"tenant.impersonation.*"},
+		"tenant_id": {"00000000-0000-0000-0000-00000000000A"},
+		"since":     {"2026-10-01"},
+		"until":     {"2026-10-19T08:00:00+08:00"},
+		"limit":     {"20"},
+		"after_id":  {"7"},
+	}
+	q.Set("cursor", encodeAuditLogCursor(pagecursor.Scope("superadmin", auditLogCursorKind, q), 42))
+	f, err = parseAuditLogFilter(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Actor != "sa@example.invalid" || f.Action != "tenant.impersonation.*" || f.TenantID != "00000000-0000-0000-0000-00000000000a" ||
+		f.Limit != 20 || f.BeforeID != 42 || f.AfterID != 7 {
+		t.Fatalf("f=%+v", f)
+	}
+	if !f.Since.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) || !f.Until.Equal(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("since=%v until=%v", f.Since, f.Until)
+	}
+
+	for _, tc := range []struct {
+		name string
+		q    url.Values
+		want error
+	}{
+		{"tenant", url.Values{"tenant_id": {"nope"}}, errAuditLogFilterInvalid},
+		{"wildcard only", url.Values{"action": {"*"}}, errAuditLogFilterInvalid},
+		{"since", url.Values{"since": {"yesterday"}}, errAuditLogFilterInvalid},
+		{"range", url.Values{"since": {"2026-10-02"}, "until": {"2026-10-01"}}, errAuditLogFilterInvalid},
+		{"limit", url.Values{"limit": {"501"}}, errAuditLogFilterInvalid},
+		{"after id", url.Values{"after_id": {"-1"}}, errAuditLogFilterInvalid},
+		{"cursor", url.Values{"cursor": {"!!"}}, errAuditLogCursorInvalid},
+		{"cursor for other filter", url.Values{"actor": {"a"}, "cursor": {encodeAuditLogCursor(pagecursor.Scope("superadmin", auditLogCursorKind, url.Values{"actor": {"b"}}), 42)}}, errAuditLogCursorInvalid},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := parseAuditLogFilter(tc.q); !errors.Is(err, tc.want) {
+				t.Fatalf("err=%v", err)
+			}
+		})
+	}
+}
+
+func TestAuditLogCursor(t *testing.T) {
+	scope := pagecursor.Scope("superadmin", auditLogCursorKind, url.Values{"action": {"tenant.create"}})
+	if id, err := decodeAuditLogCursor(encodeAuditLogCursor(scope, 99), scope); err != nil || id != 99 {
+		t.Fatalf("id=%d err=%v", id, err)
+	}
+	other := pagecursor.Scope("superadmin", auditLogCursorKind, url.Values{"action": {"tenant.export"}})
+	for _, c := range []string{"", "bm9wZQ", encodeAuditLogCursor(scope, 0), encodeAuditLogCursor(other, 99)} {
+		if _, err := decodeAuditLogCursor(c, scope); !errors.Is(err, errAuditLogCursorInvalid) {
+			t.Fatalf("cursor %q err=%v", c, err)
+		}
+	}
+}
+
+func TestAuditLogQuery(t *testing.T) {
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	sql, args := auditLogQuery(superadminAuditFilter{
+		Actor:    "actor-1",
+		Action:   "tenant.offboard_%.*",
+		TenantID: "t1",
+		Since:    &since,
+		BeforeID: 50,
+		Limit:    10,
+	}, false)
+	for _, want := range []string{
+		"(l.actor = $1 OR p.email = lower($1))",
+		"l.action LIKE $2 ESCAPE",
+		"l.target_tenant_uuid = $3::uuid",
+		"l.created_at >= $4",
+		"l.id < $5",
+		"ORDER BY l.id DESC",
+		"LIMIT $6",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Fatalf("missing %q in %s", want, sql)
+		}
+	}
+	if len(args) != 6 || args[1] != `tenant.offboard\_\%.%` || args[5] != 10 {
+		t.Fatalf("args=%v", args)
+	}
+
+	sql, args = auditLogQuery(superadminAuditFilter{Action: "tenant.create", AfterID: 3, Limit: 5}, true)
+	if !strings.Contains(sql, "l.action = $1") || !strings.Contains(sql, "l.id > $2") || !strings.Contains(sql, "ORDER BY l.id ASC") || len(args) != 3 {
+		t.Fatalf("sql=%s args=%v", sql, args)
+	}
+}
+
+func TestAuditLogsAPI(t *testing.T) {
+	var gotArgs []any
+	h := newTestHandler(t, stubPool{
+		queryFn: func(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+			if !strings.Contains(sql, "FROM iam.superadmin_audit_logs") {
+				return nil, errors.New("unexpected query")
+			}
+			gotArgs = args
+			return &reflectRows{vals: [][]any{auditLogValues(9), auditLogValues(8), auditLogValues(7)}}, nil
+		},
+		beginFn: func(context.Context) (pgx.Tx, error) { return &stubTx{}, nil },
+	})
+
+	rec := httptest.NewRecorder()
+	h.h.ServeHTTP(rec, h.newRequest(http.MethodGet, "/superadmin/api/audit-logs?action=tenant.create&limit=2", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Items      []superadminAuditEntry `json:"items"`
+		NextCursor string                 `json:"next_cursor"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Items) != 2 || body.Items[1].ID != 8 || string(body.Items[0].Payload) != `{"name":"Acme"}` || body.Items[0].ActorEmail != "sa@example.invalid" || body.Items[0].TenantName != "Acme" {
+		t.Fatalf("items=%+v", body.Items)
+	}
+	scope := pagecursor.Scope("superadmin", auditLogCursorKind, url.Values{"action": {"tenant.create"}})
+	if id, err := decodeAuditLogCursor(body.NextCursor, scope); err != nil || id != 8 {
+		t.Fatalf("cursor=%q id=%d err=%v", body.NextCursor, id, err)
+	}
+	if len(gotArgs) != 2 || gotArgs[1] != 3 {
+		t.Fatalf("args=%v", gotArgs)
+	}
+
+	rec = httptest.NewRecorder()
+	h.h.ServeHTTP(rec, h.newRequest(http.MethodGet, "/superadmin/api/audit-logs?limit=5", nil))
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "next_cursor") {
+		t.Fatalf("last page: status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.h.ServeHTTP(rec, h.newRequest(http.MethodGet, "/superadmin/api/audit-logs?cursor=bad", nil))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid_audit_log_cursor") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAuditLogsAPI_QueryError(t *testing.T) {
+	h := newTestHandler(t, stubPool{
+		queryFn: func(context.Context, string, ...any) (pgx.Rows, error) { return nil, errors.New("boom") },
+		beginFn: func(context.Context) (pgx.Tx, error) { return &stubTx{}, nil },
+	})
+	for _, path := range []string{"/superadmin/api/audit-logs", "/superadmin/api/audit-logs/export"} {
+		rec := httptest.NewRecorder()
+		h.h.ServeHTTP(rec, h.newRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("%s status=%d", path, rec.Code)
+		}
+	}
+}
+
+func TestAuditLogsExportAPI(t *testing.T) {
+	full := make([][]any, auditLogExportBatchSize)
+	for i := range full {
+		full[i] = auditLogValues(int64(i + 1))
+	}
+	var calls [][]any
+	h := newTestHandler(t, stubPool{
+		queryFn: func(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+			if !strings.Contains(sql, "ORDER BY l.id ASC") {
+				return nil, errors.New("export must read oldest first")
+			}
+			calls = append(calls, args)
+			if len(calls) == 1 {
+				return &reflectRows{vals: full}, nil
+			}
+			return &reflectRows{vals: [][]any{auditLogValues(auditLogExportBatchSize + 1)}}, nil
+		},
+		beginFn: func(context.Context) (pgx.Tx, error) { return &stubTx{}, nil },
+	})
+
+	rec := httptest.NewRecorder()
+	h.h.ServeHTTP(rec, h.newRequest(http.MethodGet, "/superadmin/api/audit-logs/export?actor=actor-1", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("status=%d type=%q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var lines int
+	var last superadminAuditEntry
+	sc := bufio.NewScanner(rec.Body)
+	for sc.Scan() {
+		if err := json.Unmarshal(sc.Bytes(), &last); err != nil {
+			t.Fatal(err)
+		}
+		lines++
+	}
+	if lines != auditLogExportBatchSize+1 || last.ID != auditLogExportBatchSize+1 {
+		t.Fatalf("lines=%d last=%d", lines, last.ID)
+	}
+	if len(calls) != 2 || calls[1][1] != int64(auditLogExportBatchSize) {
+		t.Fatalf("calls=%v", len(calls))
+	}
+
+	rec = httptest.NewRecorder()
+	h.h.ServeHTTP(rec, h.newRequest(http.MethodGet, "/superadmin/api/audit-logs/export?since=bad", nil))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid_audit_log_filter") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
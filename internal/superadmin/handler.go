@@ -2,7 +2,6 @@ package superadmin
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"html"
@@ -16,6 +15,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
 	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/authz"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/pagecursor"
 )
 
 func NewHandler() (http.Handler, error) {
@@ -27,6 +27,9 @@ type HandlerOptions struct {
 	IdentityProvider identityProvider
 	Sessions         sessionStore
 	Principals       principalStore
+	DictBaseline     dictBaselinePublisher
+	AdminInviter     tenantAdminInviter
+	OrgUnits         orgUnitWriter
 }
 
 func NewHandlerWithOptions(opts HandlerOptions) (http.Handler, error) {
@@ -52,6 +55,9 @@ func NewHandlerWithOptions(opts HandlerOptions) (http.Handler, error) {
 
 	pool := opts.Pool
 	if pool == nil {
+		if err := pagecursor.RequireSecret(); err != nil {
+			return nil, err
+		}
 		dsn, err := dbDSNFromEnv()
 		if err != nil {
 			return nil, err
@@ -91,6 +97,21 @@ func NewHandlerWithOptions(opts HandlerOptions) (http.Handler, error) {
 		sessions = newSessionStoreFromDB(db)
 	}
 
+	provisioner, err := newTenantProvisioner(pool, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	offboarder, err := newTenantOffboarder(pool)
+	if err != nil {
+		return nil, err
+	}
+
+	impersonator, err := newTenantImpersonator(pool)
+	if err != nil {
+		return nil, err
+	}
+
 	guarded := withBasicAuth(withSuperadminSession(sessions, principals, withAuthz(classifier, authorizer, router)))
 
 	router.Handle(routing.RouteClassUI, http.MethodGet, "/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -171,6 +192,85 @@ func NewHandlerWithOptions(opts HandlerOptions) (http.Handler, error) {
 		handleTenantBindDomain(w, r, pool)
 	}))
 
+	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/superadmin/api/tenants", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantsListAPI(w, r, pool)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/superadmin/api/tenants", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantsCreateAPI(w, r, pool)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/superadmin/api/tenants/{tenant_id}/enable", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantToggleAPI(w, r, pool, true)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/superadmin/api/tenants/{tenant_id}/disable", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantToggleAPI(w, r, pool, false)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/superadmin/api/tenants/{tenant_id}/domains", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantBindDomainAPI(w, r, pool)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/superadmin/api/audit-logs", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleAuditLogsAPI(w, r, pool)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/superadmin/api/audit-logs/export", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleAuditLogsExportAPI(w, r, pool)
+	}))
+
+	router.Handle(routing.RouteClassUI, http.MethodPost, "/superadmin/tenants/provision", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantProvisionForm(w, r, provisioner)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/superadmin/api/tenants/provisioning", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantProvisionAPI(w, r, provisioner)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/superadmin/api/tenants/provisioning/{request_id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantProvisionGetAPI(w, r, provisioner)
+	}))
+
+	router.Handle(routing.RouteClassUI, http.MethodPost, "/superadmin/tenants/{tenant_id}/offboarding", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantOffboardingScheduleForm(w, r, offboarder)
+	}))
+	router.Handle(routing.RouteClassUI, http.MethodPost, "/superadmin/tenants/{tenant_id}/offboarding/cancel", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantOffboardingCancelForm(w, r, offboarder)
+	}))
+	router.Handle(routing.RouteClassUI, http.MethodPost, "/superadmin/tenants/{tenant_id}/offboarding/execute", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantOffboardingExecuteForm(w, r, offboarder)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/superadmin/api/tenants/{tenant_id}/export", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantExportAPI(w, r, offboarder)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/superadmin/api/tenants/{tenant_id}/offboarding", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantOffboardingGetAPI(w, r, offboarder)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/superadmin/api/tenants/{tenant_id}/offboarding", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantOffboardingScheduleAPI(w, r, offboarder)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/superadmin/api/tenants/{tenant_id}/offboarding/cancel", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantOffboardingCancelAPI(w, r, offboarder)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/superadmin/api/tenants/{tenant_id}/offboarding/execute", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantOffboardingExecuteAPI(w, r, offboarder)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/superadmin/api/tenants/{tenant_id}/offboarding/verification", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantOffboardingVerifyAPI(w, r, offboarder)
+	}))
+
+	router.Handle(routing.RouteClassUI, http.MethodPost, "/superadmin/tenants/{tenant_id}/impersonations", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantImpersonationStartForm(w, r, impersonator)
+	}))
+	router.Handle(routing.RouteClassUI, http.MethodPost, "/superadmin/tenants/{tenant_id}/impersonations/{impersonation_id}/end", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantImpersonationEndForm(w, r, impersonator)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/superadmin/api/tenants/{tenant_id}/impersonations", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantImpersonationListAPI(w, r, impersonator)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/superadmin/api/tenants/{tenant_id}/impersonations", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantImpersonationStartAPI(w, r, impersonator)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodPost, "/superadmin/api/tenants/{tenant_id}/impersonations/{impersonation_id}/end", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantImpersonationEndAPI(w, r, impersonator)
+	}))
+	router.Handle(routing.RouteClassInternalAPI, http.MethodGet, "/superadmin/api/tenants/{tenant_id}/impersonations/{impersonation_id}/requests", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleTenantImpersonationRequestsAPI(w, r, impersonator)
+	}))
+
 	mux := http.NewServeMux()
 	mux.Handle("/", guarded)
 	return mux, nil
@@ -261,7 +361,35 @@ func authzRequirementForRoute(method string, path string) (object string, action
 			return authz.ObjectSuperadminTenants, authz.ActionAdmin, true
 		}
 		return "", "", false
+	case "/superadmin/api/tenants":
+		if method == http.MethodGet {
+			return authz.ObjectSuperadminTenants, authz.ActionRead, true
+		}
+		if method == http.MethodPost {
+			return authz.ObjectSuperadminTenants, authz.ActionAdmin, true
+		}
+		return "", "", false
+	case "/superadmin/api/audit-logs", "/superadmin/api/audit-logs/export":
+		if method == http.MethodGet {
+			return authz.ObjectSuperadminTenants, authz.ActionRead, true
+		}
+		return "", "", false
+	case "/superadmin/api/tenants/provisioning":
+		if method == http.MethodPost {
+			return authz.ObjectSuperadminTenants, authz.ActionAdmin, true
+		}
+		return "", "", false
 	default:
+		if strings.HasPrefix(path, "/superadmin/api/tenants/") {
+			// Exports carry the tenant's full data set, so they need admin even though they are reads.
+			if method == http.MethodGet && !strings.HasSuffix(path, "/export") {
+				return authz.ObjectSuperadminTenants, authz.ActionRead, true
+			}
+			if method == http.MethodGet || method == http.MethodPost {
+				return authz.ObjectSuperadminTenants, authz.ActionAdmin, true
+			}
+			return "", "", false
+		}
 		if strings.HasPrefix(path, "/superadmin/tenants/") && method == http.MethodPost {
 			return authz.ObjectSuperadminTenants, authz.ActionAdmin, true
 		}
@@ -314,23 +442,21 @@ func superadminWritesEnabled() bool {
 }
 
 type tenantRow struct {
-	ID          string
-	Name        string
-	IsActive    bool
-	PrimaryHost string
-	OtherHosts  []string
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	IsActive    bool     `json:"is_active"`
+	PrimaryHost string   `json:"primary_host"`
+	OtherHosts  []string `json:"other_hosts"`
 }
 
-func handleTenantsIndex(w http.ResponseWriter, r *http.Request, pool pgBeginner) {
-	ctx := r.Context()
+func listTenants(ctx context.Context, pool pgBeginner) ([]tenantRow, error) {
 	rows, err := pool.Query(ctx, `
 SELECT id::text, name, is_active
 FROM iam.tenants
 ORDER BY created_at ASC, id ASC
 `)
 	if err != nil {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "db_error", "db error")
-		return
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -339,15 +465,13 @@ ORDER BY created_at ASC, id ASC
 	for rows.Next() {
 		var tr tenantRow
 		if err := rows.Scan(&tr.ID, &tr.Name, &tr.IsActive); err != nil {
-			routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "db_error", "db error")
-			return
+			return nil, err
 		}
 		tenants = append(tenants, tr)
 		byID[tr.ID] = len(tenants) - 1
 	}
 	if err := rows.Err(); err != nil {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "db_error", "db error")
-		return
+		return nil, err
 	}
 
 	domainRows, err := pool.Query(ctx, `
@@ -356,8 +480,7 @@ FROM iam.tenant_domains
 ORDER BY is_primary DESC, hostname ASC
 `)
 	if err != nil {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "db_error", "db error")
-		return
+		return nil, err
 	}
 	defer domainRows.Close()
 	for domainRows.Next() {
@@ -365,8 +488,7 @@ ORDER BY is_primary DESC, hostname ASC
 		var hostname string
 		var isPrimary bool
 		if err := domainRows.Scan(&tenantID, &hostname, &isPrimary); err != nil {
-			routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "db_error", "db error")
-			return
+			return nil, err
 		}
 		idx, ok := byID[tenantID]
 		if !ok {
@@ -380,6 +502,14 @@ ORDER BY is_primary DESC, hostname ASC
 		}
 	}
 	if err := domainRows.Err(); err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
+
+func handleTenantsIndex(w http.ResponseWriter, r *http.Request, pool pgBeginner) {
+	tenants, err := listTenants(r.Context(), pool)
+	if err != nil {
 		routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "db_error", "db error")
 		return
 	}
@@ -393,6 +523,17 @@ ORDER BY is_primary DESC, hostname ASC
 	b.WriteString(`<div><button type="submit">Create</button></div>`)
 	b.WriteString(`</form>`)
 
+	b.WriteString("<h2>Provision tenant</h2>")
+	b.WriteString(`<form method="POST" action="/superadmin/tenants/provision">`)
+	b.WriteString(`<div><label>Name <input name="name" /></label></div>`)
+	b.WriteString(`<div><label>Primary Hostname <input name="hostname" placeholder="example.local" /></label></div>`)
+	b.WriteString(`<div><label>First Admin Email <input name="admin_email" type="email" /></label></div>`)
+	b.WriteString(`<div><label>As Of <input name="as_of" type="date" /></label></div>`)
+	b.WriteString(`<div><label>Root Org Code (optional) <input name="root_org_code" /></label></div>`)
+	b.WriteString(`<div><label>Root Org Name <input name="root_org_name" /></label></div>`)
+	b.WriteString(`<div><button type="submit">Provision</button></div>`)
+	b.WriteString(`</form>`)
+
 	b.WriteString("<h2>Existing tenants</h2>")
 	if len(tenants) == 0 {
 		b.WriteString("<p>(none)</p>")
@@ -428,6 +569,19 @@ ORDER BY is_primary DESC, hostname ASC
 		b.WriteString(fmt.Sprintf(`<form method="POST" action="/superadmin/tenants/%s/domains">`, html.EscapeString(t.ID)))
 		b.WriteString(`<input name="hostname" placeholder="add hostname" /> <button type="submit">Bind Domain</button>`)
 		b.WriteString(`</form>`)
+		b.WriteString(fmt.Sprintf(`<div><a href="/superadmin/api/tenants/%s/export">Export data</a></div>`, html.EscapeString(t.ID)))
+		if t.IsActive {
+			b.WriteString(fmt.Sprintf(`<form method="POST" action="/superadmin/tenants/%s/impersonations">`, html.EscapeString(t.ID)))
+			b.WriteString(`<input name="principal_email" type="email" placeholder="principal email" /> <input name="reason" placeholder="support reason" /> `)
+			b.WriteString(`<label><input name="allow_writes" type="checkbox" /> allow writes</label> <button type="submit">Impersonate</button>`)
+			b.WriteString(`</form>`)
+			b.WriteString(fmt.Sprintf(`<div><a href="/superadmin/api/tenants/%s/impersonations">Impersonation history</a></div>`, html.EscapeString(t.ID)))
+		}
+		if !t.IsActive {
+			b.WriteString(fmt.Sprintf(`<form method="POST" action="/superadmin/tenants/%s/offboarding">`, html.EscapeString(t.ID)))
+			b.WriteString(`<input name="reason" placeholder="deletion reason" /> <button type="submit">Schedule Deletion</button>`)
+			b.WriteString(`</form>`)
+		}
 		b.WriteString("</td>")
 		b.WriteString("</tr>")
 	}
@@ -452,52 +606,10 @@ func handleTenantsCreate(w http.ResponseWriter, r *http.Request, pool pgBeginner
 		routing.WriteError(w, r, routing.RouteClassUI, http.StatusBadRequest, "bad_request", "bad request")
 		return
 	}
-	name := strings.TrimSpace(r.FormValue("name"))
-	hostname := strings.ToLower(strings.TrimSpace(r.FormValue("hostname")))
-	hostname = strings.TrimSpace(hostname)
-	if name == "" || hostname == "" {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusBadRequest, "invalid_input", "invalid input")
-		return
-	}
-	if strings.Contains(hostname, ":") || strings.ContainsAny(hostname, " \t\r\n") {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusBadRequest, "invalid_hostname", "invalid hostname")
-		return
-	}
 
-	ctx := r.Context()
-	tx, err := pool.Begin(ctx)
-	if err != nil {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "db_error", "db error")
-		return
-	}
-	defer func() { _ = tx.Rollback(context.Background()) }()
-
-	var tenantID string
-	if err := tx.QueryRow(ctx, `
-INSERT INTO iam.tenants(name, is_active)
-VALUES ($1, true)
-RETURNING id::text
-`, name).Scan(&tenantID); err != nil {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "db_error", "db error")
-		return
-	}
-
-	if _, err := tx.Exec(ctx, `
-INSERT INTO iam.tenant_domains(tenant_uuid, hostname, is_primary)
-VALUES ($1::uuid, $2, true)
-`, tenantID, hostname); err != nil {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "db_error", "db error")
-		return
-	}
-
-	payload, _ := json.Marshal(map[string]any{"name": name, "hostname": hostname})
-	if err := insertAudit(ctx, tx, p.ID, "tenant.create", tenantID, payload, requestID(r)); err != nil {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "audit_error", "audit error")
-		return
-	}
-
-	if err := tx.Commit(ctx); err != nil {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "db_error", "db error")
+	if _, err := createTenant(r.Context(), pool, p.ID, r.FormValue("name"), r.FormValue("hostname"), requestID(r)); err != nil {
+		status, code := tenantConsoleErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassUI, status, code, "")
 		return
 	}
 
@@ -522,35 +634,9 @@ func handleTenantToggle(w http.ResponseWriter, r *http.Request, pool pgBeginner,
 		return
 	}
 
-	ctx := r.Context()
-	tx, err := pool.Begin(ctx)
-	if err != nil {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "db_error", "db error")
-		return
-	}
-	defer func() { _ = tx.Rollback(context.Background()) }()
-
-	if _, err := tx.Exec(ctx, `
-UPDATE iam.tenants
-SET is_active = $2, updated_at = now()
-WHERE id = $1::uuid
-`, tenantID, enable); err != nil {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "db_error", "db error")
-		return
-	}
-
-	action := "tenant.disable"
-	if enable {
-		action = "tenant.enable"
-	}
-	payload, _ := json.Marshal(map[string]any{"enable": enable})
-	if err := insertAudit(ctx, tx, p.ID, action, tenantID, payload, requestID(r)); err != nil {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "audit_error", "audit error")
-		return
-	}
-
-	if err := tx.Commit(ctx); err != nil {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "db_error", "db error")
+	if err := setTenantActive(r.Context(), pool, p.ID, tenantID, enable, requestID(r)); err != nil {
+		status, code := tenantConsoleErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassUI, status, code, "")
 		return
 	}
 	http.Redirect(w, r, "/superadmin/tenants", http.StatusFound)
@@ -577,37 +663,10 @@ func handleTenantBindDomain(w http.ResponseWriter, r *http.Request, pool pgBegin
 		routing.WriteError(w, r, routing.RouteClassUI, http.StatusBadRequest, "bad_request", "bad request")
 		return
 	}
-	hostname := strings.ToLower(strings.TrimSpace(r.FormValue("hostname")))
-	hostname = strings.TrimSpace(hostname)
-	if hostname == "" || strings.Contains(hostname, ":") || strings.ContainsAny(hostname, " \t\r\n") {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusBadRequest, "invalid_hostname", "invalid hostname")
-		return
-	}
-
-	ctx := r.Context()
-	tx, err := pool.Begin(ctx)
-	if err != nil {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "db_error", "db error")
-		return
-	}
-	defer func() { _ = tx.Rollback(context.Background()) }()
 
-	if _, err := tx.Exec(ctx, `
-INSERT INTO iam.tenant_domains(tenant_uuid, hostname, is_primary)
-VALUES ($1::uuid, $2, false)
-`, tenantID, hostname); err != nil {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "db_error", "db error")
-		return
-	}
-
-	payload, _ := json.Marshal(map[string]any{"hostname": hostname})
-	if err := insertAudit(ctx, tx, p.ID, "tenant.domain.bind", tenantID, payload, requestID(r)); err != nil {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "audit_error", "audit error")
-		return
-	}
-
-	if err := tx.Commit(ctx); err != nil {
-		routing.WriteError(w, r, routing.RouteClassUI, http.StatusInternalServerError, "db_error", "db error")
+	if _, err := bindTenantDomain(r.Context(), pool, p.ID, tenantID, r.FormValue("hostname"), requestID(r)); err != nil {
+		status, code := tenantConsoleErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassUI, status, code, "")
 		return
 	}
 	http.Redirect(w, r, "/superadmin/tenants", http.StatusFound)
@@ -632,9 +691,16 @@ func insertAudit(ctx context.Context, tx pgx.Tx, actor string, action string, te
 	if payload == nil {
 		payload = []byte(`{}`)
 	}
+	// The tenant name is copied onto the row so the entry still reads after the tenant is hard-deleted; a
+	// delete is audited after the purge, when only the offboarding request still knows the name.
 	_, err := tx.Exec(ctx, `
-INSERT INTO iam.superadmin_audit_logs(actor, action, target_tenant_uuid, payload, request_id)
-VALUES ($1, $2, $3::uuid, $4::jsonb, $5)
+INSERT INTO iam.superadmin_audit_logs(actor, action, target_tenant_uuid, target_tenant_name, payload, request_id)
+SELECT $1, $2, target.id, COALESCE(
+  (SELECT t.name FROM iam.tenants t WHERE t.id = target.id),
+  (SELECT r.tenant_name FROM iam.superadmin_tenant_offboarding_requests r WHERE r.tenant_uuid = target.id ORDER BY r.id DESC LIMIT 1),
+  ''
+), $4::jsonb, $5
+FROM (SELECT NULLIF($3, '')::uuid AS id) target
 `, actor, action, tenantID, payload, reqID)
 	return err
 }
@@ -2,6 +2,7 @@ package superadmin
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/pagecursor"
 )
 
 func TestNewHandler_DefaultAllowlistNotFound(t *testing.T) {
@@ -162,6 +164,20 @@ func TestNewHandlerWithOptions_PoolNil_BadDSN(t *testing.T) {
 	}
 }
 
+func TestNewHandlerWithOptions_PoolNil_RequiresCursorSecret(t *testing.T) {
+	allowlist, err := defaultAllowlistPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ALLOWLIST_PATH", allowlist)
+	t.Setenv("SUPERADMIN_DATABASE_URL", "postgres://u:p@localhost:5432/db?sslmode=disable")
+	t.Setenv("CURSOR_SIGNING_SECRET", "")
+
+	if _, err := NewHandlerWithOptions(HandlerOptions{}); !errors.Is(err, pagecursor.ErrSecretMissing) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
 func TestNewHandlerWithOptions_PoolNil_Success(t *testing.T) {
 	allowlist, err := defaultAllowlistPath()
 	if err != nil {
@@ -171,6 +187,7 @@ func TestNewHandlerWithOptions_PoolNil_Success(t *testing.T) {
 	t.Setenv("SUPERADMIN_DATABASE_URL", "postgres://u:p@localhost:5432/db?sslmode=disable")
 	t.Setenv("SUPERADMIN_BASIC_AUTH_USER", "admin")
 	t.Setenv("SUPERADMIN_BASIC_AUTH_PASS", "admin")
+	t.Setenv("CURSOR_SIGNING_SECRET", "test-cursor-secret")
 
 	h, err := NewHandlerWithOptions(HandlerOptions{})
 	if err != nil {
@@ -219,6 +236,7 @@ func TestMustNewHandler_Success(t *testing.T) {
 	t.Setenv("SUPERADMIN_DATABASE_URL", "postgres://u:p@localhost:5432/db?sslmode=disable")
 	t.Setenv("SUPERADMIN_BASIC_AUTH_USER", "admin")
 	t.Setenv("SUPERADMIN_BASIC_AUTH_PASS", "admin")
+	t.Setenv("CURSOR_SIGNING_SECRET", "test-cursor-secret")
 
 	h := MustNewHandler()
 	if h == nil {
@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/authz"
 )
 
 func TestRequestID(t *testing.T) {
@@ -97,4 +99,22 @@ func TestAuthzRequirementForRoute(t *testing.T) {
 	if _, _, ok := authzRequirementForRoute(http.MethodGet, "/superadmin/tenants/t1/disable"); ok {
 		t.Fatal("expected no check")
 	}
+
+	if _, action, ok := authzRequirementForRoute(http.MethodGet, "/superadmin/api/tenants"); !ok || action != authz.ActionRead {
+		t.Fatalf("ok=%v action=%q", ok, action)
+	}
+	if _, action, ok := authzRequirementForRoute(http.MethodPost, "/superadmin/api/tenants"); !ok || action != authz.ActionAdmin {
+		t.Fatalf("ok=%v action=%q", ok, action)
+	}
+	if _, _, ok := authzRequirementForRoute(http.MethodDelete, "/superadmin/api/tenants"); ok {
+		t.Fatal("expected no check")
+	}
+	for _, path := range []string{"/superadmin/api/audit-logs", "/superadmin/api/audit-logs/export"} {
+		if _, action, ok := authzRequirementForRoute(http.MethodGet, path); !ok || action != authz.ActionRead {
+			t.Fatalf("%s ok=%v action=%q", path, ok, action)
+		}
+		if _, _, ok := authzRequirementForRoute(http.MethodPost, path); ok {
+			t.Fatalf("%s expected no check", path)
+		}
+	}
 }
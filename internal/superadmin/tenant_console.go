@@ -0,0 +1,150 @@
+package superadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	errTenantInvalidInput    = errors.New("superadmin: tenant name and hostname are required")
+	errTenantInvalidHostname = errors.New("superadmin: invalid tenant hostname")
+	errTenantAudit           = errors.New("superadmin: tenant audit failed")
+)
+
+// normalizeTenantHostname lowercases a hostname and rejects ports and whitespace; tenants are resolved by
+// bare host only.
+func normalizeTenantHostname(raw string) (string, error) {
+	hostname := strings.ToLower(strings.TrimSpace(raw))
+	if hostname == "" || strings.Contains(hostname, ":") || strings.ContainsAny(hostname, " \t\r\n") {
+		return "", errTenantInvalidHostname
+	}
+	return hostname, nil
+}
+
+func createTenant(ctx context.Context, pool pgBeginner, actor string, name string, hostname string, reqID string) (tenantRow, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.TrimSpace(hostname) == "" {
+		return tenantRow{}, errTenantInvalidInput
+	}
+	hostname, err := normalizeTenantHostname(hostname)
+	if err != nil {
+		return tenantRow{}, err
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return tenantRow{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	var tenantID string
+	if err := tx.QueryRow(ctx, `
+INSERT INTO iam.tenants(name, is_active)
+VALUES ($1, true)
+RETURNING id::text
+`, name).Scan(&tenantID); err != nil {
+		return tenantRow{}, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+INSERT INTO iam.tenant_domains(tenant_uuid, hostname, is_primary)
+VALUES ($1::uuid, $2, true)
+`, tenantID, hostname); err != nil {
+		return tenantRow{}, err
+	}
+
+	payload, _ := json.Marshal(map[string]any{"name": name, "hostname": hostname})
+	if err := insertAudit(ctx, tx, actor, "tenant.create", tenantID, payload, reqID); err != nil {
+		return tenantRow{}, fmt.Errorf("%w: %w", errTenantAudit, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return tenantRow{}, err
+	}
+	return tenantRow{ID: tenantID, Name: name, IsActive: true, PrimaryHost: hostname, OtherHosts: []string{}}, nil
+}
+
+func setTenantActive(ctx context.Context, pool pgBeginner, actor string, tenantID string, enable bool, reqID string) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if _, err := tx.Exec(ctx, `
+UPDATE iam.tenants
+SET is_active = $2, updated_at = now()
+WHERE id = $1::uuid
+`, tenantID, enable); err != nil {
+		return err
+	}
+
+	action := "tenant.disable"
+	if enable {
+		action = "tenant.enable"
+	}
+	payload, _ := json.Marshal(map[string]any{"enable": enable})
+	if err := insertAudit(ctx, tx, actor, action, tenantID, payload, reqID); err != nil {
+		return fmt.Errorf("%w: %w", errTenantAudit, err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func bindTenantDomain(ctx context.Context, pool pgBeginner, actor string, tenantID string, hostname string, reqID string) (string, error) {
+	hostname, err := normalizeTenantHostname(hostname)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if _, err := tx.Exec(ctx, `
+INSERT INTO iam.tenant_domains(tenant_uuid, hostname, is_primary)
+VALUES ($1::uuid, $2, false)
+`, tenantID, hostname); err != nil {
+		return "", err
+	}
+
+	payload, _ := json.Marshal(map[string]any{"hostname": hostname})
+	if err := insertAudit(ctx, tx, actor, "tenant.domain.bind", tenantID, payload, reqID); err != nil {
+		return "", fmt.Errorf("%w: %w", errTenantAudit, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return hostname, nil
+}
+
+func tenantConsoleErrorStatus(err error) (int, string) {
+	// The audit row references the tenant, so a missing tenant surfaces as a foreign key violation there.
+	if pgErr, ok := errors.AsType[*pgconn.PgError](err); ok && pgErr != nil {
+		switch pgErr.Code {
+		case "23505":
+			return http.StatusConflict, "tenant_domain_conflict"
+		case "23503":
+			return http.StatusNotFound, "tenant_not_found"
+		}
+	}
+	switch {
+	case errors.Is(err, errTenantInvalidInput):
+		return http.StatusBadRequest, "invalid_input"
+	case errors.Is(err, errTenantInvalidHostname):
+		return http.StatusBadRequest, "invalid_hostname"
+	case errors.Is(err, errTenantAudit):
+		return http.StatusInternalServerError, "audit_error"
+	default:
+		return http.StatusInternalServerError, "db_error"
+	}
+}
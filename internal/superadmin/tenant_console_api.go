@@ -0,0 +1,91 @@
+package superadmin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+)
+
+type tenantCreatePayload struct {
+	Name     string `json:"name"`
+	Hostname string `json:"hostname"`
+}
+
+type tenantDomainPayload struct {
+	Hostname string `json:"hostname"`
+}
+
+func handleTenantsListAPI(w http.ResponseWriter, r *http.Request, pool pgBeginner) {
+	tenants, err := listTenants(r.Context(), pool)
+	if err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusInternalServerError, "db_error", "db error")
+		return
+	}
+	for i := range tenants {
+		if tenants[i].OtherHosts == nil {
+			tenants[i].OtherHosts = []string{}
+		}
+	}
+	writeTenantOffboardJSON(w, http.StatusOK, map[string]any{"tenants": tenants})
+}
+
+func handleTenantsCreateAPI(w http.ResponseWriter, r *http.Request, pool pgBeginner) {
+	if !superadminWritesEnabled() {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusForbidden, "write_disabled", "write disabled")
+		return
+	}
+	p, ok := principalFromContext(r.Context())
+	if !ok || p.ID == "" {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnauthorized, "unauthorized", "unauthorized")
+		return
+	}
+
+	var req tenantCreatePayload
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_json", "bad json")
+		return
+	}
+
+	tenant, err := createTenant(r.Context(), pool, p.ID, req.Name, req.Hostname, requestID(r))
+	if err != nil {
+		status, code := tenantConsoleErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, "")
+		return
+	}
+	writeTenantOffboardJSON(w, http.StatusCreated, tenant)
+}
+
+func handleTenantToggleAPI(w http.ResponseWriter, r *http.Request, pool pgBeginner, enable bool) {
+	p, tenantID, ok := tenantOffboardWritePreamble(w, r, routing.RouteClassInternalAPI)
+	if !ok {
+		return
+	}
+	if err := setTenantActive(r.Context(), pool, p.ID, tenantID, enable, requestID(r)); err != nil {
+		status, code := tenantConsoleErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, "")
+		return
+	}
+	writeTenantOffboardJSON(w, http.StatusOK, map[string]any{"tenant_id": tenantID, "is_active": enable})
+}
+
+func handleTenantBindDomainAPI(w http.ResponseWriter, r *http.Request, pool pgBeginner) {
+	p, tenantID, ok := tenantOffboardWritePreamble(w, r, routing.RouteClassInternalAPI)
+	if !ok {
+		return
+	}
+
+	var req tenantDomainPayload
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_json", "bad json")
+		return
+	}
+
+	hostname, err := bindTenantDomain(r.Context(), pool, p.ID, tenantID, req.Hostname, requestID(r))
+	if err != nil {
+		status, code := tenantConsoleErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, "")
+		return
+	}
+	writeTenantOffboardJSON(w, http.StatusCreated, map[string]any{"tenant_id": tenantID, "hostname": hostname, "is_primary": false})
+}
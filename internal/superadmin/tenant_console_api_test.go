@@ -0,0 +1,177 @@
+package superadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTenantsListAPI(t *testing.T) {
+	h := newTestHandler(t, stubPool{
+		queryFn: func(_ context.Context, sql string, _ ...any) (pgx.Rows, error) {
+			if strings.Contains(sql, "FROM iam.tenants") {
+				return &stubRows{vals: [][]any{{"t1", "Tenant 1", true}, {"t2", "Tenant 2", false}}}, nil
+			}
+			return &stubRows{vals: [][]any{{"t1", "a.local", true}, {"t1", "b.local", false}}}, nil
+		},
+		beginFn: func(context.Context) (pgx.Tx, error) { return &stubTx{}, nil },
+	})
+
+	rec := httptest.NewRecorder()
+	h.h.ServeHTTP(rec, h.newRequest(http.MethodGet, "/superadmin/api/tenants", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Tenants []tenantRow `json:"tenants"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Tenants) != 2 || body.Tenants[0].PrimaryHost != "a.local" || len(body.Tenants[0].OtherHosts) != 1 || body.Tenants[1].IsActive {
+		t.Fatalf("tenants=%+v", body.Tenants)
+	}
+	if !strings.Contains(rec.Body.String(), `"other_hosts":[]`) {
+		t.Fatalf("empty hosts should encode as []: %s", rec.Body.String())
+	}
+}
+
+func TestTenantsListAPI_QueryError(t *testing.T) {
+	h := newTestHandler(t, stubPool{
+		queryFn: func(context.Context, string, ...any) (pgx.Rows, error) { return nil, errors.New("boom") },
+		beginFn: func(context.Context) (pgx.Tx, error) { return &stubTx{}, nil },
+	})
+	rec := httptest.NewRecorder()
+	h.h.ServeHTTP(rec, h.newRequest(http.MethodGet, "/superadmin/api/tenants", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status=%d", rec.Code)
+	}
+}
+
+func TestTenantsCreateAPI(t *testing.T) {
+	newHandler := func(t *testing.T, tx *stubTx) authedHandler {
+		return newTestHandler(t, stubPool{
+			beginFn: func(context.Context) (pgx.Tx, error) { return tx, nil },
+			queryFn: func(context.Context, string, ...any) (pgx.Rows, error) { return &stubRows{}, nil },
+		})
+	}
+	okRow := func(string, ...any) pgx.Row { return stubRow{vals: []any{"t1"}} }
+
+	t.Run("created", func(t *testing.T) {
+		tx := &stubTx{queryRowFn: okRow}
+		h := newHandler(t, tx)
+		rec := httptest.NewRecorder()
+		h.h.ServeHTTP(rec, h.newRequest(http.MethodPost, "/superadmin/api/tenants", strings.NewReader(`{"name":" Acme ","hostname":"Acme.Local"}`)))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+		}
+		var got tenantRow
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != "t1" || got.Name != "Acme" || got.PrimaryHost != "acme.local" || !got.IsActive {
+			t.Fatalf("tenant=%+v", got)
+		}
+		if len(tx.execSQLs) != 2 || !strings.Contains(tx.execSQLs[1], "iam.superadmin_audit_logs") {
+			t.Fatalf("execs=%v", tx.execSQLs)
+		}
+	})
+
+	cases := []struct {
+		name   string
+		body   string
+		tx     *stubTx
+		status int
+		code   string
+	}{
+		{name: "bad json", body: `{`, tx: &stubTx{}, status: http.StatusBadRequest, code: "bad_json"},
+		{name: "missing name", body: `{"hostname":"a.local"}`, tx: &stubTx{}, status: http.StatusBadRequest, code: "invalid_input"},
+		{name: "bad hostname", body: `{"name":"A","hostname":"a.local:8080"}`, tx: &stubTx{}, status: http.StatusBadRequest, code: "invalid_hostname"},
+		{name: "hostname taken", body: `{"name":"A","hostname":"a.local"}`, tx: &stubTx{queryRowFn: okRow, execErrAt: 1, execErr: &pgconn.PgError{Code: "23505"}}, status: http.StatusConflict, code: "tenant_domain_conflict"},
+		{name: "audit error", body: `{"name":"A","hostname":"a.local"}`, tx: &stubTx{queryRowFn: okRow, execErrAt: 2, execErr: errors.New("boom")}, status: http.StatusInternalServerError, code: "audit_error"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newHandler(t, tc.tx)
+			rec := httptest.NewRecorder()
+			h.h.ServeHTTP(rec, h.newRequest(http.MethodPost, "/superadmin/api/tenants", strings.NewReader(tc.body)))
+			if rec.Code != tc.status || !strings.Contains(rec.Body.String(), tc.code) {
+				t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	t.Run("write disabled", func(t *testing.T) {
+		t.Setenv("SUPERADMIN_WRITE_MODE", "disabled")
+		h := newHandler(t, &stubTx{})
+		rec := httptest.NewRecorder()
+		h.h.ServeHTTP(rec, h.newRequest(http.MethodPost, "/superadmin/api/tenants", strings.NewReader(`{}`)))
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+}
+
+func TestTenantToggleAndBindDomainAPI(t *testing.T) {
+	newHandler := func(t *testing.T, tx *stubTx) authedHandler {
+		return newTestHandler(t, stubPool{
+			beginFn: func(context.Context) (pgx.Tx, error) { return tx, nil },
+			queryFn: func(context.Context, string, ...any) (pgx.Rows, error) { return &stubRows{}, nil },
+		})
+	}
+
+	t.Run("disable", func(t *testing.T) {
+		tx := &stubTx{}
+		h := newHandler(t, tx)
+		rec := httptest.NewRecorder()
+		h.h.ServeHTTP(rec, h.newRequest(http.MethodPost, "/superadmin/api/tenants/t1/disable", nil))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"is_active":false`) {
+			t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("enable unknown tenant", func(t *testing.T) {
+		tx := &stubTx{execErrAt: 2, execErr: &pgconn.PgError{Code: "23503"}}
+		h := newHandler(t, tx)
+		rec := httptest.NewRecorder()
+		h.h.ServeHTTP(rec, h.newRequest(http.MethodPost, "/superadmin/api/tenants/t9/enable", nil))
+		if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "tenant_not_found") {
+			t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("bind domain", func(t *testing.T) {
+		tx := &stubTx{}
+		h := newHandler(t, tx)
+		rec := httptest.NewRecorder()
+		h.h.ServeHTTP(rec, h.newRequest(http.MethodPost, "/superadmin/api/tenants/t1/domains", strings.NewReader(`{"hostname":" B.Local "}`)))
+		if rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), `"hostname":"b.local"`) {
+			t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("bind domain bad json", func(t *testing.T) {
+		h := newHandler(t, &stubTx{})
+		rec := httptest.NewRecorder()
+		h.h.ServeHTTP(rec, h.newRequest(http.MethodPost, "/superadmin/api/tenants/t1/domains", strings.NewReader(`nope`)))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+
+	t.Run("bind domain taken", func(t *testing.T) {
+		h := newHandler(t, &stubTx{execErrAt: 1, execErr: &pgconn.PgError{Code: "23505"}})
+		rec := httptest.NewRecorder()
+		h.h.ServeHTTP(rec, h.newRequest(http.MethodPost, "/superadmin/api/tenants/t1/domains", strings.NewReader(`{"hostname":"b.local"}`)))
+		if rec.Code != http.StatusConflict {
+			t.Fatalf("status=%d", rec.Code)
+		}
+	})
+}
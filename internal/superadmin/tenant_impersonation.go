@@ -0,0 +1,410 @@
+package superadmin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultImpersonationTTL   = 30 * time.Minute
+	maxImpersonationTTL       = 4 * time.Hour
+	impersonationHandoffTTL   = 2 * time.Minute
+	defaultTenantAppURL       = "http://{host}:8080"
+	impersonationAcceptPath   = "/iam/impersonation/accept"
+	impersonationRequestLimit = 500
+)
+
+var (
+	errImpersonationTenantNotFound    = errors.New("superadmin: tenant not found")
+	errImpersonationTenantInactive    = errors.New("superadmin: tenant is not active")
+	errImpersonationTenantNoDomain    = errors.New("superadmin: tenant has no domain")
+	errImpersonationPrincipalNotFound = errors.New("superadmin: principal not found")
+	errImpersonationPrincipalInactive = errors.New("superadmin: principal is not active")
+	errImpersonationReasonRequired    = errors.New("superadmin: impersonation reason is required")
+	errImpersonationInvalidTTL        = errors.New("superadmin: invalid impersonation ttl")
+	errImpersonationNotFound          = errors.New("superadmin: impersonation not found")
+	errImpersonationEnded             = errors.New("superadmin: impersonation already ended")
+)
+
+type tenantImpersonationRequest struct {
+	PrincipalID    string `json:"principal_id"`
+	PrincipalEmail string `json:"principal_email"`
+	Reason         string `json:"reason"`
+	ReadOnly       *bool  `json:"read_only"`
+	TTLMinutes     int    `json:"ttl_minutes"`
+}
+
+type tenantImpersonation struct {
+	ID               string     `json:"impersonation_id"`
+	TenantID         string     `json:"tenant_id"`
+	PrincipalID      string     `json:"principal_id"`
+	PrincipalEmail   string     `json:"principal_email"`
+	SuperadminActor  string     `json:"superadmin_actor"`
+	SuperadminEmail  string     `json:"superadmin_email"`
+	Reason           string     `json:"reason"`
+	ReadOnly         bool       `json:"read_only"`
+	HandoffExpiresAt time.Time  `json:"handoff_expires_at"`
+	AcceptedAt       *time.Time `json:"accepted_at,omitempty"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	EndedAt          *time.Time `json:"ended_at,omitempty"`
+	EndedBy          string     `json:"ended_by,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	RequestCount     int64      `json:"request_count"`
+	// HandoffURL is only returned when the impersonation is started; only the ticket hash is stored.
+	HandoffURL string `json:"handoff_url,omitempty"`
+}
+
+type tenantImpersonationRequestLog struct {
+	ID          int64      `json:"id"`
+	Method      string     `json:"method"`
+	Path        string     `json:"path"`
+	RequestID   string     `json:"request_id"`
+	StatusCode  *int       `json:"status_code,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+type tenantImpersonator struct {
+	pool      pgBeginner
+	ttl       time.Duration
+	appURL    string
+	now       func() time.Time
+	newTicket func() (string, error)
+}
+
+func newTenantImpersonator(pool pgBeginner) (*tenantImpersonator, error) {
+	ttl, err := impersonationTTLFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	appURL := strings.TrimSpace(os.Getenv("SUPERADMIN_TENANT_APP_URL"))
+	if appURL == "" {
+		appURL = defaultTenantAppURL
+	}
+	if !strings.Contains(appURL, "{host}") {
+		return nil, errors.New("superadmin: SUPERADMIN_TENANT_APP_URL must contain {host}")
+	}
+	return &tenantImpersonator{
+		pool:      pool,
+		ttl:       ttl,
+		appURL:    strings.TrimRight(appURL, "/"),
+		now:       time.Now,
+		newTicket: func() (string, error) { return randomURLToken(32) },
+	}, nil
+}
+
+func impersonationTTLFromEnv() (time.Duration, error) {
+	raw := strings.TrimSpace(os.Getenv("SUPERADMIN_IMPERSONATION_TTL"))
+	if raw == "" {
+		return defaultImpersonationTTL, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 || d > maxImpersonationTTL {
+		return 0, errors.New("superadmin: invalid SUPERADMIN_IMPERSONATION_TTL")
+	}
+	return d, nil
+}
+
+// Start issues a time-boxed impersonation of a tenant principal. The returned handoff URL carries a one-time
+// ticket that the tenant server exchanges for a session marked as impersonated; it expires within minutes.
+func (m *tenantImpersonator) Start(ctx context.Context, actor superadminPrincipal, tenantID string, req tenantImpersonationRequest, reqID string) (tenantImpersonation, error) {
+	if _, err := uuid.Parse(tenantID); err != nil {
+		return tenantImpersonation{}, errImpersonationTenantNotFound
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return tenantImpersonation{}, errImpersonationReasonRequired
+	}
+	ttl := m.ttl
+	if req.TTLMinutes != 0 {
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+		if req.TTLMinutes < 0 || ttl > maxImpersonationTTL {
+			return tenantImpersonation{}, errImpersonationInvalidTTL
+		}
+	}
+	readOnly := true
+	if req.ReadOnly != nil {
+		readOnly = *req.ReadOnly
+	}
+
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return tenantImpersonation{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	var active bool
+	var host string
+	if err := tx.QueryRow(ctx, `
+SELECT t.is_active, COALESCE((
+  SELECT d.hostname FROM iam.tenant_domains d
+  WHERE d.tenant_uuid = t.id
+  ORDER BY d.is_primary DESC, d.hostname ASC
+  LIMIT 1
+), '')
+FROM iam.tenants t
+WHERE t.id = $1::uuid
+`, tenantID).Scan(&active, &host); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return tenantImpersonation{}, errImpersonationTenantNotFound
+		}
+		return tenantImpersonation{}, err
+	}
+	if !active {
+		return tenantImpersonation{}, errImpersonationTenantInactive
+	}
+	if host == "" {
+		return tenantImpersonation{}, errImpersonationTenantNoDomain
+	}
+
+	out := tenantImpersonation{
+		TenantID:        tenantID,
+		SuperadminActor: actor.ID,
+		SuperadminEmail: actor.Email,
+		Reason:          reason,
+		ReadOnly:        readOnly,
+	}
+	var status string
+	if err := tx.QueryRow(ctx, `
+SELECT id::text, email, status
+FROM iam.principals
+WHERE tenant_uuid = $1::uuid
+  AND (id::text = $2 OR email = $3)
+ORDER BY (id::text = $2) DESC
+LIMIT 1
+`, tenantID, strings.TrimSpace(req.PrincipalID), strings.ToLower(strings.TrimSpace(req.PrincipalEmail))).Scan(&out.PrincipalID, &out.PrincipalEmail, &status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return tenantImpersonation{}, errImpersonationPrincipalNotFound
+		}
+		return tenantImpersonation{}, err
+	}
+	if status != "active" {
+		return tenantImpersonation{}, errImpersonationPrincipalInactive
+	}
+
+	ticket, err := m.newTicket()
+	if err != nil {
+		return tenantImpersonation{}, err
+	}
+	ticketSum := sha256.Sum256([]byte(ticket))
+	now := m.now().UTC()
+	out.ExpiresAt = now.Add(ttl)
+	out.HandoffExpiresAt = now.Add(min(impersonationHandoffTTL, ttl))
+
+	if err := tx.QueryRow(ctx, `
+INSERT INTO iam.impersonation_sessions (
+  tenant_uuid, principal_id, superadmin_actor, superadmin_email, reason, read_only,
+  handoff_token_sha256, handoff_expires_at, expires_at
+)
+VALUES ($1::uuid, $2::uuid, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id::text, created_at
+`, tenantID, out.PrincipalID, actor.ID, actor.Email, reason, readOnly, ticketSum[:], out.HandoffExpiresAt, out.ExpiresAt).Scan(&out.ID, &out.CreatedAt); err != nil {
+		return tenantImpersonation{}, err
+	}
+
+	payload, _ := json.Marshal(map[string]any{
+		"impersonation_id": out.ID,
+		"principal_id":     out.PrincipalID,
+		"principal_email":  out.PrincipalEmail,
+		"reason":           reason,
+		"read_only":        readOnly,
+		"expires_at":       out.ExpiresAt.Format(time.RFC3339),
+	})
+	if err := insertAudit(ctx, tx, actor.ID, "tenant.impersonation.start", tenantID, payload, reqID); err != nil {
+		return tenantImpersonation{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return tenantImpersonation{}, err
+	}
+
+	out.HandoffURL = strings.ReplaceAll(m.appURL, "{host}", host) + impersonationAcceptPath + "?ticket=" + url.QueryEscape(ticket)
+	return out, nil
+}
+
+// End closes an impersonation and revokes every tenant session issued from it.
+func (m *tenantImpersonator) End(ctx context.Context, actor string, tenantID string, impersonationID string, reqID string) (tenantImpersonation, error) {
+	if _, err := uuid.Parse(tenantID); err != nil {
+		return tenantImpersonation{}, errImpersonationNotFound
+	}
+	if _, err := uuid.Parse(impersonationID); err != nil {
+		return tenantImpersonation{}, errImpersonationNotFound
+	}
+
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return tenantImpersonation{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	imp, err := loadImpersonationTx(ctx, tx, tenantID, impersonationID, true)
+	if err != nil {
+		return tenantImpersonation{}, err
+	}
+	if imp.EndedAt != nil {
+		return tenantImpersonation{}, errImpersonationEnded
+	}
+
+	endedAt := m.now().UTC()
+	if _, err := tx.Exec(ctx, `
+UPDATE iam.impersonation_sessions
+SET ended_at = $3, ended_by = $4
+WHERE tenant_uuid = $1::uuid AND id = $2::uuid
+`, tenantID, impersonationID, endedAt, actor); err != nil {
+		return tenantImpersonation{}, err
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE iam.sessions
+SET revoked_at = $2
+WHERE impersonation_id = $1::uuid AND revoked_at IS NULL
+`, impersonationID, endedAt); err != nil {
+		return tenantImpersonation{}, err
+	}
+
+	payload, _ := json.Marshal(map[string]any{
+		"impersonation_id": impersonationID,
+		"principal_id":     imp.PrincipalID,
+		"request_count":    imp.RequestCount,
+	})
+	if err := insertAudit(ctx, tx, actor, "tenant.impersonation.end", tenantID, payload, reqID); err != nil {
+		return tenantImpersonation{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return tenantImpersonation{}, err
+	}
+
+	imp.EndedAt = &endedAt
+	imp.EndedBy = actor
+	return imp, nil
+}
+
+func (m *tenantImpersonator) List(ctx context.Context, tenantID string) ([]tenantImpersonation, error) {
+	if _, err := uuid.Parse(tenantID); err != nil {
+		return nil, errImpersonationTenantNotFound
+	}
+	rows, err := m.pool.Query(ctx, impersonationSelectSQL+`
+WHERE i.tenant_uuid = $1::uuid
+ORDER BY i.created_at DESC, i.id
+LIMIT 200
+`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]tenantImpersonation, 0)
+	for rows.Next() {
+		imp, err := scanImpersonation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, imp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Requests returns the audited request log of one impersonation, oldest first.
+func (m *tenantImpersonator) Requests(ctx context.Context, tenantID string, impersonationID string) ([]tenantImpersonationRequestLog, error) {
+	if _, err := uuid.Parse(tenantID); err != nil {
+		return nil, errImpersonationNotFound
+	}
+	if _, err := uuid.Parse(impersonationID); err != nil {
+		return nil, errImpersonationNotFound
+	}
+	rows, err := m.pool.Query(ctx, `
+SELECT id, method, path, request_id, status_code, created_at, completed_at
+FROM iam.impersonation_request_logs
+WHERE tenant_uuid = $1::uuid AND impersonation_id = $2::uuid
+ORDER BY id
+LIMIT $3
+`, tenantID, impersonationID, impersonationRequestLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]tenantImpersonationRequestLog, 0)
+	for rows.Next() {
+		var l tenantImpersonationRequestLog
+		if err := rows.Scan(&l.ID, &l.Method, &l.Path, &l.RequestID, &l.StatusCode, &l.CreatedAt, &l.CompletedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+const impersonationSelectSQL = `
+SELECT i.id::text, i.tenant_uuid::text, i.principal_id::text, COALESCE(p.email, ''),
+  i.superadmin_actor, i.superadmin_email, i.reason, i.read_only,
+  i.handoff_expires_at, i.accepted_at, i.expires_at, i.ended_at, COALESCE(i.ended_by, ''), i.created_at,
+  (SELECT count(*) FROM iam.impersonation_request_logs l WHERE l.impersonation_id = i.id)
+FROM iam.impersonation_sessions i
+LEFT JOIN iam.principals p ON p.id = i.principal_id
+`
+
+func scanImpersonation(row pgx.Row) (tenantImpersonation, error) {
+	var imp tenantImpersonation
+	err := row.Scan(
+		&imp.ID, &imp.TenantID, &imp.PrincipalID, &imp.PrincipalEmail,
+		&imp.SuperadminActor, &imp.SuperadminEmail, &imp.Reason, &imp.ReadOnly,
+		&imp.HandoffExpiresAt, &imp.AcceptedAt, &imp.ExpiresAt, &imp.EndedAt, &imp.EndedBy, &imp.CreatedAt,
+		&imp.RequestCount,
+	)
+	return imp, err
+}
+
+func loadImpersonationTx(ctx context.Context, tx pgx.Tx, tenantID string, impersonationID string, forUpdate bool) (tenantImpersonation, error) {
+	sql := impersonationSelectSQL + `WHERE i.tenant_uuid = $1::uuid AND i.id = $2::uuid`
+	if forUpdate {
+		sql += ` FOR UPDATE OF i`
+	}
+	imp, err := scanImpersonation(tx.QueryRow(ctx, sql, tenantID, impersonationID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return tenantImpersonation{}, errImpersonationNotFound
+		}
+		return tenantImpersonation{}, err
+	}
+	return imp, nil
+}
+
+func tenantImpersonationErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, errImpersonationTenantNotFound):
+		return http.StatusNotFound, "tenant_not_found"
+	case errors.Is(err, errImpersonationTenantInactive):
+		return http.StatusConflict, "impersonation_tenant_inactive"
+	case errors.Is(err, errImpersonationTenantNoDomain):
+		return http.StatusConflict, "impersonation_tenant_no_domain"
+	case errors.Is(err, errImpersonationPrincipalNotFound):
+		return http.StatusNotFound, "impersonation_principal_not_found"
+	case errors.Is(err, errImpersonationPrincipalInactive):
+		return http.StatusConflict, "impersonation_principal_inactive"
+	case errors.Is(err, errImpersonationReasonRequired):
+		return http.StatusBadRequest, "impersonation_reason_required"
+	case errors.Is(err, errImpersonationInvalidTTL):
+		return http.StatusBadRequest, "impersonation_invalid_ttl"
+	case errors.Is(err, errImpersonationNotFound):
+		return http.StatusNotFound, "impersonation_not_found"
+	case errors.Is(err, errImpersonationEnded):
+		return http.StatusConflict, "impersonation_ended"
+	default:
+		return http.StatusInternalServerError, "db_error"
+	}
+}
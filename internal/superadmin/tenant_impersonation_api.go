@@ -0,0 +1,167 @@
+package superadmin
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+)
+
+func impersonationIDFromPath(path string) (string, bool) {
+	// /superadmin/(api/)tenants/{tenant_id}/impersonations/{impersonation_id}/...
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "impersonations" && i >= 3 {
+			return parts[i+1], parts[i+1] != ""
+		}
+	}
+	return "", false
+}
+
+func handleTenantImpersonationStartAPI(w http.ResponseWriter, r *http.Request, impersonator *tenantImpersonator) {
+	p, tenantID, ok := tenantOffboardWritePreamble(w, r, routing.RouteClassInternalAPI)
+	if !ok {
+		return
+	}
+
+	var req tenantImpersonationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_json", "bad json")
+		return
+	}
+
+	imp, err := impersonator.Start(r.Context(), p, tenantID, req, requestID(r))
+	if err != nil {
+		status, code := tenantImpersonationErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, "")
+		return
+	}
+	writeTenantOffboardJSON(w, http.StatusCreated, imp)
+}
+
+func handleTenantImpersonationListAPI(w http.ResponseWriter, r *http.Request, impersonator *tenantImpersonator) {
+	tenantID, ok := tenantIDFromAPIPath(r.URL.Path)
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_request", "bad request")
+		return
+	}
+	items, err := impersonator.List(r.Context(), tenantID)
+	if err != nil {
+		status, code := tenantImpersonationErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, "")
+		return
+	}
+	writeTenantOffboardJSON(w, http.StatusOK, map[string]any{"tenant_id": tenantID, "impersonations": items})
+}
+
+func handleTenantImpersonationRequestsAPI(w http.ResponseWriter, r *http.Request, impersonator *tenantImpersonator) {
+	tenantID, ok := tenantIDFromAPIPath(r.URL.Path)
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_request", "bad request")
+		return
+	}
+	impersonationID, ok := impersonationIDFromPath(r.URL.Path)
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_request", "bad request")
+		return
+	}
+	logs, err := impersonator.Requests(r.Context(), tenantID, impersonationID)
+	if err != nil {
+		status, code := tenantImpersonationErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, "")
+		return
+	}
+	writeTenantOffboardJSON(w, http.StatusOK, map[string]any{"impersonation_id": impersonationID, "requests": logs})
+}
+
+func handleTenantImpersonationEndAPI(w http.ResponseWriter, r *http.Request, impersonator *tenantImpersonator) {
+	p, tenantID, ok := tenantOffboardWritePreamble(w, r, routing.RouteClassInternalAPI)
+	if !ok {
+		return
+	}
+	impersonationID, ok := impersonationIDFromPath(r.URL.Path)
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_request", "bad request")
+		return
+	}
+
+	imp, err := impersonator.End(r.Context(), p.ID, tenantID, impersonationID, requestID(r))
+	if err != nil {
+		status, code := tenantImpersonationErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, "")
+		return
+	}
+	writeTenantOffboardJSON(w, http.StatusOK, imp)
+}
+
+func handleTenantImpersonationStartForm(w http.ResponseWriter, r *http.Request, impersonator *tenantImpersonator) {
+	p, tenantID, ok := tenantOffboardWritePreamble(w, r, routing.RouteClassUI)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		routing.WriteError(w, r, routing.RouteClassUI, http.StatusBadRequest, "bad_request", "bad request")
+		return
+	}
+
+	readOnly := r.FormValue("allow_writes") != "on"
+	imp, err := impersonator.Start(r.Context(), p, tenantID, tenantImpersonationRequest{
+		PrincipalEmail: r.FormValue("principal_email"),
+		Reason:         r.FormValue("reason"),
+		ReadOnly:       &readOnly,
+	}, requestID(r))
+	if err != nil {
+		status, code := tenantImpersonationErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassUI, status, code, "")
+		return
+	}
+	writeTenantImpersonationPage(w, imp)
+}
+
+func handleTenantImpersonationEndForm(w http.ResponseWriter, r *http.Request, impersonator *tenantImpersonator) {
+	p, tenantID, ok := tenantOffboardWritePreamble(w, r, routing.RouteClassUI)
+	if !ok {
+		return
+	}
+	impersonationID, ok := impersonationIDFromPath(r.URL.Path)
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassUI, http.StatusBadRequest, "bad_request", "bad request")
+		return
+	}
+
+	if _, err := impersonator.End(r.Context(), p.ID, tenantID, impersonationID, requestID(r)); err != nil {
+		status, code := tenantImpersonationErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassUI, status, code, "")
+		return
+	}
+	http.Redirect(w, r, "/superadmin/tenants", http.StatusFound)
+}
+
+func writeTenantImpersonationPage(w http.ResponseWriter, imp tenantImpersonation) {
+	tenantID := html.EscapeString(imp.TenantID)
+	id := html.EscapeString(imp.ID)
+	mode := "read-only"
+	if !imp.ReadOnly {
+		mode = "read-write"
+	}
+
+	var b strings.Builder
+	b.WriteString("<h1>SuperAdmin / Impersonation</h1>")
+	b.WriteString("<p>Tenant: <code>" + tenantID + "</code></p>")
+	b.WriteString("<p>Principal: " + html.EscapeString(imp.PrincipalEmail) + " (<code>" + html.EscapeString(imp.PrincipalID) + "</code>)</p>")
+	b.WriteString("<p>Mode: <b>" + mode + "</b>, expires at " + html.EscapeString(imp.ExpiresAt.Format(time.RFC3339)) + "</p>")
+	b.WriteString("<p>Reason: " + html.EscapeString(imp.Reason) + "</p>")
+	if imp.HandoffURL != "" {
+		b.WriteString(`<p><a href="` + html.EscapeString(imp.HandoffURL) + `" target="_blank" rel="noopener noreferrer">Open tenant session</a>`)
+		b.WriteString(" (one-time link, valid until " + html.EscapeString(imp.HandoffExpiresAt.Format(time.RFC3339)) + ")</p>")
+	}
+	b.WriteString(fmt.Sprintf(`<form method="POST" action="/superadmin/tenants/%s/impersonations/%s/end"><button type="submit">End impersonation</button></form>`, tenantID, id))
+	b.WriteString(fmt.Sprintf(`<p><a href="/superadmin/api/tenants/%s/impersonations/%s/requests">Request log</a></p>`, tenantID, id))
+	b.WriteString(`<p><a href="/superadmin/tenants">Back to tenants</a></p>`)
+
+	writeHTML(w, "Impersonation", b.String())
+}
@@ -0,0 +1,397 @@
+package superadmin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const impersonationTestID = "22222222-2222-2222-2222-222222222222"
+
+type impersonationRecord struct {
+	principalID string
+	actor       string
+	email       string
+	reason      string
+	readOnly    bool
+	ticketSum   []byte
+	handoffExp  time.Time
+	expiresAt   time.Time
+	endedAt     *time.Time
+	endedBy     string
+}
+
+// impersonationDB fakes the tables read and written by tenantImpersonator. It holds at most one tenant,
+// one principal and one impersonation.
+type impersonationDB struct {
+	tenantActive    bool
+	host            string
+	principalStatus string
+	imp             *impersonationRecord
+	revoked         int
+	audits          []offboardAudit
+	logs            [][]any
+}
+
+func newImpersonationDB() *impersonationDB {
+	return &impersonationDB{tenantActive: true, host: "acme.localhost", principalStatus: "active"}
+}
+
+func (db *impersonationDB) Begin(context.Context) (pgx.Tx, error) {
+	return &impersonationTx{stubTx: &stubTx{}, db: db}, nil
+}
+
+func (db *impersonationDB) Query(_ context.Context, sql string, _ ...any) (pgx.Rows, error) {
+	if strings.Contains(sql, "SELECT id, method, path") {
+		return &reflectRows{vals: db.logs}, nil
+	}
+	if db.imp == nil {
+		return &reflectRows{}, nil
+	}
+	return &reflectRows{vals: [][]any{db.impValues()}}, nil
+}
+
+func (db *impersonationDB) impValues() []any {
+	return []any{
+		impersonationTestID, offboardTenantID, db.imp.principalID, "admin@acme.local",
+		db.imp.actor, db.imp.email, db.imp.reason, db.imp.readOnly,
+		db.imp.handoffExp, nil, db.imp.expiresAt, db.imp.endedAt, db.imp.endedBy, db.imp.expiresAt.Add(-time.Hour),
+		int64(len(db.logs)),
+	}
+}
+
+type impersonationTx struct {
+	*stubTx
+	db *impersonationDB
+}
+
+func (t *impersonationTx) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	db := t.db
+	switch {
+	case strings.Contains(sql, "FROM iam.tenants t"):
+		if args[0].(string) != offboardTenantID {
+			return offboardRow{err: pgx.ErrNoRows}
+		}
+		return offboardRow{vals: []any{db.tenantActive, db.host}}
+	case strings.Contains(sql, "FROM iam.impersonation_sessions i"):
+		if db.imp == nil || args[1].(string) != impersonationTestID {
+			return offboardRow{err: pgx.ErrNoRows}
+		}
+		return offboardRow{vals: db.impValues()}
+	case strings.Contains(sql, "FROM iam.principals"):
+		if args[1].(string) != "p1" && args[2].(string) != "admin@acme.local" {
+			return offboardRow{err: pgx.ErrNoRows}
+		}
+		return offboardRow{vals: []any{"p1", "admin@acme.local", db.principalStatus}}
+	case strings.Contains(sql, "INSERT INTO iam.impersonation_sessions"):
+		db.imp = &impersonationRecord{
+			principalID: args[1].(string),
+			actor:       args[2].(string),
+			email:       args[3].(string),
+			reason:      args[4].(string),
+			readOnly:    args[5].(bool),
+			ticketSum:   args[6].([]byte),
+			handoffExp:  args[7].(time.Time),
+			expiresAt:   args[8].(time.Time),
+		}
+		return offboardRow{vals: []any{impersonationTestID, time.Now()}}
+	default:
+		return offboardRow{err: errors.New("unexpected query row: " + sql)}
+	}
+}
+
+func (t *impersonationTx) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	db := t.db
+	switch {
+	case strings.Contains(sql, "UPDATE iam.impersonation_sessions"):
+		endedAt := args[2].(time.Time)
+		db.imp.endedAt = &endedAt
+		db.imp.endedBy = args[3].(string)
+	case strings.Contains(sql, "UPDATE iam.sessions"):
+		db.revoked++
+	case strings.Contains(sql, "INSERT INTO iam.superadmin_audit_logs"):
+		db.audits = append(db.audits, offboardAudit{action: args[1].(string), tenantID: args[2].(string), payload: string(args[3].([]byte))})
+	default:
+		return pgconn.CommandTag{}, errors.New("unexpected exec: " + sql)
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+type reflectRows struct {
+	stubRows
+	vals [][]any
+	idx  int
+}
+
+func (r *reflectRows) Next() bool {
+	if r.idx >= len(r.vals) {
+		return false
+	}
+	r.idx++
+	return true
+}
+
+func (r *reflectRows) Scan(dest ...any) error {
+	return offboardRow{vals: r.vals[r.idx-1]}.Scan(dest...)
+}
+
+func newTestImpersonator(db *impersonationDB, now time.Time) *tenantImpersonator {
+	return &tenantImpersonator{
+		pool:      db,
+		ttl:       defaultImpersonationTTL,
+		appURL:    "https://{host}",
+		now:       func() time.Time { return now },
+		newTicket: func() (string, error) { return "ticket-1", nil },
+	}
+}
+
+func TestTenantImpersonator_StartAndEnd(t *testing.T) {
+	db := newImpersonationDB()
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	m := newTestImpersonator(db, now)
+	ctx := context.Background()
+	actor := superadminPrincipal{ID: "sa-1", Email: "ops@example.invalid"}
+
+	imp, err := m.Start(ctx, actor, offboardTenantID, tenantImpersonationRequest{PrincipalEmail: "Admin@acme.local", Reason: " ticket 42 "}, "req-1")
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if imp.ID != impersonationTestID || imp.PrincipalID != "p1" || !imp.ReadOnly || imp.Reason != "ticket 42" {
+		t.Fatalf("imp=%+v", imp)
+	}
+	if !imp.ExpiresAt.Equal(now.Add(defaultImpersonationTTL)) || !imp.HandoffExpiresAt.Equal(now.Add(impersonationHandoffTTL)) {
+		t.Fatalf("imp=%+v", imp)
+	}
+	if imp.HandoffURL != "https://acme.localhost/iam/impersonation/accept?ticket=ticket-1" {
+		t.Fatalf("url=%s", imp.HandoffURL)
+	}
+	sum := sha256.Sum256([]byte("ticket-1"))
+	if string(db.imp.ticketSum) != string(sum[:]) || db.imp.actor != "sa-1" || db.imp.email != "ops@example.invalid" {
+		t.Fatalf("stored=%+v", db.imp)
+	}
+
+	ended, err := m.End(ctx, "sa-2", offboardTenantID, impersonationTestID, "req-2")
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if ended.EndedAt == nil || ended.EndedBy != "sa-2" || db.revoked != 1 {
+		t.Fatalf("ended=%+v revoked=%d", ended, db.revoked)
+	}
+	if _, err := m.End(ctx, "sa-2", offboardTenantID, impersonationTestID, "req-3"); !errors.Is(err, errImpersonationEnded) {
+		t.Fatalf("err=%v", err)
+	}
+	if got := auditActions(&offboardDB{audits: db.audits}); got != "tenant.impersonation.start,tenant.impersonation.end" {
+		t.Fatalf("audits=%s", got)
+	}
+	if !strings.Contains(db.audits[0].payload, `"read_only":true`) || db.audits[0].tenantID != offboardTenantID {
+		t.Fatalf("audit=%+v", db.audits[0])
+	}
+}
+
+func TestTenantImpersonator_StartErrors(t *testing.T) {
+	ctx := context.Background()
+	actor := superadminPrincipal{ID: "sa-1"}
+	ok := tenantImpersonationRequest{PrincipalID: "p1", Reason: "support"}
+	writable := false
+
+	cases := []struct {
+		name  string
+		setup func(db *impersonationDB)
+		tid   string
+		req   tenantImpersonationRequest
+		want  error
+	}{
+		{name: "bad tenant id", tid: "nope", req: ok, want: errImpersonationTenantNotFound},
+		{name: "missing tenant", tid: "33333333-3333-3333-3333-333333333333", req: ok, want: errImpersonationTenantNotFound},
+		{name: "missing reason", tid: offboardTenantID, req: tenantImpersonationRequest{PrincipalID: "p1"}, want: errImpersonationReasonRequired},
+		{name: "ttl too long", tid: offboardTenantID, req: tenantImpersonationRequest{PrincipalID: "p1", Reason: "x", TTLMinutes: 600}, want: errImpersonationInvalidTTL},
+		{name: "negative ttl", tid: offboardTenantID, req: tenantImpersonationRequest{PrincipalID: "p1", Reason: "x", TTLMinutes: -1}, want: errImpersonationInvalidTTL},
+		{name: "inactive tenant", setup: func(db *impersonationDB) { db.tenantActive = false }, tid: offboardTenantID, req: ok, want: errImpersonationTenantInactive},
+		{name: "no domain", setup: func(db *impersonationDB) { db.host = "" }, tid: offboardTenantID, req: ok, want: errImpersonationTenantNoDomain},
+		{name: "unknown principal", tid: offboardTenantID, req: tenantImpersonationRequest{PrincipalID: "p9", Reason: "x"}, want: errImpersonationPrincipalNotFound},
+		{name: "disabled principal", setup: func(db *impersonationDB) { db.principalStatus = "disabled" }, tid: offboardTenantID, req: ok, want: errImpersonationPrincipalInactive},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newImpersonationDB()
+			if tc.setup != nil {
+				tc.setup(db)
+			}
+			if _, err := newTestImpersonator(db, time.Now()).Start(ctx, actor, tc.tid, tc.req, "r"); !errors.Is(err, tc.want) {
+				t.Fatalf("err=%v", err)
+			}
+		})
+	}
+
+	db := newImpersonationDB()
+	imp, err := newTestImpersonator(db, time.Now()).Start(ctx, actor, offboardTenantID, tenantImpersonationRequest{PrincipalID: "p1", Reason: "x", ReadOnly: &writable, TTLMinutes: 1}, "r")
+	if err != nil || imp.ReadOnly || imp.ExpiresAt.Sub(imp.HandoffExpiresAt) != 0 {
+		t.Fatalf("imp=%+v err=%v", imp, err)
+	}
+}
+
+func TestTenantImpersonationErrorStatus(t *testing.T) {
+	cases := map[error]string{
+		errImpersonationTenantNotFound:    "tenant_not_found",
+		errImpersonationTenantInactive:    "impersonation_tenant_inactive",
+		errImpersonationTenantNoDomain:    "impersonation_tenant_no_domain",
+		errImpersonationPrincipalNotFound: "impersonation_principal_not_found",
+		errImpersonationPrincipalInactive: "impersonation_principal_inactive",
+		errImpersonationReasonRequired:    "impersonation_reason_required",
+		errImpersonationInvalidTTL:        "impersonation_invalid_ttl",
+		errImpersonationNotFound:          "impersonation_not_found",
+		errImpersonationEnded:             "impersonation_ended",
+		errors.New("boom"):                "db_error",
+	}
+	for err, want := range cases {
+		if _, code := tenantImpersonationErrorStatus(err); code != want {
+			t.Fatalf("err=%v code=%s want=%s", err, code, want)
+		}
+	}
+}
+
+func TestImpersonationConfigFromEnv(t *testing.T) {
+	t.Setenv("SUPERADMIN_IMPERSONATION_TTL", "")
+	if d, err := impersonationTTLFromEnv(); err != nil || d != defaultImpersonationTTL {
+		t.Fatalf("d=%v err=%v", d, err)
+	}
+	t.Setenv("SUPERADMIN_IMPERSONATION_TTL", "15m")
+	if d, err := impersonationTTLFromEnv(); err != nil || d != 15*time.Minute {
+		t.Fatalf("d=%v err=%v", d, err)
+	}
+	for _, v := range []string{"x", "0s", "5h"} {
+		t.Setenv("SUPERADMIN_IMPERSONATION_TTL", v)
+		if _, err := impersonationTTLFromEnv(); err == nil {
+			t.Fatalf("expected error for %q", v)
+		}
+	}
+
+	t.Setenv("SUPERADMIN_IMPERSONATION_TTL", "")
+	t.Setenv("SUPERADMIN_TENANT_APP_URL", "https://app.example.invalid")
+	if _, err := newTenantImpersonator(nil); err == nil {
+		t.Fatal("expected error")
+	}
+	t.Setenv("SUPERADMIN_TENANT_APP_URL", "")
+	if m, err := newTenantImpersonator(nil); err != nil || m.appURL != defaultTenantAppURL {
+		t.Fatalf("m=%+v err=%v", m, err)
+	}
+}
+
+func TestTenantImpersonationAPI(t *testing.T) {
+	db := newImpersonationDB()
+	a := newAuthedHandlerWithOptions(t, HandlerOptions{
+		Pool:         db,
+		DictBaseline: &fakeDictPublisher{},
+		AdminInviter: &fakeAdminInviter{},
+		OrgUnits:     &fakeOrgUnitWriter{},
+	})
+	base := "/superadmin/api/tenants/" + offboardTenantID + "/impersonations"
+
+	rec := httptest.NewRecorder()
+	a.h.ServeHTTP(rec, a.newRequest(http.MethodPost, base, strings.NewReader(`{`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	a.h.ServeHTTP(rec, a.newRequest(http.MethodPost, base, strings.NewReader(`{"principal_id":"p1"}`)))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "impersonation_reason_required") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	a.h.ServeHTTP(rec, a.newRequest(http.MethodPost, base, strings.NewReader(`{"principal_id":"p1","reason":"ticket 7"}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	var started tenantImpersonation
+	if err := json.Unmarshal(rec.Body.Bytes(), &started); err != nil || !strings.Contains(started.HandoffURL, "acme.localhost") {
+		t.Fatalf("body=%s err=%v", rec.Body.String(), err)
+	}
+
+	db.logs = [][]any{{int64(1), "GET", "/org/api/org-units", "req-9", nil, time.Now(), nil}}
+	rec = httptest.NewRecorder()
+	a.h.ServeHTTP(rec, a.newRequest(http.MethodGet, base, nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"request_count":1`) || strings.Contains(rec.Body.String(), "handoff_url") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	a.h.ServeHTTP(rec, a.newRequest(http.MethodGet, base+"/"+impersonationTestID+"/requests", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"path":"/org/api/org-units"`) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	a.h.ServeHTTP(rec, a.newRequest(http.MethodPost, base+"/"+impersonationTestID+"/end", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"ended_at"`) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	a.h.ServeHTTP(rec, a.newRequest(http.MethodPost, base+"/not-a-uuid/end", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTenantImpersonationForms(t *testing.T) {
+	db := newImpersonationDB()
+	a := newAuthedHandlerWithOptions(t, HandlerOptions{
+		Pool:         db,
+		DictBaseline: &fakeDictPublisher{},
+		AdminInviter: &fakeAdminInviter{},
+		OrgUnits:     &fakeOrgUnitWriter{},
+	})
+	base := "/superadmin/tenants/" + offboardTenantID + "/impersonations"
+
+	post := func(path string, form url.Values) *httptest.ResponseRecorder {
+		req := a.newRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		a.h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(base, url.Values{"principal_email": {"admin@acme.local"}, "reason": {"support"}, "allow_writes": {"on"}})
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "read-write") || !strings.Contains(rec.Body.String(), "Open tenant session") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	if db.imp.readOnly {
+		t.Fatal("expected writable impersonation")
+	}
+
+	rec = post(base+"/"+impersonationTestID+"/end", nil)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = post(base, url.Values{"principal_email": {"nobody@acme.local"}, "reason": {"support"}})
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestImpersonationIDFromPath(t *testing.T) {
+	for _, p := range []string{
+		"/superadmin/api/tenants/t1/impersonations/i1/end",
+		"/superadmin/tenants/t1/impersonations/i1/end",
+	} {
+		if id, ok := impersonationIDFromPath(p); !ok || id != "i1" {
+			t.Fatalf("path=%s id=%q ok=%v", p, id, ok)
+		}
+	}
+	if _, ok := impersonationIDFromPath("/superadmin/api/tenants/t1/impersonations"); ok {
+		t.Fatal("expected no id")
+	}
+}
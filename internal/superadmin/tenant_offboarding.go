@@ -0,0 +1,732 @@
+package superadmin
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	offboardStatusScheduled = "scheduled"
+	offboardStatusCancelled = "cancelled"
+	offboardStatusDeleted   = "deleted"
+
+	tenantExportFormat        = "bugs-and-blossoms.tenant-export"
+	tenantExportFormatVersion = 2
+
+	defaultTenantDeleteGracePeriod = 7 * 24 * time.Hour
+
+	globalTenantID = "00000000-0000-0000-0000-000000000000"
+)
+
+var (
+	errOffboardTenantNotFound   = errors.New("superadmin: tenant not found")
+	errOffboardTenantProtected  = errors.New("superadmin: tenant cannot be offboarded")
+	errOffboardTenantActive     = errors.New("superadmin: tenant must be disabled before offboarding")
+	errOffboardAlreadyScheduled = errors.New("superadmin: tenant deletion already scheduled")
+	errOffboardNotScheduled     = errors.New("superadmin: tenant deletion not scheduled")
+	errOffboardNotFound         = errors.New("superadmin: tenant offboarding not found")
+	errOffboardGracePeriod      = errors.New("superadmin: tenant deletion grace period has not elapsed")
+	errOffboardTokenMismatch    = errors.New("superadmin: confirmation token mismatch")
+	errOffboardResidualData     = errors.New("superadmin: tenant data remains after purge")
+	errOffboardExportUnreadable = errors.New("superadmin: tenant table not readable for export")
+)
+
+type tenantExportSection struct {
+	Name string
+	SQL  string
+}
+
+// tenantExportFixedSections cover tenant data in tables without a tenant_uuid column; every other section
+// comes from the catalog (see loadTenantExportSectionsTx).
+var tenantExportFixedSections = []tenantExportSection{
+	{Name: "iam.tenants", SQL: "SELECT to_jsonb(t)::text FROM iam.tenants t WHERE t.id = $1::uuid"},
+	{Name: "iam.role_authz_capabilities", SQL: `
+SELECT (to_jsonb(c) || jsonb_build_object('role_slug', rd.role_slug))::text
+FROM iam.role_authz_capabilities c
+JOIN iam.role_definitions rd ON rd.id = c.role_id
+WHERE rd.tenant_uuid = $1::uuid
+ORDER BY rd.role_slug, c.authz_capability_key`},
+}
+
+// tenantExportOmittedTables are tenant-scoped tables the archive leaves out. Sessions, model credentials and
+// vault material are secrets, not tenant data a customer takes with them; provisioning runs and offboarding
+// requests are the superadmin's records about the tenant.
+var tenantExportOmittedTables = map[string]bool{
+	"iam.sessions":                               true,
+	"iam.cubebox_model_credentials":              true,
+	"iam.cubebox_vault_data_keys":                true,
+	"iam.cubebox_vault_secrets":                  true,
+	"iam.superadmin_tenant_provisioning_runs":    true,
+	"iam.superadmin_tenant_offboarding_requests": true,
+}
+
+// tenantExportRedactedColumns are stripped from rows of otherwise exported tables.
+var tenantExportRedactedColumns = map[string][]string{
+	"iam.webhook_subscriptions":  {"secret"},
+	"iam.impersonation_sessions": {"handoff_token_sha256"},
+}
+
+func tenantTableExportSection(schema string, table string, primaryKey []string) tenantExportSection {
+	name := schema + "." + table
+	row := "to_jsonb(t)"
+	for _, col := range tenantExportRedactedColumns[name] {
+		row += " - '" + col + "'"
+	}
+	orderBy := "1"
+	if len(primaryKey) > 0 {
+		cols := make([]string, 0, len(primaryKey))
+		for _, col := range primaryKey {
+			cols = append(cols, "t."+pgx.Identifier{col}.Sanitize())
+		}
+		orderBy = strings.Join(cols, ", ")
+	}
+	return tenantExportSection{
+		Name: name,
+		SQL: fmt.Sprintf("SELECT (%s)::text FROM %s t WHERE t.tenant_uuid = $1::uuid ORDER BY %s",
+			row, pgx.Identifier{schema, table}.Sanitize(), orderBy),
+	}
+}
+
+// loadTenantExportSectionsTx lists the archive entries: the fixed sections plus one per table that carries a
+// tenant_uuid column, discovered the same way verifyTenantPurgedTx finds tables, so a new tenant-scoped table
+// is exported without touching this file. Rows are ordered by primary key. A table the runtime role cannot
+// read fails the export rather than silently producing a partial archive.
+func loadTenantExportSectionsTx(ctx context.Context, tx pgx.Tx) ([]tenantExportSection, error) {
+	rows, err := tx.Query(ctx, `
+SELECT n.nspname, c.relname, has_table_privilege(c.oid, 'SELECT'),
+  COALESCE((
+    SELECT array_agg(pa.attname::text ORDER BY k.ord)
+    FROM pg_index i
+    CROSS JOIN LATERAL unnest(i.indkey) WITH ORDINALITY AS k(attnum, ord)
+    JOIN pg_attribute pa ON pa.attrelid = i.indrelid AND pa.attnum = k.attnum
+    WHERE i.indrelid = c.oid AND i.indisprimary
+  ), '{}'::text[])
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+JOIN pg_attribute a ON a.attrelid = c.oid AND a.attname = 'tenant_uuid' AND NOT a.attisdropped
+WHERE c.relkind IN ('r', 'p')
+  AND NOT c.relispartition
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+ORDER BY n.nspname, c.relname
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sections := append([]tenantExportSection(nil), tenantExportFixedSections...)
+	for rows.Next() {
+		var schema, table string
+		var readable bool
+		var primaryKey []string
+		if err := rows.Scan(&schema, &table, &readable, &primaryKey); err != nil {
+			return nil, err
+		}
+		name := schema + "." + table
+		if tenantExportOmittedTables[name] {
+			continue
+		}
+		if !readable {
+			return nil, fmt.Errorf("%w: %s", errOffboardExportUnreadable, name)
+		}
+		sections = append(sections, tenantTableExportSection(schema, table, primaryKey))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
+
+type tenantExportManifestSection struct {
+	Name   string `json:"name"`
+	File   string `json:"file"`
+	Rows   int    `json:"rows"`
+	SHA256 string `json:"sha256"`
+}
+
+type tenantExportManifest struct {
+	Format        string                        `json:"format"`
+	FormatVersion int                           `json:"format_version"`
+	TenantID      string                        `json:"tenant_id"`
+	TenantName    string                        `json:"tenant_name"`
+	ExportedAt    time.Time                     `json:"exported_at"`
+	ExportedBy    string                        `json:"exported_by"`
+	Sections      []tenantExportManifestSection `json:"sections"`
+}
+
+type tenantPurgeTableCheck struct {
+	Table    string `json:"table"`
+	RLS      bool   `json:"rls"`
+	Readable bool   `json:"readable"`
+	Rows     int64  `json:"rows"`
+}
+
+type tenantPurgeVerification struct {
+	TenantID   string                  `json:"tenant_id"`
+	VerifiedAt time.Time               `json:"verified_at"`
+	Clean      bool                    `json:"clean"`
+	Tables     []tenantPurgeTableCheck `json:"tables"`
+}
+
+func (v tenantPurgeVerification) residualTables() []string {
+	out := make([]string, 0)
+	for _, t := range v.Tables {
+		if !t.Readable || t.Rows > 0 {
+			out = append(out, t.Table)
+		}
+	}
+	return out
+}
+
+type tenantOffboarding struct {
+	TenantID    string     `json:"tenant_id"`
+	TenantName  string     `json:"tenant_name"`
+	Status      string     `json:"status"`
+	Reason      string     `json:"reason,omitempty"`
+	RequestedBy string     `json:"requested_by"`
+	PurgeAfter  time.Time  `json:"purge_after"`
+	ClosedBy    string     `json:"closed_by,omitempty"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+	// ConfirmationToken is only returned when the deletion is scheduled; only its hash is stored.
+	ConfirmationToken string                   `json:"confirmation_token,omitempty"`
+	Verification      *tenantPurgeVerification `json:"verification,omitempty"`
+}
+
+type tenantOffboarder struct {
+	pool  pgBeginner
+	grace time.Duration
+	now   func() time.Time
+}
+
+func newTenantOffboarder(pool pgBeginner) (*tenantOffboarder, error) {
+	grace, err := tenantDeleteGracePeriodFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return &tenantOffboarder{pool: pool, grace: grace, now: time.Now}, nil
+}
+
+func tenantDeleteGracePeriodFromEnv() (time.Duration, error) {
+	raw := strings.TrimSpace(os.Getenv("SUPERADMIN_TENANT_DELETE_GRACE"))
+	if raw == "" {
+		return defaultTenantDeleteGracePeriod, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0, errors.New("superadmin: invalid SUPERADMIN_TENANT_DELETE_GRACE")
+	}
+	return d, nil
+}
+
+// Export bundles the tenant's data into a zip archive: one NDJSON file per section plus manifest.json with
+// per-file row counts and sha256 digests. All sections are read from a single snapshot.
+func (o *tenantOffboarder) Export(ctx context.Context, actor string, tenantID string, reqID string) ([]byte, tenantExportManifest, error) {
+	if err := validateOffboardTenantID(tenantID); err != nil {
+		return nil, tenantExportManifest{}, err
+	}
+
+	tx, err := o.pool.Begin(ctx)
+	if err != nil {
+		return nil, tenantExportManifest{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if _, err := tx.Exec(ctx, `SET TRANSACTION ISOLATION LEVEL REPEATABLE READ;`); err != nil {
+		return nil, tenantExportManifest{}, err
+	}
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
+		return nil, tenantExportManifest{}, err
+	}
+	name, _, err := loadOffboardTenantTx(ctx, tx, tenantID, false)
+	if err != nil {
+		return nil, tenantExportManifest{}, err
+	}
+	sections, err := loadTenantExportSectionsTx(ctx, tx)
+	if err != nil {
+		return nil, tenantExportManifest{}, err
+	}
+
+	manifest := tenantExportManifest{
+		Format:        tenantExportFormat,
+		FormatVersion: tenantExportFormatVersion,
+		TenantID:      tenantID,
+		TenantName:    name,
+		ExportedAt:    o.now().UTC(),
+		ExportedBy:    actor,
+		Sections:      make([]tenantExportManifestSection, 0, len(sections)),
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, section := range sections {
+		entry, err := writeTenantExportSection(ctx, tx, zw, section, tenantID)
+		if err != nil {
+			return nil, tenantExportManifest{}, err
+		}
+		manifest.Sections = append(manifest.Sections, entry)
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, tenantExportManifest{}, err
+	}
+	mw, err := zw.Create("manifest.json")
+	if err != nil {
+		return nil, tenantExportManifest{}, err
+	}
+	if _, err := mw.Write(manifestJSON); err != nil {
+		return nil, tenantExportManifest{}, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, tenantExportManifest{}, err
+	}
+
+	archiveSum := sha256.Sum256(buf.Bytes())
+	payload, _ := json.Marshal(map[string]any{
+		"tenant_id":      tenantID,
+		"format_version": tenantExportFormatVersion,
+		"sections":       len(manifest.Sections),
+		"archive_sha256": hex.EncodeToString(archiveSum[:]),
+	})
+	if err := insertAudit(ctx, tx, actor, "tenant.export", tenantID, payload, reqID); err != nil {
+		return nil, tenantExportManifest{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, tenantExportManifest{}, err
+	}
+	return buf.Bytes(), manifest, nil
+}
+
+func writeTenantExportSection(ctx context.Context, tx pgx.Tx, zw *zip.Writer, section tenantExportSection, tenantID string) (tenantExportManifestSection, error) {
+	entry := tenantExportManifestSection{Name: section.Name, File: section.Name + ".ndjson"}
+	w, err := zw.Create(entry.File)
+	if err != nil {
+		return entry, err
+	}
+
+	rows, err := tx.Query(ctx, section.SQL, tenantID)
+	if err != nil {
+		return entry, err
+	}
+	defer rows.Close()
+
+	h := sha256.New()
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return entry, err
+		}
+		b := []byte(line + "\n")
+		if _, err := w.Write(b); err != nil {
+			return entry, err
+		}
+		_, _ = h.Write(b)
+		entry.Rows++
+	}
+	if err := rows.Err(); err != nil {
+		return entry, err
+	}
+	entry.SHA256 = hex.EncodeToString(h.Sum(nil))
+	return entry, nil
+}
+
+// Schedule opens the grace period for a disabled tenant and returns the one-time confirmation token that
+// Execute requires.
+func (o *tenantOffboarder) Schedule(ctx context.Context, actor string, tenantID string, reason string, reqID string) (tenantOffboarding, error) {
+	if err := validateOffboardTenantID(tenantID); err != nil {
+		return tenantOffboarding{}, err
+	}
+
+	tx, err := o.pool.Begin(ctx)
+	if err != nil {
+		return tenantOffboarding{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	name, active, err := loadOffboardTenantTx(ctx, tx, tenantID, true)
+	if err != nil {
+		return tenantOffboarding{}, err
+	}
+	if active {
+		return tenantOffboarding{}, errOffboardTenantActive
+	}
+
+	token, err := randomURLToken(24)
+	if err != nil {
+		return tenantOffboarding{}, err
+	}
+	tokenSum := sha256.Sum256([]byte(token))
+	reason = strings.TrimSpace(reason)
+	purgeAfter := o.now().Add(o.grace).UTC()
+
+	var id int64
+	if err := tx.QueryRow(ctx, `
+INSERT INTO iam.superadmin_tenant_offboarding_requests (tenant_uuid, tenant_name, reason, token_sha256, purge_after, requested_by)
+VALUES ($1::uuid, $2, $3, $4, $5, $6)
+ON CONFLICT (tenant_uuid) WHERE status = 'scheduled' DO NOTHING
+RETURNING id
+`, tenantID, name, reason, tokenSum[:], purgeAfter, actor).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return tenantOffboarding{}, errOffboardAlreadyScheduled
+		}
+		return tenantOffboarding{}, err
+	}
+
+	payload, _ := json.Marshal(map[string]any{
+		"tenant_id":   tenantID,
+		"tenant_name": name,
+		"reason":      reason,
+		"purge_after": purgeAfter.Format(time.RFC3339),
+	})
+	if err := insertAudit(ctx, tx, actor, "tenant.offboarding.schedule", tenantID, payload, reqID); err != nil {
+		return tenantOffboarding{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return tenantOffboarding{}, err
+	}
+
+	return tenantOffboarding{
+		TenantID:          tenantID,
+		TenantName:        name,
+		Status:            offboardStatusScheduled,
+		Reason:            reason,
+		RequestedBy:       actor,
+		PurgeAfter:        purgeAfter,
+		ConfirmationToken: token,
+	}, nil
+}
+
+func (o *tenantOffboarder) Cancel(ctx context.Context, actor string, tenantID string, reqID string) (tenantOffboarding, error) {
+	if err := validateOffboardTenantID(tenantID); err != nil {
+		return tenantOffboarding{}, err
+	}
+
+	tx, err := o.pool.Begin(ctx)
+	if err != nil {
+		return tenantOffboarding{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	off, _, err := loadScheduledOffboardingTx(ctx, tx, tenantID)
+	if err != nil {
+		return tenantOffboarding{}, err
+	}
+	closedAt := o.now().UTC()
+	if _, err := tx.Exec(ctx, `
+UPDATE iam.superadmin_tenant_offboarding_requests
+SET status = 'cancelled', closed_by = $2, closed_at = $3, updated_at = now()
+WHERE tenant_uuid = $1::uuid AND status = 'scheduled'
+`, tenantID, actor, closedAt); err != nil {
+		return tenantOffboarding{}, err
+	}
+
+	payload, _ := json.Marshal(map[string]any{"tenant_id": tenantID})
+	if err := insertAudit(ctx, tx, actor, "tenant.offboarding.cancel", tenantID, payload, reqID); err != nil {
+		return tenantOffboarding{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return tenantOffboarding{}, err
+	}
+
+	off.Status = offboardStatusCancelled
+	off.ClosedBy = actor
+	off.ClosedAt = &closedAt
+	return off, nil
+}
+
+// Execute hard-deletes the tenant once the grace period has passed and the confirmation token matches. The
+// purge and the verification scan share one transaction, so a non-empty scan rolls the whole delete back.
+// The audit row keeps the tenant id and name after the tenant rows are gone.
+func (o *tenantOffboarder) Execute(ctx context.Context, actor string, tenantID string, token string, reqID string) (tenantOffboarding, error) {
+	if err := validateOffboardTenantID(tenantID); err != nil {
+		return tenantOffboarding{}, err
+	}
+
+	tx, err := o.pool.Begin(ctx)
+	if err != nil {
+		return tenantOffboarding{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	off, tokenSum, err := loadScheduledOffboardingTx(ctx, tx, tenantID)
+	if err != nil {
+		return tenantOffboarding{}, err
+	}
+	got := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	if subtle.ConstantTimeCompare(got[:], tokenSum) != 1 {
+		return tenantOffboarding{}, errOffboardTokenMismatch
+	}
+	now := o.now().UTC()
+	if now.Before(off.PurgeAfter) {
+		return tenantOffboarding{}, errOffboardGracePeriod
+	}
+	_, active, err := loadOffboardTenantTx(ctx, tx, tenantID, true)
+	if err != nil {
+		return tenantOffboarding{}, err
+	}
+	if active {
+		return tenantOffboarding{}, errOffboardTenantActive
+	}
+
+	deleted := map[string]json.RawMessage{}
+	for _, fn := range []string{"orgunit.purge_tenant_data", "person.purge_tenant_data", "iam.purge_tenant_data"} {
+		var counts string
+		if err := tx.QueryRow(ctx, `SELECT `+fn+`($1::uuid)::text`, tenantID).Scan(&counts); err != nil {
+			return tenantOffboarding{}, err
+		}
+		deleted[fn] = json.RawMessage(counts)
+	}
+
+	verification, err := verifyTenantPurgedTx(ctx, tx, tenantID, now)
+	if err != nil {
+		return tenantOffboarding{}, err
+	}
+	if !verification.Clean {
+		return tenantOffboarding{Verification: &verification}, fmt.Errorf("%w: %s", errOffboardResidualData, strings.Join(verification.residualTables(), ","))
+	}
+
+	verificationJSON, err := json.Marshal(verification)
+	if err != nil {
+		return tenantOffboarding{}, err
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE iam.superadmin_tenant_offboarding_requests
+SET status = 'deleted', closed_by = $2, closed_at = $3, verification = $4::jsonb, updated_at = now()
+WHERE tenant_uuid = $1::uuid AND status = 'scheduled'
+`, tenantID, actor, now, verificationJSON); err != nil {
+		return tenantOffboarding{}, err
+	}
+
+	payload, _ := json.Marshal(map[string]any{
+		"tenant_id":      tenantID,
+		"tenant_name":    off.TenantName,
+		"deleted":        deleted,
+		"tables_checked": len(verification.Tables),
+		"clean":          verification.Clean,
+	})
+	if err := insertAudit(ctx, tx, actor, "tenant.offboarding.delete", tenantID, payload, reqID); err != nil {
+		return tenantOffboarding{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return tenantOffboarding{}, err
+	}
+
+	off.Status = offboardStatusDeleted
+	off.ClosedBy = actor
+	off.ClosedAt = &now
+	off.Verification = &verification
+	return off, nil
+}
+
+// Get returns the latest offboarding request for the tenant, including the stored verification report.
+func (o *tenantOffboarder) Get(ctx context.Context, tenantID string) (tenantOffboarding, error) {
+	if err := validateOffboardTenantID(tenantID); err != nil {
+		return tenantOffboarding{}, err
+	}
+
+	tx, err := o.pool.Begin(ctx)
+	if err != nil {
+		return tenantOffboarding{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	off := tenantOffboarding{TenantID: tenantID}
+	var closedBy *string
+	var verification string
+	if err := tx.QueryRow(ctx, `
+SELECT tenant_name, status, reason, requested_by, purge_after, closed_by, closed_at, verification::text
+FROM iam.superadmin_tenant_offboarding_requests
+WHERE tenant_uuid = $1::uuid
+ORDER BY id DESC
+LIMIT 1
+`, tenantID).Scan(&off.TenantName, &off.Status, &off.Reason, &off.RequestedBy, &off.PurgeAfter, &closedBy, &off.ClosedAt, &verification); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return tenantOffboarding{}, errOffboardNotFound
+		}
+		return tenantOffboarding{}, err
+	}
+	if closedBy != nil {
+		off.ClosedBy = *closedBy
+	}
+	if verification != "" && verification != "{}" {
+		var v tenantPurgeVerification
+		if err := json.Unmarshal([]byte(verification), &v); err != nil {
+			return tenantOffboarding{}, err
+		}
+		off.Verification = &v
+	}
+	return off, tx.Commit(ctx)
+}
+
+// Verify re-runs the residual-data scan for a tenant without changing anything.
+func (o *tenantOffboarder) Verify(ctx context.Context, tenantID string) (tenantPurgeVerification, error) {
+	if err := validateOffboardTenantID(tenantID); err != nil {
+		return tenantPurgeVerification{}, err
+	}
+
+	tx, err := o.pool.Begin(ctx)
+	if err != nil {
+		return tenantPurgeVerification{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	v, err := verifyTenantPurgedTx(ctx, tx, tenantID, o.now().UTC())
+	if err != nil {
+		return tenantPurgeVerification{}, err
+	}
+	return v, tx.Commit(ctx)
+}
+
+type tenantScopedTable struct {
+	schema    string
+	name      string
+	rls       bool
+	tenantCol bool
+	readable  bool
+}
+
+// verifyTenantPurgedTx discovers every table that is either under RLS or carries a tenant_uuid column and
+// counts what the tenant can still see. The offboarding requests themselves are excluded: they are the
+// record of the deletion. Tables the runtime role cannot read count as not clean, so a new
+// tenant-scoped table without a grant fails verification instead of being skipped.
+func verifyTenantPurgedTx(ctx context.Context, tx pgx.Tx, tenantID string, now time.Time) (tenantPurgeVerification, error) {
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
+		return tenantPurgeVerification{}, err
+	}
+
+	rows, err := tx.Query(ctx, `
+SELECT n.nspname, c.relname, c.relrowsecurity, a.attname IS NOT NULL, has_table_privilege(c.oid, 'SELECT')
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+LEFT JOIN pg_attribute a ON a.attrelid = c.oid AND a.attname = 'tenant_uuid' AND NOT a.attisdropped
+WHERE c.relkind IN ('r', 'p')
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND (c.relrowsecurity OR a.attname IS NOT NULL)
+  AND NOT (n.nspname = 'iam' AND c.relname = 'superadmin_tenant_offboarding_requests')
+ORDER BY n.nspname, c.relname
+`)
+	if err != nil {
+		return tenantPurgeVerification{}, err
+	}
+	tables := make([]tenantScopedTable, 0, 32)
+	for rows.Next() {
+		var t tenantScopedTable
+		if err := rows.Scan(&t.schema, &t.name, &t.rls, &t.tenantCol, &t.readable); err != nil {
+			rows.Close()
+			return tenantPurgeVerification{}, err
+		}
+		tables = append(tables, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return tenantPurgeVerification{}, err
+	}
+
+	v := tenantPurgeVerification{TenantID: tenantID, VerifiedAt: now, Clean: true}
+	var tenantRows int64
+	if err := tx.QueryRow(ctx, `SELECT count(*) FROM iam.tenants WHERE id = $1::uuid`, tenantID).Scan(&tenantRows); err != nil {
+		return tenantPurgeVerification{}, err
+	}
+	v.Tables = append(v.Tables, tenantPurgeTableCheck{Table: "iam.tenants", Readable: true, Rows: tenantRows})
+
+	for _, t := range tables {
+		check := tenantPurgeTableCheck{Table: t.schema + "." + t.name, RLS: t.rls, Readable: t.readable}
+		if t.readable {
+			sql := "SELECT count(*) FROM " + pgx.Identifier{t.schema, t.name}.Sanitize()
+			args := []any{}
+			if t.tenantCol {
+				sql += " WHERE tenant_uuid = $1::uuid"
+				args = append(args, tenantID)
+			}
+			if err := tx.QueryRow(ctx, sql, args...).Scan(&check.Rows); err != nil {
+				return tenantPurgeVerification{}, err
+			}
+		}
+		v.Tables = append(v.Tables, check)
+	}
+	v.Clean = len(v.residualTables()) == 0
+	return v, nil
+}
+
+func loadOffboardTenantTx(ctx context.Context, tx pgx.Tx, tenantID string, forUpdate bool) (string, bool, error) {
+	sql := `SELECT name, is_active FROM iam.tenants WHERE id = $1::uuid`
+	if forUpdate {
+		sql += ` FOR UPDATE`
+	}
+	var name string
+	var active bool
+	if err := tx.QueryRow(ctx, sql, tenantID).Scan(&name, &active); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", false, errOffboardTenantNotFound
+		}
+		return "", false, err
+	}
+	return name, active, nil
+}
+
+func loadScheduledOffboardingTx(ctx context.Context, tx pgx.Tx, tenantID string) (tenantOffboarding, []byte, error) {
+	off := tenantOffboarding{TenantID: tenantID, Status: offboardStatusScheduled}
+	var tokenSum []byte
+	if err := tx.QueryRow(ctx, `
+SELECT tenant_name, reason, requested_by, purge_after, token_sha256
+FROM iam.superadmin_tenant_offboarding_requests
+WHERE tenant_uuid = $1::uuid AND status = 'scheduled'
+FOR UPDATE
+`, tenantID).Scan(&off.TenantName, &off.Reason, &off.RequestedBy, &off.PurgeAfter, &tokenSum); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return tenantOffboarding{}, nil, errOffboardNotScheduled
+		}
+		return tenantOffboarding{}, nil, err
+	}
+	return off, tokenSum, nil
+}
+
+func validateOffboardTenantID(tenantID string) error {
+	if tenantID == globalTenantID {
+		return errOffboardTenantProtected
+	}
+	if _, err := uuid.Parse(tenantID); err != nil {
+		return errOffboardTenantNotFound
+	}
+	return nil
+}
+
+func tenantOffboardErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, errOffboardTenantNotFound):
+		return http.StatusNotFound, "tenant_not_found"
+	case errors.Is(err, errOffboardTenantProtected):
+		return http.StatusForbidden, "tenant_offboarding_protected"
+	case errors.Is(err, errOffboardTenantActive):
+		return http.StatusConflict, "tenant_offboarding_tenant_active"
+	case errors.Is(err, errOffboardAlreadyScheduled):
+		return http.StatusConflict, "tenant_offboarding_already_scheduled"
+	case errors.Is(err, errOffboardNotScheduled):
+		return http.StatusConflict, "tenant_offboarding_not_scheduled"
+	case errors.Is(err, errOffboardNotFound):
+		return http.StatusNotFound, "tenant_offboarding_not_found"
+	case errors.Is(err, errOffboardGracePeriod):
+		return http.StatusConflict, "tenant_offboarding_grace_period"
+	case errors.Is(err, errOffboardTokenMismatch):
+		return http.StatusForbidden, "tenant_offboarding_token_mismatch"
+	case errors.Is(err, errOffboardResidualData):
+		return http.StatusInternalServerError, "tenant_offboarding_residual_data"
+	case errors.Is(err, errOffboardExportUnreadable):
+		return http.StatusInternalServerError, "tenant_export_table_unreadable"
+	default:
+		return http.StatusInternalServerError, "db_error"
+	}
+}
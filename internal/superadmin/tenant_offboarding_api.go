@@ -0,0 +1,274 @@
+package superadmin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+)
+
+type tenantOffboardSchedulePayload struct {
+	Reason string `json:"reason"`
+}
+
+type tenantOffboardExecutePayload struct {
+	ConfirmationToken string `json:"confirmation_token"`
+}
+
+func tenantIDFromAPIPath(path string) (string, bool) {
+	// /superadmin/api/tenants/{tenant_id}/...
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 5 {
+		return "", false
+	}
+	if parts[0] != "superadmin" || parts[1] != "api" || parts[2] != "tenants" {
+		return "", false
+	}
+	return parts[3], true
+}
+
+func handleTenantExportAPI(w http.ResponseWriter, r *http.Request, offboarder *tenantOffboarder) {
+	p, ok := principalFromContext(r.Context())
+	if !ok || p.ID == "" {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnauthorized, "unauthorized", "unauthorized")
+		return
+	}
+	tenantID, ok := tenantIDFromAPIPath(r.URL.Path)
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_request", "bad request")
+		return
+	}
+
+	archive, manifest, err := offboarder.Export(r.Context(), p.ID, tenantID, requestID(r))
+	if err != nil {
+		status, code := tenantOffboardErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, "")
+		return
+	}
+
+	filename := fmt.Sprintf("tenant-%s-export-v%d-%s.zip", tenantID, manifest.FormatVersion, manifest.ExportedAt.Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(archive)
+}
+
+func handleTenantOffboardingGetAPI(w http.ResponseWriter, r *http.Request, offboarder *tenantOffboarder) {
+	tenantID, ok := tenantIDFromAPIPath(r.URL.Path)
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_request", "bad request")
+		return
+	}
+	off, err := offboarder.Get(r.Context(), tenantID)
+	if err != nil {
+		status, code := tenantOffboardErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, "")
+		return
+	}
+	writeTenantOffboardJSON(w, http.StatusOK, off)
+}
+
+func handleTenantOffboardingVerifyAPI(w http.ResponseWriter, r *http.Request, offboarder *tenantOffboarder) {
+	tenantID, ok := tenantIDFromAPIPath(r.URL.Path)
+	if !ok {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_request", "bad request")
+		return
+	}
+	v, err := offboarder.Verify(r.Context(), tenantID)
+	if err != nil {
+		status, code := tenantOffboardErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, "")
+		return
+	}
+	writeTenantOffboardJSON(w, http.StatusOK, v)
+}
+
+func handleTenantOffboardingScheduleAPI(w http.ResponseWriter, r *http.Request, offboarder *tenantOffboarder) {
+	p, tenantID, ok := tenantOffboardWritePreamble(w, r, routing.RouteClassInternalAPI)
+	if !ok {
+		return
+	}
+
+	var payload tenantOffboardSchedulePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_json", "bad json")
+		return
+	}
+
+	off, err := offboarder.Schedule(r.Context(), p.ID, tenantID, payload.Reason, requestID(r))
+	if err != nil {
+		status, code := tenantOffboardErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, "")
+		return
+	}
+	writeTenantOffboardJSON(w, http.StatusCreated, off)
+}
+
+func handleTenantOffboardingCancelAPI(w http.ResponseWriter, r *http.Request, offboarder *tenantOffboarder) {
+	p, tenantID, ok := tenantOffboardWritePreamble(w, r, routing.RouteClassInternalAPI)
+	if !ok {
+		return
+	}
+
+	off, err := offboarder.Cancel(r.Context(), p.ID, tenantID, requestID(r))
+	if err != nil {
+		status, code := tenantOffboardErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, "")
+		return
+	}
+	writeTenantOffboardJSON(w, http.StatusOK, off)
+}
+
+func handleTenantOffboardingExecuteAPI(w http.ResponseWriter, r *http.Request, offboarder *tenantOffboarder) {
+	p, tenantID, ok := tenantOffboardWritePreamble(w, r, routing.RouteClassInternalAPI)
+	if !ok {
+		return
+	}
+
+	var payload tenantOffboardExecutePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_json", "bad json")
+		return
+	}
+
+	off, err := offboarder.Execute(r.Context(), p.ID, tenantID, payload.ConfirmationToken, requestID(r))
+	if err != nil {
+		status, code := tenantOffboardErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, "")
+		return
+	}
+	writeTenantOffboardJSON(w, http.StatusOK, off)
+}
+
+func handleTenantOffboardingScheduleForm(w http.ResponseWriter, r *http.Request, offboarder *tenantOffboarder) {
+	p, tenantID, ok := tenantOffboardWritePreamble(w, r, routing.RouteClassUI)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		routing.WriteError(w, r, routing.RouteClassUI, http.StatusBadRequest, "bad_request", "bad request")
+		return
+	}
+
+	off, err := offboarder.Schedule(r.Context(), p.ID, tenantID, r.FormValue("reason"), requestID(r))
+	if err != nil {
+		status, code := tenantOffboardErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassUI, status, code, "")
+		return
+	}
+	writeTenantOffboardingPage(w, off)
+}
+
+func handleTenantOffboardingCancelForm(w http.ResponseWriter, r *http.Request, offboarder *tenantOffboarder) {
+	p, tenantID, ok := tenantOffboardWritePreamble(w, r, routing.RouteClassUI)
+	if !ok {
+		return
+	}
+
+	if _, err := offboarder.Cancel(r.Context(), p.ID, tenantID, requestID(r)); err != nil {
+		status, code := tenantOffboardErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassUI, status, code, "")
+		return
+	}
+	http.Redirect(w, r, "/superadmin/tenants", http.StatusFound)
+}
+
+func handleTenantOffboardingExecuteForm(w http.ResponseWriter, r *http.Request, offboarder *tenantOffboarder) {
+	p, tenantID, ok := tenantOffboardWritePreamble(w, r, routing.RouteClassUI)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		routing.WriteError(w, r, routing.RouteClassUI, http.StatusBadRequest, "bad_request", "bad request")
+		return
+	}
+
+	off, err := offboarder.Execute(r.Context(), p.ID, tenantID, r.FormValue("confirmation_token"), requestID(r))
+	if err != nil {
+		status, code := tenantOffboardErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassUI, status, code, "")
+		return
+	}
+	writeTenantOffboardingPage(w, off)
+}
+
+func tenantOffboardWritePreamble(w http.ResponseWriter, r *http.Request, rc routing.RouteClass) (superadminPrincipal, string, bool) {
+	if !superadminWritesEnabled() {
+		routing.WriteError(w, r, rc, http.StatusForbidden, "write_disabled", "write disabled")
+		return superadminPrincipal{}, "", false
+	}
+
+	p, ok := principalFromContext(r.Context())
+	if !ok || p.ID == "" {
+		routing.WriteError(w, r, rc, http.StatusUnauthorized, "unauthorized", "unauthorized")
+		return superadminPrincipal{}, "", false
+	}
+
+	tenantIDFrom := tenantIDFromPath
+	if rc == routing.RouteClassInternalAPI {
+		tenantIDFrom = tenantIDFromAPIPath
+	}
+	tenantID, ok := tenantIDFrom(r.URL.Path)
+	if !ok {
+		routing.WriteError(w, r, rc, http.StatusBadRequest, "bad_request", "bad request")
+		return superadminPrincipal{}, "", false
+	}
+	return p, tenantID, true
+}
+
+func writeTenantOffboardJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeTenantOffboardingPage(w http.ResponseWriter, off tenantOffboarding) {
+	id := html.EscapeString(off.TenantID)
+
+	var b strings.Builder
+	b.WriteString("<h1>SuperAdmin / Tenant Offboarding</h1>")
+	b.WriteString("<p>Tenant: <code>" + id + "</code> " + html.EscapeString(off.TenantName) + "</p>")
+	b.WriteString("<p>Status: <b>" + html.EscapeString(off.Status) + "</b></p>")
+
+	if off.Status == offboardStatusScheduled {
+		b.WriteString("<p>Deletion allowed after: " + html.EscapeString(off.PurgeAfter.Format(time.RFC3339)) + "</p>")
+		if off.ConfirmationToken != "" {
+			b.WriteString("<p>Confirmation token (shown once): <code>" + html.EscapeString(off.ConfirmationToken) + "</code></p>")
+		}
+		b.WriteString(fmt.Sprintf(`<p><a href="/superadmin/api/tenants/%s/export">Download export</a></p>`, id))
+		b.WriteString(fmt.Sprintf(`<form method="POST" action="/superadmin/tenants/%s/offboarding/execute">`, id))
+		b.WriteString(`<label>Confirmation token <input name="confirmation_token" autocomplete="off" /></label> `)
+		b.WriteString(`<button type="submit">Delete permanently</button></form>`)
+		b.WriteString(fmt.Sprintf(`<form method="POST" action="/superadmin/tenants/%s/offboarding/cancel"><button type="submit">Cancel deletion</button></form>`, id))
+	}
+
+	if off.Verification != nil {
+		b.WriteString("<h2>Verification</h2>")
+		if off.Verification.Clean {
+			b.WriteString("<p>No tenant rows remain.</p>")
+		}
+		b.WriteString(`<table border="1" cellpadding="6" cellspacing="0">`)
+		b.WriteString("<thead><tr><th>Table</th><th>RLS</th><th>Rows</th></tr></thead><tbody>")
+		for _, t := range off.Verification.Tables {
+			rows := fmt.Sprintf("%d", t.Rows)
+			if !t.Readable {
+				rows = "unreadable"
+			}
+			rls := "no"
+			if t.RLS {
+				rls = "yes"
+			}
+			b.WriteString("<tr><td>" + html.EscapeString(t.Table) + "</td><td>" + rls + "</td><td>" + rows + "</td></tr>")
+		}
+		b.WriteString("</tbody></table>")
+	}
+	b.WriteString(`<p><a href="/superadmin/tenants">Back to tenants</a></p>`)
+
+	writeHTML(w, "Tenant Offboarding", b.String())
+}
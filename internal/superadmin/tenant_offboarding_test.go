@@ -0,0 +1,670 @@
+package superadmin
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const offboardTenantID = "11111111-1111-1111-1111-111111111111"
+
+type offboardTenant struct {
+	name   string
+	active bool
+}
+
+type offboardRequest struct {
+	status       string
+	reason       string
+	requestedBy  string
+	purgeAfter   time.Time
+	tokenSum     []byte
+	closedBy     *string
+	closedAt     *time.Time
+	verification string
+}
+
+type offboardAudit struct {
+	action   string
+	tenantID string
+	payload  string
+}
+
+// offboardDB fakes the tables used by the offboarding workflow. residual holds per-table row counts seen by
+// the verification scan; purging clears them unless stickyResidual is set.
+type offboardDB struct {
+	tenants        map[string]*offboardTenant
+	requests       []*offboardRequest
+	audits         []offboardAudit
+	purgeCalls     []string
+	sections       map[string][]string
+	exportTables   [][]any
+	tables         [][]any
+	residual       map[string]int64
+	stickyResidual bool
+	beginErr       error
+}
+
+func newOffboardDB() *offboardDB {
+	return &offboardDB{
+		tenants: map[string]*offboardTenant{offboardTenantID: {name: "Acme", active: false}},
+		sections: map[string][]string{
+			`"iam"."principals"`:     {`{"email":"a@acme.local"}`, `{"email":"b@acme.local"}`},
+			`"orgunit"."org_events"`: {`{"id":1}`},
+		},
+		exportTables: [][]any{
+			{"iam", "principals", true, []string{"id"}},
+			{"iam", "sessions", false, []string{"token_sha256"}},
+			{"iam", "webhook_subscriptions", true, []string{"id"}},
+			{"orgunit", "org_events", true, []string{"id"}},
+			{"orgunit", "org_unit_lineage", true, []string{}},
+		},
+		tables: [][]any{
+			{"iam", "principals", false, true, true},
+			{"iam", "role_authz_capabilities", true, false, true},
+			{"orgunit", "org_events", true, true, true},
+		},
+		residual: map[string]int64{`"iam"."principals"`: 2, `"iam"."role_authz_capabilities"`: 3, `"orgunit"."org_events"`: 1},
+	}
+}
+
+func (db *offboardDB) Begin(context.Context) (pgx.Tx, error) {
+	if db.beginErr != nil {
+		return nil, db.beginErr
+	}
+	return &offboardTx{stubTx: &stubTx{}, db: db}, nil
+}
+
+func (db *offboardDB) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return &stubRows{}, nil
+}
+
+// scheduled ignores the tenant: the fake only ever holds requests for offboardTenantID.
+func (db *offboardDB) scheduled(string) *offboardRequest {
+	for _, r := range db.requests {
+		if r.status == offboardStatusScheduled {
+			return r
+		}
+	}
+	return nil
+}
+
+type offboardTx struct {
+	*stubTx
+	db *offboardDB
+}
+
+func (t *offboardTx) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	db := t.db
+	switch {
+	case strings.Contains(sql, "SET TRANSACTION"), strings.Contains(sql, "set_config"):
+	case strings.Contains(sql, "UPDATE iam.superadmin_tenant_offboarding_requests"):
+		r := db.scheduled(args[0].(string))
+		actor := args[1].(string)
+		closedAt := args[2].(time.Time)
+		r.closedBy, r.closedAt = &actor, &closedAt
+		r.status = offboardStatusCancelled
+		if strings.Contains(sql, "'deleted'") {
+			r.status = offboardStatusDeleted
+			r.verification = string(args[3].([]byte))
+		}
+	case strings.Contains(sql, "INSERT INTO iam.superadmin_audit_logs"):
+		db.audits = append(db.audits, offboardAudit{action: args[1].(string), tenantID: args[2].(string), payload: string(args[3].([]byte))})
+	default:
+		return pgconn.CommandTag{}, errors.New("unexpected exec: " + sql)
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+func (t *offboardTx) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	db := t.db
+	switch {
+	case strings.Contains(sql, "SELECT name, is_active FROM iam.tenants"):
+		tenant, ok := db.tenants[args[0].(string)]
+		if !ok {
+			return offboardRow{err: pgx.ErrNoRows}
+		}
+		return offboardRow{vals: []any{tenant.name, tenant.active}}
+	case strings.Contains(sql, "count(*) FROM iam.tenants"):
+		if _, ok := db.tenants[args[0].(string)]; ok {
+			return offboardRow{vals: []any{int64(1)}}
+		}
+		return offboardRow{vals: []any{int64(0)}}
+	case strings.Contains(sql, "SELECT count(*) FROM "):
+		table := strings.Fields(strings.TrimPrefix(sql, "SELECT count(*) FROM "))[0]
+		return offboardRow{vals: []any{db.residual[table]}}
+	case strings.Contains(sql, "INSERT INTO iam.superadmin_tenant_offboarding_requests"):
+		if db.scheduled(args[0].(string)) != nil {
+			return offboardRow{err: pgx.ErrNoRows}
+		}
+		db.requests = append(db.requests, &offboardRequest{
+			status:      offboardStatusScheduled,
+			reason:      args[2].(string),
+			tokenSum:    args[3].([]byte),
+			purgeAfter:  args[4].(time.Time),
+			requestedBy: args[5].(string),
+		})
+		return offboardRow{vals: []any{int64(len(db.requests))}}
+	case strings.Contains(sql, "status = 'scheduled'"):
+		r := db.scheduled(args[0].(string))
+		if r == nil {
+			return offboardRow{err: pgx.ErrNoRows}
+		}
+		return offboardRow{vals: []any{db.tenantName(), r.reason, r.requestedBy, r.purgeAfter, r.tokenSum}}
+	case strings.Contains(sql, "ORDER BY id DESC"):
+		if len(db.requests) == 0 {
+			return offboardRow{err: pgx.ErrNoRows}
+		}
+		r := db.requests[len(db.requests)-1]
+		verification := r.verification
+		if verification == "" {
+			verification = "{}"
+		}
+		return offboardRow{vals: []any{db.tenantName(), r.status, r.reason, r.requestedBy, r.purgeAfter, r.closedBy, r.closedAt, verification}}
+	case strings.Contains(sql, "purge_tenant_data"):
+		db.purgeCalls = append(db.purgeCalls, strings.Fields(sql)[1])
+		if strings.Contains(sql, "iam.purge_tenant_data") {
+			delete(db.tenants, args[0].(string))
+		}
+		if !db.stickyResidual {
+			for k := range db.residual {
+				db.residual[k] = 0
+			}
+		}
+		return offboardRow{vals: []any{`{"rows":1}`}}
+	default:
+		return offboardRow{err: errors.New("unexpected query row: " + sql)}
+	}
+}
+
+func (t *offboardTx) Query(_ context.Context, sql string, _ ...any) (pgx.Rows, error) {
+	if strings.Contains(sql, "indisprimary") {
+		return &stubRows{vals: t.db.exportTables}, nil
+	}
+	if strings.Contains(sql, "FROM pg_class") {
+		return &stubRows{vals: t.db.tables}, nil
+	}
+	for table, lines := range t.db.sections {
+		if strings.Contains(sql, "FROM "+table+" t") {
+			rows := make([][]any, 0, len(lines))
+			for _, line := range lines {
+				rows = append(rows, []any{line})
+			}
+			return &stubRows{vals: rows}, nil
+		}
+	}
+	return &stubRows{}, nil
+}
+
+func (db *offboardDB) tenantName() string { return "Acme" }
+
+type offboardRow struct {
+	vals []any
+	err  error
+}
+
+func (r offboardRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i := range dest {
+		v := reflect.ValueOf(dest[i]).Elem()
+		if r.vals[i] == nil {
+			v.Set(reflect.Zero(v.Type()))
+			continue
+		}
+		v.Set(reflect.ValueOf(r.vals[i]))
+	}
+	return nil
+}
+
+func newTestOffboarder(db *offboardDB, now time.Time) *tenantOffboarder {
+	return &tenantOffboarder{pool: db, grace: time.Hour, now: func() time.Time { return now }}
+}
+
+func auditActions(db *offboardDB) string {
+	out := make([]string, 0, len(db.audits))
+	for _, a := range db.audits {
+		out = append(out, a.action)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestTenantOffboarder_ScheduleThenExecute(t *testing.T) {
+	db := newOffboardDB()
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	o := newTestOffboarder(db, now)
+	ctx := context.Background()
+
+	off, err := o.Schedule(ctx, "actor-1", offboardTenantID, " customer left ", "req-1")
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if off.Status != offboardStatusScheduled || off.ConfirmationToken == "" || !off.PurgeAfter.Equal(now.Add(time.Hour)) || off.Reason != "customer left" {
+		t.Fatalf("off=%+v", off)
+	}
+	sum := sha256.Sum256([]byte(off.ConfirmationToken))
+	if !bytes.Equal(db.requests[0].tokenSum, sum[:]) {
+		t.Fatal("token hash not stored")
+	}
+
+	if _, err := o.Execute(ctx, "actor-1", offboardTenantID, "wrong", "req-2"); !errors.Is(err, errOffboardTokenMismatch) {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := o.Execute(ctx, "actor-1", offboardTenantID, off.ConfirmationToken, "req-2"); !errors.Is(err, errOffboardGracePeriod) {
+		t.Fatalf("err=%v", err)
+	}
+	if len(db.purgeCalls) != 0 {
+		t.Fatalf("purge=%v", db.purgeCalls)
+	}
+
+	o.now = func() time.Time { return now.Add(2 * time.Hour) }
+	done, err := o.Execute(ctx, "actor-2", offboardTenantID, off.ConfirmationToken, "req-3")
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if done.Status != offboardStatusDeleted || done.ClosedBy != "actor-2" || done.Verification == nil || !done.Verification.Clean {
+		t.Fatalf("done=%+v", done)
+	}
+	if len(done.Verification.Tables) != 4 || done.Verification.Tables[0].Table != "iam.tenants" {
+		t.Fatalf("tables=%+v", done.Verification.Tables)
+	}
+	if strings.Join(db.purgeCalls, ",") != "orgunit.purge_tenant_data($1::uuid)::text,person.purge_tenant_data($1::uuid)::text,iam.purge_tenant_data($1::uuid)::text" {
+		t.Fatalf("purge=%v", db.purgeCalls)
+	}
+	if got := auditActions(db); got != "tenant.offboarding.schedule,tenant.offboarding.delete" {
+		t.Fatalf("audits=%s", got)
+	}
+	last := db.audits[len(db.audits)-1]
+	if last.tenantID != offboardTenantID || !strings.Contains(last.payload, offboardTenantID) {
+		t.Fatalf("audit=%+v", last)
+	}
+
+	got, err := o.Get(ctx, offboardTenantID)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if got.Status != offboardStatusDeleted || got.Verification == nil || !got.Verification.Clean || got.ConfirmationToken != "" {
+		t.Fatalf("got=%+v", got)
+	}
+}
+
+func TestTenantOffboarder_ScheduleErrors(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	db := newOffboardDB()
+	o := newTestOffboarder(db, now)
+	if _, err := o.Schedule(ctx, "actor", globalTenantID, "", "r"); !errors.Is(err, errOffboardTenantProtected) {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := o.Schedule(ctx, "actor", "not-a-uuid", "", "r"); !errors.Is(err, errOffboardTenantNotFound) {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := o.Schedule(ctx, "actor", "22222222-2222-2222-2222-222222222222", "", "r"); !errors.Is(err, errOffboardTenantNotFound) {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := o.Schedule(ctx, "actor", offboardTenantID, "", "r"); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := o.Schedule(ctx, "actor", offboardTenantID, "", "r"); !errors.Is(err, errOffboardAlreadyScheduled) {
+		t.Fatalf("err=%v", err)
+	}
+
+	db = newOffboardDB()
+	db.tenants[offboardTenantID].active = true
+	if _, err := newTestOffboarder(db, now).Schedule(ctx, "actor", offboardTenantID, "", "r"); !errors.Is(err, errOffboardTenantActive) {
+		t.Fatalf("err=%v", err)
+	}
+
+	db = newOffboardDB()
+	db.beginErr = errors.New("begin")
+	if _, err := newTestOffboarder(db, now).Schedule(ctx, "actor", offboardTenantID, "", "r"); err == nil || err.Error() != "begin" {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestTenantOffboarder_CancelAndReenabledTenant(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	db := newOffboardDB()
+	o := newTestOffboarder(db, now)
+
+	if _, err := o.Cancel(ctx, "actor", offboardTenantID, "r"); !errors.Is(err, errOffboardNotScheduled) {
+		t.Fatalf("err=%v", err)
+	}
+	off, err := o.Schedule(ctx, "actor", offboardTenantID, "", "r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelled, err := o.Cancel(ctx, "actor-2", offboardTenantID, "r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cancelled.Status != offboardStatusCancelled || cancelled.ClosedBy != "actor-2" {
+		t.Fatalf("cancelled=%+v", cancelled)
+	}
+	if _, err := o.Execute(ctx, "actor", offboardTenantID, off.ConfirmationToken, "r"); !errors.Is(err, errOffboardNotScheduled) {
+		t.Fatalf("err=%v", err)
+	}
+
+	off, err = o.Schedule(ctx, "actor", offboardTenantID, "", "r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.tenants[offboardTenantID].active = true
+	o.now = func() time.Time { return now.Add(2 * time.Hour) }
+	if _, err := o.Execute(ctx, "actor", offboardTenantID, off.ConfirmationToken, "r"); !errors.Is(err, errOffboardTenantActive) {
+		t.Fatalf("err=%v", err)
+	}
+	if got := auditActions(db); got != "tenant.offboarding.schedule,tenant.offboarding.cancel,tenant.offboarding.schedule" {
+		t.Fatalf("audits=%s", got)
+	}
+}
+
+func TestTenantOffboarder_ExecuteResidualDataFails(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	db := newOffboardDB()
+	db.stickyResidual = true
+	o := newTestOffboarder(db, now)
+
+	off, err := o.Schedule(ctx, "actor", offboardTenantID, "", "r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.now = func() time.Time { return now.Add(2 * time.Hour) }
+	res, err := o.Execute(ctx, "actor", offboardTenantID, off.ConfirmationToken, "r")
+	if !errors.Is(err, errOffboardResidualData) {
+		t.Fatalf("err=%v", err)
+	}
+	if !strings.Contains(err.Error(), "iam.principals") || res.Verification == nil || res.Verification.Clean {
+		t.Fatalf("err=%v res=%+v", err, res)
+	}
+	if got := auditActions(db); got != "tenant.offboarding.schedule" {
+		t.Fatalf("audits=%s", got)
+	}
+}
+
+func TestTenantOffboarder_VerifyFlagsUnreadableTables(t *testing.T) {
+	db := newOffboardDB()
+	db.tenants = map[string]*offboardTenant{}
+	db.residual = map[string]int64{}
+	db.tables = append(db.tables, []any{"person", "people", true, true, false})
+
+	v, err := newTestOffboarder(db, time.Now()).Verify(context.Background(), offboardTenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Clean || strings.Join(v.residualTables(), ",") != "person.people" {
+		t.Fatalf("v=%+v", v)
+	}
+
+	db.tables = db.tables[:3]
+	v, err = newTestOffboarder(db, time.Now()).Verify(context.Background(), offboardTenantID)
+	if err != nil || !v.Clean {
+		t.Fatalf("v=%+v err=%v", v, err)
+	}
+}
+
+func TestTenantOffboarder_ExportArchive(t *testing.T) {
+	db := newOffboardDB()
+	db.tenants[offboardTenantID].active = true
+	o := newTestOffboarder(db, time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+
+	archive, manifest, err := o.Export(context.Background(), "actor", offboardTenantID, "req-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.FormatVersion != tenantExportFormatVersion || manifest.TenantName != "Acme" || len(manifest.Sections) != len(tenantExportFixedSections)+4 {
+		t.Fatalf("manifest=%+v", manifest)
+	}
+	names := make([]string, 0, len(manifest.Sections))
+	for _, s := range manifest.Sections {
+		names = append(names, s.Name)
+	}
+	if got := strings.Join(names, ","); got != "iam.tenants,iam.role_authz_capabilities,iam.principals,iam.webhook_subscriptions,orgunit.org_events,orgunit.org_unit_lineage" {
+		t.Fatalf("sections=%s", got)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		files[f.Name] = string(b)
+	}
+	if files["iam.principals.ndjson"] != "{\"email\":\"a@acme.local\"}\n{\"email\":\"b@acme.local\"}\n" {
+		t.Fatalf("principals=%q", files["iam.principals.ndjson"])
+	}
+
+	var stored tenantExportManifest
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &stored); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range stored.Sections {
+		sum := sha256.Sum256([]byte(files[s.File]))
+		if s.SHA256 != hex.EncodeToString(sum[:]) {
+			t.Fatalf("section %s hash mismatch", s.Name)
+		}
+		if s.Name == "iam.principals" && s.Rows != 2 || s.Name == "orgunit.org_events" && s.Rows != 1 {
+			t.Fatalf("section=%+v", s)
+		}
+	}
+	if got := auditActions(db); got != "tenant.export" || db.audits[0].tenantID != offboardTenantID {
+		t.Fatalf("audits=%+v", db.audits)
+	}
+
+	if _, _, err := o.Export(context.Background(), "actor", "22222222-2222-2222-2222-222222222222", "r"); !errors.Is(err, errOffboardTenantNotFound) {
+		t.Fatalf("err=%v", err)
+	}
+
+	db.exportTables = append(db.exportTables, []any{"person", "people", false, []string{"id"}})
+	if _, _, err := o.Export(context.Background(), "actor", offboardTenantID, "r"); !errors.Is(err, errOffboardExportUnreadable) || !strings.Contains(err.Error(), "person.people") {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestTenantTableExportSection(t *testing.T) {
+	s := tenantTableExportSection("iam", "webhook_subscriptions", []string{"tenant_uuid", "id"})
+	if s.Name != "iam.webhook_subscriptions" || s.SQL != `SELECT (to_jsonb(t) - 'secret')::text FROM "iam"."webhook_subscriptions" t WHERE t.tenant_uuid = $1::uuid ORDER BY t."tenant_uuid", t."id"` {
+		t.Fatalf("section=%+v", s)
+	}
+	s = tenantTableExportSection("orgunit", "org_unit_lineage", nil)
+	if s.SQL != `SELECT (to_jsonb(t))::text FROM "orgunit"."org_unit_lineage" t WHERE t.tenant_uuid = $1::uuid ORDER BY 1` {
+		t.Fatalf("section=%+v", s)
+	}
+}
+
+func TestTenantOffboardErrorStatus(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{errOffboardTenantNotFound, http.StatusNotFound, "tenant_not_found"},
+		{errOffboardTenantProtected, http.StatusForbidden, "tenant_offboarding_protected"},
+		{errOffboardTenantActive, http.StatusConflict, "tenant_offboarding_tenant_active"},
+		{errOffboardAlreadyScheduled, http.StatusConflict, "tenant_offboarding_already_scheduled"},
+		{errOffboardNotScheduled, http.StatusConflict, "tenant_offboarding_not_scheduled"},
+		{errOffboardNotFound, http.StatusNotFound, "tenant_offboarding_not_found"},
+		{errOffboardGracePeriod, http.StatusConflict, "tenant_offboarding_grace_period"},
+		{errOffboardTokenMismatch, http.StatusForbidden, "tenant_offboarding_token_mismatch"},
+		{errOffboardResidualData, http.StatusInternalServerError, "tenant_offboarding_residual_data"},
+		{errOffboardExportUnreadable, http.StatusInternalServerError, "tenant_export_table_unreadable"},
+		{errors.New("boom"), http.StatusInternalServerError, "db_error"},
+	}
+	for _, tc := range cases {
+		status, code := tenantOffboardErrorStatus(tc.err)
+		if status != tc.status || code != tc.code {
+			t.Fatalf("err=%v status=%d code=%s", tc.err, status, code)
+		}
+	}
+}
+
+func TestTenantDeleteGracePeriodFromEnv(t *testing.T) {
+	t.Setenv("SUPERADMIN_TENANT_DELETE_GRACE", "")
+	if d, err := tenantDeleteGracePeriodFromEnv(); err != nil || d != defaultTenantDeleteGracePeriod {
+		t.Fatalf("d=%v err=%v", d, err)
+	}
+	t.Setenv("SUPERADMIN_TENANT_DELETE_GRACE", "48h")
+	if d, err := tenantDeleteGracePeriodFromEnv(); err != nil || d != 48*time.Hour {
+		t.Fatalf("d=%v err=%v", d, err)
+	}
+	t.Setenv("SUPERADMIN_TENANT_DELETE_GRACE", "soon")
+	if _, err := tenantDeleteGracePeriodFromEnv(); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := newTenantOffboarder(newOffboardDB()); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestTenantOffboardingAPI(t *testing.T) {
+	t.Setenv("SUPERADMIN_TENANT_DELETE_GRACE", "0s")
+	db := newOffboardDB()
+	a := newAuthedHandlerWithOptions(t, HandlerOptions{
+		Pool:         db,
+		DictBaseline: &fakeDictPublisher{},
+		AdminInviter: &fakeAdminInviter{},
+		OrgUnits:     &fakeOrgUnitWriter{},
+	})
+	base := "/superadmin/api/tenants/" + offboardTenantID
+
+	rec := httptest.NewRecorder()
+	a.h.ServeHTTP(rec, a.newRequest(http.MethodGet, base+"/offboarding", nil))
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "tenant_offboarding_not_found") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	a.h.ServeHTTP(rec, a.newRequest(http.MethodGet, base+"/export", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/zip" || !strings.Contains(rec.Header().Get("Content-Disposition"), "export-v2") {
+		t.Fatalf("status=%d headers=%v", rec.Code, rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	a.h.ServeHTTP(rec, a.newRequest(http.MethodPost, base+"/offboarding", strings.NewReader(`{"reason":"churn"}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	var scheduled tenantOffboarding
+	if err := json.Unmarshal(rec.Body.Bytes(), &scheduled); err != nil || scheduled.ConfirmationToken == "" {
+		t.Fatalf("body=%s err=%v", rec.Body.String(), err)
+	}
+
+	rec = httptest.NewRecorder()
+	a.h.ServeHTTP(rec, a.newRequest(http.MethodPost, base+"/offboarding", nil))
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "tenant_offboarding_already_scheduled") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	a.h.ServeHTTP(rec, a.newRequest(http.MethodPost, base+"/offboarding/execute", strings.NewReader(`{`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	a.h.ServeHTTP(rec, a.newRequest(http.MethodPost, base+"/offboarding/execute", strings.NewReader(`{"confirmation_token":"`+scheduled.ConfirmationToken+`"}`)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"status":"deleted"`) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	a.h.ServeHTTP(rec, a.newRequest(http.MethodGet, base+"/offboarding/verification", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"clean":true`) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	a.h.ServeHTTP(rec, a.newRequest(http.MethodPost, base+"/offboarding/cancel", nil))
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "tenant_offboarding_not_scheduled") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	t.Setenv("SUPERADMIN_WRITE_MODE", "disabled")
+	rec = httptest.NewRecorder()
+	a.h.ServeHTTP(rec, a.newRequest(http.MethodPost, base+"/offboarding", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status=%d", rec.Code)
+	}
+}
+
+func TestTenantOffboardingForms(t *testing.T) {
+	t.Setenv("SUPERADMIN_TENANT_DELETE_GRACE", "0s")
+	db := newOffboardDB()
+	a := newAuthedHandlerWithOptions(t, HandlerOptions{
+		Pool:         db,
+		DictBaseline: &fakeDictPublisher{},
+		AdminInviter: &fakeAdminInviter{},
+		OrgUnits:     &fakeOrgUnitWriter{},
+	})
+	base := "/superadmin/tenants/" + offboardTenantID
+
+	post := func(path string, form url.Values) *httptest.ResponseRecorder {
+		req := a.newRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		a.h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(base+"/offboarding", url.Values{"reason": {"churn"}})
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Confirmation token (shown once)") || !strings.Contains(rec.Body.String(), "/offboarding/execute") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = post(base+"/offboarding/cancel", nil)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	o := newTestOffboarder(db, time.Now())
+	o.grace = 0
+	off, err := o.Schedule(context.Background(), "actor", offboardTenantID, "", "r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec = post(base+"/offboarding/execute", url.Values{"confirmation_token": {"nope"}})
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	rec = post(base+"/offboarding/execute", url.Values{"confirmation_token": {off.ConfirmationToken}})
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "No tenant rows remain.") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTenantIDFromAPIPath(t *testing.T) {
+	if id, ok := tenantIDFromAPIPath("/superadmin/api/tenants/t1/export"); !ok || id != "t1" {
+		t.Fatalf("id=%q ok=%v", id, ok)
+	}
+	for _, p := range []string{"/superadmin/api/tenants/t1", "/superadmin/tenants/t1/x/y", "/x/api/tenants/t1/export"} {
+		if _, ok := tenantIDFromAPIPath(p); ok {
+			t.Fatalf("path=%s", p)
+		}
+	}
+}
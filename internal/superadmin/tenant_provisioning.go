@@ -0,0 +1,634 @@
+package superadmin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	iammodule "github.com/jacksonlee411/Bugs-And-Blossoms/modules/iam"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/iam/infrastructure/kratos"
+	orgunitservices "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/services"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/authz"
+)
+
+const (
+	provisionStepCreateTenant  = "create_tenant"
+	provisionStepBindDomain    = "bind_primary_domain"
+	provisionStepDictBaseline  = "publish_dict_baseline"
+	provisionStepSeedRoles     = "seed_builtin_roles"
+	provisionStepInviteAdmin   = "invite_first_admin"
+	provisionStepCreateRootOrg = "create_root_org"
+
+	provisionStatusPending   = "pending"
+	provisionStatusRunning   = "running"
+	provisionStatusSucceeded = "succeeded"
+	provisionStatusFailed    = "failed"
+	provisionStatusSkipped   = "skipped"
+
+	provisionDictReleaseID = "tenant-provisioning"
+
+	// provisionClaimTTL bounds how long a runner owns a run without recording a step. A runner that dies
+	// mid-run blocks retries of the same request_id for at most this long.
+	provisionClaimTTL = 5 * time.Minute
+)
+
+var provisionSteps = []string{
+	provisionStepCreateTenant,
+	provisionStepBindDomain,
+	provisionStepDictBaseline,
+	provisionStepSeedRoles,
+	provisionStepInviteAdmin,
+	provisionStepCreateRootOrg,
+}
+
+var (
+	errProvisionInvalidInput    = errors.New("superadmin: invalid provisioning input")
+	errProvisionInvalidHostname = errors.New("superadmin: invalid provisioning hostname")
+	errProvisionInvalidAsOf     = errors.New("superadmin: invalid provisioning as_of")
+	errProvisionRequestConflict = errors.New("superadmin: provisioning request_id reused with different input")
+	errProvisionNotFound        = errors.New("superadmin: provisioning run not found")
+	errProvisionHostnameTaken   = errors.New("superadmin: hostname is bound to another tenant")
+	errProvisionInProgress      = errors.New("superadmin: provisioning run is already in progress")
+	errProvisionRolesMissing    = errors.New("superadmin: built-in roles are missing after seeding")
+)
+
+type tenantProvisionInput struct {
+	Name        string `json:"name"`
+	Hostname    string `json:"hostname"`
+	AdminEmail  string `json:"admin_email"`
+	AsOf        string `json:"as_of"`
+	RootOrgCode string `json:"root_org_code,omitempty"`
+	RootOrgName string `json:"root_org_name,omitempty"`
+}
+
+type tenantProvisionStep struct {
+	Step       string         `json:"step"`
+	Status     string         `json:"status"`
+	Detail     map[string]any `json:"detail,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+}
+
+type tenantProvisionRun struct {
+	RequestID string                `json:"request_id"`
+	TenantID  string                `json:"tenant_id,omitempty"`
+	Status    string                `json:"status"`
+	Input     tenantProvisionInput  `json:"input"`
+	Steps     []tenantProvisionStep `json:"steps"`
+	// InitialAdminPassword is only returned by the run that completed the invite step and is never persisted.
+	InitialAdminPassword string `json:"initial_admin_password,omitempty"`
+
+	claimToken string
+}
+
+type dictBaselinePublisher interface {
+	PublishBaseline(ctx context.Context, req iammodule.DictBaselineReleaseRequest) (iammodule.DictBaselineReleaseResult, error)
+}
+
+// tenantAdminInvite carries the one-time password of the admin identity. AlreadyExists means the identity
+// was there before this call and its password was reset to InitialPassword.
+type tenantAdminInvite struct {
+	KratosIdentityID string
+	InitialPassword  string
+	AlreadyExists    bool
+}
+
+type tenantAdminInviter interface {
+	InviteTenantAdmin(ctx context.Context, tenantID string, email string) (tenantAdminInvite, error)
+}
+
+type orgUnitWriter interface {
+	Write(ctx context.Context, tenantID string, req orgunitservices.WriteOrgUnitRequest) (orgunitservices.OrgUnitWriteResult, error)
+}
+
+type tenantProvisioner struct {
+	pool   pgBeginner
+	dicts  dictBaselinePublisher
+	admins tenantAdminInviter
+	orgs   orgUnitWriter
+}
+
+// Run executes the provisioning steps in order. A retry with the same request_id resumes after the last
+// succeeded step; every step is idempotent on its own so a crash between executing and recording a step is safe.
+// A retry that arrives while another caller holds the claim on the same request_id fails with
+// errProvisionInProgress instead of running the steps twice (the invite step would reset the password the
+// other caller is about to return). Each step runs and is recorded in its own short transaction.
+func (p *tenantProvisioner) Run(ctx context.Context, actor string, requestID string, in tenantProvisionInput) (tenantProvisionRun, error) {
+	in, err := normalizeTenantProvisionInput(in)
+	if err != nil {
+		return tenantProvisionRun{}, err
+	}
+	requestID = strings.TrimSpace(requestID)
+	if requestID == "" {
+		return tenantProvisionRun{}, errProvisionInvalidInput
+	}
+
+	run, err := p.startRun(ctx, actor, requestID, in)
+	if err != nil {
+		return tenantProvisionRun{}, err
+	}
+
+	for i := range run.Steps {
+		step := &run.Steps[i]
+		if step.Status == provisionStatusSucceeded || step.Status == provisionStatusSkipped {
+			continue
+		}
+		detail, stepErr := p.execStep(ctx, actor, &run, step.Step)
+		now := time.Now().UTC()
+		step.FinishedAt = &now
+		step.Detail = detail
+		if stepErr != nil {
+			step.Status = provisionStatusFailed
+			step.Error = stepErr.Error()
+			run.Status = provisionStatusFailed
+		} else {
+			step.Status = provisionStatusSucceeded
+			step.Error = ""
+			if allProvisionStepsDone(run.Steps) {
+				run.Status = provisionStatusSucceeded
+			}
+		}
+		if err := p.recordStep(ctx, actor, run, *step); err != nil {
+			return tenantProvisionRun{}, err
+		}
+		if stepErr != nil {
+			return run, nil
+		}
+	}
+	return run, nil
+}
+
+func (p *tenantProvisioner) Get(ctx context.Context, requestID string) (tenantProvisionRun, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return tenantProvisionRun{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	run, err := loadTenantProvisionRunTx(ctx, tx, strings.TrimSpace(requestID), false)
+	if err != nil {
+		return tenantProvisionRun{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return tenantProvisionRun{}, err
+	}
+	return run, nil
+}
+
+func (p *tenantProvisioner) startRun(ctx context.Context, actor string, requestID string, in tenantProvisionInput) (tenantProvisionRun, error) {
+	inputJSON, _ := json.Marshal(in)
+	stepsJSON, _ := json.Marshal(newTenantProvisionSteps(in))
+
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return tenantProvisionRun{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if _, err := tx.Exec(ctx, `
+INSERT INTO iam.superadmin_tenant_provisioning_runs(request_id, input, steps, actor)
+VALUES ($1, $2::jsonb, $3::jsonb, $4)
+ON CONFLICT (request_id) DO NOTHING
+`, requestID, inputJSON, stepsJSON, actor); err != nil {
+		return tenantProvisionRun{}, err
+	}
+
+	run, err := loadTenantProvisionRunTx(ctx, tx, requestID, true)
+	if err != nil {
+		return tenantProvisionRun{}, err
+	}
+	if run.Input != in {
+		return tenantProvisionRun{}, errProvisionRequestConflict
+	}
+	if run.Status != provisionStatusSucceeded {
+		if err := claimTenantProvisionRunTx(ctx, tx, &run); err != nil {
+			return tenantProvisionRun{}, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return tenantProvisionRun{}, err
+	}
+	return run, nil
+}
+
+func (p *tenantProvisioner) execStep(ctx context.Context, actor string, run *tenantProvisionRun, step string) (map[string]any, error) {
+	switch step {
+	case provisionStepCreateTenant:
+		tenantID, created, err := p.createTenant(ctx, run.RequestID, run.Input.Name)
+		if err != nil {
+			return nil, err
+		}
+		run.TenantID = tenantID
+		return map[string]any{"tenant_id": tenantID, "created": created}, nil
+	case provisionStepBindDomain:
+		bound, err := p.bindPrimaryDomain(ctx, run.TenantID, run.Input.Hostname)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"hostname": run.Input.Hostname, "created": bound}, nil
+	case provisionStepDictBaseline:
+		result, err := p.dicts.PublishBaseline(ctx, iammodule.DictBaselineReleaseRequest{
+			TargetTenantID: run.TenantID,
+			AsOf:           run.Input.AsOf,
+			ReleaseID:      provisionDictReleaseID,
+			RequestID:      run.RequestID + "#" + provisionStepDictBaseline,
+			Operator:       actor,
+			Initiator:      actor,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{
+			"task_id":              result.TaskID,
+			"dict_events_applied":  result.DictEventsApplied,
+			"dict_events_retried":  result.DictEventsRetried,
+			"value_events_applied": result.ValueEventsApplied,
+			"value_events_retried": result.ValueEventsRetried,
+		}, nil
+	case provisionStepSeedRoles:
+		roles, err := p.seedBuiltinRoles(ctx, run.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"roles": roles}, nil
+	case provisionStepInviteAdmin:
+		invite, err := p.admins.InviteTenantAdmin(ctx, run.TenantID, run.Input.AdminEmail)
+		if err != nil {
+			return nil, err
+		}
+		run.InitialAdminPassword = invite.InitialPassword
+		return map[string]any{
+			"email":              run.Input.AdminEmail,
+			"kratos_identity_id": invite.KratosIdentityID,
+			"already_exists":     invite.AlreadyExists,
+		}, nil
+	case provisionStepCreateRootOrg:
+		name := run.Input.RootOrgName
+		isBusinessUnit := true
+		result, err := p.orgs.Write(ctx, run.TenantID, orgunitservices.WriteOrgUnitRequest{
+			Intent:        "create_org",
+			OrgCode:       run.Input.RootOrgCode,
+			EffectiveDate: run.Input.AsOf,
+			RequestID:     run.RequestID + "#" + provisionStepCreateRootOrg,
+			Patch:         orgunitservices.OrgUnitWritePatch{Name: &name, IsBusinessUnit: &isBusinessUnit},
+			InitiatorUUID: actor,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"org_code": result.OrgCode, "effective_date": result.EffectiveDate}, nil
+	default:
+		return nil, errors.New("superadmin: unknown provisioning step " + step)
+	}
+}
+
+func (p *tenantProvisioner) createTenant(ctx context.Context, requestID string, name string) (string, bool, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	// The run row lock serialises concurrent retries so at most one tenant is created per request_id.
+	var tenantID string
+	if err := tx.QueryRow(ctx, `
+SELECT COALESCE(tenant_uuid::text, '')
+FROM iam.superadmin_tenant_provisioning_runs
+WHERE request_id = $1
+FOR UPDATE
+`, requestID).Scan(&tenantID); err != nil {
+		return "", false, err
+	}
+	if tenantID != "" {
+		return tenantID, false, nil
+	}
+
+	if err := tx.QueryRow(ctx, `
+INSERT INTO iam.tenants(name, is_active)
+VALUES ($1, true)
+RETURNING id::text
+`, name).Scan(&tenantID); err != nil {
+		return "", false, err
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE iam.superadmin_tenant_provisioning_runs
+SET tenant_uuid = $2::uuid, updated_at = now()
+WHERE request_id = $1
+`, requestID, tenantID); err != nil {
+		return "", false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return "", false, err
+	}
+	return tenantID, true, nil
+}
+
+func (p *tenantProvisioner) bindPrimaryDomain(ctx context.Context, tenantID string, hostname string) (bool, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	var owner string
+	err = tx.QueryRow(ctx, `
+SELECT tenant_uuid::text
+FROM iam.tenant_domains
+WHERE hostname = $1
+`, hostname).Scan(&owner)
+	switch {
+	case err == nil && owner == tenantID:
+		return false, nil
+	case err == nil:
+		return false, errProvisionHostnameTaken
+	case !errors.Is(err, pgx.ErrNoRows):
+		return false, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+INSERT INTO iam.tenant_domains(tenant_uuid, hostname, is_primary)
+VALUES ($1::uuid, $2, true)
+`, tenantID, hostname); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// seedBuiltinRoles re-applies the built-in role seed for the tenant and checks that both roles are there.
+// The tenants_seed_builtin_authz_roles trigger normally seeds them with the tenant row already; the seed is
+// an upsert, so running it again only makes the step visible in the run and the audit log.
+func (p *tenantProvisioner) seedBuiltinRoles(ctx context.Context, tenantID string) ([]string, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `SELECT iam.seed_builtin_authz_roles($1::uuid);`, tenantID); err != nil {
+		return nil, err
+	}
+	roles := []string{authz.RoleTenantAdmin, authz.RoleTenantViewer}
+	var seeded bool
+	if err := tx.QueryRow(ctx, `
+SELECT count(*) = cardinality($2::text[])
+FROM iam.role_definitions
+WHERE tenant_uuid = $1::uuid
+  AND role_slug = ANY($2::text[])
+  AND system_managed
+`, tenantID, roles).Scan(&seeded); err != nil {
+		return nil, err
+	}
+	if !seeded {
+		return nil, errProvisionRolesMissing
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (p *tenantProvisioner) recordStep(ctx context.Context, actor string, run tenantProvisionRun, step tenantProvisionStep) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if err := updateTenantProvisionRunTx(ctx, tx, run); err != nil {
+		return err
+	}
+	payload, _ := json.Marshal(map[string]any{
+		"step":   step.Step,
+		"status": step.Status,
+		"detail": step.Detail,
+		"error":  step.Error,
+	})
+	if err := insertAudit(ctx, tx, actor, "tenant.provision."+step.Step, run.TenantID, payload, run.RequestID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func loadTenantProvisionRunTx(ctx context.Context, tx pgx.Tx, requestID string, forUpdate bool) (tenantProvisionRun, error) {
+	query := `
+SELECT COALESCE(tenant_uuid::text, ''), status, input::text, steps::text
+FROM iam.superadmin_tenant_provisioning_runs
+WHERE request_id = $1
+`
+	if forUpdate {
+		query += "FOR UPDATE\n"
+	}
+	run := tenantProvisionRun{RequestID: requestID}
+	var inputJSON string
+	var stepsJSON string
+	if err := tx.QueryRow(ctx, query, requestID).Scan(&run.TenantID, &run.Status, &inputJSON, &stepsJSON); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return tenantProvisionRun{}, errProvisionNotFound
+		}
+		return tenantProvisionRun{}, err
+	}
+	if err := json.Unmarshal([]byte(inputJSON), &run.Input); err != nil {
+		return tenantProvisionRun{}, err
+	}
+	if err := json.Unmarshal([]byte(stepsJSON), &run.Steps); err != nil {
+		return tenantProvisionRun{}, err
+	}
+	return run, nil
+}
+
+// claimTenantProvisionRunTx marks the run as running and claims it for this runner unless another runner
+// holds an unexpired claim.
+func claimTenantProvisionRunTx(ctx context.Context, tx pgx.Tx, run *tenantProvisionRun) error {
+	token, err := randomURLToken(18)
+	if err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, `
+UPDATE iam.superadmin_tenant_provisioning_runs
+SET status = 'running', claim_token = $2, claimed_until = now() + make_interval(secs => $3), updated_at = now()
+WHERE request_id = $1
+  AND (claim_token IS NULL OR claimed_until <= now())
+`, run.RequestID, token, provisionClaimTTL.Seconds())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errProvisionInProgress
+	}
+	run.Status = provisionStatusRunning
+	run.claimToken = token
+	return nil
+}
+
+// updateTenantProvisionRunTx records the run under this runner's claim. The claim is renewed while the run is
+// still running and released once it has succeeded or failed. A runner whose claim was taken over gets
+// errProvisionInProgress.
+func updateTenantProvisionRunTx(ctx context.Context, tx pgx.Tx, run tenantProvisionRun) error {
+	stepsJSON, _ := json.Marshal(run.Steps)
+	tag, err := tx.Exec(ctx, `
+UPDATE iam.superadmin_tenant_provisioning_runs
+SET status = $2::text,
+    steps = $3::jsonb,
+    claim_token = CASE WHEN $2::text = 'running' THEN claim_token END,
+    claimed_until = CASE WHEN $2::text = 'running' THEN now() + make_interval(secs => $5) END,
+    updated_at = now()
+WHERE request_id = $1
+  AND claim_token = $4
+`, run.RequestID, run.Status, stepsJSON, run.claimToken, provisionClaimTTL.Seconds())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errProvisionInProgress
+	}
+	return nil
+}
+
+func newTenantProvisionSteps(in tenantProvisionInput) []tenantProvisionStep {
+	steps := make([]tenantProvisionStep, 0, len(provisionSteps))
+	for _, step := range provisionSteps {
+		status := provisionStatusPending
+		if step == provisionStepCreateRootOrg && in.RootOrgCode == "" {
+			status = provisionStatusSkipped
+		}
+		steps = append(steps, tenantProvisionStep{Step: step, Status: status})
+	}
+	return steps
+}
+
+func allProvisionStepsDone(steps []tenantProvisionStep) bool {
+	for _, step := range steps {
+		if step.Status != provisionStatusSucceeded && step.Status != provisionStatusSkipped {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeTenantProvisionInput(in tenantProvisionInput) (tenantProvisionInput, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Hostname = strings.ToLower(strings.TrimSpace(in.Hostname))
+	in.AdminEmail = strings.ToLower(strings.TrimSpace(in.AdminEmail))
+	in.AsOf = strings.TrimSpace(in.AsOf)
+	in.RootOrgCode = strings.TrimSpace(in.RootOrgCode)
+	in.RootOrgName = strings.TrimSpace(in.RootOrgName)
+
+	if in.Name == "" || in.Hostname == "" || in.AdminEmail == "" {
+		return tenantProvisionInput{}, errProvisionInvalidInput
+	}
+	if strings.Contains(in.Hostname, ":") || strings.ContainsAny(in.Hostname, " \t\r\n") {
+		return tenantProvisionInput{}, errProvisionInvalidHostname
+	}
+	if !strings.Contains(in.AdminEmail, "@") || strings.ContainsAny(in.AdminEmail, " \t\r\n") {
+		return tenantProvisionInput{}, errProvisionInvalidInput
+	}
+	if _, err := time.Parse("2006-01-02", in.AsOf); err != nil {
+		return tenantProvisionInput{}, errProvisionInvalidAsOf
+	}
+	if in.RootOrgCode == "" {
+		in.RootOrgName = ""
+	} else if in.RootOrgName == "" {
+		return tenantProvisionInput{}, errProvisionInvalidInput
+	}
+	return in, nil
+}
+
+func tenantProvisionErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, errProvisionInvalidHostname):
+		return http.StatusBadRequest, "invalid_hostname"
+	case errors.Is(err, errProvisionInvalidAsOf):
+		return http.StatusBadRequest, "invalid_as_of"
+	case errors.Is(err, errProvisionInvalidInput):
+		return http.StatusBadRequest, "invalid_input"
+	case errors.Is(err, errProvisionRequestConflict):
+		return http.StatusConflict, "tenant_provision_request_conflict"
+	case errors.Is(err, errProvisionInProgress):
+		return http.StatusConflict, "tenant_provision_in_progress"
+	case errors.Is(err, errProvisionNotFound):
+		return http.StatusNotFound, "tenant_provision_not_found"
+	default:
+		return http.StatusInternalServerError, "db_error"
+	}
+}
+
+type kratosTenantAdminInviter struct {
+	client *kratos.AdminClient
+}
+
+func newKratosTenantAdminInviterFromEnv() (tenantAdminInviter, error) {
+	adminURL := strings.TrimSpace(os.Getenv("KRATOS_ADMIN_URL"))
+	if adminURL == "" {
+		adminURL = "http://127.0.0.1:4434"
+	}
+	c, err := kratos.NewAdmin(adminURL)
+	if err != nil {
+		return nil, err
+	}
+	return &kratosTenantAdminInviter{client: c}, nil
+}
+
+// InviteTenantAdmin creates the tenant-admin identity with a one-time initial password. The principal row is
+// created on first login from the identity traits, so nothing is written to iam.principals here.
+//
+// The invite step only runs again when its success was never recorded, and then nobody has seen the first
+// password: it was only ever in the response of the run that failed. An identity that already exists (the
+// identifier is scoped to the tenant this run created) therefore gets a fresh password instead.
+func (i *kratosTenantAdminInviter) InviteTenantAdmin(ctx context.Context, tenantID string, email string) (tenantAdminInvite, error) {
+	password, err := generateInitialPassword()
+	if err != nil {
+		return tenantAdminInvite{}, err
+	}
+	identifier := tenantID + ":" + email
+	ident, err := i.client.CreateIdentity(ctx, kratos.CreateIdentityRequest{
+		Traits: map[string]any{
+			"tenant_uuid": tenantID,
+			"email":       email,
+			"role_slug":   authz.RoleTenantAdmin,
+		},
+		Identifier: identifier,
+		Password:   password,
+	})
+	if err == nil {
+		return tenantAdminInvite{KratosIdentityID: ident.ID, InitialPassword: password}, nil
+	}
+	if he, ok := errors.AsType[*kratos.HTTPError](err); !ok || he.StatusCode != http.StatusConflict {
+		return tenantAdminInvite{}, err
+	}
+	existing, found, err := i.client.FindIdentityByIdentifier(ctx, identifier)
+	if err != nil {
+		return tenantAdminInvite{}, err
+	}
+	if !found {
+		return tenantAdminInvite{}, errors.New("superadmin: tenant admin identity conflicts but cannot be found")
+	}
+	if err := i.client.ResetPassword(ctx, existing, identifier, password); err != nil {
+		return tenantAdminInvite{}, err
+	}
+	return tenantAdminInvite{KratosIdentityID: existing.ID, InitialPassword: password, AlreadyExists: true}, nil
+}
+
+func generateInitialPassword() (string, error) {
+	return randomURLToken(18)
+}
+
+func randomURLToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,181 @@
+package superadmin
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/internal/routing"
+	iammodule "github.com/jacksonlee411/Bugs-And-Blossoms/modules/iam"
+	orgunitmodule "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit"
+)
+
+type tenantProvisionPayload struct {
+	RequestID   string `json:"request_id"`
+	Name        string `json:"name"`
+	Hostname    string `json:"hostname"`
+	AdminEmail  string `json:"admin_email"`
+	AsOf        string `json:"as_of"`
+	RootOrgCode string `json:"root_org_code"`
+	RootOrgName string `json:"root_org_name"`
+}
+
+func newTenantProvisioner(pool pgBeginner, opts HandlerOptions) (*tenantProvisioner, error) {
+	dicts := opts.DictBaseline
+	if dicts == nil {
+		dicts = iammodule.NewDictPGStore(pool)
+	}
+	admins := opts.AdminInviter
+	if admins == nil {
+		a, err := newKratosTenantAdminInviterFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		admins = a
+	}
+	orgs := opts.OrgUnits
+	if orgs == nil {
+		orgs = orgunitmodule.NewWriteServiceWithPGStore(pool)
+	}
+	return &tenantProvisioner{pool: pool, dicts: dicts, admins: admins, orgs: orgs}, nil
+}
+
+func handleTenantProvisionForm(w http.ResponseWriter, r *http.Request, provisioner *tenantProvisioner) {
+	if !superadminWritesEnabled() {
+		routing.WriteError(w, r, routing.RouteClassUI, http.StatusForbidden, "write_disabled", "write disabled")
+		return
+	}
+
+	p, ok := principalFromContext(r.Context())
+	if !ok || p.ID == "" {
+		routing.WriteError(w, r, routing.RouteClassUI, http.StatusUnauthorized, "unauthorized", "unauthorized")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		routing.WriteError(w, r, routing.RouteClassUI, http.StatusBadRequest, "bad_request", "bad request")
+		return
+	}
+	reqID := strings.TrimSpace(r.FormValue("request_id"))
+	if reqID == "" {
+		reqID = requestID(r)
+	}
+	in := tenantProvisionInput{
+		Name:        r.FormValue("name"),
+		Hostname:    r.FormValue("hostname"),
+		AdminEmail:  r.FormValue("admin_email"),
+		AsOf:        r.FormValue("as_of"),
+		RootOrgCode: r.FormValue("root_org_code"),
+		RootOrgName: r.FormValue("root_org_name"),
+	}
+
+	run, err := provisioner.Run(r.Context(), p.ID, reqID, in)
+	if err != nil {
+		status, code := tenantProvisionErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassUI, status, code, "")
+		return
+	}
+	writeTenantProvisionRun(w, run)
+}
+
+func handleTenantProvisionAPI(w http.ResponseWriter, r *http.Request, provisioner *tenantProvisioner) {
+	if !superadminWritesEnabled() {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusForbidden, "write_disabled", "write disabled")
+		return
+	}
+
+	p, ok := principalFromContext(r.Context())
+	if !ok || p.ID == "" {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusUnauthorized, "unauthorized", "unauthorized")
+		return
+	}
+
+	var payload tenantProvisionPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_json", "bad json")
+		return
+	}
+
+	run, err := provisioner.Run(r.Context(), p.ID, payload.RequestID, tenantProvisionInput{
+		Name:        payload.Name,
+		Hostname:    payload.Hostname,
+		AdminEmail:  payload.AdminEmail,
+		AsOf:        payload.AsOf,
+		RootOrgCode: payload.RootOrgCode,
+		RootOrgName: payload.RootOrgName,
+	})
+	if err != nil {
+		status, code := tenantProvisionErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, "")
+		return
+	}
+	writeTenantProvisionJSON(w, http.StatusOK, run)
+}
+
+func handleTenantProvisionGetAPI(w http.ResponseWriter, r *http.Request, provisioner *tenantProvisioner) {
+	reqID := strings.TrimPrefix(r.URL.Path, "/superadmin/api/tenants/provisioning/")
+	if reqID == "" || strings.Contains(reqID, "/") {
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, http.StatusBadRequest, "bad_request", "bad request")
+		return
+	}
+
+	run, err := provisioner.Get(r.Context(), reqID)
+	if err != nil {
+		status, code := tenantProvisionErrorStatus(err)
+		routing.WriteError(w, r, routing.RouteClassInternalAPI, status, code, "")
+		return
+	}
+	writeTenantProvisionJSON(w, http.StatusOK, run)
+}
+
+func writeTenantProvisionJSON(w http.ResponseWriter, status int, run tenantProvisionRun) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(run)
+}
+
+func writeTenantProvisionRun(w http.ResponseWriter, run tenantProvisionRun) {
+	var b strings.Builder
+	b.WriteString("<h1>SuperAdmin / Tenant Provisioning</h1>")
+	b.WriteString("<p>Request: <code>" + html.EscapeString(run.RequestID) + "</code></p>")
+	if run.TenantID != "" {
+		b.WriteString("<p>Tenant: <code>" + html.EscapeString(run.TenantID) + "</code></p>")
+	}
+	b.WriteString("<p>Status: <b>" + html.EscapeString(run.Status) + "</b></p>")
+	if run.InitialAdminPassword != "" {
+		b.WriteString("<p>Initial admin password (shown once): <code>" + html.EscapeString(run.InitialAdminPassword) + "</code></p>")
+	}
+
+	b.WriteString(`<table border="1" cellpadding="6" cellspacing="0">`)
+	b.WriteString("<thead><tr><th>Step</th><th>Status</th><th>Error</th></tr></thead><tbody>")
+	for _, step := range run.Steps {
+		b.WriteString("<tr>")
+		b.WriteString("<td>" + html.EscapeString(step.Step) + "</td>")
+		b.WriteString("<td>" + html.EscapeString(step.Status) + "</td>")
+		b.WriteString("<td>" + html.EscapeString(step.Error) + "</td>")
+		b.WriteString("</tr>")
+	}
+	b.WriteString("</tbody></table>")
+
+	if run.Status == provisionStatusFailed {
+		b.WriteString(`<form method="POST" action="/superadmin/tenants/provision">`)
+		fields := []struct{ name, value string }{
+			{"request_id", run.RequestID},
+			{"name", run.Input.Name},
+			{"hostname", run.Input.Hostname},
+			{"admin_email", run.Input.AdminEmail},
+			{"as_of", run.Input.AsOf},
+			{"root_org_code", run.Input.RootOrgCode},
+			{"root_org_name", run.Input.RootOrgName},
+		}
+		for _, f := range fields {
+			b.WriteString(fmt.Sprintf(`<input type="hidden" name="%s" value="%s" />`, f.name, html.EscapeString(f.value)))
+		}
+		b.WriteString(`<button type="submit">Retry</button></form>`)
+	}
+	b.WriteString(`<p><a href="/superadmin/tenants">Back to tenants</a></p>`)
+
+	writeHTML(w, "Tenant Provisioning", b.String())
+}
@@ -0,0 +1,593 @@
+package superadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	iammodule "github.com/jacksonlee411/Bugs-And-Blossoms/modules/iam"
+	orgunitservices "github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/services"
+)
+
+// provisionDB is a tiny in-memory stand-in for the tables touched by the provisioning pipeline.
+type provisionDB struct {
+	runs     map[string]*provisionRunRow
+	tenants  int
+	domains  map[string]string
+	seeds    int
+	rolesOK  bool
+	audits   []string
+	beginErr error
+}
+
+type provisionRunRow struct {
+	tenantID     string
+	status       string
+	input        string
+	steps        string
+	claim        string
+	claimExpired bool
+}
+
+func newProvisionDB() *provisionDB {
+	return &provisionDB{runs: map[string]*provisionRunRow{}, domains: map[string]string{}, rolesOK: true}
+}
+
+func (db *provisionDB) Begin(context.Context) (pgx.Tx, error) {
+	if db.beginErr != nil {
+		return nil, db.beginErr
+	}
+	return &provisionTx{stubTx: &stubTx{}, db: db}, nil
+}
+
+func (db *provisionDB) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return &stubRows{}, nil
+}
+
+type provisionTx struct {
+	*stubTx
+	db *provisionDB
+}
+
+func (t *provisionTx) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	db := t.db
+	switch {
+	case strings.Contains(sql, "INSERT INTO iam.superadmin_tenant_provisioning_runs"):
+		if _, ok := db.runs[args[0].(string)]; !ok {
+			db.runs[args[0].(string)] = &provisionRunRow{status: "running", input: string(args[1].([]byte)), steps: string(args[2].([]byte))}
+		}
+	case strings.Contains(sql, "SET tenant_uuid"):
+		db.runs[args[0].(string)].tenantID = args[1].(string)
+	case strings.Contains(sql, "claim_token IS NULL"):
+		row := db.runs[args[0].(string)]
+		if row.claim != "" && !row.claimExpired {
+			return pgconn.NewCommandTag("UPDATE 0"), nil
+		}
+		row.status = provisionStatusRunning
+		row.claim = args[1].(string)
+		row.claimExpired = false
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	case strings.Contains(sql, "UPDATE iam.superadmin_tenant_provisioning_runs"):
+		row := db.runs[args[0].(string)]
+		if row.claim == "" || row.claim != args[3].(string) {
+			return pgconn.NewCommandTag("UPDATE 0"), nil
+		}
+		row.status = args[1].(string)
+		row.steps = string(args[2].([]byte))
+		if row.status != provisionStatusRunning {
+			row.claim = ""
+		}
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	case strings.Contains(sql, "set_config('app.current_tenant'"):
+	case strings.Contains(sql, "iam.seed_builtin_authz_roles"):
+		db.seeds++
+	case strings.Contains(sql, "INSERT INTO iam.tenant_domains"):
+		db.domains[args[1].(string)] = args[0].(string)
+	case strings.Contains(sql, "INSERT INTO iam.superadmin_audit_logs"):
+		db.audits = append(db.audits, args[1].(string))
+	default:
+		return pgconn.CommandTag{}, errors.New("unexpected exec: " + sql)
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+func (t *provisionTx) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	db := t.db
+	switch {
+	case strings.Contains(sql, "FROM iam.role_definitions"):
+		return stubRow{vals: []any{db.rolesOK}}
+	case strings.Contains(sql, "FROM iam.superadmin_tenant_provisioning_runs"):
+		row, ok := db.runs[args[0].(string)]
+		if !ok {
+			return stubRow{err: pgx.ErrNoRows}
+		}
+		if strings.Contains(sql, "input::text") {
+			return stubRow{vals: []any{row.tenantID, row.status, row.input, row.steps}}
+		}
+		return stubRow{vals: []any{row.tenantID}}
+	case strings.Contains(sql, "INSERT INTO iam.tenants"):
+		db.tenants++
+		return stubRow{vals: []any{"00000000-0000-0000-0000-00000000000" + string(rune('0'+db.tenants))}}
+	case strings.Contains(sql, "FROM iam.tenant_domains"):
+		owner, ok := db.domains[args[0].(string)]
+		if !ok {
+			return stubRow{err: pgx.ErrNoRows}
+		}
+		return stubRow{vals: []any{owner}}
+	default:
+		return stubRow{err: errors.New("unexpected query row: " + sql)}
+	}
+}
+
+type fakeDictPublisher struct {
+	calls int
+	err   error
+	last  iammodule.DictBaselineReleaseRequest
+}
+
+func (f *fakeDictPublisher) PublishBaseline(_ context.Context, req iammodule.DictBaselineReleaseRequest) (iammodule.DictBaselineReleaseResult, error) {
+	f.calls++
+	f.last = req
+	if f.err != nil {
+		return iammodule.DictBaselineReleaseResult{}, f.err
+	}
+	return iammodule.DictBaselineReleaseResult{TaskID: "task-1", DictEventsApplied: 1, ValueEventsApplied: 2}, nil
+}
+
+type fakeAdminInviter struct {
+	calls int
+	err   error
+}
+
+func (f *fakeAdminInviter) InviteTenantAdmin(context.Context, string, string) (tenantAdminInvite, error) {
+	f.calls++
+	if f.err != nil {
+		return tenantAdminInvite{}, f.err
+	}
+	return tenantAdminInvite{KratosIdentityID: "kid-admin", InitialPassword: "initial-pw"}, nil
+}
+
+type fakeOrgUnitWriter struct {
+	calls int
+	last  orgunitservices.WriteOrgUnitRequest
+}
+
+func (f *fakeOrgUnitWriter) Write(_ context.Context, _ string, req orgunitservices.WriteOrgUnitRequest) (orgunitservices.OrgUnitWriteResult, error) {
+	f.calls++
+	f.last = req
+	return orgunitservices.OrgUnitWriteResult{OrgCode: req.OrgCode, EffectiveDate: req.EffectiveDate}, nil
+}
+
+type provisionFixture struct {
+	db     *provisionDB
+	dicts  *fakeDictPublisher
+	admins *fakeAdminInviter
+	orgs   *fakeOrgUnitWriter
+	p      *tenantProvisioner
+}
+
+func newProvisionFixture() provisionFixture {
+	f := provisionFixture{
+		db:     newProvisionDB(),
+		dicts:  &fakeDictPublisher{},
+		admins: &fakeAdminInviter{},
+		orgs:   &fakeOrgUnitWriter{},
+	}
+	f.p = &tenantProvisioner{pool: f.db, dicts: f.dicts, admins: f.admins, orgs: f.orgs}
+	return f
+}
+
+func validProvisionInput() tenantProvisionInput {
+	return tenantProvisionInput{
+		Name:        " Acme ",
+		Hostname:    "Acme.Local",
+		AdminEmail:  "Admin@Acme.Local",
+		AsOf:        "2026-01-01",
+		RootOrgCode: "ROOT",
+		RootOrgName: "Acme HQ",
+	}
+}
+
+func stepStatuses(run tenantProvisionRun) string {
+	parts := make([]string, 0, len(run.Steps))
+	for _, step := range run.Steps {
+		parts = append(parts, step.Step+"="+step.Status)
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestTenantProvisionerRun_AllStepsThenRetryIsNoop(t *testing.T) {
+	f := newProvisionFixture()
+	ctx := context.Background()
+
+	run, err := f.p.Run(ctx, "actor-1", "req-1", validProvisionInput())
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if run.Status != provisionStatusSucceeded || run.TenantID == "" || run.InitialAdminPassword != "initial-pw" {
+		t.Fatalf("run=%+v", run)
+	}
+	want := "create_tenant=succeeded,bind_primary_domain=succeeded,publish_dict_baseline=succeeded,seed_builtin_roles=succeeded,invite_first_admin=succeeded,create_root_org=succeeded"
+	if got := stepStatuses(run); got != want {
+		t.Fatalf("steps=%s", got)
+	}
+	if f.db.domains["acme.local"] != run.TenantID || f.db.seeds != 1 || f.db.runs["req-1"].claim != "" {
+		t.Fatalf("domains=%v seeds=%d row=%+v", f.db.domains, f.db.seeds, f.db.runs["req-1"])
+	}
+	if f.dicts.last.RequestID != "req-1#publish_dict_baseline" || f.dicts.last.TargetTenantID != run.TenantID || f.dicts.last.SourceTenantID != "" {
+		t.Fatalf("dict req=%+v", f.dicts.last)
+	}
+	if f.orgs.last.Intent != "create_org" || f.orgs.last.RequestID != "req-1#create_root_org" || *f.orgs.last.Patch.Name != "Acme HQ" {
+		t.Fatalf("org req=%+v", f.orgs.last)
+	}
+	if len(f.db.audits) != 6 || f.db.audits[0] != "tenant.provision.create_tenant" || f.db.audits[3] != "tenant.provision.seed_builtin_roles" || f.db.audits[5] != "tenant.provision.create_root_org" {
+		t.Fatalf("audits=%v", f.db.audits)
+	}
+	if strings.Contains(f.db.runs["req-1"].steps, "initial-pw") {
+		t.Fatal("initial password must not be persisted")
+	}
+
+	again, err := f.p.Run(ctx, "actor-1", "req-1", validProvisionInput())
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if again.Status != provisionStatusSucceeded || again.InitialAdminPassword != "" {
+		t.Fatalf("again=%+v", again)
+	}
+	if f.db.tenants != 1 || f.dicts.calls != 1 || f.admins.calls != 1 || f.orgs.calls != 1 || len(f.db.audits) != 6 {
+		t.Fatalf("tenants=%d dicts=%d admins=%d orgs=%d audits=%d", f.db.tenants, f.dicts.calls, f.admins.calls, f.orgs.calls, len(f.db.audits))
+	}
+}
+
+func TestTenantProvisionerRun_ResumesAfterFailedStep(t *testing.T) {
+	f := newProvisionFixture()
+	f.dicts.err = iammodule.ErrDictBaselineNotReady
+	ctx := context.Background()
+	in := validProvisionInput()
+	in.RootOrgCode = ""
+
+	run, err := f.p.Run(ctx, "actor-1", "req-2", in)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want := "create_tenant=succeeded,bind_primary_domain=succeeded,publish_dict_baseline=failed,seed_builtin_roles=pending,invite_first_admin=pending,create_root_org=skipped"
+	if run.Status != provisionStatusFailed || stepStatuses(run) != want {
+		t.Fatalf("status=%s steps=%s", run.Status, stepStatuses(run))
+	}
+	if run.Steps[2].Error == "" || f.db.runs["req-2"].status != provisionStatusFailed {
+		t.Fatalf("run=%+v row=%+v", run, f.db.runs["req-2"])
+	}
+
+	f.dicts.err = nil
+	run, err = f.p.Run(ctx, "actor-1", "req-2", in)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if run.Status != provisionStatusSucceeded || run.Steps[2].Error != "" {
+		t.Fatalf("run=%+v", run)
+	}
+	if f.db.tenants != 1 || f.dicts.calls != 2 || f.orgs.calls != 0 {
+		t.Fatalf("tenants=%d dicts=%d orgs=%d", f.db.tenants, f.dicts.calls, f.orgs.calls)
+	}
+	if len(f.db.audits) != 6 {
+		t.Fatalf("audits=%v", f.db.audits)
+	}
+}
+
+func TestTenantProvisionerRun_ConcurrentRetryIsRejected(t *testing.T) {
+	f := newProvisionFixture()
+	ctx := context.Background()
+	in, _ := normalizeTenantProvisionInput(validProvisionInput())
+	inputJSON, _ := json.Marshal(in)
+	stepsJSON, _ := json.Marshal(newTenantProvisionSteps(in))
+	f.db.runs["req-6"] = &provisionRunRow{status: provisionStatusRunning, input: string(inputJSON), steps: string(stepsJSON), claim: "other-runner"}
+
+	if _, err := f.p.Run(ctx, "actor-1", "req-6", validProvisionInput()); !errors.Is(err, errProvisionInProgress) {
+		t.Fatalf("err=%v", err)
+	}
+	if f.db.tenants != 0 || f.admins.calls != 0 {
+		t.Fatalf("tenants=%d admins=%d", f.db.tenants, f.admins.calls)
+	}
+
+	// An expired claim is taken over, and the previous runner can no longer record steps.
+	f.db.runs["req-6"].claimExpired = true
+	run, err := f.p.Run(ctx, "actor-1", "req-6", validProvisionInput())
+	if err != nil || run.Status != provisionStatusSucceeded {
+		t.Fatalf("run=%+v err=%v", run, err)
+	}
+	stale := run
+	stale.claimToken = "other-runner"
+	if err := f.p.recordStep(ctx, "actor-1", stale, stale.Steps[0]); !errors.Is(err, errProvisionInProgress) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestTenantProvisionerRun_SeedRolesVerifiesBuiltinRoles(t *testing.T) {
+	f := newProvisionFixture()
+	f.db.rolesOK = false
+
+	run, err := f.p.Run(context.Background(), "actor-1", "req-7", validProvisionInput())
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if run.Status != provisionStatusFailed || run.Steps[3].Step != provisionStepSeedRoles || run.Steps[3].Error != errProvisionRolesMissing.Error() || f.admins.calls != 0 {
+		t.Fatalf("run=%+v admins=%d", run, f.admins.calls)
+	}
+	if f.db.runs["req-7"].claim != "" {
+		t.Fatalf("a failed run must release its claim, row=%+v", f.db.runs["req-7"])
+	}
+}
+
+func TestTenantProvisionerRun_HostnameOwnedByAnotherTenantFails(t *testing.T) {
+	f := newProvisionFixture()
+	f.db.domains["acme.local"] = "other-tenant"
+
+	run, err := f.p.Run(context.Background(), "actor-1", "req-3", validProvisionInput())
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if run.Status != provisionStatusFailed || run.Steps[1].Status != provisionStatusFailed {
+		t.Fatalf("run=%+v", run)
+	}
+	if f.dicts.calls != 0 {
+		t.Fatalf("dict calls=%d", f.dicts.calls)
+	}
+}
+
+func TestTenantProvisionerRun_Errors(t *testing.T) {
+	f := newProvisionFixture()
+	ctx := context.Background()
+
+	if _, err := f.p.Run(ctx, "actor-1", " ", validProvisionInput()); !errors.Is(err, errProvisionInvalidInput) {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := f.p.Run(ctx, "actor-1", "req-4", validProvisionInput()); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	changed := validProvisionInput()
+	changed.Name = "Other"
+	if _, err := f.p.Run(ctx, "actor-1", "req-4", changed); !errors.Is(err, errProvisionRequestConflict) {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := f.p.Get(ctx, "missing"); !errors.Is(err, errProvisionNotFound) {
+		t.Fatalf("err=%v", err)
+	}
+
+	f.db.beginErr = errors.New("boom")
+	if _, err := f.p.Run(ctx, "actor-1", "req-5", validProvisionInput()); err == nil {
+		t.Fatal("expected begin error")
+	}
+	if _, err := f.p.Get(ctx, "req-4"); err == nil {
+		t.Fatal("expected begin error")
+	}
+}
+
+func TestNormalizeTenantProvisionInput(t *testing.T) {
+	in, err := normalizeTenantProvisionInput(validProvisionInput())
+	if err != nil || in.Name != "Acme" || in.Hostname != "acme.local" || in.AdminEmail != "admin@acme.local" {
+		t.Fatalf("in=%+v err=%v", in, err)
+	}
+	noRoot := validProvisionInput()
+	noRoot.RootOrgCode = ""
+	if in, err := normalizeTenantProvisionInput(noRoot); err != nil || in.RootOrgName != "" {
+		t.Fatalf("in=%+v err=%v", in, err)
+	}
+
+	cases := []struct {
+		name   string
+		mutate func(*tenantProvisionInput)
+		want   error
+	}{
+		{"missing name", func(in *tenantProvisionInput) { in.Name = "" }, errProvisionInvalidInput},
+		{"hostname port", func(in *tenantProvisionInput) { in.Hostname = "a.local:8080" }, errProvisionInvalidHostname},
+		{"bad email", func(in *tenantProvisionInput) { in.AdminEmail = "nobody" }, errProvisionInvalidInput},
+		{"bad as_of", func(in *tenantProvisionInput) { in.AsOf = "" }, errProvisionInvalidAsOf},
+		{"root without name", func(in *tenantProvisionInput) { in.RootOrgName = "" }, errProvisionInvalidInput},
+	}
+	for _, tc := range cases {
+		in := validProvisionInput()
+		tc.mutate(&in)
+		if _, err := normalizeTenantProvisionInput(in); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: err=%v", tc.name, err)
+		}
+	}
+}
+
+func TestTenantProvisionErrorStatus(t *testing.T) {
+	cases := map[error]string{
+		errProvisionInvalidHostname: "invalid_hostname",
+		errProvisionInvalidAsOf:     "invalid_as_of",
+		errProvisionInvalidInput:    "invalid_input",
+		errProvisionRequestConflict: "tenant_provision_request_conflict",
+		errProvisionInProgress:      "tenant_provision_in_progress",
+		errProvisionNotFound:        "tenant_provision_not_found",
+		errors.New("boom"):          "db_error",
+	}
+	for err, want := range cases {
+		if _, code := tenantProvisionErrorStatus(err); code != want {
+			t.Fatalf("err=%v code=%s", err, code)
+		}
+	}
+}
+
+func newProvisionHandler(t *testing.T, f provisionFixture) authedHandler {
+	t.Helper()
+	return newAuthedHandlerWithOptions(t, HandlerOptions{
+		Pool:         f.db,
+		DictBaseline: f.dicts,
+		AdminInviter: f.admins,
+		OrgUnits:     f.orgs,
+	})
+}
+
+func TestTenantProvisionAPI(t *testing.T) {
+	f := newProvisionFixture()
+	h := newProvisionHandler(t, f)
+
+	body := `{"request_id":"req-api","name":"Acme","hostname":"acme.local","admin_email":"admin@acme.local","as_of":"2026-01-01"}`
+	rec := httptest.NewRecorder()
+	h.h.ServeHTTP(rec, h.newRequest(http.MethodPost, "/superadmin/api/tenants/provisioning", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	var run tenantProvisionRun
+	if err := json.Unmarshal(rec.Body.Bytes(), &run); err != nil {
+		t.Fatal(err)
+	}
+	if run.Status != provisionStatusSucceeded || run.InitialAdminPassword != "initial-pw" {
+		t.Fatalf("run=%+v", run)
+	}
+
+	rec = httptest.NewRecorder()
+	h.h.ServeHTTP(rec, h.newRequest(http.MethodGet, "/superadmin/api/tenants/provisioning/req-api", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"status":"succeeded"`) || strings.Contains(rec.Body.String(), "initial-pw") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.h.ServeHTTP(rec, h.newRequest(http.MethodGet, "/superadmin/api/tenants/provisioning/missing", nil))
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "tenant_provision_not_found") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.h.ServeHTTP(rec, h.newRequest(http.MethodPost, "/superadmin/api/tenants/provisioning", strings.NewReader("{")))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "bad_json") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.h.ServeHTTP(rec, h.newRequest(http.MethodPost, "/superadmin/api/tenants/provisioning", strings.NewReader(`{"name":"Acme"}`)))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid_input") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	t.Setenv("SUPERADMIN_WRITE_MODE", "disabled")
+	rec = httptest.NewRecorder()
+	h.h.ServeHTTP(rec, h.newRequest(http.MethodPost, "/superadmin/api/tenants/provisioning", strings.NewReader(body)))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status=%d", rec.Code)
+	}
+}
+
+func TestTenantProvisionForm_FailureOffersRetry(t *testing.T) {
+	f := newProvisionFixture()
+	f.admins.err = errors.New("kratos down")
+	h := newProvisionHandler(t, f)
+
+	form := url.Values{
+		"request_id":  {"req-form"},
+		"name":        {"Acme"},
+		"hostname":    {"acme.local"},
+		"admin_email": {"admin@acme.local"},
+		"as_of":       {"2026-01-01"},
+	}
+	post := func() *httptest.ResponseRecorder {
+		req := h.newRequest(http.MethodPost, "/superadmin/tenants/provision", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		h.h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post()
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "kratos down") || !strings.Contains(rec.Body.String(), "Retry") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	f.admins.err = nil
+	rec = post()
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<b>succeeded</b>") || !strings.Contains(rec.Body.String(), "initial-pw") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	form.Set("as_of", "bad")
+	if rec := post(); rec.Code != http.StatusBadRequest {
+		t.Fatalf("status=%d", rec.Code)
+	}
+}
+
+func TestKratosTenantAdminInviter(t *testing.T) {
+	var identifiers []string
+	var resetPassword string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/admin/identities":
+			if r.URL.Query().Get("credentials_identifier") != "t1:a@example.invalid" {
+				_ = json.NewEncoder(w).Encode([]any{})
+				return
+			}
+			_ = json.NewEncoder(w).Encode([]any{map[string]any{"id": "kid-1", "schema_id": "default", "traits": map[string]any{"email": "a@example.invalid"}}})
+			return
+		case r.Method == http.MethodPut && r.URL.Path == "/admin/identities/kid-1":
+			var req struct {
+				Credentials struct {
+					Password struct {
+						Config struct {
+							Password string `json:"password"`
+						} `json:"config"`
+					} `json:"password"`
+				} `json:"credentials"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			resetPassword = req.Credentials.Password.Config.Password
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "kid-1"})
+			return
+		}
+		var req struct {
+			Traits      map[string]any `json:"traits"`
+			Credentials struct {
+				Password struct {
+					Identifiers []string `json:"identifiers"`
+				} `json:"password"`
+			} `json:"credentials"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		identifiers = append(identifiers, req.Credentials.Password.Identifiers...)
+		if req.Traits["role_slug"] != "tenant-admin" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if len(identifiers) > 1 {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "kid-1"})
+	}))
+	defer srv.Close()
+
+	t.Setenv("KRATOS_ADMIN_URL", srv.URL)
+	inviter, err := newKratosTenantAdminInviterFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := inviter.InviteTenantAdmin(context.Background(), "t1", "a@example.invalid")
+	if err != nil || first.KratosIdentityID != "kid-1" || first.InitialPassword == "" {
+		t.Fatalf("invite=%+v err=%v", first, err)
+	}
+	if identifiers[0] != "t1:a@example.invalid" {
+		t.Fatalf("identifiers=%v", identifiers)
+	}
+
+	// A retry whose first invite was never recorded gets a fresh password for the existing identity.
+	retry, err := inviter.InviteTenantAdmin(context.Background(), "t1", "a@example.invalid")
+	if err != nil || !retry.AlreadyExists || retry.KratosIdentityID != "kid-1" || retry.InitialPassword == "" || retry.InitialPassword == first.InitialPassword || resetPassword != retry.InitialPassword {
+		t.Fatalf("invite=%+v reset=%q err=%v", retry, resetPassword, err)
+	}
+
+	if _, err := inviter.InviteTenantAdmin(context.Background(), "t2", "a@example.invalid"); err == nil {
+		t.Fatal("expected error for a conflicting identity that cannot be found")
+	}
+
+	t.Setenv("KRATOS_ADMIN_URL", "ftp://x")
+	if _, err := newKratosTenantAdminInviterFromEnv(); err == nil {
+		t.Fatal("expected error")
+	}
+}
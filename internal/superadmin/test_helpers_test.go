@@ -140,6 +140,8 @@ func (r *stubRows) Scan(dest ...any) error {
 			*d = row[i].(string)
 		case *bool:
 			*d = row[i].(bool)
+		case *[]string:
+			*d = row[i].([]string)
 		default:
 			return errors.New("unsupported dest")
 		}
@@ -175,6 +177,11 @@ type authedHandler struct {
 }
 
 func newAuthedHandler(t *testing.T, pool pgBeginner) authedHandler {
+	t.Helper()
+	return newAuthedHandlerWithOptions(t, HandlerOptions{Pool: pool})
+}
+
+func newAuthedHandlerWithOptions(t *testing.T, opts HandlerOptions) authedHandler {
 	t.Helper()
 	t.Setenv("AUTHZ_MODE", "enforce")
 
@@ -189,12 +196,10 @@ func newAuthedHandler(t *testing.T, pool pgBeginner) authedHandler {
 		t.Fatal(err)
 	}
 
-	h, err := NewHandlerWithOptions(HandlerOptions{
-		Pool:             pool,
-		IdentityProvider: stubIdentityProvider{},
-		Principals:       principals,
-		Sessions:         sessions,
-	})
+	opts.IdentityProvider = stubIdentityProvider{}
+	opts.Principals = principals
+	opts.Sessions = sessions
+	h, err := NewHandlerWithOptions(opts)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION iam.notify_dict_changed()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  -- NOTIFY is transactional: listeners only see the change after the submitting transaction commits.
+  PERFORM pg_notify(
+    'iam_dict_changed',
+    jsonb_build_object('tenant_uuid', NEW.tenant_uuid, 'dict_code', NEW.dict_code)::text
+  );
+  RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS dict_events_notify_changed ON iam.dict_events;
+CREATE TRIGGER dict_events_notify_changed
+AFTER INSERT ON iam.dict_events
+FOR EACH ROW
+EXECUTE FUNCTION iam.notify_dict_changed();
+
+DROP TRIGGER IF EXISTS dict_value_events_notify_changed ON iam.dict_value_events;
+CREATE TRIGGER dict_value_events_notify_changed
+AFTER INSERT ON iam.dict_value_events
+FOR EACH ROW
+EXECUTE FUNCTION iam.notify_dict_changed();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS dict_value_events_notify_changed ON iam.dict_value_events;
+DROP TRIGGER IF EXISTS dict_events_notify_changed ON iam.dict_events;
+DROP FUNCTION IF EXISTS iam.notify_dict_changed();
+-- +goose StatementEnd
@@ -0,0 +1,131 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Offboarding requests intentionally carry no FK to iam.tenants: the row (and its verification report)
+-- must outlive the tenant it describes.
+CREATE TABLE IF NOT EXISTS iam.superadmin_tenant_offboarding_requests (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL,
+  tenant_name text NOT NULL,
+  status text NOT NULL DEFAULT 'scheduled',
+  reason text NOT NULL DEFAULT '',
+  token_sha256 bytea NOT NULL,
+  purge_after timestamptz NOT NULL,
+  requested_by text NOT NULL,
+  closed_by text NULL,
+  closed_at timestamptz NULL,
+  verification jsonb NOT NULL DEFAULT '{}'::jsonb,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT superadmin_tenant_offboarding_requests_status_check CHECK (status IN ('scheduled', 'cancelled', 'deleted')),
+  CONSTRAINT superadmin_tenant_offboarding_requests_token_len_check CHECK (octet_length(token_sha256) = 32),
+  CONSTRAINT superadmin_tenant_offboarding_requests_verification_is_object_check CHECK (jsonb_typeof(verification) = 'object')
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS superadmin_tenant_offboarding_requests_scheduled_unique
+  ON iam.superadmin_tenant_offboarding_requests (tenant_uuid)
+  WHERE status = 'scheduled';
+CREATE INDEX IF NOT EXISTS superadmin_tenant_offboarding_requests_tenant_idx
+  ON iam.superadmin_tenant_offboarding_requests (tenant_uuid, id DESC);
+
+CREATE OR REPLACE FUNCTION iam.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'iam.principal_org_scope_bindings',
+    'iam.principal_authz_assignment_revisions',
+    'iam.principal_role_assignments',
+    'iam.role_definitions',
+    'iam.cubebox_model_selections',
+    'iam.cubebox_model_health_checks',
+    'iam.cubebox_model_credentials',
+    'iam.cubebox_model_providers',
+    'iam.cubebox_conversation_events',
+    'iam.cubebox_conversations',
+    'iam.dict_value_segments',
+    'iam.dict_value_events',
+    'iam.dict_events',
+    'iam.dicts',
+    'iam.sessions',
+    'iam.principals',
+    'iam.tenant_domains'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+  IF EXISTS (SELECT 1 FROM iam.tenants WHERE id = p_tenant_uuid AND is_active) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_ACTIVE',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (SELECT id FROM iam.role_definitions WHERE tenant_uuid = p_tenant_uuid);
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.role_authz_capabilities', v_count);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  DELETE FROM iam.tenants WHERE id = p_tenant_uuid;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.tenants', v_count);
+
+  RETURN v_counts;
+END;
+$$;
+
+REVOKE ALL ON FUNCTION iam.purge_tenant_data(uuid) FROM PUBLIC;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.superadmin_tenant_offboarding_requests TO superadmin_runtime';
+    EXECUTE 'GRANT USAGE, SELECT ON SEQUENCE iam.superadmin_tenant_offboarding_requests_id_seq TO superadmin_runtime';
+    EXECUTE 'GRANT SELECT ON ' ||
+      'iam.principals, iam.sessions, ' ||
+      'iam.role_definitions, iam.role_authz_capabilities, iam.principal_role_assignments, ' ||
+      'iam.principal_authz_assignment_revisions, iam.principal_org_scope_bindings, ' ||
+      'iam.cubebox_conversations, iam.cubebox_conversation_events, ' ||
+      'iam.cubebox_model_providers, iam.cubebox_model_credentials, ' ||
+      'iam.cubebox_model_selections, iam.cubebox_model_health_checks ' ||
+      'TO superadmin_runtime';
+    EXECUTE 'GRANT EXECUTE ON FUNCTION iam.purge_tenant_data(uuid) TO superadmin_runtime';
+  END IF;
+END
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS iam.purge_tenant_data(uuid);
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'REVOKE SELECT ON ' ||
+      'iam.principals, iam.sessions, ' ||
+      'iam.role_definitions, iam.role_authz_capabilities, iam.principal_role_assignments, ' ||
+      'iam.principal_authz_assignment_revisions, iam.principal_org_scope_bindings, ' ||
+      'iam.cubebox_conversations, iam.cubebox_conversation_events, ' ||
+      'iam.cubebox_model_providers, iam.cubebox_model_credentials, ' ||
+      'iam.cubebox_model_selections, iam.cubebox_model_health_checks ' ||
+      'FROM superadmin_runtime';
+  END IF;
+END
+$$;
+DROP TABLE IF EXISTS iam.superadmin_tenant_offboarding_requests;
+-- +goose StatementEnd
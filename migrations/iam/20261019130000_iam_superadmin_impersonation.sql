@@ -0,0 +1,86 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Impersonation sessions are issued by superadmins and redeemed once on the tenant host. They follow
+-- iam.sessions (no RLS, always filtered by tenant_uuid) and are removed with the principal they target.
+CREATE TABLE IF NOT EXISTS iam.impersonation_sessions (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  principal_id uuid NOT NULL REFERENCES iam.principals(id) ON DELETE CASCADE,
+  superadmin_actor text NOT NULL,
+  superadmin_email text NOT NULL DEFAULT '',
+  reason text NOT NULL,
+  read_only boolean NOT NULL DEFAULT true,
+  handoff_token_sha256 bytea NOT NULL,
+  handoff_expires_at timestamptz NOT NULL,
+  accepted_at timestamptz NULL,
+  expires_at timestamptz NOT NULL,
+  ended_at timestamptz NULL,
+  ended_by text NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT impersonation_sessions_actor_nonempty_check CHECK (btrim(superadmin_actor) <> ''),
+  CONSTRAINT impersonation_sessions_reason_nonempty_check CHECK (btrim(reason) <> ''),
+  CONSTRAINT impersonation_sessions_handoff_token_len_check CHECK (octet_length(handoff_token_sha256) = 32),
+  CONSTRAINT impersonation_sessions_handoff_before_expiry_check CHECK (handoff_expires_at <= expires_at)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS impersonation_sessions_handoff_token_unique ON iam.impersonation_sessions (handoff_token_sha256);
+CREATE INDEX IF NOT EXISTS impersonation_sessions_tenant_idx ON iam.impersonation_sessions (tenant_uuid, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS iam.impersonation_request_logs (
+  id bigserial PRIMARY KEY,
+  impersonation_id uuid NOT NULL REFERENCES iam.impersonation_sessions(id) ON DELETE CASCADE,
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  superadmin_actor text NOT NULL,
+  method text NOT NULL,
+  path text NOT NULL,
+  request_id text NOT NULL DEFAULT '',
+  status_code integer NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  completed_at timestamptz NULL,
+  CONSTRAINT impersonation_request_logs_method_nonempty_check CHECK (btrim(method) <> ''),
+  CONSTRAINT impersonation_request_logs_path_nonempty_check CHECK (btrim(path) <> '')
+);
+
+CREATE INDEX IF NOT EXISTS impersonation_request_logs_session_idx ON iam.impersonation_request_logs (impersonation_id, id);
+CREATE INDEX IF NOT EXISTS impersonation_request_logs_tenant_idx ON iam.impersonation_request_logs (tenant_uuid, id);
+
+ALTER TABLE iam.sessions
+  ADD COLUMN IF NOT EXISTS impersonation_id uuid NULL REFERENCES iam.impersonation_sessions(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS sessions_impersonation_idx ON iam.sessions (impersonation_id) WHERE impersonation_id IS NOT NULL;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.impersonation_sessions TO superadmin_runtime';
+    EXECUTE 'GRANT SELECT ON iam.impersonation_request_logs TO superadmin_runtime';
+    EXECUTE 'GRANT UPDATE (revoked_at) ON iam.sessions TO superadmin_runtime';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_runtime') THEN
+    EXECUTE 'GRANT SELECT, UPDATE ON iam.impersonation_sessions TO app_runtime';
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.impersonation_request_logs TO app_runtime';
+    EXECUTE 'GRANT USAGE, SELECT ON SEQUENCE iam.impersonation_request_logs_id_seq TO app_runtime';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_nobypassrls') THEN
+    EXECUTE 'GRANT SELECT, UPDATE ON iam.impersonation_sessions TO app_nobypassrls';
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.impersonation_request_logs TO app_nobypassrls';
+    EXECUTE 'GRANT USAGE, SELECT ON SEQUENCE iam.impersonation_request_logs_id_seq TO app_nobypassrls';
+  END IF;
+END
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'REVOKE UPDATE (revoked_at) ON iam.sessions FROM superadmin_runtime';
+  END IF;
+END
+$$;
+DROP INDEX IF EXISTS iam.sessions_impersonation_idx;
+ALTER TABLE iam.sessions DROP COLUMN IF EXISTS impersonation_id;
+DROP TABLE IF EXISTS iam.impersonation_request_logs;
+DROP TABLE IF EXISTS iam.impersonation_sessions;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Keyset indexes for the superadmin audit log API: filters by actor, action or time range page by id.
+CREATE INDEX IF NOT EXISTS superadmin_audit_logs_actor_idx ON iam.superadmin_audit_logs (actor, id);
+CREATE INDEX IF NOT EXISTS superadmin_audit_logs_action_idx ON iam.superadmin_audit_logs (action text_pattern_ops, id);
+CREATE INDEX IF NOT EXISTS superadmin_audit_logs_created_at_idx ON iam.superadmin_audit_logs (created_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS iam.superadmin_audit_logs_created_at_idx;
+DROP INDEX IF EXISTS iam.superadmin_audit_logs_action_idx;
+DROP INDEX IF EXISTS iam.superadmin_audit_logs_actor_idx;
+-- +goose StatementEnd
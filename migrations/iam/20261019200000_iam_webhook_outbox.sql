@@ -0,0 +1,487 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Webhook outbox: change events are written by triggers in the same transaction as the business event, then
+-- delivered to tenant-registered endpoints by the server's delivery worker. The worker spans tenants, so the
+-- tables follow iam.sessions (no RLS, always filtered by tenant_uuid) and rows are only written through
+-- iam.enqueue_webhook_event() or the tenant-scoped webhook APIs.
+CREATE TABLE IF NOT EXISTS iam.webhook_subscriptions (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  endpoint_url text NOT NULL,
+  event_types text[] NOT NULL,
+  secret text NOT NULL,
+  description text NOT NULL DEFAULT '',
+  active boolean NOT NULL DEFAULT true,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT webhook_subscriptions_endpoint_url_check CHECK (endpoint_url ~ '^https?://'),
+  CONSTRAINT webhook_subscriptions_event_types_nonempty_check CHECK (cardinality(event_types) > 0),
+  CONSTRAINT webhook_subscriptions_secret_len_check CHECK (char_length(secret) >= 16)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_subscriptions_tenant_idx ON iam.webhook_subscriptions (tenant_uuid, created_at, id);
+
+CREATE TABLE IF NOT EXISTS iam.webhook_outbox (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  source text NOT NULL,
+  event_uuid uuid NOT NULL,
+  event_type text NOT NULL,
+  payload jsonb NOT NULL DEFAULT '{}'::jsonb,
+  occurred_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT webhook_outbox_source_check CHECK (source IN ('orgunit','dict')),
+  CONSTRAINT webhook_outbox_event_type_nonempty_check CHECK (btrim(event_type) <> ''),
+  CONSTRAINT webhook_outbox_payload_is_object_check CHECK (jsonb_typeof(payload) = 'object')
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS webhook_outbox_event_unique ON iam.webhook_outbox (source, event_uuid);
+CREATE INDEX IF NOT EXISTS webhook_outbox_tenant_idx ON iam.webhook_outbox (tenant_uuid, occurred_at, id);
+
+CREATE TABLE IF NOT EXISTS iam.webhook_deliveries (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  subscription_id uuid NOT NULL REFERENCES iam.webhook_subscriptions(id) ON DELETE CASCADE,
+  outbox_id bigint NOT NULL REFERENCES iam.webhook_outbox(id) ON DELETE CASCADE,
+  status text NOT NULL DEFAULT 'pending',
+  attempts integer NOT NULL DEFAULT 0,
+  next_attempt_at timestamptz NOT NULL DEFAULT now(),
+  last_status_code integer NULL,
+  last_error text NOT NULL DEFAULT '',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  delivered_at timestamptz NULL,
+  dead_at timestamptz NULL,
+  CONSTRAINT webhook_deliveries_status_check CHECK (status IN ('pending','delivered','dead')),
+  CONSTRAINT webhook_deliveries_attempts_check CHECK (attempts >= 0),
+  CONSTRAINT webhook_deliveries_subscription_outbox_unique UNIQUE (subscription_id, outbox_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON iam.webhook_deliveries (next_attempt_at, id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_tenant_idx ON iam.webhook_deliveries (tenant_uuid, status, id DESC);
+
+-- enqueue_webhook_event records one change event and fans it out to the tenant's active subscriptions.
+-- It is idempotent per (source, event_uuid); the delivery worker polls for due rows.
+CREATE OR REPLACE FUNCTION iam.enqueue_webhook_event(
+  p_tenant_uuid uuid,
+  p_source text,
+  p_event_uuid uuid,
+  p_event_type text,
+  p_payload jsonb
+)
+RETURNS bigint
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
+AS $$
+DECLARE
+  v_outbox_id bigint;
+BEGIN
+  INSERT INTO iam.webhook_outbox (tenant_uuid, source, event_uuid, event_type, payload)
+  VALUES (p_tenant_uuid, p_source, p_event_uuid, p_event_type, COALESCE(p_payload, '{}'::jsonb))
+  ON CONFLICT (source, event_uuid) DO NOTHING
+  RETURNING id INTO v_outbox_id;
+
+  IF v_outbox_id IS NULL THEN
+    RETURN NULL;
+  END IF;
+
+  INSERT INTO iam.webhook_deliveries (tenant_uuid, subscription_id, outbox_id)
+  SELECT s.tenant_uuid, s.id, v_outbox_id
+  FROM iam.webhook_subscriptions s
+  WHERE s.tenant_uuid = p_tenant_uuid
+    AND s.active
+    AND (p_event_type = ANY (s.event_types) OR '*' = ANY (s.event_types));
+
+  RETURN v_outbox_id;
+END;
+$$;
+
+REVOKE ALL ON FUNCTION iam.enqueue_webhook_event(uuid, text, uuid, text, jsonb) FROM PUBLIC;
+
+CREATE OR REPLACE FUNCTION iam.enqueue_dict_value_webhook_event()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_event_type text;
+BEGIN
+  v_event_type := CASE NEW.event_type
+    WHEN 'DICT_VALUE_CREATED' THEN 'dict.value_created'
+    WHEN 'LABEL_CORRECTED' THEN 'dict.value_corrected'
+    WHEN 'DISABLED' THEN 'dict.value_disabled'
+    WHEN 'REENABLED' THEN 'dict.value_enabled'
+    WHEN 'RESCINDED' THEN 'dict.value_rescinded'
+    ELSE 'dict.value_changed'
+  END;
+
+  PERFORM iam.enqueue_webhook_event(
+    NEW.tenant_uuid,
+    'dict',
+    NEW.event_uuid,
+    v_event_type,
+    jsonb_build_object(
+      'dict_code', NEW.dict_code,
+      'code', NEW.code,
+      'effective_day', NEW.effective_day,
+      'source_event_type', NEW.event_type,
+      'request_id', NEW.request_id,
+      'before', NEW.before_snapshot,
+      'after', NEW.after_snapshot
+    )
+  );
+  RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS dict_value_events_enqueue_webhook ON iam.dict_value_events;
+CREATE TRIGGER dict_value_events_enqueue_webhook
+AFTER INSERT ON iam.dict_value_events
+FOR EACH ROW
+EXECUTE FUNCTION iam.enqueue_dict_value_webhook_event();
+
+CREATE OR REPLACE FUNCTION iam.seed_builtin_authz_roles(p_tenant_uuid uuid)
+RETURNS void
+LANGUAGE plpgsql
+SECURITY DEFINER
+AS $$
+DECLARE
+  v_admin_role_id uuid;
+  v_viewer_role_id uuid;
+BEGIN
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  INSERT INTO iam.role_definitions (tenant_uuid, role_slug, name, description, system_managed)
+  VALUES (
+    p_tenant_uuid,
+    'tenant-admin',
+    'Tenant Admin',
+    'Built-in tenant administrator role',
+    true
+  )
+  ON CONFLICT (tenant_uuid, role_slug) DO UPDATE SET
+    name = EXCLUDED.name,
+    description = EXCLUDED.description,
+    system_managed = true,
+    updated_at = now()
+  RETURNING id INTO v_admin_role_id;
+
+  INSERT INTO iam.role_definitions (tenant_uuid, role_slug, name, description, system_managed)
+  VALUES (
+    p_tenant_uuid,
+    'tenant-viewer',
+    'Tenant Viewer',
+    'Built-in tenant viewer role',
+    true
+  )
+  ON CONFLICT (tenant_uuid, role_slug) DO UPDATE SET
+    name = EXCLUDED.name,
+    description = EXCLUDED.description,
+    system_managed = true,
+    updated_at = now()
+  RETURNING id INTO v_viewer_role_id;
+
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (v_admin_role_id, v_viewer_role_id);
+
+  INSERT INTO iam.role_authz_capabilities (role_id, authz_capability_key)
+  SELECT v_admin_role_id, key
+  FROM unnest(ARRAY[
+    'iam.authz:read',
+    'iam.authz:admin',
+    'iam.dicts:read',
+    'iam.dicts:admin',
+    'iam.dict_release:admin',
+    'iam.webhooks:admin',
+    'cubebox.conversations:read',
+    'cubebox.conversations:use',
+    'cubebox.model_provider:update',
+    'cubebox.model_credential:read',
+    'cubebox.model_credential:rotate',
+    'cubebox.model_credential:deactivate',
+    'cubebox.model_selection:select',
+    'cubebox.model_selection:verify',
+    'orgunit.orgunits:read',
+    'orgunit.orgunits:admin'
+  ]::text[]) AS key
+  ON CONFLICT DO NOTHING;
+
+  INSERT INTO iam.role_authz_capabilities (role_id, authz_capability_key)
+  SELECT v_viewer_role_id, key
+  FROM unnest(ARRAY[
+    'iam.dicts:read',
+    'cubebox.conversations:read',
+    'cubebox.conversations:use',
+    'orgunit.orgunits:read'
+  ]::text[]) AS key
+  ON CONFLICT DO NOTHING;
+END
+$$;
+
+DO $$
+DECLARE
+  v_tenant uuid;
+BEGIN
+  FOR v_tenant IN SELECT id FROM iam.tenants LOOP
+    PERFORM iam.seed_builtin_authz_roles(v_tenant);
+  END LOOP;
+END
+$$;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_runtime') THEN
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.webhook_subscriptions TO app_runtime';
+    EXECUTE 'GRANT SELECT ON iam.webhook_outbox TO app_runtime';
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.webhook_deliveries TO app_runtime';
+    EXECUTE 'GRANT USAGE, SELECT ON SEQUENCE iam.webhook_deliveries_id_seq TO app_runtime';
+    EXECUTE 'GRANT EXECUTE ON FUNCTION iam.enqueue_webhook_event(uuid, text, uuid, text, jsonb) TO app_runtime';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_nobypassrls') THEN
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.webhook_subscriptions TO app_nobypassrls';
+    EXECUTE 'GRANT SELECT ON iam.webhook_outbox TO app_nobypassrls';
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.webhook_deliveries TO app_nobypassrls';
+    EXECUTE 'GRANT USAGE, SELECT ON SEQUENCE iam.webhook_deliveries_id_seq TO app_nobypassrls';
+    EXECUTE 'GRANT EXECUTE ON FUNCTION iam.enqueue_webhook_event(uuid, text, uuid, text, jsonb) TO app_nobypassrls';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    -- Tenant provisioning publishes the dict baseline, which fires the dict value webhook trigger.
+    EXECUTE 'GRANT EXECUTE ON FUNCTION iam.enqueue_webhook_event(uuid, text, uuid, text, jsonb) TO superadmin_runtime';
+    -- Offboarding verification counts residual rows in these tables after iam.purge_tenant_data().
+    EXECUTE 'GRANT SELECT ON iam.webhook_subscriptions, iam.webhook_outbox, iam.webhook_deliveries TO superadmin_runtime';
+  END IF;
+END
+$$;
+
+-- Tenant hard delete also clears webhook subscriptions, the outbox and the delivery log.
+CREATE OR REPLACE FUNCTION iam.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'iam.webhook_deliveries',
+    'iam.webhook_outbox',
+    'iam.webhook_subscriptions',
+    'iam.principal_org_scope_bindings',
+    'iam.principal_authz_assignment_revisions',
+    'iam.principal_role_assignments',
+    'iam.role_definitions',
+    'iam.cubebox_model_selections',
+    'iam.cubebox_model_health_checks',
+    'iam.cubebox_model_credentials',
+    'iam.cubebox_model_providers',
+    'iam.cubebox_conversation_events',
+    'iam.cubebox_conversations',
+    'iam.dict_value_segments',
+    'iam.dict_value_events',
+    'iam.dict_events',
+    'iam.dicts',
+    'iam.sessions',
+    'iam.principals',
+    'iam.tenant_domains'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+  IF EXISTS (SELECT 1 FROM iam.tenants WHERE id = p_tenant_uuid AND is_active) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_ACTIVE',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (SELECT id FROM iam.role_definitions WHERE tenant_uuid = p_tenant_uuid);
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.role_authz_capabilities', v_count);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  DELETE FROM iam.tenants WHERE id = p_tenant_uuid;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.tenants', v_count);
+
+  RETURN v_counts;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION iam.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'iam.principal_org_scope_bindings',
+    'iam.principal_authz_assignment_revisions',
+    'iam.principal_role_assignments',
+    'iam.role_definitions',
+    'iam.cubebox_model_selections',
+    'iam.cubebox_model_health_checks',
+    'iam.cubebox_model_credentials',
+    'iam.cubebox_model_providers',
+    'iam.cubebox_conversation_events',
+    'iam.cubebox_conversations',
+    'iam.dict_value_segments',
+    'iam.dict_value_events',
+    'iam.dict_events',
+    'iam.dicts',
+    'iam.sessions',
+    'iam.principals',
+    'iam.tenant_domains'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+  IF EXISTS (SELECT 1 FROM iam.tenants WHERE id = p_tenant_uuid AND is_active) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_ACTIVE',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (SELECT id FROM iam.role_definitions WHERE tenant_uuid = p_tenant_uuid);
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.role_authz_capabilities', v_count);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  DELETE FROM iam.tenants WHERE id = p_tenant_uuid;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.tenants', v_count);
+
+  RETURN v_counts;
+END;
+$$;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'REVOKE SELECT ON iam.webhook_subscriptions, iam.webhook_outbox, iam.webhook_deliveries FROM superadmin_runtime';
+  END IF;
+END
+$$;
+
+CREATE OR REPLACE FUNCTION iam.seed_builtin_authz_roles(p_tenant_uuid uuid)
+RETURNS void
+LANGUAGE plpgsql
+SECURITY DEFINER
+AS $$
+DECLARE
+  v_admin_role_id uuid;
+  v_viewer_role_id uuid;
+BEGIN
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  INSERT INTO iam.role_definitions (tenant_uuid, role_slug, name, description, system_managed)
+  VALUES (
+    p_tenant_uuid,
+    'tenant-admin',
+    'Tenant Admin',
+    'Built-in tenant administrator role',
+    true
+  )
+  ON CONFLICT (tenant_uuid, role_slug) DO UPDATE SET
+    name = EXCLUDED.name,
+    description = EXCLUDED.description,
+    system_managed = true,
+    updated_at = now()
+  RETURNING id INTO v_admin_role_id;
+
+  INSERT INTO iam.role_definitions (tenant_uuid, role_slug, name, description, system_managed)
+  VALUES (
+    p_tenant_uuid,
+    'tenant-viewer',
+    'Tenant Viewer',
+    'Built-in tenant viewer role',
+    true
+  )
+  ON CONFLICT (tenant_uuid, role_slug) DO UPDATE SET
+    name = EXCLUDED.name,
+    description = EXCLUDED.description,
+    system_managed = true,
+    updated_at = now()
+  RETURNING id INTO v_viewer_role_id;
+
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (v_admin_role_id, v_viewer_role_id);
+
+  INSERT INTO iam.role_authz_capabilities (role_id, authz_capability_key)
+  SELECT v_admin_role_id, key
+  FROM unnest(ARRAY[
+    'iam.authz:read',
+    'iam.authz:admin',
+    'iam.dicts:read',
+    'iam.dicts:admin',
+    'iam.dict_release:admin',
+    'cubebox.conversations:read',
+    'cubebox.conversations:use',
+    'cubebox.model_provider:update',
+    'cubebox.model_credential:read',
+    'cubebox.model_credential:rotate',
+    'cubebox.model_credential:deactivate',
+    'cubebox.model_selection:select',
+    'cubebox.model_selection:verify',
+    'orgunit.orgunits:read',
+    'orgunit.orgunits:admin'
+  ]::text[]) AS key
+  ON CONFLICT DO NOTHING;
+
+  INSERT INTO iam.role_authz_capabilities (role_id, authz_capability_key)
+  SELECT v_viewer_role_id, key
+  FROM unnest(ARRAY[
+    'iam.dicts:read',
+    'cubebox.conversations:read',
+    'cubebox.conversations:use',
+    'orgunit.orgunits:read'
+  ]::text[]) AS key
+  ON CONFLICT DO NOTHING;
+END
+$$;
+
+DO $$
+DECLARE
+  v_tenant uuid;
+BEGIN
+  FOR v_tenant IN SELECT id FROM iam.tenants LOOP
+    PERFORM iam.seed_builtin_authz_roles(v_tenant);
+  END LOOP;
+END
+$$;
+
+DROP TRIGGER IF EXISTS dict_value_events_enqueue_webhook ON iam.dict_value_events;
+DROP FUNCTION IF EXISTS iam.enqueue_dict_value_webhook_event();
+DROP FUNCTION IF EXISTS iam.enqueue_webhook_event(uuid, text, uuid, text, jsonb);
+DROP TABLE IF EXISTS iam.webhook_deliveries;
+DROP TABLE IF EXISTS iam.webhook_outbox;
+DROP TABLE IF EXISTS iam.webhook_subscriptions;
+-- +goose StatementEnd
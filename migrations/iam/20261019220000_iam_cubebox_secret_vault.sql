@@ -0,0 +1,210 @@
+-- +goose Up
+-- +goose StatementBegin
+-- CubeBox secret vault: model secrets are encrypted with a per-tenant data key, and the data key is stored
+-- wrapped by a master key that only the server holds (CUBEBOX_VAULT_MASTER_KEY_FILE). master_key_version
+-- records which master key wrapped each data key so rotation can rewrap them tenant by tenant. Like the other
+-- cubebox tables these carry no RLS and are always filtered by tenant_uuid.
+CREATE TABLE IF NOT EXISTS iam.cubebox_vault_data_keys (
+  tenant_uuid uuid PRIMARY KEY REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  wrapped_key bytea NOT NULL,
+  master_key_version integer NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  rewrapped_at timestamptz NULL,
+  CONSTRAINT cubebox_vault_data_keys_wrapped_key_nonempty_check CHECK (octet_length(wrapped_key) > 0),
+  CONSTRAINT cubebox_vault_data_keys_master_key_version_positive_check CHECK (master_key_version > 0)
+);
+
+CREATE INDEX IF NOT EXISTS cubebox_vault_data_keys_master_key_version_idx
+  ON iam.cubebox_vault_data_keys (master_key_version);
+
+CREATE TABLE IF NOT EXISTS iam.cubebox_vault_secrets (
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  secret_id text NOT NULL,
+  provider_id text NOT NULL,
+  ciphertext bytea NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (tenant_uuid, secret_id),
+  CONSTRAINT cubebox_vault_secrets_id_nonempty_check CHECK (btrim(secret_id) <> ''),
+  CONSTRAINT cubebox_vault_secrets_provider_nonempty_check CHECK (btrim(provider_id) <> ''),
+  CONSTRAINT cubebox_vault_secrets_ciphertext_nonempty_check CHECK (octet_length(ciphertext) > 0)
+);
+
+CREATE TABLE IF NOT EXISTS iam.cubebox_vault_decrypt_audit (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  secret_id text NOT NULL,
+  provider_id text NOT NULL DEFAULT '',
+  master_key_version integer NOT NULL,
+  decrypted_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT cubebox_vault_decrypt_audit_secret_nonempty_check CHECK (btrim(secret_id) <> ''),
+  CONSTRAINT cubebox_vault_decrypt_audit_master_key_version_positive_check CHECK (master_key_version > 0)
+);
+
+CREATE INDEX IF NOT EXISTS cubebox_vault_decrypt_audit_tenant_decrypted_idx
+  ON iam.cubebox_vault_decrypt_audit (tenant_uuid, decrypted_at DESC, id DESC);
+
+-- Offboarding verification counts residual rows in these tables after iam.purge_tenant_data().
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT ON ' ||
+      'iam.cubebox_vault_decrypt_audit, ' ||
+      'iam.cubebox_vault_secrets, ' ||
+      'iam.cubebox_vault_data_keys ' ||
+      'TO superadmin_runtime';
+  END IF;
+END
+$$;
+
+-- Tenant hard delete also clears the vault: data keys, sealed secrets and the decrypt audit.
+CREATE OR REPLACE FUNCTION iam.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'iam.cubebox_vault_decrypt_audit',
+    'iam.cubebox_vault_secrets',
+    'iam.cubebox_vault_data_keys',
+    'iam.webhook_deliveries',
+    'iam.webhook_outbox',
+    'iam.webhook_subscriptions',
+    'iam.principal_org_scope_bindings',
+    'iam.principal_authz_assignment_revisions',
+    'iam.principal_role_assignments',
+    'iam.role_definitions',
+    'iam.cubebox_model_selections',
+    'iam.cubebox_model_health_checks',
+    'iam.cubebox_model_credentials',
+    'iam.cubebox_model_providers',
+    'iam.cubebox_conversation_events',
+    'iam.cubebox_conversations',
+    'iam.dict_value_segments',
+    'iam.dict_value_events',
+    'iam.dict_events',
+    'iam.dicts',
+    'iam.sessions',
+    'iam.principals',
+    'iam.tenant_domains'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+  IF EXISTS (SELECT 1 FROM iam.tenants WHERE id = p_tenant_uuid AND is_active) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_ACTIVE',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (SELECT id FROM iam.role_definitions WHERE tenant_uuid = p_tenant_uuid);
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.role_authz_capabilities', v_count);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  DELETE FROM iam.tenants WHERE id = p_tenant_uuid;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.tenants', v_count);
+
+  RETURN v_counts;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION iam.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'iam.webhook_deliveries',
+    'iam.webhook_outbox',
+    'iam.webhook_subscriptions',
+    'iam.principal_org_scope_bindings',
+    'iam.principal_authz_assignment_revisions',
+    'iam.principal_role_assignments',
+    'iam.role_definitions',
+    'iam.cubebox_model_selections',
+    'iam.cubebox_model_health_checks',
+    'iam.cubebox_model_credentials',
+    'iam.cubebox_model_providers',
+    'iam.cubebox_conversation_events',
+    'iam.cubebox_conversations',
+    'iam.dict_value_segments',
+    'iam.dict_value_events',
+    'iam.dict_events',
+    'iam.dicts',
+    'iam.sessions',
+    'iam.principals',
+    'iam.tenant_domains'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+  IF EXISTS (SELECT 1 FROM iam.tenants WHERE id = p_tenant_uuid AND is_active) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_ACTIVE',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (SELECT id FROM iam.role_definitions WHERE tenant_uuid = p_tenant_uuid);
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.role_authz_capabilities', v_count);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  DELETE FROM iam.tenants WHERE id = p_tenant_uuid;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.tenants', v_count);
+
+  RETURN v_counts;
+END;
+$$;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'REVOKE SELECT ON ' ||
+      'iam.cubebox_vault_decrypt_audit, ' ||
+      'iam.cubebox_vault_secrets, ' ||
+      'iam.cubebox_vault_data_keys ' ||
+      'FROM superadmin_runtime';
+  END IF;
+END
+$$;
+DROP TABLE IF EXISTS iam.cubebox_vault_decrypt_audit;
+DROP TABLE IF EXISTS iam.cubebox_vault_secrets;
+DROP TABLE IF EXISTS iam.cubebox_vault_data_keys;
+-- +goose StatementEnd
@@ -0,0 +1,210 @@
+-- +goose Up
+-- +goose StatementBegin
+-- CubeBox token usage: one row per (turn, model) with prompt/completion token counts taken from the provider
+-- response, or estimated from text length when the provider reports none (estimated = true). Rows are a cost
+-- ledger and deliberately outlive the conversation and principal they describe.
+-- Token budgets cap daily and monthly usage per tenant (principal_id = '') or per principal; a limit of 0
+-- means unlimited. Like the other cubebox tables these carry no RLS and are always filtered by tenant_uuid.
+CREATE TABLE IF NOT EXISTS iam.cubebox_turn_usage (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  principal_id uuid NOT NULL,
+  conversation_id text NOT NULL,
+  turn_id text NOT NULL,
+  provider_id text NOT NULL DEFAULT '',
+  model_slug text NOT NULL,
+  prompt_tokens bigint NOT NULL DEFAULT 0,
+  completion_tokens bigint NOT NULL DEFAULT 0,
+  total_tokens bigint GENERATED ALWAYS AS (prompt_tokens + completion_tokens) STORED,
+  estimated boolean NOT NULL DEFAULT false,
+  recorded_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT cubebox_turn_usage_turn_model_unique UNIQUE (tenant_uuid, conversation_id, turn_id, model_slug),
+  CONSTRAINT cubebox_turn_usage_turn_nonempty_check CHECK (btrim(turn_id) <> ''),
+  CONSTRAINT cubebox_turn_usage_model_nonempty_check CHECK (btrim(model_slug) <> ''),
+  CONSTRAINT cubebox_turn_usage_tokens_nonnegative_check CHECK (prompt_tokens >= 0 AND completion_tokens >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS cubebox_turn_usage_tenant_recorded_idx
+  ON iam.cubebox_turn_usage (tenant_uuid, recorded_at DESC);
+
+CREATE INDEX IF NOT EXISTS cubebox_turn_usage_tenant_principal_recorded_idx
+  ON iam.cubebox_turn_usage (tenant_uuid, principal_id, recorded_at DESC);
+
+CREATE TABLE IF NOT EXISTS iam.cubebox_token_budgets (
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  principal_id text NOT NULL DEFAULT '',
+  daily_token_limit bigint NOT NULL DEFAULT 0,
+  monthly_token_limit bigint NOT NULL DEFAULT 0,
+  updated_by uuid NULL,
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (tenant_uuid, principal_id),
+  CONSTRAINT cubebox_token_budgets_limits_nonnegative_check CHECK (daily_token_limit >= 0 AND monthly_token_limit >= 0)
+);
+
+-- Offboarding export reads these tables and verification counts residual rows after iam.purge_tenant_data().
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT ON ' ||
+      'iam.cubebox_turn_usage, ' ||
+      'iam.cubebox_token_budgets ' ||
+      'TO superadmin_runtime';
+  END IF;
+END
+$$;
+
+-- Tenant hard delete also clears the usage ledger and token budgets.
+CREATE OR REPLACE FUNCTION iam.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'iam.cubebox_turn_usage',
+    'iam.cubebox_token_budgets',
+    'iam.cubebox_vault_decrypt_audit',
+    'iam.cubebox_vault_secrets',
+    'iam.cubebox_vault_data_keys',
+    'iam.webhook_deliveries',
+    'iam.webhook_outbox',
+    'iam.webhook_subscriptions',
+    'iam.principal_org_scope_bindings',
+    'iam.principal_authz_assignment_revisions',
+    'iam.principal_role_assignments',
+    'iam.role_definitions',
+    'iam.cubebox_model_selections',
+    'iam.cubebox_model_health_checks',
+    'iam.cubebox_model_credentials',
+    'iam.cubebox_model_providers',
+    'iam.cubebox_conversation_events',
+    'iam.cubebox_conversations',
+    'iam.dict_value_segments',
+    'iam.dict_value_events',
+    'iam.dict_events',
+    'iam.dicts',
+    'iam.sessions',
+    'iam.principals',
+    'iam.tenant_domains'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+  IF EXISTS (SELECT 1 FROM iam.tenants WHERE id = p_tenant_uuid AND is_active) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_ACTIVE',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (SELECT id FROM iam.role_definitions WHERE tenant_uuid = p_tenant_uuid);
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.role_authz_capabilities', v_count);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  DELETE FROM iam.tenants WHERE id = p_tenant_uuid;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.tenants', v_count);
+
+  RETURN v_counts;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION iam.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'iam.cubebox_vault_decrypt_audit',
+    'iam.cubebox_vault_secrets',
+    'iam.cubebox_vault_data_keys',
+    'iam.webhook_deliveries',
+    'iam.webhook_outbox',
+    'iam.webhook_subscriptions',
+    'iam.principal_org_scope_bindings',
+    'iam.principal_authz_assignment_revisions',
+    'iam.principal_role_assignments',
+    'iam.role_definitions',
+    'iam.cubebox_model_selections',
+    'iam.cubebox_model_health_checks',
+    'iam.cubebox_model_credentials',
+    'iam.cubebox_model_providers',
+    'iam.cubebox_conversation_events',
+    'iam.cubebox_conversations',
+    'iam.dict_value_segments',
+    'iam.dict_value_events',
+    'iam.dict_events',
+    'iam.dicts',
+    'iam.sessions',
+    'iam.principals',
+    'iam.tenant_domains'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+  IF EXISTS (SELECT 1 FROM iam.tenants WHERE id = p_tenant_uuid AND is_active) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_ACTIVE',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (SELECT id FROM iam.role_definitions WHERE tenant_uuid = p_tenant_uuid);
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.role_authz_capabilities', v_count);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  DELETE FROM iam.tenants WHERE id = p_tenant_uuid;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.tenants', v_count);
+
+  RETURN v_counts;
+END;
+$$;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'REVOKE SELECT ON ' ||
+      'iam.cubebox_turn_usage, ' ||
+      'iam.cubebox_token_budgets ' ||
+      'FROM superadmin_runtime';
+  END IF;
+END
+$$;
+DROP TABLE IF EXISTS iam.cubebox_token_budgets;
+DROP TABLE IF EXISTS iam.cubebox_turn_usage;
+-- +goose StatementEnd
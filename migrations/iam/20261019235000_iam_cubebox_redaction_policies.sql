@@ -0,0 +1,198 @@
+-- +goose Up
+-- +goose StatementBegin
+-- CubeBox redaction policies: which kinds of values (pernr, email, phone, identity) and which ext field keys
+-- are replaced with placeholders before prompts reach a model provider. Tenants without a row get the
+-- built-in default (enabled, every kind). Each change is appended to the audit table with the full policy.
+-- Like the other cubebox tables these carry no RLS and are always filtered by tenant_uuid.
+CREATE TABLE IF NOT EXISTS iam.cubebox_redaction_policies (
+  tenant_uuid uuid PRIMARY KEY REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  enabled boolean NOT NULL DEFAULT true,
+  kinds text[] NOT NULL DEFAULT '{}',
+  ext_fields text[] NOT NULL DEFAULT '{}',
+  updated_by uuid NULL,
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS iam.cubebox_redaction_policy_audit (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  changed_by uuid NOT NULL,
+  changed_at timestamptz NOT NULL DEFAULT now(),
+  policy jsonb NOT NULL,
+  CONSTRAINT cubebox_redaction_policy_audit_policy_object_check CHECK (jsonb_typeof(policy) = 'object')
+);
+
+CREATE INDEX IF NOT EXISTS cubebox_redaction_policy_audit_tenant_id_idx
+  ON iam.cubebox_redaction_policy_audit (tenant_uuid, id DESC);
+
+-- Offboarding export reads these tables and verification counts residual rows after iam.purge_tenant_data().
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT ON ' ||
+      'iam.cubebox_redaction_policy_audit, ' ||
+      'iam.cubebox_redaction_policies ' ||
+      'TO superadmin_runtime';
+  END IF;
+END
+$$;
+
+-- Tenant hard delete also clears redaction policies and their audit trail.
+CREATE OR REPLACE FUNCTION iam.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'iam.cubebox_redaction_policy_audit',
+    'iam.cubebox_redaction_policies',
+    'iam.cubebox_turn_usage',
+    'iam.cubebox_token_budgets',
+    'iam.cubebox_vault_decrypt_audit',
+    'iam.cubebox_vault_secrets',
+    'iam.cubebox_vault_data_keys',
+    'iam.webhook_deliveries',
+    'iam.webhook_outbox',
+    'iam.webhook_subscriptions',
+    'iam.principal_org_scope_bindings',
+    'iam.principal_authz_assignment_revisions',
+    'iam.principal_role_assignments',
+    'iam.role_definitions',
+    'iam.cubebox_model_selections',
+    'iam.cubebox_model_health_checks',
+    'iam.cubebox_model_credentials',
+    'iam.cubebox_model_providers',
+    'iam.cubebox_conversation_events',
+    'iam.cubebox_conversations',
+    'iam.dict_value_segments',
+    'iam.dict_value_events',
+    'iam.dict_events',
+    'iam.dicts',
+    'iam.sessions',
+    'iam.principals',
+    'iam.tenant_domains'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+  IF EXISTS (SELECT 1 FROM iam.tenants WHERE id = p_tenant_uuid AND is_active) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_ACTIVE',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (SELECT id FROM iam.role_definitions WHERE tenant_uuid = p_tenant_uuid);
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.role_authz_capabilities', v_count);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  DELETE FROM iam.tenants WHERE id = p_tenant_uuid;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.tenants', v_count);
+
+  RETURN v_counts;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION iam.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'iam.cubebox_turn_usage',
+    'iam.cubebox_token_budgets',
+    'iam.cubebox_vault_decrypt_audit',
+    'iam.cubebox_vault_secrets',
+    'iam.cubebox_vault_data_keys',
+    'iam.webhook_deliveries',
+    'iam.webhook_outbox',
+    'iam.webhook_subscriptions',
+    'iam.principal_org_scope_bindings',
+    'iam.principal_authz_assignment_revisions',
+    'iam.principal_role_assignments',
+    'iam.role_definitions',
+    'iam.cubebox_model_selections',
+    'iam.cubebox_model_health_checks',
+    'iam.cubebox_model_credentials',
+    'iam.cubebox_model_providers',
+    'iam.cubebox_conversation_events',
+    'iam.cubebox_conversations',
+    'iam.dict_value_segments',
+    'iam.dict_value_events',
+    'iam.dict_events',
+    'iam.dicts',
+    'iam.sessions',
+    'iam.principals',
+    'iam.tenant_domains'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+  IF EXISTS (SELECT 1 FROM iam.tenants WHERE id = p_tenant_uuid AND is_active) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_ACTIVE',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (SELECT id FROM iam.role_definitions WHERE tenant_uuid = p_tenant_uuid);
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.role_authz_capabilities', v_count);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  DELETE FROM iam.tenants WHERE id = p_tenant_uuid;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.tenants', v_count);
+
+  RETURN v_counts;
+END;
+$$;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'REVOKE SELECT ON ' ||
+      'iam.cubebox_redaction_policy_audit, ' ||
+      'iam.cubebox_redaction_policies ' ||
+      'FROM superadmin_runtime';
+  END IF;
+END
+$$;
+DROP TABLE IF EXISTS iam.cubebox_redaction_policy_audit;
+DROP TABLE IF EXISTS iam.cubebox_redaction_policies;
+-- +goose StatementEnd
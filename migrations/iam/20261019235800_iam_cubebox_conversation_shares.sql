@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+-- CubeBox share links: read-only access to one conversation, or to one turn of it, for a fixed list of
+-- principals of the owner's tenant. Links expire and can be revoked by their owner; revoked rows are kept
+-- so the owner can see what was shared. Shares go with their conversation, including retention purges.
+-- Like the other cubebox tables this carries no RLS and is always filtered by tenant_uuid.
+CREATE TABLE IF NOT EXISTS iam.cubebox_conversation_shares (
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  share_id text NOT NULL,
+  conversation_id text NOT NULL,
+  turn_id text NULL,
+  owner_principal_id uuid NOT NULL,
+  principal_ids uuid[] NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  expires_at timestamptz NOT NULL,
+  revoked_at timestamptz NULL,
+  PRIMARY KEY (tenant_uuid, share_id),
+  CONSTRAINT cubebox_conversation_shares_conversation_fk FOREIGN KEY (tenant_uuid, conversation_id)
+    REFERENCES iam.cubebox_conversations(tenant_uuid, conversation_id) ON DELETE CASCADE,
+  CONSTRAINT cubebox_conversation_shares_principals_nonempty_check CHECK (cardinality(principal_ids) > 0),
+  CONSTRAINT cubebox_conversation_shares_expiry_check CHECK (expires_at > created_at),
+  CONSTRAINT cubebox_conversation_shares_turn_id_nonempty_or_null_check CHECK (
+    turn_id IS NULL OR btrim(turn_id) <> ''
+  )
+);
+
+CREATE INDEX IF NOT EXISTS cubebox_conversation_shares_owner_idx
+  ON iam.cubebox_conversation_shares (tenant_uuid, owner_principal_id, created_at DESC);
+
+-- Offboarding export reads share links and verification counts residual rows; they cascade with their conversation.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT ON ' ||
+      'iam.cubebox_conversation_shares ' ||
+      'TO superadmin_runtime';
+  END IF;
+END
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'REVOKE SELECT ON ' ||
+      'iam.cubebox_conversation_shares ' ||
+      'FROM superadmin_runtime';
+  END IF;
+END
+$$;
+DROP TABLE IF EXISTS iam.cubebox_conversation_shares;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- CubeBox answer feedback is stored as turn.feedback conversation events. Reports and dataset exports read
+-- them tenant-wide by time, which the per-conversation event index cannot serve.
+CREATE INDEX IF NOT EXISTS cubebox_conversation_events_feedback_idx
+  ON iam.cubebox_conversation_events (tenant_uuid, created_at)
+  WHERE event_type = 'turn.feedback';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS iam.cubebox_conversation_events_feedback_idx;
+-- +goose StatementEnd
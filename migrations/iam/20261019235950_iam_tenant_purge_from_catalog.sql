@@ -0,0 +1,158 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Tenant hard delete purges every iam table that carries a tenant_uuid column, found in the catalog the same
+-- way offboarding verification finds them, so a new tenant-scoped table is covered without redefining this
+-- function. Tables go before the tables they reference. The superadmin's provisioning runs and offboarding
+-- requests are kept: they are records about the tenant, and runs lose their tenant reference with the row.
+CREATE OR REPLACE FUNCTION iam.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
+AS $$
+DECLARE
+  v_keep oid[] := ARRAY[
+    'iam.superadmin_tenant_provisioning_runs'::regclass::oid,
+    'iam.superadmin_tenant_offboarding_requests'::regclass::oid
+  ];
+  v_pending oid[];
+  v_table oid;
+  v_name text;
+  v_progress boolean;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+  IF EXISTS (SELECT 1 FROM iam.tenants WHERE id = p_tenant_uuid AND is_active) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_ACTIVE',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (SELECT id FROM iam.role_definitions WHERE tenant_uuid = p_tenant_uuid);
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.role_authz_capabilities', v_count);
+
+  SELECT COALESCE(array_agg(c.oid ORDER BY c.relname), '{}'::oid[]) INTO v_pending
+  FROM pg_class c
+  JOIN pg_attribute a ON a.attrelid = c.oid AND a.attname = 'tenant_uuid' AND NOT a.attisdropped
+  WHERE c.relnamespace = 'iam'::regnamespace
+    AND c.relkind IN ('r', 'p')
+    AND NOT c.relispartition
+    AND c.oid <> ALL (v_keep);
+
+  WHILE cardinality(v_pending) > 0 LOOP
+    v_progress := false;
+    FOREACH v_table IN ARRAY v_pending LOOP
+      CONTINUE WHEN EXISTS (
+        SELECT 1
+        FROM pg_constraint fk
+        WHERE fk.contype = 'f'
+          AND fk.confrelid = v_table
+          AND fk.conrelid <> v_table
+          AND fk.conrelid = ANY (v_pending)
+      );
+      SELECT format('iam.%I', relname) INTO v_name FROM pg_class WHERE oid = v_table;
+      EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_name) USING p_tenant_uuid;
+      GET DIAGNOSTICS v_count = ROW_COUNT;
+      v_counts := v_counts || jsonb_build_object(v_name, v_count);
+      v_pending := array_remove(v_pending, v_table);
+      v_progress := true;
+    END LOOP;
+    IF NOT v_progress THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'IAM_TENANT_PURGE_FK_CYCLE',
+        DETAIL = format('tables=%s', v_pending::regclass[]);
+    END IF;
+  END LOOP;
+
+  DELETE FROM iam.tenants WHERE id = p_tenant_uuid;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.tenants', v_count);
+
+  RETURN v_counts;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION iam.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, iam, public
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'iam.cubebox_redaction_policy_audit',
+    'iam.cubebox_redaction_policies',
+    'iam.cubebox_turn_usage',
+    'iam.cubebox_token_budgets',
+    'iam.cubebox_vault_decrypt_audit',
+    'iam.cubebox_vault_secrets',
+    'iam.cubebox_vault_data_keys',
+    'iam.webhook_deliveries',
+    'iam.webhook_outbox',
+    'iam.webhook_subscriptions',
+    'iam.principal_org_scope_bindings',
+    'iam.principal_authz_assignment_revisions',
+    'iam.principal_role_assignments',
+    'iam.role_definitions',
+    'iam.cubebox_model_selections',
+    'iam.cubebox_model_health_checks',
+    'iam.cubebox_model_credentials',
+    'iam.cubebox_model_providers',
+    'iam.cubebox_conversation_events',
+    'iam.cubebox_conversations',
+    'iam.dict_value_segments',
+    'iam.dict_value_events',
+    'iam.dict_events',
+    'iam.dicts',
+    'iam.sessions',
+    'iam.principals',
+    'iam.tenant_domains'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+  IF EXISTS (SELECT 1 FROM iam.tenants WHERE id = p_tenant_uuid AND is_active) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'IAM_TENANT_PURGE_ACTIVE',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  DELETE FROM iam.role_authz_capabilities
+  WHERE role_id IN (SELECT id FROM iam.role_definitions WHERE tenant_uuid = p_tenant_uuid);
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.role_authz_capabilities', v_count);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  DELETE FROM iam.tenants WHERE id = p_tenant_uuid;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  v_counts := v_counts || jsonb_build_object('iam.tenants', v_count);
+
+  RETURN v_counts;
+END;
+$$;
+-- +goose StatementEnd
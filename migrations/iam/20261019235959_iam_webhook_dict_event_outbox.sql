@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Dict-level events (iam.dict_events, written by iam.submit_dict_event) feed the same webhook outbox as
+-- dict value events.
+CREATE OR REPLACE FUNCTION iam.enqueue_dict_webhook_event()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_event_type text;
+BEGIN
+  v_event_type := CASE NEW.event_type
+    WHEN 'DICT_CREATED' THEN 'dict.created'
+    WHEN 'DICT_DISABLED' THEN 'dict.disabled'
+    ELSE 'dict.changed'
+  END;
+
+  PERFORM iam.enqueue_webhook_event(
+    NEW.tenant_uuid,
+    'dict',
+    NEW.event_uuid,
+    v_event_type,
+    jsonb_build_object(
+      'dict_code', NEW.dict_code,
+      'effective_day', NEW.effective_day,
+      'source_event_type', NEW.event_type,
+      'request_id', NEW.request_id,
+      'before', NEW.before_snapshot,
+      'after', NEW.after_snapshot
+    )
+  );
+  RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS dict_events_enqueue_webhook ON iam.dict_events;
+CREATE TRIGGER dict_events_enqueue_webhook
+AFTER INSERT ON iam.dict_events
+FOR EACH ROW
+EXECUTE FUNCTION iam.enqueue_dict_webhook_event();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS dict_events_enqueue_webhook ON iam.dict_events;
+DROP FUNCTION IF EXISTS iam.enqueue_dict_webhook_event();
+-- +goose StatementEnd
@@ -0,0 +1,72 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Tenant hard delete: orgunit tables are kernel-owned and guarded, so the purge runs as orgunit_kernel.
+CREATE OR REPLACE FUNCTION orgunit.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'orgunit.org_unit_versions',
+    'orgunit.org_unit_codes',
+    'orgunit.org_trees',
+    'orgunit.org_events',
+    'orgunit.org_node_key_registry',
+    'orgunit.tenant_field_policies',
+    'orgunit.tenant_field_policy_events',
+    'orgunit.tenant_field_configs',
+    'orgunit.tenant_field_config_events'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = orgunit.global_tenant_id() THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORGUNIT_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  RETURN v_counts;
+END;
+$$;
+
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+REVOKE ALL ON FUNCTION orgunit.purge_tenant_data(uuid) FROM PUBLIC;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT USAGE ON SCHEMA orgunit TO superadmin_runtime';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.org_node_key_registry, ' ||
+      'orgunit.org_trees, ' ||
+      'orgunit.org_events, ' ||
+      'orgunit.org_unit_versions, ' ||
+      'orgunit.org_unit_codes, ' ||
+      'orgunit.tenant_field_configs, ' ||
+      'orgunit.tenant_field_config_events ' ||
+      'TO superadmin_runtime';
+    EXECUTE 'GRANT EXECUTE ON FUNCTION orgunit.purge_tenant_data(uuid) TO superadmin_runtime';
+  END IF;
+END $$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS orgunit.purge_tenant_data(uuid);
+-- +goose StatementEnd
@@ -0,0 +1,881 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Bitemporal reads (known_at): org_events_effective_for_replay takes an optional tx_time bound, and with a
+-- NULL org it folds the whole tenant. The per-event projection steps move out of the org rebuild into
+-- apply_replayed_org_event so a known_at replay of the tenant runs exactly the kernel projection.
+DROP FUNCTION IF EXISTS orgunit.org_events_effective_for_replay(uuid, char(8), bigint, uuid, text, date, jsonb, text, uuid, timestamptz, timestamptz, timestamptz);
+
+CREATE OR REPLACE FUNCTION orgunit.org_events_effective_for_replay(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_pending_event_id bigint DEFAULT NULL,
+  p_pending_event_uuid uuid DEFAULT NULL,
+  p_pending_event_type text DEFAULT NULL,
+  p_pending_effective_date date DEFAULT NULL,
+  p_pending_payload jsonb DEFAULT NULL,
+  p_pending_request_id text DEFAULT NULL,
+  p_pending_initiator_uuid uuid DEFAULT NULL,
+  p_pending_tx_time timestamptz DEFAULT NULL,
+  p_pending_transaction_time timestamptz DEFAULT NULL,
+  p_pending_created_at timestamptz DEFAULT NULL,
+  p_known_at timestamptz DEFAULT NULL
+)
+RETURNS TABLE (
+  id bigint,
+  event_uuid uuid,
+  tenant_uuid uuid,
+  org_node_key char(8),
+  event_type text,
+  effective_date date,
+  payload jsonb,
+  request_id text,
+  initiator_uuid uuid,
+  transaction_time timestamptz,
+  created_at timestamptz
+)
+LANGUAGE sql
+STABLE
+AS $$
+  WITH source_events AS (
+    SELECT
+      e.id,
+      e.event_uuid,
+      e.tenant_uuid,
+      e.org_node_key,
+      e.event_type,
+      e.effective_date,
+      COALESCE(e.payload, '{}'::jsonb) AS payload,
+      e.request_id,
+      e.initiator_uuid,
+      e.tx_time,
+      e.transaction_time,
+      e.created_at
+    FROM orgunit.org_events e
+    WHERE e.tenant_uuid = p_tenant_uuid
+      AND (p_org_node_key IS NULL OR e.org_node_key = p_org_node_key)
+      AND (p_known_at IS NULL OR e.tx_time <= p_known_at)
+
+    UNION ALL
+
+    SELECT
+      p_pending_event_id,
+      p_pending_event_uuid,
+      p_tenant_uuid,
+      p_org_node_key,
+      p_pending_event_type,
+      p_pending_effective_date,
+      COALESCE(p_pending_payload, '{}'::jsonb),
+      p_pending_request_id,
+      p_pending_initiator_uuid,
+      p_pending_tx_time,
+      p_pending_transaction_time,
+      p_pending_created_at
+    WHERE p_pending_event_id IS NOT NULL
+  ),
+  correction_events AS (
+    SELECT
+      se.*,
+      (se.payload->>'target_event_uuid')::uuid AS target_event_uuid
+    FROM source_events se
+    WHERE se.event_type IN ('CORRECT_EVENT','CORRECT_STATUS','RESCIND_EVENT','RESCIND_ORG')
+      AND se.payload ? 'target_event_uuid'
+  ),
+  latest_corrections AS (
+    SELECT DISTINCT ON (tenant_uuid, target_event_uuid)
+      tenant_uuid,
+      target_event_uuid,
+      event_type AS correction_type,
+      payload AS correction_payload,
+      tx_time,
+      id
+    FROM correction_events
+    ORDER BY tenant_uuid, target_event_uuid, tx_time DESC, id DESC
+  ),
+  latest_effective_date_corrections AS (
+    -- Sticky effective_date: take the latest CORRECT_EVENT that explicitly carries effective_date,
+    -- regardless of later corrections that don't include effective_date.
+    SELECT DISTINCT ON (tenant_uuid, target_event_uuid)
+      tenant_uuid,
+      target_event_uuid,
+      NULLIF(btrim(payload->>'effective_date'), '')::date AS sticky_effective_date,
+      tx_time,
+      id
+    FROM correction_events
+    WHERE event_type = 'CORRECT_EVENT'
+      AND payload ? 'effective_date'
+    ORDER BY tenant_uuid, target_event_uuid, tx_time DESC, id DESC
+  )
+  SELECT
+    se.id,
+    se.event_uuid,
+    se.tenant_uuid,
+    se.org_node_key,
+    CASE
+      WHEN lc.correction_type = 'CORRECT_STATUS'
+        AND COALESCE(lc.correction_payload->>'target_status', '') = 'active'
+        THEN 'ENABLE'
+      WHEN lc.correction_type = 'CORRECT_STATUS'
+        AND COALESCE(lc.correction_payload->>'target_status', '') = 'disabled'
+        THEN 'DISABLE'
+      WHEN lc.correction_type = 'CORRECT_EVENT'
+        AND se.event_type <> 'CREATE'
+        AND (
+          orgunit.merge_org_event_payload_with_correction(se.payload, lc.correction_payload) ?| ARRAY[
+            'name',
+            'parent_org_node_key',
+            'status',
+            'is_business_unit',
+            'manager_uuid',
+            'manager_pernr',
+            'ext',
+            'new_name',
+            'new_parent_org_node_key'
+          ]
+        )
+        THEN 'UPDATE'
+      ELSE se.event_type
+    END AS event_type,
+    COALESCE(lec.sticky_effective_date, se.effective_date) AS effective_date,
+    CASE
+      WHEN lc.correction_type = 'CORRECT_EVENT'
+        THEN orgunit.merge_org_event_payload_with_correction(se.payload, lc.correction_payload)
+      ELSE se.payload
+    END AS payload,
+    se.request_id,
+    se.initiator_uuid,
+    se.transaction_time,
+    se.created_at
+  FROM source_events se
+  LEFT JOIN latest_corrections lc
+    ON lc.tenant_uuid = se.tenant_uuid
+   AND lc.target_event_uuid = se.event_uuid
+  LEFT JOIN latest_effective_date_corrections lec
+    ON lec.tenant_uuid = se.tenant_uuid
+   AND lec.target_event_uuid = se.event_uuid
+  WHERE se.event_type IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT')
+    AND COALESCE(lc.correction_type, '') NOT IN ('RESCIND_EVENT', 'RESCIND_ORG')
+  ORDER BY effective_date, id;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.apply_replayed_org_event(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_event_id bigint,
+  p_event_type text,
+  p_effective_date date,
+  p_payload jsonb
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_payload jsonb;
+  v_parent_org_node_key char(8);
+  v_new_parent_org_node_key char(8);
+  v_name text;
+  v_new_name text;
+  v_manager_uuid uuid;
+  v_is_business_unit boolean;
+  v_org_code text;
+  v_root_path ltree;
+  v_status text;
+BEGIN
+  v_payload := COALESCE(p_payload, '{}'::jsonb);
+
+  IF p_event_type = 'CREATE' THEN
+    v_parent_org_node_key := NULLIF(v_payload->>'parent_org_node_key', '')::char(8);
+    v_name := NULLIF(btrim(v_payload->>'name'), '');
+    v_manager_uuid := NULLIF(v_payload->>'manager_uuid', '')::uuid;
+    v_org_code := NULLIF(v_payload->>'org_code', '');
+    v_status := NULLIF(btrim(v_payload->>'status'), '');
+    v_is_business_unit := NULL;
+    IF v_payload ? 'is_business_unit' THEN
+      BEGIN
+        v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
+      EXCEPTION
+        WHEN invalid_text_representation THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_INVALID_ARGUMENT',
+            DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
+      END;
+    END IF;
+    PERFORM orgunit.apply_create_logic(p_tenant_uuid, p_org_node_key, v_org_code, v_parent_org_node_key, p_effective_date, v_name, v_manager_uuid, v_is_business_unit, p_event_id, v_status);
+    SELECT v.node_path INTO v_root_path
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.org_node_key = p_org_node_key
+      AND v.validity @> p_effective_date
+    ORDER BY lower(v.validity) DESC
+    LIMIT 1;
+    IF v_root_path IS NOT NULL THEN
+      PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
+    END IF;
+  ELSIF p_event_type = 'UPDATE' THEN
+    PERFORM orgunit.apply_update_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_payload, p_event_id);
+    IF (v_payload ? 'parent_org_node_key')
+      OR (v_payload ? 'new_parent_org_node_key')
+      OR (v_payload ? 'name')
+      OR (v_payload ? 'new_name')
+    THEN
+      SELECT v.node_path INTO v_root_path
+      FROM orgunit.org_unit_versions v
+      WHERE v.tenant_uuid = p_tenant_uuid
+        AND v.org_node_key = p_org_node_key
+        AND v.validity @> p_effective_date
+      ORDER BY lower(v.validity) DESC
+      LIMIT 1;
+      IF v_root_path IS NOT NULL THEN
+        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
+      END IF;
+    END IF;
+  ELSIF p_event_type = 'MOVE' THEN
+    v_new_parent_org_node_key := NULLIF(v_payload->>'new_parent_org_node_key', '')::char(8);
+    PERFORM orgunit.apply_move_logic(p_tenant_uuid, p_org_node_key, v_new_parent_org_node_key, p_effective_date, p_event_id);
+    SELECT v.node_path INTO v_root_path
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.org_node_key = p_org_node_key
+      AND v.validity @> p_effective_date
+    ORDER BY lower(v.validity) DESC
+    LIMIT 1;
+    IF v_root_path IS NOT NULL THEN
+      PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
+    END IF;
+  ELSIF p_event_type = 'RENAME' THEN
+    v_new_name := NULLIF(btrim(v_payload->>'new_name'), '');
+    PERFORM orgunit.apply_rename_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_new_name, p_event_id);
+    SELECT v.node_path INTO v_root_path
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.org_node_key = p_org_node_key
+      AND v.validity @> p_effective_date
+    ORDER BY lower(v.validity) DESC
+    LIMIT 1;
+    IF v_root_path IS NOT NULL THEN
+      PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, p_effective_date);
+    END IF;
+  ELSIF p_event_type = 'DISABLE' THEN
+    PERFORM orgunit.apply_disable_logic(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_id);
+  ELSIF p_event_type = 'ENABLE' THEN
+    PERFORM orgunit.apply_enable_logic(p_tenant_uuid, p_org_node_key, p_effective_date, p_event_id);
+  ELSIF p_event_type = 'SET_BUSINESS_UNIT' THEN
+    IF NOT (v_payload ? 'is_business_unit') THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_INVALID_ARGUMENT',
+        DETAIL = 'is_business_unit is required';
+    END IF;
+    BEGIN
+      v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
+    EXCEPTION
+      WHEN invalid_text_representation THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_INVALID_ARGUMENT',
+          DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
+    END;
+    PERFORM orgunit.apply_set_business_unit_logic(p_tenant_uuid, p_org_node_key, p_effective_date, v_is_business_unit, p_event_id);
+  ELSE
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = format('unexpected event_type: %s', p_event_type);
+  END IF;
+
+  PERFORM orgunit.apply_org_event_ext_payload(
+    p_tenant_uuid,
+    p_org_node_key,
+    p_effective_date,
+    p_event_type,
+    v_payload,
+    p_event_id
+  );
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.rebuild_org_unit_versions_for_org_with_pending_event(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_pending_event_id bigint,
+  p_pending_event_uuid uuid,
+  p_pending_event_type text,
+  p_pending_effective_date date,
+  p_pending_payload jsonb,
+  p_pending_request_id text,
+  p_pending_initiator_uuid uuid,
+  p_pending_tx_time timestamptz,
+  p_pending_transaction_time timestamptz,
+  p_pending_created_at timestamptz
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_event record;
+  v_root_path ltree;
+  v_org_node_keys char(8)[];
+  v_root_org_node_key char(8);
+  v_has_create boolean;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+
+  IF p_pending_event_id IS NOT NULL THEN
+    IF p_pending_event_uuid IS NULL
+      OR p_pending_event_type IS NULL
+      OR p_pending_effective_date IS NULL
+      OR p_pending_request_id IS NULL
+      OR p_pending_initiator_uuid IS NULL
+      OR p_pending_tx_time IS NULL
+      OR p_pending_transaction_time IS NULL
+      OR p_pending_created_at IS NULL
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_INVALID_ARGUMENT',
+        DETAIL = 'pending event metadata is incomplete';
+    END IF;
+  END IF;
+
+  SELECT t.root_org_node_key INTO v_root_org_node_key
+  FROM orgunit.org_trees t
+  WHERE t.tenant_uuid = p_tenant_uuid;
+
+  SELECT EXISTS (
+    SELECT 1
+    FROM orgunit.org_events_effective_for_replay(
+      p_tenant_uuid,
+      p_org_node_key,
+      p_pending_event_id,
+      p_pending_event_uuid,
+      p_pending_event_type,
+      p_pending_effective_date,
+      p_pending_payload,
+      p_pending_request_id,
+      p_pending_initiator_uuid,
+      p_pending_tx_time,
+      p_pending_transaction_time,
+      p_pending_created_at
+    ) e
+    WHERE e.event_type = 'CREATE'
+  ) INTO v_has_create;
+
+  IF v_has_create AND v_root_org_node_key = p_org_node_key THEN
+    DELETE FROM orgunit.org_trees
+    WHERE tenant_uuid = p_tenant_uuid;
+  ELSIF v_root_org_node_key = p_org_node_key AND NOT v_has_create THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = format('root org missing create event: org_node_key=%s', p_org_node_key);
+  END IF;
+
+  DELETE FROM orgunit.org_unit_codes
+  WHERE tenant_uuid = p_tenant_uuid
+    AND org_node_key = p_org_node_key;
+
+  DELETE FROM orgunit.org_unit_versions
+  WHERE tenant_uuid = p_tenant_uuid
+    AND org_node_key = p_org_node_key;
+
+  FOR v_event IN
+    SELECT *
+    FROM orgunit.org_events_effective_for_replay(
+      p_tenant_uuid,
+      p_org_node_key,
+      p_pending_event_id,
+      p_pending_event_uuid,
+      p_pending_event_type,
+      p_pending_effective_date,
+      p_pending_payload,
+      p_pending_request_id,
+      p_pending_initiator_uuid,
+      p_pending_tx_time,
+      p_pending_transaction_time,
+      p_pending_created_at
+    )
+    ORDER BY effective_date, id
+  LOOP
+    PERFORM orgunit.apply_replayed_org_event(
+      p_tenant_uuid,
+      v_event.org_node_key,
+      v_event.id,
+      v_event.event_type,
+      v_event.effective_date,
+      v_event.payload
+    );
+  END LOOP;
+
+  SELECT v.node_path INTO v_root_path
+  FROM orgunit.org_unit_versions v
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.org_node_key = p_org_node_key
+  ORDER BY lower(v.validity) DESC
+  LIMIT 1;
+
+  IF v_root_path IS NULL THEN
+    RETURN;
+  END IF;
+
+  SELECT array_agg(DISTINCT v.org_node_key) INTO v_org_node_keys
+  FROM orgunit.org_unit_versions v
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.node_path <@ v_root_path;
+
+  PERFORM orgunit.assert_org_unit_validity(p_tenant_uuid, v_org_node_keys);
+END;
+$$;
+
+-- Projects the tenant's org history into org_unit_versions and org_unit_codes as it was known at p_known_at:
+-- every effective event recorded by then is replayed in effective-date order through the same kernel steps
+-- a write uses. It rewrites the live projection, so callers run it in a transaction they roll back after
+-- reading. It holds the tenant write lock until then.
+CREATE OR REPLACE FUNCTION orgunit.replay_org_unit_versions_known_at(
+  p_tenant_uuid uuid,
+  p_known_at timestamptz
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_event record;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_known_at IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'known_at is required';
+  END IF;
+
+  PERFORM pg_advisory_xact_lock(hashtextextended(format('org:write-lock:%s', p_tenant_uuid), 0));
+
+  DELETE FROM orgunit.org_trees
+  WHERE tenant_uuid = p_tenant_uuid;
+
+  DELETE FROM orgunit.org_unit_codes
+  WHERE tenant_uuid = p_tenant_uuid;
+
+  DELETE FROM orgunit.org_unit_versions
+  WHERE tenant_uuid = p_tenant_uuid;
+
+  FOR v_event IN
+    SELECT *
+    FROM orgunit.org_events_effective_for_replay(p_tenant_uuid, NULL, p_known_at => p_known_at)
+    ORDER BY effective_date, id
+  LOOP
+    PERFORM orgunit.apply_replayed_org_event(
+      p_tenant_uuid,
+      v_event.org_node_key,
+      v_event.id,
+      v_event.event_type,
+      v_event.effective_date,
+      v_event.payload
+    );
+  END LOOP;
+END;
+$$;
+
+ALTER FUNCTION orgunit.replay_org_unit_versions_known_at(uuid, timestamptz)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.replay_org_unit_versions_known_at(uuid, timestamptz)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.replay_org_unit_versions_known_at(uuid, timestamptz)
+  SET search_path = pg_catalog, orgunit, public;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS orgunit.replay_org_unit_versions_known_at(uuid, timestamptz);
+DROP FUNCTION IF EXISTS orgunit.org_events_effective_for_replay(uuid, char(8), bigint, uuid, text, date, jsonb, text, uuid, timestamptz, timestamptz, timestamptz, timestamptz);
+
+CREATE OR REPLACE FUNCTION orgunit.org_events_effective_for_replay(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_pending_event_id bigint,
+  p_pending_event_uuid uuid,
+  p_pending_event_type text,
+  p_pending_effective_date date,
+  p_pending_payload jsonb,
+  p_pending_request_id text,
+  p_pending_initiator_uuid uuid,
+  p_pending_tx_time timestamptz,
+  p_pending_transaction_time timestamptz,
+  p_pending_created_at timestamptz
+)
+RETURNS TABLE (
+  id bigint,
+  event_uuid uuid,
+  tenant_uuid uuid,
+  org_node_key char(8),
+  event_type text,
+  effective_date date,
+  payload jsonb,
+  request_id text,
+  initiator_uuid uuid,
+  transaction_time timestamptz,
+  created_at timestamptz
+)
+LANGUAGE sql
+STABLE
+AS $$
+  WITH source_events AS (
+    SELECT
+      e.id,
+      e.event_uuid,
+      e.tenant_uuid,
+      e.org_node_key,
+      e.event_type,
+      e.effective_date,
+      COALESCE(e.payload, '{}'::jsonb) AS payload,
+      e.request_id,
+      e.initiator_uuid,
+      e.tx_time,
+      e.transaction_time,
+      e.created_at
+    FROM orgunit.org_events e
+    WHERE e.tenant_uuid = p_tenant_uuid
+      AND e.org_node_key = p_org_node_key
+
+    UNION ALL
+
+    SELECT
+      p_pending_event_id,
+      p_pending_event_uuid,
+      p_tenant_uuid,
+      p_org_node_key,
+      p_pending_event_type,
+      p_pending_effective_date,
+      COALESCE(p_pending_payload, '{}'::jsonb),
+      p_pending_request_id,
+      p_pending_initiator_uuid,
+      p_pending_tx_time,
+      p_pending_transaction_time,
+      p_pending_created_at
+    WHERE p_pending_event_id IS NOT NULL
+  ),
+  correction_events AS (
+    SELECT
+      se.*,
+      (se.payload->>'target_event_uuid')::uuid AS target_event_uuid
+    FROM source_events se
+    WHERE se.event_type IN ('CORRECT_EVENT','CORRECT_STATUS','RESCIND_EVENT','RESCIND_ORG')
+      AND se.payload ? 'target_event_uuid'
+  ),
+  latest_corrections AS (
+    SELECT DISTINCT ON (tenant_uuid, target_event_uuid)
+      tenant_uuid,
+      target_event_uuid,
+      event_type AS correction_type,
+      payload AS correction_payload,
+      tx_time,
+      id
+    FROM correction_events
+    ORDER BY tenant_uuid, target_event_uuid, tx_time DESC, id DESC
+  ),
+  latest_effective_date_corrections AS (
+    -- Sticky effective_date: take the latest CORRECT_EVENT that explicitly carries effective_date,
+    -- regardless of later corrections that don't include effective_date.
+    SELECT DISTINCT ON (tenant_uuid, target_event_uuid)
+      tenant_uuid,
+      target_event_uuid,
+      NULLIF(btrim(payload->>'effective_date'), '')::date AS sticky_effective_date,
+      tx_time,
+      id
+    FROM correction_events
+    WHERE event_type = 'CORRECT_EVENT'
+      AND payload ? 'effective_date'
+    ORDER BY tenant_uuid, target_event_uuid, tx_time DESC, id DESC
+  )
+  SELECT
+    se.id,
+    se.event_uuid,
+    se.tenant_uuid,
+    se.org_node_key,
+    CASE
+      WHEN lc.correction_type = 'CORRECT_STATUS'
+        AND COALESCE(lc.correction_payload->>'target_status', '') = 'active'
+        THEN 'ENABLE'
+      WHEN lc.correction_type = 'CORRECT_STATUS'
+        AND COALESCE(lc.correction_payload->>'target_status', '') = 'disabled'
+        THEN 'DISABLE'
+      WHEN lc.correction_type = 'CORRECT_EVENT'
+        AND se.event_type <> 'CREATE'
+        AND (
+          orgunit.merge_org_event_payload_with_correction(se.payload, lc.correction_payload) ?| ARRAY[
+            'name',
+            'parent_org_node_key',
+            'status',
+            'is_business_unit',
+            'manager_uuid',
+            'manager_pernr',
+            'ext',
+            'new_name',
+            'new_parent_org_node_key'
+          ]
+        )
+        THEN 'UPDATE'
+      ELSE se.event_type
+    END AS event_type,
+    COALESCE(lec.sticky_effective_date, se.effective_date) AS effective_date,
+    CASE
+      WHEN lc.correction_type = 'CORRECT_EVENT'
+        THEN orgunit.merge_org_event_payload_with_correction(se.payload, lc.correction_payload)
+      ELSE se.payload
+    END AS payload,
+    se.request_id,
+    se.initiator_uuid,
+    se.transaction_time,
+    se.created_at
+  FROM source_events se
+  LEFT JOIN latest_corrections lc
+    ON lc.tenant_uuid = se.tenant_uuid
+   AND lc.target_event_uuid = se.event_uuid
+  LEFT JOIN latest_effective_date_corrections lec
+    ON lec.tenant_uuid = se.tenant_uuid
+   AND lec.target_event_uuid = se.event_uuid
+  WHERE se.event_type IN ('CREATE','UPDATE','MOVE','RENAME','DISABLE','ENABLE','SET_BUSINESS_UNIT')
+    AND COALESCE(lc.correction_type, '') NOT IN ('RESCIND_EVENT', 'RESCIND_ORG')
+  ORDER BY effective_date, id;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.rebuild_org_unit_versions_for_org_with_pending_event(
+  p_tenant_uuid uuid,
+  p_org_node_key char(8),
+  p_pending_event_id bigint,
+  p_pending_event_uuid uuid,
+  p_pending_event_type text,
+  p_pending_effective_date date,
+  p_pending_payload jsonb,
+  p_pending_request_id text,
+  p_pending_initiator_uuid uuid,
+  p_pending_tx_time timestamptz,
+  p_pending_transaction_time timestamptz,
+  p_pending_created_at timestamptz
+)
+RETURNS void
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_event record;
+  v_payload jsonb;
+  v_parent_org_node_key char(8);
+  v_new_parent_org_node_key char(8);
+  v_name text;
+  v_new_name text;
+  v_manager_uuid uuid;
+  v_is_business_unit boolean;
+  v_org_code text;
+  v_root_path ltree;
+  v_org_node_keys char(8)[];
+  v_root_org_node_key char(8);
+  v_has_create boolean;
+  v_status text;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_org_node_key IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'org_node_key is required';
+  END IF;
+
+  IF p_pending_event_id IS NOT NULL THEN
+    IF p_pending_event_uuid IS NULL
+      OR p_pending_event_type IS NULL
+      OR p_pending_effective_date IS NULL
+      OR p_pending_request_id IS NULL
+      OR p_pending_initiator_uuid IS NULL
+      OR p_pending_tx_time IS NULL
+      OR p_pending_transaction_time IS NULL
+      OR p_pending_created_at IS NULL
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_INVALID_ARGUMENT',
+        DETAIL = 'pending event metadata is incomplete';
+    END IF;
+  END IF;
+
+  SELECT t.root_org_node_key INTO v_root_org_node_key
+  FROM orgunit.org_trees t
+  WHERE t.tenant_uuid = p_tenant_uuid;
+
+  SELECT EXISTS (
+    SELECT 1
+    FROM orgunit.org_events_effective_for_replay(
+      p_tenant_uuid,
+      p_org_node_key,
+      p_pending_event_id,
+      p_pending_event_uuid,
+      p_pending_event_type,
+      p_pending_effective_date,
+      p_pending_payload,
+      p_pending_request_id,
+      p_pending_initiator_uuid,
+      p_pending_tx_time,
+      p_pending_transaction_time,
+      p_pending_created_at
+    ) e
+    WHERE e.event_type = 'CREATE'
+  ) INTO v_has_create;
+
+  IF v_has_create AND v_root_org_node_key = p_org_node_key THEN
+    DELETE FROM orgunit.org_trees
+    WHERE tenant_uuid = p_tenant_uuid;
+  ELSIF v_root_org_node_key = p_org_node_key AND NOT v_has_create THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = format('root org missing create event: org_node_key=%s', p_org_node_key);
+  END IF;
+
+  DELETE FROM orgunit.org_unit_codes
+  WHERE tenant_uuid = p_tenant_uuid
+    AND org_node_key = p_org_node_key;
+
+  DELETE FROM orgunit.org_unit_versions
+  WHERE tenant_uuid = p_tenant_uuid
+    AND org_node_key = p_org_node_key;
+
+  FOR v_event IN
+    SELECT *
+    FROM orgunit.org_events_effective_for_replay(
+      p_tenant_uuid,
+      p_org_node_key,
+      p_pending_event_id,
+      p_pending_event_uuid,
+      p_pending_event_type,
+      p_pending_effective_date,
+      p_pending_payload,
+      p_pending_request_id,
+      p_pending_initiator_uuid,
+      p_pending_tx_time,
+      p_pending_transaction_time,
+      p_pending_created_at
+    )
+    ORDER BY effective_date, id
+  LOOP
+    v_payload := COALESCE(v_event.payload, '{}'::jsonb);
+
+    IF v_event.event_type = 'CREATE' THEN
+      v_parent_org_node_key := NULLIF(v_payload->>'parent_org_node_key', '')::char(8);
+      v_name := NULLIF(btrim(v_payload->>'name'), '');
+      v_manager_uuid := NULLIF(v_payload->>'manager_uuid', '')::uuid;
+      v_org_code := NULLIF(v_payload->>'org_code', '');
+      v_status := NULLIF(btrim(v_payload->>'status'), '');
+      v_is_business_unit := NULL;
+      IF v_payload ? 'is_business_unit' THEN
+        BEGIN
+          v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
+        EXCEPTION
+          WHEN invalid_text_representation THEN
+            RAISE EXCEPTION USING
+              MESSAGE = 'ORG_INVALID_ARGUMENT',
+              DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
+        END;
+      END IF;
+      PERFORM orgunit.apply_create_logic(p_tenant_uuid, v_event.org_node_key, v_org_code, v_parent_org_node_key, v_event.effective_date, v_name, v_manager_uuid, v_is_business_unit, v_event.id, v_status);
+      SELECT v.node_path INTO v_root_path
+      FROM orgunit.org_unit_versions v
+      WHERE v.tenant_uuid = p_tenant_uuid
+        AND v.org_node_key = p_org_node_key
+        AND v.validity @> v_event.effective_date
+      ORDER BY lower(v.validity) DESC
+      LIMIT 1;
+      IF v_root_path IS NOT NULL THEN
+        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, v_event.effective_date);
+      END IF;
+    ELSIF v_event.event_type = 'UPDATE' THEN
+      PERFORM orgunit.apply_update_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_payload, v_event.id);
+      IF (v_payload ? 'parent_org_node_key')
+        OR (v_payload ? 'new_parent_org_node_key')
+        OR (v_payload ? 'name')
+        OR (v_payload ? 'new_name')
+      THEN
+        SELECT v.node_path INTO v_root_path
+        FROM orgunit.org_unit_versions v
+        WHERE v.tenant_uuid = p_tenant_uuid
+          AND v.org_node_key = p_org_node_key
+          AND v.validity @> v_event.effective_date
+        ORDER BY lower(v.validity) DESC
+        LIMIT 1;
+        IF v_root_path IS NOT NULL THEN
+          PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, v_event.effective_date);
+        END IF;
+      END IF;
+    ELSIF v_event.event_type = 'MOVE' THEN
+      v_new_parent_org_node_key := NULLIF(v_payload->>'new_parent_org_node_key', '')::char(8);
+      PERFORM orgunit.apply_move_logic(p_tenant_uuid, v_event.org_node_key, v_new_parent_org_node_key, v_event.effective_date, v_event.id);
+      SELECT v.node_path INTO v_root_path
+      FROM orgunit.org_unit_versions v
+      WHERE v.tenant_uuid = p_tenant_uuid
+        AND v.org_node_key = p_org_node_key
+        AND v.validity @> v_event.effective_date
+      ORDER BY lower(v.validity) DESC
+      LIMIT 1;
+      IF v_root_path IS NOT NULL THEN
+        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, v_event.effective_date);
+      END IF;
+    ELSIF v_event.event_type = 'RENAME' THEN
+      v_new_name := NULLIF(btrim(v_payload->>'new_name'), '');
+      PERFORM orgunit.apply_rename_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_new_name, v_event.id);
+      SELECT v.node_path INTO v_root_path
+      FROM orgunit.org_unit_versions v
+      WHERE v.tenant_uuid = p_tenant_uuid
+        AND v.org_node_key = p_org_node_key
+        AND v.validity @> v_event.effective_date
+      ORDER BY lower(v.validity) DESC
+      LIMIT 1;
+      IF v_root_path IS NOT NULL THEN
+        PERFORM orgunit.rebuild_full_name_path_subtree(p_tenant_uuid, v_root_path, v_event.effective_date);
+      END IF;
+    ELSIF v_event.event_type = 'DISABLE' THEN
+      PERFORM orgunit.apply_disable_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_event.id);
+    ELSIF v_event.event_type = 'ENABLE' THEN
+      PERFORM orgunit.apply_enable_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_event.id);
+    ELSIF v_event.event_type = 'SET_BUSINESS_UNIT' THEN
+      IF NOT (v_payload ? 'is_business_unit') THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_INVALID_ARGUMENT',
+          DETAIL = 'is_business_unit is required';
+      END IF;
+      BEGIN
+        v_is_business_unit := (v_payload->>'is_business_unit')::boolean;
+      EXCEPTION
+        WHEN invalid_text_representation THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_INVALID_ARGUMENT',
+            DETAIL = format('is_business_unit=%s', v_payload->>'is_business_unit');
+      END;
+      PERFORM orgunit.apply_set_business_unit_logic(p_tenant_uuid, v_event.org_node_key, v_event.effective_date, v_is_business_unit, v_event.id);
+    ELSE
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_INVALID_ARGUMENT',
+        DETAIL = format('unexpected event_type: %s', v_event.event_type);
+    END IF;
+
+    PERFORM orgunit.apply_org_event_ext_payload(
+      p_tenant_uuid,
+      v_event.org_node_key,
+      v_event.effective_date,
+      v_event.event_type,
+      v_payload,
+      v_event.id
+    );
+  END LOOP;
+
+  SELECT v.node_path INTO v_root_path
+  FROM orgunit.org_unit_versions v
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.org_node_key = p_org_node_key
+  ORDER BY lower(v.validity) DESC
+  LIMIT 1;
+
+  IF v_root_path IS NULL THEN
+    RETURN;
+  END IF;
+
+  SELECT array_agg(DISTINCT v.org_node_key) INTO v_org_node_keys
+  FROM orgunit.org_unit_versions v
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.node_path <@ v_root_path;
+
+  PERFORM orgunit.assert_org_unit_validity(p_tenant_uuid, v_org_node_keys);
+END;
+$$;
+
+DROP FUNCTION IF EXISTS orgunit.apply_replayed_org_event(uuid, char(8), bigint, text, date, jsonb);
+-- +goose StatementEnd
@@ -0,0 +1,556 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Tenant field validation rules: CEL predicates over the candidate org unit record, evaluated by the write
+-- service and the create/append/correct prechecks. Rules are effective-dated per (field, scope, rule_key)
+-- the same way tenant_field_policies are, and only orgunit_kernel may write them.
+CREATE TABLE IF NOT EXISTS orgunit.tenant_field_validation_rules (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL,
+  field_key text NOT NULL,
+  scope_type text NOT NULL,
+  scope_key text NOT NULL,
+  rule_key text NOT NULL,
+  rule_expr text NOT NULL,
+  message text NULL,
+  enabled_on date NOT NULL,
+  disabled_on date NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  disabled_at timestamptz NULL,
+  CONSTRAINT tenant_field_validation_rules_field_key_format_check CHECK (field_key ~ '^[a-z][a-z0-9_]{0,62}$'),
+  CONSTRAINT tenant_field_validation_rules_rule_key_format_check CHECK (rule_key ~ '^[a-z][a-z0-9_]{0,62}$'),
+  CONSTRAINT tenant_field_validation_rules_scope_type_check CHECK (scope_type IN ('GLOBAL','FORM')),
+  CONSTRAINT tenant_field_validation_rules_scope_key_check CHECK (
+    (scope_type = 'GLOBAL' AND scope_key = 'global')
+    OR
+    (scope_type = 'FORM' AND scope_key IN (
+      'orgunit.create_dialog',
+      'orgunit.details.add_version_dialog',
+      'orgunit.details.insert_version_dialog',
+      'orgunit.details.correct_dialog'
+    ))
+  ),
+  CONSTRAINT tenant_field_validation_rules_rule_expr_check CHECK (btrim(rule_expr) <> ''),
+  CONSTRAINT tenant_field_validation_rules_message_check CHECK (
+    message IS NULL OR (message = btrim(message) AND message <> '')
+  ),
+  CONSTRAINT tenant_field_validation_rules_disabled_on_check CHECK (disabled_on IS NULL OR disabled_on > enabled_on)
+);
+
+CREATE INDEX IF NOT EXISTS tenant_field_validation_rules_tenant_scope_idx
+  ON orgunit.tenant_field_validation_rules (tenant_uuid, field_key, scope_type, scope_key, rule_key, enabled_on DESC);
+
+CREATE TABLE IF NOT EXISTS orgunit.tenant_field_validation_rule_events (
+  id bigserial PRIMARY KEY,
+  event_uuid uuid NOT NULL,
+  tenant_uuid uuid NOT NULL,
+  event_type text NOT NULL,
+  field_key text NOT NULL,
+  scope_type text NOT NULL,
+  scope_key text NOT NULL,
+  rule_key text NOT NULL,
+  payload jsonb NOT NULL DEFAULT '{}'::jsonb,
+  request_id text NOT NULL,
+  initiator_uuid uuid NOT NULL,
+  transaction_time timestamptz NOT NULL DEFAULT now(),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT tenant_field_validation_rule_events_event_type_check CHECK (event_type IN ('UPSERT','DISABLE')),
+  CONSTRAINT tenant_field_validation_rule_events_request_id_unique UNIQUE (tenant_uuid, request_id),
+  CONSTRAINT tenant_field_validation_rule_events_event_uuid_unique UNIQUE (event_uuid),
+  CONSTRAINT tenant_field_validation_rule_events_payload_is_object_check CHECK (jsonb_typeof(payload) = 'object')
+);
+
+CREATE INDEX IF NOT EXISTS tenant_field_validation_rule_events_tenant_time_idx
+  ON orgunit.tenant_field_validation_rule_events (tenant_uuid, transaction_time DESC, id DESC);
+
+ALTER TABLE orgunit.tenant_field_validation_rules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.tenant_field_validation_rules FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.tenant_field_validation_rules;
+CREATE POLICY tenant_isolation ON orgunit.tenant_field_validation_rules
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
+
+ALTER TABLE orgunit.tenant_field_validation_rule_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.tenant_field_validation_rule_events FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.tenant_field_validation_rule_events;
+CREATE POLICY tenant_isolation ON orgunit.tenant_field_validation_rule_events
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
+
+DROP TRIGGER IF EXISTS guard_tenant_field_validation_rules_write ON orgunit.tenant_field_validation_rules;
+CREATE TRIGGER guard_tenant_field_validation_rules_write
+BEFORE INSERT OR UPDATE OR DELETE ON orgunit.tenant_field_validation_rules
+FOR EACH ROW EXECUTE FUNCTION orgunit.guard_tenant_field_policies_write();
+
+DROP TRIGGER IF EXISTS guard_tenant_field_validation_rule_events_write ON orgunit.tenant_field_validation_rule_events;
+CREATE TRIGGER guard_tenant_field_validation_rule_events_write
+BEFORE INSERT OR UPDATE OR DELETE ON orgunit.tenant_field_validation_rule_events
+FOR EACH ROW EXECUTE FUNCTION orgunit.guard_tenant_field_policies_write();
+
+CREATE OR REPLACE FUNCTION orgunit.assert_tenant_field_validation_rules_non_overlapping()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_overlap_id bigint;
+BEGIN
+  SELECT r.id
+  INTO v_overlap_id
+  FROM orgunit.tenant_field_validation_rules r
+  WHERE r.tenant_uuid = NEW.tenant_uuid
+    AND r.field_key = NEW.field_key
+    AND r.scope_type = NEW.scope_type
+    AND r.scope_key = NEW.scope_key
+    AND r.rule_key = NEW.rule_key
+    AND r.id <> NEW.id
+    AND daterange(NEW.enabled_on, COALESCE(NEW.disabled_on, 'infinity'::date), '[)')
+      && daterange(r.enabled_on, COALESCE(r.disabled_on, 'infinity'::date), '[)')
+  LIMIT 1;
+
+  IF v_overlap_id IS NOT NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'FIELD_POLICY_SCOPE_OVERLAP',
+      DETAIL = format(
+        'field_key=%s scope_type=%s scope_key=%s rule_key=%s overlap_id=%s',
+        NEW.field_key, NEW.scope_type, NEW.scope_key, NEW.rule_key, v_overlap_id
+      );
+  END IF;
+
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS tenant_field_validation_rules_non_overlapping ON orgunit.tenant_field_validation_rules;
+CREATE TRIGGER tenant_field_validation_rules_non_overlapping
+BEFORE INSERT OR UPDATE ON orgunit.tenant_field_validation_rules
+FOR EACH ROW EXECUTE FUNCTION orgunit.assert_tenant_field_validation_rules_non_overlapping();
+
+CREATE OR REPLACE FUNCTION orgunit.upsert_tenant_field_validation_rule(
+  p_tenant_uuid uuid,
+  p_field_key text,
+  p_scope_type text,
+  p_scope_key text,
+  p_rule_key text,
+  p_rule_expr text,
+  p_message text,
+  p_enabled_on date,
+  p_request_id text,
+  p_initiator_uuid uuid
+)
+RETURNS bigint
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_scope_type text := upper(btrim(p_scope_type));
+  v_scope_key text := btrim(p_scope_key);
+  v_rule_expr text := NULLIF(btrim(p_rule_expr), '');
+  v_message text := NULLIF(btrim(p_message), '');
+  v_event_type text;
+  v_rule_id bigint;
+  v_open_id bigint;
+  v_open_enabled_on date;
+  v_next_enabled_on date;
+BEGIN
+  IF v_scope_type NOT IN ('GLOBAL','FORM') THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_type invalid';
+  END IF;
+  IF v_scope_type = 'GLOBAL' THEN
+    v_scope_key := 'global';
+  END IF;
+  IF v_scope_key = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_key required';
+  END IF;
+  IF v_rule_expr IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'FIELD_POLICY_EXPR_INVALID', DETAIL = 'rule_expr required';
+  END IF;
+
+  PERFORM pg_advisory_xact_lock(hashtextextended(
+    format('orgunit.field_validation_rule:%s:%s:%s:%s:%s', p_tenant_uuid, p_field_key, v_scope_type, v_scope_key, p_rule_key),
+    0
+  ));
+
+  SELECT event_type, (payload->>'rule_id')::bigint
+  INTO v_event_type, v_rule_id
+  FROM orgunit.tenant_field_validation_rule_events
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id
+  LIMIT 1;
+
+  IF FOUND THEN
+    IF v_event_type <> 'UPSERT' THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_REQUEST_ID_CONFLICT', DETAIL = format('request_id=%s', p_request_id);
+    END IF;
+    RETURN v_rule_id;
+  END IF;
+
+  SELECT id, enabled_on
+  INTO v_open_id, v_open_enabled_on
+  FROM orgunit.tenant_field_validation_rules
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_field_key
+    AND scope_type = v_scope_type
+    AND scope_key = v_scope_key
+    AND rule_key = p_rule_key
+    AND disabled_on IS NULL
+  ORDER BY enabled_on DESC
+  LIMIT 1
+  FOR UPDATE;
+
+  IF v_open_id IS NOT NULL AND v_open_enabled_on < p_enabled_on THEN
+    UPDATE orgunit.tenant_field_validation_rules
+    SET disabled_on = p_enabled_on, disabled_at = now(), updated_at = now()
+    WHERE id = v_open_id;
+  END IF;
+
+  UPDATE orgunit.tenant_field_validation_rules
+  SET rule_expr = v_rule_expr,
+      message = v_message,
+      updated_at = now()
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_field_key
+    AND scope_type = v_scope_type
+    AND scope_key = v_scope_key
+    AND rule_key = p_rule_key
+    AND enabled_on = p_enabled_on
+  RETURNING id INTO v_rule_id;
+
+  IF v_rule_id IS NULL THEN
+    SELECT MIN(enabled_on)
+    INTO v_next_enabled_on
+    FROM orgunit.tenant_field_validation_rules
+    WHERE tenant_uuid = p_tenant_uuid
+      AND field_key = p_field_key
+      AND scope_type = v_scope_type
+      AND scope_key = v_scope_key
+      AND rule_key = p_rule_key
+      AND enabled_on > p_enabled_on;
+
+    INSERT INTO orgunit.tenant_field_validation_rules (
+      tenant_uuid,
+      field_key,
+      scope_type,
+      scope_key,
+      rule_key,
+      rule_expr,
+      message,
+      enabled_on,
+      disabled_on
+    ) VALUES (
+      p_tenant_uuid,
+      p_field_key,
+      v_scope_type,
+      v_scope_key,
+      p_rule_key,
+      v_rule_expr,
+      v_message,
+      p_enabled_on,
+      v_next_enabled_on
+    )
+    RETURNING id INTO v_rule_id;
+  END IF;
+
+  INSERT INTO orgunit.tenant_field_validation_rule_events (
+    event_uuid,
+    tenant_uuid,
+    event_type,
+    field_key,
+    scope_type,
+    scope_key,
+    rule_key,
+    payload,
+    request_id,
+    initiator_uuid
+  ) VALUES (
+    gen_random_uuid(),
+    p_tenant_uuid,
+    'UPSERT',
+    p_field_key,
+    v_scope_type,
+    v_scope_key,
+    p_rule_key,
+    jsonb_build_object('rule_id', v_rule_id, 'rule_expr', v_rule_expr),
+    p_request_id,
+    p_initiator_uuid
+  );
+
+  RETURN v_rule_id;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.disable_tenant_field_validation_rule(
+  p_tenant_uuid uuid,
+  p_field_key text,
+  p_scope_type text,
+  p_scope_key text,
+  p_rule_key text,
+  p_disabled_on date,
+  p_request_id text,
+  p_initiator_uuid uuid
+)
+RETURNS bigint
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_scope_type text := upper(btrim(p_scope_type));
+  v_scope_key text := btrim(p_scope_key);
+  v_event_type text;
+  v_rule_id bigint;
+  v_enabled_on date;
+BEGIN
+  IF v_scope_type NOT IN ('GLOBAL','FORM') THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_type invalid';
+  END IF;
+  IF v_scope_type = 'GLOBAL' THEN
+    v_scope_key := 'global';
+  END IF;
+  IF v_scope_key = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_key required';
+  END IF;
+
+  PERFORM pg_advisory_xact_lock(hashtextextended(
+    format('orgunit.field_validation_rule:%s:%s:%s:%s:%s', p_tenant_uuid, p_field_key, v_scope_type, v_scope_key, p_rule_key),
+    0
+  ));
+
+  SELECT event_type, (payload->>'rule_id')::bigint
+  INTO v_event_type, v_rule_id
+  FROM orgunit.tenant_field_validation_rule_events
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id
+  LIMIT 1;
+
+  IF FOUND THEN
+    IF v_event_type <> 'DISABLE' THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_REQUEST_ID_CONFLICT', DETAIL = format('request_id=%s', p_request_id);
+    END IF;
+    RETURN v_rule_id;
+  END IF;
+
+  SELECT id, enabled_on
+  INTO v_rule_id, v_enabled_on
+  FROM orgunit.tenant_field_validation_rules
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_field_key
+    AND scope_type = v_scope_type
+    AND scope_key = v_scope_key
+    AND rule_key = p_rule_key
+    AND enabled_on < p_disabled_on
+    AND p_disabled_on < COALESCE(disabled_on, 'infinity'::date)
+  ORDER BY enabled_on DESC
+  LIMIT 1
+  FOR UPDATE;
+
+  IF v_rule_id IS NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_FIELD_VALIDATION_RULE_NOT_FOUND',
+      DETAIL = format('field_key=%s rule_key=%s', p_field_key, p_rule_key);
+  END IF;
+
+  UPDATE orgunit.tenant_field_validation_rules
+  SET disabled_on = p_disabled_on, disabled_at = now(), updated_at = now()
+  WHERE id = v_rule_id;
+
+  INSERT INTO orgunit.tenant_field_validation_rule_events (
+    event_uuid,
+    tenant_uuid,
+    event_type,
+    field_key,
+    scope_type,
+    scope_key,
+    rule_key,
+    payload,
+    request_id,
+    initiator_uuid
+  ) VALUES (
+    gen_random_uuid(),
+    p_tenant_uuid,
+    'DISABLE',
+    p_field_key,
+    v_scope_type,
+    v_scope_key,
+    p_rule_key,
+    jsonb_build_object('rule_id', v_rule_id, 'disabled_on', p_disabled_on),
+    p_request_id,
+    p_initiator_uuid
+  );
+
+  RETURN v_rule_id;
+END;
+$$;
+
+ALTER TABLE IF EXISTS orgunit.tenant_field_validation_rules OWNER TO orgunit_kernel;
+ALTER TABLE IF EXISTS orgunit.tenant_field_validation_rule_events OWNER TO orgunit_kernel;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE
+  orgunit.tenant_field_validation_rules,
+  orgunit.tenant_field_validation_rule_events
+TO orgunit_kernel;
+
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA orgunit TO orgunit_kernel;
+
+ALTER FUNCTION orgunit.assert_tenant_field_validation_rules_non_overlapping()
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.assert_tenant_field_validation_rules_non_overlapping()
+  SET search_path = pg_catalog, orgunit, public;
+
+ALTER FUNCTION orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, text, date, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, text, date, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, text, date, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+ALTER FUNCTION orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, text, date, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, text, date, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, text, date, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.tenant_field_validation_rules, ' ||
+      'orgunit.tenant_field_validation_rule_events ' ||
+      'FROM app';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.tenant_field_validation_rules, ' ||
+      'orgunit.tenant_field_validation_rule_events ' ||
+      'TO app';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_runtime') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.tenant_field_validation_rules, ' ||
+      'orgunit.tenant_field_validation_rule_events ' ||
+      'FROM app_runtime';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.tenant_field_validation_rules, ' ||
+      'orgunit.tenant_field_validation_rule_events ' ||
+      'TO app_runtime';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_nobypassrls') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.tenant_field_validation_rules, ' ||
+      'orgunit.tenant_field_validation_rule_events ' ||
+      'FROM app_nobypassrls';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.tenant_field_validation_rules, ' ||
+      'orgunit.tenant_field_validation_rule_events ' ||
+      'TO app_nobypassrls';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.tenant_field_validation_rules, ' ||
+      'orgunit.tenant_field_validation_rule_events ' ||
+      'FROM superadmin_runtime';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.tenant_field_validation_rules, ' ||
+      'orgunit.tenant_field_validation_rule_events ' ||
+      'TO superadmin_runtime';
+  END IF;
+END $$;
+
+-- Tenant hard delete also clears validation rules.
+CREATE OR REPLACE FUNCTION orgunit.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'orgunit.org_unit_search_index',
+    'orgunit.org_unit_lineage',
+    'orgunit.org_composite_operations',
+    'orgunit.org_unit_versions',
+    'orgunit.org_unit_codes',
+    'orgunit.org_trees',
+    'orgunit.org_events',
+    'orgunit.org_node_key_registry',
+    'orgunit.tenant_field_validation_rules',
+    'orgunit.tenant_field_validation_rule_events',
+    'orgunit.tenant_field_policies',
+    'orgunit.tenant_field_policy_events',
+    'orgunit.tenant_field_configs',
+    'orgunit.tenant_field_config_events'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = orgunit.global_tenant_id() THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORGUNIT_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  RETURN v_counts;
+END;
+$$;
+
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SET search_path = pg_catalog, orgunit, public;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION orgunit.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'orgunit.org_unit_search_index',
+    'orgunit.org_unit_lineage',
+    'orgunit.org_composite_operations',
+    'orgunit.org_unit_versions',
+    'orgunit.org_unit_codes',
+    'orgunit.org_trees',
+    'orgunit.org_events',
+    'orgunit.org_node_key_registry',
+    'orgunit.tenant_field_policies',
+    'orgunit.tenant_field_policy_events',
+    'orgunit.tenant_field_configs',
+    'orgunit.tenant_field_config_events'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = orgunit.global_tenant_id() THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORGUNIT_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  RETURN v_counts;
+END;
+$$;
+
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SET search_path = pg_catalog, orgunit, public;
+DROP FUNCTION IF EXISTS orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, text, date, text, uuid);
+DROP FUNCTION IF EXISTS orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, text, date, text, uuid);
+DROP TABLE IF EXISTS orgunit.tenant_field_validation_rule_events;
+DROP TABLE IF EXISTS orgunit.tenant_field_validation_rules;
+DROP FUNCTION IF EXISTS orgunit.assert_tenant_field_validation_rules_non_overlapping();
+-- +goose StatementEnd
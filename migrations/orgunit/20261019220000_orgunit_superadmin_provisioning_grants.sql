@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Tenant provisioning creates the root org unit through the regular write service on the superadmin pool.
+-- Writes still go through the kernel submit functions; superadmin_runtime only needs to read what the
+-- write service resolves before submitting (codes, versions, field metadata and the effective events).
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT USAGE ON SCHEMA orgunit TO superadmin_runtime';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.org_node_key_registry, ' ||
+      'orgunit.org_trees, ' ||
+      'orgunit.org_events, ' ||
+      'orgunit.org_events_effective, ' ||
+      'orgunit.org_unit_versions, ' ||
+      'orgunit.org_unit_codes, ' ||
+      'orgunit.tenant_field_configs, ' ||
+      'orgunit.tenant_field_policies ' ||
+      'TO superadmin_runtime';
+  END IF;
+END $$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'REVOKE SELECT ON TABLE orgunit.org_events_effective FROM superadmin_runtime';
+  END IF;
+END $$;
+-- +goose StatementEnd
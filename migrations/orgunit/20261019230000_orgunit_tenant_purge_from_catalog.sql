@@ -0,0 +1,117 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Tenant hard delete purges every orgunit table that carries a tenant_uuid column, found in the catalog
+-- the same way offboarding verification finds them, so a new tenant-scoped table is covered without
+-- redefining this function. Tables go before the tables they reference.
+CREATE OR REPLACE FUNCTION orgunit.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_pending oid[];
+  v_table oid;
+  v_name text;
+  v_progress boolean;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = orgunit.global_tenant_id() THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORGUNIT_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  SELECT COALESCE(array_agg(c.oid ORDER BY c.relname), '{}'::oid[]) INTO v_pending
+  FROM pg_class c
+  JOIN pg_attribute a ON a.attrelid = c.oid AND a.attname = 'tenant_uuid' AND NOT a.attisdropped
+  WHERE c.relnamespace = 'orgunit'::regnamespace
+    AND c.relkind IN ('r', 'p')
+    AND NOT c.relispartition;
+
+  WHILE cardinality(v_pending) > 0 LOOP
+    v_progress := false;
+    FOREACH v_table IN ARRAY v_pending LOOP
+      CONTINUE WHEN EXISTS (
+        SELECT 1
+        FROM pg_constraint fk
+        WHERE fk.contype = 'f'
+          AND fk.confrelid = v_table
+          AND fk.conrelid <> v_table
+          AND fk.conrelid = ANY (v_pending)
+      );
+      SELECT format('orgunit.%I', relname) INTO v_name FROM pg_class WHERE oid = v_table;
+      EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_name) USING p_tenant_uuid;
+      GET DIAGNOSTICS v_count = ROW_COUNT;
+      v_counts := v_counts || jsonb_build_object(v_name, v_count);
+      v_pending := array_remove(v_pending, v_table);
+      v_progress := true;
+    END LOOP;
+    IF NOT v_progress THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORGUNIT_TENANT_PURGE_FK_CYCLE',
+        DETAIL = format('tables=%s', v_pending::regclass[]);
+    END IF;
+  END LOOP;
+
+  RETURN v_counts;
+END;
+$$;
+
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SET search_path = pg_catalog, orgunit, public;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION orgunit.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'orgunit.org_unit_search_index',
+    'orgunit.org_unit_lineage',
+    'orgunit.org_composite_operations',
+    'orgunit.org_unit_versions',
+    'orgunit.org_unit_codes',
+    'orgunit.org_trees',
+    'orgunit.org_events',
+    'orgunit.org_node_key_registry',
+    'orgunit.tenant_field_validation_rules',
+    'orgunit.tenant_field_validation_rule_events',
+    'orgunit.tenant_field_policies',
+    'orgunit.tenant_field_policy_events',
+    'orgunit.tenant_field_configs',
+    'orgunit.tenant_field_config_events'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = orgunit.global_tenant_id() THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORGUNIT_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  RETURN v_counts;
+END;
+$$;
+
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SET search_path = pg_catalog, orgunit, public;
+-- +goose StatementEnd
@@ -0,0 +1,666 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Validation rules apply to every write, or to the one dialog named by form_key. form_key reuses the form
+-- keys of tenant field configs; '' means the rule is not tied to a form.
+ALTER TABLE orgunit.tenant_field_validation_rules DISABLE TRIGGER guard_tenant_field_validation_rules_write;
+ALTER TABLE orgunit.tenant_field_validation_rule_events DISABLE TRIGGER guard_tenant_field_validation_rule_events_write;
+
+ALTER TABLE orgunit.tenant_field_validation_rules ADD COLUMN IF NOT EXISTS form_key text NOT NULL DEFAULT '';
+ALTER TABLE orgunit.tenant_field_validation_rule_events ADD COLUMN IF NOT EXISTS form_key text NOT NULL DEFAULT '';
+UPDATE orgunit.tenant_field_validation_rules SET form_key = scope_key WHERE scope_type = 'FORM';
+UPDATE orgunit.tenant_field_validation_rule_events SET form_key = scope_key WHERE scope_type = 'FORM';
+
+ALTER TABLE orgunit.tenant_field_validation_rules ENABLE TRIGGER guard_tenant_field_validation_rules_write;
+ALTER TABLE orgunit.tenant_field_validation_rule_events ENABLE TRIGGER guard_tenant_field_validation_rule_events_write;
+
+DROP INDEX IF EXISTS orgunit.tenant_field_validation_rules_tenant_scope_idx;
+DROP FUNCTION IF EXISTS orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, text, date, text, uuid);
+DROP FUNCTION IF EXISTS orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, text, date, text, uuid);
+
+ALTER TABLE orgunit.tenant_field_validation_rules
+  DROP CONSTRAINT IF EXISTS tenant_field_validation_rules_scope_type_check,
+  DROP CONSTRAINT IF EXISTS tenant_field_validation_rules_scope_key_check,
+  DROP COLUMN IF EXISTS scope_type,
+  DROP COLUMN IF EXISTS scope_key,
+  ADD CONSTRAINT tenant_field_validation_rules_form_key_check CHECK (
+    form_key IN (
+      '',
+      'orgunit.create_dialog',
+      'orgunit.details.add_version_dialog',
+      'orgunit.details.insert_version_dialog',
+      'orgunit.details.correct_dialog'
+    )
+  );
+ALTER TABLE orgunit.tenant_field_validation_rule_events
+  DROP COLUMN IF EXISTS scope_type,
+  DROP COLUMN IF EXISTS scope_key;
+
+CREATE INDEX IF NOT EXISTS tenant_field_validation_rules_tenant_form_idx
+  ON orgunit.tenant_field_validation_rules (tenant_uuid, field_key, form_key, rule_key, enabled_on DESC);
+
+CREATE OR REPLACE FUNCTION orgunit.assert_tenant_field_validation_rules_non_overlapping()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_overlap_id bigint;
+BEGIN
+  SELECT r.id
+  INTO v_overlap_id
+  FROM orgunit.tenant_field_validation_rules r
+  WHERE r.tenant_uuid = NEW.tenant_uuid
+    AND r.field_key = NEW.field_key
+    AND r.form_key = NEW.form_key
+    AND r.rule_key = NEW.rule_key
+    AND r.id <> NEW.id
+    AND daterange(NEW.enabled_on, COALESCE(NEW.disabled_on, 'infinity'::date), '[)')
+      && daterange(r.enabled_on, COALESCE(r.disabled_on, 'infinity'::date), '[)')
+  LIMIT 1;
+
+  IF v_overlap_id IS NOT NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'FIELD_POLICY_SCOPE_OVERLAP',
+      DETAIL = format(
+        'field_key=%s form_key=%s rule_key=%s overlap_id=%s',
+        NEW.field_key, NEW.form_key, NEW.rule_key, v_overlap_id
+      );
+  END IF;
+
+  RETURN NEW;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.upsert_tenant_field_validation_rule(
+  p_tenant_uuid uuid,
+  p_field_key text,
+  p_form_key text,
+  p_rule_key text,
+  p_rule_expr text,
+  p_message text,
+  p_enabled_on date,
+  p_request_id text,
+  p_initiator_uuid uuid
+)
+RETURNS bigint
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_form_key text := btrim(COALESCE(p_form_key, ''));
+  v_rule_expr text := NULLIF(btrim(p_rule_expr), '');
+  v_message text := NULLIF(btrim(p_message), '');
+  v_event_type text;
+  v_rule_id bigint;
+  v_open_id bigint;
+  v_open_enabled_on date;
+  v_next_enabled_on date;
+BEGIN
+  IF v_form_key NOT IN (
+    '',
+    'orgunit.create_dialog',
+    'orgunit.details.add_version_dialog',
+    'orgunit.details.insert_version_dialog',
+    'orgunit.details.correct_dialog'
+  ) THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'form_key invalid';
+  END IF;
+  IF v_rule_expr IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'FIELD_POLICY_EXPR_INVALID', DETAIL = 'rule_expr required';
+  END IF;
+
+  PERFORM pg_advisory_xact_lock(hashtextextended(
+    format('orgunit.field_validation_rule:%s:%s:%s:%s', p_tenant_uuid, p_field_key, v_form_key, p_rule_key),
+    0
+  ));
+
+  SELECT event_type, (payload->>'rule_id')::bigint
+  INTO v_event_type, v_rule_id
+  FROM orgunit.tenant_field_validation_rule_events
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id
+  LIMIT 1;
+
+  IF FOUND THEN
+    IF v_event_type <> 'UPSERT' THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_REQUEST_ID_CONFLICT', DETAIL = format('request_id=%s', p_request_id);
+    END IF;
+    RETURN v_rule_id;
+  END IF;
+
+  SELECT id, enabled_on
+  INTO v_open_id, v_open_enabled_on
+  FROM orgunit.tenant_field_validation_rules
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_field_key
+    AND form_key = v_form_key
+    AND rule_key = p_rule_key
+    AND disabled_on IS NULL
+  ORDER BY enabled_on DESC
+  LIMIT 1
+  FOR UPDATE;
+
+  IF v_open_id IS NOT NULL AND v_open_enabled_on < p_enabled_on THEN
+    UPDATE orgunit.tenant_field_validation_rules
+    SET disabled_on = p_enabled_on, disabled_at = now(), updated_at = now()
+    WHERE id = v_open_id;
+  END IF;
+
+  UPDATE orgunit.tenant_field_validation_rules
+  SET rule_expr = v_rule_expr,
+      message = v_message,
+      updated_at = now()
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_field_key
+    AND form_key = v_form_key
+    AND rule_key = p_rule_key
+    AND enabled_on = p_enabled_on
+  RETURNING id INTO v_rule_id;
+
+  IF v_rule_id IS NULL THEN
+    SELECT MIN(enabled_on)
+    INTO v_next_enabled_on
+    FROM orgunit.tenant_field_validation_rules
+    WHERE tenant_uuid = p_tenant_uuid
+      AND field_key = p_field_key
+      AND form_key = v_form_key
+      AND rule_key = p_rule_key
+      AND enabled_on > p_enabled_on;
+
+    INSERT INTO orgunit.tenant_field_validation_rules (
+      tenant_uuid,
+      field_key,
+      form_key,
+      rule_key,
+      rule_expr,
+      message,
+      enabled_on,
+      disabled_on
+    ) VALUES (
+      p_tenant_uuid,
+      p_field_key,
+      v_form_key,
+      p_rule_key,
+      v_rule_expr,
+      v_message,
+      p_enabled_on,
+      v_next_enabled_on
+    )
+    RETURNING id INTO v_rule_id;
+  END IF;
+
+  INSERT INTO orgunit.tenant_field_validation_rule_events (
+    event_uuid,
+    tenant_uuid,
+    event_type,
+    field_key,
+    form_key,
+    rule_key,
+    payload,
+    request_id,
+    initiator_uuid
+  ) VALUES (
+    gen_random_uuid(),
+    p_tenant_uuid,
+    'UPSERT',
+    p_field_key,
+    v_form_key,
+    p_rule_key,
+    jsonb_build_object('rule_id', v_rule_id, 'rule_expr', v_rule_expr),
+    p_request_id,
+    p_initiator_uuid
+  );
+
+  RETURN v_rule_id;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.disable_tenant_field_validation_rule(
+  p_tenant_uuid uuid,
+  p_field_key text,
+  p_form_key text,
+  p_rule_key text,
+  p_disabled_on date,
+  p_request_id text,
+  p_initiator_uuid uuid
+)
+RETURNS bigint
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_form_key text := btrim(COALESCE(p_form_key, ''));
+  v_event_type text;
+  v_rule_id bigint;
+  v_enabled_on date;
+BEGIN
+  IF v_form_key NOT IN (
+    '',
+    'orgunit.create_dialog',
+    'orgunit.details.add_version_dialog',
+    'orgunit.details.insert_version_dialog',
+    'orgunit.details.correct_dialog'
+  ) THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'form_key invalid';
+  END IF;
+
+  PERFORM pg_advisory_xact_lock(hashtextextended(
+    format('orgunit.field_validation_rule:%s:%s:%s:%s', p_tenant_uuid, p_field_key, v_form_key, p_rule_key),
+    0
+  ));
+
+  SELECT event_type, (payload->>'rule_id')::bigint
+  INTO v_event_type, v_rule_id
+  FROM orgunit.tenant_field_validation_rule_events
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id
+  LIMIT 1;
+
+  IF FOUND THEN
+    IF v_event_type <> 'DISABLE' THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_REQUEST_ID_CONFLICT', DETAIL = format('request_id=%s', p_request_id);
+    END IF;
+    RETURN v_rule_id;
+  END IF;
+
+  SELECT id, enabled_on
+  INTO v_rule_id, v_enabled_on
+  FROM orgunit.tenant_field_validation_rules
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_field_key
+    AND form_key = v_form_key
+    AND rule_key = p_rule_key
+    AND enabled_on < p_disabled_on
+    AND p_disabled_on < COALESCE(disabled_on, 'infinity'::date)
+  ORDER BY enabled_on DESC
+  LIMIT 1
+  FOR UPDATE;
+
+  IF v_rule_id IS NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_FIELD_VALIDATION_RULE_NOT_FOUND',
+      DETAIL = format('field_key=%s rule_key=%s', p_field_key, p_rule_key);
+  END IF;
+
+  UPDATE orgunit.tenant_field_validation_rules
+  SET disabled_on = p_disabled_on, disabled_at = now(), updated_at = now()
+  WHERE id = v_rule_id;
+
+  INSERT INTO orgunit.tenant_field_validation_rule_events (
+    event_uuid,
+    tenant_uuid,
+    event_type,
+    field_key,
+    form_key,
+    rule_key,
+    payload,
+    request_id,
+    initiator_uuid
+  ) VALUES (
+    gen_random_uuid(),
+    p_tenant_uuid,
+    'DISABLE',
+    p_field_key,
+    v_form_key,
+    p_rule_key,
+    jsonb_build_object('rule_id', v_rule_id, 'disabled_on', p_disabled_on),
+    p_request_id,
+    p_initiator_uuid
+  );
+
+  RETURN v_rule_id;
+END;
+$$;
+
+ALTER FUNCTION orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, date, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, date, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, date, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+ALTER FUNCTION orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, date, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, date, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, date, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orgunit.tenant_field_validation_rules DISABLE TRIGGER guard_tenant_field_validation_rules_write;
+ALTER TABLE orgunit.tenant_field_validation_rule_events DISABLE TRIGGER guard_tenant_field_validation_rule_events_write;
+
+ALTER TABLE orgunit.tenant_field_validation_rules ADD COLUMN IF NOT EXISTS scope_type text NOT NULL DEFAULT 'GLOBAL';
+ALTER TABLE orgunit.tenant_field_validation_rules ADD COLUMN IF NOT EXISTS scope_key text NOT NULL DEFAULT 'global';
+ALTER TABLE orgunit.tenant_field_validation_rule_events ADD COLUMN IF NOT EXISTS scope_type text NOT NULL DEFAULT 'GLOBAL';
+ALTER TABLE orgunit.tenant_field_validation_rule_events ADD COLUMN IF NOT EXISTS scope_key text NOT NULL DEFAULT 'global';
+UPDATE orgunit.tenant_field_validation_rules SET scope_type = 'FORM', scope_key = form_key WHERE form_key <> '';
+UPDATE orgunit.tenant_field_validation_rule_events SET scope_type = 'FORM', scope_key = form_key WHERE form_key <> '';
+
+ALTER TABLE orgunit.tenant_field_validation_rules ENABLE TRIGGER guard_tenant_field_validation_rules_write;
+ALTER TABLE orgunit.tenant_field_validation_rule_events ENABLE TRIGGER guard_tenant_field_validation_rule_events_write;
+
+ALTER TABLE orgunit.tenant_field_validation_rules ALTER COLUMN scope_type DROP DEFAULT, ALTER COLUMN scope_key DROP DEFAULT;
+ALTER TABLE orgunit.tenant_field_validation_rule_events ALTER COLUMN scope_type DROP DEFAULT, ALTER COLUMN scope_key DROP DEFAULT;
+
+DROP INDEX IF EXISTS orgunit.tenant_field_validation_rules_tenant_form_idx;
+DROP FUNCTION IF EXISTS orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, date, text, uuid);
+DROP FUNCTION IF EXISTS orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, date, text, uuid);
+
+ALTER TABLE orgunit.tenant_field_validation_rules
+  DROP CONSTRAINT IF EXISTS tenant_field_validation_rules_form_key_check,
+  DROP COLUMN IF EXISTS form_key,
+  ADD CONSTRAINT tenant_field_validation_rules_scope_type_check CHECK (scope_type IN ('GLOBAL','FORM')),
+  ADD CONSTRAINT tenant_field_validation_rules_scope_key_check CHECK (
+    (scope_type = 'GLOBAL' AND scope_key = 'global')
+    OR
+    (scope_type = 'FORM' AND scope_key IN (
+      'orgunit.create_dialog',
+      'orgunit.details.add_version_dialog',
+      'orgunit.details.insert_version_dialog',
+      'orgunit.details.correct_dialog'
+    ))
+  );
+ALTER TABLE orgunit.tenant_field_validation_rule_events
+  DROP COLUMN IF EXISTS form_key;
+
+CREATE INDEX IF NOT EXISTS tenant_field_validation_rules_tenant_scope_idx
+  ON orgunit.tenant_field_validation_rules (tenant_uuid, field_key, scope_type, scope_key, rule_key, enabled_on DESC);
+
+CREATE OR REPLACE FUNCTION orgunit.assert_tenant_field_validation_rules_non_overlapping()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_overlap_id bigint;
+BEGIN
+  SELECT r.id
+  INTO v_overlap_id
+  FROM orgunit.tenant_field_validation_rules r
+  WHERE r.tenant_uuid = NEW.tenant_uuid
+    AND r.field_key = NEW.field_key
+    AND r.scope_type = NEW.scope_type
+    AND r.scope_key = NEW.scope_key
+    AND r.rule_key = NEW.rule_key
+    AND r.id <> NEW.id
+    AND daterange(NEW.enabled_on, COALESCE(NEW.disabled_on, 'infinity'::date), '[)')
+      && daterange(r.enabled_on, COALESCE(r.disabled_on, 'infinity'::date), '[)')
+  LIMIT 1;
+
+  IF v_overlap_id IS NOT NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'FIELD_POLICY_SCOPE_OVERLAP',
+      DETAIL = format(
+        'field_key=%s scope_type=%s scope_key=%s rule_key=%s overlap_id=%s',
+        NEW.field_key, NEW.scope_type, NEW.scope_key, NEW.rule_key, v_overlap_id
+      );
+  END IF;
+
+  RETURN NEW;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.upsert_tenant_field_validation_rule(
+  p_tenant_uuid uuid,
+  p_field_key text,
+  p_scope_type text,
+  p_scope_key text,
+  p_rule_key text,
+  p_rule_expr text,
+  p_message text,
+  p_enabled_on date,
+  p_request_id text,
+  p_initiator_uuid uuid
+)
+RETURNS bigint
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_scope_type text := upper(btrim(p_scope_type));
+  v_scope_key text := btrim(p_scope_key);
+  v_rule_expr text := NULLIF(btrim(p_rule_expr), '');
+  v_message text := NULLIF(btrim(p_message), '');
+  v_event_type text;
+  v_rule_id bigint;
+  v_open_id bigint;
+  v_open_enabled_on date;
+  v_next_enabled_on date;
+BEGIN
+  IF v_scope_type NOT IN ('GLOBAL','FORM') THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_type invalid';
+  END IF;
+  IF v_scope_type = 'GLOBAL' THEN
+    v_scope_key := 'global';
+  END IF;
+  IF v_scope_key = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_key required';
+  END IF;
+  IF v_rule_expr IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'FIELD_POLICY_EXPR_INVALID', DETAIL = 'rule_expr required';
+  END IF;
+
+  PERFORM pg_advisory_xact_lock(hashtextextended(
+    format('orgunit.field_validation_rule:%s:%s:%s:%s:%s', p_tenant_uuid, p_field_key, v_scope_type, v_scope_key, p_rule_key),
+    0
+  ));
+
+  SELECT event_type, (payload->>'rule_id')::bigint
+  INTO v_event_type, v_rule_id
+  FROM orgunit.tenant_field_validation_rule_events
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id
+  LIMIT 1;
+
+  IF FOUND THEN
+    IF v_event_type <> 'UPSERT' THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_REQUEST_ID_CONFLICT', DETAIL = format('request_id=%s', p_request_id);
+    END IF;
+    RETURN v_rule_id;
+  END IF;
+
+  SELECT id, enabled_on
+  INTO v_open_id, v_open_enabled_on
+  FROM orgunit.tenant_field_validation_rules
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_field_key
+    AND scope_type = v_scope_type
+    AND scope_key = v_scope_key
+    AND rule_key = p_rule_key
+    AND disabled_on IS NULL
+  ORDER BY enabled_on DESC
+  LIMIT 1
+  FOR UPDATE;
+
+  IF v_open_id IS NOT NULL AND v_open_enabled_on < p_enabled_on THEN
+    UPDATE orgunit.tenant_field_validation_rules
+    SET disabled_on = p_enabled_on, disabled_at = now(), updated_at = now()
+    WHERE id = v_open_id;
+  END IF;
+
+  UPDATE orgunit.tenant_field_validation_rules
+  SET rule_expr = v_rule_expr,
+      message = v_message,
+      updated_at = now()
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_field_key
+    AND scope_type = v_scope_type
+    AND scope_key = v_scope_key
+    AND rule_key = p_rule_key
+    AND enabled_on = p_enabled_on
+  RETURNING id INTO v_rule_id;
+
+  IF v_rule_id IS NULL THEN
+    SELECT MIN(enabled_on)
+    INTO v_next_enabled_on
+    FROM orgunit.tenant_field_validation_rules
+    WHERE tenant_uuid = p_tenant_uuid
+      AND field_key = p_field_key
+      AND scope_type = v_scope_type
+      AND scope_key = v_scope_key
+      AND rule_key = p_rule_key
+      AND enabled_on > p_enabled_on;
+
+    INSERT INTO orgunit.tenant_field_validation_rules (
+      tenant_uuid,
+      field_key,
+      scope_type,
+      scope_key,
+      rule_key,
+      rule_expr,
+      message,
+      enabled_on,
+      disabled_on
+    ) VALUES (
+      p_tenant_uuid,
+      p_field_key,
+      v_scope_type,
+      v_scope_key,
+      p_rule_key,
+      v_rule_expr,
+      v_message,
+      p_enabled_on,
+      v_next_enabled_on
+    )
+    RETURNING id INTO v_rule_id;
+  END IF;
+
+  INSERT INTO orgunit.tenant_field_validation_rule_events (
+    event_uuid,
+    tenant_uuid,
+    event_type,
+    field_key,
+    scope_type,
+    scope_key,
+    rule_key,
+    payload,
+    request_id,
+    initiator_uuid
+  ) VALUES (
+    gen_random_uuid(),
+    p_tenant_uuid,
+    'UPSERT',
+    p_field_key,
+    v_scope_type,
+    v_scope_key,
+    p_rule_key,
+    jsonb_build_object('rule_id', v_rule_id, 'rule_expr', v_rule_expr),
+    p_request_id,
+    p_initiator_uuid
+  );
+
+  RETURN v_rule_id;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.disable_tenant_field_validation_rule(
+  p_tenant_uuid uuid,
+  p_field_key text,
+  p_scope_type text,
+  p_scope_key text,
+  p_rule_key text,
+  p_disabled_on date,
+  p_request_id text,
+  p_initiator_uuid uuid
+)
+RETURNS bigint
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_scope_type text := upper(btrim(p_scope_type));
+  v_scope_key text := btrim(p_scope_key);
+  v_event_type text;
+  v_rule_id bigint;
+  v_enabled_on date;
+BEGIN
+  IF v_scope_type NOT IN ('GLOBAL','FORM') THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_type invalid';
+  END IF;
+  IF v_scope_type = 'GLOBAL' THEN
+    v_scope_key := 'global';
+  END IF;
+  IF v_scope_key = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'scope_key required';
+  END IF;
+
+  PERFORM pg_advisory_xact_lock(hashtextextended(
+    format('orgunit.field_validation_rule:%s:%s:%s:%s:%s', p_tenant_uuid, p_field_key, v_scope_type, v_scope_key, p_rule_key),
+    0
+  ));
+
+  SELECT event_type, (payload->>'rule_id')::bigint
+  INTO v_event_type, v_rule_id
+  FROM orgunit.tenant_field_validation_rule_events
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id
+  LIMIT 1;
+
+  IF FOUND THEN
+    IF v_event_type <> 'DISABLE' THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_REQUEST_ID_CONFLICT', DETAIL = format('request_id=%s', p_request_id);
+    END IF;
+    RETURN v_rule_id;
+  END IF;
+
+  SELECT id, enabled_on
+  INTO v_rule_id, v_enabled_on
+  FROM orgunit.tenant_field_validation_rules
+  WHERE tenant_uuid = p_tenant_uuid
+    AND field_key = p_field_key
+    AND scope_type = v_scope_type
+    AND scope_key = v_scope_key
+    AND rule_key = p_rule_key
+    AND enabled_on < p_disabled_on
+    AND p_disabled_on < COALESCE(disabled_on, 'infinity'::date)
+  ORDER BY enabled_on DESC
+  LIMIT 1
+  FOR UPDATE;
+
+  IF v_rule_id IS NULL THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_FIELD_VALIDATION_RULE_NOT_FOUND',
+      DETAIL = format('field_key=%s rule_key=%s', p_field_key, p_rule_key);
+  END IF;
+
+  UPDATE orgunit.tenant_field_validation_rules
+  SET disabled_on = p_disabled_on, disabled_at = now(), updated_at = now()
+  WHERE id = v_rule_id;
+
+  INSERT INTO orgunit.tenant_field_validation_rule_events (
+    event_uuid,
+    tenant_uuid,
+    event_type,
+    field_key,
+    scope_type,
+    scope_key,
+    rule_key,
+    payload,
+    request_id,
+    initiator_uuid
+  ) VALUES (
+    gen_random_uuid(),
+    p_tenant_uuid,
+    'DISABLE',
+    p_field_key,
+    v_scope_type,
+    v_scope_key,
+    p_rule_key,
+    jsonb_build_object('rule_id', v_rule_id, 'disabled_on', p_disabled_on),
+    p_request_id,
+    p_initiator_uuid
+  );
+
+  RETURN v_rule_id;
+END;
+$$;
+
+ALTER FUNCTION orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, text, date, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, text, date, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.upsert_tenant_field_validation_rule(uuid, text, text, text, text, text, text, date, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+ALTER FUNCTION orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, text, date, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, text, date, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.disable_tenant_field_validation_rule(uuid, text, text, text, text, date, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+-- +goose StatementEnd
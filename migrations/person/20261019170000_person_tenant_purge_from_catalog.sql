@@ -0,0 +1,93 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Tenant hard delete purges every person table that carries a tenant_uuid column, found in the catalog the
+-- same way offboarding verification finds them, so a new tenant-scoped table is covered without redefining
+-- this function. Tables go before the tables they reference.
+CREATE OR REPLACE FUNCTION person.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, person, public
+AS $$
+DECLARE
+  v_pending oid[];
+  v_table oid;
+  v_name text;
+  v_progress boolean;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'PERSON_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  SELECT COALESCE(array_agg(c.oid ORDER BY c.relname), '{}'::oid[]) INTO v_pending
+  FROM pg_class c
+  JOIN pg_attribute a ON a.attrelid = c.oid AND a.attname = 'tenant_uuid' AND NOT a.attisdropped
+  WHERE c.relnamespace = 'person'::regnamespace
+    AND c.relkind IN ('r', 'p')
+    AND NOT c.relispartition;
+
+  WHILE cardinality(v_pending) > 0 LOOP
+    v_progress := false;
+    FOREACH v_table IN ARRAY v_pending LOOP
+      CONTINUE WHEN EXISTS (
+        SELECT 1
+        FROM pg_constraint fk
+        WHERE fk.contype = 'f'
+          AND fk.confrelid = v_table
+          AND fk.conrelid <> v_table
+          AND fk.conrelid = ANY (v_pending)
+      );
+      SELECT format('person.%I', relname) INTO v_name FROM pg_class WHERE oid = v_table;
+      EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_name) USING p_tenant_uuid;
+      GET DIAGNOSTICS v_count = ROW_COUNT;
+      v_counts := v_counts || jsonb_build_object(v_name, v_count);
+      v_pending := array_remove(v_pending, v_table);
+      v_progress := true;
+    END LOOP;
+    IF NOT v_progress THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'PERSON_TENANT_PURGE_FK_CYCLE',
+        DETAIL = format('tables=%s', v_pending::regclass[]);
+    END IF;
+  END LOOP;
+
+  RETURN v_counts;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION person.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, person, public
+AS $$
+DECLARE
+  v_versions bigint;
+  v_persons bigint;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'PERSON_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  DELETE FROM person.person_versions WHERE tenant_uuid = p_tenant_uuid;
+  GET DIAGNOSTICS v_versions = ROW_COUNT;
+  DELETE FROM person.persons WHERE tenant_uuid = p_tenant_uuid;
+  GET DIAGNOSTICS v_persons = ROW_COUNT;
+
+  RETURN jsonb_build_object('person.person_versions', v_versions, 'person.persons', v_persons);
+END;
+$$;
+-- +goose StatementEnd
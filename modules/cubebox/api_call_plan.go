@@ -45,6 +45,8 @@ func DecodeAPICallPlan(raw []byte) (APICallPlan, error) {
 	return normalizeAPICallPlan(plan), nil
 }
 
+// ValidateAPICallPlan checks a plan as a dependency DAG: every depends_on entry must name a call declared
+// earlier in the same plan. Calls that do not depend on each other may run concurrently.
 func ValidateAPICallPlan(plan APICallPlan) error {
 	if len(plan.Calls) == 0 {
 		return wrapAPICallPlanBoundaryError("calls required")
@@ -52,12 +54,12 @@ func ValidateAPICallPlan(plan APICallPlan) error {
 
 	seenIDs := make(map[string]struct{}, len(plan.Calls))
 	for i, call := range plan.Calls {
-		if err := validateAPICallStep(call, i, plan.Calls, seenIDs); err != nil {
+		if err := validateAPICallStep(call, i, seenIDs); err != nil {
 			return err
 		}
 		seenIDs[strings.TrimSpace(call.ID)] = struct{}{}
 	}
-	return nil
+	return validateAPICallPlanDependencies(plan.Calls)
 }
 
 func normalizeAPICallPlan(plan APICallPlan) APICallPlan {
@@ -90,7 +92,7 @@ func normalizeAPICallPath(path string) string {
 	return "/" + path
 }
 
-func validateAPICallStep(call APICallStep, index int, calls []APICallStep, seenIDs map[string]struct{}) error {
+func validateAPICallStep(call APICallStep, index int, seenIDs map[string]struct{}) error {
 	id := strings.TrimSpace(call.ID)
 	if id == "" {
 		return wrapAPICallPlanBoundaryError(fmt.Sprintf("calls[%d].id required", index))
@@ -110,30 +112,87 @@ func validateAPICallStep(call APICallStep, index int, calls []APICallStep, seenI
 	if call.DependsOn == nil {
 		return wrapAPICallPlanBoundaryError(fmt.Sprintf("calls[%d].depends_on required", index))
 	}
-	if len(call.DependsOn) > 1 {
-		return wrapAPICallPlanBoundaryError(fmt.Sprintf("calls[%d].depends_on must be linear", index))
-	}
-	if index == 0 {
-		if len(call.DependsOn) != 0 {
-			return wrapAPICallPlanBoundaryError("calls[0].depends_on must be empty")
+	seenDeps := make(map[string]struct{}, len(call.DependsOn))
+	for j, dep := range call.DependsOn {
+		dep = strings.TrimSpace(dep)
+		if dep == "" {
+			return wrapAPICallPlanBoundaryError(fmt.Sprintf("calls[%d].depends_on[%d] required", index, j))
 		}
-		return nil
-	}
-	if len(call.DependsOn) == 0 {
-		return wrapAPICallPlanBoundaryError(fmt.Sprintf("calls[%d].depends_on required", index))
+		if dep == id {
+			return wrapAPICallPlanBoundaryError(fmt.Sprintf("calls[%d].depends_on must not reference itself", index))
+		}
+		if _, exists := seenDeps[dep]; exists {
+			return wrapAPICallPlanBoundaryError(fmt.Sprintf("calls[%d].depends_on[%d] duplicated", index, j))
+		}
+		seenDeps[dep] = struct{}{}
 	}
+	return nil
+}
 
-	expected := strings.TrimSpace(calls[index-1].ID)
-	actual := strings.TrimSpace(call.DependsOn[0])
-	if actual == "" {
-		return wrapAPICallPlanBoundaryError(fmt.Sprintf("calls[%d].depends_on[0] required", index))
+// validateAPICallPlanDependencies resolves depends_on against the plan. A cycle is reported as such before
+// the ordering rule, so the planner is told why a plan that only looks out of order was rejected.
+func validateAPICallPlanDependencies(calls []APICallStep) error {
+	indexByID := make(map[string]int, len(calls))
+	for i, call := range calls {
+		indexByID[strings.TrimSpace(call.ID)] = i
+	}
+	for i, call := range calls {
+		for j, dep := range call.DependsOn {
+			if _, ok := indexByID[strings.TrimSpace(dep)]; !ok {
+				return wrapAPICallPlanBoundaryError(fmt.Sprintf("calls[%d].depends_on[%d] references unknown call", i, j))
+			}
+		}
+	}
+	if cycle := findAPICallPlanCycle(calls, indexByID); cycle != "" {
+		return wrapAPICallPlanBoundaryError("depends_on forms a cycle: " + cycle)
 	}
-	if actual != expected {
-		return wrapAPICallPlanBoundaryError(fmt.Sprintf("calls[%d].depends_on must reference immediate previous call", index))
+	for i, call := range calls {
+		for j, dep := range call.DependsOn {
+			if indexByID[strings.TrimSpace(dep)] >= i {
+				return wrapAPICallPlanBoundaryError(fmt.Sprintf("calls[%d].depends_on[%d] must reference an earlier call", i, j))
+			}
+		}
 	}
 	return nil
 }
 
+func findAPICallPlanCycle(calls []APICallStep, indexByID map[string]int) string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(calls))
+	var path []string
+	var visit func(i int) string
+	visit = func(i int) string {
+		state[i] = visiting
+		path = append(path, strings.TrimSpace(calls[i].ID))
+		for _, dep := range calls[i].DependsOn {
+			next := indexByID[strings.TrimSpace(dep)]
+			switch state[next] {
+			case visiting:
+				return strings.Join(append(path, strings.TrimSpace(calls[next].ID)), " -> ")
+			case unvisited:
+				if cycle := visit(next); cycle != "" {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		return ""
+	}
+	for i := range calls {
+		if state[i] == unvisited {
+			if cycle := visit(i); cycle != "" {
+				return cycle
+			}
+		}
+	}
+	return ""
+}
+
 func wrapAPICallPlanDecodeError(detail string) error {
 	return fmt.Errorf("%w: %s", ErrAPICallPlanSchemaConstrainedDecodeFailed, strings.TrimSpace(detail))
 }
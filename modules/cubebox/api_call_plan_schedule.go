@@ -0,0 +1,111 @@
+package cubebox
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// DefaultAPICallPlanMaxConcurrency bounds how many calls of one plan run at the same time.
+const DefaultAPICallPlanMaxConcurrency = 4
+
+var errAPICallDependencyFailed = errors.New("cubebox: api call dependency failed")
+
+// APICallExecFunc executes one validated plan call.
+type APICallExecFunc func(ctx context.Context, call APICallStep) (ExecuteResult, error)
+
+// ExecuteAPICallPlanConcurrently starts each call as soon as every call it depends on has finished, running
+// at most maxConcurrency calls at once. Results are returned in plan order whatever order the calls finish
+// in, so working results and fingerprints built from them stay identical to a sequential run. The first
+// failure cancels the calls still running; cancelling ctx (for example on interrupt) stops the plan and
+// returns ctx.Err().
+func ExecuteAPICallPlanConcurrently(ctx context.Context, plan APICallPlan, maxConcurrency int, exec APICallExecFunc) ([]ExecuteResult, error) {
+	if err := ValidateAPICallPlan(plan); err != nil {
+		return nil, err
+	}
+	if maxConcurrency <= 0 {
+		maxConcurrency = DefaultAPICallPlanMaxConcurrency
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	n := len(plan.Calls)
+	indexByID := make(map[string]int, n)
+	done := make([]chan struct{}, n)
+	for i, call := range plan.Calls {
+		indexByID[strings.TrimSpace(call.ID)] = i
+		done[i] = make(chan struct{})
+	}
+	results := make([]ExecuteResult, n)
+	errs := make([]error, n)
+	slots := make(chan struct{}, maxConcurrency)
+
+	var wg sync.WaitGroup
+	for i, call := range plan.Calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[i])
+			for _, dep := range call.DependsOn {
+				d := indexByID[strings.TrimSpace(dep)]
+				select {
+				case <-done[d]:
+				case <-runCtx.Done():
+					errs[i] = runCtx.Err()
+					return
+				}
+				if errs[d] != nil {
+					errs[i] = errAPICallDependencyFailed
+					return
+				}
+			}
+			select {
+			case slots <- struct{}{}:
+			case <-runCtx.Done():
+				errs[i] = runCtx.Err()
+				return
+			}
+			defer func() { <-slots }()
+			if err := runCtx.Err(); err != nil {
+				errs[i] = err
+				return
+			}
+			result, err := exec(runCtx, call)
+			if err != nil {
+				errs[i] = err
+				cancel()
+				return
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := firstAPICallPlanError(errs); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// firstAPICallPlanError prefers the error that caused the plan to stop over the cancellations and skipped
+// dependents it triggered.
+func firstAPICallPlanError(errs []error) error {
+	var fallback error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, errAPICallDependencyFailed) || errors.Is(err, context.Canceled) {
+			if fallback == nil {
+				fallback = err
+			}
+			continue
+		}
+		return err
+	}
+	return fallback
+}
@@ -0,0 +1,149 @@
+package cubebox_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+)
+
+func testAPICallStep(id string, dependsOn ...string) cubebox.APICallStep {
+	if dependsOn == nil {
+		dependsOn = []string{}
+	}
+	return cubebox.APICallStep{ID: id, Method: "GET", Path: "/org/api/org-units", Params: map[string]any{"parent_org_code": id}, DependsOn: dependsOn}
+}
+
+func TestValidateAPICallPlanAcceptsDAG(t *testing.T) {
+	plan := cubebox.APICallPlan{Calls: []cubebox.APICallStep{
+		testAPICallStep("a"),
+		testAPICallStep("b"),
+		testAPICallStep("c", "a", "b"),
+		testAPICallStep("d", "a"),
+	}}
+	if err := cubebox.ValidateAPICallPlan(plan); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestValidateAPICallPlanRejectsInvalidDependencies(t *testing.T) {
+	cases := map[string]struct {
+		calls []cubebox.APICallStep
+		want  string
+	}{
+		"unknown":   {calls: []cubebox.APICallStep{testAPICallStep("a"), testAPICallStep("b", "zzz")}, want: "references unknown call"},
+		"self":      {calls: []cubebox.APICallStep{testAPICallStep("a", "a")}, want: "must not reference itself"},
+		"duplicate": {calls: []cubebox.APICallStep{testAPICallStep("a"), testAPICallStep("b", "a", "a")}, want: "depends_on[1] duplicated"},
+		"blank":     {calls: []cubebox.APICallStep{testAPICallStep("a"), testAPICallStep("b", " ")}, want: "depends_on[0] required"},
+		"forward":   {calls: []cubebox.APICallStep{testAPICallStep("a", "b"), testAPICallStep("b")}, want: "must reference an earlier call"},
+		"cycle":     {calls: []cubebox.APICallStep{testAPICallStep("a", "c"), testAPICallStep("b", "a"), testAPICallStep("c", "b")}, want: "cycle: a -> c -> b -> a"},
+	}
+	for name, tc := range cases {
+		err := cubebox.ValidateAPICallPlan(cubebox.APICallPlan{Calls: tc.calls})
+		if !errors.Is(err, cubebox.ErrAPICallPlanBoundaryViolation) || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: err=%v", name, err)
+		}
+	}
+}
+
+func TestExecuteAPICallPlanConcurrentlyRunsIndependentStepsInParallel(t *testing.T) {
+	plan := cubebox.APICallPlan{Calls: []cubebox.APICallStep{
+		testAPICallStep("a"),
+		testAPICallStep("b"),
+		testAPICallStep("c"),
+		testAPICallStep("d", "a", "b", "c"),
+	}}
+	var started sync.WaitGroup
+	started.Add(3)
+	allStarted := make(chan struct{})
+	go func() { started.Wait(); close(allStarted) }()
+	var finished sync.Map
+
+	results, err := cubebox.ExecuteAPICallPlanConcurrently(context.Background(), plan, 3, func(_ context.Context, call cubebox.APICallStep) (cubebox.ExecuteResult, error) {
+		if call.ID != "d" {
+			started.Done()
+			select {
+			case <-allStarted:
+			case <-time.After(2 * time.Second):
+				return cubebox.ExecuteResult{}, errors.New("independent steps did not run concurrently")
+			}
+			// Finish in reverse plan order to show results are still merged by plan position.
+			time.Sleep(time.Duration('d'-call.ID[0]) * 5 * time.Millisecond)
+		} else {
+			for _, dep := range []string{"a", "b", "c"} {
+				if _, ok := finished.Load(dep); !ok {
+					return cubebox.ExecuteResult{}, errors.New("dependent step started early")
+				}
+			}
+		}
+		finished.Store(call.ID, true)
+		return cubebox.ExecuteResult{StepID: call.ID}, nil
+	})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	for i, want := range []string{"a", "b", "c", "d"} {
+		if results[i].StepID != want {
+			t.Fatalf("results=%+v", results)
+		}
+	}
+}
+
+func TestExecuteAPICallPlanConcurrentlyHonoursLimit(t *testing.T) {
+	plan := cubebox.APICallPlan{Calls: []cubebox.APICallStep{testAPICallStep("a"), testAPICallStep("b"), testAPICallStep("c"), testAPICallStep("d"), testAPICallStep("e")}}
+	var inFlight, peak atomic.Int32
+	_, err := cubebox.ExecuteAPICallPlanConcurrently(context.Background(), plan, 2, func(context.Context, cubebox.APICallStep) (cubebox.ExecuteResult, error) {
+		n := inFlight.Add(1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		inFlight.Add(-1)
+		return cubebox.ExecuteResult{}, nil
+	})
+	if err != nil || peak.Load() > 2 {
+		t.Fatalf("peak=%d err=%v", peak.Load(), err)
+	}
+}
+
+func TestExecuteAPICallPlanConcurrentlyStopsOnFailureAndInterrupt(t *testing.T) {
+	boom := errors.New("boom")
+	plan := cubebox.APICallPlan{Calls: []cubebox.APICallStep{testAPICallStep("a"), testAPICallStep("b"), testAPICallStep("c", "a")}}
+	var ranDependent atomic.Bool
+	_, err := cubebox.ExecuteAPICallPlanConcurrently(context.Background(), plan, 0, func(ctx context.Context, call cubebox.APICallStep) (cubebox.ExecuteResult, error) {
+		switch call.ID {
+		case "a":
+			return cubebox.ExecuteResult{}, boom
+		case "b":
+			<-ctx.Done()
+			return cubebox.ExecuteResult{}, ctx.Err()
+		default:
+			ranDependent.Store(true)
+			return cubebox.ExecuteResult{}, nil
+		}
+	})
+	if !errors.Is(err, boom) || ranDependent.Load() {
+		t.Fatalf("err=%v ranDependent=%v", err, ranDependent.Load())
+	}
+
+	runtime := cubebox.NewRuntime()
+	turn := runtime.StartTurn(cubebox.TurnOwner{}, "compare")
+	ctx, cancel := turn.WithInterrupt(context.Background())
+	defer cancel()
+	_, err = cubebox.ExecuteAPICallPlanConcurrently(ctx, plan, 0, func(ctx context.Context, _ cubebox.APICallStep) (cubebox.ExecuteResult, error) {
+		runtime.InterruptTurn(turn.TurnID)
+		<-ctx.Done()
+		return cubebox.ExecuteResult{}, ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err=%v", err)
+	}
+}
@@ -55,6 +55,7 @@ type ExecuteResult struct {
 	Path                string
 	OperationID         string
 	StepID              string
+	Params              map[string]any
 	Payload             map[string]any
 	ResultFocus         []string
 	ConfirmedEntity     *QueryEntity
@@ -72,3 +72,13 @@ func (t APITool) RequiresParam(name string) bool {
 func APIToolRouteID(method string, path string) string {
 	return strings.ToUpper(strings.TrimSpace(method)) + " " + normalizeAPICallPath(path)
 }
+
+// APIToolOperationDomain returns the module that owns an operation_id: everything before the first dot, so
+// "orgunit.list" belongs to "orgunit". Tools are routed to their module's runner by this prefix.
+func APIToolOperationDomain(operationID string) string {
+	domain, _, ok := strings.Cut(strings.TrimSpace(operationID), ".")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(domain)
+}
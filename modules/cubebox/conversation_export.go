@@ -0,0 +1,326 @@
+package cubebox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var ErrConversationTurnNotFound = errors.New("CUBEBOX_CONVERSATION_TURN_NOT_FOUND")
+
+// QueryEvidenceRecordedEventType carries the API calls a turn executed before its answer was narrated, so
+// an exported or shared answer can show what it was based on.
+const QueryEvidenceRecordedEventType = "turn.query_evidence.recorded"
+
+const queryEvidenceSummaryDepth = 2
+
+// QueryEvidenceCall is one executed API call of a turn. ResultSummary keeps the scalar facts of the result
+// and replaces lists with their length; Entities are the records the result cited. Withheld is set on a
+// copy shown to a reader who may not see every cited entity; its params, summary and entities are dropped.
+type QueryEvidenceCall struct {
+	StepID        string           `json:"step_id,omitempty"`
+	Method        string           `json:"method"`
+	Path          string           `json:"path"`
+	OperationID   string           `json:"operation_id,omitempty"`
+	Params        map[string]any   `json:"params,omitempty"`
+	ResultSummary map[string]any   `json:"result_summary,omitempty"`
+	Entities      []QueryCandidate `json:"entities,omitempty"`
+	Withheld      bool             `json:"withheld,omitempty"`
+}
+
+func QueryEvidenceCallsFromResults(results []ExecuteResult) []QueryEvidenceCall {
+	out := make([]QueryEvidenceCall, 0, len(results))
+	for _, result := range results {
+		call := QueryEvidenceCall{
+			StepID:        strings.TrimSpace(result.StepID),
+			Method:        strings.ToUpper(strings.TrimSpace(result.Method)),
+			Path:          strings.TrimSpace(result.Path),
+			OperationID:   strings.TrimSpace(result.OperationID),
+			Params:        copyQueryNarrationPayload(result.Params),
+			ResultSummary: summarizeQueryEvidenceResult(result.Payload, queryEvidenceSummaryDepth),
+			Entities:      queryEvidenceEntities(result),
+		}
+		if call.Method == "" || call.Path == "" {
+			continue
+		}
+		out = append(out, call)
+	}
+	return out
+}
+
+func QueryEvidencePayload(calls []QueryEvidenceCall) map[string]any {
+	return map[string]any{"calls": calls}
+}
+
+// queryEvidenceCallsFromPayload reads the payload back both from a stored event, where it is decoded JSON,
+// and from an in-memory one that still holds the typed calls.
+func queryEvidenceCallsFromPayload(payload map[string]any) []QueryEvidenceCall {
+	raw, err := json.Marshal(payload["calls"])
+	if err != nil {
+		return nil
+	}
+	var calls []QueryEvidenceCall
+	if err := json.Unmarshal(raw, &calls); err != nil {
+		return nil
+	}
+	return calls
+}
+
+func summarizeQueryEvidenceResult(payload map[string]any, depth int) map[string]any {
+	if len(payload) == 0 || depth <= 0 {
+		return nil
+	}
+	summary := make(map[string]any, len(payload))
+	for key, value := range payload {
+		switch v := value.(type) {
+		case nil:
+		case []any:
+			summary[key+"_count"] = len(v)
+		case []map[string]any:
+			summary[key+"_count"] = len(v)
+		case map[string]any:
+			if nested := summarizeQueryEvidenceResult(v, depth-1); len(nested) > 0 {
+				summary[key] = nested
+			}
+		default:
+			summary[key] = v
+		}
+	}
+	if len(summary) == 0 {
+		return nil
+	}
+	return summary
+}
+
+func queryEvidenceEntities(result ExecuteResult) []QueryCandidate {
+	out := make([]QueryCandidate, 0, len(result.PresentedCandidates)+1)
+	seen := map[string]struct{}{}
+	add := func(candidate QueryCandidate) {
+		key := candidate.Domain + "\x00" + candidate.EntityKey
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		out = append(out, candidate)
+	}
+	if entity := NormalizeQueryEntity(derefQueryEntity(result.ConfirmedEntity)); entity != nil {
+		add(QueryCandidate{Domain: entity.Domain, EntityKey: entity.EntityKey, AsOf: entity.AsOf})
+	}
+	for _, candidate := range result.PresentedCandidates {
+		if normalized := NormalizeQueryCandidate(candidate); normalized != nil {
+			add(*normalized)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func derefQueryEntity(entity *QueryEntity) QueryEntity {
+	if entity == nil {
+		return QueryEntity{}
+	}
+	return *entity
+}
+
+// ConversationExport is a conversation, or one turn of it, rebuilt from its canonical events.
+type ConversationExport struct {
+	Conversation Conversation             `json:"conversation"`
+	Turns        []ConversationExportTurn `json:"turns"`
+}
+
+// ConversationExportTurn is one question and its answer. Status is completed, interrupted, error, or
+// in_progress for a turn without a terminal event. AnswerWithheld is set by FilterEvidence when the answer
+// was dropped along with evidence the reader may not see.
+type ConversationExportTurn struct {
+	TurnID           string              `json:"turn_id"`
+	StartedAt        string              `json:"started_at,omitempty"`
+	Status           string              `json:"status"`
+	UserMessage      string              `json:"user_message,omitempty"`
+	AssistantMessage string              `json:"assistant_message,omitempty"`
+	ErrorCode        string              `json:"error_code,omitempty"`
+	Evidence         []QueryEvidenceCall `json:"evidence,omitempty"`
+	AnswerWithheld   bool                `json:"answer_withheld,omitempty"`
+}
+
+// BuildConversationExport replays the events of a conversation into turns. A non-empty turnID keeps only
+// that turn and fails with ErrConversationTurnNotFound when the conversation has no such turn.
+func BuildConversationExport(replay ConversationReplayResponse, turnID string) (ConversationExport, error) {
+	turnID = strings.TrimSpace(turnID)
+	export := ConversationExport{Conversation: replay.Conversation, Turns: []ConversationExportTurn{}}
+	index := map[string]int{}
+	var answers []strings.Builder
+	for _, event := range replay.Events {
+		if event.TurnID == nil || strings.TrimSpace(*event.TurnID) == "" {
+			continue
+		}
+		id := strings.TrimSpace(*event.TurnID)
+		if turnID != "" && id != turnID {
+			continue
+		}
+		i, ok := index[id]
+		if !ok {
+			i = len(export.Turns)
+			index[id] = i
+			export.Turns = append(export.Turns, ConversationExportTurn{TurnID: id, StartedAt: event.TS, Status: "in_progress"})
+			answers = append(answers, strings.Builder{})
+		}
+		turn := &export.Turns[i]
+		switch event.Type {
+		case "turn.user_message.accepted":
+			turn.UserMessage = strings.TrimSpace(stringValue(event.Payload["text"]))
+		case "turn.agent_message.delta":
+			answers[i].WriteString(stringValue(event.Payload["delta"]))
+		case QueryEvidenceRecordedEventType:
+			turn.Evidence = append(turn.Evidence, queryEvidenceCallsFromPayload(event.Payload)...)
+		case "turn.error":
+			turn.Status = "error"
+			turn.ErrorCode = strings.TrimSpace(stringValue(event.Payload["code"]))
+		case "turn.completed":
+			if turn.Status != "error" {
+				turn.Status = "completed"
+				if status := strings.TrimSpace(stringValue(event.Payload["status"])); status != "" {
+					turn.Status = status
+				}
+			}
+		}
+	}
+	if turnID != "" && len(export.Turns) == 0 {
+		return ConversationExport{}, ErrConversationTurnNotFound
+	}
+	for i := range export.Turns {
+		export.Turns[i].AssistantMessage = strings.TrimSpace(answers[i].String())
+	}
+	return export, nil
+}
+
+// FilterEvidence returns a copy in which every evidence call the reader may not fully see is withheld: one
+// authorize rejects because the reader may not call that tool, one citing an entity allow rejects, an org
+// unit call naming a rejected org code in its params, or an org unit call with nothing to check at all. The
+// answer of a turn with withheld evidence was narrated from it, so it is withheld too.
+func (e ConversationExport) FilterEvidence(authorize func(QueryEvidenceCall) bool, allow func(QueryCandidate) bool) ConversationExport {
+	out := ConversationExport{Conversation: e.Conversation, Turns: make([]ConversationExportTurn, 0, len(e.Turns))}
+	for _, turn := range e.Turns {
+		filtered := turn
+		filtered.Evidence = make([]QueryEvidenceCall, 0, len(turn.Evidence))
+		for _, call := range turn.Evidence {
+			if !authorize(call) || !queryEvidenceCallVisible(call, allow) {
+				call = QueryEvidenceCall{StepID: call.StepID, Method: call.Method, Path: call.Path, OperationID: call.OperationID, Withheld: true}
+				filtered.AnswerWithheld = true
+			}
+			filtered.Evidence = append(filtered.Evidence, call)
+		}
+		if len(filtered.Evidence) == 0 {
+			filtered.Evidence = nil
+		}
+		if filtered.AnswerWithheld {
+			filtered.AssistantMessage = ""
+		}
+		out.Turns = append(out.Turns, filtered)
+	}
+	return out
+}
+
+// queryEvidenceOrgCodeParams are the params through which an org unit call names the units it reads.
+var queryEvidenceOrgCodeParams = []string{"org_code", "parent_org_code"}
+
+func queryEvidenceCallVisible(call QueryEvidenceCall, allow func(QueryCandidate) bool) bool {
+	entities := call.Entities
+	if strings.HasPrefix(call.OperationID, "orgunit.") {
+		if len(entities) == 0 {
+			return false
+		}
+		asOf := strings.TrimSpace(stringValue(call.Params["as_of"]))
+		entities = append([]QueryCandidate(nil), entities...)
+		for _, name := range queryEvidenceOrgCodeParams {
+			if code := strings.TrimSpace(stringValue(call.Params[name])); code != "" {
+				entities = append(entities, QueryCandidate{Domain: "orgunit", EntityKey: code, AsOf: asOf})
+			}
+		}
+	}
+	for _, entity := range entities {
+		if !allow(entity) {
+			return false
+		}
+	}
+	return true
+}
+
+// RenderConversationExportMarkdown renders the export for pasting into a ticket or a message. Params and
+// result summaries are written as compact JSON so they stay copyable.
+func RenderConversationExportMarkdown(export ConversationExport) string {
+	var out strings.Builder
+	title := strings.TrimSpace(export.Conversation.Title)
+	if title == "" {
+		title = export.Conversation.ID
+	}
+	fmt.Fprintf(&out, "# %s\n", title)
+	for i, turn := range export.Turns {
+		fmt.Fprintf(&out, "\n## 第 %d 轮", i+1)
+		if turn.StartedAt != "" {
+			fmt.Fprintf(&out, " · %s", turn.StartedAt)
+		}
+		out.WriteString("\n")
+		if turn.UserMessage != "" {
+			fmt.Fprintf(&out, "\n**提问**\n\n%s\n", turn.UserMessage)
+		}
+		if turn.AnswerWithheld {
+			out.WriteString("\n**回答**\n\n> 回答引用了你无权查看的组织，已隐藏。\n")
+		} else if turn.AssistantMessage != "" {
+			fmt.Fprintf(&out, "\n**回答**\n\n%s\n", turn.AssistantMessage)
+		}
+		if turn.Status == "error" {
+			fmt.Fprintf(&out, "\n> 本轮失败：%s\n", turn.ErrorCode)
+		}
+		if len(turn.Evidence) == 0 {
+			continue
+		}
+		out.WriteString("\n**查询依据**\n\n")
+		for j, call := range turn.Evidence {
+			fmt.Fprintf(&out, "%d. `%s %s`", j+1, call.Method, call.Path)
+			if call.OperationID != "" {
+				fmt.Fprintf(&out, " (%s)", call.OperationID)
+			}
+			out.WriteString("\n")
+			if call.Withheld {
+				out.WriteString("   - 该查询涉及你无权查看的组织，已隐藏参数与结果。\n")
+				continue
+			}
+			if len(call.Params) > 0 {
+				fmt.Fprintf(&out, "   - 参数：`%s`\n", markdownJSON(call.Params))
+			}
+			if len(call.ResultSummary) > 0 {
+				fmt.Fprintf(&out, "   - 结果：`%s`\n", markdownJSON(call.ResultSummary))
+			}
+			if len(call.Entities) > 0 {
+				fmt.Fprintf(&out, "   - 涉及：%s\n", markdownEntities(call.Entities))
+			}
+		}
+	}
+	return out.String()
+}
+
+// markdownJSON relies on encoding/json sorting map keys, so the same evidence always renders the same way.
+func markdownJSON(value map[string]any) string {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return strings.ReplaceAll(string(body), "`", "'")
+}
+
+func markdownEntities(entities []QueryCandidate) string {
+	labels := make([]string, 0, len(entities))
+	for _, entity := range entities {
+		label := entity.EntityKey
+		if entity.Name != "" {
+			label = entity.Name + " (" + entity.EntityKey + ")"
+		}
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return strings.Join(labels, "、")
+}
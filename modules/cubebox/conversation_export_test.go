@@ -0,0 +1,158 @@
+package cubebox
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func exportTestReplay() ConversationReplayResponse {
+	turn1, turn2 := "turn_1", "turn_2"
+	calls := QueryEvidenceCallsFromResults([]ExecuteResult{{
+		StepID:      "step-1",
+		Method:      "get",
+		Path:        "/org/api/org-units",
+		OperationID: "orgunit.list",
+		Params:      map[string]any{"as_of": "2026-10-01"},
+		Payload: map[string]any{
+			"as_of":     "2026-10-01",
+			"org_units": []any{map[string]any{"org_code": "A001"}, map[string]any{"org_code": "B002"}},
+			"page":      map[string]any{"total": 2, "items": []any{1}},
+		},
+		PresentedCandidates: []QueryCandidate{
+			{Domain: "orgunit", EntityKey: "A001", Name: "财务部"},
+			{Domain: "orgunit", EntityKey: "B002", Name: "人事部"},
+		},
+	}})
+	return ConversationReplayResponse{
+		Conversation: Conversation{ID: "conv_1", Title: "组织查询", Status: "active"},
+		Events: []CanonicalEvent{
+			{Sequence: 1, Type: "conversation.loaded"},
+			{Sequence: 2, TurnID: &turn1, Type: "turn.started", TS: "2026-10-19T08:00:00Z"},
+			{Sequence: 3, TurnID: &turn1, Type: "turn.user_message.accepted", Payload: map[string]any{"text": "列出组织"}},
+			{Sequence: 4, TurnID: &turn1, Type: QueryEvidenceRecordedEventType, Payload: QueryEvidencePayload(calls)},
+			{Sequence: 5, TurnID: &turn1, Type: "turn.agent_message.delta", Payload: map[string]any{"delta": "共 2 个"}},
+			{Sequence: 6, TurnID: &turn1, Type: "turn.agent_message.delta", Payload: map[string]any{"delta": "组织。"}},
+			{Sequence: 7, TurnID: &turn1, Type: "turn.completed", Payload: map[string]any{"status": "completed"}},
+			{Sequence: 8, TurnID: &turn2, Type: "turn.started", TS: "2026-10-19T08:05:00Z"},
+			{Sequence: 9, TurnID: &turn2, Type: "turn.user_message.accepted", Payload: map[string]any{"text": "再查一次"}},
+			{Sequence: 10, TurnID: &turn2, Type: "turn.error", Payload: map[string]any{"code": "ai_model_timeout"}},
+			{Sequence: 11, TurnID: &turn2, Type: "turn.completed", Payload: map[string]any{"status": "completed"}},
+		},
+	}
+}
+
+func TestBuildConversationExportReplaysTurnsAndEvidence(t *testing.T) {
+	export, err := BuildConversationExport(exportTestReplay(), "")
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if len(export.Turns) != 2 {
+		t.Fatalf("turns=%+v", export.Turns)
+	}
+	first := export.Turns[0]
+	if first.UserMessage != "列出组织" || first.AssistantMessage != "共 2 个组织。" || first.Status != "completed" || first.StartedAt != "2026-10-19T08:00:00Z" {
+		t.Fatalf("first=%+v", first)
+	}
+	if len(first.Evidence) != 1 {
+		t.Fatalf("evidence=%+v", first.Evidence)
+	}
+	call := first.Evidence[0]
+	if call.Method != "GET" || call.Params["as_of"] != "2026-10-01" || len(call.Entities) != 2 {
+		t.Fatalf("call=%+v", call)
+	}
+	if call.ResultSummary["org_units_count"] != float64(2) || call.ResultSummary["page"].(map[string]any)["items_count"] != float64(1) {
+		t.Fatalf("summary=%+v", call.ResultSummary)
+	}
+	if second := export.Turns[1]; second.Status != "error" || second.ErrorCode != "ai_model_timeout" {
+		t.Fatalf("second=%+v", second)
+	}
+
+	single, err := BuildConversationExport(exportTestReplay(), "turn_2")
+	if err != nil || len(single.Turns) != 1 || single.Turns[0].TurnID != "turn_2" {
+		t.Fatalf("single=%+v err=%v", single, err)
+	}
+	if _, err := BuildConversationExport(exportTestReplay(), "turn_9"); !errors.Is(err, ErrConversationTurnNotFound) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestConversationExportFilterEvidenceAndMarkdown(t *testing.T) {
+	export, err := BuildConversationExport(exportTestReplay(), "turn_1")
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	markdown := RenderConversationExportMarkdown(export)
+	for _, want := range []string{"# 组织查询", "## 第 1 轮 · 2026-10-19T08:00:00Z", "列出组织", "共 2 个组织。", "`GET /org/api/org-units` (orgunit.list)", `参数：` + "`" + `{"as_of":"2026-10-01"}`, "涉及：人事部 (B002)、财务部 (A001)"} {
+		if !strings.Contains(markdown, want) {
+			t.Fatalf("markdown missing %q:\n%s", want, markdown)
+		}
+	}
+
+	filtered := export.FilterEvidence(func(QueryEvidenceCall) bool { return true }, func(entity QueryCandidate) bool { return entity.EntityKey != "B002" })
+	call := filtered.Turns[0].Evidence[0]
+	if !call.Withheld || call.Params != nil || call.ResultSummary != nil || call.Entities != nil || call.Path != "/org/api/org-units" {
+		t.Fatalf("call=%+v", call)
+	}
+	if export.Turns[0].Evidence[0].Withheld {
+		t.Fatal("filter must not modify the original export")
+	}
+	if turn := filtered.Turns[0]; !turn.AnswerWithheld || turn.AssistantMessage != "" {
+		t.Fatalf("turn=%+v", turn)
+	}
+	markdown = RenderConversationExportMarkdown(filtered)
+	if strings.Contains(markdown, "财务部") || strings.Contains(markdown, "共 2 个组织。") || !strings.Contains(markdown, "已隐藏参数与结果") || !strings.Contains(markdown, "回答引用了你无权查看的组织") {
+		t.Fatalf("markdown=%s", markdown)
+	}
+
+	visible := export.FilterEvidence(func(QueryEvidenceCall) bool { return true }, func(QueryCandidate) bool { return true })
+	if turn := visible.Turns[0]; turn.AnswerWithheld || turn.AssistantMessage != "共 2 个组织。" || turn.Evidence[0].Withheld {
+		t.Fatalf("turn=%+v", turn)
+	}
+}
+
+func TestConversationExportFilterEvidenceChecksOrgUnitParams(t *testing.T) {
+	export := ConversationExport{Turns: []ConversationExportTurn{{
+		TurnID:           "turn_1",
+		AssistantMessage: "C003 下没有组织。",
+		Evidence: []QueryEvidenceCall{
+			{Method: "GET", Path: "/org/api/org-units", OperationID: "orgunit.list", Params: map[string]any{"as_of": "2026-10-01", "parent_org_code": "C003"}, Entities: []QueryCandidate{{Domain: "orgunit", EntityKey: "A001"}}},
+			{Method: "GET", Path: "/org/api/org-units/audit", OperationID: "orgunit.audit", Params: map[string]any{"org_code": "A001"}},
+			{Method: "GET", Path: "/iam/api/dicts", OperationID: "iam.dicts", Params: map[string]any{"as_of": "2026-10-01"}},
+		},
+	}}}
+	var checked []QueryCandidate
+	filtered := export.FilterEvidence(func(QueryEvidenceCall) bool { return true }, func(entity QueryCandidate) bool {
+		checked = append(checked, entity)
+		return entity.EntityKey != "C003"
+	})
+	evidence := filtered.Turns[0].Evidence
+	if !evidence[0].Withheld || !evidence[1].Withheld || evidence[2].Withheld {
+		t.Fatalf("evidence=%+v", evidence)
+	}
+	if !filtered.Turns[0].AnswerWithheld || filtered.Turns[0].AssistantMessage != "" {
+		t.Fatalf("turn=%+v", filtered.Turns[0])
+	}
+	if last := checked[len(checked)-1]; last.EntityKey != "C003" || last.AsOf != "2026-10-01" || last.Domain != "orgunit" {
+		t.Fatalf("checked=%+v", checked)
+	}
+}
+
+func TestConversationExportFilterEvidenceAuthorizesEveryCall(t *testing.T) {
+	export := ConversationExport{Turns: []ConversationExportTurn{{
+		TurnID:           "turn_1",
+		AssistantMessage: "共有 2 个角色。",
+		Evidence: []QueryEvidenceCall{
+			{Method: "GET", Path: "/iam/api/dicts", OperationID: "iam.dicts", Params: map[string]any{"as_of": "2026-10-01"}},
+			{Method: "GET", Path: "/iam/api/authz/roles", OperationID: "iam.authz.roles", ResultSummary: map[string]any{"roles_count": float64(2)}},
+		},
+	}}}
+	filtered := export.FilterEvidence(func(call QueryEvidenceCall) bool { return call.OperationID != "iam.authz.roles" }, func(QueryCandidate) bool { return true })
+	evidence := filtered.Turns[0].Evidence
+	if evidence[0].Withheld || !evidence[1].Withheld || evidence[1].ResultSummary != nil {
+		t.Fatalf("evidence=%+v", evidence)
+	}
+	if !filtered.Turns[0].AnswerWithheld || filtered.Turns[0].AssistantMessage != "" {
+		t.Fatalf("turn=%+v", filtered.Turns[0])
+	}
+}
@@ -0,0 +1,175 @@
+package cubebox
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrConversationCursorInvalid = errors.New("CUBEBOX_CONVERSATION_CURSOR_INVALID")
+var ErrConversationListQueryInvalid = errors.New("CUBEBOX_CONVERSATION_LIST_QUERY_INVALID")
+
+const (
+	conversationListDefaultLimit = 20
+	conversationListMaxLimit     = 100
+	conversationSearchMaxRunes   = 100
+)
+
+// ConversationListQuery filters one principal's conversations. UpdatedFrom/UpdatedTo bound updated_at as
+// [from, to); Archived nil lists both states. Cursor is the NextCursor of the previous page.
+type ConversationListQuery struct {
+	Limit       int32
+	Cursor      string
+	Query       string
+	Archived    *bool
+	UpdatedFrom time.Time
+	UpdatedTo   time.Time
+}
+
+// conversationCursor is the keyset position of the last item on a page. It only carries a position:
+// every page is still filtered by tenant and principal, so a forged cursor cannot reach other rows. The
+// HTTP API hands it out inside a signed token bound to the filters of the listing.
+type conversationCursor struct {
+	UpdatedAt      time.Time `json:"t"`
+	ConversationID string    `json:"c"`
+}
+
+func NormalizeConversationListQuery(query ConversationListQuery) (ConversationListQuery, error) {
+	if query.Limit <= 0 {
+		query.Limit = conversationListDefaultLimit
+	}
+	if query.Limit > conversationListMaxLimit {
+		query.Limit = conversationListMaxLimit
+	}
+	query.Cursor = strings.TrimSpace(query.Cursor)
+	query.Query = strings.TrimSpace(query.Query)
+	if len([]rune(query.Query)) > conversationSearchMaxRunes {
+		return ConversationListQuery{}, ErrConversationListQueryInvalid
+	}
+	if !query.UpdatedFrom.IsZero() && !query.UpdatedTo.IsZero() && !query.UpdatedFrom.Before(query.UpdatedTo) {
+		return ConversationListQuery{}, ErrConversationListQueryInvalid
+	}
+	if _, err := decodeConversationCursor(query.Cursor); err != nil {
+		return ConversationListQuery{}, err
+	}
+	return query, nil
+}
+
+func encodeConversationCursor(updatedAt time.Time, conversationID string) string {
+	payload, _ := json.Marshal(conversationCursor{UpdatedAt: updatedAt.UTC(), ConversationID: conversationID})
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// decodeConversationCursor returns nil for an empty token, which starts at the first page.
+func decodeConversationCursor(token string) (*conversationCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrConversationCursorInvalid
+	}
+	var cursor conversationCursor
+	if err := json.Unmarshal(payload, &cursor); err != nil || cursor.UpdatedAt.IsZero() || strings.TrimSpace(cursor.ConversationID) == "" {
+		return nil, ErrConversationCursorInvalid
+	}
+	return &cursor, nil
+}
+
+// conversationSearchPattern turns user input into an ILIKE substring pattern. Titles and messages are
+// mostly Chinese, which Postgres text search does not segment, so search is a trigram-indexed substring
+// match rather than tsvector.
+func conversationSearchPattern(query string) string {
+	if query == "" {
+		return ""
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(query)
+	return "%" + escaped + "%"
+}
+
+// ListConversations returns one page, newest first by (updated_at, conversation_id). The search matches the
+// title and the text of user and agent messages.
+func (s *Store) ListConversations(ctx context.Context, tenantID string, principalID string, query ConversationListQuery) (ConversationListResponse, error) {
+	query, err := NormalizeConversationListQuery(query)
+	if err != nil {
+		return ConversationListResponse{}, err
+	}
+	cursor, _ := decodeConversationCursor(query.Cursor)
+	var cursorAt any
+	cursorID := ""
+	if cursor != nil {
+		cursorAt = cursor.UpdatedAt
+		cursorID = cursor.ConversationID
+	}
+	var archived any
+	if query.Archived != nil {
+		archived = *query.Archived
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return ConversationListResponse{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
+		return ConversationListResponse{}, err
+	}
+	rows, err := tx.Query(ctx, `
+SELECT c.conversation_id, c.title, c.status, c.archived, c.updated_at
+FROM iam.cubebox_conversations c
+LEFT JOIN iam.cubebox_conversation_search_docs d
+  ON d.tenant_uuid = c.tenant_uuid
+ AND d.conversation_id = c.conversation_id
+WHERE c.tenant_uuid = $1::uuid
+  AND c.principal_id = $2::uuid
+  AND ($3::boolean IS NULL OR c.archived = $3::boolean)
+  AND ($4::timestamptz IS NULL OR c.updated_at >= $4::timestamptz)
+  AND ($5::timestamptz IS NULL OR c.updated_at < $5::timestamptz)
+  AND ($6::text = '' OR c.title ILIKE $6::text OR d.body ILIKE $6::text)
+  AND ($7::timestamptz IS NULL OR (c.updated_at, c.conversation_id) < ($7::timestamptz, $8::text))
+ORDER BY c.updated_at DESC, c.conversation_id DESC
+LIMIT $9;
+`, tenantID, principalID, archived, zeroTimeAsNull(query.UpdatedFrom), zeroTimeAsNull(query.UpdatedTo),
+		conversationSearchPattern(query.Query), cursorAt, cursorID, query.Limit+1)
+	if err != nil {
+		return ConversationListResponse{}, err
+	}
+	defer rows.Close()
+	items := []ConversationListItem{}
+	updatedAts := []time.Time{}
+	for rows.Next() {
+		var item ConversationListItem
+		var updatedAt time.Time
+		if err := rows.Scan(&item.ID, &item.Title, &item.Status, &item.Archived, &updatedAt); err != nil {
+			return ConversationListResponse{}, err
+		}
+		item.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+		items = append(items, item)
+		updatedAts = append(updatedAts, updatedAt)
+	}
+	if err := rows.Err(); err != nil {
+		return ConversationListResponse{}, err
+	}
+	rows.Close()
+	if err := tx.Commit(ctx); err != nil {
+		return ConversationListResponse{}, err
+	}
+	response := ConversationListResponse{Items: items}
+	// One row past the limit is fetched only to learn whether a next page exists.
+	if int32(len(items)) > query.Limit {
+		response.Items = items[:query.Limit]
+		last := query.Limit - 1
+		response.NextCursor = encodeConversationCursor(updatedAts[last], items[last].ID)
+	}
+	return response, nil
+}
+
+func zeroTimeAsNull(value time.Time) any {
+	if value.IsZero() {
+		return nil
+	}
+	return value
+}
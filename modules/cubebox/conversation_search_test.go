@@ -0,0 +1,67 @@
+package cubebox
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestStoreListConversationsPagesWithKeysetCursor(t *testing.T) {
+	newer := time.Date(2026, 10, 19, 9, 0, 0, 123456000, time.UTC)
+	older := newer.Add(-time.Hour)
+	tx := &txStub{rowsQueue: []pgx.Rows{&rowsStub{rows: [][]any{
+		{"conv_3", "财务报表", "active", false, newer},
+		{"conv_2", "100%_done", "archived", true, older},
+		{"conv_1", "extra", "active", false, older.Add(-time.Hour)},
+	}}}}
+	store := NewStore(tx)
+
+	archived := true
+	page, err := store.ListConversations(context.Background(), "tenant-1", "principal-1", ConversationListQuery{Limit: 2, Query: " 100%_ ", Archived: &archived})
+	if err != nil {
+		t.Fatalf("ListConversations err=%v", err)
+	}
+	if len(page.Items) != 2 || page.Items[1].ID != "conv_2" || page.NextCursor == "" {
+		t.Fatalf("page=%+v", page)
+	}
+	args := tx.queryArgs[0]
+	if args[2] != true || args[5] != `%100\%\_%` || args[6] != nil || args[8] != int32(3) {
+		t.Fatalf("args=%v", args)
+	}
+
+	cursor, err := decodeConversationCursor(page.NextCursor)
+	if err != nil || !cursor.UpdatedAt.Equal(older) || cursor.ConversationID != "conv_2" {
+		t.Fatalf("cursor=%+v err=%v", cursor, err)
+	}
+	tx.rowsQueue = []pgx.Rows{&rowsStub{rows: [][]any{{"conv_1", "extra", "active", false, older.Add(-time.Hour)}}}}
+	page, err = store.ListConversations(context.Background(), "tenant-1", "principal-1", ConversationListQuery{Limit: 2, Cursor: page.NextCursor})
+	if err != nil || len(page.Items) != 1 || page.NextCursor != "" {
+		t.Fatalf("page=%+v err=%v", page, err)
+	}
+	if args := tx.queryArgs[1]; args[2] != nil || args[5] != "" || !args[6].(time.Time).Equal(older) || args[7] != "conv_2" {
+		t.Fatalf("args=%v", args)
+	}
+}
+
+func TestNormalizeConversationListQuery(t *testing.T) {
+	query, err := NormalizeConversationListQuery(ConversationListQuery{Limit: 500})
+	if err != nil || query.Limit != conversationListMaxLimit {
+		t.Fatalf("query=%+v err=%v", query, err)
+	}
+	if _, err := NormalizeConversationListQuery(ConversationListQuery{Cursor: "not-base64!"}); !errors.Is(err, ErrConversationCursorInvalid) {
+		t.Fatalf("err=%v", err)
+	}
+	day := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	for _, bad := range []ConversationListQuery{
+		{UpdatedFrom: day, UpdatedTo: day},
+		{Query: strings.Repeat("查", conversationSearchMaxRunes+1)},
+	} {
+		if _, err := NormalizeConversationListQuery(bad); !errors.Is(err, ErrConversationListQueryInvalid) {
+			t.Fatalf("query=%+v err=%v", bad, err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package cubebox
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrConversationShareNotFound = errors.New("CUBEBOX_CONVERSATION_SHARE_NOT_FOUND")
+var ErrConversationShareInvalid = errors.New("CUBEBOX_CONVERSATION_SHARE_INVALID")
+
+const (
+	conversationShareMaxPrincipals     = 50
+	conversationShareDefaultExpiryDays = 7
+	conversationShareMaxExpiryDays     = 90
+	conversationShareListLimit         = 100
+)
+
+// ConversationShare is a read-only link to a conversation, or to one turn of it when TurnID is set, for a
+// fixed list of principals of the owner's tenant. Links expire and can be revoked by their owner.
+type ConversationShare struct {
+	ID             string   `json:"share_id"`
+	ConversationID string   `json:"conversation_id"`
+	TurnID         string   `json:"turn_id,omitempty"`
+	OwnerID        string   `json:"owner_principal_id"`
+	PrincipalIDs   []string `json:"principal_ids"`
+	CreatedAt      string   `json:"created_at"`
+	ExpiresAt      string   `json:"expires_at"`
+	RevokedAt      string   `json:"revoked_at,omitempty"`
+}
+
+type ConversationShareInput struct {
+	ConversationID string
+	TurnID         string
+	PrincipalIDs   []string
+	ExpiresInDays  int
+}
+
+// NormalizeConversationShareInput trims and de-duplicates the principals and applies the default expiry.
+// Whether the principals exist in the tenant is checked by the store.
+func NormalizeConversationShareInput(input ConversationShareInput) (ConversationShareInput, error) {
+	input.ConversationID = strings.TrimSpace(input.ConversationID)
+	input.TurnID = strings.TrimSpace(input.TurnID)
+	if input.ConversationID == "" {
+		return ConversationShareInput{}, ErrConversationShareInvalid
+	}
+	principals := make([]string, 0, len(input.PrincipalIDs))
+	for _, raw := range input.PrincipalIDs {
+		id, err := uuid.Parse(strings.TrimSpace(raw))
+		if err != nil {
+			return ConversationShareInput{}, ErrConversationShareInvalid
+		}
+		if !slices.Contains(principals, id.String()) {
+			principals = append(principals, id.String())
+		}
+	}
+	if len(principals) == 0 || len(principals) > conversationShareMaxPrincipals {
+		return ConversationShareInput{}, ErrConversationShareInvalid
+	}
+	input.PrincipalIDs = principals
+	if input.ExpiresInDays == 0 {
+		input.ExpiresInDays = conversationShareDefaultExpiryDays
+	}
+	if input.ExpiresInDays < 0 || input.ExpiresInDays > conversationShareMaxExpiryDays {
+		return ConversationShareInput{}, ErrConversationShareInvalid
+	}
+	return input, nil
+}
+
+// VisibleTo reports whether principalID may open the share at now. The owner always may until it is
+// revoked or expired, so the link can be checked before it is sent.
+func (s ConversationShare) VisibleTo(principalID string, now time.Time) bool {
+	if s.RevokedAt != "" {
+		return false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, s.ExpiresAt)
+	if err != nil || !now.Before(expiresAt) {
+		return false
+	}
+	principalID = strings.TrimSpace(principalID)
+	return principalID != "" && (principalID == s.OwnerID || slices.Contains(s.PrincipalIDs, principalID))
+}
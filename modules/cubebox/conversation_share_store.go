@@ -0,0 +1,176 @@
+package cubebox
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const conversationShareColumns = `share_id, conversation_id, COALESCE(turn_id, ''), owner_principal_id::text, principal_ids::text[], created_at, expires_at, revoked_at`
+
+// CreateConversationShare shares one of ownerID's conversations. Every principal must be an active
+// principal of the same tenant, and a turn, when given, must belong to the conversation.
+func (s *Store) CreateConversationShare(ctx context.Context, tenantID string, ownerID string, input ConversationShareInput) (ConversationShare, error) {
+	input, err := NormalizeConversationShareInput(input)
+	if err != nil {
+		return ConversationShare{}, err
+	}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return ConversationShare{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	var owned bool
+	if err := tx.QueryRow(ctx, `
+SELECT EXISTS (
+  SELECT 1 FROM iam.cubebox_conversations
+  WHERE tenant_uuid = $1::uuid AND conversation_id = $2 AND principal_id = $3::uuid
+);
+`, tenantID, input.ConversationID, ownerID).Scan(&owned); err != nil {
+		return ConversationShare{}, err
+	}
+	if !owned {
+		return ConversationShare{}, ErrConversationNotFound
+	}
+	if input.TurnID != "" {
+		var hasTurn bool
+		if err := tx.QueryRow(ctx, `
+SELECT EXISTS (
+  SELECT 1 FROM iam.cubebox_conversation_events
+  WHERE tenant_uuid = $1::uuid AND conversation_id = $2 AND turn_id = $3
+);
+`, tenantID, input.ConversationID, input.TurnID).Scan(&hasTurn); err != nil {
+			return ConversationShare{}, err
+		}
+		if !hasTurn {
+			return ConversationShare{}, ErrConversationTurnNotFound
+		}
+	}
+	var principals int
+	if err := tx.QueryRow(ctx, `
+SELECT count(*)::int
+FROM iam.principals
+WHERE tenant_uuid = $1::uuid
+  AND status = 'active'
+  AND id = ANY($2::text[]::uuid[]);
+`, tenantID, input.PrincipalIDs).Scan(&principals); err != nil {
+		return ConversationShare{}, err
+	}
+	if principals != len(input.PrincipalIDs) {
+		return ConversationShare{}, ErrConversationShareInvalid
+	}
+
+	shareID := "shr_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	share, err := scanConversationShare(tx.QueryRow(ctx, `
+INSERT INTO iam.cubebox_conversation_shares (tenant_uuid, share_id, conversation_id, turn_id, owner_principal_id, principal_ids, expires_at)
+VALUES ($1::uuid, $2, $3, NULLIF($4, ''), $5::uuid, $6::text[]::uuid[], now() + make_interval(days => $7))
+RETURNING `+conversationShareColumns+`;
+`, tenantID, shareID, input.ConversationID, input.TurnID, ownerID, input.PrincipalIDs, input.ExpiresInDays))
+	if err != nil {
+		return ConversationShare{}, err
+	}
+	return share, tx.Commit(ctx)
+}
+
+// ListConversationShares returns the shares ownerID created, newest first, including revoked and expired
+// ones so the owner can see what was shared.
+func (s *Store) ListConversationShares(ctx context.Context, tenantID string, ownerID string) ([]ConversationShare, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+	rows, err := tx.Query(ctx, `
+SELECT `+conversationShareColumns+`
+FROM iam.cubebox_conversation_shares
+WHERE tenant_uuid = $1::uuid
+  AND owner_principal_id = $2::uuid
+ORDER BY created_at DESC, share_id DESC
+LIMIT $3;
+`, tenantID, ownerID, conversationShareListLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []ConversationShare{}
+	for rows.Next() {
+		share, err := scanConversationShare(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, share)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	return out, tx.Commit(ctx)
+}
+
+// GetConversationShare loads a share by id within the tenant. Callers decide visibility with VisibleTo.
+func (s *Store) GetConversationShare(ctx context.Context, tenantID string, shareID string) (ConversationShare, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return ConversationShare{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+	share, err := scanConversationShare(tx.QueryRow(ctx, `
+SELECT `+conversationShareColumns+`
+FROM iam.cubebox_conversation_shares
+WHERE tenant_uuid = $1::uuid
+  AND share_id = $2;
+`, tenantID, strings.TrimSpace(shareID)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ConversationShare{}, ErrConversationShareNotFound
+		}
+		return ConversationShare{}, err
+	}
+	return share, tx.Commit(ctx)
+}
+
+// RevokeConversationShare revokes one of ownerID's shares. Revoking twice is not an error and keeps the
+// first revocation time.
+func (s *Store) RevokeConversationShare(ctx context.Context, tenantID string, ownerID string, shareID string) (ConversationShare, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return ConversationShare{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+	share, err := scanConversationShare(tx.QueryRow(ctx, `
+UPDATE iam.cubebox_conversation_shares
+SET revoked_at = COALESCE(revoked_at, now())
+WHERE tenant_uuid = $1::uuid
+  AND share_id = $2
+  AND owner_principal_id = $3::uuid
+RETURNING `+conversationShareColumns+`;
+`, tenantID, strings.TrimSpace(shareID), ownerID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ConversationShare{}, ErrConversationShareNotFound
+		}
+		return ConversationShare{}, err
+	}
+	return share, tx.Commit(ctx)
+}
+
+func scanConversationShare(row pgx.Row) (ConversationShare, error) {
+	var share ConversationShare
+	var createdAt, expiresAt time.Time
+	var revokedAt pgtype.Timestamptz
+	if err := row.Scan(&share.ID, &share.ConversationID, &share.TurnID, &share.OwnerID, &share.PrincipalIDs, &createdAt, &expiresAt, &revokedAt); err != nil {
+		return ConversationShare{}, err
+	}
+	share.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	share.ExpiresAt = expiresAt.UTC().Format(time.RFC3339)
+	if revokedAt.Valid {
+		share.RevokedAt = revokedAt.Time.UTC().Format(time.RFC3339)
+	}
+	return share, nil
+}
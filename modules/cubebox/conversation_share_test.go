@@ -0,0 +1,80 @@
+package cubebox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	shareTestViewer = "9b2f3c1e-1111-4a5b-8c9d-000000000001"
+	shareTestOther  = "9b2f3c1e-1111-4a5b-8c9d-000000000002"
+)
+
+func TestStoreCreateConversationShareChecksOwnerTurnAndPrincipals(t *testing.T) {
+	created := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	shareRow := rowStub{vals: []any{"shr_1", "conv_1", "turn_1", "owner-1", []string{shareTestViewer}, created, created.AddDate(0, 0, 7), pgtype.Timestamptz{}}}
+	tx := &txStub{rowQueue: []pgx.Row{rowStub{vals: []any{true}}, rowStub{vals: []any{true}}, rowStub{vals: []any{1}}, shareRow}}
+	share, err := NewStore(tx).CreateConversationShare(context.Background(), "tenant-1", "owner-1", ConversationShareInput{
+		ConversationID: " conv_1 ",
+		TurnID:         "turn_1",
+		PrincipalIDs:   []string{shareTestViewer, " " + shareTestViewer},
+	})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if share.ID != "shr_1" || share.ExpiresAt != "2026-10-26T08:00:00Z" || share.RevokedAt != "" {
+		t.Fatalf("share=%+v", share)
+	}
+
+	for name, rows := range map[string][]pgx.Row{
+		"not owner":         {rowStub{vals: []any{false}}},
+		"unknown turn":      {rowStub{vals: []any{true}}, rowStub{vals: []any{false}}},
+		"foreign principal": {rowStub{vals: []any{true}}, rowStub{vals: []any{true}}, rowStub{vals: []any{0}}},
+	} {
+		tx := &txStub{rowQueue: rows}
+		_, err := NewStore(tx).CreateConversationShare(context.Background(), "tenant-1", "owner-1", ConversationShareInput{
+			ConversationID: "conv_1",
+			TurnID:         "turn_1",
+			PrincipalIDs:   []string{shareTestViewer},
+		})
+		want := map[string]error{"not owner": ErrConversationNotFound, "unknown turn": ErrConversationTurnNotFound, "foreign principal": ErrConversationShareInvalid}[name]
+		if !errors.Is(err, want) {
+			t.Fatalf("%s: err=%v", name, err)
+		}
+	}
+}
+
+func TestConversationShareInputAndVisibility(t *testing.T) {
+	input, err := NormalizeConversationShareInput(ConversationShareInput{ConversationID: "conv_1", PrincipalIDs: []string{shareTestViewer}})
+	if err != nil || input.ExpiresInDays != conversationShareDefaultExpiryDays {
+		t.Fatalf("input=%+v err=%v", input, err)
+	}
+	for _, bad := range []ConversationShareInput{
+		{PrincipalIDs: []string{shareTestViewer}},
+		{ConversationID: "conv_1"},
+		{ConversationID: "conv_1", PrincipalIDs: []string{"not-a-uuid"}},
+		{ConversationID: "conv_1", PrincipalIDs: []string{shareTestViewer}, ExpiresInDays: conversationShareMaxExpiryDays + 1},
+	} {
+		if _, err := NormalizeConversationShareInput(bad); !errors.Is(err, ErrConversationShareInvalid) {
+			t.Fatalf("input=%+v err=%v", bad, err)
+		}
+	}
+
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	share := ConversationShare{OwnerID: "owner-1", PrincipalIDs: []string{shareTestViewer}, ExpiresAt: "2026-10-20T08:00:00Z"}
+	if !share.VisibleTo(shareTestViewer, now) || !share.VisibleTo("owner-1", now) || share.VisibleTo(shareTestOther, now) {
+		t.Fatalf("share=%+v", share)
+	}
+	if share.VisibleTo(shareTestViewer, now.Add(24*time.Hour)) {
+		t.Fatal("expired share must not be visible")
+	}
+	share.RevokedAt = "2026-10-19T07:00:00Z"
+	if share.VisibleTo("owner-1", now) {
+		t.Fatal("revoked share must not be visible")
+	}
+}
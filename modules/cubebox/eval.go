@@ -0,0 +1,366 @@
+package cubebox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrEvalSuiteInvalid = errors.New("CUBEBOX_EVAL_SUITE_INVALID")
+
+// EvalOutcomeError marks a scenario whose evaluated turn ended with turn.error instead of an answer.
+const EvalOutcomeError = "ERROR"
+
+// EvalSuite is a recorded set of CubeBox conversations replayed offline against the query flow, so a
+// prompt or knowledge-pack change can be measured before it ships. AsOf pins the planner's "today" so
+// relative dates in prompts resolve the same way on every run.
+type EvalSuite struct {
+	Name      string         `json:"name"`
+	AsOf      string         `json:"as_of,omitempty"`
+	OrgUnits  []EvalOrgUnit  `json:"org_units,omitempty"`
+	Scenarios []EvalScenario `json:"scenarios"`
+}
+
+// EvalOrgUnit seeds the in-memory org store. The first unit becomes the tree root and the principal's
+// org scope.
+type EvalOrgUnit struct {
+	OrgCode        string `json:"org_code"`
+	Name           string `json:"name"`
+	ParentOrgCode  string `json:"parent_org_code,omitempty"`
+	IsBusinessUnit bool   `json:"is_business_unit,omitempty"`
+}
+
+// EvalScenario replays Turns in order and scores only the last one; earlier turns build the conversation
+// history the planner sees.
+type EvalScenario struct {
+	ID     string              `json:"id"`
+	Turns  []EvalTurn          `json:"turns"`
+	Expect EvalExpectation     `json:"expect"`
+	Source *EvalScenarioSource `json:"source,omitempty"`
+}
+
+// EvalScenarioSource records the rated production turn a scenario was exported from. The harness ignores
+// it; it is there for whoever reviews the scenario.
+type EvalScenarioSource struct {
+	ConversationID string   `json:"conversation_id,omitempty"`
+	TurnID         string   `json:"turn_id,omitempty"`
+	TraceID        string   `json:"trace_id,omitempty"`
+	Rating         string   `json:"rating,omitempty"`
+	Reasons        []string `json:"reasons,omitempty"`
+	Comment        string   `json:"comment,omitempty"`
+}
+
+// EvalTurn is one user prompt. Planner and Narrator hold the recorded provider outputs for the scripted
+// stand-in, consumed in call order; a local provider ignores them.
+type EvalTurn struct {
+	Prompt   string   `json:"prompt"`
+	Planner  []string `json:"planner,omitempty"`
+	Narrator []string `json:"narrator,omitempty"`
+}
+
+type EvalExpectation struct {
+	Outcome       string             `json:"outcome"`
+	Calls         []EvalExpectedCall `json:"calls,omitempty"`
+	MissingParams []string           `json:"missing_params,omitempty"`
+	Narration     EvalNarrationCheck `json:"narration"`
+}
+
+// EvalExpectedCall matches an executed call by method and path; Params is a subset check so scenarios
+// need not pin pagination defaults.
+type EvalExpectedCall struct {
+	Method string         `json:"method"`
+	Path   string         `json:"path"`
+	Params map[string]any `json:"params,omitempty"`
+}
+
+type EvalNarrationCheck struct {
+	Contains    []string `json:"contains,omitempty"`
+	NotContains []string `json:"not_contains,omitempty"`
+}
+
+// EvalObservation is what the harness saw while the evaluated turn ran.
+type EvalObservation struct {
+	Outcome           string        `json:"outcome"`
+	Calls             []APICallStep `json:"calls,omitempty"`
+	MissingParams     []string      `json:"missing_params,omitempty"`
+	Narration         string        `json:"narration,omitempty"`
+	ErrorCode         string        `json:"error_code,omitempty"`
+	PlanningRounds    int           `json:"planning_rounds"`
+	BoundaryViolation bool          `json:"boundary_violation,omitempty"`
+	BudgetExhausted   bool          `json:"budget_exhausted,omitempty"`
+}
+
+type EvalScenarioResult struct {
+	ID            string          `json:"id"`
+	PlanCorrect   bool            `json:"plan_correct"`
+	NarrationPass bool            `json:"narration_pass"`
+	Passed        bool            `json:"passed"`
+	Failures      []string        `json:"failures,omitempty"`
+	Observation   EvalObservation `json:"observation"`
+}
+
+type EvalMetrics struct {
+	Scenarios             int     `json:"scenarios"`
+	Passed                int     `json:"passed"`
+	PlanAccuracy          float64 `json:"plan_accuracy"`
+	NarrationPassRate     float64 `json:"narration_pass_rate"`
+	ClarificationRate     float64 `json:"clarification_rate"`
+	BoundaryViolationRate float64 `json:"boundary_violation_rate"`
+	MeanPlanningRounds    float64 `json:"mean_planning_rounds"`
+	MaxPlanningRounds     int     `json:"max_planning_rounds"`
+	BudgetPlanningRounds  int     `json:"budget_planning_rounds"`
+	BudgetExhausted       int     `json:"budget_exhausted"`
+}
+
+type EvalReport struct {
+	Suite    string               `json:"suite"`
+	Provider string               `json:"provider"`
+	Metrics  EvalMetrics          `json:"metrics"`
+	Results  []EvalScenarioResult `json:"results"`
+}
+
+// EvalComparison is a report measured against a baseline. Regressed scenarios passed in the baseline and
+// fail now; Fixed is the reverse.
+type EvalComparison struct {
+	Baseline  EvalMetrics        `json:"baseline"`
+	Current   EvalMetrics        `json:"current"`
+	Deltas    map[string]float64 `json:"deltas"`
+	Regressed []string           `json:"regressed,omitempty"`
+	Fixed     []string           `json:"fixed,omitempty"`
+	Missing   []string           `json:"missing,omitempty"`
+}
+
+func LoadEvalSuite(path string) (EvalSuite, error) {
+	data, err := os.ReadFile(strings.TrimSpace(path))
+	if err != nil {
+		return EvalSuite{}, err
+	}
+	var suite EvalSuite
+	if err := decodeStrictJSONObject(data, &suite); err != nil {
+		return EvalSuite{}, fmt.Errorf("%w: %s", ErrEvalSuiteInvalid, err.Error())
+	}
+	if err := ValidateEvalSuite(suite); err != nil {
+		return EvalSuite{}, err
+	}
+	return suite, nil
+}
+
+func ValidateEvalSuite(suite EvalSuite) error {
+	if strings.TrimSpace(suite.Name) == "" {
+		return fmt.Errorf("%w: name required", ErrEvalSuiteInvalid)
+	}
+	if len(suite.Scenarios) == 0 {
+		return fmt.Errorf("%w: scenarios required", ErrEvalSuiteInvalid)
+	}
+	if asOf := strings.TrimSpace(suite.AsOf); asOf != "" {
+		if _, err := time.Parse(time.DateOnly, asOf); err != nil {
+			return fmt.Errorf("%w: as_of must be YYYY-MM-DD", ErrEvalSuiteInvalid)
+		}
+	}
+	seen := map[string]struct{}{}
+	for _, scenario := range suite.Scenarios {
+		id := strings.TrimSpace(scenario.ID)
+		if id == "" {
+			return fmt.Errorf("%w: scenario id required", ErrEvalSuiteInvalid)
+		}
+		if _, ok := seen[id]; ok {
+			return fmt.Errorf("%w: duplicate scenario id %s", ErrEvalSuiteInvalid, id)
+		}
+		seen[id] = struct{}{}
+		if len(scenario.Turns) == 0 {
+			return fmt.Errorf("%w: scenario %s has no turns", ErrEvalSuiteInvalid, id)
+		}
+		for _, turn := range scenario.Turns {
+			if strings.TrimSpace(turn.Prompt) == "" {
+				return fmt.Errorf("%w: scenario %s has an empty prompt", ErrEvalSuiteInvalid, id)
+			}
+		}
+		switch scenario.Expect.Outcome {
+		case string(PlannerOutcomeAPICalls):
+			if len(scenario.Expect.Calls) == 0 {
+				return fmt.Errorf("%w: scenario %s expects API_CALLS without calls", ErrEvalSuiteInvalid, id)
+			}
+		case string(PlannerOutcomeClarify), string(PlannerOutcomeNoQuery), EvalOutcomeError:
+			if len(scenario.Expect.Calls) > 0 {
+				return fmt.Errorf("%w: scenario %s expects calls with outcome %s", ErrEvalSuiteInvalid, id, scenario.Expect.Outcome)
+			}
+		default:
+			return fmt.Errorf("%w: scenario %s has unknown outcome %q", ErrEvalSuiteInvalid, id, scenario.Expect.Outcome)
+		}
+	}
+	return nil
+}
+
+// ScoreEvalScenario compares one observation with the scenario's expectation. The plan is correct when
+// the outcome matches and, for API_CALLS, every executed call matches the expected call at the same
+// position; for CLARIFY the expected missing params must all be reported.
+func ScoreEvalScenario(scenario EvalScenario, observation EvalObservation) EvalScenarioResult {
+	result := EvalScenarioResult{ID: strings.TrimSpace(scenario.ID), Observation: observation}
+	expect := scenario.Expect
+
+	var planFailures []string
+	if observation.Outcome != expect.Outcome {
+		detail := ""
+		if observation.ErrorCode != "" {
+			detail = " (" + observation.ErrorCode + ")"
+		}
+		planFailures = append(planFailures, fmt.Sprintf("outcome=%s%s, want %s", observation.Outcome, detail, expect.Outcome))
+	}
+	switch expect.Outcome {
+	case string(PlannerOutcomeAPICalls):
+		planFailures = append(planFailures, evalCallFailures(expect.Calls, observation.Calls)...)
+	case string(PlannerOutcomeClarify):
+		for _, param := range expect.MissingParams {
+			if !slices.Contains(observation.MissingParams, param) {
+				planFailures = append(planFailures, "missing_params lacks "+param)
+			}
+		}
+	}
+	result.PlanCorrect = len(planFailures) == 0
+
+	var narrationFailures []string
+	for _, text := range expect.Narration.Contains {
+		if !strings.Contains(observation.Narration, text) {
+			narrationFailures = append(narrationFailures, fmt.Sprintf("narration lacks %q", text))
+		}
+	}
+	for _, text := range expect.Narration.NotContains {
+		if strings.Contains(observation.Narration, text) {
+			narrationFailures = append(narrationFailures, fmt.Sprintf("narration contains %q", text))
+		}
+	}
+	result.NarrationPass = len(narrationFailures) == 0
+
+	result.Failures = append(planFailures, narrationFailures...)
+	result.Passed = result.PlanCorrect && result.NarrationPass
+	return result
+}
+
+func evalCallFailures(expected []EvalExpectedCall, actual []APICallStep) []string {
+	if len(expected) != len(actual) {
+		return []string{fmt.Sprintf("executed %d calls, want %d", len(actual), len(expected))}
+	}
+	var failures []string
+	for i, want := range expected {
+		got := actual[i]
+		if !strings.EqualFold(strings.TrimSpace(want.Method), strings.TrimSpace(got.Method)) ||
+			strings.TrimSpace(want.Path) != strings.TrimSpace(got.Path) {
+			failures = append(failures, fmt.Sprintf("call %d is %s %s, want %s %s", i+1, got.Method, got.Path, want.Method, want.Path))
+			continue
+		}
+		for key, value := range want.Params {
+			actualValue, ok := got.Params[key]
+			if !ok || fmt.Sprint(actualValue) != fmt.Sprint(value) {
+				failures = append(failures, fmt.Sprintf("call %d param %s=%v, want %v", i+1, key, actualValue, value))
+			}
+		}
+	}
+	return failures
+}
+
+func SummarizeEvalResults(suite EvalSuite, provider string, results []EvalScenarioResult) EvalReport {
+	metrics := EvalMetrics{
+		Scenarios:            len(results),
+		BudgetPlanningRounds: DefaultQueryLoopBudget().MaxPlanningRounds,
+	}
+	var planCorrect, narrationPass, clarified, violations, rounds int
+	for _, result := range results {
+		if result.Passed {
+			metrics.Passed++
+		}
+		if result.PlanCorrect {
+			planCorrect++
+		}
+		if result.NarrationPass {
+			narrationPass++
+		}
+		if result.Observation.Outcome == string(PlannerOutcomeClarify) {
+			clarified++
+		}
+		if result.Observation.BoundaryViolation {
+			violations++
+		}
+		if result.Observation.BudgetExhausted {
+			metrics.BudgetExhausted++
+		}
+		rounds += result.Observation.PlanningRounds
+		metrics.MaxPlanningRounds = max(metrics.MaxPlanningRounds, result.Observation.PlanningRounds)
+	}
+	metrics.PlanAccuracy = evalRate(planCorrect, len(results))
+	metrics.NarrationPassRate = evalRate(narrationPass, len(results))
+	metrics.ClarificationRate = evalRate(clarified, len(results))
+	metrics.BoundaryViolationRate = evalRate(violations, len(results))
+	metrics.MeanPlanningRounds = evalRate(rounds, len(results))
+	return EvalReport{
+		Suite:    strings.TrimSpace(suite.Name),
+		Provider: strings.TrimSpace(provider),
+		Metrics:  metrics,
+		Results:  results,
+	}
+}
+
+func evalRate(count int, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Round(float64(count)/float64(total)*10000) / 10000
+}
+
+func LoadEvalReport(path string) (EvalReport, error) {
+	data, err := os.ReadFile(strings.TrimSpace(path))
+	if err != nil {
+		return EvalReport{}, err
+	}
+	var report EvalReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return EvalReport{}, err
+	}
+	return report, nil
+}
+
+func CompareEvalReports(baseline EvalReport, current EvalReport) EvalComparison {
+	comparison := EvalComparison{
+		Baseline: baseline.Metrics,
+		Current:  current.Metrics,
+		Deltas: map[string]float64{
+			"plan_accuracy":           evalDelta(current.Metrics.PlanAccuracy, baseline.Metrics.PlanAccuracy),
+			"narration_pass_rate":     evalDelta(current.Metrics.NarrationPassRate, baseline.Metrics.NarrationPassRate),
+			"clarification_rate":      evalDelta(current.Metrics.ClarificationRate, baseline.Metrics.ClarificationRate),
+			"boundary_violation_rate": evalDelta(current.Metrics.BoundaryViolationRate, baseline.Metrics.BoundaryViolationRate),
+			"mean_planning_rounds":    evalDelta(current.Metrics.MeanPlanningRounds, baseline.Metrics.MeanPlanningRounds),
+		},
+	}
+	currentByID := make(map[string]EvalScenarioResult, len(current.Results))
+	for _, result := range current.Results {
+		currentByID[result.ID] = result
+	}
+	for _, base := range baseline.Results {
+		now, ok := currentByID[base.ID]
+		switch {
+		case !ok:
+			comparison.Missing = append(comparison.Missing, base.ID)
+		case base.Passed && !now.Passed:
+			comparison.Regressed = append(comparison.Regressed, base.ID)
+		case !base.Passed && now.Passed:
+			comparison.Fixed = append(comparison.Fixed, base.ID)
+		}
+	}
+	return comparison
+}
+
+// HasRegression reports whether the run is worse than the baseline: a scenario stopped passing, plan
+// accuracy fell, or more plans crossed the tool boundary.
+func (c EvalComparison) HasRegression() bool {
+	return len(c.Regressed) > 0 ||
+		c.Deltas["plan_accuracy"] < 0 ||
+		c.Deltas["boundary_violation_rate"] > 0
+}
+
+func evalDelta(current float64, baseline float64) float64 {
+	return math.Round((current-baseline)*10000) / 10000
+}
@@ -0,0 +1,121 @@
+package cubebox_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/cubebox"
+)
+
+func evalListScenario() cubebox.EvalScenario {
+	return cubebox.EvalScenario{
+		ID:    "list",
+		Turns: []cubebox.EvalTurn{{Prompt: "列出集团总部的下级组织"}},
+		Expect: cubebox.EvalExpectation{
+			Outcome: "API_CALLS",
+			Calls:   []cubebox.EvalExpectedCall{{Method: "GET", Path: "/org/api/org-units", Params: map[string]any{"parent_org_code": "100000", "page": 1}}},
+			Narration: cubebox.EvalNarrationCheck{
+				Contains:    []string{"研发中心"},
+				NotContains: []string{"step-1"},
+			},
+		},
+	}
+}
+
+func TestScoreEvalScenarioMatchesCallsAndNarration(t *testing.T) {
+	scenario := evalListScenario()
+	observation := cubebox.EvalObservation{
+		Outcome: "API_CALLS",
+		Calls: []cubebox.APICallStep{{
+			ID: "step-1", Method: "GET", Path: "/org/api/org-units",
+			// JSON decoding yields float64; the subset check compares printed values.
+			Params: map[string]any{"parent_org_code": "100000", "page": float64(1), "page_size": float64(100)},
+		}},
+		Narration:      "集团总部下有研发中心。",
+		PlanningRounds: 2,
+	}
+	result := cubebox.ScoreEvalScenario(scenario, observation)
+	if !result.Passed || !result.PlanCorrect || !result.NarrationPass || len(result.Failures) != 0 {
+		t.Fatalf("result=%+v", result)
+	}
+
+	observation.Calls[0].Params["parent_org_code"] = "200000"
+	observation.Narration = "step-1 返回了财务部。"
+	result = cubebox.ScoreEvalScenario(scenario, observation)
+	if result.PlanCorrect || result.NarrationPass || result.Passed {
+		t.Fatalf("result=%+v", result)
+	}
+	if got := strings.Join(result.Failures, "\n"); !strings.Contains(got, "param parent_org_code=200000") ||
+		!strings.Contains(got, `lacks "研发中心"`) || !strings.Contains(got, `contains "step-1"`) {
+		t.Fatalf("failures=%s", got)
+	}
+
+	result = cubebox.ScoreEvalScenario(scenario, cubebox.EvalObservation{Outcome: cubebox.EvalOutcomeError, ErrorCode: "ai_plan_boundary_violation"})
+	if result.PlanCorrect || !strings.Contains(result.Failures[0], "ERROR (ai_plan_boundary_violation)") {
+		t.Fatalf("result=%+v", result)
+	}
+}
+
+func TestScoreEvalScenarioClarifyNeedsMissingParams(t *testing.T) {
+	scenario := cubebox.EvalScenario{
+		ID:     "clarify",
+		Turns:  []cubebox.EvalTurn{{Prompt: "查一下那个组织"}},
+		Expect: cubebox.EvalExpectation{Outcome: "CLARIFY", MissingParams: []string{"org_code"}},
+	}
+	if result := cubebox.ScoreEvalScenario(scenario, cubebox.EvalObservation{Outcome: "CLARIFY", MissingParams: []string{"as_of", "org_code"}}); !result.Passed {
+		t.Fatalf("result=%+v", result)
+	}
+	if result := cubebox.ScoreEvalScenario(scenario, cubebox.EvalObservation{Outcome: "CLARIFY", MissingParams: []string{"as_of"}}); result.PlanCorrect {
+		t.Fatalf("result=%+v", result)
+	}
+}
+
+func TestSummarizeAndCompareEvalReports(t *testing.T) {
+	suite := cubebox.EvalSuite{Name: "core", Scenarios: []cubebox.EvalScenario{evalListScenario()}}
+	baseline := cubebox.SummarizeEvalResults(suite, "scripted", []cubebox.EvalScenarioResult{
+		{ID: "a", Passed: true, PlanCorrect: true, NarrationPass: true, Observation: cubebox.EvalObservation{Outcome: "API_CALLS", PlanningRounds: 2}},
+		{ID: "b", Passed: false, PlanCorrect: false, NarrationPass: true, Observation: cubebox.EvalObservation{Outcome: "CLARIFY", PlanningRounds: 1}},
+		{ID: "c", Passed: true, PlanCorrect: true, NarrationPass: true, Observation: cubebox.EvalObservation{Outcome: "ERROR", PlanningRounds: 80, BudgetExhausted: true}},
+	})
+	m := baseline.Metrics
+	if m.Scenarios != 3 || m.Passed != 2 || m.PlanAccuracy != 0.6667 || m.ClarificationRate != 0.3333 ||
+		m.MaxPlanningRounds != 80 || m.MeanPlanningRounds != 27.6667 || m.BudgetExhausted != 1 ||
+		m.BudgetPlanningRounds != cubebox.DefaultQueryLoopMaxPlanningRounds {
+		t.Fatalf("metrics=%+v", m)
+	}
+
+	current := cubebox.SummarizeEvalResults(suite, "scripted", []cubebox.EvalScenarioResult{
+		{ID: "a", Passed: false, PlanCorrect: false, Observation: cubebox.EvalObservation{Outcome: "ERROR", PlanningRounds: 1, BoundaryViolation: true}},
+		{ID: "b", Passed: true, PlanCorrect: true, NarrationPass: true, Observation: cubebox.EvalObservation{Outcome: "CLARIFY", PlanningRounds: 1}},
+	})
+	comparison := cubebox.CompareEvalReports(baseline, current)
+	if strings.Join(comparison.Regressed, ",") != "a" || strings.Join(comparison.Fixed, ",") != "b" || strings.Join(comparison.Missing, ",") != "c" {
+		t.Fatalf("comparison=%+v", comparison)
+	}
+	if comparison.Deltas["boundary_violation_rate"] != 0.5 || !comparison.HasRegression() {
+		t.Fatalf("comparison=%+v", comparison)
+	}
+	if same := cubebox.CompareEvalReports(baseline, baseline); same.HasRegression() {
+		t.Fatalf("comparison=%+v", same)
+	}
+}
+
+func TestValidateEvalSuiteRejectsBadScenarios(t *testing.T) {
+	valid := evalListScenario()
+	cases := map[string]cubebox.EvalSuite{
+		"name required":       {Scenarios: []cubebox.EvalScenario{valid}},
+		"duplicate scenario":  {Name: "s", Scenarios: []cubebox.EvalScenario{valid, valid}},
+		"has no turns":        {Name: "s", Scenarios: []cubebox.EvalScenario{{ID: "x", Expect: valid.Expect}}},
+		"without calls":       {Name: "s", Scenarios: []cubebox.EvalScenario{{ID: "x", Turns: valid.Turns, Expect: cubebox.EvalExpectation{Outcome: "API_CALLS"}}}},
+		"unknown outcome":     {Name: "s", Scenarios: []cubebox.EvalScenario{{ID: "x", Turns: valid.Turns, Expect: cubebox.EvalExpectation{Outcome: "DONE"}}}},
+		"as_of must be":       {Name: "s", AsOf: "today", Scenarios: []cubebox.EvalScenario{valid}},
+		"has an empty prompt": {Name: "s", Scenarios: []cubebox.EvalScenario{{ID: "x", Turns: []cubebox.EvalTurn{{}}, Expect: valid.Expect}}},
+	}
+	for want, suite := range cases {
+		err := cubebox.ValidateEvalSuite(suite)
+		if !errors.Is(err, cubebox.ErrEvalSuiteInvalid) || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: err=%v", want, err)
+		}
+	}
+}
@@ -55,11 +55,17 @@ type ProviderChatRequest struct {
 	Model    string
 	Messages []PromptItem
 	Input    string
+	// Tools are offered as native function definitions; empty keeps the request text-only.
+	Tools []ProviderTool
 }
 
 type ProviderChatChunk struct {
 	Delta string
-	Done  bool
+	// ToolCalls are function-call fragments as streamed; join them with a ProviderToolCallBuffer.
+	ToolCalls []ProviderToolCallDelta
+	Done      bool
+	// Usage is the provider's own token count, when it reports one; it arrives with the Done chunk.
+	Usage *TokenUsage
 }
 
 type ProviderChatStream interface {
@@ -80,11 +86,25 @@ type GatewayEventSink interface {
 	WriteFallback(CanonicalEvent)
 }
 
+// UsageStore persists metered turns and answers budget admission.
+type UsageStore interface {
+	RecordTurnUsage(ctx context.Context, records []UsageRecord) error
+	GetTokenBudgetSnapshot(ctx context.Context, tenantID string, principalID string, now time.Time) (TokenBudgetSnapshot, error)
+}
+
+// RedactionPolicyReader returns the tenant's redaction policy, or the default when none is stored.
+type RedactionPolicyReader interface {
+	GetRedactionPolicy(ctx context.Context, tenantID string) (RedactionPolicy, error)
+}
+
 type GatewayService struct {
 	runtime        *Runtime
 	configReader   RuntimeConfigReader
 	adapter        ProviderAdapter
 	secretResolver SecretResolver
+	usage          UsageStore
+	reservations   *tokenReservations
+	redaction      RedactionPolicyReader
 	now            func() time.Time
 }
 
@@ -95,6 +115,8 @@ type gatewayLifecycleMeta struct {
 	modelSlug    string
 	runtime      string
 	startedAt    time.Time
+	usage        *UsageMeter
+	redactor     *Redactor
 }
 
 func NewGatewayService(runtime *Runtime, configReader RuntimeConfigReader, adapter ProviderAdapter, secretResolver SecretResolver) *GatewayService {
@@ -107,6 +129,84 @@ func NewGatewayService(runtime *Runtime, configReader RuntimeConfigReader, adapt
 	}
 }
 
+// WithUsageStore enables token budget admission in AdmitTurn and usage recording in RecordTurnUsage.
+func (s *GatewayService) WithUsageStore(usage UsageStore) *GatewayService {
+	s.usage = usage
+	s.reservations = newTokenReservations()
+	return s
+}
+
+// WithRedactionPolicies makes AdmitTurn attach a per-turn Redactor built from the tenant's policy; the
+// provider adapter must be wrapped with NewRedactingProviderAdapter for it to take effect.
+func (s *GatewayService) WithRedactionPolicies(reader RedactionPolicyReader) *GatewayService {
+	s.redaction = reader
+	return s
+}
+
+// AdmitTurn checks the tenant and principal token budgets before a turn starts and returns the context the
+// turn must run under, which carries the turn's UsageMeter and Redactor. An admitted turn reserves its
+// estimated tokens until RecordTurnUsage settles them, so turns admitted concurrently count against each
+// other. A refused turn is closed with an error event and false is returned.
+func (s *GatewayService) AdmitTurn(ctx context.Context, request GatewayStreamRequest, store StreamAppendStore, sink GatewayEventSink) (context.Context, bool) {
+	ctx = WithUsageMeter(ctx, NewUsageMeter())
+	if s.redaction != nil {
+		policy, err := s.redaction.GetRedactionPolicy(ctx, request.TenantID)
+		if err != nil {
+			// Without its policy the turn could leak what the tenant asked to withhold, so it fails closed.
+			s.refuseTurn(ctx, request, store, sink, "redaction", "cubebox_turn_stream_failed", "AI 脱敏策略加载失败，当前响应已终止。")
+			return ctx, false
+		}
+		ctx = WithRedactor(ctx, NewRedactor(policy, request))
+	}
+	if s.usage == nil {
+		return ctx, true
+	}
+	snapshot, err := s.usage.GetTokenBudgetSnapshot(ctx, request.TenantID, request.PrincipalID, s.now())
+	if err != nil {
+		s.refuseTurn(ctx, request, store, sink, "budget", "cubebox_turn_stream_failed", "AI 用量预算检查失败，当前响应已终止。")
+		return ctx, false
+	}
+	status, release := s.reservations.admit(snapshot, request.TenantID, request.PrincipalID, estimateTurnReservation(request))
+	if status.Exceeded {
+		s.refuseTurn(ctx, request, store, sink, "budget", "ai_budget_exceeded", "AI 用量已达到预算上限，请联系管理员调整预算。")
+		return ctx, false
+	}
+	return withTokenReservation(ctx, release), true
+}
+
+func (s *GatewayService) refuseTurn(ctx context.Context, request GatewayStreamRequest, store StreamAppendStore, sink GatewayEventSink, runtime string, code string, message string) {
+	lifecycle := gatewayLifecycleMeta{
+		traceID:   "trace_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		runtime:   runtime,
+		startedAt: s.now(),
+	}
+	sequence := request.NextSequence
+	if prepared, err := PrepareTurnStream(ctx, store, request, s.buildCanonicalContext(request, lifecycle)); err == nil {
+		sequence = prepared.Sequence
+	}
+	if sequence <= 0 {
+		sequence = 1
+	}
+	turnID := TurnIDsForSequence(sequence).TurnID
+	s.appendTerminalError(ctx, store, sink, request, turnID, &sequence, lifecycle, code, message, false)
+}
+
+// RecordTurnUsage stores what the turn's UsageMeter counted and then releases the turn's reservation. It
+// runs after the turn has finished, so it does not depend on the request context still being live.
+func (s *GatewayService) RecordTurnUsage(ctx context.Context, request GatewayStreamRequest) error {
+	defer releaseTokenReservation(ctx)
+	if s.usage == nil {
+		return nil
+	}
+	records := UsageMeterFromContext(ctx).Records(request, s.now())
+	if len(records) == 0 {
+		return nil
+	}
+	recordCtx, cancel := terminalAppendContext(ctx)
+	defer cancel()
+	return s.usage.RecordTurnUsage(recordCtx, records)
+}
+
 func (s *GatewayService) StreamTurn(
 	ctx context.Context,
 	request GatewayStreamRequest,
@@ -118,6 +218,8 @@ func (s *GatewayService) StreamTurn(
 		traceID:   "trace_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
 		runtime:   "deterministic-fixture",
 		startedAt: startedAt,
+		usage:     UsageMeterFromContext(ctx),
+		redactor:  RedactorFromContext(ctx),
 	}
 	hasProviderRuntime := s.configReader != nil && s.adapter != nil && s.secretResolver != nil
 	var config ActiveModelRuntimeConfig
@@ -150,6 +252,7 @@ func (s *GatewayService) StreamTurn(
 		ConversationID: request.ConversationID,
 	}, request.Prompt, prepared.TurnIDs)
 	defer s.runtime.FinishTurn(turn.TurnID)
+	lifecycle.usage.Bind(turn.TurnID, lifecycle.providerID)
 
 	sequence := prepared.Sequence
 	providerPromptView := prepared.ProviderPromptView
@@ -480,6 +583,8 @@ func (s *GatewayService) completedPayload(status string, lifecycle gatewayLifecy
 	payload := s.lifecyclePayload(lifecycle)
 	payload["status"] = status
 	payload["latency_ms"] = s.latencyMS(lifecycle)
+	lifecycle.usage.AnnotatePayload(payload)
+	lifecycle.redactor.AnnotatePayload(payload)
 	return payload
 }
 
@@ -551,11 +656,28 @@ func (a *OpenAICompatibleAdapter) StreamChatCompletion(ctx context.Context, requ
 			"content": request.Input,
 		})
 	}
-	body, err := json.Marshal(map[string]any{
-		"model":    request.Model,
-		"messages": messages,
-		"stream":   true,
-	})
+	payload := map[string]any{
+		"model":          request.Model,
+		"messages":       messages,
+		"stream":         true,
+		"stream_options": map[string]any{"include_usage": true},
+	}
+	if len(request.Tools) > 0 {
+		tools := make([]map[string]any, 0, len(request.Tools))
+		for _, tool := range request.Tools {
+			tools = append(tools, map[string]any{
+				"type": "function",
+				"function": map[string]any{
+					"name":        tool.Name,
+					"description": tool.Description,
+					"parameters":  tool.Parameters,
+				},
+			})
+		}
+		payload["tools"] = tools
+		payload["tool_choice"] = "auto"
+	}
+	body, err := json.Marshal(payload)
 	if err != nil {
 		return nil, err
 	}
@@ -628,10 +750,14 @@ func normalizeProviderMessages(items []PromptItem) ([]map[string]string, error)
 }
 
 type openAICompatibleStream struct {
-	body    io.ReadCloser
-	scanner *bufio.Scanner
+	body     io.ReadCloser
+	scanner  *bufio.Scanner
+	finished bool
+	usage    *TokenUsage
 }
 
+// Recv keeps reading after finish_reason: with include_usage the token counts come in a trailing chunk
+// that has no choices, just before [DONE].
 func (s *openAICompatibleStream) Recv() (ProviderChatChunk, error) {
 	for s.scanner.Scan() {
 		line := strings.TrimSpace(s.scanner.Text())
@@ -643,26 +769,55 @@ func (s *openAICompatibleStream) Recv() (ProviderChatChunk, error) {
 		}
 		payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
 		if payload == "[DONE]" {
-			return ProviderChatChunk{Done: true}, nil
+			return ProviderChatChunk{Done: true, Usage: s.usage}, nil
 		}
 		var decoded struct {
 			Choices []struct {
 				Delta struct {
-					Content string `json:"content"`
+					Content   string `json:"content"`
+					ToolCalls []struct {
+						Index    int    `json:"index"`
+						ID       string `json:"id"`
+						Function struct {
+							Name      string `json:"name"`
+							Arguments string `json:"arguments"`
+						} `json:"function"`
+					} `json:"tool_calls"`
 				} `json:"delta"`
 				FinishReason *string `json:"finish_reason"`
 			} `json:"choices"`
+			Usage *struct {
+				PromptTokens     int64 `json:"prompt_tokens"`
+				CompletionTokens int64 `json:"completion_tokens"`
+			} `json:"usage"`
 		}
 		if err := json.Unmarshal([]byte(payload), &decoded); err != nil {
 			return ProviderChatChunk{}, ErrProviderStreamInvalid
 		}
-		if len(decoded.Choices) == 0 {
+		if decoded.Usage != nil {
+			s.usage = &TokenUsage{PromptTokens: decoded.Usage.PromptTokens, CompletionTokens: decoded.Usage.CompletionTokens}
+		}
+		if len(decoded.Choices) == 0 || s.finished {
 			continue
 		}
-		if decoded.Choices[0].FinishReason != nil && *decoded.Choices[0].FinishReason != "" {
-			return ProviderChatChunk{Done: true}, nil
+		choice := decoded.Choices[0]
+		chunk := ProviderChatChunk{Delta: choice.Delta.Content}
+		for _, call := range choice.Delta.ToolCalls {
+			chunk.ToolCalls = append(chunk.ToolCalls, ProviderToolCallDelta{
+				Index:     call.Index,
+				ID:        call.ID,
+				Name:      call.Function.Name,
+				Arguments: call.Function.Arguments,
+			})
 		}
-		return ProviderChatChunk{Delta: decoded.Choices[0].Delta.Content}, nil
+		if choice.FinishReason != nil && *choice.FinishReason != "" {
+			s.finished = true
+			// Some providers put the last tool-call fragment on the finishing chunk itself.
+			if chunk.Delta == "" && len(chunk.ToolCalls) == 0 {
+				continue
+			}
+		}
+		return chunk, nil
 	}
 	if err := s.scanner.Err(); err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
@@ -674,6 +829,9 @@ func (s *openAICompatibleStream) Recv() (ProviderChatChunk, error) {
 		}
 		return ProviderChatChunk{}, ErrProviderStreamInvalid
 	}
+	if s.finished {
+		return ProviderChatChunk{Done: true, Usage: s.usage}, nil
+	}
 	return ProviderChatChunk{}, io.EOF
 }
 
@@ -216,7 +216,7 @@ func TestOpenAICompatibleAdapterStreamChatCompletion(t *testing.T) {
 	if sawAuth != "Bearer sk-test" {
 		t.Fatalf("authorization=%q", sawAuth)
 	}
-	if !strings.Contains(sawBody, `"model":"gpt-4.1"`) || !strings.Contains(sawBody, `"stream":true`) {
+	if !strings.Contains(sawBody, `"model":"gpt-4.1"`) || !strings.Contains(sawBody, `"stream":true`) || strings.Contains(sawBody, `"tools"`) {
 		t.Fatalf("unexpected body=%s", sawBody)
 	}
 	if !strings.Contains(sawBody, `"messages":[{"content":"ctx","role":"system"},{"content":"hello","role":"user"}]`) {
@@ -224,6 +224,49 @@ func TestOpenAICompatibleAdapterStreamChatCompletion(t *testing.T) {
 	}
 }
 
+func TestOpenAICompatibleAdapterSendsToolsAndStreamsToolCalls(t *testing.T) {
+	var sawBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		sawBody = string(payload)
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"tool_calls\":[{\"index\":0,\"id\":\"call_1\",\"type\":\"function\",\"function\":{\"name\":\"orgunit_list\",\"arguments\":\"{\\\"as_of\"}}]},\"finish_reason\":null}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"arguments\":\"\\\":\\\"2026-04-25\\\"}\"}}]},\"finish_reason\":\"tool_calls\"}]}\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	stream, err := NewOpenAICompatibleAdapter(server.Client()).StreamChatCompletion(context.Background(), ProviderChatRequest{
+		BaseURL:  server.URL,
+		APIKey:   "sk-test",
+		Model:    "gpt-4.1",
+		Messages: []PromptItem{{Role: "user", Content: "列出组织"}},
+		Tools:    []ProviderTool{{Name: "orgunit_list", Description: "列出组织。", Parameters: emptyFunctionParameters()}},
+	})
+	if err != nil {
+		t.Fatalf("stream chat completion: %v", err)
+	}
+	defer func() { _ = stream.Close() }()
+
+	var buffer ProviderToolCallBuffer
+	for {
+		chunk, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		buffer.Add(chunk.ToolCalls)
+		if chunk.Done {
+			break
+		}
+	}
+	if calls := buffer.Calls(); len(calls) != 1 || calls[0].ID != "call_1" || calls[0].Name != "orgunit_list" || calls[0].Arguments != `{"as_of":"2026-04-25"}` {
+		t.Fatalf("calls=%+v", calls)
+	}
+	if !strings.Contains(sawBody, `"tools":[{"function":{"description":"列出组织。","name":"orgunit_list","parameters":{`) || !strings.Contains(sawBody, `"tool_choice":"auto"`) {
+		t.Fatalf("unexpected body=%s", sawBody)
+	}
+}
+
 func TestOpenAICompatibleAdapterNormalizesSummaryRoleToUserWithPrefix(t *testing.T) {
 	var sawBody string
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -56,6 +56,8 @@ type knowledgePackAPIsDoc struct {
 	APITools []struct {
 		OperationID string `yaml:"operation_id"`
 		QueryIntent string `yaml:"query_intent"`
+		Method      string `yaml:"method"`
+		Path        string `yaml:"path"`
 	} `yaml:"api_tools"`
 }
 
@@ -175,15 +177,38 @@ func ValidateKnowledgePack(pack KnowledgePack) error {
 	return nil
 }
 
+// ValidateKnowledgePacks checks every pack on its own and then that the packs can be served side by side:
+// each pack owns exactly one operation_id domain, and no intent, operation_id or API route is declared by
+// more than one pack.
 func ValidateKnowledgePacks(packs []KnowledgePack) error {
 	if len(packs) == 0 {
 		return wrapKnowledgePackError("knowledge packs missing")
 	}
+	declaredDomains := map[string]string{}
+	declaredIntents := map[string]string{}
 	declaredOperations := map[string]string{}
+	declaredRoutes := map[string]string{}
 	for _, pack := range packs {
 		if err := ValidateKnowledgePack(pack); err != nil {
 			return err
 		}
+		packDir := strings.TrimSpace(pack.Dir)
+		queriesBlock, err := extractFencedBlock(pack.Files["queries.md"], "yaml")
+		if err != nil {
+			return wrapKnowledgePackError(fmt.Sprintf("queries.md invalid: %v", err))
+		}
+		var queriesDoc knowledgePackQueriesDoc
+		if err := yaml.Unmarshal([]byte(queriesBlock), &queriesDoc); err != nil {
+			return wrapKnowledgePackError(fmt.Sprintf("queries.md yaml invalid: %v", err))
+		}
+		for _, item := range queriesDoc.Intents {
+			key := strings.TrimSpace(item.Key)
+			if ownerDir, exists := declaredIntents[key]; exists {
+				return wrapKnowledgePackError(fmt.Sprintf("intent declared by multiple knowledge packs: %s (%s, %s)", key, ownerDir, packDir))
+			}
+			declaredIntents[key] = packDir
+		}
+
 		apisBlock, err := extractFencedBlock(pack.Files["apis.md"], "yaml")
 		if err != nil {
 			return wrapKnowledgePackError(fmt.Sprintf("apis.md invalid: %v", err))
@@ -192,21 +217,43 @@ func ValidateKnowledgePacks(packs []KnowledgePack) error {
 		if err := yaml.Unmarshal([]byte(apisBlock), &apisDoc); err != nil {
 			return wrapKnowledgePackError(fmt.Sprintf("apis.md yaml invalid: %v", err))
 		}
+		packDomain := ""
 		for _, item := range apisDoc.APITools {
 			operationID := strings.TrimSpace(item.OperationID)
 			if operationID == "" {
 				continue
 			}
+			domain := APIToolOperationDomain(operationID)
+			if domain == "" {
+				return wrapKnowledgePackError(fmt.Sprintf("apis.md operation_id missing domain prefix: %s", operationID))
+			}
+			if packDomain == "" {
+				packDomain = domain
+			} else if domain != packDomain {
+				return wrapKnowledgePackError(fmt.Sprintf("apis.md operation_id outside pack domain %s: %s", packDomain, operationID))
+			}
 			if ownerDir, exists := declaredOperations[operationID]; exists {
 				return wrapKnowledgePackError(fmt.Sprintf(
 					"operation_id declared by multiple knowledge packs: %s (%s, %s)",
 					operationID,
 					ownerDir,
-					strings.TrimSpace(pack.Dir),
+					packDir,
 				))
 			}
-			declaredOperations[operationID] = strings.TrimSpace(pack.Dir)
+			declaredOperations[operationID] = packDir
+			if strings.TrimSpace(item.Path) == "" {
+				continue
+			}
+			routeID := APIToolRouteID(item.Method, item.Path)
+			if ownerDir, exists := declaredRoutes[routeID]; exists {
+				return wrapKnowledgePackError(fmt.Sprintf("api route declared by multiple knowledge packs: %s (%s, %s)", routeID, ownerDir, packDir))
+			}
+			declaredRoutes[routeID] = packDir
+		}
+		if ownerDir, exists := declaredDomains[packDomain]; exists {
+			return wrapKnowledgePackError(fmt.Sprintf("operation_id domain declared by multiple knowledge packs: %s (%s, %s)", packDomain, ownerDir, packDir))
 		}
+		declaredDomains[packDomain] = packDir
 	}
 	return nil
 }
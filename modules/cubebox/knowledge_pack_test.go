@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
 )
 
@@ -249,6 +250,49 @@ func TestValidateKnowledgePacksRejectsDuplicateOperationID(t *testing.T) {
 	}
 }
 
+func TestValidateKnowledgePacksRejectsCollisions(t *testing.T) {
+	orgunit := fakeKnowledgePack("modules/orgunit/presentation/cubebox", "orgunit.details", []string{"org_code"}, "", nil)
+	withRoute := func(pack KnowledgePack, method string, path string) KnowledgePack {
+		files := map[string]string{}
+		for name, content := range pack.Files {
+			files[name] = content
+		}
+		files["apis.md"] = strings.Replace(files["apis.md"], "\n```", "\n    method: "+method+"\n    path: "+path+"\n```", 1)
+		return KnowledgePack{Dir: pack.Dir, Files: files}
+	}
+	mixed := fakeKnowledgePack("modules/sample/presentation/cubebox", "sample.details", []string{"sample_id"}, "", nil)
+	mixed.Files["queries.md"] = strings.Replace(mixed.Files["queries.md"], "no_query_guidance:", "  - key: other.details\n    required_params: []\n    optional_params: []\nno_query_guidance:", 1)
+	mixed.Files["apis.md"] = strings.Replace(mixed.Files["apis.md"], "\n```", "\n  - operation_id: other.details\n    query_intent: other.details\n```", 1)
+
+	cases := map[string][]KnowledgePack{
+		"shared domain": {
+			orgunit,
+			fakeKnowledgePack("modules/orgunit_extra/presentation/cubebox", "orgunit.audit", []string{"org_code"}, "", nil),
+		},
+		"shared route": {
+			withRoute(orgunit, "GET", "/org/api/org-units/details"),
+			withRoute(fakeKnowledgePack("modules/sample/presentation/cubebox", "sample.details", []string{"sample_id"}, "", nil), "get", "org/api/org-units/details"),
+		},
+		"mixed domains": {orgunit, mixed},
+		"missing prefix": {
+			fakeKnowledgePack("modules/sample/presentation/cubebox", "details", []string{"sample_id"}, "", nil),
+		},
+	}
+	for name, packs := range cases {
+		if err := ValidateKnowledgePacks(packs); !errors.Is(err, ErrKnowledgePackInvalid) {
+			t.Fatalf("%s: expected ErrKnowledgePackInvalid, got %v", name, err)
+		}
+	}
+
+	distinct := []KnowledgePack{
+		withRoute(orgunit, "GET", "/org/api/org-units/details"),
+		withRoute(fakeKnowledgePack("modules/iam/presentation/cubebox", "iam.dicts", []string{"as_of"}, "", nil), "GET", "/iam/api/dicts"),
+	}
+	if err := ValidateKnowledgePacks(distinct); err != nil {
+		t.Fatalf("ValidateKnowledgePacks err=%v", err)
+	}
+}
+
 func fakeKnowledgePack(dir string, operationID string, requiredParams []string, scopeSummary string, prompts []string) KnowledgePack {
 	return KnowledgePack{
 		Dir: dir,
@@ -0,0 +1,251 @@
+package cubebox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NativeToolCallingCapability is the capability_summary key a model selection sets to true when its
+// provider accepts function definitions and streams tool_calls.
+const NativeToolCallingCapability = "tool_calls"
+
+// The planner's non-call outcomes are offered as functions too, so a tool-calling model never has to fall
+// back to the JSON envelope to clarify or stop.
+const (
+	PlannerClarifyFunction = "cubebox_clarify"
+	PlannerDoneFunction    = "cubebox_done"
+	PlannerNoQueryFunction = "cubebox_no_query"
+)
+
+func SupportsNativeToolCalling(capabilitySummary map[string]any) bool {
+	enabled, _ := capabilitySummary[NativeToolCallingCapability].(bool)
+	return enabled
+}
+
+// PlannerFunctionName turns an operation_id into a provider-safe function name ("orgunit.list" becomes
+// "orgunit_list"). Tools without an operation_id are named after their route.
+func PlannerFunctionName(tool APITool) string {
+	source := strings.TrimSpace(tool.OperationID)
+	if source == "" {
+		source = strings.ToLower(tool.Method) + "_" + normalizeAPICallPath(tool.Path)
+	}
+	var b strings.Builder
+	for _, r := range source {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	name := strings.Trim(b.String(), "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// PlannerFunctionStepDependsOn is the argument every API function accepts besides its request params. It
+// names calls of the same response by position ("step-1" is the first tool call) that must finish first.
+const PlannerFunctionStepDependsOn = "depends_on"
+
+// PlannerFunctionTools renders the API tool catalog as function definitions followed by the three control
+// functions. request_schema becomes JSON Schema parameters plus depends_on; tools whose names collide keep
+// the first.
+func PlannerFunctionTools(tools []APITool) []ProviderTool {
+	out := make([]ProviderTool, 0, len(tools)+3)
+	seen := map[string]struct{}{}
+	for _, tool := range tools {
+		tool = tool.Normalized()
+		name := PlannerFunctionName(tool)
+		if name == "" || isPlannerControlFunction(name) {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		out = append(out, ProviderTool{
+			Name:        name,
+			Description: strings.TrimSpace(fmt.Sprintf("%s (%s %s)", tool.UseSummary, tool.Method, tool.Path)),
+			Parameters:  apiToolParametersSchema(tool.RequestSchema),
+		})
+	}
+	return append(out,
+		ProviderTool{
+			Name:        PlannerClarifyFunction,
+			Description: "缺少必填参数或无法确定查询对象时，向用户追问。不能与其他函数同时调用。",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"missing_params":      map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "minItems": 1},
+					"clarifying_question": map[string]any{"type": "string"},
+				},
+				"required":             []string{"missing_params", "clarifying_question"},
+				"additionalProperties": false,
+			},
+		},
+		ProviderTool{
+			Name:        PlannerDoneFunction,
+			Description: "working_results 已足够回答用户问题时调用，结束查询。不能与其他函数同时调用。",
+			Parameters:  emptyFunctionParameters(),
+		},
+		ProviderTool{
+			Name:        PlannerNoQueryFunction,
+			Description: "请求不属于只读查询域时调用。不能与其他函数同时调用。",
+			Parameters:  emptyFunctionParameters(),
+		},
+	)
+}
+
+func apiToolParametersSchema(schema APIToolRequestSchema) map[string]any {
+	properties := map[string]any{}
+	for _, name := range append(append([]string{}, schema.Required...), schema.Optional...) {
+		spec := schema.Params[name]
+		property := map[string]any{}
+		switch strings.TrimSpace(spec.Type) {
+		case "boolean", "integer", "number", "object", "array":
+			property["type"] = spec.Type
+		case "date":
+			property["type"] = "string"
+			property["format"] = "date"
+		default:
+			property["type"] = "string"
+		}
+		if description := strings.TrimSpace(spec.Description); description != "" {
+			property["description"] = description
+		}
+		properties[name] = property
+	}
+	properties[PlannerFunctionStepDependsOn] = map[string]any{
+		"type":        "array",
+		"items":       map[string]any{"type": "string"},
+		"description": "本次回复中必须先执行完的调用编号，step-N 表示第 N 个函数调用；只约束执行先后，不会把前一调用的结果填入参数。互不依赖时省略。",
+	}
+	required := schema.Required
+	if required == nil {
+		required = []string{}
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// plannerToolCallDependsOn removes depends_on from decoded arguments and returns it as step ids.
+func plannerToolCallDependsOn(params map[string]any) ([]string, error) {
+	raw, ok := params[PlannerFunctionStepDependsOn]
+	delete(params, PlannerFunctionStepDependsOn)
+	if !ok || raw == nil {
+		return []string{}, nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, errors.New("must be an array of step ids")
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		id, ok := item.(string)
+		if !ok {
+			return nil, errors.New("must be an array of step ids")
+		}
+		out = append(out, strings.TrimSpace(id))
+	}
+	return out, nil
+}
+
+func emptyFunctionParameters() map[string]any {
+	return map[string]any{"type": "object", "properties": map[string]any{}, "additionalProperties": false}
+}
+
+func isPlannerControlFunction(name string) bool {
+	switch name {
+	case PlannerClarifyFunction, PlannerDoneFunction, PlannerNoQueryFunction:
+		return true
+	}
+	return false
+}
+
+// DecodePlannerToolCalls maps the function calls of one planner response onto the same outcome
+// DecodePlannerOutcome returns for the JSON envelope, and runs through it so both paths validate alike.
+// The N-th call becomes step "step-N"; its depends_on argument, if any, is lifted out of the params and
+// checked with the rest of the plan, so unknown, self or later references are rejected.
+func DecodePlannerToolCalls(calls []ProviderToolCall, tools []APITool) (PlannerOutcome, error) {
+	if len(calls) == 0 {
+		return PlannerOutcome{}, wrapPlannerOutcomeError("tool calls required")
+	}
+	byName := map[string]APITool{}
+	for _, tool := range tools {
+		tool = tool.Normalized()
+		if name := PlannerFunctionName(tool); name != "" {
+			if _, ok := byName[name]; !ok {
+				byName[name] = tool
+			}
+		}
+	}
+
+	envelope := map[string]any{}
+	steps := make([]APICallStep, 0, len(calls))
+	for i, call := range calls {
+		name := strings.TrimSpace(call.Name)
+		arguments := strings.TrimSpace(call.Arguments)
+		if arguments == "" {
+			arguments = "{}"
+		}
+		if isPlannerControlFunction(name) {
+			if len(calls) != 1 {
+				return PlannerOutcome{}, wrapPlannerOutcomeError(name + " must be the only tool call")
+			}
+			switch name {
+			case PlannerClarifyFunction:
+				var args struct {
+					MissingParams      []string `json:"missing_params"`
+					ClarifyingQuestion string   `json:"clarifying_question"`
+				}
+				if err := decodeStrictJSONObject([]byte(arguments), &args); err != nil {
+					return PlannerOutcome{}, wrapPlannerOutcomeError(err.Error())
+				}
+				envelope["outcome"] = PlannerOutcomeClarify
+				envelope["missing_params"] = args.MissingParams
+				envelope["clarifying_question"] = args.ClarifyingQuestion
+			case PlannerDoneFunction:
+				envelope["outcome"] = PlannerOutcomeDone
+			case PlannerNoQueryFunction:
+				envelope["outcome"] = PlannerOutcomeNoQuery
+			}
+			break
+		}
+		tool, ok := byName[name]
+		if !ok {
+			return PlannerOutcome{}, wrapAPICallPlanBoundaryError(fmt.Sprintf("tool_calls[%d] unknown function: %s", i, name))
+		}
+		params := map[string]any{}
+		if err := decodeStrictJSONObject([]byte(arguments), &params); err != nil {
+			return PlannerOutcome{}, wrapAPICallPlanDecodeError(fmt.Sprintf("tool_calls[%d].arguments: %s", i, err))
+		}
+		dependsOn, err := plannerToolCallDependsOn(params)
+		if err != nil {
+			return PlannerOutcome{}, wrapAPICallPlanDecodeError(fmt.Sprintf("tool_calls[%d].arguments.%s: %s", i, PlannerFunctionStepDependsOn, err))
+		}
+		steps = append(steps, APICallStep{
+			ID:        fmt.Sprintf("step-%d", i+1),
+			Method:    tool.Method,
+			Path:      tool.Path,
+			Params:    params,
+			DependsOn: dependsOn,
+		})
+	}
+	if len(steps) > 0 {
+		envelope["outcome"] = PlannerOutcomeAPICalls
+		envelope["calls"] = steps
+	}
+	raw, err := json.Marshal(envelope)
+	if err != nil {
+		return PlannerOutcome{}, wrapPlannerOutcomeError(err.Error())
+	}
+	return DecodePlannerOutcome(raw)
+}
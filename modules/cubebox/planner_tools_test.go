@@ -0,0 +1,185 @@
+package cubebox
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func plannerToolsTestCatalog() []APITool {
+	return []APITool{
+		{
+			Method:      "get",
+			Path:        "/org/api/org-units",
+			OperationID: "orgunit.list",
+			UseSummary:  "列出组织。",
+			RequestSchema: APIToolRequestSchema{
+				Required: []string{"as_of"},
+				Optional: []string{"include_disabled", "page", "filter"},
+				Params: map[string]APIParamSpec{
+					"as_of":            {Type: "date", Description: "业务生效日期，YYYY-MM-DD。"},
+					"include_disabled": {Type: "boolean"},
+					"page":             {Type: "integer"},
+					"filter":           {Type: "object"},
+				},
+			},
+		},
+		{Method: "GET", Path: "/org/api/org-units/details", OperationID: "orgunit.details", RequestSchema: APIToolRequestSchema{Required: []string{"org_code", "as_of"}}},
+	}
+}
+
+func TestPlannerFunctionToolsRenderRequestSchemaAsJSONSchema(t *testing.T) {
+	tools := PlannerFunctionTools(plannerToolsTestCatalog())
+	if len(tools) != 5 || tools[0].Name != "orgunit_list" || tools[2].Name != PlannerClarifyFunction || tools[4].Name != PlannerNoQueryFunction {
+		t.Fatalf("tools=%+v", tools)
+	}
+	if tools[0].Description != "列出组织。 (GET /org/api/org-units)" {
+		t.Fatalf("description=%q", tools[0].Description)
+	}
+	params := tools[0].Parameters
+	properties := params["properties"].(map[string]any)
+	if asOf := properties["as_of"].(map[string]any); asOf["type"] != "string" || asOf["format"] != "date" || asOf["description"] == nil {
+		t.Fatalf("as_of=%+v", asOf)
+	}
+	if properties["page"].(map[string]any)["type"] != "integer" || properties["filter"].(map[string]any)["type"] != "object" {
+		t.Fatalf("properties=%+v", properties)
+	}
+	if required := params["required"].([]string); len(required) != 1 || required[0] != "as_of" || params["additionalProperties"] != false {
+		t.Fatalf("params=%+v", params)
+	}
+	if dependsOn := properties[PlannerFunctionStepDependsOn].(map[string]any); dependsOn["type"] != "array" {
+		t.Fatalf("depends_on=%+v", dependsOn)
+	}
+	// Params the catalog lists without a spec still appear, as strings.
+	if tools[1].Parameters["properties"].(map[string]any)["org_code"].(map[string]any)["type"] != "string" {
+		t.Fatalf("details=%+v", tools[1].Parameters)
+	}
+
+	if !SupportsNativeToolCalling(map[string]any{"tool_calls": true}) || SupportsNativeToolCalling(map[string]any{"tool_calls": "yes"}) || SupportsNativeToolCalling(nil) {
+		t.Fatal("tool_calls capability must be an explicit true")
+	}
+}
+
+func TestProviderToolCallBufferJoinsFragmentsByIndex(t *testing.T) {
+	var buffer ProviderToolCallBuffer
+	buffer.Add([]ProviderToolCallDelta{{Index: 1, ID: "call_2", Name: "orgunit_details", Arguments: `{"org_code":`}})
+	buffer.Add([]ProviderToolCallDelta{{Index: 0, ID: "call_1", Name: "orgunit_list", Arguments: `{}`}, {Index: 1, Arguments: `"100000"}`}})
+	calls := buffer.Calls()
+	if buffer.Len() != 2 || calls[0].ID != "call_1" || calls[1].Name != "orgunit_details" || calls[1].Arguments != `{"org_code":"100000"}` {
+		t.Fatalf("calls=%+v", calls)
+	}
+}
+
+func TestDecodePlannerToolCallsMapsToPlannerOutcome(t *testing.T) {
+	catalog := plannerToolsTestCatalog()
+	outcome, err := DecodePlannerToolCalls([]ProviderToolCall{
+		{Name: "orgunit_list", Arguments: `{"as_of":"2026-04-25","page":2}`},
+		{Name: "orgunit_details", Arguments: `{"org_code":"100000","as_of":"2026-04-25"}`},
+	}, catalog)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if outcome.Type != PlannerOutcomeAPICalls || len(outcome.Calls.Calls) != 2 {
+		t.Fatalf("outcome=%+v", outcome)
+	}
+	if first := outcome.Calls.Calls[0]; first.ID != "step-1" || first.Method != "GET" || first.Path != "/org/api/org-units" || first.Params["page"] != float64(2) || len(first.DependsOn) != 0 {
+		t.Fatalf("first=%+v", first)
+	}
+
+	clarify, err := DecodePlannerToolCalls([]ProviderToolCall{{Name: PlannerClarifyFunction, Arguments: `{"missing_params":["org_code"],"clarifying_question":"哪个组织？"}`}}, catalog)
+	if err != nil || clarify.Type != PlannerOutcomeClarify || clarify.MissingParams[0] != "org_code" {
+		t.Fatalf("clarify=%+v err=%v", clarify, err)
+	}
+	if done, err := DecodePlannerToolCalls([]ProviderToolCall{{Name: PlannerDoneFunction}}, catalog); err != nil || done.Type != PlannerOutcomeDone {
+		t.Fatalf("done=%+v err=%v", done, err)
+	}
+
+	for _, tc := range []struct {
+		calls []ProviderToolCall
+		want  error
+	}{
+		{nil, ErrPlannerOutcomeInvalid},
+		{[]ProviderToolCall{{Name: PlannerDoneFunction}, {Name: "orgunit_list", Arguments: `{"as_of":"2026-04-25"}`}}, ErrPlannerOutcomeInvalid},
+		{[]ProviderToolCall{{Name: PlannerClarifyFunction, Arguments: `{"missing_params":[]}`}}, ErrPlannerOutcomeInvalid},
+		{[]ProviderToolCall{{Name: "orgunit_delete", Arguments: `{}`}}, ErrAPICallPlanBoundaryViolation},
+		{[]ProviderToolCall{{Name: "orgunit_list", Arguments: `{"as_of":`}}, ErrAPICallPlanSchemaConstrainedDecodeFailed},
+	} {
+		if _, err := DecodePlannerToolCalls(tc.calls, catalog); !errors.Is(err, tc.want) {
+			t.Fatalf("calls=%+v err=%v want=%v", tc.calls, err, tc.want)
+		}
+	}
+}
+
+func TestDecodePlannerToolCallsChecksDependsOn(t *testing.T) {
+	catalog := plannerToolsTestCatalog()
+	outcome, err := DecodePlannerToolCalls([]ProviderToolCall{
+		{Name: "orgunit_list", Arguments: `{"as_of":"2026-04-25"}`},
+		{Name: "orgunit_details", Arguments: `{"org_code":"100000","as_of":"2026-04-25","depends_on":["step-1"]}`},
+	}, catalog)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	second := outcome.Calls.Calls[1]
+	if len(second.DependsOn) != 1 || second.DependsOn[0] != "step-1" {
+		t.Fatalf("second=%+v", second)
+	}
+	if _, ok := second.Params[PlannerFunctionStepDependsOn]; ok {
+		t.Fatalf("depends_on must not be sent as a request param: %+v", second.Params)
+	}
+
+	for _, tc := range []struct {
+		arguments string
+		want      error
+	}{
+		{`{"org_code":"100000","as_of":"2026-04-25","depends_on":["step-9"]}`, ErrAPICallPlanBoundaryViolation},
+		{`{"org_code":"100000","as_of":"2026-04-25","depends_on":["step-2"]}`, ErrAPICallPlanBoundaryViolation},
+		{`{"org_code":"100000","as_of":"2026-04-25","depends_on":"step-1"}`, ErrAPICallPlanSchemaConstrainedDecodeFailed},
+	} {
+		_, err := DecodePlannerToolCalls([]ProviderToolCall{
+			{Name: "orgunit_list", Arguments: `{"as_of":"2026-04-25"}`},
+			{Name: "orgunit_details", Arguments: tc.arguments},
+		}, catalog)
+		if !errors.Is(err, tc.want) {
+			t.Fatalf("arguments=%s err=%v want=%v", tc.arguments, err, tc.want)
+		}
+	}
+	// A call may only wait for one declared before it in the same response.
+	if _, err := DecodePlannerToolCalls([]ProviderToolCall{
+		{Name: "orgunit_list", Arguments: `{"as_of":"2026-04-25","depends_on":["step-2"]}`},
+		{Name: "orgunit_details", Arguments: `{"org_code":"100000","as_of":"2026-04-25"}`},
+	}, catalog); !errors.Is(err, ErrAPICallPlanBoundaryViolation) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestRedactingProviderAdapterRestoresPlaceholdersInToolCallArguments(t *testing.T) {
+	redactor := NewRedactor(DefaultRedactionPolicy(), redactionTestRequest())
+	ctx := WithRedactor(context.Background(), redactor)
+	redactor.Redact("li.si@example.com")
+	adapter := &providerAdapterStub{stream: &providerChunkStub{chunks: []ProviderChatChunk{
+		{ToolCalls: []ProviderToolCallDelta{{Index: 0, Name: "orgunit_search", Arguments: `{"keyword":"[[EMA`}}},
+		{ToolCalls: []ProviderToolCallDelta{{Index: 0, Arguments: `IL_1]]"}`}}},
+		{Done: true},
+	}}}
+	stream, err := NewRedactingProviderAdapter(adapter).StreamChatCompletion(ctx, ProviderChatRequest{Model: "gpt-4.1"})
+	if err != nil {
+		t.Fatalf("StreamChatCompletion err=%v", err)
+	}
+	var buffer ProviderToolCallBuffer
+	for {
+		chunk, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		for _, call := range chunk.ToolCalls {
+			if strings.Contains(call.Arguments, "[[") {
+				t.Fatalf("partial placeholder reached the caller: %+v", chunk)
+			}
+		}
+		buffer.Add(chunk.ToolCalls)
+	}
+	if calls := buffer.Calls(); len(calls) != 1 || calls[0].Arguments != `{"keyword":"li.si@example.com"}` {
+		t.Fatalf("calls=%+v", calls)
+	}
+}
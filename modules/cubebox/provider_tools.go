@@ -0,0 +1,79 @@
+package cubebox
+
+import (
+	"sort"
+	"strings"
+)
+
+// ProviderTool is a function definition offered to a provider; Parameters is a JSON Schema object.
+type ProviderTool struct {
+	Name        string
+	Description string
+	Parameters  map[string]any
+}
+
+// ProviderToolCallDelta is one streamed fragment of a function call. Providers send the id and name once
+// and the arguments JSON in pieces, all keyed by Index.
+type ProviderToolCallDelta struct {
+	Index     int
+	ID        string
+	Name      string
+	Arguments string
+}
+
+type ProviderToolCall struct {
+	ID        string
+	Name      string
+	Arguments string
+}
+
+// ProviderToolCallBuffer joins streamed tool-call fragments back into whole calls.
+type ProviderToolCallBuffer struct {
+	calls map[int]*ProviderToolCall
+	args  map[int]*strings.Builder
+}
+
+func (b *ProviderToolCallBuffer) Add(deltas []ProviderToolCallDelta) {
+	if len(deltas) == 0 {
+		return
+	}
+	if b.calls == nil {
+		b.calls = map[int]*ProviderToolCall{}
+		b.args = map[int]*strings.Builder{}
+	}
+	for _, delta := range deltas {
+		call, ok := b.calls[delta.Index]
+		if !ok {
+			call = &ProviderToolCall{}
+			b.calls[delta.Index] = call
+			b.args[delta.Index] = &strings.Builder{}
+		}
+		if delta.ID != "" {
+			call.ID = delta.ID
+		}
+		if delta.Name != "" {
+			call.Name = delta.Name
+		}
+		b.args[delta.Index].WriteString(delta.Arguments)
+	}
+}
+
+func (b *ProviderToolCallBuffer) Len() int {
+	return len(b.calls)
+}
+
+// Calls returns the joined calls in index order.
+func (b *ProviderToolCallBuffer) Calls() []ProviderToolCall {
+	indexes := make([]int, 0, len(b.calls))
+	for index := range b.calls {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	out := make([]ProviderToolCall, 0, len(indexes))
+	for _, index := range indexes {
+		call := *b.calls[index]
+		call.Arguments = b.args[index].String()
+		out = append(out, call)
+	}
+	return out
+}
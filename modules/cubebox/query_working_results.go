@@ -181,11 +181,9 @@ func (s *QueryWorkingResultsState) AppendPlan(round int, plan APICallPlan, resul
 		Round: round,
 		Steps: make([]QueryCompletedPlanStep, 0, len(plan.Calls)),
 	}
+	results = alignResultsToPlan(plan, results)
 	for index, call := range plan.Calls {
-		var result ExecuteResult
-		if index < len(results) {
-			result = results[index]
-		}
+		result := results[index]
 		fingerprint := StepFingerprint(call)
 		s.executed[fingerprint] = struct{}{}
 		s.executedOrder = appendIfMissing(s.executedOrder, fingerprint)
@@ -207,6 +205,28 @@ func (s *QueryWorkingResultsState) AppendPlan(round int, plan APICallPlan, resul
 	s.completed = append(s.completed, completed)
 }
 
+// alignResultsToPlan returns one result per plan call in plan order. Results are matched by StepID when the
+// executor set it and by position otherwise, so steps that ran concurrently still merge deterministically.
+func alignResultsToPlan(plan APICallPlan, results []ExecuteResult) []ExecuteResult {
+	aligned := make([]ExecuteResult, len(plan.Calls))
+	byStepID := make(map[string]ExecuteResult, len(results))
+	for _, result := range results {
+		if stepID := strings.TrimSpace(result.StepID); stepID != "" {
+			byStepID[stepID] = result
+		}
+	}
+	for index, call := range plan.Calls {
+		if result, ok := byStepID[strings.TrimSpace(call.ID)]; ok {
+			aligned[index] = result
+			continue
+		}
+		if index < len(results) && strings.TrimSpace(results[index].StepID) == "" {
+			aligned[index] = results[index]
+		}
+	}
+	return aligned
+}
+
 func (s *QueryWorkingResultsState) Snapshot() QueryWorkingResults {
 	if s == nil {
 		return QueryWorkingResults{
@@ -100,6 +100,31 @@ func TestQueryWorkingResultsAppendPlanBuildsObservationLedger(t *testing.T) {
 	}
 }
 
+func TestQueryWorkingResultsAppendPlanMergesByStepID(t *testing.T) {
+	plan := cubebox.APICallPlan{Calls: []cubebox.APICallStep{
+		{ID: "step-1", Method: "GET", Path: "/org/api/org-units", Params: map[string]any{"parent_org_code": "A"}, DependsOn: []string{}},
+		{ID: "step-2", Method: "GET", Path: "/org/api/org-units", Params: map[string]any{"parent_org_code": "B"}, DependsOn: []string{}},
+	}}
+	inOrder := cubebox.NewQueryWorkingResultsState("compare", cubebox.DefaultQueryLoopBudget())
+	inOrder.AppendPlan(1, plan, []cubebox.ExecuteResult{
+		{StepID: "step-1", OperationID: "op-a", Payload: map[string]any{"org_units": []any{"a1"}}},
+		{StepID: "step-2", OperationID: "op-b", Payload: map[string]any{"org_units": []any{"b1", "b2"}}},
+	})
+	reversed := cubebox.NewQueryWorkingResultsState("compare", cubebox.DefaultQueryLoopBudget())
+	reversed.AppendPlan(1, plan, []cubebox.ExecuteResult{
+		{StepID: "step-2", OperationID: "op-b", Payload: map[string]any{"org_units": []any{"b1", "b2"}}},
+		{StepID: "step-1", OperationID: "op-a", Payload: map[string]any{"org_units": []any{"a1"}}},
+	})
+	left, _ := json.Marshal(inOrder.Snapshot())
+	right, _ := json.Marshal(reversed.Snapshot())
+	if string(left) != string(right) {
+		t.Fatalf("left=%s right=%s", left, right)
+	}
+	if steps := inOrder.Snapshot().CompletedPlans[0].Steps; steps[0].OperationID != "op-a" || steps[1].ItemCount != 2 {
+		t.Fatalf("steps=%+v", steps)
+	}
+}
+
 func TestQueryWorkingResultsRepeatDetectionUsesBudget(t *testing.T) {
 	state := cubebox.NewQueryWorkingResultsState("查组织树", cubebox.QueryLoopBudget{
 		MaxPlanningRounds:     4,
@@ -0,0 +1,469 @@
+package cubebox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrRedactionPolicyInvalid = errors.New("CUBEBOX_REDACTION_POLICY_INVALID")
+
+const (
+	RedactionKindPernr    = "pernr"
+	RedactionKindEmail    = "email"
+	RedactionKindPhone    = "phone"
+	RedactionKindIdentity = "identity"
+	// RedactionKindExtField is not a policy kind; it labels values taken from the policy's ExtFields.
+	RedactionKindExtField = "ext_field"
+
+	redactionPlaceholderMaxLen = 32
+	redactionKnownValueMinLen  = 4
+)
+
+var redactionPolicyKinds = []string{RedactionKindEmail, RedactionKindIdentity, RedactionKindPernr, RedactionKindPhone}
+
+var redactionPlaceholderTags = map[string]string{
+	RedactionKindPernr:    "PERNR",
+	RedactionKindEmail:    "EMAIL",
+	RedactionKindPhone:    "PHONE",
+	RedactionKindIdentity: "ID",
+	RedactionKindExtField: "EXT",
+}
+
+var (
+	redactionExtFieldKeyRe  = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+	redactionPlaceholderRe  = regexp.MustCompile(`\[\[[A-Z]+_[0-9]+\]\]`)
+	redactionJSONFieldRe    = regexp.MustCompile(`"([A-Za-z0-9_]+)"\s*:\s*"([^"\\]*)"`)
+	redactionExtFieldItemRe = regexp.MustCompile(`\{[^{}]*"field_key"\s*:\s*"([^"\\]*)"[^{}]*\}`)
+	redactionExtValueRe     = regexp.MustCompile(`"(value|display_value)"\s*:\s*"([^"\\]*)"`)
+	redactionPernrLabelRe   = regexp.MustCompile(`(?i)(?:工号|pernr)[\s:：=#]*(?:为|是)?\s*([0-9]{1,8})`)
+	redactionEmailRe        = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+	redactionPhoneRe        = regexp.MustCompile(`\+[0-9]{1,3}[ \-]?[0-9]{6,14}|1[3-9][0-9]{9}`)
+)
+
+// RedactionPolicy decides which values a tenant's prompts are stripped of before they reach a model
+// provider. Tenants without a stored policy get DefaultRedactionPolicy.
+type RedactionPolicy struct {
+	Enabled   bool     `json:"enabled"`
+	Kinds     []string `json:"kinds"`
+	ExtFields []string `json:"ext_fields"`
+	UpdatedAt string   `json:"updated_at,omitempty"`
+	UpdatedBy string   `json:"updated_by,omitempty"`
+}
+
+func DefaultRedactionPolicy() RedactionPolicy {
+	return RedactionPolicy{
+		Enabled:   true,
+		Kinds:     append([]string(nil), redactionPolicyKinds...),
+		ExtFields: []string{},
+	}
+}
+
+// NormalizeRedactionPolicy validates kinds and ext field keys and returns them sorted and deduplicated.
+func NormalizeRedactionPolicy(policy RedactionPolicy) (RedactionPolicy, error) {
+	kinds, err := normalizeRedactionList(policy.Kinds, func(kind string) bool {
+		_, ok := redactionPlaceholderTags[kind]
+		return ok && kind != RedactionKindExtField
+	})
+	if err != nil {
+		return RedactionPolicy{}, err
+	}
+	extFields, err := normalizeRedactionList(policy.ExtFields, redactionExtFieldKeyRe.MatchString)
+	if err != nil {
+		return RedactionPolicy{}, err
+	}
+	policy.Kinds = kinds
+	policy.ExtFields = extFields
+	return policy, nil
+}
+
+func normalizeRedactionList(values []string, valid func(string) bool) ([]string, error) {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if !valid(value) {
+			return nil, ErrRedactionPolicyInvalid
+		}
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		out = append(out, value)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+type RedactionPolicyAuditEntry struct {
+	ID        int64           `json:"id"`
+	ChangedBy string          `json:"changed_by"`
+	ChangedAt string          `json:"changed_at"`
+	Policy    RedactionPolicy `json:"policy"`
+}
+
+// Redactor replaces sensitive values with placeholders such as [[PERNR_1]] and restores them in model
+// output. It is created per turn, so a value keeps the same placeholder across every provider call of the
+// turn (planner rounds, narration) and the model can refer back to it.
+type Redactor struct {
+	mu            sync.Mutex
+	enabled       bool
+	kinds         map[string]bool
+	extFields     map[string]bool
+	byValue       map[string]string
+	byPlaceholder map[string]string
+	kindOf        map[string]string
+	counters      map[string]int
+	order         []string
+}
+
+type RedactionReplacement struct {
+	Placeholder string `json:"placeholder"`
+	Kind        string `json:"kind"`
+	Value       string `json:"value"`
+}
+
+type RedactionPreview struct {
+	Text         string                 `json:"text"`
+	Replacements []RedactionReplacement `json:"replacements"`
+}
+
+// NewRedactor builds the redactor for one turn. With identity redaction on, the tenant and principal IDs of
+// the request are registered up front, because the canonical context sends them as plain values.
+func NewRedactor(policy RedactionPolicy, request GatewayStreamRequest) *Redactor {
+	r := &Redactor{
+		enabled:       policy.Enabled,
+		kinds:         map[string]bool{},
+		extFields:     map[string]bool{},
+		byValue:       map[string]string{},
+		byPlaceholder: map[string]string{},
+		kindOf:        map[string]string{},
+		counters:      map[string]int{},
+	}
+	for _, kind := range policy.Kinds {
+		r.kinds[kind] = true
+	}
+	for _, key := range policy.ExtFields {
+		r.extFields[key] = true
+	}
+	if r.enabled && r.kinds[RedactionKindIdentity] {
+		for _, value := range []string{request.TenantID, request.PrincipalID} {
+			if strings.TrimSpace(value) != "" {
+				r.placeholderFor(RedactionKindIdentity, strings.TrimSpace(value))
+			}
+		}
+	}
+	return r
+}
+
+// Redact returns text with every sensitive value replaced. JSON fields are handled first so that values
+// learned there (a pernr, an ext field value) are also caught where they reappear in free text.
+func (r *Redactor) Redact(text string) string {
+	if r == nil || !r.enabled || text == "" {
+		return text
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	text = redactionJSONFieldRe.ReplaceAllStringFunc(text, func(match string) string {
+		parts := redactionJSONFieldRe.FindStringSubmatch(match)
+		kind := r.fieldKind(parts[1])
+		if kind == "" || parts[2] == "" || redactionPlaceholderRe.MatchString(parts[2]) {
+			return match
+		}
+		return replaceJSONValue(match, parts[2], r.placeholderFor(kind, parts[2]))
+	})
+	if len(r.extFields) > 0 {
+		text = redactionExtFieldItemRe.ReplaceAllStringFunc(text, func(item string) string {
+			if !r.extFields[redactionExtFieldItemRe.FindStringSubmatch(item)[1]] {
+				return item
+			}
+			return redactionExtValueRe.ReplaceAllStringFunc(item, func(match string) string {
+				parts := redactionExtValueRe.FindStringSubmatch(match)
+				if parts[2] == "" || redactionPlaceholderRe.MatchString(parts[2]) {
+					return match
+				}
+				return replaceJSONValue(match, parts[2], r.placeholderFor(RedactionKindExtField, parts[2]))
+			})
+		})
+	}
+	if r.kinds[RedactionKindPernr] {
+		text = r.replaceGroup(text, redactionPernrLabelRe, RedactionKindPernr)
+	}
+	if r.kinds[RedactionKindEmail] {
+		text = r.replaceGroup(text, redactionEmailRe, RedactionKindEmail)
+	}
+	if r.kinds[RedactionKindPhone] {
+		text = r.replaceGroup(text, redactionPhoneRe, RedactionKindPhone)
+	}
+	for _, value := range r.order {
+		if len([]rune(value)) < redactionKnownValueMinLen {
+			continue
+		}
+		text = replaceWholeValue(text, value, r.byValue[value])
+	}
+	return text
+}
+
+// Restore puts the original values back in place of known placeholders; unknown ones are left as they are.
+func (r *Redactor) Restore(text string) string {
+	if r == nil || !strings.Contains(text, "[[") {
+		return text
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return redactionPlaceholderRe.ReplaceAllStringFunc(text, func(placeholder string) string {
+		if value, ok := r.byPlaceholder[placeholder]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// Preview redacts text and lists every placeholder the redactor has handed out, for the dry-run endpoint.
+func (r *Redactor) Preview(text string) RedactionPreview {
+	redacted := r.Redact(text)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := RedactionPreview{Text: redacted, Replacements: []RedactionReplacement{}}
+	for _, value := range r.order {
+		placeholder := r.byValue[value]
+		out.Replacements = append(out.Replacements, RedactionReplacement{Placeholder: placeholder, Kind: r.kindOf[placeholder], Value: value})
+	}
+	return out
+}
+
+// AnnotatePayload records how many distinct values of each kind were withheld from the provider, so the
+// turn.completed event shows what the policy did without storing the values themselves.
+func (r *Redactor) AnnotatePayload(payload map[string]any) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := map[string]any{}
+	for _, placeholder := range r.byValue {
+		kind := r.kindOf[placeholder]
+		n, _ := counts[kind].(int)
+		counts[kind] = n + 1
+	}
+	payload["redaction_enabled"] = r.enabled
+	payload["redacted_values"] = counts
+}
+
+func (r *Redactor) fieldKind(key string) string {
+	switch {
+	case r.extFields[key]:
+		return RedactionKindExtField
+	case r.kinds[RedactionKindPernr] && (key == "pernr" || strings.HasSuffix(key, "_pernr")):
+		return RedactionKindPernr
+	case r.kinds[RedactionKindEmail] && (key == "email" || strings.HasSuffix(key, "_email")):
+		return RedactionKindEmail
+	case r.kinds[RedactionKindPhone] && (key == "phone" || key == "mobile" || strings.HasSuffix(key, "_phone")):
+		return RedactionKindPhone
+	}
+	return ""
+}
+
+func (r *Redactor) placeholderFor(kind string, value string) string {
+	if placeholder, ok := r.byValue[value]; ok {
+		return placeholder
+	}
+	r.counters[kind]++
+	placeholder := "[[" + redactionPlaceholderTags[kind] + "_" + strconv.Itoa(r.counters[kind]) + "]]"
+	r.byValue[value] = placeholder
+	r.byPlaceholder[placeholder] = value
+	r.kindOf[placeholder] = kind
+	r.order = append(r.order, value)
+	return placeholder
+}
+
+// replaceGroup replaces the last submatch group of re (or the whole match) where it is not part of a
+// longer run of digits or letters.
+func (r *Redactor) replaceGroup(text string, re *regexp.Regexp, kind string) string {
+	matches := re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[len(m)-2], m[len(m)-1]
+		if start < 0 || !isRedactionBoundary(text, start, end) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(r.placeholderFor(kind, text[start:end]))
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// replaceJSONValue swaps the quoted value at the end of a `"key": "value"` match.
+func replaceJSONValue(match string, value string, placeholder string) string {
+	idx := strings.LastIndex(match, `"`+value+`"`)
+	return match[:idx] + `"` + placeholder + `"` + match[idx+len(value)+2:]
+}
+
+func replaceWholeValue(text string, value string, placeholder string) string {
+	var b strings.Builder
+	rest := text
+	offset := 0
+	for {
+		idx := strings.Index(rest, value)
+		if idx < 0 {
+			break
+		}
+		start := offset + idx
+		end := start + len(value)
+		if isRedactionBoundary(text, start, end) {
+			b.WriteString(text[offset:start])
+			b.WriteString(placeholder)
+		} else {
+			b.WriteString(text[offset:end])
+		}
+		offset = end
+		rest = text[offset:]
+	}
+	if offset == 0 {
+		return text
+	}
+	b.WriteString(text[offset:])
+	return b.String()
+}
+
+func isRedactionBoundary(text string, start int, end int) bool {
+	return (start == 0 || !isRedactionWordByte(text[start-1])) && (end == len(text) || !isRedactionWordByte(text[end]))
+}
+
+func isRedactionWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+type redactorContextKey struct{}
+
+func WithRedactor(ctx context.Context, redactor *Redactor) context.Context {
+	return context.WithValue(ctx, redactorContextKey{}, redactor)
+}
+
+func RedactorFromContext(ctx context.Context) *Redactor {
+	redactor, _ := ctx.Value(redactorContextKey{}).(*Redactor)
+	return redactor
+}
+
+// NewRedactingProviderAdapter redacts the messages of every stream opened under a context carrying a
+// Redactor and restores placeholders in the streamed deltas, so callers only ever see original values.
+func NewRedactingProviderAdapter(inner ProviderAdapter) ProviderAdapter {
+	return redactingProviderAdapter{inner: inner}
+}
+
+type redactingProviderAdapter struct {
+	inner ProviderAdapter
+}
+
+func (a redactingProviderAdapter) StreamChatCompletion(ctx context.Context, request ProviderChatRequest) (ProviderChatStream, error) {
+	redactor := RedactorFromContext(ctx)
+	if redactor == nil {
+		return a.inner.StreamChatCompletion(ctx, request)
+	}
+	messages := make([]PromptItem, len(request.Messages))
+	for i, item := range request.Messages {
+		messages[i] = PromptItem{Role: item.Role, Content: redactor.Redact(item.Content)}
+	}
+	request.Messages = messages
+	request.Input = redactor.Redact(request.Input)
+	stream, err := a.inner.StreamChatCompletion(ctx, request)
+	if err != nil {
+		return stream, err
+	}
+	return &restoringChatStream{inner: stream, redactor: redactor}, nil
+}
+
+// restoringChatStream holds back a trailing "[[..." until it is long enough to be a whole placeholder,
+// since providers split deltas at arbitrary points. Tool-call arguments are held back per call the same way.
+type restoringChatStream struct {
+	inner       ProviderChatStream
+	redactor    *Redactor
+	pending     string
+	pendingArgs map[int]string
+}
+
+func (s *restoringChatStream) Recv() (ProviderChatChunk, error) {
+	chunk, err := s.inner.Recv()
+	if err != nil {
+		if errors.Is(err, io.EOF) && (s.pending != "" || len(s.pendingArgs) > 0) {
+			flushed := ProviderChatChunk{Delta: s.redactor.Restore(s.pending), ToolCalls: s.flushPendingArgs()}
+			s.pending = ""
+			return flushed, nil
+		}
+		return chunk, err
+	}
+	text := s.pending + chunk.Delta
+	s.pending = ""
+	if !chunk.Done {
+		if cut := restoreHoldIndex(text); cut < len(text) {
+			s.pending = text[cut:]
+			text = text[:cut]
+		}
+	}
+	chunk.Delta = s.redactor.Restore(text)
+	if len(chunk.ToolCalls) > 0 {
+		calls := make([]ProviderToolCallDelta, len(chunk.ToolCalls))
+		for i, call := range chunk.ToolCalls {
+			args := s.pendingArgs[call.Index] + call.Arguments
+			delete(s.pendingArgs, call.Index)
+			if !chunk.Done {
+				if cut := restoreHoldIndex(args); cut < len(args) {
+					if s.pendingArgs == nil {
+						s.pendingArgs = map[int]string{}
+					}
+					s.pendingArgs[call.Index] = args[cut:]
+					args = args[:cut]
+				}
+			}
+			call.Arguments = s.redactor.Restore(args)
+			calls[i] = call
+		}
+		chunk.ToolCalls = calls
+	}
+	if chunk.Done {
+		chunk.ToolCalls = append(chunk.ToolCalls, s.flushPendingArgs()...)
+	}
+	return chunk, nil
+}
+
+func (s *restoringChatStream) flushPendingArgs() []ProviderToolCallDelta {
+	if len(s.pendingArgs) == 0 {
+		return nil
+	}
+	calls := make([]ProviderToolCallDelta, 0, len(s.pendingArgs))
+	for index, args := range s.pendingArgs {
+		calls = append(calls, ProviderToolCallDelta{Index: index, Arguments: s.redactor.Restore(args)})
+	}
+	s.pendingArgs = nil
+	return calls
+}
+
+func (s *restoringChatStream) Close() error {
+	return s.inner.Close()
+}
+
+func restoreHoldIndex(text string) int {
+	start := strings.LastIndex(text, "[[")
+	if start < 0 || strings.Contains(text[start:], "]]") {
+		if strings.HasSuffix(text, "[") {
+			return len(text) - 1
+		}
+		return len(text)
+	}
+	if len(text)-start > redactionPlaceholderMaxLen {
+		return len(text)
+	}
+	return start
+}
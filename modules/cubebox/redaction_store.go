@@ -0,0 +1,118 @@
+package cubebox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const redactionPolicyAuditLimit = 20
+
+// GetRedactionPolicy returns the tenant's stored policy, or DefaultRedactionPolicy when none is stored.
+func (s *Store) GetRedactionPolicy(ctx context.Context, tenantID string) (RedactionPolicy, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return RedactionPolicy{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+	var policy RedactionPolicy
+	var updatedAt time.Time
+	if err := tx.QueryRow(ctx, `
+SELECT enabled, kinds, ext_fields, COALESCE(updated_by::text, ''), updated_at
+FROM iam.cubebox_redaction_policies
+WHERE tenant_uuid = $1::uuid;
+`, tenantID).Scan(&policy.Enabled, &policy.Kinds, &policy.ExtFields, &policy.UpdatedBy, &updatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return DefaultRedactionPolicy(), tx.Commit(ctx)
+		}
+		return RedactionPolicy{}, err
+	}
+	policy.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+	return policy, tx.Commit(ctx)
+}
+
+// UpdateRedactionPolicy replaces the tenant's policy and appends the new version to the audit trail in the
+// same transaction.
+func (s *Store) UpdateRedactionPolicy(ctx context.Context, tenantID string, principalID string, input RedactionPolicy) (RedactionPolicy, error) {
+	policy, err := NormalizeRedactionPolicy(input)
+	if err != nil {
+		return RedactionPolicy{}, err
+	}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return RedactionPolicy{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	var updatedAt time.Time
+	if err := tx.QueryRow(ctx, `
+INSERT INTO iam.cubebox_redaction_policies (tenant_uuid, enabled, kinds, ext_fields, updated_by, updated_at)
+VALUES ($1::uuid, $2, $3, $4, $5::uuid, now())
+ON CONFLICT (tenant_uuid) DO UPDATE
+SET enabled = EXCLUDED.enabled,
+    kinds = EXCLUDED.kinds,
+    ext_fields = EXCLUDED.ext_fields,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = EXCLUDED.updated_at
+RETURNING updated_at;
+`, tenantID, policy.Enabled, policy.Kinds, policy.ExtFields, principalID).Scan(&updatedAt); err != nil {
+		return RedactionPolicy{}, err
+	}
+	policy.UpdatedBy = principalID
+	policy.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+	snapshot, err := json.Marshal(policy)
+	if err != nil {
+		return RedactionPolicy{}, err
+	}
+	if _, err := tx.Exec(ctx, `
+INSERT INTO iam.cubebox_redaction_policy_audit (tenant_uuid, changed_by, changed_at, policy)
+VALUES ($1::uuid, $2::uuid, $3, $4::jsonb);
+`, tenantID, principalID, updatedAt, snapshot); err != nil {
+		return RedactionPolicy{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return RedactionPolicy{}, err
+	}
+	return policy, nil
+}
+
+// ListRedactionPolicyAudit returns the most recent policy changes, newest first.
+func (s *Store) ListRedactionPolicyAudit(ctx context.Context, tenantID string) ([]RedactionPolicyAuditEntry, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+	rows, err := tx.Query(ctx, `
+SELECT id, changed_by::text, changed_at, policy
+FROM iam.cubebox_redaction_policy_audit
+WHERE tenant_uuid = $1::uuid
+ORDER BY id DESC
+LIMIT $2;
+`, tenantID, redactionPolicyAuditLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []RedactionPolicyAuditEntry{}
+	for rows.Next() {
+		var entry RedactionPolicyAuditEntry
+		var changedAt time.Time
+		var raw []byte
+		if err := rows.Scan(&entry.ID, &entry.ChangedBy, &changedAt, &raw); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &entry.Policy); err != nil {
+			return nil, err
+		}
+		entry.ChangedAt = changedAt.UTC().Format(time.RFC3339)
+		out = append(out, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, tx.Commit(ctx)
+}
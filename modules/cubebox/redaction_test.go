@@ -0,0 +1,185 @@
+package cubebox
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	redactionTestTenantID    = "00000000-0000-0000-0000-00000000000a"
+	redactionTestPrincipalID = "00000000-0000-0000-0000-00000000000b"
+)
+
+type redactionPolicyReaderStub struct {
+	policy RedactionPolicy
+	err    error
+}
+
+func (s redactionPolicyReaderStub) GetRedactionPolicy(context.Context, string) (RedactionPolicy, error) {
+	return s.policy, s.err
+}
+
+func redactionTestRequest() GatewayStreamRequest {
+	request := usageTestRequest()
+	request.TenantID = redactionTestTenantID
+	request.PrincipalID = redactionTestPrincipalID
+	return request
+}
+
+func TestRedactorReplacesSensitiveValuesAndRestoresThem(t *testing.T) {
+	policy := DefaultRedactionPolicy()
+	policy.ExtFields = []string{"x_cost_center"}
+	redactor := NewRedactor(policy, redactionTestRequest())
+
+	text := `{"tenant_id":"` + redactionTestTenantID + `","items":[{"pernr":"100023","manager_pernr":"200045","name":"张三","ext":{"x_cost_center":"CC-9001"}}],` +
+		`"ext_fields":[{"field_key":"x_cost_center","value":"CC-9001","display_value":"成本中心 9001"},{"field_key":"x_level","value":"L3"}]}` +
+		` 请联系 zhang.san@example.com 或 13812345678，工号 100023 的上级是 200045。`
+	redacted := redactor.Redact(text)
+	for _, secret := range []string{redactionTestTenantID, "100023", "200045", "CC-9001", "成本中心 9001", "zhang.san@example.com", "13812345678"} {
+		if strings.Contains(redacted, secret) {
+			t.Fatalf("%q leaked: %s", secret, redacted)
+		}
+	}
+	for _, kept := range []string{`"name":"张三"`, `"value":"L3"`, "[[PERNR_1]]", "[[PERNR_2]]", "[[EXT_1]]", "[[EMAIL_1]]", "[[PHONE_1]]", "[[ID_1]]"} {
+		if !strings.Contains(redacted, kept) {
+			t.Fatalf("expected %q in %s", kept, redacted)
+		}
+	}
+	// Placeholders are stable for the turn, so a second call and the model's answer line up.
+	if again := redactor.Redact("工号 100023"); again != "工号 [[PERNR_1]]" {
+		t.Fatalf("again=%q", again)
+	}
+	if restored := redactor.Restore(redacted); restored != text {
+		t.Fatalf("restored=%s", restored)
+	}
+	if got := redactor.Restore("[[PERNR_9]] [[summary]]"); got != "[[PERNR_9]] [[summary]]" {
+		t.Fatalf("unknown placeholders must be kept, got %q", got)
+	}
+
+	payload := map[string]any{}
+	redactor.AnnotatePayload(payload)
+	if counts := payload["redacted_values"].(map[string]any); counts[RedactionKindPernr] != 2 || counts[RedactionKindIdentity] != 2 || counts[RedactionKindExtField] != 2 {
+		t.Fatalf("payload=%+v", payload)
+	}
+
+	disabled := NewRedactor(RedactionPolicy{Kinds: []string{RedactionKindEmail}}, redactionTestRequest())
+	if got := disabled.Redact(text); got != text {
+		t.Fatalf("disabled policy redacted: %s", got)
+	}
+}
+
+func TestRedactingProviderAdapterRestoresPlaceholdersSplitAcrossDeltas(t *testing.T) {
+	redactor := NewRedactor(DefaultRedactionPolicy(), redactionTestRequest())
+	ctx := WithRedactor(context.Background(), redactor)
+	adapter := &providerAdapterStub{stream: &providerChunkStub{chunks: []ProviderChatChunk{
+		{Delta: "工号 [[PER"},
+		{Delta: "NR_1]] 的邮箱是 ["},
+		{Delta: "[EMAIL_1]]，数组 [1"},
+		{Delta: "] 结束 [[PERNR"},
+	}}}
+
+	stream, err := NewRedactingProviderAdapter(adapter).StreamChatCompletion(ctx, ProviderChatRequest{
+		Model:    "gpt-4.1",
+		Messages: []PromptItem{{Role: "user", Content: `查 {"pernr":"100023"} 和 li.si@example.com`}},
+	})
+	if err != nil {
+		t.Fatalf("StreamChatCompletion err=%v", err)
+	}
+	if got := adapter.lastRequest.Messages[0].Content; got != `查 {"pernr":"[[PERNR_1]]"} 和 [[EMAIL_1]]` {
+		t.Fatalf("provider saw %q", got)
+	}
+	var deltas []string
+	for {
+		chunk, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		deltas = append(deltas, chunk.Delta)
+	}
+	if got := strings.Join(deltas, ""); got != "工号 100023 的邮箱是 li.si@example.com，数组 [1] 结束 [[PERNR" {
+		t.Fatalf("deltas=%q", deltas)
+	}
+	for _, delta := range deltas[:len(deltas)-1] {
+		if strings.Contains(delta, "[[") {
+			t.Fatalf("partial placeholder reached the caller: %q", deltas)
+		}
+	}
+
+	plain := &providerAdapterStub{stream: &providerChunkStub{}}
+	if _, err := NewRedactingProviderAdapter(plain).StreamChatCompletion(context.Background(), ProviderChatRequest{Input: "13812345678"}); err != nil || plain.lastRequest.Input != "13812345678" {
+		t.Fatalf("without a redactor the request must pass through, got %+v err=%v", plain.lastRequest, err)
+	}
+}
+
+func TestGatewayServiceAdmitTurnAttachesRedactor(t *testing.T) {
+	adapter := &providerAdapterStub{stream: &providerChunkStub{}}
+	service := usageTestGateway(adapter, &usageStoreStub{}).WithRedactionPolicies(redactionPolicyReaderStub{policy: DefaultRedactionPolicy()})
+	ctx, admitted := service.AdmitTurn(context.Background(), redactionTestRequest(), &appendEventStoreStub{}, &eventSinkStub{})
+	if !admitted || RedactorFromContext(ctx) == nil || UsageMeterFromContext(ctx) == nil {
+		t.Fatalf("admitted=%v", admitted)
+	}
+
+	service.WithRedactionPolicies(redactionPolicyReaderStub{err: errors.New("db down")})
+	store := &appendEventStoreStub{}
+	if _, admitted := service.AdmitTurn(context.Background(), redactionTestRequest(), store, &eventSinkStub{}); admitted {
+		t.Fatal("expected turn to be refused without a redaction policy")
+	}
+	if types := collectEventTypes(store.events); !reflect.DeepEqual(types, []string{"turn.error", "turn.completed"}) || store.events[0].Payload["code"] != "cubebox_turn_stream_failed" {
+		t.Fatalf("events=%+v", store.events)
+	}
+}
+
+func TestNormalizeRedactionPolicy(t *testing.T) {
+	policy, err := NormalizeRedactionPolicy(RedactionPolicy{Enabled: true, Kinds: []string{" phone", "email", "phone"}, ExtFields: []string{"x_b", "x_a"}})
+	if err != nil || !reflect.DeepEqual(policy.Kinds, []string{"email", "phone"}) || !reflect.DeepEqual(policy.ExtFields, []string{"x_a", "x_b"}) {
+		t.Fatalf("policy=%+v err=%v", policy, err)
+	}
+	for _, bad := range []RedactionPolicy{
+		{Kinds: []string{"ext_field"}},
+		{Kinds: []string{"salary"}},
+		{ExtFields: []string{"X-Bad"}},
+	} {
+		if _, err := NormalizeRedactionPolicy(bad); !errors.Is(err, ErrRedactionPolicyInvalid) {
+			t.Fatalf("policy=%+v err=%v", bad, err)
+		}
+	}
+}
+
+func TestStoreRedactionPolicyQueries(t *testing.T) {
+	updatedAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	tx := &txStub{
+		rowQueue: []pgx.Row{
+			rowStub{err: pgx.ErrNoRows},
+			rowStub{vals: []any{updatedAt}},
+		},
+		rowsQueue: []pgx.Rows{
+			&rowsStub{rows: [][]any{{int64(3), redactionTestPrincipalID, updatedAt, []byte(`{"enabled":false,"kinds":["email"],"ext_fields":[]}`)}}},
+		},
+	}
+	store := NewStore(tx)
+
+	policy, err := store.GetRedactionPolicy(context.Background(), redactionTestTenantID)
+	if err != nil || !reflect.DeepEqual(policy, DefaultRedactionPolicy()) {
+		t.Fatalf("policy=%+v err=%v", policy, err)
+	}
+	if _, err := store.UpdateRedactionPolicy(context.Background(), redactionTestTenantID, redactionTestPrincipalID, RedactionPolicy{Kinds: []string{"salary"}}); !errors.Is(err, ErrRedactionPolicyInvalid) {
+		t.Fatalf("err=%v", err)
+	}
+	saved, err := store.UpdateRedactionPolicy(context.Background(), redactionTestTenantID, redactionTestPrincipalID, RedactionPolicy{Kinds: []string{"email"}})
+	if err != nil || saved.UpdatedBy != redactionTestPrincipalID || saved.UpdatedAt != "2026-10-19T09:00:00Z" {
+		t.Fatalf("saved=%+v err=%v", saved, err)
+	}
+	if len(tx.execSQLs) != 1 || !strings.Contains(tx.execSQLs[0], "INSERT INTO iam.cubebox_redaction_policy_audit") {
+		t.Fatalf("exec=%v", tx.execSQLs)
+	}
+	audit, err := store.ListRedactionPolicyAudit(context.Background(), redactionTestTenantID)
+	if err != nil || len(audit) != 1 || audit[0].ID != 3 || audit[0].Policy.Enabled || !reflect.DeepEqual(audit[0].Policy.Kinds, []string{"email"}) {
+		t.Fatalf("audit=%+v err=%v", audit, err)
+	}
+}
@@ -0,0 +1,99 @@
+package cubebox
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrRetentionPolicyInvalid = errors.New("CUBEBOX_RETENTION_POLICY_INVALID")
+
+const (
+	retentionMaxDays                = 3650
+	defaultRetentionInterval        = time.Hour
+	defaultRetentionBatchSize int32 = 500
+)
+
+// RetentionPolicy purges a tenant's archived conversations, with their events, once they have been archived
+// for ArchivedRetentionDays. Zero keeps them forever, which is also the default without a stored policy.
+// Active conversations are never purged.
+type RetentionPolicy struct {
+	ArchivedRetentionDays int    `json:"archived_retention_days"`
+	UpdatedAt             string `json:"updated_at,omitempty"`
+	UpdatedBy             string `json:"updated_by,omitempty"`
+}
+
+func ValidateRetentionPolicy(policy RetentionPolicy) error {
+	if policy.ArchivedRetentionDays < 0 || policy.ArchivedRetentionDays > retentionMaxDays {
+		return ErrRetentionPolicyInvalid
+	}
+	return nil
+}
+
+// RetentionRun is the audit record of one purge run for one tenant.
+type RetentionRun struct {
+	ID                    int64  `json:"id"`
+	TenantID              string `json:"-"`
+	RanAt                 string `json:"ran_at"`
+	Cutoff                string `json:"cutoff"`
+	ArchivedRetentionDays int    `json:"archived_retention_days"`
+	PurgedConversations   int64  `json:"purged_conversations"`
+	PurgedEvents          int64  `json:"purged_events"`
+}
+
+type RetentionPurger interface {
+	PurgeExpiredConversations(ctx context.Context, now time.Time, batchSize int32) ([]RetentionRun, error)
+}
+
+// RetentionWorker applies every tenant's retention policy on a fixed interval. Each pass deletes at most
+// BatchSize conversations per tenant; a tenant with a backlog is drained over the following passes.
+type RetentionWorker struct {
+	Purger    RetentionPurger
+	Interval  time.Duration
+	BatchSize int32
+	Now       func() time.Time
+	OnError   func(error)
+}
+
+// Run purges immediately and then once per Interval until ctx is done.
+func (w *RetentionWorker) Run(ctx context.Context) error {
+	if w.Purger == nil {
+		return errors.New("retention worker not configured")
+	}
+	for {
+		if _, err := w.RunOnce(ctx); err != nil && ctx.Err() == nil && w.OnError != nil {
+			w.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(w.interval()):
+		}
+	}
+}
+
+// RunOnce runs one purge pass and returns the runs that deleted something.
+func (w *RetentionWorker) RunOnce(ctx context.Context) ([]RetentionRun, error) {
+	return w.Purger.PurgeExpiredConversations(ctx, w.now(), w.batchSize())
+}
+
+func (w *RetentionWorker) interval() time.Duration {
+	if w.Interval > 0 {
+		return w.Interval
+	}
+	return defaultRetentionInterval
+}
+
+func (w *RetentionWorker) batchSize() int32 {
+	if w.BatchSize > 0 {
+		return w.BatchSize
+	}
+	return defaultRetentionBatchSize
+}
+
+func (w *RetentionWorker) now() time.Time {
+	if w.Now != nil {
+		return w.Now().UTC()
+	}
+	return time.Now().UTC()
+}
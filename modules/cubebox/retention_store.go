@@ -0,0 +1,210 @@
+package cubebox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const retentionRunListLimit = 20
+
+func (s *Store) GetRetentionPolicy(ctx context.Context, tenantID string) (RetentionPolicy, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return RetentionPolicy{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+	var policy RetentionPolicy
+	var updatedAt time.Time
+	if err := tx.QueryRow(ctx, `
+SELECT archived_retention_days, COALESCE(updated_by::text, ''), updated_at
+FROM iam.cubebox_retention_policies
+WHERE tenant_uuid = $1::uuid;
+`, tenantID).Scan(&policy.ArchivedRetentionDays, &policy.UpdatedBy, &updatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return RetentionPolicy{}, tx.Commit(ctx)
+		}
+		return RetentionPolicy{}, err
+	}
+	policy.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+	return policy, tx.Commit(ctx)
+}
+
+func (s *Store) UpsertRetentionPolicy(ctx context.Context, tenantID string, principalID string, input RetentionPolicy) (RetentionPolicy, error) {
+	if err := ValidateRetentionPolicy(input); err != nil {
+		return RetentionPolicy{}, err
+	}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return RetentionPolicy{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+	var updatedAt time.Time
+	if err := tx.QueryRow(ctx, `
+INSERT INTO iam.cubebox_retention_policies (tenant_uuid, archived_retention_days, updated_by, updated_at)
+VALUES ($1::uuid, $2, $3::uuid, now())
+ON CONFLICT (tenant_uuid) DO UPDATE
+SET archived_retention_days = EXCLUDED.archived_retention_days,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = EXCLUDED.updated_at
+RETURNING updated_at;
+`, tenantID, input.ArchivedRetentionDays, principalID).Scan(&updatedAt); err != nil {
+		return RetentionPolicy{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return RetentionPolicy{}, err
+	}
+	return RetentionPolicy{
+		ArchivedRetentionDays: input.ArchivedRetentionDays,
+		UpdatedBy:             principalID,
+		UpdatedAt:             updatedAt.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// ListRetentionRuns returns the most recent purge runs of the tenant, newest first.
+func (s *Store) ListRetentionRuns(ctx context.Context, tenantID string) ([]RetentionRun, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+	rows, err := tx.Query(ctx, `
+SELECT id, ran_at, cutoff, archived_retention_days, purged_conversations, purged_events
+FROM iam.cubebox_retention_runs
+WHERE tenant_uuid = $1::uuid
+ORDER BY id DESC
+LIMIT $2;
+`, tenantID, retentionRunListLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []RetentionRun{}
+	for rows.Next() {
+		var run RetentionRun
+		var ranAt, cutoff time.Time
+		if err := rows.Scan(&run.ID, &ranAt, &cutoff, &run.ArchivedRetentionDays, &run.PurgedConversations, &run.PurgedEvents); err != nil {
+			return nil, err
+		}
+		run.TenantID = tenantID
+		run.RanAt = ranAt.UTC().Format(time.RFC3339)
+		run.Cutoff = cutoff.UTC().Format(time.RFC3339)
+		out = append(out, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, tx.Commit(ctx)
+}
+
+// PurgeExpiredConversations applies every tenant's retention policy. Each tenant is purged in its own
+// transaction together with its audit row, so a failing tenant does not hold back the others; the first
+// error is returned after all tenants were tried. Runs that delete nothing are not recorded.
+func (s *Store) PurgeExpiredConversations(ctx context.Context, now time.Time, batchSize int32) ([]RetentionRun, error) {
+	policies, err := s.listRetentionPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	runs := []RetentionRun{}
+	var firstErr error
+	for tenantID, days := range policies {
+		run, err := s.purgeTenantConversations(ctx, tenantID, days, now, batchSize)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if run.PurgedConversations > 0 {
+			runs = append(runs, run)
+		}
+	}
+	return runs, firstErr
+}
+
+func (s *Store) listRetentionPolicies(ctx context.Context) (map[string]int, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+	rows, err := tx.Query(ctx, `
+SELECT tenant_uuid::text, archived_retention_days
+FROM iam.cubebox_retention_policies
+WHERE archived_retention_days > 0;
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	policies := map[string]int{}
+	for rows.Next() {
+		var tenantID string
+		var days int
+		if err := rows.Scan(&tenantID, &days); err != nil {
+			return nil, err
+		}
+		policies[tenantID] = days
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return policies, tx.Commit(ctx)
+}
+
+func (s *Store) purgeTenantConversations(ctx context.Context, tenantID string, days int, now time.Time, batchSize int32) (RetentionRun, error) {
+	run := RetentionRun{
+		TenantID:              tenantID,
+		ArchivedRetentionDays: days,
+		RanAt:                 now.UTC().Format(time.RFC3339),
+	}
+	cutoff := now.UTC().AddDate(0, 0, -days)
+	run.Cutoff = cutoff.Format(time.RFC3339)
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return RetentionRun{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+	// Events and search documents go with their conversation through ON DELETE CASCADE; the event count is
+	// taken from the same snapshot the delete runs against.
+	if err := tx.QueryRow(ctx, `
+WITH doomed AS (
+  SELECT conversation_id
+  FROM iam.cubebox_conversations
+  WHERE tenant_uuid = $1::uuid
+    AND archived
+    AND archived_at < $2
+  ORDER BY archived_at ASC
+  LIMIT $3
+  FOR UPDATE SKIP LOCKED
+), doomed_events AS (
+  SELECT count(*)::bigint AS n
+  FROM iam.cubebox_conversation_events e
+  JOIN doomed d ON d.conversation_id = e.conversation_id
+  WHERE e.tenant_uuid = $1::uuid
+), deleted AS (
+  DELETE FROM iam.cubebox_conversations c
+  USING doomed d
+  WHERE c.tenant_uuid = $1::uuid
+    AND c.conversation_id = d.conversation_id
+  RETURNING 1
+)
+SELECT (SELECT count(*)::bigint FROM deleted), (SELECT n FROM doomed_events);
+`, tenantID, cutoff, batchSize).Scan(&run.PurgedConversations, &run.PurgedEvents); err != nil {
+		return RetentionRun{}, err
+	}
+	if run.PurgedConversations == 0 {
+		return run, tx.Commit(ctx)
+	}
+	if err := tx.QueryRow(ctx, `
+INSERT INTO iam.cubebox_retention_runs (tenant_uuid, ran_at, cutoff, archived_retention_days, purged_conversations, purged_events)
+VALUES ($1::uuid, $2, $3, $4, $5, $6)
+RETURNING id;
+`, tenantID, now.UTC(), cutoff, days, run.PurgedConversations, run.PurgedEvents).Scan(&run.ID); err != nil {
+		return RetentionRun{}, err
+	}
+	return run, tx.Commit(ctx)
+}
@@ -0,0 +1,74 @@
+package cubebox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type retentionPurgerStub struct {
+	now       time.Time
+	batchSize int32
+}
+
+func (s *retentionPurgerStub) PurgeExpiredConversations(_ context.Context, now time.Time, batchSize int32) ([]RetentionRun, error) {
+	s.now = now
+	s.batchSize = batchSize
+	return nil, nil
+}
+
+func TestStorePurgeExpiredConversationsRecordsRun(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	tx := &txStub{
+		rowsQueue: []pgx.Rows{&rowsStub{rows: [][]any{{"tenant-1", 30}}}},
+		rowQueue: []pgx.Row{
+			rowStub{vals: []any{int64(2), int64(14)}},
+			rowStub{vals: []any{int64(7)}},
+		},
+	}
+	runs, err := NewStore(tx).PurgeExpiredConversations(context.Background(), now, 100)
+	if err != nil {
+		t.Fatalf("PurgeExpiredConversations err=%v", err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("runs=%+v", runs)
+	}
+	run := runs[0]
+	if run.ID != 7 || run.TenantID != "tenant-1" || run.PurgedConversations != 2 || run.PurgedEvents != 14 || run.Cutoff != "2026-09-19T09:00:00Z" {
+		t.Fatalf("run=%+v", run)
+	}
+
+	// A tenant with nothing to purge leaves no audit row.
+	tx.rowsQueue = []pgx.Rows{&rowsStub{rows: [][]any{{"tenant-1", 30}}}}
+	tx.rowQueue = []pgx.Row{rowStub{vals: []any{int64(0), int64(0)}}}
+	runs, err = NewStore(tx).PurgeExpiredConversations(context.Background(), now, 100)
+	if err != nil || len(runs) != 0 || len(tx.rowQueue) != 0 {
+		t.Fatalf("runs=%+v err=%v", runs, err)
+	}
+}
+
+func TestRetentionPolicyValidationAndWorkerDefaults(t *testing.T) {
+	for _, days := range []int{-1, retentionMaxDays + 1} {
+		if err := ValidateRetentionPolicy(RetentionPolicy{ArchivedRetentionDays: days}); !errors.Is(err, ErrRetentionPolicyInvalid) {
+			t.Fatalf("days=%d err=%v", days, err)
+		}
+	}
+	if _, err := NewStore(&txStub{}).UpsertRetentionPolicy(context.Background(), "tenant-1", "principal-1", RetentionPolicy{ArchivedRetentionDays: -5}); !errors.Is(err, ErrRetentionPolicyInvalid) {
+		t.Fatalf("err=%v", err)
+	}
+
+	purger := &retentionPurgerStub{}
+	worker := &RetentionWorker{Purger: purger, Now: func() time.Time { return time.Date(2026, 10, 19, 17, 0, 0, 0, time.FixedZone("UTC+8", 8*3600)) }}
+	if _, err := worker.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce err=%v", err)
+	}
+	if purger.batchSize != defaultRetentionBatchSize || !purger.now.Equal(time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)) || purger.now.Location() != time.UTC {
+		t.Fatalf("purger=%+v", purger)
+	}
+	if err := (&RetentionWorker{}).Run(context.Background()); err == nil {
+		t.Fatal("expected unconfigured worker to fail")
+	}
+}
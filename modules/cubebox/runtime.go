@@ -1,6 +1,7 @@
 package cubebox
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -52,7 +53,8 @@ type ConversationListItem struct {
 }
 
 type ConversationListResponse struct {
-	Items []ConversationListItem `json:"items"`
+	Items      []ConversationListItem `json:"items"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
 }
 
 type ModelProvider struct {
@@ -283,6 +285,23 @@ func (t DeterministicTurn) InterruptSignal() <-chan struct{} {
 	return t.interrupt
 }
 
+// WithInterrupt derives a context that is cancelled when the turn is interrupted, so work started for the
+// turn stops with it.
+func (t DeterministicTurn) WithInterrupt(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	if t.interrupt == nil {
+		return ctx, cancel
+	}
+	go func() {
+		select {
+		case <-t.interrupt:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
 func deterministicChunks(prompt string) []string {
 	reply := fmt.Sprintf("已收到你的消息：%s\n我正在整理回复。", prompt)
 	if strings.TrimSpace(prompt) == "" {
@@ -0,0 +1,448 @@
+package cubebox
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SecretVaultMasterKeyFileEnv names the file holding the vault master keys. Each non-comment line is
+// "<version> <base64 32-byte key>"; the highest version wraps new data keys and every listed version can
+// still unwrap, so a master key is rotated by appending a line, rewrapping, then dropping the old line.
+// Servers re-read the file when it changes, so neither edit needs a restart.
+const SecretVaultMasterKeyFileEnv = "CUBEBOX_VAULT_MASTER_KEY_FILE"
+
+const vaultSecretRefPrefix = "vault://"
+
+var ErrSecretVaultUnavailable = errors.New("CUBEBOX_SECRET_VAULT_UNAVAILABLE")
+var ErrSecretVaultKeyInvalid = errors.New("CUBEBOX_SECRET_VAULT_KEY_INVALID")
+
+// SecretVaultKeyring holds the master keys by version. A keyring loaded from a file re-reads it whenever
+// its modification time changes, so a rotated key file takes effect on running servers without a restart.
+type SecretVaultKeyring struct {
+	path string
+
+	mu      sync.RWMutex
+	modTime time.Time
+	keys    map[int][]byte
+	active  int
+}
+
+func NewSecretVaultKeyring(keys map[int][]byte) (*SecretVaultKeyring, error) {
+	ring := &SecretVaultKeyring{keys: make(map[int][]byte, len(keys))}
+	for version, key := range keys {
+		if version <= 0 || len(key) != 32 {
+			return nil, fmt.Errorf("%w: version %d", ErrSecretVaultKeyInvalid, version)
+		}
+		ring.keys[version] = append([]byte(nil), key...)
+		if version > ring.active {
+			ring.active = version
+		}
+	}
+	if ring.active == 0 {
+		return nil, fmt.Errorf("%w: no master key", ErrSecretVaultKeyInvalid)
+	}
+	return ring, nil
+}
+
+// LoadSecretVaultKeyringFile reads a master key file in the SecretVaultMasterKeyFileEnv format.
+func LoadSecretVaultKeyringFile(path string) (*SecretVaultKeyring, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	ring, err := parseSecretVaultKeyring(f)
+	if err != nil {
+		return nil, err
+	}
+	ring.path = path
+	ring.modTime = info.ModTime()
+	return ring, nil
+}
+
+// LoadSecretVaultKeyringFromEnv returns nil without error when no master key file is configured, which
+// leaves the vault disabled and env:// refs as the only working credentials.
+func LoadSecretVaultKeyringFromEnv() (*SecretVaultKeyring, error) {
+	path := strings.TrimSpace(os.Getenv(SecretVaultMasterKeyFileEnv))
+	if path == "" {
+		return nil, nil
+	}
+	return LoadSecretVaultKeyringFile(path)
+}
+
+func parseSecretVaultKeyring(r io.Reader) (*SecretVaultKeyring, error) {
+	keys := map[int][]byte{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w: line %d", ErrSecretVaultKeyInvalid, line)
+		}
+		version, err := strconv.Atoi(strings.TrimPrefix(fields[0], "v"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d", ErrSecretVaultKeyInvalid, line)
+		}
+		key, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d", ErrSecretVaultKeyInvalid, line)
+		}
+		if _, dup := keys[version]; dup {
+			return nil, fmt.Errorf("%w: duplicate version %d", ErrSecretVaultKeyInvalid, version)
+		}
+		keys[version] = key
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewSecretVaultKeyring(keys)
+}
+
+func (k *SecretVaultKeyring) ActiveVersion() int {
+	_, active := k.current()
+	return active
+}
+
+// current returns the keys after reloading a changed key file. A file that cannot be read or parsed, for
+// example one caught halfway through an edit, leaves the loaded keys in place until the next call.
+func (k *SecretVaultKeyring) current() (map[int][]byte, int) {
+	if k.path != "" {
+		if info, err := os.Stat(k.path); err == nil {
+			k.mu.RLock()
+			changed := !info.ModTime().Equal(k.modTime)
+			k.mu.RUnlock()
+			if changed {
+				if next, err := LoadSecretVaultKeyringFile(k.path); err == nil {
+					k.mu.Lock()
+					k.keys, k.active, k.modTime = next.keys, next.active, next.modTime
+					k.mu.Unlock()
+				}
+			}
+		}
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys, k.active
+}
+
+func (k *SecretVaultKeyring) wrapDataKey(tenantID string, dataKey []byte) ([]byte, int, error) {
+	keys, active := k.current()
+	sealed, err := sealAESGCM(keys[active], dataKey, vaultDataKeyAAD(tenantID))
+	return sealed, active, err
+}
+
+func (k *SecretVaultKeyring) unwrapDataKey(tenantID string, wrapped []byte, version int) ([]byte, error) {
+	keys, _ := k.current()
+	key, ok := keys[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: master key version %d not loaded", ErrSecretVaultKeyInvalid, version)
+	}
+	dataKey, err := openAESGCM(key, wrapped, vaultDataKeyAAD(tenantID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSecretVaultKeyInvalid, err)
+	}
+	return dataKey, nil
+}
+
+// SecretVault stores model secrets encrypted under a per-tenant data key and resolves vault:// refs.
+// Other refs fall through to EnvSecretResolver, so existing env:// credentials keep working.
+type SecretVault struct {
+	pool     TxBeginner
+	keys     *SecretVaultKeyring
+	fallback SecretResolver
+	now      func() time.Time
+}
+
+// NewSecretVault returns a vault over pool. A nil keyring disables sealing and vault:// resolution.
+func NewSecretVault(pool TxBeginner, keys *SecretVaultKeyring) *SecretVault {
+	return &SecretVault{
+		pool:     pool,
+		keys:     keys,
+		fallback: EnvSecretResolver{},
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (v *SecretVault) Enabled() bool {
+	return v != nil && v.keys != nil
+}
+
+// ResolveSecretRef decrypts a vault:// ref and records the decrypt in the same transaction; the secret is
+// not returned unless the audit row commits.
+func (v *SecretVault) ResolveSecretRef(ctx context.Context, tenantID string, providerID string, secretRef string) (string, error) {
+	secretRef = strings.TrimSpace(secretRef)
+	if !strings.HasPrefix(secretRef, vaultSecretRefPrefix) {
+		return v.fallback.ResolveSecretRef(ctx, tenantID, providerID, secretRef)
+	}
+	secretID := strings.TrimSpace(strings.TrimPrefix(secretRef, vaultSecretRefPrefix))
+	if secretID == "" {
+		return "", ErrSecretRefInvalid
+	}
+	if !v.Enabled() {
+		return "", fmt.Errorf("%w: %w", ErrSecretMissing, ErrSecretVaultUnavailable)
+	}
+
+	tx, err := v.pool.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	var ciphertext, wrapped []byte
+	var keyVersion int
+	err = tx.QueryRow(ctx, `
+SELECT s.ciphertext, k.wrapped_key, k.master_key_version
+FROM iam.cubebox_vault_secrets s
+JOIN iam.cubebox_vault_data_keys k ON k.tenant_uuid = s.tenant_uuid
+WHERE s.tenant_uuid = $1::uuid
+  AND s.secret_id = $2;
+`, tenantID, secretID).Scan(&ciphertext, &wrapped, &keyVersion)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrSecretMissing
+		}
+		return "", err
+	}
+	dataKey, err := v.keys.unwrapDataKey(tenantID, wrapped, keyVersion)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := openAESGCM(dataKey, ciphertext, vaultSecretAAD(tenantID, secretID))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrSecretVaultKeyInvalid, err)
+	}
+	if _, err := tx.Exec(ctx, `
+INSERT INTO iam.cubebox_vault_decrypt_audit (tenant_uuid, secret_id, provider_id, master_key_version, decrypted_at)
+VALUES ($1::uuid, $2, $3, $4, $5);
+`, tenantID, secretID, strings.TrimSpace(providerID), keyVersion, v.now()); err != nil {
+		return "", err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// sealSecret encrypts value inside the caller's transaction and returns its vault:// ref, creating the
+// tenant data key on first use.
+func (v *SecretVault) sealSecret(ctx context.Context, tx pgx.Tx, tenantID string, providerID string, value string) (string, error) {
+	if !v.Enabled() {
+		return "", ErrSecretVaultUnavailable
+	}
+	dataKey, err := v.tenantDataKey(ctx, tx, tenantID)
+	if err != nil {
+		return "", err
+	}
+	secretID := "sec_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	ciphertext, err := sealAESGCM(dataKey, []byte(value), vaultSecretAAD(tenantID, secretID))
+	if err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(ctx, `
+INSERT INTO iam.cubebox_vault_secrets (tenant_uuid, secret_id, provider_id, ciphertext, created_at)
+VALUES ($1::uuid, $2, $3, $4, $5);
+`, tenantID, secretID, strings.TrimSpace(providerID), ciphertext, v.now()); err != nil {
+		return "", err
+	}
+	return vaultSecretRefPrefix + secretID, nil
+}
+
+func (v *SecretVault) tenantDataKey(ctx context.Context, tx pgx.Tx, tenantID string) ([]byte, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	wrapped, version, err := v.keys.wrapDataKey(tenantID, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	// A concurrent first write may win the insert; whichever row exists afterwards is the tenant's key.
+	if _, err := tx.Exec(ctx, `
+INSERT INTO iam.cubebox_vault_data_keys (tenant_uuid, wrapped_key, master_key_version, created_at)
+VALUES ($1::uuid, $2, $3, $4)
+ON CONFLICT (tenant_uuid) DO NOTHING;
+`, tenantID, wrapped, version, v.now()); err != nil {
+		return nil, err
+	}
+	var stored []byte
+	var storedVersion int
+	if err := tx.QueryRow(ctx, `
+SELECT wrapped_key, master_key_version
+FROM iam.cubebox_vault_data_keys
+WHERE tenant_uuid = $1::uuid;
+`, tenantID).Scan(&stored, &storedVersion); err != nil {
+		return nil, err
+	}
+	return v.keys.unwrapDataKey(tenantID, stored, storedVersion)
+}
+
+// RewrapSecretVaultDataKeys re-encrypts every tenant data key not yet under the keyring's active master key.
+// Data keys themselves do not change, so stored secrets stay readable throughout; each tenant is rewrapped
+// in its own short transaction to keep row locks brief while the server keeps resolving secrets.
+func RewrapSecretVaultDataKeys(ctx context.Context, pool TxBeginner, keys *SecretVaultKeyring) (int, error) {
+	if keys == nil {
+		return 0, ErrSecretVaultUnavailable
+	}
+	tenantIDs, err := listSecretVaultRewrapTenants(ctx, pool, keys.ActiveVersion())
+	if err != nil {
+		return 0, err
+	}
+	rewrapped := 0
+	for _, tenantID := range tenantIDs {
+		ok, err := rewrapSecretVaultDataKey(ctx, pool, keys, tenantID)
+		if err != nil {
+			return rewrapped, fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+		if ok {
+			rewrapped++
+		}
+	}
+	return rewrapped, nil
+}
+
+func listSecretVaultRewrapTenants(ctx context.Context, pool TxBeginner, activeVersion int) ([]string, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+	rows, err := tx.Query(ctx, `
+SELECT tenant_uuid::text
+FROM iam.cubebox_vault_data_keys
+WHERE master_key_version <> $1
+ORDER BY tenant_uuid ASC;
+`, activeVersion)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var tenantID string
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, err
+		}
+		out = append(out, tenantID)
+	}
+	return out, rows.Err()
+}
+
+func rewrapSecretVaultDataKey(ctx context.Context, pool TxBeginner, keys *SecretVaultKeyring, tenantID string) (bool, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	var wrapped []byte
+	var version int
+	if err := tx.QueryRow(ctx, `
+SELECT wrapped_key, master_key_version
+FROM iam.cubebox_vault_data_keys
+WHERE tenant_uuid = $1::uuid
+FOR UPDATE;
+`, tenantID).Scan(&wrapped, &version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	if version == keys.ActiveVersion() {
+		return false, nil
+	}
+	dataKey, err := keys.unwrapDataKey(tenantID, wrapped, version)
+	if err != nil {
+		return false, err
+	}
+	rewrapped, newVersion, err := keys.wrapDataKey(tenantID, dataKey)
+	if err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE iam.cubebox_vault_data_keys
+SET wrapped_key = $2,
+    master_key_version = $3,
+    rewrapped_at = now()
+WHERE tenant_uuid = $1::uuid;
+`, tenantID, rewrapped, newVersion); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// MaskSecretValue derives the display form of a submitted secret; short values reveal nothing.
+func MaskSecretValue(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) < 12 {
+		return "****"
+	}
+	prefix := value[:3]
+	if idx := strings.IndexAny(value, "-_"); idx > 0 && idx < 8 {
+		prefix = value[:idx+1]
+	}
+	return prefix + "****" + value[len(value)-4:]
+}
+
+func vaultDataKeyAAD(tenantID string) []byte {
+	return []byte("cubebox-vault:data-key:" + strings.TrimSpace(tenantID))
+}
+
+func vaultSecretAAD(tenantID string, secretID string) []byte {
+	return []byte("cubebox-vault:secret:" + strings.TrimSpace(tenantID) + ":" + secretID)
+}
+
+func sealAESGCM(key []byte, plaintext []byte, aad []byte) ([]byte, error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func openAESGCM(key []byte, sealed []byte, aad []byte) ([]byte, error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], aad)
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
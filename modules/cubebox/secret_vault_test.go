@@ -0,0 +1,203 @@
+package cubebox
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func testSecretVaultKeyring(t *testing.T, versions ...int) *SecretVaultKeyring {
+	t.Helper()
+	keys := map[int][]byte{}
+	for _, version := range versions {
+		keys[version] = bytes.Repeat([]byte{byte(version)}, 32)
+	}
+	ring, err := NewSecretVaultKeyring(keys)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	return ring
+}
+
+func TestParseSecretVaultKeyring(t *testing.T) {
+	key1 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	key2 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+	ring, err := parseSecretVaultKeyring(strings.NewReader("# master keys\n1 " + key1 + "\n\nv2 " + key2 + "\n"))
+	if err != nil || ring.ActiveVersion() != 2 || len(ring.keys) != 2 {
+		t.Fatalf("ring=%+v err=%v", ring, err)
+	}
+
+	for _, input := range []string{
+		"",
+		"1",
+		"x " + key1,
+		"1 not-base64!",
+		"1 " + base64.StdEncoding.EncodeToString([]byte("short")),
+		"0 " + key1,
+		"1 " + key1 + "\n1 " + key2,
+	} {
+		if _, err := parseSecretVaultKeyring(strings.NewReader(input)); !errors.Is(err, ErrSecretVaultKeyInvalid) {
+			t.Fatalf("input=%q err=%v", input, err)
+		}
+	}
+}
+
+func TestSecretVaultKeyringReloadsChangedFile(t *testing.T) {
+	key1 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	key2 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+	path := filepath.Join(t.TempDir(), "vault.keys")
+	if err := os.WriteFile(path, []byte("1 "+key1+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ring, err := LoadSecretVaultKeyringFile(path)
+	if err != nil || ring.ActiveVersion() != 1 {
+		t.Fatalf("ring=%+v err=%v", ring, err)
+	}
+
+	// Another process rotated first and wrapped a data key under version 2.
+	dataKey := bytes.Repeat([]byte{9}, 32)
+	wrapped, _, err := testSecretVaultKeyring(t, 1, 2).wrapDataKey("t1", dataKey)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := ring.unwrapDataKey("t1", wrapped, 2); !errors.Is(err, ErrSecretVaultKeyInvalid) {
+		t.Fatalf("err=%v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("1 "+key1+"\n2 "+key2+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	unwrapped, err := ring.unwrapDataKey("t1", wrapped, 2)
+	if err != nil || !bytes.Equal(unwrapped, dataKey) || ring.ActiveVersion() != 2 {
+		t.Fatalf("active=%d err=%v", ring.ActiveVersion(), err)
+	}
+
+	// A broken edit keeps the keys already loaded.
+	if err := os.WriteFile(path, []byte("2 not-base64!\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, later.Add(time.Minute), later.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ring.unwrapDataKey("t1", wrapped, 2); err != nil || ring.ActiveVersion() != 2 {
+		t.Fatalf("active=%d err=%v", ring.ActiveVersion(), err)
+	}
+}
+
+func TestSecretVaultSealAndResolveAuditsDecrypt(t *testing.T) {
+	ring := testSecretVaultKeyring(t, 1)
+	dataKey := bytes.Repeat([]byte{9}, 32)
+	wrapped, version, err := ring.wrapDataKey("t1", dataKey)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+
+	sealTx := &txStub{rowQueue: []pgx.Row{rowStub{vals: []any{wrapped, version}}}}
+	vault := NewSecretVault(sealTx, ring)
+	vault.now = func() time.Time { return time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC) }
+	ref, err := vault.sealSecret(context.Background(), sealTx, "t1", "openai", "sk-live-0123456789abcd")
+	if err != nil || !strings.HasPrefix(ref, vaultSecretRefPrefix) {
+		t.Fatalf("ref=%q err=%v", ref, err)
+	}
+	if len(sealTx.execArgs) != 2 || !strings.Contains(sealTx.execSQLs[1], "cubebox_vault_secrets") {
+		t.Fatalf("sqls=%v", sealTx.execSQLs)
+	}
+	ciphertext := sealTx.execArgs[1][3].([]byte)
+	if bytes.Contains(ciphertext, []byte("sk-live")) {
+		t.Fatal("secret stored in plaintext")
+	}
+
+	resolveTx := &txStub{rowQueue: []pgx.Row{rowStub{vals: []any{ciphertext, wrapped, version}}}}
+	vault.pool = resolveTx
+	got, err := vault.ResolveSecretRef(context.Background(), "t1", "openai", ref)
+	if err != nil || got != "sk-live-0123456789abcd" {
+		t.Fatalf("got=%q err=%v", got, err)
+	}
+	if len(resolveTx.execSQLs) != 1 || !strings.Contains(resolveTx.execSQLs[0], "cubebox_vault_decrypt_audit") || resolveTx.execArgs[0][1] != strings.TrimPrefix(ref, vaultSecretRefPrefix) {
+		t.Fatalf("sqls=%v args=%v", resolveTx.execSQLs, resolveTx.execArgs)
+	}
+
+	// A secret sealed for one tenant does not open under another tenant's id.
+	otherTx := &txStub{rowQueue: []pgx.Row{rowStub{vals: []any{ciphertext, wrapped, version}}}}
+	vault.pool = otherTx
+	if _, err := vault.ResolveSecretRef(context.Background(), "t2", "openai", ref); !errors.Is(err, ErrSecretVaultKeyInvalid) {
+		t.Fatalf("err=%v", err)
+	}
+
+	auditFailTx := &txStub{execErr: errors.New("audit down"), rowQueue: []pgx.Row{rowStub{vals: []any{ciphertext, wrapped, version}}}}
+	vault.pool = auditFailTx
+	if got, err := vault.ResolveSecretRef(context.Background(), "t1", "openai", ref); err == nil || got != "" {
+		t.Fatalf("got=%q err=%v", got, err)
+	}
+}
+
+func TestSecretVaultResolveFallbackAndDisabled(t *testing.T) {
+	t.Setenv("CUBEBOX_VAULT_TEST_KEY", "sk-env")
+	vault := NewSecretVault(nil, nil)
+	if got, err := vault.ResolveSecretRef(context.Background(), "t1", "openai", "env://CUBEBOX_VAULT_TEST_KEY"); err != nil || got != "sk-env" {
+		t.Fatalf("got=%q err=%v", got, err)
+	}
+	if _, err := vault.ResolveSecretRef(context.Background(), "t1", "openai", "vault://sec_1"); !errors.Is(err, ErrSecretMissing) || !errors.Is(err, ErrSecretVaultUnavailable) {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := vault.ResolveSecretRef(context.Background(), "t1", "openai", "vault://"); !errors.Is(err, ErrSecretRefInvalid) {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := vault.sealSecret(context.Background(), &txStub{}, "t1", "openai", "sk"); !errors.Is(err, ErrSecretVaultUnavailable) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestRewrapSecretVaultDataKey(t *testing.T) {
+	dataKey := bytes.Repeat([]byte{9}, 32)
+	wrapped, _, err := testSecretVaultKeyring(t, 1).wrapDataKey("t1", dataKey)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	rotated := testSecretVaultKeyring(t, 1, 2)
+
+	tx := &txStub{rowQueue: []pgx.Row{rowStub{vals: []any{wrapped, 1}}}}
+	ok, err := rewrapSecretVaultDataKey(context.Background(), tx, rotated, "t1")
+	if err != nil || !ok || len(tx.execArgs) != 1 {
+		t.Fatalf("ok=%v err=%v execs=%d", ok, err, len(tx.execArgs))
+	}
+	if tx.execArgs[0][2] != 2 {
+		t.Fatalf("version=%v", tx.execArgs[0][2])
+	}
+	unwrapped, err := testSecretVaultKeyring(t, 2).unwrapDataKey("t1", tx.execArgs[0][1].([]byte), 2)
+	if err != nil || !bytes.Equal(unwrapped, dataKey) {
+		t.Fatalf("err=%v", err)
+	}
+
+	current := &txStub{rowQueue: []pgx.Row{rowStub{vals: []any{tx.execArgs[0][1].([]byte), 2}}}}
+	if ok, err := rewrapSecretVaultDataKey(context.Background(), current, rotated, "t1"); err != nil || ok || len(current.execArgs) != 0 {
+		t.Fatalf("ok=%v err=%v", ok, err)
+	}
+	if _, err := RewrapSecretVaultDataKeys(context.Background(), &txStub{}, nil); !errors.Is(err, ErrSecretVaultUnavailable) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestMaskSecretValue(t *testing.T) {
+	for input, want := range map[string]string{
+		"sk-live-0123456789abcd": "sk-****abcd",
+		"abcdefghijklmnop":       "abc****mnop",
+		"short":                  "****",
+	} {
+		if got := MaskSecretValue(input); got != want {
+			t.Fatalf("input=%q got=%q want=%q", input, got, want)
+		}
+	}
+}
@@ -20,7 +20,8 @@ type TxBeginner interface {
 }
 
 type Store struct {
-	pool TxBeginner
+	pool  TxBeginner
+	vault *SecretVault
 }
 
 type ConversationSummary struct {
@@ -48,6 +49,8 @@ type RotateModelCredentialInput struct {
 	ProviderID   string
 	SecretRef    string
 	MaskedSecret string
+	// SecretValue, when set, is sealed into the secret vault; SecretRef and MaskedSecret are then derived.
+	SecretValue string
 }
 
 type SelectActiveModelInput struct {
@@ -66,6 +69,19 @@ func NewStore(pool TxBeginner) *Store {
 	return &Store{pool: pool}
 }
 
+// WithSecretVault lets credential rotation accept secret values and model verification resolve vault:// refs.
+func (s *Store) WithSecretVault(vault *SecretVault) *Store {
+	s.vault = vault
+	return s
+}
+
+func (s *Store) secretResolver() SecretResolver {
+	if s.vault != nil {
+		return s.vault
+	}
+	return EnvSecretResolver{}
+}
+
 func (s *Store) CreateConversation(ctx context.Context, tenantID string, principalID string) (ConversationReplayResponse, error) {
 	conversationID := "conv_" + strings.ReplaceAll(uuid.NewString(), "-", "")
 	now := time.Now().UTC()
@@ -170,43 +186,6 @@ func (s *Store) GetConversation(ctx context.Context, tenantID string, principalI
 	}, nil
 }
 
-func (s *Store) ListConversations(ctx context.Context, tenantID string, principalID string, limit int32) (ConversationListResponse, error) {
-	if limit <= 0 {
-		limit = 20
-	}
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		return ConversationListResponse{}, err
-	}
-	defer func() { _ = tx.Rollback(context.Background()) }()
-	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
-		return ConversationListResponse{}, err
-	}
-	rows, err := cubeboxsqlc.New(tx).ListConversations(ctx, cubeboxsqlc.ListConversationsParams{
-		Column1: uuidToPGType(tenantID),
-		Column2: uuidToPGType(principalID),
-		Limit:   limit,
-	})
-	if err != nil {
-		return ConversationListResponse{}, err
-	}
-	if err := tx.Commit(ctx); err != nil {
-		return ConversationListResponse{}, err
-	}
-
-	items := make([]ConversationListItem, 0, len(rows))
-	for _, row := range rows {
-		items = append(items, ConversationListItem{
-			ID:        row.ConversationID,
-			Title:     row.Title,
-			Status:    row.Status,
-			Archived:  row.Archived,
-			UpdatedAt: pgTime(row.UpdatedAt).Format(time.RFC3339),
-		})
-	}
-	return ConversationListResponse{Items: items}, nil
-}
-
 func (s *Store) RenameConversation(ctx context.Context, tenantID string, principalID string, conversationID string, title string) (ConversationReplayResponse, error) {
 	return s.updateConversationMetadata(ctx, tenantID, principalID, conversationID, conversationMetadataUpdate{
 		title:     strings.TrimSpace(title),
@@ -574,6 +553,18 @@ func (s *Store) RotateModelCredential(ctx context.Context, tenantID string, prin
 	if len(current) > 0 {
 		nextVersion = current[0].Version + 1
 	}
+	secretRef := strings.TrimSpace(input.SecretRef)
+	maskedSecret := strings.TrimSpace(input.MaskedSecret)
+	if secretValue := strings.TrimSpace(input.SecretValue); secretValue != "" {
+		if !s.vault.Enabled() {
+			return ModelCredential{}, ErrSecretVaultUnavailable
+		}
+		secretRef, err = s.vault.sealSecret(ctx, tx, tenantID, providerID, secretValue)
+		if err != nil {
+			return ModelCredential{}, err
+		}
+		maskedSecret = MaskSecretValue(secretValue)
+	}
 	now := time.Now().UTC()
 	if err := q.DeactivateProviderCredentials(ctx, cubeboxsqlc.DeactivateProviderCredentialsParams{
 		Column1:    uuidToPGType(tenantID),
@@ -586,8 +577,8 @@ func (s *Store) RotateModelCredential(ctx context.Context, tenantID string, prin
 		Column1:      uuidToPGType(tenantID),
 		CredentialID: "cred_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
 		ProviderID:   providerID,
-		SecretRef:    strings.TrimSpace(input.SecretRef),
-		MaskedSecret: strings.TrimSpace(input.MaskedSecret),
+		SecretRef:    secretRef,
+		MaskedSecret: maskedSecret,
 		Version:      nextVersion,
 		Active:       true,
 		Column8:      uuidToPGType(principalID),
@@ -700,7 +691,7 @@ func (s *Store) SelectActiveModel(ctx context.Context, tenantID string, principa
 }
 
 func (s *Store) VerifyActiveModel(ctx context.Context, tenantID string, principalID string) (ModelHealth, error) {
-	service := NewModelVerificationService(s, s, NewOpenAICompatibleAdapter(nil), EnvSecretResolver{})
+	service := NewModelVerificationService(s, s, NewOpenAICompatibleAdapter(nil), s.secretResolver())
 	return service.VerifyActiveModel(ctx, tenantID, principalID)
 }
 
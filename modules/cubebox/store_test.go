@@ -24,6 +24,7 @@ type txStub struct {
 	queryErr  error
 	rowQueue  []pgx.Row
 	rowsQueue []pgx.Rows
+	queryArgs [][]any
 }
 
 func (t *txStub) Begin(context.Context) (pgx.Tx, error) { return t, nil }
@@ -45,7 +46,8 @@ func (t *txStub) Exec(_ context.Context, sql string, args ...any) (pgconn.Comman
 	return pgconn.CommandTag{}, t.execErr
 }
 
-func (t *txStub) Query(_ context.Context, _ string, _ ...any) (pgx.Rows, error) {
+func (t *txStub) Query(_ context.Context, _ string, args ...any) (pgx.Rows, error) {
+	t.queryArgs = append(t.queryArgs, args)
 	if t.queryErr != nil {
 		return nil, t.queryErr
 	}
@@ -94,6 +96,14 @@ func (r rowStub) Scan(dest ...any) error {
 			*d = r.vals[i].([]byte)
 		case *int32:
 			*d = r.vals[i].(int32)
+		case *int:
+			*d = r.vals[i].(int)
+		case *int64:
+			*d = r.vals[i].(int64)
+		case *time.Time:
+			*d = r.vals[i].(time.Time)
+		case *[]string:
+			*d = r.vals[i].([]string)
 		default:
 			return errors.New("unsupported scan destination")
 		}
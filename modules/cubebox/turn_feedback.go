@@ -0,0 +1,436 @@
+package cubebox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrTurnFeedbackInvalid = errors.New("CUBEBOX_TURN_FEEDBACK_INVALID")
+
+// TurnFeedbackEventType is appended to the rated turn. A turn rated again keeps every event; reports and
+// exports read only the latest one.
+const TurnFeedbackEventType = "turn.feedback"
+
+const (
+	TurnFeedbackRatingUp   = "up"
+	TurnFeedbackRatingDown = "down"
+
+	turnFeedbackMaxReasons      = 5
+	turnFeedbackMaxCommentRunes = 500
+	turnFeedbackNoOperation     = "none"
+	turnFeedbackNoReason        = "unspecified"
+)
+
+// TurnFeedbackReasons is the closed set of reason tags, so reports group on stable keys instead of free
+// text. Free text goes in the comment.
+var TurnFeedbackReasons = []string{
+	"accurate",
+	"helpful",
+	"wrong_data",
+	"wrong_entity",
+	"misunderstood",
+	"incomplete",
+	"unclear",
+	"out_of_scope",
+	"other",
+}
+
+type TurnFeedbackInput struct {
+	TurnID  string
+	Rating  string
+	Reasons []string
+	Comment string
+}
+
+// TurnFeedback is the payload of a turn.feedback event. Besides the rating it copies what the rated turn
+// did: its trace, the plan it executed, how it ended and what it answered. A report or export then needs
+// only the feedback events, and keeps what the user actually rated even if the turn is compacted later.
+type TurnFeedback struct {
+	TurnID        string              `json:"turn_id"`
+	TraceID       string              `json:"trace_id,omitempty"`
+	Rating        string              `json:"rating"`
+	Reasons       []string            `json:"reasons,omitempty"`
+	Comment       string              `json:"comment,omitempty"`
+	Prompt        string              `json:"prompt"`
+	Outcome       string              `json:"outcome"`
+	Plan          []QueryEvidenceCall `json:"plan,omitempty"`
+	MissingParams []string            `json:"missing_params,omitempty"`
+	Narration     string              `json:"narration,omitempty"`
+	ErrorCode     string              `json:"error_code,omitempty"`
+}
+
+// TurnFeedbackRecord is a stored feedback event together with where and when it was recorded.
+type TurnFeedbackRecord struct {
+	ConversationID string `json:"conversation_id"`
+	PrincipalID    string `json:"principal_id"`
+	RecordedAt     string `json:"recorded_at"`
+	TurnFeedback
+}
+
+func NormalizeTurnFeedbackInput(input TurnFeedbackInput) (TurnFeedbackInput, error) {
+	input.TurnID = strings.TrimSpace(input.TurnID)
+	input.Rating = strings.TrimSpace(input.Rating)
+	input.Comment = strings.TrimSpace(input.Comment)
+	if input.TurnID == "" {
+		return TurnFeedbackInput{}, fmt.Errorf("%w: turn_id required", ErrTurnFeedbackInvalid)
+	}
+	if input.Rating != TurnFeedbackRatingUp && input.Rating != TurnFeedbackRatingDown {
+		return TurnFeedbackInput{}, fmt.Errorf("%w: rating must be up or down", ErrTurnFeedbackInvalid)
+	}
+	if len([]rune(input.Comment)) > turnFeedbackMaxCommentRunes {
+		return TurnFeedbackInput{}, fmt.Errorf("%w: comment too long", ErrTurnFeedbackInvalid)
+	}
+	reasons := make([]string, 0, len(input.Reasons))
+	for _, reason := range input.Reasons {
+		reason = strings.TrimSpace(reason)
+		if !slices.Contains(TurnFeedbackReasons, reason) {
+			return TurnFeedbackInput{}, fmt.Errorf("%w: unknown reason %q", ErrTurnFeedbackInvalid, reason)
+		}
+		if !slices.Contains(reasons, reason) {
+			reasons = append(reasons, reason)
+		}
+	}
+	if len(reasons) > turnFeedbackMaxReasons {
+		return TurnFeedbackInput{}, fmt.Errorf("%w: too many reasons", ErrTurnFeedbackInvalid)
+	}
+	input.Reasons = reasons
+	return input, nil
+}
+
+// BuildTurnFeedback attaches a rating to a finished turn of the replayed conversation. The outcome mirrors
+// the planner outcomes the eval harness scores: API_CALLS when the turn executed calls, CLARIFY when it
+// asked for missing params, NO_QUERY when it answered without querying, and ERROR for any turn that did
+// not complete.
+func BuildTurnFeedback(replay ConversationReplayResponse, input TurnFeedbackInput) (TurnFeedback, error) {
+	input, err := NormalizeTurnFeedbackInput(input)
+	if err != nil {
+		return TurnFeedback{}, err
+	}
+	export, err := BuildConversationExport(replay, input.TurnID)
+	if err != nil {
+		return TurnFeedback{}, err
+	}
+	turn := export.Turns[0]
+	if turn.Status == "in_progress" {
+		return TurnFeedback{}, fmt.Errorf("%w: turn has not finished", ErrTurnFeedbackInvalid)
+	}
+	feedback := TurnFeedback{
+		TurnID:    turn.TurnID,
+		Rating:    input.Rating,
+		Reasons:   input.Reasons,
+		Comment:   input.Comment,
+		Prompt:    turn.UserMessage,
+		Plan:      turn.Evidence,
+		Narration: turn.AssistantMessage,
+	}
+	clarified := false
+	for _, event := range replay.Events {
+		if event.TurnID == nil || strings.TrimSpace(*event.TurnID) != turn.TurnID {
+			continue
+		}
+		if feedback.TraceID == "" {
+			feedback.TraceID = strings.TrimSpace(stringValue(event.Payload["trace_id"]))
+		}
+		if event.Type == QueryClarificationRequestedEventType {
+			clarified = true
+			feedback.MissingParams = append(feedback.MissingParams, stringSliceValue(event.Payload["missing_params"])...)
+		}
+	}
+	switch {
+	case turn.Status != "completed":
+		feedback.Outcome = EvalOutcomeError
+		feedback.ErrorCode = turn.ErrorCode
+		if feedback.ErrorCode == "" {
+			feedback.ErrorCode = turn.Status
+		}
+	case len(feedback.Plan) > 0:
+		feedback.Outcome = string(PlannerOutcomeAPICalls)
+	case clarified:
+		feedback.Outcome = string(PlannerOutcomeClarify)
+	default:
+		feedback.Outcome = string(PlannerOutcomeNoQuery)
+	}
+	return feedback, nil
+}
+
+// Payload is the feedback as a canonical event payload, with the plan already in its decoded JSON shape so
+// the in-memory event matches the stored one.
+func (f TurnFeedback) Payload() map[string]any {
+	raw, err := json.Marshal(f)
+	if err != nil {
+		return map[string]any{}
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return map[string]any{}
+	}
+	return payload
+}
+
+func stringSliceValue(value any) []string {
+	switch v := value.(type) {
+	case []string:
+		return append([]string(nil), v...)
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if text := strings.TrimSpace(stringValue(item)); text != "" {
+				out = append(out, text)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// TurnFeedbackQuery bounds recorded_at as [From, To). Rating empty selects both ratings.
+type TurnFeedbackQuery struct {
+	From   time.Time
+	To     time.Time
+	Rating string
+}
+
+// NormalizeTurnFeedbackQuery defaults to the last 30 days and allows at most a year, like the usage report.
+func NormalizeTurnFeedbackQuery(query TurnFeedbackQuery, now time.Time) (TurnFeedbackQuery, error) {
+	query.Rating = strings.TrimSpace(query.Rating)
+	if query.Rating != "" && query.Rating != TurnFeedbackRatingUp && query.Rating != TurnFeedbackRatingDown {
+		return TurnFeedbackQuery{}, ErrTurnFeedbackInvalid
+	}
+	if query.To.IsZero() {
+		query.To = now.UTC()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.AddDate(0, 0, -30)
+	}
+	if !query.From.Before(query.To) || query.To.Sub(query.From) > 366*24*time.Hour {
+		return TurnFeedbackQuery{}, ErrTurnFeedbackInvalid
+	}
+	return query, nil
+}
+
+type TurnFeedbackReportRow struct {
+	Key   string `json:"key"`
+	Up    int64  `json:"up"`
+	Down  int64  `json:"down"`
+	Total int64  `json:"total"`
+}
+
+func (r *TurnFeedbackReportRow) add(rating string) {
+	if rating == TurnFeedbackRatingUp {
+		r.Up++
+	} else {
+		r.Down++
+	}
+	r.Total++
+}
+
+// TurnFeedbackReport counts rated turns by reason tag and by the operation_id of each executed call. A
+// turn with several reasons or calls counts once under each, so the rows of a group need not sum to Total.
+type TurnFeedbackReport struct {
+	From        string                  `json:"from"`
+	To          string                  `json:"to"`
+	Total       TurnFeedbackReportRow   `json:"total"`
+	ByReason    []TurnFeedbackReportRow `json:"by_reason"`
+	ByOperation []TurnFeedbackReportRow `json:"by_operation"`
+}
+
+func BuildTurnFeedbackReport(query TurnFeedbackQuery, records []TurnFeedbackRecord) TurnFeedbackReport {
+	report := TurnFeedbackReport{
+		From:        query.From.UTC().Format(time.RFC3339),
+		To:          query.To.UTC().Format(time.RFC3339),
+		Total:       TurnFeedbackReportRow{Key: "total"},
+		ByReason:    []TurnFeedbackReportRow{},
+		ByOperation: []TurnFeedbackReportRow{},
+	}
+	byReason := map[string]*TurnFeedbackReportRow{}
+	byOperation := map[string]*TurnFeedbackReportRow{}
+	count := func(rows map[string]*TurnFeedbackReportRow, key string, rating string) {
+		row, ok := rows[key]
+		if !ok {
+			row = &TurnFeedbackReportRow{Key: key}
+			rows[key] = row
+		}
+		row.add(rating)
+	}
+	for _, record := range records {
+		report.Total.add(record.Rating)
+		reasons := record.Reasons
+		if len(reasons) == 0 {
+			reasons = []string{turnFeedbackNoReason}
+		}
+		for _, reason := range reasons {
+			count(byReason, reason, record.Rating)
+		}
+		operations := []string{}
+		for _, call := range record.Plan {
+			operation := call.OperationID
+			if operation == "" {
+				operation = call.Method + " " + call.Path
+			}
+			if !slices.Contains(operations, operation) {
+				operations = append(operations, operation)
+			}
+		}
+		if len(operations) == 0 {
+			operations = []string{turnFeedbackNoOperation}
+		}
+		for _, operation := range operations {
+			count(byOperation, operation, record.Rating)
+		}
+	}
+	report.ByReason = sortedTurnFeedbackRows(byReason)
+	report.ByOperation = sortedTurnFeedbackRows(byOperation)
+	return report
+}
+
+// sortedTurnFeedbackRows puts the most down-voted keys first, since those are what tuning starts from.
+func sortedTurnFeedbackRows(rows map[string]*TurnFeedbackReportRow) []TurnFeedbackReportRow {
+	out := make([]TurnFeedbackReportRow, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, *row)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Down != out[j].Down {
+			return out[i].Down > out[j].Down
+		}
+		if out[i].Total != out[j].Total {
+			return out[i].Total > out[j].Total
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+// BuildTurnFeedbackEvalSuite turns rated turns into scenarios for the offline eval harness. Each scenario
+// replays only the rated turn: the recorded plan is replayed as one planner round with its params already
+// resolved, and the recorded answer as the narration. An up-rated turn expects what it did, so it guards
+// against regressions as is. A down-rated turn also expects what it did, which is what was wrong; its
+// source carries the reasons so a reviewer can correct the expectation before adding it to a suite. Turns
+// that ended in an error cannot be replayed and are left out.
+//
+// Org units cited by the plans seed the suite as children of the first one; the export does not know the
+// real hierarchy, so tree-shaped queries need their seed edited.
+func BuildTurnFeedbackEvalSuite(name string, records []TurnFeedbackRecord) EvalSuite {
+	suite := EvalSuite{Name: strings.TrimSpace(name), Scenarios: []EvalScenario{}}
+	seeded := map[string]struct{}{}
+	for _, record := range records {
+		scenario, ok := turnFeedbackEvalScenario(record)
+		if !ok {
+			continue
+		}
+		suite.Scenarios = append(suite.Scenarios, scenario)
+		for _, call := range record.Plan {
+			for _, entity := range call.Entities {
+				if entity.Domain != "orgunit" {
+					continue
+				}
+				if _, ok := seeded[entity.EntityKey]; ok {
+					continue
+				}
+				seeded[entity.EntityKey] = struct{}{}
+				unit := EvalOrgUnit{OrgCode: entity.EntityKey, Name: entity.Name}
+				if unit.Name == "" {
+					unit.Name = entity.EntityKey
+				}
+				if len(suite.OrgUnits) == 0 {
+					unit.IsBusinessUnit = true
+				} else {
+					unit.ParentOrgCode = suite.OrgUnits[0].OrgCode
+				}
+				suite.OrgUnits = append(suite.OrgUnits, unit)
+			}
+		}
+	}
+	return suite
+}
+
+func turnFeedbackEvalScenario(record TurnFeedbackRecord) (EvalScenario, bool) {
+	if record.Outcome == EvalOutcomeError || strings.TrimSpace(record.Prompt) == "" {
+		return EvalScenario{}, false
+	}
+	turn := EvalTurn{Prompt: record.Prompt}
+	if record.Narration != "" {
+		turn.Narrator = []string{record.Narration}
+	}
+	expect := EvalExpectation{Outcome: record.Outcome}
+	switch record.Outcome {
+	case string(PlannerOutcomeAPICalls):
+		calls := make([]map[string]any, 0, len(record.Plan))
+		for i, call := range record.Plan {
+			params := call.Params
+			if params == nil {
+				params = map[string]any{}
+			}
+			calls = append(calls, map[string]any{
+				"id":         fmt.Sprintf("step-%d", i+1),
+				"method":     call.Method,
+				"path":       call.Path,
+				"params":     params,
+				"depends_on": []string{},
+			})
+			expect.Calls = append(expect.Calls, EvalExpectedCall{Method: call.Method, Path: call.Path, Params: call.Params})
+		}
+		turn.Planner = []string{
+			turnFeedbackPlannerOutput(map[string]any{"outcome": string(PlannerOutcomeAPICalls), "calls": calls}),
+			turnFeedbackPlannerOutput(map[string]any{"outcome": string(PlannerOutcomeDone)}),
+		}
+		if record.Rating == TurnFeedbackRatingUp {
+			expect.Narration.Contains = turnFeedbackNarrationAnchors(record)
+		}
+	case string(PlannerOutcomeClarify):
+		if len(record.MissingParams) == 0 {
+			return EvalScenario{}, false
+		}
+		turn.Planner = []string{turnFeedbackPlannerOutput(map[string]any{
+			"outcome":             string(PlannerOutcomeClarify),
+			"missing_params":      record.MissingParams,
+			"clarifying_question": record.Narration,
+		})}
+		expect.MissingParams = record.MissingParams
+	case string(PlannerOutcomeNoQuery):
+		turn.Planner = []string{turnFeedbackPlannerOutput(map[string]any{"outcome": string(PlannerOutcomeNoQuery)})}
+	default:
+		return EvalScenario{}, false
+	}
+	return EvalScenario{
+		ID:     "feedback-" + record.TurnID,
+		Turns:  []EvalTurn{turn},
+		Expect: expect,
+		Source: &EvalScenarioSource{
+			ConversationID: record.ConversationID,
+			TurnID:         record.TurnID,
+			TraceID:        record.TraceID,
+			Rating:         record.Rating,
+			Reasons:        record.Reasons,
+			Comment:        record.Comment,
+		},
+	}, true
+}
+
+func turnFeedbackPlannerOutput(outcome map[string]any) string {
+	raw, err := json.Marshal(outcome)
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
+
+// turnFeedbackNarrationAnchors picks the names of cited entities that the approved answer mentions. They
+// are facts a good answer must keep, unlike its wording.
+func turnFeedbackNarrationAnchors(record TurnFeedbackRecord) []string {
+	var anchors []string
+	for _, call := range record.Plan {
+		for _, entity := range call.Entities {
+			if entity.Name != "" && strings.Contains(record.Narration, entity.Name) && !slices.Contains(anchors, entity.Name) {
+				anchors = append(anchors, entity.Name)
+			}
+		}
+	}
+	return anchors
+}
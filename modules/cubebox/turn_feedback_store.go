@@ -0,0 +1,65 @@
+package cubebox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// turnFeedbackListLimit caps one report or export; a window with more rated turns should be narrowed.
+const turnFeedbackListLimit = 5000
+
+// ListTurnFeedback returns the latest feedback of every rated turn recorded in the query window, newest
+// first. Rating filters on that latest feedback, so a turn rated down and then up counts only as up.
+func (s *Store) ListTurnFeedback(ctx context.Context, tenantID string, query TurnFeedbackQuery) ([]TurnFeedbackRecord, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(ctx, `
+SELECT latest.conversation_id, latest.principal_id::text, latest.created_at, latest.payload
+FROM (
+  SELECT DISTINCT ON (e.conversation_id, e.turn_id)
+    e.conversation_id, c.principal_id, e.created_at, e.payload
+  FROM iam.cubebox_conversation_events e
+  JOIN iam.cubebox_conversations c
+    ON c.tenant_uuid = e.tenant_uuid
+   AND c.conversation_id = e.conversation_id
+  WHERE e.tenant_uuid = $1::uuid
+    AND e.event_type = $2
+    AND e.created_at >= $3
+    AND e.created_at < $4
+  ORDER BY e.conversation_id, e.turn_id, e.sequence DESC
+) latest
+WHERE ($5::text = '' OR latest.payload->>'rating' = $5::text)
+ORDER BY latest.created_at DESC, latest.conversation_id
+LIMIT $6;
+`, tenantID, TurnFeedbackEventType, query.From, query.To, query.Rating, turnFeedbackListLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	records := []TurnFeedbackRecord{}
+	for rows.Next() {
+		var record TurnFeedbackRecord
+		var recordedAt time.Time
+		var payload []byte
+		if err := rows.Scan(&record.ConversationID, &record.PrincipalID, &recordedAt, &payload); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &record.TurnFeedback); err != nil {
+			return nil, err
+		}
+		record.RecordedAt = recordedAt.UTC().Format(time.RFC3339)
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	return records, tx.Commit(ctx)
+}
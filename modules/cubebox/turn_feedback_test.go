@@ -0,0 +1,148 @@
+package cubebox
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func feedbackTestReplay() ConversationReplayResponse {
+	replay := exportTestReplay()
+	turn3 := "turn_3"
+	replay.Events = append(replay.Events,
+		CanonicalEvent{Sequence: 12, TurnID: &turn3, Type: "turn.started", Payload: map[string]any{"trace_id": "trace-3"}},
+		CanonicalEvent{Sequence: 13, TurnID: &turn3, Type: "turn.user_message.accepted", Payload: map[string]any{"text": "查一下组织详情"}},
+		CanonicalEvent{Sequence: 14, TurnID: &turn3, Type: "turn.agent_message.delta", Payload: map[string]any{"delta": "你想查询哪个组织？"}},
+		CanonicalEvent{Sequence: 15, TurnID: &turn3, Type: QueryClarificationRequestedEventType, Payload: map[string]any{"missing_params": []any{"org_code"}}},
+		CanonicalEvent{Sequence: 16, TurnID: &turn3, Type: "turn.completed", Payload: map[string]any{"status": "completed", "trace_id": "trace-3"}},
+	)
+	replay.Events[1].Payload = map[string]any{"trace_id": "trace-1"}
+	replay.NextSequence = 17
+	return replay
+}
+
+func TestBuildTurnFeedbackCapturesOutcomePlanAndTrace(t *testing.T) {
+	replay := feedbackTestReplay()
+	feedback, err := BuildTurnFeedback(replay, TurnFeedbackInput{TurnID: "turn_1", Rating: "up", Reasons: []string{"accurate", "accurate"}, Comment: " 很准 "})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if feedback.TraceID != "trace-1" || feedback.Outcome != "API_CALLS" || len(feedback.Plan) != 1 || feedback.Prompt != "列出组织" ||
+		feedback.Narration != "共 2 个组织。" || len(feedback.Reasons) != 1 || feedback.Comment != "很准" {
+		t.Fatalf("feedback=%+v", feedback)
+	}
+	if payload := feedback.Payload(); payload["rating"] != "up" || len(payload["plan"].([]any)) != 1 {
+		t.Fatalf("payload=%+v", payload)
+	}
+
+	failed, err := BuildTurnFeedback(replay, TurnFeedbackInput{TurnID: "turn_2", Rating: "down", Reasons: []string{"wrong_data"}})
+	if err != nil || failed.Outcome != EvalOutcomeError || failed.ErrorCode != "ai_model_timeout" {
+		t.Fatalf("failed=%+v err=%v", failed, err)
+	}
+	clarified, err := BuildTurnFeedback(replay, TurnFeedbackInput{TurnID: "turn_3", Rating: "down", Reasons: []string{"misunderstood"}})
+	if err != nil || clarified.Outcome != "CLARIFY" || clarified.TraceID != "trace-3" || strings.Join(clarified.MissingParams, ",") != "org_code" {
+		t.Fatalf("clarified=%+v err=%v", clarified, err)
+	}
+
+	if _, err := BuildTurnFeedback(replay, TurnFeedbackInput{TurnID: "turn_9", Rating: "up"}); !errors.Is(err, ErrConversationTurnNotFound) {
+		t.Fatalf("err=%v", err)
+	}
+	replay.Events = replay.Events[:len(replay.Events)-1]
+	if _, err := BuildTurnFeedback(replay, TurnFeedbackInput{TurnID: "turn_3", Rating: "up"}); !errors.Is(err, ErrTurnFeedbackInvalid) {
+		t.Fatalf("unfinished turn err=%v", err)
+	}
+	for _, bad := range []TurnFeedbackInput{
+		{Rating: "up"},
+		{TurnID: "turn_1", Rating: "meh"},
+		{TurnID: "turn_1", Rating: "down", Reasons: []string{"rude"}},
+		{TurnID: "turn_1", Rating: "down", Comment: strings.Repeat("长", turnFeedbackMaxCommentRunes+1)},
+	} {
+		if _, err := NormalizeTurnFeedbackInput(bad); !errors.Is(err, ErrTurnFeedbackInvalid) {
+			t.Fatalf("input=%+v err=%v", bad, err)
+		}
+	}
+}
+
+func feedbackTestRecords(t *testing.T) []TurnFeedbackRecord {
+	t.Helper()
+	replay := feedbackTestReplay()
+	var records []TurnFeedbackRecord
+	for _, input := range []TurnFeedbackInput{
+		{TurnID: "turn_1", Rating: "up", Reasons: []string{"accurate"}},
+		{TurnID: "turn_2", Rating: "down", Reasons: []string{"wrong_data"}},
+		{TurnID: "turn_3", Rating: "down", Reasons: []string{"misunderstood", "wrong_data"}},
+	} {
+		feedback, err := BuildTurnFeedback(replay, input)
+		if err != nil {
+			t.Fatalf("err=%v", err)
+		}
+		records = append(records, TurnFeedbackRecord{ConversationID: "conv_1", TurnFeedback: feedback})
+	}
+	return records
+}
+
+func TestBuildTurnFeedbackReportGroupsByReasonAndOperation(t *testing.T) {
+	query, err := NormalizeTurnFeedbackQuery(TurnFeedbackQuery{}, time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC))
+	if err != nil || query.From != time.Date(2026, 9, 19, 8, 0, 0, 0, time.UTC) {
+		t.Fatalf("query=%+v err=%v", query, err)
+	}
+	report := BuildTurnFeedbackReport(query, feedbackTestRecords(t))
+	if report.Total.Up != 1 || report.Total.Down != 2 || report.Total.Total != 3 {
+		t.Fatalf("total=%+v", report.Total)
+	}
+	if first := report.ByReason[0]; first.Key != "wrong_data" || first.Down != 2 {
+		t.Fatalf("by_reason=%+v", report.ByReason)
+	}
+	if len(report.ByOperation) != 2 || report.ByOperation[0].Key != "none" || report.ByOperation[1].Key != "orgunit.list" || report.ByOperation[1].Up != 1 {
+		t.Fatalf("by_operation=%+v", report.ByOperation)
+	}
+	if _, err := NormalizeTurnFeedbackQuery(TurnFeedbackQuery{Rating: "meh"}, time.Now()); !errors.Is(err, ErrTurnFeedbackInvalid) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestBuildTurnFeedbackEvalSuiteIsValidSuite(t *testing.T) {
+	suite := BuildTurnFeedbackEvalSuite("cubebox-feedback", feedbackTestRecords(t))
+	if err := ValidateEvalSuite(suite); err != nil {
+		t.Fatalf("err=%v suite=%+v", err, suite)
+	}
+	// The failed turn cannot be replayed and is left out.
+	if len(suite.Scenarios) != 2 {
+		t.Fatalf("scenarios=%+v", suite.Scenarios)
+	}
+	up := suite.Scenarios[0]
+	if up.ID != "feedback-turn_1" || up.Source.Rating != "up" || len(up.Turns[0].Planner) != 2 || len(up.Expect.Calls) != 1 ||
+		!strings.Contains(up.Turns[0].Planner[0], `"path":"/org/api/org-units"`) {
+		t.Fatalf("up=%+v", up)
+	}
+	if _, err := DecodePlannerOutcome([]byte(up.Turns[0].Planner[0])); err != nil {
+		t.Fatalf("planner recording err=%v", err)
+	}
+	if clarify := suite.Scenarios[1]; clarify.Expect.Outcome != "CLARIFY" || clarify.Source.Reasons[0] != "misunderstood" {
+		t.Fatalf("clarify=%+v", clarify)
+	}
+	if len(suite.OrgUnits) != 2 || !suite.OrgUnits[0].IsBusinessUnit || suite.OrgUnits[1].ParentOrgCode != suite.OrgUnits[0].OrgCode {
+		t.Fatalf("org_units=%+v", suite.OrgUnits)
+	}
+}
+
+func TestStoreListTurnFeedbackDecodesLatestFeedback(t *testing.T) {
+	recordedAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	tx := &txStub{rowsQueue: []pgx.Rows{&rowsStub{rows: [][]any{
+		{"conv_1", "p1", recordedAt, []byte(`{"turn_id":"turn_1","rating":"down","reasons":["wrong_data"],"prompt":"列出组织","outcome":"NO_QUERY"}`)},
+	}}}}
+	records, err := NewStore(tx).ListTurnFeedback(context.Background(), "tenant-1", TurnFeedbackQuery{Rating: "down", From: recordedAt.Add(-time.Hour), To: recordedAt.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if len(records) != 1 || records[0].TurnID != "turn_1" || records[0].Reasons[0] != "wrong_data" || records[0].RecordedAt != "2026-10-19T08:00:00Z" {
+		t.Fatalf("records=%+v", records)
+	}
+	if args := tx.queryArgs[0]; args[1] != TurnFeedbackEventType || args[4] != "down" {
+		t.Fatalf("args=%v", args)
+	}
+}
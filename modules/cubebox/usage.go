@@ -0,0 +1,431 @@
+package cubebox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrTokenBudgetInvalid = errors.New("CUBEBOX_TOKEN_BUDGET_INVALID")
+var ErrUsageReportInvalid = errors.New("CUBEBOX_USAGE_REPORT_INVALID")
+
+const (
+	TokenBudgetScopeTenant    = "tenant"
+	TokenBudgetScopePrincipal = "principal"
+	TokenBudgetWindowDaily    = "daily"
+	TokenBudgetWindowMonthly  = "monthly"
+
+	UsageReportGroupByPrincipal = "principal"
+	UsageReportGroupByModel     = "model"
+	UsageReportGroupByDay       = "day"
+)
+
+// TokenUsage counts the tokens of one or more provider calls. Estimated is set when any part of the count
+// came from text length instead of the provider's own usage report.
+type TokenUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	Estimated        bool  `json:"estimated"`
+}
+
+func (u TokenUsage) TotalTokens() int64 {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+func (u TokenUsage) add(other TokenUsage) TokenUsage {
+	return TokenUsage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		Estimated:        u.Estimated || other.Estimated,
+	}
+}
+
+type UsageRecord struct {
+	TenantID       string
+	PrincipalID    string
+	ConversationID string
+	TurnID         string
+	ProviderID     string
+	ModelSlug      string
+	Usage          TokenUsage
+	RecordedAt     time.Time
+}
+
+// UsageMeter sums the provider usage of one turn. A query turn calls the provider several times (planner
+// rounds, narration), and the calls may start before the turn ID is known, so the meter is bound later.
+type UsageMeter struct {
+	mu         sync.Mutex
+	turnID     string
+	providerID string
+	byModel    map[string]TokenUsage
+}
+
+func NewUsageMeter() *UsageMeter {
+	return &UsageMeter{byModel: map[string]TokenUsage{}}
+}
+
+func (m *UsageMeter) Bind(turnID string, providerID string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.turnID = strings.TrimSpace(turnID)
+	m.providerID = strings.TrimSpace(providerID)
+}
+
+func (m *UsageMeter) Add(modelSlug string, usage TokenUsage) {
+	if m == nil {
+		return
+	}
+	modelSlug = strings.TrimSpace(modelSlug)
+	if modelSlug == "" {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.byModel[modelSlug] = m.byModel[modelSlug].add(usage)
+}
+
+func (m *UsageMeter) Total() TokenUsage {
+	if m == nil {
+		return TokenUsage{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var total TokenUsage
+	for _, usage := range m.byModel {
+		total = total.add(usage)
+	}
+	return total
+}
+
+// AnnotatePayload adds the tokens counted so far to a turn.completed payload. Streams still open when the
+// turn ends are settled on close, so the stored record can exceed what an interrupted turn reports here.
+func (m *UsageMeter) AnnotatePayload(payload map[string]any) {
+	if m == nil {
+		return
+	}
+	usage := m.Total()
+	payload["prompt_tokens"] = usage.PromptTokens
+	payload["completion_tokens"] = usage.CompletionTokens
+	payload["total_tokens"] = usage.TotalTokens()
+	payload["usage_estimated"] = usage.Estimated
+}
+
+// Records returns one record per model, or nothing while the meter is unbound or empty.
+func (m *UsageMeter) Records(request GatewayStreamRequest, recordedAt time.Time) []UsageRecord {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.turnID == "" {
+		return nil
+	}
+	out := make([]UsageRecord, 0, len(m.byModel))
+	for modelSlug, usage := range m.byModel {
+		if usage.TotalTokens() == 0 {
+			continue
+		}
+		out = append(out, UsageRecord{
+			TenantID:       request.TenantID,
+			PrincipalID:    request.PrincipalID,
+			ConversationID: request.ConversationID,
+			TurnID:         m.turnID,
+			ProviderID:     m.providerID,
+			ModelSlug:      modelSlug,
+			Usage:          usage,
+			RecordedAt:     recordedAt,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ModelSlug < out[j].ModelSlug })
+	return out
+}
+
+type usageMeterContextKey struct{}
+
+func WithUsageMeter(ctx context.Context, meter *UsageMeter) context.Context {
+	return context.WithValue(ctx, usageMeterContextKey{}, meter)
+}
+
+func UsageMeterFromContext(ctx context.Context) *UsageMeter {
+	meter, _ := ctx.Value(usageMeterContextKey{}).(*UsageMeter)
+	return meter
+}
+
+// NewMeteredProviderAdapter counts every stream opened under a context carrying a UsageMeter. Provider
+// reported usage wins; otherwise prompt and completion are estimated from the text sent and received.
+func NewMeteredProviderAdapter(inner ProviderAdapter) ProviderAdapter {
+	return meteredProviderAdapter{inner: inner}
+}
+
+type meteredProviderAdapter struct {
+	inner ProviderAdapter
+}
+
+func (a meteredProviderAdapter) StreamChatCompletion(ctx context.Context, request ProviderChatRequest) (ProviderChatStream, error) {
+	stream, err := a.inner.StreamChatCompletion(ctx, request)
+	meter := UsageMeterFromContext(ctx)
+	if err != nil || meter == nil {
+		return stream, err
+	}
+	return &meteredChatStream{inner: stream, meter: meter, request: request}, nil
+}
+
+type meteredChatStream struct {
+	inner      ProviderChatStream
+	meter      *UsageMeter
+	request    ProviderChatRequest
+	completion strings.Builder
+	reported   *TokenUsage
+	settled    sync.Once
+}
+
+func (s *meteredChatStream) Recv() (ProviderChatChunk, error) {
+	chunk, err := s.inner.Recv()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			s.settle()
+		}
+		return chunk, err
+	}
+	s.completion.WriteString(chunk.Delta)
+	for _, call := range chunk.ToolCalls {
+		s.completion.WriteString(call.Name)
+		s.completion.WriteString(call.Arguments)
+	}
+	if chunk.Usage != nil {
+		usage := *chunk.Usage
+		s.reported = &usage
+	}
+	if chunk.Done {
+		s.settle()
+	}
+	return chunk, nil
+}
+
+// Close settles streams abandoned mid-answer (interrupts, provider errors) with what was received so far.
+func (s *meteredChatStream) Close() error {
+	s.settle()
+	return s.inner.Close()
+}
+
+func (s *meteredChatStream) settle() {
+	s.settled.Do(func() {
+		s.meter.Add(s.request.Model, MeasureProviderUsage(s.request, s.completion.String(), s.reported))
+	})
+}
+
+// MeasureProviderUsage returns the provider's reported usage, or an estimate when it reported none.
+func MeasureProviderUsage(request ProviderChatRequest, completion string, reported *TokenUsage) TokenUsage {
+	if reported != nil && reported.TotalTokens() > 0 {
+		return TokenUsage{PromptTokens: reported.PromptTokens, CompletionTokens: reported.CompletionTokens}
+	}
+	prompt := estimatePromptTokens(request.Messages, "")
+	if prompt == 0 {
+		prompt = estimateTextTokens(request.Input)
+	}
+	return TokenUsage{
+		PromptTokens:     int64(prompt),
+		CompletionTokens: int64(estimateTextTokens(completion)),
+		Estimated:        true,
+	}
+}
+
+// TokenBudget caps token usage for the whole tenant (PrincipalID empty) or one principal. A zero limit is
+// unlimited.
+type TokenBudget struct {
+	PrincipalID       string `json:"principal_id"`
+	DailyTokenLimit   int64  `json:"daily_token_limit"`
+	MonthlyTokenLimit int64  `json:"monthly_token_limit"`
+	UpdatedAt         string `json:"updated_at,omitempty"`
+}
+
+func ValidateTokenBudget(budget TokenBudget) error {
+	principalID := strings.TrimSpace(budget.PrincipalID)
+	if principalID != "" {
+		if _, err := uuid.Parse(principalID); err != nil {
+			return ErrTokenBudgetInvalid
+		}
+	}
+	if budget.DailyTokenLimit < 0 || budget.MonthlyTokenLimit < 0 {
+		return ErrTokenBudgetInvalid
+	}
+	if budget.DailyTokenLimit > 0 && budget.MonthlyTokenLimit > 0 && budget.DailyTokenLimit > budget.MonthlyTokenLimit {
+		return ErrTokenBudgetInvalid
+	}
+	return nil
+}
+
+// TokenBudgetSnapshot is what admission needs: the budgets that apply to one principal and the usage
+// already recorded in the current UTC day and month.
+type TokenBudgetSnapshot struct {
+	Tenant               *TokenBudget
+	Principal            *TokenBudget
+	TenantDailyUsed      int64
+	TenantMonthlyUsed    int64
+	PrincipalDailyUsed   int64
+	PrincipalMonthlyUsed int64
+}
+
+type TokenBudgetStatus struct {
+	Exceeded bool
+	Scope    string
+	Window   string
+	Limit    int64
+	Used     int64
+}
+
+// EvaluateTokenBudget reports the first exhausted budget, principal before tenant and daily before monthly.
+func EvaluateTokenBudget(snapshot TokenBudgetSnapshot) TokenBudgetStatus {
+	type check struct {
+		scope  string
+		window string
+		limit  int64
+		used   int64
+	}
+	var checks []check
+	if snapshot.Principal != nil {
+		checks = append(checks,
+			check{TokenBudgetScopePrincipal, TokenBudgetWindowDaily, snapshot.Principal.DailyTokenLimit, snapshot.PrincipalDailyUsed},
+			check{TokenBudgetScopePrincipal, TokenBudgetWindowMonthly, snapshot.Principal.MonthlyTokenLimit, snapshot.PrincipalMonthlyUsed},
+		)
+	}
+	if snapshot.Tenant != nil {
+		checks = append(checks,
+			check{TokenBudgetScopeTenant, TokenBudgetWindowDaily, snapshot.Tenant.DailyTokenLimit, snapshot.TenantDailyUsed},
+			check{TokenBudgetScopeTenant, TokenBudgetWindowMonthly, snapshot.Tenant.MonthlyTokenLimit, snapshot.TenantMonthlyUsed},
+		)
+	}
+	for _, c := range checks {
+		if c.limit > 0 && c.used >= c.limit {
+			return TokenBudgetStatus{Exceeded: true, Scope: c.scope, Window: c.window, Limit: c.limit, Used: c.used}
+		}
+	}
+	return TokenBudgetStatus{}
+}
+
+// turnReservationReplyTokens is what a turn reserves on top of its prompt estimate, covering the reply and
+// the conversation history the prompt view adds.
+const turnReservationReplyTokens = 2000
+
+// estimateTurnReservation is the token count AdmitTurn holds against the budgets until the turn's real
+// usage is recorded.
+func estimateTurnReservation(request GatewayStreamRequest) int64 {
+	return int64(estimateTextTokens(request.Prompt)) + turnReservationReplyTokens
+}
+
+// tokenReservations holds the estimated tokens of admitted turns whose usage is not recorded yet, per
+// tenant and per principal. Admission counts them as already used, so concurrent turns cannot all pass on
+// the same remaining budget. Reservations are per process: turns on other replicas count once recorded.
+type tokenReservations struct {
+	mu        sync.Mutex
+	tenant    map[string]int64
+	principal map[string]int64
+}
+
+func newTokenReservations() *tokenReservations {
+	return &tokenReservations{tenant: map[string]int64{}, principal: map[string]int64{}}
+}
+
+// admit evaluates snapshot with the outstanding reservations added and, when no budget is exhausted,
+// reserves tokens for the turn. release gives the reservation back and is safe to call more than once.
+func (r *tokenReservations) admit(snapshot TokenBudgetSnapshot, tenantID string, principalID string, tokens int64) (TokenBudgetStatus, func()) {
+	principalKey := tenantID + "\x00" + principalID
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	snapshot.TenantDailyUsed += r.tenant[tenantID]
+	snapshot.TenantMonthlyUsed += r.tenant[tenantID]
+	snapshot.PrincipalDailyUsed += r.principal[principalKey]
+	snapshot.PrincipalMonthlyUsed += r.principal[principalKey]
+	if status := EvaluateTokenBudget(snapshot); status.Exceeded {
+		return status, func() {}
+	}
+	r.tenant[tenantID] += tokens
+	r.principal[principalKey] += tokens
+
+	var once sync.Once
+	return TokenBudgetStatus{}, func() {
+		once.Do(func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if r.tenant[tenantID] -= tokens; r.tenant[tenantID] <= 0 {
+				delete(r.tenant, tenantID)
+			}
+			if r.principal[principalKey] -= tokens; r.principal[principalKey] <= 0 {
+				delete(r.principal, principalKey)
+			}
+		})
+	}
+}
+
+type tokenReservationContextKey struct{}
+
+func withTokenReservation(ctx context.Context, release func()) context.Context {
+	return context.WithValue(ctx, tokenReservationContextKey{}, release)
+}
+
+func releaseTokenReservation(ctx context.Context) {
+	if release, ok := ctx.Value(tokenReservationContextKey{}).(func()); ok {
+		release()
+	}
+}
+
+// TokenBudgetWindows returns the starts of the UTC day and month containing now.
+func TokenBudgetWindows(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return day, month
+}
+
+type UsageReportQuery struct {
+	From    time.Time
+	To      time.Time
+	GroupBy string
+}
+
+func NormalizeUsageReportQuery(query UsageReportQuery, now time.Time) (UsageReportQuery, error) {
+	if query.GroupBy == "" {
+		query.GroupBy = UsageReportGroupByDay
+	}
+	switch query.GroupBy {
+	case UsageReportGroupByPrincipal, UsageReportGroupByModel, UsageReportGroupByDay:
+	default:
+		return UsageReportQuery{}, ErrUsageReportInvalid
+	}
+	if query.To.IsZero() {
+		query.To = now.UTC()
+	}
+	if query.From.IsZero() {
+		_, query.From = TokenBudgetWindows(query.To)
+	}
+	if !query.From.Before(query.To) || query.To.Sub(query.From) > 366*24*time.Hour {
+		return UsageReportQuery{}, ErrUsageReportInvalid
+	}
+	return query, nil
+}
+
+type UsageReportRow struct {
+	Key              string `json:"key"`
+	PromptTokens     int64  `json:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens"`
+	TotalTokens      int64  `json:"total_tokens"`
+	Turns            int64  `json:"turns"`
+	Estimated        bool   `json:"estimated"`
+}
+
+type UsageReport struct {
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	GroupBy string           `json:"group_by"`
+	Rows    []UsageReportRow `json:"rows"`
+	Total   UsageReportRow   `json:"total"`
+}
@@ -0,0 +1,239 @@
+package cubebox
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// RecordTurnUsage adds records to the usage ledger. A turn recorded twice for the same model accumulates.
+func (s *Store) RecordTurnUsage(ctx context.Context, records []UsageRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+	for _, record := range records {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO iam.cubebox_turn_usage (
+  tenant_uuid, principal_id, conversation_id, turn_id, provider_id, model_slug,
+  prompt_tokens, completion_tokens, estimated, recorded_at
+)
+VALUES ($1::uuid, $2::uuid, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (tenant_uuid, conversation_id, turn_id, model_slug) DO UPDATE
+SET prompt_tokens = iam.cubebox_turn_usage.prompt_tokens + EXCLUDED.prompt_tokens,
+    completion_tokens = iam.cubebox_turn_usage.completion_tokens + EXCLUDED.completion_tokens,
+    estimated = iam.cubebox_turn_usage.estimated OR EXCLUDED.estimated,
+    recorded_at = EXCLUDED.recorded_at;
+`, record.TenantID, record.PrincipalID, record.ConversationID, record.TurnID, strings.TrimSpace(record.ProviderID), record.ModelSlug,
+			record.Usage.PromptTokens, record.Usage.CompletionTokens, record.Usage.Estimated, record.RecordedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// GetTokenBudgetSnapshot loads the tenant-wide and principal budgets together with the usage counted against
+// them in the UTC day and month containing now.
+func (s *Store) GetTokenBudgetSnapshot(ctx context.Context, tenantID string, principalID string, now time.Time) (TokenBudgetSnapshot, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return TokenBudgetSnapshot{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	budgets, err := listTokenBudgets(ctx, tx, tenantID, principalID)
+	if err != nil {
+		return TokenBudgetSnapshot{}, err
+	}
+	var snapshot TokenBudgetSnapshot
+	for i := range budgets {
+		if budgets[i].PrincipalID == "" {
+			snapshot.Tenant = &budgets[i]
+		} else {
+			snapshot.Principal = &budgets[i]
+		}
+	}
+	if snapshot.Tenant == nil && snapshot.Principal == nil {
+		return snapshot, tx.Commit(ctx)
+	}
+	dayStart, monthStart := TokenBudgetWindows(now)
+	if err := tx.QueryRow(ctx, `
+SELECT
+  COALESCE(SUM(total_tokens) FILTER (WHERE recorded_at >= $3), 0)::bigint,
+  COALESCE(SUM(total_tokens), 0)::bigint,
+  COALESCE(SUM(total_tokens) FILTER (WHERE recorded_at >= $3 AND principal_id::text = $2), 0)::bigint,
+  COALESCE(SUM(total_tokens) FILTER (WHERE principal_id::text = $2), 0)::bigint
+FROM iam.cubebox_turn_usage
+WHERE tenant_uuid = $1::uuid
+  AND recorded_at >= $4;
+`, tenantID, principalID, dayStart, monthStart).Scan(
+		&snapshot.TenantDailyUsed,
+		&snapshot.TenantMonthlyUsed,
+		&snapshot.PrincipalDailyUsed,
+		&snapshot.PrincipalMonthlyUsed,
+	); err != nil {
+		return TokenBudgetSnapshot{}, err
+	}
+	return snapshot, tx.Commit(ctx)
+}
+
+func (s *Store) ListTokenBudgets(ctx context.Context, tenantID string) ([]TokenBudget, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+	budgets, err := listTokenBudgets(ctx, tx, tenantID, "")
+	if err != nil {
+		return nil, err
+	}
+	return budgets, tx.Commit(ctx)
+}
+
+// UpsertTokenBudget sets one budget; a budget with both limits at zero is removed.
+func (s *Store) UpsertTokenBudget(ctx context.Context, tenantID string, principalID string, input TokenBudget) (TokenBudget, error) {
+	input.PrincipalID = strings.TrimSpace(input.PrincipalID)
+	if err := ValidateTokenBudget(input); err != nil {
+		return TokenBudget{}, err
+	}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return TokenBudget{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if input.DailyTokenLimit == 0 && input.MonthlyTokenLimit == 0 {
+		if _, err := tx.Exec(ctx, `
+DELETE FROM iam.cubebox_token_budgets
+WHERE tenant_uuid = $1::uuid
+  AND principal_id = $2;
+`, tenantID, input.PrincipalID); err != nil {
+			return TokenBudget{}, err
+		}
+		return input, tx.Commit(ctx)
+	}
+	var updatedAt time.Time
+	if err := tx.QueryRow(ctx, `
+INSERT INTO iam.cubebox_token_budgets (tenant_uuid, principal_id, daily_token_limit, monthly_token_limit, updated_by, updated_at)
+VALUES ($1::uuid, $2, $3, $4, $5::uuid, now())
+ON CONFLICT (tenant_uuid, principal_id) DO UPDATE
+SET daily_token_limit = EXCLUDED.daily_token_limit,
+    monthly_token_limit = EXCLUDED.monthly_token_limit,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = EXCLUDED.updated_at
+RETURNING updated_at;
+`, tenantID, input.PrincipalID, input.DailyTokenLimit, input.MonthlyTokenLimit, principalID).Scan(&updatedAt); err != nil {
+		return TokenBudget{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return TokenBudget{}, err
+	}
+	input.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+	return input, nil
+}
+
+// GetUsageReport sums the ledger over [From, To) grouped by principal, model or UTC day.
+func (s *Store) GetUsageReport(ctx context.Context, tenantID string, query UsageReportQuery) (UsageReport, error) {
+	keyExpr := ""
+	switch query.GroupBy {
+	case UsageReportGroupByPrincipal:
+		keyExpr = "principal_id::text"
+	case UsageReportGroupByModel:
+		keyExpr = "model_slug"
+	case UsageReportGroupByDay:
+		keyExpr = "to_char(recorded_at AT TIME ZONE 'UTC', 'YYYY-MM-DD')"
+	default:
+		return UsageReport{}, ErrUsageReportInvalid
+	}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return UsageReport{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+	rows, err := tx.Query(ctx, `
+SELECT `+keyExpr+` AS key,
+  COALESCE(SUM(prompt_tokens), 0)::bigint,
+  COALESCE(SUM(completion_tokens), 0)::bigint,
+  COALESCE(SUM(total_tokens), 0)::bigint,
+  COUNT(DISTINCT (conversation_id, turn_id))::bigint,
+  bool_or(estimated)
+FROM iam.cubebox_turn_usage
+WHERE tenant_uuid = $1::uuid
+  AND recorded_at >= $2
+  AND recorded_at < $3
+GROUP BY key
+ORDER BY key ASC;
+`, tenantID, query.From, query.To)
+	if err != nil {
+		return UsageReport{}, err
+	}
+	defer rows.Close()
+	report := UsageReport{
+		From:    query.From.UTC().Format(time.RFC3339),
+		To:      query.To.UTC().Format(time.RFC3339),
+		GroupBy: query.GroupBy,
+		Rows:    []UsageReportRow{},
+		Total:   UsageReportRow{Key: "total"},
+	}
+	for rows.Next() {
+		var row UsageReportRow
+		if err := rows.Scan(&row.Key, &row.PromptTokens, &row.CompletionTokens, &row.TotalTokens, &row.Turns, &row.Estimated); err != nil {
+			return UsageReport{}, err
+		}
+		report.Rows = append(report.Rows, row)
+		report.Total.PromptTokens += row.PromptTokens
+		report.Total.CompletionTokens += row.CompletionTokens
+		report.Total.TotalTokens += row.TotalTokens
+		report.Total.Estimated = report.Total.Estimated || row.Estimated
+	}
+	if err := rows.Err(); err != nil {
+		return UsageReport{}, err
+	}
+	// Turns is not additive across principal/model groups, so the total is counted separately.
+	if err := tx.QueryRow(ctx, `
+SELECT COUNT(DISTINCT (conversation_id, turn_id))::bigint
+FROM iam.cubebox_turn_usage
+WHERE tenant_uuid = $1::uuid
+  AND recorded_at >= $2
+  AND recorded_at < $3;
+`, tenantID, query.From, query.To).Scan(&report.Total.Turns); err != nil {
+		return UsageReport{}, err
+	}
+	return report, tx.Commit(ctx)
+}
+
+// listTokenBudgets returns the tenant-wide budget and, when principalID is set, that principal's budget;
+// with principalID empty it returns every budget of the tenant.
+func listTokenBudgets(ctx context.Context, tx pgx.Tx, tenantID string, principalID string) ([]TokenBudget, error) {
+	rows, err := tx.Query(ctx, `
+SELECT principal_id, daily_token_limit, monthly_token_limit, updated_at
+FROM iam.cubebox_token_budgets
+WHERE tenant_uuid = $1::uuid
+  AND ($2 = '' OR principal_id IN ('', $2))
+ORDER BY principal_id ASC;
+`, tenantID, strings.TrimSpace(principalID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []TokenBudget{}
+	for rows.Next() {
+		var budget TokenBudget
+		var updatedAt time.Time
+		if err := rows.Scan(&budget.PrincipalID, &budget.DailyTokenLimit, &budget.MonthlyTokenLimit, &updatedAt); err != nil {
+			return nil, err
+		}
+		budget.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+		out = append(out, budget)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,340 @@
+package cubebox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type usageStoreStub struct {
+	snapshot    TokenBudgetSnapshot
+	snapshotErr error
+	records     []UsageRecord
+}
+
+func (s *usageStoreStub) RecordTurnUsage(_ context.Context, records []UsageRecord) error {
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func (s *usageStoreStub) GetTokenBudgetSnapshot(context.Context, string, string, time.Time) (TokenBudgetSnapshot, error) {
+	return s.snapshot, s.snapshotErr
+}
+
+func usageTestGateway(adapter ProviderAdapter, usage UsageStore) *GatewayService {
+	service := NewGatewayService(
+		NewRuntime(),
+		runtimeConfigReaderStub{
+			config: ActiveModelRuntimeConfig{
+				Selection:  ActiveModelSelection{ProviderID: "provider-1", ModelSlug: "gpt-4.1"},
+				Provider:   ModelProvider{ID: "provider-1", ProviderType: "openai-compatible", BaseURL: "https://example.invalid/v1", Enabled: true},
+				Credential: ModelCredential{SecretRef: "env://OPENAI_API_KEY", Active: true},
+			},
+		},
+		adapter,
+		secretResolverStub{secret: "sk-test"},
+	).WithUsageStore(usage)
+	service.now = func() time.Time { return time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC) }
+	return service
+}
+
+func usageTestRequest() GatewayStreamRequest {
+	return GatewayStreamRequest{
+		TenantID:       "tenant-1",
+		PrincipalID:    "principal-1",
+		ConversationID: "conv-1",
+		Prompt:         "hello",
+		NextSequence:   1,
+	}
+}
+
+func TestOpenAICompatibleStreamReadsUsageAfterFinishReason(t *testing.T) {
+	var sawBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		sawBody = string(payload)
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"你好\"},\"finish_reason\":null}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"choices\":[],\"usage\":{\"prompt_tokens\":12,\"completion_tokens\":3,\"total_tokens\":15}}\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	stream, err := NewOpenAICompatibleAdapter(server.Client()).StreamChatCompletion(context.Background(), ProviderChatRequest{
+		BaseURL: server.URL,
+		APIKey:  "sk-test",
+		Model:   "gpt-4.1",
+		Input:   "hello",
+	})
+	if err != nil {
+		t.Fatalf("stream chat completion: %v", err)
+	}
+	defer func() { _ = stream.Close() }()
+
+	if chunk, err := stream.Recv(); err != nil || chunk.Delta != "你好" {
+		t.Fatalf("chunk=%+v err=%v", chunk, err)
+	}
+	done, err := stream.Recv()
+	if err != nil || !done.Done {
+		t.Fatalf("done=%+v err=%v", done, err)
+	}
+	if done.Usage == nil || *done.Usage != (TokenUsage{PromptTokens: 12, CompletionTokens: 3}) {
+		t.Fatalf("usage=%+v", done.Usage)
+	}
+	if !strings.Contains(sawBody, `"stream_options":{"include_usage":true}`) {
+		t.Fatalf("body=%s", sawBody)
+	}
+}
+
+func TestGatewayServiceRecordsMeteredTurnUsage(t *testing.T) {
+	usage := &usageStoreStub{}
+	reported := &TokenUsage{PromptTokens: 20, CompletionTokens: 5}
+	adapter := NewMeteredProviderAdapter(&providerAdapterStub{stream: &providerChunkStub{chunks: []ProviderChatChunk{{Delta: "你好"}, {Done: true, Usage: reported}}}})
+	service := usageTestGateway(adapter, usage)
+	request := usageTestRequest()
+	sink := &eventSinkStub{}
+
+	ctx, admitted := service.AdmitTurn(context.Background(), request, &appendEventStoreStub{}, sink)
+	if !admitted {
+		t.Fatalf("turn not admitted: %+v", sink.events)
+	}
+	service.StreamTurn(ctx, request, &appendEventStoreStub{}, sink)
+	if err := service.RecordTurnUsage(ctx, request); err != nil {
+		t.Fatalf("RecordTurnUsage err=%v", err)
+	}
+
+	completed := sink.events[len(sink.events)-1]
+	if completed.Payload["status"] != "completed" || completed.Payload["total_tokens"] != int64(25) || completed.Payload["usage_estimated"] != false {
+		t.Fatalf("completed payload=%+v", completed.Payload)
+	}
+	want := []UsageRecord{{
+		TenantID:       "tenant-1",
+		PrincipalID:    "principal-1",
+		ConversationID: "conv-1",
+		TurnID:         "turn_seq_1",
+		ProviderID:     "provider-1",
+		ModelSlug:      "gpt-4.1",
+		Usage:          TokenUsage{PromptTokens: 20, CompletionTokens: 5},
+		RecordedAt:     time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+	}}
+	if !reflect.DeepEqual(usage.records, want) {
+		t.Fatalf("records=%+v", usage.records)
+	}
+}
+
+func TestMeteredProviderAdapterEstimatesWhenProviderReportsNoUsage(t *testing.T) {
+	meter := NewUsageMeter()
+	ctx := WithUsageMeter(context.Background(), meter)
+	adapter := NewMeteredProviderAdapter(&providerAdapterStub{stream: &providerChunkStub{chunks: []ProviderChatChunk{{Delta: "部分回答"}}}})
+	request := ProviderChatRequest{Model: "gpt-4.1", Messages: []PromptItem{{Role: "user", Content: "列出所有组织"}}}
+
+	stream, err := adapter.StreamChatCompletion(ctx, request)
+	if err != nil {
+		t.Fatalf("stream err=%v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("recv err=%v", err)
+	}
+	// Closed mid-answer: what arrived is still counted, once.
+	_ = stream.Close()
+	_ = stream.Close()
+
+	want := TokenUsage{PromptTokens: int64(estimateTextTokens("列出所有组织")), CompletionTokens: int64(estimateTextTokens("部分回答")), Estimated: true}
+	if got := meter.Total(); got != want {
+		t.Fatalf("total=%+v want %+v", got, want)
+	}
+	if records := meter.Records(usageTestRequest(), time.Now()); records != nil {
+		t.Fatalf("unbound meter produced records=%+v", records)
+	}
+
+	// Without a meter in the context the stream passes through untouched.
+	inner := &providerChunkStub{}
+	passthrough, _ := NewMeteredProviderAdapter(&providerAdapterStub{stream: inner}).StreamChatCompletion(context.Background(), request)
+	if passthrough != ProviderChatStream(inner) {
+		t.Fatalf("expected passthrough stream, got %T", passthrough)
+	}
+}
+
+func TestGatewayServiceAdmitTurnRejectsExhaustedBudget(t *testing.T) {
+	adapter := &providerAdapterStub{stream: &providerChunkStub{}}
+	usage := &usageStoreStub{snapshot: TokenBudgetSnapshot{
+		Tenant:            &TokenBudget{MonthlyTokenLimit: 1000},
+		TenantMonthlyUsed: 1000,
+	}}
+	service := usageTestGateway(adapter, usage)
+	store := &appendEventStoreStub{}
+	sink := &eventSinkStub{}
+
+	if _, admitted := service.AdmitTurn(context.Background(), usageTestRequest(), store, sink); admitted {
+		t.Fatal("expected turn to be refused")
+	}
+	if types := collectEventTypes(store.events); !reflect.DeepEqual(types, []string{"turn.error", "turn.completed"}) {
+		t.Fatalf("event types=%v", types)
+	}
+	if store.events[0].Payload["code"] != "ai_budget_exceeded" || *store.events[0].TurnID != "turn_seq_1" || store.events[1].Payload["status"] != "failed" {
+		t.Fatalf("events=%+v", store.events)
+	}
+	if adapter.requestCount != 0 {
+		t.Fatalf("provider called %d times", adapter.requestCount)
+	}
+
+	usage.snapshot = TokenBudgetSnapshot{}
+	usage.snapshotErr = errors.New("db down")
+	store.events = nil
+	if _, admitted := service.AdmitTurn(context.Background(), usageTestRequest(), store, &eventSinkStub{}); admitted {
+		t.Fatal("expected turn to be refused when budgets cannot be read")
+	}
+	if store.events[0].Payload["code"] != "cubebox_turn_stream_failed" {
+		t.Fatalf("events=%+v", store.events)
+	}
+}
+
+func TestGatewayServiceAdmitTurnReservesUntilUsageIsRecorded(t *testing.T) {
+	adapter := &providerAdapterStub{stream: &providerChunkStub{}}
+	usage := &usageStoreStub{snapshot: TokenBudgetSnapshot{Principal: &TokenBudget{PrincipalID: "principal-1", DailyTokenLimit: 2 * turnReservationReplyTokens}}}
+	service := usageTestGateway(adapter, usage)
+	request := usageTestRequest()
+
+	if _, admitted := service.AdmitTurn(context.Background(), request, &appendEventStoreStub{}, &eventSinkStub{}); !admitted {
+		t.Fatal("first turn must be admitted")
+	}
+	second, admitted := service.AdmitTurn(context.Background(), request, &appendEventStoreStub{}, &eventSinkStub{})
+	if !admitted {
+		t.Fatal("second turn must be admitted")
+	}
+	// Nothing is recorded yet, but the two turns in flight have reserved the principal's daily budget.
+	store := &appendEventStoreStub{}
+	if _, admitted := service.AdmitTurn(context.Background(), request, store, &eventSinkStub{}); admitted || store.events[0].Payload["code"] != "ai_budget_exceeded" {
+		t.Fatalf("third turn must be refused, events=%+v", store.events)
+	}
+	other := request
+	other.PrincipalID = "principal-2"
+	if _, admitted := service.AdmitTurn(context.Background(), other, &appendEventStoreStub{}, &eventSinkStub{}); !admitted {
+		t.Fatal("another principal's turn must be admitted")
+	}
+
+	if err := service.RecordTurnUsage(second, request); err != nil {
+		t.Fatal(err)
+	}
+	// Settling twice must not release more than the turn reserved.
+	_ = service.RecordTurnUsage(second, request)
+	if _, admitted := service.AdmitTurn(context.Background(), request, &appendEventStoreStub{}, &eventSinkStub{}); !admitted {
+		t.Fatal("a settled turn must release its reservation")
+	}
+	if _, admitted := service.AdmitTurn(context.Background(), request, &appendEventStoreStub{}, &eventSinkStub{}); admitted {
+		t.Fatal("the released reservation is taken again by the next turn")
+	}
+}
+
+func TestEvaluateTokenBudget(t *testing.T) {
+	snapshot := TokenBudgetSnapshot{
+		Tenant:               &TokenBudget{DailyTokenLimit: 500, MonthlyTokenLimit: 5000},
+		Principal:            &TokenBudget{PrincipalID: "p", DailyTokenLimit: 100},
+		TenantDailyUsed:      499,
+		TenantMonthlyUsed:    4000,
+		PrincipalDailyUsed:   99,
+		PrincipalMonthlyUsed: 99999,
+	}
+	// The principal has no monthly limit, so its monthly usage is never checked.
+	if status := EvaluateTokenBudget(snapshot); status.Exceeded {
+		t.Fatalf("status=%+v", status)
+	}
+	snapshot.PrincipalDailyUsed = 100
+	snapshot.TenantDailyUsed = 500
+	if status := EvaluateTokenBudget(snapshot); status != (TokenBudgetStatus{Exceeded: true, Scope: TokenBudgetScopePrincipal, Window: TokenBudgetWindowDaily, Limit: 100, Used: 100}) {
+		t.Fatalf("status=%+v", status)
+	}
+	snapshot.Principal = nil
+	if status := EvaluateTokenBudget(snapshot); status.Scope != TokenBudgetScopeTenant || status.Window != TokenBudgetWindowDaily {
+		t.Fatalf("status=%+v", status)
+	}
+
+	day, month := TokenBudgetWindows(time.Date(2026, 10, 19, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*3600)))
+	if !day.Equal(time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)) || !month.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("day=%s month=%s", day, month)
+	}
+}
+
+func TestValidateTokenBudgetAndUsageReportQuery(t *testing.T) {
+	for _, budget := range []TokenBudget{
+		{PrincipalID: "not-a-uuid", DailyTokenLimit: 1},
+		{DailyTokenLimit: -1},
+		{DailyTokenLimit: 200, MonthlyTokenLimit: 100},
+	} {
+		if err := ValidateTokenBudget(budget); !errors.Is(err, ErrTokenBudgetInvalid) {
+			t.Fatalf("budget=%+v err=%v", budget, err)
+		}
+	}
+	if err := ValidateTokenBudget(TokenBudget{PrincipalID: "00000000-0000-0000-0000-000000000001", DailyTokenLimit: 100}); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	query, err := NormalizeUsageReportQuery(UsageReportQuery{}, now)
+	if err != nil || query.GroupBy != UsageReportGroupByDay || !query.From.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) || !query.To.Equal(now) {
+		t.Fatalf("query=%+v err=%v", query, err)
+	}
+	for _, bad := range []UsageReportQuery{
+		{GroupBy: "tenant"},
+		{From: now, To: now},
+		{From: now.AddDate(-2, 0, 0), To: now},
+	} {
+		if _, err := NormalizeUsageReportQuery(bad, now); !errors.Is(err, ErrUsageReportInvalid) {
+			t.Fatalf("query=%+v err=%v", bad, err)
+		}
+	}
+}
+
+func TestStoreTokenUsageQueries(t *testing.T) {
+	tx := &txStub{
+		rowsQueue: []pgx.Rows{
+			&rowsStub{rows: [][]any{
+				{"", int64(0), int64(5000), time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+				{"principal-1", int64(100), int64(0), time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)},
+			}},
+		},
+		rowQueue: []pgx.Row{rowStub{vals: []any{int64(40), int64(4000), int64(10), int64(90)}}},
+	}
+	store := NewStore(tx)
+	snapshot, err := store.GetTokenBudgetSnapshot(context.Background(), "tenant-1", "principal-1", time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetTokenBudgetSnapshot err=%v", err)
+	}
+	if snapshot.Tenant == nil || snapshot.Tenant.MonthlyTokenLimit != 5000 || snapshot.Principal == nil || snapshot.Principal.DailyTokenLimit != 100 ||
+		snapshot.TenantMonthlyUsed != 4000 || snapshot.PrincipalDailyUsed != 10 {
+		t.Fatalf("snapshot=%+v", snapshot)
+	}
+
+	records := []UsageRecord{{TenantID: "tenant-1", PrincipalID: "principal-1", ConversationID: "conv-1", TurnID: "turn_seq_1", ModelSlug: "gpt-4.1", Usage: TokenUsage{PromptTokens: 7, CompletionTokens: 2, Estimated: true}}}
+	if err := store.RecordTurnUsage(context.Background(), records); err != nil {
+		t.Fatalf("RecordTurnUsage err=%v", err)
+	}
+	if len(tx.execSQLs) != 1 || !strings.Contains(tx.execSQLs[0], "ON CONFLICT (tenant_uuid, conversation_id, turn_id, model_slug)") {
+		t.Fatalf("exec=%v", tx.execSQLs)
+	}
+	if args := tx.execArgs[0]; args[3] != "turn_seq_1" || args[6] != int64(7) || args[7] != int64(2) || args[8] != true {
+		t.Fatalf("args=%v", args)
+	}
+
+	if _, err := store.UpsertTokenBudget(context.Background(), "tenant-1", "principal-1", TokenBudget{PrincipalID: "x", DailyTokenLimit: 1}); !errors.Is(err, ErrTokenBudgetInvalid) {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := store.UpsertTokenBudget(context.Background(), "tenant-1", "principal-1", TokenBudget{}); err != nil {
+		t.Fatalf("clear budget err=%v", err)
+	}
+	if last := tx.execSQLs[len(tx.execSQLs)-1]; !strings.Contains(last, "DELETE FROM iam.cubebox_token_budgets") {
+		t.Fatalf("expected clearing a budget to delete it, got %s", last)
+	}
+	if _, err := store.GetUsageReport(context.Background(), "tenant-1", UsageReportQuery{GroupBy: "tenant"}); !errors.Is(err, ErrUsageReportInvalid) {
+		t.Fatalf("err=%v", err)
+	}
+}
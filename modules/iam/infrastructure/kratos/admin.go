@@ -0,0 +1,154 @@
+package kratos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type AdminClient struct {
+	adminBaseURL string
+	httpClient   *http.Client
+}
+
+type CreateIdentityRequest struct {
+	SchemaID   string
+	Traits     map[string]any
+	Identifier string
+	Password   string
+}
+
+func NewAdmin(adminBaseURL string) (*AdminClient, error) {
+	adminBaseURL, err := normalizeBaseURL(adminBaseURL, "admin")
+	if err != nil {
+		return nil, err
+	}
+	return &AdminClient{
+		adminBaseURL: adminBaseURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}, nil
+}
+
+// CreateIdentity creates a password identity. An existing identifier surfaces as *HTTPError with 409.
+func (c *AdminClient) CreateIdentity(ctx context.Context, in CreateIdentityRequest) (Identity, error) {
+	schemaID := strings.TrimSpace(in.SchemaID)
+	if schemaID == "" {
+		schemaID = "default"
+	}
+	if strings.TrimSpace(in.Identifier) == "" || in.Password == "" {
+		return Identity{}, errors.New("kratos: missing identifier or password")
+	}
+
+	body, _ := json.Marshal(map[string]any{
+		"schema_id": schemaID,
+		"traits":    in.Traits,
+		"credentials": map[string]any{
+			"password": map[string]any{
+				"identifiers": []string{strings.TrimSpace(in.Identifier)},
+				"config":      map[string]any{"password": in.Password},
+			},
+		},
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.adminBaseURL+"/admin/identities", bytes.NewReader(body))
+	if err != nil {
+		return Identity{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Identity{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return Identity{}, readHTTPError(resp)
+	}
+
+	var out Identity
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Identity{}, err
+	}
+	return out, nil
+}
+
+// FindIdentityByIdentifier looks up the identity whose credentials use identifier; ok is false when there
+// is none.
+func (c *AdminClient) FindIdentityByIdentifier(ctx context.Context, identifier string) (Identity, bool, error) {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return Identity{}, false, errors.New("kratos: missing identifier")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.adminBaseURL+"/admin/identities?credentials_identifier="+url.QueryEscape(identifier), nil)
+	if err != nil {
+		return Identity{}, false, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Identity{}, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return Identity{}, false, readHTTPError(resp)
+	}
+
+	var out []Identity
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Identity{}, false, err
+	}
+	if len(out) == 0 {
+		return Identity{}, false, nil
+	}
+	return out[0], true, nil
+}
+
+// ResetPassword replaces the password credential of an identity, keeping its identifier and traits. Kratos
+// replaces credentials wholesale on update, so any other credential the identity had is dropped.
+func (c *AdminClient) ResetPassword(ctx context.Context, ident Identity, identifier string, password string) error {
+	if strings.TrimSpace(ident.ID) == "" || strings.TrimSpace(identifier) == "" || password == "" {
+		return errors.New("kratos: missing identity, identifier or password")
+	}
+	schemaID := strings.TrimSpace(ident.SchemaID)
+	if schemaID == "" {
+		schemaID = "default"
+	}
+	body, _ := json.Marshal(map[string]any{
+		"schema_id": schemaID,
+		"traits":    ident.Traits,
+		"state":     "active",
+		"credentials": map[string]any{
+			"password": map[string]any{
+				"identifiers": []string{strings.TrimSpace(identifier)},
+				"config":      map[string]any{"password": password},
+			},
+		},
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.adminBaseURL+"/admin/identities/"+url.PathEscape(ident.ID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return readHTTPError(resp)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package kratos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewAdmin(t *testing.T) {
+	if _, err := NewAdmin(" "); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := NewAdmin("ftp://localhost:4434"); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := NewAdmin("http://localhost:4434/"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdminClient_CreateIdentity(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/admin/identities" {
+			t.Fatalf("method=%s path=%s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got["schema_id"] == "conflict" {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "kid-1", "traits": got["traits"]})
+	}))
+	defer srv.Close()
+
+	c, err := NewAdmin(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ident, err := c.CreateIdentity(context.Background(), CreateIdentityRequest{
+		Traits:     map[string]any{"email": "a@example.invalid"},
+		Identifier: " t1:a@example.invalid ",
+		Password:   "pw",
+	})
+	if err != nil || ident.ID != "kid-1" {
+		t.Fatalf("ident=%+v err=%v", ident, err)
+	}
+	creds := got["credentials"].(map[string]any)["password"].(map[string]any)
+	if got["schema_id"] != "default" || creds["identifiers"].([]any)[0] != "t1:a@example.invalid" {
+		t.Fatalf("got=%v", got)
+	}
+
+	_, err = c.CreateIdentity(context.Background(), CreateIdentityRequest{SchemaID: "conflict", Identifier: "x", Password: "pw"})
+	if he, ok := errors.AsType[*HTTPError](err); !ok || he.StatusCode != http.StatusConflict {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := c.CreateIdentity(context.Background(), CreateIdentityRequest{Identifier: "x"}); err == nil {
+		t.Fatal("expected missing password error")
+	}
+}
+
+func TestAdminClient_FindIdentityAndResetPassword(t *testing.T) {
+	var put map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/admin/identities":
+			switch r.URL.Query().Get("credentials_identifier") {
+			case "t1:a@example.invalid":
+				_ = json.NewEncoder(w).Encode([]any{map[string]any{"id": "kid-1", "schema_id": "tenant", "traits": map[string]any{"email": "a@example.invalid"}}})
+			case "broken":
+				w.WriteHeader(http.StatusInternalServerError)
+			default:
+				_ = json.NewEncoder(w).Encode([]any{})
+			}
+		case r.Method == http.MethodPut && r.URL.Path == "/admin/identities/kid-1":
+			if err := json.NewDecoder(r.Body).Decode(&put); err != nil {
+				t.Fatal(err)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "kid-1"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewAdmin(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ident, ok, err := c.FindIdentityByIdentifier(context.Background(), " t1:a@example.invalid ")
+	if err != nil || !ok || ident.ID != "kid-1" || ident.SchemaID != "tenant" {
+		t.Fatalf("ident=%+v ok=%v err=%v", ident, ok, err)
+	}
+	if _, ok, err := c.FindIdentityByIdentifier(context.Background(), "t1:b@example.invalid"); err != nil || ok {
+		t.Fatalf("ok=%v err=%v", ok, err)
+	}
+	if _, _, err := c.FindIdentityByIdentifier(context.Background(), "broken"); err == nil {
+		t.Fatal("expected http error")
+	}
+	if _, _, err := c.FindIdentityByIdentifier(context.Background(), " "); err == nil {
+		t.Fatal("expected missing identifier error")
+	}
+
+	if err := c.ResetPassword(context.Background(), ident, "t1:a@example.invalid", "new-pw"); err != nil {
+		t.Fatal(err)
+	}
+	creds := put["credentials"].(map[string]any)["password"].(map[string]any)
+	if put["schema_id"] != "tenant" || put["traits"].(map[string]any)["email"] != "a@example.invalid" || creds["config"].(map[string]any)["password"] != "new-pw" || creds["identifiers"].([]any)[0] != "t1:a@example.invalid" {
+		t.Fatalf("put=%v", put)
+	}
+	if err := c.ResetPassword(context.Background(), Identity{ID: "kid-9"}, "x", "pw"); err == nil {
+		t.Fatal("expected http error")
+	}
+	if err := c.ResetPassword(context.Background(), ident, "t1:a@example.invalid", ""); err == nil {
+		t.Fatal("expected missing password error")
+	}
+}
@@ -19,9 +19,10 @@ type Client struct {
 }
 
 type Identity struct {
-	ID     string         `json:"id"`
-	Traits map[string]any `json:"traits"`
-	Raw    map[string]any `json:"-"`
+	ID       string         `json:"id"`
+	SchemaID string         `json:"schema_id,omitempty"`
+	Traits   map[string]any `json:"traits"`
+	Raw      map[string]any `json:"-"`
 }
 
 type HTTPError struct {
@@ -38,20 +39,9 @@ func (e *HTTPError) Error() string {
 }
 
 func New(publicBaseURL string) (*Client, error) {
-	publicBaseURL = strings.TrimSpace(publicBaseURL)
-	publicBaseURL = strings.TrimRight(publicBaseURL, "/")
-	if publicBaseURL == "" {
-		return nil, errors.New("kratos: missing public base url")
-	}
-	u, err := url.Parse(publicBaseURL)
+	publicBaseURL, err := normalizeBaseURL(publicBaseURL, "public")
 	if err != nil {
-		return nil, errors.New("kratos: invalid public base url")
-	}
-	if u.Scheme != "http" && u.Scheme != "https" {
-		return nil, errors.New("kratos: invalid public base url scheme")
-	}
-	if u.Host == "" {
-		return nil, errors.New("kratos: invalid public base url host")
+		return nil, err
 	}
 	return &Client{
 		publicBaseURL: publicBaseURL,
@@ -165,6 +155,25 @@ func (c *Client) submitLoginPassword(ctx context.Context, flowID string, identif
 	return out.SessionToken, nil
 }
 
+func normalizeBaseURL(raw string, kind string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	raw = strings.TrimRight(raw, "/")
+	if raw == "" {
+		return "", errors.New("kratos: missing " + kind + " base url")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", errors.New("kratos: invalid " + kind + " base url")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", errors.New("kratos: invalid " + kind + " base url scheme")
+	}
+	if u.Host == "" {
+		return "", errors.New("kratos: invalid " + kind + " base url host")
+	}
+	return raw, nil
+}
+
 func readHTTPError(resp *http.Response) error {
 	const maxBody = 4096
 	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxBody))
@@ -0,0 +1,132 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DictChangeChannel is notified by iam.notify_dict_changed() whenever a dict or dict value event commits.
+const DictChangeChannel = "iam_dict_changed"
+
+const (
+	defaultDictChangeRetryDelay    = time.Second
+	defaultDictChangeMaxRetryDelay = 30 * time.Second
+)
+
+type DictChange struct {
+	TenantID string `json:"tenant_uuid"`
+	DictCode string `json:"dict_code"`
+}
+
+type DictChangeSink interface {
+	Invalidate(tenantID string, dictCode string)
+	InvalidateAll()
+}
+
+type DictChangeConn interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+	Close(ctx context.Context) error
+}
+
+type DictChangeConnector func(ctx context.Context) (DictChangeConn, error)
+
+type DictChangeListener struct {
+	Connect       DictChangeConnector
+	Sink          DictChangeSink
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+	OnError       func(error)
+}
+
+func ParseDictChange(payload string) (DictChange, error) {
+	var change DictChange
+	if err := json.Unmarshal([]byte(payload), &change); err != nil {
+		return DictChange{}, err
+	}
+	change.TenantID = strings.TrimSpace(change.TenantID)
+	change.DictCode = strings.TrimSpace(change.DictCode)
+	if change.TenantID == "" || change.DictCode == "" {
+		return DictChange{}, errors.New("dict change payload missing tenant_uuid or dict_code")
+	}
+	return change, nil
+}
+
+// Run listens until ctx is done. Any gap in the notification stream (initial subscribe, reconnect,
+// unparsable payload) invalidates the whole sink, because changes committed during the gap are unknown.
+func (l *DictChangeListener) Run(ctx context.Context) error {
+	if l.Connect == nil || l.Sink == nil {
+		return errors.New("dict change listener not configured")
+	}
+	delay := l.retryDelay()
+	for {
+		subscribed, err := l.listenOnce(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		l.Sink.InvalidateAll()
+		if err != nil && l.OnError != nil {
+			l.OnError(err)
+		}
+		if subscribed {
+			delay = l.retryDelay()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, l.maxRetryDelay())
+	}
+}
+
+func (l *DictChangeListener) listenOnce(ctx context.Context) (bool, error) {
+	conn, err := l.Connect(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+DictChangeChannel); err != nil {
+		return false, err
+	}
+	l.Sink.InvalidateAll()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		if notification == nil || notification.Channel != DictChangeChannel {
+			continue
+		}
+		change, err := ParseDictChange(notification.Payload)
+		if err != nil {
+			l.Sink.InvalidateAll()
+			if l.OnError != nil {
+				l.OnError(err)
+			}
+			continue
+		}
+		l.Sink.Invalidate(change.TenantID, change.DictCode)
+	}
+}
+
+func (l *DictChangeListener) retryDelay() time.Duration {
+	if l.RetryDelay > 0 {
+		return l.RetryDelay
+	}
+	return defaultDictChangeRetryDelay
+}
+
+func (l *DictChangeListener) maxRetryDelay() time.Duration {
+	if l.MaxRetryDelay > 0 {
+		return l.MaxRetryDelay
+	}
+	return defaultDictChangeMaxRetryDelay
+}
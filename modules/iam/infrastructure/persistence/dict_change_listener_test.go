@@ -0,0 +1,125 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type recordingDictChangeSink struct {
+	mu            sync.Mutex
+	invalidated   []DictChange
+	invalidateAll int
+}
+
+func (s *recordingDictChangeSink) Invalidate(tenantID string, dictCode string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invalidated = append(s.invalidated, DictChange{TenantID: tenantID, DictCode: dictCode})
+}
+
+func (s *recordingDictChangeSink) InvalidateAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invalidateAll++
+}
+
+type scriptedDictChangeConn struct {
+	execErr       error
+	notifications []*pgconn.Notification
+	listened      []string
+	closed        bool
+}
+
+func (c *scriptedDictChangeConn) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	c.listened = append(c.listened, sql)
+	return pgconn.CommandTag{}, c.execErr
+}
+
+func (c *scriptedDictChangeConn) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	if len(c.notifications) == 0 {
+		return nil, errors.New("connection lost")
+	}
+	n := c.notifications[0]
+	c.notifications = c.notifications[1:]
+	return n, nil
+}
+
+func (c *scriptedDictChangeConn) Close(context.Context) error {
+	c.closed = true
+	return nil
+}
+
+func TestParseDictChange(t *testing.T) {
+	change, err := ParseDictChange(`{"tenant_uuid":" t1 ","dict_code":"org_type"}`)
+	if err != nil || change.TenantID != "t1" || change.DictCode != "org_type" {
+		t.Fatalf("change=%+v err=%v", change, err)
+	}
+	if _, err := ParseDictChange(`{"tenant_uuid":"t1"}`); err == nil {
+		t.Fatal("expected missing dict_code error")
+	}
+	if _, err := ParseDictChange(`not-json`); err == nil {
+		t.Fatal("expected json error")
+	}
+}
+
+func TestDictChangeListenerRun_NotConfigured(t *testing.T) {
+	if err := (&DictChangeListener{}).Run(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestDictChangeListenerRun_InvalidatesAndReconnects(t *testing.T) {
+	sink := &recordingDictChangeSink{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := &scriptedDictChangeConn{notifications: []*pgconn.Notification{
+		{Channel: "other", Payload: `{"tenant_uuid":"t9","dict_code":"x"}`},
+		{Channel: DictChangeChannel, Payload: `{"tenant_uuid":"t1","dict_code":"org_type"}`},
+		{Channel: DictChangeChannel, Payload: `broken`},
+	}}
+	second := &scriptedDictChangeConn{execErr: errors.New("listen failed")}
+	conns := []*scriptedDictChangeConn{first, second}
+	var errs []error
+	connects := 0
+
+	listener := &DictChangeListener{
+		Connect: func(context.Context) (DictChangeConn, error) {
+			connects++
+			switch connects {
+			case 1, 2:
+				return conns[connects-1], nil
+			case 3:
+				return nil, errors.New("dial failed")
+			default:
+				cancel()
+				return nil, context.Canceled
+			}
+		},
+		Sink:       sink,
+		RetryDelay: time.Millisecond,
+		OnError:    func(err error) { errs = append(errs, err) },
+	}
+
+	if err := listener.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err=%v", err)
+	}
+	if len(sink.invalidated) != 1 || sink.invalidated[0] != (DictChange{TenantID: "t1", DictCode: "org_type"}) {
+		t.Fatalf("invalidated=%+v", sink.invalidated)
+	}
+	// subscribe + broken payload + three failed rounds
+	if sink.invalidateAll != 5 {
+		t.Fatalf("invalidateAll=%d", sink.invalidateAll)
+	}
+	if len(errs) != 4 {
+		t.Fatalf("errs=%v", errs)
+	}
+	if !first.closed || !second.closed || first.listened[0] != "LISTEN "+DictChangeChannel {
+		t.Fatalf("first=%+v second=%+v", first, second)
+	}
+}
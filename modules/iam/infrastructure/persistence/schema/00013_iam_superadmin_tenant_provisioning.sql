@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS iam.superadmin_tenant_provisioning_runs (
+  id bigserial PRIMARY KEY,
+  request_id text NOT NULL,
+  tenant_uuid uuid NULL REFERENCES iam.tenants(id) ON DELETE SET NULL,
+  status text NOT NULL DEFAULT 'running',
+  input jsonb NOT NULL DEFAULT '{}'::jsonb,
+  steps jsonb NOT NULL DEFAULT '[]'::jsonb,
+  actor text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT superadmin_tenant_provisioning_runs_request_id_nonempty_check CHECK (btrim(request_id) <> ''),
+  CONSTRAINT superadmin_tenant_provisioning_runs_status_check CHECK (status IN ('running', 'succeeded', 'failed')),
+  CONSTRAINT superadmin_tenant_provisioning_runs_input_is_object_check CHECK (jsonb_typeof(input) = 'object'),
+  CONSTRAINT superadmin_tenant_provisioning_runs_steps_is_array_check CHECK (jsonb_typeof(steps) = 'array')
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS superadmin_tenant_provisioning_runs_request_id_unique ON iam.superadmin_tenant_provisioning_runs (request_id);
+CREATE INDEX IF NOT EXISTS superadmin_tenant_provisioning_runs_tenant_idx ON iam.superadmin_tenant_provisioning_runs (tenant_uuid, id);
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE ON iam.superadmin_tenant_provisioning_runs TO superadmin_runtime';
+    EXECUTE 'GRANT USAGE, SELECT ON SEQUENCE iam.superadmin_tenant_provisioning_runs_id_seq TO superadmin_runtime';
+    EXECUTE 'GRANT SELECT ON iam.dict_events, iam.dict_value_events, iam.dicts, iam.dict_value_segments TO superadmin_runtime';
+    EXECUTE 'GRANT EXECUTE ON FUNCTION iam.seed_builtin_authz_roles(uuid) TO superadmin_runtime';
+  END IF;
+END
+$$;
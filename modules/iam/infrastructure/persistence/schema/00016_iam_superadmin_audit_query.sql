@@ -0,0 +1,4 @@
+-- Keyset indexes for the superadmin audit log API: filters by actor, action or time range page by id.
+CREATE INDEX IF NOT EXISTS superadmin_audit_logs_actor_idx ON iam.superadmin_audit_logs (actor, id);
+CREATE INDEX IF NOT EXISTS superadmin_audit_logs_action_idx ON iam.superadmin_audit_logs (action text_pattern_ops, id);
+CREATE INDEX IF NOT EXISTS superadmin_audit_logs_created_at_idx ON iam.superadmin_audit_logs (created_at, id);
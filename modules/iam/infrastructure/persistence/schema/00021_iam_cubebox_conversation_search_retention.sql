@@ -0,0 +1,110 @@
+-- CubeBox conversation search: one document per conversation holding the text of its user messages and
+-- agent reply deltas, appended by a trigger in the event insert transaction. Messages are mostly Chinese,
+-- which text search configurations do not segment, so the document and the title are trigram indexed and
+-- searched with ILIKE.
+-- Retention: a tenant policy purges conversations archived for longer than archived_retention_days (0 keeps
+-- them forever); every run that deletes something leaves an audit row with its counts. Like the other
+-- cubebox tables these carry no RLS and are always filtered by tenant_uuid.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE IF NOT EXISTS iam.cubebox_conversation_search_docs (
+  tenant_uuid uuid NOT NULL,
+  conversation_id text NOT NULL,
+  body text NOT NULL DEFAULT '',
+  PRIMARY KEY (tenant_uuid, conversation_id),
+  CONSTRAINT cubebox_conversation_search_docs_conversation_fk FOREIGN KEY (tenant_uuid, conversation_id)
+    REFERENCES iam.cubebox_conversations(tenant_uuid, conversation_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS cubebox_conversation_search_docs_body_trgm
+  ON iam.cubebox_conversation_search_docs
+  USING gin (body gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS cubebox_conversations_title_trgm
+  ON iam.cubebox_conversations
+  USING gin (title gin_trgm_ops);
+
+CREATE OR REPLACE FUNCTION iam.cubebox_conversation_search_docs_append()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_text text;
+BEGIN
+  IF NEW.event_type = 'turn.user_message.accepted' THEN
+    v_text := E'\n' || COALESCE(NEW.payload->>'text', '') || E'\n';
+  ELSE
+    v_text := COALESCE(NEW.payload->>'delta', '');
+  END IF;
+  IF v_text = '' THEN
+    RETURN NEW;
+  END IF;
+  INSERT INTO iam.cubebox_conversation_search_docs (tenant_uuid, conversation_id, body)
+  VALUES (NEW.tenant_uuid, NEW.conversation_id, v_text)
+  ON CONFLICT (tenant_uuid, conversation_id) DO UPDATE
+  SET body = iam.cubebox_conversation_search_docs.body || EXCLUDED.body;
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS cubebox_conversation_events_search_docs ON iam.cubebox_conversation_events;
+CREATE TRIGGER cubebox_conversation_events_search_docs
+  AFTER INSERT ON iam.cubebox_conversation_events
+  FOR EACH ROW
+  WHEN (NEW.event_type IN ('turn.user_message.accepted', 'turn.agent_message.delta'))
+  EXECUTE FUNCTION iam.cubebox_conversation_search_docs_append();
+
+INSERT INTO iam.cubebox_conversation_search_docs (tenant_uuid, conversation_id, body)
+SELECT
+  tenant_uuid,
+  conversation_id,
+  string_agg(
+    CASE
+      WHEN event_type = 'turn.user_message.accepted' THEN E'\n' || COALESCE(payload->>'text', '') || E'\n'
+      ELSE COALESCE(payload->>'delta', '')
+    END,
+    '' ORDER BY sequence
+  )
+FROM iam.cubebox_conversation_events
+WHERE event_type IN ('turn.user_message.accepted', 'turn.agent_message.delta')
+GROUP BY tenant_uuid, conversation_id
+ON CONFLICT (tenant_uuid, conversation_id) DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS cubebox_conversations_tenant_archived_at_idx
+  ON iam.cubebox_conversations (tenant_uuid, archived_at)
+  WHERE archived;
+
+CREATE TABLE IF NOT EXISTS iam.cubebox_retention_policies (
+  tenant_uuid uuid PRIMARY KEY REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  archived_retention_days integer NOT NULL DEFAULT 0,
+  updated_by uuid NULL,
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT cubebox_retention_policies_days_check CHECK (archived_retention_days BETWEEN 0 AND 3650)
+);
+
+CREATE TABLE IF NOT EXISTS iam.cubebox_retention_runs (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL REFERENCES iam.tenants(id) ON DELETE CASCADE,
+  ran_at timestamptz NOT NULL DEFAULT now(),
+  cutoff timestamptz NOT NULL,
+  archived_retention_days integer NOT NULL,
+  purged_conversations bigint NOT NULL DEFAULT 0,
+  purged_events bigint NOT NULL DEFAULT 0,
+  CONSTRAINT cubebox_retention_runs_counts_nonnegative_check CHECK (purged_conversations >= 0 AND purged_events >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS cubebox_retention_runs_tenant_id_idx
+  ON iam.cubebox_retention_runs (tenant_uuid, id DESC);
+
+-- Offboarding verification counts residual rows in these tables; they cascade with the tenant and its conversations.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT ON ' ||
+      'iam.cubebox_conversation_search_docs, ' ||
+      'iam.cubebox_retention_policies, ' ||
+      'iam.cubebox_retention_runs ' ||
+      'TO superadmin_runtime';
+  END IF;
+END
+$$;
@@ -0,0 +1,5 @@
+-- CubeBox answer feedback is stored as turn.feedback conversation events. Reports and dataset exports read
+-- them tenant-wide by time, which the per-conversation event index cannot serve.
+CREATE INDEX IF NOT EXISTS cubebox_conversation_events_feedback_idx
+  ON iam.cubebox_conversation_events (tenant_uuid, created_at)
+  WHERE event_type = 'turn.feedback';
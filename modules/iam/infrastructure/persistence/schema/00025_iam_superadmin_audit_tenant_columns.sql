@@ -0,0 +1,21 @@
+-- Superadmin audit rows outlive the tenant they are about: the target tenant is a plain column (the FK used
+-- to null it when the tenant was hard-deleted) and its name is captured when the row is written.
+ALTER TABLE iam.superadmin_audit_logs
+  DROP CONSTRAINT IF EXISTS superadmin_audit_logs_target_tenant_uuid_fkey;
+ALTER TABLE iam.superadmin_audit_logs
+  ADD COLUMN IF NOT EXISTS target_tenant_name text NOT NULL DEFAULT '';
+
+-- Rows written before the change: recover targets nulled by a delete from the payload, then names from
+-- the live tenant or, for deleted tenants, the offboarding record.
+UPDATE iam.superadmin_audit_logs
+SET target_tenant_uuid = (payload->>'tenant_id')::uuid
+WHERE target_tenant_uuid IS NULL
+  AND payload->>'tenant_id' ~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$';
+UPDATE iam.superadmin_audit_logs l
+SET target_tenant_name = COALESCE(
+  (SELECT t.name FROM iam.tenants t WHERE t.id = l.target_tenant_uuid),
+  (SELECT r.tenant_name FROM iam.superadmin_tenant_offboarding_requests r
+   WHERE r.tenant_uuid = l.target_tenant_uuid ORDER BY r.id DESC LIMIT 1),
+  ''
+)
+WHERE l.target_tenant_uuid IS NOT NULL;
@@ -0,0 +1,14 @@
+-- A provisioning run is claimed on its status row for the duration of one runner instead of holding a
+-- transaction open; an expired claim can be taken over by a retry.
+ALTER TABLE iam.superadmin_tenant_provisioning_runs
+  ADD COLUMN IF NOT EXISTS claim_token text NULL,
+  ADD COLUMN IF NOT EXISTS claimed_until timestamptz NULL;
+
+-- The seed_builtin_roles step verifies the built-in roles after seeding them.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT ON iam.role_definitions TO superadmin_runtime';
+  END IF;
+END
+$$;
@@ -0,0 +1,256 @@
+package persistence
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookIDHeader        = "X-Webhook-Id"
+
+	defaultWebhookBatchSize    = 20
+	defaultWebhookMaxAttempts  = 8
+	defaultWebhookBaseBackoff  = 30 * time.Second
+	defaultWebhookMaxBackoff   = time.Hour
+	defaultWebhookPollInterval = 2 * time.Second
+	defaultWebhookTimeout      = 10 * time.Second
+
+	webhookErrorLimit = 512
+)
+
+// WebhookEnvelope is the JSON body POSTed to subscribers.
+type WebhookEnvelope struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	TenantID   string          `json:"tenant_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Attempt    int             `json:"attempt"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// SignWebhookPayload returns the X-Webhook-Signature value: a hex HMAC-SHA256 over "<timestamp>.<body>"
+// keyed by the subscription secret. Binding the timestamp lets receivers reject replays.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks a signature produced by SignWebhookPayload in constant time.
+func VerifyWebhookSignature(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhookPayload(secret, timestamp, body)), []byte(strings.TrimSpace(signature)))
+}
+
+// WebhookDispatcher delivers claimed outbox events. A non-2xx answer or transport error is retried with
+// exponential backoff; after MaxAttempts the delivery is moved to the dead-letter state and only comes
+// back through the replay API.
+type WebhookDispatcher struct {
+	Queue WebhookDeliveryQueue
+	// Client overrides the default delivery client, which only dials public addresses.
+	Client *http.Client
+	// AllowInsecureEndpoints lets the default client deliver over http to internal addresses; only for
+	// local development.
+	AllowInsecureEndpoints bool
+
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	PollInterval time.Duration
+	Now          func() time.Time
+	OnError      func(error)
+
+	defaultClientOnce sync.Once
+	defaultClient     *http.Client
+}
+
+// Run polls until ctx is done. A full batch is followed immediately by the next one.
+func (d *WebhookDispatcher) Run(ctx context.Context) error {
+	if d.Queue == nil {
+		return errors.New("webhook dispatcher not configured")
+	}
+	for {
+		n, err := d.RunOnce(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && d.OnError != nil {
+			d.OnError(err)
+		}
+		if err == nil && n >= d.batchSize() {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d.pollInterval()):
+		}
+	}
+}
+
+// RunOnce claims one batch of due deliveries, sends them and records the outcome. It returns the number
+// of deliveries attempted.
+func (d *WebhookDispatcher) RunOnce(ctx context.Context) (int, error) {
+	jobs, err := d.Queue.ClaimWebhookDeliveries(ctx, d.batchSize(), d.lease())
+	if err != nil {
+		return 0, err
+	}
+	for _, job := range jobs {
+		statusCode, sendErr := d.send(ctx, job)
+		if sendErr == nil {
+			if err := d.Queue.CompleteWebhookDelivery(ctx, job.ID, statusCode); err != nil {
+				return len(jobs), err
+			}
+			continue
+		}
+		failure := WebhookDeliveryFailure{StatusCode: statusCode, Error: truncateWebhookError(sendErr.Error())}
+		if job.Attempt >= d.maxAttempts() {
+			failure.Dead = true
+		} else {
+			failure.RetryAt = d.now().Add(d.Backoff(job.Attempt))
+		}
+		if err := d.Queue.FailWebhookDelivery(ctx, job.ID, failure); err != nil {
+			return len(jobs), err
+		}
+	}
+	return len(jobs), nil
+}
+
+// Backoff is the wait after the given failed attempt: BaseBackoff doubled per attempt, capped at MaxBackoff.
+func (d *WebhookDispatcher) Backoff(attempt int) time.Duration {
+	delay := d.baseBackoff()
+	for i := 1; i < attempt && delay < d.maxBackoff(); i++ {
+		delay *= 2
+	}
+	return min(delay, d.maxBackoff())
+}
+
+func (d *WebhookDispatcher) send(ctx context.Context, job WebhookDeliveryJob) (int, error) {
+	data := job.Payload
+	if len(data) == 0 {
+		data = json.RawMessage(`{}`)
+	}
+	body, err := json.Marshal(WebhookEnvelope{
+		ID:         job.EventUUID,
+		Type:       job.EventType,
+		TenantID:   job.TenantID,
+		OccurredAt: job.OccurredAt.UTC(),
+		Attempt:    job.Attempt,
+		Data:       data,
+	})
+	if err != nil {
+		return 0, err
+	}
+	timestamp := d.now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.EndpointURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	// Deliveries only go to https endpoints.
+	if d.Client == nil && !d.AllowInsecureEndpoints && req.URL.Scheme != "https" {
+		return 0, ErrWebhookEndpointInvalid
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, job.EventUUID)
+	req.Header.Set(WebhookEventHeader, job.EventType)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(job.Secret, timestamp, body))
+
+	resp, err := d.client().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func truncateWebhookError(msg string) string {
+	if len(msg) <= webhookErrorLimit {
+		return msg
+	}
+	return msg[:webhookErrorLimit]
+}
+
+// lease keeps a claimed delivery from being picked up again while its request is still in flight.
+func (d *WebhookDispatcher) lease() time.Duration {
+	return 2 * d.client().Timeout
+}
+
+func (d *WebhookDispatcher) client() *http.Client {
+	if d.Client != nil && d.Client.Timeout > 0 {
+		return d.Client
+	}
+	if d.Client != nil {
+		c := *d.Client
+		c.Timeout = defaultWebhookTimeout
+		return &c
+	}
+	d.defaultClientOnce.Do(func() {
+		d.defaultClient = newWebhookHTTPClient(defaultWebhookTimeout, d.AllowInsecureEndpoints)
+	})
+	return d.defaultClient
+}
+
+func (d *WebhookDispatcher) now() time.Time {
+	if d.Now != nil {
+		return d.Now()
+	}
+	return time.Now()
+}
+
+func (d *WebhookDispatcher) batchSize() int {
+	if d.BatchSize > 0 {
+		return d.BatchSize
+	}
+	return defaultWebhookBatchSize
+}
+
+func (d *WebhookDispatcher) maxAttempts() int {
+	if d.MaxAttempts > 0 {
+		return d.MaxAttempts
+	}
+	return defaultWebhookMaxAttempts
+}
+
+func (d *WebhookDispatcher) baseBackoff() time.Duration {
+	if d.BaseBackoff > 0 {
+		return d.BaseBackoff
+	}
+	return defaultWebhookBaseBackoff
+}
+
+func (d *WebhookDispatcher) maxBackoff() time.Duration {
+	if d.MaxBackoff > 0 {
+		return d.MaxBackoff
+	}
+	return defaultWebhookMaxBackoff
+}
+
+func (d *WebhookDispatcher) pollInterval() time.Duration {
+	if d.PollInterval > 0 {
+		return d.PollInterval
+	}
+	return defaultWebhookPollInterval
+}
@@ -0,0 +1,287 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+const webhookTestTenant = "00000000-0000-0000-0000-000000000001"
+
+type webhookReceiver struct {
+	mu       sync.Mutex
+	secret   string
+	status   int
+	bodies   []WebhookEnvelope
+	verified []bool
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	ts, _ := strconv.ParseInt(req.Header.Get(WebhookTimestampHeader), 10, 64)
+	var envelope WebhookEnvelope
+	_ = json.Unmarshal(body, &envelope)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, envelope)
+	r.verified = append(r.verified, VerifyWebhookSignature(r.secret, ts, body, req.Header.Get(WebhookSignatureHeader)) &&
+		req.Header.Get(WebhookEventHeader) == envelope.Type && req.Header.Get(WebhookIDHeader) == envelope.ID)
+	w.WriteHeader(r.status)
+}
+
+func (r *webhookReceiver) setStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+type webhookClock struct {
+	now time.Time
+}
+
+func (c *webhookClock) Now() time.Time { return c.now }
+
+func newWebhookTestSetup(t *testing.T, status int, eventTypes []string) (*WebhookMemoryStore, *WebhookDispatcher, *webhookReceiver, *webhookClock, WebhookSubscription) {
+	t.Helper()
+	clock := &webhookClock{now: time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)}
+	store := NewWebhookMemoryStore()
+	store.now = clock.Now
+	receiver := &webhookReceiver{status: status}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	sub, err := store.CreateWebhookSubscription(context.Background(), webhookTestTenant, WebhookSubscriptionCreateRequest{
+		EndpointURL: server.URL + "/hooks",
+		EventTypes:  eventTypes,
+	})
+	if err != nil {
+		t.Fatalf("create subscription err=%v", err)
+	}
+	receiver.secret = sub.Secret
+	dispatcher := &WebhookDispatcher{
+		Queue:       store,
+		Client:      server.Client(),
+		MaxAttempts: 3,
+		BaseBackoff: time.Minute,
+		MaxBackoff:  10 * time.Minute,
+		Now:         clock.Now,
+	}
+	return store, dispatcher, receiver, clock, sub
+}
+
+func TestWebhookDispatcherDeliversSignedPayload(t *testing.T) {
+	store, dispatcher, receiver, _, sub := newWebhookTestSetup(t, http.StatusNoContent, []string{"orgunit.renamed"})
+	store.Enqueue(webhookTestTenant, "11111111-1111-1111-1111-111111111111", "orgunit.renamed", json.RawMessage(`{"org_code":"RD"}`))
+	store.Enqueue(webhookTestTenant, "22222222-2222-2222-2222-222222222222", "orgunit.moved", json.RawMessage(`{}`))
+
+	n, err := dispatcher.RunOnce(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("n=%d err=%v", n, err)
+	}
+	if len(receiver.bodies) != 1 || !receiver.verified[0] {
+		t.Fatalf("bodies=%+v verified=%v", receiver.bodies, receiver.verified)
+	}
+	got := receiver.bodies[0]
+	if got.ID != "11111111-1111-1111-1111-111111111111" || got.Type != "orgunit.renamed" || got.TenantID != webhookTestTenant || got.Attempt != 1 || string(got.Data) != `{"org_code":"RD"}` {
+		t.Fatalf("envelope=%+v", got)
+	}
+
+	deliveries, _ := store.ListWebhookDeliveries(context.Background(), webhookTestTenant, WebhookDeliveryFilter{SubscriptionID: sub.ID})
+	if len(deliveries) != 1 || deliveries[0].Status != WebhookDeliveryDelivered || *deliveries[0].LastStatusCode != http.StatusNoContent || deliveries[0].DeliveredAt == nil {
+		t.Fatalf("deliveries=%+v", deliveries)
+	}
+	if n, err := dispatcher.RunOnce(context.Background()); err != nil || n != 0 {
+		t.Fatalf("second run n=%d err=%v", n, err)
+	}
+}
+
+func TestWebhookDispatcherRetriesThenDeadLettersAndReplays(t *testing.T) {
+	ctx := context.Background()
+	store, dispatcher, receiver, clock, _ := newWebhookTestSetup(t, http.StatusInternalServerError, []string{WebhookEventAll})
+	store.Enqueue(webhookTestTenant, "33333333-3333-3333-3333-333333333333", "dict.value_disabled", json.RawMessage(`{"dict_code":"org_type"}`))
+
+	if n, _ := dispatcher.RunOnce(ctx); n != 1 {
+		t.Fatalf("first attempt n=%d", n)
+	}
+	pending, _ := store.ListWebhookDeliveries(ctx, webhookTestTenant, WebhookDeliveryFilter{Status: WebhookDeliveryPending})
+	if len(pending) != 1 || pending[0].Attempts != 1 || !pending[0].NextAttemptAt.Equal(clock.now.Add(time.Minute)) || *pending[0].LastStatusCode != http.StatusInternalServerError {
+		t.Fatalf("pending=%+v", pending)
+	}
+	if n, _ := dispatcher.RunOnce(ctx); n != 0 {
+		t.Fatalf("retry before backoff elapsed n=%d", n)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	if n, _ := dispatcher.RunOnce(ctx); n != 1 {
+		t.Fatalf("second attempt n=%d", n)
+	}
+	pending, _ = store.ListWebhookDeliveries(ctx, webhookTestTenant, WebhookDeliveryFilter{Status: WebhookDeliveryPending})
+	if len(pending) != 1 || !pending[0].NextAttemptAt.Equal(clock.now.Add(2*time.Minute)) {
+		t.Fatalf("pending after second attempt=%+v", pending)
+	}
+
+	clock.now = clock.now.Add(2 * time.Minute)
+	if n, _ := dispatcher.RunOnce(ctx); n != 1 {
+		t.Fatalf("third attempt n=%d", n)
+	}
+	dead, _ := store.ListWebhookDeliveries(ctx, webhookTestTenant, WebhookDeliveryFilter{Status: WebhookDeliveryDead})
+	if len(dead) != 1 || dead[0].Attempts != 3 || dead[0].DeadAt == nil || dead[0].LastError == "" {
+		t.Fatalf("dead=%+v", dead)
+	}
+	clock.now = clock.now.Add(time.Hour)
+	if n, _ := dispatcher.RunOnce(ctx); n != 0 {
+		t.Fatalf("dead letter was retried n=%d", n)
+	}
+
+	receiver.setStatus(http.StatusOK)
+	replayed, err := store.ReplayWebhookDeliveries(ctx, webhookTestTenant, WebhookReplayRequest{DeliveryIDs: []int64{dead[0].ID}})
+	if err != nil || replayed != 1 {
+		t.Fatalf("replayed=%d err=%v", replayed, err)
+	}
+	if n, _ := dispatcher.RunOnce(ctx); n != 1 {
+		t.Fatalf("replay attempt n=%d", n)
+	}
+	delivered, _ := store.ListWebhookDeliveries(ctx, webhookTestTenant, WebhookDeliveryFilter{Status: WebhookDeliveryDelivered})
+	if len(delivered) != 1 || delivered[0].Attempts != 1 {
+		t.Fatalf("delivered=%+v", delivered)
+	}
+	if len(receiver.bodies) != 4 || receiver.bodies[3].Attempt != 1 {
+		t.Fatalf("bodies=%+v", receiver.bodies)
+	}
+	for i, ok := range receiver.verified {
+		if !ok {
+			t.Fatalf("attempt %d signature not verified", i)
+		}
+	}
+}
+
+func TestWebhookMemoryStoreReplaySinceAndInactiveSubscription(t *testing.T) {
+	ctx := context.Background()
+	store, dispatcher, receiver, clock, sub := newWebhookTestSetup(t, http.StatusOK, []string{"orgunit.created"})
+	start := clock.now
+	store.Enqueue(webhookTestTenant, "44444444-4444-4444-4444-444444444444", "orgunit.created", nil)
+
+	if _, err := store.UpdateWebhookSubscription(ctx, webhookTestTenant, WebhookSubscriptionUpdateRequest{
+		ID: sub.ID, EndpointURL: sub.EndpointURL, EventTypes: sub.EventTypes, Active: false,
+	}); err != nil {
+		t.Fatalf("deactivate err=%v", err)
+	}
+	if n, _ := dispatcher.RunOnce(ctx); n != 0 {
+		t.Fatalf("inactive subscription delivered n=%d", n)
+	}
+	// Events recorded while inactive are not fanned out, but can be replayed once reactivated.
+	store.Enqueue(webhookTestTenant, "55555555-5555-5555-5555-555555555555", "orgunit.created", nil)
+	rotated, err := store.UpdateWebhookSubscription(ctx, webhookTestTenant, WebhookSubscriptionUpdateRequest{
+		ID: sub.ID, EndpointURL: sub.EndpointURL, EventTypes: sub.EventTypes, Active: true, RotateSecret: true,
+	})
+	if err != nil || rotated.Secret == "" || rotated.Secret == sub.Secret {
+		t.Fatalf("rotated=%+v err=%v", rotated, err)
+	}
+	receiver.secret = rotated.Secret
+
+	replayed, err := store.ReplayWebhookDeliveries(ctx, webhookTestTenant, WebhookReplayRequest{SubscriptionID: sub.ID, Since: &start})
+	if err != nil || replayed != 2 {
+		t.Fatalf("replayed=%d err=%v", replayed, err)
+	}
+	if n, _ := dispatcher.RunOnce(ctx); n != 2 {
+		t.Fatalf("n=%d", n)
+	}
+	if len(receiver.verified) != 2 || !receiver.verified[0] || !receiver.verified[1] {
+		t.Fatalf("verified=%v", receiver.verified)
+	}
+
+	if _, err := store.ReplayWebhookDeliveries(ctx, webhookTestTenant, WebhookReplayRequest{}); !errors.Is(err, ErrWebhookReplayInvalid) {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := store.ReplayWebhookDeliveries(ctx, "00000000-0000-0000-0000-000000000002", WebhookReplayRequest{SubscriptionID: sub.ID, Since: &start}); !errors.Is(err, ErrWebhookSubscriptionNotFound) {
+		t.Fatalf("cross tenant err=%v", err)
+	}
+}
+
+func TestWebhookDispatcherBackoffAndSignature(t *testing.T) {
+	d := &WebhookDispatcher{}
+	for attempt, want := range map[int]time.Duration{1: 30 * time.Second, 2: time.Minute, 3: 2 * time.Minute, 20: time.Hour} {
+		if got := d.Backoff(attempt); got != want {
+			t.Fatalf("attempt %d backoff=%v want %v", attempt, got, want)
+		}
+	}
+
+	body := []byte(`{"id":"x"}`)
+	sig := SignWebhookPayload("whsec_test", 1760860800, body)
+	if !VerifyWebhookSignature("whsec_test", 1760860800, body, sig) {
+		t.Fatal("signature should verify")
+	}
+	if VerifyWebhookSignature("whsec_test", 1760860801, body, sig) || VerifyWebhookSignature("whsec_other", 1760860800, body, sig) {
+		t.Fatal("signature must bind timestamp and secret")
+	}
+}
+
+func TestNormalizeWebhookSubscription(t *testing.T) {
+	endpoint, types, err := NormalizeWebhookSubscription(" https://hr.example.com/hook ", []string{"orgunit.moved", "orgunit.created", "orgunit.moved"}, false)
+	if err != nil || endpoint != "https://hr.example.com/hook" || len(types) != 2 || types[0] != "orgunit.created" {
+		t.Fatalf("endpoint=%q types=%v err=%v", endpoint, types, err)
+	}
+	for _, raw := range []string{"ftp://x", "https://", "https://user:pw@x.example.com", "not a url"} {
+		if _, _, err := NormalizeWebhookSubscription(raw, []string{"*"}, true); !errors.Is(err, ErrWebhookEndpointInvalid) {
+			t.Fatalf("%q err=%v", raw, err)
+		}
+	}
+	if _, _, err := NormalizeWebhookSubscription("https://x.example.com", []string{"orgunit.exploded"}, false); !errors.Is(err, ErrWebhookEventTypesInvalid) {
+		t.Fatalf("err=%v", err)
+	}
+	if _, _, err := NormalizeWebhookSubscription("https://x.example.com", nil, false); !errors.Is(err, ErrWebhookEventTypesInvalid) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestNormalizeWebhookSubscriptionRejectsInternalEndpoints(t *testing.T) {
+	for _, raw := range []string{
+		"http://hr.example.com/hook",
+		"https://localhost/hook",
+		"https://127.0.0.1/hook",
+		"https://10.1.2.3/hook",
+		"https://192.168.0.10:8443/hook",
+		"https://169.254.169.254/latest/meta-data",
+		"https://100.64.0.1/hook",
+		"https://[::1]/hook",
+		"https://[fd00:ec2::254]/hook",
+		"https://[::ffff:127.0.0.1]/hook",
+	} {
+		if _, _, err := NormalizeWebhookSubscription(raw, []string{"*"}, false); !errors.Is(err, ErrWebhookEndpointInvalid) {
+			t.Fatalf("%q err=%v", raw, err)
+		}
+	}
+	if _, _, err := NormalizeWebhookSubscription("http://127.0.0.1:9000/hook", []string{"*"}, true); err != nil {
+		t.Fatalf("insecure endpoints must be allowed in dev, err=%v", err)
+	}
+}
+
+// The default client refuses to connect to internal addresses even when a public-looking URL resolves there.
+func TestWebhookDispatcherDefaultClientRefusesInternalAddresses(t *testing.T) {
+	receiver := &webhookReceiver{status: http.StatusNoContent}
+	server := httptest.NewTLSServer(receiver)
+	t.Cleanup(server.Close)
+
+	d := &WebhookDispatcher{}
+	_, err := d.send(context.Background(), WebhookDeliveryJob{EndpointURL: server.URL + "/hooks", EventUUID: "e1", EventType: "orgunit.renamed", Secret: "whsec_test"})
+	if !errors.Is(err, errWebhookAddressBlocked) || len(receiver.bodies) != 0 {
+		t.Fatalf("err=%v bodies=%d", err, len(receiver.bodies))
+	}
+	plain := &WebhookDispatcher{}
+	if _, err := plain.send(context.Background(), WebhookDeliveryJob{EndpointURL: "http://hr.example.com/hooks"}); !errors.Is(err, ErrWebhookEndpointInvalid) {
+		t.Fatalf("plain http err=%v", err)
+	}
+	for _, raw := range []string{"8.8.8.8:443", "[2606:4700::1111]:443"} {
+		if err := webhookDialControl("tcp", raw, nil); err != nil {
+			t.Fatalf("%s err=%v", raw, err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package persistence
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// errWebhookAddressBlocked is returned by the delivery dialer; it surfaces in the delivery log as a
+// transport error rather than as a status code from inside the network.
+var errWebhookAddressBlocked = errors.New("webhook endpoint resolves to a non-public address")
+
+// webhookCGNATPrefix is shared address space (RFC 6598): not covered by netip's IsPrivate, still internal.
+var webhookCGNATPrefix = netip.MustParsePrefix("100.64.0.0/10")
+
+// webhookAddrBlocked reports whether a webhook may not be delivered to addr: loopback, RFC 1918 / ULA,
+// link-local (which includes cloud metadata such as 169.254.169.254), CGNAT, unspecified and multicast.
+func webhookAddrBlocked(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return !addr.IsValid() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() ||
+		webhookCGNATPrefix.Contains(addr) ||
+		(addr.Is4() && addr.As4()[0] == 0)
+}
+
+// validateWebhookEndpointTarget rejects plain http and literal internal hosts at registration time. Names
+// are not resolved here: what they point at can change, so the delivery dialer checks every connection.
+func validateWebhookEndpointTarget(u *url.URL, allowInsecure bool) error {
+	if allowInsecure {
+		return nil
+	}
+	if u.Scheme != "https" {
+		return ErrWebhookEndpointInvalid
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrWebhookEndpointInvalid
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && webhookAddrBlocked(addr) {
+		return ErrWebhookEndpointInvalid
+	}
+	return nil
+}
+
+// webhookDialControl runs after name resolution, on the address actually being connected to, so DNS
+// rebinding cannot steer a delivery into the server's own network.
+func webhookDialControl(_ string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil || webhookAddrBlocked(addrPort.Addr()) {
+		return errWebhookAddressBlocked
+	}
+	return nil
+}
+
+// newWebhookHTTPClient returns the delivery client. Unless allowInsecure, it dials public addresses only,
+// ignores proxy settings (the proxy address would be checked instead of the target) and does not follow
+// redirects, so a 3xx counts as a failed delivery.
+func newWebhookHTTPClient(timeout time.Duration, allowInsecure bool) *http.Client {
+	if allowInsecure {
+		return &http.Client{Timeout: timeout}
+	}
+	dialer := &net.Dialer{Timeout: timeout, Control: webhookDialControl}
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          32,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   timeout,
+		ExpectContinueTimeout: time.Second,
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
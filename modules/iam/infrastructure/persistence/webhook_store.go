@@ -0,0 +1,858 @@
+package persistence
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+
+	// WebhookEventAll subscribes to every event type, including ones added later.
+	WebhookEventAll = "*"
+
+	webhookSecretPrefix = "whsec_"
+)
+
+// WebhookEventTypes lists the event types written to iam.webhook_outbox by the orgunit and dict triggers.
+var WebhookEventTypes = []string{
+	"orgunit.created",
+	"orgunit.updated",
+	"orgunit.moved",
+	"orgunit.renamed",
+	"orgunit.disabled",
+	"orgunit.enabled",
+	"orgunit.business_unit_changed",
+	"orgunit.corrected",
+	"orgunit.rescinded",
+	"dict.created",
+	"dict.disabled",
+	"dict.value_created",
+	"dict.value_corrected",
+	"dict.value_disabled",
+	"dict.value_enabled",
+	"dict.value_rescinded",
+}
+
+var (
+	ErrWebhookSubscriptionNotFound = errors.New("WEBHOOK_SUBSCRIPTION_NOT_FOUND")
+	ErrWebhookEndpointInvalid      = errors.New("WEBHOOK_ENDPOINT_INVALID")
+	ErrWebhookEventTypesInvalid    = errors.New("WEBHOOK_EVENT_TYPES_INVALID")
+	ErrWebhookReplayInvalid        = errors.New("WEBHOOK_REPLAY_INVALID")
+)
+
+type WebhookSubscription struct {
+	ID          string    `json:"subscription_id"`
+	EndpointURL string    `json:"endpoint_url"`
+	EventTypes  []string  `json:"event_types"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// Secret is only returned when a subscription is created or its secret is rotated.
+	Secret string `json:"secret,omitempty"`
+}
+
+type WebhookSubscriptionCreateRequest struct {
+	EndpointURL string   `json:"endpoint_url"`
+	EventTypes  []string `json:"event_types"`
+	Description string   `json:"description"`
+}
+
+type WebhookSubscriptionUpdateRequest struct {
+	ID           string   `json:"subscription_id"`
+	EndpointURL  string   `json:"endpoint_url"`
+	EventTypes   []string `json:"event_types"`
+	Description  string   `json:"description"`
+	Active       bool     `json:"active"`
+	RotateSecret bool     `json:"rotate_secret"`
+}
+
+type WebhookDelivery struct {
+	ID             int64      `json:"delivery_id"`
+	SubscriptionID string     `json:"subscription_id"`
+	EventUUID      string     `json:"event_uuid"`
+	EventType      string     `json:"event_type"`
+	OccurredAt     time.Time  `json:"occurred_at"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastStatusCode *int       `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	DeadAt         *time.Time `json:"dead_at,omitempty"`
+}
+
+type WebhookDeliveryFilter struct {
+	Status         string
+	SubscriptionID string
+	Limit          int
+}
+
+// WebhookReplayRequest re-queues deliveries, either by id (typically from the dead-letter view) or every
+// outbox event since a point in time for one subscription.
+type WebhookReplayRequest struct {
+	DeliveryIDs    []int64    `json:"delivery_ids"`
+	SubscriptionID string     `json:"subscription_id"`
+	Since          *time.Time `json:"since"`
+}
+
+// WebhookDeliveryJob is a claimed delivery with everything the worker needs to send it.
+type WebhookDeliveryJob struct {
+	ID             int64
+	TenantID       string
+	SubscriptionID string
+	EndpointURL    string
+	Secret         string
+	Attempt        int
+	EventUUID      string
+	EventType      string
+	Payload        json.RawMessage
+	OccurredAt     time.Time
+}
+
+type WebhookDeliveryFailure struct {
+	StatusCode int
+	Error      string
+	RetryAt    time.Time
+	Dead       bool
+}
+
+type WebhookStore interface {
+	ListWebhookSubscriptions(ctx context.Context, tenantID string) ([]WebhookSubscription, error)
+	CreateWebhookSubscription(ctx context.Context, tenantID string, req WebhookSubscriptionCreateRequest) (WebhookSubscription, error)
+	UpdateWebhookSubscription(ctx context.Context, tenantID string, req WebhookSubscriptionUpdateRequest) (WebhookSubscription, error)
+	ListWebhookDeliveries(ctx context.Context, tenantID string, filter WebhookDeliveryFilter) ([]WebhookDelivery, error)
+	ReplayWebhookDeliveries(ctx context.Context, tenantID string, req WebhookReplayRequest) (int, error)
+}
+
+// WebhookDeliveryQueue is the cross-tenant side used by the delivery worker.
+type WebhookDeliveryQueue interface {
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDeliveryJob, error)
+	CompleteWebhookDelivery(ctx context.Context, id int64, statusCode int) error
+	FailWebhookDelivery(ctx context.Context, id int64, failure WebhookDeliveryFailure) error
+}
+
+// NormalizeWebhookSubscription validates an endpoint and event type list and returns them trimmed,
+// de-duplicated and sorted. Unless allowInsecure, the endpoint must be https and must not name a
+// loopback, private or link-local host.
+func NormalizeWebhookSubscription(endpointURL string, eventTypes []string, allowInsecure bool) (string, []string, error) {
+	endpointURL = strings.TrimSpace(endpointURL)
+	u, err := url.Parse(endpointURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return "", nil, ErrWebhookEndpointInvalid
+	}
+	if err := validateWebhookEndpointTarget(u, allowInsecure); err != nil {
+		return "", nil, err
+	}
+	out := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		eventType = strings.TrimSpace(eventType)
+		if eventType != WebhookEventAll && !slices.Contains(WebhookEventTypes, eventType) {
+			return "", nil, ErrWebhookEventTypesInvalid
+		}
+		if !slices.Contains(out, eventType) {
+			out = append(out, eventType)
+		}
+	}
+	if len(out) == 0 {
+		return "", nil, ErrWebhookEventTypesInvalid
+	}
+	sort.Strings(out)
+	return endpointURL, out, nil
+}
+
+func webhookSubscribed(eventTypes []string, eventType string) bool {
+	return slices.Contains(eventTypes, eventType) || slices.Contains(eventTypes, WebhookEventAll)
+}
+
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + hex.EncodeToString(buf), nil
+}
+
+func validateWebhookReplayRequest(req WebhookReplayRequest) error {
+	byID := len(req.DeliveryIDs) > 0
+	bySubscription := strings.TrimSpace(req.SubscriptionID) != "" && req.Since != nil
+	if byID == bySubscription {
+		return ErrWebhookReplayInvalid
+	}
+	return nil
+}
+
+type WebhookPGStore struct {
+	Pool PGBeginner
+	// AllowInsecureEndpoints accepts http and internal endpoints; only for local development.
+	AllowInsecureEndpoints bool
+}
+
+func NewWebhookPGStore(pool PGBeginner) *WebhookPGStore {
+	return &WebhookPGStore{Pool: pool}
+}
+
+const webhookSubscriptionColumns = `id::text, endpoint_url, event_types, description, active, created_at, updated_at`
+
+func scanWebhookSubscription(row pgx.Row) (WebhookSubscription, error) {
+	var item WebhookSubscription
+	err := row.Scan(&item.ID, &item.EndpointURL, &item.EventTypes, &item.Description, &item.Active, &item.CreatedAt, &item.UpdatedAt)
+	return item, err
+}
+
+func (s *WebhookPGStore) ListWebhookSubscriptions(ctx context.Context, tenantID string) ([]WebhookSubscription, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	rows, err := tx.Query(ctx, `
+SELECT `+webhookSubscriptionColumns+`
+FROM iam.webhook_subscriptions
+WHERE tenant_uuid = $1::uuid
+ORDER BY created_at, id
+`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]WebhookSubscription, 0)
+	for rows.Next() {
+		item, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *WebhookPGStore) CreateWebhookSubscription(ctx context.Context, tenantID string, req WebhookSubscriptionCreateRequest) (WebhookSubscription, error) {
+	endpointURL, eventTypes, err := NormalizeWebhookSubscription(req.EndpointURL, req.EventTypes, s.AllowInsecureEndpoints)
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	item, err := scanWebhookSubscription(tx.QueryRow(ctx, `
+INSERT INTO iam.webhook_subscriptions (tenant_uuid, endpoint_url, event_types, secret, description)
+VALUES ($1::uuid, $2::text, $3::text[], $4::text, $5::text)
+RETURNING `+webhookSubscriptionColumns, tenantID, endpointURL, eventTypes, secret, strings.TrimSpace(req.Description)))
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return WebhookSubscription{}, err
+	}
+	item.Secret = secret
+	return item, nil
+}
+
+func (s *WebhookPGStore) UpdateWebhookSubscription(ctx context.Context, tenantID string, req WebhookSubscriptionUpdateRequest) (WebhookSubscription, error) {
+	endpointURL, eventTypes, err := NormalizeWebhookSubscription(req.EndpointURL, req.EventTypes, s.AllowInsecureEndpoints)
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+	var secret any
+	if req.RotateSecret {
+		rotated, err := newWebhookSecret()
+		if err != nil {
+			return WebhookSubscription{}, err
+		}
+		secret = rotated
+	}
+
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	item, err := scanWebhookSubscription(tx.QueryRow(ctx, `
+UPDATE iam.webhook_subscriptions
+SET endpoint_url = $3::text,
+    event_types = $4::text[],
+    description = $5::text,
+    active = $6::boolean,
+    secret = COALESCE($7::text, secret),
+    updated_at = now()
+WHERE tenant_uuid = $1::uuid
+  AND id = $2::uuid
+RETURNING `+webhookSubscriptionColumns,
+		tenantID, strings.TrimSpace(req.ID), endpointURL, eventTypes, strings.TrimSpace(req.Description), req.Active, secret))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return WebhookSubscription{}, ErrWebhookSubscriptionNotFound
+		}
+		return WebhookSubscription{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return WebhookSubscription{}, err
+	}
+	if rotated, ok := secret.(string); ok {
+		item.Secret = rotated
+	}
+	return item, nil
+}
+
+func (s *WebhookPGStore) ListWebhookDeliveries(ctx context.Context, tenantID string, filter WebhookDeliveryFilter) ([]WebhookDelivery, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	rows, err := tx.Query(ctx, `
+SELECT d.id, d.subscription_id::text, o.event_uuid::text, o.event_type, o.occurred_at, d.status, d.attempts,
+  d.next_attempt_at, d.last_status_code, d.last_error, d.created_at, d.delivered_at, d.dead_at
+FROM iam.webhook_deliveries d
+JOIN iam.webhook_outbox o ON o.id = d.outbox_id
+WHERE d.tenant_uuid = $1::uuid
+  AND ($2::text = '' OR d.status = $2::text)
+  AND ($3::text = '' OR d.subscription_id = NULLIF($3::text, '')::uuid)
+ORDER BY d.id DESC
+LIMIT $4::int
+`, tenantID, filter.Status, strings.TrimSpace(filter.SubscriptionID), filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]WebhookDelivery, 0)
+	for rows.Next() {
+		var item WebhookDelivery
+		if err := rows.Scan(
+			&item.ID, &item.SubscriptionID, &item.EventUUID, &item.EventType, &item.OccurredAt, &item.Status, &item.Attempts,
+			&item.NextAttemptAt, &item.LastStatusCode, &item.LastError, &item.CreatedAt, &item.DeliveredAt, &item.DeadAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *WebhookPGStore) ReplayWebhookDeliveries(ctx context.Context, tenantID string, req WebhookReplayRequest) (int, error) {
+	if err := validateWebhookReplayRequest(req); err != nil {
+		return 0, err
+	}
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	var replayed int
+	if len(req.DeliveryIDs) > 0 {
+		tag, err := tx.Exec(ctx, `
+UPDATE iam.webhook_deliveries
+SET status = 'pending',
+    attempts = 0,
+    next_attempt_at = now(),
+    delivered_at = NULL,
+    dead_at = NULL,
+    last_error = '',
+    last_status_code = NULL,
+    updated_at = now()
+WHERE tenant_uuid = $1::uuid
+  AND id = ANY($2::bigint[])
+`, tenantID, req.DeliveryIDs)
+		if err != nil {
+			return 0, err
+		}
+		replayed = int(tag.RowsAffected())
+	} else {
+		var found bool
+		if err := tx.QueryRow(ctx, `
+SELECT EXISTS (SELECT 1 FROM iam.webhook_subscriptions WHERE tenant_uuid = $1::uuid AND id = $2::uuid)
+`, tenantID, strings.TrimSpace(req.SubscriptionID)).Scan(&found); err != nil {
+			return 0, err
+		}
+		if !found {
+			return 0, ErrWebhookSubscriptionNotFound
+		}
+		tag, err := tx.Exec(ctx, `
+INSERT INTO iam.webhook_deliveries (tenant_uuid, subscription_id, outbox_id)
+SELECT s.tenant_uuid, s.id, o.id
+FROM iam.webhook_subscriptions s
+JOIN iam.webhook_outbox o ON o.tenant_uuid = s.tenant_uuid
+WHERE s.tenant_uuid = $1::uuid
+  AND s.id = $2::uuid
+  AND o.occurred_at >= $3::timestamptz
+  AND (o.event_type = ANY (s.event_types) OR '*' = ANY (s.event_types))
+ON CONFLICT (subscription_id, outbox_id) DO UPDATE SET
+  status = 'pending',
+  attempts = 0,
+  next_attempt_at = now(),
+  delivered_at = NULL,
+  dead_at = NULL,
+  last_error = '',
+  last_status_code = NULL,
+  updated_at = now()
+`, tenantID, strings.TrimSpace(req.SubscriptionID), *req.Since)
+		if err != nil {
+			return 0, err
+		}
+		replayed = int(tag.RowsAffected())
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return replayed, nil
+}
+
+// ClaimWebhookDeliveries leases due deliveries of active subscriptions across tenants. The attempt counter
+// and next_attempt_at move forward at claim time, so a worker that dies mid-delivery only delays the
+// event by the lease instead of losing it.
+func (s *WebhookPGStore) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDeliveryJob, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	rows, err := tx.Query(ctx, `
+WITH due AS (
+  SELECT d.id
+  FROM iam.webhook_deliveries d
+  JOIN iam.webhook_subscriptions s ON s.id = d.subscription_id
+  WHERE d.status = 'pending'
+    AND d.next_attempt_at <= now()
+    AND s.active
+  ORDER BY d.next_attempt_at, d.id
+  LIMIT $1::int
+  FOR UPDATE OF d SKIP LOCKED
+)
+UPDATE iam.webhook_deliveries d
+SET attempts = d.attempts + 1,
+    next_attempt_at = now() + make_interval(secs => $2::double precision),
+    updated_at = now()
+FROM due, iam.webhook_subscriptions s, iam.webhook_outbox o
+WHERE d.id = due.id
+  AND s.id = d.subscription_id
+  AND o.id = d.outbox_id
+RETURNING d.id, d.tenant_uuid::text, d.subscription_id::text, s.endpoint_url, s.secret, d.attempts,
+  o.event_uuid::text, o.event_type, o.payload, o.occurred_at
+`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]WebhookDeliveryJob, 0)
+	for rows.Next() {
+		var job WebhookDeliveryJob
+		if err := rows.Scan(&job.ID, &job.TenantID, &job.SubscriptionID, &job.EndpointURL, &job.Secret, &job.Attempt,
+			&job.EventUUID, &job.EventType, &job.Payload, &job.OccurredAt); err != nil {
+			return nil, err
+		}
+		out = append(out, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (s *WebhookPGStore) CompleteWebhookDelivery(ctx context.Context, id int64, statusCode int) error {
+	return s.execWebhookDelivery(ctx, `
+UPDATE iam.webhook_deliveries
+SET status = 'delivered',
+    last_status_code = $2::int,
+    last_error = '',
+    delivered_at = now(),
+    updated_at = now()
+WHERE id = $1
+`, id, statusCode)
+}
+
+func (s *WebhookPGStore) FailWebhookDelivery(ctx context.Context, id int64, failure WebhookDeliveryFailure) error {
+	var statusCode any
+	if failure.StatusCode > 0 {
+		statusCode = failure.StatusCode
+	}
+	if failure.Dead {
+		return s.execWebhookDelivery(ctx, `
+UPDATE iam.webhook_deliveries
+SET status = 'dead',
+    last_status_code = $2::int,
+    last_error = $3::text,
+    dead_at = now(),
+    updated_at = now()
+WHERE id = $1
+`, id, statusCode, failure.Error)
+	}
+	return s.execWebhookDelivery(ctx, `
+UPDATE iam.webhook_deliveries
+SET last_status_code = $2::int,
+    last_error = $3::text,
+    next_attempt_at = $4::timestamptz,
+    updated_at = now()
+WHERE id = $1
+`, id, statusCode, failure.Error, failure.RetryAt)
+}
+
+func (s *WebhookPGStore) execWebhookDelivery(ctx context.Context, sql string, args ...any) error {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// WebhookMemoryStore backs the DB-less dev server and tests. Enqueue stands in for the outbox triggers.
+// Being dev-only, it accepts insecure endpoints unless AllowInsecureEndpoints is cleared.
+type WebhookMemoryStore struct {
+	AllowInsecureEndpoints bool
+
+	mu            sync.Mutex
+	now           func() time.Time
+	subscriptions []memoryWebhookSubscription
+	outbox        []memoryWebhookEvent
+	deliveries    []memoryWebhookDelivery
+	nextID        int64
+}
+
+type memoryWebhookSubscription struct {
+	tenantID string
+	secret   string
+	item     WebhookSubscription
+}
+
+type memoryWebhookEvent struct {
+	id         int64
+	tenantID   string
+	eventUUID  string
+	eventType  string
+	payload    json.RawMessage
+	occurredAt time.Time
+}
+
+type memoryWebhookDelivery struct {
+	tenantID string
+	outboxID int64
+	item     WebhookDelivery
+}
+
+func NewWebhookMemoryStore() *WebhookMemoryStore {
+	return &WebhookMemoryStore{AllowInsecureEndpoints: true, now: time.Now}
+}
+
+func (s *WebhookMemoryStore) nextSeq() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+func (s *WebhookMemoryStore) ListWebhookSubscriptions(_ context.Context, tenantID string) ([]WebhookSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]WebhookSubscription, 0)
+	for _, sub := range s.subscriptions {
+		if sub.tenantID == tenantID {
+			out = append(out, sub.item)
+		}
+	}
+	return out, nil
+}
+
+func (s *WebhookMemoryStore) CreateWebhookSubscription(_ context.Context, tenantID string, req WebhookSubscriptionCreateRequest) (WebhookSubscription, error) {
+	endpointURL, eventTypes, err := NormalizeWebhookSubscription(req.EndpointURL, req.EventTypes, s.AllowInsecureEndpoints)
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now().UTC()
+	item := WebhookSubscription{
+		ID:          newMemoryUUID(s.nextSeq()),
+		EndpointURL: endpointURL,
+		EventTypes:  eventTypes,
+		Description: strings.TrimSpace(req.Description),
+		Active:      true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	s.subscriptions = append(s.subscriptions, memoryWebhookSubscription{tenantID: tenantID, secret: secret, item: item})
+	item.Secret = secret
+	return item, nil
+}
+
+func (s *WebhookMemoryStore) UpdateWebhookSubscription(_ context.Context, tenantID string, req WebhookSubscriptionUpdateRequest) (WebhookSubscription, error) {
+	endpointURL, eventTypes, err := NormalizeWebhookSubscription(req.EndpointURL, req.EventTypes, s.AllowInsecureEndpoints)
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.subscriptions {
+		sub := &s.subscriptions[i]
+		if sub.tenantID != tenantID || sub.item.ID != strings.TrimSpace(req.ID) {
+			continue
+		}
+		sub.item.EndpointURL = endpointURL
+		sub.item.EventTypes = eventTypes
+		sub.item.Description = strings.TrimSpace(req.Description)
+		sub.item.Active = req.Active
+		sub.item.UpdatedAt = s.now().UTC()
+		item := sub.item
+		if req.RotateSecret {
+			secret, err := newWebhookSecret()
+			if err != nil {
+				return WebhookSubscription{}, err
+			}
+			sub.secret = secret
+			item.Secret = secret
+		}
+		return item, nil
+	}
+	return WebhookSubscription{}, ErrWebhookSubscriptionNotFound
+}
+
+// Enqueue records an outbox event and fans it out like iam.enqueue_webhook_event.
+func (s *WebhookMemoryStore) Enqueue(tenantID string, eventUUID string, eventType string, payload json.RawMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event := memoryWebhookEvent{
+		id:         s.nextSeq(),
+		tenantID:   tenantID,
+		eventUUID:  eventUUID,
+		eventType:  eventType,
+		payload:    payload,
+		occurredAt: s.now().UTC(),
+	}
+	s.outbox = append(s.outbox, event)
+	for _, sub := range s.subscriptions {
+		if sub.tenantID == tenantID && sub.item.Active && webhookSubscribed(sub.item.EventTypes, eventType) {
+			s.addDelivery(sub, event)
+		}
+	}
+}
+
+func (s *WebhookMemoryStore) addDelivery(sub memoryWebhookSubscription, event memoryWebhookEvent) {
+	now := s.now().UTC()
+	s.deliveries = append(s.deliveries, memoryWebhookDelivery{
+		tenantID: sub.tenantID,
+		outboxID: event.id,
+		item: WebhookDelivery{
+			ID:             s.nextSeq(),
+			SubscriptionID: sub.item.ID,
+			EventUUID:      event.eventUUID,
+			EventType:      event.eventType,
+			OccurredAt:     event.occurredAt,
+			Status:         WebhookDeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		},
+	})
+}
+
+func (s *WebhookMemoryStore) ListWebhookDeliveries(_ context.Context, tenantID string, filter WebhookDeliveryFilter) ([]WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]WebhookDelivery, 0)
+	for i := len(s.deliveries) - 1; i >= 0; i-- {
+		d := s.deliveries[i]
+		if d.tenantID != tenantID ||
+			(filter.Status != "" && d.item.Status != filter.Status) ||
+			(filter.SubscriptionID != "" && d.item.SubscriptionID != filter.SubscriptionID) {
+			continue
+		}
+		out = append(out, d.item)
+		if filter.Limit > 0 && len(out) == filter.Limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (s *WebhookMemoryStore) ReplayWebhookDeliveries(_ context.Context, tenantID string, req WebhookReplayRequest) (int, error) {
+	if err := validateWebhookReplayRequest(req); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	replayed := 0
+	if len(req.DeliveryIDs) > 0 {
+		for i := range s.deliveries {
+			if s.deliveries[i].tenantID == tenantID && slices.Contains(req.DeliveryIDs, s.deliveries[i].item.ID) {
+				s.requeue(&s.deliveries[i])
+				replayed++
+			}
+		}
+		return replayed, nil
+	}
+
+	subscriptionID := strings.TrimSpace(req.SubscriptionID)
+	idx := slices.IndexFunc(s.subscriptions, func(sub memoryWebhookSubscription) bool {
+		return sub.tenantID == tenantID && sub.item.ID == subscriptionID
+	})
+	if idx < 0 {
+		return 0, ErrWebhookSubscriptionNotFound
+	}
+	sub := s.subscriptions[idx]
+	for _, event := range s.outbox {
+		if event.tenantID != tenantID || event.occurredAt.Before(*req.Since) || !webhookSubscribed(sub.item.EventTypes, event.eventType) {
+			continue
+		}
+		existing := slices.IndexFunc(s.deliveries, func(d memoryWebhookDelivery) bool {
+			return d.item.SubscriptionID == sub.item.ID && d.outboxID == event.id
+		})
+		if existing >= 0 {
+			s.requeue(&s.deliveries[existing])
+		} else {
+			s.addDelivery(sub, event)
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+func (s *WebhookMemoryStore) requeue(d *memoryWebhookDelivery) {
+	d.item.Status = WebhookDeliveryPending
+	d.item.Attempts = 0
+	d.item.NextAttemptAt = s.now().UTC()
+	d.item.DeliveredAt = nil
+	d.item.DeadAt = nil
+	d.item.LastError = ""
+	d.item.LastStatusCode = nil
+}
+
+func (s *WebhookMemoryStore) ClaimWebhookDeliveries(_ context.Context, limit int, lease time.Duration) ([]WebhookDeliveryJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now().UTC()
+	out := make([]WebhookDeliveryJob, 0)
+	for i := range s.deliveries {
+		d := &s.deliveries[i]
+		if d.item.Status != WebhookDeliveryPending || d.item.NextAttemptAt.After(now) {
+			continue
+		}
+		subIdx := slices.IndexFunc(s.subscriptions, func(sub memoryWebhookSubscription) bool { return sub.item.ID == d.item.SubscriptionID })
+		eventIdx := slices.IndexFunc(s.outbox, func(event memoryWebhookEvent) bool { return event.id == d.outboxID })
+		if subIdx < 0 || eventIdx < 0 || !s.subscriptions[subIdx].item.Active {
+			continue
+		}
+		sub, event := s.subscriptions[subIdx], s.outbox[eventIdx]
+		d.item.Attempts++
+		d.item.NextAttemptAt = now.Add(lease)
+		out = append(out, WebhookDeliveryJob{
+			ID:             d.item.ID,
+			TenantID:       d.tenantID,
+			SubscriptionID: sub.item.ID,
+			EndpointURL:    sub.item.EndpointURL,
+			Secret:         sub.secret,
+			Attempt:        d.item.Attempts,
+			EventUUID:      event.eventUUID,
+			EventType:      event.eventType,
+			Payload:        event.payload,
+			OccurredAt:     event.occurredAt,
+		})
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (s *WebhookMemoryStore) CompleteWebhookDelivery(_ context.Context, id int64, statusCode int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.deliveries {
+		if d := &s.deliveries[i]; d.item.ID == id {
+			now := s.now().UTC()
+			d.item.Status = WebhookDeliveryDelivered
+			d.item.LastStatusCode = &statusCode
+			d.item.LastError = ""
+			d.item.DeliveredAt = &now
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *WebhookMemoryStore) FailWebhookDelivery(_ context.Context, id int64, failure WebhookDeliveryFailure) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.deliveries {
+		d := &s.deliveries[i]
+		if d.item.ID != id {
+			continue
+		}
+		d.item.LastStatusCode = nil
+		if failure.StatusCode > 0 {
+			statusCode := failure.StatusCode
+			d.item.LastStatusCode = &statusCode
+		}
+		d.item.LastError = failure.Error
+		if failure.Dead {
+			now := s.now().UTC()
+			d.item.Status = WebhookDeliveryDead
+			d.item.DeadAt = &now
+		} else {
+			d.item.NextAttemptAt = failure.RetryAt
+		}
+		return nil
+	}
+	return nil
+}
+
+// newMemoryUUID derives a stable UUID-shaped id from a sequence number.
+func newMemoryUUID(seq int64) string {
+	var b [16]byte
+	for i := 15; i >= 8 && seq > 0; i-- {
+		b[i] = byte(seq)
+		seq >>= 8
+	}
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
@@ -2,8 +2,10 @@ package iam
 
 import (
 	"context"
+	"slices"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/iam/infrastructure/persistence"
 	dictpkg "github.com/jacksonlee411/Bugs-And-Blossoms/pkg/dict"
 )
@@ -12,6 +14,20 @@ type PGBeginner interface {
 	Begin(ctx context.Context) (pgx.Tx, error)
 }
 
+type DictBaselineReleaseRequest = persistence.DictBaselineReleaseRequest
+type DictBaselineReleaseResult = persistence.DictBaselineReleaseResult
+type DictBaselineReleasePreview = persistence.DictBaselineReleasePreview
+type DictBaselineReleaseConflict = persistence.DictBaselineReleaseConflict
+type DictBaselineReleaseStore = persistence.DictBaselineReleaseStore
+
+var (
+	ErrDictBaselineNotReady      = persistence.ErrDictBaselineNotReady
+	ErrDictReleaseIDRequired     = persistence.ErrDictReleaseIDRequired
+	ErrDictReleaseSourceInvalid  = persistence.ErrDictReleaseSourceInvalid
+	ErrDictReleaseTargetRequired = persistence.ErrDictReleaseTargetRequired
+	ErrDictReleasePayloadInvalid = persistence.ErrDictReleasePayloadInvalid
+)
+
 type DictPGStore struct {
 	pool PGBeginner
 	core *persistence.PGStore
@@ -87,6 +103,14 @@ func (s *DictPGStore) SubmitValueEvent(
 	return persistence.SubmitValueEvent(ctx, s.pool, tenantID, dictCode, code, eventType, day, payload, requestID, initiator)
 }
 
+func (s *DictPGStore) PreviewBaseline(ctx context.Context, req persistence.DictBaselineReleaseRequest) (persistence.DictBaselineReleasePreview, error) {
+	return s.core.PreviewBaseline(ctx, req)
+}
+
+func (s *DictPGStore) PublishBaseline(ctx context.Context, req persistence.DictBaselineReleaseRequest) (persistence.DictBaselineReleaseResult, error) {
+	return s.core.PublishBaseline(ctx, req)
+}
+
 func (s *DictPGStore) ListDictValueAudit(ctx context.Context, tenantID string, dictCode string, code string, limit int) ([]persistence.DictValueAuditItem, error) {
 	return s.core.ListDictValueAudit(ctx, tenantID, dictCode, code, limit)
 }
@@ -119,3 +143,63 @@ func (s *DictMemoryStore) ValuesForTenant(tenantID string) []persistence.DictVal
 	}
 	return nil
 }
+
+type DictChangeSink = persistence.DictChangeSink
+
+// NewDictChangeListener subscribes sink to committed dict changes on a connection hijacked from pool,
+// so the LISTEN session never returns to the pool.
+func NewDictChangeListener(pool *pgxpool.Pool, sink DictChangeSink) *persistence.DictChangeListener {
+	return &persistence.DictChangeListener{
+		Connect: func(ctx context.Context) (persistence.DictChangeConn, error) {
+			conn, err := pool.Acquire(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return conn.Hijack(), nil
+		},
+		Sink: sink,
+	}
+}
+
+type WebhookStore = persistence.WebhookStore
+type WebhookDeliveryQueue = persistence.WebhookDeliveryQueue
+type WebhookDispatcher = persistence.WebhookDispatcher
+type WebhookPGStore = persistence.WebhookPGStore
+type WebhookMemoryStore = persistence.WebhookMemoryStore
+type WebhookSubscription = persistence.WebhookSubscription
+type WebhookSubscriptionCreateRequest = persistence.WebhookSubscriptionCreateRequest
+type WebhookSubscriptionUpdateRequest = persistence.WebhookSubscriptionUpdateRequest
+type WebhookDelivery = persistence.WebhookDelivery
+type WebhookDeliveryFilter = persistence.WebhookDeliveryFilter
+type WebhookReplayRequest = persistence.WebhookReplayRequest
+
+const (
+	WebhookDeliveryPending   = persistence.WebhookDeliveryPending
+	WebhookDeliveryDelivered = persistence.WebhookDeliveryDelivered
+	WebhookDeliveryDead      = persistence.WebhookDeliveryDead
+)
+
+var (
+	ErrWebhookSubscriptionNotFound = persistence.ErrWebhookSubscriptionNotFound
+	ErrWebhookEndpointInvalid      = persistence.ErrWebhookEndpointInvalid
+	ErrWebhookEventTypesInvalid    = persistence.ErrWebhookEventTypesInvalid
+	ErrWebhookReplayInvalid        = persistence.ErrWebhookReplayInvalid
+)
+
+// WebhookEventTypes lists the event types a subscription may name.
+func WebhookEventTypes() []string {
+	return slices.Clone(persistence.WebhookEventTypes)
+}
+
+func NewWebhookPGStore(pool PGBeginner) *WebhookPGStore {
+	return persistence.NewWebhookPGStore(pool)
+}
+
+func NewWebhookMemoryStore() *WebhookMemoryStore {
+	return persistence.NewWebhookMemoryStore()
+}
+
+// NewWebhookDispatcher delivers the outbox of queue; zero-valued tuning fields fall back to the defaults.
+func NewWebhookDispatcher(queue WebhookDeliveryQueue) *WebhookDispatcher {
+	return &persistence.WebhookDispatcher{Queue: queue}
+}
@@ -55,6 +55,7 @@ var fieldDefinitions = []FieldDefinition{
 		DataSourceType:   "PLAIN",
 		DataSourceConfig: map[string]any{},
 		LabelI18nKey:     "org.fields.location_code",
+		AllowFilter:      true,
 	},
 	{
 		FieldKey:         "cost_center",
@@ -62,6 +63,7 @@ var fieldDefinitions = []FieldDefinition{
 		DataSourceType:   "PLAIN",
 		DataSourceConfig: map[string]any{},
 		LabelI18nKey:     "org.fields.cost_center",
+		AllowFilter:      true,
 	},
 }
 
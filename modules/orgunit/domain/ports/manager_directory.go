@@ -0,0 +1,17 @@
+package ports
+
+import "context"
+
+// ManagerRecord is a person as of the effective date of an org unit write.
+type ManagerRecord struct {
+	PersonUUID  string
+	Pernr       string
+	DisplayName string
+	Active      bool
+}
+
+// ManagerDirectory resolves manager_pernr against the person directory. found is false when the tenant has
+// no person with that pernr; a person without an active version on asOf comes back with Active=false.
+type ManagerDirectory interface {
+	FindManagerAsOf(ctx context.Context, tenantID string, pernr string, asOf string) (ManagerRecord, bool, error)
+}
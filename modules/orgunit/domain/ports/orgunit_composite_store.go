@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// OrgUnitCompositeResult is what the kernel reports for a MERGE or SPLIT: the step events it submitted and
+// the per-unit snapshots after the operation, keyed by org_node_key.
+type OrgUnitCompositeResult struct {
+	OperationUUID string
+	OperationType string
+	EventUUIDs    []string
+	AfterSnapshot map[string]json.RawMessage
+}
+
+// OrgUnitCompositeWriteStore submits a composite operation atomically under a single request_id. Stores
+// that cannot run composite operations simply do not implement it.
+type OrgUnitCompositeWriteStore interface {
+	SubmitCompositeOperation(ctx context.Context, tenantID string, operationUUID string, operationType string, effectiveDate string, payload json.RawMessage, requestID string, initiatorUUID string) (OrgUnitCompositeResult, error)
+}
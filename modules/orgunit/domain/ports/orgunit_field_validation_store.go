@@ -0,0 +1,22 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+)
+
+// OrgUnitFieldValidationStore feeds tenant validation rules and as-of org records into the write path and
+// prechecks. Stores that do not implement it skip field validation.
+type OrgUnitFieldValidationStore interface {
+	ListEnabledFieldValidationRulesAsOf(ctx context.Context, tenantID string, asOf string) ([]types.TenantFieldValidationRule, error)
+	GetOrgUnitValidationRecordAsOf(ctx context.Context, tenantID string, orgNodeKey string, asOf string) (types.OrgUnitValidationRecord, bool, error)
+}
+
+// TenantFieldValidationRuleStore maintains the effective-dated tenant validation rules behind the
+// field-validation-rules API.
+type TenantFieldValidationRuleStore interface {
+	ListTenantFieldValidationRules(ctx context.Context, tenantID string) ([]types.TenantFieldValidationRuleRecord, error)
+	UpsertTenantFieldValidationRule(ctx context.Context, tenantID string, key types.TenantFieldValidationRuleKey, ruleExpr string, message *string, enabledOn string, requestID string, initiatorUUID string) (types.TenantFieldValidationRuleRecord, error)
+	DisableTenantFieldValidationRule(ctx context.Context, tenantID string, key types.TenantFieldValidationRuleKey, disabledOn string, requestID string, initiatorUUID string) (types.TenantFieldValidationRuleRecord, error)
+}
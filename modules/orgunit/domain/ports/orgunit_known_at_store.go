@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+)
+
+// OrgUnitKnownAtEventStore lists a tenant's effective event stream as known at knownAt, ordered by
+// org_node_key, effective_date and event id. Stores that cannot bound the stream by tx_time do not
+// implement it.
+type OrgUnitKnownAtEventStore interface {
+	ListReplayEventsKnownAt(ctx context.Context, tenantID string, knownAt time.Time) ([]types.OrgUnitReplayEvent, error)
+}
@@ -32,3 +32,16 @@ type OrgUnitResult struct {
 	EffectiveDate string
 	Fields        map[string]any
 }
+
+// OrgUnitReplayEvent is one effective org event as it was known at some transaction time, with the
+// corrections and rescinds recorded up to then already folded in by the kernel.
+type OrgUnitReplayEvent struct {
+	EventID       int64
+	EventUUID     string
+	OrgNodeKey    string
+	OrgCode       string
+	EventType     string
+	EffectiveDate string
+	Payload       json.RawMessage
+	TxTime        time.Time
+}
@@ -0,0 +1,43 @@
+package types
+
+import "time"
+
+// TenantFieldValidationRule is a tenant-authored CEL predicate over a candidate org unit record. A rule
+// passes when Expr evaluates to true. A rule with a FormKey only runs for the write that form submits and
+// overrides a rule without one that has the same FieldKey and RuleKey.
+type TenantFieldValidationRule struct {
+	FieldKey string
+	FormKey  string
+	RuleKey  string
+	Expr     string
+	Message  string
+}
+
+// TenantFieldValidationRuleKey identifies one effective-dated rule; FormKey is "" for a rule that applies
+// to every write.
+type TenantFieldValidationRuleKey struct {
+	FieldKey string
+	FormKey  string
+	RuleKey  string
+}
+
+// TenantFieldValidationRuleRecord is one stored effective-dated row of a tenant validation rule.
+type TenantFieldValidationRuleRecord struct {
+	ID         int64
+	FieldKey   string
+	FormKey    string
+	RuleKey    string
+	RuleExpr   string
+	Message    *string
+	EnabledOn  string
+	DisabledOn *string
+	UpdatedAt  time.Time
+}
+
+// OrgUnitValidationRecord is the flattened as-of view of an org unit that validation rules see: core
+// fields plus enabled ext fields keyed by field_key.
+type OrgUnitValidationRecord struct {
+	OrgNodeKey       string
+	ParentOrgNodeKey string
+	Fields           map[string]any
+}
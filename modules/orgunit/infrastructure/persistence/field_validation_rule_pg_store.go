@@ -0,0 +1,172 @@
+package persistence
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/ports"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+)
+
+// FieldValidationRulePGStore writes tenant validation rules through the orgunit kernel functions and reads
+// them back under the tenant's RLS context.
+type FieldValidationRulePGStore struct {
+	pool pgBeginner
+}
+
+func NewFieldValidationRulePGStore(pool pgBeginner) ports.TenantFieldValidationRuleStore {
+	return &FieldValidationRulePGStore{pool: pool}
+}
+
+const tenantFieldValidationRuleColumns = `
+  id,
+  field_key,
+  form_key,
+  rule_key,
+  rule_expr,
+  message,
+  enabled_on::text,
+  disabled_on::text,
+  updated_at`
+
+func (s *FieldValidationRulePGStore) ListTenantFieldValidationRules(ctx context.Context, tenantID string) ([]types.TenantFieldValidationRuleRecord, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+SELECT`+tenantFieldValidationRuleColumns+`
+FROM orgunit.tenant_field_validation_rules
+WHERE tenant_uuid = $1::uuid
+ORDER BY field_key ASC, rule_key ASC, form_key ASC, enabled_on DESC
+`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]types.TenantFieldValidationRuleRecord, 0)
+	for rows.Next() {
+		rule, err := scanTenantFieldValidationRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *FieldValidationRulePGStore) UpsertTenantFieldValidationRule(
+	ctx context.Context,
+	tenantID string,
+	key types.TenantFieldValidationRuleKey,
+	ruleExpr string,
+	message *string,
+	enabledOn string,
+	requestID string,
+	initiatorUUID string,
+) (types.TenantFieldValidationRuleRecord, error) {
+	return s.submitTenantFieldValidationRule(ctx, tenantID, `
+SELECT orgunit.upsert_tenant_field_validation_rule(
+  $1::uuid,
+  $2::text,
+  $3::text,
+  $4::text,
+  $5::text,
+  $6::text,
+  $7::date,
+  $8::text,
+  $9::uuid
+)
+`, tenantID, key.FieldKey, key.FormKey, key.RuleKey, ruleExpr, message, enabledOn, requestID, initiatorUUID)
+}
+
+func (s *FieldValidationRulePGStore) DisableTenantFieldValidationRule(
+	ctx context.Context,
+	tenantID string,
+	key types.TenantFieldValidationRuleKey,
+	disabledOn string,
+	requestID string,
+	initiatorUUID string,
+) (types.TenantFieldValidationRuleRecord, error) {
+	return s.submitTenantFieldValidationRule(ctx, tenantID, `
+SELECT orgunit.disable_tenant_field_validation_rule(
+  $1::uuid,
+  $2::text,
+  $3::text,
+  $4::text,
+  $5::date,
+  $6::text,
+  $7::uuid
+)
+`, tenantID, key.FieldKey, key.FormKey, key.RuleKey, disabledOn, requestID, initiatorUUID)
+}
+
+// submitTenantFieldValidationRule runs one kernel mutation and reads back the rule row it returned, in the
+// same transaction so a retried request_id yields the row the first attempt wrote.
+func (s *FieldValidationRulePGStore) submitTenantFieldValidationRule(ctx context.Context, tenantID string, sql string, args ...any) (types.TenantFieldValidationRuleRecord, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return types.TenantFieldValidationRuleRecord{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
+		return types.TenantFieldValidationRuleRecord{}, err
+	}
+
+	var ruleID int64
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&ruleID); err != nil {
+		return types.TenantFieldValidationRuleRecord{}, err
+	}
+
+	rule, err := scanTenantFieldValidationRule(tx.QueryRow(ctx, `
+SELECT`+tenantFieldValidationRuleColumns+`
+FROM orgunit.tenant_field_validation_rules
+WHERE tenant_uuid = $1::uuid
+  AND id = $2::bigint
+`, tenantID, ruleID))
+	if err != nil {
+		return types.TenantFieldValidationRuleRecord{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return types.TenantFieldValidationRuleRecord{}, err
+	}
+	return rule, nil
+}
+
+func scanTenantFieldValidationRule(row pgx.Row) (types.TenantFieldValidationRuleRecord, error) {
+	var rule types.TenantFieldValidationRuleRecord
+	if err := row.Scan(
+		&rule.ID,
+		&rule.FieldKey,
+		&rule.FormKey,
+		&rule.RuleKey,
+		&rule.RuleExpr,
+		&rule.Message,
+		&rule.EnabledOn,
+		&rule.DisabledOn,
+		&rule.UpdatedAt,
+	); err != nil {
+		return types.TenantFieldValidationRuleRecord{}, err
+	}
+	rule.Message = cloneOptionalString(rule.Message)
+	rule.DisabledOn = cloneOptionalString(rule.DisabledOn)
+	rule.RuleExpr = strings.TrimSpace(rule.RuleExpr)
+	return rule, nil
+}
@@ -0,0 +1,46 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+)
+
+func TestFieldValidationRulePGStore(t *testing.T) {
+	ctx := context.Background()
+	store := func(tx pgx.Tx, err error) *FieldValidationRulePGStore {
+		return NewFieldValidationRulePGStore(beginFunc(func(context.Context) (pgx.Tx, error) { return tx, err })).(*FieldValidationRulePGStore)
+	}
+	key := types.TenantFieldValidationRuleKey{FieldKey: "name", FormKey: "orgunit.create_dialog", RuleKey: "max_len"}
+
+	if _, err := store(nil, errors.New("begin")).ListTenantFieldValidationRules(ctx, "t1"); err == nil {
+		t.Fatal("expected begin error")
+	}
+	if _, err := store(&txStub{queryErr: errors.New("query")}, nil).ListTenantFieldValidationRules(ctx, "t1"); err == nil {
+		t.Fatal("expected query error")
+	}
+	rules, err := store(&txStub{rows: &rowsWithData{stubRows: &stubRows{}, data: [][]any{
+		{int64(1), "name", "", "max_len", " size(record.name) <= 40 "},
+	}}}, nil).ListTenantFieldValidationRules(ctx, "t1")
+	if err != nil || len(rules) != 1 || rules[0].FormKey != "" || rules[0].RuleExpr != "size(record.name) <= 40" {
+		t.Fatalf("rules=%+v err=%v", rules, err)
+	}
+
+	if _, err := store(&txStub{execErr: errors.New("exec")}, nil).UpsertTenantFieldValidationRule(ctx, "t1", key, "true", nil, "2026-01-01", "r1", "u1"); err == nil {
+		t.Fatal("expected exec error")
+	}
+	if _, err := store(&txStub{row: stubRow{err: errors.New("kernel")}}, nil).DisableTenantFieldValidationRule(ctx, "t1", key, "2026-02-01", "r2", "u1"); err == nil {
+		t.Fatal("expected kernel error")
+	}
+	row := stubRow{vals: []any{int64(7), "name", "orgunit.create_dialog", "max_len", "true"}}
+	if _, err := store(&txStub{row: row, commitErr: errors.New("commit")}, nil).UpsertTenantFieldValidationRule(ctx, "t1", key, "true", nil, "2026-01-01", "r1", "u1"); err == nil {
+		t.Fatal("expected commit error")
+	}
+	rule, err := store(&txStub{row: row}, nil).UpsertTenantFieldValidationRule(ctx, "t1", key, "true", nil, "2026-01-01", "r1", "u1")
+	if err != nil || rule.ID != 7 || rule.FormKey != "orgunit.create_dialog" || rule.RuleKey != "max_len" {
+		t.Fatalf("rule=%+v err=%v", rule, err)
+	}
+}
@@ -0,0 +1,71 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/ports"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+)
+
+// KnownAtEventPGStore reads the kernel's effective event stream bounded by tx_time. It only reads
+// org_events, so a known_at audit neither waits on nor blocks the tenant's org writes.
+type KnownAtEventPGStore struct {
+	pool pgBeginner
+}
+
+func NewKnownAtEventPGStore(pool pgBeginner) ports.OrgUnitKnownAtEventStore {
+	return &KnownAtEventPGStore{pool: pool}
+}
+
+// ListReplayEventsKnownAt folds only the events recorded at or before knownAt, so later corrections and
+// rescinds do not leak into audit reads. Units rescinded since keep their code from the CREATE payload.
+func (s *KnownAtEventPGStore) ListReplayEventsKnownAt(ctx context.Context, tenantID string, knownAt time.Time) ([]types.OrgUnitReplayEvent, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+SELECT
+  r.id,
+  r.event_uuid::text,
+  btrim(r.org_node_key::text),
+  COALESCE(c.org_code, ''),
+  r.event_type,
+  r.effective_date::text,
+  r.payload,
+  r.transaction_time
+FROM orgunit.org_events_effective_known_at($1::uuid, NULL, $2::timestamptz) r
+LEFT JOIN orgunit.org_unit_codes c
+  ON c.tenant_uuid = r.tenant_uuid
+ AND c.org_node_key = r.org_node_key
+ORDER BY r.org_node_key, r.effective_date, r.id
+`, tenantID, knownAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]types.OrgUnitReplayEvent, 0)
+	for rows.Next() {
+		var event types.OrgUnitReplayEvent
+		if err := rows.Scan(&event.EventID, &event.EventUUID, &event.OrgNodeKey, &event.OrgCode, &event.EventType, &event.EffectiveDate, &event.Payload, &event.TxTime); err != nil {
+			return nil, err
+		}
+		out = append(out, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,45 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestKnownAtEventPGStore(t *testing.T) {
+	ctx := context.Background()
+	knownAt := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	store := func(tx pgx.Tx, err error) *KnownAtEventPGStore {
+		return NewKnownAtEventPGStore(beginFunc(func(context.Context) (pgx.Tx, error) { return tx, err })).(*KnownAtEventPGStore)
+	}
+
+	if _, err := store(nil, errors.New("begin")).ListReplayEventsKnownAt(ctx, "t1", knownAt); err == nil {
+		t.Fatal("expected begin error")
+	}
+	if _, err := store(&txStub{execErr: errors.New("exec")}, nil).ListReplayEventsKnownAt(ctx, "t1", knownAt); err == nil {
+		t.Fatal("expected exec error")
+	}
+	if _, err := store(&txStub{queryErr: errors.New("query")}, nil).ListReplayEventsKnownAt(ctx, "t1", knownAt); err == nil {
+		t.Fatal("expected query error")
+	}
+	if _, err := store(&txStub{rows: &rowsWithData{stubRows: &stubRows{}, data: [][]any{{int64(1)}}, scanErr: errors.New("scan")}}, nil).ListReplayEventsKnownAt(ctx, "t1", knownAt); err == nil {
+		t.Fatal("expected scan error")
+	}
+
+	recorded := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	rows := func() *rowsWithData {
+		return &rowsWithData{stubRows: &stubRows{}, data: [][]any{
+			{int64(1), "e1", "AAAAAAAB", "ROOT", "CREATE", "2026-01-01", nil, recorded},
+		}}
+	}
+	if _, err := store(&txStub{rows: rows(), commitErr: errors.New("commit")}, nil).ListReplayEventsKnownAt(ctx, "t1", knownAt); err == nil {
+		t.Fatal("expected commit error")
+	}
+	events, err := store(&txStub{rows: rows()}, nil).ListReplayEventsKnownAt(ctx, "t1", knownAt)
+	if err != nil || len(events) != 1 || events[0].OrgCode != "ROOT" || events[0].EventType != "CREATE" || !events[0].TxTime.Equal(recorded) {
+		t.Fatalf("events=%+v err=%v", events, err)
+	}
+}
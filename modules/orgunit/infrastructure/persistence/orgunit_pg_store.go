@@ -60,6 +60,10 @@ func (s *OrgUnitPGStore) SubmitEvent(ctx context.Context, tenantID string, event
 		return 0, err
 	}
 
+	if _, err := orgunitpkg.FillSearchPinyin(ctx, tx, tenantID); err != nil {
+		return 0, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return 0, err
 	}
@@ -91,6 +95,10 @@ SELECT orgunit.submit_org_event_correction(
 		return "", err
 	}
 
+	if _, err := orgunitpkg.FillSearchPinyin(ctx, tx, tenantID); err != nil {
+		return "", err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return "", err
 	}
@@ -122,6 +130,10 @@ SELECT orgunit.submit_org_status_correction(
 		return "", err
 	}
 
+	if _, err := orgunitpkg.FillSearchPinyin(ctx, tx, tenantID); err != nil {
+		return "", err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return "", err
 	}
@@ -153,6 +165,10 @@ SELECT orgunit.submit_org_event_rescind(
 		return "", err
 	}
 
+	if _, err := orgunitpkg.FillSearchPinyin(ctx, tx, tenantID); err != nil {
+		return "", err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return "", err
 	}
@@ -183,12 +199,67 @@ SELECT orgunit.submit_org_rescind(
 		return 0, err
 	}
 
+	if _, err := orgunitpkg.FillSearchPinyin(ctx, tx, tenantID); err != nil {
+		return 0, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return 0, err
 	}
 	return rescindedEvents, nil
 }
 
+func (s *OrgUnitPGStore) SubmitCompositeOperation(ctx context.Context, tenantID string, operationUUID string, operationType string, effectiveDate string, payload json.RawMessage, requestID string, initiatorUUID string) (ports.OrgUnitCompositeResult, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return ports.OrgUnitCompositeResult{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
+		return ports.OrgUnitCompositeResult{}, err
+	}
+
+	var raw []byte
+	if err := tx.QueryRow(ctx, `
+SELECT orgunit.submit_org_composite(
+  $1::uuid,
+  $2::uuid,
+  $3::text,
+  $4::date,
+  $5::jsonb,
+  $6::text,
+  $7::uuid
+)
+`, operationUUID, tenantID, operationType, effectiveDate, []byte(payload), requestID, initiatorUUID).Scan(&raw); err != nil {
+		return ports.OrgUnitCompositeResult{}, err
+	}
+
+	var decoded struct {
+		OperationUUID string                     `json:"operation_uuid"`
+		OperationType string                     `json:"operation_type"`
+		EventUUIDs    []string                   `json:"event_uuids"`
+		AfterSnapshot map[string]json.RawMessage `json:"after_snapshot"`
+	}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return ports.OrgUnitCompositeResult{}, err
+	}
+
+	if _, err := orgunitpkg.FillSearchPinyin(ctx, tx, tenantID); err != nil {
+		return ports.OrgUnitCompositeResult{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ports.OrgUnitCompositeResult{}, err
+	}
+	return ports.OrgUnitCompositeResult{
+		OperationUUID: decoded.OperationUUID,
+		OperationType: decoded.OperationType,
+		EventUUIDs:    decoded.EventUUIDs,
+		AfterSnapshot: decoded.AfterSnapshot,
+	}, nil
+}
+
 func (s *OrgUnitPGStore) FindEventByUUID(ctx context.Context, tenantID string, eventUUID string) (types.OrgUnitEvent, error) {
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
@@ -444,6 +515,10 @@ SELECT orgunit.submit_org_event(
 		return 0, "", err
 	}
 
+	if _, err := orgunitpkg.FillSearchPinyin(ctx, tx, tenantID); err != nil {
+		return 0, "", err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return 0, "", err
 	}
@@ -566,6 +641,110 @@ func (s *OrgUnitPGStore) ResolveOrgCodeByNodeKey(ctx context.Context, tenantID s
 	return orgCode, nil
 }
 
+func (s *OrgUnitPGStore) ListEnabledFieldValidationRulesAsOf(ctx context.Context, tenantID string, asOf string) ([]types.TenantFieldValidationRule, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+SELECT field_key, form_key, rule_key, rule_expr, COALESCE(message, '')
+FROM orgunit.tenant_field_validation_rules
+WHERE tenant_uuid = $1::uuid
+  AND enabled_on <= $2::date
+  AND (disabled_on IS NULL OR $2::date < disabled_on)
+ORDER BY field_key ASC, rule_key ASC, form_key ASC
+`, tenantID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]types.TenantFieldValidationRule, 0)
+	for rows.Next() {
+		var rule types.TenantFieldValidationRule
+		if err := rows.Scan(&rule.FieldKey, &rule.FormKey, &rule.RuleKey, &rule.Expr, &rule.Message); err != nil {
+			return nil, err
+		}
+		out = append(out, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetOrgUnitValidationRecordAsOf reads the version of orgNodeKey valid on asOf as a flat field map: core
+// fields plus every ext field enabled on that day, read from its physical column.
+func (s *OrgUnitPGStore) GetOrgUnitValidationRecordAsOf(ctx context.Context, tenantID string, orgNodeKey string, asOf string) (types.OrgUnitValidationRecord, bool, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return types.OrgUnitValidationRecord{}, false, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
+		return types.OrgUnitValidationRecord{}, false, err
+	}
+
+	var record types.OrgUnitValidationRecord
+	var raw []byte
+	err = tx.QueryRow(ctx, `
+SELECT
+  v.org_node_key::text,
+  COALESCE(v.parent_org_node_key::text, ''),
+  COALESCE((
+    SELECT jsonb_object_agg(f.field_key, to_jsonb(v) -> f.physical_col)
+    FROM orgunit.tenant_field_configs f
+    WHERE f.tenant_uuid = v.tenant_uuid
+      AND f.enabled_on <= $3::date
+      AND (f.disabled_on IS NULL OR $3::date < f.disabled_on)
+  ), '{}'::jsonb) || jsonb_build_object(
+    'org_code', c.org_code,
+    'name', v.name,
+    'parent_org_code', COALESCE(pc.org_code, ''),
+    'status', v.status,
+    'is_business_unit', v.is_business_unit
+  )
+FROM orgunit.org_unit_versions v
+JOIN orgunit.org_unit_codes c
+  ON c.tenant_uuid = v.tenant_uuid
+ AND c.org_node_key = v.org_node_key
+LEFT JOIN orgunit.org_unit_codes pc
+  ON pc.tenant_uuid = v.tenant_uuid
+ AND pc.org_node_key = v.parent_org_node_key
+WHERE v.tenant_uuid = $1::uuid
+  AND v.org_node_key = $2::char(8)
+  AND v.validity @> $3::date
+LIMIT 1
+`, tenantID, orgNodeKey, asOf).Scan(&record.OrgNodeKey, &record.ParentOrgNodeKey, &raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return types.OrgUnitValidationRecord{}, false, nil
+	}
+	if err != nil {
+		return types.OrgUnitValidationRecord{}, false, err
+	}
+	if err := json.Unmarshal(raw, &record.Fields); err != nil {
+		return types.OrgUnitValidationRecord{}, false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return types.OrgUnitValidationRecord{}, false, err
+	}
+	record.OrgNodeKey = strings.TrimSpace(record.OrgNodeKey)
+	record.ParentOrgNodeKey = strings.TrimSpace(record.ParentOrgNodeKey)
+	return record, true, nil
+}
+
 func cloneOptionalString(in *string) *string {
 	if in == nil {
 		return nil
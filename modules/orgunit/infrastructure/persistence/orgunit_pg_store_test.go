@@ -605,3 +605,97 @@ func TestOrgUnitPGStore_ListEnabledTenantFieldConfigsAsOf(t *testing.T) {
 		t.Fatalf("cfg1=%+v", cfgs[1])
 	}
 }
+
+func TestOrgUnitPGStore_SubmitCompositeOperation(t *testing.T) {
+	ctx := context.Background()
+	submit := func(store ports.OrgUnitWriteStore) (ports.OrgUnitCompositeResult, error) {
+		return store.(ports.OrgUnitCompositeWriteStore).SubmitCompositeOperation(ctx, "t1", "op1", "MERGE", "2026-01-01", []byte(`{}`), "req", "t1")
+	}
+
+	store := NewOrgUnitPGStore(beginFunc(func(context.Context) (pgx.Tx, error) {
+		return nil, errors.New("begin")
+	}))
+	if _, err := submit(store); err == nil {
+		t.Fatal("expected begin error")
+	}
+
+	store = NewOrgUnitPGStore(beginFunc(func(context.Context) (pgx.Tx, error) {
+		return &txStub{execErr: errors.New("exec")}, nil
+	}))
+	if _, err := submit(store); err == nil {
+		t.Fatal("expected exec error")
+	}
+
+	store = NewOrgUnitPGStore(beginFunc(func(context.Context) (pgx.Tx, error) {
+		return &txStub{row: stubRow{err: errors.New("row")}}, nil
+	}))
+	if _, err := submit(store); err == nil {
+		t.Fatal("expected row error")
+	}
+
+	store = NewOrgUnitPGStore(beginFunc(func(context.Context) (pgx.Tx, error) {
+		return &txStub{row: stubRow{vals: []any{[]byte(`not-json`)}}}, nil
+	}))
+	if _, err := submit(store); err == nil {
+		t.Fatal("expected decode error")
+	}
+
+	result := []byte(`{"operation_uuid":"op1","operation_type":"MERGE","event_uuids":["e1","e2"],"after_snapshot":{"AAAAAAAB":{"status":"active"}}}`)
+	store = NewOrgUnitPGStore(beginFunc(func(context.Context) (pgx.Tx, error) {
+		return &txStub{row: stubRow{vals: []any{result}}, commitErr: errors.New("commit")}, nil
+	}))
+	if _, err := submit(store); err == nil {
+		t.Fatal("expected commit error")
+	}
+
+	store = NewOrgUnitPGStore(beginFunc(func(context.Context) (pgx.Tx, error) {
+		return &txStub{row: stubRow{vals: []any{result}}}, nil
+	}))
+	got, err := submit(store)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.OperationUUID != "op1" || len(got.EventUUIDs) != 2 || string(got.AfterSnapshot["AAAAAAAB"]) != `{"status":"active"}` {
+		t.Fatalf("result=%+v", got)
+	}
+}
+
+func TestOrgUnitPGStore_FieldValidationReads(t *testing.T) {
+	ctx := context.Background()
+	validation := func(tx pgx.Tx, err error) ports.OrgUnitFieldValidationStore {
+		return NewOrgUnitPGStore(beginFunc(func(context.Context) (pgx.Tx, error) { return tx, err })).(ports.OrgUnitFieldValidationStore)
+	}
+
+	if _, err := validation(nil, errors.New("begin")).ListEnabledFieldValidationRulesAsOf(ctx, "t1", "2026-01-01"); err == nil {
+		t.Fatal("expected begin error")
+	}
+	if _, err := validation(&txStub{queryErr: errors.New("query")}, nil).ListEnabledFieldValidationRulesAsOf(ctx, "t1", "2026-01-01"); err == nil {
+		t.Fatal("expected query error")
+	}
+	rules, err := validation(&txStub{rows: &rowsWithData{stubRows: &stubRows{}, data: [][]any{
+		{"cost_center", "", "six_digits", `record.cost_center.matches("^[0-9]{6}$")`, "6 digits"},
+	}}}, nil).ListEnabledFieldValidationRulesAsOf(ctx, "t1", "2026-01-01")
+	if err != nil || len(rules) != 1 || rules[0].RuleKey != "six_digits" || rules[0].Message != "6 digits" {
+		t.Fatalf("rules=%+v err=%v", rules, err)
+	}
+
+	if _, _, err := validation(&txStub{execErr: errors.New("exec")}, nil).GetOrgUnitValidationRecordAsOf(ctx, "t1", "AAAAAAAB", "2026-01-01"); err == nil {
+		t.Fatal("expected exec error")
+	}
+	if _, found, err := validation(&txStub{row: stubRow{err: pgx.ErrNoRows}}, nil).GetOrgUnitValidationRecordAsOf(ctx, "t1", "AAAAAAAB", "2026-01-01"); err != nil || found {
+		t.Fatalf("found=%v err=%v", found, err)
+	}
+	if _, _, err := validation(&txStub{row: stubRow{vals: []any{"AAAAAAAB", "", []byte(`{`)}}}, nil).GetOrgUnitValidationRecordAsOf(ctx, "t1", "AAAAAAAB", "2026-01-01"); err == nil {
+		t.Fatal("expected decode error")
+	}
+	if _, _, err := validation(&txStub{row: stubRow{vals: []any{"AAAAAAAB", "", []byte(`{}`)}}, commitErr: errors.New("commit")}, nil).GetOrgUnitValidationRecordAsOf(ctx, "t1", "AAAAAAAB", "2026-01-01"); err == nil {
+		t.Fatal("expected commit error")
+	}
+	record, found, err := validation(&txStub{row: stubRow{vals: []any{"AAAAAAAB", "AAAAAAAA", []byte(`{"name":"R&D","org_type":"site","location_code":null}`)}}}, nil).GetOrgUnitValidationRecordAsOf(ctx, "t1", "AAAAAAAB", "2026-01-01")
+	if err != nil || !found {
+		t.Fatalf("found=%v err=%v", found, err)
+	}
+	if record.ParentOrgNodeKey != "AAAAAAAA" || record.Fields["org_type"] != "site" || record.Fields["location_code"] != nil {
+		t.Fatalf("record=%+v", record)
+	}
+}
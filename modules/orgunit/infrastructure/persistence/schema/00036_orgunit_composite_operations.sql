@@ -0,0 +1,477 @@
+-- Org unit MERGE/SPLIT: a composite operation is one request_id, one audit row and a set of ordinary
+-- step events (MOVE/DISABLE/CREATE) submitted in the same transaction. Lineage records which units were
+-- folded into a survivor or carved out of a source.
+CREATE TABLE IF NOT EXISTS orgunit.org_composite_operations (
+  id bigserial PRIMARY KEY,
+  operation_uuid uuid NOT NULL UNIQUE,
+  tenant_uuid uuid NOT NULL,
+  operation_type text NOT NULL,
+  effective_date date NOT NULL,
+  payload jsonb NOT NULL DEFAULT '{}'::jsonb,
+  request_id text NOT NULL,
+  initiator_uuid uuid NOT NULL,
+  event_uuids uuid[] NOT NULL DEFAULT '{}'::uuid[],
+  before_snapshot jsonb NOT NULL DEFAULT '{}'::jsonb,
+  after_snapshot jsonb NOT NULL DEFAULT '{}'::jsonb,
+  tx_time timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT org_composite_operations_type_check CHECK (operation_type IN ('MERGE','SPLIT')),
+  CONSTRAINT org_composite_operations_payload_is_object_check CHECK (jsonb_typeof(payload) = 'object'),
+  CONSTRAINT org_composite_operations_request_id_unique UNIQUE (tenant_uuid, request_id)
+);
+
+CREATE TABLE IF NOT EXISTS orgunit.org_unit_lineage (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL,
+  org_node_key char(8) NOT NULL,
+  relation text NOT NULL,
+  related_org_node_key char(8) NOT NULL,
+  effective_date date NOT NULL,
+  operation_uuid uuid NOT NULL,
+  CONSTRAINT org_unit_lineage_relation_check CHECK (relation IN ('merged_into','split_from')),
+  CONSTRAINT org_unit_lineage_org_node_key_format_check CHECK (
+    orgunit.is_valid_org_node_key(btrim(org_node_key::text))
+  ),
+  CONSTRAINT org_unit_lineage_related_org_node_key_format_check CHECK (
+    orgunit.is_valid_org_node_key(btrim(related_org_node_key::text))
+  ),
+  CONSTRAINT org_unit_lineage_operation_fkey
+    FOREIGN KEY (operation_uuid)
+    REFERENCES orgunit.org_composite_operations(operation_uuid)
+    ON DELETE CASCADE,
+  CONSTRAINT org_unit_lineage_unique UNIQUE (tenant_uuid, org_node_key, relation, related_org_node_key, operation_uuid)
+);
+
+CREATE INDEX IF NOT EXISTS org_unit_lineage_node_idx
+  ON orgunit.org_unit_lineage (tenant_uuid, org_node_key);
+CREATE INDEX IF NOT EXISTS org_unit_lineage_related_idx
+  ON orgunit.org_unit_lineage (tenant_uuid, related_org_node_key);
+
+ALTER TABLE orgunit.org_composite_operations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.org_composite_operations FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.org_composite_operations;
+CREATE POLICY tenant_isolation ON orgunit.org_composite_operations
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
+
+ALTER TABLE orgunit.org_unit_lineage ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.org_unit_lineage FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.org_unit_lineage;
+CREATE POLICY tenant_isolation ON orgunit.org_unit_lineage
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
+
+-- orgunit.composite_children lists the direct children of p_parent as of p_as_of, regardless of status.
+CREATE OR REPLACE FUNCTION orgunit.composite_children(
+  p_tenant_uuid uuid,
+  p_parent char(8),
+  p_as_of date
+)
+RETURNS char(8)[]
+LANGUAGE sql
+STABLE
+AS $$
+  SELECT COALESCE(array_agg(v.org_node_key ORDER BY v.org_node_key), ARRAY[]::char(8)[])
+  FROM orgunit.org_unit_versions v
+  WHERE v.tenant_uuid = p_tenant_uuid
+    AND v.parent_org_node_key = p_parent
+    AND v.validity @> p_as_of;
+$$;
+
+CREATE OR REPLACE FUNCTION orgunit.submit_org_composite(
+  p_operation_uuid uuid,
+  p_tenant_uuid uuid,
+  p_operation_type text,
+  p_effective_date date,
+  p_payload jsonb,
+  p_request_id text,
+  p_initiator_uuid uuid
+)
+RETURNS jsonb
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_lock_key text;
+  v_payload jsonb;
+  v_existing orgunit.org_composite_operations%ROWTYPE;
+  v_survivor char(8);
+  v_source char(8);
+  v_sources char(8)[];
+  v_parent char(8);
+  v_child char(8);
+  v_children char(8)[];
+  v_involved char(8)[] := ARRAY[]::char(8)[];
+  v_created char(8)[] := ARRAY[]::char(8)[];
+  v_distributed char(8)[] := ARRAY[]::char(8)[];
+  v_unit jsonb;
+  v_new_key char(8);
+  v_status text;
+  v_step int := 0;
+  v_event_uuid uuid;
+  v_event_uuids uuid[] := ARRAY[]::uuid[];
+  v_before jsonb := '{}'::jsonb;
+  v_after jsonb := '{}'::jsonb;
+  v_key char(8);
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+
+  IF p_operation_uuid IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'operation_uuid is required';
+  END IF;
+  IF p_operation_type IS NULL OR p_operation_type NOT IN ('MERGE','SPLIT') THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = format('unsupported operation_type: %s', p_operation_type);
+  END IF;
+  IF p_effective_date IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'effective_date is required';
+  END IF;
+  IF p_request_id IS NULL OR btrim(p_request_id) = '' THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'request_id is required';
+  END IF;
+  IF p_initiator_uuid IS NULL THEN
+    RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'initiator_uuid is required';
+  END IF;
+
+  v_lock_key := format('org:write-lock:%s', p_tenant_uuid);
+  PERFORM pg_advisory_xact_lock(hashtextextended(v_lock_key, 0));
+
+  v_payload := COALESCE(p_payload, '{}'::jsonb);
+
+  SELECT * INTO v_existing
+  FROM orgunit.org_composite_operations
+  WHERE tenant_uuid = p_tenant_uuid
+    AND request_id = p_request_id;
+
+  IF FOUND THEN
+    IF v_existing.operation_type <> p_operation_type
+      OR v_existing.effective_date <> p_effective_date
+      OR v_existing.payload <> v_payload
+      OR v_existing.initiator_uuid <> p_initiator_uuid
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_REQUEST_ID_CONFLICT',
+        DETAIL = format('request_id=%s', p_request_id);
+    END IF;
+
+    RETURN jsonb_build_object(
+      'operation_uuid', v_existing.operation_uuid,
+      'operation_type', v_existing.operation_type,
+      'event_uuids', to_jsonb(v_existing.event_uuids),
+      'after_snapshot', v_existing.after_snapshot
+    );
+  END IF;
+
+  IF p_operation_type = 'MERGE' THEN
+    v_survivor := NULLIF(btrim(v_payload->>'survivor_org_node_key'), '')::char(8);
+    SELECT COALESCE(array_agg(DISTINCT btrim(x)::char(8)), ARRAY[]::char(8)[]) INTO v_sources
+    FROM jsonb_array_elements_text(COALESCE(v_payload->'source_org_node_keys', '[]'::jsonb)) AS x;
+
+    IF v_survivor IS NULL OR cardinality(v_sources) = 0 THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'survivor and sources are required';
+    END IF;
+    IF v_survivor = ANY(v_sources) THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_MERGE_SURVIVOR_INVALID',
+        DETAIL = format('survivor=%s is also a source', v_survivor);
+    END IF;
+
+    v_involved := ARRAY[v_survivor] || v_sources;
+    FOREACH v_key IN ARRAY v_involved LOOP
+      SELECT v.status, v.parent_org_node_key INTO v_status, v_parent
+      FROM orgunit.org_unit_versions v
+      WHERE v.tenant_uuid = p_tenant_uuid
+        AND v.org_node_key = v_key
+        AND v.validity @> p_effective_date;
+      IF NOT FOUND THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_NOT_FOUND_AS_OF',
+          DETAIL = format('org_node_key=%s as_of=%s', v_key, p_effective_date);
+      END IF;
+      IF v_status <> 'active' THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_COMPOSITE_TARGET_DISABLED',
+          DETAIL = format('org_node_key=%s as_of=%s', v_key, p_effective_date);
+      END IF;
+      IF v_key <> v_survivor AND v_parent IS NULL THEN
+        RAISE EXCEPTION USING
+          MESSAGE = 'ORG_ROOT_CANNOT_BE_MERGED',
+          DETAIL = format('org_node_key=%s', v_key);
+      END IF;
+    END LOOP;
+
+    -- Re-parenting a source's children under a survivor that sits inside that source's subtree would
+    -- create a cycle, so the survivor must live outside every source subtree.
+    IF EXISTS (
+      SELECT 1
+      FROM orgunit.org_unit_versions v
+      WHERE v.tenant_uuid = p_tenant_uuid
+        AND v.org_node_key = v_survivor
+        AND v.validity @> p_effective_date
+        AND v.path_node_keys && (SELECT array_agg(btrim(s::text)) FROM unnest(v_sources) AS s)
+    ) THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_MERGE_SURVIVOR_INVALID',
+        DETAIL = format('survivor=%s is inside a source subtree', v_survivor);
+    END IF;
+
+    FOREACH v_source IN ARRAY v_sources LOOP
+      v_involved := v_involved || orgunit.composite_children(p_tenant_uuid, v_source, p_effective_date);
+    END LOOP;
+    FOREACH v_key IN ARRAY v_involved LOOP
+      v_before := v_before || jsonb_build_object(btrim(v_key::text), orgunit.extract_orgunit_snapshot(p_tenant_uuid, v_key, p_effective_date));
+    END LOOP;
+
+    INSERT INTO orgunit.org_composite_operations (
+      operation_uuid, tenant_uuid, operation_type, effective_date, payload, request_id, initiator_uuid
+    )
+    VALUES (
+      p_operation_uuid, p_tenant_uuid, p_operation_type, p_effective_date, v_payload, p_request_id, p_initiator_uuid
+    );
+
+    FOREACH v_source IN ARRAY v_sources LOOP
+      v_children := orgunit.composite_children(p_tenant_uuid, v_source, p_effective_date);
+      FOREACH v_child IN ARRAY v_children LOOP
+        v_step := v_step + 1;
+        v_event_uuid := gen_random_uuid();
+        PERFORM orgunit.submit_org_event(
+          v_event_uuid, p_tenant_uuid, v_child, 'MOVE', p_effective_date,
+          jsonb_build_object('new_parent_org_node_key', btrim(v_survivor::text)),
+          format('%s#%s', p_request_id, v_step), p_initiator_uuid
+        );
+        v_event_uuids := v_event_uuids || v_event_uuid;
+      END LOOP;
+
+      v_step := v_step + 1;
+      v_event_uuid := gen_random_uuid();
+      PERFORM orgunit.submit_org_event(
+        v_event_uuid, p_tenant_uuid, v_source, 'DISABLE', p_effective_date,
+        '{}'::jsonb, format('%s#%s', p_request_id, v_step), p_initiator_uuid
+      );
+      v_event_uuids := v_event_uuids || v_event_uuid;
+
+      INSERT INTO orgunit.org_unit_lineage (tenant_uuid, org_node_key, relation, related_org_node_key, effective_date, operation_uuid)
+      VALUES (p_tenant_uuid, v_source, 'merged_into', v_survivor, p_effective_date, p_operation_uuid);
+    END LOOP;
+  ELSE
+    v_source := NULLIF(btrim(v_payload->>'source_org_node_key'), '')::char(8);
+    IF v_source IS NULL
+      OR jsonb_typeof(v_payload->'new_units') IS DISTINCT FROM 'array'
+      OR jsonb_array_length(v_payload->'new_units') = 0
+    THEN
+      RAISE EXCEPTION USING MESSAGE = 'ORG_INVALID_ARGUMENT', DETAIL = 'source and new_units are required';
+    END IF;
+
+    SELECT v.status, v.parent_org_node_key INTO v_status, v_parent
+    FROM orgunit.org_unit_versions v
+    WHERE v.tenant_uuid = p_tenant_uuid
+      AND v.org_node_key = v_source
+      AND v.validity @> p_effective_date;
+    IF NOT FOUND THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_NOT_FOUND_AS_OF',
+        DETAIL = format('org_node_key=%s as_of=%s', v_source, p_effective_date);
+    END IF;
+    IF v_status <> 'active' THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_COMPOSITE_TARGET_DISABLED',
+        DETAIL = format('org_node_key=%s as_of=%s', v_source, p_effective_date);
+    END IF;
+    IF v_parent IS NULL THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_ROOT_CANNOT_BE_SPLIT',
+        DETAIL = format('org_node_key=%s', v_source);
+    END IF;
+
+    v_children := orgunit.composite_children(p_tenant_uuid, v_source, p_effective_date);
+    FOR v_unit IN SELECT value FROM jsonb_array_elements(v_payload->'new_units') LOOP
+      FOR v_child IN
+        SELECT btrim(x)::char(8)
+        FROM jsonb_array_elements_text(COALESCE(v_unit->'child_org_node_keys', '[]'::jsonb)) AS x
+      LOOP
+        IF NOT (v_child = ANY(v_children)) THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_SPLIT_CHILD_NOT_UNDER_SOURCE',
+            DETAIL = format('child=%s source=%s', v_child, v_source);
+        END IF;
+        IF v_child = ANY(v_distributed) THEN
+          RAISE EXCEPTION USING
+            MESSAGE = 'ORG_INVALID_ARGUMENT',
+            DETAIL = format('child=%s is assigned twice', v_child);
+        END IF;
+        v_distributed := v_distributed || v_child;
+      END LOOP;
+    END LOOP;
+    IF COALESCE((v_payload->>'disable_source')::boolean, false)
+      AND EXISTS (SELECT 1 FROM unnest(v_children) AS c WHERE NOT (c = ANY(v_distributed)))
+    THEN
+      RAISE EXCEPTION USING
+        MESSAGE = 'ORG_SPLIT_SOURCE_HAS_CHILDREN',
+        DETAIL = format('source=%s', v_source);
+    END IF;
+
+    v_involved := ARRAY[v_source] || v_children;
+    FOREACH v_key IN ARRAY v_involved LOOP
+      v_before := v_before || jsonb_build_object(btrim(v_key::text), orgunit.extract_orgunit_snapshot(p_tenant_uuid, v_key, p_effective_date));
+    END LOOP;
+
+    INSERT INTO orgunit.org_composite_operations (
+      operation_uuid, tenant_uuid, operation_type, effective_date, payload, request_id, initiator_uuid
+    )
+    VALUES (
+      p_operation_uuid, p_tenant_uuid, p_operation_type, p_effective_date, v_payload, p_request_id, p_initiator_uuid
+    );
+
+    FOR v_unit IN SELECT value FROM jsonb_array_elements(v_payload->'new_units') LOOP
+      v_step := v_step + 1;
+      v_event_uuid := gen_random_uuid();
+      PERFORM orgunit.submit_org_event(
+        v_event_uuid, p_tenant_uuid, NULL, 'CREATE', p_effective_date,
+        jsonb_strip_nulls(jsonb_build_object(
+          'parent_org_node_key', btrim(v_parent::text),
+          'org_code', v_unit->>'org_code',
+          'name', v_unit->>'name',
+          'is_business_unit', v_unit->'is_business_unit'
+        )),
+        format('%s#%s', p_request_id, v_step), p_initiator_uuid
+      );
+      v_event_uuids := v_event_uuids || v_event_uuid;
+
+      SELECT e.org_node_key INTO v_new_key
+      FROM orgunit.org_events e
+      WHERE e.event_uuid = v_event_uuid;
+      v_created := v_created || v_new_key;
+
+      INSERT INTO orgunit.org_unit_lineage (tenant_uuid, org_node_key, relation, related_org_node_key, effective_date, operation_uuid)
+      VALUES (p_tenant_uuid, v_new_key, 'split_from', v_source, p_effective_date, p_operation_uuid);
+
+      FOR v_child IN
+        SELECT btrim(x)::char(8)
+        FROM jsonb_array_elements_text(COALESCE(v_unit->'child_org_node_keys', '[]'::jsonb)) AS x
+      LOOP
+        v_step := v_step + 1;
+        v_event_uuid := gen_random_uuid();
+        PERFORM orgunit.submit_org_event(
+          v_event_uuid, p_tenant_uuid, v_child, 'MOVE', p_effective_date,
+          jsonb_build_object('new_parent_org_node_key', btrim(v_new_key::text)),
+          format('%s#%s', p_request_id, v_step), p_initiator_uuid
+        );
+        v_event_uuids := v_event_uuids || v_event_uuid;
+      END LOOP;
+    END LOOP;
+
+    IF COALESCE((v_payload->>'disable_source')::boolean, false) THEN
+      v_step := v_step + 1;
+      v_event_uuid := gen_random_uuid();
+      PERFORM orgunit.submit_org_event(
+        v_event_uuid, p_tenant_uuid, v_source, 'DISABLE', p_effective_date,
+        '{}'::jsonb, format('%s#%s', p_request_id, v_step), p_initiator_uuid
+      );
+      v_event_uuids := v_event_uuids || v_event_uuid;
+    END IF;
+
+    v_involved := v_involved || v_created;
+  END IF;
+
+  FOREACH v_key IN ARRAY v_involved LOOP
+    v_after := v_after || jsonb_build_object(btrim(v_key::text), orgunit.extract_orgunit_snapshot(p_tenant_uuid, v_key, p_effective_date));
+  END LOOP;
+
+  UPDATE orgunit.org_composite_operations
+  SET event_uuids = v_event_uuids,
+      before_snapshot = v_before,
+      after_snapshot = v_after
+  WHERE operation_uuid = p_operation_uuid;
+
+  RETURN jsonb_build_object(
+    'operation_uuid', p_operation_uuid,
+    'operation_type', p_operation_type,
+    'event_uuids', to_jsonb(v_event_uuids),
+    'after_snapshot', v_after
+  );
+END;
+$$;
+
+ALTER TABLE IF EXISTS orgunit.org_composite_operations OWNER TO orgunit_kernel;
+ALTER TABLE IF EXISTS orgunit.org_unit_lineage OWNER TO orgunit_kernel;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE
+  orgunit.org_composite_operations,
+  orgunit.org_unit_lineage
+TO orgunit_kernel;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA orgunit TO orgunit_kernel;
+
+ALTER FUNCTION orgunit.composite_children(uuid, char(8), date)
+  OWNER TO orgunit_kernel;
+
+ALTER FUNCTION orgunit.submit_org_composite(uuid, uuid, text, date, jsonb, text, uuid)
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.submit_org_composite(uuid, uuid, text, date, jsonb, text, uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.submit_org_composite(uuid, uuid, text, date, jsonb, text, uuid)
+  SET search_path = pg_catalog, orgunit, public;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_nobypassrls') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE ' ||
+      'orgunit.org_composite_operations, ' ||
+      'orgunit.org_unit_lineage ' ||
+      'FROM app_nobypassrls';
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.org_composite_operations, ' ||
+      'orgunit.org_unit_lineage ' ||
+      'TO app_nobypassrls';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT ON TABLE ' ||
+      'orgunit.org_composite_operations, ' ||
+      'orgunit.org_unit_lineage ' ||
+      'TO superadmin_runtime';
+  END IF;
+END $$;
+
+-- Tenant hard delete also clears composite operations; lineage rows cascade with their operation.
+CREATE OR REPLACE FUNCTION orgunit.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'orgunit.org_unit_lineage',
+    'orgunit.org_composite_operations',
+    'orgunit.org_unit_versions',
+    'orgunit.org_unit_codes',
+    'orgunit.org_trees',
+    'orgunit.org_events',
+    'orgunit.org_node_key_registry',
+    'orgunit.tenant_field_policies',
+    'orgunit.tenant_field_policy_events',
+    'orgunit.tenant_field_configs',
+    'orgunit.tenant_field_config_events'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = orgunit.global_tenant_id() THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORGUNIT_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  RETURN v_counts;
+END;
+$$;
+
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SET search_path = pg_catalog, orgunit, public;
@@ -0,0 +1,280 @@
+-- Org unit search index: one row per org_unit_versions row, so an as_of search is a validity lookup.
+-- Name, full name path, org_code and status follow the versions/codes tables through triggers inside the
+-- event transaction; pinyin columns are computed by the application (the database has no pinyin
+-- dictionary) and filled through orgunit.set_org_unit_search_pinyin. NULL pinyin means "not yet filled".
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE IF NOT EXISTS orgunit.org_unit_search_index (
+  tenant_uuid uuid NOT NULL,
+  version_id bigint NOT NULL,
+  org_node_key char(8) NOT NULL,
+  validity daterange NOT NULL,
+  org_code text NOT NULL DEFAULT '',
+  name text NOT NULL,
+  full_name_path text NOT NULL DEFAULT '',
+  status text NOT NULL DEFAULT 'active',
+  name_pinyin text NULL,
+  name_initials text NULL,
+  PRIMARY KEY (tenant_uuid, version_id),
+  CONSTRAINT org_unit_search_index_org_node_key_format_check CHECK (
+    orgunit.is_valid_org_node_key(btrim(org_node_key::text))
+  )
+);
+
+CREATE INDEX IF NOT EXISTS org_unit_search_index_validity_gist
+  ON orgunit.org_unit_search_index
+  USING gist (tenant_uuid gist_uuid_ops, validity);
+
+CREATE INDEX IF NOT EXISTS org_unit_search_index_node_idx
+  ON orgunit.org_unit_search_index (tenant_uuid, org_node_key);
+
+CREATE INDEX IF NOT EXISTS org_unit_search_index_name_trgm
+  ON orgunit.org_unit_search_index
+  USING gin (lower(name) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS org_unit_search_index_path_trgm
+  ON orgunit.org_unit_search_index
+  USING gin (lower(full_name_path) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS org_unit_search_index_code_trgm
+  ON orgunit.org_unit_search_index
+  USING gin (lower(org_code) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS org_unit_search_index_pinyin_trgm
+  ON orgunit.org_unit_search_index
+  USING gin (name_pinyin gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS org_unit_search_index_initials_trgm
+  ON orgunit.org_unit_search_index
+  USING gin (name_initials gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS org_unit_search_index_pending_pinyin_idx
+  ON orgunit.org_unit_search_index (tenant_uuid)
+  WHERE name_pinyin IS NULL;
+
+ALTER TABLE orgunit.org_unit_search_index ENABLE ROW LEVEL SECURITY;
+ALTER TABLE orgunit.org_unit_search_index FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON orgunit.org_unit_search_index;
+CREATE POLICY tenant_isolation ON orgunit.org_unit_search_index
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
+
+-- orgunit.sync_org_unit_search_version mirrors one org_unit_versions row. Pinyin survives updates that
+-- keep the name and is cleared when the name changes.
+CREATE OR REPLACE FUNCTION orgunit.sync_org_unit_search_version()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    DELETE FROM orgunit.org_unit_search_index
+    WHERE tenant_uuid = OLD.tenant_uuid
+      AND version_id = OLD.id;
+    RETURN OLD;
+  END IF;
+
+  INSERT INTO orgunit.org_unit_search_index (
+    tenant_uuid,
+    version_id,
+    org_node_key,
+    validity,
+    org_code,
+    name,
+    full_name_path,
+    status
+  )
+  VALUES (
+    NEW.tenant_uuid,
+    NEW.id,
+    NEW.org_node_key,
+    NEW.validity,
+    COALESCE((
+      SELECT c.org_code
+      FROM orgunit.org_unit_codes c
+      WHERE c.tenant_uuid = NEW.tenant_uuid
+        AND c.org_node_key = NEW.org_node_key
+    ), ''),
+    NEW.name,
+    COALESCE(NEW.full_name_path, ''),
+    NEW.status
+  )
+  ON CONFLICT (tenant_uuid, version_id) DO UPDATE
+  SET org_node_key = EXCLUDED.org_node_key,
+      validity = EXCLUDED.validity,
+      org_code = EXCLUDED.org_code,
+      name = EXCLUDED.name,
+      full_name_path = EXCLUDED.full_name_path,
+      status = EXCLUDED.status,
+      name_pinyin = CASE WHEN orgunit.org_unit_search_index.name = EXCLUDED.name THEN orgunit.org_unit_search_index.name_pinyin END,
+      name_initials = CASE WHEN orgunit.org_unit_search_index.name = EXCLUDED.name THEN orgunit.org_unit_search_index.name_initials END;
+  RETURN NEW;
+END;
+$$;
+
+-- orgunit.sync_org_unit_search_code keeps org_code current; on CREATE the version row can land before
+-- its code row.
+CREATE OR REPLACE FUNCTION orgunit.sync_org_unit_search_code()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  UPDATE orgunit.org_unit_search_index
+  SET org_code = NEW.org_code
+  WHERE tenant_uuid = NEW.tenant_uuid
+    AND org_node_key = NEW.org_node_key
+    AND org_code IS DISTINCT FROM NEW.org_code;
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS org_unit_versions_search_index_sync ON orgunit.org_unit_versions;
+CREATE TRIGGER org_unit_versions_search_index_sync
+AFTER INSERT OR UPDATE OR DELETE ON orgunit.org_unit_versions
+FOR EACH ROW EXECUTE FUNCTION orgunit.sync_org_unit_search_version();
+
+DROP TRIGGER IF EXISTS org_unit_codes_search_index_sync ON orgunit.org_unit_codes;
+CREATE TRIGGER org_unit_codes_search_index_sync
+AFTER INSERT OR UPDATE OF org_code ON orgunit.org_unit_codes
+FOR EACH ROW EXECUTE FUNCTION orgunit.sync_org_unit_search_code();
+
+-- orgunit.set_org_unit_search_pinyin fills pinyin for index rows whose name still matches; it is the
+-- only write path into the index that the application role has. Returns the number of rows filled.
+CREATE OR REPLACE FUNCTION orgunit.set_org_unit_search_pinyin(
+  p_tenant_uuid uuid,
+  p_names text[],
+  p_pinyin text[],
+  p_initials text[]
+)
+RETURNS integer
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_count integer;
+BEGIN
+  PERFORM orgunit.assert_current_tenant(p_tenant_uuid);
+  IF COALESCE(array_length(p_names, 1), 0) <> COALESCE(array_length(p_pinyin, 1), 0)
+    OR COALESCE(array_length(p_names, 1), 0) <> COALESCE(array_length(p_initials, 1), 0) THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORG_INVALID_ARGUMENT',
+      DETAIL = 'names, pinyin and initials must have the same length';
+  END IF;
+
+  UPDATE orgunit.org_unit_search_index i
+  SET name_pinyin = t.pinyin,
+      name_initials = t.initials
+  FROM unnest(p_names, p_pinyin, p_initials) AS t(name, pinyin, initials)
+  WHERE i.tenant_uuid = p_tenant_uuid
+    AND i.name = t.name
+    AND i.name_pinyin IS NULL;
+  GET DIAGNOSTICS v_count = ROW_COUNT;
+  RETURN v_count;
+END;
+$$;
+
+INSERT INTO orgunit.org_unit_search_index (
+  tenant_uuid,
+  version_id,
+  org_node_key,
+  validity,
+  org_code,
+  name,
+  full_name_path,
+  status
+)
+SELECT
+  v.tenant_uuid,
+  v.id,
+  v.org_node_key,
+  v.validity,
+  COALESCE(c.org_code, ''),
+  v.name,
+  COALESCE(v.full_name_path, ''),
+  v.status
+FROM orgunit.org_unit_versions v
+LEFT JOIN orgunit.org_unit_codes c
+  ON c.tenant_uuid = v.tenant_uuid
+ AND c.org_node_key = v.org_node_key
+ON CONFLICT (tenant_uuid, version_id) DO NOTHING;
+
+ALTER TABLE IF EXISTS orgunit.org_unit_search_index OWNER TO orgunit_kernel;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE orgunit.org_unit_search_index TO orgunit_kernel;
+
+ALTER FUNCTION orgunit.sync_org_unit_search_version()
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.sync_org_unit_search_version()
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.sync_org_unit_search_version()
+  SET search_path = pg_catalog, orgunit, public;
+
+ALTER FUNCTION orgunit.sync_org_unit_search_code()
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.sync_org_unit_search_code()
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.sync_org_unit_search_code()
+  SET search_path = pg_catalog, orgunit, public;
+
+ALTER FUNCTION orgunit.set_org_unit_search_pinyin(uuid, text[], text[], text[])
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.set_org_unit_search_pinyin(uuid, text[], text[], text[])
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.set_org_unit_search_pinyin(uuid, text[], text[], text[])
+  SET search_path = pg_catalog, orgunit, public;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_nobypassrls') THEN
+    EXECUTE 'REVOKE INSERT, UPDATE, DELETE, TRUNCATE ON TABLE orgunit.org_unit_search_index FROM app_nobypassrls';
+    EXECUTE 'GRANT SELECT ON TABLE orgunit.org_unit_search_index TO app_nobypassrls';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT SELECT ON TABLE orgunit.org_unit_search_index TO superadmin_runtime';
+  END IF;
+END $$;
+
+-- Tenant hard delete also clears the search index.
+CREATE OR REPLACE FUNCTION orgunit.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_tables text[] := ARRAY[
+    'orgunit.org_unit_search_index',
+    'orgunit.org_unit_lineage',
+    'orgunit.org_composite_operations',
+    'orgunit.org_unit_versions',
+    'orgunit.org_unit_codes',
+    'orgunit.org_trees',
+    'orgunit.org_events',
+    'orgunit.org_node_key_registry',
+    'orgunit.tenant_field_policies',
+    'orgunit.tenant_field_policy_events',
+    'orgunit.tenant_field_configs',
+    'orgunit.tenant_field_config_events'
+  ];
+  v_table text;
+  v_count bigint;
+  v_counts jsonb := '{}'::jsonb;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = orgunit.global_tenant_id() THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'ORGUNIT_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  FOREACH v_table IN ARRAY v_tables LOOP
+    EXECUTE format('DELETE FROM %s WHERE tenant_uuid = $1', v_table) USING p_tenant_uuid;
+    GET DIAGNOSTICS v_count = ROW_COUNT;
+    v_counts := v_counts || jsonb_build_object(v_table, v_count);
+  END LOOP;
+
+  RETURN v_counts;
+END;
+$$;
+
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SECURITY DEFINER;
+ALTER FUNCTION orgunit.purge_tenant_data(uuid)
+  SET search_path = pg_catalog, orgunit, public;
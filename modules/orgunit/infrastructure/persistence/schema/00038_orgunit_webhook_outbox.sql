@@ -0,0 +1,56 @@
+-- Every committed org event is mirrored into iam.webhook_outbox by this trigger, so downstream systems get
+-- creates, moves, renames, status changes, corrections and rescinds without polling the audit API.
+CREATE OR REPLACE FUNCTION orgunit.enqueue_org_event_webhook()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_event_type text;
+BEGIN
+  v_event_type := CASE NEW.event_type
+    WHEN 'CREATE' THEN 'orgunit.created'
+    WHEN 'UPDATE' THEN 'orgunit.updated'
+    WHEN 'MOVE' THEN 'orgunit.moved'
+    WHEN 'RENAME' THEN 'orgunit.renamed'
+    WHEN 'DISABLE' THEN 'orgunit.disabled'
+    WHEN 'ENABLE' THEN 'orgunit.enabled'
+    WHEN 'SET_BUSINESS_UNIT' THEN 'orgunit.business_unit_changed'
+    WHEN 'CORRECT_EVENT' THEN 'orgunit.corrected'
+    WHEN 'CORRECT_STATUS' THEN 'orgunit.corrected'
+    WHEN 'RESCIND_EVENT' THEN 'orgunit.rescinded'
+    WHEN 'RESCIND_ORG' THEN 'orgunit.rescinded'
+    ELSE 'orgunit.changed'
+  END;
+
+  PERFORM iam.enqueue_webhook_event(
+    NEW.tenant_uuid,
+    'orgunit',
+    NEW.event_uuid,
+    v_event_type,
+    jsonb_build_object(
+      'org_node_key', btrim(NEW.org_node_key::text),
+      'org_code', COALESCE(NEW.after_snapshot->>'org_code', NEW.before_snapshot->>'org_code', NEW.payload->>'org_code'),
+      'effective_date', NEW.effective_date,
+      'source_event_type', NEW.event_type,
+      'target_event_uuid', NEW.payload->>'target_event_uuid',
+      'request_id', NEW.request_id,
+      'before', NEW.before_snapshot,
+      'after', NEW.after_snapshot
+    )
+  );
+  RETURN NULL;
+END;
+$$;
+
+ALTER FUNCTION orgunit.enqueue_org_event_webhook()
+  OWNER TO orgunit_kernel;
+ALTER FUNCTION orgunit.enqueue_org_event_webhook()
+  SET search_path = pg_catalog, orgunit, public;
+
+GRANT EXECUTE ON FUNCTION iam.enqueue_webhook_event(uuid, text, uuid, text, jsonb) TO orgunit_kernel;
+
+DROP TRIGGER IF EXISTS org_events_enqueue_webhook ON orgunit.org_events;
+CREATE TRIGGER org_events_enqueue_webhook
+AFTER INSERT ON orgunit.org_events
+FOR EACH ROW
+EXECUTE FUNCTION orgunit.enqueue_org_event_webhook();
@@ -7,6 +7,7 @@ import (
 	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/ports"
 	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/infrastructure/persistence"
 	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/services"
+	personmodule "github.com/jacksonlee411/Bugs-And-Blossoms/modules/person"
 )
 
 type PGBeginner interface {
@@ -21,6 +22,43 @@ func NewWriteService(store ports.OrgUnitWriteStore) services.OrgUnitWriteService
 	return services.NewOrgUnitWriteService(store)
 }
 
+// NewWriteServiceWithPGStore is the database-backed write service; manager_pernr is checked against the
+// person directory in the same database.
 func NewWriteServiceWithPGStore(pool PGBeginner) services.OrgUnitWriteService {
-	return services.NewOrgUnitWriteService(NewPGStore(pool))
+	return services.NewOrgUnitWriteServiceWithManagerDirectory(NewPGStore(pool), NewPersonManagerDirectory(personmodule.NewPGStore(pool)))
+}
+
+func NewWriteServiceWithManagerDirectory(store ports.OrgUnitWriteStore, managers ports.ManagerDirectory) services.OrgUnitWriteService {
+	return services.NewOrgUnitWriteServiceWithManagerDirectory(store, managers)
+}
+
+// NewPersonManagerDirectory lets orgunit writes resolve manager_pernr against the person directory.
+func NewPersonManagerDirectory(persons personmodule.Store) ports.ManagerDirectory {
+	return personManagerDirectory{persons: persons}
+}
+
+type personManagerDirectory struct {
+	persons personmodule.Store
+}
+
+func (d personManagerDirectory) FindManagerAsOf(ctx context.Context, tenantID string, pernr string, asOf string) (ports.ManagerRecord, bool, error) {
+	item, found, err := d.persons.FindByPernrAsOf(ctx, tenantID, pernr, asOf)
+	if err != nil || !found {
+		return ports.ManagerRecord{}, found, err
+	}
+	return ports.ManagerRecord{
+		PersonUUID:  item.PersonUUID,
+		Pernr:       item.Pernr,
+		DisplayName: item.DisplayName,
+		Active:      item.Status == personmodule.PersonStatusActive,
+	}, true, nil
+}
+
+func NewFieldValidationRulePGStore(pool PGBeginner) ports.TenantFieldValidationRuleStore {
+	return persistence.NewFieldValidationRulePGStore(pool)
+}
+
+// NewKnownAtEventPGStore reads the tx_time-bounded event stream that known_at snapshots are folded from.
+func NewKnownAtEventPGStore(pool PGBeginner) ports.OrgUnitKnownAtEventStore {
+	return persistence.NewKnownAtEventPGStore(pool)
 }
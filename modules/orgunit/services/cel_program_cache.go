@@ -0,0 +1,70 @@
+package services
+
+import (
+	"container/list"
+	"sync"
+)
+
+// celProgramCacheMaxEntries bounds each CEL compile cache. Rule expressions reach the caches from admin
+// endpoints with arbitrary text, so the caches evict instead of growing with every distinct expression.
+const celProgramCacheMaxEntries = 1024
+
+// celProgramCache is a mutex-guarded LRU keyed by expression text. It only memoizes compile work, so an
+// evicted entry just costs one recompile.
+type celProgramCache[V any] struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type celProgramCacheEntry[V any] struct {
+	key   string
+	value V
+}
+
+func newCELProgramCache[V any](maxEntries int) *celProgramCache[V] {
+	if maxEntries <= 0 {
+		maxEntries = celProgramCacheMaxEntries
+	}
+	return &celProgramCache[V]{
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+func (c *celProgramCache[V]) Load(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*celProgramCacheEntry[V]).value, true
+}
+
+func (c *celProgramCache[V]) Store(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*celProgramCacheEntry[V]).value = value
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&celProgramCacheEntry[V]{key: key, value: value})
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*celProgramCacheEntry[V]).key)
+	}
+}
+
+func (c *celProgramCache[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
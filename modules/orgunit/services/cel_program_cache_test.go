@@ -0,0 +1,31 @@
+package services
+
+import "testing"
+
+func TestCELProgramCache(t *testing.T) {
+	cache := newCELProgramCache[int](2)
+	cache.Store("a", 1)
+	cache.Store("b", 2)
+	if got, ok := cache.Load("a"); !ok || got != 1 {
+		t.Fatalf("got=%d ok=%v", got, ok)
+	}
+	// "b" is now the least recently used entry and is evicted first.
+	cache.Store("c", 3)
+	if _, ok := cache.Load("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if got, ok := cache.Load("a"); !ok || got != 1 {
+		t.Fatalf("got=%d ok=%v", got, ok)
+	}
+	cache.Store("c", 4)
+	if got, ok := cache.Load("c"); !ok || got != 4 {
+		t.Fatalf("got=%d ok=%v", got, ok)
+	}
+	if cache.Len() != 2 {
+		t.Fatalf("len=%d", cache.Len())
+	}
+
+	if newCELProgramCache[int](0).maxEntries != celProgramCacheMaxEntries {
+		t.Fatal("expected default bound")
+	}
+}
@@ -55,14 +55,15 @@ type CreateOrgUnitFieldDecisionV1 struct {
 }
 
 type CreateOrgUnitPrecheckProjectionV1 struct {
-	Readiness                         string                         `json:"readiness"`
-	MissingFields                     []string                       `json:"missing_fields"`
-	FieldDecisions                    []CreateOrgUnitFieldDecisionV1 `json:"field_decisions"`
-	CandidateConfirmationRequirements []string                       `json:"candidate_confirmation_requirements"`
-	PendingDraftSummary               string                         `json:"pending_draft_summary"`
-	PolicyExplain                     string                         `json:"policy_explain"`
-	RejectionReasons                  []string                       `json:"rejection_reasons"`
-	ProjectionDigest                  string                         `json:"projection_digest"`
+	Readiness                         string                          `json:"readiness"`
+	MissingFields                     []string                        `json:"missing_fields"`
+	FieldDecisions                    []CreateOrgUnitFieldDecisionV1  `json:"field_decisions"`
+	CandidateConfirmationRequirements []string                        `json:"candidate_confirmation_requirements"`
+	PendingDraftSummary               string                          `json:"pending_draft_summary"`
+	PolicyExplain                     string                          `json:"policy_explain"`
+	RejectionReasons                  []string                        `json:"rejection_reasons"`
+	FieldErrors                       []OrgUnitFieldValidationErrorV1 `json:"field_errors"`
+	ProjectionDigest                  string                          `json:"projection_digest"`
 }
 
 type CreateOrgUnitPrecheckInputV1 struct {
@@ -78,6 +79,7 @@ type CreateOrgUnitPrecheckInputV1 struct {
 	IsBusinessUnit                    *bool
 	Ext                               map[string]any
 	EnabledFieldConfigs               []types.TenantFieldConfig
+	InitiatorUUID                     string
 }
 
 type CreateOrgUnitPrecheckResultV1 struct {
@@ -152,6 +154,7 @@ func evaluateCreateOrgUnitPrecheckV1(
 			MissingFields:                     []string{},
 			FieldDecisions:                    []CreateOrgUnitFieldDecisionV1{},
 			RejectionReasons:                  []string{},
+			FieldErrors:                       []OrgUnitFieldValidationErrorV1{},
 		},
 	}
 
@@ -203,6 +206,9 @@ func evaluateCreateOrgUnitPrecheckV1(
 	}
 
 	if eval.Result.ContextError == nil && len(eval.Result.Projection.CandidateConfirmationRequirements) == 0 {
+		ruleRuntime := orgUnitRuleRuntimeLoader{load: func() (orgUnitRuleRuntimeContext, error) {
+			return resolveCreateOrgUnitDefaultRuleRuntime(ctx, reader, normalizedInput, eval.Result.PolicyContext.BusinessUnitNodeKey)
+		}}
 		orgCodeDecision, orgCodeFound, orgCodeErr := resolveCreateOrgUnitFieldDecision(ctx, reader, normalizedInput, eval.Result.PolicyContext.BusinessUnitNodeKey, orgUnitCreateFieldOrgCode)
 		if orgCodeErr != "" {
 			eval.Result.Projection.RejectionReasons = append(eval.Result.Projection.RejectionReasons, orgCodeErr)
@@ -210,7 +216,11 @@ func evaluateCreateOrgUnitPrecheckV1(
 		eval.OrgCodeDecision = orgCodeDecision
 		eval.OrgCodeFound = orgCodeFound
 		if orgCodeFound {
-			orgCodeValue, resolveErr := resolveCreateFieldDecisionValue(orgUnitCreateFieldOrgCode, normalizedInput.OrgCode, normalizedInput.OrgCode != "", orgCodeDecision)
+			orgCodeRuntime, err := ruleRuntime.forDecision(orgUnitCreateFieldOrgCode, orgCodeDecision)
+			if err != nil {
+				return createOrgUnitPrecheckEvaluation{}, err
+			}
+			orgCodeValue, resolveErr := resolveCreateFieldDecisionValue(orgUnitCreateFieldOrgCode, normalizedInput.OrgCode, normalizedInput.OrgCode != "", orgCodeDecision, orgCodeRuntime)
 			if resolveErr != nil {
 				eval.Result.Projection.RejectionReasons = append(eval.Result.Projection.RejectionReasons, strings.TrimSpace(resolveErr.Error()))
 			} else {
@@ -237,7 +247,11 @@ func evaluateCreateOrgUnitPrecheckV1(
 		eval.OrgTypeDecision = orgTypeDecision
 		eval.OrgTypeFound = orgTypeFound
 		if orgTypeFound {
-			orgTypeValue, resolveErr := resolveCreateFieldDecisionValue(orgUnitCreateFieldOrgType, providedOrgType, orgTypeProvided, orgTypeDecision)
+			orgTypeRuntime, err := ruleRuntime.forDecision(orgUnitCreateFieldOrgType, orgTypeDecision)
+			if err != nil {
+				return createOrgUnitPrecheckEvaluation{}, err
+			}
+			orgTypeValue, resolveErr := resolveCreateFieldDecisionValue(orgUnitCreateFieldOrgType, providedOrgType, orgTypeProvided, orgTypeDecision, orgTypeRuntime)
 			if resolveErr != nil {
 				eval.Result.Projection.RejectionReasons = append(eval.Result.Projection.RejectionReasons, strings.TrimSpace(resolveErr.Error()))
 			} else {
@@ -257,6 +271,23 @@ func evaluateCreateOrgUnitPrecheckV1(
 		}
 	}
 
+	if eval.Result.ContextError == nil && len(eval.Result.Projection.CandidateConfirmationRequirements) == 0 {
+		fieldErrors, err := resolvePrecheckFieldValidationErrors(ctx, reader, normalizedInput.TenantID, orgUnitFieldValidationTarget{
+			Intent:        string(OrgUnitWriteIntentCreateOrg),
+			AsOf:          normalizedInput.EffectiveDate,
+			OrgCode:       normalizedInput.OrgCode,
+			ParentNodeKey: eval.Result.PolicyContext.BusinessUnitNodeKey,
+			Patch:         buildCreateOrgUnitFieldValidationPatch(normalizedInput),
+		})
+		if err != nil {
+			return createOrgUnitPrecheckEvaluation{}, err
+		}
+		eval.Result.Projection.FieldErrors = fieldErrors
+		if len(fieldErrors) > 0 {
+			eval.Result.Projection.RejectionReasons = append(eval.Result.Projection.RejectionReasons, errFieldValidationFailed)
+		}
+	}
+
 	eval.Result.Projection.MissingFields = normalizeCreateOrgUnitMissingFields(
 		normalizedInput.EffectiveDate,
 		normalizedInput.Name,
@@ -285,6 +316,19 @@ func normalizeCreateOrgUnitPrecheckInput(input CreateOrgUnitPrecheckInputV1) Cre
 	return input
 }
 
+func buildCreateOrgUnitFieldValidationPatch(input CreateOrgUnitPrecheckInputV1) map[string]any {
+	patch := map[string]any{
+		"name":            input.Name,
+		"parent_org_code": input.BusinessUnitOrgCode,
+		"manager_pernr":   input.ManagerPernr,
+		"ext":             input.Ext,
+	}
+	if input.IsBusinessUnit != nil {
+		patch["is_business_unit"] = *input.IsBusinessUnit
+	}
+	return patch
+}
+
 func resolveCreateOrgUnitEnabledFieldConfigs(
 	ctx context.Context,
 	reader CreateOrgUnitPrecheckReader,
@@ -660,13 +704,14 @@ func buildCreateOrgUnitPolicyContextDigest(ctx CreateOrgUnitPolicyContextV1) str
 
 func buildCreateOrgUnitProjectionDigest(projection CreateOrgUnitPrecheckProjectionV1) string {
 	payload := struct {
-		Readiness                         string                         `json:"readiness"`
-		MissingFields                     []string                       `json:"missing_fields"`
-		FieldDecisions                    []CreateOrgUnitFieldDecisionV1 `json:"field_decisions"`
-		CandidateConfirmationRequirements []string                       `json:"candidate_confirmation_requirements"`
-		PendingDraftSummary               string                         `json:"pending_draft_summary"`
-		PolicyExplain                     string                         `json:"policy_explain"`
-		RejectionReasons                  []string                       `json:"rejection_reasons"`
+		Readiness                         string                          `json:"readiness"`
+		MissingFields                     []string                        `json:"missing_fields"`
+		FieldDecisions                    []CreateOrgUnitFieldDecisionV1  `json:"field_decisions"`
+		CandidateConfirmationRequirements []string                        `json:"candidate_confirmation_requirements"`
+		PendingDraftSummary               string                          `json:"pending_draft_summary"`
+		PolicyExplain                     string                          `json:"policy_explain"`
+		RejectionReasons                  []string                        `json:"rejection_reasons"`
+		FieldErrors                       []OrgUnitFieldValidationErrorV1 `json:"field_errors"`
 	}{
 		Readiness:                         strings.TrimSpace(projection.Readiness),
 		MissingFields:                     append([]string(nil), projection.MissingFields...),
@@ -675,6 +720,7 @@ func buildCreateOrgUnitProjectionDigest(projection CreateOrgUnitPrecheckProjecti
 		PendingDraftSummary:               strings.TrimSpace(projection.PendingDraftSummary),
 		PolicyExplain:                     strings.TrimSpace(projection.PolicyExplain),
 		RejectionReasons:                  append([]string(nil), projection.RejectionReasons...),
+		FieldErrors:                       cloneOrgUnitFieldValidationErrors(projection.FieldErrors),
 	}
 	return createOrgUnitDigest(payload)
 }
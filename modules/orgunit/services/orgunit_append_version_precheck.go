@@ -60,6 +60,7 @@ type OrgUnitAppendVersionPrecheckProjectionV1 struct {
 	PendingDraftSummary               string                                `json:"pending_draft_summary"`
 	PolicyExplain                     string                                `json:"policy_explain"`
 	RejectionReasons                  []string                              `json:"rejection_reasons"`
+	FieldErrors                       []OrgUnitFieldValidationErrorV1       `json:"field_errors"`
 	ProjectionDigest                  string                                `json:"projection_digest"`
 }
 
@@ -153,6 +154,7 @@ func evaluateOrgUnitAppendVersionPrecheckV1(
 			MissingFields:                     []string{},
 			FieldDecisions:                    []OrgUnitAppendVersionFieldDecisionV1{},
 			RejectionReasons:                  []string{},
+			FieldErrors:                       []OrgUnitFieldValidationErrorV1{},
 		},
 	}
 
@@ -219,6 +221,35 @@ func evaluateOrgUnitAppendVersionPrecheckV1(
 		}
 		eval.ParentDecision = parentDecision
 		eval.ParentFound = parentFound
+
+		if normalizedInput.EffectiveDate != "" {
+			parentNodeKey, err := resolvePrecheckFieldValidationParentNodeKey(ctx, reader, normalizedInput.TenantID, normalizedInput.NewParentOrgCode)
+			if err != nil {
+				return orgUnitAppendVersionPrecheckEvaluation{}, err
+			}
+			patch := map[string]any{}
+			if normalizedInput.NewName != "" {
+				patch["name"] = normalizedInput.NewName
+			}
+			if normalizedInput.NewParentOrgCode != "" {
+				patch["parent_org_code"] = normalizedInput.NewParentOrgCode
+			}
+			fieldErrors, err := resolvePrecheckFieldValidationErrors(ctx, reader, normalizedInput.TenantID, orgUnitFieldValidationTarget{
+				Intent:        normalizedInput.Intent,
+				AsOf:          normalizedInput.EffectiveDate,
+				OrgCode:       normalizedInput.OrgCode,
+				OrgNodeKey:    eval.Result.PolicyContext.OrgNodeKey,
+				ParentNodeKey: parentNodeKey,
+				Patch:         patch,
+			})
+			if err != nil {
+				return orgUnitAppendVersionPrecheckEvaluation{}, err
+			}
+			eval.Result.Projection.FieldErrors = fieldErrors
+			if len(fieldErrors) > 0 {
+				eval.Result.Projection.RejectionReasons = append(eval.Result.Projection.RejectionReasons, errFieldValidationFailed)
+			}
+		}
 	}
 
 	eval.Result.Projection.MissingFields = normalizeOrgUnitAppendVersionMissingFields(normalizedInput, eval)
@@ -626,6 +657,7 @@ func buildOrgUnitAppendVersionProjectionDigest(projection OrgUnitAppendVersionPr
 		PendingDraftSummary               string                                `json:"pending_draft_summary"`
 		PolicyExplain                     string                                `json:"policy_explain"`
 		RejectionReasons                  []string                              `json:"rejection_reasons"`
+		FieldErrors                       []OrgUnitFieldValidationErrorV1       `json:"field_errors"`
 	}{
 		Readiness:                         strings.TrimSpace(projection.Readiness),
 		MissingFields:                     append([]string(nil), projection.MissingFields...),
@@ -634,6 +666,7 @@ func buildOrgUnitAppendVersionProjectionDigest(projection OrgUnitAppendVersionPr
 		PendingDraftSummary:               strings.TrimSpace(projection.PendingDraftSummary),
 		PolicyExplain:                     strings.TrimSpace(projection.PolicyExplain),
 		RejectionReasons:                  append([]string(nil), projection.RejectionReasons...),
+		FieldErrors:                       cloneOrgUnitFieldValidationErrors(projection.FieldErrors),
 	}
 	return orgUnitAppendVersionDigest(payload)
 }
@@ -666,6 +699,7 @@ func CloneOrgUnitAppendVersionProjectionV1(projection OrgUnitAppendVersionPreche
 		PendingDraftSummary:               strings.TrimSpace(projection.PendingDraftSummary),
 		PolicyExplain:                     strings.TrimSpace(projection.PolicyExplain),
 		RejectionReasons:                  append([]string(nil), projection.RejectionReasons...),
+		FieldErrors:                       cloneOrgUnitFieldValidationErrors(projection.FieldErrors),
 		ProjectionDigest:                  strings.TrimSpace(projection.ProjectionDigest),
 	}
 }
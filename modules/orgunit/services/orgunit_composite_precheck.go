@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/httperr"
+	orgunitpkg "github.com/jacksonlee411/Bugs-And-Blossoms/pkg/orgunit"
+)
+
+const (
+	errOrgMergeSurvivorInvalid     = "ORG_MERGE_SURVIVOR_INVALID"
+	errOrgSplitChildNotUnderSource = "ORG_SPLIT_CHILD_NOT_UNDER_SOURCE"
+	errOrgAlreadyExists            = "ORG_ALREADY_EXISTS"
+	errOrgCompositeUnsupported     = "ORG_COMPOSITE_UNSUPPORTED"
+	orgUnitCompositeMaxTargets     = 50
+)
+
+// orgUnitCompositePlan is a MERGE or SPLIT after precheck: codes are normalized and resolved to node keys,
+// the kernel payload is ready, and deny reasons found while resolving are handed to the mutation policy.
+type orgUnitCompositePlan struct {
+	Action          OrgUnitActionKind
+	Emitted         OrgUnitEmittedEventType
+	EffectiveDate   string
+	OrgCode         string
+	IsRoot          bool
+	Payload         map[string]any
+	CreatedOrgCodes []string
+	DenyReasons     []string
+}
+
+// precheckMerge validates a MERGE request: the survivor stays, every source has its children re-parented
+// under the survivor and is then disabled.
+func (s *orgUnitWriteService) precheckMerge(ctx context.Context, tenantID string, req MergeOrgUnitsRequest) (orgUnitCompositePlan, error) {
+	effectiveDate, err := validateDate(req.EffectiveDate)
+	if err != nil {
+		return orgUnitCompositePlan{}, err
+	}
+	survivorCode, err := normalizeOrgCode(req.SurvivorOrgCode)
+	if err != nil {
+		return orgUnitCompositePlan{}, err
+	}
+	sourceCodes, err := normalizeCompositeOrgCodes(req.SourceOrgCodes)
+	if err != nil {
+		return orgUnitCompositePlan{}, err
+	}
+	if len(sourceCodes) == 0 {
+		return orgUnitCompositePlan{}, httperr.NewBadRequest("source_org_codes is required")
+	}
+
+	plan := orgUnitCompositePlan{
+		Action:        OrgUnitActionMerge,
+		Emitted:       OrgUnitEmittedMerge,
+		EffectiveDate: effectiveDate,
+		OrgCode:       survivorCode,
+	}
+	survivorKey, err := s.resolveCompositeOrgNodeKey(ctx, tenantID, survivorCode)
+	if err != nil {
+		return orgUnitCompositePlan{}, err
+	}
+	sourceKeys := make([]string, 0, len(sourceCodes))
+	for _, code := range sourceCodes {
+		if code == survivorCode {
+			plan.DenyReasons = append(plan.DenyReasons, errOrgMergeSurvivorInvalid)
+			continue
+		}
+		if strings.EqualFold(code, "ROOT") {
+			plan.IsRoot = true
+		}
+		key, err := s.resolveCompositeOrgNodeKey(ctx, tenantID, code)
+		if err != nil {
+			return orgUnitCompositePlan{}, err
+		}
+		sourceKeys = append(sourceKeys, key)
+	}
+
+	plan.Payload = map[string]any{
+		"survivor_org_node_key": survivorKey,
+		"source_org_node_keys":  sourceKeys,
+	}
+	return plan, nil
+}
+
+// precheckSplit validates a SPLIT request: new siblings of the source are created under the source's
+// parent and the selected children of the source are distributed among them.
+func (s *orgUnitWriteService) precheckSplit(ctx context.Context, tenantID string, req SplitOrgUnitRequest) (orgUnitCompositePlan, error) {
+	effectiveDate, err := validateDate(req.EffectiveDate)
+	if err != nil {
+		return orgUnitCompositePlan{}, err
+	}
+	sourceCode, err := normalizeOrgCode(req.SourceOrgCode)
+	if err != nil {
+		return orgUnitCompositePlan{}, err
+	}
+	if len(req.NewUnits) == 0 {
+		return orgUnitCompositePlan{}, httperr.NewBadRequest("new_units is required")
+	}
+	if len(req.NewUnits) > orgUnitCompositeMaxTargets {
+		return orgUnitCompositePlan{}, httperr.NewBadRequest(errOrgInvalidArgument)
+	}
+
+	plan := orgUnitCompositePlan{
+		Action:        OrgUnitActionSplit,
+		Emitted:       OrgUnitEmittedSplit,
+		EffectiveDate: effectiveDate,
+		OrgCode:       sourceCode,
+		IsRoot:        strings.EqualFold(sourceCode, "ROOT"),
+	}
+	sourceKey, err := s.resolveCompositeOrgNodeKey(ctx, tenantID, sourceCode)
+	if err != nil {
+		return orgUnitCompositePlan{}, err
+	}
+
+	seenCodes := map[string]struct{}{sourceCode: {}}
+	units := make([]map[string]any, 0, len(req.NewUnits))
+	for _, unit := range req.NewUnits {
+		code, err := normalizeOrgCode(unit.OrgCode)
+		if err != nil {
+			return orgUnitCompositePlan{}, err
+		}
+		if _, dup := seenCodes[code]; dup {
+			return orgUnitCompositePlan{}, httperr.NewBadRequest(errOrgInvalidArgument)
+		}
+		seenCodes[code] = struct{}{}
+		name := strings.TrimSpace(unit.Name)
+		if name == "" {
+			return orgUnitCompositePlan{}, httperr.NewBadRequest("name is required")
+		}
+		if _, err := s.store.ResolveOrgNodeKey(ctx, tenantID, code); err == nil {
+			plan.DenyReasons = append(plan.DenyReasons, errOrgAlreadyExists)
+		} else if !errors.Is(err, orgunitpkg.ErrOrgCodeNotFound) {
+			return orgUnitCompositePlan{}, err
+		}
+
+		childCodes, err := normalizeCompositeOrgCodes(unit.ChildOrgCodes)
+		if err != nil {
+			return orgUnitCompositePlan{}, err
+		}
+		childKeys := make([]string, 0, len(childCodes))
+		for _, childCode := range childCodes {
+			if _, dup := seenCodes[childCode]; dup {
+				plan.DenyReasons = append(plan.DenyReasons, errOrgSplitChildNotUnderSource)
+				continue
+			}
+			seenCodes[childCode] = struct{}{}
+			key, err := s.resolveCompositeOrgNodeKey(ctx, tenantID, childCode)
+			if err != nil {
+				return orgUnitCompositePlan{}, err
+			}
+			childKeys = append(childKeys, key)
+		}
+
+		item := map[string]any{
+			"org_code":            code,
+			"name":                name,
+			"child_org_node_keys": childKeys,
+		}
+		if unit.IsBusinessUnit != nil {
+			item["is_business_unit"] = *unit.IsBusinessUnit
+		}
+		units = append(units, item)
+		plan.CreatedOrgCodes = append(plan.CreatedOrgCodes, code)
+	}
+
+	plan.Payload = map[string]any{
+		"source_org_node_key": sourceKey,
+		"new_units":           units,
+		"disable_source":      req.DisableSource,
+	}
+	return plan, nil
+}
+
+func (s *orgUnitWriteService) resolveCompositeOrgNodeKey(ctx context.Context, tenantID string, orgCode string) (string, error) {
+	key, err := s.store.ResolveOrgNodeKey(ctx, tenantID, orgCode)
+	if err != nil {
+		if errors.Is(err, orgunitpkg.ErrOrgCodeNotFound) {
+			return "", errors.New(errOrgCodeNotFound)
+		}
+		return "", err
+	}
+	return key, nil
+}
+
+func normalizeCompositeOrgCodes(raw []string) ([]string, error) {
+	if len(raw) > orgUnitCompositeMaxTargets {
+		return nil, httperr.NewBadRequest(errOrgInvalidArgument)
+	}
+	out := make([]string, 0, len(raw))
+	seen := make(map[string]struct{}, len(raw))
+	for _, item := range raw {
+		code, err := normalizeOrgCode(item)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[code]; ok {
+			continue
+		}
+		seen[code] = struct{}{}
+		out = append(out, code)
+	}
+	return out, nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/ports"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/httperr"
+)
+
+type MergeOrgUnitsRequest struct {
+	EffectiveDate   string
+	SurvivorOrgCode string
+	SourceOrgCodes  []string
+	RequestID       string
+	InitiatorUUID   string
+}
+
+type SplitOrgUnitNewUnit struct {
+	OrgCode        string
+	Name           string
+	IsBusinessUnit *bool
+	ChildOrgCodes  []string
+}
+
+type SplitOrgUnitRequest struct {
+	EffectiveDate string
+	SourceOrgCode string
+	NewUnits      []SplitOrgUnitNewUnit
+	DisableSource bool
+	RequestID     string
+	InitiatorUUID string
+}
+
+// Merge folds the source units into the survivor as one MERGE operation: children of every source move
+// under the survivor, the sources are disabled, and each source records merged_into lineage.
+func (s *orgUnitWriteService) Merge(ctx context.Context, tenantID string, req MergeOrgUnitsRequest) (types.OrgUnitResult, error) {
+	plan, err := s.precheckMerge(ctx, tenantID, req)
+	if err != nil {
+		return types.OrgUnitResult{}, err
+	}
+	return s.submitComposite(ctx, tenantID, plan, req.RequestID, req.InitiatorUUID)
+}
+
+// Split creates new siblings of the source as one SPLIT operation and distributes the selected children
+// among them; each new unit records split_from lineage.
+func (s *orgUnitWriteService) Split(ctx context.Context, tenantID string, req SplitOrgUnitRequest) (types.OrgUnitResult, error) {
+	plan, err := s.precheckSplit(ctx, tenantID, req)
+	if err != nil {
+		return types.OrgUnitResult{}, err
+	}
+	return s.submitComposite(ctx, tenantID, plan, req.RequestID, req.InitiatorUUID)
+}
+
+func (s *orgUnitWriteService) submitComposite(ctx context.Context, tenantID string, plan orgUnitCompositePlan, rawRequestID string, initiatorUUID string) (types.OrgUnitResult, error) {
+	requestID := strings.TrimSpace(rawRequestID)
+	if requestID == "" {
+		return types.OrgUnitResult{}, httperr.NewBadRequest("request_id is required")
+	}
+
+	decision, err := resolveOrgUnitMutationPolicyInWrite(OrgUnitMutationPolicyKey{
+		ActionKind:       plan.Action,
+		EmittedEventType: plan.Emitted,
+	}, OrgUnitMutationPolicyFacts{
+		CanAdmin:             true,
+		TreeInitialized:      true,
+		TargetExistsAsOf:     true,
+		IsRoot:               plan.IsRoot,
+		CompositeDenyReasons: plan.DenyReasons,
+	})
+	if err != nil {
+		return types.OrgUnitResult{}, err
+	}
+	if !decision.Enabled {
+		if len(decision.DenyReasons) > 0 {
+			return types.OrgUnitResult{}, errors.New(decision.DenyReasons[0])
+		}
+		return types.OrgUnitResult{}, httperr.NewBadRequest(errOrgInvalidArgument)
+	}
+
+	submitter, ok := s.store.(ports.OrgUnitCompositeWriteStore)
+	if !ok {
+		return types.OrgUnitResult{}, errors.New(errOrgCompositeUnsupported)
+	}
+	payloadJSON, err := marshalJSON(plan.Payload)
+	if err != nil {
+		return types.OrgUnitResult{}, err
+	}
+	operationUUID, err := newUUID()
+	if err != nil {
+		return types.OrgUnitResult{}, err
+	}
+	result, err := submitter.SubmitCompositeOperation(ctx, tenantID, operationUUID, string(plan.Emitted), plan.EffectiveDate, payloadJSON, requestID, resolveInitiatorUUID(initiatorUUID, tenantID))
+	if err != nil {
+		return types.OrgUnitResult{}, err
+	}
+
+	fields := map[string]any{
+		"operation":      string(plan.Emitted),
+		"request_id":     requestID,
+		"operation_uuid": result.OperationUUID,
+		"event_count":    len(result.EventUUIDs),
+	}
+	if len(plan.CreatedOrgCodes) > 0 {
+		fields["created_org_codes"] = plan.CreatedOrgCodes
+	}
+	return types.OrgUnitResult{
+		OrgCode:       plan.OrgCode,
+		EffectiveDate: plan.EffectiveDate,
+		Fields:        fields,
+	}, nil
+}
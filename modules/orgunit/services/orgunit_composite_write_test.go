@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/ports"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/pkg/httperr"
+	orgunitpkg "github.com/jacksonlee411/Bugs-And-Blossoms/pkg/orgunit"
+)
+
+type orgUnitCompositeStoreStub struct {
+	orgUnitWriteStoreStub
+	submitFn func(operationType string, payload json.RawMessage, requestID string) (ports.OrgUnitCompositeResult, error)
+}
+
+func (s orgUnitCompositeStoreStub) SubmitCompositeOperation(_ context.Context, _ string, _ string, operationType string, _ string, payload json.RawMessage, requestID string, _ string) (ports.OrgUnitCompositeResult, error) {
+	return s.submitFn(operationType, payload, requestID)
+}
+
+func compositeCodeKeys() map[string]string {
+	return map[string]string{"ROOT": "AAAAAAAB", "SALES": "AAAAAAAC", "MKT": "AAAAAAAD", "NORTH": "AAAAAAAE", "SOUTH": "AAAAAAAF"}
+}
+
+func newCompositeStore(t *testing.T, submit func(operationType string, payload json.RawMessage, requestID string) (ports.OrgUnitCompositeResult, error)) orgUnitCompositeStoreStub {
+	t.Helper()
+	keys := compositeCodeKeys()
+	return orgUnitCompositeStoreStub{
+		orgUnitWriteStoreStub: orgUnitWriteStoreStub{
+			resolveOrgNodeKeyFn: func(_ context.Context, _ string, orgCode string) (string, error) {
+				if key, ok := keys[orgCode]; ok {
+					return key, nil
+				}
+				return "", orgunitpkg.ErrOrgCodeNotFound
+			},
+		},
+		submitFn: submit,
+	}
+}
+
+func TestOrgUnitMerge(t *testing.T) {
+	var gotPayload map[string]any
+	store := newCompositeStore(t, func(operationType string, payload json.RawMessage, requestID string) (ports.OrgUnitCompositeResult, error) {
+		if operationType != "MERGE" || requestID != "req-merge" {
+			t.Fatalf("operationType=%s requestID=%s", operationType, requestID)
+		}
+		if err := json.Unmarshal(payload, &gotPayload); err != nil {
+			t.Fatal(err)
+		}
+		return ports.OrgUnitCompositeResult{OperationUUID: "op-1", EventUUIDs: []string{"e1", "e2", "e3"}}, nil
+	})
+	svc := NewOrgUnitWriteService(store)
+
+	got, err := svc.Merge(context.Background(), "t1", MergeOrgUnitsRequest{
+		EffectiveDate:   "2026-03-01",
+		SurvivorOrgCode: "sales",
+		SourceOrgCodes:  []string{"mkt", "MKT"},
+		RequestID:       "req-merge",
+	})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if got.OrgCode != "SALES" || got.Fields["operation"] != "MERGE" || got.Fields["event_count"] != 3 || got.Fields["operation_uuid"] != "op-1" {
+		t.Fatalf("result=%+v", got)
+	}
+	sources, _ := gotPayload["source_org_node_keys"].([]any)
+	if gotPayload["survivor_org_node_key"] != "AAAAAAAC" || len(sources) != 1 || sources[0] != "AAAAAAAD" {
+		t.Fatalf("payload=%v", gotPayload)
+	}
+
+	if _, err := svc.Merge(context.Background(), "t1", MergeOrgUnitsRequest{EffectiveDate: "2026-03-01", SurvivorOrgCode: "SALES", SourceOrgCodes: []string{"SALES"}, RequestID: "r"}); err == nil || err.Error() != errOrgMergeSurvivorInvalid {
+		t.Fatalf("expected survivor invalid, got %v", err)
+	}
+	if _, err := svc.Merge(context.Background(), "t1", MergeOrgUnitsRequest{EffectiveDate: "2026-03-01", SurvivorOrgCode: "SALES", SourceOrgCodes: []string{"ROOT"}, RequestID: "r"}); err == nil || err.Error() != "ORG_ROOT_CANNOT_BE_MERGED" {
+		t.Fatalf("expected root deny, got %v", err)
+	}
+	if _, err := svc.Merge(context.Background(), "t1", MergeOrgUnitsRequest{EffectiveDate: "2026-03-01", SurvivorOrgCode: "SALES", SourceOrgCodes: []string{"GONE"}, RequestID: "r"}); err == nil || err.Error() != errOrgCodeNotFound {
+		t.Fatalf("expected code not found, got %v", err)
+	}
+	if _, err := svc.Merge(context.Background(), "t1", MergeOrgUnitsRequest{EffectiveDate: "2026-03-01", SurvivorOrgCode: "SALES", RequestID: "r"}); !httperr.IsBadRequest(err) {
+		t.Fatalf("expected sources bad request, got %v", err)
+	}
+	if _, err := svc.Merge(context.Background(), "t1", MergeOrgUnitsRequest{EffectiveDate: "2026-03-01", SurvivorOrgCode: "SALES", SourceOrgCodes: []string{"MKT"}}); !httperr.IsBadRequest(err) {
+		t.Fatalf("expected request_id bad request, got %v", err)
+	}
+
+	plain := NewOrgUnitWriteService(store.orgUnitWriteStoreStub)
+	if _, err := plain.Merge(context.Background(), "t1", MergeOrgUnitsRequest{EffectiveDate: "2026-03-01", SurvivorOrgCode: "SALES", SourceOrgCodes: []string{"MKT"}, RequestID: "r"}); err == nil || err.Error() != errOrgCompositeUnsupported {
+		t.Fatalf("expected unsupported, got %v", err)
+	}
+}
+
+func TestOrgUnitSplit(t *testing.T) {
+	var gotPayload struct {
+		Source        string `json:"source_org_node_key"`
+		DisableSource bool   `json:"disable_source"`
+		NewUnits      []struct {
+			OrgCode        string   `json:"org_code"`
+			Name           string   `json:"name"`
+			IsBusinessUnit *bool    `json:"is_business_unit"`
+			Children       []string `json:"child_org_node_keys"`
+		} `json:"new_units"`
+	}
+	store := newCompositeStore(t, func(operationType string, payload json.RawMessage, _ string) (ports.OrgUnitCompositeResult, error) {
+		if operationType != "SPLIT" {
+			t.Fatalf("operationType=%s", operationType)
+		}
+		if err := json.Unmarshal(payload, &gotPayload); err != nil {
+			t.Fatal(err)
+		}
+		return ports.OrgUnitCompositeResult{OperationUUID: "op-2", EventUUIDs: []string{"e1", "e2", "e3", "e4"}}, nil
+	})
+	svc := NewOrgUnitWriteService(store)
+
+	bu := true
+	got, err := svc.Split(context.Background(), "t1", SplitOrgUnitRequest{
+		EffectiveDate: "2026-03-01",
+		SourceOrgCode: "SALES",
+		NewUnits: []SplitOrgUnitNewUnit{
+			{OrgCode: "SALES-N", Name: " Sales North ", IsBusinessUnit: &bu, ChildOrgCodes: []string{"NORTH"}},
+			{OrgCode: "SALES-S", Name: "Sales South", ChildOrgCodes: []string{"SOUTH"}},
+		},
+		DisableSource: true,
+		RequestID:     "req-split",
+	})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	created, _ := got.Fields["created_org_codes"].([]string)
+	if got.OrgCode != "SALES" || len(created) != 2 || created[0] != "SALES-N" {
+		t.Fatalf("result=%+v", got)
+	}
+	if gotPayload.Source != "AAAAAAAC" || !gotPayload.DisableSource || len(gotPayload.NewUnits) != 2 {
+		t.Fatalf("payload=%+v", gotPayload)
+	}
+	first := gotPayload.NewUnits[0]
+	if first.Name != "Sales North" || first.IsBusinessUnit == nil || !*first.IsBusinessUnit || len(first.Children) != 1 || first.Children[0] != "AAAAAAAE" {
+		t.Fatalf("first unit=%+v", first)
+	}
+	if gotPayload.NewUnits[1].IsBusinessUnit != nil {
+		t.Fatalf("unset is_business_unit must be omitted: %+v", gotPayload.NewUnits[1])
+	}
+
+	cases := []struct {
+		name string
+		req  SplitOrgUnitRequest
+		want string
+	}{
+		{name: "existing code", req: SplitOrgUnitRequest{SourceOrgCode: "SALES", NewUnits: []SplitOrgUnitNewUnit{{OrgCode: "MKT", Name: "M"}}}, want: errOrgAlreadyExists},
+		{name: "child reused", req: SplitOrgUnitRequest{SourceOrgCode: "SALES", NewUnits: []SplitOrgUnitNewUnit{{OrgCode: "A1", Name: "A", ChildOrgCodes: []string{"NORTH"}}, {OrgCode: "A2", Name: "B", ChildOrgCodes: []string{"NORTH"}}}}, want: errOrgSplitChildNotUnderSource},
+		{name: "root", req: SplitOrgUnitRequest{SourceOrgCode: "ROOT", NewUnits: []SplitOrgUnitNewUnit{{OrgCode: "A1", Name: "A"}}}, want: "ORG_ROOT_CANNOT_BE_SPLIT"},
+	}
+	for _, tc := range cases {
+		tc.req.EffectiveDate = "2026-03-01"
+		tc.req.RequestID = "r"
+		if _, err := svc.Split(context.Background(), "t1", tc.req); err == nil || err.Error() != tc.want {
+			t.Fatalf("%s: err=%v want=%s", tc.name, err, tc.want)
+		}
+	}
+
+	for _, req := range []SplitOrgUnitRequest{
+		{EffectiveDate: "2026-03-01", SourceOrgCode: "SALES", RequestID: "r"},
+		{EffectiveDate: "2026-03-01", SourceOrgCode: "SALES", RequestID: "r", NewUnits: []SplitOrgUnitNewUnit{{OrgCode: "A1"}}},
+		{EffectiveDate: "2026-03-01", SourceOrgCode: "SALES", RequestID: "r", NewUnits: []SplitOrgUnitNewUnit{{OrgCode: "A1", Name: "A"}, {OrgCode: "a1", Name: "B"}}},
+		{EffectiveDate: "bad", SourceOrgCode: "SALES", RequestID: "r", NewUnits: []SplitOrgUnitNewUnit{{OrgCode: "A1", Name: "A"}}},
+	} {
+		if _, err := svc.Split(context.Background(), "t1", req); !httperr.IsBadRequest(err) {
+			t.Fatalf("req=%+v expected bad request, got %v", req, err)
+		}
+	}
+
+	failing := newCompositeStore(t, func(string, json.RawMessage, string) (ports.OrgUnitCompositeResult, error) {
+		return ports.OrgUnitCompositeResult{}, errors.New("ORG_SPLIT_CHILD_NOT_UNDER_SOURCE")
+	})
+	if _, err := NewOrgUnitWriteService(failing).Split(context.Background(), "t1", SplitOrgUnitRequest{EffectiveDate: "2026-03-01", SourceOrgCode: "SALES", RequestID: "r", NewUnits: []SplitOrgUnitNewUnit{{OrgCode: "A1", Name: "A", ChildOrgCodes: []string{"MKT"}}}}); err == nil || err.Error() != errOrgSplitChildNotUnderSource {
+		t.Fatalf("kernel errors must pass through, got %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	celtypes "github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+	dictpkg "github.com/jacksonlee411/Bugs-And-Blossoms/pkg/dict"
+)
+
+const (
+	orgUnitNextSeqFuncName    = "next_seq"
+	orgUnitDictLabelFuncName  = "dict_label"
+	orgUnitInheritFuncName    = "inherit"
+	orgUnitFormatDateFuncName = "format_date"
+
+	// orgUnitDefaultRuleCostLimit bounds one default_rule_expr evaluation; a rule that iterates past it fails
+	// with DEFAULT_RULE_EVAL_FAILED instead of stalling the write.
+	orgUnitDefaultRuleCostLimit = 10000
+	orgUnitDefaultRuleMaxWidth  = 12
+
+	// orgUnitDefaultRuleSeqMarker stands in for the sequence next_org_code/next_seq allocate. The marker
+	// survives string concatenation, so the evaluated value tells us the prefix the allocator must use.
+	orgUnitDefaultRuleSeqMarker = "\x00next_seq:"
+)
+
+// orgUnitValidationRecordReader is the slice of OrgUnitFieldValidationStore default rules need to expose the
+// parent unit; readers without it only see the parent's org_code.
+type orgUnitValidationRecordReader interface {
+	GetOrgUnitValidationRecordAsOf(ctx context.Context, tenantID string, orgNodeKey string, asOf string) (types.OrgUnitValidationRecord, bool, error)
+}
+
+// NewOrgUnitDefaultRuleCELEnv declares the sandbox default_rule_expr runs in. Save-time validation and
+// write-time evaluation share it so a rule that compiles is a rule the write path can run.
+func NewOrgUnitDefaultRuleCELEnv() (*cel.Env, error) {
+	opts := []cel.EnvOption{
+		ext.Strings(),
+		cel.Variable("record", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("parent", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("effective_date", cel.StringType),
+		cel.Variable("as_of", cel.TimestampType),
+		cel.Variable("initiator", cel.StringType),
+	}
+	return cel.NewEnv(append(opts, orgUnitDefaultRuleFunctions(orgUnitRuleRuntimeContext{})...)...)
+}
+
+// orgUnitDefaultRuleFunctions binds the helper functions to one evaluation's runtime. The declarations are
+// identical for every runtime, so Extend only swaps the implementations.
+func orgUnitDefaultRuleFunctions(runtime orgUnitRuleRuntimeContext) []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function(
+			orgUnitNextOrgCodeFuncName,
+			cel.Overload(
+				"next_org_code_string_int",
+				[]*cel.Type{cel.StringType, cel.IntType},
+				cel.StringType,
+				cel.BinaryBinding(func(prefix ref.Val, width ref.Val) ref.Val {
+					return celtypes.String(string(prefix.(celtypes.String)) + orgUnitDefaultRuleSeqPlaceholder(int64(width.(celtypes.Int))))
+				}),
+			),
+		),
+		cel.Function(
+			orgUnitNextSeqFuncName,
+			cel.Overload(
+				"next_seq_int",
+				[]*cel.Type{cel.IntType},
+				cel.StringType,
+				cel.UnaryBinding(func(width ref.Val) ref.Val {
+					return celtypes.String(orgUnitDefaultRuleSeqPlaceholder(int64(width.(celtypes.Int))))
+				}),
+			),
+		),
+		cel.Function(
+			orgUnitDictLabelFuncName,
+			cel.Overload(
+				"dict_label_string_string",
+				[]*cel.Type{cel.StringType, cel.StringType},
+				cel.StringType,
+				cel.BinaryBinding(func(dictCode ref.Val, code ref.Val) ref.Val {
+					if runtime.resolveDictLabel == nil {
+						return celtypes.String("")
+					}
+					label, ok, err := runtime.resolveDictLabel(string(dictCode.(celtypes.String)), string(code.(celtypes.String)))
+					if err != nil {
+						return celtypes.NewErr("dict_label: %v", err)
+					}
+					if !ok {
+						return celtypes.String("")
+					}
+					return celtypes.String(label)
+				}),
+			),
+		),
+		cel.Function(
+			orgUnitInheritFuncName,
+			cel.Overload(
+				"inherit_string",
+				[]*cel.Type{cel.StringType},
+				cel.StringType,
+				cel.UnaryBinding(func(fieldKey ref.Val) ref.Val {
+					return celtypes.String(orgUnitDefaultRuleString(runtime.parent[strings.TrimSpace(string(fieldKey.(celtypes.String)))]))
+				}),
+			),
+		),
+		cel.Function(
+			orgUnitFormatDateFuncName,
+			cel.Overload(
+				"format_date_timestamp_string",
+				[]*cel.Type{cel.TimestampType, cel.StringType},
+				cel.StringType,
+				cel.BinaryBinding(func(ts ref.Val, layout ref.Val) ref.Val {
+					return celtypes.String(formatOrgUnitDefaultRuleDate(ts.(celtypes.Timestamp).Time, string(layout.(celtypes.String))))
+				}),
+			),
+		),
+	}
+}
+
+// resolveCreateOrgUnitDefaultRuleRuntime gathers what a create default rule may read: the candidate record
+// as submitted, the parent unit as of the effective date, and who is creating it.
+func resolveCreateOrgUnitDefaultRuleRuntime(ctx context.Context, reader any, input CreateOrgUnitPrecheckInputV1, parentNodeKey string) (orgUnitRuleRuntimeContext, error) {
+	record := buildCreateOrgUnitFieldValidationPatch(input)
+	record["org_code"] = input.OrgCode
+	parent := map[string]any{"org_code": input.BusinessUnitOrgCode}
+	if parentNodeKey = strings.TrimSpace(parentNodeKey); parentNodeKey != "" && strings.TrimSpace(input.EffectiveDate) != "" {
+		if recordReader, ok := reader.(orgUnitValidationRecordReader); ok {
+			parentRecord, found, err := recordReader.GetOrgUnitValidationRecordAsOf(ctx, input.TenantID, parentNodeKey, input.EffectiveDate)
+			if err != nil {
+				return orgUnitRuleRuntimeContext{}, err
+			}
+			if found {
+				parent = parentRecord.Fields
+			}
+		}
+	}
+	tenantID := input.TenantID
+	effectiveDate := input.EffectiveDate
+	return orgUnitRuleRuntimeContext{
+		tenantID:      tenantID,
+		effectiveDate: effectiveDate,
+		initiatorUUID: resolveInitiatorUUID(input.InitiatorUUID, tenantID),
+		record:        newOrgUnitFieldValidationRecord(record),
+		parent:        newOrgUnitFieldValidationRecord(parent),
+		resolveDictLabel: func(dictCode string, code string) (string, bool, error) {
+			return dictpkg.ResolveValueLabel(ctx, tenantID, effectiveDate, strings.TrimSpace(dictCode), strings.TrimSpace(code))
+		},
+	}, nil
+}
+
+// orgUnitRuleRuntimeLoader defers reading the parent until a decision actually has a rule to evaluate; the
+// built-in next_org_code("O", 6) default is resolved without a runtime.
+type orgUnitRuleRuntimeLoader struct {
+	load    func() (orgUnitRuleRuntimeContext, error)
+	runtime *orgUnitRuleRuntimeContext
+}
+
+func (l *orgUnitRuleRuntimeLoader) forDecision(fieldKey string, decision orgUnitFieldDecision) (orgUnitRuleRuntimeContext, error) {
+	expr := strings.TrimSpace(decision.DefaultRuleRef)
+	if expr == "" || (fieldKey == orgUnitCreateFieldOrgCode && nextOrgCodeRuleRe.MatchString(expr)) {
+		return orgUnitRuleRuntimeContext{}, nil
+	}
+	if l.runtime == nil {
+		runtime, err := l.load()
+		if err != nil {
+			return orgUnitRuleRuntimeContext{}, err
+		}
+		l.runtime = &runtime
+	}
+	return *l.runtime, nil
+}
+
+func (r orgUnitRuleRuntimeContext) activation() map[string]any {
+	asOf, err := time.Parse(time.DateOnly, strings.TrimSpace(r.effectiveDate))
+	if err != nil {
+		asOf = time.Time{}
+	}
+	record := r.record
+	if record == nil {
+		record = newOrgUnitFieldValidationRecord(nil)
+	}
+	parent := r.parent
+	if parent == nil {
+		parent = newOrgUnitFieldValidationRecord(nil)
+	}
+	return map[string]any{
+		"record":         record,
+		"parent":         parent,
+		"effective_date": strings.TrimSpace(r.effectiveDate),
+		"as_of":          asOf,
+		"initiator":      strings.TrimSpace(r.initiatorUUID),
+	}
+}
+
+func orgUnitDefaultRuleSeqPlaceholder(width int64) string {
+	return orgUnitDefaultRuleSeqMarker + strconv.FormatInt(width, 10) + "\x00"
+}
+
+// splitOrgUnitDefaultRuleSequence finds a sequence placeholder in an evaluated default. Only a single
+// placeholder at the very end can be allocated, because the allocator generates prefix + zero-padded number.
+func splitOrgUnitDefaultRuleSequence(value string) (*orgUnitAutoCodeSpec, bool, error) {
+	idx := strings.Index(value, orgUnitDefaultRuleSeqMarker)
+	if idx < 0 {
+		return nil, false, nil
+	}
+	rest := value[idx+len(orgUnitDefaultRuleSeqMarker):]
+	end := strings.IndexByte(rest, '\x00')
+	if end < 0 || end != len(rest)-1 {
+		return nil, true, errors.New("sequence must end the default value")
+	}
+	width, err := strconv.Atoi(rest[:end])
+	if err != nil || width <= 0 || width > orgUnitDefaultRuleMaxWidth {
+		return nil, true, fmt.Errorf("sequence width must be between 1 and %d", orgUnitDefaultRuleMaxWidth)
+	}
+	return &orgUnitAutoCodeSpec{Prefix: value[:idx], Width: width}, true, nil
+}
+
+func orgUnitDefaultRuleString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	default:
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+}
+
+// formatOrgUnitDefaultRuleDate renders t with yyyy/yy/MM/dd tokens; every other character is copied as is,
+// so a literal such as "Q1" never turns into a Go layout verb.
+func formatOrgUnitDefaultRuleDate(t time.Time, layout string) string {
+	t = t.UTC()
+	var b strings.Builder
+	for i := 0; i < len(layout); {
+		switch {
+		case strings.HasPrefix(layout[i:], "yyyy"):
+			fmt.Fprintf(&b, "%04d", t.Year())
+			i += 4
+		case strings.HasPrefix(layout[i:], "yy"):
+			fmt.Fprintf(&b, "%02d", t.Year()%100)
+			i += 2
+		case strings.HasPrefix(layout[i:], "MM"):
+			fmt.Fprintf(&b, "%02d", int(t.Month()))
+			i += 2
+		case strings.HasPrefix(layout[i:], "dd"):
+			fmt.Fprintf(&b, "%02d", t.Day())
+			i += 2
+		default:
+			b.WriteByte(layout[i])
+			i++
+		}
+	}
+	return b.String()
+}
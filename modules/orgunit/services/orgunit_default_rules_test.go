@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+)
+
+func testOrgUnitDefaultRuleRuntime() orgUnitRuleRuntimeContext {
+	return orgUnitRuleRuntimeContext{
+		tenantID:      "t1",
+		effectiveDate: "2026-03-15",
+		initiatorUUID: "u-1",
+		record:        newOrgUnitFieldValidationRecord(map[string]any{"name": "Site A", "ext": map[string]any{"org_type": "20"}}),
+		parent:        newOrgUnitFieldValidationRecord(map[string]any{"org_code": "ROOT", "cost_center": "CC100"}),
+		resolveDictLabel: func(dictCode string, code string) (string, bool, error) {
+			if dictCode == "org_type" && code == "20" {
+				return "Company", true, nil
+			}
+			return "", false, nil
+		},
+	}
+}
+
+func TestResolveCreateDefaultFromRule_RuntimeHelpers(t *testing.T) {
+	runtime := testOrgUnitDefaultRuleRuntime()
+	cases := []struct {
+		expr string
+		want string
+	}{
+		{expr: `inherit("cost_center")`, want: "CC100"},
+		{expr: `has(parent.cost_center) ? string(parent.cost_center) : ""`, want: "CC100"},
+		{expr: `inherit("missing")`, want: ""},
+		{expr: `"P" + format_date(as_of, "yyyyMM")`, want: "P202603"},
+		{expr: `format_date(as_of, "yy-Q1-dd")`, want: "26-Q1-15"},
+		{expr: `effective_date.replace("-", "")`, want: "20260315"},
+		{expr: `dict_label("org_type", record.org_type)`, want: "Company"},
+		{expr: `dict_label("org_type", "99")`, want: ""},
+		{expr: `initiator`, want: "u-1"},
+		{expr: `record.name.upperAscii()`, want: "SITE A"},
+	}
+	for _, tc := range cases {
+		got, spec, err := resolveCreateDefaultFromRule(orgUnitCreateFieldOrgType, tc.expr, runtime)
+		if err != nil || spec != nil || got != tc.want {
+			t.Fatalf("expr=%s got=%q spec=%+v err=%v", tc.expr, got, spec, err)
+		}
+	}
+}
+
+func TestResolveCreateDefaultFromRule_DerivedSequence(t *testing.T) {
+	runtime := testOrgUnitDefaultRuleRuntime()
+
+	_, spec, err := resolveCreateDefaultFromRule(orgUnitCreateFieldOrgCode, `parent.org_code + "-" + next_seq(4)`, runtime)
+	if err != nil || spec == nil || spec.Prefix != "ROOT-" || spec.Width != 4 {
+		t.Fatalf("spec=%+v err=%v", spec, err)
+	}
+	_, spec, err = resolveCreateDefaultFromRule(orgUnitCreateFieldOrgCode, `"site-" + next_seq(3)`, runtime)
+	if err != nil || spec == nil || spec.Prefix != "SITE-" {
+		t.Fatalf("spec=%+v err=%v", spec, err)
+	}
+	_, spec, err = resolveCreateDefaultFromRule(orgUnitCreateFieldOrgCode, `parent.org_code == "" ? next_org_code("O", 6) : next_org_code(parent.org_code, 3)`, runtime)
+	if err != nil || spec == nil || spec.Prefix != "ROOT" || spec.Width != 3 {
+		t.Fatalf("spec=%+v err=%v", spec, err)
+	}
+
+	for _, tc := range []struct {
+		fieldKey string
+		expr     string
+	}{
+		{fieldKey: orgUnitCreateFieldOrgType, expr: `next_seq(4)`},
+		{fieldKey: orgUnitCreateFieldOrgCode, expr: `next_seq(4) + "X"`},
+		{fieldKey: orgUnitCreateFieldOrgCode, expr: `next_seq(2) + next_seq(2)`},
+		{fieldKey: orgUnitCreateFieldOrgCode, expr: `"A" + next_seq(0)`},
+		{fieldKey: orgUnitCreateFieldOrgCode, expr: `"A" + next_seq(13)`},
+		{fieldKey: orgUnitCreateFieldOrgCode, expr: `"编码" + next_seq(60)`},
+		{fieldKey: orgUnitCreateFieldOrgCode, expr: `"编码-" + next_seq(4)`},
+	} {
+		if _, _, err := resolveCreateDefaultFromRule(tc.fieldKey, tc.expr, runtime); err == nil || err.Error() != errDefaultRuleEvalFailed {
+			t.Fatalf("field=%s expr=%s err=%v", tc.fieldKey, tc.expr, err)
+		}
+	}
+}
+
+func TestResolveCreateDefaultFromRule_CostLimit(t *testing.T) {
+	expr := `size([0,1,2,3,4,5,6,7,8,9].map(a, [0,1,2,3,4,5,6,7,8,9].map(b, [0,1,2,3,4,5,6,7,8,9].map(c, [0,1,2,3,4,5,6,7,8,9].map(d, a + b + c + d))))) > 0 ? "x" : "y"`
+	if _, _, err := resolveCreateDefaultFromRule(orgUnitCreateFieldOrgType, expr, orgUnitRuleRuntimeContext{}); err == nil || err.Error() != errDefaultRuleEvalFailed {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestResolveCreateDefaultFromRule_DictLabelError(t *testing.T) {
+	runtime := testOrgUnitDefaultRuleRuntime()
+	runtime.resolveDictLabel = func(string, string) (string, bool, error) {
+		return "", false, errors.New("boom")
+	}
+	if _, _, err := resolveCreateDefaultFromRule(orgUnitCreateFieldOrgType, `dict_label("org_type", "20")`, runtime); err == nil || err.Error() != errDefaultRuleEvalFailed {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+type orgUnitDefaultRuleParentReaderStub struct {
+	calls  int
+	record types.OrgUnitValidationRecord
+	err    error
+}
+
+func (s *orgUnitDefaultRuleParentReaderStub) GetOrgUnitValidationRecordAsOf(_ context.Context, _ string, orgNodeKey string, asOf string) (types.OrgUnitValidationRecord, bool, error) {
+	s.calls++
+	if s.err != nil {
+		return types.OrgUnitValidationRecord{}, false, s.err
+	}
+	if orgNodeKey != s.record.OrgNodeKey || asOf != "2026-03-15" {
+		return types.OrgUnitValidationRecord{}, false, nil
+	}
+	return s.record, true, nil
+}
+
+func TestResolveCreateOrgUnitDefaultRuleRuntime(t *testing.T) {
+	input := CreateOrgUnitPrecheckInputV1{
+		TenantID:            "t1",
+		EffectiveDate:       "2026-03-15",
+		BusinessUnitOrgCode: "ROOT",
+		Name:                "Site A",
+		Ext:                 map[string]any{"org_type": "20"},
+	}
+
+	t.Run("parent from reader", func(t *testing.T) {
+		reader := &orgUnitDefaultRuleParentReaderStub{record: types.OrgUnitValidationRecord{
+			OrgNodeKey: "10000001",
+			Fields:     map[string]any{"org_code": "ROOT", "cost_center": "CC100"},
+		}}
+		runtime, err := resolveCreateOrgUnitDefaultRuleRuntime(context.Background(), reader, input, "10000001")
+		if err != nil {
+			t.Fatalf("err=%v", err)
+		}
+		if runtime.parent["cost_center"] != "CC100" || runtime.record["org_type"] != "20" || runtime.initiatorUUID != "t1" {
+			t.Fatalf("runtime=%+v", runtime)
+		}
+	})
+
+	t.Run("reader without records falls back to parent code", func(t *testing.T) {
+		runtime, err := resolveCreateOrgUnitDefaultRuleRuntime(context.Background(), struct{}{}, input, "10000001")
+		if err != nil || runtime.parent["org_code"] != "ROOT" || runtime.parent["name"] != "" {
+			t.Fatalf("runtime=%+v err=%v", runtime, err)
+		}
+	})
+
+	t.Run("reader error", func(t *testing.T) {
+		reader := &orgUnitDefaultRuleParentReaderStub{err: errors.New("boom")}
+		if _, err := resolveCreateOrgUnitDefaultRuleRuntime(context.Background(), reader, input, "10000001"); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("loader skips built-in next_org_code", func(t *testing.T) {
+		reader := &orgUnitDefaultRuleParentReaderStub{record: types.OrgUnitValidationRecord{OrgNodeKey: "10000001"}}
+		loader := orgUnitRuleRuntimeLoader{load: func() (orgUnitRuleRuntimeContext, error) {
+			return resolveCreateOrgUnitDefaultRuleRuntime(context.Background(), reader, input, "10000001")
+		}}
+		if _, err := loader.forDecision(orgUnitCreateFieldOrgCode, orgUnitFieldDecision{DefaultRuleRef: `next_org_code("O", 6)`}); err != nil || reader.calls != 0 {
+			t.Fatalf("calls=%d err=%v", reader.calls, err)
+		}
+		for range 2 {
+			if _, err := loader.forDecision(orgUnitCreateFieldOrgType, orgUnitFieldDecision{DefaultRuleRef: `inherit("org_type")`}); err != nil {
+				t.Fatalf("err=%v", err)
+			}
+		}
+		if reader.calls != 1 {
+			t.Fatalf("calls=%d", reader.calls)
+		}
+	})
+}
+
+func TestFormatOrgUnitDefaultRuleDate(t *testing.T) {
+	ts := time.Date(2026, time.January, 2, 23, 0, 0, 0, time.UTC)
+	if got := formatOrgUnitDefaultRuleDate(ts, "yyyy/MM/dd 1"); got != "2026/01/02 1" {
+		t.Fatalf("got=%q", got)
+	}
+	if got := strings.TrimSpace(formatOrgUnitDefaultRuleDate(ts, "")); got != "" {
+		t.Fatalf("got=%q", got)
+	}
+}
+
+func TestEvaluateCELExprToString_ReusesCheckedAST(t *testing.T) {
+	orgUnitDefaultRuleASTs = newCELProgramCache[orgUnitDefaultRuleCompiled](celProgramCacheMaxEntries)
+	expr := `inherit("cost_center") + "-" + dict_label("org_type", record.org_type)`
+
+	first := testOrgUnitDefaultRuleRuntime()
+	second := testOrgUnitDefaultRuleRuntime()
+	second.parent = newOrgUnitFieldValidationRecord(map[string]any{"cost_center": "CC200"})
+	second.resolveDictLabel = func(string, string) (string, bool, error) { return "Branch", true, nil }
+
+	if got, err := evaluateCELExprToString(expr, first); err != nil || got != "CC100-Company" {
+		t.Fatalf("got=%q err=%v", got, err)
+	}
+	// The cached AST is bound to each evaluation's own runtime.
+	if got, err := evaluateCELExprToString(expr, second); err != nil || got != "CC200-Branch" {
+		t.Fatalf("got=%q err=%v", got, err)
+	}
+	if err := compileCELExpr(expr); err != nil || orgUnitDefaultRuleASTs.Len() != 1 {
+		t.Fatalf("entries=%d err=%v", orgUnitDefaultRuleASTs.Len(), err)
+	}
+
+	if _, err := evaluateCELExprToString(`1 + 1`, first); err == nil || orgUnitDefaultRuleASTs.Len() != 2 {
+		t.Fatalf("a non-string rule must fail and be cached, entries=%d err=%v", orgUnitDefaultRuleASTs.Len(), err)
+	}
+	if _, err := evaluateCELExprToString(`1 + 1`, first); err == nil {
+		t.Fatal("cached failure must still fail")
+	}
+}
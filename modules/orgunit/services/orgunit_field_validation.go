@@ -0,0 +1,370 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/ports"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+	orgunitpkg "github.com/jacksonlee411/Bugs-And-Blossoms/pkg/orgunit"
+)
+
+const (
+	errFieldValidationFailed         = "FIELD_VALIDATION_FAILED"
+	errFieldValidationRuleViolated   = "FIELD_VALIDATION_RULE_VIOLATED"
+	errFieldValidationRuleEvalFailed = "FIELD_VALIDATION_RULE_EVAL_FAILED"
+
+	// orgUnitFieldValidationCostLimit bounds one rule evaluation the way orgUnitDefaultRuleCostLimit bounds
+	// default rules: a rule that iterates past it fails with FIELD_VALIDATION_RULE_EVAL_FAILED.
+	orgUnitFieldValidationCostLimit = 10000
+)
+
+// orgUnitFieldValidationFormKeys maps write intents onto the dialog form keys field configs already use,
+// so a rule with a form_key only runs for the write that dialog submits.
+var orgUnitFieldValidationFormKeys = map[string]string{
+	string(OrgUnitWriteIntentCreateOrg):     "orgunit.create_dialog",
+	string(OrgUnitWriteIntentAddVersion):    "orgunit.details.add_version_dialog",
+	string(OrgUnitWriteIntentInsertVersion): "orgunit.details.insert_version_dialog",
+	string(OrgUnitWriteIntentCorrect):       "orgunit.details.correct_dialog",
+}
+
+// orgUnitFieldValidationCoreFields are always present in record/parent so rules can compare them without
+// guarding with has().
+var orgUnitFieldValidationCoreFields = map[string]any{
+	"org_code":         "",
+	"name":             "",
+	"parent_org_code":  "",
+	"status":           "",
+	"is_business_unit": false,
+	"manager_pernr":    "",
+}
+
+// orgUnitFieldValidationPrograms caches the compile result per trimmed expression, failures included, so
+// a stored rule is compiled once per process rather than on every write that evaluates it. It is bounded
+// because the rule-test endpoint feeds it arbitrary expressions.
+var orgUnitFieldValidationPrograms = newCELProgramCache[orgUnitFieldValidationCompiled](celProgramCacheMaxEntries)
+
+type orgUnitFieldValidationCompiled struct {
+	program cel.Program
+	err     error
+}
+
+// OrgUnitFieldValidationInputV1 is the activation a validation rule runs against: the candidate record
+// after the write, its parent as of the same day, and the as-of date itself.
+type OrgUnitFieldValidationInputV1 struct {
+	Intent string         `json:"intent"`
+	AsOf   string         `json:"as_of"`
+	Record map[string]any `json:"record"`
+	Parent map[string]any `json:"parent"`
+}
+
+type OrgUnitFieldValidationErrorV1 struct {
+	FieldKey string `json:"field_key"`
+	RuleKey  string `json:"rule_key"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+// OrgUnitFieldValidationError rejects a write whose candidate record fails one or more tenant validation
+// rules; FieldErrors lists every failing rule, not just the first.
+type OrgUnitFieldValidationError struct {
+	FieldErrors []OrgUnitFieldValidationErrorV1
+}
+
+func (e *OrgUnitFieldValidationError) Error() string {
+	return errFieldValidationFailed
+}
+
+var newOrgUnitFieldValidationCELEnv = func() (*cel.Env, error) {
+	return cel.NewEnv(
+		ext.Strings(),
+		cel.Variable("record", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("parent", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("as_of", cel.TimestampType),
+		cel.Variable("intent", cel.StringType),
+	)
+}
+
+// IsOrgUnitFieldValidationFormKey reports whether formKey names a dialog a validation rule can be tied to.
+func IsOrgUnitFieldValidationFormKey(formKey string) bool {
+	for _, key := range orgUnitFieldValidationFormKeys {
+		if key == formKey {
+			return true
+		}
+	}
+	return false
+}
+
+// CompileOrgUnitFieldValidationExpr reports whether expr is a valid validation rule: it must type-check
+// against the validation activation and yield a bool.
+func CompileOrgUnitFieldValidationExpr(expr string) error {
+	_, err := orgUnitFieldValidationProgram(expr)
+	return err
+}
+
+func orgUnitFieldValidationProgram(expr string) (cel.Program, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, errors.New("validation expression is required")
+	}
+	if compiled, ok := orgUnitFieldValidationPrograms.Load(expr); ok {
+		return compiled.program, compiled.err
+	}
+	env, err := newOrgUnitFieldValidationCELEnv()
+	if err != nil {
+		// An environment failure says nothing about expr, so it is not cached.
+		return nil, err
+	}
+	program, err := compileOrgUnitFieldValidationProgram(env, expr)
+	orgUnitFieldValidationPrograms.Store(expr, orgUnitFieldValidationCompiled{program: program, err: err})
+	return program, err
+}
+
+func compileOrgUnitFieldValidationProgram(env *cel.Env, expr string) (cel.Program, error) {
+	ast, iss := env.Compile(expr)
+	if iss != nil && iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if out := ast.OutputType(); out != cel.BoolType && out != cel.DynType {
+		return nil, errors.New("validation expression must return bool")
+	}
+	return env.Program(ast, cel.CostLimit(orgUnitFieldValidationCostLimit))
+}
+
+// EvaluateOrgUnitFieldValidationRulesV1 runs every rule against input and returns one entry per failing
+// rule, ordered by field_key then rule_key. A rule that errors at runtime counts as failing.
+func EvaluateOrgUnitFieldValidationRulesV1(rules []types.TenantFieldValidationRule, input OrgUnitFieldValidationInputV1) ([]OrgUnitFieldValidationErrorV1, error) {
+	asOf, err := time.Parse(time.DateOnly, strings.TrimSpace(input.AsOf))
+	if err != nil {
+		return nil, errors.New(errEffectiveDateInvalid)
+	}
+	activation := map[string]any{
+		"record": newOrgUnitFieldValidationRecord(input.Record),
+		"parent": newOrgUnitFieldValidationRecord(input.Parent),
+		"as_of":  asOf,
+		"intent": strings.TrimSpace(input.Intent),
+	}
+	if input.Parent == nil {
+		activation["parent"] = map[string]any{}
+	}
+
+	out := make([]OrgUnitFieldValidationErrorV1, 0)
+	for _, rule := range rules {
+		passed, evalErr := evaluateOrgUnitFieldValidationRule(rule.Expr, activation)
+		item := OrgUnitFieldValidationErrorV1{
+			FieldKey: strings.TrimSpace(rule.FieldKey),
+			RuleKey:  strings.TrimSpace(rule.RuleKey),
+		}
+		switch {
+		case evalErr != nil:
+			item.Code = errFieldValidationRuleEvalFailed
+			item.Message = evalErr.Error()
+		case !passed:
+			item.Code = errFieldValidationRuleViolated
+			item.Message = strings.TrimSpace(rule.Message)
+			if item.Message == "" {
+				item.Message = strings.TrimSpace(rule.Expr)
+			}
+		default:
+			continue
+		}
+		out = append(out, item)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].FieldKey != out[j].FieldKey {
+			return out[i].FieldKey < out[j].FieldKey
+		}
+		return out[i].RuleKey < out[j].RuleKey
+	})
+	return out, nil
+}
+
+func evaluateOrgUnitFieldValidationRule(expr string, activation map[string]any) (bool, error) {
+	program, err := orgUnitFieldValidationProgram(expr)
+	if err != nil {
+		return false, err
+	}
+	val, _, err := program.Eval(activation)
+	if err != nil {
+		return false, err
+	}
+	passed, ok := val.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("validation expression returned %s, want bool", val.Type().TypeName())
+	}
+	return passed, nil
+}
+
+// selectOrgUnitFieldValidationRules keeps the rules without a form_key plus the rules of intent's dialog.
+// When both define the same field_key/rule_key the dialog's rule wins.
+func selectOrgUnitFieldValidationRules(rules []types.TenantFieldValidationRule, intent string) []types.TenantFieldValidationRule {
+	formKey := orgUnitFieldValidationFormKeys[strings.TrimSpace(intent)]
+	selected := map[string]types.TenantFieldValidationRule{}
+	fromForm := map[string]bool{}
+	for _, rule := range rules {
+		key := strings.TrimSpace(rule.FieldKey) + "\x00" + strings.TrimSpace(rule.RuleKey)
+		switch ruleFormKey := strings.TrimSpace(rule.FormKey); {
+		case ruleFormKey == "":
+			if !fromForm[key] {
+				selected[key] = rule
+			}
+		case formKey != "" && ruleFormKey == formKey:
+			selected[key] = rule
+			fromForm[key] = true
+		}
+	}
+	keys := make([]string, 0, len(selected))
+	for key := range selected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	out := make([]types.TenantFieldValidationRule, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, selected[key])
+	}
+	return out
+}
+
+func newOrgUnitFieldValidationRecord(fields map[string]any) map[string]any {
+	record := make(map[string]any, len(orgUnitFieldValidationCoreFields)+len(fields))
+	for key, value := range orgUnitFieldValidationCoreFields {
+		record[key] = value
+	}
+	mergeOrgUnitFieldValidationFields(record, fields)
+	return record
+}
+
+// mergeOrgUnitFieldValidationFields overlays src onto dst, flattening an "ext" object so ext fields are
+// addressed as record.<field_key> just like core fields.
+func mergeOrgUnitFieldValidationFields(dst map[string]any, src map[string]any) {
+	for key, value := range src {
+		if key == "ext" {
+			if ext, ok := value.(map[string]any); ok {
+				mergeOrgUnitFieldValidationFields(dst, ext)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
+
+type orgUnitFieldValidationTarget struct {
+	Intent        string
+	AsOf          string
+	RecordAsOf    string
+	OrgCode       string
+	OrgNodeKey    string
+	ParentNodeKey string
+	Patch         map[string]any
+}
+
+// collectOrgUnitFieldValidationErrors builds the candidate record (the stored version the write changes,
+// overlaid with the patch) and its parent, then runs the rules that apply to the write's intent.
+func collectOrgUnitFieldValidationErrors(ctx context.Context, store ports.OrgUnitFieldValidationStore, tenantID string, target orgUnitFieldValidationTarget) ([]OrgUnitFieldValidationErrorV1, error) {
+	rules, err := store.ListEnabledFieldValidationRulesAsOf(ctx, tenantID, target.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	rules = selectOrgUnitFieldValidationRules(rules, target.Intent)
+	if len(rules) == 0 {
+		return []OrgUnitFieldValidationErrorV1{}, nil
+	}
+
+	record := map[string]any{}
+	parentNodeKey := strings.TrimSpace(target.ParentNodeKey)
+	if orgNodeKey := strings.TrimSpace(target.OrgNodeKey); orgNodeKey != "" {
+		recordAsOf := strings.TrimSpace(target.RecordAsOf)
+		if recordAsOf == "" {
+			recordAsOf = target.AsOf
+		}
+		current, found, err := store.GetOrgUnitValidationRecordAsOf(ctx, tenantID, orgNodeKey, recordAsOf)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			mergeOrgUnitFieldValidationFields(record, current.Fields)
+			if parentNodeKey == "" {
+				parentNodeKey = strings.TrimSpace(current.ParentOrgNodeKey)
+			}
+		}
+	}
+	if orgCode := strings.TrimSpace(target.OrgCode); orgCode != "" {
+		record["org_code"] = orgCode
+	}
+	mergeOrgUnitFieldValidationFields(record, target.Patch)
+
+	var parent map[string]any
+	if parentNodeKey != "" {
+		parentRecord, found, err := store.GetOrgUnitValidationRecordAsOf(ctx, tenantID, parentNodeKey, target.AsOf)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			parent = parentRecord.Fields
+		}
+	}
+
+	return EvaluateOrgUnitFieldValidationRulesV1(rules, OrgUnitFieldValidationInputV1{
+		Intent: target.Intent,
+		AsOf:   target.AsOf,
+		Record: record,
+		Parent: parent,
+	})
+}
+
+// resolvePrecheckFieldValidationErrors runs field validation for a precheck when its reader can supply
+// rules; readers without validation support report no field errors.
+func resolvePrecheckFieldValidationErrors(ctx context.Context, reader any, tenantID string, target orgUnitFieldValidationTarget) ([]OrgUnitFieldValidationErrorV1, error) {
+	store, ok := reader.(ports.OrgUnitFieldValidationStore)
+	if !ok || strings.TrimSpace(target.AsOf) == "" {
+		return []OrgUnitFieldValidationErrorV1{}, nil
+	}
+	return collectOrgUnitFieldValidationErrors(ctx, store, tenantID, target)
+}
+
+// resolvePrecheckFieldValidationParentNodeKey resolves a requested parent for the validation activation.
+// An unknown parent yields "" so the precheck's own parent checks report it instead of validation.
+func resolvePrecheckFieldValidationParentNodeKey(ctx context.Context, reader interface {
+	ResolveOrgNodeKey(ctx context.Context, tenantID string, orgCode string) (string, error)
+}, tenantID string, parentOrgCode string) (string, error) {
+	if strings.TrimSpace(parentOrgCode) == "" || reader == nil {
+		return "", nil
+	}
+	parentCode, err := normalizeOrgCode(parentOrgCode)
+	if err != nil {
+		return "", nil
+	}
+	parentNodeKey, err := reader.ResolveOrgNodeKey(ctx, tenantID, parentCode)
+	if err != nil {
+		if errors.Is(err, orgunitpkg.ErrOrgCodeNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return parentNodeKey, nil
+}
+
+func (s *orgUnitWriteService) validateWriteFields(ctx context.Context, tenantID string, target orgUnitFieldValidationTarget) error {
+	store, ok := s.store.(ports.OrgUnitFieldValidationStore)
+	if !ok {
+		return nil
+	}
+	fieldErrors, err := collectOrgUnitFieldValidationErrors(ctx, store, tenantID, target)
+	if err != nil {
+		return err
+	}
+	if len(fieldErrors) > 0 {
+		return &OrgUnitFieldValidationError{FieldErrors: fieldErrors}
+	}
+	return nil
+}
+
+func cloneOrgUnitFieldValidationErrors(in []OrgUnitFieldValidationErrorV1) []OrgUnitFieldValidationErrorV1 {
+	return append([]OrgUnitFieldValidationErrorV1(nil), in...)
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+)
+
+type orgUnitFieldValidationStoreStub struct {
+	orgUnitWriteStoreStub
+	rules   []types.TenantFieldValidationRule
+	records map[string]types.OrgUnitValidationRecord
+	asOfs   []string
+}
+
+func (s *orgUnitFieldValidationStoreStub) ListEnabledFieldValidationRulesAsOf(context.Context, string, string) ([]types.TenantFieldValidationRule, error) {
+	return s.rules, nil
+}
+
+func (s *orgUnitFieldValidationStoreStub) GetOrgUnitValidationRecordAsOf(_ context.Context, _ string, orgNodeKey string, asOf string) (types.OrgUnitValidationRecord, bool, error) {
+	s.asOfs = append(s.asOfs, orgNodeKey+"@"+asOf)
+	record, ok := s.records[orgNodeKey]
+	return record, ok, nil
+}
+
+func testOrgUnitFieldValidationRules() []types.TenantFieldValidationRule {
+	return []types.TenantFieldValidationRule{
+		{FieldKey: "cost_center", RuleKey: "format", Expr: `record.cost_center.matches("^[0-9]{6}$")`, Message: "成本中心须为 6 位数字"},
+		{FieldKey: "location_code", RuleKey: "required_for_site", Expr: `record.org_type != 'site' || record.location_code != ''`},
+		{FieldKey: "name", RuleKey: "max_len", Expr: `size(record.name) <= 40`},
+	}
+}
+
+func TestCompileOrgUnitFieldValidationExpr(t *testing.T) {
+	for _, expr := range []string{"", "  ", "record.name ==", "1 + 1", `record.unknown_fn()`} {
+		if err := CompileOrgUnitFieldValidationExpr(expr); err == nil {
+			t.Fatalf("expr=%q expected error", expr)
+		}
+	}
+	for _, expr := range []string{
+		`record.cost_center.matches("^[0-9]{6}$")`,
+		`record.name.trim() != '' && as_of >= timestamp('2020-01-01T00:00:00Z')`,
+		`parent.org_type == 'site' || intent == 'correct'`,
+	} {
+		if err := CompileOrgUnitFieldValidationExpr(expr); err != nil {
+			t.Fatalf("expr=%q err=%v", expr, err)
+		}
+	}
+}
+
+func TestEvaluateOrgUnitFieldValidationRulesV1(t *testing.T) {
+	t.Run("passes", func(t *testing.T) {
+		got, err := EvaluateOrgUnitFieldValidationRulesV1(testOrgUnitFieldValidationRules(), OrgUnitFieldValidationInputV1{
+			AsOf:   "2026-01-01",
+			Record: map[string]any{"name": "Site A", "cost_center": "123456", "org_type": "site", "location_code": "SH01"},
+		})
+		if err != nil || len(got) != 0 {
+			t.Fatalf("got=%+v err=%v", got, err)
+		}
+	})
+
+	t.Run("collects every failure sorted", func(t *testing.T) {
+		got, err := EvaluateOrgUnitFieldValidationRulesV1(testOrgUnitFieldValidationRules(), OrgUnitFieldValidationInputV1{
+			AsOf: "2026-01-01",
+			Record: map[string]any{
+				"name": "一个名称非常非常非常非常非常非常非常非常非常非常非常非常非常非常长的组织名称超过四十个字符了",
+				"ext":  map[string]any{"cost_center": "12A", "org_type": "site"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("err=%v", err)
+		}
+		if len(got) != 3 {
+			t.Fatalf("got=%+v", got)
+		}
+		if got[0].FieldKey != "cost_center" || got[0].Code != errFieldValidationRuleViolated || got[0].Message != "成本中心须为 6 位数字" {
+			t.Fatalf("got[0]=%+v", got[0])
+		}
+		if got[1].FieldKey != "location_code" || got[1].RuleKey != "required_for_site" {
+			t.Fatalf("got[1]=%+v", got[1])
+		}
+		if got[2].FieldKey != "name" || got[2].Message != "size(record.name) <= 40" {
+			t.Fatalf("got[2]=%+v", got[2])
+		}
+	})
+
+	t.Run("runtime failure", func(t *testing.T) {
+		got, err := EvaluateOrgUnitFieldValidationRulesV1([]types.TenantFieldValidationRule{
+			{FieldKey: "cost_center", RuleKey: "missing", Expr: `record.cost_center.matches("^[0-9]+$")`},
+			{FieldKey: "budget", RuleKey: "dyn", Expr: `record.budget`},
+		}, OrgUnitFieldValidationInputV1{AsOf: "2026-01-01", Record: map[string]any{"budget": 12}})
+		if err != nil {
+			t.Fatalf("err=%v", err)
+		}
+		if len(got) != 2 || got[0].Code != errFieldValidationRuleEvalFailed || got[1].Code != errFieldValidationRuleEvalFailed {
+			t.Fatalf("got=%+v", got)
+		}
+	})
+
+	t.Run("parent and as_of", func(t *testing.T) {
+		rules := []types.TenantFieldValidationRule{
+			{FieldKey: "name", RuleKey: "parent", Expr: `parent.status == 'active' && as_of >= timestamp('2026-01-01T00:00:00Z')`},
+		}
+		got, err := EvaluateOrgUnitFieldValidationRulesV1(rules, OrgUnitFieldValidationInputV1{AsOf: "2026-02-01", Parent: map[string]any{"status": "active"}})
+		if err != nil || len(got) != 0 {
+			t.Fatalf("got=%+v err=%v", got, err)
+		}
+		got, err = EvaluateOrgUnitFieldValidationRulesV1(rules, OrgUnitFieldValidationInputV1{AsOf: "2026-02-01"})
+		if err != nil || len(got) != 1 {
+			t.Fatalf("got=%+v err=%v", got, err)
+		}
+	})
+
+	t.Run("cost limit", func(t *testing.T) {
+		items := strings.TrimSuffix(strings.Repeat("1,", 200), ",")
+		expr := "[" + items + "].all(x, [" + items + "].all(y, x + y > 0))"
+		got, err := EvaluateOrgUnitFieldValidationRulesV1([]types.TenantFieldValidationRule{
+			{FieldKey: "name", RuleKey: "expensive", Expr: expr},
+		}, OrgUnitFieldValidationInputV1{AsOf: "2026-01-01"})
+		if err != nil || len(got) != 1 || got[0].Code != errFieldValidationRuleEvalFailed || !strings.Contains(got[0].Message, "cost limit") {
+			t.Fatalf("got=%+v err=%v", got, err)
+		}
+	})
+
+	t.Run("compile result is cached", func(t *testing.T) {
+		const expr = `record.name != "cached-rule"`
+		first, err := orgUnitFieldValidationProgram(expr)
+		if err != nil {
+			t.Fatalf("err=%v", err)
+		}
+		if again, err := orgUnitFieldValidationProgram("  " + expr + "  "); err != nil || again != first {
+			t.Fatalf("program not reused: err=%v", err)
+		}
+		if _, err := orgUnitFieldValidationProgram(`record.name +`); err == nil {
+			t.Fatal("expected compile error")
+		}
+		if _, ok := orgUnitFieldValidationPrograms.Load(`record.name +`); !ok {
+			t.Fatal("compile failure not cached")
+		}
+	})
+
+	t.Run("invalid as_of", func(t *testing.T) {
+		if _, err := EvaluateOrgUnitFieldValidationRulesV1(nil, OrgUnitFieldValidationInputV1{AsOf: "bad"}); err == nil || err.Error() != errEffectiveDateInvalid {
+			t.Fatalf("err=%v", err)
+		}
+	})
+}
+
+func TestSelectOrgUnitFieldValidationRules(t *testing.T) {
+	rules := []types.TenantFieldValidationRule{
+		{FieldKey: "name", FormKey: "orgunit.create_dialog", RuleKey: "max_len", Expr: "size(record.name) <= 20"},
+		{FieldKey: "name", RuleKey: "max_len", Expr: "size(record.name) <= 40"},
+		{FieldKey: "name", FormKey: "orgunit.details.correct_dialog", RuleKey: "other", Expr: "true"},
+		{FieldKey: "cost_center", RuleKey: "format", Expr: "true"},
+	}
+	got := selectOrgUnitFieldValidationRules(rules, string(OrgUnitWriteIntentCreateOrg))
+	if len(got) != 2 || got[0].FieldKey != "cost_center" || got[1].Expr != "size(record.name) <= 20" {
+		t.Fatalf("create=%+v", got)
+	}
+	got = selectOrgUnitFieldValidationRules(rules, string(OrgUnitWriteIntentAddVersion))
+	if len(got) != 2 || got[1].Expr != "size(record.name) <= 40" {
+		t.Fatalf("add=%+v", got)
+	}
+	got = selectOrgUnitFieldValidationRules(rules, string(OrgUnitWriteIntentCorrect))
+	if len(got) != 3 {
+		t.Fatalf("correct=%+v", got)
+	}
+}
+
+func TestWriteUnified_CreateOrg_FieldValidationRejects(t *testing.T) {
+	base := withDefaultCreateFieldDecisions(orgUnitWriteStoreStub{
+		listEnabledFieldCfgsFn: func(context.Context, string, string) ([]types.TenantFieldConfig, error) {
+			return []types.TenantFieldConfig{}, nil
+		},
+		submitEventFn: func(context.Context, string, string, *int, string, string, json.RawMessage, string, string) (int64, error) {
+			t.Fatal("SubmitEvent should not be called")
+			return 0, nil
+		},
+	})
+	store := &orgUnitFieldValidationStoreStub{
+		orgUnitWriteStoreStub: base,
+		rules:                 testOrgUnitFieldValidationRules()[2:],
+	}
+	name := "一个名称非常非常非常非常非常非常非常非常非常非常非常非常非常非常长的组织名称超过四十个字符了"
+	_, err := NewOrgUnitWriteService(store).Write(context.Background(), "t1", WriteOrgUnitRequest{
+		Intent:        "create_org",
+		OrgCode:       "ROOT",
+		EffectiveDate: "2026-01-01",
+		RequestID:     "req-1",
+		Patch:         OrgUnitWritePatch{Name: &name},
+	})
+	var validationErr *OrgUnitFieldValidationError
+	if !errors.As(err, &validationErr) || err.Error() != errFieldValidationFailed {
+		t.Fatalf("err=%v", err)
+	}
+	if len(validationErr.FieldErrors) != 1 || validationErr.FieldErrors[0].FieldKey != "name" {
+		t.Fatalf("field errors=%+v", validationErr.FieldErrors)
+	}
+}
+
+func TestWriteUnified_Correct_FieldValidationReadsTargetVersion(t *testing.T) {
+	orgNodeKey := mustEncodeTestOrgNodeKey(10000001)
+	parentNodeKey := mustEncodeTestOrgNodeKey(10000000)
+	var submitted bool
+	store := &orgUnitFieldValidationStoreStub{
+		orgUnitWriteStoreStub: orgUnitWriteStoreStub{
+			listEnabledFieldCfgsFn: func(context.Context, string, string) ([]types.TenantFieldConfig, error) {
+				return []types.TenantFieldConfig{}, nil
+			},
+			resolveOrgIDFn: func(context.Context, string, string) (int, error) {
+				return 10000001, nil
+			},
+			submitCorrectionFn: func(context.Context, string, int, string, json.RawMessage, string, string) (string, error) {
+				submitted = true
+				return "evt-c1", nil
+			},
+		},
+		rules: []types.TenantFieldValidationRule{
+			{FieldKey: "location_code", RuleKey: "required_for_site", Expr: `record.org_type != 'site' || record.location_code != ''`},
+			{FieldKey: "name", RuleKey: "parent_active", Expr: `parent.status == 'active'`},
+		},
+		records: map[string]types.OrgUnitValidationRecord{
+			orgNodeKey:    {OrgNodeKey: orgNodeKey, ParentOrgNodeKey: parentNodeKey, Fields: map[string]any{"name": "Site", "org_type": "site", "location_code": "SH01"}},
+			parentNodeKey: {OrgNodeKey: parentNodeKey, Fields: map[string]any{"status": "active"}},
+		},
+	}
+	name := "Site B"
+	_, err := NewOrgUnitWriteService(store).Write(context.Background(), "t1", WriteOrgUnitRequest{
+		Intent:              "correct",
+		OrgCode:             "A001",
+		EffectiveDate:       "2026-01-03",
+		TargetEffectiveDate: "2026-01-01",
+		RequestID:           "req-3",
+		Patch:               OrgUnitWritePatch{Name: &name},
+	})
+	if err != nil || !submitted {
+		t.Fatalf("err=%v submitted=%v", err, submitted)
+	}
+	if len(store.asOfs) != 2 || store.asOfs[0] != orgNodeKey+"@2026-01-01" || store.asOfs[1] != parentNodeKey+"@2026-01-03" {
+		t.Fatalf("asOfs=%v", store.asOfs)
+	}
+}
+
+func TestBuildOrgUnitMaintainPrecheckProjectionV1_FieldValidationRejects(t *testing.T) {
+	reader := &orgUnitMaintainFieldValidationReaderStub{
+		orgUnitMaintainPrecheckReaderStub: testMaintainReaderReady(),
+		orgUnitFieldValidationStoreStub: orgUnitFieldValidationStoreStub{
+			rules: testOrgUnitFieldValidationRules()[2:],
+		},
+	}
+	result, err := BuildOrgUnitMaintainPrecheckProjectionV1(context.Background(), reader, OrgUnitMaintainPrecheckInputV1{
+		Intent:              OrgUnitMaintainIntentCorrect,
+		TenantID:            "tenant_1",
+		TargetEffectiveDate: "2026-01-01",
+		OrgCode:             "FLOWER-C",
+		CanAdmin:            true,
+		NewName:             "一个名称非常非常非常非常非常非常非常非常非常非常非常非常非常非常长的组织名称超过四十个字符了",
+	})
+	if err != nil {
+		t.Fatalf("build err=%v", err)
+	}
+	if result.Projection.Readiness == orgUnitMaintainReadinessReady {
+		t.Fatalf("readiness=%q", result.Projection.Readiness)
+	}
+	if len(result.Projection.FieldErrors) != 1 || result.Projection.FieldErrors[0].RuleKey != "max_len" {
+		t.Fatalf("field errors=%+v", result.Projection.FieldErrors)
+	}
+}
+
+type orgUnitMaintainFieldValidationReaderStub struct {
+	orgUnitMaintainPrecheckReaderStub
+	orgUnitFieldValidationStoreStub
+}
+
+func (s *orgUnitMaintainFieldValidationReaderStub) ListEnabledFieldValidationRulesAsOf(ctx context.Context, tenantID string, asOf string) ([]types.TenantFieldValidationRule, error) {
+	return s.orgUnitFieldValidationStoreStub.ListEnabledFieldValidationRulesAsOf(ctx, tenantID, asOf)
+}
+
+func (s *orgUnitMaintainFieldValidationReaderStub) GetOrgUnitValidationRecordAsOf(ctx context.Context, tenantID string, orgNodeKey string, asOf string) (types.OrgUnitValidationRecord, bool, error) {
+	return s.orgUnitFieldValidationStoreStub.GetOrgUnitValidationRecordAsOf(ctx, tenantID, orgNodeKey, asOf)
+}
@@ -0,0 +1,592 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/ports"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+	orgunitpkg "github.com/jacksonlee411/Bugs-And-Blossoms/pkg/orgunit"
+)
+
+var (
+	ErrOrgUnitReadKnownAtInvalid     = errors.New("orgunit_read_known_at_invalid")
+	ErrOrgUnitReadKnownAtUnsupported = errors.New("orgunit_read_known_at_unsupported")
+)
+
+const (
+	// orgUnitKnownAtCacheSize bounds the number of replayed snapshots kept for repeated audit queries.
+	orgUnitKnownAtCacheSize = 32
+	// orgUnitKnownAtSettleWindow keeps snapshots too close to now out of the cache: a transaction that
+	// started before known_at may still commit events stamped with an earlier tx_time.
+	orgUnitKnownAtSettleWindow = 5 * time.Minute
+)
+
+// OrgUnitKnownAtVersion is one validity slice of an org unit rebuilt from the replayed events.
+// EndDate is exclusive and empty for the open-ended slice.
+type OrgUnitKnownAtVersion struct {
+	EffectiveDate    string
+	EndDate          string
+	ParentOrgNodeKey string
+	Name             string
+	Status           string
+	IsBusinessUnit   bool
+	ManagerUUID      string
+	ManagerPernr     string
+	EventUUID        string
+	TxTime           time.Time
+}
+
+// OrgUnitKnownAtDetails is the details view of an org unit as of a day, as known at the snapshot time.
+type OrgUnitKnownAtDetails struct {
+	OrgNodeKey       string
+	OrgCode          string
+	Name             string
+	Status           string
+	ParentOrgNodeKey string
+	ParentOrgCode    string
+	ParentName       string
+	IsBusinessUnit   bool
+	ManagerPernr     string
+	FullNamePath     string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	EventUUID        string
+}
+
+type orgUnitKnownAtNode struct {
+	orgNodeKey string
+	orgCode    string
+	events     []types.OrgUnitReplayEvent
+	versions   []OrgUnitKnownAtVersion
+}
+
+// OrgUnitKnownAtSnapshot is the org history of one tenant as the system knew it at KnownAt. It answers
+// the read store contracts in memory, so the read service can run unchanged on top of it.
+type OrgUnitKnownAtSnapshot struct {
+	TenantID string
+	KnownAt  time.Time
+	nodes    map[string]*orgUnitKnownAtNode
+	byCode   map[string]string
+}
+
+// NewOrgUnitKnownAtSnapshot folds the replayed events into per-node versions. The events come from the
+// kernel's org_events_effective_known_at with corrections and rescinds already folded; each one applies
+// from its effective date onward, the same order the kernel rebuild replays them in.
+func NewOrgUnitKnownAtSnapshot(tenantID string, knownAt time.Time, events []types.OrgUnitReplayEvent) *OrgUnitKnownAtSnapshot {
+	snap := &OrgUnitKnownAtSnapshot{
+		TenantID: tenantID,
+		KnownAt:  knownAt,
+		nodes:    map[string]*orgUnitKnownAtNode{},
+		byCode:   map[string]string{},
+	}
+	grouped := map[string][]types.OrgUnitReplayEvent{}
+	for _, event := range events {
+		key, err := orgunitpkg.NormalizeOrgNodeKey(event.OrgNodeKey)
+		if err != nil {
+			continue
+		}
+		event.OrgNodeKey = key
+		grouped[key] = append(grouped[key], event)
+	}
+	for key, nodeEvents := range grouped {
+		sort.SliceStable(nodeEvents, func(i, j int) bool {
+			if nodeEvents[i].EffectiveDate != nodeEvents[j].EffectiveDate {
+				return nodeEvents[i].EffectiveDate < nodeEvents[j].EffectiveDate
+			}
+			return nodeEvents[i].EventID < nodeEvents[j].EventID
+		})
+		node := &orgUnitKnownAtNode{orgNodeKey: key, events: nodeEvents}
+		node.versions, node.orgCode = replayOrgUnitKnownAtVersions(nodeEvents)
+		if len(node.versions) == 0 {
+			continue
+		}
+		snap.nodes[key] = node
+		if node.orgCode != "" {
+			snap.byCode[node.orgCode] = key
+		}
+	}
+	return snap
+}
+
+func replayOrgUnitKnownAtVersions(events []types.OrgUnitReplayEvent) ([]OrgUnitKnownAtVersion, string) {
+	var versions []OrgUnitKnownAtVersion
+	orgCode := ""
+	for _, event := range events {
+		payload := map[string]any{}
+		if len(event.Payload) > 0 {
+			_ = json.Unmarshal(event.Payload, &payload)
+		}
+		if strings.TrimSpace(event.OrgCode) != "" {
+			orgCode = strings.TrimSpace(event.OrgCode)
+		}
+
+		var current OrgUnitKnownAtVersion
+		if event.EventType == "CREATE" {
+			if len(versions) > 0 {
+				continue
+			}
+			if code := payloadString(payload, "org_code"); code != "" && orgCode == "" {
+				orgCode = code
+			}
+			current = OrgUnitKnownAtVersion{Status: "active"}
+		} else {
+			if len(versions) == 0 {
+				// Changes before the CREATE are rejected by the kernel; skip them the same way.
+				continue
+			}
+			current = versions[len(versions)-1]
+		}
+
+		switch event.EventType {
+		case "CREATE", "UPDATE":
+			if value, ok := payloadFirstString(payload, "new_parent_org_node_key", "parent_org_node_key"); ok {
+				current.ParentOrgNodeKey = normalizeKnownAtParent(value)
+			}
+			if value, ok := payloadFirstString(payload, "new_name", "name"); ok && value != "" {
+				current.Name = value
+			}
+			if value, ok := payloadFirstString(payload, "status"); ok && value != "" {
+				current.Status = normalizeKnownAtStatus(value)
+			}
+			if value, ok := payload["is_business_unit"].(bool); ok {
+				current.IsBusinessUnit = value
+			}
+			if value, ok := payloadFirstString(payload, "manager_uuid"); ok {
+				current.ManagerUUID = value
+			}
+			if value, ok := payloadFirstString(payload, "manager_pernr"); ok {
+				current.ManagerPernr = value
+			}
+		case "MOVE":
+			if value, ok := payloadFirstString(payload, "new_parent_org_node_key"); ok {
+				current.ParentOrgNodeKey = normalizeKnownAtParent(value)
+			}
+		case "RENAME":
+			if value, ok := payloadFirstString(payload, "new_name"); ok && value != "" {
+				current.Name = value
+			}
+		case "DISABLE":
+			current.Status = "disabled"
+		case "ENABLE":
+			current.Status = "active"
+		case "SET_BUSINESS_UNIT":
+			if value, ok := payload["is_business_unit"].(bool); ok {
+				current.IsBusinessUnit = value
+			}
+		default:
+			continue
+		}
+		current.EffectiveDate = event.EffectiveDate
+		current.EndDate = ""
+		current.EventUUID = event.EventUUID
+		current.TxTime = event.TxTime
+
+		if n := len(versions); n > 0 && versions[n-1].EffectiveDate == event.EffectiveDate {
+			versions[n-1] = current
+			continue
+		}
+		if n := len(versions); n > 0 {
+			versions[n-1].EndDate = event.EffectiveDate
+		}
+		versions = append(versions, current)
+	}
+	return versions, orgCode
+}
+
+func payloadString(payload map[string]any, key string) string {
+	value, _ := payloadFirstString(payload, key)
+	return value
+}
+
+func payloadFirstString(payload map[string]any, keys ...string) (string, bool) {
+	for _, key := range keys {
+		raw, ok := payload[key]
+		if !ok {
+			continue
+		}
+		if raw == nil {
+			return "", true
+		}
+		if value, ok := raw.(string); ok {
+			return strings.TrimSpace(value), true
+		}
+	}
+	return "", false
+}
+
+func normalizeKnownAtParent(orgNodeKey string) string {
+	normalized, err := orgunitpkg.NormalizeOrgNodeKey(orgNodeKey)
+	if err != nil {
+		return ""
+	}
+	return normalized
+}
+
+func normalizeKnownAtStatus(status string) string {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "disabled", "inactive", "无效":
+		return "disabled"
+	default:
+		return "active"
+	}
+}
+
+func (s *OrgUnitKnownAtSnapshot) versionAsOf(orgNodeKey string, asOf string) (*orgUnitKnownAtNode, OrgUnitKnownAtVersion, bool) {
+	node, ok := s.nodes[strings.TrimSpace(orgNodeKey)]
+	if !ok {
+		return nil, OrgUnitKnownAtVersion{}, false
+	}
+	for i := len(node.versions) - 1; i >= 0; i-- {
+		v := node.versions[i]
+		if v.EffectiveDate <= asOf && (v.EndDate == "" || asOf < v.EndDate) {
+			return node, v, true
+		}
+	}
+	return nil, OrgUnitKnownAtVersion{}, false
+}
+
+// path walks the parent chain as of asOf from the root down to orgNodeKey.
+func (s *OrgUnitKnownAtSnapshot) path(orgNodeKey string, asOf string) ([]string, []string, []string) {
+	var keys, codes, names []string
+	seen := map[string]bool{}
+	for key := orgNodeKey; key != "" && !seen[key]; {
+		seen[key] = true
+		node, version, ok := s.versionAsOf(key, asOf)
+		if !ok {
+			break
+		}
+		keys = append(keys, key)
+		codes = append(codes, node.orgCode)
+		names = append(names, version.Name)
+		key = version.ParentOrgNodeKey
+	}
+	for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+		keys[i], keys[j] = keys[j], keys[i]
+		codes[i], codes[j] = codes[j], codes[i]
+		names[i], names[j] = names[j], names[i]
+	}
+	return keys, codes, names
+}
+
+func (s *OrgUnitKnownAtSnapshot) readNode(orgNodeKey string, asOf string, includeDisabled bool) (OrgUnitReadNode, bool) {
+	node, version, ok := s.versionAsOf(orgNodeKey, asOf)
+	if !ok || (!includeDisabled && version.Status != "active") {
+		return OrgUnitReadNode{}, false
+	}
+	keys, codes, _ := s.path(node.orgNodeKey, asOf)
+	isBusinessUnit := version.IsBusinessUnit
+	return OrgUnitReadNode{
+		OrgCode:         node.orgCode,
+		OrgNodeKey:      node.orgNodeKey,
+		Name:            version.Name,
+		Status:          version.Status,
+		IsBusinessUnit:  &isBusinessUnit,
+		PathOrgCodes:    codes,
+		PathOrgNodeKeys: keys,
+	}, true
+}
+
+func (s *OrgUnitKnownAtSnapshot) sortedKeys() []string {
+	keys := make([]string, 0, len(s.nodes))
+	for key := range s.nodes {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		left, right := s.nodes[keys[i]].orgCode, s.nodes[keys[j]].orgCode
+		if left != right {
+			return left < right
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func (s *OrgUnitKnownAtSnapshot) children(parentOrgNodeKey string, asOf string, includeDisabled bool) []OrgUnitReadNode {
+	out := make([]OrgUnitReadNode, 0)
+	for _, key := range s.sortedKeys() {
+		_, version, ok := s.versionAsOf(key, asOf)
+		if !ok || version.ParentOrgNodeKey != parentOrgNodeKey {
+			continue
+		}
+		if node, ok := s.readNode(key, asOf, includeDisabled); ok {
+			out = append(out, node)
+		}
+	}
+	return out
+}
+
+func (s *OrgUnitKnownAtSnapshot) withChildFlags(nodes []OrgUnitReadNode, asOf string, includeDisabled bool) []OrgUnitReadNode {
+	for i := range nodes {
+		nodes[i].HasVisibleChildren = len(s.children(nodes[i].OrgNodeKey, asOf, includeDisabled)) > 0
+	}
+	return nodes
+}
+
+func (s *OrgUnitKnownAtSnapshot) ListRoots(_ context.Context, _ string, asOf string, includeDisabled bool) ([]OrgUnitReadNode, error) {
+	return s.withChildFlags(s.children("", asOf, includeDisabled), asOf, includeDisabled), nil
+}
+
+func (s *OrgUnitKnownAtSnapshot) ListChildren(_ context.Context, _ string, parentOrgNodeKey string, asOf string, includeDisabled bool) ([]OrgUnitReadNode, error) {
+	return s.withChildFlags(s.children(strings.TrimSpace(parentOrgNodeKey), asOf, includeDisabled), asOf, includeDisabled), nil
+}
+
+func (s *OrgUnitKnownAtSnapshot) ListTree(_ context.Context, _ string, asOf string, includeDisabled bool) ([]OrgUnitReadNode, error) {
+	out := make([]OrgUnitReadNode, 0, len(s.nodes))
+	for _, key := range s.sortedKeys() {
+		if node, ok := s.readNode(key, asOf, includeDisabled); ok {
+			out = append(out, node)
+		}
+	}
+	return out, nil
+}
+
+func (s *OrgUnitKnownAtSnapshot) ResolveByOrgNodeKey(_ context.Context, _ string, orgNodeKey string, asOf string, includeDisabled bool) (OrgUnitReadNode, error) {
+	key, err := orgunitpkg.NormalizeOrgNodeKey(orgNodeKey)
+	if err != nil {
+		return OrgUnitReadNode{}, ErrOrgUnitReadInvalidArgument
+	}
+	node, ok := s.readNode(key, asOf, includeDisabled)
+	if !ok {
+		return OrgUnitReadNode{}, ErrOrgUnitReadNotFound
+	}
+	return node, nil
+}
+
+func (s *OrgUnitKnownAtSnapshot) ResolveByOrgCode(ctx context.Context, tenantID string, orgCode string, asOf string, includeDisabled bool) (OrgUnitReadNode, error) {
+	key, ok := s.OrgNodeKeyByCode(orgCode)
+	if !ok {
+		return OrgUnitReadNode{}, ErrOrgUnitReadNotFound
+	}
+	return s.ResolveByOrgNodeKey(ctx, tenantID, key, asOf, includeDisabled)
+}
+
+func (s *OrgUnitKnownAtSnapshot) Search(ctx context.Context, tenantID string, query string, asOf string, includeDisabled bool, limit int) ([]OrgUnitReadNode, error) {
+	nodes, err := s.ListTree(ctx, tenantID, asOf, includeDisabled)
+	if err != nil {
+		return nil, err
+	}
+	return limitReadNodes(filterReadNodesForList(nodes, query, "", nil), limit), nil
+}
+
+// ListPage serves the paged list in memory. Ext field filters and sorts and structured filters need the
+// projected columns, which the snapshot does not rebuild.
+func (s *OrgUnitKnownAtSnapshot) ListPage(ctx context.Context, req OrgUnitReadListPageRequest) ([]OrgUnitReadNode, int, error) {
+	if strings.TrimSpace(req.ExtFilterFieldKey) != "" || strings.TrimSpace(req.ExtSortFieldKey) != "" || req.Filter != nil {
+		return nil, 0, ErrOrgUnitReadExtQueryNotAllowed
+	}
+	nodes, err := s.ListTree(ctx, req.TenantID, req.AsOf, req.IncludeDisabled)
+	if err != nil {
+		return nil, 0, err
+	}
+	nodes = filterReadNodesByScope(req.ScopeFilter, nodes)
+	visible := map[string]bool{}
+	for _, node := range nodes {
+		visible[node.OrgNodeKey] = true
+	}
+	for i := range nodes {
+		nodes[i].HasVisibleChildren = hasDirectVisibleChild(nodes[i], nodes)
+	}
+
+	parentKey := strings.TrimSpace(req.ParentOrgNodeKey)
+	if parentKey == "" && strings.TrimSpace(req.ParentOrgCode) != "" {
+		key, ok := s.OrgNodeKeyByCode(req.ParentOrgCode)
+		if !ok {
+			return nil, 0, ErrOrgUnitReadNotFound
+		}
+		parentKey = key
+	}
+	if parentKey != "" {
+		parent, err := s.ResolveByOrgNodeKey(ctx, req.TenantID, parentKey, req.AsOf, true)
+		if err != nil {
+			return nil, 0, err
+		}
+		allLevels := req.IncludeDescendants != nil && *req.IncludeDescendants
+		scoped := make([]OrgUnitReadNode, 0, len(nodes))
+		for _, node := range nodes {
+			if node.OrgNodeKey == parent.OrgNodeKey || !pathHasPrefix(node.PathOrgNodeKeys, parent.PathOrgNodeKeys) {
+				continue
+			}
+			if allLevels || len(node.PathOrgNodeKeys) == len(parent.PathOrgNodeKeys)+1 {
+				scoped = append(scoped, node)
+			}
+		}
+		nodes = scoped
+	} else if !req.AllOrgUnits && strings.TrimSpace(req.Keyword) == "" && req.IsBusinessUnit == nil {
+		// Without a parent the page lists the top-most visible units, which are scope roots for scoped callers.
+		roots := make([]OrgUnitReadNode, 0, len(nodes))
+		for _, node := range nodes {
+			if n := len(node.PathOrgNodeKeys); n == 1 || !visible[node.PathOrgNodeKeys[n-2]] {
+				roots = append(roots, node)
+			}
+		}
+		nodes = roots
+	}
+
+	nodes = filterReadNodesForList(nodes, req.Keyword, req.Status, req.IsBusinessUnit)
+	if strings.TrimSpace(req.SortField) != "" {
+		sortReadNodesForList(nodes, req.SortField, req.SortOrder)
+	}
+	total := len(nodes)
+	if req.Keyset != nil {
+		return PageReadNodesByKeyset(nodes, req.SortField, req.SortOrder, *req.Keyset, req.Limit), total, nil
+	}
+	return paginateReadNodes(nodes, req.Limit, req.Offset), total, nil
+}
+
+// OrgNodeKeyByCode resolves an org code known at the snapshot time.
+func (s *OrgUnitKnownAtSnapshot) OrgNodeKeyByCode(orgCode string) (string, bool) {
+	normalized, err := orgunitpkg.NormalizeOrgCode(orgCode)
+	if err != nil {
+		return "", false
+	}
+	key, ok := s.byCode[normalized]
+	return key, ok
+}
+
+// Events returns the effective events of one org unit as known at the snapshot time; this is what the
+// versions API lists.
+func (s *OrgUnitKnownAtSnapshot) Events(orgNodeKey string) []types.OrgUnitReplayEvent {
+	node, ok := s.nodes[strings.TrimSpace(orgNodeKey)]
+	if !ok {
+		return nil
+	}
+	return append([]types.OrgUnitReplayEvent(nil), node.events...)
+}
+
+// Versions returns the validity slices of one org unit as known at the snapshot time.
+func (s *OrgUnitKnownAtSnapshot) Versions(orgNodeKey string) []OrgUnitKnownAtVersion {
+	node, ok := s.nodes[strings.TrimSpace(orgNodeKey)]
+	if !ok {
+		return nil
+	}
+	return append([]OrgUnitKnownAtVersion(nil), node.versions...)
+}
+
+// Details returns the details view of one org unit as of asOf.
+func (s *OrgUnitKnownAtSnapshot) Details(orgNodeKey string, asOf string, includeDisabled bool) (OrgUnitKnownAtDetails, error) {
+	node, version, ok := s.versionAsOf(orgNodeKey, asOf)
+	if !ok || (!includeDisabled && version.Status != "active") {
+		return OrgUnitKnownAtDetails{}, ErrOrgUnitReadNotFound
+	}
+	_, _, names := s.path(node.orgNodeKey, asOf)
+	details := OrgUnitKnownAtDetails{
+		OrgNodeKey:       node.orgNodeKey,
+		OrgCode:          node.orgCode,
+		Name:             version.Name,
+		Status:           version.Status,
+		ParentOrgNodeKey: version.ParentOrgNodeKey,
+		IsBusinessUnit:   version.IsBusinessUnit,
+		ManagerPernr:     version.ManagerPernr,
+		FullNamePath:     strings.Join(names, " / "),
+		CreatedAt:        node.versions[0].TxTime,
+		UpdatedAt:        version.TxTime,
+		EventUUID:        version.EventUUID,
+	}
+	if parent, parentVersion, ok := s.versionAsOf(version.ParentOrgNodeKey, asOf); ok {
+		details.ParentOrgCode = parent.orgCode
+		details.ParentName = parentVersion.Name
+	}
+	return details, nil
+}
+
+// ParseOrgUnitKnownAt parses the RFC 3339 known_at timestamp of a read request.
+func ParseOrgUnitKnownAt(raw string) (time.Time, error) {
+	knownAt, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(raw))
+	if err != nil {
+		return time.Time{}, ErrOrgUnitReadKnownAtInvalid
+	}
+	return knownAt.UTC(), nil
+}
+
+type orgUnitKnownAtCacheKey struct {
+	tenantID string
+	knownAt  int64
+}
+
+// OrgUnitKnownAtCache keeps recently replayed snapshots. Audit screens tend to ask the same known_at
+// repeatedly while paging through a tree, and a settled snapshot never changes.
+type OrgUnitKnownAtCache struct {
+	mu      sync.Mutex
+	size    int
+	now     func() time.Time
+	order   []orgUnitKnownAtCacheKey
+	entries map[orgUnitKnownAtCacheKey]*OrgUnitKnownAtSnapshot
+}
+
+func NewOrgUnitKnownAtCache(size int) *OrgUnitKnownAtCache {
+	if size <= 0 {
+		size = orgUnitKnownAtCacheSize
+	}
+	return &OrgUnitKnownAtCache{
+		size:    size,
+		now:     time.Now,
+		entries: map[orgUnitKnownAtCacheKey]*OrgUnitKnownAtSnapshot{},
+	}
+}
+
+var defaultOrgUnitKnownAtCache = NewOrgUnitKnownAtCache(orgUnitKnownAtCacheSize)
+
+// Snapshot returns the snapshot of tenantID as known at knownAt, replaying it through store on a miss.
+func (c *OrgUnitKnownAtCache) Snapshot(ctx context.Context, store ports.OrgUnitKnownAtEventStore, tenantID string, knownAt time.Time) (*OrgUnitKnownAtSnapshot, error) {
+	key := orgUnitKnownAtCacheKey{tenantID: tenantID, knownAt: knownAt.UnixNano()}
+	c.mu.Lock()
+	snap, ok := c.entries[key]
+	cacheable := knownAt.Before(c.now().Add(-orgUnitKnownAtSettleWindow))
+	c.mu.Unlock()
+	if ok {
+		return snap, nil
+	}
+
+	events, err := store.ListReplayEventsKnownAt(ctx, tenantID, knownAt)
+	if err != nil {
+		return nil, err
+	}
+	snap = NewOrgUnitKnownAtSnapshot(tenantID, knownAt, events)
+	if !cacheable {
+		return snap, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.entries[key]; !exists {
+		c.entries[key] = snap
+		c.order = append(c.order, key)
+		for len(c.order) > c.size {
+			delete(c.entries, c.order[0])
+			c.order = c.order[1:]
+		}
+	}
+	return snap, nil
+}
+
+// OrgUnitKnownAtSnapshotFor replays the tenant as known at the RFC 3339 knownAt through the shared cache.
+// store must implement ports.OrgUnitKnownAtEventStore.
+func OrgUnitKnownAtSnapshotFor(ctx context.Context, store any, tenantID string, knownAt string) (*OrgUnitKnownAtSnapshot, error) {
+	eventStore, ok := store.(ports.OrgUnitKnownAtEventStore)
+	if !ok {
+		return nil, ErrOrgUnitReadKnownAtUnsupported
+	}
+	parsed, err := ParseOrgUnitKnownAt(knownAt)
+	if err != nil {
+		return nil, err
+	}
+	return defaultOrgUnitKnownAtCache.Snapshot(ctx, eventStore, tenantID, parsed)
+}
+
+// forKnownAt swaps the service onto a replayed snapshot when the request asks for a known_at view.
+func (s orgUnitReadService) forKnownAt(ctx context.Context, tenantID string, knownAt string) (orgUnitReadService, error) {
+	if strings.TrimSpace(knownAt) == "" {
+		return s, nil
+	}
+	snap, err := OrgUnitKnownAtSnapshotFor(ctx, s.store, tenantID, knownAt)
+	if err != nil {
+		return s, err
+	}
+	return orgUnitReadService{store: snap}, nil
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+)
+
+type knownAtEventStoreStub struct {
+	events []types.OrgUnitReplayEvent
+	calls  int
+}
+
+func (s *knownAtEventStoreStub) ListReplayEventsKnownAt(_ context.Context, _ string, knownAt time.Time) ([]types.OrgUnitReplayEvent, error) {
+	s.calls++
+	out := make([]types.OrgUnitReplayEvent, 0, len(s.events))
+	for _, event := range s.events {
+		if !event.TxTime.After(knownAt) {
+			out = append(out, event)
+		}
+	}
+	return out, nil
+}
+
+func (s *knownAtEventStoreStub) ListRoots(context.Context, string, string, bool) ([]OrgUnitReadNode, error) {
+	return nil, errors.New("current store must not be read for known_at")
+}
+
+func (s *knownAtEventStoreStub) ListChildren(context.Context, string, string, string, bool) ([]OrgUnitReadNode, error) {
+	return nil, errors.New("current store must not be read for known_at")
+}
+
+func (s *knownAtEventStoreStub) ResolveByOrgNodeKey(context.Context, string, string, string, bool) (OrgUnitReadNode, error) {
+	return OrgUnitReadNode{}, errors.New("current store must not be read for known_at")
+}
+
+func (s *knownAtEventStoreStub) ResolveByOrgCode(context.Context, string, string, string, bool) (OrgUnitReadNode, error) {
+	return OrgUnitReadNode{}, errors.New("current store must not be read for known_at")
+}
+
+func (s *knownAtEventStoreStub) Search(context.Context, string, string, string, bool, int) ([]OrgUnitReadNode, error) {
+	return nil, errors.New("current store must not be read for known_at")
+}
+
+func knownAtTime(t *testing.T, raw string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+// knownAtFixture: two roots and a child created in January; the child is renamed in March, moved under
+// the second root from April (recorded only on 2026-04-10) and disabled from June.
+func knownAtFixture(t *testing.T) []types.OrgUnitReplayEvent {
+	return []types.OrgUnitReplayEvent{
+		{EventID: 1, EventUUID: "e1", OrgNodeKey: "AAAAAAAB", OrgCode: "ROOT", EventType: "CREATE", EffectiveDate: "2026-01-01", Payload: []byte(`{"name":"Root","is_business_unit":true}`), TxTime: knownAtTime(t, "2026-01-01T08:00:00Z")},
+		{EventID: 2, EventUUID: "e2", OrgNodeKey: "AAAAAAAC", OrgCode: "OPS", EventType: "CREATE", EffectiveDate: "2026-01-01", Payload: []byte(`{"name":"Ops","parent_org_node_key":"AAAAAAAB","manager_pernr":"7"}`), TxTime: knownAtTime(t, "2026-01-01T09:00:00Z")},
+		{EventID: 3, EventUUID: "e3", OrgNodeKey: "AAAAAAAD", OrgCode: "ALT", EventType: "CREATE", EffectiveDate: "2026-01-01", Payload: []byte(`{"name":"Alt"}`), TxTime: knownAtTime(t, "2026-01-01T10:00:00Z")},
+		{EventID: 4, EventUUID: "e4", OrgNodeKey: "AAAAAAAC", OrgCode: "OPS", EventType: "RENAME", EffectiveDate: "2026-03-01", Payload: []byte(`{"new_name":"Operations"}`), TxTime: knownAtTime(t, "2026-03-01T08:00:00Z")},
+		{EventID: 5, EventUUID: "e5", OrgNodeKey: "AAAAAAAC", OrgCode: "OPS", EventType: "MOVE", EffectiveDate: "2026-04-01", Payload: []byte(`{"new_parent_org_node_key":"AAAAAAAD"}`), TxTime: knownAtTime(t, "2026-04-10T08:00:00Z")},
+		{EventID: 6, EventUUID: "e6", OrgNodeKey: "AAAAAAAC", OrgCode: "OPS", EventType: "DISABLE", EffectiveDate: "2026-06-01", Payload: []byte(`{}`), TxTime: knownAtTime(t, "2026-05-20T08:00:00Z")},
+	}
+}
+
+func TestOrgUnitKnownAtSnapshot_FoldsVersions(t *testing.T) {
+	snap := NewOrgUnitKnownAtSnapshot("t1", knownAtTime(t, "2026-09-01T00:00:00Z"), knownAtFixture(t))
+
+	versions := snap.Versions("AAAAAAAC")
+	if len(versions) != 4 {
+		t.Fatalf("versions=%+v", versions)
+	}
+	if versions[0].EndDate != "2026-03-01" || versions[1].Name != "Operations" || versions[2].ParentOrgNodeKey != "AAAAAAAD" || versions[3].Status != "disabled" || versions[3].EndDate != "" {
+		t.Fatalf("versions=%+v", versions)
+	}
+	if versions[3].ManagerPernr != "7" || versions[3].Name != "Operations" {
+		t.Fatalf("later versions must carry earlier state forward: %+v", versions[3])
+	}
+
+	details, err := snap.Details("AAAAAAAC", "2026-04-15", false)
+	if err != nil || details.ParentOrgCode != "ALT" || details.FullNamePath != "Alt / Operations" || details.EventUUID != "e5" {
+		t.Fatalf("details=%+v err=%v", details, err)
+	}
+	if _, err := snap.Details("AAAAAAAC", "2026-07-01", false); !errors.Is(err, ErrOrgUnitReadNotFound) {
+		t.Fatalf("disabled unit must be hidden without include_disabled, err=%v", err)
+	}
+	if _, err := snap.Details("AAAAAAAC", "2025-12-31", true); !errors.Is(err, ErrOrgUnitReadNotFound) {
+		t.Fatalf("unit must not exist before CREATE, err=%v", err)
+	}
+}
+
+func TestOrgUnitReadService_KnownAt(t *testing.T) {
+	ctx := context.Background()
+	store := &knownAtEventStoreStub{events: knownAtFixture(t)}
+	svc := NewOrgUnitReadService(store)
+	all := OrgUnitReadScopeFilter{AllTenant: true}
+
+	// Before the April move was recorded, the system still showed OPS under ROOT for 2026-04-15.
+	children, err := svc.Children(ctx, OrgUnitChildrenRequest{TenantID: "t-known-at-svc", AsOf: "2026-04-15", ScopeFilter: all, ParentOrgCode: "ROOT", KnownAt: "2026-04-01T00:00:00Z"})
+	if err != nil || len(children) != 1 || children[0].OrgCode != "OPS" {
+		t.Fatalf("children=%+v err=%v", children, err)
+	}
+	children, err = svc.Children(ctx, OrgUnitChildrenRequest{TenantID: "t-known-at-svc", AsOf: "2026-04-15", ScopeFilter: all, ParentOrgCode: "ROOT", KnownAt: "2026-04-11T00:00:00Z"})
+	if err != nil || len(children) != 0 {
+		t.Fatalf("children=%+v err=%v", children, err)
+	}
+
+	roots, err := svc.VisibleRoots(ctx, OrgUnitReadRequest{TenantID: "t-known-at-svc", AsOf: "2026-04-15", ScopeFilter: all, KnownAt: "2026-04-11T00:00:00Z"})
+	if err != nil || len(roots) != 2 || roots[0].OrgCode != "ALT" || !roots[0].HasVisibleChildren || roots[1].HasVisibleChildren {
+		t.Fatalf("roots=%+v err=%v", roots, err)
+	}
+
+	nodes, total, err := svc.List(ctx, OrgUnitListRequest{TenantID: "t-known-at-svc", AsOf: "2026-04-15", ScopeFilter: all, AllOrgUnits: true, Keyword: "oper", KnownAt: "2026-04-11T00:00:00Z"})
+	if err != nil || total != 1 || nodes[0].PathOrgCodes[0] != "ALT" {
+		t.Fatalf("nodes=%+v total=%d err=%v", nodes, total, err)
+	}
+
+	if _, err := svc.VisibleRoots(ctx, OrgUnitReadRequest{TenantID: "t-known-at-svc", AsOf: "2026-04-15", ScopeFilter: all, KnownAt: "yesterday"}); !errors.Is(err, ErrOrgUnitReadKnownAtInvalid) {
+		t.Fatalf("err=%v", err)
+	}
+	plain := NewOrgUnitReadService(&orgUnitReadFakeStore{})
+	if _, err := plain.VisibleRoots(ctx, OrgUnitReadRequest{TenantID: "t1", AsOf: "2026-04-15", ScopeFilter: all, KnownAt: "2026-04-11T00:00:00Z"}); !errors.Is(err, ErrOrgUnitReadKnownAtUnsupported) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestOrgUnitKnownAtCache(t *testing.T) {
+	ctx := context.Background()
+	store := &knownAtEventStoreStub{events: knownAtFixture(t)}
+	cache := NewOrgUnitKnownAtCache(2)
+	now := knownAtTime(t, "2026-10-01T00:00:00Z")
+	cache.now = func() time.Time { return now }
+
+	settled := knownAtTime(t, "2026-04-01T00:00:00Z")
+	first, err := cache.Snapshot(ctx, store, "t1", settled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := cache.Snapshot(ctx, store, "t1", settled)
+	if first != second || store.calls != 1 {
+		t.Fatalf("settled snapshot must be cached, calls=%d", store.calls)
+	}
+	if _, err := cache.Snapshot(ctx, store, "t2", settled); err != nil || store.calls != 2 {
+		t.Fatalf("cache must be keyed by tenant, calls=%d err=%v", store.calls, err)
+	}
+
+	recent := now.Add(-time.Minute)
+	_, _ = cache.Snapshot(ctx, store, "t1", recent)
+	_, _ = cache.Snapshot(ctx, store, "t1", recent)
+	if store.calls != 4 {
+		t.Fatalf("unsettled snapshot must not be cached, calls=%d", store.calls)
+	}
+
+	_, _ = cache.Snapshot(ctx, store, "t3", settled)
+	_, _ = cache.Snapshot(ctx, store, "t1", settled)
+	if store.calls != 6 || len(cache.entries) != 2 {
+		t.Fatalf("oldest snapshot must be evicted, calls=%d entries=%d", store.calls, len(cache.entries))
+	}
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	OrgUnitListFilterOpEq      = "eq"
+	OrgUnitListFilterOpIn      = "in"
+	OrgUnitListFilterOpPrefix  = "prefix"
+	OrgUnitListFilterOpGt      = "gt"
+	OrgUnitListFilterOpGte     = "gte"
+	OrgUnitListFilterOpLt      = "lt"
+	OrgUnitListFilterOpLte     = "lte"
+	OrgUnitListFilterOpBetween = "between"
+	OrgUnitListFilterOpIsNull  = "is_null"
+	OrgUnitListFilterOpNotNull = "not_null"
+
+	orgUnitListFilterMaxDepth      = 4
+	orgUnitListFilterMaxConditions = 32
+	orgUnitListFilterMaxValues     = 100
+)
+
+// OrgUnitListFilterCoreFields are the version columns a filter may name directly; every other field
+// is an ext field key and is checked against the tenant's enabled field configs when the filter is compiled.
+var OrgUnitListFilterCoreFields = []string{"org_code", "name", "status", "is_business_unit"}
+
+var ErrOrgUnitListFilterInvalid = errors.New("orgunit_list_filter_invalid")
+
+// OrgUnitListFilter is one node of a list filter expression: either a group (exactly one of And / Or)
+// or a condition on Field. Scalar values are kept as strings and typed by the store against the field's
+// value type.
+type OrgUnitListFilter struct {
+	And    []OrgUnitListFilter `json:"and,omitempty"`
+	Or     []OrgUnitListFilter `json:"or,omitempty"`
+	Field  string              `json:"field,omitempty"`
+	Op     string              `json:"op,omitempty"`
+	Value  string              `json:"value,omitempty"`
+	Values []string            `json:"values,omitempty"`
+}
+
+type orgUnitListFilterWire struct {
+	And    []json.RawMessage `json:"and"`
+	Or     []json.RawMessage `json:"or"`
+	Field  string            `json:"field"`
+	Op     string            `json:"op"`
+	Value  json.RawMessage   `json:"value"`
+	Values []json.RawMessage `json:"values"`
+}
+
+// ParseOrgUnitListFilter decodes and validates a filter expression, for example
+// {"and":[{"field":"org_type","op":"in","values":["dept","team"]},{"field":"cost_center","op":"prefix","value":"41"}]}.
+func ParseOrgUnitListFilter(raw string) (*OrgUnitListFilter, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	conditions := 0
+	filter, err := parseOrgUnitListFilterNode(json.RawMessage(raw), 1, &conditions)
+	if err != nil {
+		return nil, err
+	}
+	return &filter, nil
+}
+
+func parseOrgUnitListFilterNode(raw json.RawMessage, depth int, conditions *int) (OrgUnitListFilter, error) {
+	if depth > orgUnitListFilterMaxDepth {
+		return OrgUnitListFilter{}, orgUnitListFilterError("nesting deeper than %d", orgUnitListFilterMaxDepth)
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var wire orgUnitListFilterWire
+	if err := dec.Decode(&wire); err != nil {
+		return OrgUnitListFilter{}, orgUnitListFilterError("bad json")
+	}
+
+	isGroup := wire.And != nil || wire.Or != nil
+	isCondition := strings.TrimSpace(wire.Field) != "" || strings.TrimSpace(wire.Op) != ""
+	switch {
+	case wire.And != nil && wire.Or != nil, isGroup && isCondition:
+		return OrgUnitListFilter{}, orgUnitListFilterError("a node is either an and/or group or a condition")
+	case isGroup:
+		members := wire.And
+		if wire.Or != nil {
+			members = wire.Or
+		}
+		if len(members) == 0 {
+			return OrgUnitListFilter{}, orgUnitListFilterError("empty group")
+		}
+		children := make([]OrgUnitListFilter, 0, len(members))
+		for _, member := range members {
+			child, err := parseOrgUnitListFilterNode(member, depth+1, conditions)
+			if err != nil {
+				return OrgUnitListFilter{}, err
+			}
+			children = append(children, child)
+		}
+		if wire.And != nil {
+			return OrgUnitListFilter{And: children}, nil
+		}
+		return OrgUnitListFilter{Or: children}, nil
+	case isCondition:
+		*conditions++
+		if *conditions > orgUnitListFilterMaxConditions {
+			return OrgUnitListFilter{}, orgUnitListFilterError("more than %d conditions", orgUnitListFilterMaxConditions)
+		}
+		return parseOrgUnitListFilterCondition(wire)
+	default:
+		return OrgUnitListFilter{}, orgUnitListFilterError("empty node")
+	}
+}
+
+func parseOrgUnitListFilterCondition(wire orgUnitListFilterWire) (OrgUnitListFilter, error) {
+	out := OrgUnitListFilter{
+		Field: strings.TrimSpace(wire.Field),
+		Op:    strings.ToLower(strings.TrimSpace(wire.Op)),
+	}
+	if out.Field == "" {
+		return OrgUnitListFilter{}, orgUnitListFilterError("field required")
+	}
+
+	hasValue := len(wire.Value) > 0
+	hasValues := wire.Values != nil
+	switch out.Op {
+	case OrgUnitListFilterOpEq, OrgUnitListFilterOpPrefix, OrgUnitListFilterOpGt, OrgUnitListFilterOpGte, OrgUnitListFilterOpLt, OrgUnitListFilterOpLte:
+		if !hasValue || hasValues {
+			return OrgUnitListFilter{}, orgUnitListFilterError("%s on %s needs value", out.Op, out.Field)
+		}
+		value, err := orgUnitListFilterScalar(wire.Value)
+		if err != nil {
+			return OrgUnitListFilter{}, err
+		}
+		if out.Op == OrgUnitListFilterOpPrefix && value == "" {
+			return OrgUnitListFilter{}, orgUnitListFilterError("prefix on %s is empty", out.Field)
+		}
+		out.Value = value
+	case OrgUnitListFilterOpIn, OrgUnitListFilterOpBetween:
+		if hasValue || !hasValues {
+			return OrgUnitListFilter{}, orgUnitListFilterError("%s on %s needs values", out.Op, out.Field)
+		}
+		if len(wire.Values) == 0 || len(wire.Values) > orgUnitListFilterMaxValues {
+			return OrgUnitListFilter{}, orgUnitListFilterError("%s on %s needs 1 to %d values", out.Op, out.Field, orgUnitListFilterMaxValues)
+		}
+		if out.Op == OrgUnitListFilterOpBetween && len(wire.Values) != 2 {
+			return OrgUnitListFilter{}, orgUnitListFilterError("between on %s needs exactly 2 values", out.Field)
+		}
+		out.Values = make([]string, 0, len(wire.Values))
+		for _, item := range wire.Values {
+			value, err := orgUnitListFilterScalar(item)
+			if err != nil {
+				return OrgUnitListFilter{}, err
+			}
+			out.Values = append(out.Values, value)
+		}
+	case OrgUnitListFilterOpIsNull, OrgUnitListFilterOpNotNull:
+		if hasValue || hasValues {
+			return OrgUnitListFilter{}, orgUnitListFilterError("%s on %s takes no value", out.Op, out.Field)
+		}
+	default:
+		return OrgUnitListFilter{}, orgUnitListFilterError("unknown op %q", out.Op)
+	}
+	return out, nil
+}
+
+func orgUnitListFilterScalar(raw json.RawMessage) (string, error) {
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", orgUnitListFilterError("bad value")
+	}
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", orgUnitListFilterError("values must be strings, numbers or booleans")
+	}
+}
+
+func orgUnitListFilterError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrOrgUnitListFilterInvalid, fmt.Sprintf(format, args...))
+}
+
+// IsGroup reports whether the node combines children with And or Or.
+func (f OrgUnitListFilter) IsGroup() bool {
+	return len(f.And) > 0 || len(f.Or) > 0
+}
+
+// Fields lists the distinct fields the expression names, in first-seen order.
+func (f OrgUnitListFilter) Fields() []string {
+	seen := map[string]struct{}{}
+	out := make([]string, 0)
+	var walk func(node OrgUnitListFilter)
+	walk = func(node OrgUnitListFilter) {
+		for _, child := range append(append([]OrgUnitListFilter(nil), node.And...), node.Or...) {
+			walk(child)
+		}
+		if node.Field == "" {
+			return
+		}
+		if _, ok := seen[node.Field]; ok {
+			return
+		}
+		seen[node.Field] = struct{}{}
+		out = append(out, node.Field)
+	}
+	walk(f)
+	return out
+}
+
+// IsOrgUnitListFilterCoreField reports whether field is a version column rather than an ext field key.
+func IsOrgUnitListFilterCoreField(field string) bool {
+	return slices.Contains(OrgUnitListFilterCoreFields, field)
+}
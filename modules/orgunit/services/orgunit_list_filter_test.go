@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseOrgUnitListFilter(t *testing.T) {
+	filter, err := ParseOrgUnitListFilter(`{"and":[
+		{"field":"org_type","op":"in","values":["dept","team"]},
+		{"or":[{"field":"cost_center","op":"prefix","value":"41"},{"field":"location_code","op":"is_null"}]},
+		{"field":"is_business_unit","op":"EQ","value":true},
+		{"field":"headcount","op":"between","values":[10,20.5]}
+	]}`)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want := &OrgUnitListFilter{And: []OrgUnitListFilter{
+		{Field: "org_type", Op: OrgUnitListFilterOpIn, Values: []string{"dept", "team"}},
+		{Or: []OrgUnitListFilter{
+			{Field: "cost_center", Op: OrgUnitListFilterOpPrefix, Value: "41"},
+			{Field: "location_code", Op: OrgUnitListFilterOpIsNull},
+		}},
+		{Field: "is_business_unit", Op: OrgUnitListFilterOpEq, Value: "true"},
+		{Field: "headcount", Op: OrgUnitListFilterOpBetween, Values: []string{"10", "20.5"}},
+	}}
+	if !reflect.DeepEqual(filter, want) {
+		t.Fatalf("filter=%+v", filter)
+	}
+	if got := filter.Fields(); !reflect.DeepEqual(got, []string{"org_type", "cost_center", "location_code", "is_business_unit", "headcount"}) {
+		t.Fatalf("fields=%v", got)
+	}
+
+	if filter, err := ParseOrgUnitListFilter("  "); err != nil || filter != nil {
+		t.Fatalf("empty filter=%+v err=%v", filter, err)
+	}
+}
+
+func TestParseOrgUnitListFilter_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"bad json":          `{`,
+		"unknown key":       `{"field":"name","op":"eq","value":"a","extra":1}`,
+		"empty node":        `{}`,
+		"empty group":       `{"and":[]}`,
+		"group and or":      `{"and":[{"field":"name","op":"is_null"}],"or":[{"field":"name","op":"is_null"}]}`,
+		"group with field":  `{"field":"name","and":[{"field":"name","op":"is_null"}]}`,
+		"missing field":     `{"op":"eq","value":"a"}`,
+		"unknown op":        `{"field":"name","op":"like","value":"a"}`,
+		"eq without value":  `{"field":"name","op":"eq"}`,
+		"eq with values":    `{"field":"name","op":"eq","value":"a","values":["b"]}`,
+		"in without values": `{"field":"name","op":"in","values":[]}`,
+		"between arity":     `{"field":"name","op":"between","values":["a"]}`,
+		"null with value":   `{"field":"name","op":"is_null","value":"a"}`,
+		"empty prefix":      `{"field":"name","op":"prefix","value":" "}`,
+		"object value":      `{"field":"name","op":"eq","value":{"a":1}}`,
+		"too deep":          `{"and":[{"and":[{"and":[{"and":[{"field":"name","op":"is_null"}]}]}]}]}`,
+	}
+	for name, raw := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseOrgUnitListFilter(raw); !errors.Is(err, ErrOrgUnitListFilterInvalid) {
+				t.Fatalf("err=%v", err)
+			}
+		})
+	}
+}
+
+func TestOrgUnitReadServiceList_FilterUsesStorePage(t *testing.T) {
+	store := newOrgUnitReadFakeStore(t)
+	filter := &OrgUnitListFilter{Field: "name", Op: OrgUnitListFilterOpPrefix, Value: "Sales"}
+	_, _, err := NewOrgUnitReadService(store).List(t.Context(), OrgUnitListRequest{
+		TenantID:    "t1",
+		AsOf:        "2026-01-01",
+		ScopeFilter: OrgUnitReadScopeFilter{AllTenant: true},
+		Filter:      filter,
+	})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if store.lastListPageReq.Filter != filter {
+		t.Fatalf("filter not passed to store page: %+v", store.lastListPageReq)
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	OrgUnitListSortCode   = "code"
+	OrgUnitListSortName   = "name"
+	OrgUnitListSortStatus = "status"
+)
+
+// OrgUnitListKeyset is a position in a keyset-paged list. Keyset pages are ordered by the sort value
+// (NULLs last in both directions) and then by org_node_key ascending, and resume strictly after the
+// position, so rows inserted or moved between requests never shift later pages. A zero value asks for
+// the first page.
+type OrgUnitListKeyset struct {
+	SortValue  *string
+	OrgNodeKey string
+}
+
+// IsStart reports whether the keyset asks for the first page.
+func (k OrgUnitListKeyset) IsStart() bool {
+	return strings.TrimSpace(k.OrgNodeKey) == ""
+}
+
+// OrgUnitListKeysetOf is the keyset position of node in a list sorted by sortField. Stores that sort on
+// something other than a core field (ext sorts) report the value through OrgUnitReadNode.SortValue.
+func OrgUnitListKeysetOf(node OrgUnitReadNode, sortField string) OrgUnitListKeyset {
+	return OrgUnitListKeyset{SortValue: orgUnitListSortValue(node, sortField), OrgNodeKey: strings.TrimSpace(node.OrgNodeKey)}
+}
+
+func orgUnitListSortValue(node OrgUnitReadNode, sortField string) *string {
+	if node.SortValue != nil {
+		value := *node.SortValue
+		return &value
+	}
+	var value string
+	switch strings.ToLower(strings.TrimSpace(sortField)) {
+	case OrgUnitListSortName:
+		value = node.Name
+	case OrgUnitListSortStatus:
+		value = normalizeOrgUnitListStatus(node.Status)
+	default:
+		value = node.OrgCode
+	}
+	return &value
+}
+
+// PageReadNodesByKeyset is the in-memory counterpart of the store keyset query: it orders nodes by
+// (sort value, org_node_key), drops everything up to and including after, and keeps at most limit nodes.
+func PageReadNodesByKeyset(nodes []OrgUnitReadNode, sortField string, sortOrder string, after OrgUnitListKeyset, limit int) []OrgUnitReadNode {
+	desc := strings.EqualFold(strings.TrimSpace(sortOrder), "desc")
+	ordered := append([]OrgUnitReadNode(nil), nodes...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return compareOrgUnitListKeyset(OrgUnitListKeysetOf(ordered[i], sortField), OrgUnitListKeysetOf(ordered[j], sortField), desc) < 0
+	})
+	out := make([]OrgUnitReadNode, 0, len(ordered))
+	for _, node := range ordered {
+		if !after.IsStart() && compareOrgUnitListKeyset(OrgUnitListKeysetOf(node, sortField), after, desc) <= 0 {
+			continue
+		}
+		out = append(out, node)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out
+}
+
+func compareOrgUnitListKeyset(a OrgUnitListKeyset, b OrgUnitListKeyset, desc bool) int {
+	switch {
+	case a.SortValue == nil && b.SortValue != nil:
+		return 1
+	case a.SortValue != nil && b.SortValue == nil:
+		return -1
+	case a.SortValue != nil && b.SortValue != nil:
+		if cmp := strings.Compare(*a.SortValue, *b.SortValue); cmp != 0 {
+			if desc {
+				return -cmp
+			}
+			return cmp
+		}
+	}
+	return strings.Compare(a.OrgNodeKey, b.OrgNodeKey)
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func keysetTestCodes(nodes []OrgUnitReadNode) []string {
+	out := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		out = append(out, node.OrgCode)
+	}
+	return out
+}
+
+func TestPageReadNodesByKeysetWalksPagesWithoutOverlap(t *testing.T) {
+	nodes := []OrgUnitReadNode{
+		{OrgNodeKey: "AAAAAAAD", OrgCode: "D", Name: "Beta"},
+		{OrgNodeKey: "AAAAAAAA", OrgCode: "A", Name: "Beta"},
+		{OrgNodeKey: "AAAAAAAC", OrgCode: "C", Name: "Alpha"},
+		{OrgNodeKey: "AAAAAAAB", OrgCode: "B", Name: "Gamma"},
+	}
+
+	first := PageReadNodesByKeyset(nodes, OrgUnitListSortName, "asc", OrgUnitListKeyset{}, 2)
+	if got := keysetTestCodes(first); !reflect.DeepEqual(got, []string{"C", "A"}) {
+		t.Fatalf("first page=%v", got)
+	}
+	after := OrgUnitListKeysetOf(first[len(first)-1], OrgUnitListSortName)
+	second := PageReadNodesByKeyset(nodes, OrgUnitListSortName, "asc", after, 2)
+	if got := keysetTestCodes(second); !reflect.DeepEqual(got, []string{"D", "B"}) {
+		t.Fatalf("second page=%v", got)
+	}
+
+	// A row inserted before the cursor position must not shift the next page.
+	inserted := append(append([]OrgUnitReadNode(nil), nodes...), OrgUnitReadNode{OrgNodeKey: "AAAAAAAE", OrgCode: "E", Name: "Aardvark"})
+	if got := keysetTestCodes(PageReadNodesByKeyset(inserted, OrgUnitListSortName, "asc", after, 2)); !reflect.DeepEqual(got, []string{"D", "B"}) {
+		t.Fatalf("second page after insert=%v", got)
+	}
+}
+
+func TestPageReadNodesByKeysetDescOrderAndNullsLast(t *testing.T) {
+	high, low := "9", "1"
+	nodes := []OrgUnitReadNode{
+		{OrgNodeKey: "AAAAAAAB", OrgCode: "B", Name: "Beta"},
+		{OrgNodeKey: "AAAAAAAC", OrgCode: "LOW", SortValue: &low},
+		{OrgNodeKey: "AAAAAAAD", OrgCode: "HIGH", SortValue: &high},
+	}
+	got := keysetTestCodes(PageReadNodesByKeyset(nodes, OrgUnitListSortName, "desc", OrgUnitListKeyset{}, 0))
+	if !reflect.DeepEqual(got, []string{"B", "HIGH", "LOW"}) {
+		t.Fatalf("order=%v", got)
+	}
+
+	nullAfter := OrgUnitListKeyset{OrgNodeKey: "AAAAAAAA"}
+	for _, desc := range []bool{false, true} {
+		if cmp := compareOrgUnitListKeyset(OrgUnitListKeyset{SortValue: &low, OrgNodeKey: "AAAAAAAZ"}, nullAfter, desc); cmp >= 0 {
+			t.Fatalf("desc=%v value should sort before null, cmp=%d", desc, cmp)
+		}
+		if cmp := compareOrgUnitListKeyset(OrgUnitListKeyset{OrgNodeKey: "AAAAAAAB"}, nullAfter, desc); cmp <= 0 {
+			t.Fatalf("desc=%v null rows should order by node key, cmp=%d", desc, cmp)
+		}
+	}
+}
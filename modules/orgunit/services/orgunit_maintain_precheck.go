@@ -60,6 +60,7 @@ type OrgUnitMaintainPrecheckProjectionV1 struct {
 	PendingDraftSummary               string                           `json:"pending_draft_summary"`
 	PolicyExplain                     string                           `json:"policy_explain"`
 	RejectionReasons                  []string                         `json:"rejection_reasons"`
+	FieldErrors                       []OrgUnitFieldValidationErrorV1  `json:"field_errors"`
 	ProjectionDigest                  string                           `json:"projection_digest"`
 }
 
@@ -159,6 +160,7 @@ func evaluateOrgUnitMaintainPrecheckV1(
 			MissingFields:                     []string{},
 			FieldDecisions:                    []OrgUnitMaintainFieldDecisionV1{},
 			RejectionReasons:                  []string{},
+			FieldErrors:                       []OrgUnitFieldValidationErrorV1{},
 		},
 	}
 
@@ -206,6 +208,17 @@ func evaluateOrgUnitMaintainPrecheckV1(
 		}
 		eval.ParentDecision = parentDecision
 		eval.ParentFound = parentFound
+
+		if normalizedInput.Intent == OrgUnitMaintainIntentCorrect && normalizedInput.TargetEffectiveDate != "" {
+			fieldErrors, err := resolveOrgUnitMaintainFieldValidationErrors(ctx, reader, normalizedInput, eval.Result.PolicyContext.OrgNodeKey)
+			if err != nil {
+				return orgUnitMaintainPrecheckEvaluation{}, err
+			}
+			eval.Result.Projection.FieldErrors = fieldErrors
+			if len(fieldErrors) > 0 {
+				eval.Result.Projection.RejectionReasons = append(eval.Result.Projection.RejectionReasons, errFieldValidationFailed)
+			}
+		}
 	}
 
 	mutationDecision, targetEvent, err := resolveOrgUnitMaintainMutationDecision(ctx, reader, normalizedInput, eval.Result.PolicyContext.OrgNodeKey, treeInitialized, targetExistsAsOf, enabledExtFieldKeys)
@@ -250,6 +263,40 @@ func orgUnitMaintainPolicyAsOf(input OrgUnitMaintainPrecheckInputV1) string {
 	return strings.TrimSpace(input.EffectiveDate)
 }
 
+// resolveOrgUnitMaintainFieldValidationErrors validates a correction the way Write does: the corrected
+// version is read as of target_effective_date and the rules apply as of the (possibly moved) effective date.
+func resolveOrgUnitMaintainFieldValidationErrors(
+	ctx context.Context,
+	reader OrgUnitMaintainPrecheckReader,
+	input OrgUnitMaintainPrecheckInputV1,
+	orgNodeKey string,
+) ([]OrgUnitFieldValidationErrorV1, error) {
+	asOf := input.EffectiveDate
+	if asOf == "" {
+		asOf = input.TargetEffectiveDate
+	}
+	parentNodeKey, err := resolvePrecheckFieldValidationParentNodeKey(ctx, reader, input.TenantID, input.NewParentOrgCode)
+	if err != nil {
+		return nil, err
+	}
+	patch := map[string]any{}
+	if input.NewName != "" {
+		patch["name"] = input.NewName
+	}
+	if input.NewParentOrgCode != "" {
+		patch["parent_org_code"] = input.NewParentOrgCode
+	}
+	return resolvePrecheckFieldValidationErrors(ctx, reader, input.TenantID, orgUnitFieldValidationTarget{
+		Intent:        string(OrgUnitWriteIntentCorrect),
+		AsOf:          asOf,
+		RecordAsOf:    input.TargetEffectiveDate,
+		OrgCode:       input.OrgCode,
+		OrgNodeKey:    orgNodeKey,
+		ParentNodeKey: parentNodeKey,
+		Patch:         patch,
+	})
+}
+
 func resolveOrgUnitMaintainEnabledFieldConfigs(
 	ctx context.Context,
 	reader OrgUnitMaintainPrecheckReader,
@@ -793,6 +840,7 @@ func buildOrgUnitMaintainProjectionDigest(projection OrgUnitMaintainPrecheckProj
 		PendingDraftSummary               string                           `json:"pending_draft_summary"`
 		PolicyExplain                     string                           `json:"policy_explain"`
 		RejectionReasons                  []string                         `json:"rejection_reasons"`
+		FieldErrors                       []OrgUnitFieldValidationErrorV1  `json:"field_errors"`
 	}{
 		Readiness:                         strings.TrimSpace(projection.Readiness),
 		MissingFields:                     append([]string(nil), projection.MissingFields...),
@@ -801,6 +849,7 @@ func buildOrgUnitMaintainProjectionDigest(projection OrgUnitMaintainPrecheckProj
 		PendingDraftSummary:               strings.TrimSpace(projection.PendingDraftSummary),
 		PolicyExplain:                     strings.TrimSpace(projection.PolicyExplain),
 		RejectionReasons:                  append([]string(nil), projection.RejectionReasons...),
+		FieldErrors:                       cloneOrgUnitFieldValidationErrors(projection.FieldErrors),
 	}
 	return orgUnitMaintainDigest(payload)
 }
@@ -833,6 +882,7 @@ func CloneOrgUnitMaintainProjectionV1(projection OrgUnitMaintainPrecheckProjecti
 		PendingDraftSummary:               strings.TrimSpace(projection.PendingDraftSummary),
 		PolicyExplain:                     strings.TrimSpace(projection.PolicyExplain),
 		RejectionReasons:                  append([]string(nil), projection.RejectionReasons...),
+		FieldErrors:                       cloneOrgUnitFieldValidationErrors(projection.FieldErrors),
 		ProjectionDigest:                  strings.TrimSpace(projection.ProjectionDigest),
 	}
 }
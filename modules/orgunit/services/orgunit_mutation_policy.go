@@ -18,6 +18,8 @@ const (
 	OrgUnitActionCorrectStatus OrgUnitActionKind = "correct_status"
 	OrgUnitActionRescindEvent  OrgUnitActionKind = "rescind_event"
 	OrgUnitActionRescindOrg    OrgUnitActionKind = "rescind_org"
+	OrgUnitActionMerge         OrgUnitActionKind = "merge"
+	OrgUnitActionSplit         OrgUnitActionKind = "split"
 )
 
 type OrgUnitEmittedEventType string
@@ -33,6 +35,8 @@ const (
 	OrgUnitEmittedCorrectStatus   OrgUnitEmittedEventType = "CORRECT_STATUS"
 	OrgUnitEmittedRescindEvent    OrgUnitEmittedEventType = "RESCIND_EVENT"
 	OrgUnitEmittedRescindOrg      OrgUnitEmittedEventType = "RESCIND_ORG"
+	OrgUnitEmittedMerge           OrgUnitEmittedEventType = "MERGE"
+	OrgUnitEmittedSplit           OrgUnitEmittedEventType = "SPLIT"
 )
 
 type OrgUnitMutationPolicyKey struct {
@@ -45,6 +49,7 @@ type OrgUnitMutationPolicyFacts struct {
 	CanAdmin              bool
 	EnabledExtFieldKeys   []string
 	RescindOrgDenyReasons []string
+	CompositeDenyReasons  []string
 
 	// Append-only facts.
 	TreeInitialized  bool
@@ -189,6 +194,38 @@ func ResolvePolicy(key OrgUnitMutationPolicyKey, facts OrgUnitMutationPolicyFact
 			Enabled:     facts.CanAdmin && len(deny) == 0,
 			DenyReasons: deny,
 		}, nil
+
+	case key.ActionKind == OrgUnitActionMerge && key.EmittedEventType == OrgUnitEmittedMerge && key.TargetEffectiveEventType == nil,
+		key.ActionKind == OrgUnitActionSplit && key.EmittedEventType == OrgUnitEmittedSplit && key.TargetEffectiveEventType == nil:
+		deny := append([]string(nil), facts.CompositeDenyReasons...)
+		if !facts.CanAdmin {
+			deny = append(deny, "FORBIDDEN")
+		}
+		if !facts.TreeInitialized {
+			deny = append(deny, "ORG_TREE_NOT_INITIALIZED")
+		}
+		if !facts.TargetExistsAsOf {
+			deny = append(deny, "ORG_NOT_FOUND_AS_OF")
+		}
+		allowed := []string{"effective_date", "source_org_codes", "survivor_org_code"}
+		rootReason := "ORG_ROOT_CANNOT_BE_MERGED"
+		if key.ActionKind == OrgUnitActionSplit {
+			allowed = []string{"disable_source", "effective_date", "new_units", "source_org_code"}
+			rootReason = "ORG_ROOT_CANNOT_BE_SPLIT"
+		}
+		if facts.IsRoot {
+			deny = append(deny, rootReason)
+		}
+		deny = dedupAndSortDenyReasons(deny)
+		enabled := len(deny) == 0
+		if !enabled {
+			allowed = []string{}
+		}
+		return OrgUnitMutationPolicyDecision{
+			Enabled:       enabled,
+			AllowedFields: allowed,
+			DenyReasons:   deny,
+		}, nil
 	default:
 		return OrgUnitMutationPolicyDecision{}, errors.New("orgunit mutation policy: invalid key")
 	}
@@ -451,6 +488,10 @@ func denyReasonPriority(code string) int {
 		return 30
 	case "ORG_ROOT_CANNOT_BE_MOVED":
 		return 40
+	case "ORG_ROOT_CANNOT_BE_MERGED", "ORG_ROOT_CANNOT_BE_SPLIT":
+		return 41
+	case "ORG_MERGE_SURVIVOR_INVALID", "ORG_SPLIT_CHILD_NOT_UNDER_SOURCE":
+		return 42
 	case "ORG_ALREADY_EXISTS":
 		return 50
 	case "ORG_ROOT_ALREADY_EXISTS":
@@ -590,3 +590,34 @@ func keysOf(m map[string]string) []string {
 	sort.Strings(out)
 	return out
 }
+
+func TestResolvePolicy_Composite(t *testing.T) {
+	decision, err := ResolvePolicy(OrgUnitMutationPolicyKey{
+		ActionKind:       OrgUnitActionMerge,
+		EmittedEventType: OrgUnitEmittedMerge,
+	}, OrgUnitMutationPolicyFacts{CanAdmin: true, TreeInitialized: true, TargetExistsAsOf: true})
+	if err != nil || !decision.Enabled || join(decision.AllowedFields) != "effective_date,source_org_codes,survivor_org_code" {
+		t.Fatalf("decision=%+v err=%v", decision, err)
+	}
+
+	decision, err = ResolvePolicy(OrgUnitMutationPolicyKey{
+		ActionKind:       OrgUnitActionSplit,
+		EmittedEventType: OrgUnitEmittedSplit,
+	}, OrgUnitMutationPolicyFacts{
+		CanAdmin:             false,
+		TreeInitialized:      true,
+		TargetExistsAsOf:     true,
+		IsRoot:               true,
+		CompositeDenyReasons: []string{"ORG_SPLIT_CHILD_NOT_UNDER_SOURCE"},
+	})
+	if err != nil || decision.Enabled || len(decision.AllowedFields) != 0 {
+		t.Fatalf("decision=%+v err=%v", decision, err)
+	}
+	if join(decision.DenyReasons) != "FORBIDDEN,ORG_ROOT_CANNOT_BE_SPLIT,ORG_SPLIT_CHILD_NOT_UNDER_SOURCE" {
+		t.Fatalf("deny=%v", decision.DenyReasons)
+	}
+
+	if _, err := ResolvePolicy(OrgUnitMutationPolicyKey{ActionKind: OrgUnitActionMerge, EmittedEventType: OrgUnitEmittedSplit}, OrgUnitMutationPolicyFacts{}); err == nil {
+		t.Fatal("mismatched composite key must be invalid")
+	}
+}
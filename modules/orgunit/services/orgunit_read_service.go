@@ -59,6 +59,7 @@ type OrgUnitReadRequest struct {
 	AsOf            string
 	ScopeFilter     OrgUnitReadScopeFilter
 	IncludeDisabled bool
+	KnownAt         string
 	Caller          string
 }
 
@@ -78,9 +79,12 @@ type OrgUnitListRequest struct {
 	SortOrder          string
 	ExtFilterFieldKey  string
 	ExtFilterValue     string
+	Filter             *OrgUnitListFilter
 	IncludeDisabled    bool
 	Limit              int
 	Offset             int
+	Keyset             *OrgUnitListKeyset
+	KnownAt            string
 	Caller             string
 }
 
@@ -100,9 +104,11 @@ type OrgUnitReadListPageRequest struct {
 	SortOrder          string
 	ExtFilterFieldKey  string
 	ExtFilterValue     string
+	Filter             *OrgUnitListFilter
 	IncludeDisabled    bool
 	Limit              int
 	Offset             int
+	Keyset             *OrgUnitListKeyset
 }
 
 type OrgUnitChildrenRequest struct {
@@ -112,6 +118,7 @@ type OrgUnitChildrenRequest struct {
 	ParentOrgCode    string
 	ParentOrgNodeKey string
 	IncludeDisabled  bool
+	KnownAt          string
 	Caller           string
 }
 
@@ -122,6 +129,8 @@ type OrgUnitSearchRequest struct {
 	Query           string
 	IncludeDisabled bool
 	Limit           int
+	Keyset          *OrgUnitListKeyset
+	KnownAt         string
 	Caller          string
 }
 
@@ -132,6 +141,7 @@ type OrgUnitResolveRequest struct {
 	OrgCodes        []string
 	OrgNodeKeys     []string
 	IncludeDisabled bool
+	KnownAt         string
 	Caller          string
 }
 
@@ -144,6 +154,9 @@ type OrgUnitReadNode struct {
 	HasVisibleChildren bool
 	PathOrgCodes       []string
 	PathOrgNodeKeys    []string
+	SortValue          *string
+	FullNamePath       string
+	SearchMatch        *orgunitpkg.SearchMatch
 }
 
 type orgUnitReadService struct {
@@ -158,6 +171,10 @@ func (s orgUnitReadService) List(ctx context.Context, req OrgUnitListRequest) ([
 	if err := validateOrgUnitReadBase(req.TenantID, req.AsOf); err != nil {
 		return nil, 0, err
 	}
+	s, err := s.forKnownAt(ctx, req.TenantID, req.KnownAt)
+	if err != nil {
+		return nil, 0, err
+	}
 	tenantHasOrgData := true
 	if !req.ScopeFilter.AllTenant && len(normalizeReadScopes(req.ScopeFilter.Scopes)) == 0 {
 		hasOrgData, err := s.tenantHasOrgData(ctx, req.TenantID, req.AsOf, req.IncludeDisabled)
@@ -186,7 +203,6 @@ func (s orgUnitReadService) List(ctx context.Context, req OrgUnitListRequest) ([
 	}
 
 	var nodes []OrgUnitReadNode
-	var err error
 	if strings.TrimSpace(req.ParentOrgNodeKey) != "" || strings.TrimSpace(req.ParentOrgCode) != "" {
 		nodes, err = s.Children(ctx, OrgUnitChildrenRequest{
 			TenantID:         req.TenantID,
@@ -217,6 +233,9 @@ func (s orgUnitReadService) List(ctx context.Context, req OrgUnitListRequest) ([
 		sortReadNodesForList(nodes, req.SortField, req.SortOrder)
 	}
 	total := len(nodes)
+	if req.Keyset != nil {
+		return PageReadNodesByKeyset(nodes, req.SortField, req.SortOrder, *req.Keyset, req.Limit), total, nil
+	}
 	return paginateReadNodes(nodes, req.Limit, req.Offset), total, nil
 }
 
@@ -231,12 +250,14 @@ func (req OrgUnitListRequest) shouldUseStorePage() bool {
 		strings.TrimSpace(req.SortField) != "" ||
 		strings.TrimSpace(req.ExtSortFieldKey) != "" ||
 		strings.TrimSpace(req.ExtFilterFieldKey) != "" ||
+		req.Filter != nil ||
+		req.Keyset != nil ||
 		req.Limit > 0 ||
 		req.Offset > 0
 }
 
 func (req OrgUnitListRequest) hasExtQuery() bool {
-	return strings.TrimSpace(req.ExtFilterFieldKey) != "" || strings.TrimSpace(req.ExtSortFieldKey) != ""
+	return strings.TrimSpace(req.ExtFilterFieldKey) != "" || strings.TrimSpace(req.ExtSortFieldKey) != "" || req.Filter != nil
 }
 
 func (s orgUnitReadService) listWithStorePage(ctx context.Context, req OrgUnitListRequest, tenantHasOrgData bool) ([]OrgUnitReadNode, int, error) {
@@ -285,10 +306,12 @@ func (s orgUnitReadService) listWithStorePage(ctx context.Context, req OrgUnitLi
 		SortOrder:          req.SortOrder,
 		ExtFilterFieldKey:  strings.TrimSpace(req.ExtFilterFieldKey),
 		ExtFilterValue:     req.ExtFilterValue,
+		Filter:             req.Filter,
 		IncludeDisabled:    req.IncludeDisabled,
 	}
 	storeReq.Limit = req.Limit
 	storeReq.Offset = req.Offset
+	storeReq.Keyset = req.Keyset
 
 	nodes, total, err := pageStore.ListPage(ctx, storeReq)
 	if err != nil {
@@ -328,8 +351,24 @@ func (s orgUnitReadService) listRange(ctx context.Context, req OrgUnitListReques
 	if err != nil {
 		return nil, 0, err
 	}
+	total := len(descendants) + 1
+	if req.Keyset != nil {
+		// The anchor leads the first keyset page; later pages only walk the descendants.
+		after := *req.Keyset
+		limit := req.Limit
+		page := []OrgUnitReadNode{}
+		if after.IsStart() {
+			page = append(page, anchor)
+			limit--
+		} else if strings.TrimSpace(after.OrgNodeKey) == strings.TrimSpace(anchor.OrgNodeKey) {
+			after = OrgUnitListKeyset{}
+		}
+		if req.Limit <= 0 || limit > 0 {
+			page = append(page, PageReadNodesByKeyset(descendants, req.SortField, req.SortOrder, after, max(limit, 0))...)
+		}
+		return page, total, nil
+	}
 	nodes := append([]OrgUnitReadNode{anchor}, descendants...)
-	total := len(nodes)
 	return paginateReadNodes(nodes, req.Limit, req.Offset), total, nil
 }
 
@@ -341,6 +380,7 @@ func (s orgUnitReadService) listFilteredRangeDescendants(ctx context.Context, re
 	rangeFilter.AllOrgUnits = true
 	rangeFilter.Limit = 0
 	rangeFilter.Offset = 0
+	rangeFilter.Keyset = nil
 
 	var nodes []OrgUnitReadNode
 	var err error
@@ -387,6 +427,10 @@ func (s orgUnitReadService) VisibleRoots(ctx context.Context, req OrgUnitReadReq
 	if err := validateOrgUnitReadBase(req.TenantID, req.AsOf); err != nil {
 		return nil, err
 	}
+	s, err := s.forKnownAt(ctx, req.TenantID, req.KnownAt)
+	if err != nil {
+		return nil, err
+	}
 	if req.ScopeFilter.AllTenant {
 		roots, err := s.store.ListRoots(ctx, req.TenantID, req.AsOf, req.IncludeDisabled)
 		if err != nil {
@@ -417,6 +461,10 @@ func (s orgUnitReadService) Children(ctx context.Context, req OrgUnitChildrenReq
 	if err := validateOrgUnitReadBase(req.TenantID, req.AsOf); err != nil {
 		return nil, err
 	}
+	s, err := s.forKnownAt(ctx, req.TenantID, req.KnownAt)
+	if err != nil {
+		return nil, err
+	}
 	parent, err := s.resolveParent(ctx, req)
 	if err != nil {
 		return nil, err
@@ -436,6 +484,10 @@ func (s orgUnitReadService) Search(ctx context.Context, req OrgUnitSearchRequest
 	if err := validateOrgUnitReadBase(req.TenantID, req.AsOf); err != nil {
 		return nil, err
 	}
+	s, err := s.forKnownAt(ctx, req.TenantID, req.KnownAt)
+	if err != nil {
+		return nil, err
+	}
 	query := strings.TrimSpace(req.Query)
 	if query == "" {
 		return nil, ErrOrgUnitReadInvalidArgument
@@ -451,7 +503,7 @@ func (s orgUnitReadService) Search(ctx context.Context, req OrgUnitSearchRequest
 		return nil, err
 	}
 	searchLimit := req.Limit
-	if !req.ScopeFilter.AllTenant {
+	if !req.ScopeFilter.AllTenant || req.Keyset != nil {
 		searchLimit = -1
 	}
 	candidates, err := s.store.Search(ctx, req.TenantID, query, req.AsOf, req.IncludeDisabled, searchLimit)
@@ -462,6 +514,9 @@ func (s orgUnitReadService) Search(ctx context.Context, req OrgUnitSearchRequest
 	if err != nil {
 		return nil, err
 	}
+	if req.Keyset != nil {
+		return PageReadNodesByKeyset(out, OrgUnitListSortCode, "", *req.Keyset, req.Limit), nil
+	}
 	return limitReadNodes(out, req.Limit), nil
 }
 
@@ -469,6 +524,10 @@ func (s orgUnitReadService) Resolve(ctx context.Context, req OrgUnitResolveReque
 	if err := validateOrgUnitReadBase(req.TenantID, req.AsOf); err != nil {
 		return nil, err
 	}
+	s, err := s.forKnownAt(ctx, req.TenantID, req.KnownAt)
+	if err != nil {
+		return nil, err
+	}
 	roots, err := s.VisibleRoots(ctx, OrgUnitReadRequest{
 		TenantID:        req.TenantID,
 		AsOf:            req.AsOf,
@@ -7,12 +7,9 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/google/cel-go/cel"
-	celtypes "github.com/google/cel-go/common/types"
-	"github.com/google/cel-go/common/types/ref"
 	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/fieldmeta"
 	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/ports"
 	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
@@ -58,7 +55,6 @@ var (
 	resolveDictLabelInWrite             = func(ctx context.Context, tenantID string, asOf string, dictCode string, code string) (string, bool, error) {
 		return globalDictResolver{}.ResolveValueLabel(ctx, tenantID, asOf, dictCode, code)
 	}
-	celCompileCache   sync.Map
 	nextOrgCodeRuleRe = regexp.MustCompile(`^next_org_code\(\s*"([^"]*)"\s*,\s*([0-9]+)\s*\)$`)
 )
 
@@ -79,6 +75,8 @@ type OrgUnitWriteService interface {
 	CorrectStatus(ctx context.Context, tenantID string, req CorrectStatusOrgUnitRequest) (types.OrgUnitResult, error)
 	RescindRecord(ctx context.Context, tenantID string, req RescindRecordOrgUnitRequest) (types.OrgUnitResult, error)
 	RescindOrg(ctx context.Context, tenantID string, req RescindOrgUnitRequest) (types.OrgUnitResult, error)
+	Merge(ctx context.Context, tenantID string, req MergeOrgUnitsRequest) (types.OrgUnitResult, error)
+	Split(ctx context.Context, tenantID string, req SplitOrgUnitRequest) (types.OrgUnitResult, error)
 }
 
 type OrgUnitWriteIntent string
@@ -207,7 +205,8 @@ type OrgUnitCorrectionPatch struct {
 }
 
 type orgUnitWriteService struct {
-	store ports.OrgUnitWriteStore
+	store    ports.OrgUnitWriteStore
+	managers ports.ManagerDirectory
 }
 
 type orgUnitRequestIDEventReader interface {
@@ -232,9 +231,15 @@ type orgUnitCreateAutoCodeSubmitter interface {
 	) (int64, string, error)
 }
 
+// orgUnitRuleRuntimeContext is what a default rule can see while it is evaluated: the candidate record,
+// the parent unit, the effective date, the initiator and tenant dict labels.
 type orgUnitRuleRuntimeContext struct {
-	tenantID      string
-	effectiveDate string
+	tenantID         string
+	effectiveDate    string
+	initiatorUUID    string
+	record           map[string]any
+	parent           map[string]any
+	resolveDictLabel func(dictCode string, code string) (string, bool, error)
 }
 
 type orgUnitAutoCodeSpec struct {
@@ -262,6 +267,14 @@ func (r createOrgUnitPrecheckWriteStoreReader) ListEnabledTenantFieldConfigsAsOf
 	return r.store.ListEnabledTenantFieldConfigsAsOf(ctx, tenantID, asOf)
 }
 
+func (r createOrgUnitPrecheckWriteStoreReader) GetOrgUnitValidationRecordAsOf(ctx context.Context, tenantID string, orgNodeKey string, asOf string) (types.OrgUnitValidationRecord, bool, error) {
+	reader, ok := r.store.(orgUnitValidationRecordReader)
+	if !ok {
+		return types.OrgUnitValidationRecord{}, false, nil
+	}
+	return reader.GetOrgUnitValidationRecordAsOf(ctx, tenantID, orgNodeKey, asOf)
+}
+
 const (
 	orgUnitNextOrgCodeFuncName  = "next_org_code"
 	orgUnitDefaultOrgCodePrefix = "O"
@@ -286,6 +299,12 @@ func NewOrgUnitWriteService(store ports.OrgUnitWriteStore) OrgUnitWriteService {
 	return &orgUnitWriteService{store: store}
 }
 
+// NewOrgUnitWriteServiceWithManagerDirectory validates manager_pernr against managers on every write that
+// sets a manager; without a directory only the pernr format is checked.
+func NewOrgUnitWriteServiceWithManagerDirectory(store ports.OrgUnitWriteStore, managers ports.ManagerDirectory) OrgUnitWriteService {
+	return &orgUnitWriteService{store: store, managers: managers}
+}
+
 func (s *orgUnitWriteService) Write(ctx context.Context, tenantID string, req WriteOrgUnitRequest) (OrgUnitWriteResult, error) {
 	intent := strings.TrimSpace(req.Intent)
 	if intent == "" {
@@ -396,7 +415,7 @@ func (s *orgUnitWriteService) Write(ctx context.Context, tenantID string, req Wr
 			fields["manager_pernr"] = ""
 			fields["manager_name"] = ""
 		} else {
-			pernr, managerUUID, managerName, err := s.resolveManager(pernrInput)
+			pernr, managerUUID, managerName, err := s.resolveManager(ctx, tenantID, pernrInput, effectiveDate)
 			if err != nil {
 				return OrgUnitWriteResult{}, err
 			}
@@ -406,6 +425,7 @@ func (s *orgUnitWriteService) Write(ctx context.Context, tenantID string, req Wr
 			payload["manager_pernr"] = pernr
 			fields["manager_pernr"] = pernr
 			if managerName != "" {
+				payload["manager_name"] = managerName
 				fields["manager_name"] = managerName
 			}
 		}
@@ -443,6 +463,16 @@ func (s *orgUnitWriteService) Write(ctx context.Context, tenantID string, req Wr
 		if _, ok := payload["name"]; !ok {
 			return OrgUnitWriteResult{}, httperr.NewBadRequest("name is required")
 		}
+		parentNodeKey, _ := payload["parent_org_node_key"].(string)
+		if err := s.validateWriteFields(ctx, tenantID, orgUnitFieldValidationTarget{
+			Intent:        intent,
+			AsOf:          effectiveDate,
+			OrgCode:       orgCode,
+			ParentNodeKey: parentNodeKey,
+			Patch:         fields,
+		}); err != nil {
+			return OrgUnitWriteResult{}, err
+		}
 
 		payloadJSON, err := marshalJSON(payload)
 		if err != nil {
@@ -500,6 +530,17 @@ func (s *orgUnitWriteService) Write(ctx context.Context, tenantID string, req Wr
 			}
 			return OrgUnitWriteResult{}, err
 		}
+		parentNodeKey, _ := payload["parent_org_node_key"].(string)
+		if err := s.validateWriteFields(ctx, tenantID, orgUnitFieldValidationTarget{
+			Intent:        intent,
+			AsOf:          effectiveDate,
+			OrgCode:       orgCode,
+			OrgNodeKey:    orgNodeKey,
+			ParentNodeKey: parentNodeKey,
+			Patch:         fields,
+		}); err != nil {
+			return OrgUnitWriteResult{}, err
+		}
 
 		payloadJSON, err := marshalJSON(payload)
 		if err != nil {
@@ -539,6 +580,18 @@ func (s *orgUnitWriteService) Write(ctx context.Context, tenantID string, req Wr
 		if len(payload) == 0 {
 			return OrgUnitWriteResult{}, httperr.NewBadRequest(errPatchRequired)
 		}
+		parentNodeKey, _ := payload["parent_org_node_key"].(string)
+		if err := s.validateWriteFields(ctx, tenantID, orgUnitFieldValidationTarget{
+			Intent:        intent,
+			AsOf:          effectiveDate,
+			RecordAsOf:    targetDate,
+			OrgCode:       orgCode,
+			OrgNodeKey:    orgNodeKey,
+			ParentNodeKey: parentNodeKey,
+			Patch:         fields,
+		}); err != nil {
+			return OrgUnitWriteResult{}, err
+		}
 
 		patchJSON, err := marshalJSON(payload)
 		if err != nil {
@@ -607,7 +660,7 @@ func (s *orgUnitWriteService) Create(ctx context.Context, tenantID string, req C
 	var managerPernr string
 	var managerName string
 	if strings.TrimSpace(req.ManagerPernr) != "" {
-		managerPernr, managerUUID, managerName, err = s.resolveManager(req.ManagerPernr)
+		managerPernr, managerUUID, managerName, err = s.resolveManager(ctx, tenantID, req.ManagerPernr, effectiveDate)
 		if err != nil {
 			return types.OrgUnitResult{}, err
 		}
@@ -644,6 +697,7 @@ func (s *orgUnitWriteService) Create(ctx context.Context, tenantID string, req C
 	if managerUUID != "" {
 		payload["manager_uuid"] = managerUUID
 		payload["manager_pernr"] = managerPernr
+		payload["manager_name"] = managerName
 	}
 
 	if len(req.Ext) > 0 {
@@ -1337,7 +1391,12 @@ func (s *orgUnitWriteService) buildCorrectionPatch(ctx context.Context, tenantID
 		if event.EventType != types.OrgUnitEventCreate {
 			return nil, nil, "", httperr.NewBadRequest(errPatchFieldNotAllowed)
 		}
-		pernr, managerUUID, managerName, err := s.resolveManager(*patch.ManagerPernr)
+		// The manager has to be active on the day the corrected event takes effect.
+		managerAsOf := event.EffectiveDate
+		if correctedDate != "" {
+			managerAsOf = correctedDate
+		}
+		pernr, managerUUID, managerName, err := s.resolveManager(ctx, tenantID, *patch.ManagerPernr, managerAsOf)
 		if err != nil {
 			return nil, nil, "", err
 		}
@@ -1347,6 +1406,7 @@ func (s *orgUnitWriteService) buildCorrectionPatch(ctx context.Context, tenantID
 		patchMap["manager_pernr"] = pernr
 		fields["manager_pernr"] = pernr
 		if managerName != "" {
+			patchMap["manager_name"] = managerName
 			fields["manager_name"] = managerName
 		}
 	}
@@ -1602,6 +1662,7 @@ func (s *orgUnitWriteService) applyCreatePolicyDefaults(
 		IsBusinessUnit:      req.Patch.IsBusinessUnit,
 		Ext:                 req.Patch.Ext,
 		EnabledFieldConfigs: fieldConfigs,
+		InitiatorUUID:       req.InitiatorUUID,
 	}
 	eval, err := evaluateCreateOrgUnitPrecheckV1(ctx, precheckReader, input)
 	if err != nil {
@@ -1629,7 +1690,7 @@ type createFieldDecisionValue struct {
 	autoCodeSpec *orgUnitAutoCodeSpec
 }
 
-func resolveCreateFieldDecisionValue(fieldKey string, provided string, providedByClient bool, decision orgUnitFieldDecision) (createFieldDecisionValue, error) {
+func resolveCreateFieldDecisionValue(fieldKey string, provided string, providedByClient bool, decision orgUnitFieldDecision, runtime orgUnitRuleRuntimeContext) (createFieldDecisionValue, error) {
 	providedValue := strings.TrimSpace(provided)
 	if !decision.Maintainable && providedValue != "" {
 		return createFieldDecisionValue{}, errors.New(errFieldNotMaintainable)
@@ -1648,7 +1709,7 @@ func resolveCreateFieldDecisionValue(fieldKey string, provided string, providedB
 	ruleExpr := strings.TrimSpace(decision.DefaultRuleRef)
 	defaultValue := strings.TrimSpace(decision.DefaultValue)
 	if ruleExpr != "" {
-		resolvedValue, spec, err := resolveCreateDefaultFromRule(fieldKey, ruleExpr)
+		resolvedValue, spec, err := resolveCreateDefaultFromRule(fieldKey, ruleExpr, runtime)
 		if err != nil {
 			return createFieldDecisionValue{}, err
 		}
@@ -1666,7 +1727,7 @@ func resolveCreateFieldDecisionValue(fieldKey string, provided string, providedB
 	return createFieldDecisionValue{}, errors.New(errDefaultRuleRequired)
 }
 
-func resolveCreateDefaultFromRule(fieldKey string, expr string) (string, *orgUnitAutoCodeSpec, error) {
+func resolveCreateDefaultFromRule(fieldKey string, expr string, runtime orgUnitRuleRuntimeContext) (string, *orgUnitAutoCodeSpec, error) {
 	expr = strings.TrimSpace(expr)
 	if expr == "" {
 		return "", nil, errors.New(errDefaultRuleRequired)
@@ -1680,10 +1741,27 @@ func resolveCreateDefaultFromRule(fieldKey string, expr string) (string, *orgUni
 			return "", nil, err
 		}
 	}
-	value, err := evaluateCELExprToString(expr)
+	value, err := evaluateCELExprToString(expr, runtime)
 	if err != nil {
 		return "", nil, errors.New(errDefaultRuleEvalFailed)
 	}
+	spec, hasSeq, err := splitOrgUnitDefaultRuleSequence(value)
+	if err != nil {
+		return "", nil, errors.New(errDefaultRuleEvalFailed)
+	}
+	if hasSeq {
+		// Only org_code has an allocator; a sequence in any other field has nothing to draw from.
+		if fieldKey != orgUnitCreateFieldOrgCode {
+			return "", nil, errors.New(errDefaultRuleEvalFailed)
+		}
+		sample := strings.Repeat("0", spec.Width)
+		normalized, err := normalizeOrgCode(spec.Prefix + sample)
+		if err != nil {
+			return "", nil, errors.New(errDefaultRuleEvalFailed)
+		}
+		spec.Prefix = strings.TrimSuffix(normalized, sample)
+		return "", spec, nil
+	}
 	return value, nil, nil
 }
 
@@ -1822,64 +1900,66 @@ func parseNextOrgCodeRule(expr string) (*orgUnitAutoCodeSpec, error) {
 	return &orgUnitAutoCodeSpec{Prefix: prefix, Width: width}, nil
 }
 
-func orgUnitWriteCELNextOrgCode(_ ...ref.Val) ref.Val {
-	return celtypes.String("")
-}
+var newOrgUnitWriteCELEnv = NewOrgUnitDefaultRuleCELEnv
 
-var newOrgUnitWriteCELEnv = func() (*cel.Env, error) {
-	return cel.NewEnv(
-		cel.Function(
-			orgUnitNextOrgCodeFuncName,
-			cel.Overload(
-				"next_org_code_string_int",
-				[]*cel.Type{cel.StringType, cel.IntType},
-				cel.StringType,
-				cel.FunctionBinding(orgUnitWriteCELNextOrgCode),
-			),
-		),
-	)
+var newOrgUnitWriteCELProgram = func(env *cel.Env, ast *cel.Ast) (cel.Program, error) {
+	return env.Program(ast, cel.CostLimit(orgUnitDefaultRuleCostLimit))
 }
 
-var newOrgUnitWriteCELProgram = func(env *cel.Env, ast *cel.Ast) (cel.Program, error) {
-	return env.Program(ast)
+// orgUnitDefaultRuleASTs caches, per expression, the checked AST of a default rule together with the env
+// it was checked in, failures included. Every default rule is checked against the one env
+// NewOrgUnitDefaultRuleCELEnv declares, so the expression is the whole key; an evaluation only binds the
+// runtime's function implementations and plans a program.
+var orgUnitDefaultRuleASTs = newCELProgramCache[orgUnitDefaultRuleCompiled](celProgramCacheMaxEntries)
+
+type orgUnitDefaultRuleCompiled struct {
+	env *cel.Env
+	ast *cel.Ast
+	err error
 }
 
-func compileCELExpr(expr string) error {
-	if _, ok := celCompileCache.Load(expr); ok {
-		return nil
+func compileOrgUnitDefaultRule(expr string) (*cel.Env, *cel.Ast, error) {
+	if compiled, ok := orgUnitDefaultRuleASTs.Load(expr); ok {
+		return compiled.env, compiled.ast, compiled.err
 	}
 	env, err := newOrgUnitWriteCELEnv()
 	if err != nil {
-		return err
+		// An environment failure says nothing about expr, so it is not cached.
+		return nil, nil, err
 	}
+	compiled := orgUnitDefaultRuleCompiled{env: env}
 	ast, iss := env.Compile(expr)
-	if iss != nil && iss.Err() != nil {
-		return iss.Err()
-	}
-	if ast.OutputType() != cel.StringType {
-		return errors.New("default expression must return string")
+	switch {
+	case iss != nil && iss.Err() != nil:
+		compiled.err = iss.Err()
+	case ast.OutputType() != cel.StringType:
+		compiled.err = errors.New("default expression must return string")
+	default:
+		compiled.ast = ast
 	}
-	celCompileCache.Store(expr, struct{}{})
-	return nil
+	orgUnitDefaultRuleASTs.Store(expr, compiled)
+	return compiled.env, compiled.ast, compiled.err
 }
 
-func evaluateCELExprToString(expr string) (string, error) {
-	env, err := newOrgUnitWriteCELEnv()
+func compileCELExpr(expr string) error {
+	_, _, err := compileOrgUnitDefaultRule(expr)
+	return err
+}
+
+func evaluateCELExprToString(expr string, runtime orgUnitRuleRuntimeContext) (string, error) {
+	env, ast, err := compileOrgUnitDefaultRule(expr)
 	if err != nil {
 		return "", err
 	}
-	ast, iss := env.Compile(expr)
-	if iss != nil && iss.Err() != nil {
-		return "", iss.Err()
-	}
-	if ast.OutputType() != cel.StringType {
-		return "", errors.New("default expression must return string")
+	env, err = env.Extend(orgUnitDefaultRuleFunctions(runtime)...)
+	if err != nil {
+		return "", err
 	}
 	program, err := newOrgUnitWriteCELProgram(env, ast)
 	if err != nil {
 		return "", err
 	}
-	out, _, err := program.Eval(map[string]any{})
+	out, _, err := program.Eval(runtime.activation())
 	if err != nil {
 		return "", err
 	}
@@ -1928,12 +2008,28 @@ func parentPatchKey(eventType types.OrgUnitEventType) (string, bool) {
 
 var pernrDigitsMax8Re = regexp.MustCompile(`^[0-9]{1,8}$`)
 
-func (s *orgUnitWriteService) resolveManager(pernrInput string) (string, string, string, error) {
+// resolveManager normalises manager_pernr and, when a directory is wired, requires the person to exist and
+// be active on asOf. It returns the canonical pernr, the person_uuid stored on the version and the display
+// name snapshot kept in the event payload.
+func (s *orgUnitWriteService) resolveManager(ctx context.Context, tenantID string, pernrInput string, asOf string) (string, string, string, error) {
 	pernr, err := normalizePernr(pernrInput)
 	if err != nil {
 		return "", "", "", err
 	}
-	return pernr, "", "", nil
+	if s.managers == nil {
+		return pernr, "", "", nil
+	}
+	manager, found, err := s.managers.FindManagerAsOf(ctx, tenantID, pernr, asOf)
+	if err != nil {
+		return "", "", "", err
+	}
+	if !found {
+		return "", "", "", errors.New(errManagerPernrNotFound)
+	}
+	if !manager.Active {
+		return "", "", "", errors.New(errManagerPernrInactive)
+	}
+	return pernr, manager.PersonUUID, manager.DisplayName, nil
 }
 
 func normalizePernr(raw string) (string, error) {
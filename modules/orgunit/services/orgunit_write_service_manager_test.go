@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/ports"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/orgunit/domain/types"
+	orgunitpkg "github.com/jacksonlee411/Bugs-And-Blossoms/pkg/orgunit"
+)
+
+type managerDirectoryStub struct {
+	records map[string]ports.ManagerRecord
+	err     error
+	asOf    []string
+}
+
+func (s *managerDirectoryStub) FindManagerAsOf(_ context.Context, _ string, pernr string, asOf string) (ports.ManagerRecord, bool, error) {
+	s.asOf = append(s.asOf, asOf)
+	if s.err != nil {
+		return ports.ManagerRecord{}, false, s.err
+	}
+	rec, ok := s.records[pernr]
+	return rec, ok, nil
+}
+
+func newManagerDirectoryStub() *managerDirectoryStub {
+	return &managerDirectoryStub{records: map[string]ports.ManagerRecord{
+		"1001": {PersonUUID: "00000000-0000-0000-0000-000000001001", Pernr: "1001", DisplayName: "Alice", Active: true},
+		"1002": {PersonUUID: "00000000-0000-0000-0000-000000001002", Pernr: "1002", DisplayName: "Bob", Active: false},
+	}}
+}
+
+func TestResolveManagerWithDirectory(t *testing.T) {
+	ctx := context.Background()
+	dir := newManagerDirectoryStub()
+	svc := &orgUnitWriteService{store: orgUnitWriteStoreStub{}, managers: dir}
+
+	pernr, managerUUID, name, err := svc.resolveManager(ctx, "t1", "001001", "2026-01-01")
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if pernr != "1001" || managerUUID != "00000000-0000-0000-0000-000000001001" || name != "Alice" {
+		t.Fatalf("pernr=%q uuid=%q name=%q", pernr, managerUUID, name)
+	}
+	if len(dir.asOf) != 1 || dir.asOf[0] != "2026-01-01" {
+		t.Fatalf("asOf=%v", dir.asOf)
+	}
+
+	if _, _, _, err := svc.resolveManager(ctx, "t1", "9999", "2026-01-01"); err == nil || err.Error() != errManagerPernrNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if _, _, _, err := svc.resolveManager(ctx, "t1", "1002", "2026-01-01"); err == nil || err.Error() != errManagerPernrInactive {
+		t.Fatalf("expected inactive, got %v", err)
+	}
+
+	dir.err = errors.New("boom")
+	if _, _, _, err := svc.resolveManager(ctx, "t1", "1001", "2026-01-01"); err == nil || err.Error() != "boom" {
+		t.Fatalf("expected directory error, got %v", err)
+	}
+}
+
+func TestCreateStoresManagerUUIDAndNameSnapshot(t *testing.T) {
+	var captured map[string]any
+	store := orgUnitWriteStoreStub{
+		resolveOrgIDFn: func(_ context.Context, _ string, _ string) (int, error) {
+			return 0, orgunitpkg.ErrOrgCodeNotFound
+		},
+		submitEventFn: func(_ context.Context, _ string, _ string, _ *int, _ string, _ string, payload json.RawMessage, _ string, _ string) (int64, error) {
+			if err := json.Unmarshal(payload, &captured); err != nil {
+				return 0, err
+			}
+			return 1, nil
+		},
+		findEventByUUIDFn: func(_ context.Context, _ string, _ string) (types.OrgUnitEvent, error) {
+			return types.OrgUnitEvent{OrgNodeKey: "10000001"}, nil
+		},
+	}
+
+	svc := NewOrgUnitWriteServiceWithManagerDirectory(store, newManagerDirectoryStub())
+	res, err := svc.Create(context.Background(), "t1", CreateOrgUnitRequest{
+		EffectiveDate: "2026-01-01",
+		OrgCode:       "ROOT",
+		Name:          "Root",
+		ManagerPernr:  "1001",
+	})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if captured["manager_uuid"] != "00000000-0000-0000-0000-000000001001" || captured["manager_pernr"] != "1001" || captured["manager_name"] != "Alice" {
+		t.Fatalf("payload=%v", captured)
+	}
+	if res.Fields["manager_name"] != "Alice" {
+		t.Fatalf("fields=%v", res.Fields)
+	}
+
+	_, err = svc.Create(context.Background(), "t1", CreateOrgUnitRequest{
+		EffectiveDate: "2026-01-01",
+		OrgCode:       "ROOT",
+		Name:          "Root",
+		ManagerPernr:  "1002",
+	})
+	if err == nil || err.Error() != errManagerPernrInactive {
+		t.Fatalf("expected inactive manager, got %v", err)
+	}
+}
+
+func TestCorrectionPatchResolvesManagerAsOfCorrectedDate(t *testing.T) {
+	dir := newManagerDirectoryStub()
+	svc := &orgUnitWriteService{store: orgUnitWriteStoreStub{}, managers: dir}
+
+	patchMap, fields, _, err := svc.buildCorrectionPatch(context.Background(), "t1", types.OrgUnitEvent{
+		EventType:     types.OrgUnitEventCreate,
+		EffectiveDate: "2026-01-01",
+	}, OrgUnitCorrectionPatch{
+		EffectiveDate: new("2026-02-01"),
+		ManagerPernr:  new("1001"),
+	}, nil)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if patchMap["manager_uuid"] != "00000000-0000-0000-0000-000000001001" || patchMap["manager_name"] != "Alice" || fields["manager_name"] != "Alice" {
+		t.Fatalf("patch=%v fields=%v", patchMap, fields)
+	}
+	if len(dir.asOf) != 1 || dir.asOf[0] != "2026-02-01" {
+		t.Fatalf("asOf=%v", dir.asOf)
+	}
+
+	if _, _, _, err := svc.buildCorrectionPatch(context.Background(), "t1", types.OrgUnitEvent{
+		EventType:     types.OrgUnitEventCreate,
+		EffectiveDate: "2026-01-01",
+	}, OrgUnitCorrectionPatch{ManagerPernr: new("4242")}, nil); err == nil || err.Error() != errManagerPernrNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if dir.asOf[len(dir.asOf)-1] != "2026-01-01" {
+		t.Fatalf("asOf=%v", dir.asOf)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"sync"
 	"testing"
 
 	"github.com/google/cel-go/cel"
@@ -244,34 +243,34 @@ func TestWrite_CreateOrg_AutoCodeBranches(t *testing.T) {
 
 func TestCreateFieldDecisionHelperFunctions(t *testing.T) {
 	t.Run("resolveCreateFieldDecisionValue branches", func(t *testing.T) {
-		if _, err := resolveCreateFieldDecisionValue(orgUnitCreateFieldOrgCode, "A001", true, orgUnitFieldDecision{Maintainable: false}); err == nil || err.Error() != errFieldNotMaintainable {
+		if _, err := resolveCreateFieldDecisionValue(orgUnitCreateFieldOrgCode, "A001", true, orgUnitFieldDecision{Maintainable: false}, orgUnitRuleRuntimeContext{}); err == nil || err.Error() != errFieldNotMaintainable {
 			t.Fatalf("err=%v", err)
 		}
-		result, err := resolveCreateFieldDecisionValue(orgUnitCreateFieldOrgType, "11", true, orgUnitFieldDecision{Maintainable: true})
+		result, err := resolveCreateFieldDecisionValue(orgUnitCreateFieldOrgType, "11", true, orgUnitFieldDecision{Maintainable: true}, orgUnitRuleRuntimeContext{})
 		if err != nil || result.value != "11" {
 			t.Fatalf("result=%+v err=%v", result, err)
 		}
-		result, err = resolveCreateFieldDecisionValue(orgUnitCreateFieldOrgCode, "", false, orgUnitFieldDecision{Maintainable: false, DefaultRuleRef: `next_org_code("F", 8)`})
+		result, err = resolveCreateFieldDecisionValue(orgUnitCreateFieldOrgCode, "", false, orgUnitFieldDecision{Maintainable: false, DefaultRuleRef: `next_org_code("F", 8)`}, orgUnitRuleRuntimeContext{})
 		if err != nil || result.autoCodeSpec == nil || result.autoCodeSpec.Prefix != "F" {
 			t.Fatalf("result=%+v err=%v", result, err)
 		}
-		result, err = resolveCreateFieldDecisionValue(orgUnitCreateFieldOrgType, "", false, orgUnitFieldDecision{Maintainable: false, DefaultRuleRef: `"11"`})
+		result, err = resolveCreateFieldDecisionValue(orgUnitCreateFieldOrgType, "", false, orgUnitFieldDecision{Maintainable: false, DefaultRuleRef: `"11"`}, orgUnitRuleRuntimeContext{})
 		if err != nil || result.value != "11" {
 			t.Fatalf("result=%+v err=%v", result, err)
 		}
-		if _, err := resolveCreateFieldDecisionValue(orgUnitCreateFieldOrgType, "", false, orgUnitFieldDecision{Maintainable: false, DefaultRuleRef: "1+1"}); err == nil || err.Error() != errDefaultRuleEvalFailed {
+		if _, err := resolveCreateFieldDecisionValue(orgUnitCreateFieldOrgType, "", false, orgUnitFieldDecision{Maintainable: false, DefaultRuleRef: "1+1"}, orgUnitRuleRuntimeContext{}); err == nil || err.Error() != errDefaultRuleEvalFailed {
 			t.Fatalf("err=%v", err)
 		}
-		if _, err := resolveCreateFieldDecisionValue(orgUnitCreateFieldOrgType, "", false, orgUnitFieldDecision{Maintainable: false}); err == nil || err.Error() != errDefaultRuleRequired {
+		if _, err := resolveCreateFieldDecisionValue(orgUnitCreateFieldOrgType, "", false, orgUnitFieldDecision{Maintainable: false}, orgUnitRuleRuntimeContext{}); err == nil || err.Error() != errDefaultRuleRequired {
 			t.Fatalf("err=%v", err)
 		}
 	})
 
 	t.Run("resolveCreateDefaultFromRule branches", func(t *testing.T) {
-		if _, _, err := resolveCreateDefaultFromRule(orgUnitCreateFieldOrgCode, ""); err == nil || err.Error() != errDefaultRuleRequired {
+		if _, _, err := resolveCreateDefaultFromRule(orgUnitCreateFieldOrgCode, "", orgUnitRuleRuntimeContext{}); err == nil || err.Error() != errDefaultRuleRequired {
 			t.Fatalf("err=%v", err)
 		}
-		if _, _, err := resolveCreateDefaultFromRule(orgUnitCreateFieldOrgCode, `next_org_code("O", )`); err == nil || err.Error() != errFieldPolicyExprInvalid {
+		if _, _, err := resolveCreateDefaultFromRule(orgUnitCreateFieldOrgCode, `next_org_code("O", )`, orgUnitRuleRuntimeContext{}); err == nil || err.Error() != errFieldPolicyExprInvalid {
 			t.Fatalf("err=%v", err)
 		}
 	})
@@ -306,17 +305,18 @@ func TestResolveCreateBusinessUnitID(t *testing.T) {
 
 func TestEvaluateCELExprToString_Branches(t *testing.T) {
 	t.Run("env error", func(t *testing.T) {
+		orgUnitDefaultRuleASTs = newCELProgramCache[orgUnitDefaultRuleCompiled](celProgramCacheMaxEntries)
 		orig := newOrgUnitWriteCELEnv
 		newOrgUnitWriteCELEnv = func() (*cel.Env, error) { return nil, errors.New("env") }
 		t.Cleanup(func() { newOrgUnitWriteCELEnv = orig })
-		if _, err := evaluateCELExprToString(`"x"`); err == nil || err.Error() != "env" {
+		if _, err := evaluateCELExprToString(`"x"`, orgUnitRuleRuntimeContext{}); err == nil || err.Error() != "env" {
 			t.Fatalf("err=%v", err)
 		}
 	})
 }
 
 func TestParseCompileAndMapCreateAutoCodeHelpers(t *testing.T) {
-	celCompileCache = sync.Map{}
+	orgUnitDefaultRuleASTs = newCELProgramCache[orgUnitDefaultRuleCompiled](celProgramCacheMaxEntries)
 
 	t.Run("parse success", func(t *testing.T) {
 		spec, err := parseNextOrgCodeRule(`next_org_code("ORG", 3)`)
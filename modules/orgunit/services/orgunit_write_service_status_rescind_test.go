@@ -305,14 +305,14 @@ func TestParentPatchKey(t *testing.T) {
 
 func TestResolveManagerInvalidPernr(t *testing.T) {
 	svc := newWriteService(orgUnitWriteStoreStub{})
-	if _, _, _, err := svc.resolveManager("ABC"); err == nil || !httperr.IsBadRequest(err) || err.Error() != errManagerPernrInvalid {
+	if _, _, _, err := svc.resolveManager(context.Background(), "t1", "ABC", "2026-01-01"); err == nil || !httperr.IsBadRequest(err) || err.Error() != errManagerPernrInvalid {
 		t.Fatalf("expected pernr invalid, got %v", err)
 	}
 }
 
 func TestResolveManagerSuccess(t *testing.T) {
 	svc := newWriteService(orgUnitWriteStoreStub{})
-	pernr, uuid, name, err := svc.resolveManager("01001")
+	pernr, uuid, name, err := svc.resolveManager(context.Background(), "t1", "01001", "2026-01-01")
 	if err != nil {
 		t.Fatalf("expected ok, got %v", err)
 	}
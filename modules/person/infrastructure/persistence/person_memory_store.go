@@ -0,0 +1,181 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type memoryPerson struct {
+	PersonUUID string
+	Pernr      string
+	Versions   []PersonVersion
+}
+
+// MemoryStore backs the person directory when the server runs without Postgres (tests, local stubs).
+type MemoryStore struct {
+	mu      sync.Mutex
+	Persons map[string]map[string]*memoryPerson
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{Persons: make(map[string]map[string]*memoryPerson)}
+}
+
+func (s *MemoryStore) itemAsOf(p *memoryPerson, asOf string) PersonItem {
+	item := PersonItem{PersonUUID: p.PersonUUID, Pernr: p.Pernr}
+	if v, ok := versionAsOf(p.Versions, asOf); ok {
+		item.DisplayName = v.DisplayName
+		item.Status = v.Status
+		item.EffectiveDate = v.EffectiveDate
+	}
+	return item
+}
+
+func (s *MemoryStore) ListPersons(_ context.Context, tenantID string, asOf string, keyword string, limit int) ([]PersonItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keyword = strings.TrimSpace(keyword)
+	pernrPrefix := keyword
+	if pernr, err := NormalizePernr(keyword); err == nil {
+		pernrPrefix = pernr
+	}
+	items := make([]PersonItem, 0)
+	for _, p := range s.Persons[tenantID] {
+		item := s.itemAsOf(p, asOf)
+		if item.Status == "" {
+			continue
+		}
+		if keyword != "" && !strings.HasPrefix(item.Pernr, pernrPrefix) && !strings.Contains(strings.ToLower(item.DisplayName), strings.ToLower(keyword)) {
+			continue
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if len(items[i].Pernr) != len(items[j].Pernr) {
+			return len(items[i].Pernr) < len(items[j].Pernr)
+		}
+		return items[i].Pernr < items[j].Pernr
+	})
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (s *MemoryStore) GetPerson(_ context.Context, tenantID string, pernr string, asOf string) (PersonDetail, error) {
+	pernr, err := NormalizePernr(pernr)
+	if err != nil {
+		return PersonDetail{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.Persons[tenantID][pernr]
+	if !ok {
+		return PersonDetail{}, ErrPersonNotFound
+	}
+	return PersonDetail{PersonItem: s.itemAsOf(p, asOf), Versions: append([]PersonVersion{}, p.Versions...)}, nil
+}
+
+func (s *MemoryStore) FindByPernrAsOf(_ context.Context, tenantID string, pernr string, asOf string) (PersonItem, bool, error) {
+	pernr, err := NormalizePernr(pernr)
+	if err != nil {
+		return PersonItem{}, false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.Persons[tenantID][pernr]
+	if !ok {
+		return PersonItem{}, false, nil
+	}
+	return s.itemAsOf(p, asOf), true, nil
+}
+
+func (s *MemoryStore) ResolvePersonUUID(_ context.Context, tenantID string, pernr string) (string, bool, error) {
+	pernr, err := NormalizePernr(pernr)
+	if err != nil {
+		return "", false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.Persons[tenantID][pernr]
+	if !ok {
+		return "", false, nil
+	}
+	return p.PersonUUID, true, nil
+}
+
+func (s *MemoryStore) UpsertPerson(_ context.Context, tenantID string, req UpsertPersonRequest) (PersonItem, bool, error) {
+	req, err := normalizeUpsertRequest(req)
+	if err != nil {
+		return PersonItem{}, false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, created := s.upsertLocked(tenantID, req)
+	return item, created, nil
+}
+
+func (s *MemoryStore) ImportPersons(_ context.Context, tenantID string, reqs []UpsertPersonRequest) (ImportResult, error) {
+	reqs, err := normalizeImportRequests(reqs)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result ImportResult
+	for _, req := range reqs {
+		if _, created := s.upsertLocked(tenantID, req); created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) upsertLocked(tenantID string, req UpsertPersonRequest) (PersonItem, bool) {
+	persons := s.Persons[tenantID]
+	if persons == nil {
+		persons = make(map[string]*memoryPerson)
+		s.Persons[tenantID] = persons
+	}
+	p, ok := persons[req.Pernr]
+	if !ok {
+		p = &memoryPerson{PersonUUID: uuid.NewString(), Pernr: req.Pernr}
+		persons[req.Pernr] = p
+	}
+	p.Versions = applyPersonVersion(p.Versions, req.EffectiveDate, req.DisplayName, req.Status)
+	return PersonItem{
+		PersonUUID:    p.PersonUUID,
+		Pernr:         p.Pernr,
+		DisplayName:   req.DisplayName,
+		Status:        req.Status,
+		EffectiveDate: req.EffectiveDate,
+	}, !ok
+}
+
+func (s *MemoryStore) DeletePerson(_ context.Context, tenantID string, pernr string) (string, error) {
+	pernr, err := NormalizePernr(pernr)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.Persons[tenantID][pernr]
+	if !ok {
+		return "", ErrPersonNotFound
+	}
+	delete(s.Persons[tenantID], pernr)
+	return p.PersonUUID, nil
+}
@@ -0,0 +1,518 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	PersonStatusActive   = "active"
+	PersonStatusInactive = "inactive"
+
+	// MaxImportRows bounds one import request; larger rosters are split by the caller.
+	MaxImportRows = 1000
+)
+
+var (
+	ErrPersonPernrInvalid         = errors.New("PERSON_PERNR_INVALID")
+	ErrPersonNotFound             = errors.New("PERSON_NOT_FOUND")
+	ErrPersonDisplayNameRequired  = errors.New("PERSON_DISPLAY_NAME_REQUIRED")
+	ErrPersonStatusInvalid        = errors.New("PERSON_STATUS_INVALID")
+	ErrPersonEffectiveDateInvalid = errors.New("PERSON_EFFECTIVE_DATE_INVALID")
+	ErrPersonImportEmpty          = errors.New("PERSON_IMPORT_EMPTY")
+	ErrPersonImportTooLarge       = errors.New("PERSON_IMPORT_TOO_LARGE")
+)
+
+var pernrPattern = regexp.MustCompile(`^[0-9]{1,8}$`)
+
+type PGBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Store is the tenant-scoped person directory. Reads are always as of an explicit day.
+type Store interface {
+	ListPersons(ctx context.Context, tenantID string, asOf string, keyword string, limit int) ([]PersonItem, error)
+	GetPerson(ctx context.Context, tenantID string, pernr string, asOf string) (PersonDetail, error)
+	FindByPernrAsOf(ctx context.Context, tenantID string, pernr string, asOf string) (PersonItem, bool, error)
+	ResolvePersonUUID(ctx context.Context, tenantID string, pernr string) (string, bool, error)
+	UpsertPerson(ctx context.Context, tenantID string, req UpsertPersonRequest) (PersonItem, bool, error)
+	ImportPersons(ctx context.Context, tenantID string, reqs []UpsertPersonRequest) (ImportResult, error)
+	DeletePerson(ctx context.Context, tenantID string, pernr string) (string, error)
+}
+
+// PersonItem is a person as of one day. Status and DisplayName are empty when the person exists but has no
+// version covering that day.
+type PersonItem struct {
+	PersonUUID    string `json:"person_uuid"`
+	Pernr         string `json:"pernr"`
+	DisplayName   string `json:"display_name"`
+	Status        string `json:"status"`
+	EffectiveDate string `json:"effective_date"`
+}
+
+type PersonVersion struct {
+	EffectiveDate string `json:"effective_date"`
+	EndDate       string `json:"end_date,omitempty"`
+	DisplayName   string `json:"display_name"`
+	Status        string `json:"status"`
+}
+
+type PersonDetail struct {
+	PersonItem
+	Versions []PersonVersion `json:"versions"`
+}
+
+// UpsertPersonRequest sets the attributes of a person from EffectiveDate until its next recorded change,
+// creating the person on first use. Replaying the same request is a no-op.
+type UpsertPersonRequest struct {
+	Pernr         string
+	DisplayName   string
+	Status        string
+	EffectiveDate string
+}
+
+type ImportResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
+
+// ImportRowError reports the first invalid row of an import; the whole import is rejected.
+type ImportRowError struct {
+	Row int
+	Err error
+}
+
+func (e *ImportRowError) Error() string { return e.Err.Error() }
+
+func (e *ImportRowError) Unwrap() error { return e.Err }
+
+// NormalizePernr validates a 1-8 digit pernr and strips leading zeros, so "00001234" and "1234" are the
+// same person.
+func NormalizePernr(raw string) (string, error) {
+	value := strings.TrimSpace(raw)
+	if !pernrPattern.MatchString(value) {
+		return "", ErrPersonPernrInvalid
+	}
+	value = strings.TrimLeft(value, "0")
+	if value == "" {
+		value = "0"
+	}
+	return value, nil
+}
+
+func normalizeUpsertRequest(req UpsertPersonRequest) (UpsertPersonRequest, error) {
+	pernr, err := NormalizePernr(req.Pernr)
+	if err != nil {
+		return UpsertPersonRequest{}, err
+	}
+	req.Pernr = pernr
+	req.DisplayName = strings.TrimSpace(req.DisplayName)
+	if req.DisplayName == "" {
+		return UpsertPersonRequest{}, ErrPersonDisplayNameRequired
+	}
+	req.Status = strings.ToLower(strings.TrimSpace(req.Status))
+	if req.Status == "" {
+		req.Status = PersonStatusActive
+	}
+	if req.Status != PersonStatusActive && req.Status != PersonStatusInactive {
+		return UpsertPersonRequest{}, ErrPersonStatusInvalid
+	}
+	req.EffectiveDate = strings.TrimSpace(req.EffectiveDate)
+	if _, err := time.Parse(time.DateOnly, req.EffectiveDate); err != nil {
+		return UpsertPersonRequest{}, ErrPersonEffectiveDateInvalid
+	}
+	return req, nil
+}
+
+func normalizeImportRequests(reqs []UpsertPersonRequest) ([]UpsertPersonRequest, error) {
+	if len(reqs) == 0 {
+		return nil, ErrPersonImportEmpty
+	}
+	if len(reqs) > MaxImportRows {
+		return nil, ErrPersonImportTooLarge
+	}
+	out := make([]UpsertPersonRequest, 0, len(reqs))
+	for i, req := range reqs {
+		normalized, err := normalizeUpsertRequest(req)
+		if err != nil {
+			return nil, &ImportRowError{Row: i + 1, Err: err}
+		}
+		out = append(out, normalized)
+	}
+	return out, nil
+}
+
+// applyPersonVersion writes one change into a timeline sorted by EffectiveDate. The version covering the
+// day is split at it, so later recorded changes keep their own values.
+func applyPersonVersion(versions []PersonVersion, day string, displayName string, status string) []PersonVersion {
+	out := make([]PersonVersion, 0, len(versions)+1)
+	inserted := false
+	next := PersonVersion{EffectiveDate: day, DisplayName: displayName, Status: status}
+	for _, v := range versions {
+		switch {
+		case v.EffectiveDate == day:
+			next.EndDate = v.EndDate
+			out = append(out, next)
+			inserted = true
+		case v.EffectiveDate < day && (v.EndDate == "" || day < v.EndDate):
+			next.EndDate = v.EndDate
+			v.EndDate = day
+			out = append(out, v, next)
+			inserted = true
+		case v.EffectiveDate > day && !inserted:
+			next.EndDate = v.EffectiveDate
+			out = append(out, next, v)
+			inserted = true
+		default:
+			out = append(out, v)
+		}
+	}
+	if !inserted {
+		out = append(out, next)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].EffectiveDate < out[j].EffectiveDate })
+	return out
+}
+
+func versionAsOf(versions []PersonVersion, asOf string) (PersonVersion, bool) {
+	for _, v := range versions {
+		if v.EffectiveDate <= asOf && (v.EndDate == "" || asOf < v.EndDate) {
+			return v, true
+		}
+	}
+	return PersonVersion{}, false
+}
+
+func escapeLikePattern(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(v)
+}
+
+type PGStore struct {
+	Pool PGBeginner
+}
+
+func NewPGStore(pool PGBeginner) *PGStore {
+	return &PGStore{Pool: pool}
+}
+
+func (s *PGStore) begin(ctx context.Context, tenantID string) (pgx.Tx, error) {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant', $1, true);`, tenantID); err != nil {
+		_ = tx.Rollback(context.Background())
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (s *PGStore) ListPersons(ctx context.Context, tenantID string, asOf string, keyword string, limit int) ([]PersonItem, error) {
+	tx, err := s.begin(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	keyword = strings.TrimSpace(keyword)
+	pernrPrefix := keyword
+	if pernr, err := NormalizePernr(keyword); err == nil {
+		pernrPrefix = pernr
+	}
+	rows, err := tx.Query(ctx, `
+SELECT p.person_uuid::text, p.pernr, v.display_name, v.status, lower(v.validity)::text
+FROM person.persons p
+JOIN person.person_versions v
+  ON v.tenant_uuid = p.tenant_uuid
+ AND v.person_uuid = p.person_uuid
+ AND v.validity @> $2::date
+WHERE p.tenant_uuid = $1::uuid
+  AND ($3 = '' OR p.pernr LIKE $4 || '%' ESCAPE '\' OR v.display_name ILIKE '%' || $5 || '%' ESCAPE '\')
+ORDER BY length(p.pernr), p.pernr
+LIMIT $6
+`, tenantID, asOf, keyword, escapeLikePattern(pernrPrefix), escapeLikePattern(keyword), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]PersonItem, 0)
+	for rows.Next() {
+		var item PersonItem
+		if err := rows.Scan(&item.PersonUUID, &item.Pernr, &item.DisplayName, &item.Status, &item.EffectiveDate); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *PGStore) FindByPernrAsOf(ctx context.Context, tenantID string, pernr string, asOf string) (PersonItem, bool, error) {
+	pernr, err := NormalizePernr(pernr)
+	if err != nil {
+		return PersonItem{}, false, err
+	}
+	tx, err := s.begin(ctx, tenantID)
+	if err != nil {
+		return PersonItem{}, false, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	item, found, err := findByPernrAsOfTx(ctx, tx, tenantID, pernr, asOf)
+	if err != nil {
+		return PersonItem{}, false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return PersonItem{}, false, err
+	}
+	return item, found, nil
+}
+
+func findByPernrAsOfTx(ctx context.Context, tx pgx.Tx, tenantID string, pernr string, asOf string) (PersonItem, bool, error) {
+	var item PersonItem
+	err := tx.QueryRow(ctx, `
+SELECT p.person_uuid::text, p.pernr, COALESCE(v.display_name, ''), COALESCE(v.status, ''), COALESCE(lower(v.validity)::text, '')
+FROM person.persons p
+LEFT JOIN person.person_versions v
+  ON v.tenant_uuid = p.tenant_uuid
+ AND v.person_uuid = p.person_uuid
+ AND v.validity @> $3::date
+WHERE p.tenant_uuid = $1::uuid
+  AND p.pernr = $2
+`, tenantID, pernr, asOf).Scan(&item.PersonUUID, &item.Pernr, &item.DisplayName, &item.Status, &item.EffectiveDate)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PersonItem{}, false, nil
+	}
+	if err != nil {
+		return PersonItem{}, false, err
+	}
+	return item, true, nil
+}
+
+func (s *PGStore) ResolvePersonUUID(ctx context.Context, tenantID string, pernr string) (string, bool, error) {
+	pernr, err := NormalizePernr(pernr)
+	if err != nil {
+		return "", false, err
+	}
+	tx, err := s.begin(ctx, tenantID)
+	if err != nil {
+		return "", false, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	var personUUID string
+	err = tx.QueryRow(ctx, `
+SELECT person_uuid::text
+FROM person.persons
+WHERE tenant_uuid = $1::uuid
+  AND pernr = $2
+`, tenantID, pernr).Scan(&personUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return "", false, err
+	}
+	return personUUID, true, nil
+}
+
+func (s *PGStore) GetPerson(ctx context.Context, tenantID string, pernr string, asOf string) (PersonDetail, error) {
+	pernr, err := NormalizePernr(pernr)
+	if err != nil {
+		return PersonDetail{}, err
+	}
+	tx, err := s.begin(ctx, tenantID)
+	if err != nil {
+		return PersonDetail{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	item, found, err := findByPernrAsOfTx(ctx, tx, tenantID, pernr, asOf)
+	if err != nil {
+		return PersonDetail{}, err
+	}
+	if !found {
+		return PersonDetail{}, ErrPersonNotFound
+	}
+	versions, err := listVersionsTx(ctx, tx, tenantID, item.PersonUUID, false)
+	if err != nil {
+		return PersonDetail{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return PersonDetail{}, err
+	}
+	return PersonDetail{PersonItem: item, Versions: versions}, nil
+}
+
+func listVersionsTx(ctx context.Context, tx pgx.Tx, tenantID string, personUUID string, forUpdate bool) ([]PersonVersion, error) {
+	sql := `
+SELECT lower(validity)::text, COALESCE(upper(validity)::text, ''), display_name, status
+FROM person.person_versions
+WHERE tenant_uuid = $1::uuid
+  AND person_uuid = $2::uuid
+ORDER BY lower(validity)
+`
+	if forUpdate {
+		sql += "FOR UPDATE\n"
+	}
+	rows, err := tx.Query(ctx, sql, tenantID, personUUID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make([]PersonVersion, 0)
+	for rows.Next() {
+		var v PersonVersion
+		if err := rows.Scan(&v.EffectiveDate, &v.EndDate, &v.DisplayName, &v.Status); err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (s *PGStore) UpsertPerson(ctx context.Context, tenantID string, req UpsertPersonRequest) (PersonItem, bool, error) {
+	req, err := normalizeUpsertRequest(req)
+	if err != nil {
+		return PersonItem{}, false, err
+	}
+	tx, err := s.begin(ctx, tenantID)
+	if err != nil {
+		return PersonItem{}, false, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	item, created, err := upsertPersonTx(ctx, tx, tenantID, req)
+	if err != nil {
+		return PersonItem{}, false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return PersonItem{}, false, err
+	}
+	return item, created, nil
+}
+
+func (s *PGStore) ImportPersons(ctx context.Context, tenantID string, reqs []UpsertPersonRequest) (ImportResult, error) {
+	reqs, err := normalizeImportRequests(reqs)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	tx, err := s.begin(ctx, tenantID)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	var result ImportResult
+	for i, req := range reqs {
+		_, created, err := upsertPersonTx(ctx, tx, tenantID, req)
+		if err != nil {
+			return ImportResult{}, &ImportRowError{Row: i + 1, Err: err}
+		}
+		if created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return ImportResult{}, err
+	}
+	return result, nil
+}
+
+// upsertPersonTx locks the person row (ON CONFLICT DO UPDATE takes the row lock) and rewrites its timeline,
+// which keeps concurrent writers for the same pernr serialised.
+func upsertPersonTx(ctx context.Context, tx pgx.Tx, tenantID string, req UpsertPersonRequest) (PersonItem, bool, error) {
+	var personUUID string
+	var created bool
+	if err := tx.QueryRow(ctx, `
+INSERT INTO person.persons (tenant_uuid, pernr)
+VALUES ($1::uuid, $2)
+ON CONFLICT (tenant_uuid, pernr) DO UPDATE SET updated_at = now()
+RETURNING person_uuid::text, (xmax = 0)
+`, tenantID, req.Pernr).Scan(&personUUID, &created); err != nil {
+		return PersonItem{}, false, err
+	}
+
+	versions, err := listVersionsTx(ctx, tx, tenantID, personUUID, true)
+	if err != nil {
+		return PersonItem{}, false, err
+	}
+	versions = applyPersonVersion(versions, req.EffectiveDate, req.DisplayName, req.Status)
+
+	if _, err := tx.Exec(ctx, `
+DELETE FROM person.person_versions
+WHERE tenant_uuid = $1::uuid
+  AND person_uuid = $2::uuid
+`, tenantID, personUUID); err != nil {
+		return PersonItem{}, false, err
+	}
+	for _, v := range versions {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO person.person_versions (tenant_uuid, person_uuid, validity, display_name, status)
+VALUES ($1::uuid, $2::uuid, daterange($3::date, NULLIF($4, '')::date, '[)'), $5, $6)
+`, tenantID, personUUID, v.EffectiveDate, v.EndDate, v.DisplayName, v.Status); err != nil {
+			return PersonItem{}, false, err
+		}
+	}
+
+	return PersonItem{
+		PersonUUID:    personUUID,
+		Pernr:         req.Pernr,
+		DisplayName:   req.DisplayName,
+		Status:        req.Status,
+		EffectiveDate: req.EffectiveDate,
+	}, created, nil
+}
+
+// DeletePerson removes a person and its whole timeline, returning the removed person_uuid. Callers are
+// responsible for refusing the delete while other modules still reference the person.
+func (s *PGStore) DeletePerson(ctx context.Context, tenantID string, pernr string) (string, error) {
+	pernr, err := NormalizePernr(pernr)
+	if err != nil {
+		return "", err
+	}
+	tx, err := s.begin(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	var personUUID string
+	if err := tx.QueryRow(ctx, `
+DELETE FROM person.persons
+WHERE tenant_uuid = $1::uuid
+  AND pernr = $2
+RETURNING person_uuid::text
+`, tenantID, pernr).Scan(&personUUID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrPersonNotFound
+		}
+		return "", err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return personUUID, nil
+}
@@ -0,0 +1,153 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizePernr(t *testing.T) {
+	for raw, want := range map[string]string{"1234": "1234", " 00001234 ": "1234", "0000": "0", "12345678": "12345678"} {
+		got, err := NormalizePernr(raw)
+		if err != nil || got != want {
+			t.Fatalf("NormalizePernr(%q)=%q,%v want %q", raw, got, err, want)
+		}
+	}
+	for _, raw := range []string{"", "123456789", "A100", "12 3"} {
+		if _, err := NormalizePernr(raw); !errors.Is(err, ErrPersonPernrInvalid) {
+			t.Fatalf("NormalizePernr(%q) err=%v", raw, err)
+		}
+	}
+}
+
+func TestNormalizeUpsertRequest(t *testing.T) {
+	req, err := normalizeUpsertRequest(UpsertPersonRequest{Pernr: "007", DisplayName: " Alice ", EffectiveDate: "2026-01-01"})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if req.Pernr != "7" || req.DisplayName != "Alice" || req.Status != PersonStatusActive {
+		t.Fatalf("req=%+v", req)
+	}
+
+	cases := []struct {
+		req  UpsertPersonRequest
+		want error
+	}{
+		{UpsertPersonRequest{Pernr: "x", DisplayName: "A", EffectiveDate: "2026-01-01"}, ErrPersonPernrInvalid},
+		{UpsertPersonRequest{Pernr: "1", DisplayName: " ", EffectiveDate: "2026-01-01"}, ErrPersonDisplayNameRequired},
+		{UpsertPersonRequest{Pernr: "1", DisplayName: "A", Status: "gone", EffectiveDate: "2026-01-01"}, ErrPersonStatusInvalid},
+		{UpsertPersonRequest{Pernr: "1", DisplayName: "A", EffectiveDate: "2026-02-30"}, ErrPersonEffectiveDateInvalid},
+	}
+	for _, tc := range cases {
+		if _, err := normalizeUpsertRequest(tc.req); !errors.Is(err, tc.want) {
+			t.Fatalf("req=%+v err=%v want %v", tc.req, err, tc.want)
+		}
+	}
+}
+
+func TestNormalizeImportRequests(t *testing.T) {
+	if _, err := normalizeImportRequests(nil); !errors.Is(err, ErrPersonImportEmpty) {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := normalizeImportRequests(make([]UpsertPersonRequest, MaxImportRows+1)); !errors.Is(err, ErrPersonImportTooLarge) {
+		t.Fatalf("err=%v", err)
+	}
+	_, err := normalizeImportRequests([]UpsertPersonRequest{
+		{Pernr: "1", DisplayName: "A", EffectiveDate: "2026-01-01"},
+		{Pernr: "2", DisplayName: "", EffectiveDate: "2026-01-01"},
+	})
+	var rowErr *ImportRowError
+	if !errors.As(err, &rowErr) || rowErr.Row != 2 || !errors.Is(err, ErrPersonDisplayNameRequired) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestApplyPersonVersion(t *testing.T) {
+	var versions []PersonVersion
+	versions = applyPersonVersion(versions, "2026-01-01", "Alice", PersonStatusActive)
+	versions = applyPersonVersion(versions, "2026-06-01", "Alice", PersonStatusInactive)
+	// A change between two recorded changes only lasts until the next one.
+	versions = applyPersonVersion(versions, "2026-03-01", "Alice Liddell", PersonStatusActive)
+	// A change before the first one is bounded by it.
+	versions = applyPersonVersion(versions, "2025-10-01", "A. Liddell", PersonStatusActive)
+	// Rewriting an existing change keeps its end.
+	versions = applyPersonVersion(versions, "2026-03-01", "Alice P. Liddell", PersonStatusActive)
+
+	want := []PersonVersion{
+		{EffectiveDate: "2025-10-01", EndDate: "2026-01-01", DisplayName: "A. Liddell", Status: PersonStatusActive},
+		{EffectiveDate: "2026-01-01", EndDate: "2026-03-01", DisplayName: "Alice", Status: PersonStatusActive},
+		{EffectiveDate: "2026-03-01", EndDate: "2026-06-01", DisplayName: "Alice P. Liddell", Status: PersonStatusActive},
+		{EffectiveDate: "2026-06-01", DisplayName: "Alice", Status: PersonStatusInactive},
+	}
+	if !reflect.DeepEqual(versions, want) {
+		t.Fatalf("versions=%+v", versions)
+	}
+
+	if v, ok := versionAsOf(versions, "2026-05-31"); !ok || v.DisplayName != "Alice P. Liddell" {
+		t.Fatalf("v=%+v ok=%v", v, ok)
+	}
+	if _, ok := versionAsOf(versions, "2025-09-30"); ok {
+		t.Fatal("expected no version before the first change")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	item, created, err := s.UpsertPerson(ctx, "t1", UpsertPersonRequest{Pernr: "0042", DisplayName: "Alice", EffectiveDate: "2026-01-01"})
+	if err != nil || !created || item.Pernr != "42" || item.PersonUUID == "" {
+		t.Fatalf("item=%+v created=%v err=%v", item, created, err)
+	}
+	if _, created, err := s.UpsertPerson(ctx, "t1", UpsertPersonRequest{Pernr: "42", DisplayName: "Alice", Status: "inactive", EffectiveDate: "2026-07-01"}); err != nil || created {
+		t.Fatalf("created=%v err=%v", created, err)
+	}
+	result, err := s.ImportPersons(ctx, "t1", []UpsertPersonRequest{
+		{Pernr: "7", DisplayName: "Bob", EffectiveDate: "2026-01-01"},
+		{Pernr: "42", DisplayName: "Alice L", EffectiveDate: "2026-02-01"},
+	})
+	if err != nil || result.Created != 1 || result.Updated != 1 {
+		t.Fatalf("result=%+v err=%v", result, err)
+	}
+
+	found, ok, err := s.FindByPernrAsOf(ctx, "t1", "42", "2026-08-01")
+	if err != nil || !ok || found.Status != PersonStatusInactive {
+		t.Fatalf("found=%+v ok=%v err=%v", found, ok, err)
+	}
+	found, ok, err = s.FindByPernrAsOf(ctx, "t1", "42", "2025-01-01")
+	if err != nil || !ok || found.Status != "" {
+		t.Fatalf("found=%+v ok=%v err=%v", found, ok, err)
+	}
+	if _, ok, _ := s.FindByPernrAsOf(ctx, "t2", "42", "2026-08-01"); ok {
+		t.Fatal("persons must be tenant scoped")
+	}
+
+	items, err := s.ListPersons(ctx, "t1", "2026-03-01", "", 10)
+	if err != nil || len(items) != 2 || items[0].Pernr != "7" || items[1].DisplayName != "Alice L" {
+		t.Fatalf("items=%+v err=%v", items, err)
+	}
+	items, _ = s.ListPersons(ctx, "t1", "2026-03-01", "bob", 10)
+	if len(items) != 1 || items[0].Pernr != "7" {
+		t.Fatalf("items=%+v", items)
+	}
+
+	detail, err := s.GetPerson(ctx, "t1", "42", "2026-03-01")
+	if err != nil || len(detail.Versions) != 3 || detail.DisplayName != "Alice L" {
+		t.Fatalf("detail=%+v err=%v", detail, err)
+	}
+
+	personUUID, ok, err := s.ResolvePersonUUID(ctx, "t1", "042")
+	if err != nil || !ok || personUUID != item.PersonUUID {
+		t.Fatalf("uuid=%q ok=%v err=%v", personUUID, ok, err)
+	}
+	if deleted, err := s.DeletePerson(ctx, "t1", "42"); err != nil || deleted != item.PersonUUID {
+		t.Fatalf("deleted=%q err=%v", deleted, err)
+	}
+	if _, err := s.DeletePerson(ctx, "t1", "42"); !errors.Is(err, ErrPersonNotFound) {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := s.GetPerson(ctx, "t1", "42", "2026-03-01"); !errors.Is(err, ErrPersonNotFound) {
+		t.Fatalf("err=%v", err)
+	}
+}
@@ -0,0 +1,104 @@
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE SCHEMA IF NOT EXISTS person;
+
+-- Person identity anchor: pernr is stored canonical (1-8 digits, no leading zeros) and unique per tenant.
+CREATE TABLE IF NOT EXISTS person.persons (
+  tenant_uuid uuid NOT NULL,
+  person_uuid uuid NOT NULL DEFAULT gen_random_uuid(),
+  pernr text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (tenant_uuid, person_uuid),
+  CONSTRAINT persons_tenant_pernr_key UNIQUE (tenant_uuid, pernr),
+  CONSTRAINT persons_pernr_format_check CHECK (pernr ~ '^[0-9]{1,8}$'),
+  CONSTRAINT persons_pernr_canonical_check CHECK (pernr = '0' OR pernr !~ '^0')
+);
+
+-- Effective-dated attributes; the timeline of one person never overlaps.
+CREATE TABLE IF NOT EXISTS person.person_versions (
+  id bigserial PRIMARY KEY,
+  tenant_uuid uuid NOT NULL,
+  person_uuid uuid NOT NULL,
+  validity daterange NOT NULL,
+  display_name text NOT NULL,
+  status text NOT NULL DEFAULT 'active',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT person_versions_person_fkey
+    FOREIGN KEY (tenant_uuid, person_uuid)
+    REFERENCES person.persons(tenant_uuid, person_uuid)
+    ON DELETE CASCADE,
+  CONSTRAINT person_versions_display_name_check CHECK (display_name <> '' AND btrim(display_name) = display_name),
+  CONSTRAINT person_versions_status_check CHECK (status IN ('active', 'inactive')),
+  CONSTRAINT person_versions_validity_check CHECK (NOT isempty(validity)),
+  CONSTRAINT person_versions_validity_bounds_check CHECK (lower_inc(validity) AND NOT upper_inc(validity)),
+  CONSTRAINT person_versions_no_overlap
+    EXCLUDE USING gist (
+      tenant_uuid gist_uuid_ops WITH =,
+      person_uuid gist_uuid_ops WITH =,
+      validity WITH &&
+    )
+);
+
+CREATE INDEX IF NOT EXISTS person_versions_lookup_btree
+  ON person.person_versions (tenant_uuid, person_uuid, lower(validity));
+
+ALTER TABLE person.persons ENABLE ROW LEVEL SECURITY;
+ALTER TABLE person.persons FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON person.persons;
+CREATE POLICY tenant_isolation ON person.persons
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
+
+ALTER TABLE person.person_versions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE person.person_versions FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON person.person_versions;
+CREATE POLICY tenant_isolation ON person.person_versions
+USING (tenant_uuid = current_setting('app.current_tenant')::uuid)
+WITH CHECK (tenant_uuid = current_setting('app.current_tenant')::uuid);
+
+-- Tenant hard delete (superadmin offboarding); versions go with their person through the FK.
+CREATE OR REPLACE FUNCTION person.purge_tenant_data(p_tenant_uuid uuid)
+RETURNS jsonb
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = pg_catalog, person, public
+AS $$
+DECLARE
+  v_versions bigint;
+  v_persons bigint;
+BEGIN
+  IF p_tenant_uuid IS NULL OR p_tenant_uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN
+    RAISE EXCEPTION USING
+      MESSAGE = 'PERSON_TENANT_PURGE_FORBIDDEN',
+      DETAIL = format('tenant_uuid=%s', p_tenant_uuid);
+  END IF;
+
+  PERFORM set_config('app.current_tenant', p_tenant_uuid::text, true);
+
+  DELETE FROM person.person_versions WHERE tenant_uuid = p_tenant_uuid;
+  GET DIAGNOSTICS v_versions = ROW_COUNT;
+  DELETE FROM person.persons WHERE tenant_uuid = p_tenant_uuid;
+  GET DIAGNOSTICS v_persons = ROW_COUNT;
+
+  RETURN jsonb_build_object('person.person_versions', v_versions, 'person.persons', v_persons);
+END;
+$$;
+
+REVOKE ALL ON FUNCTION person.purge_tenant_data(uuid) FROM PUBLIC;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_nobypassrls') THEN
+    EXECUTE 'GRANT USAGE ON SCHEMA person TO app_nobypassrls';
+    EXECUTE 'GRANT SELECT, INSERT, UPDATE, DELETE ON person.persons, person.person_versions TO app_nobypassrls';
+    EXECUTE 'GRANT USAGE, SELECT ON SEQUENCE person.person_versions_id_seq TO app_nobypassrls';
+  END IF;
+  IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'superadmin_runtime') THEN
+    EXECUTE 'GRANT USAGE ON SCHEMA person TO superadmin_runtime';
+    EXECUTE 'GRANT SELECT ON person.persons, person.person_versions TO superadmin_runtime';
+    EXECUTE 'GRANT EXECUTE ON FUNCTION person.purge_tenant_data(uuid) TO superadmin_runtime';
+  END IF;
+END
+$$;
@@ -0,0 +1,24 @@
+package person
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jacksonlee411/Bugs-And-Blossoms/modules/person/infrastructure/persistence"
+)
+
+type PGBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type Store = persistence.Store
+
+const PersonStatusActive = persistence.PersonStatusActive
+
+func NewPGStore(pool PGBeginner) Store {
+	return persistence.NewPGStore(pool)
+}
+
+func NewMemoryStore() Store {
+	return persistence.NewMemoryStore()
+}
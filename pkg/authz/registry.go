@@ -33,6 +33,7 @@ const (
 	ObjectIAMAuthz               = "iam.authz"
 	ObjectIAMDicts               = "iam.dicts"
 	ObjectIAMDictRelease         = "iam.dict_release"
+	ObjectIAMWebhooks            = "iam.webhooks"
 	ObjectCubeBoxConversations   = "cubebox.conversations"
 	ObjectCubeBoxModelProvider   = "cubebox.model_provider"
 	ObjectCubeBoxModelCredential = "cubebox.model_credential"
@@ -120,6 +121,7 @@ var registryEntries = []AuthzCapability{
 	capability(ObjectIAMDicts, ActionRead, "iam", "字典配置", "查看", ScopeDimensionNone, true, CapabilitySurfaceTenantAPI, 100),
 	capability(ObjectIAMDicts, ActionAdmin, "iam", "字典配置", "管理", ScopeDimensionNone, true, CapabilitySurfaceTenantAPI, 110),
 	capability(ObjectIAMDictRelease, ActionAdmin, "iam", "字典发布", "管理", ScopeDimensionNone, true, CapabilitySurfaceTenantAPI, 120),
+	capability(ObjectIAMWebhooks, ActionAdmin, "iam", "Webhook 订阅", "管理", ScopeDimensionNone, true, CapabilitySurfaceTenantAPI, 130),
 	capability(ObjectOrgUnitOrgUnits, ActionRead, "orgunit", "组织管理", "查看", ScopeDimensionOrganization, true, CapabilitySurfaceTenantAPI, 200),
 	capability(ObjectOrgUnitOrgUnits, ActionAdmin, "orgunit", "组织管理", "管理", ScopeDimensionOrganization, true, CapabilitySurfaceTenantAPI, 210),
 	capability(ObjectCubeBoxConversations, ActionRead, "cubebox", "CubeBox 对话", "查看", ScopeDimensionNone, true, CapabilitySurfaceTenantAPI, 300),
@@ -0,0 +1,289 @@
+package dict
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheMaxEntries and DefaultCacheTTL apply when CacheOptions leaves the bound unset.
+const (
+	DefaultCacheMaxEntries = 10000
+	DefaultCacheTTL        = 5 * time.Minute
+)
+
+type CacheOptions struct {
+	// MaxEntries bounds the number of cached lookups; the least recently used entry is evicted first.
+	MaxEntries int
+	// TTL bounds how long an entry may be served without an invalidation; it is a safety net for lost notifications.
+	TTL time.Duration
+	Now func() time.Time
+}
+
+type CacheStats struct {
+	Hits          uint64
+	Misses        uint64
+	Bypasses      uint64
+	Evictions     uint64
+	Invalidations uint64
+	Entries       int
+}
+
+type cacheScope struct {
+	tenantID string
+	dictCode string
+}
+
+type cacheKey struct {
+	scope cacheScope
+	// asOfDay is the as_of bucket. Dict validity (enabled_on/disabled_on) changes on day boundaries,
+	// so the bucket is the exact day and a lookup never crosses an effective-date boundary.
+	asOfDay string
+	kind    byte
+	arg     string
+}
+
+const (
+	cacheKindLabel   byte = 'l'
+	cacheKindOptions byte = 'o'
+)
+
+type cacheEntry struct {
+	key       cacheKey
+	label     string
+	found     bool
+	options   []Option
+	expiresAt time.Time
+}
+
+// CachingResolver decorates a Resolver with a bounded LRU cache keyed by (tenant, dict_code, as_of day).
+// Entries are dropped through Invalidate/InvalidateAll, which the dict change listener drives from
+// Postgres LISTEN/NOTIFY so that every server instance observes committed dict events.
+type CachingResolver struct {
+	next       Resolver
+	maxEntries int
+	ttl        time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[cacheKey]*list.Element
+	byScope map[cacheScope]map[cacheKey]struct{}
+	// epoch and generations guard against storing a value that was loaded before an invalidation landed.
+	epoch       uint64
+	generations map[cacheScope]uint64
+	stats       CacheStats
+}
+
+func NewCachingResolver(next Resolver, opts CacheOptions) (*CachingResolver, error) {
+	if isNilResolver(next) {
+		return nil, errors.New("dict: resolver is nil")
+	}
+	maxEntries := opts.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheMaxEntries
+	}
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &CachingResolver{
+		next:        next,
+		maxEntries:  maxEntries,
+		ttl:         ttl,
+		now:         now,
+		lru:         list.New(),
+		entries:     make(map[cacheKey]*list.Element),
+		byScope:     make(map[cacheScope]map[cacheKey]struct{}),
+		generations: make(map[cacheScope]uint64),
+	}, nil
+}
+
+func (c *CachingResolver) ResolveValueLabel(ctx context.Context, tenantID string, asOf string, dictCode string, code string) (string, bool, error) {
+	key, ok := newCacheKey(tenantID, asOf, dictCode, cacheKindLabel, code)
+	if !ok {
+		c.countBypass()
+		return c.next.ResolveValueLabel(ctx, tenantID, asOf, dictCode, code)
+	}
+	if entry, hit := c.lookup(key); hit {
+		return entry.label, entry.found, nil
+	}
+
+	token := c.fillToken(key.scope)
+	label, found, err := c.next.ResolveValueLabel(ctx, tenantID, asOf, dictCode, code)
+	if err != nil {
+		return "", false, err
+	}
+	c.store(token, cacheEntry{key: key, label: label, found: found})
+	return label, found, nil
+}
+
+func (c *CachingResolver) ListOptions(ctx context.Context, tenantID string, asOf string, dictCode string, keyword string, limit int) ([]Option, error) {
+	key, ok := newCacheKey(tenantID, asOf, dictCode, cacheKindOptions, keyword+"\x00"+strconv.Itoa(limit))
+	if !ok {
+		c.countBypass()
+		return c.next.ListOptions(ctx, tenantID, asOf, dictCode, keyword, limit)
+	}
+	if entry, hit := c.lookup(key); hit {
+		return cloneOptions(entry.options), nil
+	}
+
+	token := c.fillToken(key.scope)
+	options, err := c.next.ListOptions(ctx, tenantID, asOf, dictCode, keyword, limit)
+	if err != nil {
+		return nil, err
+	}
+	c.store(token, cacheEntry{key: key, options: cloneOptions(options)})
+	return options, nil
+}
+
+// Invalidate drops every as_of bucket cached for (tenant, dict_code).
+func (c *CachingResolver) Invalidate(tenantID string, dictCode string) {
+	scope := cacheScope{tenantID: strings.TrimSpace(tenantID), dictCode: strings.TrimSpace(dictCode)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generations[scope]++
+	for key := range c.byScope[scope] {
+		c.removeLocked(c.entries[key])
+	}
+	c.stats.Invalidations++
+}
+
+// InvalidateAll drops the whole cache, e.g. after the notification stream was interrupted.
+func (c *CachingResolver) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.epoch++
+	c.lru.Init()
+	c.entries = make(map[cacheKey]*list.Element)
+	c.byScope = make(map[cacheScope]map[cacheKey]struct{})
+	c.generations = make(map[cacheScope]uint64)
+	c.stats.Invalidations++
+}
+
+func (c *CachingResolver) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	return stats
+}
+
+type cacheFillToken struct {
+	scope      cacheScope
+	epoch      uint64
+	generation uint64
+}
+
+func (c *CachingResolver) fillToken(scope cacheScope) cacheFillToken {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return cacheFillToken{scope: scope, epoch: c.epoch, generation: c.generations[scope]}
+}
+
+func (c *CachingResolver) lookup(key cacheKey) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return cacheEntry{}, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.removeLocked(elem)
+		c.stats.Misses++
+		return cacheEntry{}, false
+	}
+	c.lru.MoveToFront(elem)
+	c.stats.Hits++
+	return *entry, true
+}
+
+func (c *CachingResolver) store(token cacheFillToken, entry cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if token.epoch != c.epoch || token.generation != c.generations[token.scope] {
+		return
+	}
+	entry.expiresAt = c.now().Add(c.ttl)
+	if elem, ok := c.entries[entry.key]; ok {
+		elem.Value = &entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[entry.key] = c.lru.PushFront(&entry)
+	keys := c.byScope[entry.key.scope]
+	if keys == nil {
+		keys = make(map[cacheKey]struct{})
+		c.byScope[entry.key.scope] = keys
+	}
+	keys[entry.key] = struct{}{}
+	for c.lru.Len() > c.maxEntries {
+		c.removeLocked(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *CachingResolver) removeLocked(elem *list.Element) {
+	if elem == nil {
+		return
+	}
+	entry := elem.Value.(*cacheEntry)
+	c.lru.Remove(elem)
+	delete(c.entries, entry.key)
+	if keys := c.byScope[entry.key.scope]; keys != nil {
+		delete(keys, entry.key)
+		if len(keys) == 0 {
+			delete(c.byScope, entry.key.scope)
+		}
+	}
+}
+
+func (c *CachingResolver) countBypass() {
+	c.mu.Lock()
+	c.stats.Bypasses++
+	c.mu.Unlock()
+}
+
+func newCacheKey(tenantID string, asOf string, dictCode string, kind byte, arg string) (cacheKey, bool) {
+	tenantID = strings.TrimSpace(tenantID)
+	dictCode = strings.TrimSpace(dictCode)
+	asOf = strings.TrimSpace(asOf)
+	if tenantID == "" || dictCode == "" {
+		return cacheKey{}, false
+	}
+	day, err := time.Parse(time.DateOnly, asOf)
+	if err != nil {
+		return cacheKey{}, false
+	}
+	return cacheKey{
+		scope:   cacheScope{tenantID: tenantID, dictCode: dictCode},
+		asOfDay: day.Format(time.DateOnly),
+		kind:    kind,
+		arg:     arg,
+	}, true
+}
+
+func cloneOptions(in []Option) []Option {
+	if in == nil {
+		return nil
+	}
+	out := make([]Option, len(in))
+	for i, item := range in {
+		out[i] = item
+		if item.DisabledOn != nil {
+			v := *item.DisabledOn
+			out[i].DisabledOn = &v
+		}
+	}
+	return out
+}
@@ -0,0 +1,253 @@
+package dict
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type countingResolver struct {
+	mu          sync.Mutex
+	labelCalls  int
+	optionCalls int
+	labels      map[string]string
+	options     []Option
+	err         error
+	beforeLoad  func()
+}
+
+func (r *countingResolver) ResolveValueLabel(_ context.Context, tenantID string, asOf string, dictCode string, code string) (string, bool, error) {
+	r.mu.Lock()
+	r.labelCalls++
+	hook := r.beforeLoad
+	r.mu.Unlock()
+	if hook != nil {
+		hook()
+	}
+	if r.err != nil {
+		return "", false, r.err
+	}
+	label, ok := r.labels[tenantID+"|"+asOf+"|"+dictCode+"|"+code]
+	return label, ok, nil
+}
+
+func (r *countingResolver) ListOptions(context.Context, string, string, string, string, int) ([]Option, error) {
+	r.mu.Lock()
+	r.optionCalls++
+	hook := r.beforeLoad
+	r.mu.Unlock()
+	if hook != nil {
+		hook()
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return cloneOptions(r.options), nil
+}
+
+func newTestCache(t *testing.T, next Resolver, opts CacheOptions) *CachingResolver {
+	t.Helper()
+	c, err := NewCachingResolver(next, opts)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	return c
+}
+
+func TestNewCachingResolver_RejectsNil(t *testing.T) {
+	if _, err := NewCachingResolver(nil, CacheOptions{}); err == nil {
+		t.Fatal("expected error")
+	}
+	var typedNil *countingResolver
+	if _, err := NewCachingResolver(typedNil, CacheOptions{}); err == nil {
+		t.Fatal("expected typed nil error")
+	}
+}
+
+func TestCachingResolver_ResolveValueLabelHitMiss(t *testing.T) {
+	next := &countingResolver{labels: map[string]string{"t1|2026-01-01|org_type|10": "部门"}}
+	c := newTestCache(t, next, CacheOptions{})
+	ctx := context.Background()
+
+	for range 3 {
+		label, ok, err := c.ResolveValueLabel(ctx, "t1", "2026-01-01", "org_type", "10")
+		if err != nil || !ok || label != "部门" {
+			t.Fatalf("label=%q ok=%v err=%v", label, ok, err)
+		}
+	}
+	if _, ok, err := c.ResolveValueLabel(ctx, "t1", "2026-01-01", "org_type", "99"); err != nil || ok {
+		t.Fatalf("ok=%v err=%v", ok, err)
+	}
+	if _, ok, _ := c.ResolveValueLabel(ctx, "t1", "2026-01-01", "org_type", "99"); ok {
+		t.Fatal("negative lookup should stay negative")
+	}
+	if next.labelCalls != 2 {
+		t.Fatalf("labelCalls=%d", next.labelCalls)
+	}
+	stats := c.Stats()
+	if stats.Hits != 3 || stats.Misses != 2 || stats.Entries != 2 {
+		t.Fatalf("stats=%+v", stats)
+	}
+}
+
+func TestCachingResolver_AsOfBucketsAreDays(t *testing.T) {
+	next := &countingResolver{labels: map[string]string{
+		"t1|2026-01-01|org_type|10": "部门",
+		"t1|2026-01-02|org_type|10": "部门（新）",
+	}}
+	c := newTestCache(t, next, CacheOptions{})
+	ctx := context.Background()
+
+	before, _, _ := c.ResolveValueLabel(ctx, "t1", "2026-01-01", "org_type", "10")
+	after, _, _ := c.ResolveValueLabel(ctx, "t1", "2026-01-02", "org_type", "10")
+	if before != "部门" || after != "部门（新）" {
+		t.Fatalf("before=%q after=%q", before, after)
+	}
+	if next.labelCalls != 2 {
+		t.Fatalf("labelCalls=%d", next.labelCalls)
+	}
+}
+
+func TestCachingResolver_BypassesInvalidKeys(t *testing.T) {
+	next := &countingResolver{}
+	c := newTestCache(t, next, CacheOptions{})
+	ctx := context.Background()
+
+	_, _, _ = c.ResolveValueLabel(ctx, "t1", "not-a-day", "org_type", "10")
+	_, _, _ = c.ResolveValueLabel(ctx, "", "2026-01-01", "org_type", "10")
+	_, _ = c.ListOptions(ctx, "t1", "2026-01-01", " ", "", 10)
+	if next.labelCalls != 2 || next.optionCalls != 1 {
+		t.Fatalf("labelCalls=%d optionCalls=%d", next.labelCalls, next.optionCalls)
+	}
+	if stats := c.Stats(); stats.Bypasses != 3 || stats.Entries != 0 {
+		t.Fatalf("stats=%+v", stats)
+	}
+}
+
+func TestCachingResolver_ErrorsAreNotCached(t *testing.T) {
+	next := &countingResolver{err: errors.New("boom")}
+	c := newTestCache(t, next, CacheOptions{})
+	ctx := context.Background()
+
+	if _, _, err := c.ResolveValueLabel(ctx, "t1", "2026-01-01", "org_type", "10"); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := c.ListOptions(ctx, "t1", "2026-01-01", "org_type", "", 10); err == nil {
+		t.Fatal("expected error")
+	}
+	if stats := c.Stats(); stats.Entries != 0 {
+		t.Fatalf("stats=%+v", stats)
+	}
+}
+
+func TestCachingResolver_ListOptionsReturnsCopies(t *testing.T) {
+	disabledOn := "2026-12-31"
+	next := &countingResolver{options: []Option{{Code: "10", Label: "部门", DisabledOn: &disabledOn}}}
+	c := newTestCache(t, next, CacheOptions{})
+	ctx := context.Background()
+
+	first, err := c.ListOptions(ctx, "t1", "2026-01-01", "org_type", "", 10)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	first[0].Label = "mutated"
+	*first[0].DisabledOn = "mutated"
+
+	second, err := c.ListOptions(ctx, "t1", "2026-01-01", "org_type", "", 10)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if second[0].Label != "部门" || *second[0].DisabledOn != "2026-12-31" {
+		t.Fatalf("second=%+v", second[0])
+	}
+	if _, err := c.ListOptions(ctx, "t1", "2026-01-01", "org_type", "部", 10); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if next.optionCalls != 2 {
+		t.Fatalf("optionCalls=%d", next.optionCalls)
+	}
+}
+
+func TestCachingResolver_InvalidateDropsAllBucketsOfDict(t *testing.T) {
+	next := &countingResolver{labels: map[string]string{}}
+	c := newTestCache(t, next, CacheOptions{})
+	ctx := context.Background()
+
+	_, _, _ = c.ResolveValueLabel(ctx, "t1", "2026-01-01", "org_type", "10")
+	_, _, _ = c.ResolveValueLabel(ctx, "t1", "2026-02-01", "org_type", "10")
+	_, _ = c.ListOptions(ctx, "t1", "2026-01-01", "org_type", "", 10)
+	_, _, _ = c.ResolveValueLabel(ctx, "t1", "2026-01-01", "cost_center", "10")
+	_, _, _ = c.ResolveValueLabel(ctx, "t2", "2026-01-01", "org_type", "10")
+
+	c.Invalidate(" t1 ", "org_type")
+	if stats := c.Stats(); stats.Entries != 2 || stats.Invalidations != 1 {
+		t.Fatalf("stats=%+v", stats)
+	}
+
+	c.InvalidateAll()
+	if stats := c.Stats(); stats.Entries != 0 || stats.Invalidations != 2 {
+		t.Fatalf("stats=%+v", stats)
+	}
+}
+
+func TestCachingResolver_InvalidationDuringLoadIsNotStored(t *testing.T) {
+	next := &countingResolver{labels: map[string]string{"t1|2026-01-01|org_type|10": "stale"}}
+	c := newTestCache(t, next, CacheOptions{})
+	next.beforeLoad = func() { c.Invalidate("t1", "org_type") }
+	ctx := context.Background()
+
+	if label, _, _ := c.ResolveValueLabel(ctx, "t1", "2026-01-01", "org_type", "10"); label != "stale" {
+		t.Fatalf("label=%q", label)
+	}
+	if stats := c.Stats(); stats.Entries != 0 {
+		t.Fatalf("stats=%+v", stats)
+	}
+
+	next.beforeLoad = func() { c.InvalidateAll() }
+	_, _ = c.ListOptions(ctx, "t1", "2026-01-01", "org_type", "", 10)
+	if stats := c.Stats(); stats.Entries != 0 {
+		t.Fatalf("stats=%+v", stats)
+	}
+}
+
+func TestCachingResolver_EvictsLeastRecentlyUsed(t *testing.T) {
+	next := &countingResolver{labels: map[string]string{}}
+	c := newTestCache(t, next, CacheOptions{MaxEntries: 2})
+	ctx := context.Background()
+
+	_, _, _ = c.ResolveValueLabel(ctx, "t1", "2026-01-01", "org_type", "a")
+	_, _, _ = c.ResolveValueLabel(ctx, "t1", "2026-01-01", "org_type", "b")
+	_, _, _ = c.ResolveValueLabel(ctx, "t1", "2026-01-01", "org_type", "a")
+	_, _, _ = c.ResolveValueLabel(ctx, "t1", "2026-01-01", "org_type", "c")
+
+	stats := c.Stats()
+	if stats.Entries != 2 || stats.Evictions != 1 {
+		t.Fatalf("stats=%+v", stats)
+	}
+	_, _, _ = c.ResolveValueLabel(ctx, "t1", "2026-01-01", "org_type", "a")
+	if got := c.Stats().Hits; got != 2 {
+		t.Fatalf("hits=%d", got)
+	}
+	_, _, _ = c.ResolveValueLabel(ctx, "t1", "2026-01-01", "org_type", "b")
+	if next.labelCalls != 4 {
+		t.Fatalf("labelCalls=%d", next.labelCalls)
+	}
+}
+
+func TestCachingResolver_TTLExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	next := &countingResolver{labels: map[string]string{}}
+	c := newTestCache(t, next, CacheOptions{TTL: time.Minute, Now: func() time.Time { return now }})
+	ctx := context.Background()
+
+	_, _, _ = c.ResolveValueLabel(ctx, "t1", "2026-01-01", "org_type", "10")
+	now = now.Add(30 * time.Second)
+	_, _, _ = c.ResolveValueLabel(ctx, "t1", "2026-01-01", "org_type", "10")
+	now = now.Add(time.Minute)
+	_, _, _ = c.ResolveValueLabel(ctx, "t1", "2026-01-01", "org_type", "10")
+	if next.labelCalls != 2 {
+		t.Fatalf("labelCalls=%d", next.labelCalls)
+	}
+}
@@ -31,11 +31,7 @@ var registry = struct {
 }{}
 
 func RegisterResolver(r Resolver) error {
-	if r == nil {
-		return errors.New("dict: resolver is nil")
-	}
-	v := reflect.ValueOf(r)
-	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface || v.Kind() == reflect.Slice || v.Kind() == reflect.Map || v.Kind() == reflect.Func || v.Kind() == reflect.Chan) && v.IsNil() {
+	if isNilResolver(r) {
 		return errors.New("dict: resolver is nil")
 	}
 	registry.mu.Lock()
@@ -82,3 +78,11 @@ func currentResolver() (Resolver, error) {
 	}
 	return r, nil
 }
+
+func isNilResolver(r Resolver) bool {
+	if r == nil {
+		return true
+	}
+	v := reflect.ValueOf(r)
+	return (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface || v.Kind() == reflect.Slice || v.Kind() == reflect.Map || v.Kind() == reflect.Func || v.Kind() == reflect.Chan) && v.IsNil()
+}
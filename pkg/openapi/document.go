@@ -0,0 +1,115 @@
+package openapi
+
+const Version = "3.1.0"
+
+// Document is the subset of an OpenAPI 3.1 document the server emits. Schemas use the JSON Schema 2020-12
+// dialect that 3.1 adopts, so nullability is expressed with type arrays rather than "nullable".
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty"`
+	Put    *Operation `json:"put,omitempty"`
+	Post   *Operation `json:"post,omitempty"`
+	Delete *Operation `json:"delete,omitempty"`
+	Patch  *Operation `json:"patch,omitempty"`
+}
+
+// Operation carries the route metadata the server already enforces as x- extensions so client teams can
+// see which capability a call needs without reading the authz registry.
+type Operation struct {
+	OperationID        string               `json:"operationId"`
+	Summary            string               `json:"summary,omitempty"`
+	Tags               []string             `json:"tags,omitempty"`
+	Parameters         []Parameter          `json:"parameters,omitempty"`
+	RequestBody        *RequestBody         `json:"requestBody,omitempty"`
+	Responses          map[string]*Response `json:"responses"`
+	RouteClass         string               `json:"x-route-class"`
+	AuthzCapabilityKey string               `json:"x-authz-capability-key,omitempty"`
+	CubeBoxCallable    bool                 `json:"x-cubebox-callable,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// SetOperation attaches op to the item under the given HTTP method. It reports false for methods the
+// document model does not carry.
+func (p *PathItem) SetOperation(method string, op *Operation) bool {
+	switch method {
+	case "GET":
+		p.Get = op
+	case "PUT":
+		p.Put = op
+	case "POST":
+		p.Post = op
+	case "DELETE":
+		p.Delete = op
+	case "PATCH":
+		p.Patch = op
+	default:
+		return false
+	}
+	return true
+}
+
+// Operation returns the operation registered under method, or nil.
+func (p *PathItem) Operation(method string) *Operation {
+	switch method {
+	case "GET":
+		return p.Get
+	case "PUT":
+		return p.Put
+	case "POST":
+		return p.Post
+	case "DELETE":
+		return p.Delete
+	case "PATCH":
+		return p.Patch
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,185 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const componentSchemaPrefix = "#/components/schemas/"
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+
+	componentNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+)
+
+// Schemas derives JSON schemas from Go types the way encoding/json would serialize them and collects
+// named struct types as reusable components.
+type Schemas struct {
+	names   map[reflect.Type]string
+	taken   map[string]reflect.Type
+	schemas map[string]*Schema
+}
+
+func NewSchemas() *Schemas {
+	return &Schemas{
+		names:   map[reflect.Type]string{},
+		taken:   map[string]reflect.Type{},
+		schemas: map[string]*Schema{},
+	}
+}
+
+// For returns the schema of v's type, registering named structs as components.
+func (s *Schemas) For(v any) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+	return s.schemaFor(reflect.TypeOf(v))
+}
+
+// Named registers v's type under an explicit component name (used when an existing contract already
+// refers to the schema by that name) and returns a reference to it.
+func (s *Schemas) Named(name string, v any) *Schema {
+	t := derefType(reflect.TypeOf(v))
+	if existing, ok := s.names[t]; ok {
+		return refSchema(existing)
+	}
+	s.names[t] = name
+	s.taken[name] = t
+	s.schemas[name] = s.structSchema(t)
+	return refSchema(name)
+}
+
+// Components returns the registered component schemas.
+func (s *Schemas) Components() map[string]*Schema {
+	out := make(map[string]*Schema, len(s.schemas))
+	for name, schema := range s.schemas {
+		out[name] = schema
+	}
+	return out
+}
+
+func (s *Schemas) schemaFor(t reflect.Type) *Schema {
+	t = derefType(t)
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+		return refSchema(s.register(t))
+	default:
+		return &Schema{}
+	}
+}
+
+func (s *Schemas) register(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	name := componentName(t)
+	if other, ok := s.taken[name]; ok && other != t {
+		name = componentName(t) + "_" + componentNameUnsafe.ReplaceAllString(t.PkgPath(), "_")
+	}
+	s.names[t] = name
+	s.taken[name] = t
+	// Register before walking the fields so self-referencing types resolve to the same component.
+	s.schemas[name] = &Schema{}
+	*s.schemas[name] = *s.structSchema(t)
+	return name
+}
+
+func (s *Schemas) structSchema(t reflect.Type) *Schema {
+	out := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.addFields(out, t)
+	sort.Strings(out.Required)
+	if len(out.Properties) == 0 {
+		out.Properties = nil
+	}
+	return out
+}
+
+func (s *Schemas) addFields(out *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && derefType(field.Type).Kind() == reflect.Struct {
+			s.addFields(out, derefType(field.Type))
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema := s.schemaFor(field.Type)
+		if strings.Contains(opts, "string") && schema.Ref == "" {
+			schema = &Schema{Type: "string"}
+		}
+		optional := strings.Contains(opts, "omitempty") || strings.Contains(opts, "omitzero")
+		if field.Type.Kind() == reflect.Pointer {
+			schema = nullable(schema)
+			optional = true
+		}
+		out.Properties[name] = schema
+		if !optional {
+			out.Required = append(out.Required, name)
+		}
+	}
+}
+
+func nullable(schema *Schema) *Schema {
+	if typ, ok := schema.Type.(string); ok {
+		copied := *schema
+		copied.Type = []string{typ, "null"}
+		return &copied
+	}
+	return schema
+}
+
+func refSchema(name string) *Schema {
+	return &Schema{Ref: componentSchemaPrefix + name}
+}
+
+func componentName(t reflect.Type) string {
+	return componentNameUnsafe.ReplaceAllString(t.Name(), "_")
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
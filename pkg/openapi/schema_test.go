@@ -0,0 +1,113 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaTestBase struct {
+	ID string `json:"id"`
+}
+
+type schemaTestNode struct {
+	schemaTestBase
+	Name      string            `json:"name"`
+	Note      string            `json:"note,omitempty"`
+	Parent    *schemaTestNode   `json:"parent"`
+	Children  []schemaTestNode  `json:"children"`
+	Labels    map[string]string `json:"labels"`
+	Raw       json.RawMessage   `json:"raw"`
+	CreatedAt time.Time         `json:"created_at"`
+	Count     int64             `json:"count"`
+	Size      *int              `json:"size"`
+	Secret    string            `json:"-"`
+	hidden    string
+}
+
+func TestSchemasFor_StructComponent(t *testing.T) {
+	s := NewSchemas()
+	ref := s.For(schemaTestNode{})
+	if ref.Ref != "#/components/schemas/schemaTestNode" {
+		t.Fatalf("ref=%+v", ref)
+	}
+	node := s.Components()["schemaTestNode"]
+	if node == nil || node.Type != "object" {
+		t.Fatalf("node=%+v", node)
+	}
+	wantRequired := []string{"children", "count", "created_at", "id", "labels", "name", "raw"}
+	if !reflect.DeepEqual(node.Required, wantRequired) {
+		t.Fatalf("required=%v", node.Required)
+	}
+	if _, ok := node.Properties["Secret"]; ok {
+		t.Fatal("json:\"-\" field leaked")
+	}
+	if _, ok := node.Properties["hidden"]; ok {
+		t.Fatal("unexported field leaked")
+	}
+	if got := node.Properties["parent"]; got.Ref != ref.Ref {
+		t.Fatalf("parent=%+v", got)
+	}
+	if got := node.Properties["children"]; got.Type != "array" || got.Items.Ref != ref.Ref {
+		t.Fatalf("children=%+v", got)
+	}
+	if got := node.Properties["created_at"]; got.Type != "string" || got.Format != "date-time" {
+		t.Fatalf("created_at=%+v", got)
+	}
+	if got := node.Properties["labels"]; got.Type != "object" || got.AdditionalProperties.Type != "string" {
+		t.Fatalf("labels=%+v", got)
+	}
+	if got := node.Properties["size"]; !reflect.DeepEqual(got.Type, []string{"integer", "null"}) {
+		t.Fatalf("size=%+v", got)
+	}
+	if got := node.Properties["raw"]; got.Type != nil {
+		t.Fatalf("raw=%+v", got)
+	}
+}
+
+func TestSchemasNamed_UsesExplicitName(t *testing.T) {
+	s := NewSchemas()
+	ref := s.Named("nodeAPIResponse", schemaTestNode{})
+	if ref.Ref != "#/components/schemas/nodeAPIResponse" {
+		t.Fatalf("ref=%+v", ref)
+	}
+	if again := s.For(&schemaTestNode{}); again.Ref != ref.Ref {
+		t.Fatalf("again=%+v", again)
+	}
+	if _, ok := s.Components()["schemaTestNode"]; ok {
+		t.Fatal("type registered twice")
+	}
+}
+
+func TestSchemasFor_ScalarsAndAnonymousStructs(t *testing.T) {
+	s := NewSchemas()
+	if got := s.For([]byte("x")); got.Type != "string" || got.Format != "byte" {
+		t.Fatalf("bytes=%+v", got)
+	}
+	if got := s.For(map[string]any{}); got.Type != "object" || got.AdditionalProperties == nil {
+		t.Fatalf("map=%+v", got)
+	}
+	got := s.For(struct {
+		Replayed int `json:"replayed"`
+	}{})
+	if got.Ref != "" || got.Properties["replayed"].Type != "integer" {
+		t.Fatalf("anonymous=%+v", got)
+	}
+	if got := s.For(nil); got.Type != nil || got.Ref != "" {
+		t.Fatalf("nil=%+v", got)
+	}
+}
+
+func TestPathItemOperations(t *testing.T) {
+	var item PathItem
+	op := &Operation{OperationID: "x"}
+	for _, method := range []string{"GET", "PUT", "POST", "DELETE", "PATCH"} {
+		if !item.SetOperation(method, op) || item.Operation(method) != op {
+			t.Fatalf("method=%s", method)
+		}
+	}
+	if item.SetOperation("TRACE", op) || item.Operation("TRACE") != nil {
+		t.Fatal("unexpected TRACE support")
+	}
+}
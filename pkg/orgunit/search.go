@@ -0,0 +1,336 @@
+package orgunit
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mozillazg/go-pinyin"
+)
+
+const (
+	SearchFieldOrgCode        = "org_code"
+	SearchFieldName           = "name"
+	SearchFieldFullNamePath   = "full_name_path"
+	SearchFieldPinyin         = "pinyin"
+	SearchFieldPinyinInitials = "pinyin_initials"
+
+	// SearchExactScore is the lowest score of an exact match (org_code, name, full pinyin or pinyin
+	// initials). A single exact match is safe to select without asking the user.
+	SearchExactScore = 0.93
+
+	// SearchFuzzyThreshold is the minimum trigram similarity for a misspelled name to count as a match.
+	SearchFuzzyThreshold = 0.3
+
+	searchPinyinFillBatch = 500
+)
+
+// SearchDocument is what a search query is matched against; pinyin is derived from Name.
+type SearchDocument struct {
+	OrgCode      string
+	Name         string
+	FullNamePath string
+}
+
+// SearchHighlight is a matched rune range [Start, End) in Field (name, full_name_path or org_code).
+type SearchHighlight struct {
+	Field string `json:"field"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// SearchMatch explains why a document matched: the strongest field and its score in (0, 1]. Fuzzy
+// matches carry no highlights.
+type SearchMatch struct {
+	Field      string
+	Score      float64
+	Fuzzy      bool
+	Highlights []SearchHighlight
+}
+
+// IsExact reports whether the match is strong enough to select on its own.
+func (m SearchMatch) IsExact() bool {
+	return m.Score >= SearchExactScore
+}
+
+// Tier groups matches by kind: 2 for a hit on the unit itself (code, name, pinyin), 1 for a hit on an
+// ancestor through the full name path, 0 for fuzzy. Results keep only their best tier, so "研发" still
+// resolves to 研发部 instead of also returning every unit below it.
+func (m SearchMatch) Tier() int {
+	switch {
+	case m.Fuzzy:
+		return 0
+	case m.Field == SearchFieldFullNamePath:
+		return 1
+	default:
+		return 2
+	}
+}
+
+type searchSyllable struct {
+	pinyin string
+	index  int
+}
+
+var searchPinyinArgs = pinyin.NewArgs()
+
+// searchSyllables splits name into pinyin syllables: one per Han character and one per ASCII letter or
+// digit. Other runes are skipped but still count toward rune offsets. Polyphonic characters use their
+// most common reading.
+func searchSyllables(name string) []searchSyllable {
+	out := make([]searchSyllable, 0, len(name))
+	index := 0
+	for _, r := range name {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			out = append(out, searchSyllable{pinyin: string(unicode.ToLower(r)), index: index})
+		case unicode.Is(unicode.Han, r):
+			if py := pinyin.SinglePinyin(r, searchPinyinArgs); len(py) > 0 && py[0] != "" {
+				out = append(out, searchSyllable{pinyin: py[0], index: index})
+			}
+		}
+		index++
+	}
+	return out
+}
+
+// SearchPinyin returns the toneless full pinyin ("yanfabu") and initials ("yfb") of name.
+func SearchPinyin(name string) (string, string) {
+	var full, initials strings.Builder
+	for _, s := range searchSyllables(name) {
+		full.WriteString(s.pinyin)
+		initials.WriteByte(s.pinyin[0])
+	}
+	return full.String(), initials.String()
+}
+
+// SearchQueryPinyin is the pinyin form of a query: Chinese input is transliterated so homophones and
+// wrong characters still meet on pinyin, anything else is lower-cased with separators removed.
+func SearchQueryPinyin(query string) string {
+	if containsHan(query) {
+		full, _ := SearchPinyin(query)
+		return full
+	}
+	return compactSearchQuery(query)
+}
+
+func containsHan(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+func compactSearchQuery(query string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(query) {
+		if unicode.IsSpace(r) || r == '\'' || r == '-' || r == '_' {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// MatchSearch scores doc against query. It reports false when nothing matches.
+func MatchSearch(query string, doc SearchDocument) (SearchMatch, bool) {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return SearchMatch{}, false
+	}
+	best := SearchMatch{}
+	consider := func(m SearchMatch) {
+		if m.Score > best.Score {
+			best = m
+		}
+	}
+
+	code := strings.ToLower(strings.TrimSpace(doc.OrgCode))
+	name := strings.ToLower(strings.TrimSpace(doc.Name))
+	path := strings.ToLower(strings.TrimSpace(doc.FullNamePath))
+	compact := compactSearchQuery(q)
+	queryPinyin := SearchQueryPinyin(q)
+	syllables := searchSyllables(strings.TrimSpace(doc.Name))
+	fullPinyin, initials := SearchPinyin(strings.TrimSpace(doc.Name))
+
+	if code != "" {
+		switch {
+		case code == q:
+			consider(SearchMatch{Field: SearchFieldOrgCode, Score: 1, Highlights: runeHighlight(SearchFieldOrgCode, code, 0, len(code))})
+		case strings.HasPrefix(code, q):
+			consider(SearchMatch{Field: SearchFieldOrgCode, Score: 0.82, Highlights: runeHighlight(SearchFieldOrgCode, code, 0, len(q))})
+		}
+	}
+	if name != "" {
+		if name == q {
+			consider(SearchMatch{Field: SearchFieldName, Score: 0.98, Highlights: runeHighlight(SearchFieldName, name, 0, len(name))})
+		} else if at := strings.Index(name, q); at >= 0 {
+			score := 0.85
+			if at == 0 {
+				score = 0.88
+			}
+			consider(SearchMatch{Field: SearchFieldName, Score: score, Highlights: runeHighlight(SearchFieldName, name, at, at+len(q))})
+		}
+	}
+	if len(syllables) > 0 {
+		nameRunes := len([]rune(strings.TrimSpace(doc.Name)))
+		switch {
+		case queryPinyin != "" && queryPinyin == fullPinyin:
+			consider(SearchMatch{Field: SearchFieldPinyin, Score: 0.95, Highlights: []SearchHighlight{{Field: SearchFieldName, Start: 0, End: nameRunes}}})
+		case compact != "" && compact == initials && len(compact) > 1:
+			consider(SearchMatch{Field: SearchFieldPinyinInitials, Score: SearchExactScore, Highlights: []SearchHighlight{{Field: SearchFieldName, Start: 0, End: nameRunes}}})
+		}
+		if from, to, ok := matchSyllablePinyin(syllables, queryPinyin); ok {
+			score := 0.78
+			if from == 0 {
+				score = 0.83
+			}
+			consider(SearchMatch{Field: SearchFieldPinyin, Score: score, Highlights: syllableHighlight(syllables, from, to)})
+		}
+		if len(compact) > 1 {
+			if at := strings.Index(initials, compact); at >= 0 {
+				score := 0.75
+				if at == 0 {
+					score = 0.8
+				}
+				consider(SearchMatch{Field: SearchFieldPinyinInitials, Score: score, Highlights: syllableHighlight(syllables, at, at+len(compact)-1)})
+			}
+		}
+	}
+	if path != "" {
+		if at := strings.Index(path, q); at >= 0 {
+			consider(SearchMatch{Field: SearchFieldFullNamePath, Score: 0.7, Highlights: runeHighlight(SearchFieldFullNamePath, path, at, at+len(q))})
+		}
+	}
+	if best.Score == 0 {
+		similarity := SearchTrigramSimilarity(name, q)
+		field := SearchFieldName
+		if s := SearchTrigramSimilarity(fullPinyin, queryPinyin); s > similarity {
+			similarity, field = s, SearchFieldPinyin
+		}
+		if similarity >= SearchFuzzyThreshold {
+			consider(SearchMatch{Field: field, Score: 0.65 * similarity, Fuzzy: true})
+		}
+	}
+	return best, best.Score > 0
+}
+
+// matchSyllablePinyin finds query as a run of whole syllables, where the last one may be a prefix
+// ("yanfa" or "yanf" in yan-fa-bu). It returns the first and last syllable positions.
+func matchSyllablePinyin(syllables []searchSyllable, query string) (int, int, bool) {
+	if len(query) < 2 {
+		return 0, 0, false
+	}
+	for from := range syllables {
+		rest := query
+		for to := from; to < len(syllables); to++ {
+			py := syllables[to].pinyin
+			if strings.HasPrefix(py, rest) {
+				return from, to, true
+			}
+			if !strings.HasPrefix(rest, py) {
+				break
+			}
+			rest = rest[len(py):]
+		}
+	}
+	return 0, 0, false
+}
+
+func syllableHighlight(syllables []searchSyllable, from int, to int) []SearchHighlight {
+	return []SearchHighlight{{Field: SearchFieldName, Start: syllables[from].index, End: syllables[to].index + 1}}
+}
+
+// runeHighlight converts a byte range of s into a rune range.
+func runeHighlight(field string, s string, from int, to int) []SearchHighlight {
+	return []SearchHighlight{{Field: field, Start: len([]rune(s[:from])), End: len([]rune(s[:to]))}}
+}
+
+// SearchTrigramSimilarity mirrors pg_trgm similarity(): the Jaccard index of the padded rune trigrams of
+// the words in a and b.
+func SearchTrigramSimilarity(a string, b string) float64 {
+	ta, tb := searchTrigrams(a), searchTrigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+func searchTrigrams(s string) map[string]struct{} {
+	out := map[string]struct{}{}
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		runes := append([]rune("  "), []rune(word)...)
+		runes = append(runes, ' ')
+		for i := 0; i+3 <= len(runes); i++ {
+			out[string(runes[i:i+3])] = struct{}{}
+		}
+	}
+	return out
+}
+
+const listSearchIndexPendingNamesQuery = `
+SELECT DISTINCT name
+FROM orgunit.org_unit_search_index
+WHERE tenant_uuid = $1::uuid
+  AND name_pinyin IS NULL
+LIMIT $2::int
+`
+
+const setSearchIndexPinyinQuery = `
+SELECT orgunit.set_org_unit_search_pinyin($1::uuid, $2::text[], $3::text[], $4::text[])
+`
+
+// FillSearchPinyin computes pinyin for search index rows that do not have it yet (new or renamed
+// versions) and returns the number of rows filled. It runs inside the caller's transaction with
+// app.current_tenant already set.
+func FillSearchPinyin(ctx context.Context, tx pgx.Tx, tenantUUID string) (int, error) {
+	total := 0
+	for {
+		rows, err := tx.Query(ctx, listSearchIndexPendingNamesQuery, tenantUUID, searchPinyinFillBatch)
+		if err != nil {
+			return total, err
+		}
+		var names []string
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				rows.Close()
+				return total, err
+			}
+			names = append(names, name)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return total, err
+		}
+		if len(names) == 0 {
+			return total, nil
+		}
+
+		fulls := make([]string, len(names))
+		initials := make([]string, len(names))
+		for i, name := range names {
+			fulls[i], initials[i] = SearchPinyin(name)
+		}
+		var filled int
+		if err := tx.QueryRow(ctx, setSearchIndexPinyinQuery, tenantUUID, names, fulls, initials).Scan(&filled); err != nil {
+			return total, err
+		}
+		total += filled
+		if filled == 0 || len(names) < searchPinyinFillBatch {
+			return total, nil
+		}
+	}
+}
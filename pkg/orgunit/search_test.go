@@ -0,0 +1,154 @@
+package orgunit_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	orgunit "github.com/jacksonlee411/Bugs-And-Blossoms/pkg/orgunit"
+)
+
+func TestSearchPinyin(t *testing.T) {
+	t.Parallel()
+
+	full, initials := orgunit.SearchPinyin("研发部")
+	if full != "yanfabu" || initials != "yfb" {
+		t.Fatalf("full=%q initials=%q", full, initials)
+	}
+	full, initials = orgunit.SearchPinyin("IT 运维部")
+	if full != "ityunweibu" || initials != "itywb" {
+		t.Fatalf("mixed full=%q initials=%q", full, initials)
+	}
+	if got := orgunit.SearchQueryPinyin("Yan Fa"); got != "yanfa" {
+		t.Fatalf("ascii query pinyin=%q", got)
+	}
+}
+
+func TestMatchSearch(t *testing.T) {
+	t.Parallel()
+
+	doc := orgunit.SearchDocument{OrgCode: "RD001", Name: "研发部", FullNamePath: "总部 / 研发部"}
+	cases := []struct {
+		name       string
+		query      string
+		field      string
+		exact      bool
+		tier       int
+		highlights []orgunit.SearchHighlight
+	}{
+		{name: "code", query: "rd001", field: orgunit.SearchFieldOrgCode, exact: true, tier: 2, highlights: []orgunit.SearchHighlight{{Field: "org_code", Start: 0, End: 5}}},
+		{name: "name", query: "研发部", field: orgunit.SearchFieldName, exact: true, tier: 2, highlights: []orgunit.SearchHighlight{{Field: "name", Start: 0, End: 3}}},
+		{name: "name substring", query: "发部", field: orgunit.SearchFieldName, tier: 2, highlights: []orgunit.SearchHighlight{{Field: "name", Start: 1, End: 3}}},
+		{name: "initials", query: "yfb", field: orgunit.SearchFieldPinyinInitials, exact: true, tier: 2, highlights: []orgunit.SearchHighlight{{Field: "name", Start: 0, End: 3}}},
+		{name: "full pinyin", query: "yanfabu", field: orgunit.SearchFieldPinyin, exact: true, tier: 2, highlights: []orgunit.SearchHighlight{{Field: "name", Start: 0, End: 3}}},
+		{name: "homophone", query: "研发布", field: orgunit.SearchFieldPinyin, exact: true, tier: 2, highlights: []orgunit.SearchHighlight{{Field: "name", Start: 0, End: 3}}},
+		{name: "pinyin prefix", query: "yanf", field: orgunit.SearchFieldPinyin, tier: 2, highlights: []orgunit.SearchHighlight{{Field: "name", Start: 0, End: 2}}},
+		{name: "initials infix", query: "fb", field: orgunit.SearchFieldPinyinInitials, tier: 2, highlights: []orgunit.SearchHighlight{{Field: "name", Start: 1, End: 3}}},
+		{name: "path", query: "总部", field: orgunit.SearchFieldFullNamePath, tier: 1, highlights: []orgunit.SearchHighlight{{Field: "full_name_path", Start: 0, End: 2}}},
+		{name: "misspelled pinyin", query: "yanfabo", field: orgunit.SearchFieldPinyin, tier: 0},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			m, ok := orgunit.MatchSearch(tc.query, doc)
+			if !ok {
+				t.Fatalf("no match")
+			}
+			if m.Field != tc.field || m.IsExact() != tc.exact || m.Tier() != tc.tier {
+				t.Fatalf("match=%+v", m)
+			}
+			if !reflect.DeepEqual(m.Highlights, tc.highlights) {
+				t.Fatalf("highlights=%+v", m.Highlights)
+			}
+		})
+	}
+
+	if _, ok := orgunit.MatchSearch("财务", doc); ok {
+		t.Fatal("unrelated query should not match")
+	}
+}
+
+func TestSearchTrigramSimilarity(t *testing.T) {
+	t.Parallel()
+
+	if got := orgunit.SearchTrigramSimilarity("sales", "sales"); got != 1 {
+		t.Fatalf("identical=%v", got)
+	}
+	if got := orgunit.SearchTrigramSimilarity("", "sales"); got != 0 {
+		t.Fatalf("empty=%v", got)
+	}
+	if got := orgunit.SearchTrigramSimilarity("yanfabu", "yanfabo"); got < orgunit.SearchFuzzyThreshold || got >= 1 {
+		t.Fatalf("misspelled=%v", got)
+	}
+}
+
+type namesRows struct {
+	names []string
+	idx   int
+}
+
+func (r *namesRows) Close()                                       {}
+func (r *namesRows) Err() error                                   { return nil }
+func (r *namesRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r *namesRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *namesRows) Values() ([]any, error)                       { return nil, nil }
+func (r *namesRows) RawValues() [][]byte                          { return nil }
+func (r *namesRows) Conn() *pgx.Conn                              { return nil }
+func (r *namesRows) Next() bool {
+	r.idx++
+	return r.idx <= len(r.names)
+}
+func (r *namesRows) Scan(dest ...any) error {
+	*dest[0].(*string) = r.names[r.idx-1]
+	return nil
+}
+
+type fillSearchTx struct {
+	*stubTx
+	names   []string
+	setArgs []any
+	setErr  error
+}
+
+func (t *fillSearchTx) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return &namesRows{names: t.names}, nil
+}
+
+func (t *fillSearchTx) QueryRow(_ context.Context, _ string, args ...any) pgx.Row {
+	t.setArgs = args
+	if t.setErr != nil {
+		return stubRow{err: t.setErr}
+	}
+	return stubRow{vals: []any{len(t.names)}}
+}
+
+func TestFillSearchPinyin(t *testing.T) {
+	t.Parallel()
+
+	tx := &fillSearchTx{stubTx: &stubTx{}, names: []string{"研发部", "HR"}}
+	filled, err := orgunit.FillSearchPinyin(context.Background(), tx, "t1")
+	if err != nil || filled != 2 {
+		t.Fatalf("filled=%d err=%v", filled, err)
+	}
+	want := []any{"t1", []string{"研发部", "HR"}, []string{"yanfabu", "hr"}, []string{"yfb", "hr"}}
+	if !reflect.DeepEqual(tx.setArgs, want) {
+		t.Fatalf("args=%v", tx.setArgs)
+	}
+
+	empty := &fillSearchTx{stubTx: &stubTx{}}
+	if filled, err := orgunit.FillSearchPinyin(context.Background(), empty, "t1"); err != nil || filled != 0 || empty.setArgs != nil {
+		t.Fatalf("filled=%d err=%v args=%v", filled, err, empty.setArgs)
+	}
+
+	boom := errors.New("boom")
+	if _, err := orgunit.FillSearchPinyin(context.Background(), &fillSearchTx{stubTx: &stubTx{}, names: []string{"x"}, setErr: boom}, "t1"); !errors.Is(err, boom) {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := orgunit.FillSearchPinyin(context.Background(), &stubTx{queryErr: boom}, "t1"); !errors.Is(err, boom) {
+		t.Fatalf("err=%v", err)
+	}
+}
@@ -0,0 +1,130 @@
+// Package pagecursor issues the opaque page tokens of the HTTP APIs. A token is base64 JSON, a dot, and an
+// HMAC-SHA256 of the JSON. It names the listing it pages (kind), carries a fingerprint of the query it was
+// issued for (scope) and the caller's keyset position, so it can neither be forged nor replayed against a
+// different filter, tenant or principal.
+package pagecursor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// SecretEnv keys every page cursor; replicas behind one load balancer must share it.
+const SecretEnv = "CURSOR_SIGNING_SECRET"
+
+var (
+	ErrInvalid       = errors.New("pagecursor: invalid cursor")
+	ErrSecretMissing = errors.New("pagecursor: " + SecretEnv + " is required; all replicas must share it")
+)
+
+var (
+	keyOnce sync.Once
+	key     []byte
+)
+
+type envelope struct {
+	Kind     string          `json:"k"`
+	Scope    string          `json:"s"`
+	Position json.RawMessage `json:"p"`
+}
+
+// RequireSecret fails with ErrSecretMissing when SecretEnv is unset. Servers that persist data call it at
+// startup: without a shared secret each process would sign with its own random key, and cursors would stop
+// verifying after a restart or on another replica.
+func RequireSecret() error {
+	if configuredSecret() == "" {
+		return ErrSecretMissing
+	}
+	return nil
+}
+
+func configuredSecret() string {
+	return strings.TrimSpace(os.Getenv(SecretEnv))
+}
+
+// signingKey reads the secret once. Only in-memory servers, which skip RequireSecret, fall back to a random key.
+func signingKey() []byte {
+	keyOnce.Do(func() {
+		if v := configuredSecret(); v != "" {
+			key = []byte(v)
+			return
+		}
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	})
+	return key
+}
+
+// Encode signs position for the listing kind and the query fingerprint scope.
+func Encode(kind string, scope string, position any) string {
+	rawPosition, _ := json.Marshal(position)
+	payload, _ := json.Marshal(envelope{Kind: kind, Scope: scope, Position: rawPosition})
+	mac := hmac.New(sha256.New, signingKey())
+	mac.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Decode verifies the signature and that the token was issued for kind and scope, then unmarshals its
+// position into dst. Every failure is ErrInvalid.
+func Decode(token string, kind string, scope string, dst any) error {
+	rawPayload, rawSig, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok {
+		return ErrInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(rawPayload)
+	if err != nil {
+		return ErrInvalid
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(rawSig)
+	if err != nil {
+		return ErrInvalid
+	}
+	mac := hmac.New(sha256.New, signingKey())
+	mac.Write(payload)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return ErrInvalid
+	}
+	var env envelope
+	if err := json.Unmarshal(payload, &env); err != nil || env.Kind != kind || env.Scope != scope {
+		return ErrInvalid
+	}
+	if err := json.Unmarshal(env.Position, dst); err != nil {
+		return ErrInvalid
+	}
+	return nil
+}
+
+// Scope fingerprints the query parameters that shape a paged result, together with whoever the result
+// belongs to (a tenant, a tenant and principal, or a superadmin console). Paging parameters (cursor, page,
+// size, limit) are left out so a client may change the page size between pages.
+func Scope(owner string, kind string, values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		switch k {
+		case "cursor", "page", "size", "limit":
+			continue
+		}
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s\x00%s", owner, kind)
+	for _, k := range keys {
+		vals := append([]string(nil), values[k]...)
+		for i := range vals {
+			vals[i] = strings.TrimSpace(vals[i])
+		}
+		_, _ = fmt.Fprintf(h, "\x00%s=%s", k, strings.Join(vals, "\x01"))
+	}
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:16])
+}
@@ -0,0 +1,73 @@
+package pagecursor
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type position struct {
+	ID int64 `json:"id"`
+}
+
+func resetKey(t *testing.T) {
+	t.Helper()
+	keyOnce = sync.Once{}
+	key = nil
+	t.Cleanup(func() {
+		keyOnce = sync.Once{}
+		key = nil
+	})
+}
+
+func TestEncodeDecode(t *testing.T) {
+	t.Setenv(SecretEnv, "test-secret")
+	resetKey(t)
+
+	scope := Scope("t1", "list", url.Values{"q": {" a "}, "limit": {"10"}})
+	token := Encode("list", scope, position{ID: 42})
+
+	var got position
+	if err := Decode(token, "list", scope, &got); err != nil || got.ID != 42 {
+		t.Fatalf("got=%+v err=%v", got, err)
+	}
+	if same := Scope("t1", "list", url.Values{"q": {"a"}, "limit": {"50"}, "cursor": {token}}); same != scope {
+		t.Fatal("paging params must not change the scope")
+	}
+
+	payload, sig, _ := strings.Cut(token, ".")
+	cases := map[string]struct {
+		token string
+		kind  string
+		scope string
+	}{
+		"tampered signature": {token: payload + "." + strings.Repeat("A", len(sig)), kind: "list", scope: scope},
+		"not a token":        {token: "page-2", kind: "list", scope: scope},
+		"other kind":         {token: token, kind: "search", scope: scope},
+		"other query":        {token: token, kind: "list", scope: Scope("t1", "list", url.Values{"q": {"b"}})},
+		"other owner":        {token: token, kind: "list", scope: Scope("t2", "list", url.Values{"q": {"a"}})},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err := Decode(tc.token, tc.kind, tc.scope, &got); !errors.Is(err, ErrInvalid) {
+				t.Fatalf("err=%v", err)
+			}
+		})
+	}
+}
+
+func TestRequireSecret(t *testing.T) {
+	t.Setenv(SecretEnv, "new-secret")
+	resetKey(t)
+	if RequireSecret() != nil || string(signingKey()) != "new-secret" {
+		t.Fatalf("key=%q", signingKey())
+	}
+
+	t.Setenv(SecretEnv, " ")
+	resetKey(t)
+	if !errors.Is(RequireSecret(), ErrSecretMissing) || len(signingKey()) != 32 {
+		t.Fatalf("err=%v key=%d", RequireSecret(), len(signingKey()))
+	}
+}
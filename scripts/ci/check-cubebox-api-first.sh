@@ -11,6 +11,7 @@ targets=(
   internal/server
   modules/cubebox
   modules/orgunit/presentation/cubebox
+  modules/iam/presentation/cubebox
 )
 
 ignore_globs=(
@@ -3,3 +3,6 @@ set -euo pipefail
 
 echo "[routing] running routing gates"
 go test ./internal/routing -run '^TestGate' -count=1
+
+echo "[routing] checking openapi document against allowlist"
+go test ./internal/server -run '^TestOpenAPI' -count=1